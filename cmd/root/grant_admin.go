@@ -0,0 +1,44 @@
+package root
+
+import (
+	"github.com/meowmix1337/the_recipe_book/internal/api"
+	"github.com/meowmix1337/the_recipe_book/internal/config"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+
+	"github.com/spf13/cobra"
+)
+
+//nolint:gochecknoglobals // cobra command
+var grantAdminCmd = &cobra.Command{
+	Use:   "grant-admin <user uuid> [scope...]",
+	Short: "Replace the admin scopes of a user (support, billing, superadmin), no scopes revoke them",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		scopes := make([]domain.AdminScope, 0, len(args)-1)
+		for _, arg := range args[1:] {
+			scope, err := domain.ParseAdminScope(arg)
+			if err != nil {
+				return err
+			}
+			scopes = append(scopes, scope)
+		}
+
+		cfg, err := config.NewConfig()
+		if err != nil {
+			return err
+		}
+
+		userRegion, err := cmd.Flags().GetString("region")
+		if err != nil {
+			return err
+		}
+
+		return api.NewServer(cfg).GrantAdmin(cmd.Context(), userRegion, args[0], scopes)
+	},
+}
+
+//nolint:gochecknoinits // cobra command
+func init() {
+	grantAdminCmd.Flags().String("region", "", "Data region of the user, the home region by default")
+	rootCmd.AddCommand(grantAdminCmd)
+}
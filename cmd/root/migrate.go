@@ -0,0 +1,141 @@
+package root
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+
+	"github.com/meowmix1337/the_recipe_book/internal/api"
+	"github.com/meowmix1337/the_recipe_book/internal/config"
+	"github.com/meowmix1337/the_recipe_book/migrations"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+//nolint:gochecknoglobals // cobra command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate the home database and every region database with the embedded migrations",
+}
+
+//nolint:gochecknoglobals // cobra command
+var migrateUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Apply every pending migration",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return eachDatabase(cmd, func(name string, runner *migrations.Runner) error {
+			if err := runner.Up(); err != nil {
+				return err
+			}
+			log.Info().Str("region", name).Msg("database migrated")
+			return nil
+		})
+	},
+}
+
+//nolint:gochecknoglobals // cobra command
+var migrateDownCmd = &cobra.Command{
+	Use:   "down [steps]",
+	Short: "Revert the last migrations, one unless steps is given",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		steps := 1
+		if len(args) == 1 {
+			var err error
+			if steps, err = strconv.Atoi(args[0]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid steps: %s", args[0])
+			}
+		}
+
+		return eachDatabase(cmd, func(name string, runner *migrations.Runner) error {
+			if err := runner.Down(steps); err != nil {
+				return err
+			}
+			log.Info().Str("region", name).Int("steps", steps).Msg("migrations reverted")
+			return nil
+		})
+	},
+}
+
+//nolint:gochecknoglobals // cobra command
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the schema version of every database, exits non-zero when one is dirty",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		var dirty bool
+		err := eachDatabase(cmd, func(name string, runner *migrations.Runner) error {
+			status, err := runner.Status()
+			if err != nil {
+				return err
+			}
+
+			event := log.Info()
+			if status.Dirty {
+				dirty = true
+				event = log.Error()
+			}
+			event.Str("region", name).
+				Uint("version", status.Version).
+				Uint("latest", status.Latest).
+				Int("pending", status.Pending).
+				Bool("dirty", status.Dirty).
+				Msg("migration status")
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if dirty {
+			return fmt.Errorf("a database has a dirty migration, fix it and force the version")
+		}
+		return nil
+	},
+}
+
+// eachDatabase runs fn with the runner of every database, or only of the database of the
+// region flag.
+func eachDatabase(cmd *cobra.Command, fn func(name string, runner *migrations.Runner) error) error {
+	cfg, err := config.NewConfig()
+	if err != nil {
+		return err
+	}
+
+	dsns := api.NewServer(cfg).DatabaseDSNs()
+	names := slices.Sorted(maps.Keys(dsns))
+	if cmd.Flags().Changed("region") {
+		name, err := cmd.Flags().GetString("region")
+		if err != nil {
+			return err
+		}
+		if _, ok := dsns[name]; !ok {
+			return fmt.Errorf("unknown region: %q", name)
+		}
+		names = []string{name}
+	}
+
+	for _, name := range names {
+		runner, err := migrations.New(dsns[name])
+		if err != nil {
+			return fmt.Errorf("region %q: %w", name, err)
+		}
+		err = fn(name, runner)
+		runner.Close() //nolint:errcheck // the outcome of fn matters
+		if err != nil {
+			return fmt.Errorf("region %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+//nolint:gochecknoinits // cobra command
+func init() {
+	migrateCmd.PersistentFlags().String("region", "", "Only migrate the database of this region, empty for the home database")
+	migrateCmd.AddCommand(migrateUpCmd, migrateDownCmd, migrateStatusCmd)
+	rootCmd.AddCommand(migrateCmd)
+}
@@ -1,6 +1,11 @@
 package root
 
 import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
 	"github.com/meowmix1337/the_recipe_book/internal/api"
 	"github.com/meowmix1337/the_recipe_book/internal/config"
 
@@ -18,35 +23,65 @@ var rootCmd = &cobra.Command{
 		cfg, err := config.NewConfig()
 		if err != nil {
 			log.Err(err).Msg("Error loading configuration")
-			return
+			os.Exit(1)
 		}
 
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
 		server := api.NewServer(cfg)
-		server.Start()
+		if err = server.Start(ctx); err != nil {
+			log.Err(err).Msg("Error running server")
+			stop()
+			os.Exit(1)
+		}
 	},
 }
 
 //nolint:gochecknoinits // viper library
 func init() {
-	// Define command-line flags and bind them to Viper.
+	// Define command-line flags and bind them to Viper, flags win over the environment and the
+	// config files.
+	rootCmd.PersistentFlags().String("config", "", "YAML config file, its keys are the environment variables")
 	rootCmd.PersistentFlags().
 		String("environment", "", "Application environment (e.g., production, staging, qa, development)")
 	rootCmd.PersistentFlags().String("hostname", "", "Hostname of the application")
 	rootCmd.PersistentFlags().String("port", "", "Port for the application")
 	rootCmd.PersistentFlags().String("loglevel", "", "log level for the application")
 	rootCmd.PersistentFlags().String("jwtSecret", "", "a secret for JWT token generation")
+	rootCmd.PersistentFlags().String("passwordHasher", "", "algorithm of new password hashes, bcrypt or argon2id")
+	rootCmd.PersistentFlags().Int("bcryptCost", 0, "bcrypt cost of new password hashes")
+	rootCmd.PersistentFlags().String("dbDSN", "", "DSN of the home database, replaces the DB_* settings")
+	rootCmd.PersistentFlags().String("redisHost", "", "host of redis")
+	rootCmd.PersistentFlags().String("redisPort", "", "port of redis")
+	rootCmd.PersistentFlags().Int("rateLimitAnonymous", 0, "requests per minute and IP for login and signup, 0 disables it")
+	rootCmd.PersistentFlags().Int("rateLimitUser", 0, "requests per minute and user, 0 disables it")
+	rootCmd.PersistentFlags().String("workerIntervals", "", "name=duration pairs of background worker intervals")
+	rootCmd.PersistentFlags().String("llmProvider", "", "language model provider of todo breakdowns, disabled by default")
+	rootCmd.PersistentFlags().String("embeddingProvider", "", "embedding provider of semantic search, disabled by default")
 
 	// Bind the flags to Viper.
-	viper.BindPFlag("ENVIRONMENT", rootCmd.PersistentFlags().Lookup("environment")) //nolint:errcheck // viper
-	viper.BindPFlag("HOSTNAME", rootCmd.PersistentFlags().Lookup("hostname"))       //nolint:errcheck // viper
-	viper.BindPFlag("PORT", rootCmd.PersistentFlags().Lookup("port"))               //nolint:errcheck // viper
-	viper.BindPFlag("LOG_LEVEL", rootCmd.PersistentFlags().Lookup("loglevel"))      //nolint:errcheck // viper
-	viper.BindPFlag("JWT_SECRET", rootCmd.PersistentFlags().Lookup("jwtSecret"))    //nolint:errcheck // viper
+	viper.BindPFlag("ENVIRONMENT", rootCmd.PersistentFlags().Lookup("environment"))                 //nolint:errcheck // viper
+	viper.BindPFlag("HOSTNAME", rootCmd.PersistentFlags().Lookup("hostname"))                       //nolint:errcheck // viper
+	viper.BindPFlag("PORT", rootCmd.PersistentFlags().Lookup("port"))                               //nolint:errcheck // viper
+	viper.BindPFlag("LOG_LEVEL", rootCmd.PersistentFlags().Lookup("loglevel"))                      //nolint:errcheck // viper
+	viper.BindPFlag("JWT_SECRET", rootCmd.PersistentFlags().Lookup("jwtSecret"))                    //nolint:errcheck // viper
+	viper.BindPFlag("CONFIG_FILE", rootCmd.PersistentFlags().Lookup("config"))                      //nolint:errcheck // viper
+	viper.BindPFlag("PASSWORD_HASHER", rootCmd.PersistentFlags().Lookup("passwordHasher"))          //nolint:errcheck // viper
+	viper.BindPFlag("BCRYPT_COST", rootCmd.PersistentFlags().Lookup("bcryptCost"))                  //nolint:errcheck // viper
+	viper.BindPFlag("DB_DSN", rootCmd.PersistentFlags().Lookup("dbDSN"))                            //nolint:errcheck // viper
+	viper.BindPFlag("REDIS_HOST", rootCmd.PersistentFlags().Lookup("redisHost"))                    //nolint:errcheck // viper
+	viper.BindPFlag("REDIS_PORT", rootCmd.PersistentFlags().Lookup("redisPort"))                    //nolint:errcheck // viper
+	viper.BindPFlag("RATE_LIMIT_ANONYMOUS", rootCmd.PersistentFlags().Lookup("rateLimitAnonymous")) //nolint:errcheck // viper
+	viper.BindPFlag("RATE_LIMIT_USER", rootCmd.PersistentFlags().Lookup("rateLimitUser"))           //nolint:errcheck // viper
+	viper.BindPFlag("WORKER_INTERVALS", rootCmd.PersistentFlags().Lookup("workerIntervals"))        //nolint:errcheck // viper
+	viper.BindPFlag("LLM_PROVIDER", rootCmd.PersistentFlags().Lookup("llmProvider"))                //nolint:errcheck // viper
+	viper.BindPFlag("EMBEDDING_PROVIDER", rootCmd.PersistentFlags().Lookup("embeddingProvider"))    //nolint:errcheck // viper
 }
 
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		log.Err(err).Msg("Error executing cmd")
-		// Handle errors appropriately in real applications.
+		os.Exit(1)
 	}
 }
@@ -0,0 +1,31 @@
+package root
+
+import (
+	"fmt"
+
+	"github.com/meowmix1337/the_recipe_book/internal/webpush"
+
+	"github.com/spf13/cobra"
+)
+
+//nolint:gochecknoglobals // cobra command
+var vapidKeysCmd = &cobra.Command{
+	Use:   "vapid-keys",
+	Short: "Generate the VAPID key pair that enables Web Push reminders",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		privateKey, publicKey, err := webpush.GenerateKeys()
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "VAPID_PRIVATE_KEY=%s\n# public key, served at /api/v1/push/public-key\n# %s\n", privateKey, publicKey)
+
+		return nil
+	},
+}
+
+//nolint:gochecknoinits // cobra command
+func init() {
+	rootCmd.AddCommand(vapidKeysCmd)
+}
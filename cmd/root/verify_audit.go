@@ -0,0 +1,27 @@
+package root
+
+import (
+	"github.com/meowmix1337/the_recipe_book/internal/api"
+	"github.com/meowmix1337/the_recipe_book/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+//nolint:gochecknoglobals // cobra command
+var verifyAuditCmd = &cobra.Command{
+	Use:   "verify-audit",
+	Short: "Verify the hash chain of the audit log, exits non-zero when it was tampered with",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		cfg, err := config.NewConfig()
+		if err != nil {
+			return err
+		}
+
+		return api.NewServer(cfg).VerifyAudit(cmd.Context())
+	},
+}
+
+//nolint:gochecknoinits // cobra command
+func init() {
+	rootCmd.AddCommand(verifyAuditCmd)
+}
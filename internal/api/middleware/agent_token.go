@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
+)
+
+// AgentTokenAuthenticator resolves a raw agent token.
+type AgentTokenAuthenticator func(ctx context.Context, token string) (*domain.AgentToken, error)
+
+// AgentTokenMiddleware authenticates the requests of AI assistants with the bearer agent token
+// of the user. The scopes of the token are checked by the routes. The queries of the request go
+// to the data region the token was issued in.
+func AgentTokenMiddleware(authenticate AgentTokenAuthenticator) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token := strings.TrimPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
+			if token == "" {
+				return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+			}
+
+			agentToken, err := authenticate(c.Request().Context(), token)
+			if err != nil {
+				switch {
+				case errors.Is(err, domain.ErrInvalidAgentToken):
+					return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+				case errors.Is(err, domain.ErrUnknownRegion):
+					return echo.NewHTTPError(http.StatusMisdirectedRequest, domain.ErrUnknownRegion.Error())
+				}
+				return echo.NewHTTPError(http.StatusInternalServerError, "Internal Server Error")
+			}
+
+			c.SetRequest(c.Request().WithContext(region.WithRegion(c.Request().Context(), agentToken.Region)))
+			c.Set("agent_token", agentToken)
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/meowmix1337/the_recipe_book/internal/audit"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+// AuditRecorder appends an entry to the audit log.
+type AuditRecorder func(ctx context.Context, entry *domain.AuditEntry)
+
+// AuditMiddleware records every successful state-changing request in the audit log. The entry
+// is attributed to the authenticated user, unauthenticated requests like signup and login are
+// attributed by the services through audit.SetUser.
+func AuditMiddleware(record AuditRecorder) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			switch req.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				return next(c)
+			}
+
+			entry := &domain.AuditEntry{
+				Path:      req.URL.Path,
+				IP:        c.RealIP(),
+				UserAgent: req.UserAgent(),
+			}
+			c.SetRequest(req.WithContext(audit.WithEntry(req.Context(), entry)))
+
+			err := next(c)
+
+			status := c.Response().Status
+			if err != nil {
+				status = ErrorStatus(err)
+			}
+			// failed requests did not change any state
+			if status >= http.StatusBadRequest {
+				return err
+			}
+
+			if claims, ok := c.Get("claims").(*domain.JWTCustomClaims); ok {
+				entry.UserID = claims.UserID
+			} else if grant, ok := c.Get("oauth_grant").(*domain.OAuthGrant); ok {
+				entry.UserID = grant.UserID
+			} else if token, ok := c.Get("agent_token").(*domain.AgentToken); ok {
+				entry.UserID = token.UserID
+			}
+			entry.Action = req.Method + " " + c.Path()
+			entry.Status = status
+			entry.RequestID = c.Response().Header().Get(echo.HeaderXRequestID)
+
+			// the entry is recorded even if the client went away after the change was made
+			record(context.WithoutCancel(c.Request().Context()), entry)
+
+			return err
+		}
+	}
+}
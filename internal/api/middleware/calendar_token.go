@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
+)
+
+// CalendarTokenAuthenticator resolves a raw calendar token.
+type CalendarTokenAuthenticator func(ctx context.Context, token string) (*domain.CalendarToken, error)
+
+// CalendarTokenMiddleware authenticates calendar subscriptions. Calendar apps only know the
+// subscription URL, so the token is read from the token query parameter unless the request
+// has an Authorization header. The queries of the request go to the data region the token was
+// issued in.
+func CalendarTokenMiddleware(authenticate CalendarTokenAuthenticator) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			// calendar tokens are read-only
+			if c.Request().Method != http.MethodGet && c.Request().Method != http.MethodHead {
+				return echo.NewHTTPError(http.StatusMethodNotAllowed, "Method Not Allowed")
+			}
+
+			token := strings.TrimPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
+			if token == "" {
+				token = c.QueryParam("token")
+			}
+			if token == "" {
+				return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+			}
+
+			calendarToken, err := authenticate(c.Request().Context(), token)
+			if err != nil {
+				switch {
+				case errors.Is(err, domain.ErrInvalidCalendarToken):
+					return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+				case errors.Is(err, domain.ErrUnknownRegion):
+					return echo.NewHTTPError(http.StatusMisdirectedRequest, domain.ErrUnknownRegion.Error())
+				}
+				return echo.NewHTTPError(http.StatusInternalServerError, "Internal Server Error")
+			}
+
+			c.SetRequest(c.Request().WithContext(region.WithRegion(c.Request().Context(), calendarToken.Region)))
+			c.Set("calendar_token", calendarToken)
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	CanaryVariantStable = "stable"
+	CanaryVariantCanary = "canary"
+
+	cohortBuckets = 100
+)
+
+// CanaryConfig controls how requests are split between the stable and canary handlers.
+type CanaryConfig struct {
+	// Percentage of users (0-100) that are routed to the canary handler.
+	Percentage int
+	// Header allows a client to opt in ("true") or out ("false") of the canary regardless of cohort.
+	Header string
+}
+
+// CanaryStats holds the comparison metrics for a single route variant.
+type CanaryStats struct {
+	Requests     uint64        `json:"requests"`
+	Errors       uint64        `json:"errors"`
+	TotalLatency time.Duration `json:"total_latency"`
+}
+
+// CanaryMetrics collects per route and variant metrics so both handlers can be compared during a rollout.
+type CanaryMetrics struct {
+	mu    sync.Mutex
+	stats map[string]map[string]*CanaryStats
+}
+
+func NewCanaryMetrics() *CanaryMetrics {
+	return &CanaryMetrics{
+		stats: make(map[string]map[string]*CanaryStats),
+	}
+}
+
+func (m *CanaryMetrics) record(route string, variant string, latency time.Duration, failed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	variants, ok := m.stats[route]
+	if !ok {
+		variants = make(map[string]*CanaryStats)
+		m.stats[route] = variants
+	}
+
+	stats, ok := variants[variant]
+	if !ok {
+		stats = &CanaryStats{}
+		variants[variant] = stats
+	}
+
+	stats.Requests++
+	stats.TotalLatency += latency
+	if failed {
+		stats.Errors++
+	}
+}
+
+// Snapshot returns a copy of the collected metrics keyed by route and variant.
+func (m *CanaryMetrics) Snapshot() map[string]map[string]CanaryStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := make(map[string]map[string]CanaryStats, len(m.stats))
+	for route, variants := range m.stats {
+		snapshot[route] = make(map[string]CanaryStats, len(variants))
+		for variant, stats := range variants {
+			snapshot[route][variant] = *stats
+		}
+	}
+
+	return snapshot
+}
+
+// Canary routes a request to either the stable or canary handler based on the
+// request header or the user's cohort, recording latency and status for each variant.
+func Canary(route string, cfg CanaryConfig, metrics *CanaryMetrics, stable, canary echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		variant := CanaryVariantStable
+		handler := stable
+		if inCanary(c, cfg) {
+			variant = CanaryVariantCanary
+			handler = canary
+		}
+
+		start := time.Now()
+		err := handler(c)
+		latency := time.Since(start)
+
+		status := c.Response().Status
+		if err != nil {
+			status = ErrorStatus(err)
+		}
+		failed := status >= 500
+
+		if metrics != nil {
+			metrics.record(route, variant, latency, failed)
+		}
+
+		log.Info().
+			Str("route", route).
+			Str("variant", variant).
+			Int("status", status).
+			Dur("latency", latency).
+			Msg("canary")
+
+		return err
+	}
+}
+
+func inCanary(c echo.Context, cfg CanaryConfig) bool {
+	if cfg.Header != "" {
+		if value := c.Request().Header.Get(cfg.Header); value != "" {
+			optIn, err := strconv.ParseBool(strings.TrimSpace(value))
+			if err == nil {
+				return optIn
+			}
+		}
+	}
+
+	if cfg.Percentage <= 0 {
+		return false
+	}
+	if cfg.Percentage >= cohortBuckets {
+		return true
+	}
+
+	return cohortBucket(cohortKey(c)) < uint32(cfg.Percentage)
+}
+
+// cohortKey keeps a user pinned to the same variant across requests, falling back to the client IP.
+func cohortKey(c echo.Context) string {
+	if claims, ok := c.Get("claims").(*domain.JWTCustomClaims); ok {
+		return claims.UUID
+	}
+
+	return c.RealIP()
+}
+
+func cohortBucket(key string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(key)) //nolint:errcheck // hash writes never fail
+	return h.Sum32() % cohortBuckets
+}
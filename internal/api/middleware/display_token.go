@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+// DisplayTokenAuthenticator resolves a raw display token.
+type DisplayTokenAuthenticator func(ctx context.Context, token string) (*domain.DisplayToken, error)
+
+// DisplayTokenMiddleware authenticates read-only display requests. The token is read from the
+// Authorization header or, for kiosk browsers that can not set headers, the token query parameter.
+func DisplayTokenMiddleware(authenticate DisplayTokenAuthenticator) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			// display tokens are read-only
+			if c.Request().Method != http.MethodGet && c.Request().Method != http.MethodHead {
+				return echo.NewHTTPError(http.StatusMethodNotAllowed, "Method Not Allowed")
+			}
+
+			token := strings.TrimPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
+			if token == "" {
+				token = c.QueryParam("token")
+			}
+			if token == "" {
+				return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+			}
+
+			displayToken, err := authenticate(c.Request().Context(), token)
+			if err != nil {
+				if errors.Is(err, domain.ErrInvalidDisplayToken) {
+					return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+				}
+				return echo.NewHTTPError(http.StatusInternalServerError, "Internal Server Error")
+			}
+
+			c.Set("display_token", displayToken)
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
+)
+
+// FeedTokenAuthenticator resolves a raw feed token.
+type FeedTokenAuthenticator func(ctx context.Context, token string) (*domain.FeedToken, error)
+
+// FeedTokenMiddleware authenticates list feeds. Most feed readers can't send headers, so the
+// token is read from the token query parameter unless the request has an Authorization header.
+// The queries of the request go to the data region the token was issued in.
+func FeedTokenMiddleware(authenticate FeedTokenAuthenticator) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			// feed tokens are read-only
+			if c.Request().Method != http.MethodGet && c.Request().Method != http.MethodHead {
+				return echo.NewHTTPError(http.StatusMethodNotAllowed, "Method Not Allowed")
+			}
+
+			token := strings.TrimPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
+			if token == "" {
+				token = c.QueryParam("token")
+			}
+			if token == "" {
+				return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+			}
+
+			feedToken, err := authenticate(c.Request().Context(), token)
+			if err != nil {
+				switch {
+				case errors.Is(err, domain.ErrInvalidFeedToken):
+					return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+				case errors.Is(err, domain.ErrUnknownRegion):
+					return echo.NewHTTPError(http.StatusMisdirectedRequest, domain.ErrUnknownRegion.Error())
+				}
+				return echo.NewHTTPError(http.StatusInternalServerError, "Internal Server Error")
+			}
+
+			c.SetRequest(c.Request().WithContext(region.WithRegion(c.Request().Context(), feedToken.Region)))
+			c.Set("feed_token", feedToken)
+			return next(c)
+		}
+	}
+}
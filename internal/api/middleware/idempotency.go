@@ -0,0 +1,178 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/meowmix1337/the_recipe_book/internal/idempotency"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// IdempotencyKeyHeader carries the key a client picks for a request and reuses on retries.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks a replayed response.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	// idempotencyNoStoreKey marks a request whose response must not be stored.
+	idempotencyNoStoreKey = "idempotency_no_store"
+
+	maxIdempotencyKey = 255
+	// maxIdempotentBody caps the request and response bodies of idempotent requests, both are
+	// held in memory.
+	maxIdempotentBody = 16 << 20
+)
+
+// IdempotencyMiddleware replays the response of a POST sent with an Idempotency-Key when the
+// client retries it, instead of running it again. Keys are scoped by user, or by client IP on
+// routes without a session, and bound to the method, path and body they were first used with:
+// reusing a key for another request is answered with 422, a retry while the first request is
+// still running with 409. Failed requests, errors and 5xx responses, release the key so they
+// can be retried, as do routes marked with NoIdempotentStore. Requests are run without replay when the store fails. This must be set after
+// RegionMiddleware on authenticated routes.
+func IdempotencyMiddleware(store idempotency.Store) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(IdempotencyKeyHeader)
+			if key == "" || c.Request().Method != http.MethodPost {
+				return next(c)
+			}
+			if len(key) > maxIdempotencyKey {
+				return echo.NewHTTPError(http.StatusBadRequest,
+					fmt.Sprintf("%s is longer than %d characters", IdempotencyKeyHeader, maxIdempotencyKey))
+			}
+
+			body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxIdempotentBody+1))
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+			}
+			if len(body) > maxIdempotentBody {
+				return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "Request Entity Too Large")
+			}
+			c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+			key = idempotencyScope(c) + ":" + key
+			fingerprint := requestFingerprint(c.Request().Method, c.Path(), body)
+			stored, err := store.Begin(c.Request().Context(), key, fingerprint, time.Now().Add(idempotency.TTL))
+			if err != nil {
+				log.Err(err).Msg("error claiming idempotency key")
+				return next(c)
+			}
+
+			switch {
+			case stored == nil:
+				return record(c, next, store, key, fingerprint)
+			case stored.Fingerprint != fingerprint:
+				return echo.NewHTTPError(http.StatusUnprocessableEntity,
+					fmt.Sprintf("%s was already used for another request", IdempotencyKeyHeader))
+			case !stored.Completed():
+				return echo.NewHTTPError(http.StatusConflict,
+					fmt.Sprintf("a request with this %s is still in progress", IdempotencyKeyHeader))
+			}
+
+			c.Response().Header().Set(IdempotentReplayedHeader, "true")
+			return c.Blob(stored.StatusCode, stored.ContentType, stored.Body)
+		}
+	}
+}
+
+// NoIdempotentStore marks the responses of a route as not storable, for routes that return
+// credentials or secrets which must not be kept in plaintext. The key is released after the
+// request, so a retry runs it again.
+func NoIdempotentStore(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		c.Set(idempotencyNoStoreKey, true)
+		return next(c)
+	}
+}
+
+// record runs the request and stores its response for replay.
+func record(c echo.Context, next echo.HandlerFunc, store idempotency.Store, key string, fingerprint string) error {
+	recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+	c.Response().Writer = recorder
+
+	err := next(c)
+	ctx := c.Request().Context()
+	noStore, _ := c.Get(idempotencyNoStoreKey).(bool)
+	if err != nil || c.Response().Status >= http.StatusInternalServerError || recorder.overflow || noStore {
+		if releaseErr := store.Release(ctx, key); releaseErr != nil {
+			log.Err(releaseErr).Msg("error releasing idempotency key")
+		}
+		return err
+	}
+
+	err = store.Complete(ctx, key, &idempotency.Response{
+		Fingerprint: fingerprint,
+		StatusCode:  c.Response().Status,
+		ContentType: c.Response().Header().Get(echo.HeaderContentType),
+		Body:        recorder.body.Bytes(),
+	})
+	if err != nil {
+		log.Err(err).Msg("error storing idempotent response")
+	}
+
+	return nil
+}
+
+// idempotencyScope keeps the keys of different users apart, so a key can't replay the
+// response of someone else.
+func idempotencyScope(c echo.Context) string {
+	if claims, ok := c.Get("claims").(*domain.JWTCustomClaims); ok {
+		return fmt.Sprintf("user_%v_%v", claims.Region, claims.UserID)
+	}
+
+	return "ip_" + c.RealIP()
+}
+
+func requestFingerprint(method string, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n")) //nolint:errcheck // hash writes never fail
+	hash.Write(body)                               //nolint:errcheck // hash writes never fail
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder copies the response body while it is written, overflow is set when the
+// body is too large to be stored.
+type responseRecorder struct {
+	http.ResponseWriter
+	body     bytes.Buffer
+	overflow bool
+}
+
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	if !r.overflow {
+		if r.body.Len()+len(p) > maxIdempotentBody {
+			r.overflow = true
+			r.body.Reset()
+		} else {
+			r.body.Write(p)
+		}
+	}
+
+	return r.ResponseWriter.Write(p)
+}
+
+func (r *responseRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := r.ResponseWriter.(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+
+	return nil, nil, errors.New("response writer does not support hijacking")
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+// IPAllowlistChecker returns ErrIPNotAllowed when the user can't access the API from ip.
+type IPAllowlistChecker func(ctx context.Context, userID uint, ip string) error
+
+// IPAllowlistMiddleware rejects requests from outside the IP allowlist of the workspace of the
+// user with 403 Forbidden. The routes in exempt stay reachable, an owner who locked themselves
+// out needs them to suspend the allowlist. The client IP is c.RealIP(), which only trusts
+// X-Forwarded-For from the configured proxies. This must be set after RegionMiddleware.
+func IPAllowlistMiddleware(check IPAllowlistChecker, exempt ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			for _, path := range exempt {
+				if c.Path() == path {
+					return next(c)
+				}
+			}
+
+			claims, ok := c.Get("claims").(*domain.JWTCustomClaims)
+			if !ok {
+				return next(c)
+			}
+
+			ip := c.RealIP()
+			if err := check(c.Request().Context(), claims.UserID, ip); err != nil {
+				if errors.Is(err, domain.ErrIPNotAllowed) {
+					return c.JSON(http.StatusForbidden, echo.Map{
+						"message": domain.ErrIPNotAllowed.Error(),
+						"ip":      ip,
+					})
+				}
+				return echo.NewHTTPError(http.StatusInternalServerError, "Internal Server Error")
+			}
+
+			return next(c)
+		}
+	}
+}
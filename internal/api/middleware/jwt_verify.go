@@ -3,22 +3,30 @@ package middleware
 import (
 	"context"
 	"errors"
-	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/labstack/echo/v4"
-	"github.com/meowmix1337/go-core/cache"
+	"github.com/meowmix1337/the_recipe_book/internal/blacklist"
 	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
 )
 
-func VerifyJWT(ctx context.Context, cache cache.Cache, tokenString string, secretKey string) (*domain.JWTCustomClaims, error) {
+// JWTKeyResolver returns the key a token is verified with by its header.
+type JWTKeyResolver func(ctx context.Context, token *jwt.Token) (interface{}, error)
+
+func VerifyJWT(ctx context.Context, blacklist blacklist.Blacklist, tokenString string, keys JWTKeyResolver) (*domain.JWTCustomClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &domain.JWTCustomClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(secretKey), nil
+		return keys(ctx, token)
 	})
 	if err != nil {
+		// a key that couldn't be looked up is no fault of the token
+		var validationErr *jwt.ValidationError
+		if errors.As(err, &validationErr) && validationErr.Errors&jwt.ValidationErrorUnverifiable != 0 &&
+			validationErr.Inner != nil && domain.KindOf(validationErr.Inner) == domain.KindInternal {
+			return nil, validationErr.Inner
+		}
 		return nil, err
 	}
 
@@ -33,33 +41,50 @@ func VerifyJWT(ctx context.Context, cache cache.Cache, tokenString string, secre
 	}
 
 	// check if the token is blacklisted
-	if isBlacklisted(ctx, cache, claims.UserID, token.Raw) {
+	blacklisted, err := blacklist.Contains(ctx, claims.UserID, token.Raw)
+	if err != nil {
+		return nil, err
+	}
+	if blacklisted {
+		return nil, echo.ErrUnauthorized
+	}
+
+	// every token of the user issued before a revocation is rejected, e.g. after a password change
+	revokedBefore, err := blacklist.RevokedBefore(ctx, claims.UUID)
+	if err != nil {
+		return nil, err
+	}
+	if !revokedBefore.IsZero() && (claims.IssuedAt == nil || claims.IssuedAt.Before(revokedBefore)) {
 		return nil, echo.ErrUnauthorized
 	}
 
 	return claims, nil
 }
 
-func isBlacklisted(ctx context.Context, cache cache.Cache, userID uint, token string) bool {
-	key := fmt.Sprintf("%v_%v", userID, token)
-	_, err := cache.Get(ctx, key)
-	return err == nil
-}
+// RoomTicketAuthenticator redeems a ticket for the room of a list and returns the claims of the
+// session it was issued for.
+type RoomTicketAuthenticator func(ctx context.Context, ticket string, listUUID string) (*domain.JWTCustomClaims, error)
 
-// JWTMiddleware verifies the JWT token on each request.
-func JWTMiddleware(secretKey string, cache cache.Cache) echo.MiddlewareFunc {
+// JWTMiddleware verifies the JWT token on each request. Browsers can't set headers on WebSocket
+// handshakes, so the WebSocket of a list room at roomPath may be opened with a single-use ticket
+// in the ticket query parameter instead, access tokens are never read from the URL.
+func JWTMiddleware(keys JWTKeyResolver, blacklist blacklist.Blacklist, roomTickets RoomTicketAuthenticator, roomPath string) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			tokenString := c.Request().Header.Get("Authorization")
+			tokenString := strings.TrimPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
+			if tokenString == "" && c.Path() == roomPath {
+				if ticket := c.QueryParam("ticket"); ticket != "" {
+					return verifyRoomTicket(c, next, blacklist, roomTickets, ticket)
+				}
+			}
 			if tokenString == "" {
 				return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
 			}
 
-			tokenString = strings.TrimPrefix(tokenString, "Bearer ")
-
-			claims, err := VerifyJWT(c.Request().Context(), cache, tokenString, secretKey)
+			claims, err := VerifyJWT(c.Request().Context(), blacklist, tokenString, keys)
 			if err != nil {
-				if errors.Is(err, echo.ErrUnauthorized) {
+				var validationErr *jwt.ValidationError
+				if errors.Is(err, echo.ErrUnauthorized) || errors.As(err, &validationErr) {
 					return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
 				}
 				return echo.NewHTTPError(http.StatusInternalServerError, "Internal Server Error")
@@ -71,3 +96,30 @@ func JWTMiddleware(secretKey string, cache cache.Cache) echo.MiddlewareFunc {
 		}
 	}
 }
+
+// verifyRoomTicket authenticates a room handshake with its ticket, a ticket issued before the
+// tokens of the user were revoked is rejected like the token it was issued with.
+func verifyRoomTicket(c echo.Context, next echo.HandlerFunc, blacklist blacklist.Blacklist, roomTickets RoomTicketAuthenticator, ticket string) error {
+	ctx := c.Request().Context()
+	claims, err := roomTickets(ctx, ticket, c.Param("uuid"))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidRoomTicket):
+			return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+		case errors.Is(err, domain.ErrUnknownRegion):
+			return echo.NewHTTPError(http.StatusMisdirectedRequest, domain.ErrUnknownRegion.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, "Internal Server Error")
+	}
+
+	revokedBefore, err := blacklist.RevokedBefore(ctx, claims.UUID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Internal Server Error")
+	}
+	if !revokedBefore.IsZero() && claims.IssuedAt.Before(revokedBefore) {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+	}
+
+	c.Set("claims", claims)
+	return next(c)
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/meowmix1337/the_recipe_book/internal/metrics"
+)
+
+// MetricsMiddleware records the duration and status of every request in RequestDuration.
+// Requests that matched no route share one label, paths of unknown routes are unbounded.
+func MetricsMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+		err := next(c)
+
+		// the error handler only writes the response after the middleware returned
+		status := c.Response().Status
+		if err != nil {
+			status = ErrorStatus(err)
+		}
+
+		route := c.Path()
+		if route == "" || (status == http.StatusNotFound && strings.HasSuffix(route, "/*")) {
+			route = "unmatched"
+		}
+
+		metrics.RequestDuration.WithLabelValues(c.Request().Method, route, strconv.Itoa(status)).
+			Observe(time.Since(start).Seconds())
+
+		return err
+	}
+}
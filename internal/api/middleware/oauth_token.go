@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
+)
+
+// OAuthTokenAuthenticator resolves a raw OAuth access token.
+type OAuthTokenAuthenticator func(ctx context.Context, token string) (*domain.OAuthGrant, error)
+
+// OAuthTokenMiddleware authenticates requests of linked third party clients with the bearer
+// access token issued by the OAuth token endpoint. The queries of the request go to the data
+// region the token was issued in.
+func OAuthTokenMiddleware(authenticate OAuthTokenAuthenticator) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token := strings.TrimPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
+			if token == "" {
+				return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+			}
+
+			grant, err := authenticate(c.Request().Context(), token)
+			if err != nil {
+				switch {
+				case errors.Is(err, domain.ErrInvalidOAuthToken):
+					return echo.NewHTTPError(http.StatusUnauthorized, "Unauthorized")
+				case errors.Is(err, domain.ErrUnknownRegion):
+					return echo.NewHTTPError(http.StatusMisdirectedRequest, domain.ErrUnknownRegion.Error())
+				}
+				return echo.NewHTTPError(http.StatusInternalServerError, "Internal Server Error")
+			}
+
+			c.SetRequest(c.Request().WithContext(region.WithRegion(c.Request().Context(), grant.Region)))
+			c.Set("oauth_grant", grant)
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/controller/validation"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+//nolint:gochecknoglobals // lookup table
+var kindStatus = map[domain.ErrorKind]int{
+	domain.KindValidation:   http.StatusBadRequest,
+	domain.KindUnauthorized: http.StatusUnauthorized,
+	domain.KindForbidden:    http.StatusForbidden,
+	domain.KindNotFound:     http.StatusNotFound,
+	domain.KindConflict:     http.StatusConflict,
+	domain.KindTooLarge:     http.StatusRequestEntityTooLarge,
+	domain.KindRateLimited:  http.StatusTooManyRequests,
+}
+
+// ErrorStatus returns the status ErrorHandler answers err with. Handlers return echo errors
+// for statuses of their own, validation errors fail with 422 and the errors of the service
+// layer by their kind.
+func ErrorStatus(err error) int {
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Code
+	}
+	if validation.FieldErrors(err) != nil {
+		return http.StatusUnprocessableEntity
+	}
+	if status, ok := kindStatus[domain.KindOf(err)]; ok {
+		return status
+	}
+
+	return http.StatusInternalServerError
+}
+
+// ErrorHandler replaces the error handler of echo, it answers the errors of handlers and
+// middleware with problem details. Internal errors are logged and masked.
+func ErrorHandler(err error, c echo.Context) {
+	// the request logger handles errors first so it can log the status
+	if c.Response().Committed {
+		return
+	}
+
+	status := ErrorStatus(err)
+	problem := &endpoint.Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Instance: c.Request().URL.Path,
+	}
+
+	var httpErr *echo.HTTPError
+	switch fieldErrors := validation.FieldErrors(err); {
+	case errors.As(err, &httpErr):
+		if message, ok := httpErr.Message.(string); ok && message != problem.Title {
+			problem.Detail = message
+		}
+		if httpErr.Internal != nil {
+			log.Err(httpErr.Internal).Int("status", status).Msg("error handling request")
+		}
+	case fieldErrors != nil:
+		problem.Detail = "Validation errors"
+		problem.Errors = fieldErrors
+	case status >= http.StatusInternalServerError:
+		log.Err(err).Str("path", problem.Instance).Msg("error handling request")
+	default:
+		problem.Detail = err.Error()
+	}
+
+	problem.Message = problem.Detail
+	if problem.Message == "" {
+		problem.Message = problem.Title
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, endpoint.ProblemContentType)
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(status)
+	} else {
+		err = c.JSON(status, problem)
+	}
+	if err != nil {
+		log.Err(err).Msg("error writing error response")
+	}
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/ratelimit"
+	"github.com/rs/zerolog/log"
+)
+
+// RateLimitKey returns the bucket of a request, requests without a bucket are not limited.
+type RateLimitKey func(c echo.Context) (string, bool)
+
+// RateLimitByIP limits requests per client IP, for routes without a session.
+func RateLimitByIP(c echo.Context) (string, bool) {
+	return "ip_" + c.RealIP(), true
+}
+
+// RateLimitByUser limits requests per user, user IDs are only unique within a data region. This
+// must be set after JWTMiddleware.
+func RateLimitByUser(c echo.Context) (string, bool) {
+	claims, ok := c.Get("claims").(*domain.JWTCustomClaims)
+	if !ok {
+		return "", false
+	}
+
+	return fmt.Sprintf("user_%v_%v", claims.Region, claims.UserID), true
+}
+
+// RateLimitByAgentToken limits requests per agent token, so a runaway assistant can't use up
+// the limit of the apps of its user. This must be set after AgentTokenMiddleware.
+func RateLimitByAgentToken(c echo.Context) (string, bool) {
+	token, ok := c.Get("agent_token").(*domain.AgentToken)
+	if !ok {
+		return "", false
+	}
+
+	return "agent_" + token.UUID, true
+}
+
+// RateLimitScoped gives the buckets of key a scope, so a route can have a limit of its own on
+// top of the limit every request counts against.
+func RateLimitScoped(scope string, key RateLimitKey) RateLimitKey {
+	return func(c echo.Context) (string, bool) {
+		bucket, ok := key(c)
+		if !ok {
+			return "", false
+		}
+
+		return scope + "_" + bucket, true
+	}
+}
+
+// RateLimitMiddleware answers requests over the limit with 429 Too Many Requests and a
+// Retry-After header, every response carries the X-RateLimit-* headers of its bucket. Requests
+// are let through when the store fails, an outage of the store must not take the API down.
+func RateLimitMiddleware(store ratelimit.Store, limit ratelimit.Limit, key RateLimitKey) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if !limit.Enabled() {
+			return next
+		}
+
+		return func(c echo.Context) error {
+			bucket, ok := key(c)
+			if !ok {
+				return next(c)
+			}
+
+			result, err := store.Take(c.Request().Context(), bucket, limit)
+			if err != nil {
+				log.Err(err).Str("bucket", bucket).Msg("error taking rate limit token")
+				return next(c)
+			}
+
+			header := c.Response().Header()
+			header.Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+			header.Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			header.Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+
+			if !result.Allowed {
+				header.Set("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+				return echo.NewHTTPError(http.StatusTooManyRequests, "Too Many Requests")
+			}
+
+			return next(c)
+		}
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
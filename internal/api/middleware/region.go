@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
+)
+
+// RegionMiddleware routes the queries of a request to the database of the data region in the
+// access token. Tokens of a region this instance doesn't serve are answered with 421 Misdirected
+// Request instead of falling back to the home database, clients retry against an instance of
+// the region. This must be set after JWTMiddleware.
+func RegionMiddleware(serves func(region string) bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims, ok := c.Get("claims").(*domain.JWTCustomClaims)
+			if !ok || claims.Region == region.Home {
+				return next(c)
+			}
+
+			if !serves(claims.Region) {
+				return echo.NewHTTPError(http.StatusMisdirectedRequest, domain.ErrUnknownRegion.Error())
+			}
+
+			c.SetRequest(c.Request().WithContext(region.WithRegion(c.Request().Context(), claims.Region)))
+			return next(c)
+		}
+	}
+}
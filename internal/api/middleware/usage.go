@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+
+	"github.com/labstack/echo/v4"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/rs/zerolog/log"
+)
+
+// UsageConsumer counts a use of a metered resource by the household of the user, it returns
+// ErrUsageLimitExceeded once the monthly cap is used up.
+type UsageConsumer func(ctx context.Context, userID uint, resource domain.QuotaResource) error
+
+// UsageMiddleware counts every API call against the monthly cap of the workspace of the user and
+// answers calls over the cap with 429 Too Many Requests. The routes in exempt are neither
+// counted nor refused, so a household over its cap can still see its usage. Calls are let
+// through when counting fails. This must be set after RegionMiddleware.
+func UsageMiddleware(consume UsageConsumer, exempt ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			for _, path := range exempt {
+				if c.Path() == path {
+					return next(c)
+				}
+			}
+
+			claims, ok := c.Get("claims").(*domain.JWTCustomClaims)
+			if !ok {
+				return next(c)
+			}
+
+			if err := consume(c.Request().Context(), claims.UserID, domain.QuotaAPICalls); err != nil {
+				if errors.Is(err, domain.ErrUsageLimitExceeded) {
+					return err
+				}
+				log.Err(err).Msg("error counting API call")
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -3,9 +3,10 @@ package api
 import (
 	"time"
 
+	apimiddleware "github.com/meowmix1337/the_recipe_book/internal/api/middleware"
+	"github.com/meowmix1337/the_recipe_book/internal/controller"
 	"github.com/meowmix1337/the_recipe_book/internal/controller/validation"
 
-	"github.com/go-playground/validator"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/rs/zerolog/log"
@@ -13,15 +14,26 @@ import (
 
 const (
 	timeout = 30 * time.Second
+	// apiPrefix is the group the authenticated routes are registered on.
+	apiPrefix = "/api"
 )
 
+// streamRoutes are the long-lived routes the timeout doesn't apply to.
+var streamRoutes = map[string]bool{
+	apiPrefix + controller.EventsRoute: true,
+	apiPrefix + controller.RoomRoute:   true,
+}
+
 func newRouter() *echo.Echo {
 	e := echo.New()
+	e.HTTPErrorHandler = apimiddleware.ErrorHandler
 
 	// Middleware
 	e.Use(middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
 		LogURI:    true,
 		LogStatus: true,
+		// errors are answered before logging, the status depends on the error
+		HandleError: true,
 		LogValuesFunc: func(c echo.Context, v middleware.RequestLoggerValues) error {
 			log.Info().
 				Str("uri", v.URI).
@@ -39,12 +51,19 @@ func newRouter() *echo.Echo {
 	e.Use(middleware.Recover())
 	e.Use(middleware.RequestID())
 	e.Use(middleware.TimeoutWithConfig(middleware.TimeoutConfig{
-		Skipper:      middleware.DefaultSkipper,
+		Skipper:      isStream,
 		ErrorMessage: "custom timeout error message returns to client",
 		Timeout:      timeout,
 	}))
 
-	e.Validator = &validation.CustomValidator{Validator: validator.New()}
+	e.Validator = validation.New()
 
 	return e
 }
+
+// isStream skips the timeout for long-lived streams and WebSockets, the timeout handler buffers
+// the response and would cut streams off. It goes by the matched route, request headers would let
+// any request opt out of the timeout.
+func isStream(c echo.Context) bool {
+	return streamRoutes[c.Path()]
+}
@@ -4,112 +4,946 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"net"
 	"net/http"
-	"os"
-	"os/signal"
-	"syscall"
+	"net/url"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/meowmix1337/go-core/cache"
 	"github.com/meowmix1337/go-core/db"
 	"github.com/meowmix1337/the_recipe_book/internal/api/middleware"
+	"github.com/meowmix1337/the_recipe_book/internal/blacklist"
 	"github.com/meowmix1337/the_recipe_book/internal/config"
 	"github.com/meowmix1337/the_recipe_book/internal/controller"
+	"github.com/meowmix1337/the_recipe_book/internal/extract"
+	"github.com/meowmix1337/the_recipe_book/internal/health"
+	"github.com/meowmix1337/the_recipe_book/internal/idempotency"
+	"github.com/meowmix1337/the_recipe_book/internal/linkcheck"
+	"github.com/meowmix1337/the_recipe_book/internal/llm"
+	"github.com/meowmix1337/the_recipe_book/internal/mail"
+	"github.com/meowmix1337/the_recipe_book/internal/metrics"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/plugin"
+	"github.com/meowmix1337/the_recipe_book/internal/pubsub"
+	"github.com/meowmix1337/the_recipe_book/internal/ratelimit"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
 	"github.com/meowmix1337/the_recipe_book/internal/repo"
+	"github.com/meowmix1337/the_recipe_book/internal/rpc"
 	"github.com/meowmix1337/the_recipe_book/internal/service"
+	"github.com/meowmix1337/the_recipe_book/internal/slack"
+	"github.com/meowmix1337/the_recipe_book/internal/storage"
+	"github.com/meowmix1337/the_recipe_book/internal/suggestion"
+	"github.com/meowmix1337/the_recipe_book/internal/tracing"
+	"github.com/meowmix1337/the_recipe_book/internal/transfer"
+	"github.com/meowmix1337/the_recipe_book/internal/webhook"
+	"github.com/meowmix1337/the_recipe_book/internal/webpush"
+	"github.com/meowmix1337/the_recipe_book/internal/worker"
+	"github.com/meowmix1337/the_recipe_book/migrations"
 
-	"github.com/golang-migrate/migrate/v4"
-	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
 
 	"github.com/rs/zerolog/log"
 )
 
-const shutdownTime = time.Second * 5
+const (
+	snapshotWorkerInterval   = time.Minute
+	webhookWorkerInterval    = 10 * time.Second
+	webhookTimeout           = 10 * time.Second
+	blacklistPruneInterval   = time.Hour
+	rateLimitPruneInterval   = time.Minute
+	rateLimitPeriod          = time.Minute
+	breakdownRateLimitPeriod = time.Hour
+	idempotencyPruneInterval = time.Hour
+	instanceHeartbeat        = 15 * time.Second
+	extractionInterval       = time.Minute
+	linkCheckInterval        = time.Minute
+	linkCheckTimeout         = 10 * time.Second
+	importInterval           = time.Minute
+	transferTimeout          = 30 * time.Second
+	emailWorkerInterval      = 10 * time.Second
+	archivalInterval         = 15 * time.Minute
+	reminderInterval         = time.Minute
+	pushTimeout              = 10 * time.Second
+	slackWorkerInterval      = 10 * time.Second
+	slackTimeout             = 10 * time.Second
+	rollupInterval           = 15 * time.Minute
+	insightInterval          = time.Hour
+	purgeInterval            = time.Hour
+	takeoutInterval          = time.Minute
+	automationWorkerInterval = 10 * time.Second
+	loginPruneInterval       = time.Hour
+	embeddingWorkerInterval  = 10 * time.Second
+	digestInterval           = 5 * time.Minute
+	escalationInterval       = 5 * time.Minute
+	// signingKeyRotationInterval is how often the age of the signing key is checked,
+	// JWT_KEY_ROTATION_DAYS decides when it is rotated.
+	signingKeyRotationInterval = time.Hour
+)
 
 type Server struct {
 	config.Config
+
+	// ShadowTodoRepo builds the candidate todo repo that receives shadow writes while
+	// SHADOW_WRITES is enabled, e.g. a new table layout or a cache backed implementation. db
+	// writes to the tables of the shadow schema, the built-in todo repo is the default.
+	ShadowTodoRepo func(db db.DB) repo.TodoRepo
+
+	// Mailer, WebhookSender, Storage, LanguageModel and Embedder replace the implementations the
+	// config selects when set, applications embedding the server deliver and store through their
+	// own this way.
+	Mailer        mail.Sender
+	WebhookSender webhook.Sender
+	Storage       storage.Storage
+	LanguageModel llm.Model
+	Embedder      llm.Embedder
+
+	// UserRepo, ListRepo and TodoRepo replace the built-in repos when set, they get the built-in
+	// repo to delegate to.
+	UserRepo func(db db.DB, builtin repo.UserRepo) repo.UserRepo
+	ListRepo func(db db.DB, builtin repo.ListRepo) repo.ListRepo
+	TodoRepo func(db db.DB, builtin repo.TodoRepo) repo.TodoRepo
 }
 
 func NewServer(cfg config.Config) *Server {
 	return &Server{
 		Config: cfg,
+		ShadowTodoRepo: func(db db.DB) repo.TodoRepo {
+			return repo.NewTodoRepo(db)
+		},
 	}
 }
 
-func (s *Server) Start() {
+// Start serves the API and runs the background workers until ctx is done, then it drains the
+// requests in progress and stops. It returns early when the server can't be set up or a
+// listener fails.
+func (s *Server) Start(ctx context.Context) error {
 	echoRouter := newRouter()
+	ipExtractor, err := s.ipExtractor()
+	if err != nil {
+		return fmt.Errorf("failed to configure trusted proxies: %w", err)
+	}
+	echoRouter.IPExtractor = ipExtractor
+
+	shutdownTracing, err := tracing.Init(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to initilize tracing: %w", err)
+	}
+
+	// a failing listener stops the server like the end of ctx does
+	ctx, stop := context.WithCancelCause(ctx)
+	defer stop(nil)
+
+	homeDB, err := s.initializeDB()
+	if err != nil {
+		return fmt.Errorf("failed to initilize DB: %w", err)
+	}
+
+	regionDBs, err := s.initializeRegions()
+	if err != nil {
+		return fmt.Errorf("failed to initilize region DBs: %w", err)
+	}
+	// every repo but the region directory follows the data region of the request
+	db := region.NewRouter(homeDB, regionDBs)
+
+	cache, err := s.initializeRedis()
+	if err != nil {
+		return fmt.Errorf("failed to initilize Redis: %w", err)
+	}
+
+	store, err := s.initializeStorage()
+	if err != nil {
+		return fmt.Errorf("failed to initilize storage: %w", err)
+	}
+
+	extractor, err := s.initializeExtractor()
+	if err != nil {
+		return fmt.Errorf("failed to initilize attachment extraction: %w", err)
+	}
+
+	tokenBlacklist, err := s.initializeBlacklist(cache, homeDB)
+	if err != nil {
+		return fmt.Errorf("failed to initilize token blacklist: %w", err)
+	}
+
+	rateLimitStore, err := s.initializeRateLimitStore()
+	if err != nil {
+		return fmt.Errorf("failed to initilize rate limit store: %w", err)
+	}
+
+	idempotencyStore, err := s.initializeIdempotencyStore(homeDB)
+	if err != nil {
+		return fmt.Errorf("failed to initilize idempotency store: %w", err)
+	}
+
+	mailer, err := s.initializeMailer()
+	if err != nil {
+		return fmt.Errorf("failed to initilize mail provider: %w", err)
+	}
+
+	pushKeys, pushSender, err := s.initializePush()
+	if err != nil {
+		return fmt.Errorf("failed to initilize web push: %w", err)
+	}
+
+	languageModel, err := s.initializeLanguageModel()
+	if err != nil {
+		return fmt.Errorf("failed to initilize language model: %w", err)
+	}
+
+	embedder, err := s.initializeEmbedder()
+	if err != nil {
+		return fmt.Errorf("failed to initilize embedding model: %w", err)
+	}
+
+	// Initialize repositories
+	userRepo := s.userRepo(db)
+	refreshTokenRepo := repo.NewRefreshTokenRepo(db)
+	userRegionRepo := repo.NewUserRegionRepo(homeDB)
+	todoRepo, candidateTodoRepo, err := s.todoRepo(db)
+	if err != nil {
+		return fmt.Errorf("failed to initilize todo repo: %w", err)
+	}
+	tagRepo := repo.NewTagRepo(db)
+	searchRepo := repo.NewSearchRepo(db)
+	embeddingRepo := repo.NewEmbeddingRepo(db)
+	listRepo := s.listRepo(db)
+	snapshotScheduleRepo := repo.NewSnapshotScheduleRepo(db)
+	displayTokenRepo := repo.NewDisplayTokenRepo(db)
+	calendarTokenRepo := repo.NewCalendarTokenRepo(db)
+	feedTokenRepo := repo.NewFeedTokenRepo(db)
+	importJobRepo := repo.NewImportJobRepo(db)
+	takeoutRepo := repo.NewTakeoutRepo(db)
+	emailChangeRepo := repo.NewEmailChangeRepo(db)
+	listMemberRepo := repo.NewListMemberRepo(db)
+	listInvitationRepo := repo.NewListInvitationRepo(db)
+	commentRepo := repo.NewCommentRepo(db)
+	oauthRepo := repo.NewOAuthRepo(db)
+	attachmentRepo := repo.NewAttachmentRepo(db)
+	secureNoteRepo := repo.NewSecureNoteRepo(db)
+	recoveryRepo := repo.NewRecoveryRepo(db)
+	onboardingRepo := repo.NewOnboardingRepo(db)
+	todoDependencyRepo := repo.NewTodoDependencyRepo(db)
+	auditRepo := repo.NewAuditRepo(db)
+	focusSessionRepo := repo.NewFocusSessionRepo(db)
+	planRepo := repo.NewPlanRepo(db)
+	webhookRepo := repo.NewWebhookRepo(db)
+	habitRepo := repo.NewHabitRepo(db)
+	ipAllowlistRepo := repo.NewIPAllowlistRepo(homeDB)
+	lockoutRepo := repo.NewLockoutRepo(db)
+	adminRepo := repo.NewAdminRepo(db)
+	workspaceRepo := repo.NewWorkspaceRepo(homeDB)
+	txManager := repo.NewTxManager(db)
+	instanceRepo := repo.NewInstanceRepo(homeDB)
+	signingKeyRepo := repo.NewSigningKeyRepo(homeDB)
+	linkRepo := repo.NewLinkRepo(db)
+	emailRepo := repo.NewEmailRepo(db)
+	pushSubscriptionRepo := repo.NewPushSubscriptionRepo(db)
+	slackRepo := repo.NewSlackRepo(db)
+	rollupRepo := repo.NewRollupRepo(db)
+	insightRepo := repo.NewInsightRepo(db)
+	ruleRepo := repo.NewRuleRepo(db)
+	automationRepo := repo.NewAutomationRepo(db)
+	agentTokenRepo := repo.NewAgentTokenRepo(db)
+	loginRepo := repo.NewLoginRepo(db)
+	digestRepo := repo.NewDigestRepo(db)
+	escalationRepo := repo.NewEscalationRepo(db)
+	templateRepo := repo.NewTemplateRepo(db)
+	encryptionRepo := repo.NewEncryptionRepo(db)
+	listStatusRepo := repo.NewListStatusRepo(db)
+	roomTicketRepo := repo.NewRoomTicketRepo(db)
+
+	// Initialize services
+	baseService := service.NewBaseService(s.Config, cache)
+	securityEvents := service.NewSecurityEvents()
+	signingKeyService := service.NewSigningKeyService(baseService, signingKeyRepo)
+	authService := service.NewAuthService(baseService, signingKeyService, refreshTokenRepo, tokenBlacklist)
+	userService := service.NewUserService(
+		baseService, authService, txManager, userRepo, userRegionRepo, lockoutRepo, oauthRepo, mailer, securityEvents,
+	)
+	recipeService := service.NewRecipeService(baseService)
+	workspaceService := service.NewWorkspaceService(baseService, userRepo, listRepo, workspaceRepo, mailer)
+	householdService := service.NewHouseholdService(baseService, userRepo, userRegionRepo, workspaceService, securityEvents)
+	tagService := service.NewTagService(baseService, tagRepo, todoRepo)
+	listService := service.NewListService(baseService, householdService, workspaceService, listRepo, listMemberRepo, todoRepo)
+	ruleService := service.NewRuleService(baseService, listService, ruleRepo)
+	todoService := service.NewTodoService(
+		baseService, tagService, listService, householdService, ruleService, todoRepo, encryptionRepo, listStatusRepo,
+		todoDependencyRepo, txManager,
+	)
+	// the canary cohort reads todos from the candidate repo while shadow writes fill it
+	var candidateTodoService service.TodoService
+	if candidateTodoRepo != nil {
+		candidateTodoService = service.NewTodoService(
+			baseService, tagService, listService, householdService, ruleService, candidateTodoRepo, encryptionRepo,
+			listStatusRepo, todoDependencyRepo, txManager,
+		)
+	}
+	todoTransferService := service.NewTodoTransferService(baseService, todoService, listService, attachmentRepo, store)
+	searchService := service.NewSearchService(baseService, searchRepo, embeddingRepo, embedder)
+	snapshotService := service.NewSnapshotService(
+		baseService, listService, householdService, workspaceService, listRepo, todoRepo, snapshotScheduleRepo, mailer,
+	)
+	displayService := service.NewDisplayService(baseService, listService, householdService, displayTokenRepo, listRepo, todoRepo)
+	calendarService := service.NewCalendarService(baseService, householdService, calendarTokenRepo, todoRepo)
+	feedService := service.NewFeedService(baseService, listService, householdService, feedTokenRepo, todoRepo)
+	shareService := service.NewShareService(
+		baseService, listService, householdService, userRepo, listRepo, listMemberRepo, listInvitationRepo, mailer,
+	)
+	commentService := service.NewCommentService(baseService, todoService, commentRepo)
+	oauthService := service.NewOAuthService(baseService, oauthRepo)
+	voiceService := service.NewVoiceService(baseService, todoService, workspaceService, userRepo)
+	agentService := service.NewAgentService(
+		baseService, householdService, workspaceService, todoService, searchService, agentTokenRepo, idempotencyStore,
+	)
+	attachmentService := service.NewAttachmentService(
+		baseService, todoService, householdService, attachmentRepo, store, extractor,
+	)
+	accountDeletionService := service.NewAccountDeletionService(
+		baseService, userRepo, userRegionRepo, attachmentRepo, auditRepo, takeoutRepo, store,
+	)
+	importService := service.NewImportService(
+		baseService, listService, todoService, attachmentService, importJobRepo, transfer.NewHTTPClient(transferTimeout),
+	)
+	secureNoteService := service.NewSecureNoteService(baseService, todoService, secureNoteRepo)
+	todoDependencyService := service.NewTodoDependencyService(baseService, todoService, todoDependencyRepo)
+	auditService := service.NewAuditService(baseService, auditRepo, userRepo)
+	focusService := service.NewFocusService(baseService, todoService, focusSessionRepo)
+	planService := service.NewPlanService(baseService, todoService, workspaceService, planRepo, userRepo)
+	webhookSender := s.webhookSender()
+	webhookService := service.NewWebhookService(baseService, householdService, workspaceService, webhookRepo, webhookSender)
+	todoService.Subscribe(webhookService)
+	securityEvents.Subscribe(webhookService)
+	eventService := service.NewEventService(baseService, listService, roomTicketRepo, pubsub.NewMemoryPubSub())
+	todoService.Subscribe(eventService)
+	linkService := service.NewLinkService(baseService, linkRepo, userRepo, linkcheck.NewHTTPChecker(linkCheckTimeout), mailer)
+	todoService.Subscribe(linkService)
+	todoService.Subscribe(searchService)
+	suggestionService := service.NewSuggestionService(baseService, todoService, listMemberRepo, s.suggestionAnalyzer())
+	breakdownService := service.NewBreakdownService(baseService, todoService, languageModel)
+	habitService := service.NewHabitService(baseService, habitRepo)
+	ipAllowlistService := service.NewIPAllowlistService(baseService, userRepo, ipAllowlistRepo, workspaceRepo, mailer, securityEvents)
+	adminService := service.NewAdminService(baseService, userRepo, adminRepo, securityEvents)
+	provisioningService := service.NewProvisioningService(baseService, userService, adminService, userRegionRepo, workspaceRepo)
+	instanceService := service.NewInstanceService(baseService, instanceRepo)
+	notificationService := service.NewNotificationService(baseService, userRepo, emailRepo, mailer)
+	recoveryService := service.NewRecoveryService(
+		baseService, authService, notificationService, securityEvents, txManager, userRepo, userRegionRepo, recoveryRepo,
+		oauthRepo,
+	)
+	emailChangeService := service.NewEmailChangeService(
+		baseService, notificationService, txManager, userRepo, userRegionRepo, emailChangeRepo,
+	)
+	takeoutService := service.NewTakeoutService(
+		baseService, listService, todoService, notificationService, userRepo, commentRepo, attachmentRepo, takeoutRepo, store,
+	)
+	pushService := service.NewPushService(baseService, pushSubscriptionRepo, pushKeys, pushSender)
+	reminderService := service.NewReminderService(baseService, todoRepo, userRepo)
+	reminderService.Subscribe(pushService)
+	reminderService.Subscribe(notificationService)
+	slackService := service.NewSlackService(
+		baseService, householdService, workspaceService, todoService, slackRepo, listMemberRepo, slack.NewHTTPSender(slackTimeout),
+	)
+	todoService.Subscribe(slackService)
+	reminderService.Subscribe(slackService)
+	chartService := service.NewChartService(baseService, listService, householdService, rollupRepo)
+	insightService := service.NewInsightService(baseService, notificationService, insightRepo, userRepo)
+	digestService := service.NewDigestService(baseService, notificationService, digestRepo, userRepo)
+	escalationService := service.NewEscalationService(
+		baseService, listService, todoService, notificationService, escalationRepo, userRepo,
+	)
+	templateService := service.NewTemplateService(baseService, todoService, templateRepo, userRepo)
+	quickAddService := service.NewQuickAddService(baseService, todoService, userRepo)
+	boardService := service.NewBoardService(baseService, listService, listStatusRepo, todoRepo, txManager)
+	encryptionService := service.NewEncryptionService(baseService, listService, encryptionRepo)
+	automationService := service.NewAutomationService(
+		baseService, householdService, todoService, notificationService, automationRepo, userRepo,
+	)
+	todoService.Subscribe(automationService)
+	loginService := service.NewLoginService(baseService, notificationService, auditService, securityEvents, loginRepo)
+	securityEvents.Subscribe(loginService)
+	onboardingService := service.NewOnboardingService(baseService, listService, todoService, onboardingRepo)
+	securityEvents.Subscribe(onboardingService)
+	// the plugins run after the hooks of the app
+	plugins := plugin.Load(s.Config.GetPluginSettings, s.Config.GetPluginsDisabled())
+	todoService.Subscribe(plugins)
+	userService.Subscribe(plugins)
+
+	auth := s.authMiddleware(signingKeyService.Resolve, tokenBlacklist, eventService.UseRoomTicket, db, ipAllowlistService.Check, rateLimitStore, workspaceService.Consume)
+	// the login, refresh and logout routes are not idempotent and the routes that return
+	// credentials are marked with NoIdempotentStore, so that tokens are never stored
+	idempotent := middleware.IdempotencyMiddleware(idempotencyStore)
+	api := echoRouter.Group(apiPrefix, append(auth, idempotent)...)
+
+	echoRouter.Use(middleware.TracingMiddleware)
+	echoRouter.Use(middleware.MetricsMiddleware)
+	// every state-changing request is recorded in the audit log
+	echoRouter.Use(middleware.AuditMiddleware(auditService.Record))
+
+	// Start background workers, the instances split the regions between them
+	if err = instanceService.Heartbeat(ctx); err != nil {
+		log.Err(err).Msg("failed to register instance, working on every region until the next heartbeat")
+	}
+	if err = signingKeyService.RotateDue(ctx); err != nil {
+		log.Err(err).Msg("failed to rotate signing key, retrying with the next rotation check")
+	}
+	// WORKER_INTERVALS changes the intervals below by the names of the workers
+	workers := worker.Group{Interval: s.GetWorkerInterval}
+	workers.Periodic(ctx, "instance_heartbeat", instanceHeartbeat, instanceService.Heartbeat)
+	workers.Periodic(ctx, "signing_key_rotation", signingKeyRotationInterval, signingKeyService.RotateDue)
+	workers.Periodic(ctx, "list_snapshots", snapshotWorkerInterval, db.Each(instanceService.Sharded(snapshotService.SendDue)))
+	workers.Periodic(ctx, "webhook_deliveries", webhookWorkerInterval, db.Each(instanceService.Sharded(webhookService.DeliverDue)))
+	workers.Periodic(ctx, "attachment_extraction", extractionInterval, db.Each(instanceService.Sharded(attachmentService.ExtractDue)))
+	workers.Periodic(ctx, "link_checks", linkCheckInterval, db.Each(instanceService.Sharded(linkService.CheckDue)))
+	workers.Periodic(ctx, "imports", importInterval, db.Each(instanceService.Sharded(importService.RunDue)))
+	workers.Periodic(ctx, "emails", emailWorkerInterval, db.Each(instanceService.Sharded(notificationService.SendDue)))
+	workers.Periodic(ctx, "todo_archival", archivalInterval, db.Each(instanceService.Sharded(listService.ArchiveDue)))
+	workers.Periodic(ctx, "reminders", reminderInterval, db.Each(instanceService.Sharded(reminderService.RemindDue)))
+	workers.Periodic(ctx, "slack_messages", slackWorkerInterval, db.Each(instanceService.Sharded(slackService.SendDue)))
+	workers.Periodic(ctx, "todo_rollups", rollupInterval, db.Each(instanceService.Sharded(chartService.RollupDue)))
+	workers.Periodic(ctx, "insights", insightInterval, db.Each(instanceService.Sharded(insightService.CheckDue)))
+	workers.Periodic(ctx, "weekly_digests", digestInterval, db.Each(instanceService.Sharded(digestService.SendDue)))
+	workers.Periodic(ctx, "todo_escalations", escalationInterval, db.Each(instanceService.Sharded(escalationService.EscalateDue)))
+	workers.Periodic(ctx, "automation_runs", automationWorkerInterval, db.Each(instanceService.Sharded(automationService.RunDue)))
+	workers.Periodic(ctx, "takeouts", takeoutInterval, db.Each(instanceService.Sharded(takeoutService.RunDue)))
+	workers.Periodic(ctx, "account_purge", purgeInterval, db.Each(instanceService.Sharded(accountDeletionService.PurgeDue)))
+	workers.Periodic(ctx, "login_history", loginPruneInterval, db.Each(instanceService.Sharded(loginService.PruneDue)))
+	workers.Periodic(ctx, "todo_embeddings", embeddingWorkerInterval, db.Each(instanceService.Sharded(searchService.EmbedDue)))
+	if pruner, ok := tokenBlacklist.(blacklist.Pruner); ok {
+		workers.Periodic(ctx, "token_blacklist", blacklistPruneInterval, pruner.Prune)
+	}
+	if pruner, ok := rateLimitStore.(ratelimit.Pruner); ok {
+		workers.Periodic(ctx, "rate_limit_buckets", rateLimitPruneInterval, pruner.Prune)
+	}
+	if pruner, ok := idempotencyStore.(idempotency.Pruner); ok {
+		workers.Periodic(ctx, "idempotency_keys", idempotencyPruneInterval, pruner.Prune)
+	}
+
+	// Initialize controllers
+	baseController := controller.NewBaseController(s.Config, cache)
+	userController := controller.NewUserController(baseController, userService, adminService, emailChangeService)
+	anonymousLimit := ratelimit.Limit{Burst: s.Config.GetRateLimitAnonymous(), Period: rateLimitPeriod}
+	anonymousRateLimit := middleware.RateLimitMiddleware(rateLimitStore, anonymousLimit, middleware.RateLimitByIP)
+	userController.AddUnprotectedRoutes(echoRouter, anonymousRateLimit, idempotent, auth...)
+	userController.AddRoutes(api)
 
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-	defer stop()
-	// Start server
+	recoveryController := controller.NewRecoveryController(baseController, recoveryService)
+	recoveryController.AddUnprotectedRoutes(echoRouter, anonymousRateLimit)
+	recoveryController.AddRoutes(api)
+
+	onboardingController := controller.NewOnboardingController(baseController, onboardingService)
+	onboardingController.AddRoutes(api)
+
+	recipeController := controller.NewRecipeController(baseController, recipeService)
+	recipeController.AddRoutes(api)
+
+	canaryController := controller.NewCanaryController(baseController, adminService)
+	canaryController.AddRoutes(api)
+
+	todoController := controller.NewTodoController(baseController, todoService, candidateTodoService)
+	todoController.AddRoutes(api)
+
+	templateController := controller.NewTemplateController(baseController, templateService)
+	templateController.AddRoutes(api)
+	quickAddController := controller.NewQuickAddController(baseController, quickAddService)
+	quickAddController.AddRoutes(api)
+
+	boardController := controller.NewBoardController(baseController, boardService)
+	boardController.AddRoutes(api)
+
+	todoTransferController := controller.NewTodoTransferController(baseController, todoTransferService)
+	todoTransferController.AddRoutes(api)
+
+	tagController := controller.NewTagController(baseController, tagService)
+	tagController.AddRoutes(api)
+
+	ruleController := controller.NewRuleController(baseController, ruleService)
+	ruleController.AddRoutes(api)
+
+	searchController := controller.NewSearchController(baseController, searchService)
+	searchController.AddRoutes(api)
+
+	listController := controller.NewListController(baseController, listService)
+	listController.AddRoutes(api)
+
+	snapshotController := controller.NewSnapshotController(baseController, snapshotService)
+	snapshotController.AddRoutes(api)
+
+	escalationController := controller.NewEscalationController(baseController, escalationService)
+	escalationController.AddRoutes(api)
+	encryptionController := controller.NewEncryptionController(baseController, encryptionService)
+	encryptionController.AddRoutes(api)
+
+	householdController := controller.NewHouseholdController(baseController, householdService)
+	householdController.AddRoutes(api)
+
+	displayController := controller.NewDisplayController(baseController, displayService)
+	displayController.AddRoutes(api)
+	displayController.AddDisplayRoutes(echoRouter)
+
+	calendarController := controller.NewCalendarController(baseController, calendarService)
+	calendarController.AddRoutes(api)
+	calendarController.AddCalendarRoutes(echoRouter)
+
+	feedController := controller.NewFeedController(baseController, feedService)
+	feedController.AddRoutes(api)
+	feedController.AddFeedRoutes(echoRouter)
+
+	accountController := controller.NewAccountController(baseController, accountDeletionService, takeoutService)
+	accountController.AddRoutes(api)
+
+	loginController := controller.NewLoginController(baseController, loginService)
+	loginController.AddRoutes(api)
+
+	jwksController := controller.NewJWKSController(baseController, signingKeyService)
+	jwksController.AddUnprotectedRoutes(echoRouter)
+
+	importController := controller.NewImportController(baseController, importService)
+	importController.AddRoutes(api)
+
+	shareController := controller.NewShareController(baseController, shareService)
+	shareController.AddRoutes(api)
+
+	commentController := controller.NewCommentController(baseController, commentService)
+	commentController.AddRoutes(api)
+
+	oauthController := controller.NewOAuthController(baseController, oauthService)
+	oauthController.AddRoutes(api)
+	oauthController.AddOAuthRoutes(echoRouter)
+
+	voiceController := controller.NewVoiceController(baseController, voiceService, oauthService)
+	voiceController.AddVoiceRoutes(echoRouter)
+
+	// assistants get the limit of a user per token
+	agentController := controller.NewAgentController(baseController, agentService)
+	agentController.AddRoutes(api)
+	agentController.AddAgentRoutes(echoRouter, middleware.RateLimitMiddleware(
+		rateLimitStore,
+		ratelimit.Limit{Burst: s.Config.GetRateLimitUser(), Period: rateLimitPeriod},
+		middleware.RateLimitByAgentToken,
+	))
+
+	attachmentController := controller.NewAttachmentController(baseController, attachmentService)
+	attachmentController.AddRoutes(api)
+
+	secureNoteController := controller.NewSecureNoteController(baseController, secureNoteService)
+	secureNoteController.AddRoutes(api)
+
+	todoDependencyController := controller.NewTodoDependencyController(baseController, todoDependencyService)
+	todoDependencyController.AddRoutes(api)
+
+	linkController := controller.NewLinkController(baseController, linkService)
+	linkController.AddRoutes(api)
+
+	auditController := controller.NewAuditController(baseController, auditService, adminService)
+	auditController.AddRoutes(api)
+
+	focusController := controller.NewFocusController(baseController, focusService)
+	focusController.AddRoutes(api)
+
+	planController := controller.NewPlanController(baseController, planService)
+	planController.AddRoutes(api)
+
+	webhookController := controller.NewWebhookController(baseController, webhookService)
+	webhookController.AddRoutes(api)
+
+	automationController := controller.NewAutomationController(baseController, automationService)
+	automationController.AddRoutes(api)
+
+	pushController := controller.NewPushController(baseController, pushService)
+	pushController.AddRoutes(api)
+
+	slackController := controller.NewSlackController(baseController, slackService)
+	slackController.AddRoutes(api)
+	slackController.AddSlackRoutes(echoRouter)
+
+	chartController := controller.NewChartController(baseController, chartService)
+	chartController.AddRoutes(api)
+
+	insightController := controller.NewInsightController(baseController, insightService)
+	insightController.AddRoutes(api)
+
+	digestController := controller.NewDigestController(baseController, digestService)
+	digestController.AddRoutes(api)
+
+	suggestionController := controller.NewSuggestionController(baseController, suggestionService)
+	suggestionController.AddRoutes(api)
+
+	// breakdowns have a limit of their own, every proposal is a request to the language model
+	breakdownController := controller.NewBreakdownController(baseController, breakdownService)
+	breakdownController.AddRoutes(api, middleware.RateLimitMiddleware(
+		rateLimitStore,
+		ratelimit.Limit{Burst: s.Config.GetBreakdownRateLimit(), Period: breakdownRateLimitPeriod},
+		middleware.RateLimitScoped("breakdown", middleware.RateLimitByUser),
+	))
+
+	closing := make(chan struct{})
+	echoRouter.Server.RegisterOnShutdown(func() { close(closing) })
+	eventController := controller.NewEventController(baseController, eventService, closing)
+	eventController.AddRoutes(api)
+
+	habitController := controller.NewHabitController(baseController, habitService)
+	habitController.AddRoutes(api)
+
+	ipAllowlistController := controller.NewIPAllowlistController(baseController, ipAllowlistService)
+	ipAllowlistController.AddRoutes(api)
+
+	adminController := controller.NewAdminController(baseController, adminService, instanceService)
+	adminController.AddRoutes(api)
+
+	workspaceController := controller.NewWorkspaceController(baseController, workspaceService)
+	workspaceController.AddRoutes(api)
+
+	plugins.AddRoutes(api)
+
+	provisioningController := controller.NewProvisioningController(baseController, provisioningService)
+	provisioningController.AddProvisioningRoutes(echoRouter)
+
+	metricsController := controller.NewMetricsController(baseController)
+	metricsController.AddMetricsRoutes(echoRouter)
+
+	docsController := controller.NewDocsController(baseController)
+	docsController.AddDocsRoutes(echoRouter)
+
+	liveness, readiness := s.healthCheckers(homeDB, regionDBs, cache)
+	healthController := controller.NewHealthController(baseController, liveness, readiness)
+	healthController.AddHealthRoutes(echoRouter)
+
+	// files of the local storage are served by the API itself
+	if files, ok := store.(storage.FileServer); ok {
+		fileController := controller.NewFileController(baseController, files)
+		fileController.AddFileRoutes(echoRouter)
+	}
+
+	// internal services call the same services over gRPC on a port of its own
+	grpcServer := rpc.NewServer(
+		&rpc.Auth{Keys: signingKeyService.Resolve, Blacklist: tokenBlacklist, Serves: db.Serves},
+		&rpc.Limits{
+			Store:       rateLimitStore,
+			Anonymous:   anonymousLimit,
+			User:        ratelimit.Limit{Burst: s.Config.GetRateLimitUser(), Period: rateLimitPeriod},
+			IPAllowlist: ipAllowlistService.Check,
+			Consume:     workspaceService.Consume,
+		},
+		&rpc.Services{UserService: userService, AdminService: adminService, TodoService: todoService},
+	)
+	if port := s.Config.GetGRPCPort(); port != "" {
+		listener, err := net.Listen("tcp", fmt.Sprintf(":%v", port))
+		if err != nil {
+			stop(fmt.Errorf("failed to listen for gRPC: %w", err))
+		} else {
+			log.Info().Msg(fmt.Sprintf("Starting gRPC server on port: %v", port))
+			go func() {
+				if err := grpcServer.Serve(listener); err != nil {
+					stop(fmt.Errorf("failed to start the gRPC server: %w", err))
+				}
+			}()
+		}
+	}
+
+	log.Info().
+		Msg(fmt.Sprintf("Starting server on port: %v and environment: %v", s.Config.GetPort(), s.Config.GetEnvironment()))
 	go func() {
-		db, err := s.initializeDB()
+		if err := echoRouter.Start(fmt.Sprintf(":%v", s.Config.GetPort())); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			stop(fmt.Errorf("failed to start the server: %w", err))
+		}
+	}()
+
+	<-ctx.Done()
+	// the cause is nil when the server was stopped through the context it was started with
+	serveErr := context.Cause(ctx)
+	if errors.Is(serveErr, context.Canceled) || errors.Is(serveErr, context.DeadlineExceeded) {
+		serveErr = nil
+	}
+	log.Info().Msg("shutting down, draining in-flight requests")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.Config.GetShutdownTimeoutSeconds())*time.Second)
+	defer cancel()
+	// stops accepting connections and waits for the requests in progress, event streams are
+	// closed right away
+	if err := echoRouter.Shutdown(ctx); err != nil {
+		log.Err(err).Msg("error draining in-flight requests")
+	}
+	rpc.Shutdown(ctx, grpcServer)
+	if err := workers.Wait(ctx); err != nil {
+		log.Err(err).Msg("error waiting for background workers")
+	}
+	// the other instances take over the shard with their next heartbeat
+	if err := instanceService.Deregister(ctx); err != nil {
+		log.Err(err).Msg("error deregistering instance")
+	}
+	// deliver what came due while draining rather than leaving it to the next instance
+	if err := db.Each(webhookService.DeliverDue)(ctx); err != nil {
+		log.Err(err).Msg("error flushing webhook deliveries")
+	}
+	if err := db.Each(snapshotService.SendDue)(ctx); err != nil {
+		log.Err(err).Msg("error flushing list snapshots")
+	}
+	if err := db.Each(notificationService.SendDue)(ctx); err != nil {
+		log.Err(err).Msg("error flushing queued emails")
+	}
+	if err := db.Each(slackService.SendDue)(ctx); err != nil {
+		log.Err(err).Msg("error flushing slack messages")
+	}
+
+	pools := []any{homeDB, cache}
+	for _, regionDB := range regionDBs {
+		pools = append(pools, regionDB)
+	}
+	closeAll(pools...)
+	if err := shutdownTracing(ctx); err != nil {
+		log.Err(err).Msg("error flushing traces")
+	}
+	log.Info().Msg("server stopped")
+
+	return serveErr
+}
+
+// closeAll closes the connection pools of the databases and the cache, those that hold none
+// are skipped.
+func closeAll(resources ...any) {
+	for _, resource := range resources {
+		if closer, ok := resource.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				log.Err(err).Msg("error closing connection pool")
+			}
+		}
+	}
+}
+
+// VerifyAudit checks the hash chain of the audit log of every region, it returns
+// ErrAuditChainBroken when any chain is broken.
+func (s *Server) VerifyAudit(ctx context.Context) error {
+	homeDB, err := s.initializeDB()
+	if err != nil {
+		return err
+	}
+	regionDBs, err := s.initializeRegions()
+	if err != nil {
+		return err
+	}
+	db := region.NewRouter(homeDB, regionDBs)
+
+	auditService := service.NewAuditService(service.NewBaseService(s.Config, nil), repo.NewAuditRepo(db), repo.NewUserRepository(db))
+
+	verify := db.Each(func(ctx context.Context) error {
+		verification, err := auditService.Verify(ctx)
 		if err != nil {
-			echoRouter.Logger.Fatal("failed to initilize DB, shutting down: %w", err)
+			return err
+		}
+
+		event := log.Info()
+		if !verification.Valid {
+			event = log.Error().Uint("broken_id", verification.BrokenID).Str("reason", verification.Reason)
+		}
+		event.Str("region", region.FromContext(ctx)).
+			Int("checked", verification.Checked).
+			Int("unchained", verification.Unchained).
+			Str("head", verification.Head).
+			Msg("audit log verified")
+
+		if !verification.Valid {
+			return domain.ErrAuditChainBroken
 		}
+		return nil
+	})
+
+	return verify(ctx)
+}
+
+// GrantAdmin replaces the admin scopes of the user with the given UUID in its data region, it
+// bootstraps the first superadmin who then manages the scopes through the API.
+func (s *Server) GrantAdmin(ctx context.Context, userRegion string, userUUID string, scopes []domain.AdminScope) error {
+	homeDB, err := s.initializeDB()
+	if err != nil {
+		return err
+	}
+	regionDBs, err := s.initializeRegions()
+	if err != nil {
+		return err
+	}
+	db := region.NewRouter(homeDB, regionDBs)
+
+	adminService := service.NewAdminService(
+		service.NewBaseService(s.Config, nil), repo.NewUserRepository(db), repo.NewAdminRepo(db), service.NewSecurityEvents(),
+	)
 
-		cache, err := s.initializeRedis()
+	return adminService.Grant(region.WithRegion(ctx, userRegion), userUUID, scopes)
+}
+
+// healthCheckers returns the checks of /healthz and /readyz. Stuck workers fail both, the
+// databases of every region and the cache only fail the readiness.
+func (s *Server) healthCheckers(homeDB db.DB, regionDBs map[string]db.DB, cache cache.Cache) (*health.Registry, *health.Registry) {
+	liveness := health.NewRegistry()
+	liveness.Register("workers", health.CheckerFunc(worker.Alive))
+
+	readiness := health.NewRegistry()
+	readiness.Register("db", health.DB(homeDB))
+	readiness.Register("migrations", health.Migrations(homeDB))
+	for _, name := range slices.Sorted(maps.Keys(regionDBs)) {
+		readiness.Register("db."+name, health.DB(regionDBs[name]))
+		readiness.Register("migrations."+name, health.Migrations(regionDBs[name]))
+	}
+	readiness.Register("redis", health.Cache(cache))
+	readiness.Register("workers", health.CheckerFunc(worker.Alive))
+
+	return liveness, readiness
+}
+
+// authMiddleware authenticates the requests of the API group and the other routes that need
+// the session.
+func (s *Server) authMiddleware(
+	keys middleware.JWTKeyResolver,
+	tokenBlacklist blacklist.Blacklist,
+	roomTickets middleware.RoomTicketAuthenticator,
+	router *region.Router,
+	ipAllowlist middleware.IPAllowlistChecker,
+	rateLimitStore ratelimit.Store,
+	consume middleware.UsageConsumer,
+) []echo.MiddlewareFunc {
+	userLimit := ratelimit.Limit{Burst: s.Config.GetRateLimitUser(), Period: rateLimitPeriod}
+
+	return []echo.MiddlewareFunc{
+		middleware.JWTMiddleware(keys, tokenBlacklist, roomTickets, apiPrefix+controller.RoomRoute),
+		// these must be set after JWTMiddleware.
+		middleware.RateLimitMiddleware(rateLimitStore, userLimit, middleware.RateLimitByUser),
+		middleware.RegionMiddleware(router.Serves),
+		middleware.IPAllowlistMiddleware(ipAllowlist, controller.IPAllowlistBypassPaths...),
+		middleware.UsageMiddleware(consume, controller.UsageExemptPaths...),
+		middleware.UserIDLoggerMiddleware,
+	}
+}
+
+// ipExtractor only trusts X-Forwarded-For from the configured proxies, the IP allowlist would
+// be bypassed by a spoofed header otherwise.
+func (s *Server) ipExtractor() (echo.IPExtractor, error) {
+	proxies := s.Config.GetTrustedProxies()
+	if len(proxies) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, proxy := range proxies {
+		prefix, err := domain.ParseCIDR(proxy)
 		if err != nil {
-			echoRouter.Logger.Fatal("failed to initilize Redis, shutting down: %w", err)
+			return nil, fmt.Errorf("invalid trusted proxy %v: %w", proxy, err)
 		}
+		_, ipNet, err := net.ParseCIDR(prefix.String())
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %v: %w", proxy, err)
+		}
+		options = append(options, echo.TrustIPRange(ipNet))
+	}
 
-		api := s.setUpAPI(echoRouter, cache)
+	return echo.ExtractIPFromXFFHeader(options...), nil
+}
 
-		// Initialize repositories
-		userRepo := repo.NewUserRepository(db)
-		refreshTokenRepo := repo.NewRefreshTokenRepo(db)
+func (s *Server) userRepo(db db.DB) repo.UserRepo {
+	var userRepo repo.UserRepo = repo.NewUserRepository(db)
+	if s.UserRepo != nil {
+		userRepo = s.UserRepo(db, userRepo)
+	}
 
-		// Initialize services
-		baseService := service.NewBaseService(s.Config, cache)
-		authService := service.NewAuthService(baseService, refreshTokenRepo)
-		userService := service.NewUserService(baseService, authService, userRepo)
-		recipeService := service.NewRecipeService(baseService)
+	return userRepo
+}
 
-		// Initialize controllers
-		baseController := controller.NewBaseController(s.Config, cache)
-		userController := controller.NewUserController(baseController, userService)
-		userController.AddUnprotectedRoutes(echoRouter)
+func (s *Server) listRepo(db db.DB) repo.ListRepo {
+	var listRepo repo.ListRepo = repo.NewListRepo(db)
+	if s.ListRepo != nil {
+		listRepo = s.ListRepo(db, listRepo)
+	}
 
-		recipeController := controller.NewRecipeController(baseController, recipeService)
-		recipeController.AddRoutes(api)
+	return listRepo
+}
 
-		log.Info().
-			Msg(fmt.Sprintf("Starting server on port: %v and environment: %v", s.Config.GetPort(), s.Config.GetEnvironment()))
-		if err = echoRouter.Start(fmt.Sprintf(":%v", s.Config.GetPort())); err != nil && errors.Is(err, http.ErrServerClosed) {
-			// TODO do things before shutdown
-			echoRouter.Logger.Fatal("shutting down the server")
+// todoRepo wraps the todo repo with shadow writes when the feature flag is enabled, it also
+// returns the candidate repo then.
+func (s *Server) todoRepo(db db.DB) (repo.TodoRepo, repo.TodoRepo, error) {
+	var todoRepo repo.TodoRepo = repo.NewTodoRepo(db)
+	if s.TodoRepo != nil {
+		todoRepo = s.TodoRepo(db, todoRepo)
+	}
+	if !s.Config.GetShadowWrites() {
+		return todoRepo, nil, nil
+	}
+
+	if s.ShadowTodoRepo == nil {
+		log.Warn().Msg("shadow writes enabled but no shadow todo repo is registered")
+		return todoRepo, nil, nil
+	}
+
+	shadowDB, err := s.initializeShadowDB()
+	if err != nil {
+		return nil, nil, err
+	}
+	candidate := s.ShadowTodoRepo(shadowDB)
+
+	log.Info().Bool("compare_reads", s.Config.GetShadowCompareReads()).Msg("shadow writes enabled for todo repo")
+	return repo.NewShadowTodoRepo(todoRepo, candidate, s.Config.GetShadowCompareReads()), candidate, nil
+}
+
+// initializeShadowDB connects to the databases of every region again with the shadow schema
+// first on the search path, the migrations already ran on the regular connections.
+func (s *Server) initializeShadowDB() (*region.Router, error) {
+	dsns := s.DatabaseDSNs()
+	databases := make(map[string]db.DB, len(dsns))
+	for name, dsn := range dsns {
+		shadowDSN, err := shadowDSN(dsn)
+		if err != nil {
+			return nil, fmt.Errorf("error configuring shadow database of region %q: %w", name, err)
 		}
-	}()
+		databases[name] = s.instrumentDB(db.NewPostgres(shadowDSN, shadowDSN), name)
+	}
 
-	<-ctx.Done()
-	ctx, cancel := context.WithTimeout(context.Background(), shutdownTime)
-	defer cancel()
-	if err := echoRouter.Shutdown(ctx); err != nil {
-		echoRouter.Logger.Fatal(err)
+	home := databases[region.Home]
+	delete(databases, region.Home)
+
+	return region.NewRouter(home, databases), nil
+}
+
+// shadowDSN returns dsn with the shadow schema of migration 000066 first on the search path, for
+// URLs as well as key=value connection strings.
+func shadowDSN(dsn string) (string, error) {
+	if !strings.HasPrefix(dsn, "postgres://") && !strings.HasPrefix(dsn, "postgresql://") {
+		return dsn + " search_path=shadow,public", nil
 	}
+
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Set("search_path", "shadow,public")
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
 }
 
-func (s *Server) setUpAPI(e *echo.Echo, cache cache.Cache) *echo.Group {
-	api := e.Group("/api")
-	api.Use(middleware.JWTMiddleware(s.GetJWTSecret(), cache))
-	// this must be set after JWTMiddleware.
-	api.Use(middleware.UserIDLoggerMiddleware)
+// DatabaseDSNs returns the DSN of the home database and of every region database, keyed by
+// region.
+func (s *Server) DatabaseDSNs() map[string]string {
+	dsns := map[string]string{
+		// TODO: add reader too
+		region.Home: s.Config.GetDBDSN(),
+	}
+	for name, dsn := range s.Config.GetDBRegions() {
+		dsns[name] = dsn
+	}
 
-	return api
+	return dsns
 }
 
 func (s *Server) initializeDB() (db.DB, error) {
-	// TODO: add reader too
-	dbDSN := fmt.Sprintf("postgres://%v:%v@%v:%v/%v?sslmode=disable",
-		s.Config.GetDBUser(),
-		s.Config.GetDBPassword(),
-		s.Config.GetDBHost(),
-		s.Config.GetDBPort(),
-		s.Config.GetDBName(),
-	)
-	db := db.NewPostgres(dbDSN, dbDSN)
+	dbDSN := s.DatabaseDSNs()[region.Home]
+	db := s.instrumentDB(db.NewPostgres(dbDSN, dbDSN), region.Home)
 
 	if err := s.runMigrations(dbDSN); err != nil {
 		return nil, fmt.Errorf("error running migration: %w", err)
@@ -120,37 +954,42 @@ func (s *Server) initializeDB() (db.DB, error) {
 	return db, nil
 }
 
-func (s *Server) runMigrations(writerDSN string) error {
-	log.Info().Msg("Running migrations")
-
-	// Create a new migrate instance
-	m, err := migrate.New(
-		fmt.Sprintf("file://%s", s.Config.GetMigrationPath()),
-		writerDSN,
-	)
-	if err != nil {
-		// debug mode, try ../migration
-		m, err = migrate.New(
-			"file://../migrations",
-			writerDSN,
-		)
-		if err != nil {
-			return fmt.Errorf("error creating migrate instance: %w", err)
+// initializeRegions connects to the databases of the data regions and migrates them like the
+// home database.
+func (s *Server) initializeRegions() (map[string]db.DB, error) {
+	regions := make(map[string]db.DB)
+	for name, dsn := range s.Config.GetDBRegions() {
+		if err := s.runMigrations(dsn); err != nil {
+			return nil, fmt.Errorf("error running migration of region %q: %w", name, err)
 		}
-		log.Info().Msg("Running in debug mode, using ../migration path")
+		regions[name] = s.instrumentDB(db.NewPostgres(dsn, dsn), name)
+
+		log.Info().Str("region", name).Msg("region database initilized")
 	}
 
-	// Run migrations
-	err = m.Up()
+	return regions, nil
+}
+
+// instrumentDB records the metrics and traces of the queries of the database of a region, the
+// queries join the transaction of their context.
+func (s *Server) instrumentDB(database db.DB, name string) db.DB {
+	return repo.NewTxDB(tracing.NewDB(metrics.NewDB(database, name), metrics.RegionLabel(name)))
+}
+
+// runMigrations applies the pending embedded migrations unless AUTO_MIGRATE is off.
+func (s *Server) runMigrations(writerDSN string) error {
+	if !s.Config.GetAutoMigrate() {
+		return nil
+	}
+	log.Info().Msg("Running migrations")
+
+	runner, err := migrations.New(writerDSN)
 	if err != nil {
-		if errors.Is(err, migrate.ErrNoChange) {
-			log.Info().Msg("no migrations to run, no changes detected")
-			return nil
-		}
-		return fmt.Errorf("error running migration: %w", err)
+		return err
 	}
+	defer runner.Close()
 
-	return nil
+	return runner.Up()
 }
 
 func (s *Server) initializeRedis() (cache.Cache, error) {
@@ -162,3 +1001,173 @@ func (s *Server) initializeRedis() (cache.Cache, error) {
 
 	return cache, nil
 }
+
+// initializeBlacklist returns the token blacklist, the database blacklist always uses the home
+// database since tokens are checked before their region is known.
+func (s *Server) initializeBlacklist(cache cache.Cache, homeDB db.DB) (blacklist.Blacklist, error) {
+	switch s.Config.GetTokenBlacklist() {
+	case "redis", "":
+		return blacklist.NewRedisBlacklist(cache), nil
+	case "database":
+		return blacklist.NewDatabaseBlacklist(homeDB), nil
+	}
+
+	return nil, fmt.Errorf("unknown token blacklist %q", s.Config.GetTokenBlacklist())
+}
+
+func (s *Server) initializeRateLimitStore() (ratelimit.Store, error) {
+	switch s.Config.GetRateLimitStore() {
+	case "memory", "":
+		return ratelimit.NewMemoryStore(), nil
+	case "redis":
+		// the cache has no atomic operations, the store runs a script on a client of its own
+		client := redis.NewClient(&redis.Options{
+			Addr:     fmt.Sprintf("%v:%v", s.Config.GetRedisHost(), s.Config.GetRedisPort()),
+			Password: s.Config.GetRedisPassword(),
+		})
+		return ratelimit.NewRedisStore(client), nil
+	}
+
+	return nil, fmt.Errorf("unknown rate limit store %q", s.Config.GetRateLimitStore())
+}
+
+// initializeIdempotencyStore returns the store of idempotent responses, the database store uses
+// the home database since the keys of signups are claimed before a region is known.
+func (s *Server) initializeIdempotencyStore(homeDB db.DB) (idempotency.Store, error) {
+	switch s.Config.GetIdempotencyStore() {
+	case "database", "":
+		return idempotency.NewDatabaseStore(homeDB), nil
+	case "memory":
+		return idempotency.NewMemoryStore(), nil
+	}
+
+	return nil, fmt.Errorf("unknown idempotency store %q", s.Config.GetIdempotencyStore())
+}
+
+func (s *Server) initializeStorage() (storage.Storage, error) {
+	if s.Storage != nil {
+		return s.Storage, nil
+	}
+
+	switch s.Config.GetStorageDriver() {
+	case "s3":
+		return storage.NewS3Storage(
+			s.Config.GetS3Endpoint(),
+			s.Config.GetS3Region(),
+			s.Config.GetS3Bucket(),
+			s.Config.GetS3AccessKeyID(),
+			s.Config.GetS3SecretAccessKey(),
+		)
+	case "local", "":
+		return storage.NewLocalStorage(s.Config.GetStoragePath(), s.Config.GetAPIURL(), s.Config.GetJWTSecret()), nil
+	}
+
+	return nil, fmt.Errorf("unknown storage driver %q", s.Config.GetStorageDriver())
+}
+
+// initializeExtractor builds the text extraction of attachments, images are only extracted
+// when an OCR command is configured.
+func (s *Server) initializeExtractor() (*extract.Pipeline, error) {
+	pipeline := extract.NewPipeline()
+	if s.Config.GetOCRCommand() == "" {
+		return pipeline, nil
+	}
+
+	ocr, err := extract.NewCommand(s.Config.GetOCRCommand())
+	if err != nil {
+		return nil, err
+	}
+	pipeline.Register("image/*", ocr)
+
+	return pipeline, nil
+}
+
+// initializeMailer returns the sender of the configured mail provider, without a provider emails
+// go out over SMTP or are only logged when no SMTP host is set either.
+func (s *Server) initializeMailer() (mail.Sender, error) {
+	if s.Mailer != nil {
+		return s.Mailer, nil
+	}
+
+	provider := s.Config.GetMailProvider()
+	if provider == "" {
+		if s.Config.GetSMTPHost() == "" {
+			log.Warn().Msg("no SMTP host configured, emails will only be logged")
+			return mail.NewLogSender(), nil
+		}
+		provider = mail.ProviderSMTP
+	}
+
+	return mail.Open(provider, mail.Settings{
+		Host:     s.Config.GetSMTPHost(),
+		Port:     s.Config.GetSMTPPort(),
+		Username: s.Config.GetSMTPUsername(),
+		Password: s.Config.GetSMTPPassword(),
+		From:     s.Config.GetMailFrom(),
+	})
+}
+
+func (s *Server) webhookSender() webhook.Sender {
+	if s.WebhookSender != nil {
+		return s.WebhookSender
+	}
+
+	return webhook.NewHTTPSender(webhookTimeout, s.Config.GetWebhookAllowPrivate())
+}
+
+// initializePush returns the VAPID keys and the sender of push messages, both are nil when no
+// VAPID private key is configured and push is disabled.
+func (s *Server) initializePush() (*webpush.Keys, webpush.Sender, error) {
+	if s.Config.GetVAPIDPrivateKey() == "" {
+		return nil, nil, nil
+	}
+
+	keys, err := webpush.ParseKeys(s.Config.GetVAPIDPrivateKey())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return keys, webpush.NewHTTPSender(pushTimeout, keys, s.Config.GetVAPIDSubject()), nil
+}
+
+// initializeLanguageModel returns the model of the configured provider, the disabled provider
+// turns off the features that need one.
+func (s *Server) initializeLanguageModel() (llm.Model, error) {
+	if s.LanguageModel != nil {
+		return s.LanguageModel, nil
+	}
+
+	return llm.Open(s.Config.GetLLMProvider(), llm.Settings{
+		URL:     s.Config.GetLLMURL(),
+		APIKey:  s.Config.GetLLMAPIKey(),
+		Model:   s.Config.GetLLMModel(),
+		Timeout: s.Config.GetLLMTimeout(),
+	})
+}
+
+// initializeEmbedder returns the embedding model of the configured provider, the disabled
+// provider turns off semantic search.
+func (s *Server) initializeEmbedder() (llm.Embedder, error) {
+	if s.Embedder != nil {
+		return s.Embedder, nil
+	}
+
+	return llm.OpenEmbedder(s.Config.GetEmbeddingProvider(), llm.Settings{
+		URL:     s.Config.GetEmbeddingURL(),
+		APIKey:  s.Config.GetEmbeddingAPIKey(),
+		Model:   s.Config.GetEmbeddingModel(),
+		Timeout: s.Config.GetLLMTimeout(),
+	})
+}
+
+func (s *Server) suggestionAnalyzer() *suggestion.Analyzer {
+	const day = 24 * time.Hour
+
+	return suggestion.NewAnalyzer(suggestion.Config{
+		MinPostponed:    s.Config.GetSuggestionMinPostponed(),
+		MinOverdue:      time.Duration(s.Config.GetSuggestionMinOverdueDays()) * day,
+		LargeEstimate:   time.Duration(s.Config.GetSuggestionLargeEstimateMinutes()) * time.Minute,
+		DropAfter:       time.Duration(s.Config.GetSuggestionDropAfterDays()) * day,
+		DropMaxPriority: domain.PriorityLow,
+	})
+}
@@ -0,0 +1,79 @@
+// Package audit carries the audit entry of a request through its context, so services can
+// attribute requests that are not authenticated yet, e.g. the user that logged in. It also
+// hash chains the entries of the log to detect tampering.
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type contextKey struct{}
+
+// WithEntry returns a context carrying the audit entry of the request.
+func WithEntry(ctx context.Context, entry *domain.AuditEntry) context.Context {
+	return context.WithValue(ctx, contextKey{}, entry)
+}
+
+// FromContext returns the audit entry of the request, nil outside of audited requests.
+func FromContext(ctx context.Context) *domain.AuditEntry {
+	entry, _ := ctx.Value(contextKey{}).(*domain.AuditEntry)
+	return entry
+}
+
+// SetRegion records the entry in the database of a region, for requests that are routed to the
+// region of the user by the services. It is a no-op outside of audited requests.
+func SetRegion(ctx context.Context, region string) {
+	if entry := FromContext(ctx); entry != nil {
+		entry.Region = region
+	}
+}
+
+// SetUser attributes the request to a user, it is a no-op outside of audited requests.
+func SetUser(ctx context.Context, userID uint) {
+	if entry := FromContext(ctx); entry != nil {
+		entry.UserID = userID
+	}
+}
+
+// Hash returns the hash chaining entry onto entry.PrevHash. It covers every recorded field, the
+// ID is left out since it is assigned by the database after the hash.
+func Hash(entry *domain.AuditEntry) string {
+	// a JSON array keeps the fields unambiguous, e.g. "ab"+"c" and "a"+"bc"
+	fields, _ := json.Marshal([]interface{}{ //nolint:errchkjson // strings and numbers only
+		entry.PrevHash,
+		entry.UserID,
+		entry.Action,
+		entry.Path,
+		entry.Status,
+		entry.IP,
+		entry.UserAgent,
+		entry.RequestID,
+		entry.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	sum := sha256.Sum256(fields)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// Verify checks an entry against the hash of the entry before it and returns why it breaks the
+// chain, or an empty string.
+func Verify(prevHash string, entry *domain.AuditEntry) string {
+	if entry.PrevHash != prevHash {
+		return "previous hash mismatch, entries were removed or reordered"
+	}
+	// the fields of anonymized entries were cleared after they were hashed
+	if !entry.AnonymizedAt.IsZero() {
+		return ""
+	}
+	if entry.Hash != Hash(entry) {
+		return "hash mismatch, the entry was modified"
+	}
+
+	return ""
+}
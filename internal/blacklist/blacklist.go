@@ -0,0 +1,28 @@
+// Package blacklist revokes access tokens before they expire, e.g. on logout. Entries only need
+// to outlive the token, expired tokens are rejected anyway. Besides single tokens, every token of
+// a user issued before a time can be revoked, e.g. when the password changed.
+package blacklist
+
+import (
+	"context"
+	"time"
+)
+
+// Blacklist holds revoked access tokens, implementations must be safe for concurrent use.
+type Blacklist interface {
+	// Add revokes the token of the user until expiresAt.
+	Add(ctx context.Context, userID uint, token string, expiresAt time.Time) error
+	Contains(ctx context.Context, userID uint, token string) (bool, error)
+	// RevokeIssuedBefore revokes the tokens of the user issued before issuedBefore until
+	// expiresAt, users are named by their UUID, which unlike their ID is unique across regions.
+	RevokeIssuedBefore(ctx context.Context, userUUID string, issuedBefore time.Time, expiresAt time.Time) error
+	// RevokedBefore returns the time tokens of the user issued before are revoked, the zero time
+	// when none are.
+	RevokedBefore(ctx context.Context, userUUID string) (time.Time, error)
+}
+
+// Pruner is implemented by blacklists that don't expire entries on their own, Prune removes the
+// entries of expired tokens.
+type Pruner interface {
+	Prune(ctx context.Context) error
+}
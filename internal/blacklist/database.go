@@ -0,0 +1,99 @@
+package blacklist
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+)
+
+// databaseBlacklist stores revoked tokens in the token_blacklist table and the revocations of
+// users in token_revocations, for deployments that want revocations to survive a Redis flush.
+// Expired rows are removed by Prune.
+type databaseBlacklist struct {
+	DB db.DB
+}
+
+func NewDatabaseBlacklist(db db.DB) *databaseBlacklist {
+	return &databaseBlacklist{
+		DB: db,
+	}
+}
+
+var (
+	_ Blacklist = (*databaseBlacklist)(nil)
+	_ Pruner    = (*databaseBlacklist)(nil)
+)
+
+func (b *databaseBlacklist) Add(ctx context.Context, userID uint, token string, expiresAt time.Time) error {
+	if !expiresAt.After(time.Now()) {
+		return nil
+	}
+
+	query := `
+		INSERT INTO token_blacklist (token_hash, user_id, expires_at) VALUES ($1, $2, $3)
+		ON CONFLICT (token_hash) DO NOTHING`
+	_, err := b.DB.Exec(ctx, query, tokenHash(token), userID, expiresAt.UTC())
+
+	return err
+}
+
+func (b *databaseBlacklist) Contains(ctx context.Context, userID uint, token string) (bool, error) {
+	var blacklisted bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM token_blacklist WHERE token_hash = $1 AND user_id = $2 AND expires_at > $3
+		)`
+	if err := b.DB.Get(ctx, &blacklisted, query, tokenHash(token), userID, time.Now().UTC()); err != nil {
+		return false, err
+	}
+
+	return blacklisted, nil
+}
+
+func (b *databaseBlacklist) RevokeIssuedBefore(ctx context.Context, userUUID string, issuedBefore time.Time, expiresAt time.Time) error {
+	if !expiresAt.After(time.Now()) {
+		return nil
+	}
+
+	// a later revocation covers the tokens of an earlier one
+	query := `
+		INSERT INTO token_revocations (user_uuid, issued_before, expires_at) VALUES ($1, $2, $3)
+		ON CONFLICT (user_uuid) DO UPDATE
+			SET issued_before = GREATEST(token_revocations.issued_before, EXCLUDED.issued_before),
+				expires_at = GREATEST(token_revocations.expires_at, EXCLUDED.expires_at)`
+	_, err := b.DB.Exec(ctx, query, userUUID, issuedBefore.UTC(), expiresAt.UTC())
+
+	return err
+}
+
+func (b *databaseBlacklist) RevokedBefore(ctx context.Context, userUUID string) (time.Time, error) {
+	var issuedBefore time.Time
+	query := `SELECT issued_before FROM token_revocations WHERE user_uuid = $1 AND expires_at > $2`
+	err := b.DB.Get(ctx, &issuedBefore, query, userUUID, time.Now().UTC())
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+
+	return issuedBefore, err
+}
+
+func (b *databaseBlacklist) Prune(ctx context.Context) error {
+	now := time.Now().UTC()
+	if _, err := b.DB.Exec(ctx, `DELETE FROM token_blacklist WHERE expires_at <= $1`, now); err != nil {
+		return err
+	}
+	_, err := b.DB.Exec(ctx, `DELETE FROM token_revocations WHERE expires_at <= $1`, now)
+
+	return err
+}
+
+// tokenHash keeps usable tokens out of the database.
+func tokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,71 @@
+package blacklist
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/meowmix1337/go-core/cache"
+)
+
+// redisBlacklist stores revoked tokens as keys expiring together with the token, so nothing
+// accumulates.
+type redisBlacklist struct {
+	cache cache.Cache
+}
+
+func NewRedisBlacklist(cache cache.Cache) *redisBlacklist {
+	return &redisBlacklist{
+		cache: cache,
+	}
+}
+
+var _ Blacklist = (*redisBlacklist)(nil)
+
+func (b *redisBlacklist) Add(ctx context.Context, userID uint, token string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+
+	return b.cache.Set(ctx, key(userID, token), "", int(ttl))
+}
+
+func (b *redisBlacklist) Contains(ctx context.Context, userID uint, token string) (bool, error) {
+	// the cache doesn't tell missing keys from other errors, both count as not blacklisted
+	_, err := b.cache.Get(ctx, key(userID, token))
+	return err == nil, nil
+}
+
+func (b *redisBlacklist) RevokeIssuedBefore(ctx context.Context, userUUID string, issuedBefore time.Time, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+
+	return b.cache.Set(ctx, revokedKey(userUUID), strconv.FormatInt(issuedBefore.UnixNano(), 10), int(ttl))
+}
+
+func (b *redisBlacklist) RevokedBefore(ctx context.Context, userUUID string) (time.Time, error) {
+	value, err := b.cache.Get(ctx, revokedKey(userUUID))
+	if err != nil {
+		// like in Contains, a missing key can't be told from other errors
+		return time.Time{}, nil
+	}
+
+	nanos, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid revocation of user %s: %w", userUUID, err)
+	}
+
+	return time.Unix(0, nanos), nil
+}
+
+func revokedKey(userUUID string) string {
+	return "revoked_before_" + userUUID
+}
+
+func key(userID uint, token string) string {
+	return fmt.Sprintf("%v_%v", userID, token)
+}
@@ -2,52 +2,300 @@ package config
 
 import (
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/viper"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type Config interface {
 	GetEnvironment() string
 	GetJWTSecret() string
+	GetJWTKeyRotationDays() int
+	GetPasswordHasher() string
+	GetBcryptCost() int
+	GetArgon2Memory() int
+	GetArgon2Iterations() int
+	GetArgon2Parallelism() int
+	GetAccountDeletionGraceDays() int
 	GetPort() string
-	GetMigrationPath() string
+	GetGRPCPort() string
+	GetAutoMigrate() bool
+	GetShutdownTimeoutSeconds() int
 
 	GetDBUser() string
 	GetDBPassword() string
 	GetDBName() string
 	GetDBHost() string
 	GetDBPort() string
+	GetDBDSN() string
+	GetDBRegions() map[string]string
 
 	GetRedisHost() string
 	GetRedisPort() string
 	GetRedisPassword() string
+	GetTokenBlacklist() string
+
+	GetTrustedProxies() []string
+	GetCountryHeader() string
+	GetRateLimitStore() string
+	GetIdempotencyStore() string
+	GetRateLimitAnonymous() int
+	GetRateLimitUser() int
+	GetMetricsToken() string
+	GetBootstrapToken() string
+
+	GetCanaryPercentage() int
+	GetCanaryHeader() string
+
+	GetShadowWrites() bool
+	GetShadowCompareReads() bool
+
+	GetMailProvider() string
+	GetSMTPHost() string
+	GetSMTPPort() string
+	GetSMTPUsername() string
+	GetSMTPPassword() string
+	GetMailFrom() string
+	GetAppURL() string
+
+	GetVoiceClientID() string
+	GetVoiceClientSecret() string
+	GetVoiceRedirectURIs() []string
+
+	GetAPIURL() string
+	GetStorageDriver() string
+	GetStoragePath() string
+	GetS3Endpoint() string
+	GetS3Region() string
+	GetS3Bucket() string
+	GetS3AccessKeyID() string
+	GetS3SecretAccessKey() string
+	GetAttachmentMaxSize() int64
+	GetOCRCommand() string
+
+	GetWebhookAllowPrivate() bool
+
+	GetVAPIDPrivateKey() string
+	GetVAPIDSubject() string
+	GetSlackSigningSecret() string
+
+	GetSuggestionMinPostponed() int
+	GetSuggestionMinOverdueDays() int
+	GetSuggestionLargeEstimateMinutes() int
+	GetSuggestionDropAfterDays() int
+
+	GetLLMProvider() string
+	GetLLMURL() string
+	GetLLMAPIKey() string
+	GetLLMModel() string
+	GetLLMTimeout() time.Duration
+	GetBreakdownRateLimit() int
+	GetEmbeddingProvider() string
+	GetEmbeddingURL() string
+	GetEmbeddingAPIKey() string
+	GetEmbeddingModel() string
+
+	GetWorkerInterval(name string, fallback time.Duration) time.Duration
+
+	GetPluginsDisabled() []string
+	GetPluginSettings(name string) map[string]string
+
+	GetFeatureFlag(name string) bool
 }
 
+// FeatureOnboardingSampleList creates a sample list for users on their first login.
+const FeatureOnboardingSampleList = "onboarding_sample_list"
+
+// featureFlags are the flags FEATURE_FLAGS can turn on.
+//
+//nolint:gochecknoglobals // constant list
+var featureFlags = []string{FeatureOnboardingSampleList}
+
 // Config holds the application configuration.
 type ConfigImpl struct {
-	Environment   string `mapstructure:"ENVIRONMENT"`
-	Hostname      string `mapstructure:"HOSTNAME"`
-	Port          string `mapstructure:"PORT"`
-	LogLevel      string `mapstructure:"LOG_LEVEL"`
-	JWTSecret     string `mapstructure:"JWT_SECRET"`
-	MigrationPath string `mapstructure:"MIGRATION_PATH"`
+	Environment string `mapstructure:"ENVIRONMENT"`
+	Hostname    string `mapstructure:"HOSTNAME"`
+	Port        string `mapstructure:"PORT"`
+	LogLevel    string `mapstructure:"LOG_LEVEL"`
+	JWTSecret   string `mapstructure:"JWT_SECRET"`
+	// JWTKeyRotationDays is how often a new key for signing access tokens is created, the
+	// previous keys keep verifying until the tokens signed with them expired.
+	JWTKeyRotationDays int `mapstructure:"JWT_KEY_ROTATION_DAYS"`
+	// PasswordHasher is the algorithm of new password hashes, either bcrypt or argon2id. Hashes
+	// of the other algorithm or with other parameters keep working and are replaced on login.
+	PasswordHasher string `mapstructure:"PASSWORD_HASHER"`
+	BcryptCost     int    `mapstructure:"BCRYPT_COST"`
+	// Argon2Memory is the memory of argon2id hashes in KiB.
+	Argon2Memory      int `mapstructure:"ARGON2_MEMORY"`
+	Argon2Iterations  int `mapstructure:"ARGON2_ITERATIONS"`
+	Argon2Parallelism int `mapstructure:"ARGON2_PARALLELISM"`
+	// AccountDeletionGraceDays is how long a deleted account can still be restored before it
+	// is purged.
+	AccountDeletionGraceDays int `mapstructure:"ACCOUNT_DELETION_GRACE_DAYS"`
+	// GRPCPort is the port of the gRPC server for internal services, empty disables it. The
+	// server has no TLS, so it belongs on a private network.
+	GRPCPort string `mapstructure:"GRPC_PORT"`
+	// AutoMigrate applies the pending migrations of every database on startup, otherwise they
+	// are applied with the migrate command
+	AutoMigrate bool `mapstructure:"AUTO_MIGRATE"`
+
+	// ShutdownTimeoutSeconds bounds draining the in-flight requests and background workers on
+	// SIGTERM, whatever is still running afterwards is cut off.
+	ShutdownTimeoutSeconds int `mapstructure:"SHUTDOWN_TIMEOUT_SECONDS"`
 
 	// Database
 	DBUser     string `mapstructure:"DB_USER"`
 	DBPassword string `mapstructure:"DB_PASSWORD"`
 	DBHost     string `mapstructure:"DB_HOST"`
-	DBPort     string `mapstructure:"DB_POST"`
+	DBPort     string `mapstructure:"DB_PORT"`
 	DBName     string `mapstructure:"DB_NAME"`
+	// DBDSN is the DSN of the home database, it replaces the settings above when set.
+	DBDSN string `mapstructure:"DB_DSN"`
+	// DBRegions is a comma separated list of region=dsn pairs, the databases users can pin
+	// their data to. The database above is the home region.
+	DBRegions string `mapstructure:"DB_REGIONS"`
 
 	RedisHost     string `mapstructure:"REDIS_HOST"`
 	RedisPort     string `mapstructure:"REDIS_PORT"`
 	RedisPassword string `mapstructure:"REDIS_PASSWORD"`
+
+	// TokenBlacklist stores revoked access tokens, either redis or database
+	TokenBlacklist string `mapstructure:"TOKEN_BLACKLIST"`
+
+	// TrustedProxies is a comma separated list of the CIDR ranges of the reverse proxies in front
+	// of the API. X-Forwarded-For is only trusted from these, the client IP is the remote address
+	// when none are configured.
+	TrustedProxies string `mapstructure:"TRUSTED_PROXIES"`
+	// CountryHeader is the header the reverse proxy sends the country of the client IP in, e.g.
+	// CF-IPCountry. Logins are only checked for new countries when it is set, it must only be set
+	// behind a proxy that overwrites it.
+	CountryHeader string `mapstructure:"COUNTRY_HEADER"`
+
+	// Rate limits in requests per minute, per IP for login and signup and per user for the
+	// authenticated routes. 0 disables a limit. The store is either memory or redis, memory
+	// limits are per instance.
+	RateLimitStore     string `mapstructure:"RATE_LIMIT_STORE"`
+	RateLimitAnonymous int    `mapstructure:"RATE_LIMIT_ANONYMOUS"`
+	RateLimitUser      int    `mapstructure:"RATE_LIMIT_USER"`
+
+	// IdempotencyStore keeps the responses replayed for retries with an Idempotency-Key, either
+	// database or memory. Memory only replays responses of the same instance.
+	IdempotencyStore string `mapstructure:"IDEMPOTENCY_STORE"`
+
+	// MetricsToken protects /metrics with a bearer token, /metrics is not served when empty
+	MetricsToken string `mapstructure:"METRICS_TOKEN"`
+
+	// BootstrapToken authenticates the provisioning API, the API is disabled when empty
+	BootstrapToken string `mapstructure:"BOOTSTRAP_TOKEN"`
+
+	// Canary
+	CanaryPercentage int    `mapstructure:"CANARY_PERCENTAGE"`
+	CanaryHeader     string `mapstructure:"CANARY_HEADER"`
+
+	// Shadow writes, used while migrating a repo to a new storage implementation. The candidate
+	// todo repo writes to the tables of the shadow schema.
+	ShadowWrites       bool `mapstructure:"SHADOW_WRITES"`
+	ShadowCompareReads bool `mapstructure:"SHADOW_COMPARE_READS"`
+
+	// Mail, emails are only logged when no SMTP host is configured. MailProvider picks another
+	// registered provider, e.g. ses or sendgrid, which read their own settings
+	MailProvider string `mapstructure:"MAIL_PROVIDER"`
+	SMTPHost     string `mapstructure:"SMTP_HOST"`
+	SMTPPort     string `mapstructure:"SMTP_PORT"`
+	SMTPUsername string `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword string `mapstructure:"SMTP_PASSWORD"`
+	MailFrom     string `mapstructure:"MAIL_FROM"`
+
+	// AppURL is the public URL of the web app, used for links in emails
+	AppURL string `mapstructure:"APP_URL"`
+
+	// Voice assistant, the OAuth client used by smart speaker skills for account linking.
+	// Account linking is disabled when no client ID is configured.
+	VoiceClientID     string `mapstructure:"VOICE_CLIENT_ID"`
+	VoiceClientSecret string `mapstructure:"VOICE_CLIENT_SECRET"`
+	// VoiceRedirectURIs is a comma separated list of the allowed redirect URIs
+	VoiceRedirectURIs string `mapstructure:"VOICE_REDIRECT_URIS"`
+
+	// APIURL is the public URL of this API, used for links to files served by the API
+	APIURL string `mapstructure:"API_URL"`
+
+	// Storage of attachments, the driver is either local or s3
+	StorageDriver     string `mapstructure:"STORAGE_DRIVER"`
+	StoragePath       string `mapstructure:"STORAGE_PATH"`
+	S3Endpoint        string `mapstructure:"S3_ENDPOINT"`
+	S3Region          string `mapstructure:"S3_REGION"`
+	S3Bucket          string `mapstructure:"S3_BUCKET"`
+	S3AccessKeyID     string `mapstructure:"S3_ACCESS_KEY_ID"`
+	S3SecretAccessKey string `mapstructure:"S3_SECRET_ACCESS_KEY"`
+	// AttachmentMaxSize is the maximum size of an uploaded file in bytes
+	AttachmentMaxSize int64 `mapstructure:"ATTACHMENT_MAX_SIZE"`
+	// OCRCommand extracts the text of image attachments, it reads the image on stdin and
+	// writes the text to stdout, e.g. "tesseract stdin stdout". Images aren't searchable by
+	// their content when it is empty.
+	OCRCommand string `mapstructure:"OCR_COMMAND"`
+
+	// WebhookAllowPrivate allows webhooks to private and loopback addresses, only enable it
+	// for development
+	WebhookAllowPrivate bool `mapstructure:"WEBHOOK_ALLOW_PRIVATE"`
+
+	// Web Push, reminders are only pushed to browsers when a VAPID private key is configured.
+	// VAPIDSubject is a mailto: or https: URL push services can use to reach the operator
+	VAPIDPrivateKey string `mapstructure:"VAPID_PRIVATE_KEY"`
+	VAPIDSubject    string `mapstructure:"VAPID_SUBJECT"`
+
+	// SlackSigningSecret verifies slash commands sent by the Slack app, commands are disabled
+	// without it. Notifications don't need it, they go out with the credentials of each user.
+	SlackSigningSecret string `mapstructure:"SLACK_SIGNING_SECRET"`
+
+	// Thresholds of the suggestions for postponed todos
+	SuggestionMinPostponed         int `mapstructure:"SUGGESTION_MIN_POSTPONED"`
+	SuggestionMinOverdueDays       int `mapstructure:"SUGGESTION_MIN_OVERDUE_DAYS"`
+	SuggestionLargeEstimateMinutes int `mapstructure:"SUGGESTION_LARGE_ESTIMATE_MINUTES"`
+	SuggestionDropAfterDays        int `mapstructure:"SUGGESTION_DROP_AFTER_DAYS"`
+
+	// Language model that proposes todo breakdowns, todos are never sent to a model with the
+	// disabled provider. LLMURL overrides the API of the provider, e.g. for a self-hosted OpenAI
+	// compatible server
+	LLMProvider       string `mapstructure:"LLM_PROVIDER"`
+	LLMURL            string `mapstructure:"LLM_URL"`
+	LLMAPIKey         string `mapstructure:"LLM_API_KEY"`
+	LLMModel          string `mapstructure:"LLM_MODEL"`
+	LLMTimeoutSeconds int    `mapstructure:"LLM_TIMEOUT_SECONDS"`
+	// BreakdownRateLimit is how many breakdowns a user may ask for per hour, zero is unlimited
+	BreakdownRateLimit int `mapstructure:"BREAKDOWN_RATE_LIMIT"`
+	// Embedding model of semantic search, todos are never sent to a model with the disabled
+	// provider. Requests time out after LLMTimeoutSeconds like those of the language model
+	EmbeddingProvider string `mapstructure:"EMBEDDING_PROVIDER"`
+	EmbeddingURL      string `mapstructure:"EMBEDDING_URL"`
+	EmbeddingAPIKey   string `mapstructure:"EMBEDDING_API_KEY"`
+	EmbeddingModel    string `mapstructure:"EMBEDDING_MODEL"`
+
+	// WorkerIntervals is a comma separated list of name=duration pairs that change how often
+	// a background worker runs, e.g. "emails=30s,reminders=2m". Workers not listed keep their
+	// interval.
+	WorkerIntervals string `mapstructure:"WORKER_INTERVALS"`
+
+	// PluginsDisabled is a comma separated list of compiled-in plugins that aren't loaded.
+	PluginsDisabled string `mapstructure:"PLUGINS_DISABLED"`
+	// PluginSettings is a comma separated list of plugin.key=value pairs, every plugin gets its
+	// own keys, e.g. "audit.endpoint=https://example.com,audit.level=info".
+	PluginSettings string `mapstructure:"PLUGIN_SETTINGS"`
+
+	// FeatureFlags is a comma separated list of the features that are turned on, e.g.
+	// "onboarding_sample_list". Features that aren't listed are off.
+	FeatureFlags string `mapstructure:"FEATURE_FLAGS"`
 }
 
 var _ Config = (*ConfigImpl)(nil)
 
-// NewConfig initializes and returns a Config instance.
+// NewConfig loads the configuration, every source overrides the ones before it: the defaults,
+// the .env file, the YAML file named by CONFIG_FILE or --config, the environment and the flags
+// of the command line. The keys of the YAML file are the names of the environment variables,
+// in either case. A configuration that fails Validate is an error.
 func NewConfig() (*ConfigImpl, error) {
 	viper.SetConfigName(".env")
 	viper.AddConfigPath(".")   // Specify the root directory for the config
@@ -59,10 +307,21 @@ func NewConfig() (*ConfigImpl, error) {
 	viper.SetDefault("ENVIRONMENT", "development")
 	viper.SetDefault("HOSTNAME", "localhost")
 	viper.SetDefault("PORT", "8081")
+	viper.SetDefault("GRPC_PORT", "")
 	viper.SetDefault("LOG_LEVEL", "debug")
-	viper.SetDefault("MIGRATION_PATH", "../migration")
+	viper.SetDefault("AUTO_MIGRATE", true)
+	viper.SetDefault("SHUTDOWN_TIMEOUT_SECONDS", 30)
 	// You should definitely replace with your own secret, this is for testing only
-	viper.SetDefault("JWT_SECRET", "some_really_bad_secret")
+	viper.SetDefault("JWT_SECRET", defaultJWTSecret)
+	viper.SetDefault("JWT_KEY_ROTATION_DAYS", 30)
+	viper.SetDefault("PASSWORD_HASHER", "bcrypt")
+	viper.SetDefault("BCRYPT_COST", bcrypt.DefaultCost)
+	// the second recommended option of RFC 9106 with less memory
+	viper.SetDefault("ARGON2_MEMORY", 64*1024)
+	viper.SetDefault("ARGON2_ITERATIONS", 3)
+	viper.SetDefault("ARGON2_PARALLELISM", 2)
+	viper.SetDefault("ACCOUNT_DELETION_GRACE_DAYS", 30)
+	viper.SetDefault("CONFIG_FILE", "")
 
 	// Database
 	viper.SetDefault("DB_USER", "admin")
@@ -70,23 +329,120 @@ func NewConfig() (*ConfigImpl, error) {
 	viper.SetDefault("DB_NAME", "the_recipe_book")
 	viper.SetDefault("DB_HOST", "localhost")
 	viper.SetDefault("DB_PORT", "5432")
+	viper.SetDefault("DB_DSN", "")
+	viper.SetDefault("DB_REGIONS", "")
 
 	// Redis
 	viper.SetDefault("REDIS_HOST", "localhost")
 	viper.SetDefault("REDIS_PORT", "6379")
 	viper.SetDefault("REDIS_PASSWORD", "")
+	viper.SetDefault("TOKEN_BLACKLIST", "redis")
+
+	viper.SetDefault("TRUSTED_PROXIES", "")
+	viper.SetDefault("COUNTRY_HEADER", "")
+
+	// Rate limits
+	viper.SetDefault("RATE_LIMIT_STORE", "memory")
+	viper.SetDefault("RATE_LIMIT_ANONYMOUS", 10)
+	viper.SetDefault("RATE_LIMIT_USER", 300)
+
+	viper.SetDefault("IDEMPOTENCY_STORE", "database")
+
+	viper.SetDefault("METRICS_TOKEN", "")
+	viper.SetDefault("BOOTSTRAP_TOKEN", "")
+
+	// Canary
+	viper.SetDefault("CANARY_PERCENTAGE", 0)
+	viper.SetDefault("CANARY_HEADER", "X-Canary")
+
+	// Shadow writes
+	viper.SetDefault("SHADOW_WRITES", false)
+	viper.SetDefault("SHADOW_COMPARE_READS", false)
+
+	// Mail
+	viper.SetDefault("MAIL_PROVIDER", "")
+	viper.SetDefault("SMTP_HOST", "")
+	viper.SetDefault("SMTP_PORT", "587")
+	viper.SetDefault("SMTP_USERNAME", "")
+	viper.SetDefault("SMTP_PASSWORD", "")
+	viper.SetDefault("MAIL_FROM", "no-reply@localhost")
+	viper.SetDefault("APP_URL", "http://localhost:3000")
+
+	// Voice assistant
+	viper.SetDefault("VOICE_CLIENT_ID", "")
+	viper.SetDefault("VOICE_CLIENT_SECRET", "")
+	viper.SetDefault("VOICE_REDIRECT_URIS", "")
+
+	viper.SetDefault("API_URL", "http://localhost:8081")
+
+	// Storage
+	viper.SetDefault("STORAGE_DRIVER", "local")
+	viper.SetDefault("STORAGE_PATH", "./data/attachments")
+	viper.SetDefault("S3_ENDPOINT", "")
+	viper.SetDefault("S3_REGION", "us-east-1")
+	viper.SetDefault("S3_BUCKET", "")
+	viper.SetDefault("S3_ACCESS_KEY_ID", "")
+	viper.SetDefault("S3_SECRET_ACCESS_KEY", "")
+	viper.SetDefault("ATTACHMENT_MAX_SIZE", 10<<20)
+	viper.SetDefault("OCR_COMMAND", "")
+
+	// Webhooks
+	viper.SetDefault("WEBHOOK_ALLOW_PRIVATE", false)
+
+	// Web Push
+	viper.SetDefault("VAPID_PRIVATE_KEY", "")
+	viper.SetDefault("VAPID_SUBJECT", "mailto:admin@localhost")
+	viper.SetDefault("SLACK_SIGNING_SECRET", "")
+
+	// Suggestions
+	viper.SetDefault("SUGGESTION_MIN_POSTPONED", 2)
+	viper.SetDefault("SUGGESTION_MIN_OVERDUE_DAYS", 3)
+	viper.SetDefault("SUGGESTION_LARGE_ESTIMATE_MINUTES", 120)
+	viper.SetDefault("SUGGESTION_DROP_AFTER_DAYS", 30)
+
+	// Language model
+	viper.SetDefault("LLM_PROVIDER", "disabled")
+	viper.SetDefault("LLM_URL", "")
+	viper.SetDefault("LLM_API_KEY", "")
+	viper.SetDefault("LLM_MODEL", "")
+	viper.SetDefault("LLM_TIMEOUT_SECONDS", 30)
+	viper.SetDefault("BREAKDOWN_RATE_LIMIT", 20)
+
+	// Embedding model
+	viper.SetDefault("EMBEDDING_PROVIDER", "disabled")
+	viper.SetDefault("EMBEDDING_URL", "")
+	viper.SetDefault("EMBEDDING_API_KEY", "")
+	viper.SetDefault("EMBEDDING_MODEL", "")
+
+	viper.SetDefault("WORKER_INTERVALS", "")
+	viper.SetDefault("PLUGINS_DISABLED", "")
+	viper.SetDefault("PLUGIN_SETTINGS", "")
+	viper.SetDefault("FEATURE_FLAGS", "")
 
 	err := viper.ReadInConfig() // Read from config file.
 	if err != nil {
 		log.Warn().Msg(fmt.Sprintf("Error reading config file: %v. Using defaults and environment variables.", err))
 	}
 
+	// unlike the .env file the YAML file has to exist once it is named
+	if file := viper.GetString("CONFIG_FILE"); file != "" {
+		viper.SetConfigFile(file)
+		viper.SetConfigType("yaml")
+		if err = viper.MergeInConfig(); err != nil {
+			return nil, fmt.Errorf("error reading config file %s: %w", file, err)
+		}
+	}
+
 	var config ConfigImpl
 	err = viper.Unmarshal(&config)
 	if err != nil {
 		return nil, err
 	}
 
+	if err = config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
 	return &config, nil
 }
 
@@ -98,12 +454,48 @@ func (c *ConfigImpl) GetJWTSecret() string {
 	return c.JWTSecret
 }
 
+func (c *ConfigImpl) GetJWTKeyRotationDays() int {
+	return c.JWTKeyRotationDays
+}
+
+func (c *ConfigImpl) GetAccountDeletionGraceDays() int {
+	return c.AccountDeletionGraceDays
+}
+
+func (c *ConfigImpl) GetPasswordHasher() string {
+	return c.PasswordHasher
+}
+
+func (c *ConfigImpl) GetBcryptCost() int {
+	return c.BcryptCost
+}
+
+func (c *ConfigImpl) GetArgon2Memory() int {
+	return c.Argon2Memory
+}
+
+func (c *ConfigImpl) GetArgon2Iterations() int {
+	return c.Argon2Iterations
+}
+
+func (c *ConfigImpl) GetArgon2Parallelism() int {
+	return c.Argon2Parallelism
+}
+
 func (c *ConfigImpl) GetPort() string {
 	return c.Port
 }
 
-func (c *ConfigImpl) GetMigrationPath() string {
-	return c.MigrationPath
+func (c *ConfigImpl) GetGRPCPort() string {
+	return c.GRPCPort
+}
+
+func (c *ConfigImpl) GetAutoMigrate() bool {
+	return c.AutoMigrate
+}
+
+func (c *ConfigImpl) GetShutdownTimeoutSeconds() int {
+	return c.ShutdownTimeoutSeconds
 }
 
 func (c *ConfigImpl) GetDBUser() string {
@@ -126,6 +518,27 @@ func (c *ConfigImpl) GetDBPort() string {
 	return c.DBPort
 }
 
+func (c *ConfigImpl) GetDBDSN() string {
+	if c.DBDSN != "" {
+		return c.DBDSN
+	}
+
+	return fmt.Sprintf("postgres://%v:%v@%v:%v/%v?sslmode=disable", c.DBUser, c.DBPassword, c.DBHost, c.DBPort, c.DBName)
+}
+
+func (c *ConfigImpl) GetDBRegions() map[string]string {
+	regions := map[string]string{}
+	for _, pair := range strings.Split(c.DBRegions, ",") {
+		region, dsn, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || region == "" || dsn == "" {
+			continue
+		}
+		regions[region] = dsn
+	}
+
+	return regions
+}
+
 func (c *ConfigImpl) GetRedisHost() string {
 	return c.RedisHost
 }
@@ -137,3 +550,308 @@ func (c *ConfigImpl) GetRedisPort() string {
 func (c *ConfigImpl) GetRedisPassword() string {
 	return c.RedisPassword
 }
+
+func (c *ConfigImpl) GetTokenBlacklist() string {
+	return c.TokenBlacklist
+}
+
+func (c *ConfigImpl) GetTrustedProxies() []string {
+	proxies := []string{}
+	for _, proxy := range strings.Split(c.TrustedProxies, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+
+	return proxies
+}
+
+func (c *ConfigImpl) GetCountryHeader() string {
+	return c.CountryHeader
+}
+
+func (c *ConfigImpl) GetRateLimitStore() string {
+	return c.RateLimitStore
+}
+
+func (c *ConfigImpl) GetIdempotencyStore() string {
+	return c.IdempotencyStore
+}
+
+func (c *ConfigImpl) GetRateLimitAnonymous() int {
+	return c.RateLimitAnonymous
+}
+
+func (c *ConfigImpl) GetRateLimitUser() int {
+	return c.RateLimitUser
+}
+
+func (c *ConfigImpl) GetMetricsToken() string {
+	return c.MetricsToken
+}
+
+func (c *ConfigImpl) GetBootstrapToken() string {
+	return c.BootstrapToken
+}
+
+func (c *ConfigImpl) GetCanaryPercentage() int {
+	return c.CanaryPercentage
+}
+
+func (c *ConfigImpl) GetCanaryHeader() string {
+	return c.CanaryHeader
+}
+
+func (c *ConfigImpl) GetShadowWrites() bool {
+	return c.ShadowWrites
+}
+
+func (c *ConfigImpl) GetShadowCompareReads() bool {
+	return c.ShadowCompareReads
+}
+
+func (c *ConfigImpl) GetMailProvider() string {
+	return c.MailProvider
+}
+
+func (c *ConfigImpl) GetSMTPHost() string {
+	return c.SMTPHost
+}
+
+func (c *ConfigImpl) GetSMTPPort() string {
+	return c.SMTPPort
+}
+
+func (c *ConfigImpl) GetSMTPUsername() string {
+	return c.SMTPUsername
+}
+
+func (c *ConfigImpl) GetSMTPPassword() string {
+	return c.SMTPPassword
+}
+
+func (c *ConfigImpl) GetMailFrom() string {
+	return c.MailFrom
+}
+
+func (c *ConfigImpl) GetAppURL() string {
+	return c.AppURL
+}
+
+func (c *ConfigImpl) GetVoiceClientID() string {
+	return c.VoiceClientID
+}
+
+func (c *ConfigImpl) GetVoiceClientSecret() string {
+	return c.VoiceClientSecret
+}
+
+func (c *ConfigImpl) GetVoiceRedirectURIs() []string {
+	uris := []string{}
+	for _, uri := range strings.Split(c.VoiceRedirectURIs, ",") {
+		if uri = strings.TrimSpace(uri); uri != "" {
+			uris = append(uris, uri)
+		}
+	}
+
+	return uris
+}
+
+func (c *ConfigImpl) GetAPIURL() string {
+	return c.APIURL
+}
+
+func (c *ConfigImpl) GetStorageDriver() string {
+	return c.StorageDriver
+}
+
+func (c *ConfigImpl) GetStoragePath() string {
+	return c.StoragePath
+}
+
+func (c *ConfigImpl) GetS3Endpoint() string {
+	return c.S3Endpoint
+}
+
+func (c *ConfigImpl) GetS3Region() string {
+	return c.S3Region
+}
+
+func (c *ConfigImpl) GetS3Bucket() string {
+	return c.S3Bucket
+}
+
+func (c *ConfigImpl) GetS3AccessKeyID() string {
+	return c.S3AccessKeyID
+}
+
+func (c *ConfigImpl) GetS3SecretAccessKey() string {
+	return c.S3SecretAccessKey
+}
+
+func (c *ConfigImpl) GetAttachmentMaxSize() int64 {
+	return c.AttachmentMaxSize
+}
+
+func (c *ConfigImpl) GetOCRCommand() string {
+	return c.OCRCommand
+}
+
+func (c *ConfigImpl) GetWebhookAllowPrivate() bool {
+	return c.WebhookAllowPrivate
+}
+
+func (c *ConfigImpl) GetVAPIDPrivateKey() string {
+	return c.VAPIDPrivateKey
+}
+
+func (c *ConfigImpl) GetVAPIDSubject() string {
+	return c.VAPIDSubject
+}
+
+func (c *ConfigImpl) GetSlackSigningSecret() string {
+	return c.SlackSigningSecret
+}
+
+func (c *ConfigImpl) GetSuggestionMinPostponed() int {
+	return c.SuggestionMinPostponed
+}
+
+func (c *ConfigImpl) GetSuggestionMinOverdueDays() int {
+	return c.SuggestionMinOverdueDays
+}
+
+func (c *ConfigImpl) GetSuggestionLargeEstimateMinutes() int {
+	return c.SuggestionLargeEstimateMinutes
+}
+
+func (c *ConfigImpl) GetSuggestionDropAfterDays() int {
+	return c.SuggestionDropAfterDays
+}
+
+func (c *ConfigImpl) GetLLMProvider() string {
+	return c.LLMProvider
+}
+
+func (c *ConfigImpl) GetLLMURL() string {
+	return c.LLMURL
+}
+
+func (c *ConfigImpl) GetLLMAPIKey() string {
+	return c.LLMAPIKey
+}
+
+func (c *ConfigImpl) GetLLMModel() string {
+	return c.LLMModel
+}
+
+func (c *ConfigImpl) GetLLMTimeout() time.Duration {
+	return time.Duration(c.LLMTimeoutSeconds) * time.Second
+}
+
+func (c *ConfigImpl) GetBreakdownRateLimit() int {
+	return c.BreakdownRateLimit
+}
+
+func (c *ConfigImpl) GetEmbeddingProvider() string {
+	return c.EmbeddingProvider
+}
+
+func (c *ConfigImpl) GetEmbeddingURL() string {
+	return c.EmbeddingURL
+}
+
+func (c *ConfigImpl) GetEmbeddingAPIKey() string {
+	return c.EmbeddingAPIKey
+}
+
+func (c *ConfigImpl) GetEmbeddingModel() string {
+	return c.EmbeddingModel
+}
+
+func (c *ConfigImpl) GetWorkerInterval(name string, fallback time.Duration) time.Duration {
+	intervals, err := parseWorkerIntervals(c.WorkerIntervals)
+	if err != nil {
+		return fallback
+	}
+	if interval, ok := intervals[name]; ok {
+		return interval
+	}
+
+	return fallback
+}
+
+func (c *ConfigImpl) GetPluginsDisabled() []string {
+	disabled := []string{}
+	for _, name := range strings.Split(c.PluginsDisabled, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			disabled = append(disabled, name)
+		}
+	}
+
+	return disabled
+}
+
+func (c *ConfigImpl) GetFeatureFlag(name string) bool {
+	return slices.Contains(splitList(c.FeatureFlags), name)
+}
+
+func (c *ConfigImpl) GetPluginSettings(name string) map[string]string {
+	settings, err := parsePluginSettings(c.PluginSettings)
+	if err != nil {
+		return map[string]string{}
+	}
+	if plugin, ok := settings[name]; ok {
+		return plugin
+	}
+
+	return map[string]string{}
+}
+
+// parsePluginSettings parses the plugin.key=value pairs of PLUGIN_SETTINGS by plugin.
+func parsePluginSettings(value string) (map[string]map[string]string, error) {
+	settings := map[string]map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		name, setting, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("plugin setting %q is not a plugin.key=value pair", pair)
+		}
+		plugin, key, ok := strings.Cut(strings.TrimSpace(name), ".")
+		if !ok || plugin == "" || key == "" {
+			return nil, fmt.Errorf("plugin setting %q is not a plugin.key=value pair", pair)
+		}
+		if settings[plugin] == nil {
+			settings[plugin] = map[string]string{}
+		}
+		settings[plugin][key] = strings.TrimSpace(setting)
+	}
+
+	return settings, nil
+}
+
+// parseWorkerIntervals parses the name=duration pairs of WORKER_INTERVALS.
+func parseWorkerIntervals(value string) (map[string]time.Duration, error) {
+	intervals := map[string]time.Duration{}
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		name, duration, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("worker interval %q is not a name=duration pair", pair)
+		}
+		interval, err := time.ParseDuration(strings.TrimSpace(duration))
+		if err != nil {
+			return nil, fmt.Errorf("worker interval of %s: %w", name, err)
+		}
+		if interval <= 0 {
+			return nil, fmt.Errorf("worker interval of %s must be positive", name)
+		}
+		intervals[strings.TrimSpace(name)] = interval
+	}
+
+	return intervals, nil
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// defaultJWTSecret lets the server start without any configuration in development, it is
+	// refused in production.
+	defaultJWTSecret   = "some_really_bad_secret"
+	minJWTSecretLength = 32
+
+	production = "production"
+)
+
+// Validate checks the configuration on startup, so a typo fails the deploy instead of the first
+// request that needs the setting. Every problem is reported at once.
+func (c *ConfigImpl) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(validPort(c.Port), "PORT %q is not a port", c.Port)
+	check(c.GRPCPort == "" || validPort(c.GRPCPort), "GRPC_PORT %q is not a port", c.GRPCPort)
+	check(c.ShutdownTimeoutSeconds > 0, "SHUTDOWN_TIMEOUT_SECONDS must be positive")
+
+	check(c.JWTSecret != "", "JWT_SECRET is required")
+	if c.Environment == production {
+		check(c.JWTSecret != defaultJWTSecret, "JWT_SECRET must be changed from the default in production")
+		check(len(c.JWTSecret) >= minJWTSecretLength,
+			"JWT_SECRET must be at least %d characters in production", minJWTSecretLength)
+	}
+	check(c.JWTKeyRotationDays > 0, "JWT_KEY_ROTATION_DAYS must be positive")
+	check(c.BcryptCost >= bcrypt.MinCost && c.BcryptCost <= bcrypt.MaxCost,
+		"BCRYPT_COST must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	check(oneOf(c.PasswordHasher, "bcrypt", "argon2id"), "PASSWORD_HASHER must be bcrypt or argon2id")
+	check(c.Argon2Iterations > 0, "ARGON2_ITERATIONS must be positive")
+	check(c.Argon2Parallelism > 0 && c.Argon2Parallelism <= math.MaxUint8,
+		"ARGON2_PARALLELISM must be between 1 and %d", math.MaxUint8)
+	// argon2 needs 8 KiB per lane
+	check(c.Argon2Memory >= 8*c.Argon2Parallelism && c.Argon2Memory <= math.MaxUint32,
+		"ARGON2_MEMORY must be at least 8 KiB per ARGON2_PARALLELISM")
+	check(c.AccountDeletionGraceDays > 0, "ACCOUNT_DELETION_GRACE_DAYS must be positive")
+
+	if c.DBDSN == "" {
+		check(c.DBHost != "" && c.DBName != "" && c.DBUser != "", "DB_HOST, DB_NAME and DB_USER are required without DB_DSN")
+		check(validPort(c.DBPort), "DB_PORT %q is not a port", c.DBPort)
+	}
+	for _, pair := range splitList(c.DBRegions) {
+		region, dsn, ok := strings.Cut(pair, "=")
+		check(ok && region != "" && dsn != "", "DB_REGIONS entry %q is not a region=dsn pair", pair)
+		// the region is part of the tokens of integrations, see region.TagToken
+		check(!strings.ContainsAny(region, ".:/?#&= "), "DB_REGIONS region %q must not contain separators", region)
+	}
+
+	check(c.RedisHost != "", "REDIS_HOST is required")
+	check(validPort(c.RedisPort), "REDIS_PORT %q is not a port", c.RedisPort)
+
+	check(oneOf(c.TokenBlacklist, "redis", "database"), "TOKEN_BLACKLIST must be redis or database")
+	check(oneOf(c.RateLimitStore, "memory", "redis"), "RATE_LIMIT_STORE must be memory or redis")
+	check(oneOf(c.IdempotencyStore, "database", "memory"), "IDEMPOTENCY_STORE must be database or memory")
+	check(c.RateLimitAnonymous >= 0, "RATE_LIMIT_ANONYMOUS can't be negative")
+	check(c.RateLimitUser >= 0, "RATE_LIMIT_USER can't be negative")
+	for _, proxy := range splitList(c.TrustedProxies) {
+		_, _, err := net.ParseCIDR(proxy)
+		check(err == nil || net.ParseIP(proxy) != nil, "TRUSTED_PROXIES entry %q is not an IP address or CIDR range", proxy)
+	}
+
+	check(c.CanaryPercentage >= 0 && c.CanaryPercentage <= 100, "CANARY_PERCENTAGE must be between 0 and 100")
+
+	check(oneOf(c.StorageDriver, "local", "s3"), "STORAGE_DRIVER must be local or s3")
+	check(c.StorageDriver != "s3" || c.S3Bucket != "", "S3_BUCKET is required for the s3 storage driver")
+	check(c.AttachmentMaxSize > 0, "ATTACHMENT_MAX_SIZE must be positive")
+
+	check(c.LLMTimeoutSeconds > 0, "LLM_TIMEOUT_SECONDS must be positive")
+	check(c.BreakdownRateLimit >= 0, "BREAKDOWN_RATE_LIMIT can't be negative")
+
+	for _, flag := range splitList(c.FeatureFlags) {
+		check(slices.Contains(featureFlags, flag), "FEATURE_FLAGS entry %q is not a feature, it must be one of %s",
+			flag, strings.Join(featureFlags, ", "))
+	}
+
+	if _, err := parseWorkerIntervals(c.WorkerIntervals); err != nil {
+		errs = append(errs, fmt.Errorf("WORKER_INTERVALS: %w", err))
+	}
+	if _, err := parsePluginSettings(c.PluginSettings); err != nil {
+		errs = append(errs, fmt.Errorf("PLUGIN_SETTINGS: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+func validPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n > 0 && n <= 65535
+}
+
+// oneOf reports whether value is one of the choices, empty picks the default of the setting.
+func oneOf(value string, choices ...string) bool {
+	return value == "" || slices.Contains(choices, value)
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AccountController struct {
+	*BaseController
+	AccountDeletionService service.AccountDeletionService
+	TakeoutService         service.TakeoutService
+}
+
+func NewAccountController(
+	base *BaseController,
+	accountDeletionService service.AccountDeletionService,
+	takeoutService service.TakeoutService,
+) *AccountController {
+	return &AccountController{
+		BaseController:         base,
+		AccountDeletionService: accountDeletionService,
+		TakeoutService:         takeoutService,
+	}
+}
+
+func (ac *AccountController) AddRoutes(e *echo.Group) {
+	e.DELETE("/"+V1+"/users/me", ac.delete)
+	e.DELETE("/"+V1+"/users/me/deletion", ac.cancelDeletion)
+	e.POST("/"+V1+"/users/me/export", ac.requestTakeout)
+	e.GET("/"+V1+"/users/me/exports/:uuid", ac.takeout)
+}
+
+// delete schedules the erasure of the account, it is purged once the grace period is over.
+func (ac *AccountController) delete(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	purgeAt, err := ac.AccountDeletionService.Schedule(c.Request().Context(), claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusAccepted, echo.Map{
+		"data": &endpoint.AccountDeletion{PurgeAt: purgeAt},
+	})
+}
+
+func (ac *AccountController) cancelDeletion(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	if err := ac.AccountDeletionService.Cancel(c.Request().Context(), claims.UserID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// requestTakeout queues an export of all the data of the user, the user is emailed a link to
+// download it once it is ready.
+func (ac *AccountController) requestTakeout(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	takeout, err := ac.TakeoutService.Request(c.Request().Context(), claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusAccepted, echo.Map{
+		"data": endpoint.NewTakeout(takeout, ""),
+	})
+}
+
+func (ac *AccountController) takeout(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	takeout, downloadURL, err := ac.TakeoutService.Takeout(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewTakeout(takeout, downloadURL),
+	})
+}
@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AdminController struct {
+	*BaseController
+	AdminService    service.AdminService
+	InstanceService service.InstanceService
+}
+
+func NewAdminController(base *BaseController, adminService service.AdminService, instanceService service.InstanceService) *AdminController {
+	return &AdminController{
+		BaseController:  base,
+		AdminService:    adminService,
+		InstanceService: instanceService,
+	}
+}
+
+func (ac *AdminController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/admin/users/:uuid/scopes", ac.scopes)
+	e.PUT("/"+V1+"/admin/users/:uuid/scopes", ac.setScopes)
+	e.GET("/"+V1+"/admin/instances", ac.instances)
+}
+
+func (ac *AdminController) scopes(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	scopes, err := ac.AdminService.Scopes(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewAdminScopes(scopes),
+	})
+}
+
+func (ac *AdminController) setScopes(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.AdminScopesRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	scopes, err := ac.AdminService.SetScopes(c.Request().Context(), claims.UserID, c.Param("uuid"), req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewAdminScopes(scopes),
+	})
+}
+
+// instances lists the live instances with their versions and shards, during a rolling deploy
+// it shows which versions still serve traffic.
+func (ac *AdminController) instances(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	if err := ac.AdminService.Authorize(c.Request().Context(), claims.UserID, domain.AdminActionReadInstances); err != nil {
+		return err
+	}
+
+	instances, err := ac.InstanceService.Live(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewInstances(instances, ac.InstanceService.ID()),
+	})
+}
@@ -0,0 +1,394 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/meowmix1337/the_recipe_book/internal/api/middleware"
+	"github.com/meowmix1337/the_recipe_book/internal/controller/validation"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+	"github.com/rs/zerolog/log"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxMCPMessage caps a message posted to the MCP route, tool arguments are small.
+const maxMCPMessage = 1 << 20
+
+type AgentController struct {
+	*BaseController
+	AgentService service.AgentService
+}
+
+func NewAgentController(base *BaseController, agentService service.AgentService) *AgentController {
+	return &AgentController{
+		BaseController: base,
+		AgentService:   agentService,
+	}
+}
+
+func (ac *AgentController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/agent-tokens", ac.tokens)
+	e.POST("/"+V1+"/agent-tokens", ac.createToken, middleware.NoIdempotentStore)
+	e.DELETE("/"+V1+"/agent-tokens/:uuid", ac.revokeToken)
+}
+
+// AddAgentRoutes registers the routes of AI assistants, they are authenticated with an agent
+// token instead of a user session. The tools are served both as routes of their own and over
+// the Model Context Protocol.
+func (ac *AgentController) AddAgentRoutes(e *echo.Echo, rateLimit echo.MiddlewareFunc) {
+	agent := e.Group("/agent")
+	agent.Use(middleware.AgentTokenMiddleware(ac.AgentService.Authenticate), rateLimit)
+
+	agent.GET("/"+V1+"/tools", ac.tools)
+	agent.GET("/"+V1+"/todos", ac.queryTodos)
+	agent.POST("/"+V1+"/todos", ac.createTodo)
+	agent.POST("/"+V1+"/todos/:uuid/complete", ac.completeTodo)
+	agent.POST("/"+V1+"/mcp", ac.mcp)
+}
+
+func (ac *AgentController) tokens(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	tokens, err := ac.AgentService.Tokens(c.Request().Context(), claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewAgentTokens(tokens),
+	})
+}
+
+func (ac *AgentController) createToken(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.AgentTokenRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	token, err := ac.AgentService.CreateToken(c.Request().Context(), claims.UserID, req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	// the token is only returned once, afterwards only its hash is stored
+	return c.JSON(http.StatusCreated, echo.Map{
+		"data": endpoint.NewAgentToken(token),
+	})
+}
+
+func (ac *AgentController) revokeToken(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	err := ac.AgentService.RevokeToken(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// agentToken returns the agent token set on the context by the agent token middleware.
+func agentToken(c echo.Context) (*domain.AgentToken, bool) {
+	token, ok := c.Get("agent_token").(*domain.AgentToken)
+	if !ok {
+		log.Error().Msg("Failed to assert agent token")
+	}
+
+	return token, ok
+}
+
+// tools returns the tools the agent token may use, with the JSON schema of their arguments.
+func (ac *AgentController) tools(c echo.Context) error {
+	token, ok := agentToken(c)
+	if !ok {
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewAgentTools(token),
+	})
+}
+
+func (ac *AgentController) queryTodos(c echo.Context) error {
+	token, ok := agentToken(c)
+	if !ok {
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	req := endpoint.AgentTodoQueryRequest{
+		Query:    c.QueryParam("query"),
+		ListUUID: c.QueryParam("list_uuid"),
+		Status:   c.QueryParam("status"),
+	}
+	dueBefore, err := timeParam(c, "due_before")
+	if err != nil {
+		return err
+	}
+	if !dueBefore.IsZero() {
+		req.DueBefore = &dueBefore
+	}
+	if value := c.QueryParam("limit"); value != "" {
+		if req.Limit, err = strconv.Atoi(value); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid limit")
+		}
+	}
+
+	todos, err := ac.queryTodosWith(c, token, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": todos,
+	})
+}
+
+func (ac *AgentController) queryTodosWith(c echo.Context, token *domain.AgentToken, req *endpoint.AgentTodoQueryRequest) ([]*endpoint.Todo, error) {
+	if err := c.Validate(req); err != nil {
+		return nil, err
+	}
+
+	todos, err := ac.AgentService.QueryTodos(c.Request().Context(), token, req.ToDomain())
+	if err != nil {
+		return nil, err
+	}
+
+	return endpoint.NewTodos(todos), nil
+}
+
+func (ac *AgentController) createTodo(c echo.Context) error {
+	token, ok := agentToken(c)
+	if !ok {
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	var req endpoint.AgentTodoCreateRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+	if key := c.Request().Header.Get(middleware.IdempotencyKeyHeader); key != "" {
+		req.IdempotencyKey = key
+	}
+
+	created, err := ac.createTodoWith(c, token, &req)
+	if err != nil {
+		return err
+	}
+	if created.Replayed {
+		c.Response().Header().Set(middleware.IdempotentReplayedHeader, "true")
+	}
+
+	return c.JSON(http.StatusCreated, echo.Map{
+		"data": created,
+	})
+}
+
+func (ac *AgentController) createTodoWith(c echo.Context, token *domain.AgentToken, req *endpoint.AgentTodoCreateRequest) (*endpoint.AgentTodoCreated, error) {
+	if err := c.Validate(req); err != nil {
+		return nil, err
+	}
+
+	created, err := ac.AgentService.CreateTodo(c.Request().Context(), token, req.ToDomain(), req.IdempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return endpoint.NewAgentTodoCreated(created), nil
+}
+
+func (ac *AgentController) completeTodo(c echo.Context) error {
+	token, ok := agentToken(c)
+	if !ok {
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	var req endpoint.AgentTodoCompleteRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+	req.UUID = c.Param("uuid")
+
+	completion, err := ac.completeTodoWith(c, token, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": completion,
+	})
+}
+
+func (ac *AgentController) completeTodoWith(c echo.Context, token *domain.AgentToken, req *endpoint.AgentTodoCompleteRequest) (*endpoint.AgentCompletion, error) {
+	if err := c.Validate(req); err != nil {
+		return nil, err
+	}
+
+	completion, err := ac.AgentService.CompleteTodo(c.Request().Context(), token, req.UUID, req.Confirmation)
+	if err != nil {
+		return nil, err
+	}
+
+	return endpoint.NewAgentCompletion(completion), nil
+}
+
+// mcp serves the tools over the Model Context Protocol with the streamable HTTP transport, every
+// request is answered with a single JSON response. The protocol is stateless here, the agent
+// token authenticates every message, so there are no sessions and no server sent events.
+func (ac *AgentController) mcp(c echo.Context) error {
+	token, ok := agentToken(c)
+	if !ok {
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxMCPMessage+1))
+	if err != nil || len(body) > maxMCPMessage {
+		return c.JSON(http.StatusBadRequest, endpoint.NewMCPError(nil, endpoint.MCPParseError, "Parse error"))
+	}
+	// batches were dropped from the protocol
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		return c.JSON(http.StatusBadRequest, endpoint.NewMCPError(nil, endpoint.MCPInvalidRequest, "Batches are not supported"))
+	}
+
+	var req endpoint.MCPRequest
+	if err = json.Unmarshal(body, &req); err != nil {
+		return c.JSON(http.StatusBadRequest, endpoint.NewMCPError(nil, endpoint.MCPParseError, "Parse error"))
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return c.JSON(http.StatusBadRequest, endpoint.NewMCPError(req.ID, endpoint.MCPInvalidRequest, "Invalid Request"))
+	}
+	// notifications, e.g. notifications/initialized, need no answer
+	if req.Notification() {
+		return c.NoContent(http.StatusAccepted)
+	}
+
+	result, rpcErr := ac.handleMCP(c, token, &req)
+	if rpcErr != nil {
+		return c.JSON(http.StatusOK, endpoint.NewMCPError(req.ID, rpcErr.Code, rpcErr.Message))
+	}
+
+	return c.JSON(http.StatusOK, endpoint.NewMCPResult(req.ID, result))
+}
+
+func (ac *AgentController) handleMCP(c echo.Context, token *domain.AgentToken, req *endpoint.MCPRequest) (any, *endpoint.MCPError) {
+	switch req.Method {
+	case "initialize":
+		var params endpoint.MCPInitializeParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &endpoint.MCPError{Code: endpoint.MCPInvalidParams, Message: "Invalid params"}
+		}
+
+		// clients asking for a version the server doesn't speak get the newest one and decide
+		version := endpoint.MCPProtocolVersions[0]
+		if slices.Contains(endpoint.MCPProtocolVersions, params.ProtocolVersion) {
+			version = params.ProtocolVersion
+		}
+
+		return &endpoint.MCPInitializeResult{
+			ProtocolVersion: version,
+			Capabilities:    map[string]any{"tools": map[string]any{}},
+			ServerInfo:      &endpoint.MCPServerInfo{Name: "todo", Version: V1},
+			Instructions: "Manage the todos of the user. Never complete a todo without showing the user " +
+				"the summary of complete_todo and getting their agreement.",
+		}, nil
+	case "ping":
+		return struct{}{}, nil
+	case "tools/list":
+		return endpoint.NewMCPToolList(endpoint.NewAgentTools(token)), nil
+	case "tools/call":
+		var params endpoint.MCPToolCallParams
+		if err := json.Unmarshal(req.Params, &params); err != nil || params.Name == "" {
+			return nil, &endpoint.MCPError{Code: endpoint.MCPInvalidParams, Message: "Invalid params"}
+		}
+
+		return ac.callTool(c, token, &params)
+	}
+
+	return nil, &endpoint.MCPError{Code: endpoint.MCPMethodNotFound, Message: "Method not found"}
+}
+
+// callTool runs a tool like its route. Errors the assistant can fix are returned as tool
+// results, so the model sees them, only internal errors fail the call.
+func (ac *AgentController) callTool(c echo.Context, token *domain.AgentToken, params *endpoint.MCPToolCallParams) (any, *endpoint.MCPError) {
+	arguments := params.Arguments
+	if len(arguments) == 0 || string(arguments) == "null" {
+		arguments = json.RawMessage("{}")
+	}
+
+	var structured any
+	var err error
+	switch params.Name {
+	case endpoint.AgentToolQueryTodos:
+		var req endpoint.AgentTodoQueryRequest
+		if err = decodeArguments(arguments, &req); err == nil {
+			var todos []*endpoint.Todo
+			todos, err = ac.queryTodosWith(c, token, &req)
+			structured = echo.Map{"todos": todos}
+		}
+	case endpoint.AgentToolCreateTodo:
+		var req endpoint.AgentTodoCreateRequest
+		if err = decodeArguments(arguments, &req); err == nil {
+			structured, err = ac.createTodoWith(c, token, &req)
+		}
+	case endpoint.AgentToolCompleteTodo:
+		var req endpoint.AgentTodoCompleteRequest
+		if err = decodeArguments(arguments, &req); err == nil {
+			structured, err = ac.completeTodoWith(c, token, &req)
+		}
+	default:
+		return nil, &endpoint.MCPError{Code: endpoint.MCPInvalidParams, Message: "Unknown tool: " + params.Name}
+	}
+
+	switch fieldErrors := validation.FieldErrors(err); {
+	case err == nil:
+	case fieldErrors != nil:
+		messages := make([]string, 0, len(fieldErrors))
+		for _, fieldErr := range fieldErrors {
+			messages = append(messages, fieldErr.Message)
+		}
+		return endpoint.NewMCPToolError(strings.Join(messages, "; ")), nil
+	case middleware.ErrorStatus(err) < http.StatusInternalServerError:
+		return endpoint.NewMCPToolError(err.Error()), nil
+	default:
+		log.Err(err).Str("tool", params.Name).Msg("error calling agent tool")
+		return nil, &endpoint.MCPError{Code: endpoint.MCPInternalError, Message: "Internal error"}
+	}
+
+	result, err := endpoint.NewMCPToolResult(structured)
+	if err != nil {
+		log.Err(err).Str("tool", params.Name).Msg("error encoding agent tool result")
+		return nil, &endpoint.MCPError{Code: endpoint.MCPInternalError, Message: "Internal error"}
+	}
+
+	return result, nil
+}
+
+func decodeArguments(arguments json.RawMessage, req any) error {
+	if err := json.Unmarshal(arguments, req); err != nil {
+		return domain.NewError(domain.KindValidation, "invalid arguments: "+err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// IPAllowlistBypassPaths are the routes that stay reachable from outside the allowlist.
+var IPAllowlistBypassPaths = []string{
+	"/api/" + V1 + "/workspace/ip-allowlist/bypass",
+	"/api/" + V1 + "/workspace/ip-allowlist/bypass/confirm",
+}
+
+type IPAllowlistController struct {
+	*BaseController
+	IPAllowlistService service.IPAllowlistService
+}
+
+func NewIPAllowlistController(base *BaseController, ipAllowlistService service.IPAllowlistService) *IPAllowlistController {
+	return &IPAllowlistController{
+		BaseController:     base,
+		IPAllowlistService: ipAllowlistService,
+	}
+}
+
+func (ac *IPAllowlistController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/workspace/ip-allowlist", ac.ranges)
+	e.POST("/"+V1+"/workspace/ip-allowlist", ac.addRange)
+	e.DELETE("/"+V1+"/workspace/ip-allowlist/:uuid", ac.removeRange)
+	e.POST("/"+V1+"/workspace/ip-allowlist/bypass", ac.requestBypass)
+	e.POST("/"+V1+"/workspace/ip-allowlist/bypass/confirm", ac.confirmBypass)
+}
+
+func (ac *IPAllowlistController) ranges(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	ranges, err := ac.IPAllowlistService.Ranges(c.Request().Context(), claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewIPRanges(ranges),
+	})
+}
+
+func (ac *IPAllowlistController) addRange(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.IPRangeCreateRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	ipRange, err := ac.IPAllowlistService.AddRange(c.Request().Context(), claims.UserID, req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, echo.Map{
+		"data": endpoint.NewIPRange(ipRange),
+	})
+}
+
+func (ac *IPAllowlistController) removeRange(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	err := ac.IPAllowlistService.RemoveRange(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (ac *IPAllowlistController) requestBypass(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	if err := ac.IPAllowlistService.RequestBypass(c.Request().Context(), claims.UserID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusAccepted)
+}
+
+func (ac *IPAllowlistController) confirmBypass(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.IPAllowlistBypassRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	suspendedUntil, err := ac.IPAllowlistService.ConfirmBypass(c.Request().Context(), claims.UserID, req.Token)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": &endpoint.IPAllowlistBypass{SuspendedUntil: suspendedUntil},
+	})
+}
@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// multipartOverhead leaves room for the multipart headers on top of the maximum file size.
+const multipartOverhead = 1 << 20
+
+type AttachmentController struct {
+	*BaseController
+	AttachmentService service.AttachmentService
+}
+
+func NewAttachmentController(base *BaseController, attachmentService service.AttachmentService) *AttachmentController {
+	return &AttachmentController{
+		BaseController:    base,
+		AttachmentService: attachmentService,
+	}
+}
+
+func (ac *AttachmentController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/todos/:uuid/attachments", ac.all)
+	e.POST("/"+V1+"/todos/:uuid/attachments", ac.upload)
+	e.GET("/"+V1+"/todos/:uuid/attachments/:attachmentUUID/download", ac.download)
+	e.DELETE("/"+V1+"/todos/:uuid/attachments/:attachmentUUID", ac.delete)
+}
+
+func (ac *AttachmentController) all(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	attachments, err := ac.AttachmentService.All(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewAttachments(attachments),
+	})
+}
+
+// upload stores the file of the multipart form field "file".
+func (ac *AttachmentController) upload(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	maxSize := ac.Config.GetAttachmentMaxSize()
+	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, maxSize+multipartOverhead)
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return domain.ErrAttachmentTooLarge
+		}
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return fmt.Errorf("error opening uploaded file: %w", err)
+	}
+	defer file.Close()
+
+	contentType := header.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = echo.MIMEOctetStream
+	}
+
+	attachment, err := ac.AttachmentService.Upload(c.Request().Context(), claims.UserID, c.Param("uuid"), &domain.AttachmentUpload{
+		Filename:    filepath.Base(header.Filename),
+		ContentType: contentType,
+		Size:        header.Size,
+		Body:        file,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, echo.Map{
+		"data": endpoint.NewAttachment(attachment),
+	})
+}
+
+func (ac *AttachmentController) download(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	download, err := ac.AttachmentService.Download(c.Request().Context(), claims.UserID, c.Param("uuid"), c.Param("attachmentUUID"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewAttachmentDownload(download),
+	})
+}
+
+func (ac *AttachmentController) delete(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	err := ac.AttachmentService.Delete(c.Request().Context(), claims.UserID, c.Param("uuid"), c.Param("attachmentUUID"))
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/pagination"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AuditController struct {
+	*BaseController
+	AuditService service.AuditService
+	AdminService service.AdminService
+}
+
+func NewAuditController(base *BaseController, auditService service.AuditService, adminService service.AdminService) *AuditController {
+	return &AuditController{
+		BaseController: base,
+		AuditService:   auditService,
+		AdminService:   adminService,
+	}
+}
+
+func (ac *AuditController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/audit", ac.all)
+	e.GET("/"+V1+"/audit/verify", ac.verify)
+}
+
+// all returns the audit log of the signed in user, admins can read the log of any user
+// with ?user=<uuid>.
+func (ac *AuditController) all(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	page, err := pageParams(c)
+	if err != nil {
+		return err
+	}
+
+	var entries []*domain.AuditEntry
+	var next *pagination.Cursor
+	if userUUID := c.QueryParam("user"); userUUID != "" && userUUID != claims.UUID {
+		if err = ac.AdminService.Authorize(c.Request().Context(), claims.UserID, domain.AdminActionReadAuditLog); err != nil {
+			return err
+		}
+		entries, next, err = ac.AuditService.ByUser(c.Request().Context(), userUUID, page)
+	} else {
+		entries, next, err = ac.AuditService.All(c.Request().Context(), claims.UserID, page)
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data":        endpoint.NewAuditEntries(entries),
+		"next_cursor": next.Encode(),
+	})
+}
+
+// verify checks the hash chain of the audit log, it is only meant for admins. A broken chain is
+// reported in the body, the request itself succeeds.
+func (ac *AuditController) verify(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	if err := ac.AdminService.Authorize(c.Request().Context(), claims.UserID, domain.AdminActionVerifyAuditLog); err != nil {
+		return err
+	}
+
+	verification, err := ac.AuditService.Verify(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewAuditVerification(verification),
+	})
+}
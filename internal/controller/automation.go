@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxAutomationRequestSize leaves room for the base64 encoding of the largest module.
+const maxAutomationRequestSize = domain.MaxAutomationModuleSize*4/3 + 64<<10
+
+type AutomationController struct {
+	*BaseController
+	AutomationService service.AutomationService
+}
+
+func NewAutomationController(base *BaseController, automationService service.AutomationService) *AutomationController {
+	return &AutomationController{
+		BaseController:    base,
+		AutomationService: automationService,
+	}
+}
+
+func (ac *AutomationController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/automations", ac.all)
+	e.POST("/"+V1+"/automations", ac.create)
+	e.GET("/"+V1+"/automations/:uuid", ac.byUUID)
+	e.DELETE("/"+V1+"/automations/:uuid", ac.delete)
+	e.GET("/"+V1+"/automations/:uuid/runs", ac.runs)
+}
+
+func (ac *AutomationController) all(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	automations, err := ac.AutomationService.All(c.Request().Context(), claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewAutomations(automations)})
+}
+
+func (ac *AutomationController) create(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, maxAutomationRequestSize)
+
+	var req endpoint.AutomationRequest
+	if err := c.Bind(&req); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return domain.ErrAutomationModuleTooLarge
+		}
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	automation, err := ac.AutomationService.Create(c.Request().Context(), claims.UserID, req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, echo.Map{"data": endpoint.NewAutomation(automation)})
+}
+
+func (ac *AutomationController) byUUID(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	automation, err := ac.AutomationService.ByUUID(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewAutomation(automation)})
+}
+
+func (ac *AutomationController) delete(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	err := ac.AutomationService.Delete(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (ac *AutomationController) runs(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	page, err := pageParams(c)
+	if err != nil {
+		return err
+	}
+
+	runs, next, err := ac.AutomationService.Runs(c.Request().Context(), claims.UserID, c.Param("uuid"), page)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data":        endpoint.NewAutomationRuns(runs),
+		"next_cursor": next.Encode(),
+	})
+}
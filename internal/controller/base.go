@@ -1,8 +1,17 @@
 package controller
 
 import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
 	"github.com/meowmix1337/go-core/cache"
+	"github.com/meowmix1337/the_recipe_book/internal/api/middleware"
 	"github.com/meowmix1337/the_recipe_book/internal/config"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/pagination"
+	"github.com/rs/zerolog/log"
 )
 
 const (
@@ -10,13 +19,64 @@ const (
 )
 
 type BaseController struct {
-	Config config.Config
-	Cache  cache.Cache
+	Config        config.Config
+	Cache         cache.Cache
+	CanaryMetrics *middleware.CanaryMetrics
 }
 
 func NewBaseController(cfg config.Config, cache cache.Cache) *BaseController {
 	return &BaseController{
-		Config: cfg,
-		Cache:  cache,
+		Config:        cfg,
+		Cache:         cache,
+		CanaryMetrics: middleware.NewCanaryMetrics(),
+	}
+}
+
+// Canary wraps a stable handler and its candidate replacement so a cohort of users can be
+// routed to the candidate while the rollout is monitored.
+func (bc *BaseController) Canary(route string, stable, candidate echo.HandlerFunc) echo.HandlerFunc {
+	cfg := middleware.CanaryConfig{
+		Percentage: bc.Config.GetCanaryPercentage(),
+		Header:     bc.Config.GetCanaryHeader(),
+	}
+
+	return middleware.Canary(route, cfg, bc.CanaryMetrics, stable, candidate)
+}
+
+// userClaims returns the JWT claims set on the context by the JWT middleware.
+func userClaims(c echo.Context) (*domain.JWTCustomClaims, bool) {
+	claims, ok := c.Get("claims").(*domain.JWTCustomClaims)
+	if !ok {
+		log.Error().Msg("Failed to assert claims")
 	}
+
+	return claims, ok
+}
+
+// pageParams parses the cursor and limit query parameters of list endpoints.
+func pageParams(c echo.Context) (*pagination.Page, error) {
+	limit := 0
+	if value := c.QueryParam("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil {
+			return nil, fmt.Errorf("%w: %s", pagination.ErrInvalidLimit, value)
+		}
+	}
+
+	return pagination.NewPage(c.QueryParam("cursor"), limit)
+}
+
+// timeParam parses an optional RFC 3339 query parameter, a missing parameter is the zero time.
+func timeParam(c echo.Context, name string) (time.Time, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, domain.NewError(domain.KindValidation, fmt.Sprintf("invalid %s: %s", name, value))
+	}
+
+	return t.UTC(), nil
 }
@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type BoardController struct {
+	*BaseController
+	BoardService service.BoardService
+}
+
+func NewBoardController(base *BaseController, boardService service.BoardService) *BoardController {
+	return &BoardController{
+		BaseController: base,
+		BoardService:   boardService,
+	}
+}
+
+func (bc *BoardController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/lists/:uuid/board", bc.board)
+	e.GET("/"+V1+"/lists/:uuid/statuses", bc.statuses)
+	e.POST("/"+V1+"/lists/:uuid/statuses", bc.createStatus)
+	e.PUT("/"+V1+"/lists/:uuid/statuses/:key", bc.updateStatus)
+	e.DELETE("/"+V1+"/lists/:uuid/statuses/:key", bc.deleteStatus)
+}
+
+// board returns the todos of the list in a column per status, todos are moved between the
+// columns with PATCH /todos/:uuid/move.
+func (bc *BoardController) board(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	board, err := bc.BoardService.Board(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewBoard(board)})
+}
+
+func (bc *BoardController) statuses(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	statuses, err := bc.BoardService.Statuses(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewListStatuses(statuses)})
+}
+
+func (bc *BoardController) createStatus(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.ListStatusRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	status, err := bc.BoardService.CreateStatus(c.Request().Context(), claims.UserID, c.Param("uuid"), req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, echo.Map{"data": endpoint.NewListStatus(status)})
+}
+
+func (bc *BoardController) updateStatus(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.ListStatusUpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	status, err := bc.BoardService.UpdateStatus(
+		c.Request().Context(), claims.UserID, c.Param("uuid"), c.Param("key"), req.ToDomain(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewListStatus(status)})
+}
+
+func (bc *BoardController) deleteStatus(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	err := bc.BoardService.DeleteStatus(c.Request().Context(), claims.UserID, c.Param("uuid"), c.Param("key"))
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type BreakdownController struct {
+	*BaseController
+	BreakdownService service.BreakdownService
+}
+
+func NewBreakdownController(base *BaseController, breakdownService service.BreakdownService) *BreakdownController {
+	return &BreakdownController{
+		BaseController:   base,
+		BreakdownService: breakdownService,
+	}
+}
+
+// AddRoutes registers the breakdown routes, rateLimit only applies to proposals since they are
+// the requests that reach the language model.
+func (bc *BreakdownController) AddRoutes(e *echo.Group, rateLimit echo.MiddlewareFunc) {
+	e.POST("/"+V1+"/todos/:uuid/breakdown", bc.propose, rateLimit)
+	e.POST("/"+V1+"/todos/:uuid/breakdown/confirm", bc.confirm)
+}
+
+// propose asks the language model for steps of the todo, nothing is created. The user picks and
+// edits the steps before confirming them.
+func (bc *BreakdownController) propose(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	breakdown, err := bc.BreakdownService.Propose(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+	setTodoETag(c, breakdown.Todo)
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewTodoBreakdown(breakdown),
+	})
+}
+
+// confirm creates the confirmed steps as todos, the todo that was broken down is kept as is.
+func (bc *BreakdownController) confirm(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.TodoBreakdownRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	split := req.ToDomain()
+	if ifMatch := c.Request().Header.Get("If-Match"); ifMatch != "" {
+		version, err := parseTodoETag(ifMatch)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid If-Match header")
+		}
+		split.Version = &version
+	}
+
+	result, err := bc.BreakdownService.Confirm(c.Request().Context(), claims.UserID, c.Param("uuid"), split)
+	if err != nil {
+		return err
+	}
+	setTodoETag(c, result.Todo)
+
+	return c.JSON(http.StatusCreated, echo.Map{
+		"data": endpoint.NewTodoSplit(result),
+	})
+}
@@ -0,0 +1,141 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/api/middleware"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+	"github.com/rs/zerolog/log"
+
+	"github.com/labstack/echo/v4"
+)
+
+const calendarContentType = "text/calendar; charset=utf-8"
+
+type CalendarController struct {
+	*BaseController
+	CalendarService service.CalendarService
+}
+
+func NewCalendarController(base *BaseController, calendarService service.CalendarService) *CalendarController {
+	return &CalendarController{
+		BaseController:  base,
+		CalendarService: calendarService,
+	}
+}
+
+func (cc *CalendarController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/todos/calendar.ics", cc.feed)
+
+	e.GET("/"+V1+"/calendar-tokens", cc.tokens)
+	e.POST("/"+V1+"/calendar-tokens", cc.createToken, middleware.NoIdempotentStore)
+	e.DELETE("/"+V1+"/calendar-tokens/:uuid", cc.revokeToken)
+}
+
+// AddCalendarRoutes registers the subscription feed, calendar apps authenticate it with a
+// calendar token in the URL.
+func (cc *CalendarController) AddCalendarRoutes(e *echo.Echo) {
+	calendar := e.Group("/calendar")
+	calendar.Use(middleware.CalendarTokenMiddleware(cc.CalendarService.Authenticate))
+
+	calendar.GET("/"+V1+"/todos.ics", cc.subscription)
+}
+
+func (cc *CalendarController) feed(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	component, err := domain.ParseCalendarComponent(c.QueryParam("component"))
+	if err != nil {
+		return err
+	}
+
+	feed, err := cc.CalendarService.Feed(c.Request().Context(), claims.UserID, component)
+	if err != nil {
+		return err
+	}
+
+	return c.Blob(http.StatusOK, calendarContentType, feed)
+}
+
+func (cc *CalendarController) subscription(c echo.Context) error {
+	token, ok := c.Get("calendar_token").(*domain.CalendarToken)
+	if !ok {
+		log.Error().Msg("Failed to assert calendar token")
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	component, err := domain.ParseCalendarComponent(c.QueryParam("component"))
+	if err != nil {
+		return err
+	}
+
+	feed, err := cc.CalendarService.Feed(c.Request().Context(), token.UserID, component)
+	if err != nil {
+		return err
+	}
+
+	// the URL carries the token, keep the feed out of shared caches
+	c.Response().Header().Set("Cache-Control", "private, no-cache")
+	return c.Blob(http.StatusOK, calendarContentType, feed)
+}
+
+func (cc *CalendarController) tokens(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	tokens, err := cc.CalendarService.Tokens(c.Request().Context(), claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewCalendarTokens(tokens),
+	})
+}
+
+func (cc *CalendarController) createToken(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.CalendarTokenRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	token, err := cc.CalendarService.CreateToken(c.Request().Context(), claims.UserID, req.Name)
+	if err != nil {
+		return err
+	}
+
+	feedURL := cc.Config.GetAPIURL() + "/calendar/" + V1 + "/todos.ics"
+	return c.JSON(http.StatusCreated, echo.Map{
+		"data": endpoint.NewCalendarToken(token, feedURL),
+	})
+}
+
+func (cc *CalendarController) revokeToken(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	err := cc.CalendarService.RevokeToken(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+	"github.com/rs/zerolog/log"
+
+	"github.com/labstack/echo/v4"
+)
+
+type CanaryController struct {
+	*BaseController
+	AdminService service.AdminService
+}
+
+func NewCanaryController(base *BaseController, adminService service.AdminService) *CanaryController {
+	return &CanaryController{
+		BaseController: base,
+		AdminService:   adminService,
+	}
+}
+
+func (cc *CanaryController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/canary/metrics", cc.metrics)
+}
+
+func (cc *CanaryController) metrics(c echo.Context) error {
+	claims, ok := c.Get("claims").(*domain.JWTCustomClaims)
+	if !ok {
+		log.Error().Msg("Failed to assert claims")
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	if err := cc.AdminService.Authorize(c.Request().Context(), claims.UserID, domain.AdminActionReadCanaryMetrics); err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": cc.CanaryMetrics.Snapshot(),
+	})
+}
@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ChartController struct {
+	*BaseController
+	ChartService service.ChartService
+}
+
+func NewChartController(base *BaseController, chartService service.ChartService) *ChartController {
+	return &ChartController{
+		BaseController: base,
+		ChartService:   chartService,
+	}
+}
+
+func (cc *ChartController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/charts/burndown", cc.burndown)
+	e.GET("/"+V1+"/charts/completion", cc.completion)
+}
+
+func (cc *ChartController) burndown(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	query, err := chartQuery(c)
+	if err != nil {
+		return err
+	}
+
+	points, err := cc.ChartService.Burndown(c.Request().Context(), claims.UserID, query)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewBurndownChart(query.Granularity, points)})
+}
+
+func (cc *ChartController) completion(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	query, err := chartQuery(c)
+	if err != nil {
+		return err
+	}
+
+	points, err := cc.ChartService.CompletionTrend(c.Request().Context(), claims.UserID, query)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewTrendChart(query.Granularity, points)})
+}
+
+// chartQuery reads ?list=, ?scope=workspace, ?from=, ?to= and ?granularity=.
+func chartQuery(c echo.Context) (*domain.ChartQuery, error) {
+	granularity, err := domain.ParseChartGranularity(c.QueryParam("granularity"))
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := timeParam(c, "from")
+	if err != nil {
+		return nil, err
+	}
+	to, err := timeParam(c, "to")
+	if err != nil {
+		return nil, err
+	}
+
+	query := &domain.ChartQuery{
+		ListUUID:    c.QueryParam("list"),
+		From:        from,
+		To:          to,
+		Granularity: granularity,
+	}
+	switch c.QueryParam("scope") {
+	case "":
+	case "workspace":
+		query.Workspace = true
+	default:
+		return nil, domain.NewError(domain.KindValidation, "scope must be workspace or empty")
+	}
+
+	return query, nil
+}
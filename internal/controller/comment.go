@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type CommentController struct {
+	*BaseController
+	CommentService service.CommentService
+}
+
+func NewCommentController(base *BaseController, commentService service.CommentService) *CommentController {
+	return &CommentController{
+		BaseController: base,
+		CommentService: commentService,
+	}
+}
+
+func (cc *CommentController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/todos/:uuid/comments", cc.all)
+	e.POST("/"+V1+"/todos/:uuid/comments", cc.create)
+	e.PATCH("/"+V1+"/todos/:uuid/comments/:commentUUID", cc.update)
+	e.DELETE("/"+V1+"/todos/:uuid/comments/:commentUUID", cc.delete)
+}
+
+func (cc *CommentController) all(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	page, err := pageParams(c)
+	if err != nil {
+		return err
+	}
+
+	comments, next, err := cc.CommentService.All(c.Request().Context(), claims.UserID, c.Param("uuid"), page)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data":        endpoint.NewComments(comments),
+		"next_cursor": next.Encode(),
+	})
+}
+
+func (cc *CommentController) create(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.CommentRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	comment, err := cc.CommentService.Create(c.Request().Context(), claims.UserID, c.Param("uuid"), req.Body)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, echo.Map{
+		"data": endpoint.NewComment(comment),
+	})
+}
+
+func (cc *CommentController) update(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.CommentRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	comment, err := cc.CommentService.Update(c.Request().Context(), claims.UserID, c.Param("uuid"), c.Param("commentUUID"), req.Body)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewComment(comment),
+	})
+}
+
+func (cc *CommentController) delete(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	err := cc.CommentService.Delete(c.Request().Context(), claims.UserID, c.Param("uuid"), c.Param("commentUUID"))
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type DigestController struct {
+	*BaseController
+	DigestService service.DigestService
+}
+
+func NewDigestController(base *BaseController, digestService service.DigestService) *DigestController {
+	return &DigestController{
+		BaseController: base,
+		DigestService:  digestService,
+	}
+}
+
+func (dc *DigestController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/digest/settings", dc.settings)
+	e.PUT("/"+V1+"/digest/settings", dc.updateSettings)
+}
+
+func (dc *DigestController) settings(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	settings, err := dc.DigestService.Settings(c.Request().Context(), claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewDigestSettings(settings)})
+}
+
+func (dc *DigestController) updateSettings(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.DigestSettingsRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	settings, err := dc.DigestService.UpdateSettings(c.Request().Context(), claims.UserID, req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewDigestSettings(settings)})
+}
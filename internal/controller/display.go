@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/meowmix1337/the_recipe_book/internal/api/middleware"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+	"github.com/rs/zerolog/log"
+
+	"github.com/labstack/echo/v4"
+)
+
+type DisplayController struct {
+	*BaseController
+	DisplayService service.DisplayService
+}
+
+func NewDisplayController(base *BaseController, displayService service.DisplayService) *DisplayController {
+	return &DisplayController{
+		BaseController: base,
+		DisplayService: displayService,
+	}
+}
+
+func (dc *DisplayController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/display-tokens", dc.all)
+	e.POST("/"+V1+"/display-tokens", dc.create, middleware.NoIdempotentStore)
+	e.DELETE("/"+V1+"/display-tokens/:uuid", dc.revoke)
+}
+
+// AddDisplayRoutes registers the read-only routes used by displays, they are authenticated
+// with a display token instead of a user session.
+func (dc *DisplayController) AddDisplayRoutes(e *echo.Echo) {
+	display := e.Group("/display")
+	display.Use(middleware.DisplayTokenMiddleware(dc.DisplayService.Authenticate))
+
+	display.GET("/"+V1+"/board", dc.board)
+}
+
+func (dc *DisplayController) all(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	tokens, err := dc.DisplayService.All(c.Request().Context(), claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewDisplayTokens(tokens),
+	})
+}
+
+func (dc *DisplayController) create(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.DisplayTokenRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	token, err := dc.DisplayService.Create(c.Request().Context(), claims.UserID, req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	// the token is only returned once, afterwards only its hash is stored
+	return c.JSON(http.StatusCreated, echo.Map{
+		"data": endpoint.NewDisplayToken(token),
+	})
+}
+
+func (dc *DisplayController) revoke(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	err := dc.DisplayService.Revoke(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// board returns the lists of the display token. Displays poll it and send the last version
+// in If-None-Match, an unchanged board is answered with 304 Not Modified.
+func (dc *DisplayController) board(c echo.Context) error {
+	token, ok := c.Get("display_token").(*domain.DisplayToken)
+	if !ok {
+		log.Error().Msg("Failed to assert display token")
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	board, err := dc.DisplayService.Board(c.Request().Context(), token)
+	if err != nil {
+		return err
+	}
+
+	etag := strconv.Quote(board.Version)
+	c.Response().Header().Set("ETag", etag)
+	c.Response().Header().Set("Cache-Control", "no-cache")
+	if c.Request().Header.Get("If-None-Match") == etag {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewDisplayBoard(board),
+	})
+}
@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/meowmix1337/the_recipe_book/internal/openapi"
+)
+
+// DocsController serves the OpenAPI document of the API and a Swagger UI to browse it, see
+// go generate ./internal/openapi.
+type DocsController struct {
+	*BaseController
+}
+
+func NewDocsController(base *BaseController) *DocsController {
+	return &DocsController{
+		BaseController: base,
+	}
+}
+
+func (dc *DocsController) AddDocsRoutes(e *echo.Echo) {
+	e.GET("/openapi.json", dc.spec)
+	e.GET("/docs", dc.docs)
+}
+
+func (dc *DocsController) spec(c echo.Context) error {
+	return c.Blob(http.StatusOK, echo.MIMEApplicationJSON, openapi.Spec)
+}
+
+func (dc *DocsController) docs(c echo.Context) error {
+	return c.HTMLBlob(http.StatusOK, openapi.Docs)
+}
@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type EncryptionController struct {
+	*BaseController
+	EncryptionService service.EncryptionService
+}
+
+func NewEncryptionController(base *BaseController, encryptionService service.EncryptionService) *EncryptionController {
+	return &EncryptionController{
+		BaseController:    base,
+		EncryptionService: encryptionService,
+	}
+}
+
+func (ec *EncryptionController) AddRoutes(e *echo.Group) {
+	e.PUT("/"+V1+"/users/me/public-key", ec.setPublicKey)
+	e.GET("/"+V1+"/lists/:uuid/public-keys", ec.publicKeys)
+	e.POST("/"+V1+"/lists/:uuid/encryption", ec.encrypt)
+	e.GET("/"+V1+"/lists/:uuid/keys", ec.keyring)
+	e.PUT("/"+V1+"/lists/:uuid/keys", ec.addKeys)
+	e.POST("/"+V1+"/lists/:uuid/keys/rotate", ec.rotate)
+}
+
+func (ec *EncryptionController) setPublicKey(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.PublicKeyRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	key, err := ec.EncryptionService.SetPublicKey(c.Request().Context(), claims.UserID, req.PublicKey)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewPublicKey(key)})
+}
+
+// publicKeys returns the public keys of the owner and the members of the list to wrap its key with.
+func (ec *EncryptionController) publicKeys(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	keys, err := ec.EncryptionService.PublicKeys(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewPublicKeys(keys)})
+}
+
+func (ec *EncryptionController) encrypt(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.ListEncryptRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	list, err := ec.EncryptionService.Encrypt(c.Request().Context(), claims.UserID, c.Param("uuid"), req.WrappedKey)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewList(list)})
+}
+
+func (ec *EncryptionController) keyring(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	keyring, err := ec.EncryptionService.Keyring(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewListKeyring(keyring)})
+}
+
+func (ec *EncryptionController) addKeys(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.ListKeysRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	keyring, err := ec.EncryptionService.AddKeys(
+		c.Request().Context(), claims.UserID, c.Param("uuid"), req.KeyVersion, req.ToDomain(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewListKeyring(keyring)})
+}
+
+func (ec *EncryptionController) rotate(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.ListKeyRotateRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	keyring, err := ec.EncryptionService.RotateKey(
+		c.Request().Context(), claims.UserID, c.Param("uuid"), req.FromVersion, req.ToDomain(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewListKeyring(keyring)})
+}
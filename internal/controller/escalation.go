@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type EscalationController struct {
+	*BaseController
+	EscalationService service.EscalationService
+}
+
+func NewEscalationController(base *BaseController, escalationService service.EscalationService) *EscalationController {
+	return &EscalationController{
+		BaseController:    base,
+		EscalationService: escalationService,
+	}
+}
+
+func (ec *EscalationController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/lists/:uuid/escalations", ec.rules)
+	e.POST("/"+V1+"/lists/:uuid/escalations", ec.create)
+	e.GET("/"+V1+"/lists/:uuid/escalations/log", ec.log)
+	e.PUT("/"+V1+"/lists/:uuid/escalations/:ruleUUID", ec.update)
+	e.DELETE("/"+V1+"/lists/:uuid/escalations/:ruleUUID", ec.delete)
+}
+
+func (ec *EscalationController) rules(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	rules, err := ec.EscalationService.Rules(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewEscalationRules(rules)})
+}
+
+func (ec *EscalationController) create(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.EscalationRuleRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	rule, err := ec.EscalationService.CreateRule(c.Request().Context(), claims.UserID, c.Param("uuid"), req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, echo.Map{"data": endpoint.NewEscalationRule(rule)})
+}
+
+func (ec *EscalationController) update(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.EscalationRuleRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	rule, err := ec.EscalationService.UpdateRule(
+		c.Request().Context(), claims.UserID, c.Param("uuid"), c.Param("ruleUUID"), req.ToDomain(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewEscalationRule(rule)})
+}
+
+func (ec *EscalationController) delete(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	err := ec.EscalationService.DeleteRule(c.Request().Context(), claims.UserID, c.Param("uuid"), c.Param("ruleUUID"))
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// log returns the activity log of the escalations of the list, newest first.
+func (ec *EscalationController) log(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	page, err := pageParams(c)
+	if err != nil {
+		return err
+	}
+
+	escalations, next, err := ec.EscalationService.Log(c.Request().Context(), claims.UserID, c.Param("uuid"), page)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data":        endpoint.NewEscalations(escalations),
+		"next_cursor": next.Encode(),
+	})
+}
@@ -0,0 +1,196 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/api/middleware"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	// eventHeartbeat keeps idle streams from being closed by proxies.
+	eventHeartbeat = 25 * time.Second
+	// roomMaxMessage bounds the messages clients send to a room, they are discarded anyway.
+	roomMaxMessage = 4 << 10
+)
+
+const (
+	// EventsRoute is the Server-Sent Events stream of the user.
+	EventsRoute = "/" + V1 + "/events"
+	// RoomRoute is the WebSocket of a list room.
+	RoomRoute = "/" + V1 + "/lists/:uuid/ws"
+	// RoomTicketRoute issues the tickets that open the WebSocket of a list room.
+	RoomTicketRoute = RoomRoute + "/tickets"
+)
+
+type EventController struct {
+	*BaseController
+	EventService service.EventService
+	// Closing ends the open streams when the server shuts down, the server would otherwise
+	// wait for them until the shutdown timeout.
+	Closing <-chan struct{}
+}
+
+func NewEventController(base *BaseController, eventService service.EventService, closing <-chan struct{}) *EventController {
+	return &EventController{
+		BaseController: base,
+		EventService:   eventService,
+		Closing:        closing,
+	}
+}
+
+func (ec *EventController) AddRoutes(e *echo.Group) {
+	e.GET(EventsRoute, ec.stream)
+	e.GET(RoomRoute, ec.room)
+	e.POST(RoomTicketRoute, ec.roomTicket, middleware.NoIdempotentStore)
+}
+
+// stream sends the todo events of the user as Server-Sent Events. The stream ends when the
+// access token expires, clients reconnect with a fresh token.
+func (ec *EventController) stream(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	ctx := c.Request().Context()
+	if claims.ExpiresAt != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, claims.ExpiresAt.Time)
+		defer cancel()
+	}
+
+	events, err := ec.EventService.Subscribe(ctx, claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	// nginx buffers responses by default, which would hold events back
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	heartbeat := time.NewTicker(eventHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ec.Closing:
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if _, err = fmt.Fprintf(res, "data: %s\n\n", event); err != nil {
+				return nil
+			}
+		case <-heartbeat.C:
+			if _, err = fmt.Fprint(res, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+		}
+		res.Flush()
+	}
+}
+
+// roomTicket returns a single-use ticket to open the WebSocket of a list with, it is only valid
+// for half a minute.
+func (ec *EventController) roomTicket(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	ticket, err := ec.EventService.RoomTicket(c.Request().Context(), claims, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, echo.Map{
+		"data": endpoint.NewRoomTicket(ticket),
+	})
+}
+
+// room joins the WebSocket of a list, everyone connected to a list owned by or shared with them
+// receives the todo events of the list. Browsers can't set headers on the handshake, so it may be
+// authenticated with a ticket from roomTicket in the ticket query parameter. The connection is
+// closed when the access token the ticket was issued with expires.
+func (ec *EventController) room(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	// hijacked connections don't cancel the request context, the reader cancels it instead
+	ctx, cancel := context.WithCancel(c.Request().Context())
+	defer cancel()
+	if claims.ExpiresAt != nil {
+		var cancelDeadline context.CancelFunc
+		ctx, cancelDeadline = context.WithDeadline(ctx, claims.ExpiresAt.Time)
+		defer cancelDeadline()
+	}
+
+	events, err := ec.EventService.SubscribeList(ctx, claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	// the handshake is authenticated with a ticket or token rather than a cookie, so other sites
+	// can't open a room on behalf of the user and the origin is not checked
+	server := websocket.Server{
+		Handler: func(ws *websocket.Conn) {
+			defer ws.Close()
+			ws.MaxPayloadBytes = roomMaxMessage
+
+			// collaborators edit through the API, messages are only read to notice the client
+			// going away
+			go func() {
+				defer cancel()
+				var message []byte
+				for websocket.Message.Receive(ws, &message) == nil {
+				}
+			}()
+
+			heartbeat := time.NewTicker(eventHeartbeat)
+			defer heartbeat.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ec.Closing:
+					return
+				case event, ok := <-events:
+					if !ok {
+						return
+					}
+					if err := websocket.Message.Send(ws, string(event)); err != nil {
+						return
+					}
+				case <-heartbeat.C:
+					ws.PayloadType = websocket.PingFrame
+					if _, err := ws.Write(nil); err != nil {
+						return
+					}
+				}
+			}
+		},
+	}
+	server.ServeHTTP(c.Response(), c.Request())
+
+	return nil
+}
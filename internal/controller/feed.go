@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/api/middleware"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+	"github.com/rs/zerolog/log"
+
+	"github.com/labstack/echo/v4"
+)
+
+const atomContentType = "application/atom+xml; charset=utf-8"
+
+type FeedController struct {
+	*BaseController
+	FeedService service.FeedService
+}
+
+func NewFeedController(base *BaseController, feedService service.FeedService) *FeedController {
+	return &FeedController{
+		BaseController: base,
+		FeedService:    feedService,
+	}
+}
+
+func (fc *FeedController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/lists/:uuid/feed-tokens", fc.tokens)
+	e.POST("/"+V1+"/lists/:uuid/feed-tokens", fc.createToken, middleware.NoIdempotentStore)
+	e.DELETE("/"+V1+"/feed-tokens/:uuid", fc.revokeToken)
+}
+
+// AddFeedRoutes registers the list feed, feed readers authenticate it with a feed token in the
+// URL. The token decides which list is read.
+func (fc *FeedController) AddFeedRoutes(e *echo.Echo) {
+	feed := e.Group("/feed")
+	feed.Use(middleware.FeedTokenMiddleware(fc.FeedService.Authenticate))
+
+	feed.GET("/"+V1+"/list.atom", fc.feed)
+}
+
+func (fc *FeedController) feedURL() string {
+	return fc.Config.GetAPIURL() + "/feed/" + V1 + "/list.atom"
+}
+
+func (fc *FeedController) feed(c echo.Context) error {
+	token, ok := c.Get("feed_token").(*domain.FeedToken)
+	if !ok {
+		log.Error().Msg("Failed to assert feed token")
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	feed, err := fc.FeedService.Feed(c.Request().Context(), token, fc.feedURL())
+	if err != nil {
+		return err
+	}
+
+	// the URL carries the token, keep the feed out of shared caches
+	c.Response().Header().Set("Cache-Control", "private, no-cache")
+	return c.Blob(http.StatusOK, atomContentType, feed)
+}
+
+func (fc *FeedController) tokens(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	tokens, err := fc.FeedService.Tokens(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewFeedTokens(tokens),
+	})
+}
+
+func (fc *FeedController) createToken(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.FeedTokenRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	token, err := fc.FeedService.CreateToken(c.Request().Context(), claims.UserID, c.Param("uuid"), req.Name)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, echo.Map{
+		"data": endpoint.NewFeedToken(token, fc.feedURL()),
+	})
+}
+
+func (fc *FeedController) revokeToken(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	err := fc.FeedService.RevokeToken(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
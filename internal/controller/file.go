@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"github.com/meowmix1337/the_recipe_book/internal/storage"
+
+	"github.com/labstack/echo/v4"
+)
+
+// FileController serves files of storages whose signed URLs point back at the API, the
+// signature in the URL replaces the user session.
+type FileController struct {
+	*BaseController
+	Files storage.FileServer
+}
+
+func NewFileController(base *BaseController, files storage.FileServer) *FileController {
+	return &FileController{
+		BaseController: base,
+		Files:          files,
+	}
+}
+
+func (fc *FileController) AddFileRoutes(e *echo.Echo) {
+	e.GET(storage.FilesPath+"*", fc.file)
+}
+
+func (fc *FileController) file(c echo.Context) error {
+	key := c.Param("*")
+	filename := c.QueryParam("filename")
+
+	expires, err := strconv.ParseInt(c.QueryParam("expires"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusForbidden, storage.ErrInvalidSignature.Error())
+	}
+
+	if err = fc.Files.Verify(key, filename, expires, c.QueryParam("signature")); err != nil {
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	}
+
+	file, err := fc.Files.Open(c.Request().Context(), key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		return fmt.Errorf("error opening file: %w", err)
+	}
+	defer file.Close()
+
+	contentType := mime.TypeByExtension(filepath.Ext(filename))
+	if contentType == "" {
+		contentType = echo.MIMEOctetStream
+	}
+
+	c.Response().Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	return c.Stream(http.StatusOK, contentType, file)
+}
@@ -0,0 +1,175 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type FocusController struct {
+	*BaseController
+	FocusService service.FocusService
+}
+
+func NewFocusController(base *BaseController, focusService service.FocusService) *FocusController {
+	return &FocusController{
+		BaseController: base,
+		FocusService:   focusService,
+	}
+}
+
+func (fc *FocusController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/focus-sessions", fc.all)
+	e.POST("/"+V1+"/focus-sessions", fc.start)
+	e.GET("/"+V1+"/focus-sessions/stats", fc.stats)
+	e.GET("/"+V1+"/focus-sessions/:uuid", fc.byUUID)
+	e.POST("/"+V1+"/focus-sessions/:uuid/interruptions", fc.interrupt)
+	e.POST("/"+V1+"/focus-sessions/:uuid/complete", fc.complete)
+	e.POST("/"+V1+"/focus-sessions/:uuid/abandon", fc.abandon)
+}
+
+func (fc *FocusController) all(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	page, err := pageParams(c)
+	if err != nil {
+		return err
+	}
+
+	filter := &domain.FocusSessionFilter{
+		TodoUUID: c.QueryParam("todo"),
+	}
+
+	sessions, next, err := fc.FocusService.All(c.Request().Context(), claims.UserID, filter, page)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data":        endpoint.NewFocusSessions(sessions),
+		"next_cursor": next.Encode(),
+	})
+}
+
+func (fc *FocusController) start(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.FocusSessionRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	session, err := fc.FocusService.Start(c.Request().Context(), claims.UserID, req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, echo.Map{
+		"data": endpoint.NewFocusSession(session),
+	})
+}
+
+// stats aggregates the time tracked in focus sessions, from and to are RFC 3339 timestamps
+// and default to the last week.
+func (fc *FocusController) stats(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	from, err := timeParam(c, "from")
+	if err != nil {
+		return err
+	}
+	to, err := timeParam(c, "to")
+	if err != nil {
+		return err
+	}
+
+	stats, err := fc.FocusService.Stats(c.Request().Context(), claims.UserID, from, to)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewFocusStats(stats),
+	})
+}
+
+func (fc *FocusController) byUUID(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	session, err := fc.FocusService.ByUUID(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewFocusSession(session),
+	})
+}
+
+func (fc *FocusController) interrupt(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	session, err := fc.FocusService.Interrupt(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewFocusSession(session),
+	})
+}
+
+func (fc *FocusController) complete(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	session, err := fc.FocusService.Complete(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewFocusSession(session),
+	})
+}
+
+func (fc *FocusController) abandon(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	session, err := fc.FocusService.Abandon(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewFocusSession(session),
+	})
+}
@@ -0,0 +1,220 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type HabitController struct {
+	*BaseController
+	HabitService service.HabitService
+}
+
+func NewHabitController(base *BaseController, habitService service.HabitService) *HabitController {
+	return &HabitController{
+		BaseController: base,
+		HabitService:   habitService,
+	}
+}
+
+func (hc *HabitController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/habits", hc.all)
+	e.POST("/"+V1+"/habits", hc.create)
+	e.GET("/"+V1+"/habits/:uuid", hc.byUUID)
+	e.PATCH("/"+V1+"/habits/:uuid", hc.update)
+	e.DELETE("/"+V1+"/habits/:uuid", hc.delete)
+	e.POST("/"+V1+"/habits/:uuid/entries", hc.check)
+	e.DELETE("/"+V1+"/habits/:uuid/entries/:date", hc.uncheck)
+	e.GET("/"+V1+"/habits/:uuid/stats", hc.stats)
+}
+
+func (hc *HabitController) all(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	page, err := pageParams(c)
+	if err != nil {
+		return err
+	}
+
+	habits, next, err := hc.HabitService.All(c.Request().Context(), claims.UserID, page)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data":        endpoint.NewHabits(habits),
+		"next_cursor": next.Encode(),
+	})
+}
+
+func (hc *HabitController) create(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.HabitCreateRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	habit, err := hc.HabitService.Create(c.Request().Context(), claims.UserID, req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, echo.Map{
+		"data": endpoint.NewHabit(habit),
+	})
+}
+
+func (hc *HabitController) byUUID(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	habit, err := hc.HabitService.ByUUID(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewHabit(habit),
+	})
+}
+
+func (hc *HabitController) update(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.HabitUpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	habit, err := hc.HabitService.Update(c.Request().Context(), claims.UserID, c.Param("uuid"), req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewHabit(habit),
+	})
+}
+
+func (hc *HabitController) delete(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	if err := hc.HabitService.Delete(c.Request().Context(), claims.UserID, c.Param("uuid")); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// check records the habit as done or skipped on a day, checking it again replaces the status.
+func (hc *HabitController) check(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.HabitEntryRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	date, err := domain.ParseDate(req.Date)
+	if err != nil {
+		return err
+	}
+
+	status := domain.HabitEntryDone
+	if req.Status != "" {
+		status = domain.HabitEntryStatus(req.Status)
+	}
+
+	entry, err := hc.HabitService.Check(c.Request().Context(), claims.UserID, c.Param("uuid"), date, status)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewHabitEntry(entry),
+	})
+}
+
+func (hc *HabitController) uncheck(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	date, err := time.Parse(domain.DateLayout, c.Param("date"))
+	if err != nil {
+		return domain.ErrInvalidDate
+	}
+
+	if err = hc.HabitService.Uncheck(c.Request().Context(), claims.UserID, c.Param("uuid"), date); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// stats reports the streaks and weekly targets of a habit, from defaults to a few weeks back and
+// today lets clients ahead of UTC pass their own day.
+func (hc *HabitController) stats(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var from time.Time
+	if param := c.QueryParam("from"); param != "" {
+		var err error
+		if from, err = domain.ParseDate(param); err != nil {
+			return err
+		}
+	}
+	today, err := domain.ParseDate(c.QueryParam("today"))
+	if err != nil {
+		return err
+	}
+
+	stats, err := hc.HabitService.Stats(c.Request().Context(), claims.UserID, c.Param("uuid"), from, today)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewHabitStats(stats),
+	})
+}
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/meowmix1337/the_recipe_book/internal/health"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+)
+
+// HealthController serves the probes of the orchestrator. /healthz reports whether the process
+// itself works and should be restarted otherwise, /readyz also checks the dependencies and
+// tells whether the instance should receive traffic.
+type HealthController struct {
+	*BaseController
+	Liveness  *health.Registry
+	Readiness *health.Registry
+}
+
+func NewHealthController(base *BaseController, liveness *health.Registry, readiness *health.Registry) *HealthController {
+	return &HealthController{
+		BaseController: base,
+		Liveness:       liveness,
+		Readiness:      readiness,
+	}
+}
+
+func (hc *HealthController) AddHealthRoutes(e *echo.Echo) {
+	e.GET("/healthz", hc.healthz)
+	e.GET("/readyz", hc.readyz)
+}
+
+func (hc *HealthController) healthz(c echo.Context) error {
+	return healthResponse(c, hc.Liveness.Run(c.Request().Context()))
+}
+
+func (hc *HealthController) readyz(c echo.Context) error {
+	return healthResponse(c, hc.Readiness.Run(c.Request().Context()))
+}
+
+func healthResponse(c echo.Context, report *health.Report) error {
+	status := http.StatusOK
+	if report.Status != health.StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+
+	return c.JSON(status, endpoint.NewHealth(report))
+}
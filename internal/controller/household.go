@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type HouseholdController struct {
+	*BaseController
+	HouseholdService service.HouseholdService
+}
+
+func NewHouseholdController(base *BaseController, householdService service.HouseholdService) *HouseholdController {
+	return &HouseholdController{
+		BaseController:   base,
+		HouseholdService: householdService,
+	}
+}
+
+func (hc *HouseholdController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/household/children", hc.children)
+	e.POST("/"+V1+"/household/children", hc.createChild)
+	e.PATCH("/"+V1+"/household/children/:uuid", hc.updateChild)
+	e.DELETE("/"+V1+"/household/children/:uuid", hc.deleteChild)
+}
+
+func (hc *HouseholdController) children(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	children, err := hc.HouseholdService.Children(c.Request().Context(), claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewChildAccounts(children),
+	})
+}
+
+func (hc *HouseholdController) createChild(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.ChildCreateRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	child, err := hc.HouseholdService.CreateChild(c.Request().Context(), claims.UserID, req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, echo.Map{
+		"data": endpoint.NewChildAccount(child),
+	})
+}
+
+func (hc *HouseholdController) updateChild(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.ChildUpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	child, err := hc.HouseholdService.UpdateChild(c.Request().Context(), claims.UserID, c.Param("uuid"), req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewChildAccount(child),
+	})
+}
+
+func (hc *HouseholdController) deleteChild(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	err := hc.HouseholdService.DeleteChild(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
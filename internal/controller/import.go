@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ImportController struct {
+	*BaseController
+	ImportService service.ImportService
+}
+
+func NewImportController(base *BaseController, importService service.ImportService) *ImportController {
+	return &ImportController{
+		BaseController: base,
+		ImportService:  importService,
+	}
+}
+
+func (ic *ImportController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/imports", ic.all)
+	e.POST("/"+V1+"/imports", ic.start)
+	e.GET("/"+V1+"/imports/:uuid", ic.byUUID)
+	e.POST("/"+V1+"/transfers", ic.transfer)
+}
+
+func (ic *ImportController) all(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	jobs, err := ic.ImportService.Jobs(c.Request().Context(), claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewImportJobs(jobs),
+	})
+}
+
+// start queues the import of the backup in the request body, the provider query parameter
+// names the app it was exported from, archive for the ZIP exports of this app. The returned job
+// is polled for the outcome.
+func (ic *ImportController) start(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	provider, err := domain.ParseImportProvider(c.QueryParam("provider"))
+	if err != nil {
+		return err
+	}
+
+	payload, err := io.ReadAll(http.MaxBytesReader(c.Response(), c.Request().Body, provider.MaxSize()))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return domain.ErrImportTooLarge
+		}
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	job, err := ic.ImportService.Start(c.Request().Context(), claims.UserID, provider, payload)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusAccepted, echo.Map{
+		"data": endpoint.NewImportJob(job),
+	})
+}
+
+// transfer queues moving the account from another instance, the returned import job is polled
+// for the progress of the download and the import.
+func (ic *ImportController) transfer(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.AccountTransferRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	job, err := ic.ImportService.Transfer(c.Request().Context(), claims.UserID, &domain.AccountTransfer{
+		SourceURL: req.SourceURL,
+		Token:     req.Token,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusAccepted, echo.Map{
+		"data": endpoint.NewImportJob(job),
+	})
+}
+
+func (ic *ImportController) byUUID(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	job, err := ic.ImportService.Job(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewImportJob(job),
+	})
+}
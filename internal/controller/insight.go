@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type InsightController struct {
+	*BaseController
+	InsightService service.InsightService
+}
+
+func NewInsightController(base *BaseController, insightService service.InsightService) *InsightController {
+	return &InsightController{
+		BaseController: base,
+		InsightService: insightService,
+	}
+}
+
+func (ic *InsightController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/insights/settings", ic.settings)
+	e.PUT("/"+V1+"/insights/settings", ic.updateSettings)
+}
+
+func (ic *InsightController) settings(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	settings, err := ic.InsightService.Settings(c.Request().Context(), claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewInsightSettings(settings)})
+}
+
+func (ic *InsightController) updateSettings(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.InsightSettingsRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	settings, err := ic.InsightService.UpdateSettings(c.Request().Context(), claims.UserID, req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewInsightSettings(settings)})
+}
@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type JWKSController struct {
+	*BaseController
+	SigningKeyService service.SigningKeyService
+}
+
+func NewJWKSController(base *BaseController, signingKeyService service.SigningKeyService) *JWKSController {
+	return &JWKSController{
+		BaseController:    base,
+		SigningKeyService: signingKeyService,
+	}
+}
+
+// AddUnprotectedRoutes registers the JWKS document, other services verify access tokens with
+// it without sharing a secret.
+func (jc *JWKSController) AddUnprotectedRoutes(e *echo.Echo) {
+	e.GET("/.well-known/jwks.json", jc.keys)
+}
+
+func (jc *JWKSController) keys(c echo.Context) error {
+	keys, err := jc.SigningKeyService.Verifiable(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	// a new key signs tokens before verifiers refetch the document, they refetch on unknown kids
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return c.JSON(http.StatusOK, endpoint.NewJWKS(keys))
+}
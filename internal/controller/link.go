@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type LinkController struct {
+	*BaseController
+	LinkService service.LinkService
+}
+
+func NewLinkController(base *BaseController, linkService service.LinkService) *LinkController {
+	return &LinkController{
+		BaseController: base,
+		LinkService:    linkService,
+	}
+}
+
+func (lc *LinkController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/settings/links", lc.settings)
+	e.PATCH("/"+V1+"/settings/links", lc.updateSettings)
+}
+
+func (lc *LinkController) settings(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	settings, err := lc.LinkService.Settings(c.Request().Context(), claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewLinkSettings(settings),
+	})
+}
+
+// updateSettings turns the link checks of the todos of the user, or only the emails about
+// dead links, on or off.
+func (lc *LinkController) updateSettings(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.LinkSettingsUpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	settings, err := lc.LinkService.UpdateSettings(c.Request().Context(), claims.UserID, req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewLinkSettings(settings),
+	})
+}
@@ -0,0 +1,189 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ListController struct {
+	*BaseController
+	ListService service.ListService
+}
+
+func NewListController(base *BaseController, listService service.ListService) *ListController {
+	return &ListController{
+		BaseController: base,
+		ListService:    listService,
+	}
+}
+
+func (lc *ListController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/lists", lc.all)
+	e.POST("/"+V1+"/lists", lc.create)
+	e.GET("/"+V1+"/lists/:uuid", lc.byUUID)
+	e.PATCH("/"+V1+"/lists/:uuid", lc.rename)
+	e.PUT("/"+V1+"/lists/:uuid/retention", lc.setRetention)
+	e.DELETE("/"+V1+"/lists/:uuid", lc.delete)
+	e.POST("/"+V1+"/lists/:uuid/merge", lc.merge)
+}
+
+func (lc *ListController) all(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	page, err := pageParams(c)
+	if err != nil {
+		return err
+	}
+
+	lists, next, err := lc.ListService.All(c.Request().Context(), claims.UserID, page)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data":        endpoint.NewLists(lists),
+		"next_cursor": next.Encode(),
+	})
+}
+
+func (lc *ListController) create(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.ListRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	list, err := lc.ListService.Create(c.Request().Context(), claims.UserID, req.Name)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, echo.Map{
+		"data": endpoint.NewList(list),
+	})
+}
+
+func (lc *ListController) byUUID(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	// collaborators can see the lists shared with them too
+	shared, err := lc.ListService.Access(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewSharedList(shared),
+	})
+}
+
+func (lc *ListController) rename(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.ListRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	list, err := lc.ListService.Rename(c.Request().Context(), claims.UserID, c.Param("uuid"), req.Name)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewList(list),
+	})
+}
+
+// setRetention sets after how many days completed todos of the list are archived, they are
+// left out of the todo queries from then on unless archived todos are asked for.
+func (lc *ListController) setRetention(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.ListRetentionRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	list, err := lc.ListService.SetRetention(c.Request().Context(), claims.UserID, c.Param("uuid"), req.Retention())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewList(list),
+	})
+}
+
+func (lc *ListController) delete(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	err := lc.ListService.Delete(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// merge folds the source list of the body into the list of the URL, dry runs only preview it.
+func (lc *ListController) merge(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.ListMergeRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	result, err := lc.ListService.Merge(c.Request().Context(), claims.UserID, c.Param("uuid"), req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewListMergeResult(result),
+	})
+}
@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type LoginController struct {
+	*BaseController
+	LoginService service.LoginService
+}
+
+func NewLoginController(base *BaseController, loginService service.LoginService) *LoginController {
+	return &LoginController{
+		BaseController: base,
+		LoginService:   loginService,
+	}
+}
+
+func (lc *LoginController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/users/me/logins", lc.logins)
+	e.GET("/"+V1+"/users/me/devices", lc.devices)
+	e.DELETE("/"+V1+"/users/me/devices/:uuid", lc.forgetDevice)
+}
+
+// logins returns the logins of the signed in user of the last 90 days, newest first.
+func (lc *LoginController) logins(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	page, err := pageParams(c)
+	if err != nil {
+		return err
+	}
+
+	logins, next, err := lc.LoginService.Logins(c.Request().Context(), claims.UserID, page)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data":        endpoint.NewLogins(logins),
+		"next_cursor": next.Encode(),
+	})
+}
+
+func (lc *LoginController) devices(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	devices, err := lc.LoginService.Devices(c.Request().Context(), claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewKnownDevices(devices)})
+}
+
+// forgetDevice removes a known device, the next login from it is alerted like one from a new
+// device.
+func (lc *LoginController) forgetDevice(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	if err := lc.LoginService.ForgetDevice(c.Request().Context(), claims.UserID, c.Param("uuid")); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/rs/zerolog/log"
+)
+
+// MetricsController serves the Prometheus metrics, scrapers authenticate with METRICS_TOKEN
+// instead of a user session. Without a token the metrics are not served, they reveal the traffic
+// and internals of the deployment.
+type MetricsController struct {
+	*BaseController
+}
+
+func NewMetricsController(base *BaseController) *MetricsController {
+	return &MetricsController{
+		BaseController: base,
+	}
+}
+
+func (mc *MetricsController) AddMetricsRoutes(e *echo.Echo) {
+	if mc.Config.GetMetricsToken() == "" {
+		log.Warn().Msg("METRICS_TOKEN is not set, /metrics is not served")
+		return
+	}
+
+	e.GET("/metrics", echo.WrapHandler(promhttp.Handler()), mc.authorize)
+}
+
+func (mc *MetricsController) authorize(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		token := mc.Config.GetMetricsToken()
+		provided := strings.TrimPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			return echo.NewHTTPError(http.StatusUnauthorized)
+		}
+
+		return next(c)
+	}
+}
@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/api/middleware"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type OAuthController struct {
+	*BaseController
+	OAuthService service.OAuthService
+}
+
+func NewOAuthController(base *BaseController, oauthService service.OAuthService) *OAuthController {
+	return &OAuthController{
+		BaseController: base,
+		OAuthService:   oauthService,
+	}
+}
+
+// AddRoutes registers the routes of the signed in user, the web app calls authorize once the
+// user consented to link their account.
+func (oc *OAuthController) AddRoutes(e *echo.Group) {
+	// the redirect URI carries the authorization code
+	e.POST("/"+V1+"/oauth/authorize", oc.authorize, middleware.NoIdempotentStore)
+	e.GET("/"+V1+"/oauth/grants", oc.grants)
+	e.DELETE("/"+V1+"/oauth/grants/:uuid", oc.revoke)
+}
+
+// AddOAuthRoutes registers the token endpoint, clients authenticate with their own credentials.
+func (oc *OAuthController) AddOAuthRoutes(e *echo.Echo) {
+	e.POST("/oauth/token", oc.token)
+}
+
+func (oc *OAuthController) authorize(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.OAuthAuthorizeRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	redirectURI, err := oc.OAuthService.Authorize(c.Request().Context(), claims.UserID, req.ClientID, req.RedirectURI, req.State)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": echo.Map{"redirect_uri": redirectURI},
+	})
+}
+
+func (oc *OAuthController) grants(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	grants, err := oc.OAuthService.Grants(c.Request().Context(), claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewOAuthGrants(grants),
+	})
+}
+
+func (oc *OAuthController) revoke(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	err := oc.OAuthService.Revoke(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// token implements the token endpoint of RFC 6749, errors use its error response format.
+func (oc *OAuthController) token(c echo.Context) error {
+	var req endpoint.OAuthTokenRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "invalid_request"})
+	}
+
+	// clients may send their credentials with HTTP Basic authentication instead of the body
+	if clientID, clientSecret, ok := c.Request().BasicAuth(); ok {
+		req.ClientID = clientID
+		req.ClientSecret = clientSecret
+	}
+
+	grant, err := oc.OAuthService.Token(c.Request().Context(), req.ToDomain())
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidClient):
+			return c.JSON(http.StatusUnauthorized, echo.Map{"error": domain.ErrInvalidClient.Error()})
+		case errors.Is(err, domain.ErrInvalidGrant), errors.Is(err, domain.ErrUnsupportedGrantType):
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		case errors.Is(err, domain.ErrUnknownRegion):
+			// the code or refresh token was issued by an instance of another region
+			return c.JSON(http.StatusMisdirectedRequest, echo.Map{"error": domain.ErrUnknownRegion.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "server_error"})
+	}
+
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.JSON(http.StatusOK, endpoint.NewOAuthTokenResponse(grant))
+}
@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type OnboardingController struct {
+	*BaseController
+	OnboardingService service.OnboardingService
+}
+
+func NewOnboardingController(base *BaseController, onboardingService service.OnboardingService) *OnboardingController {
+	return &OnboardingController{
+		BaseController:    base,
+		OnboardingService: onboardingService,
+	}
+}
+
+func (oc *OnboardingController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/users/me/onboarding", oc.state)
+	e.PATCH("/"+V1+"/users/me/onboarding", oc.update)
+	e.DELETE("/"+V1+"/users/me/onboarding", oc.reset)
+}
+
+func (oc *OnboardingController) state(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	state, err := oc.OnboardingService.State(c.Request().Context(), claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewOnboardingState(state)})
+}
+
+func (oc *OnboardingController) update(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.OnboardingUpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	state, err := oc.OnboardingService.Update(c.Request().Context(), claims.UserID, req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewOnboardingState(state)})
+}
+
+// reset restarts the onboarding, e.g. from the help menu.
+func (oc *OnboardingController) reset(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	state, err := oc.OnboardingService.Reset(c.Request().Context(), claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewOnboardingState(state)})
+}
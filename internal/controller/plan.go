@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type PlanController struct {
+	*BaseController
+	PlanService service.PlanService
+}
+
+func NewPlanController(base *BaseController, planService service.PlanService) *PlanController {
+	return &PlanController{
+		BaseController: base,
+		PlanService:    planService,
+	}
+}
+
+func (pc *PlanController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/plan/today", pc.today)
+	e.POST("/"+V1+"/plan/today", pc.plan)
+}
+
+func (pc *PlanController) today(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	date, err := domain.ParseDate(c.QueryParam("date"))
+	if err != nil {
+		return err
+	}
+
+	plan, err := pc.PlanService.ByDate(c.Request().Context(), claims.UserID, date)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewPlan(plan)})
+}
+
+func (pc *PlanController) plan(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.PlanRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	date, err := domain.ParseDate(req.Date)
+	if err != nil {
+		return err
+	}
+
+	plan, err := pc.PlanService.Plan(c.Request().Context(), claims.UserID, &domain.PlanRequest{
+		Date:     date,
+		Capacity: time.Duration(req.CapacityMinutes) * time.Minute,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, echo.Map{"data": endpoint.NewPlan(plan)})
+}
@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ProvisioningController serves the provisioning API for infrastructure tools like Terraform.
+// It is authenticated with BOOTSTRAP_TOKEN instead of a user session and only exists while the
+// token is set. Resources are put in full, so every request can be repeated safely, 201 tells
+// a creation apart from an update.
+type ProvisioningController struct {
+	*BaseController
+	ProvisioningService service.ProvisioningService
+}
+
+func NewProvisioningController(base *BaseController, provisioningService service.ProvisioningService) *ProvisioningController {
+	return &ProvisioningController{
+		BaseController:      base,
+		ProvisioningService: provisioningService,
+	}
+}
+
+func (pc *ProvisioningController) AddProvisioningRoutes(e *echo.Echo) {
+	if pc.Config.GetBootstrapToken() == "" {
+		return
+	}
+
+	g := e.Group("/provision/"+V1, pc.authorize)
+	g.GET("/workspaces/:slug", pc.workspace)
+	g.PUT("/workspaces/:slug", pc.putWorkspace)
+	g.PUT("/workspaces/:slug/quotas", pc.setQuotas)
+	g.PUT("/workspaces/:slug/sso", pc.setSSO)
+	g.PUT("/admins", pc.putAdmin)
+}
+
+func (pc *ProvisioningController) authorize(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		provided := strings.TrimPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(pc.Config.GetBootstrapToken())) != 1 {
+			return echo.NewHTTPError(http.StatusUnauthorized)
+		}
+
+		if slug := c.Param("slug"); slug != "" && !domain.ValidWorkspaceSlug(slug) {
+			return domain.ErrInvalidWorkspaceSlug
+		}
+
+		return next(c)
+	}
+}
+
+func (pc *ProvisioningController) workspace(c echo.Context) error {
+	workspace, err := pc.ProvisioningService.Workspace(c.Request().Context(), c.Param("slug"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewWorkspace(workspace)})
+}
+
+func (pc *ProvisioningController) putWorkspace(c echo.Context) error {
+	var req endpoint.WorkspaceRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	workspace, created, err := pc.ProvisioningService.PutWorkspace(c.Request().Context(), c.Param("slug"), req.ToDomain())
+	if err != nil {
+		// a child can't own a workspace, for the provisioning client the owner email is taken
+		if errors.Is(err, domain.ErrChildAccount) {
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		}
+		return err
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+
+	return c.JSON(status, echo.Map{"data": endpoint.NewWorkspace(workspace)})
+}
+
+func (pc *ProvisioningController) setQuotas(c echo.Context) error {
+	var req endpoint.QuotasRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	workspace, err := pc.ProvisioningService.SetQuotas(c.Request().Context(), c.Param("slug"), req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewWorkspace(workspace)})
+}
+
+func (pc *ProvisioningController) setSSO(c echo.Context) error {
+	var req endpoint.SSORequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	workspace, err := pc.ProvisioningService.SetSSO(c.Request().Context(), c.Param("slug"), req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewWorkspace(workspace)})
+}
+
+func (pc *ProvisioningController) putAdmin(c echo.Context) error {
+	var req endpoint.AdminProvisionRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	provision := req.ToDomain()
+	user, created, err := pc.ProvisioningService.PutAdmin(c.Request().Context(), provision)
+	if err != nil {
+		return err
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+
+	return c.JSON(status, echo.Map{"data": &endpoint.ProvisionedAdmin{
+		UUID:   user.UUID,
+		Email:  user.Email,
+		Scopes: provision.Scopes,
+	}})
+}
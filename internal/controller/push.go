@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type PushController struct {
+	*BaseController
+	PushService service.PushService
+}
+
+func NewPushController(base *BaseController, pushService service.PushService) *PushController {
+	return &PushController{
+		BaseController: base,
+		PushService:    pushService,
+	}
+}
+
+func (pc *PushController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/push/public-key", pc.publicKey)
+	e.GET("/"+V1+"/push/subscriptions", pc.subscriptions)
+	e.POST("/"+V1+"/push/subscriptions", pc.subscribe)
+	e.DELETE("/"+V1+"/push/subscriptions/:uuid", pc.unsubscribe)
+}
+
+// publicKey returns the applicationServerKey browsers pass to pushManager.subscribe.
+func (pc *PushController) publicKey(c echo.Context) error {
+	publicKey, err := pc.PushService.PublicKey()
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": echo.Map{"public_key": publicKey}})
+}
+
+func (pc *PushController) subscriptions(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	subscriptions, err := pc.PushService.Subscriptions(c.Request().Context(), claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewPushSubscriptions(subscriptions)})
+}
+
+func (pc *PushController) subscribe(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.PushSubscriptionRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	subscription, err := pc.PushService.Subscribe(c.Request().Context(), claims.UserID, req.ToDomain(c.Request().UserAgent()))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, echo.Map{"data": endpoint.NewPushSubscription(subscription)})
+}
+
+func (pc *PushController) unsubscribe(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	err := pc.PushService.Unsubscribe(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type QuickAddController struct {
+	*BaseController
+	QuickAddService service.QuickAddService
+}
+
+func NewQuickAddController(base *BaseController, quickAddService service.QuickAddService) *QuickAddController {
+	return &QuickAddController{
+		BaseController:  base,
+		QuickAddService: quickAddService,
+	}
+}
+
+func (qc *QuickAddController) AddRoutes(e *echo.Group) {
+	e.POST("/"+V1+"/todos/quick", qc.quickAdd)
+}
+
+func (qc *QuickAddController) quickAdd(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.QuickAddRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	result, err := qc.QuickAddService.QuickAdd(c.Request().Context(), claims.UserID, req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	if result.DryRun {
+		return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewQuickAdd(result)})
+	}
+	setTodoETag(c, result.Todo)
+
+	return c.JSON(http.StatusCreated, echo.Map{"data": endpoint.NewQuickAdd(result)})
+}
@@ -30,14 +30,14 @@ func (rc *RecipeController) all(c echo.Context) error {
 	claims, ok := c.Get("claims").(*domain.JWTCustomClaims)
 	if !ok {
 		log.Error().Msg("Failed to assert claims")
-		return c.JSON(http.StatusInternalServerError, echo.Map{"message": domain.ErrUnableToVerifyClaim})
+		return domain.ErrUnableToVerifyClaim
 	}
 
 	// TODO hook up pagination
 
 	_, err := rc.RecipeService.All()
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, echo.Map{"message": err.Error()})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, echo.Map{
@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type RecoveryController struct {
+	*BaseController
+	RecoveryService service.RecoveryService
+}
+
+func NewRecoveryController(base *BaseController, recoveryService service.RecoveryService) *RecoveryController {
+	return &RecoveryController{
+		BaseController:  base,
+		RecoveryService: recoveryService,
+	}
+}
+
+// AddUnprotectedRoutes registers the routes of the one recovering an account, who has no session.
+func (rc *RecoveryController) AddUnprotectedRoutes(e *echo.Echo, rateLimit echo.MiddlewareFunc) {
+	e.POST("/recovery", rc.start, rateLimit)
+	e.POST("/recovery/complete", rc.complete, rateLimit)
+}
+
+func (rc *RecoveryController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/users/me/recovery", rc.settings)
+	e.PUT("/"+V1+"/users/me/recovery", rc.configure)
+	e.DELETE("/"+V1+"/users/me/recovery/requests", rc.cancel)
+
+	e.GET("/"+V1+"/recovery-requests", rc.requests)
+	e.POST("/"+V1+"/recovery-requests/:uuid/approve", rc.approve)
+}
+
+func (rc *RecoveryController) settings(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	settings, err := rc.RecoveryService.Settings(c.Request().Context(), claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewRecoverySettings(settings)})
+}
+
+func (rc *RecoveryController) configure(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.RecoverySetupRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	settings, err := rc.RecoveryService.Configure(c.Request().Context(), claims.UserID, req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewRecoverySettings(settings)})
+}
+
+// cancel cancels the pending recovery requests of the account, e.g. after the owner was told
+// about one they didn't start.
+func (rc *RecoveryController) cancel(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	if err := rc.RecoveryService.Cancel(c.Request().Context(), claims.UserID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (rc *RecoveryController) requests(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	requests, err := rc.RecoveryService.Requests(c.Request().Context(), claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewRecoveryRequests(requests)})
+}
+
+func (rc *RecoveryController) approve(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	request, err := rc.RecoveryService.Approve(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewRecoveryRequest(request)})
+}
+
+// start answers the same for every email, only the owner and the contacts of an account with
+// trusted contacts are emailed.
+func (rc *RecoveryController) start(c echo.Context) error {
+	var req endpoint.RecoveryStartRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	ticket, err := rc.RecoveryService.Start(c.Request().Context(), &domain.RecoveryStart{Email: req.Email})
+	if err != nil {
+		if errors.Is(err, domain.ErrUnknownRegion) {
+			return echo.NewHTTPError(http.StatusMisdirectedRequest, domain.ErrUnknownRegion.Error())
+		}
+		return err
+	}
+	c.Response().Header().Set("Cache-Control", "no-store")
+
+	return c.JSON(http.StatusAccepted, echo.Map{"data": endpoint.NewRecoveryTicket(ticket)})
+}
+
+func (rc *RecoveryController) complete(c echo.Context) error {
+	var req endpoint.RecoveryCompleteRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	if err := rc.RecoveryService.Complete(c.Request().Context(), req.ToDomain()); err != nil {
+		if errors.Is(err, domain.ErrUnknownRegion) {
+			return echo.NewHTTPError(http.StatusMisdirectedRequest, domain.ErrUnknownRegion.Error())
+		}
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "Account recovered",
+	})
+}
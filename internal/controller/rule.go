@@ -0,0 +1,208 @@
+package controller
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type RuleController struct {
+	*BaseController
+	RuleService service.RuleService
+}
+
+func NewRuleController(base *BaseController, ruleService service.RuleService) *RuleController {
+	return &RuleController{
+		BaseController: base,
+		RuleService:    ruleService,
+	}
+}
+
+func (rc *RuleController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/rules", rc.all)
+	e.POST("/"+V1+"/rules", rc.create)
+	e.PUT("/"+V1+"/rules/order", rc.reorder)
+	e.POST("/"+V1+"/rules/test", rc.test)
+	e.POST("/"+V1+"/rules/expressions/validate", rc.validateExpression)
+	e.GET("/"+V1+"/rules/:uuid", rc.byUUID)
+	e.PUT("/"+V1+"/rules/:uuid", rc.update)
+	e.DELETE("/"+V1+"/rules/:uuid", rc.delete)
+}
+
+// all returns the rules in evaluation order, ?source=slack narrows them to the rules of one
+// capture source so each integration can show its routing.
+func (rc *RuleController) all(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var source domain.CaptureSource
+	if value := c.QueryParam("source"); value != "" {
+		var err error
+		if source, err = domain.ParseCaptureSource(value); err != nil {
+			return err
+		}
+	}
+
+	rules, err := rc.RuleService.All(c.Request().Context(), claims.UserID)
+	if err != nil {
+		return err
+	}
+	if source != "" {
+		rules = slices.DeleteFunc(rules, func(rule *domain.Rule) bool {
+			return rule.Source != source
+		})
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewRules(rules)})
+}
+
+func (rc *RuleController) create(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.RuleRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	rule, err := rc.RuleService.Create(c.Request().Context(), claims.UserID, req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, echo.Map{"data": endpoint.NewRule(rule)})
+}
+
+func (rc *RuleController) byUUID(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	rule, err := rc.RuleService.ByUUID(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewRule(rule)})
+}
+
+func (rc *RuleController) update(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.RuleRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	rule, err := rc.RuleService.Update(c.Request().Context(), claims.UserID, c.Param("uuid"), req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewRule(rule)})
+}
+
+func (rc *RuleController) delete(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	err := rc.RuleService.Delete(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (rc *RuleController) reorder(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.RuleOrderRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	rules, err := rc.RuleService.Reorder(c.Request().Context(), claims.UserID, req.UUIDs)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewRules(rules)})
+}
+
+// test is a dry run of the rules against a todo, so users can check a rule before relying on it.
+func (rc *RuleController) test(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.RuleTestRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	outcome, err := rc.RuleService.Evaluate(c.Request().Context(), claims.UserID, req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewRuleOutcome(outcome)})
+}
+
+// validateExpression checks an expression for a rule and tries it on a sample todo, so editors
+// can point at mistakes while the rule is written. Invalid expressions are answered with 200 and
+// the error, only malformed requests fail.
+func (rc *RuleController) validateExpression(c echo.Context) error {
+	if _, ok := userClaims(c); !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.RuleExpressionRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	target, sample := req.ToDomain()
+	check := rc.RuleService.CheckExpression(c.Request().Context(), target, req.Expression, sample)
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewRuleExpressionCheck(check)})
+}
@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type SearchController struct {
+	*BaseController
+	SearchService service.SearchService
+}
+
+func NewSearchController(base *BaseController, searchService service.SearchService) *SearchController {
+	return &SearchController{
+		BaseController: base,
+		SearchService:  searchService,
+	}
+}
+
+func (sc *SearchController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/todos/search", sc.searchTodos)
+}
+
+// searchTodos matches the words of the query, or its meaning with ?mode=semantic.
+func (sc *SearchController) searchTodos(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	limit := 0
+	if value := c.QueryParam("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid limit")
+		}
+	}
+
+	mode, err := domain.ParseSearchMode(c.QueryParam("mode"))
+	if err != nil {
+		return err
+	}
+
+	var results []*domain.TodoSearchResult
+	if mode == domain.SearchSemantic {
+		results, err = sc.SearchService.SemanticSearchTodos(c.Request().Context(), claims.UserID, c.QueryParam("q"), limit)
+	} else {
+		results, err = sc.SearchService.SearchTodos(c.Request().Context(), claims.UserID, c.QueryParam("q"), limit)
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewTodoSearchResults(results),
+	})
+}
@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/api/middleware"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type SecureNoteController struct {
+	*BaseController
+	SecureNoteService service.SecureNoteService
+}
+
+func NewSecureNoteController(base *BaseController, secureNoteService service.SecureNoteService) *SecureNoteController {
+	return &SecureNoteController{
+		BaseController:    base,
+		SecureNoteService: secureNoteService,
+	}
+}
+
+func (sc *SecureNoteController) AddRoutes(e *echo.Group) {
+	e.PUT("/"+V1+"/todos/:uuid/secure-note", sc.set)
+	e.DELETE("/"+V1+"/todos/:uuid/secure-note", sc.delete)
+	// the plaintext of the note is never stored
+	e.POST("/"+V1+"/todos/:uuid/secure-note/reveal", sc.reveal, middleware.NoIdempotentStore)
+}
+
+// set stores the note sealed, it isn't echoed back so it only leaves the server when revealed.
+func (sc *SecureNoteController) set(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.SecureNoteRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	if _, err := sc.SecureNoteService.Set(c.Request().Context(), claims.UserID, c.Param("uuid"), req.Note); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (sc *SecureNoteController) delete(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	if err := sc.SecureNoteService.Delete(c.Request().Context(), claims.UserID, c.Param("uuid")); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// reveal returns the note in plain text. It is a POST rather than a GET so the audit middleware
+// records who revealed it and when.
+func (sc *SecureNoteController) reveal(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	note, err := sc.SecureNoteService.Reveal(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+	c.Response().Header().Set("Cache-Control", "no-store")
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewSecureNote(note)})
+}
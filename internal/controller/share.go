@@ -0,0 +1,193 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ShareController struct {
+	*BaseController
+	ShareService service.ShareService
+}
+
+func NewShareController(base *BaseController, shareService service.ShareService) *ShareController {
+	return &ShareController{
+		BaseController: base,
+		ShareService:   shareService,
+	}
+}
+
+func (sc *ShareController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/lists/shared", sc.sharedLists)
+	e.GET("/"+V1+"/lists/:uuid/members", sc.members)
+	e.PATCH("/"+V1+"/lists/:uuid/members/:userUUID", sc.updateMember)
+	e.DELETE("/"+V1+"/lists/:uuid/members/:userUUID", sc.removeMember)
+	e.GET("/"+V1+"/lists/:uuid/invitations", sc.invitations)
+	e.POST("/"+V1+"/lists/:uuid/invitations", sc.invite)
+	e.DELETE("/"+V1+"/lists/:uuid/invitations/:invitationUUID", sc.revokeInvitation)
+	e.POST("/"+V1+"/invitations/accept", sc.accept)
+}
+
+func (sc *ShareController) sharedLists(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	shared, err := sc.ShareService.SharedLists(c.Request().Context(), claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewSharedLists(shared),
+	})
+}
+
+func (sc *ShareController) members(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	members, err := sc.ShareService.Members(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewListMembers(members),
+	})
+}
+
+func (sc *ShareController) updateMember(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.ListMemberRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	role, err := domain.ParseListRole(req.Role)
+	if err != nil {
+		return err
+	}
+
+	err = sc.ShareService.UpdateMember(c.Request().Context(), claims.UserID, c.Param("uuid"), c.Param("userUUID"), role)
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (sc *ShareController) removeMember(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	err := sc.ShareService.RemoveMember(c.Request().Context(), claims.UserID, c.Param("uuid"), c.Param("userUUID"))
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (sc *ShareController) invitations(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	invitations, err := sc.ShareService.Invitations(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewListInvitations(invitations),
+	})
+}
+
+func (sc *ShareController) invite(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.ListInvitationRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	role, err := domain.ParseListRole(req.Role)
+	if err != nil {
+		return err
+	}
+
+	invitation, err := sc.ShareService.Invite(c.Request().Context(), claims.UserID, c.Param("uuid"), req.Email, role)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, echo.Map{
+		"data": endpoint.NewListInvitation(invitation),
+	})
+}
+
+func (sc *ShareController) revokeInvitation(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	err := sc.ShareService.RevokeInvitation(c.Request().Context(), claims.UserID, c.Param("uuid"), c.Param("invitationUUID"))
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (sc *ShareController) accept(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.InvitationAcceptRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	shared, err := sc.ShareService.Accept(c.Request().Context(), claims.UserID, req.Token)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewSharedList(shared),
+	})
+}
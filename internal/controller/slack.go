@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+	"github.com/meowmix1337/the_recipe_book/internal/slack"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxSlackCommandSize caps the form body of a slash command, Slack sends a few hundred bytes.
+const maxSlackCommandSize = 8 << 10
+
+type SlackController struct {
+	*BaseController
+	SlackService service.SlackService
+}
+
+func NewSlackController(base *BaseController, slackService service.SlackService) *SlackController {
+	return &SlackController{
+		BaseController: base,
+		SlackService:   slackService,
+	}
+}
+
+func (sc *SlackController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/integrations/slack", sc.connection)
+	e.PUT("/"+V1+"/integrations/slack", sc.connect)
+	e.DELETE("/"+V1+"/integrations/slack", sc.disconnect)
+}
+
+// AddSlackRoutes registers the slash command endpoint of the Slack app, requests are
+// authenticated by their signature instead of a user session.
+func (sc *SlackController) AddSlackRoutes(e *echo.Echo) {
+	e.POST("/integrations/slack/command", sc.command)
+}
+
+func (sc *SlackController) connection(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	conn, err := sc.SlackService.Connection(c.Request().Context(), claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewSlackConnection(conn, "")})
+}
+
+func (sc *SlackController) connect(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.SlackConnectionRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	conn, linkCode, err := sc.SlackService.Connect(c.Request().Context(), claims.UserID, req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewSlackConnection(conn, linkCode)})
+}
+
+func (sc *SlackController) disconnect(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	if err := sc.SlackService.Disconnect(c.Request().Context(), claims.UserID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// command runs a slash command. Slack only shows the body of 200 responses, so errors the Slack
+// user can act on are answered as ephemeral messages too.
+func (sc *SlackController) command(c echo.Context) error {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Response(), c.Request().Body, maxSlackCommandSize))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	// the signature covers the raw body, so the form is parsed only after it was verified
+	err = sc.SlackService.VerifyCommand(
+		c.Request().Header.Get(slack.TimestampHeader),
+		c.Request().Header.Get(slack.SignatureHeader),
+		body,
+	)
+	if err != nil {
+		return err
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	resp, err := sc.SlackService.Command(c.Request().Context(), &domain.SlackCommand{
+		TeamID:  form.Get("team_id"),
+		UserID:  form.Get("user_id"),
+		Command: form.Get("command"),
+		Text:    form.Get("text"),
+		// direct messages are named directmessage
+		ChannelName: form.Get("channel_name"),
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidSlackLinkCode) ||
+			errors.Is(err, domain.ErrUsageLimitExceeded) ||
+			errors.Is(err, domain.ErrQuotaExceeded) ||
+			errors.Is(err, domain.ErrParentalControl) {
+			return c.JSON(http.StatusOK, endpoint.NewSlackCommandResponse(err.Error()))
+		}
+		return err
+	}
+
+	return c.JSON(http.StatusOK, endpoint.NewSlackCommandResponse(resp.Text))
+}
@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type SnapshotController struct {
+	*BaseController
+	SnapshotService service.SnapshotService
+}
+
+func NewSnapshotController(base *BaseController, snapshotService service.SnapshotService) *SnapshotController {
+	return &SnapshotController{
+		BaseController:  base,
+		SnapshotService: snapshotService,
+	}
+}
+
+func (sc *SnapshotController) AddRoutes(e *echo.Group) {
+	e.POST("/"+V1+"/lists/:uuid/snapshots", sc.send)
+	e.GET("/"+V1+"/lists/:uuid/snapshots/schedules", sc.schedules)
+	e.POST("/"+V1+"/lists/:uuid/snapshots/schedules", sc.createSchedule)
+	e.DELETE("/"+V1+"/lists/:uuid/snapshots/schedules/:scheduleUUID", sc.deleteSchedule)
+}
+
+func (sc *SnapshotController) send(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.ListSnapshotRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	err := sc.SnapshotService.Send(c.Request().Context(), claims.UserID, c.Param("uuid"), req.Recipients)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusAccepted, echo.Map{"message": "Snapshot sent"})
+}
+
+func (sc *SnapshotController) schedules(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	schedules, err := sc.SnapshotService.Schedules(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewListSnapshotSchedules(schedules),
+	})
+}
+
+func (sc *SnapshotController) createSchedule(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.ListSnapshotScheduleRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	frequency, err := domain.ParseSnapshotFrequency(req.Frequency)
+	if err != nil {
+		return err
+	}
+
+	schedule, err := sc.SnapshotService.CreateSchedule(c.Request().Context(), claims.UserID, c.Param("uuid"), req.Recipients, frequency)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, echo.Map{
+		"data": endpoint.NewListSnapshotSchedule(schedule),
+	})
+}
+
+func (sc *SnapshotController) deleteSchedule(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	err := sc.SnapshotService.DeleteSchedule(c.Request().Context(), claims.UserID, c.Param("uuid"), c.Param("scheduleUUID"))
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type SuggestionController struct {
+	*BaseController
+	SuggestionService service.SuggestionService
+}
+
+func NewSuggestionController(base *BaseController, suggestionService service.SuggestionService) *SuggestionController {
+	return &SuggestionController{
+		BaseController:    base,
+		SuggestionService: suggestionService,
+	}
+}
+
+func (sc *SuggestionController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/suggestions/postponed", sc.postponed)
+}
+
+func (sc *SuggestionController) postponed(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	suggestions, err := sc.SuggestionService.Postponed(c.Request().Context(), claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewTodoSuggestions(suggestions)})
+}
@@ -0,0 +1,182 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type TagController struct {
+	*BaseController
+	TagService service.TagService
+}
+
+func NewTagController(base *BaseController, tagService service.TagService) *TagController {
+	return &TagController{
+		BaseController: base,
+		TagService:     tagService,
+	}
+}
+
+func (tc *TagController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/tags", tc.all)
+	e.POST("/"+V1+"/tags", tc.create)
+	e.PATCH("/"+V1+"/tags/:uuid", tc.update)
+	e.POST("/"+V1+"/tags/:uuid/merge", tc.merge)
+	e.POST("/"+V1+"/todos/:uuid/tags", tc.addToTodo)
+	e.DELETE("/"+V1+"/todos/:uuid/tags/:name", tc.removeFromTodo)
+}
+
+func (tc *TagController) all(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	page, err := pageParams(c)
+	if err != nil {
+		return err
+	}
+
+	tags, next, err := tc.TagService.All(c.Request().Context(), claims.UserID, c.QueryParam("group"), page)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data":        endpoint.NewTags(tags),
+		"next_cursor": next.Encode(),
+	})
+}
+
+func (tc *TagController) create(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.TagCreateRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	tag, err := tc.TagService.Create(c.Request().Context(), claims.UserID, req.Name, req.Color)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, echo.Map{
+		"data": endpoint.NewTag(tag),
+	})
+}
+
+func (tc *TagController) update(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.TagUpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	tag, err := tc.TagService.ByUUID(ctx, claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+	if req.Name != nil {
+		if tag, err = tc.TagService.Rename(ctx, claims.UserID, tag.UUID, *req.Name); err != nil {
+			return err
+		}
+	}
+	if req.Color != nil {
+		if tag, err = tc.TagService.SetColor(ctx, claims.UserID, tag.UUID, *req.Color); err != nil {
+			return err
+		}
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewTag(tag),
+	})
+}
+
+// merge folds the source tag of the body into the tag of the URL.
+func (tc *TagController) merge(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.TagMergeRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	result, err := tc.TagService.Merge(c.Request().Context(), claims.UserID, c.Param("uuid"), req.SourceUUID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewTagMergeResult(result),
+	})
+}
+
+func (tc *TagController) addToTodo(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.TodoTagsRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	todo, err := tc.TagService.AddToTodo(c.Request().Context(), claims.UserID, c.Param("uuid"), req.Tags)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewTodo(todo),
+	})
+}
+
+func (tc *TagController) removeFromTodo(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	todo, err := tc.TagService.RemoveFromTodo(c.Request().Context(), claims.UserID, c.Param("uuid"), c.Param("name"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewTodo(todo),
+	})
+}
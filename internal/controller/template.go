@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type TemplateController struct {
+	*BaseController
+	TemplateService service.TemplateService
+}
+
+func NewTemplateController(base *BaseController, templateService service.TemplateService) *TemplateController {
+	return &TemplateController{
+		BaseController:  base,
+		TemplateService: templateService,
+	}
+}
+
+func (tc *TemplateController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/todo-templates", tc.all)
+	e.POST("/"+V1+"/todo-templates", tc.create)
+	e.GET("/"+V1+"/todo-templates/:uuid", tc.byUUID)
+	e.PUT("/"+V1+"/todo-templates/:uuid", tc.update)
+	e.DELETE("/"+V1+"/todo-templates/:uuid", tc.delete)
+	e.POST("/"+V1+"/todos/from-template/:uuid", tc.createTodo)
+}
+
+func (tc *TemplateController) all(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	templates, err := tc.TemplateService.All(c.Request().Context(), claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewTodoTemplates(templates)})
+}
+
+func (tc *TemplateController) create(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.TodoTemplateRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	template, err := tc.TemplateService.Create(c.Request().Context(), claims.UserID, req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, echo.Map{"data": endpoint.NewTodoTemplate(template)})
+}
+
+func (tc *TemplateController) byUUID(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	template, err := tc.TemplateService.ByUUID(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewTodoTemplate(template)})
+}
+
+func (tc *TemplateController) update(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.TodoTemplateRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	template, err := tc.TemplateService.Update(c.Request().Context(), claims.UserID, c.Param("uuid"), req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewTodoTemplate(template)})
+}
+
+func (tc *TemplateController) delete(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	if err := tc.TemplateService.Delete(c.Request().Context(), claims.UserID, c.Param("uuid")); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// createTodo creates a todo from a template, the body is optional.
+func (tc *TemplateController) createTodo(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.TodoFromTemplateRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	todo, err := tc.TemplateService.CreateTodo(c.Request().Context(), claims.UserID, c.Param("uuid"), req.ToDomain())
+	if err != nil {
+		return err
+	}
+	setTodoETag(c, todo)
+
+	return c.JSON(http.StatusCreated, echo.Map{"data": endpoint.NewTodo(todo)})
+}
@@ -0,0 +1,392 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type TodoController struct {
+	*BaseController
+	TodoService service.TodoService
+	// CandidateTodoService serves the reads of the canary cohort, e.g. from the candidate repo of
+	// shadow writes. Without it the cohort reads through TodoService like everyone else.
+	CandidateTodoService service.TodoService
+}
+
+func NewTodoController(base *BaseController, todoService service.TodoService, candidateTodoService service.TodoService) *TodoController {
+	return &TodoController{
+		BaseController:       base,
+		TodoService:          todoService,
+		CandidateTodoService: candidateTodoService,
+	}
+}
+
+func (tc *TodoController) AddRoutes(e *echo.Group) {
+	candidate := tc
+	if tc.CandidateTodoService != nil {
+		candidate = &TodoController{BaseController: tc.BaseController, TodoService: tc.CandidateTodoService}
+	}
+
+	e.GET("/"+V1+"/todos", tc.Canary("todos.all", tc.all, candidate.all))
+	e.POST("/"+V1+"/todos", tc.create)
+	e.GET("/"+V1+"/todos/match", tc.match)
+	e.GET("/"+V1+"/todos/:uuid", tc.Canary("todos.by_uuid", tc.byUUID, candidate.byUUID))
+	e.PATCH("/"+V1+"/todos/:uuid", tc.update)
+	e.DELETE("/"+V1+"/todos/:uuid", tc.delete)
+	e.POST("/"+V1+"/todos/:uuid/move", tc.move)
+	e.PATCH("/"+V1+"/todos/:uuid/move", tc.place)
+	e.POST("/"+V1+"/todos/:uuid/reorder", tc.reorder)
+	e.POST("/"+V1+"/todos/:uuid/split", tc.split)
+}
+
+func (tc *TodoController) all(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	page, err := pageParams(c)
+	if err != nil {
+		return err
+	}
+
+	sort, order, err := domain.ParseTodoSort(splitQueryList(c.QueryParam("sort")), c.QueryParam("order"))
+	if err != nil {
+		return err
+	}
+
+	archived, err := domain.ParseArchiveFilter(c.QueryParam("archived"))
+	if err != nil {
+		return err
+	}
+
+	filter := &domain.TodoFilter{
+		ListUUID:   c.QueryParam("list"),
+		Tags:       splitQueryList(c.QueryParam("tags")),
+		TagGroup:   c.QueryParam("tag_group"),
+		BlindIndex: c.QueryParam("blind_index"),
+		Sort:       sort,
+		Order:      order,
+		Archived:   archived,
+	}
+
+	todos, next, err := tc.TodoService.All(c.Request().Context(), claims.UserID, filter, page)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data":        endpoint.NewTodos(todos),
+		"next_cursor": next.Encode(),
+	})
+}
+
+func (tc *TodoController) create(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.TodoCreateRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	todo, err := tc.TodoService.Create(c.Request().Context(), claims.UserID, req.ToDomain())
+	if err != nil {
+		return err
+	}
+	setTodoETag(c, todo)
+
+	return c.JSON(http.StatusCreated, echo.Map{
+		"data": endpoint.NewTodo(todo),
+	})
+}
+
+func (tc *TodoController) byUUID(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	todo, err := tc.TodoService.ByUUID(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+	setTodoETag(c, todo)
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewTodo(todo),
+	})
+}
+
+func (tc *TodoController) update(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.TodoUpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	// updates have to name the version they are based on, so edits of a stale copy are refused
+	// with 409 instead of overwriting newer changes
+	update := req.ToDomain()
+	if ifMatch := c.Request().Header.Get("If-Match"); ifMatch != "" {
+		version, err := parseTodoETag(ifMatch)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid If-Match header")
+		}
+		update.Version = &version
+	}
+	if update.Version == nil {
+		return echo.NewHTTPError(http.StatusPreconditionRequired, "the If-Match header or the version of the todo is required")
+	}
+
+	todo, err := tc.TodoService.Update(c.Request().Context(), claims.UserID, c.Param("uuid"), update)
+	if err != nil {
+		return err
+	}
+	setTodoETag(c, todo)
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewTodo(todo),
+	})
+}
+
+// move relocates the todo to another list, like updates it has to name the version it is based on.
+func (tc *TodoController) move(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.TodoMoveRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	move := req.ToDomain()
+	if ifMatch := c.Request().Header.Get("If-Match"); ifMatch != "" {
+		version, err := parseTodoETag(ifMatch)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid If-Match header")
+		}
+		move.Version = &version
+	}
+	if move.Version == nil {
+		return echo.NewHTTPError(http.StatusPreconditionRequired, "the If-Match header or the version of the todo is required")
+	}
+
+	todo, err := tc.TodoService.Move(c.Request().Context(), claims.UserID, c.Param("uuid"), move)
+	if err != nil {
+		return err
+	}
+	setTodoETag(c, todo)
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewTodo(todo),
+	})
+}
+
+// place moves the todo on the board of its list to another status or position, the version is
+// optional since the column is numbered again in the same transaction.
+func (tc *TodoController) place(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.TodoPlaceRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	placement := req.ToDomain()
+	if ifMatch := c.Request().Header.Get("If-Match"); ifMatch != "" {
+		version, err := parseTodoETag(ifMatch)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid If-Match header")
+		}
+		placement.Version = &version
+	}
+
+	todo, err := tc.TodoService.Place(c.Request().Context(), claims.UserID, c.Param("uuid"), placement)
+	if err != nil {
+		return err
+	}
+	setTodoETag(c, todo)
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewTodo(todo),
+	})
+}
+
+// reorder moves the todo in the manual order of ?sort=rank. Only its own rank changes, so it
+// doesn't take a version like edits do.
+func (tc *TodoController) reorder(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.TodoReorderRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	todo, err := tc.TodoService.Reorder(c.Request().Context(), claims.UserID, c.Param("uuid"), req.ToDomain())
+	if err != nil {
+		return err
+	}
+	setTodoETag(c, todo)
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewTodo(todo),
+	})
+}
+
+// split turns the checklist of the todo into todos of their own, e.g. when a todo grew into a
+// project. It changes the description of the todo, so it has to name the version it is based on.
+func (tc *TodoController) split(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.TodoSplitRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	split := req.ToDomain()
+	if ifMatch := c.Request().Header.Get("If-Match"); ifMatch != "" {
+		version, err := parseTodoETag(ifMatch)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid If-Match header")
+		}
+		split.Version = &version
+	}
+	if split.Version == nil {
+		return echo.NewHTTPError(http.StatusPreconditionRequired, "the If-Match header or the version of the todo is required")
+	}
+
+	result, err := tc.TodoService.Split(c.Request().Context(), claims.UserID, c.Param("uuid"), split)
+	if err != nil {
+		return err
+	}
+	setTodoETag(c, result.Todo)
+
+	return c.JSON(http.StatusCreated, echo.Map{
+		"data": endpoint.NewTodoSplit(result),
+	})
+}
+
+func (tc *TodoController) delete(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	err := tc.TodoService.Delete(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// match resolves an open todo by a fuzzy title, e.g. for "complete by name" in the voice,
+// chat-bot and CLI interfaces. Ambiguous titles return 409 with the candidates.
+func (tc *TodoController) match(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	title := strings.TrimSpace(c.QueryParam("title"))
+	if title == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "title is required")
+	}
+
+	match, err := tc.TodoService.Match(c.Request().Context(), claims.UserID, c.QueryParam("list"), title)
+	if err != nil {
+		var ambiguous *domain.AmbiguousTodoMatchError
+		if errors.As(err, &ambiguous) {
+			return c.JSON(http.StatusConflict, echo.Map{
+				"message":    err.Error(),
+				"candidates": endpoint.NewTodoMatches(ambiguous.Candidates),
+			})
+		}
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewTodoMatch(match),
+	})
+}
+
+// setTodoETag sets the version of the todo as its ETag, clients send it back in If-Match.
+func setTodoETag(c echo.Context, todo *domain.Todo) {
+	c.Response().Header().Set("ETag", strconv.Quote(strconv.Itoa(todo.Version)))
+}
+
+// parseTodoETag returns the version of an ETag set by setTodoETag, weak ETags are accepted.
+func parseTodoETag(etag string) (int, error) {
+	etag = strings.TrimPrefix(strings.TrimSpace(etag), "W/")
+	if unquoted, err := strconv.Unquote(etag); err == nil {
+		etag = unquoted
+	}
+
+	return strconv.Atoi(etag)
+}
+
+// splitQueryList splits a comma separated query parameter, e.g. ?tags=work,urgent.
+func splitQueryList(value string) []string {
+	if value == "" {
+		return nil
+	}
+
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
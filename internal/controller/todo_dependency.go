@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type TodoDependencyController struct {
+	*BaseController
+	TodoDependencyService service.TodoDependencyService
+}
+
+func NewTodoDependencyController(base *BaseController, todoDependencyService service.TodoDependencyService) *TodoDependencyController {
+	return &TodoDependencyController{
+		BaseController:        base,
+		TodoDependencyService: todoDependencyService,
+	}
+}
+
+func (dc *TodoDependencyController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/todos/:uuid/dependencies", dc.graph)
+	e.POST("/"+V1+"/todos/:uuid/dependencies", dc.block)
+	e.DELETE("/"+V1+"/todos/:uuid/dependencies/:blocker", dc.unblock)
+}
+
+func (dc *TodoDependencyController) graph(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	graph, err := dc.TodoDependencyService.Graph(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewDependencyGraph(graph)})
+}
+
+func (dc *TodoDependencyController) block(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.TodoBlockRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	block := &domain.TodoBlock{BlockerUUID: req.BlockedByUUID}
+	graph, err := dc.TodoDependencyService.Block(c.Request().Context(), claims.UserID, c.Param("uuid"), block)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewDependencyGraph(graph)})
+}
+
+func (dc *TodoDependencyController) unblock(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	if err := dc.TodoDependencyService.Unblock(c.Request().Context(), claims.UserID, c.Param("uuid"), c.Param("blocker")); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,242 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+	"github.com/meowmix1337/the_recipe_book/internal/transfer"
+	"github.com/rs/zerolog/log"
+
+	"github.com/labstack/echo/v4"
+)
+
+//nolint:gochecknoglobals // read-only
+var todoFormatContentTypes = map[domain.TodoFormat]string{
+	domain.TodoFormatCSV:  "text/csv; charset=utf-8",
+	domain.TodoFormatJSON: echo.MIMEApplicationJSONCharsetUTF8,
+	domain.TodoFormatZIP:  "application/zip",
+}
+
+//nolint:gochecknoglobals // read-only
+var plannerFormatContentTypes = map[domain.PlannerFormat]string{
+	domain.PlannerFormatHTML: echo.MIMETextHTMLCharsetUTF8,
+	domain.PlannerFormatPDF:  "application/pdf",
+}
+
+type TodoTransferController struct {
+	*BaseController
+	TodoTransferService service.TodoTransferService
+}
+
+func NewTodoTransferController(base *BaseController, todoTransferService service.TodoTransferService) *TodoTransferController {
+	return &TodoTransferController{
+		BaseController:      base,
+		TodoTransferService: todoTransferService,
+	}
+}
+
+func (tc *TodoTransferController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/todos/export", tc.export)
+	e.GET("/"+V1+"/todos/planner", tc.planner)
+	e.POST("/"+V1+"/todos/import", tc.importTodos)
+}
+
+// export streams the todos as a file download. It takes the tags and archived filters of
+// GET /todos, list takes several comma separated lists. from and to select the todos created
+// on those days, completed=exclude|only selects by completion and attachments=true adds the
+// attached files to zip archives. Zip archives end with their SHA-256 in a trailer, transfers
+// from another instance verify them with it.
+func (tc *TodoTransferController) export(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	format, err := domain.ParseTodoFormat(c.QueryParam("format"))
+	if err != nil {
+		return err
+	}
+
+	archived, err := domain.ParseArchiveFilter(c.QueryParam("archived"))
+	if err != nil {
+		return err
+	}
+
+	completed, err := domain.ParseCompletedFilter(c.QueryParam("completed"))
+	if err != nil {
+		return err
+	}
+
+	filter := &domain.TodoFilter{
+		Tags:      splitQueryList(c.QueryParam("tags")),
+		Sort:      []domain.TodoSortField{domain.TodoSortCreatedAt},
+		Order:     domain.SortOrderAsc,
+		Archived:  archived,
+		Completed: completed,
+	}
+	if from := c.QueryParam("from"); from != "" {
+		if filter.CreatedFrom, err = domain.ParseDate(from); err != nil {
+			return err
+		}
+	}
+	if to := c.QueryParam("to"); to != "" {
+		day, err := domain.ParseDate(to)
+		if err != nil {
+			return err
+		}
+		// to is inclusive, the whole day is exported
+		filter.CreatedBefore = day.AddDate(0, 0, 1)
+	}
+
+	attachments := false
+	if value := c.QueryParam("attachments"); value != "" {
+		if attachments, err = strconv.ParseBool(value); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid attachments: "+value)
+		}
+	}
+
+	selection := &domain.TodoExport{
+		Format:      format,
+		ListUUIDs:   splitQueryList(c.QueryParam("list")),
+		Filter:      filter,
+		Attachments: attachments,
+	}
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentType, todoFormatContentTypes[format])
+	header.Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", "todos."+string(format)))
+
+	var out io.Writer = c.Response()
+	var digest hash.Hash
+	if format == domain.TodoFormatZIP {
+		digest = sha256.New()
+		out = io.MultiWriter(c.Response(), digest)
+		header.Set("Trailer", transfer.DigestTrailer)
+	}
+
+	err = tc.TodoTransferService.Export(c.Request().Context(), claims.UserID, selection, out)
+	if err != nil {
+		// once the download started the status can't be changed anymore
+		if c.Response().Committed {
+			log.Err(err).Msg("error streaming todo export")
+			return nil
+		}
+		header.Del(echo.HeaderContentDisposition)
+		header.Del("Trailer")
+		return err
+	}
+
+	if digest != nil {
+		header.Set(transfer.DigestTrailer, hex.EncodeToString(digest.Sum(nil)))
+	}
+
+	return nil
+}
+
+// planner renders the todos due in a week for printing. week is any date of the week, by default
+// the current one, and tz the timezone the days are cut in. It takes the list and tags filters
+// of GET /todos.
+func (tc *TodoTransferController) planner(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	format, err := domain.ParsePlannerFormat(c.QueryParam("format"))
+	if err != nil {
+		return err
+	}
+
+	week, err := domain.ParsePlannerWeek(c.QueryParam("week"), c.QueryParam("tz"), time.Now())
+	if err != nil {
+		return err
+	}
+
+	filter := &domain.TodoFilter{
+		ListUUID: c.QueryParam("list"),
+		Tags:     splitQueryList(c.QueryParam("tags")),
+	}
+
+	// the planner opens in the browser to be printed rather than downloading
+	filename := "planner-" + week.Start.Format(time.DateOnly) + "." + string(format)
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentType, plannerFormatContentTypes[format])
+	header.Set(echo.HeaderContentDisposition, fmt.Sprintf("inline; filename=%q", filename))
+
+	err = tc.TodoTransferService.Planner(c.Request().Context(), claims.UserID, filter, week, format, c.Response())
+	if err != nil {
+		if c.Response().Committed {
+			log.Err(err).Msg("error streaming weekly planner")
+			return nil
+		}
+		header.Del(echo.HeaderContentDisposition)
+		return err
+	}
+
+	return nil
+}
+
+// importTodos reads the request body as CSV or JSON, by the format query parameter or else the
+// Content-Type. Repeated map=field=column parameters read a field from a differently named
+// column, dry_run=true only reports what would be created.
+func (tc *TodoTransferController) importTodos(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	formatName := c.QueryParam("format")
+	if formatName == "" && strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), "text/csv") {
+		formatName = string(domain.TodoFormatCSV)
+	}
+	format, err := domain.ParseTodoFormat(formatName)
+	if err != nil {
+		return err
+	}
+
+	mapping, err := domain.ParseTodoMapping(c.QueryParams()["map"])
+	if err != nil {
+		return err
+	}
+
+	dryRun := false
+	if value := c.QueryParam("dry_run"); value != "" {
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid dry_run: "+value)
+		}
+	}
+
+	body := http.MaxBytesReader(c.Response(), c.Request().Body, domain.MaxTodoImportSize)
+	result, err := tc.TodoTransferService.Import(c.Request().Context(), claims.UserID, body, &domain.TodoImport{
+		Format:  format,
+		Mapping: mapping,
+		DryRun:  dryRun,
+	})
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			err = fmt.Errorf("larger than %d bytes: %w", domain.MaxTodoImportSize, domain.ErrTodoImportTooLarge)
+		}
+		return err
+	}
+
+	status := http.StatusCreated
+	if dryRun {
+		status = http.StatusOK
+	}
+
+	return c.JSON(status, echo.Map{
+		"data": endpoint.NewTodoImportResult(result),
+	})
+}
@@ -5,7 +5,6 @@ import (
 	"net/http"
 
 	"github.com/meowmix1337/the_recipe_book/internal/api/middleware"
-	"github.com/meowmix1337/the_recipe_book/internal/controller/validation"
 	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
 	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
 	"github.com/meowmix1337/the_recipe_book/internal/service"
@@ -16,58 +15,67 @@ import (
 
 type UserController struct {
 	*BaseController
-	UserService service.UserService
+	UserService        service.UserService
+	AdminService       service.AdminService
+	EmailChangeService service.EmailChangeService
 }
 
-func NewUserController(base *BaseController, userService service.UserService) *UserController {
+func NewUserController(
+	base *BaseController,
+	userService service.UserService,
+	adminService service.AdminService,
+	emailChangeService service.EmailChangeService,
+) *UserController {
 	return &UserController{
-		BaseController: base,
-		UserService:    userService,
+		BaseController:     base,
+		UserService:        userService,
+		AdminService:       adminService,
+		EmailChangeService: emailChangeService,
 	}
 }
 
-func (uc *UserController) AddUnprotectedRoutes(e *echo.Echo) {
-	e.POST("/signup", uc.signup)
-	e.POST("/login", uc.login)
+// AddUnprotectedRoutes registers the routes outside of the API group, auth is the middleware
+// of the API group.
+func (uc *UserController) AddUnprotectedRoutes(
+	e *echo.Echo, rateLimit echo.MiddlewareFunc, idempotent echo.MiddlewareFunc, auth ...echo.MiddlewareFunc,
+) {
+	e.POST("/signup", uc.signup, rateLimit, idempotent)
+	e.POST("/login", uc.login, rateLimit)
+	// the account is locked, so the emailed token replaces the session
+	e.POST("/unlock", uc.unlock, rateLimit)
+	// the links are opened from the mailbox, possibly without a session
+	e.POST("/email-change/confirm", uc.confirmEmailChange, rateLimit)
 
 	// logout needs the middleware since we need to retrieve the JWT claims.
-	e.POST("/logout", uc.logout, middleware.JWTMiddleware(uc.Config.GetJWTSecret(), uc.Cache))
-	e.POST("/refresh-token", uc.refreshToken, middleware.JWTMiddleware(uc.Config.GetJWTSecret(), uc.Cache))
+	e.POST("/logout", uc.logout, auth...)
+	e.POST("/refresh-token", uc.refreshToken, auth...)
 
 	// TODO: add refresh token route
 }
 
+func (uc *UserController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/users", uc.all)
+	e.GET("/"+V1+"/users/me", uc.profile)
+	e.PATCH("/"+V1+"/users/me", uc.updateProfile)
+	// the response carries new tokens
+	e.POST("/"+V1+"/users/me/password", uc.changePassword, middleware.NoIdempotentStore)
+	e.POST("/"+V1+"/users/me/email", uc.changeEmail)
+	e.DELETE("/"+V1+"/users/me/email", uc.cancelEmailChange)
+}
+
 func (uc *UserController) signup(c echo.Context) error {
 	var req endpoint.UserSignupRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, echo.Map{
-			"message": "Invalid input",
-		})
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
 	}
 
-	validationErrors := make(map[string]interface{})
 	if err := c.Validate(&req); err != nil {
-		validationErrors = validation.FormatValidationError(err)
-	}
-
-	passwordErrors := validation.ValidatePassword(req.Password)
-	if len(passwordErrors) > 0 {
-		validationErrors["password"] = passwordErrors
-	}
-
-	if len(validationErrors) > 0 {
-		return c.JSON(http.StatusBadRequest, &endpoint.UserSignupError{
-			Message: "Validation errors",
-			Errors:  validationErrors,
-		})
+		return err
 	}
 
 	err := uc.UserService.SignUp(c.Request().Context(), req.ToDomain())
 	if err != nil {
-		if errors.Is(err, domain.ErrUserAlreadyExists) {
-			return c.JSON(http.StatusBadRequest, echo.Map{"message": err.Error()})
-		}
-		return c.JSON(http.StatusInternalServerError, echo.Map{"message": "Internal Server Error"})
+		return err
 	}
 
 	return c.JSON(http.StatusCreated, echo.Map{"message": "User created successfully"})
@@ -76,46 +84,111 @@ func (uc *UserController) signup(c echo.Context) error {
 func (uc *UserController) login(c echo.Context) error {
 	var req endpoint.UserCredentialsRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, echo.Map{"message": "Invalid input"})
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
 	}
 
 	if err := c.Validate(&req); err != nil {
-		validationErrors := validation.FormatValidationError(err)
-		return c.JSON(http.StatusBadRequest, &endpoint.UserSignupError{
-			Message: "Validation errors",
-			Errors:  validationErrors,
-		})
+		return err
 	}
 
-	token, err := uc.UserService.Login(c.Request().Context(), req.ToDomain())
+	credentials := req.ToDomain()
+	credentials.Client = uc.loginClient(c)
+	token, err := uc.UserService.Login(c.Request().Context(), credentials)
 	if err != nil {
 		// we want to mask the actual error to the user
 		if uc.isUnauthorizedErr(err) {
-			return c.JSON(http.StatusUnauthorized, echo.Map{"message": "Unauthorized"})
+			return echo.NewHTTPError(http.StatusUnauthorized)
+		}
+		// the account lives in a region this instance does not serve
+		if errors.Is(err, domain.ErrUnknownRegion) {
+			return echo.NewHTTPError(http.StatusMisdirectedRequest, domain.ErrUnknownRegion.Error())
 		}
-		return c.JSON(http.StatusInternalServerError, echo.Map{"message": "Internal Server Error"})
+		if errors.Is(err, domain.ErrAccountLocked) {
+			return echo.NewHTTPError(http.StatusLocked, domain.ErrAccountLocked.Error())
+		}
+		return err
 	}
 
 	// return JWT token to be stored in client's local storage
 	return c.JSON(http.StatusOK, token)
 }
 
+// loginClient describes the client of a login for the new device alerts. Apps identify their
+// installation with X-Device-ID, the country is only read from the configured proxy header.
+func (uc *UserController) loginClient(c echo.Context) *domain.LoginClient {
+	client := &domain.LoginClient{
+		UserAgent: c.Request().UserAgent(),
+		DeviceID:  c.Request().Header.Get("X-Device-ID"),
+	}
+	if header := uc.Config.GetCountryHeader(); header != "" {
+		client.Country = domain.ParseCountry(c.Request().Header.Get(header))
+	}
+
+	return client
+}
+
+func (uc *UserController) unlock(c echo.Context) error {
+	var req endpoint.UnlockRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	err := uc.UserService.Unlock(c.Request().Context(), req.Token, req.Region)
+	if err != nil {
+		if errors.Is(err, domain.ErrUnknownRegion) {
+			return echo.NewHTTPError(http.StatusMisdirectedRequest, domain.ErrUnknownRegion.Error())
+		}
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"message": "Account unlocked",
+	})
+}
+
+func (uc *UserController) confirmEmailChange(c echo.Context) error {
+	var req endpoint.EmailChangeConfirmRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	change, err := uc.EmailChangeService.Confirm(c.Request().Context(), req.Token, req.Region)
+	if err != nil {
+		if errors.Is(err, domain.ErrUnknownRegion) {
+			return echo.NewHTTPError(http.StatusMisdirectedRequest, domain.ErrUnknownRegion.Error())
+		}
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewEmailChange(change),
+	})
+}
+
 func (uc *UserController) logout(c echo.Context) error {
 	claims, ok := c.Get("claims").(*domain.JWTCustomClaims)
 	if !ok {
 		log.Error().Msg("Failed to assert claims")
-		return c.JSON(http.StatusUnauthorized, echo.Map{"message": domain.ErrUnableToVerifyClaim.Error()})
+		return echo.NewHTTPError(http.StatusUnauthorized, domain.ErrUnableToVerifyClaim.Error())
 	}
 
 	token, ok := c.Get("jwt_token").(string)
 	if !ok {
 		log.Error().Msg("Failed to assert token")
-		return c.JSON(http.StatusInternalServerError, echo.Map{"message": domain.ErrUnableToRetrieveToken.Error()})
+		return domain.ErrUnableToRetrieveToken
 	}
 
 	err := uc.UserService.Logout(c.Request().Context(), token, claims)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, echo.Map{"message": "Internal Server Error"})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, echo.Map{
@@ -127,19 +200,19 @@ func (uc *UserController) refreshToken(c echo.Context) error {
 	claims, ok := c.Get("claims").(*domain.JWTCustomClaims)
 	if !ok {
 		log.Error().Msg("Failed to assert claims")
-		return c.JSON(http.StatusUnauthorized, echo.Map{"message": domain.ErrUnableToVerifyClaim.Error()})
+		return echo.NewHTTPError(http.StatusUnauthorized, domain.ErrUnableToVerifyClaim.Error())
 	}
 
 	jwtToken, ok := c.Get("jwt_token").(string)
 	if !ok {
 		log.Error().Msg("Failed to assert token")
-		return c.JSON(http.StatusInternalServerError, echo.Map{"message": domain.ErrUnableToRetrieveToken.Error()})
+		return domain.ErrUnableToRetrieveToken
 	}
 
 	// get refresh token from request
 	var req endpoint.UserRefreshTokenRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, echo.Map{"message": "Invalid input"})
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
 	}
 
 	user := &domain.User{
@@ -150,15 +223,145 @@ func (uc *UserController) refreshToken(c echo.Context) error {
 	token, err := uc.UserService.RefreshToken(c.Request().Context(), jwtToken, user, req.RefreshToken, claims.ExpiresAt.Time)
 	if err != nil {
 		if errors.Is(err, domain.ErrUnauthorized) {
-			return c.JSON(http.StatusUnauthorized, echo.Map{"message": "Unauthorized"})
+			return echo.NewHTTPError(http.StatusUnauthorized)
 		}
-		return c.JSON(http.StatusInternalServerError, echo.Map{"message": "Internal Server Error"})
+		return err
 	}
 
 	// return JWT token to be stored in client's local storage
 	return c.JSON(http.StatusOK, token)
 }
 
+func (uc *UserController) all(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	if err := uc.AdminService.Authorize(c.Request().Context(), claims.UserID, domain.AdminActionListUsers); err != nil {
+		return err
+	}
+
+	page, err := pageParams(c)
+	if err != nil {
+		return err
+	}
+
+	users, next, err := uc.UserService.All(c.Request().Context(), page)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data":        endpoint.NewUsers(users),
+		"next_cursor": next.Encode(),
+	})
+}
+
+func (uc *UserController) profile(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	user, err := uc.UserService.ByID(c.Request().Context(), claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewUser(user),
+	})
+}
+
+func (uc *UserController) updateProfile(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.UserProfileRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	user, err := uc.UserService.UpdateProfile(c.Request().Context(), claims.UserID, req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewUser(user),
+	})
+}
+
+// changePassword signs out every other session, the response has the tokens that replace those of
+// this one.
+func (uc *UserController) changePassword(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.PasswordChangeRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	token, err := uc.UserService.ChangePassword(c.Request().Context(), claims.UserID, req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": token})
+}
+
+func (uc *UserController) changeEmail(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.EmailChangeRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	change, err := uc.EmailChangeService.Request(c.Request().Context(), claims.UserID, req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusAccepted, echo.Map{
+		"data": endpoint.NewEmailChange(change),
+	})
+}
+
+func (uc *UserController) cancelEmailChange(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	if err := uc.EmailChangeService.Cancel(c.Request().Context(), claims.UserID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
 func (uc *UserController) isUnauthorizedErr(err error) bool {
 	return errors.Is(err, domain.ErrInvalidCredentials) ||
 		errors.Is(err, domain.ErrNoCredentialsProvided) ||
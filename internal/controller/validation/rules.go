@@ -0,0 +1,70 @@
+package validation
+
+import (
+	"net/mail"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-playground/validator"
+)
+
+const (
+	// tagNameSymbols are the characters other than letters, numbers and spaces tag names may
+	// have, the slash separates tag groups.
+	tagNameSymbols = "-_/#.&+:@'"
+	// dueDateGrace lets due dates earlier today pass, clients send all-day due dates as the
+	// start of the day in the time zone of the user.
+	dueDateGrace = 24 * time.Hour
+)
+
+//nolint:gochecknoglobals // registered by New
+var rules = map[string]validator.Func{
+	"strong_password": strongPassword,
+	"rfc_email":       rfcEmail,
+	"future":          future,
+	"tag_name":        tagName,
+}
+
+// strongPassword applies the password policy of ValidatePassword.
+func strongPassword(fl validator.FieldLevel) bool {
+	return len(ValidatePassword(fl.Field().String())) == 0
+}
+
+// rfcEmail accepts a bare RFC 5322 address. Display names, comments and IP literal domains are
+// refused since the address is stored and mailed to as is.
+func rfcEmail(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+	address, err := mail.ParseAddress(value)
+	if err != nil || address.Address != value || address.Name != "" {
+		return false
+	}
+
+	_, domain, _ := strings.Cut(address.Address, "@")
+
+	return domain != "" && !strings.HasPrefix(domain, "[")
+}
+
+// future accepts times that haven't passed, give or take a day for time zones.
+func future(fl validator.FieldLevel) bool {
+	t, ok := fl.Field().Interface().(time.Time)
+	if !ok {
+		return false
+	}
+
+	return t.After(time.Now().Add(-dueDateGrace))
+}
+
+// tagName accepts letters, numbers, spaces and the symbols of tagNameSymbols.
+func tagName(fl validator.FieldLevel) bool {
+	for _, char := range fl.Field().String() {
+		switch {
+		case unicode.IsLetter(char), unicode.IsNumber(char), unicode.IsMark(char), char == ' ':
+		case strings.ContainsRune(tagNameSymbols, char):
+		default:
+			return false
+		}
+	}
+
+	return true
+}
@@ -2,10 +2,13 @@ package validation
 
 import (
 	"errors"
+	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/go-playground/validator"
 	"github.com/labstack/echo/v4"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
 )
 
 type CustomValidator struct {
@@ -14,32 +17,112 @@ type CustomValidator struct {
 
 var _ echo.Validator = (*CustomValidator)(nil)
 
+// New returns a validator with the custom rules of this package registered. Fields are reported
+// by the name clients send them with rather than the Go field name.
+func New() *CustomValidator {
+	v := validator.New()
+	v.RegisterTagNameFunc(fieldName)
+	for tag, rule := range rules {
+		// registering only fails for an empty tag or a nil func
+		if err := v.RegisterValidation(tag, rule); err != nil {
+			panic(err)
+		}
+	}
+
+	return &CustomValidator{Validator: v}
+}
+
 func (cv *CustomValidator) Validate(i interface{}) error {
 	return cv.Validator.Struct(i)
 }
 
-// Custom error message for each field validation error.
-func FormatValidationError(err error) map[string]interface{} {
+// FieldErrors translates the errors of Validate into one error per failed field, nil if err
+// isn't a validation error.
+func FieldErrors(err error) []*endpoint.FieldError {
 	var validationErrors validator.ValidationErrors
 	if !errors.As(err, &validationErrors) {
 		return nil
 	}
 
-	errorsMap := make(map[string]interface{})
+	fieldErrors := make([]*endpoint.FieldError, 0, len(validationErrors))
 	for _, fieldErr := range validationErrors {
-		fieldName := fieldErr.Field()
-		key := strings.ToLower(fieldName) // Convert field name to lowercase
-		switch fieldErr.Tag() {
-		case "required":
-			errorsMap[key] = fieldName + " is required"
-		case "email":
-			errorsMap[key] = "Invalid email format"
-		case "min":
-			errorsMap[key] = fieldName + " should be at least " + fieldErr.Param() + " characters long"
-		// Add more case statements for different validation rules as needed
-		default:
-			errorsMap[key] = fieldName + " is not valid"
+		fieldErrors = append(fieldErrors, &endpoint.FieldError{
+			Field:   fieldErr.Field(),
+			Rule:    fieldErr.Tag(),
+			Param:   fieldErr.Param(),
+			Message: fieldErr.Field() + " " + message(fieldErr),
+		})
+	}
+
+	return fieldErrors
+}
+
+// message explains a failed rule in words, to follow the name of the field.
+func message(fieldErr validator.FieldError) string {
+	param := fieldErr.Param()
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "required_without":
+		return "is required without " + strings.ToLower(param)
+	case "email", "rfc_email":
+		return "must be a valid email address"
+	case "url":
+		return "must be an absolute url"
+	case "oneof":
+		return "must be one of " + strings.Join(strings.Fields(param), ", ")
+	case "min", "max", "len":
+		return sizeMessage(fieldErr)
+	case "alphanum":
+		return "must only contain letters and numbers"
+	case "hexcolor":
+		return "must be a #rrggbb hex color"
+	case "strong_password":
+		return "must be at least " + strconv.Itoa(minPasswordLength) +
+			" characters long with an uppercase and a lowercase letter, a number and a special character"
+	case "future":
+		return "must not be in the past"
+	case "tag_name":
+		return "must only contain letters, numbers, spaces and " + tagNameSymbols
+	}
+
+	return "is not valid"
+}
+
+// sizeMessage explains a failed min, max or len rule, which bound the length of strings and
+// slices and the value of numbers.
+func sizeMessage(fieldErr validator.FieldError) string {
+	var bound string
+	switch fieldErr.Tag() {
+	case "min":
+		bound = "at least "
+	case "max":
+		bound = "at most "
+	default:
+		bound = "exactly "
+	}
+
+	switch fieldErr.Kind() {
+	case reflect.String:
+		return "must be " + bound + fieldErr.Param() + " characters long"
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return "must have " + bound + fieldErr.Param() + " items"
+	}
+
+	return "must be " + bound + fieldErr.Param()
+}
+
+// fieldName names a field by its json tag, or by its query or form tag for parameters.
+func fieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "query", "form"} {
+		name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
 		}
 	}
-	return errorsMap
+
+	return strings.ToLower(field.Name)
 }
@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/api/middleware"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+	"github.com/rs/zerolog/log"
+
+	"github.com/labstack/echo/v4"
+)
+
+type VoiceController struct {
+	*BaseController
+	VoiceService service.VoiceService
+	OAuthService service.OAuthService
+}
+
+func NewVoiceController(base *BaseController, voiceService service.VoiceService, oauthService service.OAuthService) *VoiceController {
+	return &VoiceController{
+		BaseController: base,
+		VoiceService:   voiceService,
+		OAuthService:   oauthService,
+	}
+}
+
+// AddVoiceRoutes registers the routes used by smart speaker skills, they are authenticated
+// with the access token of a linked account instead of a user session.
+func (vc *VoiceController) AddVoiceRoutes(e *echo.Echo) {
+	voice := e.Group("/voice")
+	voice.Use(middleware.OAuthTokenMiddleware(vc.OAuthService.Authenticate))
+
+	voice.POST("/"+V1+"/intents", vc.intent)
+}
+
+func (vc *VoiceController) intent(c echo.Context) error {
+	grant, ok := c.Get("oauth_grant").(*domain.OAuthGrant)
+	if !ok {
+		log.Error().Msg("Failed to assert oauth grant")
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	var req endpoint.VoiceRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	voiceRequest := req.ToDomain()
+	voiceRequest.ClientID = grant.ClientID
+
+	resp, err := vc.VoiceService.Handle(c.Request().Context(), grant.UserID, voiceRequest)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewVoiceResponse(resp),
+	})
+}
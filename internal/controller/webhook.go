@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/api/middleware"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type WebhookController struct {
+	*BaseController
+	WebhookService service.WebhookService
+}
+
+func NewWebhookController(base *BaseController, webhookService service.WebhookService) *WebhookController {
+	return &WebhookController{
+		BaseController: base,
+		WebhookService: webhookService,
+	}
+}
+
+func (wc *WebhookController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/webhooks", wc.all)
+	e.POST("/"+V1+"/webhooks", wc.create, middleware.NoIdempotentStore)
+	e.GET("/"+V1+"/webhooks/:uuid", wc.byUUID)
+	e.DELETE("/"+V1+"/webhooks/:uuid", wc.delete)
+	e.GET("/"+V1+"/webhooks/:uuid/deliveries", wc.deliveries)
+}
+
+func (wc *WebhookController) all(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	webhooks, err := wc.WebhookService.All(c.Request().Context(), claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewWebhooks(webhooks)})
+}
+
+func (wc *WebhookController) create(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.WebhookRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	webhook, err := wc.WebhookService.Create(c.Request().Context(), claims.UserID, req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	// the secret is only returned once, receivers need it to verify the signatures
+	resp := endpoint.NewWebhook(webhook)
+	resp.Secret = webhook.Secret
+
+	return c.JSON(http.StatusCreated, echo.Map{"data": resp})
+}
+
+func (wc *WebhookController) byUUID(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	webhook, err := wc.WebhookService.ByUUID(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{"data": endpoint.NewWebhook(webhook)})
+}
+
+func (wc *WebhookController) delete(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	err := wc.WebhookService.Delete(c.Request().Context(), claims.UserID, c.Param("uuid"))
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (wc *WebhookController) deliveries(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	page, err := pageParams(c)
+	if err != nil {
+		return err
+	}
+
+	deliveries, next, err := wc.WebhookService.Deliveries(c.Request().Context(), claims.UserID, c.Param("uuid"), page)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data":        endpoint.NewWebhookDeliveries(deliveries),
+		"next_cursor": next.Encode(),
+	})
+}
@@ -0,0 +1,200 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// UsageExemptPaths are the routes that don't count as API calls of the workspace, a household
+// over its cap still sees its usage.
+var UsageExemptPaths = []string{
+	"/api/" + V1 + "/workspace/limits",
+}
+
+type WorkspaceController struct {
+	*BaseController
+	WorkspaceService service.WorkspaceService
+}
+
+func NewWorkspaceController(base *BaseController, workspaceService service.WorkspaceService) *WorkspaceController {
+	return &WorkspaceController{
+		BaseController:   base,
+		WorkspaceService: workspaceService,
+	}
+}
+
+func (wc *WorkspaceController) AddRoutes(e *echo.Group) {
+	e.GET("/"+V1+"/workspace/limits", wc.limits)
+	e.GET("/"+V1+"/workspace/calendar", wc.calendar)
+	e.PUT("/"+V1+"/workspace/working-hours", wc.setWorkingHours)
+	e.GET("/"+V1+"/workspace/holidays", wc.holidays)
+	e.POST("/"+V1+"/workspace/holidays", wc.createHoliday)
+	e.PATCH("/"+V1+"/workspace/holidays/:uuid", wc.updateHoliday)
+	e.DELETE("/"+V1+"/workspace/holidays/:uuid", wc.deleteHoliday)
+}
+
+// limits returns the caps of the workspace of the household and what the household used of
+// them, the metered resources in the current month.
+func (wc *WorkspaceController) limits(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	limits, err := wc.WorkspaceService.Limits(c.Request().Context(), claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewWorkspaceLimits(limits),
+	})
+}
+
+// calendar returns the working hours and the holidays of the workspace, every member of the
+// household can read them.
+func (wc *WorkspaceController) calendar(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	calendar, err := wc.WorkspaceService.Calendar(c.Request().Context(), claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewWorkCalendar(calendar),
+	})
+}
+
+func (wc *WorkspaceController) setWorkingHours(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.WorkingHoursRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	hours, err := wc.WorkspaceService.SetWorkingHours(c.Request().Context(), claims.UserID, req.ToDomain())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewWorkingHours(hours),
+	})
+}
+
+func (wc *WorkspaceController) holidays(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	calendar, err := wc.WorkspaceService.Calendar(c.Request().Context(), claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewWorkCalendar(calendar).Holidays,
+	})
+}
+
+func (wc *WorkspaceController) createHoliday(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.HolidayRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	date, err := domain.ParseDate(req.Date)
+	if err != nil {
+		return err
+	}
+
+	holiday, err := wc.WorkspaceService.CreateHoliday(c.Request().Context(), claims.UserID, &domain.Holiday{
+		Date:      date,
+		Name:      req.Name,
+		Recurring: req.Recurring,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, echo.Map{
+		"data": endpoint.NewHoliday(holiday),
+	})
+}
+
+func (wc *WorkspaceController) updateHoliday(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	var req endpoint.HolidayUpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid input")
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	update := &domain.HolidayUpdate{Name: req.Name, Recurring: req.Recurring}
+	if req.Date != nil {
+		if *req.Date == "" {
+			return domain.ErrInvalidDate
+		}
+		date, err := domain.ParseDate(*req.Date)
+		if err != nil {
+			return err
+		}
+		update.Date = &date
+	}
+
+	holiday, err := wc.WorkspaceService.UpdateHoliday(c.Request().Context(), claims.UserID, c.Param("uuid"), update)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, echo.Map{
+		"data": endpoint.NewHoliday(holiday),
+	})
+}
+
+func (wc *WorkspaceController) deleteHoliday(c echo.Context) error {
+	claims, ok := userClaims(c)
+	if !ok {
+		return domain.ErrUnableToVerifyClaim
+	}
+
+	if err := wc.WorkspaceService.DeleteHoliday(c.Request().Context(), claims.UserID, c.Param("uuid")); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,352 @@
+package export
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+// ManifestVersion is the schema version of export archives. It is increased whenever the
+// layout of an archive or the fields of its files change, so importers can tell what they read.
+// Version 2 added lists.json, ReadTodoArchive still reads every version before it.
+const ManifestVersion = 2
+
+const (
+	manifestFile = "manifest.json"
+	listsFile    = "lists.json"
+	todosFile    = "todos.json"
+	// attachmentsDir holds a directory per todo with the files attached to it.
+	attachmentsDir = "attachments"
+)
+
+// Manifest describes the contents of an export archive, it is the first file of the archive.
+type Manifest struct {
+	SchemaVersion int               `json:"schema_version"`
+	ExportedAt    time.Time         `json:"exported_at"`
+	Selection     ManifestSelection `json:"selection"`
+	Todos         int               `json:"todos"`
+	Files         []*ManifestFile   `json:"files"`
+}
+
+// ManifestSelection is the selection the archive was exported with.
+type ManifestSelection struct {
+	Lists         []string   `json:"lists"`
+	Tags          []string   `json:"tags"`
+	CreatedFrom   *time.Time `json:"created_from"`
+	CreatedBefore *time.Time `json:"created_before"`
+	Completed     string     `json:"completed"`
+	Archived      string     `json:"archived"`
+	Attachments   bool       `json:"attachments"`
+}
+
+// ManifestFile is a file of the archive besides the manifest.
+type ManifestFile struct {
+	Path string `json:"path"`
+	// Kind is lists, todos or attachment.
+	Kind        string `json:"kind"`
+	TodoUUID    string `json:"todo_uuid,omitempty"`
+	UUID        string `json:"uuid,omitempty"`
+	Filename    string `json:"filename,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Size        int64  `json:"size,omitempty"`
+}
+
+// listRecord is an exported list, the todos name it by its UUID.
+type listRecord struct {
+	UUID string `json:"uuid"`
+	Name string `json:"name"`
+	// CompletedRetentionDays is null for lists that keep their completed todos visible.
+	CompletedRetentionDays *int `json:"completed_retention_days"`
+}
+
+func newListRecord(list *domain.List) *listRecord {
+	record := &listRecord{
+		UUID: list.UUID,
+		Name: list.Name,
+	}
+	if list.CompletedRetention > 0 {
+		days := int(list.CompletedRetention / (24 * time.Hour))
+		record.CompletedRetentionDays = &days
+	}
+
+	return record
+}
+
+// AttachmentOpener opens the content of an attachment.
+type AttachmentOpener func(ctx context.Context, attachment *domain.Attachment) (io.ReadCloser, error)
+
+// TodoArchive is what WriteTodoArchive writes, lists and attachments must belong to the todos.
+type TodoArchive struct {
+	Selection   *domain.TodoExport
+	Lists       []*domain.List
+	Todos       []*domain.Todo
+	Attachments []*domain.Attachment
+	Open        AttachmentOpener
+	ExportedAt  time.Time
+}
+
+// WriteTodoArchive writes the todos as a ZIP archive of the manifest, the todos in the JSON
+// export format and the attachments, which are streamed from storage one at a time.
+func WriteTodoArchive(ctx context.Context, w io.Writer, archive *TodoArchive) error {
+	todoUUIDs := make(map[uint]string, len(archive.Todos))
+	for _, todo := range archive.Todos {
+		todoUUIDs[todo.ID] = todo.UUID
+	}
+
+	manifest := newManifest(archive)
+	attachmentPaths := make([]string, 0, len(archive.Attachments))
+	for _, attachment := range archive.Attachments {
+		todoUUID := todoUUIDs[attachment.TodoID]
+		file := &ManifestFile{
+			Path:        attachmentPath(todoUUID, attachment),
+			Kind:        "attachment",
+			TodoUUID:    todoUUID,
+			UUID:        attachment.UUID,
+			Filename:    attachment.Filename,
+			ContentType: attachment.ContentType,
+			Size:        attachment.Size,
+		}
+		manifest.Files = append(manifest.Files, file)
+		attachmentPaths = append(attachmentPaths, file.Path)
+	}
+
+	zipWriter := zip.NewWriter(w)
+
+	out, err := zipWriter.Create(manifestFile)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err = enc.Encode(manifest); err != nil {
+		return err
+	}
+
+	if out, err = zipWriter.Create(listsFile); err != nil {
+		return err
+	}
+	lists := make([]*listRecord, 0, len(archive.Lists))
+	for _, list := range archive.Lists {
+		lists = append(lists, newListRecord(list))
+	}
+	if err = json.NewEncoder(out).Encode(lists); err != nil {
+		return err
+	}
+
+	if out, err = zipWriter.Create(todosFile); err != nil {
+		return err
+	}
+	if err = WriteTodosJSON(out, archive.Todos); err != nil {
+		return err
+	}
+
+	for i, attachment := range archive.Attachments {
+		if err = writeAttachment(ctx, zipWriter, attachmentPaths[i], attachment, archive.Open); err != nil {
+			return fmt.Errorf("attachment %s: %w", attachment.UUID, err)
+		}
+	}
+
+	return zipWriter.Close()
+}
+
+func newManifest(archive *TodoArchive) *Manifest {
+	selection := ManifestSelection{
+		Lists:       make([]string, 0),
+		Tags:        make([]string, 0),
+		Attachments: archive.Selection.Attachments,
+	}
+	selection.Lists = append(selection.Lists, archive.Selection.ListUUIDs...)
+	if filter := archive.Selection.Filter; filter != nil {
+		selection.Tags = append(selection.Tags, filter.Tags...)
+		if !filter.CreatedFrom.IsZero() {
+			selection.CreatedFrom = &filter.CreatedFrom
+		}
+		if !filter.CreatedBefore.IsZero() {
+			selection.CreatedBefore = &filter.CreatedBefore
+		}
+		selection.Completed = string(filter.Completed)
+		selection.Archived = string(filter.Archived)
+	}
+
+	return &Manifest{
+		SchemaVersion: ManifestVersion,
+		ExportedAt:    archive.ExportedAt.UTC(),
+		Selection:     selection,
+		Todos:         len(archive.Todos),
+		Files:         []*ManifestFile{{Path: listsFile, Kind: "lists"}, {Path: todosFile, Kind: "todos"}},
+	}
+}
+
+// attachmentPath keeps the attachments of a todo together. The UUID keeps files of the same
+// name apart and the filename is stripped of directories, so it can't escape the directory.
+func attachmentPath(todoUUID string, attachment *domain.Attachment) string {
+	filename := path.Base(strings.ReplaceAll(attachment.Filename, `\`, "/"))
+	if filename == "." || filename == "/" || filename == ".." {
+		filename = "file"
+	}
+
+	return path.Join(attachmentsDir, todoUUID, attachment.UUID+"-"+filename)
+}
+
+func writeAttachment(ctx context.Context, zipWriter *zip.Writer, name string, attachment *domain.Attachment, open AttachmentOpener) error {
+	body, err := open(ctx, attachment)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	// attachments are mostly compressed formats already
+	out, err := zipWriter.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Store,
+		Modified: attachment.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, body)
+
+	return err
+}
+
+// ReadTodoArchive reads an export archive of any schema version up to ManifestVersion as a
+// backup, so the todos are restored as they were exported, e.g. on another instance. Version 1
+// archives don't have the lists, their lists are named after their UUIDs.
+func ReadTodoArchive(r io.ReaderAt, size int64) (*domain.Backup, error) {
+	zipReader, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrInvalidBackup, err)
+	}
+	files := make(map[string]*zip.File, len(zipReader.File))
+	for _, file := range zipReader.File {
+		files[file.Name] = file
+	}
+
+	var manifest Manifest
+	if err = readArchiveJSON(files, manifestFile, &manifest); err != nil {
+		return nil, err
+	}
+	if manifest.SchemaVersion < 1 || manifest.SchemaVersion > ManifestVersion {
+		return nil, fmt.Errorf("%w: schema version %d is not supported, this server reads up to %d",
+			domain.ErrInvalidBackup, manifest.SchemaVersion, ManifestVersion)
+	}
+
+	var lists []*listRecord
+	if manifest.SchemaVersion >= 2 {
+		if err = readArchiveJSON(files, listsFile, &lists); err != nil {
+			return nil, err
+		}
+	}
+
+	var todos []*todoRecord
+	if err = readArchiveJSON(files, todosFile, &todos); err != nil {
+		return nil, err
+	}
+
+	attachments, err := archiveAttachments(files, manifest.Files)
+	if err != nil {
+		return nil, err
+	}
+
+	return newArchiveBackup(lists, todos, attachments), nil
+}
+
+func readArchiveJSON(files map[string]*zip.File, name string, v interface{}) error {
+	file, ok := files[name]
+	if !ok {
+		return fmt.Errorf("%w: no %s, not an export archive", domain.ErrInvalidBackup, name)
+	}
+
+	body, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", domain.ErrInvalidBackup, name, err)
+	}
+	defer body.Close()
+
+	if err = json.NewDecoder(body).Decode(v); err != nil {
+		return fmt.Errorf("%w: %s: %w", domain.ErrInvalidBackup, name, err)
+	}
+
+	return nil
+}
+
+// archiveAttachments returns the attachments of the manifest by the UUIDs of their todos.
+func archiveAttachments(files map[string]*zip.File, manifestFiles []*ManifestFile) (map[string][]*domain.BackupAttachment, error) {
+	attachments := make(map[string][]*domain.BackupAttachment)
+	for _, manifestFile := range manifestFiles {
+		if manifestFile.Kind != "attachment" {
+			continue
+		}
+
+		file, ok := files[manifestFile.Path]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s is missing", domain.ErrInvalidBackup, manifestFile.Path)
+		}
+		attachments[manifestFile.TodoUUID] = append(attachments[manifestFile.TodoUUID], &domain.BackupAttachment{
+			Filename:    manifestFile.Filename,
+			ContentType: manifestFile.ContentType,
+			Size:        int64(file.UncompressedSize64),
+			Open:        file.Open,
+		})
+	}
+
+	return attachments, nil
+}
+
+func newArchiveBackup(lists []*listRecord, todos []*todoRecord, attachments map[string][]*domain.BackupAttachment) *domain.Backup {
+	backup := &domain.Backup{}
+	backupLists := make(map[string]*domain.BackupList, len(lists))
+	for _, record := range lists {
+		list := &domain.BackupList{Name: record.Name}
+		if record.CompletedRetentionDays != nil {
+			list.CompletedRetention = time.Duration(*record.CompletedRetentionDays) * 24 * time.Hour
+		}
+		backupLists[record.UUID] = list
+		backup.Lists = append(backup.Lists, list)
+	}
+
+	for _, record := range todos {
+		todo := &domain.BackupTodo{
+			Title:       record.Title,
+			Description: record.Description,
+			Priority:    domain.PriorityMedium,
+			Tags:        record.Tags,
+			CreatedAt:   record.CreatedAt,
+			Attachments: attachments[record.UUID],
+		}
+		if priority, err := domain.ParsePriority(record.Priority); err == nil {
+			todo.Priority = priority
+		}
+		if record.DueDate != nil {
+			todo.DueDate = *record.DueDate
+		}
+		if record.Estimate != nil {
+			todo.Estimate = time.Duration(*record.Estimate) * time.Minute
+		}
+		if record.CompletedAt != nil {
+			todo.Completed = true
+			todo.CompletedAt = *record.CompletedAt
+		}
+
+		if record.ListUUID == "" {
+			backup.Unlisted = append(backup.Unlisted, todo)
+			continue
+		}
+		list, ok := backupLists[record.ListUUID]
+		if !ok {
+			list = &domain.BackupList{Name: "List " + record.ListUUID}
+			backupLists[record.ListUUID] = list
+			backup.Lists = append(backup.Lists, list)
+		}
+		list.Todos = append(list.Todos, todo)
+	}
+
+	return backup
+}
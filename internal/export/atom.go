@@ -0,0 +1,107 @@
+package export
+
+import (
+	"encoding/xml"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+const atomNamespace = "http://www.w3.org/2005/Atom"
+
+type atomFeed struct {
+	XMLName xml.Name     `xml:"feed"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	ID      string       `xml:"id"`
+	Title   string       `xml:"title"`
+	Updated string       `xml:"updated"`
+	Author  atomAuthor   `xml:"author"`
+	Link    atomLink     `xml:"link"`
+	Entries []*atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Categories []atomCategory `xml:"category"`
+	Content    atomContent    `xml:"content"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// RenderAtom renders the recent activity of a list as an Atom (RFC 4287) feed, selfURL is the
+// URL the feed is read from without its token. Every addition and completion is an entry of its
+// own, so readers show a completed todo again even when they saw it being added.
+func RenderAtom(feed *domain.ListFeed, selfURL string, now time.Time) ([]byte, error) {
+	updated := feed.List.UpdatedAt
+	if len(feed.Entries) > 0 && feed.Entries[0].At.After(updated) {
+		updated = feed.Entries[0].At
+	}
+	if updated.IsZero() {
+		updated = now
+	}
+
+	atom := &atomFeed{
+		Xmlns:   atomNamespace,
+		ID:      "urn:todo:list:" + feed.List.UUID,
+		Title:   feed.List.Name,
+		Updated: updated.UTC().Format(time.RFC3339),
+		Author:  atomAuthor{Name: feed.List.Name},
+		Link:    atomLink{Rel: "self", Href: selfURL},
+		Entries: make([]*atomEntry, 0, len(feed.Entries)),
+	}
+
+	for _, entry := range feed.Entries {
+		atom.Entries = append(atom.Entries, newAtomEntry(entry))
+	}
+
+	out, err := xml.MarshalIndent(atom, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), out...), nil
+}
+
+func newAtomEntry(entry *domain.FeedEntry) *atomEntry {
+	title := "Added: " + entry.Todo.Title
+	if entry.Kind == domain.FeedEntryCompleted {
+		title = "Completed: " + entry.Todo.Title
+	}
+
+	content := entry.Todo.Description
+	if content == "" {
+		content = entry.Todo.Title
+	}
+
+	atom := &atomEntry{
+		// the todo and kind identify an entry, a todo that is reopened and completed again
+		// updates its completion entry instead of adding one
+		ID:      "urn:todo:todo:" + entry.Todo.UUID + ":" + string(entry.Kind),
+		Title:   title,
+		Updated: entry.At.UTC().Format(time.RFC3339),
+		Content: atomContent{Type: "text", Body: content},
+	}
+	for _, tag := range entry.Todo.Tags {
+		atom.Categories = append(atom.Categories, atomCategory{Term: tag.Name})
+	}
+
+	return atom
+}
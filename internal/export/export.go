@@ -0,0 +1,85 @@
+package export
+
+import (
+	"bytes"
+	"embed"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+//go:embed templates/*
+var templateFS embed.FS
+
+//nolint:gochecknoglobals // templates are parsed once
+var (
+	textTemplates = texttemplate.Must(texttemplate.New("").Funcs(texttemplate.FuncMap{
+		"date": formatDate,
+	}).ParseFS(templateFS, "templates/*.txt.tmpl"))
+	htmlTemplates = htmltemplate.Must(htmltemplate.New("").Funcs(htmltemplate.FuncMap{
+		"date":      formatDate,
+		"clock":     plannerTime,
+		"weekRange": weekRange,
+	}).ParseFS(templateFS, "templates/*.html.tmpl"))
+)
+
+// Rendered holds the text and HTML rendering of the same template.
+type Rendered struct {
+	Text string
+	HTML string
+}
+
+type ListSnapshot struct {
+	List        *domain.List
+	Open        []*domain.Todo
+	Completed   []*domain.Todo
+	GeneratedAt time.Time
+}
+
+func NewListSnapshot(list *domain.List, todos []*domain.Todo) *ListSnapshot {
+	snapshot := &ListSnapshot{
+		List:        list,
+		GeneratedAt: time.Now().UTC(),
+	}
+
+	for _, todo := range todos {
+		if todo.Completed() {
+			snapshot.Completed = append(snapshot.Completed, todo)
+			continue
+		}
+		snapshot.Open = append(snapshot.Open, todo)
+	}
+
+	return snapshot
+}
+
+func RenderListSnapshot(snapshot *ListSnapshot) (*Rendered, error) {
+	return render("list_snapshot", snapshot)
+}
+
+func render(name string, data interface{}) (*Rendered, error) {
+	var text bytes.Buffer
+	if err := textTemplates.ExecuteTemplate(&text, name+".txt.tmpl", data); err != nil {
+		return nil, err
+	}
+
+	var html bytes.Buffer
+	if err := htmlTemplates.ExecuteTemplate(&html, name+".html.tmpl", data); err != nil {
+		return nil, err
+	}
+
+	return &Rendered{
+		Text: text.String(),
+		HTML: html.String(),
+	}, nil
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format("Mon, Jan 2 2006")
+}
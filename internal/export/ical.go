@@ -0,0 +1,129 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+const (
+	// icalLineLength is the longest content line in octets, longer lines are folded.
+	icalLineLength = 75
+	icalTimeLayout = "20060102T150405Z"
+)
+
+//nolint:gochecknoglobals // lookup table
+var icalPriorities = map[domain.Priority]string{
+	domain.PriorityLow:    "9",
+	domain.PriorityMedium: "5",
+	domain.PriorityHigh:   "3",
+	domain.PriorityUrgent: "1",
+}
+
+//nolint:gochecknoglobals // replaced once
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// RenderCalendar renders the todos with a due date as an iCalendar (RFC 5545) feed. Events
+// last as long as the estimate of their todo and leave out completed todos, tasks keep them
+// so calendar apps can tick them off.
+func RenderCalendar(name string, todos []*domain.Todo, component domain.CalendarComponent, now time.Time) []byte {
+	var ical icalWriter
+	ical.line("BEGIN:VCALENDAR")
+	ical.line("VERSION:2.0")
+	ical.line("PRODID:-//todo//calendar export//EN")
+	ical.line("CALSCALE:GREGORIAN")
+	ical.line("METHOD:PUBLISH")
+	ical.text("X-WR-CALNAME", name)
+
+	stamp := now.UTC().Format(icalTimeLayout)
+	for _, todo := range todos {
+		if todo.DueDate.IsZero() {
+			continue
+		}
+
+		if component == domain.CalendarTodo {
+			ical.todo(todo, stamp)
+		} else if !todo.Completed() {
+			ical.event(todo, stamp)
+		}
+	}
+
+	ical.line("END:VCALENDAR")
+
+	return ical.Bytes()
+}
+
+type icalWriter struct {
+	bytes.Buffer
+}
+
+func (w *icalWriter) event(todo *domain.Todo, stamp string) {
+	estimate := todo.Estimate
+	if estimate <= 0 {
+		estimate = domain.DefaultTodoEstimate
+	}
+
+	w.line("BEGIN:VEVENT")
+	w.line("UID:" + todo.UUID + "@todo")
+	w.line("DTSTAMP:" + stamp)
+	w.line("DTSTART:" + todo.DueDate.UTC().Format(icalTimeLayout))
+	w.line("DTEND:" + todo.DueDate.Add(estimate).UTC().Format(icalTimeLayout))
+	w.common(todo)
+	w.line("TRANSP:TRANSPARENT")
+	w.line("END:VEVENT")
+}
+
+func (w *icalWriter) todo(todo *domain.Todo, stamp string) {
+	w.line("BEGIN:VTODO")
+	w.line("UID:" + todo.UUID + "@todo")
+	w.line("DTSTAMP:" + stamp)
+	w.line("DUE:" + todo.DueDate.UTC().Format(icalTimeLayout))
+	w.common(todo)
+	if todo.Completed() {
+		w.line("STATUS:COMPLETED")
+		w.line("COMPLETED:" + todo.CompletedAt.UTC().Format(icalTimeLayout))
+	} else {
+		w.line("STATUS:NEEDS-ACTION")
+	}
+	w.line("END:VTODO")
+}
+
+func (w *icalWriter) common(todo *domain.Todo) {
+	w.text("SUMMARY", todo.Title)
+	if todo.Description != "" {
+		w.text("DESCRIPTION", todo.Description)
+	}
+	if len(todo.Tags) > 0 {
+		categories := make([]string, 0, len(todo.Tags))
+		for _, tag := range todo.Tags {
+			categories = append(categories, icalEscaper.Replace(tag.Name))
+		}
+		w.line("CATEGORIES:" + strings.Join(categories, ","))
+	}
+	w.line("PRIORITY:" + icalPriorities[todo.Priority])
+	w.line("LAST-MODIFIED:" + todo.UpdatedAt.UTC().Format(icalTimeLayout))
+}
+
+func (w *icalWriter) text(property string, value string) {
+	w.line(property + ":" + icalEscaper.Replace(value))
+}
+
+// line writes a content line, folding it into continuation lines that start with a space
+// without splitting a UTF-8 sequence.
+func (w *icalWriter) line(line string) {
+	limit := icalLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		// continuation lines lose an octet to the leading space
+		limit = icalLineLength - 1
+	}
+	w.WriteString(line + "\r\n")
+}
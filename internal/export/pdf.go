@@ -0,0 +1,242 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// pdfFont is one of the standard fonts every PDF reader has, so no font has to be embedded.
+type pdfFont string
+
+const (
+	pdfRegular pdfFont = "F1"
+	pdfBold    pdfFont = "F2"
+)
+
+// pdfDocument writes a minimal PDF of text and line art in the standard Helvetica fonts, enough
+// for printouts without a PDF library. Coordinates are in points from the top left corner.
+type pdfDocument struct {
+	width  float64
+	height float64
+	pages  []*pdfPage
+}
+
+func newPDFDocument(width float64, height float64) *pdfDocument {
+	return &pdfDocument{
+		width:  width,
+		height: height,
+	}
+}
+
+type pdfPage struct {
+	height  float64
+	content bytes.Buffer
+}
+
+func (d *pdfDocument) addPage() *pdfPage {
+	page := &pdfPage{height: d.height}
+	d.pages = append(d.pages, page)
+
+	return page
+}
+
+// gray sets the stroke and fill color, 0 is black and 1 white.
+func (p *pdfPage) gray(level float64) {
+	fmt.Fprintf(&p.content, "%.2f g %.2f G\n", level, level)
+}
+
+func (p *pdfPage) line(x1 float64, y1 float64, x2 float64, y2 float64, width float64) {
+	fmt.Fprintf(&p.content, "%.2f w %.2f %.2f m %.2f %.2f l S\n", width, x1, p.height-y1, x2, p.height-y2)
+}
+
+func (p *pdfPage) rect(x float64, y float64, width float64, height float64, lineWidth float64) {
+	fmt.Fprintf(&p.content, "%.2f w %.2f %.2f %.2f %.2f re S\n", lineWidth, x, p.height-y-height, width, height)
+}
+
+// text draws s with its baseline at y.
+func (p *pdfPage) text(x float64, y float64, font pdfFont, size float64, s string) {
+	fmt.Fprintf(&p.content, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, p.height-y, pdfEscape(winAnsi(s)))
+}
+
+func (d *pdfDocument) writeTo(w io.Writer) error {
+	out := &pdfCounter{w: bufio.NewWriter(w)}
+	var offsets []int
+
+	object := func(body string) {
+		offsets = append(offsets, out.n)
+		fmt.Fprintf(out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	// the catalog, the page tree and the fonts come first, every page adds a page and its
+	// content stream
+	kids := make([]string, 0, len(d.pages))
+	for i := range d.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", 5+2*i))
+	}
+
+	fmt.Fprint(out, "%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d /MediaBox [0 0 %.2f %.2f] >>",
+		strings.Join(kids, " "), len(d.pages), d.width, d.height))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>", 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.content.Len(), page.content.Bytes()))
+	}
+
+	xref := out.n
+	fmt.Fprintf(out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	if out.err != nil {
+		return out.err
+	}
+
+	return out.w.Flush()
+}
+
+// pdfCounter counts the bytes written for the cross-reference table and keeps the first error.
+type pdfCounter struct {
+	w   *bufio.Writer
+	n   int
+	err error
+}
+
+func (c *pdfCounter) Write(b []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(b)
+	c.n += n
+	c.err = err
+
+	return n, err
+}
+
+func pdfEscape(b []byte) string {
+	var escaped strings.Builder
+	for _, c := range b {
+		switch c {
+		case '(', ')', '\\':
+			escaped.WriteByte('\\')
+			escaped.WriteByte(c)
+		default:
+			escaped.WriteByte(c)
+		}
+	}
+
+	return escaped.String()
+}
+
+//nolint:gochecknoglobals // lookup table
+var winAnsiSpecials = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88,
+	'‰': 0x89, 'Š': 0x8a, '‹': 0x8b, 'Œ': 0x8c, 'Ž': 0x8e, '‘': 0x91, '’': 0x92, '“': 0x93,
+	'”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9a, '›': 0x9b,
+	'œ': 0x9c, 'ž': 0x9e, 'Ÿ': 0x9f,
+}
+
+// winAnsi encodes s for the standard fonts, characters they don't have become "?".
+func winAnsi(s string) []byte {
+	encoded := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r == '\t' || r == '\n' || r == '\r':
+			encoded = append(encoded, ' ')
+		case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+			encoded = append(encoded, byte(r))
+		default:
+			if c, ok := winAnsiSpecials[r]; ok {
+				encoded = append(encoded, c)
+			} else {
+				encoded = append(encoded, '?')
+			}
+		}
+	}
+
+	return encoded
+}
+
+// helveticaWidths are the advance widths of the printable ASCII characters of Helvetica in
+// thousandths of the font size, from its Adobe font metrics.
+//
+//nolint:gochecknoglobals // lookup table
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278, // space to /
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556, // 0 to ?
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778, // @ to O
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556, // P to _
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556, // ` to o
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584, // p to ~
+}
+
+// textWidth estimates the width of s in points, bold is wider by about six percent.
+func textWidth(s string, font pdfFont, size float64) float64 {
+	var width int
+	for _, c := range winAnsi(s) {
+		if c >= 0x20 && c < 0x7f {
+			width += helveticaWidths[c-0x20]
+		} else {
+			width += 556
+		}
+	}
+	if font == pdfBold {
+		width = width * 106 / 100
+	}
+
+	return float64(width) * size / 1000
+}
+
+// wrapText breaks s into at most maxLines lines of maxWidth, breaking at spaces where it can.
+// A text that doesn't fit ends in an ellipsis.
+func wrapText(s string, font pdfFont, size float64, maxWidth float64, maxLines int) []string {
+	var lines []string
+	rest := strings.Join(strings.Fields(s), " ")
+	for rest != "" && len(lines) < maxLines {
+		if textWidth(rest, font, size) <= maxWidth {
+			lines = append(lines, rest)
+			rest = ""
+			break
+		}
+
+		// the longest prefix that fits, preferably ending before a space
+		end, space := 0, 0
+		for i, r := range rest {
+			next := i + utf8.RuneLen(r)
+			if textWidth(rest[:next], font, size) > maxWidth {
+				break
+			}
+			end = next
+			if r == ' ' {
+				space = i
+			}
+		}
+		if space > 0 {
+			end = space
+		}
+		if end == 0 {
+			_, end = utf8.DecodeRuneInString(rest)
+		}
+		lines = append(lines, rest[:end])
+		rest = strings.TrimLeft(rest[end:], " ")
+	}
+
+	if rest != "" && len(lines) > 0 {
+		last := lines[len(lines)-1]
+		for last != "" && textWidth(last+"…", font, size) > maxWidth {
+			_, n := utf8.DecodeLastRuneInString(last)
+			last = last[:len(last)-n]
+		}
+		lines[len(lines)-1] = strings.TrimRight(last, " ") + "…"
+	}
+
+	return lines
+}
@@ -0,0 +1,197 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+// WeeklyPlanner is a week at a glance, the todos due on each day of the week.
+type WeeklyPlanner struct {
+	Week        *domain.PlannerWeek
+	Days        []*PlannerDay
+	GeneratedAt time.Time
+}
+
+type PlannerDay struct {
+	// Date is midnight of the day in the timezone of the week.
+	Date  time.Time
+	Todos []*domain.Todo
+}
+
+// NewWeeklyPlanner sorts the todos into the days of the week they are due on, in the timezone
+// of the week. Todos due outside the week are left out.
+func NewWeeklyPlanner(week *domain.PlannerWeek, todos []*domain.Todo) *WeeklyPlanner {
+	planner := &WeeklyPlanner{
+		Week:        week,
+		GeneratedAt: time.Now().UTC(),
+	}
+
+	days := week.Days()
+	for _, date := range days {
+		planner.Days = append(planner.Days, &PlannerDay{Date: date})
+	}
+
+	for _, todo := range todos {
+		if todo.DueDate.Before(week.Start) || !todo.DueDate.Before(week.End()) {
+			continue
+		}
+		for i := len(days) - 1; i >= 0; i-- {
+			if !todo.DueDate.Before(days[i]) {
+				planner.Days[i].Todos = append(planner.Days[i].Todos, todo)
+				break
+			}
+		}
+	}
+
+	return planner
+}
+
+// Location is the timezone the planner is printed in.
+func (p *WeeklyPlanner) Location() *time.Location {
+	return p.Week.Start.Location()
+}
+
+// WriteWeeklyPlannerHTML writes the planner as a standalone HTML page laid out for printing.
+func WriteWeeklyPlannerHTML(w io.Writer, planner *WeeklyPlanner) error {
+	return htmlTemplates.ExecuteTemplate(w, "weekly_planner.html.tmpl", planner)
+}
+
+// plannerTime is the time of day a todo is due, empty for todos due at midnight since due dates
+// without a time are stored as midnight.
+func plannerTime(todo *domain.Todo, location *time.Location) string {
+	due := todo.DueDate.In(location)
+	if due.Hour() == 0 && due.Minute() == 0 {
+		return ""
+	}
+
+	return due.Format("15:04")
+}
+
+// weekRange names the week like "Oct 12 – 18, 2026", spelling out months and years only when
+// they change within the week.
+func weekRange(week *domain.PlannerWeek) string {
+	first, last := week.Start, week.End().AddDate(0, 0, -1)
+	switch {
+	case first.Year() != last.Year():
+		return first.Format("Jan 2, 2006") + " – " + last.Format("Jan 2, 2006")
+	case first.Month() != last.Month():
+		return first.Format("Jan 2") + " – " + last.Format("Jan 2, 2006")
+	default:
+		return first.Format("Jan 2") + " – " + last.Format("2, 2006")
+	}
+}
+
+// the PDF planner is an A4 landscape page of two rows of four boxes, the days and a box for notes
+const (
+	plannerPageWidth  = 842
+	plannerPageHeight = 595
+	plannerMargin     = 32
+	plannerGap        = 8
+	plannerHeader     = 30
+	plannerDayHeader  = 18
+	plannerFontSize   = 9
+	plannerLineHeight = 11
+	plannerRuleHeight = 14
+	plannerCheckbox   = 7
+	plannerPadding    = 6
+)
+
+// WriteWeeklyPlannerPDF writes the planner as a single page PDF with a checkbox in front of
+// every todo and ruled lines below them for notes.
+func WriteWeeklyPlannerPDF(w io.Writer, planner *WeeklyPlanner) error {
+	doc := newPDFDocument(plannerPageWidth, plannerPageHeight)
+	page := doc.addPage()
+
+	page.text(plannerMargin, plannerMargin+16, pdfBold, 16, "Week of "+weekRange(planner.Week))
+	printed := "Printed " + formatDate(planner.GeneratedAt)
+	page.gray(0.45)
+	page.text(plannerPageWidth-plannerMargin-textWidth(printed, pdfRegular, 7), plannerMargin+16, pdfRegular, 7, printed)
+	page.gray(0)
+
+	boxWidth := (plannerPageWidth - 2*plannerMargin - 3*plannerGap) / 4.0
+	boxHeight := (plannerPageHeight - 2*plannerMargin - plannerHeader - plannerGap) / 2.0
+	box := func(i int) (float64, float64) {
+		return plannerMargin + float64(i%4)*(boxWidth+plannerGap),
+			plannerMargin + plannerHeader + float64(i/4)*(boxHeight+plannerGap)
+	}
+
+	for i, day := range planner.Days {
+		x, y := box(i)
+		plannerBox(page, x, y, boxWidth, boxHeight, day.Date.Format("Monday"), day.Date.Format("Jan 2"))
+		plannerTodos(page, x, y+plannerDayHeader, boxWidth, boxHeight-plannerDayHeader, day.Todos, planner.Location())
+	}
+
+	x, y := box(len(planner.Days))
+	plannerBox(page, x, y, boxWidth, boxHeight, "Notes", "")
+	plannerRules(page, x, y+plannerDayHeader+plannerRuleHeight, boxWidth, y+boxHeight)
+
+	return doc.writeTo(w)
+}
+
+func plannerBox(page *pdfPage, x float64, y float64, width float64, height float64, title string, date string) {
+	page.rect(x, y, width, height, 0.8)
+	page.line(x, y+plannerDayHeader, x+width, y+plannerDayHeader, 0.8)
+	page.text(x+plannerPadding, y+13, pdfBold, 11, title)
+	if date != "" {
+		page.gray(0.45)
+		page.text(x+width-plannerPadding-textWidth(date, pdfRegular, plannerFontSize), y+13, pdfRegular, plannerFontSize, date)
+		page.gray(0)
+	}
+}
+
+// plannerTodos lists the todos below the header of a day and fills the space left with ruled
+// lines, todos that don't fit are counted in a last line.
+func plannerTodos(page *pdfPage, x float64, y float64, width float64, height float64, todos []*domain.Todo, location *time.Location) {
+	bottom := y + height - plannerPadding
+	textX := x + plannerPadding + plannerCheckbox + 5
+	maxWidth := x + width - plannerPadding - textX
+
+	baseline := y + plannerPadding + plannerLineHeight - 2
+	for i, todo := range todos {
+		title := todo.Title
+		if clock := plannerTime(todo, location); clock != "" {
+			title = clock + " " + title
+		}
+		lines := wrapText(title, pdfRegular, plannerFontSize, maxWidth, 2)
+
+		// keep a line for the count of the todos that don't fit
+		needed := float64(len(lines)) * plannerLineHeight
+		if i < len(todos)-1 {
+			needed += plannerLineHeight
+		}
+		if baseline+needed-plannerLineHeight > bottom {
+			page.gray(0.45)
+			page.text(textX, baseline, pdfRegular, plannerFontSize, fmt.Sprintf("+%d more", len(todos)-i))
+			page.gray(0)
+			return
+		}
+
+		boxTop := baseline - plannerCheckbox
+		page.rect(x+plannerPadding, boxTop, plannerCheckbox, plannerCheckbox, 0.6)
+		if todo.Completed() {
+			page.line(x+plannerPadding+1.5, boxTop+1.5, x+plannerPadding+plannerCheckbox-1.5, boxTop+plannerCheckbox-1.5, 0.8)
+			page.line(x+plannerPadding+1.5, boxTop+plannerCheckbox-1.5, x+plannerPadding+plannerCheckbox-1.5, boxTop+1.5, 0.8)
+			page.gray(0.45)
+		}
+		for _, line := range lines {
+			page.text(textX, baseline, pdfRegular, plannerFontSize, line)
+			baseline += plannerLineHeight
+		}
+		page.gray(0)
+		baseline += 2
+	}
+
+	plannerRules(page, x, baseline+plannerRuleHeight/2, width, bottom+plannerPadding)
+}
+
+// plannerRules rules the box from y down to bottom for handwritten notes.
+func plannerRules(page *pdfPage, x float64, y float64, width float64, bottom float64) {
+	page.gray(0.8)
+	for ; y < bottom-plannerPadding; y += plannerRuleHeight {
+		page.line(x+plannerPadding, y, x+width-plannerPadding, y, 0.4)
+	}
+	page.gray(0)
+}
@@ -0,0 +1,134 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+// Takeout is the personal data of a user that WriteTakeout writes.
+type Takeout struct {
+	User        *domain.User
+	Lists       []*domain.List
+	Todos       []*domain.Todo
+	Comments    []*domain.Comment
+	Attachments []*domain.Attachment
+	ExportedAt  time.Time
+}
+
+type profileRecord struct {
+	UUID      string    `json:"uuid"`
+	Email     string    `json:"email,omitempty"`
+	Username  string    `json:"username,omitempty"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Timezone  string    `json:"timezone"`
+	Locale    string    `json:"locale,omitempty"`
+	AvatarURL string    `json:"avatar_url,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// ExportedAt is when the takeout was assembled.
+	ExportedAt time.Time `json:"exported_at"`
+}
+
+type commentRecord struct {
+	UUID      string    `json:"uuid"`
+	TodoUUID  string    `json:"todo_uuid"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// attachmentRecord describes an attached file, the takeout lists the files without their content.
+type attachmentRecord struct {
+	UUID        string    `json:"uuid"`
+	TodoUUID    string    `json:"todo_uuid"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// WriteTakeout writes the data of the user as a ZIP archive of JSON files: the profile, the lists,
+// the todos in the JSON export format, the comments the user posted and a manifest of the
+// attachments.
+func WriteTakeout(w io.Writer, takeout *Takeout) error {
+	todoUUIDs := make(map[uint]string, len(takeout.Todos))
+	for _, todo := range takeout.Todos {
+		todoUUIDs[todo.ID] = todo.UUID
+	}
+
+	lists := make([]*listRecord, 0, len(takeout.Lists))
+	for _, list := range takeout.Lists {
+		lists = append(lists, newListRecord(list))
+	}
+
+	comments := make([]*commentRecord, 0, len(takeout.Comments))
+	for _, comment := range takeout.Comments {
+		comments = append(comments, &commentRecord{
+			UUID:      comment.UUID,
+			TodoUUID:  comment.TodoUUID,
+			Body:      comment.Body,
+			CreatedAt: comment.CreatedAt,
+			UpdatedAt: comment.UpdatedAt,
+		})
+	}
+
+	attachments := make([]*attachmentRecord, 0, len(takeout.Attachments))
+	for _, attachment := range takeout.Attachments {
+		attachments = append(attachments, &attachmentRecord{
+			UUID:        attachment.UUID,
+			TodoUUID:    todoUUIDs[attachment.TodoID],
+			Filename:    attachment.Filename,
+			ContentType: attachment.ContentType,
+			Size:        attachment.Size,
+			CreatedAt:   attachment.CreatedAt,
+		})
+	}
+
+	zipWriter := zip.NewWriter(w)
+
+	files := []struct {
+		name string
+		v    interface{}
+	}{
+		{"profile.json", &profileRecord{
+			UUID:       takeout.User.UUID,
+			Email:      takeout.User.Email,
+			Username:   takeout.User.Username,
+			FirstName:  takeout.User.FirstName,
+			LastName:   takeout.User.LastName,
+			Timezone:   takeout.User.Timezone,
+			Locale:     takeout.User.Locale,
+			AvatarURL:  takeout.User.AvatarURL,
+			CreatedAt:  takeout.User.CreatedAt,
+			ExportedAt: takeout.ExportedAt.UTC(),
+		}},
+		{listsFile, lists},
+		{"comments.json", comments},
+		{"attachments.json", attachments},
+	}
+	for _, file := range files {
+		out, err := zipWriter.Create(file.name)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		if err = enc.Encode(file.v); err != nil {
+			return err
+		}
+	}
+
+	out, err := zipWriter.Create(todosFile)
+	if err != nil {
+		return err
+	}
+	if err = WriteTodosJSON(out, takeout.Todos); err != nil {
+		return err
+	}
+
+	return zipWriter.Close()
+}
@@ -0,0 +1,398 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+const (
+	maxTodoTitleLength = 255
+	maxTodoTagLength   = 64
+	maxTodoEstimate    = 1440
+)
+
+// todoRecord is an exported todo, its JSON keys are the columns of the CSV export.
+type todoRecord struct {
+	UUID        string     `json:"uuid"`
+	ListUUID    string     `json:"list_uuid"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Priority    string     `json:"priority"`
+	DueDate     *time.Time `json:"due_date"`
+	Estimate    *int       `json:"estimate_minutes"`
+	Tags        []string   `json:"tags"`
+	CompletedAt *time.Time `json:"completed_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func newTodoRecord(todo *domain.Todo) *todoRecord {
+	record := &todoRecord{
+		UUID:        todo.UUID,
+		ListUUID:    todo.ListUUID,
+		Title:       todo.Title,
+		Description: todo.Description,
+		Priority:    todo.Priority.String(),
+		Tags:        make([]string, 0, len(todo.Tags)),
+		CreatedAt:   todo.CreatedAt,
+	}
+	if !todo.DueDate.IsZero() {
+		record.DueDate = &todo.DueDate
+	}
+	if todo.Estimate > 0 {
+		minutes := int(todo.Estimate.Minutes())
+		record.Estimate = &minutes
+	}
+	for _, tag := range todo.Tags {
+		record.Tags = append(record.Tags, tag.Name)
+	}
+	if todo.Completed() {
+		record.CompletedAt = &todo.CompletedAt
+	}
+
+	return record
+}
+
+// WriteTodosJSON writes the todos as a JSON array, one todo at a time.
+func WriteTodosJSON(w io.Writer, todos []*domain.Todo) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	for i, todo := range todos {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		if err := enc.Encode(newTodoRecord(todo)); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "]\n")
+
+	return err
+}
+
+// WriteTodosCSV writes the todos as CSV with a header of domain.TodoExportFields. Tags are
+// joined by commas.
+func WriteTodosCSV(w io.Writer, todos []*domain.Todo) error {
+	csvWriter := csv.NewWriter(w)
+	if err := csvWriter.Write(domain.TodoExportFields); err != nil {
+		return err
+	}
+
+	for _, todo := range todos {
+		record := newTodoRecord(todo)
+		row := []string{
+			record.UUID,
+			record.ListUUID,
+			csvText(record.Title),
+			csvText(record.Description),
+			record.Priority,
+			csvTime(record.DueDate),
+			"",
+			csvText(strings.Join(record.Tags, ",")),
+			csvTime(record.CompletedAt),
+			record.CreatedAt.UTC().Format(time.RFC3339),
+		}
+		if record.Estimate != nil {
+			row[6] = strconv.Itoa(*record.Estimate)
+		}
+		if err := csvWriter.Write(row); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+
+	return csvWriter.Error()
+}
+
+// csvText keeps spreadsheets from evaluating user input as a formula.
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+
+	return value
+}
+
+// csvUnescape reverts csvText.
+func csvUnescape(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune("=+-@\t\r", rune(value[1])) {
+		return value[1:]
+	}
+
+	return value
+}
+
+func csvTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+// TodoReader streams the todos of an imported file.
+type TodoReader interface {
+	// Next returns the next todo and io.EOF after the last one. A *domain.TodoImportError only
+	// rejects its row, any other error is wrapped in domain.ErrInvalidTodoImport and ends the file.
+	Next() (*domain.TodoImportRow, error)
+}
+
+// NewTodoReader reads todos of the format from r, the mapping names the column or key each
+// field is read from.
+func NewTodoReader(r io.Reader, format domain.TodoFormat, mapping domain.TodoMapping) (TodoReader, error) {
+	switch format {
+	case domain.TodoFormatCSV:
+		return newCSVTodoReader(r, mapping)
+	case domain.TodoFormatJSON:
+		return newJSONTodoReader(r, mapping)
+	case domain.TodoFormatZIP:
+		return nil, fmt.Errorf("archives can't be imported: %w", domain.ErrInvalidTodoFormat)
+	default:
+		return nil, fmt.Errorf("%q: %w", format, domain.ErrInvalidTodoFormat)
+	}
+}
+
+// todoImportFields are the fields an import reads, the others are assigned anew.
+//
+//nolint:gochecknoglobals // read-only
+var todoImportFields = []string{
+	domain.TodoFieldListUUID, domain.TodoFieldTitle, domain.TodoFieldDescription, domain.TodoFieldPriority,
+	domain.TodoFieldDueDate, domain.TodoFieldEstimate, domain.TodoFieldCompletedAt,
+}
+
+type csvTodoReader struct {
+	csv     *csv.Reader
+	columns map[string]int
+	mapping domain.TodoMapping
+}
+
+func newCSVTodoReader(r io.Reader, mapping domain.TodoMapping) (*csvTodoReader, error) {
+	csvReader := csv.NewReader(r)
+	// rows with missing columns are rejected one by one instead of failing the file
+	csvReader.FieldsPerRecord = -1
+
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading header: %w", errors.Join(domain.ErrInvalidTodoImport, err))
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		// spreadsheets start UTF-8 files with a byte order mark
+		if i == 0 {
+			column = strings.TrimPrefix(column, "\ufeff")
+		}
+		columns[strings.TrimSpace(column)] = i
+	}
+	if _, ok := columns[mapping.Column(domain.TodoFieldTitle)]; !ok {
+		return nil, fmt.Errorf("no %q column: %w", mapping.Column(domain.TodoFieldTitle), domain.ErrInvalidTodoImport)
+	}
+
+	return &csvTodoReader{
+		csv:     csvReader,
+		columns: columns,
+		mapping: mapping,
+	}, nil
+}
+
+func (r *csvTodoReader) Next() (*domain.TodoImportRow, error) {
+	record, err := r.csv.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, errors.Join(domain.ErrInvalidTodoImport, err)
+	}
+	line, _ := r.csv.FieldPos(0)
+
+	// spreadsheets leave out empty trailing columns, they read as empty
+	values := make(map[string]string, len(todoImportFields)+1)
+	for _, field := range append([]string{domain.TodoFieldTags}, todoImportFields...) {
+		if i, ok := r.columns[r.mapping.Column(field)]; ok && i < len(record) {
+			values[field] = csvUnescape(record[i])
+		}
+	}
+
+	return parseTodoRow(line, values, splitTags(values[domain.TodoFieldTags]))
+}
+
+type jsonTodoReader struct {
+	dec     *json.Decoder
+	mapping domain.TodoMapping
+	line    int
+}
+
+func newJSONTodoReader(r io.Reader, mapping domain.TodoMapping) (*jsonTodoReader, error) {
+	dec := json.NewDecoder(r)
+	token, err := dec.Token()
+	if err != nil {
+		return nil, errors.Join(domain.ErrInvalidTodoImport, err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return nil, fmt.Errorf("expected an array of todos: %w", domain.ErrInvalidTodoImport)
+	}
+
+	return &jsonTodoReader{
+		dec:     dec,
+		mapping: mapping,
+	}, nil
+}
+
+func (r *jsonTodoReader) Next() (*domain.TodoImportRow, error) {
+	if !r.dec.More() {
+		if _, err := r.dec.Token(); err != nil {
+			return nil, errors.Join(domain.ErrInvalidTodoImport, err)
+		}
+		return nil, io.EOF
+	}
+
+	var object map[string]json.RawMessage
+	if err := r.dec.Decode(&object); err != nil {
+		return nil, errors.Join(domain.ErrInvalidTodoImport, err)
+	}
+	r.line++
+
+	values := make(map[string]string, len(todoImportFields))
+	for _, field := range todoImportFields {
+		raw, ok := object[r.mapping.Column(field)]
+		if !ok {
+			continue
+		}
+		value, err := jsonScalar(raw)
+		if err != nil {
+			return nil, &domain.TodoImportError{Line: r.line, Message: fmt.Sprintf("%s: %s", field, err)}
+		}
+		values[field] = value
+	}
+
+	// tags are an array, or a comma separated string like in the CSV export
+	var tags []string
+	if raw, ok := object[r.mapping.Column(domain.TodoFieldTags)]; ok {
+		if err := json.Unmarshal(raw, &tags); err != nil {
+			value, err := jsonScalar(raw)
+			if err != nil {
+				return nil, &domain.TodoImportError{Line: r.line, Message: "tags: expected an array of strings"}
+			}
+			tags = splitTags(value)
+		}
+	}
+
+	return parseTodoRow(r.line, values, tags)
+}
+
+// jsonScalar returns a string, number or null as a string.
+func jsonScalar(raw json.RawMessage) (string, error) {
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", err
+	}
+
+	switch value := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return value, nil
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), nil
+	default:
+		return "", errors.New("expected a string or number")
+	}
+}
+
+func splitTags(value string) []string {
+	tags := make([]string, 0)
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+// parseTodoRow validates the values of a row the same way the API validates a new todo.
+func parseTodoRow(line int, values map[string]string, tags []string) (*domain.TodoImportRow, error) {
+	rowErr := func(format string, args ...interface{}) error {
+		return &domain.TodoImportError{Line: line, Message: fmt.Sprintf(format, args...)}
+	}
+
+	todo := &domain.TodoCreate{
+		ListUUID:    strings.TrimSpace(values[domain.TodoFieldListUUID]),
+		Title:       strings.TrimSpace(values[domain.TodoFieldTitle]),
+		Description: values[domain.TodoFieldDescription],
+		Priority:    domain.PriorityMedium,
+		Tags:        tags,
+	}
+	if todo.Title == "" {
+		return nil, rowErr("title is required")
+	}
+	if len(todo.Title) > maxTodoTitleLength {
+		return nil, rowErr("title is longer than %d characters", maxTodoTitleLength)
+	}
+	for _, tag := range tags {
+		if len(tag) > maxTodoTagLength {
+			return nil, rowErr("tag %q is longer than %d characters", tag, maxTodoTagLength)
+		}
+	}
+
+	if priority := strings.TrimSpace(values[domain.TodoFieldPriority]); priority != "" {
+		parsed, err := domain.ParsePriority(strings.ToLower(priority))
+		if err != nil {
+			return nil, rowErr("%s", err)
+		}
+		todo.Priority = parsed
+	}
+
+	if estimate := strings.TrimSpace(values[domain.TodoFieldEstimate]); estimate != "" {
+		minutes, err := strconv.Atoi(estimate)
+		if err != nil || minutes < 0 || minutes > maxTodoEstimate {
+			return nil, rowErr("estimate_minutes must be between 0 and %d", maxTodoEstimate)
+		}
+		todo.Estimate = time.Duration(minutes) * time.Minute
+	}
+
+	dueDate, err := parseImportTime(values[domain.TodoFieldDueDate])
+	if err != nil {
+		return nil, rowErr("due_date: %s", err)
+	}
+	todo.DueDate = dueDate
+
+	completedAt, err := parseImportTime(values[domain.TodoFieldCompletedAt])
+	if err != nil {
+		return nil, rowErr("completed_at: %s", err)
+	}
+
+	return &domain.TodoImportRow{
+		Line:        line,
+		Todo:        todo,
+		CompletedAt: completedAt,
+	}, nil
+}
+
+// parseImportTime accepts RFC 3339 timestamps and plain dates, empty is the zero time.
+func parseImportTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	if t, err := time.Parse(domain.DateLayout, value); err == nil {
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("%q is neither a RFC 3339 timestamp nor a YYYY-MM-DD date", value)
+}
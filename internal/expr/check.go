@@ -0,0 +1,177 @@
+package expr
+
+import (
+	"regexp"
+	"strings"
+)
+
+// maxPatternLength is the longest pattern matches accepts. Patterns are RE2, which matches in
+// linear time, the limit keeps compiled patterns small.
+const maxPatternLength = 256
+
+// check sets the types of the nodes, the variables and functions have to exist and the operands
+// have to fit their operators.
+func check(n *node, env Env) error {
+	for _, arg := range n.args {
+		if err := check(arg, env); err != nil {
+			return err
+		}
+	}
+
+	switch n.kind {
+	case nodeLiteral:
+		switch n.value.(type) {
+		case bool:
+			n.typ = TypeBool
+		case float64:
+			n.typ = TypeNumber
+		case string:
+			n.typ = TypeString
+		}
+	case nodeVar:
+		typ, ok := env[n.name]
+		if !ok {
+			return n.errorf("unknown variable %s", n.name)
+		}
+		n.typ = typ
+	case nodeList:
+		for _, item := range n.args {
+			if item.typ != TypeString {
+				return item.errorf("lists hold strings, not %s", item.typ)
+			}
+		}
+		n.typ = TypeList
+	case nodeUnary:
+		return checkUnary(n)
+	case nodeBinary:
+		return checkBinary(n)
+	case nodeTernary:
+		cond, then, otherwise := n.args[0], n.args[1], n.args[2]
+		if cond.typ != TypeBool {
+			return cond.errorf("condition must be bool, not %s", cond.typ)
+		}
+		if then.typ != otherwise.typ {
+			return n.errorf("branches must have the same type, not %s and %s", then.typ, otherwise.typ)
+		}
+		n.typ = then.typ
+	case nodeIndex:
+		list, index := n.args[0], n.args[1]
+		if list.typ != TypeList {
+			return n.errorf("can't index %s", list.typ)
+		}
+		if index.typ != TypeNumber {
+			return index.errorf("index must be a number, not %s", index.typ)
+		}
+		n.typ = TypeString
+	case nodeCall:
+		return checkCall(n)
+	}
+
+	return nil
+}
+
+func checkUnary(n *node) error {
+	operand := n.args[0]
+	want := TypeBool
+	if n.name == "-" {
+		want = TypeNumber
+	}
+	if operand.typ != want {
+		return n.errorf("%s needs a %s, not %s", n.name, want, operand.typ)
+	}
+	n.typ = want
+
+	return nil
+}
+
+func checkBinary(n *node) error {
+	left, right := n.args[0], n.args[1]
+
+	switch n.name {
+	case "&&", "||":
+		if left.typ != TypeBool || right.typ != TypeBool {
+			return n.errorf("%s needs bools, not %s and %s", n.name, left.typ, right.typ)
+		}
+		n.typ = TypeBool
+	case "==", "!=":
+		if left.typ != right.typ {
+			return n.errorf("can't compare %s and %s", left.typ, right.typ)
+		}
+		n.typ = TypeBool
+	case "<", "<=", ">", ">=":
+		if left.typ != right.typ || (left.typ != TypeNumber && left.typ != TypeString) {
+			return n.errorf("%s needs two numbers or two strings, not %s and %s", n.name, left.typ, right.typ)
+		}
+		n.typ = TypeBool
+	case "in":
+		if left.typ != TypeString || right.typ != TypeList {
+			return n.errorf("in needs a string and a list, not %s and %s", left.typ, right.typ)
+		}
+		n.typ = TypeBool
+	case "+":
+		if left.typ != right.typ || left.typ == TypeBool {
+			return n.errorf("can't add %s and %s", left.typ, right.typ)
+		}
+		n.typ = left.typ
+	default:
+		if left.typ != TypeNumber || right.typ != TypeNumber {
+			return n.errorf("%s needs numbers, not %s and %s", n.name, left.typ, right.typ)
+		}
+		n.typ = TypeNumber
+	}
+
+	return nil
+}
+
+func checkCall(n *node) error {
+	overloads, ok := functions[n.name]
+	if !ok {
+		return n.errorf("unknown function %s", n.name)
+	}
+
+	types := make([]string, 0, len(n.args))
+	for _, arg := range n.args {
+		types = append(types, arg.typ.String())
+	}
+	for _, fn := range overloads {
+		if fn.accepts(n.args) {
+			n.fn = fn
+			n.typ = fn.result
+			break
+		}
+	}
+	if n.fn == nil {
+		return n.errorf("no function %s(%s)", n.name, strings.Join(types, ", "))
+	}
+
+	if n.name == "matches" {
+		pattern := n.args[1]
+		source, ok := pattern.value.(string)
+		if pattern.kind != nodeLiteral || !ok {
+			return pattern.errorf("pattern must be a string literal")
+		}
+		if len(source) > maxPatternLength {
+			return pattern.errorf("pattern is longer than %d bytes", maxPatternLength)
+		}
+		compiled, err := regexp.Compile(source)
+		if err != nil {
+			return pattern.errorf("invalid pattern: %w", err)
+		}
+		n.value = compiled
+	}
+
+	return nil
+}
+
+func (o *overload) accepts(args []*node) bool {
+	if len(args) != len(o.params) {
+		return false
+	}
+	for i, arg := range args {
+		if arg.typ != o.params[i] {
+			return false
+		}
+	}
+
+	return true
+}
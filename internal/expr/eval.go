@@ -0,0 +1,435 @@
+package expr
+
+import (
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// stringHeaderSize is what an item of a list is charged on top of its text.
+const stringHeaderSize = 16
+
+type evaluator struct {
+	vars   map[string]interface{}
+	limits Limits
+	cost   int
+	memory int
+}
+
+// step charges the cost of an operation, it fails once the cost limit is exceeded.
+func (e *evaluator) step(n *node, cost int) error {
+	e.cost += cost
+	if e.limits.MaxCost > 0 && e.cost > e.limits.MaxCost {
+		return &Error{Line: n.line, Column: n.column, Err: ErrCostLimit}
+	}
+
+	return nil
+}
+
+// alloc charges the bytes of a value before it is created, it fails once the memory limit
+// is exceeded.
+func (e *evaluator) alloc(n *node, bytes int) error {
+	e.memory += bytes
+	if e.limits.MaxMemory > 0 && e.memory > e.limits.MaxMemory {
+		return &Error{Line: n.line, Column: n.column, Err: ErrMemoryLimit}
+	}
+
+	return nil
+}
+
+// textCost is the cost of an operation that reads a text of length bytes.
+func textCost(length int) int {
+	return 1 + length/16
+}
+
+func zero(typ Type) interface{} {
+	switch typ {
+	case TypeBool:
+		return false
+	case TypeNumber:
+		return float64(0)
+	case TypeList:
+		return []string{}
+	}
+
+	return ""
+}
+
+func (e *evaluator) eval(n *node) (interface{}, error) {
+	if err := e.step(n, 1); err != nil {
+		return nil, err
+	}
+
+	switch n.kind {
+	case nodeLiteral:
+		return n.value, nil
+	case nodeVar:
+		if value, ok := e.vars[n.name]; ok && value != nil {
+			return value, nil
+		}
+		return zero(n.typ), nil
+	case nodeList:
+		if err := e.alloc(n, len(n.args)*stringHeaderSize); err != nil {
+			return nil, err
+		}
+		items := make([]string, 0, len(n.args))
+		for _, arg := range n.args {
+			item, err := e.eval(arg)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item.(string))
+		}
+		return items, nil
+	case nodeUnary:
+		operand, err := e.eval(n.args[0])
+		if err != nil {
+			return nil, err
+		}
+		if n.name == "!" {
+			return !operand.(bool), nil
+		}
+		return -operand.(float64), nil
+	case nodeBinary:
+		return e.binary(n)
+	case nodeTernary:
+		cond, err := e.eval(n.args[0])
+		if err != nil {
+			return nil, err
+		}
+		if cond.(bool) {
+			return e.eval(n.args[1])
+		}
+		return e.eval(n.args[2])
+	case nodeIndex:
+		return e.index(n)
+	case nodeCall:
+		args := make([]interface{}, 0, len(n.args))
+		for _, arg := range n.args {
+			value, err := e.eval(arg)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, value)
+		}
+		return n.fn.call(e, n, args)
+	}
+
+	return nil, n.errorf("unknown expression")
+}
+
+func (e *evaluator) binary(n *node) (interface{}, error) {
+	left, err := e.eval(n.args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	// && and || only evaluate the right side when it decides the result
+	switch n.name {
+	case "&&":
+		if !left.(bool) {
+			return false, nil
+		}
+		return e.eval(n.args[1])
+	case "||":
+		if left.(bool) {
+			return true, nil
+		}
+		return e.eval(n.args[1])
+	}
+
+	right, err := e.eval(n.args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.name {
+	case "==", "!=":
+		equal, err := e.equal(n, left, right)
+		if err != nil {
+			return nil, err
+		}
+		return equal == (n.name == "=="), nil
+	case "<", "<=", ">", ">=":
+		return e.compare(n, left, right)
+	case "in":
+		list := right.([]string)
+		if err = e.step(n, len(list)+textCost(len(left.(string)))); err != nil {
+			return nil, err
+		}
+		return slices.Contains(list, left.(string)), nil
+	case "+":
+		return e.add(n, left, right)
+	}
+
+	a, b := left.(float64), right.(float64)
+	switch n.name {
+	case "-":
+		return a - b, nil
+	case "*":
+		return a * b, nil
+	case "/":
+		if b == 0 {
+			return nil, n.errorf("division by zero")
+		}
+		return a / b, nil
+	case "%":
+		if b == 0 {
+			return nil, n.errorf("modulo by zero")
+		}
+		return math.Mod(a, b), nil
+	}
+
+	return nil, n.errorf("unknown operator %s", n.name)
+}
+
+func (e *evaluator) equal(n *node, left, right interface{}) (bool, error) {
+	switch left := left.(type) {
+	case string:
+		if err := e.step(n, textCost(len(left))); err != nil {
+			return false, err
+		}
+	case []string:
+		size := 0
+		for _, item := range left {
+			size += len(item)
+		}
+		if err := e.step(n, len(left)+textCost(size)); err != nil {
+			return false, err
+		}
+		return slices.Equal(left, right.([]string)), nil
+	}
+
+	return left == right, nil
+}
+
+func (e *evaluator) compare(n *node, left, right interface{}) (bool, error) {
+	var order int
+	switch left := left.(type) {
+	case float64:
+		switch b := right.(float64); {
+		case left < b:
+			order = -1
+		case left > b:
+			order = 1
+		}
+	case string:
+		if err := e.step(n, textCost(len(left))); err != nil {
+			return false, err
+		}
+		order = strings.Compare(left, right.(string))
+	}
+
+	switch n.name {
+	case "<":
+		return order < 0, nil
+	case "<=":
+		return order <= 0, nil
+	case ">":
+		return order > 0, nil
+	}
+
+	return order >= 0, nil
+}
+
+func (e *evaluator) add(n *node, left, right interface{}) (interface{}, error) {
+	switch left := left.(type) {
+	case float64:
+		return left + right.(float64), nil
+	case string:
+		b := right.(string)
+		if err := e.alloc(n, len(left)+len(b)); err != nil {
+			return nil, err
+		}
+		if err := e.step(n, textCost(len(left)+len(b))); err != nil {
+			return nil, err
+		}
+		return left + b, nil
+	}
+
+	a, b := left.([]string), right.([]string)
+	if err := e.alloc(n, (len(a)+len(b))*stringHeaderSize); err != nil {
+		return nil, err
+	}
+
+	return append(slices.Clip(a), b...), nil
+}
+
+func (e *evaluator) index(n *node) (interface{}, error) {
+	list, err := e.eval(n.args[0])
+	if err != nil {
+		return nil, err
+	}
+	index, err := e.eval(n.args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	items, i := list.([]string), index.(float64)
+	if i != math.Trunc(i) || i < 0 || int(i) >= len(items) {
+		return nil, n.errorf("index %s out of range of a list of %d", formatNumber(i), len(items))
+	}
+
+	return items[int(i)], nil
+}
+
+func formatNumber(number float64) string {
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
+
+// overload is a function for arguments of certain types.
+type overload struct {
+	params []Type
+	result Type
+	call   func(e *evaluator, n *node, args []interface{}) (interface{}, error)
+}
+
+// textFunction returns an overload calling fn with the text of its first argument, charging the
+// cost of reading it.
+func textFunction(params []Type, result Type, fn func(text string, args []interface{}) interface{}) *overload {
+	return &overload{
+		params: params,
+		result: result,
+		call: func(e *evaluator, n *node, args []interface{}) (interface{}, error) {
+			text := args[0].(string)
+			if err := e.step(n, textCost(len(text))); err != nil {
+				return nil, err
+			}
+			return fn(text, args[1:]), nil
+		},
+	}
+}
+
+// mappedFunction is a textFunction whose result is a new text, charged by its size.
+func mappedFunction(fn func(text string) string) *overload {
+	return &overload{
+		params: []Type{TypeString},
+		result: TypeString,
+		call: func(e *evaluator, n *node, args []interface{}) (interface{}, error) {
+			text := args[0].(string)
+			if err := e.step(n, textCost(len(text))); err != nil {
+				return nil, err
+			}
+			// case mappings change the size of a text by a few bytes per rune at most
+			if err := e.alloc(n, len(text)); err != nil {
+				return nil, err
+			}
+			return fn(text), nil
+		},
+	}
+}
+
+//nolint:gochecknoglobals // lookup table
+var functions = map[string][]*overload{
+	"size": {
+		textFunction([]Type{TypeString}, TypeNumber, func(text string, _ []interface{}) interface{} {
+			return float64(utf8.RuneCountInString(text))
+		}),
+		{params: []Type{TypeList}, result: TypeNumber, call: func(_ *evaluator, _ *node, args []interface{}) (interface{}, error) {
+			return float64(len(args[0].([]string))), nil
+		}},
+	},
+	"contains": {textFunction([]Type{TypeString, TypeString}, TypeBool, func(text string, args []interface{}) interface{} {
+		return strings.Contains(text, args[0].(string))
+	})},
+	"startsWith": {textFunction([]Type{TypeString, TypeString}, TypeBool, func(text string, args []interface{}) interface{} {
+		return strings.HasPrefix(text, args[0].(string))
+	})},
+	"endsWith": {textFunction([]Type{TypeString, TypeString}, TypeBool, func(text string, args []interface{}) interface{} {
+		return strings.HasSuffix(text, args[0].(string))
+	})},
+	"matches": {{params: []Type{TypeString, TypeString}, result: TypeBool, call: func(e *evaluator, n *node, args []interface{}) (interface{}, error) {
+		text := args[0].(string)
+		// RE2 takes time linear in the text, by a factor of the pattern
+		if err := e.step(n, 4*textCost(len(text))); err != nil {
+			return nil, err
+		}
+		pattern, ok := n.value.(*regexp.Regexp)
+		if !ok {
+			return nil, n.errorf("pattern is not compiled")
+		}
+		return pattern.MatchString(text), nil
+	}}},
+	"lower": {mappedFunction(strings.ToLower)},
+	"upper": {mappedFunction(strings.ToUpper)},
+	"trim": {textFunction([]Type{TypeString}, TypeString, func(text string, _ []interface{}) interface{} {
+		return strings.TrimSpace(text)
+	})},
+	"split":   {{params: []Type{TypeString, TypeString}, result: TypeList, call: split}},
+	"join":    {{params: []Type{TypeList, TypeString}, result: TypeString, call: join}},
+	"replace": {{params: []Type{TypeString, TypeString, TypeString}, result: TypeString, call: replace}},
+	"string": {
+		{params: []Type{TypeBool}, result: TypeString, call: toString},
+		{params: []Type{TypeNumber}, result: TypeString, call: toString},
+		{params: []Type{TypeString}, result: TypeString, call: toString},
+	},
+}
+
+func split(e *evaluator, n *node, args []interface{}) (interface{}, error) {
+	text, sep := args[0].(string), args[1].(string)
+	if err := e.step(n, textCost(len(text))); err != nil {
+		return nil, err
+	}
+
+	parts := strings.Count(text, sep) + 1
+	if err := e.alloc(n, parts*stringHeaderSize); err != nil {
+		return nil, err
+	}
+
+	return strings.Split(text, sep), nil
+}
+
+func join(e *evaluator, n *node, args []interface{}) (interface{}, error) {
+	items, sep := args[0].([]string), args[1].(string)
+
+	size := len(sep) * max(len(items)-1, 0)
+	for _, item := range items {
+		size += len(item)
+	}
+	if err := e.alloc(n, size); err != nil {
+		return nil, err
+	}
+	if err := e.step(n, len(items)+textCost(size)); err != nil {
+		return nil, err
+	}
+
+	return strings.Join(items, sep), nil
+}
+
+func replace(e *evaluator, n *node, args []interface{}) (interface{}, error) {
+	text, old, replacement := args[0].(string), args[1].(string), args[2].(string)
+	if err := e.step(n, textCost(len(text))); err != nil {
+		return nil, err
+	}
+
+	// the size is known before replacing, so a replacement can't blow up the text
+	size := len(text) + strings.Count(text, old)*(len(replacement)-len(old))
+	if err := e.alloc(n, size); err != nil {
+		return nil, err
+	}
+	if err := e.step(n, textCost(size)); err != nil {
+		return nil, err
+	}
+
+	return strings.ReplaceAll(text, old, replacement), nil
+}
+
+func toString(e *evaluator, n *node, args []interface{}) (interface{}, error) {
+	var text string
+	switch value := args[0].(type) {
+	case string:
+		return value, nil
+	case bool:
+		text = strconv.FormatBool(value)
+	case float64:
+		text = formatNumber(value)
+	}
+	if err := e.alloc(n, len(text)); err != nil {
+		return nil, err
+	}
+
+	return text, nil
+}
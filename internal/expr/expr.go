@@ -0,0 +1,123 @@
+// Package expr is a small expression language for user-written automation, modelled on CEL:
+//
+//	title.lower().contains("invoice") && source in ["email", "share"]
+//	size(description) > 200 ? "high" : "low"
+//
+// Expressions have no loops, assignments or side effects and are type checked when compiled, so
+// a stored expression can only fail at runtime on bad input, e.g. a division by zero. Evaluation
+// is sandboxed by Limits, which bound the work and the memory an expression may take.
+package expr
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MaxSourceLength is the longest expression Compile accepts, in bytes.
+const MaxSourceLength = 4096
+
+// maxDepth is how deep expressions may nest, it keeps the recursion of the parser in bounds.
+const maxDepth = 64
+
+var (
+	// ErrCostLimit and ErrMemoryLimit are the runtime errors of an evaluation that exceeded its
+	// Limits.
+	ErrCostLimit   = errors.New("expression exceeded its cost limit")
+	ErrMemoryLimit = errors.New("expression exceeded its memory limit")
+)
+
+// Type is the type of a value. Values are bool, float64, string or []string in Go.
+type Type int
+
+const (
+	TypeBool Type = iota + 1
+	TypeNumber
+	TypeString
+	// TypeList is a list of strings.
+	TypeList
+)
+
+func (t Type) String() string {
+	switch t {
+	case TypeBool:
+		return "bool"
+	case TypeNumber:
+		return "number"
+	case TypeString:
+		return "string"
+	case TypeList:
+		return "list"
+	}
+
+	return "unknown"
+}
+
+// Env declares the variables an expression may use with their types.
+type Env map[string]Type
+
+// Limits sandbox an evaluation. MaxCost bounds the steps taken, every operation costs one and
+// operations on text cost more the longer the text. MaxMemory bounds the bytes of the strings
+// and lists created, in total.
+type Limits struct {
+	MaxCost   int
+	MaxMemory int
+}
+
+// Error is an error of an expression at a position of its source, lines and columns count from 1.
+type Error struct {
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Program is a compiled expression, it can be evaluated any number of times and concurrently.
+type Program struct {
+	source string
+	root   *node
+}
+
+// Compile parses and type checks an expression against the variables of env.
+func Compile(source string, env Env) (*Program, error) {
+	if len(source) > MaxSourceLength {
+		return nil, &Error{Line: 1, Column: 1, Err: fmt.Errorf("expression is longer than %d bytes", MaxSourceLength)}
+	}
+
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+	root, err := parse(tokens)
+	if err != nil {
+		return nil, err
+	}
+	if err = check(root, env); err != nil {
+		return nil, err
+	}
+
+	return &Program{source: source, root: root}, nil
+}
+
+// Type returns the type of the values the program evaluates to.
+func (p *Program) Type() Type {
+	return p.root.typ
+}
+
+// Eval evaluates the program with the values of its variables, a variable of the env without a
+// value is the zero value of its type.
+func (p *Program) Eval(vars map[string]interface{}, limits Limits) (interface{}, error) {
+	e := &evaluator{vars: vars, limits: limits}
+
+	return e.eval(p.root)
+}
+
+func (p *Program) String() string {
+	return p.source
+}
@@ -0,0 +1,128 @@
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	// tokenOp is an operator or punctuation, its text tells which.
+	tokenOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	// value is the unquoted string of a tokenString.
+	value  string
+	line   int
+	column int
+}
+
+// operators are matched longest first.
+//
+//nolint:gochecknoglobals // lookup table
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "+", "-", "*", "/", "%", "?", ":", "(", ")", "[", "]", ",", "."}
+
+// lex splits the source into tokens, the last one is tokenEOF.
+func lex(source string) ([]*token, error) {
+	var tokens []*token
+	line, lineStart := 1, 0
+
+	for i := 0; i < len(source); {
+		r, width := utf8.DecodeRuneInString(source[i:])
+		column := utf8.RuneCountInString(source[lineStart:i]) + 1
+
+		switch {
+		case r == '\n':
+			i++
+			line, lineStart = line+1, i
+		case unicode.IsSpace(r):
+			i += width
+		case r == '_' || unicode.IsLetter(r):
+			start := i
+			for i < len(source) {
+				r, width = utf8.DecodeRuneInString(source[i:])
+				if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				i += width
+			}
+			tokens = append(tokens, &token{kind: tokenIdent, text: source[start:i], line: line, column: column})
+		case r >= '0' && r <= '9':
+			start := i
+			for i < len(source) && (source[i] >= '0' && source[i] <= '9' || source[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, &token{kind: tokenNumber, text: source[start:i], line: line, column: column})
+		case r == '"' || r == '\'':
+			value, end, err := unquote(source, i)
+			if err != nil {
+				return nil, &Error{Line: line, Column: column, Err: err}
+			}
+			tokens = append(tokens, &token{kind: tokenString, text: source[i:end], value: value, line: line, column: column})
+			i = end
+		default:
+			op := ""
+			for _, candidate := range operators {
+				if strings.HasPrefix(source[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, &Error{Line: line, Column: column, Err: fmt.Errorf("unexpected character %q", r)}
+			}
+			tokens = append(tokens, &token{kind: tokenOp, text: op, line: line, column: column})
+			i += len(op)
+		}
+	}
+
+	column := utf8.RuneCountInString(source[lineStart:]) + 1
+
+	return append(tokens, &token{kind: tokenEOF, line: line, column: column}), nil
+}
+
+// unquote reads the string literal starting at source[start], it returns the string and the
+// offset after the closing quote. Literals can't span lines.
+func unquote(source string, start int) (string, int, error) {
+	quote := source[start]
+	var b strings.Builder
+
+	for i := start + 1; i < len(source); i++ {
+		switch c := source[i]; c {
+		case quote:
+			return b.String(), i + 1, nil
+		case '\n':
+			return "", 0, errors.New("string is not terminated")
+		case '\\':
+			i++
+			if i == len(source) {
+				return "", 0, errors.New("string is not terminated")
+			}
+			switch escaped := source[i]; escaped {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case '\\', '"', '\'':
+				b.WriteByte(escaped)
+			default:
+				return "", 0, fmt.Errorf(`unknown escape sequence \%c`, escaped)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return "", 0, errors.New("string is not terminated")
+}
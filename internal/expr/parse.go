@@ -0,0 +1,316 @@
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+type nodeKind int
+
+const (
+	nodeLiteral nodeKind = iota
+	nodeVar
+	nodeList
+	nodeUnary
+	nodeBinary
+	nodeTernary
+	nodeCall
+	nodeIndex
+)
+
+// node is a node of the syntax tree. The checker sets the type of every node and resolves the
+// functions of calls.
+type node struct {
+	kind   nodeKind
+	line   int
+	column int
+	// name is the operator of unary and binary nodes, the variable of a nodeVar and the function
+	// of a nodeCall.
+	name string
+	// value is the value of a nodeLiteral, the checker keeps the compiled pattern of matches here.
+	value interface{}
+	args  []*node
+	typ   Type
+	fn    *overload
+}
+
+func (n *node) errorf(format string, args ...interface{}) error {
+	return &Error{Line: n.line, Column: n.column, Err: fmt.Errorf(format, args...)}
+}
+
+type parser struct {
+	tokens []*token
+	pos    int
+	depth  int
+}
+
+func parse(tokens []*token) (*node, error) {
+	p := &parser{tokens: tokens}
+
+	root, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	if next := p.peek(); next.kind != tokenEOF {
+		return nil, p.unexpected(next)
+	}
+
+	return root, nil
+}
+
+func (p *parser) peek() *token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() *token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+
+	return t
+}
+
+// accept consumes the next token if it is one of the operators.
+func (p *parser) accept(ops ...string) *token {
+	t := p.peek()
+	if t.kind != tokenOp {
+		return nil
+	}
+	for _, op := range ops {
+		if t.text == op {
+			return p.next()
+		}
+	}
+
+	return nil
+}
+
+func (p *parser) expect(op string) error {
+	if p.accept(op) == nil {
+		return p.unexpected(p.peek())
+	}
+
+	return nil
+}
+
+func (p *parser) unexpected(t *token) error {
+	if t.kind == tokenEOF {
+		return &Error{Line: t.line, Column: t.column, Err: errors.New("unexpected end of expression")}
+	}
+
+	return &Error{Line: t.line, Column: t.column, Err: fmt.Errorf("unexpected %q", t.text)}
+}
+
+func newNode(kind nodeKind, at *token, name string, args ...*node) *node {
+	return &node{kind: kind, line: at.line, column: at.column, name: name, args: args}
+}
+
+// ternary parses cond ? a : b, the lowest precedence of all.
+func (p *parser) ternary() (*node, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxDepth {
+		t := p.peek()
+		return nil, &Error{Line: t.line, Column: t.column, Err: fmt.Errorf("expression is nested deeper than %d levels", maxDepth)}
+	}
+
+	cond, err := p.binary(0)
+	if err != nil {
+		return nil, err
+	}
+	op := p.accept("?")
+	if op == nil {
+		return cond, nil
+	}
+
+	then, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+	if err = p.expect(":"); err != nil {
+		return nil, err
+	}
+	otherwise, err := p.ternary()
+	if err != nil {
+		return nil, err
+	}
+
+	return newNode(nodeTernary, op, "?", cond, then, otherwise), nil
+}
+
+// precedence lists the binary operators from the loosest to the tightest binding.
+//
+//nolint:gochecknoglobals // lookup table
+var precedence = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!=", "<", "<=", ">", ">=", "in"},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *parser) binary(level int) (*node, error) {
+	if level == len(precedence) {
+		return p.unary()
+	}
+
+	left, err := p.binary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.accept(precedence[level]...)
+		if op == nil && level == 2 && p.peek().kind == tokenIdent && p.peek().text == "in" {
+			op = p.next()
+		}
+		if op == nil {
+			return left, nil
+		}
+
+		right, err := p.binary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = newNode(nodeBinary, op, op.text, left, right)
+	}
+}
+
+func (p *parser) unary() (*node, error) {
+	op := p.accept("!", "-")
+	if op == nil {
+		return p.postfix()
+	}
+
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxDepth {
+		return nil, &Error{Line: op.line, Column: op.column, Err: fmt.Errorf("expression is nested deeper than %d levels", maxDepth)}
+	}
+
+	operand, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+
+	return newNode(nodeUnary, op, op.text, operand), nil
+}
+
+// postfix parses the method calls and indexes after a primary, x.f(y) calls f(x, y).
+func (p *parser) postfix() (*node, error) {
+	n, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if op := p.accept("."); op != nil {
+			name := p.next()
+			if name.kind != tokenIdent {
+				return nil, p.unexpected(name)
+			}
+			if err = p.expect("("); err != nil {
+				return nil, err
+			}
+			args, err := p.args(")")
+			if err != nil {
+				return nil, err
+			}
+			n = newNode(nodeCall, name, name.text, append([]*node{n}, args...)...)
+			continue
+		}
+		if op := p.accept("["); op != nil {
+			index, err := p.ternary()
+			if err != nil {
+				return nil, err
+			}
+			if err = p.expect("]"); err != nil {
+				return nil, err
+			}
+			n = newNode(nodeIndex, op, "[]", n, index)
+			continue
+		}
+
+		return n, nil
+	}
+}
+
+func (p *parser) primary() (*node, error) {
+	t := p.next()
+
+	switch t.kind {
+	case tokenNumber:
+		number, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, &Error{Line: t.line, Column: t.column, Err: fmt.Errorf("invalid number %q", t.text)}
+		}
+		n := newNode(nodeLiteral, t, "")
+		n.value = number
+		return n, nil
+	case tokenString:
+		n := newNode(nodeLiteral, t, "")
+		n.value = t.value
+		return n, nil
+	case tokenIdent:
+		switch t.text {
+		case "true", "false":
+			n := newNode(nodeLiteral, t, "")
+			n.value = t.text == "true"
+			return n, nil
+		case "in":
+			return nil, p.unexpected(t)
+		}
+		if p.accept("(") == nil {
+			return newNode(nodeVar, t, t.text), nil
+		}
+		args, err := p.args(")")
+		if err != nil {
+			return nil, err
+		}
+		return newNode(nodeCall, t, t.text, args...), nil
+	case tokenOp:
+		switch t.text {
+		case "(":
+			n, err := p.ternary()
+			if err != nil {
+				return nil, err
+			}
+			if err = p.expect(")"); err != nil {
+				return nil, err
+			}
+			return n, nil
+		case "[":
+			items, err := p.args("]")
+			if err != nil {
+				return nil, err
+			}
+			return newNode(nodeList, t, "", items...), nil
+		}
+	}
+
+	return nil, p.unexpected(t)
+}
+
+// args parses a comma separated list of expressions up to the closing token.
+func (p *parser) args(closing string) ([]*node, error) {
+	var args []*node
+	if p.accept(closing) != nil {
+		return args, nil
+	}
+
+	for {
+		arg, err := p.ternary()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+
+		if p.accept(closing) != nil {
+			return args, nil
+		}
+		if err = p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package extract
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// Command extracts text with an external program that reads the file on stdin and writes the
+// text to stdout, e.g. OCR of images with "tesseract stdin stdout".
+type Command struct {
+	name string
+	args []string
+}
+
+// NewCommand parses a command line, arguments are split at blanks and can't be quoted.
+func NewCommand(commandLine string) (*Command, error) {
+	fields := strings.Fields(commandLine)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty extractor command")
+	}
+
+	return &Command{name: fields[0], args: fields[1:]}, nil
+}
+
+var _ Extractor = (*Command)(nil)
+
+func (c *Command) Extract(ctx context.Context, _ string, body io.Reader) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.name, c.args...) //nolint:gosec // the command is set by the operator
+	cmd.Stdin = body
+	cmd.Stdout = &limitedWriter{w: &stdout, remaining: MaxText}
+	cmd.Stderr = &limitedWriter{w: &stderr, remaining: 1024}
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s: %w: %s", c.name, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
+
+// limitedWriter drops what is written beyond remaining, the program isn't failed for writing
+// too much.
+type limitedWriter struct {
+	w         io.Writer
+	remaining int
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	if l.remaining <= 0 {
+		return n, nil
+	}
+	if len(p) > l.remaining {
+		p = p[:l.remaining]
+	}
+	l.remaining -= len(p)
+	if _, err := l.w.Write(p); err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
@@ -0,0 +1,72 @@
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxDocument caps the size of a document the DOCX extractor reads, the zip has to be read
+// into memory and the attachment size limit is configurable.
+const maxDocument = 64 << 20
+
+// DOCX reads the paragraphs of the main part of a Word document, headers, footers and
+// comments are left out.
+func DOCX(_ context.Context, _ string, body io.Reader) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(body, maxDocument))
+	if err != nil {
+		return "", err
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("invalid docx: %w", err)
+	}
+
+	document, err := archive.Open("word/document.xml")
+	if err != nil {
+		return "", fmt.Errorf("invalid docx: %w", err)
+	}
+	defer document.Close()
+
+	var text strings.Builder
+	decoder := xml.NewDecoder(io.LimitReader(document, maxDocument))
+	var inText bool
+	for text.Len() < MaxText {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("invalid docx: %w", err)
+		}
+
+		switch element := token.(type) {
+		case xml.StartElement:
+			switch element.Name.Local {
+			case "t":
+				inText = true
+			case "tab", "br":
+				text.WriteByte(' ')
+			}
+		case xml.EndElement:
+			switch element.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				text.WriteByte('\n')
+			}
+		case xml.CharData:
+			if inText {
+				text.Write(element)
+			}
+		}
+	}
+
+	return text.String(), nil
+}
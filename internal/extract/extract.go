@@ -0,0 +1,129 @@
+// Package extract pulls the text out of uploaded files, so attachments can be found by their
+// content. Extractors are picked by content type, formats without an extractor are not
+// searchable by content.
+package extract
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxText caps the extracted text of a file in bytes, the rest of a longer file isn't
+// searchable.
+const MaxText = 1 << 20
+
+// ErrUnsupported means there is no extractor for the content type.
+var ErrUnsupported = errors.New("content type not supported")
+
+// Extractor returns the text of a file, implementations must be safe for concurrent use.
+type Extractor interface {
+	Extract(ctx context.Context, contentType string, body io.Reader) (string, error)
+}
+
+// ExtractorFunc adapts a function to Extractor.
+type ExtractorFunc func(ctx context.Context, contentType string, body io.Reader) (string, error)
+
+func (f ExtractorFunc) Extract(ctx context.Context, contentType string, body io.Reader) (string, error) {
+	return f(ctx, contentType, body)
+}
+
+// Pipeline extracts the text of a file with the extractor registered for its content type and
+// normalizes it for the search index.
+type Pipeline struct {
+	extractors map[string]Extractor
+}
+
+// NewPipeline returns a pipeline with the built-in extractors for plain text, DOCX and PDF.
+// Images need an OCR extractor, see Command.
+func NewPipeline() *Pipeline {
+	p := &Pipeline{extractors: map[string]Extractor{}}
+	p.Register("text/*", ExtractorFunc(Text))
+	p.Register("application/json", ExtractorFunc(Text))
+	p.Register("application/pdf", ExtractorFunc(PDF))
+	p.Register("application/vnd.openxmlformats-officedocument.wordprocessingml.document", ExtractorFunc(DOCX))
+
+	return p
+}
+
+// Register sets the extractor of a content type, a type/* pattern covers the subtypes without
+// an extractor of their own. Register is not safe for concurrent use with Extract.
+func (p *Pipeline) Register(contentType string, extractor Extractor) {
+	p.extractors[strings.ToLower(contentType)] = extractor
+}
+
+// Extract returns the normalized text of body, ErrUnsupported when no extractor handles the
+// content type.
+func (p *Pipeline) Extract(ctx context.Context, contentType string, body io.Reader) (string, error) {
+	extractor, ok := p.extractor(contentType)
+	if !ok {
+		return "", ErrUnsupported
+	}
+
+	text, err := extractor.Extract(ctx, contentType, body)
+	if err != nil {
+		return "", err
+	}
+
+	return normalize(text), nil
+}
+
+func (p *Pipeline) extractor(contentType string) (Extractor, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+
+	if extractor, ok := p.extractors[mediaType]; ok {
+		return extractor, true
+	}
+	if kind, _, found := strings.Cut(mediaType, "/"); found {
+		extractor, ok := p.extractors[kind+"/*"]
+		return extractor, ok
+	}
+
+	return nil, false
+}
+
+// Text reads plain text files.
+func Text(_ context.Context, _ string, body io.Reader) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(body, MaxText))
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// normalize drops invalid UTF-8 and control characters, collapses runs of blanks and caps the
+// text at MaxText without cutting a character in half.
+func normalize(text string) string {
+	text = strings.ToValidUTF8(text, " ")
+
+	var b strings.Builder
+	b.Grow(min(len(text), MaxText))
+	var blank bool
+	for _, r := range text {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			blank = b.Len() > 0
+			continue
+		}
+		if blank {
+			if b.Len()+1+utf8.RuneLen(r) > MaxText {
+				break
+			}
+			b.WriteByte(' ')
+			blank = false
+		}
+		if b.Len()+utf8.RuneLen(r) > MaxText {
+			break
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,130 @@
+package extract
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"io"
+	"regexp"
+	"strings"
+)
+
+//nolint:gochecknoglobals // compiled once
+var pdfStream = regexp.MustCompile(`(?s)stream\r?\n(.*?)\r?\nendstream`)
+
+// PDF reads the literal strings shown by the text operators of the content streams of a PDF.
+// Streams are inflated when they are Flate encoded. Text in hex strings, which fonts with a
+// custom encoding use, and scanned pages are not read, scans need OCR.
+func PDF(_ context.Context, _ string, body io.Reader) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(body, maxDocument))
+	if err != nil {
+		return "", err
+	}
+
+	var text strings.Builder
+	for _, match := range pdfStream.FindAllSubmatch(data, -1) {
+		if text.Len() >= MaxText {
+			break
+		}
+		content := match[1]
+		if inflated, err := inflate(content); err == nil {
+			content = inflated
+		}
+		pdfText(&text, content)
+	}
+
+	return text.String(), nil
+}
+
+func inflate(data []byte) ([]byte, error) {
+	reader, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(io.LimitReader(reader, maxDocument))
+}
+
+// pdfText writes the strings of the text objects of a content stream, a text object ends a
+// line. The strings of a TJ array are the kerned parts of the same text and joined without a
+// blank.
+func pdfText(text *strings.Builder, content []byte) {
+	var inText, inArray bool
+	for i := 0; i < len(content); i++ {
+		switch {
+		case bytes.HasPrefix(content[i:], []byte("BT")) && operatorEnds(content, i+2):
+			inText = true
+			i++
+		case bytes.HasPrefix(content[i:], []byte("ET")) && operatorEnds(content, i+2):
+			if inText {
+				text.WriteByte('\n')
+			}
+			inText = false
+			i++
+		case content[i] == '[' && inText:
+			inArray = true
+		case content[i] == ']' && inText:
+			inArray = false
+			text.WriteByte(' ')
+		case content[i] == '(' && inText:
+			var value string
+			value, i = pdfString(content, i)
+			text.WriteString(value)
+			if !inArray {
+				text.WriteByte(' ')
+			}
+		}
+	}
+}
+
+func operatorEnds(content []byte, i int) bool {
+	return i >= len(content) || strings.IndexByte(" \t\r\n[]()<>/%", content[i]) >= 0
+}
+
+// pdfString decodes the literal string starting at the parenthesis at start and returns it
+// with the index of its closing parenthesis.
+func pdfString(content []byte, start int) (string, int) {
+	var value strings.Builder
+	depth := 0
+	for i := start; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case c == '\\' && i+1 < len(content):
+			i++
+			switch escaped := content[i]; escaped {
+			case 'n', 'r', 't':
+				value.WriteByte(' ')
+			case '\r', '\n':
+				// line continuation
+			default:
+				if escaped >= '0' && escaped <= '7' {
+					code := 0
+					for j := 0; j < 3 && i < len(content) && content[i] >= '0' && content[i] <= '7'; j++ {
+						code = code*8 + int(content[i]-'0')
+						i++
+					}
+					i--
+					value.WriteByte(byte(code)) //nolint:gosec // octal escapes are at most 3 digits
+				} else {
+					value.WriteByte(escaped)
+				}
+			}
+		case c == '(':
+			if depth > 0 {
+				value.WriteByte(c)
+			}
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return value.String(), i
+			}
+			value.WriteByte(c)
+		default:
+			value.WriteByte(c)
+		}
+	}
+
+	return value.String(), len(content)
+}
@@ -0,0 +1,61 @@
+// Package fuzzy scores how well a typed or spoken name matches a title.
+package fuzzy
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Normalize lowercases and strips punctuation, which speech recognition and quick typing do not
+// produce reliably.
+func Normalize(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}), " ")
+}
+
+// Score rates from 0 to 1 how well query matches title, both are normalized first. A title
+// containing the query as a whole word sequence scores at least partialScore, more the larger
+// the part of the title it covers, other titles are scored by their edit distance.
+func Score(query string, title string) float64 {
+	query, title = Normalize(query), Normalize(title)
+	if query == "" || title == "" {
+		return 0
+	}
+	if query == title {
+		return 1
+	}
+
+	similarity := 1 - float64(levenshtein([]rune(query), []rune(title)))/float64(max(len([]rune(query)), len([]rune(title))))
+	if strings.Contains(" "+title+" ", " "+query+" ") {
+		partial := partialScore + (1-partialScore)*float64(len(query))/float64(len(title))
+		return max(partial, similarity)
+	}
+
+	return similarity
+}
+
+// partialScore is the minimum score of a title containing the whole query.
+const partialScore = 0.6
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,48 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/meowmix1337/go-core/cache"
+	"github.com/meowmix1337/go-core/db"
+)
+
+// ErrDirtyMigration means a migration failed halfway and the schema needs a manual fix.
+var ErrDirtyMigration = errors.New("migration is dirty")
+
+// DB pings a database with a trivial query.
+func DB(database db.DB) HealthChecker {
+	return CheckerFunc(func(ctx context.Context) error {
+		var one int
+		return database.Get(ctx, &one, `SELECT 1`)
+	})
+}
+
+// Cache writes a short-lived key, the cache has no ping of its own.
+func Cache(c cache.Cache) HealthChecker {
+	return CheckerFunc(func(ctx context.Context) error {
+		return c.Set(ctx, "healthz", time.Now().UTC().Format(time.RFC3339), int(time.Minute))
+	})
+}
+
+// Migrations fails while the schema of a database is left dirty by a failed migration.
+func Migrations(database db.DB) HealthChecker {
+	return CheckerFunc(func(ctx context.Context) error {
+		var status struct {
+			Version int64 `db:"version"`
+			Dirty   bool  `db:"dirty"`
+		}
+		if err := database.Get(ctx, &status, `SELECT version, dirty FROM schema_migrations`); err != nil {
+			return err
+		}
+
+		if status.Dirty {
+			return fmt.Errorf("version %d: %w", status.Version, ErrDirtyMigration)
+		}
+
+		return nil
+	})
+}
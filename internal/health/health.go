@@ -0,0 +1,118 @@
+// Package health reports the state of the API and its dependencies. Checkers are registered by
+// name and run concurrently, every check is reported with its latency.
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// checkTimeout bounds every check, a dependency that doesn't answer in time counts as down.
+const checkTimeout = 2 * time.Second
+
+// HealthChecker checks a single dependency, it returns an error when the dependency is down.
+type HealthChecker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc adapts a function to a HealthChecker.
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusFail Status = "fail"
+)
+
+// Result is the outcome of a single check.
+type Result struct {
+	Name    string
+	Status  Status
+	Latency time.Duration
+	Err     error
+}
+
+// Report is the outcome of every check, it fails when any check failed.
+type Report struct {
+	Status Status
+	Checks []*Result
+}
+
+type namedChecker struct {
+	name    string
+	checker HealthChecker
+}
+
+// Registry holds the checkers of an endpoint, it is safe for concurrent use.
+type Registry struct {
+	mu       sync.RWMutex
+	checkers []namedChecker
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a checker, the checks are reported in the order they were registered.
+func (r *Registry) Register(name string, checker HealthChecker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checkers = append(r.checkers, namedChecker{name: name, checker: checker})
+}
+
+// Run runs every check concurrently and waits for all of them.
+func (r *Registry) Run(ctx context.Context) *Report {
+	r.mu.RLock()
+	checkers := r.checkers
+	r.mu.RUnlock()
+
+	report := &Report{
+		Status: StatusOK,
+		Checks: make([]*Result, len(checkers)),
+	}
+
+	var wg sync.WaitGroup
+	for i, named := range checkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Checks[i] = run(ctx, named)
+		}()
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+
+	return report
+}
+
+func run(ctx context.Context, named namedChecker) *Result {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := named.checker.Check(ctx)
+
+	result := &Result{
+		Name:    named.name,
+		Status:  StatusOK,
+		Latency: time.Since(start),
+		Err:     err,
+	}
+	if err != nil {
+		result.Status = StatusFail
+	}
+
+	return result
+}
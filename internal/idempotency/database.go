@@ -0,0 +1,107 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+)
+
+// databaseStore keeps the responses in the idempotency_keys table of the home database, so a
+// retry gets the original response from any instance. Expired rows are removed by Prune.
+type databaseStore struct {
+	DB db.DB
+}
+
+func NewDatabaseStore(db db.DB) *databaseStore {
+	return &databaseStore{
+		DB: db,
+	}
+}
+
+var (
+	_ Store  = (*databaseStore)(nil)
+	_ Pruner = (*databaseStore)(nil)
+)
+
+type storedResponse struct {
+	Fingerprint string         `db:"fingerprint"`
+	StatusCode  sql.NullInt32  `db:"status_code"`
+	ContentType sql.NullString `db:"content_type"`
+	Body        []byte         `db:"body"`
+}
+
+func (s *databaseStore) Begin(ctx context.Context, key string, fingerprint string, expiresAt time.Time) (*Response, error) {
+	// an expired key is taken over like a new one, the claim is atomic so concurrent retries
+	// can't both run
+	query := `
+		INSERT INTO idempotency_keys (key_hash, fingerprint, expires_at) VALUES ($1, $2, $3)
+		ON CONFLICT (key_hash) DO UPDATE SET
+			fingerprint = EXCLUDED.fingerprint,
+			status_code = NULL,
+			content_type = NULL,
+			body = NULL,
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= $4
+		RETURNING key_hash`
+
+	// the key is claimed again when the request holding it was released in between
+	var err error
+	for range 2 {
+		var claimed string
+		err = s.DB.Get(ctx, &claimed, query, keyHash(key), fingerprint, expiresAt.UTC(), time.Now().UTC())
+		if err == nil {
+			return nil, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+
+		var stored storedResponse
+		err = s.DB.Get(ctx, &stored, `SELECT fingerprint, status_code, content_type, body FROM idempotency_keys WHERE key_hash = $1`, keyHash(key))
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return &Response{
+			Fingerprint: stored.Fingerprint,
+			StatusCode:  int(stored.StatusCode.Int32),
+			ContentType: stored.ContentType.String,
+			Body:        stored.Body,
+		}, nil
+	}
+
+	return nil, err
+}
+
+func (s *databaseStore) Complete(ctx context.Context, key string, response *Response) error {
+	query := `UPDATE idempotency_keys SET status_code = $1, content_type = $2, body = $3 WHERE key_hash = $4`
+	_, err := s.DB.Exec(ctx, query, response.StatusCode, response.ContentType, response.Body, keyHash(key))
+
+	return err
+}
+
+func (s *databaseStore) Release(ctx context.Context, key string) error {
+	_, err := s.DB.Exec(ctx, `DELETE FROM idempotency_keys WHERE key_hash = $1`, keyHash(key))
+
+	return err
+}
+
+func (s *databaseStore) Prune(ctx context.Context) error {
+	_, err := s.DB.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= $1`, time.Now().UTC())
+
+	return err
+}
+
+// keyHash keeps the keys, which are scoped by user, fixed in length.
+func keyHash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
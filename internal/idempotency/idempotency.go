@@ -0,0 +1,44 @@
+// Package idempotency remembers the responses of requests sent with an Idempotency-Key, so a
+// client retrying a request gets the original response instead of creating a duplicate.
+package idempotency
+
+import (
+	"context"
+	"time"
+)
+
+// TTL is how long a response is replayed, retries after that run the request again.
+const TTL = 24 * time.Hour
+
+// Response is a stored response, a response without a StatusCode belongs to a request still
+// in progress.
+type Response struct {
+	// Fingerprint identifies the request the key was first used with, a key can't be reused
+	// for another request.
+	Fingerprint string
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+// Completed reports whether the request of the response finished.
+func (r *Response) Completed() bool {
+	return r.StatusCode != 0
+}
+
+// Store keeps the responses by key, implementations must be safe for concurrent use.
+type Store interface {
+	// Begin claims key for a request and returns nil, or returns the response of the request
+	// that claimed it before when the key is taken. The claim expires at expiresAt.
+	Begin(ctx context.Context, key string, fingerprint string, expiresAt time.Time) (*Response, error)
+	// Complete stores the response of the request that claimed key.
+	Complete(ctx context.Context, key string, response *Response) error
+	// Release gives up the claim of a failed request, so it can be retried with the same key.
+	Release(ctx context.Context, key string) error
+}
+
+// Pruner is implemented by stores that don't expire keys on their own, Prune removes the
+// expired keys.
+type Pruner interface {
+	Prune(ctx context.Context) error
+}
@@ -0,0 +1,80 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryStore keeps the responses in memory, a retry only gets the original response from the
+// instance that answered it. Expired keys are removed by Prune.
+type memoryStore struct {
+	mu        sync.Mutex
+	responses map[string]*memoryResponse
+}
+
+type memoryResponse struct {
+	Response
+	expiresAt time.Time
+}
+
+func NewMemoryStore() *memoryStore {
+	return &memoryStore{
+		responses: map[string]*memoryResponse{},
+	}
+}
+
+var (
+	_ Store  = (*memoryStore)(nil)
+	_ Pruner = (*memoryStore)(nil)
+)
+
+func (s *memoryStore) Begin(_ context.Context, key string, fingerprint string, expiresAt time.Time) (*Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.responses[key]; ok && existing.expiresAt.After(time.Now()) {
+		response := existing.Response
+		return &response, nil
+	}
+	s.responses[key] = &memoryResponse{
+		Response:  Response{Fingerprint: fingerprint},
+		expiresAt: expiresAt,
+	}
+
+	return nil, nil
+}
+
+func (s *memoryStore) Complete(_ context.Context, key string, response *Response) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.responses[key]; ok {
+		existing.Response = *response
+	}
+
+	return nil
+}
+
+func (s *memoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.responses, key)
+
+	return nil
+}
+
+func (s *memoryStore) Prune(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for key, response := range s.responses {
+		if !response.expiresAt.After(now) {
+			delete(s.responses, key)
+		}
+	}
+
+	return nil
+}
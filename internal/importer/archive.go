@@ -0,0 +1,58 @@
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/meowmix1337/the_recipe_book/internal/export"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+// Archive reads the ZIP export archives of this app, see export.WriteTodoArchive. Lists, todos,
+// their timestamps and attachments are restored as they were exported, so an account can move
+// between instances.
+type Archive struct{}
+
+func (Archive) Parse(r io.Reader) (*domain.Backup, error) {
+	// ZIP archives are read from the end, the upload is small enough to hold
+	payload, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrInvalidBackup, err)
+	}
+
+	backup, err := export.ReadTodoArchive(bytes.NewReader(payload), int64(len(payload)))
+	if err != nil {
+		return nil, err
+	}
+	if len(backup.Lists) == 0 && len(backup.Unlisted) == 0 {
+		return nil, fmt.Errorf("%w: %w", domain.ErrInvalidBackup, errEmpty)
+	}
+
+	for _, list := range backup.Lists {
+		list.Name = truncate(list.Name, maxNameLength)
+		if list.Name == "" {
+			list.Name = "Archive"
+		}
+		list.Todos = archiveTodos(list.Todos)
+	}
+	backup.Unlisted = archiveTodos(backup.Unlisted)
+
+	return backup, nil
+}
+
+// archiveTodos normalizes the todos like those of other providers, todos without a title are
+// dropped.
+func archiveTodos(todos []*domain.BackupTodo) []*domain.BackupTodo {
+	kept := make([]*domain.BackupTodo, 0, len(todos))
+	for _, todo := range todos {
+		todo.Title = truncate(todo.Title, maxNameLength)
+		if todo.Title == "" {
+			continue
+		}
+		todo.Tags = tags(todo.Tags)
+		kept = append(kept, todo)
+	}
+
+	return kept
+}
@@ -0,0 +1,96 @@
+// Package importer reads the backups of other todo apps and the export archives of this one.
+// Every provider has an adapter that maps its backup onto lists, todos and tags, the import
+// service creates them.
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+const (
+	maxNameLength = 255
+	maxTagLength  = 64
+)
+
+// errEmpty is returned for backups without anything to import, most likely the wrong file.
+var errEmpty = errors.New("backup has nothing to import")
+
+// Adapter maps the backup of a provider, implementations must be safe for concurrent use.
+type Adapter interface {
+	// Parse reads a backup, malformed backups fail with domain.ErrInvalidBackup.
+	Parse(r io.Reader) (*domain.Backup, error)
+}
+
+// New returns the adapter of the provider.
+func New(provider domain.ImportProvider) (Adapter, error) {
+	switch provider {
+	case domain.ImportTodoist:
+		return Todoist{}, nil
+	case domain.ImportTrello:
+		return Trello{}, nil
+	case domain.ImportArchive:
+		return Archive{}, nil
+	default:
+		return nil, fmt.Errorf("%q: %w", provider, domain.ErrInvalidImportProvider)
+	}
+}
+
+func decode(r io.Reader, v interface{}) error {
+	if err := json.NewDecoder(r).Decode(v); err != nil {
+		return fmt.Errorf("%w: %w", domain.ErrInvalidBackup, err)
+	}
+
+	return nil
+}
+
+// truncate shortens s to max bytes without splitting a rune, names longer than the columns
+// they are stored in are cut rather than failing the import.
+func truncate(s string, max int) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= max {
+		return s
+	}
+
+	s = s[:max]
+	for !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+
+	return s
+}
+
+// tags normalizes the tag names of a todo, dropping empty and repeated ones.
+func tags(names []string) []string {
+	normalized := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = truncate(name, maxTagLength)
+		if name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		normalized = append(normalized, name)
+	}
+
+	return normalized
+}
+
+// parseDue accepts RFC 3339 timestamps, timestamps without a zone and plain dates, anything
+// else leaves the todo without a due date.
+func parseDue(value string) time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", domain.DateLayout} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC()
+		}
+	}
+
+	return time.Time{}
+}
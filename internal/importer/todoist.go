@@ -0,0 +1,93 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+// Todoist reads the JSON backups of Todoist, as returned by its sync API. Projects become
+// lists and labels become tags, sections are flattened into their project.
+type Todoist struct{}
+
+type todoistBackup struct {
+	Projects []struct {
+		ID         string `json:"id"`
+		Name       string `json:"name"`
+		IsDeleted  bool   `json:"is_deleted"`
+		IsArchived bool   `json:"is_archived"`
+	} `json:"projects"`
+	Items []struct {
+		ProjectID   string   `json:"project_id"`
+		Content     string   `json:"content"`
+		Description string   `json:"description"`
+		Priority    int      `json:"priority"`
+		Labels      []string `json:"labels"`
+		Checked     bool     `json:"checked"`
+		IsDeleted   bool     `json:"is_deleted"`
+		Due         *struct {
+			Date string `json:"date"`
+		} `json:"due"`
+	} `json:"items"`
+}
+
+// todoistPriorities maps the priorities of the API, 4 is what the Todoist apps show as p1.
+// Todoist's default priority 1 is the same as medium, the default here.
+//
+//nolint:gochecknoglobals // lookup table
+var todoistPriorities = map[int]domain.Priority{
+	1: domain.PriorityMedium,
+	2: domain.PriorityMedium,
+	3: domain.PriorityHigh,
+	4: domain.PriorityUrgent,
+}
+
+func (Todoist) Parse(r io.Reader) (*domain.Backup, error) {
+	var backup todoistBackup
+	if err := decode(r, &backup); err != nil {
+		return nil, err
+	}
+	if len(backup.Projects) == 0 {
+		return nil, fmt.Errorf("%w: %w", domain.ErrInvalidBackup, errEmpty)
+	}
+
+	lists := make(map[string]*domain.BackupList, len(backup.Projects))
+	imported := &domain.Backup{}
+	for _, project := range backup.Projects {
+		if project.IsDeleted || project.IsArchived {
+			continue
+		}
+		list := &domain.BackupList{Name: truncate(project.Name, maxNameLength)}
+		if list.Name == "" {
+			list.Name = "Todoist"
+		}
+		lists[project.ID] = list
+		imported.Lists = append(imported.Lists, list)
+	}
+
+	for _, item := range backup.Items {
+		list, ok := lists[item.ProjectID]
+		title := truncate(item.Content, maxNameLength)
+		if !ok || item.IsDeleted || title == "" {
+			continue
+		}
+
+		todo := &domain.BackupTodo{
+			Title:       title,
+			Description: item.Description,
+			Priority:    domain.PriorityMedium,
+			Tags:        tags(item.Labels),
+			Completed:   item.Checked,
+		}
+		if priority, ok := todoistPriorities[item.Priority]; ok {
+			todo.Priority = priority
+		}
+		if item.Due != nil {
+			todo.DueDate = parseDue(item.Due.Date)
+		}
+		list.Todos = append(list.Todos, todo)
+	}
+
+	return imported, nil
+}
@@ -0,0 +1,87 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+// Trello reads the JSON export of a Trello board. The board becomes a list and its cards
+// todos, the Trello list a card is on and the card's labels become tags. Archived cards and
+// cards of archived lists are left out.
+type Trello struct{}
+
+type trelloBoard struct {
+	Name  string `json:"name"`
+	Lists []struct {
+		ID     string `json:"id"`
+		Name   string `json:"name"`
+		Closed bool   `json:"closed"`
+	} `json:"lists"`
+	Cards []struct {
+		Name        string  `json:"name"`
+		Desc        string  `json:"desc"`
+		IDList      string  `json:"idList"`
+		Due         *string `json:"due"`
+		DueComplete bool    `json:"dueComplete"`
+		Closed      bool    `json:"closed"`
+		Labels      []struct {
+			Name  string `json:"name"`
+			Color string `json:"color"`
+		} `json:"labels"`
+	} `json:"cards"`
+}
+
+func (Trello) Parse(r io.Reader) (*domain.Backup, error) {
+	var board trelloBoard
+	if err := decode(r, &board); err != nil {
+		return nil, err
+	}
+	if len(board.Lists) == 0 {
+		return nil, fmt.Errorf("%w: %w", domain.ErrInvalidBackup, errEmpty)
+	}
+
+	columns := make(map[string]string, len(board.Lists))
+	for _, column := range board.Lists {
+		if !column.Closed {
+			columns[column.ID] = column.Name
+		}
+	}
+
+	list := &domain.BackupList{Name: truncate(board.Name, maxNameLength)}
+	if list.Name == "" {
+		list.Name = "Trello"
+	}
+	for _, card := range board.Cards {
+		column, ok := columns[card.IDList]
+		title := truncate(card.Name, maxNameLength)
+		if !ok || card.Closed || title == "" {
+			continue
+		}
+
+		// unnamed labels are only told apart by their color
+		names := []string{column}
+		for _, label := range card.Labels {
+			if label.Name != "" {
+				names = append(names, label.Name)
+			} else {
+				names = append(names, label.Color)
+			}
+		}
+
+		todo := &domain.BackupTodo{
+			Title:       title,
+			Description: card.Desc,
+			Priority:    domain.PriorityMedium,
+			Tags:        tags(names),
+			Completed:   card.DueComplete,
+		}
+		if card.Due != nil {
+			todo.DueDate = parseDue(*card.Due)
+		}
+		list.Todos = append(list.Todos, todo)
+	}
+
+	return &domain.Backup{Lists: []*domain.BackupList{list}}, nil
+}
@@ -0,0 +1,107 @@
+// Package linkcheck checks whether the links found in todos still lead somewhere.
+package linkcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/webhook"
+)
+
+const (
+	maxRedirects = 5
+	// maxResponseBody is how much of a response is read, only the status matters.
+	maxResponseBody = 4 << 10
+)
+
+// ErrUnchecked is returned for links that are never requested, e.g. links to private
+// addresses. They are neither working nor broken.
+var ErrUnchecked = errors.New("link can't be checked")
+
+// Checker requests links, implementations must be safe for concurrent use.
+type Checker interface {
+	// Check returns the status code of the response to the link, or zero when no response
+	// was received.
+	Check(ctx context.Context, link string) (int, error)
+}
+
+// Broken reports whether the outcome of a check means the link is broken. Missing pages,
+// server errors and unreachable hosts are broken, links that need a login or are rate limited
+// still exist.
+func Broken(statusCode int, err error) bool {
+	if errors.Is(err, ErrUnchecked) {
+		return false
+	}
+
+	return err != nil ||
+		statusCode == http.StatusNotFound ||
+		statusCode == http.StatusGone ||
+		statusCode >= http.StatusInternalServerError
+}
+
+type httpChecker struct {
+	client *http.Client
+}
+
+// NewHTTPChecker requests links with the given timeout, following a few redirects. Like
+// webhooks, connections to loopback, private and link-local addresses are refused.
+func NewHTTPChecker(timeout time.Duration) *httpChecker {
+	dialer := &net.Dialer{Timeout: timeout}
+	webhook.RefusePrivate(dialer)
+
+	return &httpChecker{
+		client: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: timeout,
+				MaxIdleConnsPerHost: 2,
+			},
+			CheckRedirect: func(_ *http.Request, via []*http.Request) error {
+				if len(via) >= maxRedirects {
+					return fmt.Errorf("stopped after %d redirects", maxRedirects)
+				}
+				return nil
+			},
+		},
+	}
+}
+
+var _ Checker = (*httpChecker)(nil)
+
+func (c *httpChecker) Check(ctx context.Context, link string) (int, error) {
+	statusCode, err := c.request(ctx, http.MethodHead, link)
+	// plenty of servers answer HEAD requests wrongly, a failing HEAD is confirmed with a GET
+	if err == nil && statusCode >= http.StatusBadRequest {
+		statusCode, err = c.request(ctx, http.MethodGet, link)
+	}
+	if errors.Is(err, webhook.ErrPrivateAddress) {
+		return 0, fmt.Errorf("%s: %w", link, ErrUnchecked)
+	}
+
+	return statusCode, err
+}
+
+func (c *httpChecker) request(ctx context.Context, method string, link string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, link, http.NoBody)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", link, ErrUnchecked)
+	}
+	req.Header.Set("User-Agent", "todo-linkcheck/1")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("error requesting link: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// drain a bit of the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBody))
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,84 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+const (
+	anthropicURL     = "https://api.anthropic.com/v1"
+	anthropicVersion = "2023-06-01"
+)
+
+// anthropicModel uses the messages API of Anthropic. It has no JSON mode, requests for JSON
+// rely on their instructions.
+type anthropicModel struct {
+	client *http.Client
+	url    string
+	apiKey string
+	model  string
+}
+
+func newAnthropicModel(settings Settings) (Model, error) {
+	if settings.Model == "" || settings.APIKey == "" {
+		return nil, errors.New("anthropic provider needs a model and an API key")
+	}
+
+	return &anthropicModel{
+		client: newHTTPClient(settings.Timeout),
+		url:    baseURL(settings.URL, anthropicURL) + "/messages",
+		apiKey: settings.APIKey,
+		model:  settings.Model,
+	}, nil
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicRequest struct {
+	Model     string             `json:"model"`
+	System    string             `json:"system,omitempty"`
+	Messages  []anthropicMessage `json:"messages"`
+	MaxTokens int                `json:"max_tokens"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+}
+
+func (m *anthropicModel) Complete(ctx context.Context, req *Request) (string, error) {
+	body := &anthropicRequest{
+		Model:     m.model,
+		System:    req.System,
+		Messages:  []anthropicMessage{{Role: "user", Content: req.Prompt}},
+		MaxTokens: req.maxTokens(),
+	}
+
+	header := http.Header{}
+	header.Set("X-Api-Key", m.apiKey)
+	header.Set("Anthropic-Version", anthropicVersion)
+
+	var resp anthropicResponse
+	if err := postJSON(ctx, m.client, m.url, header, body, &resp); err != nil {
+		return "", err
+	}
+
+	var text strings.Builder
+	for _, block := range resp.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return "", errors.New("language model returned no text")
+	}
+
+	return text.String(), nil
+}
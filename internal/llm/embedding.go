@@ -0,0 +1,163 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Embedder turns texts into vectors whose distance reflects how close their meanings are,
+// implementations must be safe for concurrent use.
+type Embedder interface {
+	// Embed returns a vector per input, in the order of the inputs.
+	Embed(ctx context.Context, inputs []string) ([][]float32, error)
+	// Name identifies the model, vectors of embedders with different names are never compared.
+	Name() string
+}
+
+// EmbedderProvider builds the Embedder of an API, providers register themselves with
+// RegisterEmbedder.
+type EmbedderProvider func(settings Settings) (Embedder, error)
+
+//nolint:gochecknoglobals // providers register themselves by name, like database drivers
+var embedders = struct {
+	mu        sync.RWMutex
+	providers map[string]EmbedderProvider
+}{providers: map[string]EmbedderProvider{
+	ProviderDisabled: func(Settings) (Embedder, error) {
+		return disabledEmbedder{}, nil
+	},
+	ProviderOpenAI: newOpenAIEmbedder,
+}}
+
+// RegisterEmbedder makes an embedding provider available by name, registering a name twice
+// panics.
+func RegisterEmbedder(name string, provider EmbedderProvider) {
+	embedders.mu.Lock()
+	defer embedders.mu.Unlock()
+
+	name = strings.ToLower(name)
+	if _, ok := embedders.providers[name]; ok {
+		panic(fmt.Sprintf("embedding provider %q registered twice", name))
+	}
+	embedders.providers[name] = provider
+}
+
+// OpenEmbedder builds the Embedder of the named provider, empty is the disabled provider.
+func OpenEmbedder(name string, settings Settings) (Embedder, error) {
+	if name == "" {
+		name = ProviderDisabled
+	}
+
+	embedders.mu.RLock()
+	provider, ok := embedders.providers[strings.ToLower(name)]
+	embedders.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%q, registered are %s: %w", name, strings.Join(EmbedderProviders(), ", "), ErrUnknownProvider)
+	}
+
+	return provider(settings)
+}
+
+// EmbedderProviders returns the names of the registered embedding providers, sorted.
+func EmbedderProviders() []string {
+	embedders.mu.RLock()
+	defer embedders.mu.RUnlock()
+
+	names := make([]string, 0, len(embedders.providers))
+	for name := range embedders.providers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// EmbedderEnabled reports whether embedder can embed texts at all.
+func EmbedderEnabled(embedder Embedder) bool {
+	_, disabled := embedder.(disabledEmbedder)
+	return embedder != nil && !disabled
+}
+
+type disabledEmbedder struct{}
+
+func (disabledEmbedder) Embed(context.Context, []string) ([][]float32, error) {
+	return nil, ErrDisabled
+}
+
+func (disabledEmbedder) Name() string {
+	return ProviderDisabled
+}
+
+// openAIEmbedder uses the embeddings API of OpenAI, which Ollama and other self-hosted servers
+// offer as well.
+type openAIEmbedder struct {
+	client *http.Client
+	url    string
+	apiKey string
+	model  string
+}
+
+func newOpenAIEmbedder(settings Settings) (Embedder, error) {
+	if settings.Model == "" {
+		return nil, errors.New("openai embedding provider needs a model")
+	}
+
+	return &openAIEmbedder{
+		client: newHTTPClient(settings.Timeout),
+		url:    baseURL(settings.URL, openAIURL) + "/embeddings",
+		apiKey: settings.APIKey,
+		model:  settings.Model,
+	}, nil
+}
+
+type openAIEmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type openAIEmbeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+func (e *openAIEmbedder) Embed(ctx context.Context, inputs []string) ([][]float32, error) {
+	if len(inputs) == 0 {
+		return nil, nil
+	}
+
+	header := http.Header{}
+	if e.apiKey != "" {
+		header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+
+	var resp openAIEmbeddingResponse
+	if err := postJSON(ctx, e.client, e.url, header, &openAIEmbeddingRequest{Model: e.model, Input: inputs}, &resp); err != nil {
+		return nil, err
+	}
+
+	vectors := make([][]float32, len(inputs))
+	for _, data := range resp.Data {
+		if data.Index < 0 || data.Index >= len(vectors) {
+			return nil, fmt.Errorf("embedding model returned index %d for %d inputs", data.Index, len(inputs))
+		}
+		vectors[data.Index] = data.Embedding
+	}
+	for i, vector := range vectors {
+		if len(vector) == 0 {
+			return nil, fmt.Errorf("embedding model returned no vector for input %d", i)
+		}
+	}
+
+	return vectors, nil
+}
+
+func (e *openAIEmbedder) Name() string {
+	return ProviderOpenAI + "/" + e.model
+}
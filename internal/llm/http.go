@@ -0,0 +1,79 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const defaultTimeout = 30 * time.Second
+
+func newHTTPClient(timeout time.Duration) *http.Client {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	return &http.Client{Timeout: timeout}
+}
+
+// apiError is the error body of both APIs, {"error": {"message": "..."}}.
+type apiError struct {
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// postJSON posts body to url and decodes the response into out, responses other than 2xx are
+// errors with the message of the provider.
+func postJSON(ctx context.Context, client *http.Client, url string, header http.Header, body any, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var apiErr apiError
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error.Message != "" {
+			return fmt.Errorf("language model responded with %d: %s", resp.StatusCode, apiErr.Error.Message)
+		}
+		return fmt.Errorf("language model responded with %d", resp.StatusCode)
+	}
+
+	if err = json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("error decoding language model response: %w", err)
+	}
+
+	return nil
+}
+
+func baseURL(url string, fallback string) string {
+	if url == "" {
+		url = fallback
+	}
+
+	return strings.TrimRight(url, "/")
+}
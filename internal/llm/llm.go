@@ -0,0 +1,134 @@
+// Package llm asks large language models for completions and embedding models for vectors.
+// Providers register themselves by name like mail providers, the disabled provider is the
+// default so the server runs without any model and never sends data to one unless configured to.
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ProviderDisabled  = "disabled"
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+
+	// DefaultMaxTokens caps replies when a request doesn't.
+	DefaultMaxTokens = 1024
+	// maxResponseBody is how much of a response of a provider is read.
+	maxResponseBody = 1 << 20
+)
+
+var (
+	// ErrDisabled is returned by the disabled provider, features that need a model are off.
+	ErrDisabled        = errors.New("no language model is configured")
+	ErrUnknownProvider = errors.New("unknown language model provider")
+)
+
+// Settings configure a provider. URL is the base URL of the API, empty picks the public API of
+// the provider, so OpenAI compatible servers like Ollama or vLLM only need their URL.
+type Settings struct {
+	URL     string
+	APIKey  string
+	Model   string
+	Timeout time.Duration
+}
+
+// Request is a single turn conversation with the model.
+type Request struct {
+	// System sets the task, Prompt is the message of the user.
+	System string
+	Prompt string
+	// JSON asks for a reply that is a single JSON object, providers without a JSON mode rely
+	// on the instructions of System.
+	JSON      bool
+	MaxTokens int
+}
+
+func (r *Request) maxTokens() int {
+	if r.MaxTokens > 0 {
+		return r.MaxTokens
+	}
+
+	return DefaultMaxTokens
+}
+
+// Model completes requests, implementations must be safe for concurrent use.
+type Model interface {
+	Complete(ctx context.Context, req *Request) (string, error)
+}
+
+// Provider builds the Model of an API. Providers register themselves with Register, usually
+// from the init func of their package.
+type Provider func(settings Settings) (Model, error)
+
+//nolint:gochecknoglobals // providers register themselves by name, like database drivers
+var providers = struct {
+	mu        sync.RWMutex
+	providers map[string]Provider
+}{providers: map[string]Provider{
+	ProviderDisabled: func(Settings) (Model, error) {
+		return disabledModel{}, nil
+	},
+	ProviderOpenAI:    newOpenAIModel,
+	ProviderAnthropic: newAnthropicModel,
+}}
+
+// Register makes a provider available by name, registering a name twice panics.
+func Register(name string, provider Provider) {
+	providers.mu.Lock()
+	defer providers.mu.Unlock()
+
+	name = strings.ToLower(name)
+	if _, ok := providers.providers[name]; ok {
+		panic(fmt.Sprintf("language model provider %q registered twice", name))
+	}
+	providers.providers[name] = provider
+}
+
+// Open builds the Model of the named provider, empty is the disabled provider.
+func Open(name string, settings Settings) (Model, error) {
+	if name == "" {
+		name = ProviderDisabled
+	}
+
+	providers.mu.RLock()
+	provider, ok := providers.providers[strings.ToLower(name)]
+	providers.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%q, registered are %s: %w", name, strings.Join(Providers(), ", "), ErrUnknownProvider)
+	}
+
+	return provider(settings)
+}
+
+// Providers returns the names of the registered providers, sorted.
+func Providers() []string {
+	providers.mu.RLock()
+	defer providers.mu.RUnlock()
+
+	names := make([]string, 0, len(providers.providers))
+	for name := range providers.providers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// Enabled reports whether model can complete requests at all.
+func Enabled(model Model) bool {
+	_, disabled := model.(disabledModel)
+	return model != nil && !disabled
+}
+
+type disabledModel struct{}
+
+func (disabledModel) Complete(context.Context, *Request) (string, error) {
+	return "", ErrDisabled
+}
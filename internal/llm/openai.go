@@ -0,0 +1,78 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+const openAIURL = "https://api.openai.com/v1"
+
+// openAIModel uses the chat completions API of OpenAI, which most self-hosted servers offer
+// as well. The API key is optional for them.
+type openAIModel struct {
+	client *http.Client
+	url    string
+	apiKey string
+	model  string
+}
+
+func newOpenAIModel(settings Settings) (Model, error) {
+	if settings.Model == "" {
+		return nil, errors.New("openai provider needs a model")
+	}
+
+	return &openAIModel{
+		client: newHTTPClient(settings.Timeout),
+		url:    baseURL(settings.URL, openAIURL) + "/chat/completions",
+		apiKey: settings.APIKey,
+		model:  settings.Model,
+	}, nil
+}
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIRequest struct {
+	Model          string            `json:"model"`
+	Messages       []openAIMessage   `json:"messages"`
+	MaxTokens      int               `json:"max_tokens"`
+	ResponseFormat map[string]string `json:"response_format,omitempty"`
+}
+
+type openAIResponse struct {
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+	} `json:"choices"`
+}
+
+func (m *openAIModel) Complete(ctx context.Context, req *Request) (string, error) {
+	body := &openAIRequest{
+		Model:     m.model,
+		MaxTokens: req.maxTokens(),
+	}
+	if req.System != "" {
+		body.Messages = append(body.Messages, openAIMessage{Role: "system", Content: req.System})
+	}
+	body.Messages = append(body.Messages, openAIMessage{Role: "user", Content: req.Prompt})
+	if req.JSON {
+		body.ResponseFormat = map[string]string{"type": "json_object"}
+	}
+
+	header := http.Header{}
+	if m.apiKey != "" {
+		header.Set("Authorization", "Bearer "+m.apiKey)
+	}
+
+	var resp openAIResponse
+	if err := postJSON(ctx, m.client, m.url, header, body, &resp); err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 {
+		return "", errors.New("language model returned no choices")
+	}
+
+	return resp.Choices[0].Message.Content, nil
+}
@@ -0,0 +1,38 @@
+package mail
+
+import (
+	"context"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+type Message struct {
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Sender delivers email messages, implementations must be safe for concurrent use.
+type Sender interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// logSender only logs the messages, it is used when no SMTP server is configured.
+type logSender struct{}
+
+func NewLogSender() *logSender {
+	return &logSender{}
+}
+
+var _ Sender = (*logSender)(nil)
+
+func (s *logSender) Send(_ context.Context, msg *Message) error {
+	log.Info().
+		Str("to", strings.Join(msg.To, ",")).
+		Str("subject", msg.Subject).
+		Msg("email not sent, no SMTP server configured")
+
+	return nil
+}
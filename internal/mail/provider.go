@@ -0,0 +1,84 @@
+package mail
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	ProviderSMTP = "smtp"
+	ProviderLog  = "log"
+)
+
+var ErrUnknownProvider = errors.New("unknown mail provider")
+
+// Settings are the mail settings of the app. Providers other than SMTP usually only need From
+// and read their credentials from their own environment variables.
+type Settings struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// Provider builds the Sender of an email service, e.g. SES or SendGrid. Providers register
+// themselves with Register, usually from the init func of their package.
+type Provider func(settings Settings) (Sender, error)
+
+//nolint:gochecknoglobals // providers register themselves by name, like database drivers
+var providers = struct {
+	mu        sync.RWMutex
+	providers map[string]Provider
+}{providers: map[string]Provider{
+	ProviderSMTP: func(settings Settings) (Sender, error) {
+		if settings.Host == "" {
+			return nil, errors.New("smtp provider needs a host")
+		}
+		return NewSMTPSender(settings.Host, settings.Port, settings.Username, settings.Password, settings.From), nil
+	},
+	ProviderLog: func(Settings) (Sender, error) {
+		return NewLogSender(), nil
+	},
+}}
+
+// Register makes a provider available by name, registering a name twice panics.
+func Register(name string, provider Provider) {
+	providers.mu.Lock()
+	defer providers.mu.Unlock()
+
+	name = strings.ToLower(name)
+	if _, ok := providers.providers[name]; ok {
+		panic(fmt.Sprintf("mail provider %q registered twice", name))
+	}
+	providers.providers[name] = provider
+}
+
+// Open builds the Sender of the named provider.
+func Open(name string, settings Settings) (Sender, error) {
+	providers.mu.RLock()
+	provider, ok := providers.providers[strings.ToLower(name)]
+	providers.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%q, registered are %s: %w", name, strings.Join(Providers(), ", "), ErrUnknownProvider)
+	}
+
+	return provider(settings)
+}
+
+// Providers returns the names of the registered providers, sorted.
+func Providers() []string {
+	providers.mu.RLock()
+	defer providers.mu.RUnlock()
+
+	names := make([]string, 0, len(providers.providers))
+	for name := range providers.providers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
@@ -0,0 +1,229 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+//go:embed templates/*
+var templateFS embed.FS
+
+//nolint:gochecknoglobals // templates are parsed once
+var (
+	templateFuncs = map[string]interface{}{
+		"date":     formatDate,
+		"datetime": formatDateTime,
+	}
+	textTemplates = texttemplate.Must(texttemplate.New("").Funcs(templateFuncs).ParseFS(templateFS, "templates/*.txt.tmpl"))
+	htmlTemplates = htmltemplate.Must(htmltemplate.New("").Funcs(templateFuncs).ParseFS(templateFS, "templates/*.html.tmpl"))
+)
+
+// Template is the data of one of the email templates, every template has a text and an HTML
+// version.
+type Template interface {
+	Subject() string
+	name() string
+}
+
+// Verification asks a new user to confirm their email address.
+type Verification struct {
+	Name      string
+	URL       string
+	ExpiresAt time.Time
+}
+
+func (Verification) Subject() string { return "Confirm your email address" }
+func (Verification) name() string    { return "verification" }
+
+// PasswordReset carries the link to choose a new password.
+type PasswordReset struct {
+	Name      string
+	URL       string
+	ExpiresAt time.Time
+}
+
+func (PasswordReset) Subject() string { return "Reset your password" }
+func (PasswordReset) name() string    { return "password_reset" }
+
+// EmailChange carries the link to confirm changing the email of an account to Email, Current is
+// set for the email to the current address.
+type EmailChange struct {
+	Name      string
+	Email     string
+	URL       string
+	ExpiresAt time.Time
+	Current   bool
+}
+
+func (EmailChange) Subject() string { return "Confirm your new email address" }
+func (EmailChange) name() string    { return "email_change" }
+
+// Takeout carries the link to download the data export of the user.
+type Takeout struct {
+	Name      string
+	URL       string
+	ExpiresAt time.Time
+}
+
+func (Takeout) Subject() string { return "Your data export is ready" }
+func (Takeout) name() string    { return "takeout" }
+
+// DueReminder lists the todos that are due soon.
+type DueReminder struct {
+	Name  string
+	Todos []*domain.Todo
+	URL   string
+}
+
+func (r DueReminder) Subject() string {
+	if len(r.Todos) == 1 {
+		return fmt.Sprintf("Due soon: %s", r.Todos[0].Title)
+	}
+	return fmt.Sprintf("%d todos are due soon", len(r.Todos))
+}
+func (DueReminder) name() string { return "due_reminder" }
+
+// WeeklyDigest sums up the week from From until To.
+type WeeklyDigest struct {
+	Name      string
+	From      time.Time
+	To        time.Time
+	Completed []*domain.Todo
+	Overdue   []*domain.Todo
+	Upcoming  []*domain.Todo
+	URL       string
+}
+
+func (WeeklyDigest) Subject() string { return "Your week in review" }
+func (WeeklyDigest) name() string    { return "weekly_digest" }
+
+// ProductivityNudge is a gentle heads-up about an unusual pattern in the todos of the user,
+// Overdue is set for overdue spikes and IdleDays when nothing was completed for a while.
+type ProductivityNudge struct {
+	Name     string
+	Kind     domain.NudgeKind
+	Overdue  int
+	IdleDays int
+	URL      string
+}
+
+// OverdueSpike tells the templates which of the nudges to render.
+func (n ProductivityNudge) OverdueSpike() bool {
+	return n.Kind == domain.NudgeOverdueSpike
+}
+
+func (n ProductivityNudge) Subject() string {
+	if n.OverdueSpike() {
+		return "A few todos slipped past their due date"
+	}
+	return "Checking in on your todos"
+}
+func (ProductivityNudge) name() string { return "productivity_nudge" }
+
+// AutomationNotice is a notification an automation of the user sent.
+type AutomationNotice struct {
+	Name       string
+	Automation string
+	Title      string
+	Body       string
+	URL        string
+}
+
+func (n AutomationNotice) Subject() string { return n.Title }
+func (AutomationNotice) name() string      { return "automation_notice" }
+
+// LoginAlert tells the user about a login from a device or country they didn't log in from
+// before, URL leads to their recent logins.
+type LoginAlert struct {
+	Name       string
+	Device     string
+	IP         string
+	Country    string
+	At         time.Time
+	NewDevice  bool
+	NewCountry bool
+	URL        string
+}
+
+func (LoginAlert) Subject() string { return "New login to your account" }
+func (LoginAlert) name() string    { return "login_alert" }
+
+// Escalation lists the overdue todos escalation rules escalated, with the priority they were
+// raised to.
+type Escalation struct {
+	Name        string
+	Escalations []*domain.Escalation
+	URL         string
+}
+
+func (e Escalation) Subject() string {
+	if len(e.Escalations) == 1 {
+		return fmt.Sprintf("Overdue: %s", e.Escalations[0].Title)
+	}
+	return fmt.Sprintf("%d todos are overdue", len(e.Escalations))
+}
+func (Escalation) name() string { return "escalation" }
+
+// RecoveryStarted tells the user that a recovery of their account through their trusted contacts
+// was started, URL leads to where they can cancel it.
+type RecoveryStarted struct {
+	Name      string
+	Threshold int
+	ReadyAt   time.Time
+	URL       string
+}
+
+func (RecoveryStarted) Subject() string { return "Someone asked to recover your account" }
+func (RecoveryStarted) name() string    { return "recovery_started" }
+
+// RecoveryApproval asks a trusted contact to approve the recovery of the account of UserName.
+type RecoveryApproval struct {
+	Name     string
+	UserName string
+	URL      string
+}
+
+func (RecoveryApproval) Subject() string { return "A recovery of an account needs your approval" }
+func (RecoveryApproval) name() string    { return "recovery_approval" }
+
+// Render renders a template into a message to the recipients.
+func Render(to []string, data Template) (*Message, error) {
+	var text bytes.Buffer
+	if err := textTemplates.ExecuteTemplate(&text, data.name()+".txt.tmpl", data); err != nil {
+		return nil, fmt.Errorf("error rendering %s text: %w", data.name(), err)
+	}
+
+	var html bytes.Buffer
+	if err := htmlTemplates.ExecuteTemplate(&html, data.name()+".html.tmpl", data); err != nil {
+		return nil, fmt.Errorf("error rendering %s html: %w", data.name(), err)
+	}
+
+	return &Message{
+		To:      to,
+		Subject: data.Subject(),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format("Mon, Jan 2 2006")
+}
+
+func formatDateTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format("Mon, Jan 2 2006 15:04 MST")
+}
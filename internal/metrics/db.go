@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+)
+
+// instrumentedDB records the duration of every query of a database in DBQueryDuration.
+type instrumentedDB struct {
+	db     db.DB
+	region string
+}
+
+// NewDB instruments the database of region.
+func NewDB(db db.DB, region string) *instrumentedDB {
+	return &instrumentedDB{
+		db:     db,
+		region: RegionLabel(region),
+	}
+}
+
+var _ db.DB = (*instrumentedDB)(nil)
+
+func (d *instrumentedDB) Get(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	defer d.observe("get", time.Now())
+	return d.db.Get(ctx, dest, query, args...)
+}
+
+func (d *instrumentedDB) Get_RO(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	defer d.observe("get_ro", time.Now())
+	return d.db.Get_RO(ctx, dest, query, args...)
+}
+
+func (d *instrumentedDB) Select(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	defer d.observe("select", time.Now())
+	return d.db.Select(ctx, dest, query, args...)
+}
+
+func (d *instrumentedDB) Select_RO(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	defer d.observe("select_ro", time.Now())
+	return d.db.Select_RO(ctx, dest, query, args...)
+}
+
+func (d *instrumentedDB) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	defer d.observe("exec", time.Now())
+	return d.db.Exec(ctx, query, args...)
+}
+
+// Transaction records the whole transaction, including the time fn spends outside of queries.
+func (d *instrumentedDB) Transaction(ctx context.Context, fn func(ctx context.Context, tx db.Tx) error) error {
+	defer d.observe("transaction", time.Now())
+	return d.db.Transaction(ctx, fn)
+}
+
+// Close closes the wrapped database when it holds a connection pool.
+func (d *instrumentedDB) Close() error {
+	if closer, ok := d.db.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+func (d *instrumentedDB) observe(operation string, start time.Time) {
+	DBQueryDuration.WithLabelValues(d.region, operation).Observe(time.Since(start).Seconds())
+}
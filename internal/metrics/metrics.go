@@ -0,0 +1,114 @@
+// Package metrics holds the Prometheus collectors of the API, they are registered with the
+// default registry and served on /metrics.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// RequestDuration is labeled with the route pattern, not the path, to keep the cardinality
+	// bounded.
+	RequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Duration of HTTP requests by route and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// GRPCRequestDuration is labeled with the full method name and the status code of the call.
+	GRPCRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_request_duration_seconds",
+		Help:    "Duration of gRPC calls by method and code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "code"})
+
+	Logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_logins_total",
+		Help: "Login attempts by result.",
+	}, []string{"result"})
+
+	// PasswordHashDuration has larger buckets than the default, bcrypt is slow on purpose.
+	PasswordHashDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "password_hash_duration_seconds",
+		Help:    "Duration of hashing and comparing passwords.",
+		Buckets: prometheus.ExponentialBuckets(0.01, 2, 10),
+	}, []string{"operation"})
+
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_query_duration_seconds",
+		Help:    "Duration of database queries by region and operation.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"region", "operation"})
+
+	// WorkerQueueDepth is the number of items due when a worker ran, capped at its batch size.
+	WorkerQueueDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "worker_queue_depth",
+		Help: "Items due for a background worker by region.",
+	}, []string{"worker", "region"})
+
+	WorkerRunDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "worker_run_duration_seconds",
+		Help:    "Duration of background worker runs.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"worker"})
+
+	// EmailsSent counts attempts to send queued emails, a message that is retried counts once
+	// for every attempt.
+	EmailsSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "emails_sent_total",
+		Help: "Attempts to send emails by kind and result.",
+	}, []string{"kind", "result"})
+
+	// PushMessages counts Web Push messages by result, gone subscriptions were dropped by the
+	// push service and are deleted.
+	PushMessages = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "push_messages_total",
+		Help: "Web Push messages sent by result.",
+	}, []string{"result"})
+
+	// PluginHookFailures counts the hooks of plugins that returned an error or panicked, they
+	// never fail the request that ran them.
+	PluginHookFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "plugin_hook_failures_total",
+		Help: "Failed plugin hooks by plugin and hook.",
+	}, []string{"plugin", "hook"})
+)
+
+const (
+	LoginSuccess            = "success"
+	LoginInvalidCredentials = "invalid_credentials"
+	LoginLocked             = "locked"
+	LoginError              = "error"
+
+	EmailSent   = "sent"
+	EmailRetry  = "retry"
+	EmailFailed = "failed"
+
+	PushSent   = "sent"
+	PushGone   = "gone"
+	PushFailed = "failed"
+)
+
+func init() {
+	prometheus.MustRegister(
+		RequestDuration,
+		GRPCRequestDuration,
+		Logins,
+		PasswordHashDuration,
+		DBQueryDuration,
+		WorkerQueueDepth,
+		WorkerRunDuration,
+		EmailsSent,
+		PushMessages,
+		PluginHookFailures,
+	)
+}
+
+// RegionLabel names the home region, its region name is empty.
+func RegionLabel(region string) string {
+	if region == "" {
+		return "home"
+	}
+
+	return region
+}
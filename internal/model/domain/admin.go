@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"fmt"
+)
+
+// AdminScope is a slice of the admin powers, staff only get the scopes their job needs.
+type AdminScope string
+
+const (
+	// AdminScopeSupport helps users with their accounts.
+	AdminScopeSupport AdminScope = "support"
+	// AdminScopeBilling manages the plans and payments of users.
+	AdminScopeBilling AdminScope = "billing"
+	// AdminScopeSuperadmin may do everything, including granting scopes.
+	AdminScopeSuperadmin AdminScope = "superadmin"
+)
+
+//nolint:gochecknoglobals // lookup table
+var AdminScopes = []AdminScope{AdminScopeSupport, AdminScopeBilling, AdminScopeSuperadmin}
+
+// AdminAction is an admin endpoint, every action has its own policy.
+type AdminAction string
+
+const (
+	AdminActionListUsers         AdminAction = "users.list"
+	AdminActionReadAuditLog      AdminAction = "audit.read"
+	AdminActionVerifyAuditLog    AdminAction = "audit.verify"
+	AdminActionReadCanaryMetrics AdminAction = "canary.read"
+	AdminActionManageScopes      AdminAction = "admin.scopes"
+	AdminActionReadInstances     AdminAction = "instances.read"
+)
+
+// adminPolicies lists the scopes allowed to perform each action besides superadmin. Actions
+// missing here are superadmin only.
+//
+//nolint:gochecknoglobals // lookup table
+var adminPolicies = map[AdminAction][]AdminScope{
+	AdminActionListUsers:    {AdminScopeSupport, AdminScopeBilling},
+	AdminActionReadAuditLog: {AdminScopeSupport},
+	// support debugs rolling deploys
+	AdminActionReadInstances: {AdminScopeSupport},
+}
+
+var (
+	ErrAdminForbidden      = NewError(KindForbidden, "admin scope required")
+	ErrInvalidAdminScope   = NewError(KindValidation, "invalid admin scope")
+	ErrRevokeOwnSuperadmin = NewError(KindConflict, "superadmins cannot revoke their own superadmin scope")
+)
+
+// ParseAdminScope validates an admin scope.
+func ParseAdminScope(value string) (AdminScope, error) {
+	for _, scope := range AdminScopes {
+		if string(scope) == value {
+			return scope, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrInvalidAdminScope, value)
+}
+
+// AdminAllowed reports whether any of the scopes may perform the action.
+func AdminAllowed(scopes []AdminScope, action AdminAction) bool {
+	for _, scope := range scopes {
+		if scope == AdminScopeSuperadmin {
+			return true
+		}
+		for _, allowed := range adminPolicies[action] {
+			if scope == allowed {
+				return true
+			}
+		}
+	}
+
+	return false
+}
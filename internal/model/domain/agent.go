@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+const (
+	// AgentTokenPrefix makes agent tokens recognizable, e.g. in leaked credential scans.
+	AgentTokenPrefix = "agt_"
+	// AgentConfirmationExpiration is how long an assistant has to ask its user before a
+	// confirmation expires.
+	AgentConfirmationExpiration = 10 * time.Minute
+	// DefaultAgentQueryLimit and MaxAgentQueryLimit are small, whatever an assistant reads
+	// fills its context window.
+	DefaultAgentQueryLimit = 10
+	MaxAgentQueryLimit     = 50
+)
+
+var (
+	ErrAgentTokenNotFound = NewError(KindNotFound, "agent token not found")
+	ErrInvalidAgentToken  = NewError(KindUnauthorized, "invalid agent token")
+	ErrInvalidAgentScope  = NewError(KindValidation, "agent scope must be todos:read, todos:create or todos:complete")
+	ErrAgentScopeMissing  = NewError(KindForbidden, "agent token lacks the scope")
+	// ErrInvalidConfirmation is returned for confirmations that expired, are of another token
+	// or todo, or of a todo that changed since, the assistant has to ask its user again.
+	ErrInvalidConfirmation  = NewError(KindConflict, "confirmation is invalid or expired, ask the user again")
+	ErrIdempotencyKeyReused = NewError(KindValidation, "idempotency key was already used for another todo")
+	ErrIdempotencyKeyInUse  = NewError(KindConflict, "a request with this idempotency key is still in progress")
+)
+
+// AgentScope is what an agent token may do, tokens only get the scopes the assistant needs.
+type AgentScope string
+
+const (
+	AgentScopeRead   AgentScope = "todos:read"
+	AgentScopeCreate AgentScope = "todos:create"
+	// AgentScopeComplete completes todos, every completion is confirmed by the user first.
+	AgentScopeComplete AgentScope = "todos:complete"
+)
+
+// AgentScopes are all scopes, in the order they are shown.
+var AgentScopes = []AgentScope{AgentScopeRead, AgentScopeCreate, AgentScopeComplete}
+
+func ParseAgentScope(value string) (AgentScope, error) {
+	if scope := AgentScope(value); slices.Contains(AgentScopes, scope) {
+		return scope, nil
+	}
+
+	return "", fmt.Errorf("%q: %w", value, ErrInvalidAgentScope)
+}
+
+// AgentToken is a long-lived credential for an AI assistant, e.g. an MCP client. Unlike a user
+// session it can only use the agent routes and only within its scopes.
+type AgentToken struct {
+	ID         uint
+	UUID       string
+	UserID     uint
+	Name       string
+	Scopes     []AgentScope
+	ExpiresAt  time.Time
+	LastUsedAt time.Time
+	CreatedAt  time.Time
+
+	// Token is only set right after creation, afterwards only its hash is known.
+	Token string
+
+	// Region is the data region the token was issued in, set by Authenticate.
+	Region string
+}
+
+// Expired reports whether the token has an expiry that has passed.
+func (t *AgentToken) Expired(now time.Time) bool {
+	return !t.ExpiresAt.IsZero() && !now.Before(t.ExpiresAt)
+}
+
+func (t *AgentToken) Allows(scope AgentScope) bool {
+	return slices.Contains(t.Scopes, scope)
+}
+
+type AgentTokenCreate struct {
+	Name      string
+	Scopes    []AgentScope
+	ExpiresAt time.Time
+}
+
+// AgentTodoQuery narrows the todos an assistant reads, Text searches titles, descriptions and
+// attachments.
+type AgentTodoQuery struct {
+	Text      string
+	ListUUID  string
+	Completed CompletedFilter
+	// DueBefore only returns todos due before it, zero returns todos with any or no due date.
+	DueBefore time.Time
+	Limit     int
+}
+
+// AgentTodoCreated is a todo created by an assistant, Replayed is set when the request was a
+// retry with an idempotency key that already created the todo.
+type AgentTodoCreated struct {
+	Todo     *Todo
+	Replayed bool
+}
+
+// AgentCompletion is the answer to an assistant completing a todo. Without a confirmation
+// nothing is changed: the assistant gets a Summary to show its user and a Confirmation to send
+// back once the user agreed, which completes the todo.
+type AgentCompletion struct {
+	Todo      *Todo
+	Completed bool
+	// Summary describes what the confirmation does, in words for the user.
+	Summary               string
+	Confirmation          string
+	ConfirmationExpiresAt time.Time
+}
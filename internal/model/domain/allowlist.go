@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"net/netip"
+	"time"
+)
+
+const (
+	// IPAllowlistBypassExpiration is how long an emailed bypass token can be used.
+	IPAllowlistBypassExpiration = time.Hour
+	// IPAllowlistSuspension is how long a bypass suspends the allowlist, long enough for the
+	// owner to fix the ranges.
+	IPAllowlistSuspension = 24 * time.Hour
+	// IPAllowlistBypassPrefix marks emailed bypass tokens.
+	IPAllowlistBypassPrefix = "ipb_"
+)
+
+var (
+	ErrIPNotAllowed         = NewError(KindForbidden, "access from this IP address is not allowed by the workspace IP allowlist")
+	ErrInvalidCIDR          = NewError(KindValidation, "invalid CIDR range")
+	ErrIPRangeNotFound      = NewError(KindNotFound, "IP range not found")
+	ErrInvalidBypassToken   = NewError(KindValidation, "invalid or expired bypass token")
+	ErrIPAllowlistOwnerOnly = NewError(KindForbidden, "only the workspace owner can do this")
+)
+
+// IPRange is a CIDR range the members of a workspace can access the API from. A workspace
+// without ranges can be accessed from anywhere.
+type IPRange struct {
+	ID          uint
+	UUID        string
+	WorkspaceID uint
+	CIDR        string
+	Label       string
+	CreatedAt   time.Time
+}
+
+type IPRangeCreate struct {
+	CIDR  string
+	Label string
+}
+
+// ParseCIDR parses an IPv4 or IPv6 range, a single address is a range of one.
+func ParseCIDR(cidr string) (netip.Prefix, error) {
+	if prefix, err := netip.ParsePrefix(cidr); err == nil {
+		return prefix.Masked(), nil
+	}
+	if addr, err := netip.ParseAddr(cidr); err == nil {
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+
+	return netip.Prefix{}, ErrInvalidCIDR
+}
+
+// IPAllowed reports whether ip is within any of the ranges, IPv4-mapped IPv6 addresses match
+// IPv4 ranges.
+func IPAllowed(ranges []*IPRange, ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, ipRange := range ranges {
+		prefix, err := ParseCIDR(ipRange.CIDR)
+		if err == nil && prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
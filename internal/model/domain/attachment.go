@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"io"
+	"time"
+)
+
+const (
+	// AttachmentURLExpiration is how long a signed download URL is valid.
+	AttachmentURLExpiration = 15 * time.Minute
+	// AttachmentExtractionAttempts is how often the text extraction of an attachment is tried.
+	AttachmentExtractionAttempts = 3
+	// AttachmentExtractionTimeout is how long an extraction may take, the claim of a worker
+	// that takes longer expires and another worker tries again.
+	AttachmentExtractionTimeout = 5 * time.Minute
+)
+
+// ExtractionStatus is how far the text extraction of an attachment got, only extracted
+// attachments are searchable by their content.
+type ExtractionStatus string
+
+const (
+	ExtractionPending     ExtractionStatus = "pending"
+	ExtractionExtracting  ExtractionStatus = "extracting"
+	ExtractionDone        ExtractionStatus = "done"
+	ExtractionUnsupported ExtractionStatus = "unsupported"
+	ExtractionFailed      ExtractionStatus = "failed"
+)
+
+var (
+	ErrAttachmentNotFound = NewError(KindNotFound, "attachment not found")
+	ErrAttachmentTooLarge = NewError(KindTooLarge, "attachment too large")
+)
+
+type Attachment struct {
+	ID          uint
+	UUID        string
+	TodoID      uint
+	UserID      uint
+	Filename    string
+	ContentType string
+	Size        int64
+	StorageKey  string
+	CreatedAt   time.Time
+
+	ExtractionStatus   ExtractionStatus
+	ExtractionAttempts int
+	// ExtractionStartedAt is the claim of the worker extracting the text.
+	ExtractionStartedAt time.Time
+}
+
+type AttachmentUpload struct {
+	Filename    string
+	ContentType string
+	Size        int64
+	Body        io.Reader
+}
+
+// AttachmentDownload is a signed URL the attachment can be downloaded from without a session.
+type AttachmentDownload struct {
+	URL       string
+	ExpiresAt time.Time
+}
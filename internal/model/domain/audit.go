@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrAuditChainBroken = errors.New("audit log chain is broken")
+
+// AuditEntry records a state-changing request. Action is the route template, e.g.
+// "PATCH /api/v1/todos/:uuid", Path is the requested path with the resource identifiers.
+type AuditEntry struct {
+	ID        uint
+	UserID    uint
+	Action    string
+	Path      string
+	Status    int
+	IP        string
+	UserAgent string
+	RequestID string
+	// Region is set for requests that are routed to a region by the services, like login.
+	Region    string
+	CreatedAt time.Time
+
+	// PrevHash is the Hash of the previous entry, so removing, reordering or changing entries
+	// breaks the chain.
+	PrevHash string
+	Hash     string
+	// AnonymizedAt is set once the user, IP and user agent of the entry were cleared because
+	// the account was purged. The entry still links the chain but no longer matches its hash.
+	AnonymizedAt time.Time
+}
+
+// AuditVerification is the result of checking the hash chain of the audit log.
+type AuditVerification struct {
+	Valid bool
+	// Checked is the number of chained entries, Unchained the number of entries written before
+	// the log was chained.
+	Checked   int
+	Unchained int
+	// Anonymized counts the checked entries whose hash can't be checked since they were
+	// anonymized, only their place in the chain is.
+	Anonymized int
+	// Head is the hash of the last entry. Truncating the log keeps the chain valid, recording
+	// the head elsewhere detects it.
+	Head string
+	// BrokenID is the first entry breaking the chain and Reason why, when the chain isn't valid.
+	BrokenID uint
+	Reason   string
+}
@@ -14,14 +14,23 @@ const (
 var (
 	ErrUnableToVerifyClaim   = errors.New("unable to verify claims")
 	ErrUnableToRetrieveToken = errors.New("unable to retrieve token")
-	ErrRefreshTokenNotFound  = errors.New("refresh token does not exist")
+	ErrRefreshTokenNotFound  = NewError(KindUnauthorized, "refresh token does not exist")
+	ErrPasswordUnchanged     = NewError(KindValidation, "the new password is the current one")
 )
 
+func init() {
+	// tokens carry their times in microseconds, the precision of the database, so a revocation
+	// rejects the tokens issued earlier in the same second but not the ones issued after it
+	jwt.TimePrecision = time.Microsecond
+}
+
 type JWTCustomClaims struct {
 	UserID uint   `json:"user_id"`
 	Email  string `json:"email"`
 	UUID   string `json:"uuid"`
-	Admin  bool   `json:"admin"`
+	// Region routes the requests of the token to the database of the data region of the user,
+	// so any instance serving the region can accept it.
+	Region string `json:"region,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -34,3 +43,9 @@ type RefreshToken struct {
 	UpdatedAt time.Time
 	DeletedAt time.Time
 }
+
+// PasswordChange changes the password of a signed in user, the current password is entered again.
+type PasswordChange struct {
+	CurrentPassword string
+	NewPassword     string
+}
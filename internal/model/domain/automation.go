@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+const (
+	// MaxAutomationModuleSize caps the WebAssembly module of an automation.
+	MaxAutomationModuleSize = 256 << 10
+	// MaxAutomations is how many automations a user can have.
+	MaxAutomations = 10
+	// AutomationFuel is how many instructions a run may execute.
+	AutomationFuel = 5_000_000
+	// AutomationMemoryPages caps the memory of a run to 1 MiB.
+	AutomationMemoryPages = 16
+	// AutomationTimeout is how long a run may take, host calls included.
+	AutomationTimeout = 5 * time.Second
+	// AutomationMaxTodos and AutomationMaxNotifications are how many todos and notifications a
+	// single run can create.
+	AutomationMaxTodos         = 10
+	AutomationMaxNotifications = 3
+	// AutomationLogSize caps what a run logs, later lines are dropped.
+	AutomationLogSize = 4 << 10
+	// AutomationEntryPoint is the function of the module called with every event.
+	AutomationEntryPoint = "handle"
+)
+
+var (
+	ErrAutomationNotFound       = NewError(KindNotFound, "automation not found")
+	ErrAutomationModuleTooLarge = NewError(KindTooLarge, "automation module is larger than 256 KiB")
+	ErrInvalidAutomationModule  = NewError(KindValidation, "invalid automation module")
+	ErrAutomationEventsTodoOnly = NewError(KindValidation, "automations can only subscribe to todo events")
+	ErrAutomationLimitReached   = NewError(KindConflict, "automation limit reached")
+)
+
+// Automation is a WebAssembly module of a power user run by a background worker when one of its
+// events happens to a todo of the user. Modules are sandboxed, they only reach the outside
+// through the host functions of the "todo" module:
+//
+//	event_size() -> i32            size of the event as JSON, the payload sent to webhooks
+//	event_read(ptr, len i32) -> i32 copies the event to memory, returns the bytes copied
+//	create_todo(ptr, len i32) -> i32 creates a todo from JSON, returns 1 on success
+//	notify(ptr, len i32) -> i32     emails the user a JSON {"title", "body"}, returns 1 on success
+//	log(ptr, len i32)               appends a line to the log of the run
+//
+// The module exports a function handle without parameters or results.
+type Automation struct {
+	ID     uint
+	UUID   string
+	UserID uint
+	Name   string
+	Events []EventType
+	// Module is the binary of the module, it is left out of lists.
+	Module    []byte
+	CreatedAt time.Time
+}
+
+func (a *Automation) Subscribed(event EventType) bool {
+	return slices.Contains(a.Events, event)
+}
+
+type AutomationCreate struct {
+	Name   string
+	Events []EventType
+	Module []byte
+}
+
+type AutomationRunStatus string
+
+const (
+	AutomationRunPending   AutomationRunStatus = "pending"
+	AutomationRunSucceeded AutomationRunStatus = "succeeded"
+	AutomationRunFailed    AutomationRunStatus = "failed"
+)
+
+// AutomationRun is an event queued for an automation, runs aren't retried since a module may
+// have created todos before it failed.
+type AutomationRun struct {
+	ID   uint
+	UUID string
+	// Automation only has the fields needed to run it.
+	Automation *Automation
+	Event      EventType
+	Payload    []byte
+	Status     AutomationRunStatus
+	// FuelUsed is how many instructions the run executed.
+	FuelUsed   uint64
+	Log        string
+	Error      string
+	FinishedAt time.Time
+	CreatedAt  time.Time
+}
+
+// AutomationNotice is a notification an automation sends its user.
+type AutomationNotice struct {
+	Automation string
+	Title      string
+	Body       string
+}
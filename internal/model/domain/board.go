@@ -0,0 +1,146 @@
+package domain
+
+import (
+	"regexp"
+	"sort"
+	"time"
+)
+
+var (
+	ErrListStatusNotFound = NewError(KindNotFound, "list status not found")
+	ErrListStatusExists   = NewError(KindConflict, "list already has a status with this key")
+	ErrLastListStatus     = NewError(KindValidation, "a list keeps at least one status")
+	ErrTodoNotOnBoard     = NewError(KindValidation, "only todos of a list have a status")
+	ErrInvalidListStatus  = NewError(KindValidation, "status keys are 1 to 32 lowercase letters, digits and dashes")
+)
+
+//nolint:gochecknoglobals // compiled once
+var listStatusKey = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// ValidListStatusKey reports whether key can name a status.
+func ValidListStatusKey(key string) bool {
+	return listStatusKey.MatchString(key)
+}
+
+// ListStatus is a column of the board of a list, e.g. "in progress". Todos moved into a done
+// status are completed, moving them out again reopens them.
+type ListStatus struct {
+	ID     uint
+	ListID uint
+	// Key names the status in the API and on the todos, it can't be changed.
+	Key  string
+	Name string
+	// Position orders the statuses of a list from 1 on.
+	Position  int
+	Done      bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// DefaultListStatuses are the statuses of lists that don't have statuses of their own, they are
+// stored once the statuses of the list are changed.
+func DefaultListStatuses(listID uint) []*ListStatus {
+	return []*ListStatus{
+		{ListID: listID, Key: "backlog", Name: "Backlog", Position: 1},
+		{ListID: listID, Key: "in-progress", Name: "In progress", Position: 2},
+		{ListID: listID, Key: "blocked", Name: "Blocked", Position: 3},
+		{ListID: listID, Key: "done", Name: "Done", Position: 4, Done: true},
+	}
+}
+
+// ListStatusCreate adds a status to a list, at the end unless Position is set.
+type ListStatusCreate struct {
+	Key      string
+	Name     string
+	Done     bool
+	Position int
+}
+
+// ListStatusUpdate changes a status, nil fields stay as they are. Position moves the status to
+// another column, the others move up or down.
+type ListStatusUpdate struct {
+	Name     *string
+	Done     *bool
+	Position *int
+}
+
+// TodoPlacement moves a todo to Status of the board of its list, at Position from 1 on. Positions
+// past the end of the column put it last.
+type TodoPlacement struct {
+	Status   string
+	Position int
+	// Version is the version of the todo the client last read, nil skips the check.
+	Version *int
+	// Force completes the todo in a done status even though open todos block it.
+	Force bool
+}
+
+// Board is a list with its todos in the columns of its statuses.
+type Board struct {
+	List    *List
+	Columns []*BoardColumn
+}
+
+type BoardColumn struct {
+	Status *ListStatus
+	// Todos are in position order, todos that weren't placed yet follow in the order they were
+	// created.
+	Todos []*Todo
+}
+
+// NewBoard sorts todos into the columns of statuses. Todos that weren't placed yet, or whose
+// status is gone, are in the first column, or in the first done column once they are completed.
+func NewBoard(list *List, statuses []*ListStatus, todos []*Todo) *Board {
+	board := &Board{List: list, Columns: make([]*BoardColumn, 0, len(statuses))}
+	byKey := make(map[string]*BoardColumn, len(statuses))
+	var firstDone *BoardColumn
+	for _, status := range statuses {
+		column := &BoardColumn{Status: status, Todos: []*Todo{}}
+		board.Columns = append(board.Columns, column)
+		byKey[status.Key] = column
+		if status.Done && firstDone == nil {
+			firstDone = column
+		}
+	}
+	if len(board.Columns) == 0 {
+		return board
+	}
+
+	for _, todo := range todos {
+		column, ok := byKey[todo.Status]
+		switch {
+		case ok:
+		case todo.Completed() && firstDone != nil:
+			column = firstDone
+		default:
+			column = board.Columns[0]
+		}
+		column.Todos = append(column.Todos, todo)
+	}
+
+	for _, column := range board.Columns {
+		sort.SliceStable(column.Todos, func(i, j int) bool {
+			a, b := column.Todos[i], column.Todos[j]
+			if (a.Position == 0) != (b.Position == 0) {
+				return a.Position != 0
+			}
+			if a.Position != b.Position {
+				return a.Position < b.Position
+			}
+			return a.CreatedAt.Before(b.CreatedAt)
+		})
+	}
+
+	return board
+}
+
+// Column returns the column of a status, nil when the board has no such status.
+func (b *Board) Column(key string) *BoardColumn {
+	for _, column := range b.Columns {
+		if column.Status.Key == key {
+			return column
+		}
+	}
+
+	return nil
+}
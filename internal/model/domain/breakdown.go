@@ -0,0 +1,37 @@
+package domain
+
+import "time"
+
+const (
+	// MaxBreakdownSteps caps the steps of a proposed breakdown, longer proposals are cut.
+	MaxBreakdownSteps = 20
+	// MaxBreakdownStepEstimate caps the estimate of a step like the estimate of a todo.
+	MaxBreakdownStepEstimate = 24 * time.Hour
+)
+
+var (
+	ErrBreakdownDisabled = NewError(KindNotFound, "todo breakdown is not enabled on this server")
+)
+
+// TodoBreakdown is the proposal of a language model for splitting a todo into steps. Nothing is
+// created until the user confirms the steps, possibly edited, as a TodoSplit.
+type TodoBreakdown struct {
+	Todo  *Todo
+	Steps []*BreakdownStep
+}
+
+// Estimate returns the estimate of all steps.
+func (b *TodoBreakdown) Estimate() time.Duration {
+	var estimate time.Duration
+	for _, step := range b.Steps {
+		estimate += step.Estimate
+	}
+
+	return estimate
+}
+
+type BreakdownStep struct {
+	Title string
+	// Estimate is zero when the model didn't estimate the step.
+	Estimate time.Duration
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// CalendarTokenPrefix makes calendar subscription tokens recognizable, e.g. in leaked
+// credential scans.
+const CalendarTokenPrefix = "cal_"
+
+var (
+	ErrCalendarTokenNotFound = NewError(KindNotFound, "calendar token not found")
+	ErrInvalidCalendarToken  = NewError(KindUnauthorized, "invalid calendar token")
+	ErrInvalidCalendarFormat = NewError(KindValidation, "invalid calendar component, expected vevent or vtodo")
+)
+
+// CalendarToken is a read-only credential for the calendar feed of a user. Calendar apps
+// poll the feed with the token in the subscription URL since they can't send a session.
+type CalendarToken struct {
+	ID         uint
+	UUID       string
+	UserID     uint
+	Name       string
+	LastUsedAt time.Time
+	CreatedAt  time.Time
+
+	// Token is only set right after creation, afterwards only its hash is known.
+	Token string
+
+	// Region is the data region the token was issued in, set by Authenticate.
+	Region string
+}
+
+// CalendarComponent is how due todos appear in a calendar feed.
+type CalendarComponent string
+
+const (
+	// CalendarEvent renders todos as events at their due time, every calendar app shows them.
+	CalendarEvent CalendarComponent = "vevent"
+	// CalendarTodo renders todos as tasks, only some apps show them.
+	CalendarTodo CalendarComponent = "vtodo"
+)
+
+// ParseCalendarComponent parses a component name, empty is CalendarEvent.
+func ParseCalendarComponent(component string) (CalendarComponent, error) {
+	switch CalendarComponent(component) {
+	case "", CalendarEvent:
+		return CalendarEvent, nil
+	case CalendarTodo:
+		return CalendarTodo, nil
+	default:
+		return "", fmt.Errorf("%q: %w", component, ErrInvalidCalendarFormat)
+	}
+}
@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultChartPeriod is the period charts cover when no period is given.
+	DefaultChartPeriod = 30 * 24 * time.Hour
+	// MaxChartPoints caps the buckets of a chart, a year of days.
+	MaxChartPoints = 366
+	// RollupWindow is how far back the rollup worker recomputes the rollups, changes to todos
+	// older than that, e.g. reopening a todo completed last month, don't change the history.
+	RollupWindow = 48 * time.Hour
+)
+
+var (
+	ErrInvalidChartGranularity = NewError(KindValidation, "granularity must be day, week or month")
+	ErrInvalidChartRange       = NewError(KindValidation, "chart range must end after it starts")
+	ErrChartRangeTooLarge      = NewError(KindValidation, fmt.Sprintf("chart range has more than %d points, use a coarser granularity", MaxChartPoints))
+)
+
+// ChartGranularity is the size of the buckets of a chart, buckets start at midnight UTC and
+// weeks start on Monday.
+type ChartGranularity string
+
+const (
+	ChartDay   ChartGranularity = "day"
+	ChartWeek  ChartGranularity = "week"
+	ChartMonth ChartGranularity = "month"
+)
+
+// ParseChartGranularity defaults to ChartDay.
+func ParseChartGranularity(name string) (ChartGranularity, error) {
+	switch granularity := ChartGranularity(name); granularity {
+	case "":
+		return ChartDay, nil
+	case ChartDay, ChartWeek, ChartMonth:
+		return granularity, nil
+	}
+
+	return "", fmt.Errorf("%q: %w", name, ErrInvalidChartGranularity)
+}
+
+// Truncate returns the start of the bucket t falls into.
+func (g ChartGranularity) Truncate(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch g {
+	case ChartWeek:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case ChartMonth:
+		return day.AddDate(0, 0, 1-day.Day())
+	}
+
+	return day
+}
+
+// Next returns the start of the bucket after the one starting at start.
+func (g ChartGranularity) Next(start time.Time) time.Time {
+	switch g {
+	case ChartWeek:
+		return start.AddDate(0, 0, 7)
+	case ChartMonth:
+		return start.AddDate(0, 1, 0)
+	}
+
+	return start.AddDate(0, 0, 1)
+}
+
+// ChartQuery selects the todos a chart covers: those of a list, those of the household of the
+// user with Workspace, or otherwise those of the user.
+type ChartQuery struct {
+	// ListUUID is resolved to RollupScope.ListID by the service.
+	ListUUID    string
+	Workspace   bool
+	From        time.Time
+	To          time.Time
+	Granularity ChartGranularity
+}
+
+// RollupScope selects the rollups of a list, or of the users when ListID is 0.
+type RollupScope struct {
+	ListID  uint
+	UserIDs []uint
+}
+
+// TodoRollup counts the todos created, completed and deleted while open on a day, or before a
+// day for the totals a chart starts from.
+type TodoRollup struct {
+	Day       time.Time
+	Created   int
+	Completed int
+	Deleted   int
+}
+
+// Opened is how much the rollup changed the number of open todos.
+func (r *TodoRollup) Opened() int {
+	return r.Created - r.Completed - r.Deleted
+}
+
+// BurndownPoint is the state at the end of a bucket, Completed counts the todos completed since
+// the start of the chart.
+type BurndownPoint struct {
+	Start     time.Time
+	Open      int
+	Completed int
+}
+
+// TrendPoint counts the todos created and completed during a bucket.
+type TrendPoint struct {
+	Start     time.Time
+	Created   int
+	Completed int
+}
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// MaxChecklistItems caps the todos created by a single split.
+const MaxChecklistItems = 100
+
+var (
+	ErrNoChecklist        = NewError(KindValidation, "todo has no checklist items")
+	ErrChecklistTooLong   = NewError(KindValidation, "todo has too many checklist items to split at once")
+	ErrSplitListAmbiguous = NewError(KindValidation, "either a list or a new list can be given, not both")
+)
+
+//nolint:gochecknoglobals // compiled once
+var checklistItemPattern = regexp.MustCompile(`^\s*[-*+]\s+\[([ xX])\]\s+(.*\S)\s*$`)
+
+// ChecklistItem is a Markdown task list item of a todo description, e.g. "- [x] buy paint".
+type ChecklistItem struct {
+	Title     string
+	Completed bool
+	// Estimate is only set on items that don't come from a description, e.g. of a breakdown.
+	Estimate time.Duration
+}
+
+// ParseChecklist returns the checklist items of a description in order and the description
+// without them.
+func ParseChecklist(description string) ([]ChecklistItem, string) {
+	var items []ChecklistItem
+	var rest []string
+	for _, line := range strings.Split(description, "\n") {
+		match := checklistItemPattern.FindStringSubmatch(line)
+		if match == nil {
+			rest = append(rest, line)
+			continue
+		}
+		items = append(items, ChecklistItem{
+			Title:     match[2],
+			Completed: match[1] != " ",
+		})
+	}
+
+	return items, strings.TrimSpace(strings.Join(rest, "\n"))
+}
+
+// TodoSplit turns the checklist of a todo into todos of their own. They are created in the list
+// of the todo unless ListUUID names another list or NewList asks for a list named after the todo.
+type TodoSplit struct {
+	ListUUID string
+	NewList  bool
+	// Items are split off instead of the checklist, the description of the todo is kept then.
+	Items []ChecklistItem
+	// Version is the version of the todo the client last read, nil skips the check.
+	Version *int
+}
+
+type TodoSplitResult struct {
+	// Todo is the split todo, its description no longer holds the checklist.
+	Todo *Todo
+	// List is only set when a new list was created for the todos.
+	List  *List
+	Todos []*Todo
+}
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"time"
+)
+
+var (
+	ErrCommentNotFound  = NewError(KindNotFound, "comment not found")
+	ErrCommentForbidden = NewError(KindForbidden, "only the author can change this comment")
+)
+
+type Comment struct {
+	ID     uint
+	UUID   string
+	TodoID uint
+	// TodoUUID is only set for comments read across todos.
+	TodoUUID   string
+	AuthorID   uint
+	AuthorUUID string
+	// AuthorName is the full name of the author, falling back to the username or email.
+	AuthorName string
+	Body       string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	DeletedAt  time.Time
+}
+
+// Edited reports whether the comment was changed after it was posted.
+func (c *Comment) Edited() bool {
+	return c.UpdatedAt.After(c.CreatedAt)
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// DateLayout is the layout of calendar dates, e.g. of plans and habit entries.
+const DateLayout = time.DateOnly
+
+var ErrInvalidDate = NewError(KindValidation, "invalid date, expected YYYY-MM-DD")
+
+// ParseDate parses a YYYY-MM-DD date, an empty date is today in UTC.
+func ParseDate(date string) (time.Time, error) {
+	if date == "" {
+		return Day(time.Now()), nil
+	}
+
+	day, err := time.Parse(DateLayout, date)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q: %w", date, ErrInvalidDate)
+	}
+
+	return day, nil
+}
+
+// Day truncates t to the start of its day in UTC.
+func Day(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// DayIn returns the calendar date of t in the timezone, at the start of the day in UTC like
+// the dates returned by Day.
+func DayIn(t time.Time, location *time.Location) time.Time {
+	year, month, day := t.In(location).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,54 @@
+package domain
+
+import "time"
+
+const (
+	// DigestPeriod is the week a digest sums up, upcoming todos are those due within the next.
+	DigestPeriod = 7 * 24 * time.Hour
+	// DefaultDigestWeekday and DefaultDigestHour are when digests are sent in the timezone of the
+	// user unless they picked another time.
+	DefaultDigestWeekday = time.Monday
+	DefaultDigestHour    = 8
+	// MaxDigestTodos caps the todos of each section of a digest, the rest are left out.
+	MaxDigestTodos = 20
+	// DigestRetryInterval is when a digest that failed to be assembled is tried again.
+	DigestRetryInterval = time.Hour
+)
+
+// DigestSettings are the weekly digest a user opted in to, it is sent on Weekday at Hour in the
+// timezone of the user. Users who never saved settings get DefaultDigestSettings.
+type DigestSettings struct {
+	UserID  uint
+	Enabled bool
+	Weekday time.Weekday
+	Hour    int
+	// NextSendAt and LastSentAt are kept by the digest worker.
+	NextSendAt time.Time
+	LastSentAt time.Time
+}
+
+func DefaultDigestSettings(userID uint) *DigestSettings {
+	return &DigestSettings{
+		UserID:  userID,
+		Weekday: DefaultDigestWeekday,
+		Hour:    DefaultDigestHour,
+	}
+}
+
+// NextSend returns the first time after now the digest is due, on Weekday at Hour in location.
+func (s *DigestSettings) NextSend(now time.Time, location *time.Location) time.Time {
+	local := now.In(location)
+	next := time.Date(local.Year(), local.Month(), local.Day(), s.Hour, 0, 0, 0, location)
+	next = next.AddDate(0, 0, (int(s.Weekday)-int(next.Weekday())+7)%7)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 7)
+	}
+
+	return next
+}
+
+type DigestSettingsUpdate struct {
+	Enabled bool
+	Weekday time.Weekday
+	Hour    int
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"time"
+)
+
+const (
+	// DisplayTokenPrefix makes display tokens recognizable, e.g. in leaked credential scans.
+	DisplayTokenPrefix = "dsp_"
+	// DisplayRefreshInterval is how often a display should poll its board for updates.
+	DisplayRefreshInterval = 15 * time.Second
+)
+
+var (
+	ErrDisplayTokenNotFound = NewError(KindNotFound, "display token not found")
+	ErrInvalidDisplayToken  = NewError(KindUnauthorized, "invalid display token")
+)
+
+// DisplayToken is a long-lived, read-only credential for wall mounted displays. It can only
+// read the lists it is scoped to and never acts as a user session.
+type DisplayToken struct {
+	ID         uint
+	UUID       string
+	UserID     uint
+	Name       string
+	ListIDs    []uint
+	ListUUIDs  []string
+	ExpiresAt  time.Time
+	LastUsedAt time.Time
+	CreatedAt  time.Time
+
+	// Token is only set right after creation, afterwards only its hash is known.
+	Token string
+}
+
+// Expired reports whether the token has an expiry that has passed.
+func (t *DisplayToken) Expired(now time.Time) bool {
+	return !t.ExpiresAt.IsZero() && !now.Before(t.ExpiresAt)
+}
+
+type DisplayTokenCreate struct {
+	Name      string
+	ListUUIDs []string
+	ExpiresAt time.Time
+}
+
+// DisplayBoard is the read-only view of the lists a display token is scoped to. Version
+// changes whenever any list or todo on the board changes.
+type DisplayBoard struct {
+	Lists   []*DisplayBoardList
+	Version string
+}
+
+type DisplayBoardList struct {
+	List  *List
+	Todos []*Todo
+}
@@ -0,0 +1,51 @@
+package domain
+
+import "time"
+
+const (
+	// EmailChangeExpiration is how long the emailed links of an email change can be used.
+	EmailChangeExpiration = 24 * time.Hour
+	// EmailChangeTokenPrefix marks the emailed email change tokens.
+	EmailChangeTokenPrefix = "eml_"
+)
+
+var (
+	// ErrIncorrectPassword is returned for a wrong password of a signed in user, unlike
+	// ErrInvalidCredentials it doesn't end the session of the client.
+	ErrIncorrectPassword       = NewError(KindForbidden, "incorrect password")
+	ErrEmailUnchanged          = NewError(KindValidation, "the new email address is the current one")
+	ErrEmailTaken              = NewError(KindConflict, "email address is already in use")
+	ErrChildAccountEmail       = NewError(KindForbidden, "child accounts have no email address")
+	ErrEmailChangeNotFound     = NewError(KindNotFound, "no email change is pending")
+	ErrInvalidEmailChangeToken = NewError(KindValidation, "invalid or expired email change token")
+)
+
+// EmailChangeRequest changes the email of an account, the password is entered again.
+type EmailChangeRequest struct {
+	Email    string
+	Password string
+}
+
+// EmailChange is a pending change of the email of an account. Both the current and the new
+// address get a link to confirm it, the email is swapped once both confirmed.
+type EmailChange struct {
+	ID             uint
+	UUID           string
+	UserID         uint
+	NewEmail       string
+	OldConfirmedAt time.Time
+	NewConfirmedAt time.Time
+	ExpiresAt      time.Time
+	CompletedAt    time.Time
+	CreatedAt      time.Time
+}
+
+// Confirmed reports whether both addresses confirmed the change.
+func (c *EmailChange) Confirmed() bool {
+	return !c.OldConfirmedAt.IsZero() && !c.NewConfirmedAt.IsZero()
+}
+
+// Completed reports whether the email was swapped.
+func (c *EmailChange) Completed() bool {
+	return !c.CompletedAt.IsZero()
+}
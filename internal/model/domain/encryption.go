@@ -0,0 +1,52 @@
+package domain
+
+import "time"
+
+// MaxBlindIndexes caps the blind indexes of a single encrypted todo.
+const MaxBlindIndexes = 32
+
+var (
+	ErrListEncrypted    = NewError(KindValidation, "todos of an encrypted list take ciphertext instead of a title and description")
+	ErrListNotEncrypted = NewError(KindValidation, "only todos of an encrypted list take ciphertext")
+	ErrTodoEncrypted    = NewError(KindValidation, "the server can't read encrypted todos to do this")
+	ErrStaleListKey     = NewError(KindConflict, "todo must be encrypted with the current key of the list")
+
+	ErrTooManyBlindIndexes = NewError(KindValidation, "todo has too many blind indexes")
+
+	ErrListNotEmpty         = NewError(KindValidation, "only a list without todos can be encrypted")
+	ErrListAlreadyEncrypted = NewError(KindConflict, "list is already encrypted")
+	ErrPublicKeyNotFound    = NewError(KindNotFound, "public key not found")
+	ErrListKeyNotMember     = NewError(KindValidation, "list keys can only be wrapped for the owner and members of the list")
+	ErrIncompleteListKeys   = NewError(KindValidation, "a new list key must be wrapped for the owner and every member of the list")
+)
+
+// PublicKey is the key list keys are wrapped with for a user. Its format is up to the clients,
+// the server stores it as is and never sees the private key.
+type PublicKey struct {
+	UserID    uint
+	UserUUID  string
+	Key       string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// ListKey is a version of the key of an encrypted list, wrapped with the public key of a user who
+// holds it. Clients encrypt todos with the current version and keep the older versions to read
+// the todos that weren't encrypted again after a rotation. Blind indexes are keyed hashes clients
+// derive from the list key, so the server can match them without learning what they stand for.
+type ListKey struct {
+	ListID     uint
+	UserID     uint
+	UserUUID   string
+	KeyVersion int
+	WrappedKey string
+	CreatedAt  time.Time
+}
+
+// ListKeyring is what a user needs to read an encrypted list: the versions of its key wrapped
+// for them. Pending are the members the owner still has to wrap the current key for.
+type ListKeyring struct {
+	KeyVersion int
+	Keys       []*ListKey
+	Pending    []string
+}
@@ -0,0 +1,52 @@
+package domain
+
+import "errors"
+
+// ErrorKind sorts the errors of the service layer by what went wrong, so the transports can
+// answer them alike without knowing every error.
+type ErrorKind int
+
+const (
+	// KindInternal is a failure the caller can't do anything about, errors without a kind are
+	// internal.
+	KindInternal ErrorKind = iota
+	// KindValidation is input that can't be acted on.
+	KindValidation
+	// KindUnauthorized is a missing or invalid credential.
+	KindUnauthorized
+	// KindForbidden is an action the caller isn't allowed to take.
+	KindForbidden
+	KindNotFound
+	// KindConflict is an action that clashes with the current state, e.g. a name that's taken
+	// or a stale version.
+	KindConflict
+	KindTooLarge
+	// KindRateLimited is a limit on how often something may be done.
+	KindRateLimited
+)
+
+// kindError is an error of a kind, the sentinel errors of this package are kindErrors.
+type kindError struct {
+	kind    ErrorKind
+	message string
+}
+
+func (e *kindError) Error() string {
+	return e.message
+}
+
+// NewError returns an error of kind, like errors.New every call returns a distinct error.
+func NewError(kind ErrorKind, message string) error {
+	return &kindError{kind: kind, message: message}
+}
+
+// KindOf returns the kind of the first error of a kind that err wraps, KindInternal if it
+// wraps none.
+func KindOf(err error) ErrorKind {
+	var kinded *kindError
+	if errors.As(err, &kinded) {
+		return kinded.kind
+	}
+
+	return KindInternal
+}
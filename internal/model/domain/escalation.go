@@ -0,0 +1,52 @@
+package domain
+
+import "time"
+
+var (
+	ErrEscalationRuleNotFound = NewError(KindNotFound, "escalation rule not found")
+	ErrEscalationNoActions    = NewError(KindValidation, "escalation rule must set a priority or notify")
+)
+
+// EscalationRule escalates the open todos of a list once they are overdue by OverdueBy: their
+// priority is raised to Priority and the owner of the list is emailed when Notify is set. A rule
+// applies to a todo once per due date, postponing the todo arms it again.
+type EscalationRule struct {
+	ID     uint
+	UUID   string
+	ListID uint
+	UserID uint
+	// OverdueBy is counted in whole days, zero escalates todos as soon as they are overdue.
+	OverdueBy time.Duration
+	// Priority is nil when the rule leaves the priority as is, a todo with a higher priority
+	// keeps it.
+	Priority  *Priority
+	Notify    bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type EscalationRuleCreate struct {
+	OverdueBy time.Duration
+	Priority  *Priority
+	Notify    bool
+}
+
+// Escalation is a rule that was applied to a todo, the escalations of a list are its activity log.
+type Escalation struct {
+	ID       uint
+	RuleID   uint
+	RuleUUID string
+	ListID   uint
+	TodoID   uint
+	TodoUUID string
+	// UserID owns the todo, the owner of the list it is in.
+	UserID  uint
+	Title   string
+	DueDate time.Time
+	// PriorityFrom is the priority the todo had, PriorityTo the one it was raised to or nil
+	// when it was left as is.
+	PriorityFrom Priority
+	PriorityTo   *Priority
+	Notified     bool
+	CreatedAt    time.Time
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrInvalidEventType = NewError(KindValidation, "invalid event type")
+
+// EventType names a change of a resource, it is also the event name sent to webhooks.
+type EventType string
+
+const (
+	EventTodoCreated   EventType = "todo.created"
+	EventTodoUpdated   EventType = "todo.updated"
+	EventTodoCompleted EventType = "todo.completed"
+	EventTodoDeleted   EventType = "todo.deleted"
+	EventTodoMoved     EventType = "todo.moved"
+
+	// security events are only sent to webhooks of household owners, see SecurityEvent.
+	EventSecurityLogin             EventType = "security.login"
+	EventSecurityLoginFailed       EventType = "security.login_failed"
+	EventSecurityTokenRevoked      EventType = "security.token_revoked"
+	EventSecurityPermissionChanged EventType = "security.permission_changed"
+	// EventSecurityLoginAlert follows a login from a new device or country, see Login.
+	EventSecurityLoginAlert EventType = "security.login_alert"
+)
+
+// EventTypes lists every event type in a stable order.
+//
+//nolint:gochecknoglobals // lookup table
+var EventTypes = []EventType{
+	EventTodoCreated, EventTodoUpdated, EventTodoCompleted, EventTodoDeleted, EventTodoMoved,
+	EventSecurityLogin, EventSecurityLoginFailed, EventSecurityTokenRevoked, EventSecurityPermissionChanged,
+	EventSecurityLoginAlert,
+}
+
+func ParseEventType(name string) (EventType, error) {
+	for _, eventType := range EventTypes {
+		if string(eventType) == name {
+			return eventType, nil
+		}
+	}
+
+	return "", fmt.Errorf("%q: %w", name, ErrInvalidEventType)
+}
+
+// Security reports whether the event is a security event.
+func (t EventType) Security() bool {
+	return strings.HasPrefix(string(t), "security.")
+}
+
+// TodoEvent is published after a todo changed, Todo is the state right after the change.
+type TodoEvent struct {
+	Type EventType
+	Todo *Todo
+	// Previous is the todo before it was moved, it is only set for EventTodoMoved.
+	Previous   *Todo
+	OccurredAt time.Time
+}
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"time"
+)
+
+const (
+	// FeedTokenPrefix makes feed tokens recognizable, e.g. in leaked credential scans.
+	FeedTokenPrefix = "fed_"
+	// FeedWindow is how far back a list feed reaches, older additions and completions drop out.
+	FeedWindow = 30 * 24 * time.Hour
+	// FeedMaxEntries caps the entries of a list feed, the newest are kept.
+	FeedMaxEntries = 100
+)
+
+var (
+	ErrFeedTokenNotFound = NewError(KindNotFound, "feed token not found")
+	ErrInvalidFeedToken  = NewError(KindUnauthorized, "invalid feed token")
+)
+
+// FeedToken is a read-only credential for the Atom feed of a single list. Feed readers poll
+// the feed with the token in the URL since they can't send a session.
+type FeedToken struct {
+	ID         uint
+	UUID       string
+	UserID     uint
+	ListID     uint
+	ListUUID   string
+	Name       string
+	LastUsedAt time.Time
+	CreatedAt  time.Time
+
+	// Token is only set right after creation, afterwards only its hash is known.
+	Token string
+
+	// Region is the data region the token was issued in, set by Authenticate.
+	Region string
+}
+
+// FeedEntryKind is what happened to the todo of a feed entry.
+type FeedEntryKind string
+
+const (
+	FeedEntryAdded     FeedEntryKind = "added"
+	FeedEntryCompleted FeedEntryKind = "completed"
+)
+
+// FeedEntry is a todo that was added to or completed on a list.
+type FeedEntry struct {
+	Kind FeedEntryKind
+	Todo *Todo
+	At   time.Time
+}
+
+// ListFeed is the recent activity of a list, newest entry first.
+type ListFeed struct {
+	List    *List
+	Entries []*FeedEntry
+}
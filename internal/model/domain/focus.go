@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"time"
+)
+
+const (
+	// DefaultFocusDuration is the length of a classic pomodoro.
+	DefaultFocusDuration = 25 * time.Minute
+	// DefaultFocusStatsPeriod is the period stats cover when no period is given.
+	DefaultFocusStatsPeriod = 7 * 24 * time.Hour
+)
+
+var (
+	ErrFocusSessionNotFound = NewError(KindNotFound, "focus session not found")
+	ErrFocusSessionActive   = NewError(KindConflict, "a focus session is already active")
+	ErrFocusSessionEnded    = NewError(KindConflict, "focus session already ended")
+)
+
+type FocusStatus string
+
+const (
+	FocusStatusActive    FocusStatus = "active"
+	FocusStatusCompleted FocusStatus = "completed"
+	FocusStatusAbandoned FocusStatus = "abandoned"
+)
+
+type FocusSession struct {
+	ID        uint
+	UUID      string
+	UserID    uint
+	TodoID    uint
+	TodoUUID  string
+	TodoTitle string
+	Status    FocusStatus
+	Planned   time.Duration
+	// Focused is the time spent on the session, it is only known once the session ended.
+	Focused       time.Duration
+	Interruptions int
+	StartedAt     time.Time
+	EndedAt       time.Time
+}
+
+// Ends returns when the planned session is over.
+func (s *FocusSession) Ends() time.Time {
+	return s.StartedAt.Add(s.Planned)
+}
+
+type FocusSessionStart struct {
+	TodoUUID string
+	// Duration defaults to DefaultFocusDuration.
+	Duration time.Duration
+}
+
+type FocusSessionFilter struct {
+	// TodoUUID is resolved to TodoID by the service.
+	TodoUUID string
+	TodoID   uint
+}
+
+// FocusStats aggregates the ended focus sessions of a period, the time tracked per todo.
+type FocusStats struct {
+	From          time.Time
+	To            time.Time
+	Sessions      int
+	Completed     int
+	Interruptions int
+	Focused       time.Duration
+	Todos         []*FocusTodoStats
+}
+
+type FocusTodoStats struct {
+	TodoUUID  string
+	TodoTitle string
+	Sessions  int
+	Focused   time.Duration
+}
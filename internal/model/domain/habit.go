@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"time"
+)
+
+const (
+	// DailyHabitTarget is the weekly target of habits done every day, their streaks count days
+	// instead of weeks.
+	DailyHabitTarget = 7
+	// DefaultHabitStatsWeeks is how many weeks stats cover when no period is given.
+	DefaultHabitStatsWeeks = 4
+)
+
+var (
+	ErrHabitNotFound      = NewError(KindNotFound, "habit not found")
+	ErrHabitEntryNotFound = NewError(KindNotFound, "habit entry not found")
+	ErrFutureHabitEntry   = NewError(KindValidation, "habit entries can't be in the future")
+)
+
+// Habit is a recurring goal that is met by checking it off TargetPerWeek times a week on any
+// days, unlike a todo it is never completed for good.
+type Habit struct {
+	ID            uint
+	UUID          string
+	UserID        uint
+	Title         string
+	TargetPerWeek int
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Daily reports whether the habit is meant to be done every day.
+func (h *Habit) Daily() bool {
+	return h.TargetPerWeek >= DailyHabitTarget
+}
+
+type HabitCreate struct {
+	Title         string
+	TargetPerWeek int
+}
+
+// HabitUpdate holds the fields to change, nil fields are left untouched.
+type HabitUpdate struct {
+	Title         *string
+	TargetPerWeek *int
+}
+
+type HabitEntryStatus string
+
+const (
+	HabitEntryDone HabitEntryStatus = "done"
+	// HabitEntrySkipped excuses a day, e.g. when sick, it neither counts nor breaks a streak.
+	HabitEntrySkipped HabitEntryStatus = "skipped"
+)
+
+type HabitEntry struct {
+	HabitID uint
+	Date    time.Time
+	Status  HabitEntryStatus
+}
+
+type HabitStats struct {
+	From time.Time
+	To   time.Time
+	// StreakUnit is "day" for daily habits and "week" for the others.
+	StreakUnit    string
+	CurrentStreak int
+	LongestStreak int
+	Done          int
+	Skipped       int
+	// CompletionRate is the share of the weekly targets met within the period, from 0 to 1.
+	CompletionRate float64
+	Weeks          []*HabitWeek
+}
+
+// HabitWeek sums up a week starting on Monday, skipped days lower the target once too few days
+// are left to meet it.
+type HabitWeek struct {
+	Start   time.Time
+	Done    int
+	Skipped int
+	Target  int
+	Met     bool
+}
+
+// WeekStart returns the Monday of the week of the given day.
+func WeekStart(day time.Time) time.Time {
+	day = Day(day)
+	offset := (int(day.Weekday()) + 6) % 7
+
+	return day.AddDate(0, 0, -offset)
+}
@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+var (
+	ErrInvalidWorkingHours = NewError(KindValidation, "invalid working hours")
+	ErrHolidayNotFound     = NewError(KindNotFound, "holiday not found")
+	ErrHolidayExists       = NewError(KindConflict, "the workspace already has a holiday on that date")
+	ErrWorkspaceOwnerOnly  = NewError(KindForbidden, "only the owner can change the workspace")
+)
+
+// WorkingHours are the hours the members of a workspace usually work, the same on every
+// working day.
+type WorkingHours struct {
+	// Start and End are the times of day, since midnight in Timezone.
+	Start    time.Duration
+	End      time.Duration
+	Days     []time.Weekday
+	Timezone string
+}
+
+// DefaultWorkingHours are nine to five on weekdays in UTC.
+func DefaultWorkingHours() *WorkingHours {
+	return &WorkingHours{
+		Start:    9 * time.Hour,
+		End:      17 * time.Hour,
+		Days:     []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+		Timezone: "UTC",
+	}
+}
+
+func (h *WorkingHours) Validate() error {
+	if h.Start < 0 || h.End > 24*time.Hour || h.Start >= h.End {
+		return fmt.Errorf("working hours have to start before they end within a day: %w", ErrInvalidWorkingHours)
+	}
+	if len(h.Days) == 0 {
+		return fmt.Errorf("at least one working day is required: %w", ErrInvalidWorkingHours)
+	}
+	if _, err := time.LoadLocation(h.Timezone); err != nil {
+		return fmt.Errorf("unknown timezone %q: %w", h.Timezone, ErrInvalidWorkingHours)
+	}
+
+	return nil
+}
+
+// Length is how long a working day is.
+func (h *WorkingHours) Length() time.Duration {
+	return h.End - h.Start
+}
+
+// Location returns the timezone of the working hours, UTC when it is unknown.
+func (h *WorkingHours) Location() *time.Location {
+	location, err := time.LoadLocation(h.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return location
+}
+
+// Holiday is a day off of every member of a workspace.
+type Holiday struct {
+	ID          uint
+	UUID        string
+	WorkspaceID uint
+	// Date is the calendar date, only its month and day matter for recurring holidays.
+	Date      time.Time
+	Name      string
+	Recurring bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// On reports whether the holiday falls on the calendar date.
+func (h *Holiday) On(date time.Time) bool {
+	if h.Recurring {
+		return h.Date.Month() == date.Month() && h.Date.Day() == date.Day()
+	}
+
+	return h.Date.Equal(Day(date))
+}
+
+// HolidayUpdate holds the fields to change, nil fields are left untouched.
+type HolidayUpdate struct {
+	Date      *time.Time
+	Name      *string
+	Recurring *bool
+}
+
+// WorkCalendar is when the members of a workspace work, used to plan their days and to hold
+// scheduled emails on holidays.
+type WorkCalendar struct {
+	Hours    *WorkingHours
+	Holidays []*Holiday
+}
+
+// Date returns the calendar date of t in the timezone of the workspace.
+func (c *WorkCalendar) Date(t time.Time) time.Time {
+	year, month, day := t.In(c.Hours.Location()).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// Holiday returns the holiday on the calendar date, nil on other days.
+func (c *WorkCalendar) Holiday(date time.Time) *Holiday {
+	for _, holiday := range c.Holidays {
+		if holiday.On(date) {
+			return holiday
+		}
+	}
+
+	return nil
+}
+
+// WorkingDay reports whether the calendar date is a working day that isn't a holiday.
+func (c *WorkCalendar) WorkingDay(date time.Time) bool {
+	return slices.Contains(c.Hours.Days, date.Weekday()) && c.Holiday(date) == nil
+}
@@ -0,0 +1,49 @@
+package domain
+
+var (
+	ErrChildNotFound      = NewError(KindNotFound, "child account not found")
+	ErrUsernameTaken      = NewError(KindConflict, "username already taken")
+	ErrChildAccount       = NewError(KindForbidden, "not available for child accounts")
+	ErrParentalControl    = NewError(KindForbidden, "not allowed by parental controls")
+	ErrInvalidChildUpdate = NewError(KindValidation, "no child account changes provided")
+)
+
+// ParentalAction is an action a parent can allow or deny for their child accounts.
+type ParentalAction string
+
+const (
+	ParentalActionShare  ParentalAction = "share"
+	ParentalActionDelete ParentalAction = "delete"
+)
+
+// ParentalControls are the permissions a parent grants a child account, everything is denied by default.
+type ParentalControls struct {
+	AllowSharing  bool
+	AllowDeletion bool
+}
+
+// Allows reports whether the controls permit the action.
+func (p ParentalControls) Allows(action ParentalAction) bool {
+	switch action {
+	case ParentalActionShare:
+		return p.AllowSharing
+	case ParentalActionDelete:
+		return p.AllowDeletion
+	default:
+		return false
+	}
+}
+
+// ChildCreate holds the details of a new child account, child accounts log in with a username instead of an email.
+type ChildCreate struct {
+	Username  string
+	Password  string
+	FirstName string
+	LastName  string
+	Controls  ParentalControls
+}
+
+type ChildUpdate struct {
+	Password *string
+	Controls *ParentalControls
+}
@@ -0,0 +1,140 @@
+package domain
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	// MaxImportSize caps the size of an uploaded backup in bytes.
+	MaxImportSize = 10 << 20
+	// MaxArchiveImportSize caps the size of an uploaded export archive, they carry attachments.
+	MaxArchiveImportSize = 100 << 20
+	// ImportJobTimeout is how long an import may run, a job whose worker didn't finish it by
+	// then was interrupted.
+	ImportJobTimeout = 10 * time.Minute
+)
+
+var (
+	ErrImportJobNotFound     = NewError(KindNotFound, "import job not found")
+	ErrInvalidImportProvider = NewError(KindValidation, "invalid import provider, expected todoist, trello or archive")
+	ErrInvalidBackup         = NewError(KindValidation, "invalid backup")
+	ErrImportTooLarge        = NewError(KindTooLarge, "backup too large")
+	ErrInvalidTransferSource = NewError(KindValidation, "invalid source, expected the https URL of a todo instance")
+)
+
+// ImportProvider is the todo app a backup was exported from.
+type ImportProvider string
+
+const (
+	ImportTodoist ImportProvider = "todoist"
+	ImportTrello  ImportProvider = "trello"
+	// ImportArchive restores a ZIP export archive of this app, e.g. of another instance.
+	ImportArchive ImportProvider = "archive"
+)
+
+func ParseImportProvider(provider string) (ImportProvider, error) {
+	switch ImportProvider(provider) {
+	case ImportTodoist, ImportTrello, ImportArchive:
+		return ImportProvider(provider), nil
+	default:
+		return "", fmt.Errorf("%q: %w", provider, ErrInvalidImportProvider)
+	}
+}
+
+// MaxSize is the largest backup of the provider that is accepted.
+func (p ImportProvider) MaxSize() int64 {
+	if p == ImportArchive {
+		return MaxArchiveImportSize
+	}
+
+	return MaxImportSize
+}
+
+type ImportStatus string
+
+const (
+	ImportPending   ImportStatus = "pending"
+	ImportRunning   ImportStatus = "running"
+	ImportCompleted ImportStatus = "completed"
+	ImportFailed    ImportStatus = "failed"
+)
+
+// ImportJob imports a backup in the background. A failed job keeps what it created so far.
+type ImportJob struct {
+	ID           uint
+	UUID         string
+	UserID       uint
+	Provider     ImportProvider
+	Status       ImportStatus
+	ListsCreated int
+	TodosCreated int
+	Error        string
+	// SourceURL is the instance a transfer downloads the export archive from, it is empty for
+	// uploaded backups.
+	SourceURL string
+	// BytesReceived is how much of the archive of a transfer was downloaded.
+	BytesReceived int64
+	// ArchiveSHA256 is the digest the archive of a transfer was verified with.
+	ArchiveSHA256 string
+	// StartedAt is the claim of the worker running the job.
+	StartedAt  time.Time
+	FinishedAt time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// AccountTransfer moves an account from another instance of this app. The token is an access
+// token of the account on the source instance, it is dropped once the transfer finished.
+type AccountTransfer struct {
+	SourceURL string
+	Token     string
+}
+
+// Backup is what a provider adapter read from a backup, ready to be created locally.
+type Backup struct {
+	Lists []*BackupList
+	// Unlisted are the todos without a list.
+	Unlisted []*BackupTodo
+}
+
+type BackupList struct {
+	Name string
+	// CompletedRetention is kept by archives, other providers leave it zero.
+	CompletedRetention time.Duration
+	Todos              []*BackupTodo
+}
+
+type BackupTodo struct {
+	Title       string
+	Description string
+	Priority    Priority
+	DueDate     time.Time
+	Estimate    time.Duration
+	Tags        []string
+	Completed   bool
+	// CreatedAt and CompletedAt are kept by archives, the todos of other providers are
+	// created and completed by the import.
+	CreatedAt   time.Time
+	CompletedAt time.Time
+	Attachments []*BackupAttachment
+}
+
+// BackupAttachment is a file attached to a todo of a backup, its content is read on import.
+type BackupAttachment struct {
+	Filename    string
+	ContentType string
+	Size        int64
+	Open        func() (io.ReadCloser, error)
+}
+
+// Todos counts the todos of every list of the backup and those without a list.
+func (b *Backup) Todos() int {
+	count := len(b.Unlisted)
+	for _, list := range b.Lists {
+		count += len(list.Todos)
+	}
+
+	return count
+}
@@ -0,0 +1,102 @@
+package domain
+
+import "time"
+
+const (
+	// DefaultOverdueSpike is how many todos have to become overdue within a day for a nudge.
+	DefaultOverdueSpike = 5
+	// DefaultIdleDays is how many days without a completed todo lead to a nudge.
+	DefaultIdleDays = 7
+	// InsightCheckInterval is how often the insights of a user are checked.
+	InsightCheckInterval = 24 * time.Hour
+	// OverdueSpikeCooldown is how long after an overdue nudge the next one is held back.
+	OverdueSpikeCooldown = 7 * 24 * time.Hour
+	// OverdueBaselineDays is how many days before the last one the usual overdue count is taken
+	// from, a day is only a spike when it is well above the average of these days.
+	OverdueBaselineDays = 7
+	// OverdueSpikeFactor is how many times the daily average a spike has to reach.
+	OverdueSpikeFactor = 2
+)
+
+// NudgeKind is the pattern a productivity nudge is about.
+type NudgeKind string
+
+const (
+	// NudgeOverdueSpike is sent when many more todos became overdue in a day than usual.
+	NudgeOverdueSpike NudgeKind = "overdue_spike"
+	// NudgeIdle is sent when no todo was completed for a while although some are open.
+	NudgeIdle NudgeKind = "idle"
+)
+
+// InsightSettings are the productivity nudges a user opted in to, the thresholds apply when
+// Enabled. Users who never saved settings get DefaultInsightSettings.
+type InsightSettings struct {
+	UserID       uint
+	Enabled      bool
+	OverdueSpike int
+	IdleDays     int
+	// NextCheckAt, OverdueNudgedAt and IdleNudgedAt are kept by the insight worker.
+	NextCheckAt     time.Time
+	OverdueNudgedAt time.Time
+	IdleNudgedAt    time.Time
+}
+
+func DefaultInsightSettings(userID uint) *InsightSettings {
+	return &InsightSettings{
+		UserID:       userID,
+		OverdueSpike: DefaultOverdueSpike,
+		IdleDays:     DefaultIdleDays,
+	}
+}
+
+// IdlePeriod is how long without a completed todo counts as idle.
+func (s *InsightSettings) IdlePeriod() time.Duration {
+	return time.Duration(s.IdleDays) * 24 * time.Hour
+}
+
+type InsightSettingsUpdate struct {
+	Enabled      bool
+	OverdueSpike int
+	IdleDays     int
+}
+
+// ProductivityStats are the counts the nudges of a user are detected from.
+type ProductivityStats struct {
+	// OverdueToday counts the open todos that became overdue during the last day.
+	OverdueToday int
+	// OverdueBaseline counts the todos that became overdue during the OverdueBaselineDays
+	// before, including those completed late.
+	OverdueBaseline int
+	// Completed counts the todos completed during the idle period of the user.
+	Completed int
+	// Open counts the open todos created before the idle period, a user who only just started
+	// isn't idle.
+	Open int
+}
+
+// ProductivityNudge is a gentle heads-up about an unusual pattern.
+type ProductivityNudge struct {
+	Kind NudgeKind
+	// Overdue is set for NudgeOverdueSpike, IdleDays for NudgeIdle.
+	Overdue  int
+	IdleDays int
+}
+
+// Detect returns the nudges the stats call for, nudges sent recently are held back.
+func (s *InsightSettings) Detect(stats *ProductivityStats, now time.Time) []*ProductivityNudge {
+	var nudges []*ProductivityNudge
+
+	average := float64(stats.OverdueBaseline) / OverdueBaselineDays
+	if stats.OverdueToday >= s.OverdueSpike &&
+		float64(stats.OverdueToday) >= OverdueSpikeFactor*average &&
+		now.Sub(s.OverdueNudgedAt) >= OverdueSpikeCooldown {
+		nudges = append(nudges, &ProductivityNudge{Kind: NudgeOverdueSpike, Overdue: stats.OverdueToday})
+	}
+
+	// an idle user is nudged once per idle period, not every day of it
+	if stats.Completed == 0 && stats.Open > 0 && now.Sub(s.IdleNudgedAt) >= s.IdlePeriod() {
+		nudges = append(nudges, &ProductivityNudge{Kind: NudgeIdle, IdleDays: s.IdleDays})
+	}
+
+	return nudges
+}
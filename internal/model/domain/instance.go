@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"hash/fnv"
+	"time"
+)
+
+// Instance is a running API server. Instances register themselves and send heartbeats while
+// they run, the live ones split the background work between them by shard.
+type Instance struct {
+	ID       string
+	Hostname string
+	Version  string
+	// Regions are the data regions the instance serves besides the home region.
+	Regions []string
+	// Shard is the shard the instance works on out of Shards, Shards is 0 until the instance
+	// saw the other live instances.
+	Shard       int
+	Shards      int
+	StartedAt   time.Time
+	HeartbeatAt time.Time
+}
+
+// ShardOf returns the shard out of shards that key belongs to, every key belongs to shard 0
+// when there are no shards.
+func ShardOf(key string, shards int) int {
+	if shards <= 1 {
+		return 0
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(key)) //nolint:errcheck // hash writes never fail
+
+	return int(hash.Sum32() % uint32(shards)) //nolint:gosec // shards is a small positive count
+}
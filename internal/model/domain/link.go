@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// LinkDeadAfter is how many checks of a link have to fail in a row before it is flagged as
+	// dead, so a site that is briefly down isn't reported.
+	LinkDeadAfter = 3
+	// LinkCheckInterval is how often working links are checked again, failing links are
+	// retried after LinkRetryInterval.
+	LinkCheckInterval = 7 * 24 * time.Hour
+	LinkRetryInterval = 24 * time.Hour
+
+	// MaxTodoLinks caps the links checked per todo, the rest of a long description is ignored.
+	MaxTodoLinks  = 20
+	maxLinkLength = 2048
+)
+
+//nolint:gochecknoglobals // compiled once
+var linkPattern = regexp.MustCompile(`https?://[^\s<>"']+`)
+
+// TodoLink is a link found in the description of a todo and the outcome of its last check.
+type TodoLink struct {
+	TodoID uint
+	UserID uint
+	URL    string
+	// StatusCode is the status of the last response, zero when the check got no response.
+	StatusCode int
+	// Failures counts the failed checks since the link last worked.
+	Failures    int
+	CheckedAt   time.Time
+	NextCheckAt time.Time
+	DeadSince   time.Time
+	NotifiedAt  time.Time
+
+	// TodoUUID and TodoTitle are only set on the links returned for checking.
+	TodoUUID  string
+	TodoTitle string
+}
+
+func (l *TodoLink) Dead() bool {
+	return !l.DeadSince.IsZero()
+}
+
+// Record updates the link with the outcome of a check at now and schedules the next one.
+func (l *TodoLink) Record(now time.Time, statusCode int, broken bool) {
+	l.StatusCode = statusCode
+	l.CheckedAt = now
+	if !broken {
+		l.Failures = 0
+		l.DeadSince = time.Time{}
+		l.NotifiedAt = time.Time{}
+		l.NextCheckAt = now.Add(LinkCheckInterval)
+		return
+	}
+
+	l.Failures++
+	if l.Failures >= LinkDeadAfter && !l.Dead() {
+		l.DeadSince = now
+	}
+	l.NextCheckAt = now.Add(LinkRetryInterval)
+}
+
+// LinkSettings are the choices of a user about the link checker.
+type LinkSettings struct {
+	// Check enables checking the links of the todos of the user.
+	Check bool
+	// Notify emails the user when a link is found dead.
+	Notify bool
+}
+
+func DefaultLinkSettings() *LinkSettings {
+	return &LinkSettings{Check: true, Notify: true}
+}
+
+// ExtractLinks returns the distinct http and https links in the text in order of appearance,
+// punctuation right after a link is not part of it.
+func ExtractLinks(text string) []string {
+	links := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, match := range linkPattern.FindAllString(text, -1) {
+		link := strings.TrimRight(match, ".,;:!?)]")
+		if len(link) > maxLinkLength || seen[link] {
+			continue
+		}
+		if parsed, err := url.Parse(link); err != nil || parsed.Hostname() == "" {
+			continue
+		}
+
+		seen[link] = true
+		links = append(links, link)
+		if len(links) == MaxTodoLinks {
+			break
+		}
+	}
+
+	return links
+}
+
+// LinkSettingsUpdate holds the settings to change, nil fields are left untouched.
+type LinkSettingsUpdate struct {
+	Check  *bool
+	Notify *bool
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"time"
+)
+
+var (
+	ErrListNotFound  = NewError(KindNotFound, "list not found")
+	ErrListMergeSelf = NewError(KindValidation, "a list can't be merged into itself")
+)
+
+type List struct {
+	ID     uint
+	UUID   string
+	UserID uint
+	Name   string
+	// CompletedRetention is how long completed todos stay visible before they are archived,
+	// zero keeps them visible.
+	CompletedRetention time.Duration
+	// KeyVersion is the current version of the key of an end-to-end encrypted list, zero for
+	// lists that aren't encrypted.
+	KeyVersion int
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	DeletedAt  time.Time
+}
+
+// Encrypted tells whether the todos of the list are end-to-end encrypted.
+func (l *List) Encrypted() bool {
+	return l.KeyVersion > 0
+}
+
+// ListMerge folds the source list into another list of the same owner.
+type ListMerge struct {
+	SourceUUID string
+	// SkipDuplicates leaves out todos whose title and due date match a todo of the target,
+	// they are deleted together with the source list.
+	SkipDuplicates bool
+	// DryRun only reports what the merge would do.
+	DryRun bool
+}
+
+type ListMergeResult struct {
+	Target *List
+	DryRun bool
+	// Moved are the todos of the source list that are, or would be, moved to the target.
+	Moved      []*Todo
+	Duplicates []*Todo
+}
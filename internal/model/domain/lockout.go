@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"time"
+)
+
+const (
+	// LoginMaxFailures is how many failed logins in a row lock an account.
+	LoginMaxFailures = 5
+	// LoginLockout is how long an account stays locked unless it is unlocked by email.
+	LoginLockout = 30 * time.Minute
+	// UnlockTokenExpiration is how long an emailed unlock link can be used, as long as the lockout.
+	UnlockTokenExpiration = LoginLockout
+	// UnlockTokenPrefix marks emailed unlock tokens.
+	UnlockTokenPrefix = "unl_"
+)
+
+var (
+	ErrAccountLocked      = NewError(KindForbidden, "account is locked after too many failed logins, check your email to unlock it")
+	ErrInvalidUnlockToken = NewError(KindValidation, "invalid or expired unlock token")
+)
@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// LoginRetention is how long logins are kept, a country is new when none of the logins
+	// kept came from it.
+	LoginRetention = 90 * 24 * time.Hour
+	// maxDeviceIDLength caps the device ID an app sends, longer IDs are cut before hashing.
+	maxDeviceIDLength = 255
+	// maxDeviceNameLength caps the user agents used as device names when nothing is recognized.
+	maxDeviceNameLength = 60
+)
+
+var (
+	ErrKnownDeviceNotFound = NewError(KindNotFound, "known device not found")
+)
+
+//nolint:gochecknoglobals // compiled once
+var (
+	// userAgentVersionPattern matches the version numbers of a user agent, e.g. "120.0.6099.71",
+	// so an update of the browser isn't a new device.
+	userAgentVersionPattern = regexp.MustCompile(`[0-9]+([._][0-9]+)*`)
+	countryPattern          = regexp.MustCompile(`^[A-Z]{2}$`)
+)
+
+// LoginClient is what a login request tells about the client. DeviceID is an optional stable ID
+// an app sends with its logins, Country the ISO 3166 code the reverse proxy derived from the IP.
+type LoginClient struct {
+	UserAgent string
+	DeviceID  string
+	Country   string
+}
+
+// Fingerprint identifies the device of the client, by its device ID or otherwise by its user
+// agent without version numbers.
+func (c *LoginClient) Fingerprint() string {
+	source := "ua:" + userAgentVersionPattern.ReplaceAllString(strings.ToLower(c.UserAgent), "")
+	if c.DeviceID != "" {
+		source = "id:" + truncateRunes(c.DeviceID, maxDeviceIDLength)
+	}
+	sum := sha256.Sum256([]byte(source))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// ParseCountry returns the country code of a proxy header, empty for unknown countries like the
+// "XX" and "T1" some proxies send.
+func ParseCountry(value string) string {
+	country := strings.ToUpper(strings.TrimSpace(value))
+	if !countryPattern.MatchString(country) || country == "XX" {
+		return ""
+	}
+
+	return country
+}
+
+// Login is a successful login, NewDevice and NewCountry tell why the user was alerted.
+type Login struct {
+	ID          uint
+	UUID        string
+	UserID      uint
+	IP          string
+	UserAgent   string
+	Country     string
+	Fingerprint string
+	NewDevice   bool
+	NewCountry  bool
+	CreatedAt   time.Time
+}
+
+// Suspicious reports whether the user is alerted of the login.
+func (l *Login) Suspicious() bool {
+	return l.NewDevice || l.NewCountry
+}
+
+// LoginHistory sums up the logins kept of a user.
+type LoginHistory struct {
+	Logins int
+	// FromCountry counts the logins from the country asked about.
+	FromCountry int
+}
+
+// KnownDevice is a device a user logged in from, logins from unknown devices are alerted.
+type KnownDevice struct {
+	ID          uint
+	UUID        string
+	UserID      uint
+	Fingerprint string
+	UserAgent   string
+	LastIP      string
+	LastCountry string
+	FirstSeenAt time.Time
+	LastSeenAt  time.Time
+}
+
+// DeviceName describes the device of a user agent for people, e.g. "Firefox on Windows".
+func DeviceName(userAgent string) string {
+	var browser, system string
+	switch {
+	case strings.Contains(userAgent, "Edg/"):
+		browser = "Edge"
+	case strings.Contains(userAgent, "OPR/"):
+		browser = "Opera"
+	case strings.Contains(userAgent, "Firefox/"):
+		browser = "Firefox"
+	case strings.Contains(userAgent, "Chrome/"):
+		browser = "Chrome"
+	case strings.Contains(userAgent, "Safari/"):
+		browser = "Safari"
+	}
+	switch {
+	case strings.Contains(userAgent, "iPhone"), strings.Contains(userAgent, "iPad"):
+		system = "iOS"
+	case strings.Contains(userAgent, "Android"):
+		system = "Android"
+	case strings.Contains(userAgent, "Windows"):
+		system = "Windows"
+	case strings.Contains(userAgent, "Mac OS X"):
+		system = "macOS"
+	case strings.Contains(userAgent, "Linux"):
+		system = "Linux"
+	}
+
+	switch {
+	case browser != "" && system != "":
+		return browser + " on " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	case userAgent != "":
+		return truncateRunes(userAgent, maxDeviceNameLength)
+	}
+
+	return "Unknown device"
+}
@@ -0,0 +1,101 @@
+package domain
+
+import "time"
+
+const (
+	// EmailMaxAttempts is how often sending an email is tried before it is given up on.
+	EmailMaxAttempts = 6
+	// EmailRetryBase is the delay before the first retry of an email, every retry doubles it.
+	EmailRetryBase = time.Minute
+	// EmailRetryMax caps the delay between two attempts to send an email.
+	EmailRetryMax = 2 * time.Hour
+)
+
+// NotificationKind is what an email is about, it is stored with the email for metrics and
+// support.
+type NotificationKind string
+
+const (
+	NotificationVerification     NotificationKind = "verification"
+	NotificationPasswordReset    NotificationKind = "password_reset"
+	NotificationDueReminder      NotificationKind = "due_reminder"
+	NotificationWeeklyDigest     NotificationKind = "weekly_digest"
+	NotificationNudge            NotificationKind = "productivity_nudge"
+	NotificationTakeout          NotificationKind = "takeout"
+	NotificationEmailChange      NotificationKind = "email_change"
+	NotificationAutomation       NotificationKind = "automation"
+	NotificationLoginAlert       NotificationKind = "login_alert"
+	NotificationEscalation       NotificationKind = "escalation"
+	NotificationRecoveryStarted  NotificationKind = "recovery_started"
+	NotificationRecoveryApproval NotificationKind = "recovery_approval"
+)
+
+type EmailStatus string
+
+const (
+	EmailPending EmailStatus = "pending"
+	EmailSent    EmailStatus = "sent"
+	EmailFailed  EmailStatus = "failed"
+)
+
+// Email is a rendered message waiting in the outbox of the notification worker.
+type Email struct {
+	ID uint
+	// UserID is zero for addresses that don't belong to an account yet.
+	UserID        uint
+	UUID          string
+	Kind          NotificationKind
+	To            []string
+	Subject       string
+	Text          string
+	HTML          string
+	Status        EmailStatus
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+	SentAt        time.Time
+	CreatedAt     time.Time
+}
+
+// EmailBackoff returns how long to wait after the given number of failed attempts, doubling from
+// EmailRetryBase up to EmailRetryMax.
+func EmailBackoff(attempts int) time.Duration {
+	delay := EmailRetryBase
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= EmailRetryMax {
+			return EmailRetryMax
+		}
+	}
+
+	return delay
+}
+
+// WeeklyDigest sums up a week of a user: the todos completed from From until To, the open todos
+// overdue at To and those due within the week after.
+type WeeklyDigest struct {
+	From      time.Time
+	To        time.Time
+	Completed []*Todo
+	Overdue   []*Todo
+	Upcoming  []*Todo
+}
+
+// Empty reports whether there is nothing to sum up, empty digests aren't sent.
+func (d *WeeklyDigest) Empty() bool {
+	return len(d.Completed) == 0 && len(d.Overdue) == 0 && len(d.Upcoming) == 0
+}
+
+// In converts the times of the digest to location, the dates in the email are read in the
+// timezone of the user.
+func (d *WeeklyDigest) In(location *time.Location) {
+	d.From = d.From.In(location)
+	d.To = d.To.In(location)
+	for _, todos := range [][]*Todo{d.Completed, d.Overdue, d.Upcoming} {
+		for _, todo := range todos {
+			if !todo.DueDate.IsZero() {
+				todo.DueDate = todo.DueDate.In(location)
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"time"
+)
+
+const (
+	OAuthCodePrefix         = "oac_"
+	OAuthAccessTokenPrefix  = "oat_"
+	OAuthRefreshTokenPrefix = "ort_"
+
+	OAuthCodeExpiration        = 5 * time.Minute
+	OAuthAccessTokenExpiration = time.Hour
+
+	OAuthGrantTypeAuthorizationCode = "authorization_code"
+	OAuthGrantTypeRefreshToken      = "refresh_token"
+)
+
+// The OAuth errors follow the error codes of RFC 6749, so they can be returned to clients as is.
+var (
+	ErrInvalidClient        = NewError(KindValidation, "invalid_client")
+	ErrInvalidGrant         = NewError(KindValidation, "invalid_grant")
+	ErrUnsupportedGrantType = NewError(KindValidation, "unsupported_grant_type")
+	ErrInvalidRedirectURI   = NewError(KindValidation, "invalid redirect uri")
+	ErrInvalidOAuthToken    = NewError(KindUnauthorized, "invalid oauth token")
+	ErrOAuthGrantNotFound   = NewError(KindNotFound, "oauth grant not found")
+)
+
+// OAuthClient is a third party allowed to link user accounts, e.g. a smart speaker skill.
+type OAuthClient struct {
+	ID           string
+	Secret       string
+	RedirectURIs []string
+}
+
+func (c *OAuthClient) AllowsRedirect(uri string) bool {
+	for _, allowed := range c.RedirectURIs {
+		if allowed == uri {
+			return true
+		}
+	}
+
+	return false
+}
+
+type OAuthAuthorizationCode struct {
+	ID          uint
+	UserID      uint
+	ClientID    string
+	RedirectURI string
+	ExpiresAt   time.Time
+	UsedAt      time.Time
+}
+
+// OAuthGrant is an account linked to a client. Its tokens act on behalf of the user.
+type OAuthGrant struct {
+	ID              uint
+	UUID            string
+	UserID          uint
+	ClientID        string
+	AccessExpiresAt time.Time
+	LastUsedAt      time.Time
+	CreatedAt       time.Time
+
+	// AccessToken and RefreshToken are only set right after they are issued.
+	AccessToken  string
+	RefreshToken string
+
+	// Region is the data region the grant was issued in, set by Authenticate.
+	Region string
+}
+
+type OAuthTokenRequest struct {
+	GrantType    string
+	Code         string
+	RedirectURI  string
+	RefreshToken string
+	ClientID     string
+	ClientSecret string
+}
@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"regexp"
+	"slices"
+	"time"
+)
+
+// Onboarding steps, in the order clients walk new users through them.
+const (
+	OnboardingStepProfile   = "profile"
+	OnboardingStepList      = "create_list"
+	OnboardingStepTodo      = "create_todo"
+	OnboardingStepComplete  = "complete_todo"
+	OnboardingStepReminders = "reminders"
+	OnboardingStepInvite    = "invite"
+)
+
+// MaxDismissedTips caps the dismissed tips of a user.
+const MaxDismissedTips = 100
+
+var (
+	ErrUnknownOnboardingStep = NewError(KindValidation, "unknown onboarding step")
+	ErrInvalidOnboardingTip  = NewError(KindValidation, "tip keys are 1 to 64 lowercase letters, digits, dots, dashes and underscores")
+	ErrTooManyDismissedTips  = NewError(KindValidation, "too many dismissed tips")
+)
+
+//nolint:gochecknoglobals // constant list
+var onboardingSteps = []string{
+	OnboardingStepProfile,
+	OnboardingStepList,
+	OnboardingStepTodo,
+	OnboardingStepComplete,
+	OnboardingStepReminders,
+	OnboardingStepInvite,
+}
+
+//nolint:gochecknoglobals // compiled once
+var onboardingTipKey = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,63}$`)
+
+// ValidOnboardingStep reports whether key is one of the onboarding steps.
+func ValidOnboardingStep(key string) bool {
+	return slices.Contains(onboardingSteps, key)
+}
+
+// ValidOnboardingTip reports whether key can name a tip. Tips are up to the clients, the server
+// only remembers which ones were dismissed.
+func ValidOnboardingTip(key string) bool {
+	return onboardingTipKey.MatchString(key)
+}
+
+// OnboardingState is how far a user is through onboarding, it is the same on every device.
+type OnboardingState struct {
+	UserID uint
+	// Steps are all onboarding steps in order, those not completed yet have no CompletedAt.
+	Steps         []*OnboardingStep
+	DismissedTips []string
+	// DismissedAt is when the user skipped the rest of the onboarding.
+	DismissedAt time.Time
+	UpdatedAt   time.Time
+}
+
+type OnboardingStep struct {
+	Key         string
+	CompletedAt time.Time
+}
+
+// NewOnboardingState lists the steps of a user with when they were completed.
+func NewOnboardingState(userID uint, completed map[string]time.Time, dismissedTips []string) *OnboardingState {
+	state := &OnboardingState{
+		UserID:        userID,
+		Steps:         make([]*OnboardingStep, 0, len(onboardingSteps)),
+		DismissedTips: dismissedTips,
+	}
+	for _, key := range onboardingSteps {
+		state.Steps = append(state.Steps, &OnboardingStep{Key: key, CompletedAt: completed[key]})
+	}
+
+	return state
+}
+
+// Next returns the first step that isn't completed yet, empty once the user completed or
+// dismissed the onboarding.
+func (s *OnboardingState) Next() string {
+	if !s.DismissedAt.IsZero() {
+		return ""
+	}
+	for _, step := range s.Steps {
+		if step.CompletedAt.IsZero() {
+			return step.Key
+		}
+	}
+
+	return ""
+}
+
+// Done reports whether the user completed every step or dismissed the onboarding.
+func (s *OnboardingState) Done() bool {
+	return s.Next() == ""
+}
+
+// OnboardingUpdate completes steps and dismisses tips, both are kept when already done.
+// Dismissed skips or resumes the rest of the onboarding, nil leaves it as it is.
+type OnboardingUpdate struct {
+	CompleteSteps []string
+	DismissTips   []string
+	Dismissed     *bool
+}
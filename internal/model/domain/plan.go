@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"time"
+)
+
+const (
+	// DefaultDailyCapacity is the time a user can spend on todos in a day until they plan
+	// with a different capacity, later plans reuse the last capacity.
+	DefaultDailyCapacity = 6 * time.Hour
+	// DefaultTodoEstimate is assumed for todos without an estimate.
+	DefaultTodoEstimate = 30 * time.Minute
+)
+
+var (
+	ErrPlanNotFound = NewError(KindNotFound, "plan not found")
+)
+
+// Plan is the set of todos a user picked, or had picked for them, to do on a day.
+type Plan struct {
+	ID       uint
+	UserID   uint
+	Date     time.Time
+	Capacity time.Duration
+	// Items are ordered by rank, best first.
+	Items     []*PlanItem
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Planned returns the estimated time of every planned todo.
+func (p *Plan) Planned() time.Duration {
+	var planned time.Duration
+	for _, item := range p.Items {
+		planned += item.Estimate
+	}
+
+	return planned
+}
+
+// PlanItem is a planned todo, Reasons explain the score in plain words so users can tell why
+// the todo made it into the plan.
+type PlanItem struct {
+	Todo     *Todo
+	Position int
+	Score    float64
+	// Estimate is the todo estimate at planning time, or DefaultTodoEstimate.
+	Estimate time.Duration
+	Reasons  []string
+}
+
+type PlanRequest struct {
+	// Date defaults to today in UTC.
+	Date time.Time
+	// Capacity defaults to the capacity of the last plan, or DefaultDailyCapacity.
+	Capacity time.Duration
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PlannerFormat is a printable format of the weekly planner.
+type PlannerFormat string
+
+const (
+	PlannerFormatHTML PlannerFormat = "html"
+	PlannerFormatPDF  PlannerFormat = "pdf"
+)
+
+var (
+	ErrInvalidPlannerFormat = NewError(KindValidation, "invalid format, expected html or pdf")
+	ErrInvalidPlannerWeek   = NewError(KindValidation, "invalid week, expected a date like 2006-01-02 and a known timezone")
+)
+
+// ParsePlannerFormat parses a format name, empty is PlannerFormatHTML.
+func ParsePlannerFormat(format string) (PlannerFormat, error) {
+	switch PlannerFormat(strings.ToLower(format)) {
+	case "", PlannerFormatHTML:
+		return PlannerFormatHTML, nil
+	case PlannerFormatPDF:
+		return PlannerFormatPDF, nil
+	default:
+		return "", fmt.Errorf("%q: %w", format, ErrInvalidPlannerFormat)
+	}
+}
+
+// PlannerWeek is the week a planner covers, Monday to Sunday in the timezone of Start.
+type PlannerWeek struct {
+	// Start is midnight of the Monday.
+	Start time.Time
+}
+
+// ParsePlannerWeek returns the week of the date in the timezone, an empty date is the week of
+// now and an empty timezone is UTC.
+func ParsePlannerWeek(date string, timezone string, now time.Time) (*PlannerWeek, error) {
+	location := time.UTC
+	if timezone != "" {
+		var err error
+		if location, err = time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("unknown timezone %q: %w", timezone, ErrInvalidPlannerWeek)
+		}
+	}
+
+	day := now.In(location)
+	if date != "" {
+		var err error
+		if day, err = time.ParseInLocation(time.DateOnly, date, location); err != nil {
+			return nil, fmt.Errorf("%q: %w", date, ErrInvalidPlannerWeek)
+		}
+	}
+
+	// weeks start on Monday, Sunday is the seventh day
+	offset := (int(day.Weekday()) + 6) % 7
+
+	return &PlannerWeek{
+		Start: time.Date(day.Year(), day.Month(), day.Day()-offset, 0, 0, 0, 0, location),
+	}, nil
+}
+
+// End is midnight after the Sunday of the week.
+func (w *PlannerWeek) End() time.Time {
+	return w.Start.AddDate(0, 0, 7)
+}
+
+// Days returns midnight of every day of the week, days are added by date so a daylight saving
+// change doesn't shift them.
+func (w *PlannerWeek) Days() []time.Time {
+	days := make([]time.Time, 0, 7)
+	for i := range 7 {
+		days = append(days, w.Start.AddDate(0, 0, i))
+	}
+
+	return days
+}
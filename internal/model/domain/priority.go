@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"fmt"
+)
+
+var (
+	ErrInvalidPriority = NewError(KindValidation, "invalid priority")
+)
+
+// Priority is ordered from least to most important so it can be sorted on.
+type Priority int
+
+const (
+	PriorityLow Priority = iota
+	PriorityMedium
+	PriorityHigh
+	PriorityUrgent
+)
+
+//nolint:gochecknoglobals // lookup table
+var priorityNames = map[Priority]string{
+	PriorityLow:    "low",
+	PriorityMedium: "medium",
+	PriorityHigh:   "high",
+	PriorityUrgent: "urgent",
+}
+
+func (p Priority) String() string {
+	return priorityNames[p]
+}
+
+func ParsePriority(name string) (Priority, error) {
+	for priority, priorityName := range priorityNames {
+		if priorityName == name {
+			return priority, nil
+		}
+	}
+
+	return PriorityLow, fmt.Errorf("%q: %w", name, ErrInvalidPriority)
+}
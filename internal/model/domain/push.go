@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"time"
+)
+
+var (
+	ErrPushSubscriptionNotFound = NewError(KindNotFound, "push subscription not found")
+	ErrPushDisabled             = NewError(KindNotFound, "push notifications are not enabled on this server")
+	ErrInvalidPushSubscription  = NewError(KindValidation, "push subscription needs an https endpoint and the p256dh and auth keys")
+)
+
+// PushSubscription is a browser registered for the Web Push reminders of a user, the keys
+// encrypt the messages so only that browser can read them.
+type PushSubscription struct {
+	ID       uint
+	UUID     string
+	UserID   uint
+	Endpoint string
+	P256dh   string
+	Auth     string
+	// UserAgent helps users tell their browsers apart.
+	UserAgent string
+	CreatedAt time.Time
+}
@@ -0,0 +1,394 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var ErrQuickAddNoTitle = NewError(KindValidation, "quick add needs a title besides the due date, tags and priority")
+
+// QuickAddField is the field of a todo a part of a quick add was read as.
+type QuickAddField string
+
+const (
+	QuickAddFieldDueDate  QuickAddField = "due_date"
+	QuickAddFieldPriority QuickAddField = "priority"
+	QuickAddFieldTag      QuickAddField = "tag"
+)
+
+// QuickAdd is a todo parsed from a single line like "Pay rent tomorrow 5pm #finance !high".
+type QuickAdd struct {
+	Title string
+	// DueDate is zero without a date or time. A date without a time is due at the start of the
+	// day like the all-day due dates of the clients, a time without a date the next time it is
+	// that time of day.
+	DueDate  time.Time
+	Priority *Priority
+	Tags     []string
+	// Matches are the parts of the text that were read as fields, so clients can show how the
+	// text was understood. The date and time of the due date make a single match.
+	Matches []QuickAddMatch
+}
+
+type QuickAddMatch struct {
+	Field QuickAddField
+	Text  string
+}
+
+// QuickAddCreate creates a todo from a single line of text, in a list when ListUUID is set.
+type QuickAddCreate struct {
+	Text     string
+	ListUUID string
+	// DryRun only parses the text, e.g. to preview it while the user types.
+	DryRun bool
+}
+
+type QuickAddResult struct {
+	QuickAdd *QuickAdd
+	// Todo is the todo created, nil for a dry run.
+	Todo   *Todo
+	DryRun bool
+}
+
+// TodoCreate returns the todo to create from the quick add, medium priority unless it has one.
+func (q *QuickAdd) TodoCreate() *TodoCreate {
+	todoCreate := &TodoCreate{
+		Title:    q.Title,
+		Priority: PriorityMedium,
+		DueDate:  q.DueDate,
+		Tags:     append([]string{}, q.Tags...),
+	}
+	if q.Priority != nil {
+		todoCreate.Priority = *q.Priority
+	}
+
+	return todoCreate
+}
+
+// quickAddTagSymbols are the symbols tags of a quick add may contain besides letters and numbers,
+// those of tag names without the ones that end a sentence.
+const quickAddTagSymbols = "-_/&+'"
+
+//nolint:gochecknoglobals // lookup tables
+var (
+	quickAddWeekdays = map[string]time.Weekday{
+		// "sat" and "sun" are left out, they are more likely words of the title
+		"sunday": time.Sunday,
+		"monday": time.Monday, "mon": time.Monday,
+		"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+		"wednesday": time.Wednesday, "wed": time.Wednesday,
+		"thursday": time.Thursday, "thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday,
+		"friday": time.Friday, "fri": time.Friday,
+		"saturday": time.Saturday,
+	}
+	quickAddMonths = map[string]time.Month{
+		"january": time.January, "jan": time.January,
+		"february": time.February, "feb": time.February,
+		"march": time.March, "mar": time.March,
+		"april": time.April, "apr": time.April,
+		"may":  time.May,
+		"june": time.June, "jun": time.June,
+		"july": time.July, "jul": time.July,
+		"august": time.August, "aug": time.August,
+		"september": time.September, "sep": time.September, "sept": time.September,
+		"october": time.October, "oct": time.October,
+		"november": time.November, "nov": time.November,
+		"december": time.December, "dec": time.December,
+	}
+	// quickAddConnectives are left out along with a date or time they precede, e.g. "due friday".
+	quickAddConnectives = map[string]bool{"on": true, "by": true, "due": true, "at": true}
+)
+
+// ParseQuickAdd reads the due date, tags and priority out of a quick add at now in location, the
+// rest of the words make the title. Dates are "today", "tonight", "tomorrow", weekdays, which are
+// the next such day after today, "next week", "next month", "in 3 days", "oct 20" and 2026-10-20.
+// Times are "5pm", "5:30 pm", "17:00" and "noon". Tags are "#finance", priorities "!high". Only the
+// first date and time count, later ones stay in the title.
+func ParseQuickAdd(text string, now time.Time, location *time.Location) (*QuickAdd, error) {
+	now = now.In(location)
+	words := strings.Fields(text)
+
+	quickAdd := &QuickAdd{}
+	var title []string
+	var date, clock *quickAddMatch
+	for i := 0; i < len(words); {
+		word := words[i]
+
+		if tag, ok := quickAddTag(word); ok {
+			quickAdd.Tags = append(quickAdd.Tags, tag)
+			quickAdd.Matches = append(quickAdd.Matches, QuickAddMatch{Field: QuickAddFieldTag, Text: word})
+			i++
+			continue
+		}
+		if priority, ok := quickAddPriority(word); ok && quickAdd.Priority == nil {
+			quickAdd.Priority = &priority
+			quickAdd.Matches = append(quickAdd.Matches, QuickAddMatch{Field: QuickAddFieldPriority, Text: word})
+			i++
+			continue
+		}
+
+		// a connective only goes along with the date or time right after it
+		start := i
+		if quickAddConnectives[quickAddWord(word)] && i+1 < len(words) {
+			i++
+		}
+		if date == nil {
+			if match := matchQuickAddDate(words[i:], now); match != nil {
+				match.text = strings.Join(words[start:i+match.words], " ")
+				date = match
+				i += match.words
+				continue
+			}
+		}
+		if clock == nil {
+			if match := matchQuickAddTime(words[i:]); match != nil {
+				match.text = strings.Join(words[start:i+match.words], " ")
+				clock = match
+				i += match.words
+				continue
+			}
+		}
+
+		title = append(title, words[start])
+		i = start + 1
+	}
+
+	quickAdd.Title = strings.Join(title, " ")
+	if quickAdd.Title == "" {
+		return nil, ErrQuickAddNoTitle
+	}
+	quickAdd.Tags = NormalizeTagNames(quickAdd.Tags)
+
+	if date != nil || clock != nil {
+		quickAdd.DueDate = quickAddDueDate(date, clock, now)
+		var parts []string
+		for _, match := range []*quickAddMatch{date, clock} {
+			if match != nil {
+				parts = append(parts, match.text)
+			}
+		}
+		quickAdd.Matches = append(quickAdd.Matches, QuickAddMatch{Field: QuickAddFieldDueDate, Text: strings.Join(parts, " ")})
+	}
+
+	return quickAdd, nil
+}
+
+// quickAddMatch is a date or time read from the words of a quick add.
+type quickAddMatch struct {
+	words int
+	text  string
+	// day is the date of a date match, at midnight of now.
+	day time.Time
+	// hour and minute are the time of a time match, or the default time of a date like "tonight".
+	hour, minute int
+	hasTime      bool
+}
+
+// quickAddDueDate combines a date and a time, either may be nil.
+func quickAddDueDate(date *quickAddMatch, clock *quickAddMatch, now time.Time) time.Time {
+	hour, minute := 0, 0
+	switch {
+	case clock != nil:
+		hour, minute = clock.hour, clock.minute
+	case date.hasTime:
+		hour, minute = date.hour, date.minute
+	}
+
+	if date == nil {
+		// a time alone is the next time it is that time of day
+		due := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+		if !due.After(now) {
+			due = time.Date(now.Year(), now.Month(), now.Day()+1, hour, minute, 0, 0, now.Location())
+		}
+		return due
+	}
+
+	return time.Date(date.day.Year(), date.day.Month(), date.day.Day(), hour, minute, 0, 0, now.Location())
+}
+
+func matchQuickAddDate(words []string, now time.Time) *quickAddMatch {
+	if len(words) == 0 {
+		return nil
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	word := quickAddWord(words[0])
+
+	switch word {
+	case "today":
+		return &quickAddMatch{words: 1, day: today}
+	case "tonight":
+		return &quickAddMatch{words: 1, day: today, hour: 20, hasTime: true}
+	case "tomorrow", "tmrw", "tmr":
+		return &quickAddMatch{words: 1, day: today.AddDate(0, 0, 1)}
+	}
+
+	if weekday, ok := quickAddWeekdays[word]; ok {
+		return &quickAddMatch{words: 1, day: nextWeekday(today, weekday)}
+	}
+
+	if day, err := time.ParseInLocation(DateLayout, word, now.Location()); err == nil {
+		return &quickAddMatch{words: 1, day: day}
+	}
+
+	if len(words) < 2 {
+		return nil
+	}
+	next := quickAddWord(words[1])
+
+	if word == "next" {
+		switch next {
+		case "week":
+			return &quickAddMatch{words: 2, day: nextWeekday(today, time.Monday)}
+		case "month":
+			return &quickAddMatch{words: 2, day: time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, now.Location())}
+		}
+		if weekday, ok := quickAddWeekdays[next]; ok {
+			return &quickAddMatch{words: 2, day: nextWeekday(today, weekday)}
+		}
+	}
+
+	if month, ok := quickAddMonths[word]; ok {
+		if day, ok := quickAddDayOfMonth(next); ok {
+			return &quickAddMatch{words: 2, day: nextDate(today, month, day)}
+		}
+	}
+
+	if word == "in" && len(words) >= 3 {
+		count, err := strconv.Atoi(next)
+		if next == "a" || next == "an" {
+			count, err = 1, nil
+		}
+		if err != nil || count < 1 || count > 365 {
+			return nil
+		}
+		switch strings.TrimSuffix(quickAddWord(words[2]), "s") {
+		case "day":
+			return &quickAddMatch{words: 3, day: today.AddDate(0, 0, count)}
+		case "week":
+			return &quickAddMatch{words: 3, day: today.AddDate(0, 0, 7*count)}
+		case "month":
+			return &quickAddMatch{words: 3, day: today.AddDate(0, count, 0)}
+		}
+	}
+
+	return nil
+}
+
+func matchQuickAddTime(words []string) *quickAddMatch {
+	if len(words) == 0 {
+		return nil
+	}
+	word := quickAddWord(words[0])
+
+	switch word {
+	case "noon":
+		return &quickAddMatch{words: 1, hour: 12, hasTime: true}
+	case "midnight":
+		return &quickAddMatch{words: 1, hour: 0, hasTime: true}
+	}
+
+	// "5pm" and "5:30pm", or "5 pm" spread over two words
+	consumed := 1
+	suffix := ""
+	switch {
+	case strings.HasSuffix(word, "am"), strings.HasSuffix(word, "pm"):
+		word, suffix = word[:len(word)-2], word[len(word)-2:]
+	case len(words) > 1 && (quickAddWord(words[1]) == "am" || quickAddWord(words[1]) == "pm"):
+		suffix = quickAddWord(words[1])
+		consumed = 2
+	}
+
+	hourText, minuteText, hasMinute := strings.Cut(word, ":")
+	hour, err := strconv.Atoi(hourText)
+	if err != nil || len(hourText) > 2 {
+		return nil
+	}
+	minute := 0
+	if hasMinute {
+		if len(minuteText) != 2 {
+			return nil
+		}
+		if minute, err = strconv.Atoi(minuteText); err != nil || minute > 59 {
+			return nil
+		}
+	}
+
+	switch suffix {
+	case "":
+		// a bare number is more likely part of the title, "17:00" isn't
+		if !hasMinute || hour > 23 {
+			return nil
+		}
+	default:
+		if hour < 1 || hour > 12 {
+			return nil
+		}
+		hour %= 12
+		if suffix == "pm" {
+			hour += 12
+		}
+	}
+
+	return &quickAddMatch{words: consumed, hour: hour, minute: minute, hasTime: true}
+}
+
+// quickAddTag returns the tag of a word like "#finance", trailing punctuation isn't part of it.
+func quickAddTag(word string) (string, bool) {
+	tag, ok := strings.CutPrefix(word, "#")
+	tag = strings.TrimRight(tag, ".,;:!?")
+	if !ok || tag == "" {
+		return "", false
+	}
+	for _, char := range tag {
+		if !unicode.IsLetter(char) && !unicode.IsNumber(char) && !unicode.IsMark(char) &&
+			!strings.ContainsRune(quickAddTagSymbols, char) {
+			return "", false
+		}
+	}
+
+	return tag, true
+}
+
+// quickAddPriority returns the priority of a word like "!high".
+func quickAddPriority(word string) (Priority, bool) {
+	name, ok := strings.CutPrefix(word, "!")
+	if !ok {
+		return PriorityLow, false
+	}
+	priority, err := ParsePriority(strings.ToLower(strings.TrimRight(name, ".,;:")))
+
+	return priority, err == nil
+}
+
+// quickAddDayOfMonth reads a day like "20" or "20th".
+func quickAddDayOfMonth(word string) (int, bool) {
+	for _, suffix := range []string{"st", "nd", "rd", "th"} {
+		word = strings.TrimSuffix(word, suffix)
+	}
+	day, err := strconv.Atoi(word)
+
+	return day, err == nil && day >= 1 && day <= 31
+}
+
+// quickAddWord lowercases a word and drops the punctuation that may follow it in a sentence.
+func quickAddWord(word string) string {
+	return strings.ToLower(strings.TrimRight(word, ".,;!?"))
+}
+
+// nextWeekday returns the next weekday after day.
+func nextWeekday(day time.Time, weekday time.Weekday) time.Time {
+	days := (int(weekday)-int(day.Weekday())+6)%7 + 1
+	return day.AddDate(0, 0, days)
+}
+
+// nextDate returns the next date with the month and day from today on, next year once it passed.
+func nextDate(today time.Time, month time.Month, day int) time.Time {
+	date := time.Date(today.Year(), month, day, 0, 0, 0, 0, today.Location())
+	if date.Before(today) {
+		date = time.Date(today.Year()+1, month, day, 0, 0, 0, 0, today.Location())
+	}
+
+	return date
+}
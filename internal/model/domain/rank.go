@@ -0,0 +1,95 @@
+package domain
+
+import "strings"
+
+// MaxRankLength is the length past which the ranks of a user are spread out again with
+// SpreadRanks, ranks grow by a digit every few moves into the same gap.
+const MaxRankLength = 32
+
+// rankDigits are the digits of ranks in ascending byte order. Ranks never end in the lowest
+// digit, so there is always room for a rank below any other.
+const rankDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+var ErrReorderOtherOwner = NewError(KindValidation, "todos can only be ordered among the todos of the same owner")
+
+// TodoReorder moves a todo right after AfterUUID in the order of its owner, an empty AfterUUID
+// moves it to the top.
+type TodoReorder struct {
+	AfterUUID string
+}
+
+// RankBetween returns a rank that sorts after lower and before upper. An empty lower is below
+// every rank and an empty upper above every rank, lower must sort before upper otherwise. The
+// rank grows by a digit once the two are adjacent, so it stays short for the usual drags.
+func RankBetween(lower string, upper string) string {
+	if upper != "" {
+		// keep the common prefix, the ranks only differ after it
+		n := 0
+		for n < len(upper) && rankDigitAt(lower, n) == upper[n] {
+			n++
+		}
+		if n > 0 {
+			return upper[:n] + RankBetween(rankSuffix(lower, n), upper[n:])
+		}
+	}
+
+	low := 0
+	if lower != "" {
+		low = strings.IndexByte(rankDigits, lower[0])
+	}
+	high := len(rankDigits)
+	if upper != "" {
+		high = strings.IndexByte(rankDigits, upper[0])
+	}
+	if high-low > 1 {
+		return string(rankDigits[(low+high)/2])
+	}
+
+	// the first digits are adjacent, the first digit of upper alone already sorts in between
+	if len(upper) > 1 {
+		return upper[:1]
+	}
+
+	return string(rankDigits[low]) + RankBetween(rankSuffix(lower, 1), "")
+}
+
+// rankDigitAt returns the digit of rank at i, ranks are padded with the lowest digit.
+func rankDigitAt(rank string, i int) byte {
+	if i < len(rank) {
+		return rank[i]
+	}
+
+	return rankDigits[0]
+}
+
+func rankSuffix(rank string, i int) string {
+	if i < len(rank) {
+		return rank[i:]
+	}
+
+	return ""
+}
+
+// SpreadRanks returns n ascending ranks of equal length spaced evenly apart, to rank todos again
+// once their ranks grew too long.
+func SpreadRanks(n int) []string {
+	// leave a gap of at least a full digit between neighbours
+	length, space := 1, len(rankDigits)
+	for space < (n+1)*len(rankDigits) {
+		length++
+		space *= len(rankDigits)
+	}
+
+	ranks := make([]string, 0, n)
+	digits := make([]byte, length)
+	for i := 1; i <= n; i++ {
+		value := i * (space / (n + 1))
+		for d := length - 1; d >= 0; d-- {
+			digits[d] = rankDigits[value%len(rankDigits)]
+			value /= len(rankDigits)
+		}
+		ranks = append(ranks, strings.TrimRight(string(digits), rankDigits[:1]))
+	}
+
+	return ranks
+}
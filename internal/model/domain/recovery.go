@@ -0,0 +1,109 @@
+package domain
+
+import "time"
+
+const (
+	// RecoveryDelay is how long a recovery request waits before it can be completed, even once
+	// enough contacts approved it, so the owner has time to notice and cancel it.
+	RecoveryDelay = 72 * time.Hour
+	// RecoveryExpiration is how long a recovery request can be approved and completed.
+	RecoveryExpiration = 7 * 24 * time.Hour
+	// RecoveryTokenPrefix marks the tokens of recovery requests.
+	RecoveryTokenPrefix = "rcv_"
+	// MaxTrustedContacts caps the trusted contacts of an account.
+	MaxTrustedContacts = 5
+)
+
+var (
+	ErrChildAccountRecovery     = NewError(KindForbidden, "child accounts are recovered by their parent")
+	ErrTrustedContactNotFound   = NewError(KindNotFound, "trusted contact not found")
+	ErrTrustedContactSelf       = NewError(KindValidation, "users can't be their own trusted contact")
+	ErrTrustedContactChild      = NewError(KindValidation, "child accounts can't be trusted contacts")
+	ErrTooManyTrustedContacts   = NewError(KindValidation, "account has too many trusted contacts")
+	ErrInvalidRecoveryThreshold = NewError(KindValidation, "recovery threshold must be between 1 and the number of trusted contacts")
+	ErrRecoveryNotFound         = NewError(KindNotFound, "recovery request not found")
+	ErrInvalidRecoveryToken     = NewError(KindValidation, "invalid or expired recovery token")
+	ErrRecoveryNotApproved      = NewError(KindConflict, "recovery request isn't approved by enough trusted contacts yet")
+	ErrRecoveryNotReady         = NewError(KindConflict, "recovery request can't be completed before its delay passed")
+)
+
+// TrustedContact is a user that can approve the recovery of an account.
+type TrustedContact struct {
+	UserID    uint
+	UUID      string
+	Email     string
+	FirstName string
+	LastName  string
+	CreatedAt time.Time
+}
+
+// RecoverySettings are the trusted contacts of an account, Threshold of them have to approve a
+// recovery request. Requests are the pending recovery requests of the account.
+type RecoverySettings struct {
+	Threshold int
+	Contacts  []*TrustedContact
+	Requests  []*RecoveryRequest
+}
+
+// RecoverySetup replaces the trusted contacts of an account, the password is entered again.
+type RecoverySetup struct {
+	ContactEmails []string
+	Threshold     int
+	Password      string
+}
+
+// RecoveryStart starts the recovery of the account of Email.
+type RecoveryStart struct {
+	Email string
+}
+
+// RecoveryTicket is what the one recovering an account keeps to complete the recovery, it is
+// the only way to it since the account may have no email to send a link to.
+type RecoveryTicket struct {
+	Token     string
+	Region    string
+	ReadyAt   time.Time
+	ExpiresAt time.Time
+}
+
+// RecoveryCompletion sets a new password with the token of an approved recovery request.
+type RecoveryCompletion struct {
+	Token       string
+	Region      string
+	NewPassword string
+}
+
+// RecoveryRequest is a request to recover an account. Anyone can start one, the trusted
+// contacts check with the owner before they approve it and the owner is told about it.
+type RecoveryRequest struct {
+	ID     uint
+	UUID   string
+	UserID uint
+	// UserUUID and UserName name the account to the contacts.
+	UserUUID string
+	UserName string
+	// Approvals counts the approvals of current contacts, Threshold is what the account needs.
+	Approvals int
+	Threshold int
+	// Approved tells a contact whether they approved the request already.
+	Approved    bool
+	ReadyAt     time.Time
+	ExpiresAt   time.Time
+	CompletedAt time.Time
+	CancelledAt time.Time
+	CreatedAt   time.Time
+}
+
+// Completable reports why the request can't be completed at now, nil once it can.
+func (r *RecoveryRequest) Completable(now time.Time) error {
+	switch {
+	case !r.CompletedAt.IsZero() || !r.CancelledAt.IsZero() || !now.Before(r.ExpiresAt):
+		return ErrInvalidRecoveryToken
+	case r.Threshold == 0 || r.Approvals < r.Threshold:
+		return ErrRecoveryNotApproved
+	case now.Before(r.ReadyAt):
+		return ErrRecoveryNotReady
+	}
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+const (
+	// ReminderLead is how long before their due date open todos are reminded of.
+	ReminderLead = time.Hour
+	// OverdueWindow is how long after their due date todos are still reported overdue, older
+	// todos were overdue before anyone could be told and are left alone.
+	OverdueWindow = 24 * time.Hour
+)
+
+// ReminderKind tells whether the todos of a reminder are due soon or overdue.
+type ReminderKind string
+
+const (
+	ReminderDueSoon ReminderKind = "due_soon"
+	ReminderOverdue ReminderKind = "overdue"
+)
+
+// Reminder holds the todos of a user that are due soon or just became overdue.
+type Reminder struct {
+	Kind   ReminderKind
+	UserID uint
+	Todos  []*Todo
+	// Location is the timezone of the user, due dates are shown in it.
+	Location *time.Location
+}
@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+const (
+	// RoomTicketPrefix makes room tickets recognizable, e.g. in leaked credential scans.
+	RoomTicketPrefix = "rtk_"
+	// RoomTicketExpiration is how long a room ticket can be used, clients fetch it right before
+	// opening the WebSocket.
+	RoomTicketExpiration = 30 * time.Second
+)
+
+var ErrInvalidRoomTicket = NewError(KindUnauthorized, "invalid room ticket")
+
+// RoomTicket opens the WebSocket of a list room once. Browsers can't set headers on the handshake,
+// so the ticket is passed in the URL instead of the access token, which would end up in logs and
+// browser histories.
+type RoomTicket struct {
+	Ticket   string
+	UserID   uint
+	UserUUID string
+	Email    string
+	ListUUID string
+	// SessionExpiresAt is when the access token the ticket was issued with expires, the room is
+	// closed then.
+	SessionExpiresAt time.Time
+	ExpiresAt        time.Time
+	CreatedAt        time.Time
+}
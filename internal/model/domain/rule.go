@@ -0,0 +1,458 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/meowmix1337/the_recipe_book/internal/expr"
+)
+
+var (
+	ErrRuleNotFound   = NewError(KindNotFound, "rule not found")
+	ErrRuleNoActions  = NewError(KindValidation, "rule must add a tag, set a priority, move to a list or compute a field")
+	ErrRuleNoMatch    = NewError(KindValidation, "rule must have a condition or a field and operator to match")
+	ErrInvalidRuleSet = NewError(KindValidation, "rules must be ordered by listing every rule once")
+
+	ErrInvalidCaptureSource = NewError(KindValidation, "capture source must be app, slack, voice, email, share or agent")
+
+	ErrInvalidRuleExpression = NewError(KindValidation, "rule expression is invalid")
+	ErrInvalidRuleTarget     = NewError(KindValidation, "rule expression target must be condition, title, description, priority or tags")
+)
+
+// CaptureSource is where a todo was captured, rules can be limited to todos from one source.
+type CaptureSource string
+
+const (
+	// CaptureSourceApp are todos created through the API by the apps.
+	CaptureSourceApp   CaptureSource = "app"
+	CaptureSourceSlack CaptureSource = "slack"
+	CaptureSourceVoice CaptureSource = "voice"
+	// CaptureSourceEmail and CaptureSourceShare are declared by the clients that create todos
+	// for them, an email gateway and the share sheets of the mobile apps.
+	CaptureSourceEmail CaptureSource = "email"
+	CaptureSourceShare CaptureSource = "share"
+	// CaptureSourceAgent are todos created by AI assistants with an agent token.
+	CaptureSourceAgent CaptureSource = "agent"
+)
+
+func ParseCaptureSource(name string) (CaptureSource, error) {
+	switch source := CaptureSource(strings.ToLower(name)); source {
+	case CaptureSourceApp, CaptureSourceSlack, CaptureSourceVoice, CaptureSourceEmail, CaptureSourceShare, CaptureSourceAgent:
+		return source, nil
+	}
+
+	return "", fmt.Errorf("%q: %w", name, ErrInvalidCaptureSource)
+}
+
+// RuleField is the part of a todo a rule matches on.
+type RuleField string
+
+const (
+	RuleFieldTitle       RuleField = "title"
+	RuleFieldDescription RuleField = "description"
+)
+
+type RuleOperator string
+
+const (
+	RuleOperatorContains   RuleOperator = "contains"
+	RuleOperatorEquals     RuleOperator = "equals"
+	RuleOperatorStartsWith RuleOperator = "starts_with"
+	RuleOperatorEndsWith   RuleOperator = "ends_with"
+)
+
+// Rule tags and sorts the todos of a user whose Field matches Value, e.g. todos with "invoice"
+// in their title get the finance tag and a high priority. Matching ignores case. Rules with a
+// Condition match when the expression is true instead, see RuleExpressionTarget.
+//
+// A rule with a Source only applies to todos captured there, and a Channel narrows it to one
+// channel of the source: the Slack channel a command was sent in, the OAuth client of a voice
+// assistant, the address a mail was sent to, the app that shared a todo or the agent token of an
+// AI assistant. Such rules route captured todos, e.g. everything sent to Slack's #groceries goes
+// to the shopping list. Only new todos have a source, rules with one don't apply to changes.
+type Rule struct {
+	ID       uint
+	UUID     string
+	UserID   uint
+	Name     string
+	Position int
+	Field    RuleField
+	Operator RuleOperator
+	Value    string
+	Source   CaptureSource
+	Channel  string
+	// Condition is an expression that replaces Field, Operator and Value when it is set.
+	Condition string
+	// Tags, Priority and ListUUID are the actions, a nil priority and an empty list are left as is.
+	Tags     []string
+	Priority *Priority
+	ListUUID string
+	Computed RuleComputed
+	// Stop skips the rules after this one when it matches.
+	Stop      bool
+	CreatedAt time.Time
+}
+
+// RuleComputed are actions computed by expressions from the todo, e.g. a title stripped of a
+// prefix or a priority that depends on the length of the description. An empty expression
+// computes nothing, computed tags and priority apply after Tags and Priority.
+type RuleComputed struct {
+	Title       string
+	Description string
+	Priority    string
+	Tags        string
+}
+
+// Empty reports whether nothing is computed.
+func (c RuleComputed) Empty() bool {
+	return c == RuleComputed{}
+}
+
+// expressions returns the expressions by their target, in the order they are computed.
+func (c RuleComputed) expressions() []ruleExpression {
+	return []ruleExpression{
+		{RuleTargetTitle, c.Title},
+		{RuleTargetDescription, c.Description},
+		{RuleTargetPriority, c.Priority},
+		{RuleTargetTags, c.Tags},
+	}
+}
+
+type ruleExpression struct {
+	target RuleExpressionTarget
+	source string
+}
+
+// Matches reports whether the rule applies to the todo described by subject. An empty Value
+// contains every text, so a rule with a source and no value matches everything captured there.
+// It fails when the condition can't be evaluated, e.g. because it exceeded its limits.
+func (r *Rule) Matches(subject *RuleSubject) (bool, error) {
+	if r.Source != "" && r.Source != subject.Source {
+		return false, nil
+	}
+	if r.Channel != "" && !strings.EqualFold(normalizeChannel(r.Channel), normalizeChannel(subject.Channel)) {
+		return false, nil
+	}
+	if r.Condition != "" {
+		matched, err := EvaluateRuleExpression(RuleTargetCondition, r.Condition, subject)
+		if err != nil {
+			return false, err
+		}
+		return matched.(bool), nil
+	}
+
+	return r.matchesField(subject), nil
+}
+
+func (r *Rule) matchesField(subject *RuleSubject) bool {
+	text := subject.Title
+	if r.Field == RuleFieldDescription {
+		text = subject.Description
+	}
+	text = strings.ToLower(text)
+	value := strings.ToLower(r.Value)
+
+	switch r.Operator {
+	case RuleOperatorContains:
+		return strings.Contains(text, value)
+	case RuleOperatorEquals:
+		return strings.TrimSpace(text) == strings.TrimSpace(value)
+	case RuleOperatorStartsWith:
+		return strings.HasPrefix(text, value)
+	case RuleOperatorEndsWith:
+		return strings.HasSuffix(text, value)
+	}
+
+	return false
+}
+
+// normalizeChannel drops the # of Slack channel names, people write them both ways.
+func normalizeChannel(channel string) string {
+	return strings.TrimPrefix(strings.TrimSpace(channel), "#")
+}
+
+// RuleCreate holds the definition of a rule, updates replace the whole definition.
+type RuleCreate struct {
+	Name      string
+	Field     RuleField
+	Operator  RuleOperator
+	Value     string
+	Source    CaptureSource
+	Channel   string
+	Condition string
+	Tags      []string
+	Priority  *Priority
+	ListUUID  string
+	Computed  RuleComputed
+	Stop      bool
+}
+
+// RuleSubject is the todo the rules are evaluated against. Source and Channel are only set for
+// new todos.
+type RuleSubject struct {
+	Title       string
+	Description string
+	Source      CaptureSource
+	Channel     string
+}
+
+// RuleOutcome is what the matching rules do to a todo. Tags add up, a later rule overrides the
+// priority, list and computed text of an earlier one. Title and Description are empty unless a
+// rule computed them.
+type RuleOutcome struct {
+	Matched     []*Rule
+	Tags        []string
+	Priority    *Priority
+	ListUUID    string
+	Title       string
+	Description string
+	// Errors are the expressions that failed, a failed condition doesn't match and a failed
+	// computation is left out.
+	Errors []*RuleError
+}
+
+// RuleError is an expression of a rule that failed to evaluate.
+type RuleError struct {
+	Rule   *Rule
+	Target RuleExpressionTarget
+	Err    error
+}
+
+// EvaluateRules applies the rules in order to the subject, until a matching rule stops it. A
+// computed title or description is what the rules after it see.
+func EvaluateRules(rules []*Rule, subject *RuleSubject) *RuleOutcome {
+	outcome := &RuleOutcome{
+		Matched: []*Rule{},
+		Tags:    []string{},
+		Errors:  []*RuleError{},
+	}
+	current := *subject
+	for _, rule := range rules {
+		matched, err := rule.Matches(&current)
+		if err != nil {
+			outcome.Errors = append(outcome.Errors, &RuleError{Rule: rule, Target: RuleTargetCondition, Err: err})
+			continue
+		}
+		if !matched {
+			continue
+		}
+
+		outcome.Matched = append(outcome.Matched, rule)
+		outcome.addTags(rule.Tags)
+		if rule.Priority != nil {
+			outcome.Priority = rule.Priority
+		}
+		if rule.ListUUID != "" {
+			outcome.ListUUID = rule.ListUUID
+		}
+		outcome.compute(rule, &current)
+		if rule.Stop {
+			break
+		}
+	}
+
+	return outcome
+}
+
+func (o *RuleOutcome) addTags(tags []string) {
+	for _, tag := range tags {
+		if !slices.Contains(o.Tags, tag) {
+			o.Tags = append(o.Tags, tag)
+		}
+	}
+}
+
+// compute applies the computed actions of a matching rule, subject is updated with the text
+// computed.
+func (o *RuleOutcome) compute(rule *Rule, subject *RuleSubject) {
+	for _, expression := range rule.Computed.expressions() {
+		if expression.source == "" {
+			continue
+		}
+
+		value, err := EvaluateRuleExpression(expression.target, expression.source, subject)
+		if err == nil {
+			err = o.apply(expression.target, value, subject)
+		}
+		if err != nil {
+			o.Errors = append(o.Errors, &RuleError{Rule: rule, Target: expression.target, Err: err})
+		}
+	}
+}
+
+func (o *RuleOutcome) apply(target RuleExpressionTarget, value interface{}, subject *RuleSubject) error {
+	switch target {
+	// an empty text leaves it as is, e.g. when a prefix to strip isn't there
+	case RuleTargetTitle:
+		if title := truncateRunes(strings.TrimSpace(value.(string)), MaxComputedTitleLength); title != "" {
+			o.Title, subject.Title = title, title
+		}
+	case RuleTargetDescription:
+		if description := value.(string); description != "" {
+			o.Description, subject.Description = description, description
+		}
+	case RuleTargetPriority:
+		if value.(string) == "" {
+			return nil
+		}
+		priority, err := ParsePriority(value.(string))
+		if err != nil {
+			return err
+		}
+		o.Priority = &priority
+	case RuleTargetTags:
+		o.addTags(NormalizeTagNames(value.([]string)))
+	}
+
+	return nil
+}
+
+// MaxComputedTitleLength is the longest title a rule computes, in characters, like the longest
+// title a todo is created with.
+const MaxComputedTitleLength = 255
+
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	return string([]rune(s)[:n])
+}
+
+// RuleExpressionTarget is what an expression of a rule computes. Expressions are written in the
+// language of package expr and see the variables title, description, source and channel of the
+// todo, as strings. A condition is a bool, tags a list and the other targets strings.
+type RuleExpressionTarget string
+
+const (
+	RuleTargetCondition   RuleExpressionTarget = "condition"
+	RuleTargetTitle       RuleExpressionTarget = "title"
+	RuleTargetDescription RuleExpressionTarget = "description"
+	RuleTargetPriority    RuleExpressionTarget = "priority"
+	RuleTargetTags        RuleExpressionTarget = "tags"
+)
+
+// ruleExpressionTypes are the types the targets evaluate to.
+//
+//nolint:gochecknoglobals // lookup table
+var ruleExpressionTypes = map[RuleExpressionTarget]expr.Type{
+	RuleTargetCondition:   expr.TypeBool,
+	RuleTargetTitle:       expr.TypeString,
+	RuleTargetDescription: expr.TypeString,
+	RuleTargetPriority:    expr.TypeString,
+	RuleTargetTags:        expr.TypeList,
+}
+
+//nolint:gochecknoglobals // the variables are the same for every rule
+var ruleExpressionEnv = expr.Env{
+	"title":       expr.TypeString,
+	"description": expr.TypeString,
+	"source":      expr.TypeString,
+	"channel":     expr.TypeString,
+}
+
+// RuleExpressionLimits sandbox the expressions of rules, an expression that exceeds them fails.
+// They are generous for text the size of a todo and keep a rule from stalling the creation of
+// todos.
+//
+//nolint:gochecknoglobals // configuration of the sandbox
+var RuleExpressionLimits = expr.Limits{
+	MaxCost:   10000,
+	MaxMemory: 256 << 10,
+}
+
+func ParseRuleExpressionTarget(name string) (RuleExpressionTarget, error) {
+	target := RuleExpressionTarget(strings.ToLower(name))
+	if _, ok := ruleExpressionTypes[target]; !ok {
+		return "", fmt.Errorf("%q: %w", name, ErrInvalidRuleTarget)
+	}
+
+	return target, nil
+}
+
+// CompileRuleExpression compiles an expression for a target, it fails with an
+// ErrInvalidRuleExpression that wraps the *expr.Error with the position of the mistake.
+func CompileRuleExpression(target RuleExpressionTarget, source string) (*expr.Program, error) {
+	want, ok := ruleExpressionTypes[target]
+	if !ok {
+		return nil, fmt.Errorf("%q: %w", target, ErrInvalidRuleTarget)
+	}
+
+	program, err := expr.Compile(source, ruleExpressionEnv)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w: %w", target, ErrInvalidRuleExpression, err)
+	}
+	if program.Type() != want {
+		err = &expr.Error{Line: 1, Column: 1, Err: fmt.Errorf("expression is a %s, not a %s", program.Type(), want)}
+		return nil, fmt.Errorf("%s: %w: %w", target, ErrInvalidRuleExpression, err)
+	}
+
+	return program, nil
+}
+
+// EvaluateRuleExpression compiles and evaluates an expression against the subject within
+// RuleExpressionLimits. Expressions are compiled on every evaluation, compiling costs about as
+// much as evaluating.
+func EvaluateRuleExpression(target RuleExpressionTarget, source string, subject *RuleSubject) (interface{}, error) {
+	program, err := CompileRuleExpression(target, source)
+	if err != nil {
+		return nil, err
+	}
+
+	return evaluateRuleExpression(program, subject)
+}
+
+func evaluateRuleExpression(program *expr.Program, subject *RuleSubject) (interface{}, error) {
+	return program.Eval(map[string]interface{}{
+		"title":       subject.Title,
+		"description": subject.Description,
+		"source":      string(subject.Source),
+		"channel":     subject.Channel,
+	}, RuleExpressionLimits)
+}
+
+// RuleExpressionCheck is the result of checking an expression, and of evaluating it against a
+// sample todo when there is one. Err is the compile or runtime error, Line and Column are where
+// it occurred.
+type RuleExpressionCheck struct {
+	Target RuleExpressionTarget
+	Valid  bool
+	Type   string
+	Err    error
+	Line   int
+	Column int
+	// Result is the value of the expression for the sample, nil without one.
+	Result interface{}
+}
+
+// CheckRuleExpression checks an expression for a target and evaluates it against the sample,
+// which may be nil. An invalid expression isn't an error, it is reported in the check.
+func CheckRuleExpression(target RuleExpressionTarget, source string, sample *RuleSubject) *RuleExpressionCheck {
+	check := &RuleExpressionCheck{Target: target}
+
+	program, err := CompileRuleExpression(target, source)
+	if err == nil && sample != nil {
+		check.Result, err = evaluateRuleExpression(program, sample)
+	}
+	if program != nil {
+		check.Type = program.Type().String()
+	}
+	if err != nil {
+		check.Result = nil
+		check.Err = err
+		var exprErr *expr.Error
+		if errors.As(err, &exprErr) {
+			check.Line, check.Column = exprErr.Line, exprErr.Column
+		}
+		// a sample that fails at runtime doesn't make the expression invalid
+		check.Valid = program != nil
+		return check
+	}
+	check.Valid = true
+
+	return check
+}
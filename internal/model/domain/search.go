@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+
+	// maxEmbeddingTextLength caps the text of a todo that is embedded, long descriptions are
+	// cut to stay within the input limits of the embedding models.
+	maxEmbeddingTextLength = 8000
+)
+
+var (
+	ErrEmptySearchQuery       = NewError(KindValidation, "search query is empty")
+	ErrInvalidSearchMode      = NewError(KindValidation, "invalid search mode")
+	ErrSemanticSearchDisabled = NewError(KindNotFound, "semantic search is not enabled on this server")
+)
+
+// SearchMode is how todos are matched with a query. Keyword search matches the words of the
+// query, semantic search compares the meaning of the query with the embeddings of the todos.
+type SearchMode string
+
+const (
+	SearchKeyword  SearchMode = "keyword"
+	SearchSemantic SearchMode = "semantic"
+)
+
+// ParseSearchMode parses the mode of a search, empty is keyword search.
+func ParseSearchMode(value string) (SearchMode, error) {
+	switch mode := SearchMode(value); mode {
+	case "":
+		return SearchKeyword, nil
+	case SearchKeyword, SearchSemantic:
+		return mode, nil
+	}
+
+	return "", fmt.Errorf("%q: %w", value, ErrInvalidSearchMode)
+}
+
+type TodoSearchResult struct {
+	Todo *Todo
+	Rank float64
+	// Snippet is the matching text with the matched terms wrapped in <mark> tags, semantic
+	// results have no snippet.
+	Snippet string
+	// AttachmentUUID is set when the snippet is from the best matching attachment of the todo
+	// because the todo itself didn't match.
+	AttachmentUUID string
+}
+
+// TodoEmbedding is the embedding of a todo for semantic search. It is due while the todo
+// changed since it was last embedded.
+type TodoEmbedding struct {
+	TodoID uint
+	UserID uint
+	// Model is the embedder that embedded ContentHash, a todo is embedded again when either
+	// changed.
+	Model       string
+	ContentHash string
+	// Text is what is embedded of the todo, see TodoEmbeddingText.
+	Text     string
+	Attempts int
+	DueAt    time.Time
+}
+
+// TodoEmbeddingText returns the text of a todo that is embedded, its title and description.
+func TodoEmbeddingText(todo *Todo) string {
+	text := strings.TrimSpace(todo.Title + "\n\n" + todo.Description)
+	return truncateRunes(text, maxEmbeddingTextLength)
+}
+
+// EmbeddingContentHash identifies the text that was embedded, so unchanged texts aren't sent to
+// the model again.
+func EmbeddingContentHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
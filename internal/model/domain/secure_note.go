@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+var (
+	ErrSecureNoteNotFound = NewError(KindNotFound, "secure note not found")
+	ErrSecureNoteUnsealed = NewError(KindInternal, "secure note can't be opened with the key of this server")
+)
+
+// SecureNote is a secret of a todo, e.g. a locker code. It is stored sealed and only revealed on
+// request, exports, search and notifications never include it.
+type SecureNote struct {
+	TodoID uint
+	// Sealed is Note encrypted with the key of the server, Note is only set once it is revealed.
+	Sealed    []byte
+	Note      string
+	UpdatedBy uint
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
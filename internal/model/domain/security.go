@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"time"
+)
+
+var ErrSecurityEventsOwnerOnly = NewError(KindForbidden, "only the household owner can subscribe to security events")
+
+// SecurityEvent is a security relevant event of a household member, e.g. for a SIEM. User is
+// the account the event happened to, the request details come from the audit entry.
+type SecurityEvent struct {
+	Type      EventType
+	User      *User
+	IP        string
+	UserAgent string
+	RequestID string
+	// Details describe the event, e.g. the reason of a failed login or what permission changed.
+	Details    map[string]string
+	OccurredAt time.Time
+}
+
+// OwnerID returns the household owner the event is reported to.
+func (e *SecurityEvent) OwnerID() uint {
+	if e.User.IsChild() {
+		return e.User.ParentID
+	}
+
+	return e.User.ID
+}
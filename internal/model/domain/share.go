@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// ListInvitationExpiration is how long an invitation to a list can be accepted.
+const ListInvitationExpiration = time.Hour * 24 * 7
+
+var (
+	ErrInvalidListRole         = NewError(KindValidation, "invalid list role")
+	ErrListReadOnly            = NewError(KindForbidden, "list is read-only for viewers")
+	ErrListOwnerOnly           = NewError(KindForbidden, "only the list owner can do this")
+	ErrAlreadyListMember       = NewError(KindConflict, "user is already a member of this list")
+	ErrListMemberNotFound      = NewError(KindNotFound, "list member not found")
+	ErrInvitationNotFound      = NewError(KindNotFound, "invitation not found")
+	ErrInvitationExpired       = NewError(KindValidation, "invitation expired")
+	ErrInvitationEmailMismatch = NewError(KindForbidden, "invitation was sent to a different email")
+)
+
+// ListRole is the access a user has to a list.
+type ListRole string
+
+const (
+	ListRoleOwner  ListRole = "owner"
+	ListRoleEditor ListRole = "editor"
+	ListRoleViewer ListRole = "viewer"
+)
+
+// CanWrite reports whether the role can change the todos of a list.
+func (r ListRole) CanWrite() bool {
+	return r == ListRoleOwner || r == ListRoleEditor
+}
+
+// ParseListRole parses the role of a collaborator, the owner role can not be granted.
+func ParseListRole(value string) (ListRole, error) {
+	switch role := ListRole(value); role {
+	case ListRoleEditor, ListRoleViewer:
+		return role, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrInvalidListRole, value)
+	}
+}
+
+type ListMember struct {
+	ListID    uint
+	UserID    uint
+	UserUUID  string
+	Email     string
+	Role      ListRole
+	CreatedAt time.Time
+}
+
+// SharedList is a list together with the role the current user has on it.
+type SharedList struct {
+	List *List
+	Role ListRole
+}
+
+type ListInvitation struct {
+	ID         uint
+	UUID       string
+	ListID     uint
+	InviterID  uint
+	Email      string
+	Role       ListRole
+	ExpiresAt  time.Time
+	AcceptedAt time.Time
+	CreatedAt  time.Time
+}
+
+// Accepted reports whether the invitation was already accepted.
+func (i *ListInvitation) Accepted() bool {
+	return !i.AcceptedAt.IsZero()
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"errors"
+	"time"
+)
+
+// SigningKeyAlgorithm is the JWS algorithm of access tokens, signing keys are P-256 keys.
+const SigningKeyAlgorithm = "ES256"
+
+var (
+	// ErrUnknownSigningKey is returned for a token signed with a key that doesn't exist or
+	// is retired.
+	ErrUnknownSigningKey = NewError(KindUnauthorized, "unknown signing key")
+	ErrNoSigningKey      = NewError(KindInternal, "no active signing key")
+)
+
+// SigningKey is a key access tokens are signed with. The newest key signs new tokens, the older
+// ones keep verifying the tokens they signed until RetiresAt, so a rotation doesn't log anyone out.
+type SigningKey struct {
+	ID uint
+	// KID names the key in the kid header of tokens and in the JWKS document.
+	KID       string
+	Algorithm string
+	// PrivateKey is the sealed PKCS #8 private key, only the service can open it.
+	PrivateKey []byte
+	// PublicKey is the PKIX public key.
+	PublicKey []byte
+	CreatedAt time.Time
+	// RetiresAt is set once a newer key took over, zero while the key is the active one.
+	RetiresAt time.Time
+}
+
+// Retired reports whether the key no longer verifies tokens.
+func (k *SigningKey) Retired(now time.Time) bool {
+	return !k.RetiresAt.IsZero() && !now.Before(k.RetiresAt)
+}
+
+// ECDSAPublicKey parses the public key.
+func (k *SigningKey) ECDSAPublicKey() (*ecdsa.PublicKey, error) {
+	parsed, err := x509.ParsePKIXPublicKey(k.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	public, ok := parsed.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errors.New("signing key is not an ECDSA key")
+	}
+
+	return public, nil
+}
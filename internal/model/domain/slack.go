@@ -0,0 +1,140 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+const (
+	// SlackLinkCodePrefix makes link codes recognizable, users paste them into Slack.
+	SlackLinkCodePrefix = "slk_"
+	// SlackLinkCodeTTL is how long a link code can be used after the connection was saved.
+	SlackLinkCodeTTL = time.Hour
+	// SlackMaxAttempts is how often posting a message is tried before it is given up on.
+	SlackMaxAttempts = 5
+	// SlackRetryBase is the delay before the first retry of a message, every retry doubles it.
+	SlackRetryBase = 30 * time.Second
+	// SlackRetryMax caps the delay between two attempts to post a message.
+	SlackRetryMax = time.Hour
+)
+
+var (
+	ErrSlackNotConnected      = NewError(KindNotFound, "slack is not connected")
+	ErrInvalidSlackConnection = NewError(KindValidation, "a slack connection needs a webhook url, or a bot token and a channel")
+	ErrInvalidSlackEvent      = NewError(KindValidation, "invalid slack event")
+	ErrSlackCommandsDisabled  = NewError(KindNotFound, "slack commands are not enabled on this server")
+	ErrSlackNotLinked         = NewError(KindNotFound, "slack user is not linked")
+	ErrInvalidSlackLinkCode   = NewError(KindValidation, "invalid or expired slack link code")
+)
+
+// SlackEvent is a kind of todo notification posted to Slack.
+type SlackEvent string
+
+const (
+	// SlackEventAssigned is a todo handed over to the user, by moving it to a list they own.
+	SlackEventAssigned SlackEvent = "assigned"
+	SlackEventOverdue  SlackEvent = "overdue"
+	// SlackEventCompleted is a todo completed in a shared list of the user.
+	SlackEventCompleted SlackEvent = "completed"
+)
+
+//nolint:gochecknoglobals // read-only
+var SlackEvents = []SlackEvent{SlackEventAssigned, SlackEventOverdue, SlackEventCompleted}
+
+func ParseSlackEvent(name string) (SlackEvent, error) {
+	if event := SlackEvent(name); slices.Contains(SlackEvents, event) {
+		return event, nil
+	}
+
+	return "", fmt.Errorf("%q: %w", name, ErrInvalidSlackEvent)
+}
+
+// SlackConnection posts the todo notifications of a user to Slack. Messages go to WebhookURL
+// when it is set, otherwise to Channel with the bot token of the Slack app of the user.
+type SlackConnection struct {
+	ID         uint
+	UserID     uint
+	WebhookURL string
+	// BotToken is never returned once saved.
+	BotToken string
+	Channel  string
+	Events   []SlackEvent
+	// TeamID and SlackUserID are the Slack user linked for slash commands, empty until the
+	// user sent the link code.
+	TeamID      string
+	SlackUserID string
+	// LinkCodeHash and LinkCodeExpiresAt are set while a link code is waiting to be used.
+	LinkCodeHash      string
+	LinkCodeExpiresAt time.Time
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+func (c *SlackConnection) Subscribed(event SlackEvent) bool {
+	return slices.Contains(c.Events, event)
+}
+
+func (c *SlackConnection) Linked() bool {
+	return c.SlackUserID != ""
+}
+
+type SlackConnectionUpdate struct {
+	WebhookURL string
+	BotToken   string
+	Channel    string
+	Events     []SlackEvent
+}
+
+type SlackMessageStatus string
+
+const (
+	SlackMessagePending SlackMessageStatus = "pending"
+	SlackMessageSent    SlackMessageStatus = "sent"
+	SlackMessageFailed  SlackMessageStatus = "failed"
+)
+
+// SlackMessage is a notification waiting in the outbox of the Slack worker.
+type SlackMessage struct {
+	ID   uint
+	UUID string
+	// Connection only has the fields needed to post the message.
+	Connection    *SlackConnection
+	Event         SlackEvent
+	Text          string
+	Status        SlackMessageStatus
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+	SentAt        time.Time
+	CreatedAt     time.Time
+}
+
+// SlackBackoff returns how long to wait after the given number of failed attempts, doubling from
+// SlackRetryBase up to SlackRetryMax.
+func SlackBackoff(attempts int) time.Duration {
+	delay := SlackRetryBase
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= SlackRetryMax {
+			return SlackRetryMax
+		}
+	}
+
+	return delay
+}
+
+// SlackCommand is a slash command a Slack user sent, Text is what they typed after the command.
+type SlackCommand struct {
+	TeamID  string
+	UserID  string
+	Command string
+	Text    string
+	// ChannelName is the channel the command was sent in, without the #.
+	ChannelName string
+}
+
+// SlackCommandResponse is shown only to the Slack user who sent the command.
+type SlackCommandResponse struct {
+	Text string
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+var (
+	ErrSnapshotScheduleNotFound = NewError(KindNotFound, "snapshot schedule not found")
+	ErrInvalidFrequency         = NewError(KindValidation, "invalid frequency")
+)
+
+type SnapshotFrequency string
+
+const (
+	SnapshotFrequencyDaily  SnapshotFrequency = "daily"
+	SnapshotFrequencyWeekly SnapshotFrequency = "weekly"
+)
+
+func ParseSnapshotFrequency(frequency string) (SnapshotFrequency, error) {
+	switch SnapshotFrequency(frequency) {
+	case SnapshotFrequencyDaily, SnapshotFrequencyWeekly:
+		return SnapshotFrequency(frequency), nil
+	default:
+		return "", fmt.Errorf("%q: %w", frequency, ErrInvalidFrequency)
+	}
+}
+
+// Next returns the next time a snapshot is due after t.
+func (f SnapshotFrequency) Next(t time.Time) time.Time {
+	if f == SnapshotFrequencyWeekly {
+		return t.AddDate(0, 0, 7)
+	}
+
+	return t.AddDate(0, 0, 1)
+}
+
+// ListSnapshotSchedule periodically emails a snapshot of a list to the recipients.
+type ListSnapshotSchedule struct {
+	ID         uint
+	UUID       string
+	ListID     uint
+	UserID     uint
+	Recipients []string
+	Frequency  SnapshotFrequency
+	NextRunAt  time.Time
+	LastSentAt time.Time
+	CreatedAt  time.Time
+}
@@ -0,0 +1,29 @@
+package domain
+
+// SuggestionAction is what the user could do about a stalled todo.
+type SuggestionAction string
+
+const (
+	// SuggestionBreakDown splits a large todo into smaller steps that are easier to start.
+	SuggestionBreakDown SuggestionAction = "break_down"
+	// SuggestionDrop deletes a todo that evidently isn't important anymore.
+	SuggestionDrop SuggestionAction = "drop"
+	// SuggestionDelegate hands a todo to a collaborator of its list.
+	SuggestionDelegate SuggestionAction = "delegate"
+)
+
+// TodoSuggestion proposes actions for a todo that keeps getting postponed or is long overdue.
+type TodoSuggestion struct {
+	Todo *Todo
+	// Stalled explains why the todo was picked up.
+	Stalled []string
+	// Actions are ordered from most to least fitting.
+	Actions []*SuggestedAction
+}
+
+type SuggestedAction struct {
+	Action SuggestionAction
+	Reason string
+	// Delegates are the emails of the collaborators a todo could be delegated to.
+	Delegates []string
+}
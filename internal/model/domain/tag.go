@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// TagGroupSeparator separates the group of a tag from the rest of its name, e.g. "context/home"
+// is in the group "context". Groups are optional, a name without it has no group.
+const TagGroupSeparator = "/"
+
+var (
+	ErrTagNotFound      = NewError(KindNotFound, "tag not found")
+	ErrTagAlreadyExists = NewError(KindConflict, "tag already exists")
+	ErrTagMergeSelf     = NewError(KindValidation, "a tag can't be merged into itself")
+)
+
+type Tag struct {
+	ID     uint
+	UUID   string
+	UserID uint
+	Name   string
+	// Color is a lowercase #rrggbb hex color, empty when the tag has none.
+	Color     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TagMergeResult reports how the todos of a merged tag were rewritten.
+type TagMergeResult struct {
+	Target *Tag
+	// Retagged counts the todos moved from the source tag to the target, Duplicates those that
+	// already had both and only lost the source.
+	Retagged   int
+	Duplicates int
+}
+
+// Group returns the group of the tag, empty for tags without one.
+func (t *Tag) Group() string {
+	group, _, ok := strings.Cut(t.Name, TagGroupSeparator)
+	if !ok {
+		return ""
+	}
+
+	return group
+}
+
+// Label returns the name of the tag without its group.
+func (t *Tag) Label() string {
+	return strings.TrimPrefix(t.Name, t.Group()+TagGroupSeparator)
+}
+
+// NormalizeTagName makes tag names case insensitive and trims surrounding whitespace.
+func NormalizeTagName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// NormalizeTagNames normalizes and de-duplicates tag names, dropping empty ones.
+func NormalizeTagNames(names []string) []string {
+	seen := make(map[string]struct{}, len(names))
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		name = NormalizeTagName(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		normalized = append(normalized, name)
+	}
+
+	return normalized
+}
+
+// NormalizeTagGroup normalizes a group like a tag name, a trailing separator is optional.
+func NormalizeTagGroup(group string) string {
+	return strings.TrimSuffix(NormalizeTagName(group), TagGroupSeparator)
+}
+
+// NormalizeTagColor lowercases a hex color so colors compare equal regardless of their case.
+func NormalizeTagColor(color string) string {
+	return strings.ToLower(strings.TrimSpace(color))
+}
@@ -0,0 +1,49 @@
+package domain
+
+import "time"
+
+const (
+	// TakeoutExpiration is how long a finished takeout can be downloaded, its archive is deleted
+	// afterwards.
+	TakeoutExpiration = 72 * time.Hour
+	// TakeoutJobTimeout is how long assembling a takeout may take, a job whose worker didn't
+	// finish it by then is run again.
+	TakeoutJobTimeout = 10 * time.Minute
+)
+
+var (
+	ErrTakeoutNotFound   = NewError(KindNotFound, "data export not found")
+	ErrTakeoutInProgress = NewError(KindConflict, "a data export is already being prepared")
+)
+
+type TakeoutStatus string
+
+const (
+	TakeoutPending   TakeoutStatus = "pending"
+	TakeoutRunning   TakeoutStatus = "running"
+	TakeoutCompleted TakeoutStatus = "completed"
+	TakeoutFailed    TakeoutStatus = "failed"
+)
+
+// Takeout is an export of all the personal data of a user, assembled in the background into a
+// ZIP archive that can be downloaded until ExpiresAt.
+type Takeout struct {
+	ID         uint
+	UUID       string
+	UserID     uint
+	Status     TakeoutStatus
+	StorageKey string
+	Size       int64
+	Error      string
+	// StartedAt is the claim of the worker assembling the archive.
+	StartedAt  time.Time
+	FinishedAt time.Time
+	ExpiresAt  time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// Expired reports whether the archive of the takeout was or is about to be deleted.
+func (t *Takeout) Expired(now time.Time) bool {
+	return !t.ExpiresAt.IsZero() && !now.Before(t.ExpiresAt)
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+var (
+	ErrTodoTemplateNotFound    = NewError(KindNotFound, "todo template not found")
+	ErrInvalidTemplateSubtask  = NewError(KindValidation, "template subtasks must be a single line")
+	ErrTemplateTooManySubtasks = NewError(KindValidation, "template has too many subtasks")
+)
+
+// TodoTemplate is a reusable todo, e.g. a release checklist. Todos created from it get the
+// subtasks as a checklist below the description, they can be split into todos of their own.
+type TodoTemplate struct {
+	ID          uint
+	UUID        string
+	UserID      uint
+	Name        string
+	Title       string
+	Description string
+	Priority    Priority
+	Estimate    time.Duration
+	Tags        []string
+	Subtasks    []string
+	// DueIn is counted in whole days from when a todo is created, nil creates todos without a
+	// due date.
+	DueIn     *time.Duration
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type TodoTemplateCreate struct {
+	// Name tells templates apart, the title of the template when empty.
+	Name        string
+	Title       string
+	Description string
+	Priority    Priority
+	Estimate    time.Duration
+	Tags        []string
+	Subtasks    []string
+	DueIn       *time.Duration
+}
+
+// Validate checks that every subtask makes a checklist item of its own.
+func (t *TodoTemplateCreate) Validate() error {
+	if len(t.Subtasks) > MaxChecklistItems {
+		return ErrTemplateTooManySubtasks
+	}
+	for _, subtask := range t.Subtasks {
+		if strings.ContainsAny(subtask, "\r\n") {
+			return ErrInvalidTemplateSubtask
+		}
+	}
+
+	return nil
+}
+
+// TodoCreate returns the todo to create from the template at now. The due date keeps the time
+// of day in location, also across a change to or from daylight saving time.
+func (t *TodoTemplate) TodoCreate(now time.Time, location *time.Location) *TodoCreate {
+	description := t.Description
+	if len(t.Subtasks) > 0 {
+		checklist := make([]string, 0, len(t.Subtasks))
+		for _, subtask := range t.Subtasks {
+			checklist = append(checklist, "- [ ] "+subtask)
+		}
+		description = strings.TrimSpace(description + "\n\n" + strings.Join(checklist, "\n"))
+	}
+
+	todoCreate := &TodoCreate{
+		Title:       t.Title,
+		Description: description,
+		Priority:    t.Priority,
+		Estimate:    t.Estimate,
+		Tags:        append([]string{}, t.Tags...),
+	}
+	if t.DueIn != nil {
+		todoCreate.DueDate = now.In(location).AddDate(0, 0, int(*t.DueIn/(24*time.Hour)))
+	}
+
+	return todoCreate
+}
+
+// TodoFromTemplate picks the list of a todo created from a template, an empty ListUUID creates
+// it without a list.
+type TodoFromTemplate struct {
+	ListUUID string
+	// Title replaces the title of the template when set, e.g. "Release 2.4".
+	Title string
+}
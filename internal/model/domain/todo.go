@@ -0,0 +1,271 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+var (
+	ErrTodoNotFound = NewError(KindNotFound, "todo not found")
+	ErrInvalidSort  = NewError(KindValidation, "invalid sort")
+	// ErrInvalidArchiveFilter is returned for an unknown archived filter of a todo query.
+	ErrInvalidArchiveFilter = NewError(KindValidation, "archived must be include or only")
+	// ErrInvalidCompletedFilter is returned for an unknown completed filter of a todo query.
+	ErrInvalidCompletedFilter = NewError(KindValidation, "completed must be exclude or only")
+	// ErrConflict is returned when a resource changed since the version an update was based on.
+	ErrConflict = NewError(KindConflict, "resource was changed by someone else")
+)
+
+type TodoSortField string
+
+const (
+	TodoSortPriority  TodoSortField = "priority"
+	TodoSortDueDate   TodoSortField = "due_date"
+	TodoSortTitle     TodoSortField = "title"
+	TodoSortCreatedAt TodoSortField = "created_at"
+	TodoSortUpdatedAt TodoSortField = "updated_at"
+	// TodoSortRank is the order the user dragged the todos into, see TodoReorder.
+	TodoSortRank TodoSortField = "rank"
+)
+
+type SortOrder string
+
+const (
+	SortOrderAsc  SortOrder = "asc"
+	SortOrderDesc SortOrder = "desc"
+)
+
+type Todo struct {
+	ID          uint
+	UUID        string
+	UserID      uint
+	ListID      uint
+	ListUUID    string
+	Title       string
+	Description string
+	Priority    Priority
+	DueDate     time.Time
+	// Estimate is how long the todo is expected to take, zero when unknown.
+	Estimate time.Duration
+	// Postponed counts how often the due date was moved later.
+	Postponed   int
+	CompletedAt time.Time
+	// ArchivedAt is set by the archival once a completed todo is past the retention of its list.
+	ArchivedAt time.Time
+	Tags       []*Tag
+	// Version is increased by every update.
+	Version int
+	// DeadLinks are the links in the description the link checker found dead.
+	DeadLinks []string
+	// Ciphertext holds the title and description of a todo of an encrypted list, encrypted with
+	// the list key of KeyVersion. Title and Description are empty then.
+	Ciphertext string
+	KeyVersion int
+	// Status is the key of the status of the todo on the board of its list and Position its
+	// place in that column from 1 on, both are empty until the todo is placed, see NewBoard.
+	Status   string
+	Position int
+	// Rank orders the todos of the owner by hand, empty until the todo was first reordered.
+	Rank string
+	// HasSecureNote tells whether the todo has a SecureNote, the note itself is never loaded
+	// with the todo.
+	HasSecureNote bool
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	DeletedAt     time.Time
+}
+
+func (t *Todo) Completed() bool {
+	return !t.CompletedAt.IsZero()
+}
+
+// Encrypted tells whether the todo belongs to an end-to-end encrypted list, the server can't
+// read its title and description.
+func (t *Todo) Encrypted() bool {
+	return t.Ciphertext != ""
+}
+
+// Overdue returns how long an open todo is past its due date, zero when it isn't overdue.
+func (t *Todo) Overdue(now time.Time) time.Duration {
+	if t.Completed() || t.DueDate.IsZero() || !now.After(t.DueDate) {
+		return 0
+	}
+
+	return now.Sub(t.DueDate)
+}
+
+type TodoCreate struct {
+	// ListUUID is resolved to ListID by the service, both are empty for todos without a list.
+	ListUUID    string
+	ListID      uint
+	Title       string
+	Description string
+	Priority    Priority
+	DueDate     time.Time
+	Estimate    time.Duration
+	Tags        []string
+	// Ciphertext, KeyVersion and BlindIndexes are only set for todos of encrypted lists, which
+	// have neither a title nor a description.
+	Ciphertext   string
+	KeyVersion   int
+	BlindIndexes []string
+	// Source and Channel tell the rules where the todo was captured, see Rule.
+	Source  CaptureSource
+	Channel string
+	// CreatedAt and CompletedAt are only set when a todo is restored from an archive, zero
+	// creates an open todo now. Restored todos are kept as they were, rules don't apply to them.
+	CreatedAt   time.Time
+	CompletedAt time.Time
+}
+
+// TodoUpdate holds the fields to change, nil fields are left untouched.
+type TodoUpdate struct {
+	Title       *string
+	Description *string
+	Priority    *Priority
+	DueDate     *time.Time
+	// Estimate clears the estimate when zero.
+	Estimate  *time.Duration
+	Completed *bool
+	// Ciphertext re-encrypts a todo of an encrypted list with the list key of KeyVersion, and
+	// BlindIndexes replaces its blind indexes unless nil.
+	Ciphertext   *string
+	KeyVersion   *int
+	BlindIndexes []string
+	// Version is the version the update is based on, nil updates whatever version is current.
+	Version *int
+	// Force completes the todo even though open todos block it.
+	Force bool
+}
+
+// TodoMove names the list to move a todo to, an empty ListUUID moves it out of its list.
+type TodoMove struct {
+	ListUUID string
+	// ListID and OwnerID are resolved by the service, todos belong to the owner of their list.
+	ListID  uint
+	OwnerID uint
+	// Version is the version the move is based on, nil moves whatever version is current.
+	Version *int
+}
+
+type TodoFilter struct {
+	// ListUUID is resolved to ListID by the service.
+	ListUUID string
+	ListID   uint
+	// Tags only returns todos tagged with every one of the given tag names.
+	Tags []string
+	// TagGroup only returns todos with at least one tag in the group.
+	TagGroup string
+	// Sort lists the fields to order by, in order of precedence.
+	Sort  []TodoSortField
+	Order SortOrder
+	// Archived picks whether archived todos are returned, by default they are left out.
+	Archived ArchiveFilter
+	// DueFrom and DueBefore only return todos due from DueFrom and before DueBefore, a zero
+	// time leaves that end of the range open. Todos without a due date are left out by either.
+	DueFrom   time.Time
+	DueBefore time.Time
+	// CreatedFrom and CreatedBefore only return todos created from CreatedFrom and before
+	// CreatedBefore, a zero time leaves that end of the range open.
+	CreatedFrom   time.Time
+	CreatedBefore time.Time
+	// Completed picks whether completed todos are returned, by default they are.
+	Completed CompletedFilter
+	// BlindIndex only returns encrypted todos with the blind index, see ListKey.
+	BlindIndex string
+}
+
+// CompletedFilter selects completed todos.
+type CompletedFilter string
+
+const (
+	CompletedInclude CompletedFilter = ""
+	CompletedExclude CompletedFilter = "exclude"
+	CompletedOnly    CompletedFilter = "only"
+)
+
+func ParseCompletedFilter(value string) (CompletedFilter, error) {
+	switch filter := CompletedFilter(value); filter {
+	case CompletedInclude, CompletedExclude, CompletedOnly:
+		return filter, nil
+	default:
+		return "", fmt.Errorf("%q: %w", value, ErrInvalidCompletedFilter)
+	}
+}
+
+// ArchiveFilter selects archived todos, a todo counts as archived once it is completed for
+// longer than the retention of its list, even before the archival worker stamped it.
+type ArchiveFilter string
+
+const (
+	ArchiveExclude ArchiveFilter = ""
+	ArchiveInclude ArchiveFilter = "include"
+	ArchiveOnly    ArchiveFilter = "only"
+)
+
+func ParseArchiveFilter(value string) (ArchiveFilter, error) {
+	switch filter := ArchiveFilter(value); filter {
+	case ArchiveExclude, ArchiveInclude, ArchiveOnly:
+		return filter, nil
+	default:
+		return "", fmt.Errorf("%q: %w", value, ErrInvalidArchiveFilter)
+	}
+}
+
+// ParseTodoSort validates the requested sort fields and order.
+func ParseTodoSort(fields []string, order string) ([]TodoSortField, SortOrder, error) {
+	sortFields := make([]TodoSortField, 0, len(fields))
+	for _, field := range fields {
+		sortField := TodoSortField(field)
+		switch sortField {
+		case TodoSortPriority, TodoSortDueDate, TodoSortTitle, TodoSortCreatedAt, TodoSortUpdatedAt, TodoSortRank:
+			sortFields = append(sortFields, sortField)
+		default:
+			return nil, "", fmt.Errorf("sort field %q: %w", field, ErrInvalidSort)
+		}
+	}
+
+	sortOrder := SortOrder(order)
+	switch sortOrder {
+	case "":
+		sortOrder = SortOrderAsc
+	case SortOrderAsc, SortOrderDesc:
+	default:
+		return nil, "", fmt.Errorf("sort order %q: %w", order, ErrInvalidSort)
+	}
+
+	return sortFields, sortOrder, nil
+}
+
+const (
+	// TodoMatchThreshold is the minimum confidence for a title to match.
+	TodoMatchThreshold = 0.6
+	// TodoMatchAmbiguity is how close the confidence of two matches can be before the
+	// match is ambiguous.
+	TodoMatchAmbiguity = 0.1
+)
+
+var (
+	ErrNoTodoMatch        = NewError(KindNotFound, "no todo matches the title")
+	ErrAmbiguousTodoMatch = NewError(KindConflict, "several todos match the title")
+)
+
+// TodoMatch is a todo matching a fuzzy title, Confidence ranges from 0 to 1.
+type TodoMatch struct {
+	Todo       *Todo
+	Confidence float64
+}
+
+// AmbiguousTodoMatchError is returned when several todos match a title about equally well,
+// the candidates let the client ask which one was meant.
+type AmbiguousTodoMatchError struct {
+	Candidates []*TodoMatch
+}
+
+func (e *AmbiguousTodoMatchError) Error() string {
+	return ErrAmbiguousTodoMatch.Error()
+}
+
+func (e *AmbiguousTodoMatchError) Unwrap() error {
+	return ErrAmbiguousTodoMatch
+}
@@ -0,0 +1,60 @@
+package domain
+
+import "time"
+
+const (
+	// MaxTodoBlockers caps the todos a single todo can be blocked by.
+	MaxTodoBlockers = 50
+	// MaxDependencyGraph caps the todos of a dependency graph.
+	MaxDependencyGraph = 500
+)
+
+var (
+	ErrTodoBlocked         = NewError(KindConflict, "todo is blocked by open todos, complete them first or force it")
+	ErrDependencySelf      = NewError(KindValidation, "a todo can't block itself")
+	ErrDependencyOtherList = NewError(KindValidation, "only todos of the same list can block each other")
+	ErrDependencyCycle     = NewError(KindConflict, "the todo already blocks this todo, directly or through others")
+	ErrDependencyNotFound  = NewError(KindNotFound, "dependency not found")
+	ErrTooManyBlockers     = NewError(KindValidation, "todo is blocked by too many todos")
+)
+
+// TodoDependency is a todo that is blocked by another one until that is completed. Dependencies
+// only hold between todos of the same list, or of no list of the same owner, and are ignored
+// once one of them is moved elsewhere.
+type TodoDependency struct {
+	TodoID      uint
+	TodoUUID    string
+	BlockerID   uint
+	BlockerUUID string
+	CreatedAt   time.Time
+}
+
+// TodoBlock marks a todo as blocked by the todo of BlockerUUID.
+type TodoBlock struct {
+	BlockerUUID string
+}
+
+// DependencyGraph is a todo together with every todo it waits for and every todo that waits for
+// it, directly or through others. Edges point from the blocked todo to its blocker.
+type DependencyGraph struct {
+	Todo  *Todo
+	Todos []*Todo
+	Edges []*TodoDependency
+}
+
+// BlockedBy returns the open todos of the graph that directly block the todo of id.
+func (g *DependencyGraph) BlockedBy(id uint) []*Todo {
+	byID := make(map[uint]*Todo, len(g.Todos))
+	for _, todo := range g.Todos {
+		byID[todo.ID] = todo
+	}
+
+	var blockers []*Todo
+	for _, edge := range g.Edges {
+		if blocker, ok := byID[edge.BlockerID]; ok && edge.TodoID == id && !blocker.Completed() {
+			blockers = append(blockers, blocker)
+		}
+	}
+
+	return blockers
+}
@@ -0,0 +1,173 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// TodoFormat is a file format todos are exported to and imported from.
+type TodoFormat string
+
+const (
+	TodoFormatCSV  TodoFormat = "csv"
+	TodoFormatJSON TodoFormat = "json"
+	// TodoFormatZIP is an archive of the todos as JSON together with a manifest describing the
+	// export and, if selected, the files attached to the todos. It can only be exported.
+	TodoFormatZIP TodoFormat = "zip"
+)
+
+const (
+	// MaxTodoImportRows caps the todos of a single import.
+	MaxTodoImportRows = 1000
+	// MaxTodoImportSize caps the size of an imported file in bytes.
+	MaxTodoImportSize = 5 << 20
+)
+
+// Fields of exported todos. Imports map them onto the columns of a CSV file or the keys of
+// the JSON objects, by default onto the column or key of the same name.
+const (
+	TodoFieldUUID        = "uuid"
+	TodoFieldListUUID    = "list_uuid"
+	TodoFieldTitle       = "title"
+	TodoFieldDescription = "description"
+	TodoFieldPriority    = "priority"
+	TodoFieldDueDate     = "due_date"
+	TodoFieldEstimate    = "estimate_minutes"
+	TodoFieldTags        = "tags"
+	TodoFieldCompletedAt = "completed_at"
+	TodoFieldCreatedAt   = "created_at"
+)
+
+var (
+	ErrInvalidTodoFormat  = NewError(KindValidation, "invalid format, expected csv, json or zip")
+	ErrInvalidTodoMapping = NewError(KindValidation, "invalid field mapping, expected field=column")
+	ErrTodoImportTooLarge = NewError(KindTooLarge, "import too large")
+	ErrInvalidTodoImport  = NewError(KindValidation, "invalid import file")
+
+	// ErrAttachmentsNeedArchive is returned when attachments are selected for an export that
+	// isn't an archive, only archives can hold files.
+	ErrAttachmentsNeedArchive = NewError(KindValidation, "attachments can only be exported in the zip format")
+)
+
+//nolint:gochecknoglobals // column order of exports
+var TodoExportFields = []string{
+	TodoFieldUUID, TodoFieldListUUID, TodoFieldTitle, TodoFieldDescription, TodoFieldPriority,
+	TodoFieldDueDate, TodoFieldEstimate, TodoFieldTags, TodoFieldCompletedAt, TodoFieldCreatedAt,
+}
+
+// ParseTodoFormat parses a format name, empty is TodoFormatJSON.
+func ParseTodoFormat(format string) (TodoFormat, error) {
+	switch TodoFormat(strings.ToLower(format)) {
+	case "", TodoFormatJSON:
+		return TodoFormatJSON, nil
+	case TodoFormatCSV:
+		return TodoFormatCSV, nil
+	case TodoFormatZIP:
+		return TodoFormatZIP, nil
+	default:
+		return "", fmt.Errorf("%q: %w", format, ErrInvalidTodoFormat)
+	}
+}
+
+// TodoExport selects the todos of an export.
+type TodoExport struct {
+	Format TodoFormat
+	// ListUUIDs only exports the todos of these lists, empty exports the todos of the user.
+	ListUUIDs []string
+	// Filter narrows the todos down further, its list is ignored in favor of ListUUIDs.
+	Filter *TodoFilter
+	// Attachments adds the files attached to the todos, only TodoFormatZIP can hold them.
+	Attachments bool
+}
+
+// TodoMapping maps todo fields to the columns or keys they are imported from.
+type TodoMapping map[string]string
+
+// ParseTodoMapping parses field=column pairs, only the fields an import reads can be mapped.
+func ParseTodoMapping(pairs []string) (TodoMapping, error) {
+	mapping := make(TodoMapping, len(pairs))
+	for _, pair := range pairs {
+		field, column, ok := strings.Cut(pair, "=")
+		field = strings.TrimSpace(field)
+		if !ok || column == "" || field == TodoFieldUUID || field == TodoFieldCreatedAt {
+			return nil, fmt.Errorf("%q: %w", pair, ErrInvalidTodoMapping)
+		}
+		if !slices.Contains(TodoExportFields, field) {
+			return nil, fmt.Errorf("unknown field %q: %w", field, ErrInvalidTodoMapping)
+		}
+		mapping[field] = column
+	}
+
+	return mapping, nil
+}
+
+// Column returns the column or key the field is imported from.
+func (m TodoMapping) Column(field string) string {
+	if column, ok := m[field]; ok {
+		return column
+	}
+
+	return field
+}
+
+// TodoImportRow is a todo read from an imported file.
+type TodoImportRow struct {
+	// Line is the line of a CSV file or the position of the object in a JSON array, from 1.
+	Line        int
+	Todo        *TodoCreate
+	CompletedAt time.Time
+}
+
+// DuplicateKey identifies the todo for duplicate detection, todos of the same list with the
+// same title due on the same day are considered the same.
+func (r *TodoImportRow) DuplicateKey() string {
+	return TodoDuplicateKey(r.Todo.ListUUID, r.Todo.Title, r.Todo.DueDate)
+}
+
+// TodoDuplicateKey is the key TodoImportRow.DuplicateKey compares existing todos by.
+func TodoDuplicateKey(listUUID string, title string, dueDate time.Time) string {
+	due := ""
+	if !dueDate.IsZero() {
+		due = dueDate.UTC().Format(DateLayout)
+	}
+
+	return listUUID + "\x00" + strings.ToLower(strings.TrimSpace(title)) + "\x00" + due
+}
+
+// TodoImportError is a row that can't be imported, the rest of the import goes on.
+type TodoImportError struct {
+	Line    int
+	Message string
+}
+
+func (e *TodoImportError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// TodoImport holds the options of an import.
+type TodoImport struct {
+	Format  TodoFormat
+	Mapping TodoMapping
+	// DryRun only reports what the import would create.
+	DryRun bool
+}
+
+type TodoImportResult struct {
+	DryRun bool
+	// Created are the todos the import created, or would create on a dry run.
+	Created []*TodoImportRecord
+	// Duplicates already exist or appear earlier in the file, they are skipped.
+	Duplicates []*TodoImportRecord
+	Errors     []*TodoImportError
+}
+
+type TodoImportRecord struct {
+	Line int
+	// UUID is empty on a dry run.
+	UUID     string
+	ListUUID string
+	Title    string
+	DueDate  time.Time
+}
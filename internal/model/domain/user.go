@@ -2,26 +2,43 @@ package domain
 
 import (
 	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
 	"time"
 )
 
 var (
-	ErrNoCredentialsProvided = errors.New("no credentials provided")
-	ErrUserAlreadyExists     = errors.New("user already exists")
-	ErrUserNotFound          = errors.New("user not found")
-	ErrInvalidCredentials    = errors.New("invalid credentials")
+	ErrNoCredentialsProvided = NewError(KindUnauthorized, "no credentials provided")
+	ErrUserAlreadyExists     = NewError(KindConflict, "user already exists")
+	ErrUserNotFound          = NewError(KindNotFound, "user not found")
+	ErrInvalidCredentials    = NewError(KindUnauthorized, "invalid credentials")
 	ErrJWTGeneration         = errors.New("error generating jwt token")
-	ErrUnauthorized          = errors.Join(ErrInvalidCredentials, ErrNoCredentialsProvided, ErrUserNotFound)
+	ErrUnknownRegion         = NewError(KindValidation, "unknown data region")
+	// ErrDeletionNotScheduled is returned when cancelling the deletion of an account that isn't
+	// scheduled for deletion.
+	ErrDeletionNotScheduled = NewError(KindNotFound, "account deletion is not scheduled")
+	ErrChildAccountDeletion = NewError(KindForbidden, "child accounts are deleted by their parent")
+	ErrInvalidTimezone      = NewError(KindValidation, "unknown timezone, expected an IANA name like Europe/Berlin")
+	ErrInvalidLocale        = NewError(KindValidation, "invalid locale, expected a language tag like en-US")
+	ErrInvalidAvatarURL     = NewError(KindValidation, "invalid avatar URL, expected an http or https URL")
+	ErrUnauthorized         = errors.Join(ErrInvalidCredentials, ErrNoCredentialsProvided, ErrUserNotFound)
 )
 
 type UserSignup struct {
 	Email    string
 	Password string
+	// Region is the data region the account is stored in, empty for the home region.
+	Region string
 }
 
 type UserCredentials struct {
 	Email    string
+	Username string
 	Password string
+	// Client is nil when nothing is known about the client, its logins aren't checked for new
+	// devices then.
+	Client *LoginClient
 }
 
 type User struct {
@@ -31,6 +48,92 @@ type User struct {
 	Password  string
 	FirstName string
 	LastName  string
+	// Timezone is the IANA name of the timezone of the user, reminders and due dates are read
+	// in it. Locale is a language tag like en-US, empty when the user didn't pick one.
+	Timezone  string
+	Locale    string
+	AvatarURL string
 	CreatedAt time.Time
 	DeletedAt time.Time
+	// PurgeAt is set while the account is scheduled for deletion, the account and its data are
+	// purged after it.
+	PurgeAt time.Time
+
+	// ParentID and Username are only set for child accounts.
+	ParentID uint
+	Username string
+	Controls ParentalControls
+}
+
+// IsChild reports whether the account is a child account managed by a parent.
+func (u *User) IsChild() bool {
+	return u.ParentID != 0
+}
+
+// Location returns the timezone of the user, UTC when it is unknown.
+func (u *User) Location() *time.Location {
+	if u.Timezone == "" {
+		return time.UTC
+	}
+	location, err := time.LoadLocation(u.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return location
+}
+
+var localePattern = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+
+// UserProfileUpdate holds the profile fields to change, nil fields are left untouched and empty
+// strings clear a field. A cleared timezone is UTC.
+type UserProfileUpdate struct {
+	FirstName *string
+	LastName  *string
+	Timezone  *string
+	Locale    *string
+	AvatarURL *string
+}
+
+func (u *UserProfileUpdate) Validate() error {
+	if u.Timezone != nil && *u.Timezone != "" {
+		// time.LoadLocation also accepts Local, which is the timezone of the server
+		if _, err := time.LoadLocation(*u.Timezone); err != nil || *u.Timezone == "Local" {
+			return fmt.Errorf("%q: %w", *u.Timezone, ErrInvalidTimezone)
+		}
+	}
+	if u.Locale != nil && *u.Locale != "" && !localePattern.MatchString(*u.Locale) {
+		return fmt.Errorf("%q: %w", *u.Locale, ErrInvalidLocale)
+	}
+	if u.AvatarURL != nil && *u.AvatarURL != "" {
+		// the avatar is shown by the apps, other schemes like javascript: must not get there
+		parsed, err := url.Parse(*u.AvatarURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return ErrInvalidAvatarURL
+		}
+	}
+
+	return nil
+}
+
+// Apply changes the fields of the user that are set.
+func (u *UserProfileUpdate) Apply(user *User) {
+	if u.FirstName != nil {
+		user.FirstName = *u.FirstName
+	}
+	if u.LastName != nil {
+		user.LastName = *u.LastName
+	}
+	if u.Timezone != nil {
+		user.Timezone = *u.Timezone
+		if user.Timezone == "" {
+			user.Timezone = "UTC"
+		}
+	}
+	if u.Locale != nil {
+		user.Locale = *u.Locale
+	}
+	if u.AvatarURL != nil {
+		user.AvatarURL = *u.AvatarURL
+	}
 }
@@ -0,0 +1,28 @@
+package domain
+
+var (
+	ErrUnknownVoiceIntent = NewError(KindValidation, "unknown voice intent")
+)
+
+type VoiceIntent string
+
+const (
+	VoiceIntentAddItem      VoiceIntent = "add_item"
+	VoiceIntentListToday    VoiceIntent = "list_today"
+	VoiceIntentCompleteItem VoiceIntent = "complete_item"
+)
+
+// VoiceRequest is a resolved intent of a smart speaker skill, Item holds the spoken item name
+// for the add and complete intents.
+type VoiceRequest struct {
+	Intent VoiceIntent
+	Item   string
+	// ClientID is the OAuth client of the assistant, it is the channel of captured todos.
+	ClientID string
+}
+
+// VoiceResponse holds the sentence the speaker reads out and the todos it is about.
+type VoiceResponse struct {
+	Speech string
+	Todos  []*Todo
+}
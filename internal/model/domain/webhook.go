@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+const (
+	// WebhookSecretPrefix makes webhook secrets recognizable, e.g. in leaked credential scans.
+	WebhookSecretPrefix = "whsec_"
+	// WebhookMaxAttempts is how often a delivery is tried before it is given up on.
+	WebhookMaxAttempts = 8
+	// WebhookRetryBase is the delay before the first retry, every retry doubles it.
+	WebhookRetryBase = 30 * time.Second
+	// WebhookRetryMax caps the delay between retries.
+	WebhookRetryMax = 6 * time.Hour
+)
+
+var (
+	ErrWebhookNotFound   = NewError(KindNotFound, "webhook not found")
+	ErrInvalidWebhookURL = NewError(KindValidation, "webhook url must be an absolute http or https url")
+)
+
+// Webhook posts signed JSON payloads to URL when one of its events happens to a todo of the user.
+type Webhook struct {
+	ID     uint
+	UUID   string
+	UserID uint
+	URL    string
+	// Secret signs the payloads, it is only shown when the webhook is created.
+	Secret    string
+	Events    []EventType
+	CreatedAt time.Time
+}
+
+func (w *Webhook) Subscribed(event EventType) bool {
+	return slices.Contains(w.Events, event)
+}
+
+type WebhookCreate struct {
+	URL    string
+	Events []EventType
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is an event queued for a webhook, the payload is rendered when the event
+// happens so retries send the same body.
+type WebhookDelivery struct {
+	ID   uint
+	UUID string
+	// Webhook only has the fields needed to send the delivery.
+	Webhook        *Webhook
+	Event          EventType
+	Payload        []byte
+	Status         WebhookDeliveryStatus
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastStatusCode int
+	LastError      string
+	DeliveredAt    time.Time
+	CreatedAt      time.Time
+}
+
+// WebhookBackoff returns the delay before the next attempt after the given number of failed
+// attempts, it doubles with every attempt up to WebhookRetryMax.
+func WebhookBackoff(attempts int) time.Duration {
+	delay := WebhookRetryBase
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= WebhookRetryMax {
+			return WebhookRetryMax
+		}
+	}
+
+	return delay
+}
@@ -0,0 +1,189 @@
+package domain
+
+import (
+	"regexp"
+	"time"
+)
+
+var (
+	ErrWorkspaceNotFound      = NewError(KindNotFound, "workspace not found")
+	ErrWorkspaceOwnerMismatch = NewError(KindConflict, "workspace belongs to another owner")
+	ErrWorkspaceOwnerTaken    = NewError(KindConflict, "owner already has a workspace")
+	ErrInvalidWorkspaceSlug   = NewError(KindValidation, "workspace slugs are 1 to 64 lowercase letters, digits and dashes")
+	ErrPasswordRequired       = NewError(KindValidation, "password is required to create the account")
+	ErrQuotaExceeded          = NewError(KindForbidden, "workspace quota exceeded")
+	ErrUsageLimitExceeded     = NewError(KindRateLimited, "monthly usage limit of the workspace exceeded")
+)
+
+//nolint:gochecknoglobals // compiled once
+var workspaceSlug = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,63}$`)
+
+// ValidWorkspaceSlug reports whether slug can identify a workspace.
+func ValidWorkspaceSlug(slug string) bool {
+	return workspaceSlug.MatchString(slug)
+}
+
+// QuotaResource is a resource capped by the quotas of a workspace.
+type QuotaResource string
+
+const (
+	// QuotaMembers are the child accounts of the household.
+	QuotaMembers QuotaResource = "members"
+	// QuotaLists are the lists of the owner and the child accounts.
+	QuotaLists QuotaResource = "lists"
+
+	// The metered resources are capped per month.
+
+	// QuotaWebhookDeliveries are the attempts to deliver a webhook.
+	QuotaWebhookDeliveries QuotaResource = "webhook_deliveries"
+	// QuotaAPICalls are the requests to the API of the household members.
+	QuotaAPICalls QuotaResource = "api_calls"
+	// QuotaIntegrationSyncs are the requests of linked integrations, e.g. smart speakers.
+	QuotaIntegrationSyncs QuotaResource = "integration_syncs"
+)
+
+//nolint:gochecknoglobals // lookup table
+var QuotaResources = []QuotaResource{
+	QuotaMembers, QuotaLists, QuotaWebhookDeliveries, QuotaAPICalls, QuotaIntegrationSyncs,
+}
+
+// Metered reports whether the usage of resource is counted per month rather than counted
+// from what the household has.
+func (r QuotaResource) Metered() bool {
+	return r == QuotaWebhookDeliveries || r == QuotaAPICalls || r == QuotaIntegrationSyncs
+}
+
+// Quotas cap the resources of a workspace, 0 is unlimited.
+type Quotas struct {
+	MaxMembers int
+	MaxLists   int
+	// monthly caps, 0 falls back to the cap of the plan
+	MaxWebhookDeliveries int
+	MaxAPICalls          int
+	MaxIntegrationSyncs  int
+}
+
+// Limit returns the cap of resource, 0 when it is unlimited.
+func (q Quotas) Limit(resource QuotaResource) int {
+	switch resource {
+	case QuotaMembers:
+		return q.MaxMembers
+	case QuotaLists:
+		return q.MaxLists
+	case QuotaWebhookDeliveries:
+		return q.MaxWebhookDeliveries
+	case QuotaAPICalls:
+		return q.MaxAPICalls
+	case QuotaIntegrationSyncs:
+		return q.MaxIntegrationSyncs
+	}
+
+	return 0
+}
+
+// planQuotas are the monthly caps of the metered resources per plan. Workspaces on other plans
+// are unlimited unless their own quotas cap them.
+//
+//nolint:gochecknoglobals // lookup table
+var planQuotas = map[string]Quotas{
+	"team": {
+		MaxWebhookDeliveries: 10_000,
+		MaxAPICalls:          100_000,
+		MaxIntegrationSyncs:  5_000,
+	},
+	"business": {
+		MaxWebhookDeliveries: 100_000,
+		MaxAPICalls:          1_000_000,
+		MaxIntegrationSyncs:  50_000,
+	},
+}
+
+// UsageWarningPercent is the share of a monthly cap at which the owner is warned, the cap is
+// only enforced once it is used up.
+const UsageWarningPercent = 80
+
+// UsageWarning reports whether used reached the warning share of limit.
+func UsageWarning(used int, limit int) bool {
+	return limit > 0 && used*100 >= limit*UsageWarningPercent
+}
+
+// UsagePeriod returns the calendar month in UTC that t falls in, usage is counted per month.
+func UsagePeriod(t time.Time) (time.Time, time.Time) {
+	t = t.UTC()
+	start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	return start, start.AddDate(0, 1, 0)
+}
+
+// Workspace is a household provisioned by an enterprise deployment, identified by a slug the
+// provisioning tool picks. The owner is the parent account of the household.
+type Workspace struct {
+	ID        uint
+	Slug      string
+	Name      string
+	OwnerUUID string
+	Region    string
+	Plan      string
+	Quotas    Quotas
+	SSO       *SSOConfig
+	Hours     *WorkingHours
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Limit returns the cap of resource, the quotas of the workspace win over its plan.
+func (w *Workspace) Limit(resource QuotaResource) int {
+	if limit := w.Quotas.Limit(resource); limit > 0 || !resource.Metered() {
+		return limit
+	}
+
+	return planQuotas[w.Plan].Limit(resource)
+}
+
+// ResourceUsage is the usage of a resource of a workspace, Limit 0 is unlimited.
+type ResourceUsage struct {
+	Resource QuotaResource
+	Limit    int
+	Used     int
+	// Warning is set once the usage reached UsageWarningPercent of the limit.
+	Warning bool
+}
+
+// WorkspaceLimits are the caps and the usage of a workspace, metered resources in the month of
+// PeriodStart.
+type WorkspaceLimits struct {
+	Slug        string
+	Plan        string
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	Resources   []*ResourceUsage
+}
+
+// WorkspaceProvision creates or updates a workspace, the owner account is created with
+// OwnerPassword unless it exists already.
+type WorkspaceProvision struct {
+	Name          string
+	Plan          string
+	OwnerEmail    string
+	OwnerPassword string
+	// Region is the data region of a new owner account, empty for the home region.
+	Region string
+}
+
+// SSOConfig is the OpenID Connect provider of a workspace.
+type SSOConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	// Domains are the email domains the provider signs in.
+	Domains   []string
+	UpdatedAt time.Time
+}
+
+// AdminProvision seeds a staff account with admin scopes.
+type AdminProvision struct {
+	Email    string
+	Password string
+	Region   string
+	Scopes   []AdminScope
+}
@@ -0,0 +1,8 @@
+package endpoint
+
+import "time"
+
+// AccountDeletion is a scheduled account deletion, it can be cancelled until PurgeAt.
+type AccountDeletion struct {
+	PurgeAt time.Time `json:"purge_at"`
+}
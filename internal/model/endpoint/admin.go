@@ -0,0 +1,30 @@
+package endpoint
+
+import "github.com/meowmix1337/the_recipe_book/internal/model/domain"
+
+type AdminScopesRequest struct {
+	Scopes []string `json:"scopes" validate:"dive,oneof=support billing superadmin"`
+}
+
+func (r *AdminScopesRequest) ToDomain() []domain.AdminScope {
+	scopes := make([]domain.AdminScope, 0, len(r.Scopes))
+	for _, scope := range r.Scopes {
+		scopes = append(scopes, domain.AdminScope(scope))
+	}
+
+	return scopes
+}
+
+type AdminScopes struct {
+	Scopes []domain.AdminScope `json:"scopes"`
+}
+
+func NewAdminScopes(scopes []domain.AdminScope) *AdminScopes {
+	if scopes == nil {
+		scopes = []domain.AdminScope{}
+	}
+
+	return &AdminScopes{
+		Scopes: scopes,
+	}
+}
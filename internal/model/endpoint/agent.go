@@ -0,0 +1,268 @@
+package endpoint
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type AgentToken struct {
+	UUID       string     `json:"uuid"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	Token      string     `json:"token,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func NewAgentToken(token *domain.AgentToken) *AgentToken {
+	scopes := make([]string, 0, len(token.Scopes))
+	for _, scope := range token.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+
+	return &AgentToken{
+		UUID:       token.UUID,
+		Name:       token.Name,
+		Scopes:     scopes,
+		Token:      token.Token,
+		ExpiresAt:  timeOrNil(token.ExpiresAt),
+		LastUsedAt: timeOrNil(token.LastUsedAt),
+		CreatedAt:  token.CreatedAt,
+	}
+}
+
+func NewAgentTokens(tokens []*domain.AgentToken) []*AgentToken {
+	resp := make([]*AgentToken, 0, len(tokens))
+	for _, token := range tokens {
+		resp = append(resp, NewAgentToken(token))
+	}
+
+	return resp
+}
+
+type AgentTokenRequest struct {
+	// Name is the channel of the todos the assistant creates, rules can route them by it.
+	Name      string     `json:"name" validate:"required,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,oneof=todos:read todos:create todos:complete"`
+	ExpiresAt *time.Time `json:"expires_at" validate:"omitempty,future"`
+}
+
+func (r *AgentTokenRequest) ToDomain() *domain.AgentTokenCreate {
+	tokenCreate := &domain.AgentTokenCreate{
+		Name: r.Name,
+	}
+	for _, value := range r.Scopes {
+		scope, err := domain.ParseAgentScope(value)
+		if err == nil && !slices.Contains(tokenCreate.Scopes, scope) {
+			tokenCreate.Scopes = append(tokenCreate.Scopes, scope)
+		}
+	}
+	if r.ExpiresAt != nil {
+		tokenCreate.ExpiresAt = r.ExpiresAt.UTC()
+	}
+
+	return tokenCreate
+}
+
+// AgentTool describes an agent route for assistants. The arguments described by InputSchema
+// are the query parameters of GET routes and the body of the others, path parameters included.
+type AgentTool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Scope       string          `json:"scope"`
+	Method      string          `json:"method"`
+	Path        string          `json:"path"`
+	InputSchema json.RawMessage `json:"input_schema"`
+	// ReadOnly, Destructive and Idempotent hint how careful an assistant has to be.
+	ReadOnly    bool `json:"read_only"`
+	Destructive bool `json:"destructive"`
+	Idempotent  bool `json:"idempotent"`
+}
+
+const (
+	AgentToolQueryTodos   = "query_todos"
+	AgentToolCreateTodo   = "create_todo"
+	AgentToolCompleteTodo = "complete_todo"
+)
+
+// AgentTools are the tools of the agent routes, in the order they are listed.
+var AgentTools = []*AgentTool{
+	{
+		Name: AgentToolQueryTodos,
+		Description: "Find todos of the user. Open todos are returned by default, soonest due first. " +
+			"query searches titles, descriptions and attachments, results are ranked by relevance then.",
+		Scope:  string(domain.AgentScopeRead),
+		Method: "GET",
+		Path:   "/agent/v1/todos",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"query": {"type": "string", "maxLength": 255, "description": "Words to search for"},
+				"list_uuid": {"type": "string", "description": "Only todos of this list"},
+				"status": {"type": "string", "enum": ["open", "completed", "all"], "default": "open"},
+				"due_before": {"type": "string", "format": "date-time", "description": "Only todos due before this time"},
+				"limit": {"type": "integer", "minimum": 1, "maximum": 50, "default": 10}
+			},
+			"additionalProperties": false
+		}`),
+		ReadOnly:   true,
+		Idempotent: true,
+	},
+	{
+		Name: AgentToolCreateTodo,
+		Description: "Create a todo for the user. Send a new idempotency_key with every todo and reuse it " +
+			"when retrying, so a retry never creates the todo twice.",
+		Scope:  string(domain.AgentScopeCreate),
+		Method: "POST",
+		Path:   "/agent/v1/todos",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"title": {"type": "string", "minLength": 1, "maxLength": 255},
+				"description": {"type": "string"},
+				"list_uuid": {"type": "string", "description": "List to add the todo to, none by default"},
+				"priority": {"type": "string", "enum": ["low", "medium", "high", "urgent"], "default": "medium"},
+				"due_date": {"type": "string", "format": "date-time", "description": "Must be in the future"},
+				"tags": {"type": "array", "items": {"type": "string", "maxLength": 64}},
+				"idempotency_key": {"type": "string", "maxLength": 255}
+			},
+			"required": ["title"],
+			"additionalProperties": false
+		}`),
+		Idempotent: true,
+	},
+	{
+		Name: AgentToolCompleteTodo,
+		Description: "Mark a todo of the user as done, in two steps. Call it without a confirmation first: " +
+			"nothing changes and you get a summary and a confirmation. Show the summary to the user and call " +
+			"it again with the confirmation only once they agreed. Confirmations expire after 10 minutes " +
+			"or when the todo changes.",
+		Scope:  string(domain.AgentScopeComplete),
+		Method: "POST",
+		Path:   "/agent/v1/todos/{uuid}/complete",
+		InputSchema: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"uuid": {"type": "string", "description": "UUID of the todo"},
+				"confirmation": {"type": "string", "description": "Confirmation of the first call, after the user agreed"}
+			},
+			"required": ["uuid"],
+			"additionalProperties": false
+		}`),
+		Destructive: true,
+	},
+}
+
+// NewAgentTools returns the tools the scopes of token allow.
+func NewAgentTools(token *domain.AgentToken) []*AgentTool {
+	tools := make([]*AgentTool, 0, len(AgentTools))
+	for _, tool := range AgentTools {
+		if token.Allows(domain.AgentScope(tool.Scope)) {
+			tools = append(tools, tool)
+		}
+	}
+
+	return tools
+}
+
+// AgentTodoQueryRequest are the arguments of query_todos.
+type AgentTodoQueryRequest struct {
+	Query     string     `json:"query" validate:"max=255"`
+	ListUUID  string     `json:"list_uuid"`
+	Status    string     `json:"status" validate:"omitempty,oneof=open completed all"`
+	DueBefore *time.Time `json:"due_before"`
+	Limit     int        `json:"limit" validate:"min=0,max=50"`
+}
+
+func (r *AgentTodoQueryRequest) ToDomain() *domain.AgentTodoQuery {
+	query := &domain.AgentTodoQuery{
+		Text:      r.Query,
+		ListUUID:  r.ListUUID,
+		Completed: domain.CompletedExclude,
+		Limit:     r.Limit,
+	}
+	switch r.Status {
+	case "completed":
+		query.Completed = domain.CompletedOnly
+	case "all":
+		query.Completed = domain.CompletedInclude
+	}
+	if r.DueBefore != nil {
+		query.DueBefore = r.DueBefore.UTC()
+	}
+
+	return query
+}
+
+// AgentTodoCreateRequest are the arguments of create_todo, on the agent routes the
+// Idempotency-Key header takes precedence over IdempotencyKey.
+type AgentTodoCreateRequest struct {
+	ListUUID       string     `json:"list_uuid"`
+	Title          string     `json:"title" validate:"required,max=255"`
+	Description    string     `json:"description"`
+	Priority       string     `json:"priority" validate:"omitempty,oneof=low medium high urgent"`
+	DueDate        *time.Time `json:"due_date" validate:"omitempty,future"`
+	Tags           []string   `json:"tags" validate:"dive,max=64,tag_name"`
+	IdempotencyKey string     `json:"idempotency_key" validate:"max=255"`
+}
+
+func (r *AgentTodoCreateRequest) ToDomain() *domain.TodoCreate {
+	todo := &domain.TodoCreate{
+		ListUUID:    r.ListUUID,
+		Title:       r.Title,
+		Description: r.Description,
+		Priority:    domain.PriorityMedium,
+		Tags:        r.Tags,
+	}
+	if priority, err := domain.ParsePriority(r.Priority); err == nil {
+		todo.Priority = priority
+	}
+	if r.DueDate != nil {
+		todo.DueDate = *r.DueDate
+	}
+
+	return todo
+}
+
+// AgentTodoCompleteRequest are the arguments of complete_todo, on the agent routes UUID is
+// taken from the path.
+type AgentTodoCompleteRequest struct {
+	UUID         string `json:"uuid" validate:"required"`
+	Confirmation string `json:"confirmation" validate:"max=255"`
+}
+
+type AgentTodoCreated struct {
+	Todo *Todo `json:"todo"`
+	// Replayed is set when the idempotency key already created the todo before.
+	Replayed bool `json:"replayed"`
+}
+
+func NewAgentTodoCreated(created *domain.AgentTodoCreated) *AgentTodoCreated {
+	return &AgentTodoCreated{
+		Todo:     NewTodo(created.Todo),
+		Replayed: created.Replayed,
+	}
+}
+
+type AgentCompletion struct {
+	Todo      *Todo  `json:"todo"`
+	Completed bool   `json:"completed"`
+	Summary   string `json:"summary"`
+	// Confirmation is only set until the user confirmed.
+	Confirmation          string     `json:"confirmation,omitempty"`
+	ConfirmationExpiresAt *time.Time `json:"confirmation_expires_at,omitempty"`
+}
+
+func NewAgentCompletion(completion *domain.AgentCompletion) *AgentCompletion {
+	return &AgentCompletion{
+		Todo:                  NewTodo(completion.Todo),
+		Completed:             completion.Completed,
+		Summary:               completion.Summary,
+		Confirmation:          completion.Confirmation,
+		ConfirmationExpiresAt: timeOrNil(completion.ConfirmationExpiresAt),
+	}
+}
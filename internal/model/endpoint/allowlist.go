@@ -0,0 +1,52 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type IPRange struct {
+	UUID      string    `json:"uuid"`
+	CIDR      string    `json:"cidr"`
+	Label     string    `json:"label"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewIPRange(ipRange *domain.IPRange) *IPRange {
+	return &IPRange{
+		UUID:      ipRange.UUID,
+		CIDR:      ipRange.CIDR,
+		Label:     ipRange.Label,
+		CreatedAt: ipRange.CreatedAt,
+	}
+}
+
+func NewIPRanges(ranges []*domain.IPRange) []*IPRange {
+	resp := make([]*IPRange, 0, len(ranges))
+	for _, ipRange := range ranges {
+		resp = append(resp, NewIPRange(ipRange))
+	}
+
+	return resp
+}
+
+type IPRangeCreateRequest struct {
+	CIDR  string `json:"cidr" validate:"required,max=64"`
+	Label string `json:"label" validate:"max=255"`
+}
+
+func (r *IPRangeCreateRequest) ToDomain() *domain.IPRangeCreate {
+	return &domain.IPRangeCreate{
+		CIDR:  r.CIDR,
+		Label: r.Label,
+	}
+}
+
+type IPAllowlistBypassRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type IPAllowlistBypass struct {
+	SuspendedUntil time.Time `json:"suspended_until"`
+}
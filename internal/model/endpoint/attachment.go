@@ -0,0 +1,50 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type Attachment struct {
+	UUID        string    `json:"uuid"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+	// ExtractionStatus tells whether the attachment is searchable by its content yet.
+	ExtractionStatus domain.ExtractionStatus `json:"extraction_status"`
+}
+
+func NewAttachment(attachment *domain.Attachment) *Attachment {
+	return &Attachment{
+		UUID:        attachment.UUID,
+		Filename:    attachment.Filename,
+		ContentType: attachment.ContentType,
+		Size:        attachment.Size,
+		CreatedAt:   attachment.CreatedAt,
+
+		ExtractionStatus: attachment.ExtractionStatus,
+	}
+}
+
+func NewAttachments(attachments []*domain.Attachment) []*Attachment {
+	resp := make([]*Attachment, 0, len(attachments))
+	for _, attachment := range attachments {
+		resp = append(resp, NewAttachment(attachment))
+	}
+
+	return resp
+}
+
+type AttachmentDownload struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func NewAttachmentDownload(download *domain.AttachmentDownload) *AttachmentDownload {
+	return &AttachmentDownload{
+		URL:       download.URL,
+		ExpiresAt: download.ExpiresAt,
+	}
+}
@@ -0,0 +1,57 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type AuditEntry struct {
+	Action    string    `json:"action"`
+	Path      string    `json:"path"`
+	Status    int       `json:"status"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	RequestID string    `json:"request_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewAuditEntries(entries []*domain.AuditEntry) []*AuditEntry {
+	resp := make([]*AuditEntry, 0, len(entries))
+	for _, entry := range entries {
+		resp = append(resp, &AuditEntry{
+			Action:    entry.Action,
+			Path:      entry.Path,
+			Status:    entry.Status,
+			IP:        entry.IP,
+			UserAgent: entry.UserAgent,
+			RequestID: entry.RequestID,
+			CreatedAt: entry.CreatedAt,
+		})
+	}
+
+	return resp
+}
+
+type AuditVerification struct {
+	Valid     bool `json:"valid"`
+	Checked   int  `json:"checked"`
+	Unchained int  `json:"unchained"`
+	// Anonymized counts the entries of purged accounts, only their place in the chain is checked.
+	Anonymized int    `json:"anonymized"`
+	Head       string `json:"head"`
+	BrokenID   uint   `json:"broken_id,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+func NewAuditVerification(verification *domain.AuditVerification) *AuditVerification {
+	return &AuditVerification{
+		Valid:      verification.Valid,
+		Checked:    verification.Checked,
+		Unchained:  verification.Unchained,
+		Anonymized: verification.Anonymized,
+		Head:       verification.Head,
+		BrokenID:   verification.BrokenID,
+		Reason:     verification.Reason,
+	}
+}
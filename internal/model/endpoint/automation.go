@@ -0,0 +1,91 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type Automation struct {
+	UUID      string    `json:"uuid"`
+	Name      string    `json:"name"`
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewAutomation(automation *domain.Automation) *Automation {
+	events := make([]string, 0, len(automation.Events))
+	for _, event := range automation.Events {
+		events = append(events, string(event))
+	}
+
+	return &Automation{
+		UUID:      automation.UUID,
+		Name:      automation.Name,
+		Events:    events,
+		CreatedAt: automation.CreatedAt,
+	}
+}
+
+func NewAutomations(automations []*domain.Automation) []*Automation {
+	resp := make([]*Automation, 0, len(automations))
+	for _, automation := range automations {
+		resp = append(resp, NewAutomation(automation))
+	}
+
+	return resp
+}
+
+type AutomationRequest struct {
+	Name   string   `json:"name" validate:"required,max=100"`
+	Events []string `json:"events" validate:"required,min=1,dive,oneof=todo.created todo.updated todo.completed todo.deleted todo.moved"`
+	// Module is the WebAssembly binary, base64 encoded.
+	Module []byte `json:"module" validate:"required"`
+}
+
+func (r *AutomationRequest) ToDomain() *domain.AutomationCreate {
+	automationCreate := &domain.AutomationCreate{
+		Name:   r.Name,
+		Module: r.Module,
+	}
+	for _, event := range r.Events {
+		if eventType, err := domain.ParseEventType(event); err == nil {
+			automationCreate.Events = append(automationCreate.Events, eventType)
+		}
+	}
+
+	return automationCreate
+}
+
+type AutomationRun struct {
+	UUID       string     `json:"uuid"`
+	Event      string     `json:"event"`
+	Status     string     `json:"status"`
+	FuelUsed   uint64     `json:"fuel_used"`
+	Log        string     `json:"log,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	FinishedAt *time.Time `json:"finished_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func NewAutomationRun(run *domain.AutomationRun) *AutomationRun {
+	return &AutomationRun{
+		UUID:       run.UUID,
+		Event:      string(run.Event),
+		Status:     string(run.Status),
+		FuelUsed:   run.FuelUsed,
+		Log:        run.Log,
+		Error:      run.Error,
+		FinishedAt: timeOrNil(run.FinishedAt),
+		CreatedAt:  run.CreatedAt,
+	}
+}
+
+func NewAutomationRuns(runs []*domain.AutomationRun) []*AutomationRun {
+	resp := make([]*AutomationRun, 0, len(runs))
+	for _, run := range runs {
+		resp = append(resp, NewAutomationRun(run))
+	}
+
+	return resp
+}
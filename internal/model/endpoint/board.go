@@ -0,0 +1,113 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type ListStatus struct {
+	Key      string `json:"key"`
+	Name     string `json:"name"`
+	Position int    `json:"position"`
+	// Done statuses complete the todos moved into them.
+	Done      bool       `json:"done"`
+	CreatedAt *time.Time `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+func NewListStatus(status *domain.ListStatus) *ListStatus {
+	return &ListStatus{
+		Key:       status.Key,
+		Name:      status.Name,
+		Position:  status.Position,
+		Done:      status.Done,
+		CreatedAt: timeOrNil(status.CreatedAt),
+		UpdatedAt: timeOrNil(status.UpdatedAt),
+	}
+}
+
+func NewListStatuses(statuses []*domain.ListStatus) []*ListStatus {
+	resp := make([]*ListStatus, 0, len(statuses))
+	for _, status := range statuses {
+		resp = append(resp, NewListStatus(status))
+	}
+
+	return resp
+}
+
+// ListStatusRequest adds a status to the board of a list, at the end unless position is set.
+type ListStatusRequest struct {
+	Key      string `json:"key" validate:"required,max=32"`
+	Name     string `json:"name" validate:"required,max=64"`
+	Done     bool   `json:"done"`
+	Position int    `json:"position" validate:"omitempty,min=1"`
+}
+
+func (r *ListStatusRequest) ToDomain() *domain.ListStatusCreate {
+	return &domain.ListStatusCreate{
+		Key:      r.Key,
+		Name:     r.Name,
+		Done:     r.Done,
+		Position: r.Position,
+	}
+}
+
+type ListStatusUpdateRequest struct {
+	Name     *string `json:"name" validate:"omitempty,min=1,max=64"`
+	Done     *bool   `json:"done"`
+	Position *int    `json:"position" validate:"omitempty,min=1"`
+}
+
+func (r *ListStatusUpdateRequest) ToDomain() *domain.ListStatusUpdate {
+	return &domain.ListStatusUpdate{
+		Name:     r.Name,
+		Done:     r.Done,
+		Position: r.Position,
+	}
+}
+
+type Board struct {
+	List    *List          `json:"list"`
+	Columns []*BoardColumn `json:"columns"`
+}
+
+type BoardColumn struct {
+	Status *ListStatus `json:"status"`
+	Todos  []*Todo     `json:"todos"`
+}
+
+func NewBoard(board *domain.Board) *Board {
+	resp := &Board{
+		List:    NewList(board.List),
+		Columns: make([]*BoardColumn, 0, len(board.Columns)),
+	}
+	for _, column := range board.Columns {
+		resp.Columns = append(resp.Columns, &BoardColumn{
+			Status: NewListStatus(column.Status),
+			Todos:  NewTodos(column.Todos),
+		})
+	}
+
+	return resp
+}
+
+// TodoPlaceRequest moves a todo on the board of its list to status, at position from 1 on or at
+// the end of the column.
+type TodoPlaceRequest struct {
+	Status   string `json:"status" validate:"required,max=32"`
+	Position int    `json:"position" validate:"omitempty,min=1"`
+	// Version is the version the move is based on, the If-Match header takes precedence.
+	Version *int `json:"version" validate:"omitempty,min=1"`
+	// Force moves the todo into a done status even though open todos block it.
+	Force bool `json:"force"`
+}
+
+func (t *TodoPlaceRequest) ToDomain() *domain.TodoPlacement {
+	return &domain.TodoPlacement{
+		Status:   t.Status,
+		Position: t.Position,
+		Version:  t.Version,
+		Force:    t.Force,
+	}
+}
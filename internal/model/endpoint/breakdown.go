@@ -0,0 +1,65 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type TodoBreakdown struct {
+	Todo  *Todo            `json:"todo"`
+	Steps []*BreakdownStep `json:"steps"`
+	// Estimate is the estimate of all steps.
+	Estimate int `json:"estimate_minutes"`
+}
+
+func NewTodoBreakdown(breakdown *domain.TodoBreakdown) *TodoBreakdown {
+	steps := make([]*BreakdownStep, 0, len(breakdown.Steps))
+	for _, step := range breakdown.Steps {
+		steps = append(steps, &BreakdownStep{
+			Title:    step.Title,
+			Estimate: int(step.Estimate.Minutes()),
+		})
+	}
+
+	return &TodoBreakdown{
+		Todo:     NewTodo(breakdown.Todo),
+		Steps:    steps,
+		Estimate: int(breakdown.Estimate().Minutes()),
+	}
+}
+
+type BreakdownStep struct {
+	Title    string `json:"title" validate:"required,max=255"`
+	Estimate int    `json:"estimate_minutes" validate:"min=0,max=1440"`
+}
+
+// TodoBreakdownRequest confirms the steps of a breakdown, usually the proposed steps after the
+// user removed and edited some. They are created like the items of a split.
+type TodoBreakdownRequest struct {
+	Steps []*BreakdownStep `json:"steps" validate:"required,min=1,max=100,dive,required"`
+	// ListUUID puts the new todos in another list, by default they go to the list of the todo.
+	ListUUID string `json:"list_uuid"`
+	// NewList creates a list named after the todo for the new todos.
+	NewList bool `json:"new_list"`
+	// Version is the version of the todo that was broken down, the If-Match header takes
+	// precedence. It is optional since the todo itself isn't changed.
+	Version *int `json:"version" validate:"omitempty,min=1"`
+}
+
+func (r *TodoBreakdownRequest) ToDomain() *domain.TodoSplit {
+	split := &domain.TodoSplit{
+		ListUUID: r.ListUUID,
+		NewList:  r.NewList,
+		Version:  r.Version,
+		Items:    make([]domain.ChecklistItem, 0, len(r.Steps)),
+	}
+	for _, step := range r.Steps {
+		split.Items = append(split.Items, domain.ChecklistItem{
+			Title:    step.Title,
+			Estimate: time.Duration(step.Estimate) * time.Minute,
+		})
+	}
+
+	return split
+}
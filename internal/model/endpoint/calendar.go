@@ -0,0 +1,46 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type CalendarToken struct {
+	UUID  string `json:"uuid"`
+	Name  string `json:"name"`
+	Token string `json:"token,omitempty"`
+	// URL is the subscription URL to paste into a calendar app, only set with the token.
+	URL        string     `json:"url,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// NewCalendarToken converts a calendar token, feedURL is the feed the subscription URL points to.
+func NewCalendarToken(token *domain.CalendarToken, feedURL string) *CalendarToken {
+	resp := &CalendarToken{
+		UUID:       token.UUID,
+		Name:       token.Name,
+		Token:      token.Token,
+		LastUsedAt: timeOrNil(token.LastUsedAt),
+		CreatedAt:  token.CreatedAt,
+	}
+	if token.Token != "" {
+		resp.URL = feedURL + "?token=" + token.Token
+	}
+
+	return resp
+}
+
+func NewCalendarTokens(tokens []*domain.CalendarToken) []*CalendarToken {
+	resp := make([]*CalendarToken, 0, len(tokens))
+	for _, token := range tokens {
+		resp = append(resp, NewCalendarToken(token, ""))
+	}
+
+	return resp
+}
+
+type CalendarTokenRequest struct {
+	Name string `json:"name" validate:"required,max=255"`
+}
@@ -0,0 +1,55 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+// Chart is a time series in the shape charting libraries take, e.g. Chart.js or ECharts: the
+// labels of the x axis, and a series of values per label for every line.
+type Chart struct {
+	Granularity string `json:"granularity"`
+	// Labels are the first days of the buckets, as YYYY-MM-DD in UTC.
+	Labels []string       `json:"labels"`
+	Series []*ChartSeries `json:"series"`
+}
+
+type ChartSeries struct {
+	Name string `json:"name"`
+	Data []int  `json:"data"`
+}
+
+func NewBurndownChart(granularity domain.ChartGranularity, points []*domain.BurndownPoint) *Chart {
+	chart := newChart(granularity, len(points), "open", "completed")
+	for _, point := range points {
+		chart.Labels = append(chart.Labels, point.Start.Format(time.DateOnly))
+		chart.Series[0].Data = append(chart.Series[0].Data, point.Open)
+		chart.Series[1].Data = append(chart.Series[1].Data, point.Completed)
+	}
+
+	return chart
+}
+
+func NewTrendChart(granularity domain.ChartGranularity, points []*domain.TrendPoint) *Chart {
+	chart := newChart(granularity, len(points), "created", "completed")
+	for _, point := range points {
+		chart.Labels = append(chart.Labels, point.Start.Format(time.DateOnly))
+		chart.Series[0].Data = append(chart.Series[0].Data, point.Created)
+		chart.Series[1].Data = append(chart.Series[1].Data, point.Completed)
+	}
+
+	return chart
+}
+
+func newChart(granularity domain.ChartGranularity, points int, names ...string) *Chart {
+	chart := &Chart{
+		Granularity: string(granularity),
+		Labels:      make([]string, 0, points),
+	}
+	for _, name := range names {
+		chart.Series = append(chart.Series, &ChartSeries{Name: name, Data: make([]int, 0, points)})
+	}
+
+	return chart
+}
@@ -0,0 +1,48 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type CommentAuthor struct {
+	UUID string `json:"uuid"`
+	Name string `json:"name"`
+}
+
+type Comment struct {
+	UUID      string        `json:"uuid"`
+	Author    CommentAuthor `json:"author"`
+	Body      string        `json:"body"`
+	Edited    bool          `json:"edited"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+func NewComment(comment *domain.Comment) *Comment {
+	return &Comment{
+		UUID: comment.UUID,
+		Author: CommentAuthor{
+			UUID: comment.AuthorUUID,
+			Name: comment.AuthorName,
+		},
+		Body:      comment.Body,
+		Edited:    comment.Edited(),
+		CreatedAt: comment.CreatedAt,
+		UpdatedAt: comment.UpdatedAt,
+	}
+}
+
+func NewComments(comments []*domain.Comment) []*Comment {
+	resp := make([]*Comment, 0, len(comments))
+	for _, comment := range comments {
+		resp = append(resp, NewComment(comment))
+	}
+
+	return resp
+}
+
+type CommentRequest struct {
+	Body string `json:"body" validate:"required,max=10000"`
+}
@@ -0,0 +1,48 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type DigestSettings struct {
+	Enabled bool `json:"enabled"`
+	// Weekday is 0 for Sunday through 6 for Saturday.
+	Weekday    int        `json:"weekday"`
+	Hour       int        `json:"hour"`
+	NextSendAt *time.Time `json:"next_send_at,omitempty"`
+	LastSentAt *time.Time `json:"last_sent_at,omitempty"`
+}
+
+func NewDigestSettings(settings *domain.DigestSettings) *DigestSettings {
+	resp := &DigestSettings{
+		Enabled: settings.Enabled,
+		Weekday: int(settings.Weekday),
+		Hour:    settings.Hour,
+	}
+	if settings.Enabled && !settings.NextSendAt.IsZero() {
+		resp.NextSendAt = &settings.NextSendAt
+	}
+	if !settings.LastSentAt.IsZero() {
+		resp.LastSentAt = &settings.LastSentAt
+	}
+
+	return resp
+}
+
+// DigestSettingsRequest opts in to the weekly digest, it is sent on the weekday (0 for Sunday)
+// at the hour in the timezone of the user.
+type DigestSettingsRequest struct {
+	Enabled bool `json:"enabled"`
+	Weekday *int `json:"weekday" validate:"required,min=0,max=6"`
+	Hour    *int `json:"hour" validate:"required,min=0,max=23"`
+}
+
+func (r *DigestSettingsRequest) ToDomain() *domain.DigestSettingsUpdate {
+	return &domain.DigestSettingsUpdate{
+		Enabled: r.Enabled,
+		Weekday: time.Weekday(*r.Weekday),
+		Hour:    *r.Hour,
+	}
+}
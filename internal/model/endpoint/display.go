@@ -0,0 +1,86 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type DisplayToken struct {
+	UUID       string     `json:"uuid"`
+	Name       string     `json:"name"`
+	ListUUIDs  []string   `json:"list_uuids"`
+	Token      string     `json:"token,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func NewDisplayToken(token *domain.DisplayToken) *DisplayToken {
+	return &DisplayToken{
+		UUID:       token.UUID,
+		Name:       token.Name,
+		ListUUIDs:  token.ListUUIDs,
+		Token:      token.Token,
+		ExpiresAt:  timeOrNil(token.ExpiresAt),
+		LastUsedAt: timeOrNil(token.LastUsedAt),
+		CreatedAt:  token.CreatedAt,
+	}
+}
+
+func NewDisplayTokens(tokens []*domain.DisplayToken) []*DisplayToken {
+	resp := make([]*DisplayToken, 0, len(tokens))
+	for _, token := range tokens {
+		resp = append(resp, NewDisplayToken(token))
+	}
+
+	return resp
+}
+
+type DisplayTokenRequest struct {
+	Name      string     `json:"name" validate:"required,max=255"`
+	ListUUIDs []string   `json:"list_uuids" validate:"required,min=1,max=20,dive,required"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func (r *DisplayTokenRequest) ToDomain() *domain.DisplayTokenCreate {
+	tokenCreate := &domain.DisplayTokenCreate{
+		Name:      r.Name,
+		ListUUIDs: r.ListUUIDs,
+	}
+	if r.ExpiresAt != nil {
+		tokenCreate.ExpiresAt = r.ExpiresAt.UTC()
+	}
+
+	return tokenCreate
+}
+
+type DisplayBoard struct {
+	Lists []*DisplayBoardList `json:"lists"`
+	// RefreshAfter tells the display how many seconds to wait before polling again.
+	RefreshAfter int    `json:"refresh_after"`
+	Version      string `json:"version"`
+}
+
+type DisplayBoardList struct {
+	UUID  string  `json:"uuid"`
+	Name  string  `json:"name"`
+	Todos []*Todo `json:"todos"`
+}
+
+func NewDisplayBoard(board *domain.DisplayBoard) *DisplayBoard {
+	lists := make([]*DisplayBoardList, 0, len(board.Lists))
+	for _, boardList := range board.Lists {
+		lists = append(lists, &DisplayBoardList{
+			UUID:  boardList.List.UUID,
+			Name:  boardList.List.Name,
+			Todos: NewTodos(boardList.Todos),
+		})
+	}
+
+	return &DisplayBoard{
+		Lists:        lists,
+		RefreshAfter: int(domain.DisplayRefreshInterval.Seconds()),
+		Version:      board.Version,
+	}
+}
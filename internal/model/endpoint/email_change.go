@@ -0,0 +1,48 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type EmailChange struct {
+	NewEmail string `json:"new_email"`
+	// CurrentConfirmed and NewConfirmed tell which of the addresses confirmed the change, the
+	// email is changed once both did.
+	CurrentConfirmed bool       `json:"current_confirmed"`
+	NewConfirmed     bool       `json:"new_confirmed"`
+	Completed        bool       `json:"completed"`
+	ExpiresAt        time.Time  `json:"expires_at"`
+	CompletedAt      *time.Time `json:"completed_at"`
+}
+
+func NewEmailChange(change *domain.EmailChange) *EmailChange {
+	return &EmailChange{
+		NewEmail:         change.NewEmail,
+		CurrentConfirmed: !change.OldConfirmedAt.IsZero(),
+		NewConfirmed:     !change.NewConfirmedAt.IsZero(),
+		Completed:        change.Completed(),
+		ExpiresAt:        change.ExpiresAt,
+		CompletedAt:      timeOrNil(change.CompletedAt),
+	}
+}
+
+// EmailChangeRequest changes the email of the account, the password is entered again.
+type EmailChangeRequest struct {
+	Email    string `json:"email" validate:"required,rfc_email"`
+	Password string `json:"password" validate:"required"`
+}
+
+func (r *EmailChangeRequest) ToDomain() *domain.EmailChangeRequest {
+	return &domain.EmailChangeRequest{
+		Email:    r.Email,
+		Password: r.Password,
+	}
+}
+
+// EmailChangeConfirmRequest confirms an email change with the token and region of an emailed link.
+type EmailChangeConfirmRequest struct {
+	Token  string `json:"token" validate:"required"`
+	Region string `json:"region" validate:"omitempty,max=64"`
+}
@@ -0,0 +1,113 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type PublicKey struct {
+	UserUUID string `json:"user_uuid"`
+	// PublicKey is null for users that didn't set up a key yet, keys can't be wrapped for them.
+	PublicKey *string    `json:"public_key"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+func NewPublicKey(key *domain.PublicKey) *PublicKey {
+	resp := &PublicKey{
+		UserUUID:  key.UserUUID,
+		UpdatedAt: timeOrNil(key.UpdatedAt),
+	}
+	if key.Key != "" {
+		resp.PublicKey = &key.Key
+	}
+
+	return resp
+}
+
+func NewPublicKeys(keys []*domain.PublicKey) []*PublicKey {
+	resp := make([]*PublicKey, 0, len(keys))
+	for _, key := range keys {
+		resp = append(resp, NewPublicKey(key))
+	}
+
+	return resp
+}
+
+// PublicKeyRequest sets the public key of the user, its format is up to the clients.
+type PublicKeyRequest struct {
+	PublicKey string `json:"public_key" validate:"required,max=8192"`
+}
+
+// ListEncryptRequest encrypts an empty list, wrapped_key is its first key wrapped with the
+// public key of the owner.
+type ListEncryptRequest struct {
+	WrappedKey string `json:"wrapped_key" validate:"required,max=8192"`
+}
+
+type ListKey struct {
+	UserUUID   string    `json:"user_uuid"`
+	KeyVersion int       `json:"key_version"`
+	WrappedKey string    `json:"wrapped_key"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type ListKeyring struct {
+	KeyVersion int        `json:"key_version"`
+	Keys       []*ListKey `json:"keys"`
+	// PendingUserUUIDs are the users still missing the current key, only shown to the owner.
+	PendingUserUUIDs []string `json:"pending_user_uuids"`
+}
+
+func NewListKeyring(keyring *domain.ListKeyring) *ListKeyring {
+	resp := &ListKeyring{
+		KeyVersion:       keyring.KeyVersion,
+		Keys:             make([]*ListKey, 0, len(keyring.Keys)),
+		PendingUserUUIDs: keyring.Pending,
+	}
+	for _, key := range keyring.Keys {
+		resp.Keys = append(resp.Keys, &ListKey{
+			UserUUID:   key.UserUUID,
+			KeyVersion: key.KeyVersion,
+			WrappedKey: key.WrappedKey,
+			CreatedAt:  key.CreatedAt,
+		})
+	}
+
+	return resp
+}
+
+type ListKeyRequest struct {
+	UserUUID   string `json:"user_uuid" validate:"required"`
+	WrappedKey string `json:"wrapped_key" validate:"required,max=8192"`
+}
+
+// ListKeysRequest hands the current key of a list, key_version, to members of the list.
+type ListKeysRequest struct {
+	KeyVersion int               `json:"key_version" validate:"min=1"`
+	Keys       []*ListKeyRequest `json:"keys" validate:"required,min=1,max=500,dive,required"`
+}
+
+func (r *ListKeysRequest) ToDomain() []*domain.ListKey {
+	return newListKeys(r.Keys)
+}
+
+// ListKeyRotateRequest replaces the key of a list at from_version with a new key wrapped for the
+// owner and every member with a public key.
+type ListKeyRotateRequest struct {
+	FromVersion int               `json:"from_version" validate:"min=1"`
+	Keys        []*ListKeyRequest `json:"keys" validate:"required,min=1,max=500,dive,required"`
+}
+
+func (r *ListKeyRotateRequest) ToDomain() []*domain.ListKey {
+	return newListKeys(r.Keys)
+}
+
+func newListKeys(reqs []*ListKeyRequest) []*domain.ListKey {
+	keys := make([]*domain.ListKey, 0, len(reqs))
+	for _, req := range reqs {
+		keys = append(keys, &domain.ListKey{UserUUID: req.UserUUID, WrappedKey: req.WrappedKey})
+	}
+
+	return keys
+}
@@ -0,0 +1,98 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type EscalationRule struct {
+	UUID        string `json:"uuid"`
+	OverdueDays int    `json:"overdue_days"`
+	// Priority is empty when the rule leaves the priority as is.
+	Priority  string    `json:"priority,omitempty"`
+	Notify    bool      `json:"notify"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewEscalationRule(rule *domain.EscalationRule) *EscalationRule {
+	resp := &EscalationRule{
+		UUID:        rule.UUID,
+		OverdueDays: int(rule.OverdueBy / (24 * time.Hour)),
+		Notify:      rule.Notify,
+		CreatedAt:   rule.CreatedAt,
+	}
+	if rule.Priority != nil {
+		resp.Priority = rule.Priority.String()
+	}
+
+	return resp
+}
+
+func NewEscalationRules(rules []*domain.EscalationRule) []*EscalationRule {
+	resp := make([]*EscalationRule, 0, len(rules))
+	for _, rule := range rules {
+		resp = append(resp, NewEscalationRule(rule))
+	}
+
+	return resp
+}
+
+// EscalationRuleRequest creates an escalation rule or replaces its definition, it needs a
+// priority, notify or both. Zero overdue days escalate todos as soon as they are overdue.
+type EscalationRuleRequest struct {
+	OverdueDays int    `json:"overdue_days" validate:"min=0,max=365"`
+	Priority    string `json:"priority" validate:"omitempty,oneof=low medium high urgent"`
+	Notify      bool   `json:"notify"`
+}
+
+func (r *EscalationRuleRequest) ToDomain() *domain.EscalationRuleCreate {
+	ruleCreate := &domain.EscalationRuleCreate{
+		OverdueBy: time.Duration(r.OverdueDays) * 24 * time.Hour,
+		Notify:    r.Notify,
+	}
+	if priority, err := domain.ParsePriority(r.Priority); err == nil {
+		ruleCreate.Priority = &priority
+	}
+
+	return ruleCreate
+}
+
+// Escalation is an entry of the activity log of a list, a rule that was applied to a todo.
+type Escalation struct {
+	RuleUUID string    `json:"rule_uuid"`
+	TodoUUID string    `json:"todo_uuid"`
+	Title    string    `json:"title"`
+	DueDate  time.Time `json:"due_date"`
+	// PriorityTo is the priority the todo was raised to, empty when it was left as is.
+	PriorityFrom string    `json:"priority_from"`
+	PriorityTo   string    `json:"priority_to,omitempty"`
+	Notified     bool      `json:"notified"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func NewEscalation(escalation *domain.Escalation) *Escalation {
+	resp := &Escalation{
+		RuleUUID:     escalation.RuleUUID,
+		TodoUUID:     escalation.TodoUUID,
+		Title:        escalation.Title,
+		DueDate:      escalation.DueDate,
+		PriorityFrom: escalation.PriorityFrom.String(),
+		Notified:     escalation.Notified,
+		CreatedAt:    escalation.CreatedAt,
+	}
+	if escalation.PriorityTo != nil {
+		resp.PriorityTo = escalation.PriorityTo.String()
+	}
+
+	return resp
+}
+
+func NewEscalations(escalations []*domain.Escalation) []*Escalation {
+	resp := make([]*Escalation, 0, len(escalations))
+	for _, escalation := range escalations {
+		resp = append(resp, NewEscalation(escalation))
+	}
+
+	return resp
+}
@@ -0,0 +1,50 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+// EventPayload is the JSON body of an event, both webhooks and the event stream send it.
+type EventPayload struct {
+	ID        string           `json:"id"`
+	Event     string           `json:"event"`
+	CreatedAt time.Time        `json:"created_at"`
+	Data      EventPayloadData `json:"data"`
+}
+
+type EventPayloadData struct {
+	Todo *Todo `json:"todo"`
+	// Previous is the todo before it was moved.
+	Previous *Todo `json:"previous,omitempty"`
+}
+
+func NewTodoEventPayload(id string, event *domain.TodoEvent) *EventPayload {
+	payload := &EventPayload{
+		ID:        id,
+		Event:     string(event.Type),
+		CreatedAt: event.OccurredAt,
+		Data: EventPayloadData{
+			Todo: NewTodo(event.Todo),
+		},
+	}
+	if event.Previous != nil {
+		payload.Data.Previous = NewTodo(event.Previous)
+	}
+
+	return payload
+}
+
+// RoomTicket opens the WebSocket of a list room once, it is passed in the ticket query parameter.
+type RoomTicket struct {
+	Ticket    string    `json:"ticket"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func NewRoomTicket(ticket *domain.RoomTicket) *RoomTicket {
+	return &RoomTicket{
+		Ticket:    ticket.Ticket,
+		ExpiresAt: ticket.ExpiresAt,
+	}
+}
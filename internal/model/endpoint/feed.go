@@ -0,0 +1,48 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type FeedToken struct {
+	UUID     string `json:"uuid"`
+	ListUUID string `json:"list_uuid"`
+	Name     string `json:"name"`
+	Token    string `json:"token,omitempty"`
+	// URL is the feed URL to paste into a feed reader, only set with the token.
+	URL        string     `json:"url,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// NewFeedToken converts a feed token, feedURL is the feed the reader URL points to.
+func NewFeedToken(token *domain.FeedToken, feedURL string) *FeedToken {
+	resp := &FeedToken{
+		UUID:       token.UUID,
+		ListUUID:   token.ListUUID,
+		Name:       token.Name,
+		Token:      token.Token,
+		LastUsedAt: timeOrNil(token.LastUsedAt),
+		CreatedAt:  token.CreatedAt,
+	}
+	if token.Token != "" {
+		resp.URL = feedURL + "?token=" + token.Token
+	}
+
+	return resp
+}
+
+func NewFeedTokens(tokens []*domain.FeedToken) []*FeedToken {
+	resp := make([]*FeedToken, 0, len(tokens))
+	for _, token := range tokens {
+		resp = append(resp, NewFeedToken(token, ""))
+	}
+
+	return resp
+}
+
+type FeedTokenRequest struct {
+	Name string `json:"name" validate:"required,max=255"`
+}
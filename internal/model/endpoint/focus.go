@@ -0,0 +1,96 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type FocusSession struct {
+	UUID           string     `json:"uuid"`
+	TodoUUID       string     `json:"todo_uuid"`
+	TodoTitle      string     `json:"todo_title"`
+	Status         string     `json:"status"`
+	PlannedSeconds int        `json:"planned_seconds"`
+	FocusedSeconds int        `json:"focused_seconds"`
+	Interruptions  int        `json:"interruptions"`
+	StartedAt      time.Time  `json:"started_at"`
+	EndsAt         time.Time  `json:"ends_at"`
+	EndedAt        *time.Time `json:"ended_at"`
+}
+
+func NewFocusSession(session *domain.FocusSession) *FocusSession {
+	return &FocusSession{
+		UUID:           session.UUID,
+		TodoUUID:       session.TodoUUID,
+		TodoTitle:      session.TodoTitle,
+		Status:         string(session.Status),
+		PlannedSeconds: int(session.Planned.Seconds()),
+		FocusedSeconds: int(session.Focused.Seconds()),
+		Interruptions:  session.Interruptions,
+		StartedAt:      session.StartedAt,
+		EndsAt:         session.Ends(),
+		EndedAt:        timeOrNil(session.EndedAt),
+	}
+}
+
+func NewFocusSessions(sessions []*domain.FocusSession) []*FocusSession {
+	resp := make([]*FocusSession, 0, len(sessions))
+	for _, session := range sessions {
+		resp = append(resp, NewFocusSession(session))
+	}
+
+	return resp
+}
+
+type FocusSessionRequest struct {
+	TodoUUID string `json:"todo_uuid" validate:"required"`
+	// DurationMinutes defaults to a 25 minute pomodoro.
+	DurationMinutes int `json:"duration_minutes" validate:"omitempty,min=1,max=180"`
+}
+
+func (r *FocusSessionRequest) ToDomain() *domain.FocusSessionStart {
+	return &domain.FocusSessionStart{
+		TodoUUID: r.TodoUUID,
+		Duration: time.Duration(r.DurationMinutes) * time.Minute,
+	}
+}
+
+type FocusStats struct {
+	From           time.Time         `json:"from"`
+	To             time.Time         `json:"to"`
+	Sessions       int               `json:"sessions"`
+	Completed      int               `json:"completed"`
+	Interruptions  int               `json:"interruptions"`
+	FocusedSeconds int               `json:"focused_seconds"`
+	Todos          []*FocusTodoStats `json:"todos"`
+}
+
+type FocusTodoStats struct {
+	TodoUUID       string `json:"todo_uuid"`
+	TodoTitle      string `json:"todo_title"`
+	Sessions       int    `json:"sessions"`
+	FocusedSeconds int    `json:"focused_seconds"`
+}
+
+func NewFocusStats(stats *domain.FocusStats) *FocusStats {
+	todos := make([]*FocusTodoStats, 0, len(stats.Todos))
+	for _, todo := range stats.Todos {
+		todos = append(todos, &FocusTodoStats{
+			TodoUUID:       todo.TodoUUID,
+			TodoTitle:      todo.TodoTitle,
+			Sessions:       todo.Sessions,
+			FocusedSeconds: int(todo.Focused.Seconds()),
+		})
+	}
+
+	return &FocusStats{
+		From:           stats.From,
+		To:             stats.To,
+		Sessions:       stats.Sessions,
+		Completed:      stats.Completed,
+		Interruptions:  stats.Interruptions,
+		FocusedSeconds: int(stats.Focused.Seconds()),
+		Todos:          todos,
+	}
+}
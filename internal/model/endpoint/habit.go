@@ -0,0 +1,128 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type Habit struct {
+	UUID          string    `json:"uuid"`
+	Title         string    `json:"title"`
+	TargetPerWeek int       `json:"target_per_week"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func NewHabit(habit *domain.Habit) *Habit {
+	return &Habit{
+		UUID:          habit.UUID,
+		Title:         habit.Title,
+		TargetPerWeek: habit.TargetPerWeek,
+		CreatedAt:     habit.CreatedAt,
+		UpdatedAt:     habit.UpdatedAt,
+	}
+}
+
+func NewHabits(habits []*domain.Habit) []*Habit {
+	resp := make([]*Habit, 0, len(habits))
+	for _, habit := range habits {
+		resp = append(resp, NewHabit(habit))
+	}
+
+	return resp
+}
+
+type HabitCreateRequest struct {
+	Title string `json:"title" validate:"required,max=255"`
+	// TargetPerWeek defaults to every day.
+	TargetPerWeek int `json:"target_per_week" validate:"omitempty,min=1,max=7"`
+}
+
+func (r *HabitCreateRequest) ToDomain() *domain.HabitCreate {
+	habitCreate := &domain.HabitCreate{
+		Title:         r.Title,
+		TargetPerWeek: r.TargetPerWeek,
+	}
+	if habitCreate.TargetPerWeek == 0 {
+		habitCreate.TargetPerWeek = domain.DailyHabitTarget
+	}
+
+	return habitCreate
+}
+
+type HabitUpdateRequest struct {
+	Title         *string `json:"title" validate:"omitempty,min=1,max=255"`
+	TargetPerWeek *int    `json:"target_per_week" validate:"omitempty,min=1,max=7"`
+}
+
+func (r *HabitUpdateRequest) ToDomain() *domain.HabitUpdate {
+	return &domain.HabitUpdate{
+		Title:         r.Title,
+		TargetPerWeek: r.TargetPerWeek,
+	}
+}
+
+type HabitEntry struct {
+	Date   string `json:"date"`
+	Status string `json:"status"`
+}
+
+func NewHabitEntry(entry *domain.HabitEntry) *HabitEntry {
+	return &HabitEntry{
+		Date:   entry.Date.Format(domain.DateLayout),
+		Status: string(entry.Status),
+	}
+}
+
+type HabitEntryRequest struct {
+	// Date defaults to today in UTC.
+	Date string `json:"date"`
+	// Status defaults to done.
+	Status string `json:"status" validate:"omitempty,oneof=done skipped"`
+}
+
+type HabitStats struct {
+	From           string       `json:"from"`
+	To             string       `json:"to"`
+	StreakUnit     string       `json:"streak_unit"`
+	CurrentStreak  int          `json:"current_streak"`
+	LongestStreak  int          `json:"longest_streak"`
+	Done           int          `json:"done"`
+	Skipped        int          `json:"skipped"`
+	CompletionRate float64      `json:"completion_rate"`
+	Weeks          []*HabitWeek `json:"weeks"`
+}
+
+type HabitWeek struct {
+	Start   string `json:"start"`
+	Done    int    `json:"done"`
+	Skipped int    `json:"skipped"`
+	Target  int    `json:"target"`
+	Met     bool   `json:"met"`
+}
+
+func NewHabitStats(stats *domain.HabitStats) *HabitStats {
+	weeks := make([]*HabitWeek, 0, len(stats.Weeks))
+	for _, week := range stats.Weeks {
+		weeks = append(weeks, &HabitWeek{
+			Start:   week.Start.Format(domain.DateLayout),
+			Done:    week.Done,
+			Skipped: week.Skipped,
+			Target:  week.Target,
+			Met:     week.Met,
+		})
+	}
+
+	return &HabitStats{
+		From:           stats.From.Format(domain.DateLayout),
+		To:             stats.To.Format(domain.DateLayout),
+		StreakUnit:     stats.StreakUnit,
+		CurrentStreak:  stats.CurrentStreak,
+		LongestStreak:  stats.LongestStreak,
+		Done:           stats.Done,
+		Skipped:        stats.Skipped,
+		CompletionRate: stats.CompletionRate,
+		Weeks:          weeks,
+	}
+}
@@ -0,0 +1,37 @@
+package endpoint
+
+import (
+	"github.com/meowmix1337/the_recipe_book/internal/health"
+)
+
+type HealthCheck struct {
+	Name      string        `json:"name"`
+	Status    health.Status `json:"status"`
+	LatencyMS float64       `json:"latency_ms"`
+	Error     string        `json:"error,omitempty"`
+}
+
+type Health struct {
+	Status health.Status  `json:"status"`
+	Checks []*HealthCheck `json:"checks"`
+}
+
+func NewHealth(report *health.Report) *Health {
+	checks := make([]*HealthCheck, 0, len(report.Checks))
+	for _, result := range report.Checks {
+		check := &HealthCheck{
+			Name:      result.Name,
+			Status:    result.Status,
+			LatencyMS: float64(result.Latency.Microseconds()) / 1000,
+		}
+		if result.Err != nil {
+			check.Error = result.Err.Error()
+		}
+		checks = append(checks, check)
+	}
+
+	return &Health{
+		Status: report.Status,
+		Checks: checks,
+	}
+}
@@ -0,0 +1,87 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type ParentalControls struct {
+	AllowSharing  bool `json:"allow_sharing"`
+	AllowDeletion bool `json:"allow_deletion"`
+}
+
+type ChildAccount struct {
+	UUID      string           `json:"uuid"`
+	Username  string           `json:"username"`
+	FirstName string           `json:"first_name"`
+	LastName  string           `json:"last_name"`
+	Controls  ParentalControls `json:"parental_controls"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+func NewChildAccount(user *domain.User) *ChildAccount {
+	return &ChildAccount{
+		UUID:      user.UUID,
+		Username:  user.Username,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Controls: ParentalControls{
+			AllowSharing:  user.Controls.AllowSharing,
+			AllowDeletion: user.Controls.AllowDeletion,
+		},
+		CreatedAt: user.CreatedAt,
+	}
+}
+
+func NewChildAccounts(users []*domain.User) []*ChildAccount {
+	resp := make([]*ChildAccount, 0, len(users))
+	for _, user := range users {
+		resp = append(resp, NewChildAccount(user))
+	}
+
+	return resp
+}
+
+type ChildCreateRequest struct {
+	Username      string `json:"username" validate:"required,alphanum,min=3,max=255"`
+	Password      string `json:"password" validate:"required,strong_password"`
+	FirstName     string `json:"first_name" validate:"max=255"`
+	LastName      string `json:"last_name" validate:"max=255"`
+	AllowSharing  bool   `json:"allow_sharing"`
+	AllowDeletion bool   `json:"allow_deletion"`
+}
+
+func (r *ChildCreateRequest) ToDomain() *domain.ChildCreate {
+	return &domain.ChildCreate{
+		Username:  r.Username,
+		Password:  r.Password,
+		FirstName: r.FirstName,
+		LastName:  r.LastName,
+		Controls: domain.ParentalControls{
+			AllowSharing:  r.AllowSharing,
+			AllowDeletion: r.AllowDeletion,
+		},
+	}
+}
+
+// ChildUpdateRequest replaces the parental controls when provided and resets the password when set.
+type ChildUpdateRequest struct {
+	Password string            `json:"password" validate:"omitempty,strong_password"`
+	Controls *ParentalControls `json:"parental_controls"`
+}
+
+func (r *ChildUpdateRequest) ToDomain() *domain.ChildUpdate {
+	childUpdate := &domain.ChildUpdate{}
+	if r.Password != "" {
+		childUpdate.Password = &r.Password
+	}
+	if r.Controls != nil {
+		childUpdate.Controls = &domain.ParentalControls{
+			AllowSharing:  r.Controls.AllowSharing,
+			AllowDeletion: r.Controls.AllowDeletion,
+		}
+	}
+
+	return childUpdate
+}
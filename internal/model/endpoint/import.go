@@ -0,0 +1,56 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type ImportJob struct {
+	UUID         string `json:"uuid"`
+	Provider     string `json:"provider"`
+	Status       string `json:"status"`
+	ListsCreated int    `json:"lists_created"`
+	TodosCreated int    `json:"todos_created"`
+	Error        string `json:"error,omitempty"`
+	// SourceURL, BytesReceived and ArchiveSHA256 are set for transfers from another instance.
+	SourceURL     string     `json:"source_url,omitempty"`
+	BytesReceived int64      `json:"bytes_received,omitempty"`
+	ArchiveSHA256 string     `json:"archive_sha256,omitempty"`
+	StartedAt     *time.Time `json:"started_at"`
+	FinishedAt    *time.Time `json:"finished_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+func NewImportJob(job *domain.ImportJob) *ImportJob {
+	return &ImportJob{
+		UUID:          job.UUID,
+		Provider:      string(job.Provider),
+		Status:        string(job.Status),
+		ListsCreated:  job.ListsCreated,
+		TodosCreated:  job.TodosCreated,
+		Error:         job.Error,
+		SourceURL:     job.SourceURL,
+		BytesReceived: job.BytesReceived,
+		ArchiveSHA256: job.ArchiveSHA256,
+		StartedAt:     timeOrNil(job.StartedAt),
+		FinishedAt:    timeOrNil(job.FinishedAt),
+		CreatedAt:     job.CreatedAt,
+	}
+}
+
+func NewImportJobs(jobs []*domain.ImportJob) []*ImportJob {
+	resp := make([]*ImportJob, 0, len(jobs))
+	for _, job := range jobs {
+		resp = append(resp, NewImportJob(job))
+	}
+
+	return resp
+}
+
+// AccountTransferRequest starts moving the account from another instance, token is an access
+// token of the account there.
+type AccountTransferRequest struct {
+	SourceURL string `json:"source_url" validate:"required,url"`
+	Token     string `json:"token" validate:"required"`
+}
@@ -0,0 +1,35 @@
+package endpoint
+
+import (
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type InsightSettings struct {
+	Enabled      bool `json:"enabled"`
+	OverdueSpike int  `json:"overdue_spike"`
+	IdleDays     int  `json:"idle_days"`
+}
+
+func NewInsightSettings(settings *domain.InsightSettings) *InsightSettings {
+	return &InsightSettings{
+		Enabled:      settings.Enabled,
+		OverdueSpike: settings.OverdueSpike,
+		IdleDays:     settings.IdleDays,
+	}
+}
+
+// InsightSettingsRequest opts in to productivity nudges. OverdueSpike is how many todos have to
+// become overdue within a day, IdleDays how many days without a completed todo are unusual.
+type InsightSettingsRequest struct {
+	Enabled      bool `json:"enabled"`
+	OverdueSpike int  `json:"overdue_spike" validate:"required,min=1,max=1000"`
+	IdleDays     int  `json:"idle_days" validate:"required,min=1,max=90"`
+}
+
+func (r *InsightSettingsRequest) ToDomain() *domain.InsightSettingsUpdate {
+	return &domain.InsightSettingsUpdate{
+		Enabled:      r.Enabled,
+		OverdueSpike: r.OverdueSpike,
+		IdleDays:     r.IdleDays,
+	}
+}
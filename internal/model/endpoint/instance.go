@@ -0,0 +1,43 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type Instance struct {
+	ID          string    `json:"id"`
+	Hostname    string    `json:"hostname"`
+	Version     string    `json:"version"`
+	Regions     []string  `json:"regions"`
+	Shard       int       `json:"shard"`
+	Shards      int       `json:"shards"`
+	Current     bool      `json:"current"`
+	StartedAt   time.Time `json:"started_at"`
+	HeartbeatAt time.Time `json:"heartbeat_at"`
+}
+
+// NewInstances converts the live instances, current marks the instance that answered the request.
+func NewInstances(instances []*domain.Instance, currentID string) []*Instance {
+	resp := make([]*Instance, 0, len(instances))
+	for _, instance := range instances {
+		regions := instance.Regions
+		if regions == nil {
+			regions = []string{}
+		}
+		resp = append(resp, &Instance{
+			ID:          instance.ID,
+			Hostname:    instance.Hostname,
+			Version:     instance.Version,
+			Regions:     regions,
+			Shard:       instance.Shard,
+			Shards:      instance.Shards,
+			Current:     instance.ID == currentID,
+			StartedAt:   instance.StartedAt,
+			HeartbeatAt: instance.HeartbeatAt,
+		})
+	}
+
+	return resp
+}
@@ -0,0 +1,50 @@
+package endpoint
+
+import (
+	"encoding/base64"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+// p256CoordinateSize is the size of the coordinates of a P-256 point, JWKs pad them to it.
+const p256CoordinateSize = 32
+
+// JWK is a public signing key as a JSON Web Key (RFC 7517).
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	Y         string `json:"y"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+}
+
+// JWKS is the JSON Web Key Set access tokens are verified with.
+type JWKS struct {
+	Keys []*JWK `json:"keys"`
+}
+
+// NewJWKS returns the public keys of the signing keys, keys that can't be parsed are left out
+// as they verify nothing.
+func NewJWKS(keys []*domain.SigningKey) *JWKS {
+	resp := &JWKS{Keys: make([]*JWK, 0, len(keys))}
+	for _, key := range keys {
+		public, err := key.ECDSAPublicKey()
+		if err != nil {
+			continue
+		}
+
+		resp.Keys = append(resp.Keys, &JWK{
+			KeyType:   "EC",
+			Curve:     "P-256",
+			X:         base64.RawURLEncoding.EncodeToString(public.X.FillBytes(make([]byte, p256CoordinateSize))),
+			Y:         base64.RawURLEncoding.EncodeToString(public.Y.FillBytes(make([]byte, p256CoordinateSize))),
+			KeyID:     key.KID,
+			Use:       "sig",
+			Algorithm: key.Algorithm,
+		})
+	}
+
+	return resp
+}
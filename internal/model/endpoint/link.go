@@ -0,0 +1,28 @@
+package endpoint
+
+import "github.com/meowmix1337/the_recipe_book/internal/model/domain"
+
+type LinkSettings struct {
+	CheckLinks bool `json:"check_links"`
+	Notify     bool `json:"notify"`
+}
+
+func NewLinkSettings(settings *domain.LinkSettings) *LinkSettings {
+	return &LinkSettings{
+		CheckLinks: settings.Check,
+		Notify:     settings.Notify,
+	}
+}
+
+// LinkSettingsUpdateRequest only changes the provided settings.
+type LinkSettingsUpdateRequest struct {
+	CheckLinks *bool `json:"check_links"`
+	Notify     *bool `json:"notify"`
+}
+
+func (r *LinkSettingsUpdateRequest) ToDomain() *domain.LinkSettingsUpdate {
+	return &domain.LinkSettingsUpdate{
+		Check:  r.CheckLinks,
+		Notify: r.Notify,
+	}
+}
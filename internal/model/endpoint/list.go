@@ -0,0 +1,94 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type List struct {
+	UUID string `json:"uuid"`
+	Name string `json:"name"`
+	// CompletedRetentionDays is null for lists that keep their completed todos visible.
+	CompletedRetentionDays *int `json:"completed_retention_days"`
+	// Encrypted lists hold todos encrypted with the list key of key_version.
+	Encrypted  bool      `json:"encrypted"`
+	KeyVersion int       `json:"key_version"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func NewList(list *domain.List) *List {
+	resp := &List{
+		UUID:       list.UUID,
+		Name:       list.Name,
+		Encrypted:  list.Encrypted(),
+		KeyVersion: list.KeyVersion,
+		CreatedAt:  list.CreatedAt,
+		UpdatedAt:  list.UpdatedAt,
+	}
+	if list.CompletedRetention > 0 {
+		days := int(list.CompletedRetention / (24 * time.Hour))
+		resp.CompletedRetentionDays = &days
+	}
+
+	return resp
+}
+
+func NewLists(lists []*domain.List) []*List {
+	resp := make([]*List, 0, len(lists))
+	for _, list := range lists {
+		resp = append(resp, NewList(list))
+	}
+
+	return resp
+}
+
+type ListRequest struct {
+	Name string `json:"name" validate:"required,max=255"`
+}
+
+type ListRetentionRequest struct {
+	// CompletedRetentionDays is how many days completed todos stay visible, null keeps them.
+	CompletedRetentionDays *int `json:"completed_retention_days" validate:"omitempty,min=1,max=3650"`
+}
+
+// Retention returns the retention as a duration, zero when completed todos are kept visible.
+func (r *ListRetentionRequest) Retention() time.Duration {
+	if r.CompletedRetentionDays == nil {
+		return 0
+	}
+
+	return time.Duration(*r.CompletedRetentionDays) * 24 * time.Hour
+}
+
+type ListMergeRequest struct {
+	// SourceUUID is the list folded into the list of the URL.
+	SourceUUID     string `json:"source_uuid" validate:"required"`
+	SkipDuplicates bool   `json:"skip_duplicates"`
+	DryRun         bool   `json:"dry_run"`
+}
+
+func (r *ListMergeRequest) ToDomain() *domain.ListMerge {
+	return &domain.ListMerge{
+		SourceUUID:     r.SourceUUID,
+		SkipDuplicates: r.SkipDuplicates,
+		DryRun:         r.DryRun,
+	}
+}
+
+type ListMergeResult struct {
+	List       *List   `json:"list"`
+	DryRun     bool    `json:"dry_run"`
+	Moved      []*Todo `json:"moved"`
+	Duplicates []*Todo `json:"duplicates"`
+}
+
+func NewListMergeResult(result *domain.ListMergeResult) *ListMergeResult {
+	return &ListMergeResult{
+		List:       NewList(result.Target),
+		DryRun:     result.DryRun,
+		Moved:      NewTodos(result.Moved),
+		Duplicates: NewTodos(result.Duplicates),
+	}
+}
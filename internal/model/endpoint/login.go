@@ -0,0 +1,71 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type Login struct {
+	UUID       string    `json:"uuid"`
+	Device     string    `json:"device"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	Country    string    `json:"country,omitempty"`
+	NewDevice  bool      `json:"new_device"`
+	NewCountry bool      `json:"new_country"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func NewLogin(login *domain.Login) *Login {
+	return &Login{
+		UUID:       login.UUID,
+		Device:     domain.DeviceName(login.UserAgent),
+		IP:         login.IP,
+		UserAgent:  login.UserAgent,
+		Country:    login.Country,
+		NewDevice:  login.NewDevice,
+		NewCountry: login.NewCountry,
+		CreatedAt:  login.CreatedAt,
+	}
+}
+
+func NewLogins(logins []*domain.Login) []*Login {
+	resp := make([]*Login, 0, len(logins))
+	for _, login := range logins {
+		resp = append(resp, NewLogin(login))
+	}
+
+	return resp
+}
+
+type KnownDevice struct {
+	UUID        string    `json:"uuid"`
+	Name        string    `json:"name"`
+	UserAgent   string    `json:"user_agent"`
+	LastIP      string    `json:"last_ip"`
+	LastCountry string    `json:"last_country,omitempty"`
+	FirstSeenAt time.Time `json:"first_seen_at"`
+	LastSeenAt  time.Time `json:"last_seen_at"`
+}
+
+func NewKnownDevice(device *domain.KnownDevice) *KnownDevice {
+	return &KnownDevice{
+		UUID:        device.UUID,
+		Name:        domain.DeviceName(device.UserAgent),
+		UserAgent:   device.UserAgent,
+		LastIP:      device.LastIP,
+		LastCountry: device.LastCountry,
+		FirstSeenAt: device.FirstSeenAt,
+		LastSeenAt:  device.LastSeenAt,
+	}
+}
+
+func NewKnownDevices(devices []*domain.KnownDevice) []*KnownDevice {
+	resp := make([]*KnownDevice, 0, len(devices))
+	for _, device := range devices {
+		resp = append(resp, NewKnownDevice(device))
+	}
+
+	return resp
+}
@@ -0,0 +1,151 @@
+package endpoint
+
+import (
+	"encoding/json"
+)
+
+// The agent tools are also served over the Model Context Protocol, JSON-RPC 2.0 messages posted
+// to a single route. Only the tools capability is implemented.
+
+// MCPProtocolVersions are the versions of the protocol the server speaks, newest first.
+var MCPProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// JSON-RPC error codes.
+const (
+	MCPParseError     = -32700
+	MCPInvalidRequest = -32600
+	MCPMethodNotFound = -32601
+	MCPInvalidParams  = -32602
+	MCPInternalError  = -32603
+)
+
+// MCPRequest is a JSON-RPC request, requests without an ID are notifications and get no
+// response.
+type MCPRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+func (r *MCPRequest) Notification() bool {
+	return len(r.ID) == 0
+}
+
+type MCPResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *MCPError       `json:"error,omitempty"`
+}
+
+type MCPError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func NewMCPResult(id json.RawMessage, result any) *MCPResponse {
+	return &MCPResponse{JSONRPC: "2.0", ID: id, Result: result}
+}
+
+func NewMCPError(id json.RawMessage, code int, message string) *MCPResponse {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+
+	return &MCPResponse{JSONRPC: "2.0", ID: id, Error: &MCPError{Code: code, Message: message}}
+}
+
+type MCPInitializeParams struct {
+	ProtocolVersion string `json:"protocolVersion"`
+}
+
+type MCPInitializeResult struct {
+	ProtocolVersion string         `json:"protocolVersion"`
+	Capabilities    map[string]any `json:"capabilities"`
+	ServerInfo      *MCPServerInfo `json:"serverInfo"`
+	Instructions    string         `json:"instructions,omitempty"`
+}
+
+type MCPServerInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type MCPTool struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	InputSchema json.RawMessage     `json:"inputSchema"`
+	Annotations *MCPToolAnnotations `json:"annotations"`
+}
+
+type MCPToolAnnotations struct {
+	ReadOnlyHint    bool `json:"readOnlyHint"`
+	DestructiveHint bool `json:"destructiveHint"`
+	IdempotentHint  bool `json:"idempotentHint"`
+	// OpenWorldHint is false, the tools only reach the todos of the user.
+	OpenWorldHint bool `json:"openWorldHint"`
+}
+
+type MCPToolList struct {
+	Tools []*MCPTool `json:"tools"`
+}
+
+func NewMCPToolList(tools []*AgentTool) *MCPToolList {
+	list := &MCPToolList{
+		Tools: make([]*MCPTool, 0, len(tools)),
+	}
+	for _, tool := range tools {
+		list.Tools = append(list.Tools, &MCPTool{
+			Name:        tool.Name,
+			Description: tool.Description,
+			InputSchema: tool.InputSchema,
+			Annotations: &MCPToolAnnotations{
+				ReadOnlyHint:    tool.ReadOnly,
+				DestructiveHint: tool.Destructive,
+				IdempotentHint:  tool.Idempotent,
+			},
+		})
+	}
+
+	return list
+}
+
+type MCPToolCallParams struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// MCPToolResult is the result of a tool call. Errors the assistant can act on, e.g. invalid
+// arguments or a missing scope, are results with IsError set rather than JSON-RPC errors.
+type MCPToolResult struct {
+	Content []*MCPContent `json:"content"`
+	// StructuredContent is the result as an object, Content has it as JSON text for clients
+	// that don't read it.
+	StructuredContent any  `json:"structuredContent,omitempty"`
+	IsError           bool `json:"isError"`
+}
+
+type MCPContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func NewMCPToolResult(structured any) (*MCPToolResult, error) {
+	text, err := json.Marshal(structured)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MCPToolResult{
+		Content:           []*MCPContent{{Type: "text", Text: string(text)}},
+		StructuredContent: structured,
+	}, nil
+}
+
+func NewMCPToolError(message string) *MCPToolResult {
+	return &MCPToolResult{
+		Content: []*MCPContent{{Type: "text", Text: message}},
+		IsError: true,
+	}
+}
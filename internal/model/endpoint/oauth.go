@@ -0,0 +1,72 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type OAuthAuthorizeRequest struct {
+	ResponseType string `json:"response_type" validate:"required,eq=code"`
+	ClientID     string `json:"client_id" validate:"required"`
+	RedirectURI  string `json:"redirect_uri" validate:"required,url"`
+	State        string `json:"state" validate:"max=1024"`
+}
+
+// OAuthTokenRequest is sent form encoded, as required by RFC 6749.
+type OAuthTokenRequest struct {
+	GrantType    string `form:"grant_type"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	RefreshToken string `form:"refresh_token"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+}
+
+func (r *OAuthTokenRequest) ToDomain() *domain.OAuthTokenRequest {
+	return &domain.OAuthTokenRequest{
+		GrantType:    r.GrantType,
+		Code:         r.Code,
+		RedirectURI:  r.RedirectURI,
+		RefreshToken: r.RefreshToken,
+		ClientID:     r.ClientID,
+		ClientSecret: r.ClientSecret,
+	}
+}
+
+type OAuthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+func NewOAuthTokenResponse(grant *domain.OAuthGrant) *OAuthTokenResponse {
+	return &OAuthTokenResponse{
+		AccessToken:  grant.AccessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(time.Until(grant.AccessExpiresAt).Seconds()),
+		RefreshToken: grant.RefreshToken,
+	}
+}
+
+type OAuthGrant struct {
+	UUID       string     `json:"uuid"`
+	ClientID   string     `json:"client_id"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func NewOAuthGrants(grants []*domain.OAuthGrant) []*OAuthGrant {
+	resp := make([]*OAuthGrant, 0, len(grants))
+	for _, grant := range grants {
+		resp = append(resp, &OAuthGrant{
+			UUID:       grant.UUID,
+			ClientID:   grant.ClientID,
+			LastUsedAt: timeOrNil(grant.LastUsedAt),
+			CreatedAt:  grant.CreatedAt,
+		})
+	}
+
+	return resp
+}
@@ -0,0 +1,60 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+// OnboardingState is how far the user is through onboarding. NextStep is the step to show next,
+// empty once Done.
+type OnboardingState struct {
+	Steps         []*OnboardingStep `json:"steps"`
+	NextStep      string            `json:"next_step,omitempty"`
+	Done          bool              `json:"done"`
+	DismissedTips []string          `json:"dismissed_tips"`
+	DismissedAt   *time.Time        `json:"dismissed_at,omitempty"`
+	UpdatedAt     *time.Time        `json:"updated_at,omitempty"`
+}
+
+type OnboardingStep struct {
+	Key         string     `json:"key"`
+	Completed   bool       `json:"completed"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+func NewOnboardingState(state *domain.OnboardingState) *OnboardingState {
+	resp := &OnboardingState{
+		Steps:         make([]*OnboardingStep, 0, len(state.Steps)),
+		NextStep:      state.Next(),
+		Done:          state.Done(),
+		DismissedTips: state.DismissedTips,
+	}
+	for _, step := range state.Steps {
+		resp.Steps = append(resp.Steps, &OnboardingStep{
+			Key:         step.Key,
+			Completed:   !step.CompletedAt.IsZero(),
+			CompletedAt: timeOrNil(step.CompletedAt),
+		})
+	}
+	resp.DismissedAt = timeOrNil(state.DismissedAt)
+	resp.UpdatedAt = timeOrNil(state.UpdatedAt)
+
+	return resp
+}
+
+// OnboardingUpdateRequest completes steps and dismisses tips, those already done are kept.
+// Dismissed skips the rest of the onboarding, false resumes it.
+type OnboardingUpdateRequest struct {
+	CompleteSteps []string `json:"complete_steps" validate:"max=20,dive,required,max=64"`
+	DismissTips   []string `json:"dismiss_tips" validate:"max=50,dive,required,max=64"`
+	Dismissed     *bool    `json:"dismissed"`
+}
+
+func (r *OnboardingUpdateRequest) ToDomain() *domain.OnboardingUpdate {
+	return &domain.OnboardingUpdate{
+		CompleteSteps: r.CompleteSteps,
+		DismissTips:   r.DismissTips,
+		Dismissed:     r.Dismissed,
+	}
+}
@@ -0,0 +1,51 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type Plan struct {
+	Date            string      `json:"date"`
+	CapacityMinutes int         `json:"capacity_minutes"`
+	PlannedMinutes  int         `json:"planned_minutes"`
+	Items           []*PlanItem `json:"items"`
+	UpdatedAt       time.Time   `json:"updated_at"`
+}
+
+type PlanItem struct {
+	Rank            int      `json:"rank"`
+	Score           float64  `json:"score"`
+	EstimateMinutes int      `json:"estimate_minutes"`
+	Reasons         []string `json:"reasons"`
+	Todo            *Todo    `json:"todo"`
+}
+
+func NewPlan(plan *domain.Plan) *Plan {
+	items := make([]*PlanItem, 0, len(plan.Items))
+	for _, item := range plan.Items {
+		items = append(items, &PlanItem{
+			Rank:            item.Position,
+			Score:           item.Score,
+			EstimateMinutes: int(item.Estimate.Minutes()),
+			Reasons:         item.Reasons,
+			Todo:            NewTodo(item.Todo),
+		})
+	}
+
+	return &Plan{
+		Date:            plan.Date.Format(domain.DateLayout),
+		CapacityMinutes: int(plan.Capacity.Minutes()),
+		PlannedMinutes:  int(plan.Planned().Minutes()),
+		Items:           items,
+		UpdatedAt:       plan.UpdatedAt,
+	}
+}
+
+type PlanRequest struct {
+	// Date is the local date of the user as YYYY-MM-DD, it defaults to today in UTC.
+	Date string `json:"date"`
+	// CapacityMinutes defaults to the capacity of the last plan.
+	CapacityMinutes int `json:"capacity_minutes" validate:"min=0,max=1440"`
+}
@@ -0,0 +1,29 @@
+package endpoint
+
+// ProblemContentType is the media type of Problem responses.
+const ProblemContentType = "application/problem+json"
+
+// Problem is the body of every error response, an RFC 7807 problem details object.
+type Problem struct {
+	// Type is about:blank, the status explains the problem well enough.
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	// Instance is the path of the request that failed.
+	Instance string `json:"instance,omitempty"`
+	// Message repeats Detail, or Title when there is none, for the clients of the error
+	// responses that came before problem details.
+	Message string `json:"message"`
+	// Errors lists the failed fields of requests that didn't pass validation.
+	Errors []*FieldError `json:"errors,omitempty"`
+}
+
+// FieldError is a rule a field of a request failed. Field is the name the client sent it with,
+// elements of lists are indexed like tags[1].
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,153 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type WorkspaceRequest struct {
+	Name  string `json:"name" validate:"required,max=255"`
+	Plan  string `json:"plan" validate:"max=64"`
+	Owner struct {
+		Email string `json:"email" validate:"required,rfc_email"`
+		// Password is only used to create the owner account when it doesn't exist yet.
+		Password string `json:"password"`
+		Region   string `json:"region" validate:"omitempty,max=64"`
+	} `json:"owner"`
+}
+
+func (r *WorkspaceRequest) ToDomain() *domain.WorkspaceProvision {
+	return &domain.WorkspaceProvision{
+		Name:          r.Name,
+		Plan:          r.Plan,
+		OwnerEmail:    r.Owner.Email,
+		OwnerPassword: r.Owner.Password,
+		Region:        r.Owner.Region,
+	}
+}
+
+// QuotasRequest caps the resources of a workspace, 0 is unlimited. The monthly caps of 0 fall
+// back to the caps of the plan.
+type QuotasRequest struct {
+	MaxMembers           int `json:"max_members" validate:"min=0"`
+	MaxLists             int `json:"max_lists" validate:"min=0"`
+	MaxWebhookDeliveries int `json:"max_webhook_deliveries" validate:"min=0"`
+	MaxAPICalls          int `json:"max_api_calls" validate:"min=0"`
+	MaxIntegrationSyncs  int `json:"max_integration_syncs" validate:"min=0"`
+}
+
+func (r *QuotasRequest) ToDomain() domain.Quotas {
+	return domain.Quotas{
+		MaxMembers:           r.MaxMembers,
+		MaxLists:             r.MaxLists,
+		MaxWebhookDeliveries: r.MaxWebhookDeliveries,
+		MaxAPICalls:          r.MaxAPICalls,
+		MaxIntegrationSyncs:  r.MaxIntegrationSyncs,
+	}
+}
+
+type SSORequest struct {
+	IssuerURL    string   `json:"issuer_url" validate:"required,url,max=2048"`
+	ClientID     string   `json:"client_id" validate:"required,max=255"`
+	ClientSecret string   `json:"client_secret" validate:"required"`
+	Domains      []string `json:"domains" validate:"dive,fqdn"`
+}
+
+func (r *SSORequest) ToDomain() *domain.SSOConfig {
+	return &domain.SSOConfig{
+		IssuerURL:    r.IssuerURL,
+		ClientID:     r.ClientID,
+		ClientSecret: r.ClientSecret,
+		Domains:      r.Domains,
+	}
+}
+
+type Quotas struct {
+	MaxMembers           int `json:"max_members"`
+	MaxLists             int `json:"max_lists"`
+	MaxWebhookDeliveries int `json:"max_webhook_deliveries"`
+	MaxAPICalls          int `json:"max_api_calls"`
+	MaxIntegrationSyncs  int `json:"max_integration_syncs"`
+}
+
+// SSOConfig leaves out the client secret, it can be replaced but not read back.
+type SSOConfig struct {
+	IssuerURL string    `json:"issuer_url"`
+	ClientID  string    `json:"client_id"`
+	Domains   []string  `json:"domains"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Workspace struct {
+	Slug      string     `json:"slug"`
+	Name      string     `json:"name"`
+	OwnerUUID string     `json:"owner_uuid"`
+	Region    string     `json:"region"`
+	Plan      string     `json:"plan"`
+	Quotas    Quotas     `json:"quotas"`
+	SSO       *SSOConfig `json:"sso"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func NewWorkspace(workspace *domain.Workspace) *Workspace {
+	resp := &Workspace{
+		Slug:      workspace.Slug,
+		Name:      workspace.Name,
+		OwnerUUID: workspace.OwnerUUID,
+		Region:    workspace.Region,
+		Plan:      workspace.Plan,
+		Quotas: Quotas{
+			MaxMembers:           workspace.Quotas.MaxMembers,
+			MaxLists:             workspace.Quotas.MaxLists,
+			MaxWebhookDeliveries: workspace.Quotas.MaxWebhookDeliveries,
+			MaxAPICalls:          workspace.Quotas.MaxAPICalls,
+			MaxIntegrationSyncs:  workspace.Quotas.MaxIntegrationSyncs,
+		},
+		CreatedAt: workspace.CreatedAt,
+		UpdatedAt: workspace.UpdatedAt,
+	}
+
+	if workspace.SSO != nil {
+		resp.SSO = &SSOConfig{
+			IssuerURL: workspace.SSO.IssuerURL,
+			ClientID:  workspace.SSO.ClientID,
+			Domains:   workspace.SSO.Domains,
+			UpdatedAt: workspace.SSO.UpdatedAt,
+		}
+		if resp.SSO.Domains == nil {
+			resp.SSO.Domains = []string{}
+		}
+	}
+
+	return resp
+}
+
+type AdminProvisionRequest struct {
+	Email string `json:"email" validate:"required,rfc_email"`
+	// Password is only used to create the account when it doesn't exist yet.
+	Password string   `json:"password"`
+	Region   string   `json:"region" validate:"omitempty,max=64"`
+	Scopes   []string `json:"scopes" validate:"required,min=1,dive,oneof=support billing superadmin"`
+}
+
+func (r *AdminProvisionRequest) ToDomain() *domain.AdminProvision {
+	scopes := make([]domain.AdminScope, 0, len(r.Scopes))
+	for _, scope := range r.Scopes {
+		scopes = append(scopes, domain.AdminScope(scope))
+	}
+
+	return &domain.AdminProvision{
+		Email:    r.Email,
+		Password: r.Password,
+		Region:   r.Region,
+		Scopes:   scopes,
+	}
+}
+
+type ProvisionedAdmin struct {
+	UUID   string              `json:"uuid"`
+	Email  string              `json:"email"`
+	Scopes []domain.AdminScope `json:"scopes"`
+}
@@ -0,0 +1,57 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+// maxUserAgentLength caps the user agent stored with a push subscription.
+const maxUserAgentLength = 255
+
+type PushSubscription struct {
+	UUID      string    `json:"uuid"`
+	Endpoint  string    `json:"endpoint"`
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewPushSubscription(subscription *domain.PushSubscription) *PushSubscription {
+	return &PushSubscription{
+		UUID:      subscription.UUID,
+		Endpoint:  subscription.Endpoint,
+		UserAgent: subscription.UserAgent,
+		CreatedAt: subscription.CreatedAt,
+	}
+}
+
+func NewPushSubscriptions(subscriptions []*domain.PushSubscription) []*PushSubscription {
+	resp := make([]*PushSubscription, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		resp = append(resp, NewPushSubscription(subscription))
+	}
+
+	return resp
+}
+
+// PushSubscriptionRequest is the JSON of a browser PushSubscription, as returned by its toJSON.
+type PushSubscriptionRequest struct {
+	Endpoint string `json:"endpoint" validate:"required,url,max=2048"`
+	Keys     struct {
+		P256dh string `json:"p256dh" validate:"required,max=128"`
+		Auth   string `json:"auth" validate:"required,max=64"`
+	} `json:"keys"`
+}
+
+func (r *PushSubscriptionRequest) ToDomain(userAgent string) *domain.PushSubscription {
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	return &domain.PushSubscription{
+		Endpoint:  r.Endpoint,
+		P256dh:    r.Keys.P256dh,
+		Auth:      r.Keys.Auth,
+		UserAgent: userAgent,
+	}
+}
@@ -0,0 +1,67 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+// QuickAddRequest creates a todo from a single line like "Pay rent tomorrow 5pm #finance !high",
+// dry_run only returns how it was read. The text is capped like the title of a todo.
+type QuickAddRequest struct {
+	Text     string `json:"text" validate:"required,max=255"`
+	ListUUID string `json:"list_uuid"`
+	DryRun   bool   `json:"dry_run"`
+}
+
+func (r *QuickAddRequest) ToDomain() *domain.QuickAddCreate {
+	return &domain.QuickAddCreate{
+		Text:     r.Text,
+		ListUUID: r.ListUUID,
+		DryRun:   r.DryRun,
+	}
+}
+
+type QuickAddMatch struct {
+	Field string `json:"field"`
+	Text  string `json:"text"`
+}
+
+// QuickAdd is how the text of a quick add was read, the due date is in the timezone of the user.
+type QuickAdd struct {
+	Title    string           `json:"title"`
+	DueDate  *time.Time       `json:"due_date"`
+	Priority string           `json:"priority,omitempty"`
+	Tags     []string         `json:"tags"`
+	Matches  []*QuickAddMatch `json:"matches"`
+}
+
+type QuickAddResponse struct {
+	Parsed *QuickAdd `json:"parsed"`
+	// Todo is null for a dry run.
+	Todo   *Todo `json:"todo"`
+	DryRun bool  `json:"dry_run"`
+}
+
+func NewQuickAdd(result *domain.QuickAddResult) *QuickAddResponse {
+	quickAdd := result.QuickAdd
+	parsed := &QuickAdd{
+		Title:   quickAdd.Title,
+		DueDate: timeOrNil(quickAdd.DueDate),
+		Tags:    quickAdd.Tags,
+		Matches: make([]*QuickAddMatch, 0, len(quickAdd.Matches)),
+	}
+	if quickAdd.Priority != nil {
+		parsed.Priority = quickAdd.Priority.String()
+	}
+	for _, match := range quickAdd.Matches {
+		parsed.Matches = append(parsed.Matches, &QuickAddMatch{Field: string(match.Field), Text: match.Text})
+	}
+
+	resp := &QuickAddResponse{Parsed: parsed, DryRun: result.DryRun}
+	if result.Todo != nil {
+		resp.Todo = NewTodo(result.Todo)
+	}
+
+	return resp
+}
@@ -0,0 +1,135 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+// TrustedContact is a user that can approve the recovery of the account.
+type TrustedContact struct {
+	UUID      string    `json:"uuid"`
+	Email     string    `json:"email"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewTrustedContact(contact *domain.TrustedContact) *TrustedContact {
+	return &TrustedContact{
+		UUID:      contact.UUID,
+		Email:     contact.Email,
+		FirstName: contact.FirstName,
+		LastName:  contact.LastName,
+		CreatedAt: contact.CreatedAt,
+	}
+}
+
+// RecoverySettings are the trusted contacts of the account and its pending recovery requests.
+type RecoverySettings struct {
+	Threshold int                `json:"threshold"`
+	Contacts  []*TrustedContact  `json:"contacts"`
+	Requests  []*RecoveryRequest `json:"requests"`
+}
+
+func NewRecoverySettings(settings *domain.RecoverySettings) *RecoverySettings {
+	resp := &RecoverySettings{
+		Threshold: settings.Threshold,
+		Contacts:  make([]*TrustedContact, 0, len(settings.Contacts)),
+		Requests:  NewRecoveryRequests(settings.Requests),
+	}
+	for _, contact := range settings.Contacts {
+		resp.Contacts = append(resp.Contacts, NewTrustedContact(contact))
+	}
+
+	return resp
+}
+
+// RecoveryRequest is a pending recovery of an account, for its owner or a trusted contact.
+type RecoveryRequest struct {
+	UUID      string    `json:"uuid"`
+	UserUUID  string    `json:"user_uuid"`
+	UserName  string    `json:"user_name"`
+	Approvals int       `json:"approvals"`
+	Threshold int       `json:"threshold"`
+	Approved  bool      `json:"approved"`
+	ReadyAt   time.Time `json:"ready_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewRecoveryRequest(request *domain.RecoveryRequest) *RecoveryRequest {
+	return &RecoveryRequest{
+		UUID:      request.UUID,
+		UserUUID:  request.UserUUID,
+		UserName:  request.UserName,
+		Approvals: request.Approvals,
+		Threshold: request.Threshold,
+		Approved:  request.Approved,
+		ReadyAt:   request.ReadyAt,
+		ExpiresAt: request.ExpiresAt,
+		CreatedAt: request.CreatedAt,
+	}
+}
+
+func NewRecoveryRequests(requests []*domain.RecoveryRequest) []*RecoveryRequest {
+	resp := make([]*RecoveryRequest, 0, len(requests))
+	for _, request := range requests {
+		resp = append(resp, NewRecoveryRequest(request))
+	}
+
+	return resp
+}
+
+// RecoveryTicket is returned when a recovery is started, the token completes it.
+type RecoveryTicket struct {
+	Token     string    `json:"token"`
+	Region    string    `json:"region,omitempty"`
+	ReadyAt   time.Time `json:"ready_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func NewRecoveryTicket(ticket *domain.RecoveryTicket) *RecoveryTicket {
+	return &RecoveryTicket{
+		Token:     ticket.Token,
+		Region:    ticket.Region,
+		ReadyAt:   ticket.ReadyAt,
+		ExpiresAt: ticket.ExpiresAt,
+	}
+}
+
+// RecoverySetupRequest replaces the trusted contacts of the account, no contacts turn recovery
+// off. The current password is entered again.
+type RecoverySetupRequest struct {
+	ContactEmails []string `json:"contact_emails" validate:"max=5,dive,required,email"`
+	Threshold     int      `json:"threshold" validate:"min=0,max=5"`
+	Password      string   `json:"password" validate:"required"`
+}
+
+func (r *RecoverySetupRequest) ToDomain() *domain.RecoverySetup {
+	return &domain.RecoverySetup{
+		ContactEmails: r.ContactEmails,
+		Threshold:     r.Threshold,
+		Password:      r.Password,
+	}
+}
+
+// RecoveryStartRequest starts the recovery of the account of an email.
+type RecoveryStartRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// RecoveryCompleteRequest sets a new password with the token and region of an approved recovery.
+type RecoveryCompleteRequest struct {
+	Token       string `json:"token" validate:"required"`
+	Region      string `json:"region" validate:"omitempty,max=64"`
+	NewPassword string `json:"new_password" validate:"required,strong_password"`
+}
+
+func (r *RecoveryCompleteRequest) ToDomain() *domain.RecoveryCompletion {
+	return &domain.RecoveryCompletion{
+		Token:       r.Token,
+		Region:      r.Region,
+		NewPassword: r.NewPassword,
+	}
+}
@@ -0,0 +1,235 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type Rule struct {
+	UUID     string `json:"uuid"`
+	Name     string `json:"name"`
+	Position int    `json:"position"`
+	// Field and Operator are empty when the rule matches on its condition.
+	Field     string `json:"field"`
+	Operator  string `json:"operator"`
+	Value     string `json:"value"`
+	Condition string `json:"condition,omitempty"`
+	// Source and Channel are empty when the rule applies to every todo.
+	Source  string   `json:"source,omitempty"`
+	Channel string   `json:"channel,omitempty"`
+	Tags    []string `json:"tags"`
+	// Priority and ListUUID are empty when the rule leaves them as they are.
+	Priority  string        `json:"priority,omitempty"`
+	ListUUID  string        `json:"list_uuid,omitempty"`
+	Computed  *RuleComputed `json:"computed,omitempty"`
+	Stop      bool          `json:"stop"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// RuleComputed are the expressions that compute the fields of todos, empty ones compute nothing.
+type RuleComputed struct {
+	Title       string `json:"title,omitempty" validate:"max=4096"`
+	Description string `json:"description,omitempty" validate:"max=4096"`
+	Priority    string `json:"priority,omitempty" validate:"max=4096"`
+	Tags        string `json:"tags,omitempty" validate:"max=4096"`
+}
+
+func NewRule(rule *domain.Rule) *Rule {
+	resp := &Rule{
+		UUID:      rule.UUID,
+		Name:      rule.Name,
+		Position:  rule.Position,
+		Field:     string(rule.Field),
+		Operator:  string(rule.Operator),
+		Value:     rule.Value,
+		Condition: rule.Condition,
+		Source:    string(rule.Source),
+		Channel:   rule.Channel,
+		Tags:      rule.Tags,
+		ListUUID:  rule.ListUUID,
+		Stop:      rule.Stop,
+		CreatedAt: rule.CreatedAt,
+	}
+	if rule.Priority != nil {
+		resp.Priority = rule.Priority.String()
+	}
+	if !rule.Computed.Empty() {
+		resp.Computed = &RuleComputed{
+			Title:       rule.Computed.Title,
+			Description: rule.Computed.Description,
+			Priority:    rule.Computed.Priority,
+			Tags:        rule.Computed.Tags,
+		}
+	}
+
+	return resp
+}
+
+func NewRules(rules []*domain.Rule) []*Rule {
+	resp := make([]*Rule, 0, len(rules))
+	for _, rule := range rules {
+		resp = append(resp, NewRule(rule))
+	}
+
+	return resp
+}
+
+// RuleRequest creates a rule or replaces its definition. It needs at least one of tags, priority,
+// list_uuid and computed. Rules for a source may leave out the value to route everything captured
+// there, rules with a condition leave out field, operator and value.
+type RuleRequest struct {
+	Name      string       `json:"name" validate:"max=255"`
+	Field     string       `json:"field" validate:"required_without=Condition,omitempty,oneof=title description"`
+	Operator  string       `json:"operator" validate:"required_without=Condition,omitempty,oneof=contains equals starts_with ends_with"`
+	Value     string       `json:"value" validate:"required_without_all=Source Condition,max=255"`
+	Condition string       `json:"condition" validate:"max=4096"`
+	Source    string       `json:"source" validate:"omitempty,oneof=app slack voice email share agent"`
+	Channel   string       `json:"channel" validate:"max=255"`
+	Tags      []string     `json:"tags" validate:"max=20,dive,required,max=64,tag_name"`
+	Priority  string       `json:"priority" validate:"omitempty,oneof=low medium high urgent"`
+	ListUUID  string       `json:"list_uuid"`
+	Computed  RuleComputed `json:"computed"`
+	Stop      bool         `json:"stop"`
+}
+
+func (r *RuleRequest) ToDomain() *domain.RuleCreate {
+	ruleCreate := &domain.RuleCreate{
+		Name:      r.Name,
+		Field:     domain.RuleField(r.Field),
+		Operator:  domain.RuleOperator(r.Operator),
+		Value:     r.Value,
+		Source:    domain.CaptureSource(r.Source),
+		Channel:   r.Channel,
+		Condition: r.Condition,
+		Tags:      r.Tags,
+		ListUUID:  r.ListUUID,
+		Computed: domain.RuleComputed{
+			Title:       r.Computed.Title,
+			Description: r.Computed.Description,
+			Priority:    r.Computed.Priority,
+			Tags:        r.Computed.Tags,
+		},
+		Stop: r.Stop,
+	}
+	if priority, err := domain.ParsePriority(r.Priority); err == nil {
+		ruleCreate.Priority = &priority
+	}
+
+	return ruleCreate
+}
+
+// RuleOrderRequest lists the uuids of all rules of the user in the order to evaluate them.
+type RuleOrderRequest struct {
+	UUIDs []string `json:"uuids" validate:"required,dive,required"`
+}
+
+// RuleTestRequest is a todo to try the rules on, nothing is saved. Source and channel pretend
+// it was captured there.
+type RuleTestRequest struct {
+	Title       string `json:"title" validate:"required,max=255"`
+	Description string `json:"description"`
+	Source      string `json:"source" validate:"omitempty,oneof=app slack voice email share agent"`
+	Channel     string `json:"channel" validate:"max=255"`
+}
+
+func (r *RuleTestRequest) ToDomain() *domain.RuleSubject {
+	return &domain.RuleSubject{
+		Title:       r.Title,
+		Description: r.Description,
+		Source:      domain.CaptureSource(r.Source),
+		Channel:     r.Channel,
+	}
+}
+
+// RuleOutcome is what the rules would do to the todo of a RuleTestRequest. Title and description
+// are set when a rule computed them.
+type RuleOutcome struct {
+	Matched     []*Rule      `json:"matched"`
+	Tags        []string     `json:"tags"`
+	Priority    string       `json:"priority,omitempty"`
+	ListUUID    string       `json:"list_uuid,omitempty"`
+	Title       string       `json:"title,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Errors      []*RuleError `json:"errors"`
+}
+
+// RuleError is an expression of a rule that failed on the todo.
+type RuleError struct {
+	RuleUUID string `json:"rule_uuid"`
+	Target   string `json:"target"`
+	Error    string `json:"error"`
+}
+
+func NewRuleOutcome(outcome *domain.RuleOutcome) *RuleOutcome {
+	resp := &RuleOutcome{
+		Matched:     NewRules(outcome.Matched),
+		Tags:        outcome.Tags,
+		ListUUID:    outcome.ListUUID,
+		Title:       outcome.Title,
+		Description: outcome.Description,
+		Errors:      make([]*RuleError, 0, len(outcome.Errors)),
+	}
+	if outcome.Priority != nil {
+		resp.Priority = outcome.Priority.String()
+	}
+	for _, ruleErr := range outcome.Errors {
+		resp.Errors = append(resp.Errors, &RuleError{
+			RuleUUID: ruleErr.Rule.UUID,
+			Target:   string(ruleErr.Target),
+			Error:    ruleErr.Err.Error(),
+		})
+	}
+
+	return resp
+}
+
+// RuleExpressionRequest checks an expression before it is saved in a rule. Target is what the
+// expression is for and defaults to condition, sample is a todo to evaluate it against.
+type RuleExpressionRequest struct {
+	Expression string           `json:"expression" validate:"required,max=4096"`
+	Target     string           `json:"target" validate:"omitempty,oneof=condition title description priority tags"`
+	Sample     *RuleTestRequest `json:"sample"`
+}
+
+// ToDomain returns the target and the sample, which is nil when there is none.
+func (r *RuleExpressionRequest) ToDomain() (domain.RuleExpressionTarget, *domain.RuleSubject) {
+	target := domain.RuleTargetCondition
+	if r.Target != "" {
+		target = domain.RuleExpressionTarget(r.Target)
+	}
+	if r.Sample == nil {
+		return target, nil
+	}
+
+	return target, r.Sample.ToDomain()
+}
+
+// RuleExpressionCheck is the result of a RuleExpressionRequest. An invalid expression has the
+// error with its line and column, an expression that failed on the sample is valid but has an
+// error as well. Result is the value for the sample.
+type RuleExpressionCheck struct {
+	Target string      `json:"target"`
+	Valid  bool        `json:"valid"`
+	Type   string      `json:"type,omitempty"`
+	Error  string      `json:"error,omitempty"`
+	Line   int         `json:"line,omitempty"`
+	Column int         `json:"column,omitempty"`
+	Result interface{} `json:"result"`
+}
+
+func NewRuleExpressionCheck(check *domain.RuleExpressionCheck) *RuleExpressionCheck {
+	resp := &RuleExpressionCheck{
+		Target: string(check.Target),
+		Valid:  check.Valid,
+		Type:   check.Type,
+		Line:   check.Line,
+		Column: check.Column,
+		Result: check.Result,
+	}
+	if check.Err != nil {
+		resp.Error = check.Err.Error()
+	}
+
+	return resp
+}
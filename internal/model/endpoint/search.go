@@ -0,0 +1,26 @@
+package endpoint
+
+import "github.com/meowmix1337/the_recipe_book/internal/model/domain"
+
+type TodoSearchResult struct {
+	Todo    *Todo   `json:"todo"`
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+	// AttachmentUUID is the attachment the snippet is from when the todo itself didn't match.
+	AttachmentUUID string `json:"attachment_uuid,omitempty"`
+}
+
+func NewTodoSearchResults(results []*domain.TodoSearchResult) []*TodoSearchResult {
+	resp := make([]*TodoSearchResult, 0, len(results))
+	for _, result := range results {
+		resp = append(resp, &TodoSearchResult{
+			Todo:    NewTodo(result.Todo),
+			Rank:    result.Rank,
+			Snippet: result.Snippet,
+
+			AttachmentUUID: result.AttachmentUUID,
+		})
+	}
+
+	return resp
+}
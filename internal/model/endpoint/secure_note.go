@@ -0,0 +1,27 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+// SecureNote is the revealed secure note of a todo.
+type SecureNote struct {
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func NewSecureNote(note *domain.SecureNote) *SecureNote {
+	return &SecureNote{
+		Note:      note.Note,
+		CreatedAt: note.CreatedAt,
+		UpdatedAt: note.UpdatedAt,
+	}
+}
+
+// SecureNoteRequest sets the secure note of a todo, e.g. a locker code.
+type SecureNoteRequest struct {
+	Note string `json:"note" validate:"required,max=2048"`
+}
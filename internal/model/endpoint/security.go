@@ -0,0 +1,52 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+// SecurityEventPayload is the JSON body of a security event webhook. It uses the envelope of
+// EventPayload with a flat body that SIEMs can index without a custom parser.
+type SecurityEventPayload struct {
+	ID        string            `json:"id"`
+	Event     string            `json:"event"`
+	CreatedAt time.Time         `json:"created_at"`
+	Data      SecurityEventData `json:"data"`
+}
+
+type SecurityEventData struct {
+	Outcome   string            `json:"outcome"`
+	UserUUID  string            `json:"user_uuid"`
+	Email     string            `json:"email,omitempty"`
+	Username  string            `json:"username,omitempty"`
+	Child     bool              `json:"child"`
+	IP        string            `json:"ip,omitempty"`
+	UserAgent string            `json:"user_agent,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
+	Details   map[string]string `json:"details,omitempty"`
+}
+
+func NewSecurityEventPayload(id string, event *domain.SecurityEvent) *SecurityEventPayload {
+	outcome := "success"
+	if event.Type == domain.EventSecurityLoginFailed {
+		outcome = "failure"
+	}
+
+	return &SecurityEventPayload{
+		ID:        id,
+		Event:     string(event.Type),
+		CreatedAt: event.OccurredAt,
+		Data: SecurityEventData{
+			Outcome:   outcome,
+			UserUUID:  event.User.UUID,
+			Email:     event.User.Email,
+			Username:  event.User.Username,
+			Child:     event.User.IsChild(),
+			IP:        event.IP,
+			UserAgent: event.UserAgent,
+			RequestID: event.RequestID,
+			Details:   event.Details,
+		},
+	}
+}
@@ -0,0 +1,89 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type SharedList struct {
+	List
+	Role string `json:"role"`
+}
+
+func NewSharedList(shared *domain.SharedList) *SharedList {
+	return &SharedList{
+		List: *NewList(shared.List),
+		Role: string(shared.Role),
+	}
+}
+
+func NewSharedLists(shared []*domain.SharedList) []*SharedList {
+	resp := make([]*SharedList, 0, len(shared))
+	for _, list := range shared {
+		resp = append(resp, NewSharedList(list))
+	}
+
+	return resp
+}
+
+type ListMember struct {
+	UserUUID  string    `json:"user_uuid"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewListMembers(members []*domain.ListMember) []*ListMember {
+	resp := make([]*ListMember, 0, len(members))
+	for _, member := range members {
+		resp = append(resp, &ListMember{
+			UserUUID:  member.UserUUID,
+			Email:     member.Email,
+			Role:      string(member.Role),
+			CreatedAt: member.CreatedAt,
+		})
+	}
+
+	return resp
+}
+
+type ListInvitation struct {
+	UUID      string    `json:"uuid"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewListInvitation(invitation *domain.ListInvitation) *ListInvitation {
+	return &ListInvitation{
+		UUID:      invitation.UUID,
+		Email:     invitation.Email,
+		Role:      string(invitation.Role),
+		ExpiresAt: invitation.ExpiresAt,
+		CreatedAt: invitation.CreatedAt,
+	}
+}
+
+func NewListInvitations(invitations []*domain.ListInvitation) []*ListInvitation {
+	resp := make([]*ListInvitation, 0, len(invitations))
+	for _, invitation := range invitations {
+		resp = append(resp, NewListInvitation(invitation))
+	}
+
+	return resp
+}
+
+type ListInvitationRequest struct {
+	Email string `json:"email" validate:"required,rfc_email"`
+	Role  string `json:"role" validate:"required,oneof=viewer editor"`
+}
+
+type ListMemberRequest struct {
+	Role string `json:"role" validate:"required,oneof=viewer editor"`
+}
+
+type InvitationAcceptRequest struct {
+	Token string `json:"token" validate:"required"`
+}
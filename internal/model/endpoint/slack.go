@@ -0,0 +1,81 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type SlackConnection struct {
+	// Delivery tells whether messages go to an incoming "webhook" or are posted by a "bot".
+	Delivery string   `json:"delivery"`
+	Channel  string   `json:"channel,omitempty"`
+	Events   []string `json:"events"`
+	// Linked tells whether a Slack user was linked for slash commands.
+	Linked bool `json:"linked"`
+	// LinkCode is only returned when the connection is saved.
+	LinkCode          string     `json:"link_code,omitempty"`
+	LinkCodeExpiresAt *time.Time `json:"link_code_expires_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+func NewSlackConnection(conn *domain.SlackConnection, linkCode string) *SlackConnection {
+	events := make([]string, 0, len(conn.Events))
+	for _, event := range conn.Events {
+		events = append(events, string(event))
+	}
+
+	resp := &SlackConnection{
+		Delivery:  "bot",
+		Channel:   conn.Channel,
+		Events:    events,
+		Linked:    conn.Linked(),
+		LinkCode:  linkCode,
+		CreatedAt: conn.CreatedAt,
+		UpdatedAt: conn.UpdatedAt,
+	}
+	if conn.WebhookURL != "" {
+		resp.Delivery = "webhook"
+	}
+	if linkCode != "" {
+		resp.LinkCodeExpiresAt = &conn.LinkCodeExpiresAt
+	}
+
+	return resp
+}
+
+// SlackConnectionRequest connects an incoming webhook, or a bot token and the channel the bot
+// posts to. The bot token may be left out to keep the stored one.
+type SlackConnectionRequest struct {
+	WebhookURL string   `json:"webhook_url" validate:"omitempty,url,max=2048"`
+	BotToken   string   `json:"bot_token" validate:"max=255"`
+	Channel    string   `json:"channel" validate:"max=255"`
+	Events     []string `json:"events" validate:"required,min=1,dive,oneof=assigned overdue completed"`
+}
+
+func (r *SlackConnectionRequest) ToDomain() *domain.SlackConnectionUpdate {
+	update := &domain.SlackConnectionUpdate{
+		WebhookURL: r.WebhookURL,
+		BotToken:   r.BotToken,
+		Channel:    r.Channel,
+	}
+	for _, event := range r.Events {
+		if slackEvent, err := domain.ParseSlackEvent(event); err == nil {
+			update.Events = append(update.Events, slackEvent)
+		}
+	}
+
+	return update
+}
+
+// SlackCommandResponse is the reply to a slash command, ephemeral replies are only shown to the
+// Slack user who sent the command.
+type SlackCommandResponse struct {
+	ResponseType string `json:"response_type"`
+	Text         string `json:"text"`
+}
+
+func NewSlackCommandResponse(text string) *SlackCommandResponse {
+	return &SlackCommandResponse{ResponseType: "ephemeral", Text: text}
+}
@@ -0,0 +1,45 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type ListSnapshotSchedule struct {
+	UUID       string     `json:"uuid"`
+	Recipients []string   `json:"recipients"`
+	Frequency  string     `json:"frequency"`
+	NextRunAt  time.Time  `json:"next_run_at"`
+	LastSentAt *time.Time `json:"last_sent_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func NewListSnapshotSchedule(schedule *domain.ListSnapshotSchedule) *ListSnapshotSchedule {
+	return &ListSnapshotSchedule{
+		UUID:       schedule.UUID,
+		Recipients: schedule.Recipients,
+		Frequency:  string(schedule.Frequency),
+		NextRunAt:  schedule.NextRunAt,
+		LastSentAt: timeOrNil(schedule.LastSentAt),
+		CreatedAt:  schedule.CreatedAt,
+	}
+}
+
+func NewListSnapshotSchedules(schedules []*domain.ListSnapshotSchedule) []*ListSnapshotSchedule {
+	resp := make([]*ListSnapshotSchedule, 0, len(schedules))
+	for _, schedule := range schedules {
+		resp = append(resp, NewListSnapshotSchedule(schedule))
+	}
+
+	return resp
+}
+
+type ListSnapshotRequest struct {
+	Recipients []string `json:"recipients" validate:"required,min=1,max=20,dive,required,rfc_email"`
+}
+
+type ListSnapshotScheduleRequest struct {
+	Recipients []string `json:"recipients" validate:"required,min=1,max=20,dive,required,rfc_email"`
+	Frequency  string   `json:"frequency" validate:"required,oneof=daily weekly"`
+}
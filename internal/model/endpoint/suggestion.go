@@ -0,0 +1,39 @@
+package endpoint
+
+import (
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type TodoSuggestion struct {
+	Todo    *Todo              `json:"todo"`
+	Stalled []string           `json:"stalled"`
+	Actions []*SuggestedAction `json:"actions"`
+}
+
+type SuggestedAction struct {
+	Action    string   `json:"action"`
+	Reason    string   `json:"reason"`
+	Delegates []string `json:"delegates,omitempty"`
+}
+
+func NewTodoSuggestions(suggestions []*domain.TodoSuggestion) []*TodoSuggestion {
+	resp := make([]*TodoSuggestion, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		actions := make([]*SuggestedAction, 0, len(suggestion.Actions))
+		for _, action := range suggestion.Actions {
+			actions = append(actions, &SuggestedAction{
+				Action:    string(action.Action),
+				Reason:    action.Reason,
+				Delegates: action.Delegates,
+			})
+		}
+
+		resp = append(resp, &TodoSuggestion{
+			Todo:    NewTodo(suggestion.Todo),
+			Stalled: suggestion.Stalled,
+			Actions: actions,
+		})
+	}
+
+	return resp
+}
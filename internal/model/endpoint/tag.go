@@ -0,0 +1,66 @@
+package endpoint
+
+import "github.com/meowmix1337/the_recipe_book/internal/model/domain"
+
+type Tag struct {
+	UUID string `json:"uuid"`
+	Name string `json:"name"`
+	// Group and Label split the name at the group separator, Group is empty for tags without one.
+	Group string `json:"group"`
+	Label string `json:"label"`
+	Color string `json:"color,omitempty"`
+}
+
+func NewTag(tag *domain.Tag) *Tag {
+	return &Tag{
+		UUID:  tag.UUID,
+		Name:  tag.Name,
+		Group: tag.Group(),
+		Label: tag.Label(),
+		Color: tag.Color,
+	}
+}
+
+func NewTags(tags []*domain.Tag) []*Tag {
+	resp := make([]*Tag, 0, len(tags))
+	for _, tag := range tags {
+		resp = append(resp, NewTag(tag))
+	}
+
+	return resp
+}
+
+type TagCreateRequest struct {
+	Name  string `json:"name" validate:"required,max=64,tag_name"`
+	Color string `json:"color" validate:"omitempty,len=7,hexcolor"`
+}
+
+// TagUpdateRequest renames a tag and sets its color, fields left out are kept. An empty color
+// removes it.
+type TagUpdateRequest struct {
+	Name  *string `json:"name" validate:"omitempty,min=1,max=64,tag_name"`
+	Color *string `json:"color" validate:"omitempty,len=7,hexcolor"`
+}
+
+type TagMergeRequest struct {
+	// SourceUUID is the tag folded into the tag of the URL.
+	SourceUUID string `json:"source_uuid" validate:"required"`
+}
+
+type TagMergeResult struct {
+	Tag        *Tag `json:"tag"`
+	Retagged   int  `json:"retagged"`
+	Duplicates int  `json:"duplicates"`
+}
+
+func NewTagMergeResult(result *domain.TagMergeResult) *TagMergeResult {
+	return &TagMergeResult{
+		Tag:        NewTag(result.Target),
+		Retagged:   result.Retagged,
+		Duplicates: result.Duplicates,
+	}
+}
+
+type TodoTagsRequest struct {
+	Tags []string `json:"tags" validate:"required,min=1,dive,required,max=64,tag_name"`
+}
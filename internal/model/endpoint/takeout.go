@@ -0,0 +1,32 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type Takeout struct {
+	UUID   string `json:"uuid"`
+	Status string `json:"status"`
+	Size   int64  `json:"size,omitempty"`
+	Error  string `json:"error,omitempty"`
+	// DownloadURL is set while the archive can be downloaded, it expires with it.
+	DownloadURL string     `json:"download_url,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func NewTakeout(takeout *domain.Takeout, downloadURL string) *Takeout {
+	return &Takeout{
+		UUID:        takeout.UUID,
+		Status:      string(takeout.Status),
+		Size:        takeout.Size,
+		Error:       takeout.Error,
+		DownloadURL: downloadURL,
+		FinishedAt:  timeOrNil(takeout.FinishedAt),
+		ExpiresAt:   timeOrNil(takeout.ExpiresAt),
+		CreatedAt:   takeout.CreatedAt,
+	}
+}
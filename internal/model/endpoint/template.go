@@ -0,0 +1,100 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type TodoTemplate struct {
+	UUID            string   `json:"uuid"`
+	Name            string   `json:"name"`
+	Title           string   `json:"title"`
+	Description     string   `json:"description"`
+	Priority        string   `json:"priority"`
+	EstimateMinutes int      `json:"estimate_minutes"`
+	Tags            []string `json:"tags"`
+	Subtasks        []string `json:"subtasks"`
+	// DueInDays is empty for templates whose todos have no due date.
+	DueInDays *int      `json:"due_in_days,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func NewTodoTemplate(template *domain.TodoTemplate) *TodoTemplate {
+	resp := &TodoTemplate{
+		UUID:            template.UUID,
+		Name:            template.Name,
+		Title:           template.Title,
+		Description:     template.Description,
+		Priority:        template.Priority.String(),
+		EstimateMinutes: int(template.Estimate / time.Minute),
+		Tags:            template.Tags,
+		Subtasks:        template.Subtasks,
+		CreatedAt:       template.CreatedAt,
+		UpdatedAt:       template.UpdatedAt,
+	}
+	if template.DueIn != nil {
+		days := int(*template.DueIn / (24 * time.Hour))
+		resp.DueInDays = &days
+	}
+
+	return resp
+}
+
+func NewTodoTemplates(templates []*domain.TodoTemplate) []*TodoTemplate {
+	resp := make([]*TodoTemplate, 0, len(templates))
+	for _, template := range templates {
+		resp = append(resp, NewTodoTemplate(template))
+	}
+
+	return resp
+}
+
+// TodoTemplateRequest creates a template or replaces it. The subtasks become a checklist of the
+// todos created from it, due_in_days gives them a due date that many days after they were created.
+type TodoTemplateRequest struct {
+	Name        string   `json:"name" validate:"max=255"`
+	Title       string   `json:"title" validate:"required,max=255"`
+	Description string   `json:"description"`
+	Priority    string   `json:"priority" validate:"omitempty,oneof=low medium high urgent"`
+	Estimate    int      `json:"estimate_minutes" validate:"min=0,max=1440"`
+	Tags        []string `json:"tags" validate:"max=20,dive,required,max=64,tag_name"`
+	Subtasks    []string `json:"subtasks" validate:"max=100,dive,required,max=255"`
+	DueInDays   *int     `json:"due_in_days" validate:"omitempty,min=1,max=3650"`
+}
+
+func (r *TodoTemplateRequest) ToDomain() *domain.TodoTemplateCreate {
+	templateCreate := &domain.TodoTemplateCreate{
+		Name:        r.Name,
+		Title:       r.Title,
+		Description: r.Description,
+		Priority:    domain.PriorityMedium,
+		Estimate:    time.Duration(r.Estimate) * time.Minute,
+		Tags:        r.Tags,
+		Subtasks:    r.Subtasks,
+	}
+	if priority, err := domain.ParsePriority(r.Priority); err == nil {
+		templateCreate.Priority = priority
+	}
+	if r.DueInDays != nil {
+		dueIn := time.Duration(*r.DueInDays) * 24 * time.Hour
+		templateCreate.DueIn = &dueIn
+	}
+
+	return templateCreate
+}
+
+// TodoFromTemplateRequest creates a todo from a template, in the list of list_uuid and with
+// another title when given.
+type TodoFromTemplateRequest struct {
+	ListUUID string `json:"list_uuid"`
+	Title    string `json:"title" validate:"max=255"`
+}
+
+func (r *TodoFromTemplateRequest) ToDomain() *domain.TodoFromTemplate {
+	return &domain.TodoFromTemplate{
+		ListUUID: r.ListUUID,
+		Title:    r.Title,
+	}
+}
@@ -0,0 +1,276 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type Todo struct {
+	UUID        string     `json:"uuid"`
+	ListUUID    string     `json:"list_uuid,omitempty"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Priority    string     `json:"priority"`
+	DueDate     *time.Time `json:"due_date"`
+	Estimate    *int       `json:"estimate_minutes"`
+	Postponed   int        `json:"postponed_count"`
+	Completed   bool       `json:"completed"`
+	CompletedAt *time.Time `json:"completed_at"`
+	ArchivedAt  *time.Time `json:"archived_at"`
+	Tags        []string   `json:"tags"`
+	// TagDetails has the color and group of every tag in Tags, in the same order.
+	TagDetails []*Tag   `json:"tag_details"`
+	DeadLinks  []string `json:"dead_links"`
+	Version    int      `json:"version"`
+	// Ciphertext replaces the title and description of todos of encrypted lists, key_version is
+	// the list key it was encrypted with.
+	Ciphertext string `json:"ciphertext,omitempty"`
+	KeyVersion int    `json:"key_version,omitempty"`
+	// Status is the column of the todo on the board of its list, position its place in the
+	// column from 1 on. Todos that weren't placed on the board yet have neither.
+	Status   string `json:"status,omitempty"`
+	Position int    `json:"position,omitempty"`
+	// Rank orders the todos by hand with ?sort=rank, it is only set once the todo was reordered.
+	Rank string `json:"rank,omitempty"`
+	// HasSecureNote tells whether the todo has a secure note, it is only returned by the reveal
+	// endpoint.
+	HasSecureNote bool      `json:"has_secure_note"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func NewTodo(todo *domain.Todo) *Todo {
+	tags := make([]string, 0, len(todo.Tags))
+	for _, tag := range todo.Tags {
+		tags = append(tags, tag.Name)
+	}
+	deadLinks := todo.DeadLinks
+	if deadLinks == nil {
+		deadLinks = []string{}
+	}
+
+	return &Todo{
+		UUID:          todo.UUID,
+		ListUUID:      todo.ListUUID,
+		Title:         todo.Title,
+		Description:   todo.Description,
+		Priority:      todo.Priority.String(),
+		DueDate:       timeOrNil(todo.DueDate),
+		Estimate:      minutesOrNil(todo.Estimate),
+		Postponed:     todo.Postponed,
+		Completed:     todo.Completed(),
+		CompletedAt:   timeOrNil(todo.CompletedAt),
+		ArchivedAt:    timeOrNil(todo.ArchivedAt),
+		Tags:          tags,
+		TagDetails:    NewTags(todo.Tags),
+		DeadLinks:     deadLinks,
+		Version:       todo.Version,
+		Ciphertext:    todo.Ciphertext,
+		KeyVersion:    todo.KeyVersion,
+		Status:        todo.Status,
+		Position:      todo.Position,
+		Rank:          todo.Rank,
+		HasSecureNote: todo.HasSecureNote,
+		CreatedAt:     todo.CreatedAt,
+		UpdatedAt:     todo.UpdatedAt,
+	}
+}
+
+func NewTodos(todos []*domain.Todo) []*Todo {
+	resp := make([]*Todo, 0, len(todos))
+	for _, todo := range todos {
+		resp = append(resp, NewTodo(todo))
+	}
+
+	return resp
+}
+
+type TodoCreateRequest struct {
+	ListUUID    string     `json:"list_uuid"`
+	Title       string     `json:"title" validate:"required_without=Ciphertext,max=255"`
+	Description string     `json:"description"`
+	Priority    string     `json:"priority" validate:"omitempty,oneof=low medium high urgent"`
+	DueDate     *time.Time `json:"due_date" validate:"omitempty,future"`
+	Estimate    int        `json:"estimate_minutes" validate:"min=0,max=1440"`
+	Tags        []string   `json:"tags" validate:"dive,max=64,tag_name"`
+	// Source is set by the clients that capture todos elsewhere, Slack and voice todos don't come
+	// through this request. Channel is the address or app the todo was captured from.
+	Source  string `json:"source" validate:"omitempty,oneof=app email share"`
+	Channel string `json:"channel" validate:"max=255"`
+	// Ciphertext replaces the title and description of todos of encrypted lists, encrypted with
+	// the list key of key_version. BlindIndexes are the tokens the todo can be filtered by.
+	Ciphertext   string   `json:"ciphertext" validate:"omitempty,max=65536,base64"`
+	KeyVersion   int      `json:"key_version" validate:"required_with=Ciphertext,min=0"`
+	BlindIndexes []string `json:"blind_indexes" validate:"max=32,dive,required,max=128"`
+}
+
+func (t *TodoCreateRequest) ToDomain() *domain.TodoCreate {
+	todo := &domain.TodoCreate{
+		ListUUID:     t.ListUUID,
+		Title:        t.Title,
+		Description:  t.Description,
+		Priority:     domain.PriorityMedium,
+		Estimate:     time.Duration(t.Estimate) * time.Minute,
+		Tags:         t.Tags,
+		Ciphertext:   t.Ciphertext,
+		KeyVersion:   t.KeyVersion,
+		BlindIndexes: t.BlindIndexes,
+		Source:       domain.CaptureSourceApp,
+		Channel:      t.Channel,
+	}
+	if t.Source != "" {
+		todo.Source = domain.CaptureSource(t.Source)
+	}
+	if priority, err := domain.ParsePriority(t.Priority); err == nil {
+		todo.Priority = priority
+	}
+	if t.DueDate != nil {
+		todo.DueDate = *t.DueDate
+	}
+
+	return todo
+}
+
+type TodoUpdateRequest struct {
+	Title       *string    `json:"title" validate:"omitempty,min=1,max=255"`
+	Description *string    `json:"description"`
+	Priority    *string    `json:"priority" validate:"omitempty,oneof=low medium high urgent"`
+	DueDate     *time.Time `json:"due_date" validate:"omitempty,future"`
+	// Estimate clears the estimate when zero.
+	Estimate  *int  `json:"estimate_minutes" validate:"omitempty,min=0,max=1440"`
+	Completed *bool `json:"completed"`
+	// Ciphertext encrypts a todo of an encrypted list again, e.g. with the key of key_version
+	// after a rotation. BlindIndexes replace the blind indexes of the todo unless null.
+	Ciphertext   *string  `json:"ciphertext" validate:"omitempty,min=1,max=65536,base64"`
+	KeyVersion   *int     `json:"key_version" validate:"required_with=Ciphertext,omitempty,min=1"`
+	BlindIndexes []string `json:"blind_indexes" validate:"max=32,dive,required,max=128"`
+	// Version is the version the update is based on, the If-Match header takes precedence.
+	Version *int `json:"version" validate:"omitempty,min=1"`
+	// Force completes the todo even though open todos block it.
+	Force bool `json:"force"`
+}
+
+func (t *TodoUpdateRequest) ToDomain() *domain.TodoUpdate {
+	todo := &domain.TodoUpdate{
+		Title:        t.Title,
+		Description:  t.Description,
+		DueDate:      t.DueDate,
+		Completed:    t.Completed,
+		Ciphertext:   t.Ciphertext,
+		KeyVersion:   t.KeyVersion,
+		BlindIndexes: t.BlindIndexes,
+		Version:      t.Version,
+		Force:        t.Force,
+	}
+	if t.Priority != nil {
+		if priority, err := domain.ParsePriority(*t.Priority); err == nil {
+			todo.Priority = &priority
+		}
+	}
+	if t.Estimate != nil {
+		estimate := time.Duration(*t.Estimate) * time.Minute
+		todo.Estimate = &estimate
+	}
+
+	return todo
+}
+
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+func minutesOrNil(d time.Duration) *int {
+	if d <= 0 {
+		return nil
+	}
+
+	minutes := int(d.Minutes())
+	return &minutes
+}
+
+type TodoMatch struct {
+	Todo       *Todo   `json:"todo"`
+	Confidence float64 `json:"confidence"`
+}
+
+func NewTodoMatch(match *domain.TodoMatch) *TodoMatch {
+	return &TodoMatch{
+		Todo:       NewTodo(match.Todo),
+		Confidence: match.Confidence,
+	}
+}
+
+func NewTodoMatches(matches []*domain.TodoMatch) []*TodoMatch {
+	resp := make([]*TodoMatch, 0, len(matches))
+	for _, match := range matches {
+		resp = append(resp, NewTodoMatch(match))
+	}
+
+	return resp
+}
+
+type TodoMoveRequest struct {
+	// ListUUID is the destination list, empty moves the todo out of its list.
+	ListUUID string `json:"list_uuid"`
+	// Version is the version the move is based on, the If-Match header takes precedence.
+	Version *int `json:"version" validate:"omitempty,min=1"`
+}
+
+func (t *TodoMoveRequest) ToDomain() *domain.TodoMove {
+	return &domain.TodoMove{
+		ListUUID: t.ListUUID,
+		Version:  t.Version,
+	}
+}
+
+type TodoSplitRequest struct {
+	// ListUUID puts the new todos in another list, by default they go to the list of the todo.
+	ListUUID string `json:"list_uuid"`
+	// NewList creates a list named after the todo for the new todos.
+	NewList bool `json:"new_list"`
+	// Version is the version the split is based on, the If-Match header takes precedence.
+	Version *int `json:"version" validate:"omitempty,min=1"`
+}
+
+func (t *TodoSplitRequest) ToDomain() *domain.TodoSplit {
+	return &domain.TodoSplit{
+		ListUUID: t.ListUUID,
+		NewList:  t.NewList,
+		Version:  t.Version,
+	}
+}
+
+type TodoSplitResponse struct {
+	Todo  *Todo   `json:"todo"`
+	List  *List   `json:"list,omitempty"`
+	Todos []*Todo `json:"todos"`
+}
+
+func NewTodoSplit(result *domain.TodoSplitResult) *TodoSplitResponse {
+	resp := &TodoSplitResponse{
+		Todo:  NewTodo(result.Todo),
+		Todos: NewTodos(result.Todos),
+	}
+	if result.List != nil {
+		resp.List = NewList(result.List)
+	}
+
+	return resp
+}
+
+// TodoReorderRequest moves a todo right after after_uuid in the manual order, or to the top
+// without it.
+type TodoReorderRequest struct {
+	AfterUUID string `json:"after_uuid"`
+}
+
+func (t *TodoReorderRequest) ToDomain() *domain.TodoReorder {
+	return &domain.TodoReorder{
+		AfterUUID: t.AfterUUID,
+	}
+}
@@ -0,0 +1,74 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+// DependencyGraph is a todo with the todos it waits for and those waiting for it. BlockedBy are
+// the open todos that block it directly, it can only be completed with force while there are any.
+type DependencyGraph struct {
+	TodoUUID  string            `json:"todo_uuid"`
+	Blocked   bool              `json:"blocked"`
+	BlockedBy []string          `json:"blocked_by"`
+	Todos     []*DependencyNode `json:"todos"`
+	Edges     []*DependencyEdge `json:"edges"`
+}
+
+// DependencyNode is a todo of a dependency graph, Blocked tells whether open todos block it.
+type DependencyNode struct {
+	UUID       string     `json:"uuid"`
+	Title      string     `json:"title"`
+	Ciphertext string     `json:"ciphertext,omitempty"`
+	Priority   string     `json:"priority"`
+	DueDate    *time.Time `json:"due_date"`
+	Completed  bool       `json:"completed"`
+	Blocked    bool       `json:"blocked"`
+}
+
+// DependencyEdge is a todo that is blocked by another one.
+type DependencyEdge struct {
+	TodoUUID      string    `json:"todo_uuid"`
+	BlockedByUUID string    `json:"blocked_by_uuid"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func NewDependencyGraph(graph *domain.DependencyGraph) *DependencyGraph {
+	resp := &DependencyGraph{
+		TodoUUID:  graph.Todo.UUID,
+		BlockedBy: []string{},
+		Todos:     make([]*DependencyNode, 0, len(graph.Todos)),
+		Edges:     make([]*DependencyEdge, 0, len(graph.Edges)),
+	}
+	for _, blocker := range graph.BlockedBy(graph.Todo.ID) {
+		resp.BlockedBy = append(resp.BlockedBy, blocker.UUID)
+	}
+	resp.Blocked = len(resp.BlockedBy) > 0
+
+	for _, todo := range graph.Todos {
+		resp.Todos = append(resp.Todos, &DependencyNode{
+			UUID:       todo.UUID,
+			Title:      todo.Title,
+			Ciphertext: todo.Ciphertext,
+			Priority:   todo.Priority.String(),
+			DueDate:    timeOrNil(todo.DueDate),
+			Completed:  todo.Completed(),
+			Blocked:    len(graph.BlockedBy(todo.ID)) > 0,
+		})
+	}
+	for _, edge := range graph.Edges {
+		resp.Edges = append(resp.Edges, &DependencyEdge{
+			TodoUUID:      edge.TodoUUID,
+			BlockedByUUID: edge.BlockerUUID,
+			CreatedAt:     edge.CreatedAt,
+		})
+	}
+
+	return resp
+}
+
+// TodoBlockRequest marks the todo as blocked by the todo of blocked_by_uuid, of the same list.
+type TodoBlockRequest struct {
+	BlockedByUUID string `json:"blocked_by_uuid" validate:"required,max=255"`
+}
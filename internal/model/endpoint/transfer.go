@@ -0,0 +1,59 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type TodoImportResult struct {
+	DryRun     bool                `json:"dry_run"`
+	Created    []*TodoImportRecord `json:"created"`
+	Duplicates []*TodoImportRecord `json:"duplicates"`
+	Errors     []*TodoImportError  `json:"errors"`
+}
+
+type TodoImportRecord struct {
+	Line     int        `json:"line"`
+	UUID     string     `json:"uuid,omitempty"`
+	ListUUID string     `json:"list_uuid,omitempty"`
+	Title    string     `json:"title"`
+	DueDate  *time.Time `json:"due_date"`
+}
+
+type TodoImportError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+func NewTodoImportResult(result *domain.TodoImportResult) *TodoImportResult {
+	resp := &TodoImportResult{
+		DryRun:     result.DryRun,
+		Created:    newTodoImportRecords(result.Created),
+		Duplicates: newTodoImportRecords(result.Duplicates),
+		Errors:     make([]*TodoImportError, 0, len(result.Errors)),
+	}
+	for _, importErr := range result.Errors {
+		resp.Errors = append(resp.Errors, &TodoImportError{
+			Line:    importErr.Line,
+			Message: importErr.Message,
+		})
+	}
+
+	return resp
+}
+
+func newTodoImportRecords(records []*domain.TodoImportRecord) []*TodoImportRecord {
+	resp := make([]*TodoImportRecord, 0, len(records))
+	for _, record := range records {
+		resp = append(resp, &TodoImportRecord{
+			Line:     record.Line,
+			UUID:     record.UUID,
+			ListUUID: record.ListUUID,
+			Title:    record.Title,
+			DueDate:  timeOrNil(record.DueDate),
+		})
+	}
+
+	return resp
+}
@@ -1,32 +1,96 @@
 package endpoint
 
-import "github.com/meowmix1337/the_recipe_book/internal/model/domain"
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type User struct {
+	UUID      string    `json:"uuid"`
+	Email     string    `json:"email"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Timezone  string    `json:"timezone"`
+	Locale    string    `json:"locale"`
+	AvatarURL string    `json:"avatar_url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewUser(user *domain.User) *User {
+	return &User{
+		UUID:      user.UUID,
+		Email:     user.Email,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Timezone:  user.Timezone,
+		Locale:    user.Locale,
+		AvatarURL: user.AvatarURL,
+		CreatedAt: user.CreatedAt,
+	}
+}
+
+func NewUsers(users []*domain.User) []*User {
+	resp := make([]*User, 0, len(users))
+	for _, user := range users {
+		resp = append(resp, NewUser(user))
+	}
+
+	return resp
+}
+
+// UserProfileRequest changes the fields that are set, empty strings clear a field.
+type UserProfileRequest struct {
+	FirstName *string `json:"first_name" validate:"omitempty,max=255"`
+	LastName  *string `json:"last_name" validate:"omitempty,max=255"`
+	// Timezone is an IANA name like Europe/Berlin, reminders and due dates are read in it.
+	Timezone  *string `json:"timezone" validate:"omitempty,max=64"`
+	Locale    *string `json:"locale" validate:"omitempty,max=35"`
+	AvatarURL *string `json:"avatar_url" validate:"omitempty,url,max=2048"`
+}
+
+func (r *UserProfileRequest) ToDomain() *domain.UserProfileUpdate {
+	return &domain.UserProfileUpdate{
+		FirstName: r.FirstName,
+		LastName:  r.LastName,
+		Timezone:  r.Timezone,
+		Locale:    r.Locale,
+		AvatarURL: r.AvatarURL,
+	}
+}
 
 type UserSignupRequest struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required"`
+	Email    string `json:"email" validate:"required,rfc_email"`
+	Password string `json:"password" validate:"required,strong_password"`
+	// Region is the data region to store the account in, e.g. eu, it can't be changed later.
+	Region string `json:"region" validate:"omitempty,max=64"`
 }
 
 func (u *UserSignupRequest) ToDomain() *domain.UserSignup {
 	return &domain.UserSignup{
 		Email:    u.Email,
 		Password: u.Password,
+		Region:   u.Region,
 	}
 }
 
-type UserSignupError struct {
-	Message string      `json:"message"`
-	Errors  interface{} `json:"errors"`
+// UnlockRequest lifts a lockout with the token and region of the emailed unlock link.
+type UnlockRequest struct {
+	Token  string `json:"token" validate:"required"`
+	Region string `json:"region" validate:"omitempty,max=64"`
 }
 
+// UserCredentialsRequest logs in with an email, or with a username for child accounts.
 type UserCredentialsRequest struct {
-	Email    string `json:"email" validate:"required,email"`
+	Email    string `json:"email" validate:"required_without=Username,omitempty,rfc_email"`
+	Username string `json:"username" validate:"required_without=Email"`
 	Password string `json:"password" validate:"required"`
 }
 
 func (u *UserCredentialsRequest) ToDomain() *domain.UserCredentials {
 	return &domain.UserCredentials{
 		Email:    u.Email,
+		Username: u.Username,
 		Password: u.Password,
 	}
 }
@@ -34,3 +98,16 @@ func (u *UserCredentialsRequest) ToDomain() *domain.UserCredentials {
 type UserRefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" validate:"required"`
 }
+
+// PasswordChangeRequest changes the password of the account, the current password is entered again.
+type PasswordChangeRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,strong_password"`
+}
+
+func (r *PasswordChangeRequest) ToDomain() *domain.PasswordChange {
+	return &domain.PasswordChange{
+		CurrentPassword: r.CurrentPassword,
+		NewPassword:     r.NewPassword,
+	}
+}
@@ -0,0 +1,29 @@
+package endpoint
+
+import (
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type VoiceRequest struct {
+	Intent string `json:"intent" validate:"required,oneof=add_item list_today complete_item"`
+	Item   string `json:"item" validate:"max=255"`
+}
+
+func (r *VoiceRequest) ToDomain() *domain.VoiceRequest {
+	return &domain.VoiceRequest{
+		Intent: domain.VoiceIntent(r.Intent),
+		Item:   r.Item,
+	}
+}
+
+type VoiceResponse struct {
+	Speech string  `json:"speech"`
+	Todos  []*Todo `json:"todos"`
+}
+
+func NewVoiceResponse(voiceResponse *domain.VoiceResponse) *VoiceResponse {
+	return &VoiceResponse{
+		Speech: voiceResponse.Speech,
+		Todos:  NewTodos(voiceResponse.Todos),
+	}
+}
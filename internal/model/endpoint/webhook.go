@@ -0,0 +1,97 @@
+package endpoint
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type Webhook struct {
+	UUID   string   `json:"uuid"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	// Secret is only returned when the webhook is created.
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewWebhook(webhook *domain.Webhook) *Webhook {
+	events := make([]string, 0, len(webhook.Events))
+	for _, event := range webhook.Events {
+		events = append(events, string(event))
+	}
+
+	return &Webhook{
+		UUID:      webhook.UUID,
+		URL:       webhook.URL,
+		Events:    events,
+		CreatedAt: webhook.CreatedAt,
+	}
+}
+
+func NewWebhooks(webhooks []*domain.Webhook) []*Webhook {
+	resp := make([]*Webhook, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		resp = append(resp, NewWebhook(webhook))
+	}
+
+	return resp
+}
+
+type WebhookRequest struct {
+	URL    string   `json:"url" validate:"required,url,max=2048"`
+	Events []string `json:"events" validate:"required,min=1,dive,oneof=todo.created todo.updated todo.completed todo.deleted todo.moved security.login security.login_failed security.token_revoked security.permission_changed security.login_alert"`
+}
+
+func (r *WebhookRequest) ToDomain() *domain.WebhookCreate {
+	webhookCreate := &domain.WebhookCreate{
+		URL: r.URL,
+	}
+	for _, event := range r.Events {
+		if eventType, err := domain.ParseEventType(event); err == nil {
+			webhookCreate.Events = append(webhookCreate.Events, eventType)
+		}
+	}
+
+	return webhookCreate
+}
+
+type WebhookDelivery struct {
+	UUID           string     `json:"uuid"`
+	Event          string     `json:"event"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at"`
+	LastStatusCode int        `json:"last_status_code,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func NewWebhookDelivery(delivery *domain.WebhookDelivery) *WebhookDelivery {
+	resp := &WebhookDelivery{
+		UUID:           delivery.UUID,
+		Event:          string(delivery.Event),
+		Status:         string(delivery.Status),
+		Attempts:       delivery.Attempts,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		DeliveredAt:    timeOrNil(delivery.DeliveredAt),
+		CreatedAt:      delivery.CreatedAt,
+	}
+	// only pending deliveries are attempted again
+	if delivery.Status == domain.WebhookDeliveryPending {
+		resp.NextAttemptAt = timeOrNil(delivery.NextAttemptAt)
+	}
+
+	return resp
+}
+
+func NewWebhookDeliveries(deliveries []*domain.WebhookDelivery) []*WebhookDelivery {
+	resp := make([]*WebhookDelivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		resp = append(resp, NewWebhookDelivery(delivery))
+	}
+
+	return resp
+}
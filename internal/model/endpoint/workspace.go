@@ -0,0 +1,158 @@
+package endpoint
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type ResourceUsage struct {
+	Resource domain.QuotaResource `json:"resource"`
+	Metered  bool                 `json:"metered"`
+	// Limit and Remaining are null when the resource is unlimited.
+	Limit     *int `json:"limit"`
+	Used      int  `json:"used"`
+	Remaining *int `json:"remaining"`
+	Warning   bool `json:"warning"`
+}
+
+type WorkspaceLimits struct {
+	Workspace   string           `json:"workspace"`
+	Plan        string           `json:"plan"`
+	PeriodStart time.Time        `json:"period_start"`
+	PeriodEnd   time.Time        `json:"period_end"`
+	Resources   []*ResourceUsage `json:"resources"`
+}
+
+func NewWorkspaceLimits(limits *domain.WorkspaceLimits) *WorkspaceLimits {
+	resources := make([]*ResourceUsage, 0, len(limits.Resources))
+	for _, usage := range limits.Resources {
+		resource := &ResourceUsage{
+			Resource: usage.Resource,
+			Metered:  usage.Resource.Metered(),
+			Used:     usage.Used,
+			Warning:  usage.Warning,
+		}
+		if usage.Limit > 0 {
+			limit, remaining := usage.Limit, max(usage.Limit-usage.Used, 0)
+			resource.Limit, resource.Remaining = &limit, &remaining
+		}
+		resources = append(resources, resource)
+	}
+
+	return &WorkspaceLimits{
+		Workspace:   limits.Slug,
+		Plan:        limits.Plan,
+		PeriodStart: limits.PeriodStart,
+		PeriodEnd:   limits.PeriodEnd,
+		Resources:   resources,
+	}
+}
+
+// WorkingHours are sent as "15:04" times of day and lowercase weekday names.
+type WorkingHours struct {
+	Start    string   `json:"start"`
+	End      string   `json:"end"`
+	Days     []string `json:"days"`
+	Timezone string   `json:"timezone"`
+}
+
+func NewWorkingHours(hours *domain.WorkingHours) *WorkingHours {
+	days := make([]string, 0, len(hours.Days))
+	for _, day := range hours.Days {
+		days = append(days, strings.ToLower(day.String()))
+	}
+
+	return &WorkingHours{
+		Start:    formatTimeOfDay(hours.Start),
+		End:      formatTimeOfDay(hours.End),
+		Days:     days,
+		Timezone: hours.Timezone,
+	}
+}
+
+type Holiday struct {
+	UUID      string `json:"uuid"`
+	Date      string `json:"date"`
+	Name      string `json:"name"`
+	Recurring bool   `json:"recurring"`
+}
+
+func NewHoliday(holiday *domain.Holiday) *Holiday {
+	return &Holiday{
+		UUID:      holiday.UUID,
+		Date:      holiday.Date.Format(domain.DateLayout),
+		Name:      holiday.Name,
+		Recurring: holiday.Recurring,
+	}
+}
+
+type WorkCalendar struct {
+	WorkingHours *WorkingHours `json:"working_hours"`
+	Holidays     []*Holiday    `json:"holidays"`
+}
+
+func NewWorkCalendar(calendar *domain.WorkCalendar) *WorkCalendar {
+	holidays := make([]*Holiday, 0, len(calendar.Holidays))
+	for _, holiday := range calendar.Holidays {
+		holidays = append(holidays, NewHoliday(holiday))
+	}
+
+	return &WorkCalendar{
+		WorkingHours: NewWorkingHours(calendar.Hours),
+		Holidays:     holidays,
+	}
+}
+
+type WorkingHoursRequest struct {
+	Start    string   `json:"start" validate:"required,datetime=15:04"`
+	End      string   `json:"end" validate:"required,datetime=15:04"`
+	Days     []string `json:"days" validate:"required,min=1,max=7,dive,oneof=sunday monday tuesday wednesday thursday friday saturday"`
+	Timezone string   `json:"timezone" validate:"required,max=64"`
+}
+
+func (r *WorkingHoursRequest) ToDomain() *domain.WorkingHours {
+	hours := &domain.WorkingHours{
+		Start:    parseTimeOfDay(r.Start),
+		End:      parseTimeOfDay(r.End),
+		Timezone: r.Timezone,
+	}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if slices.Contains(r.Days, strings.ToLower(day.String())) {
+			hours.Days = append(hours.Days, day)
+		}
+	}
+
+	return hours
+}
+
+// HolidayRequest creates a holiday, Date is a YYYY-MM-DD date.
+type HolidayRequest struct {
+	Date      string `json:"date" validate:"required"`
+	Name      string `json:"name" validate:"required,max=255"`
+	Recurring bool   `json:"recurring"`
+}
+
+// HolidayUpdateRequest only changes the provided fields.
+type HolidayUpdateRequest struct {
+	Date      *string `json:"date"`
+	Name      *string `json:"name" validate:"omitempty,min=1,max=255"`
+	Recurring *bool   `json:"recurring"`
+}
+
+func formatTimeOfDay(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d/time.Hour), int(d%time.Hour/time.Minute))
+}
+
+// parseTimeOfDay parses a validated "15:04" time of day.
+func parseTimeOfDay(value string) time.Duration {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0
+	}
+
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+}
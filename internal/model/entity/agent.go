@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type AgentToken struct {
+	ID         uint         `db:"id"`
+	UUID       string       `db:"uuid"`
+	UserID     uint         `db:"user_id"`
+	Name       string       `db:"name"`
+	Scopes     string       `db:"scopes"`
+	TokenHash  string       `db:"token_hash"`
+	ExpiresAt  sql.NullTime `db:"expires_at"`
+	LastUsedAt sql.NullTime `db:"last_used_at"`
+	CreatedAt  time.Time    `db:"created_at"`
+	UpdatedAt  time.Time    `db:"updated_at"`
+	DeletedAt  sql.NullTime `db:"deleted_at"`
+}
+
+func (a *AgentToken) ToDomain() *domain.AgentToken {
+	token := new(domain.AgentToken)
+	token.ID = a.ID
+	token.UUID = a.UUID
+	token.UserID = a.UserID
+	token.Name = a.Name
+	for _, scope := range strings.Split(a.Scopes, ",") {
+		token.Scopes = append(token.Scopes, domain.AgentScope(scope))
+	}
+	if a.ExpiresAt.Valid {
+		token.ExpiresAt = a.ExpiresAt.Time
+	}
+	if a.LastUsedAt.Valid {
+		token.LastUsedAt = a.LastUsedAt.Time
+	}
+	token.CreatedAt = a.CreatedAt
+
+	return token
+}
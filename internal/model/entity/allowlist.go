@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type IPRange struct {
+	ID          uint         `db:"id"`
+	UUID        string       `db:"uuid"`
+	WorkspaceID uint         `db:"workspace_id"`
+	CIDR        string       `db:"cidr"`
+	Label       string       `db:"label"`
+	CreatedAt   time.Time    `db:"created_at"`
+	DeletedAt   sql.NullTime `db:"deleted_at"`
+}
+
+func (r *IPRange) ToDomain() *domain.IPRange {
+	return &domain.IPRange{
+		ID:          r.ID,
+		UUID:        r.UUID,
+		WorkspaceID: r.WorkspaceID,
+		CIDR:        r.CIDR,
+		Label:       r.Label,
+		CreatedAt:   r.CreatedAt,
+	}
+}
@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type Attachment struct {
+	ID          uint         `db:"id"`
+	UUID        string       `db:"uuid"`
+	TodoID      uint         `db:"todo_id"`
+	UserID      uint         `db:"user_id"`
+	Filename    string       `db:"filename"`
+	ContentType string       `db:"content_type"`
+	Size        int64        `db:"size"`
+	StorageKey  string       `db:"storage_key"`
+	CreatedAt   time.Time    `db:"created_at"`
+	UpdatedAt   time.Time    `db:"updated_at"`
+	DeletedAt   sql.NullTime `db:"deleted_at"`
+
+	ExtractionStatus    string       `db:"extraction_status"`
+	ExtractionAttempts  int          `db:"extraction_attempts"`
+	ExtractionStartedAt sql.NullTime `db:"extraction_started_at"`
+}
+
+func (a *Attachment) ToDomain() *domain.Attachment {
+	attachment := new(domain.Attachment)
+	attachment.ID = a.ID
+	attachment.UUID = a.UUID
+	attachment.TodoID = a.TodoID
+	attachment.UserID = a.UserID
+	attachment.Filename = a.Filename
+	attachment.ContentType = a.ContentType
+	attachment.Size = a.Size
+	attachment.StorageKey = a.StorageKey
+	attachment.CreatedAt = a.CreatedAt
+	attachment.ExtractionStatus = domain.ExtractionStatus(a.ExtractionStatus)
+	attachment.ExtractionAttempts = a.ExtractionAttempts
+	attachment.ExtractionStartedAt = a.ExtractionStartedAt.Time
+
+	return attachment
+}
@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type AuditEntry struct {
+	ID           uint          `db:"id"`
+	UserID       sql.NullInt64 `db:"user_id"`
+	Action       string        `db:"action"`
+	Path         string        `db:"path"`
+	Status       int           `db:"status"`
+	IP           string        `db:"ip"`
+	UserAgent    string        `db:"user_agent"`
+	RequestID    string        `db:"request_id"`
+	CreatedAt    time.Time     `db:"created_at"`
+	PrevHash     string        `db:"prev_hash"`
+	Hash         string        `db:"hash"`
+	AnonymizedAt sql.NullTime  `db:"anonymized_at"`
+}
+
+func (a *AuditEntry) ToDomain() *domain.AuditEntry {
+	entry := new(domain.AuditEntry)
+	entry.ID = a.ID
+	if a.UserID.Valid {
+		entry.UserID = uint(a.UserID.Int64)
+	}
+	entry.Action = a.Action
+	entry.Path = a.Path
+	entry.Status = a.Status
+	entry.IP = a.IP
+	entry.UserAgent = a.UserAgent
+	entry.RequestID = a.RequestID
+	entry.CreatedAt = a.CreatedAt
+	entry.PrevHash = a.PrevHash
+	entry.Hash = a.Hash
+	if a.AnonymizedAt.Valid {
+		entry.AnonymizedAt = a.AnonymizedAt.Time
+	}
+
+	return entry
+}
@@ -0,0 +1,85 @@
+package entity
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type Automation struct {
+	ID        uint         `db:"id"`
+	UUID      string       `db:"uuid"`
+	UserID    uint         `db:"user_id"`
+	Name      string       `db:"name"`
+	Events    string       `db:"events"`
+	Module    []byte       `db:"module"`
+	CreatedAt time.Time    `db:"created_at"`
+	UpdatedAt time.Time    `db:"updated_at"`
+	DeletedAt sql.NullTime `db:"deleted_at"`
+}
+
+func (a *Automation) ToDomain() *domain.Automation {
+	automation := new(domain.Automation)
+	automation.ID = a.ID
+	automation.UUID = a.UUID
+	automation.UserID = a.UserID
+	automation.Name = a.Name
+	for _, event := range strings.Split(a.Events, ",") {
+		automation.Events = append(automation.Events, domain.EventType(event))
+	}
+	automation.Module = a.Module
+	automation.CreatedAt = a.CreatedAt
+
+	return automation
+}
+
+// AutomationRun is a run joined with the automation it runs.
+type AutomationRun struct {
+	ID               uint           `db:"id"`
+	UUID             string         `db:"uuid"`
+	AutomationID     uint           `db:"automation_id"`
+	AutomationUUID   string         `db:"automation_uuid"`
+	AutomationUserID uint           `db:"automation_user_id"`
+	AutomationName   string         `db:"automation_name"`
+	AutomationModule []byte         `db:"automation_module"`
+	Event            string         `db:"event"`
+	Payload          string         `db:"payload"`
+	Status           string         `db:"status"`
+	FuelUsed         int64          `db:"fuel_used"`
+	Log              sql.NullString `db:"log"`
+	Error            sql.NullString `db:"error"`
+	FinishedAt       sql.NullTime   `db:"finished_at"`
+	CreatedAt        time.Time      `db:"created_at"`
+	UpdatedAt        time.Time      `db:"updated_at"`
+}
+
+func (a *AutomationRun) ToDomain() *domain.AutomationRun {
+	run := new(domain.AutomationRun)
+	run.ID = a.ID
+	run.UUID = a.UUID
+	run.Automation = &domain.Automation{
+		ID:     a.AutomationID,
+		UUID:   a.AutomationUUID,
+		UserID: a.AutomationUserID,
+		Name:   a.AutomationName,
+		Module: a.AutomationModule,
+	}
+	run.Event = domain.EventType(a.Event)
+	run.Payload = []byte(a.Payload)
+	run.Status = domain.AutomationRunStatus(a.Status)
+	run.FuelUsed = uint64(a.FuelUsed)
+	if a.Log.Valid {
+		run.Log = a.Log.String
+	}
+	if a.Error.Valid {
+		run.Error = a.Error.String
+	}
+	if a.FinishedAt.Valid {
+		run.FinishedAt = a.FinishedAt.Time
+	}
+	run.CreatedAt = a.CreatedAt
+
+	return run
+}
@@ -0,0 +1,34 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type CalendarToken struct {
+	ID         uint         `db:"id"`
+	UUID       string       `db:"uuid"`
+	UserID     uint         `db:"user_id"`
+	Name       string       `db:"name"`
+	TokenHash  string       `db:"token_hash"`
+	LastUsedAt sql.NullTime `db:"last_used_at"`
+	CreatedAt  time.Time    `db:"created_at"`
+	UpdatedAt  time.Time    `db:"updated_at"`
+	DeletedAt  sql.NullTime `db:"deleted_at"`
+}
+
+func (t *CalendarToken) ToDomain() *domain.CalendarToken {
+	token := new(domain.CalendarToken)
+	token.ID = t.ID
+	token.UUID = t.UUID
+	token.UserID = t.UserID
+	token.Name = t.Name
+	if t.LastUsedAt.Valid {
+		token.LastUsedAt = t.LastUsedAt.Time
+	}
+	token.CreatedAt = t.CreatedAt
+
+	return token
+}
@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type Comment struct {
+	ID         uint         `db:"id"`
+	UUID       string       `db:"uuid"`
+	TodoID     uint         `db:"todo_id"`
+	TodoUUID   string       `db:"todo_uuid"`
+	UserID     uint         `db:"user_id"`
+	AuthorUUID string       `db:"author_uuid"`
+	AuthorName string       `db:"author_name"`
+	Body       string       `db:"body"`
+	CreatedAt  time.Time    `db:"created_at"`
+	UpdatedAt  time.Time    `db:"updated_at"`
+	DeletedAt  sql.NullTime `db:"deleted_at"`
+}
+
+func (c *Comment) ToDomain() *domain.Comment {
+	comment := new(domain.Comment)
+	comment.ID = c.ID
+	comment.UUID = c.UUID
+	comment.TodoID = c.TodoID
+	comment.TodoUUID = c.TodoUUID
+	comment.AuthorID = c.UserID
+	comment.AuthorUUID = c.AuthorUUID
+	comment.AuthorName = c.AuthorName
+	comment.Body = c.Body
+	comment.CreatedAt = c.CreatedAt
+	comment.UpdatedAt = c.UpdatedAt
+	if c.DeletedAt.Valid {
+		comment.DeletedAt = c.DeletedAt.Time
+	}
+
+	return comment
+}
@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type DigestSettings struct {
+	UserID     uint         `db:"user_id"`
+	Enabled    bool         `db:"enabled"`
+	Weekday    int          `db:"weekday"`
+	Hour       int          `db:"hour"`
+	NextSendAt time.Time    `db:"next_send_at"`
+	LastSentAt sql.NullTime `db:"last_sent_at"`
+	CreatedAt  time.Time    `db:"created_at"`
+	UpdatedAt  time.Time    `db:"updated_at"`
+}
+
+func (s *DigestSettings) ToDomain() *domain.DigestSettings {
+	settings := new(domain.DigestSettings)
+	settings.UserID = s.UserID
+	settings.Enabled = s.Enabled
+	settings.Weekday = time.Weekday(s.Weekday)
+	settings.Hour = s.Hour
+	settings.NextSendAt = s.NextSendAt
+	if s.LastSentAt.Valid {
+		settings.LastSentAt = s.LastSentAt.Time
+	}
+
+	return settings
+}
@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type DisplayToken struct {
+	ID         uint         `db:"id"`
+	UUID       string       `db:"uuid"`
+	UserID     uint         `db:"user_id"`
+	Name       string       `db:"name"`
+	TokenHash  string       `db:"token_hash"`
+	ExpiresAt  sql.NullTime `db:"expires_at"`
+	LastUsedAt sql.NullTime `db:"last_used_at"`
+	CreatedAt  time.Time    `db:"created_at"`
+	UpdatedAt  time.Time    `db:"updated_at"`
+	DeletedAt  sql.NullTime `db:"deleted_at"`
+}
+
+func (d *DisplayToken) ToDomain() *domain.DisplayToken {
+	token := new(domain.DisplayToken)
+	token.ID = d.ID
+	token.UUID = d.UUID
+	token.UserID = d.UserID
+	token.Name = d.Name
+	if d.ExpiresAt.Valid {
+		token.ExpiresAt = d.ExpiresAt.Time
+	}
+	if d.LastUsedAt.Valid {
+		token.LastUsedAt = d.LastUsedAt.Time
+	}
+	token.CreatedAt = d.CreatedAt
+
+	return token
+}
+
+type DisplayTokenList struct {
+	DisplayTokenID uint   `db:"display_token_id"`
+	ListID         uint   `db:"list_id"`
+	ListUUID       string `db:"list_uuid"`
+}
@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type Email struct {
+	ID            uint           `db:"id"`
+	UUID          string         `db:"uuid"`
+	UserID        sql.NullInt64  `db:"user_id"`
+	Kind          string         `db:"kind"`
+	Recipients    string         `db:"recipients"`
+	Subject       string         `db:"subject"`
+	TextBody      string         `db:"text_body"`
+	HTMLBody      string         `db:"html_body"`
+	Status        string         `db:"status"`
+	Attempts      int            `db:"attempts"`
+	NextAttemptAt time.Time      `db:"next_attempt_at"`
+	LastError     sql.NullString `db:"last_error"`
+	SentAt        sql.NullTime   `db:"sent_at"`
+	CreatedAt     time.Time      `db:"created_at"`
+	UpdatedAt     time.Time      `db:"updated_at"`
+}
+
+func (e *Email) ToDomain() *domain.Email {
+	email := new(domain.Email)
+	email.ID = e.ID
+	email.UUID = e.UUID
+	if e.UserID.Valid {
+		email.UserID = uint(e.UserID.Int64)
+	}
+	email.Kind = domain.NotificationKind(e.Kind)
+	email.To = strings.Split(e.Recipients, ",")
+	email.Subject = e.Subject
+	email.Text = e.TextBody
+	email.HTML = e.HTMLBody
+	email.Status = domain.EmailStatus(e.Status)
+	email.Attempts = e.Attempts
+	email.NextAttemptAt = e.NextAttemptAt
+	if e.LastError.Valid {
+		email.LastError = e.LastError.String
+	}
+	email.SentAt = e.SentAt.Time
+	email.CreatedAt = e.CreatedAt
+
+	return email
+}
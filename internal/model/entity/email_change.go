@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type EmailChange struct {
+	ID             uint         `db:"id"`
+	UUID           string       `db:"uuid"`
+	UserID         uint         `db:"user_id"`
+	NewEmail       string       `db:"new_email"`
+	OldConfirmedAt sql.NullTime `db:"old_confirmed_at"`
+	NewConfirmedAt sql.NullTime `db:"new_confirmed_at"`
+	ExpiresAt      time.Time    `db:"expires_at"`
+	CompletedAt    sql.NullTime `db:"completed_at"`
+	CreatedAt      time.Time    `db:"created_at"`
+}
+
+func (e *EmailChange) ToDomain() *domain.EmailChange {
+	change := new(domain.EmailChange)
+	change.ID = e.ID
+	change.UUID = e.UUID
+	change.UserID = e.UserID
+	change.NewEmail = e.NewEmail
+	change.OldConfirmedAt = e.OldConfirmedAt.Time
+	change.NewConfirmedAt = e.NewConfirmedAt.Time
+	change.ExpiresAt = e.ExpiresAt
+	change.CompletedAt = e.CompletedAt.Time
+	change.CreatedAt = e.CreatedAt
+
+	return change
+}
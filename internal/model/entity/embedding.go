@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type TodoEmbedding struct {
+	TodoID      uint      `db:"todo_id"`
+	UserID      uint      `db:"user_id"`
+	Model       string    `db:"model"`
+	ContentHash string    `db:"content_hash"`
+	Attempts    int       `db:"attempts"`
+	DueAt       time.Time `db:"due_at"`
+
+	Title       string `db:"title"`
+	Description string `db:"description"`
+}
+
+func (e *TodoEmbedding) ToDomain() *domain.TodoEmbedding {
+	return &domain.TodoEmbedding{
+		TodoID:      e.TodoID,
+		UserID:      e.UserID,
+		Model:       e.Model,
+		ContentHash: e.ContentHash,
+		Text:        domain.TodoEmbeddingText(&domain.Todo{Title: e.Title, Description: e.Description}),
+		Attempts:    e.Attempts,
+		DueAt:       e.DueAt,
+	}
+}
@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type PublicKey struct {
+	UserID    uint           `db:"user_id"`
+	UserUUID  string         `db:"user_uuid"`
+	PublicKey sql.NullString `db:"public_key"`
+	CreatedAt sql.NullTime   `db:"created_at"`
+	UpdatedAt sql.NullTime   `db:"updated_at"`
+}
+
+func (k *PublicKey) ToDomain() *domain.PublicKey {
+	key := new(domain.PublicKey)
+	key.UserID = k.UserID
+	key.UserUUID = k.UserUUID
+	key.Key = k.PublicKey.String
+	key.CreatedAt = k.CreatedAt.Time
+	key.UpdatedAt = k.UpdatedAt.Time
+
+	return key
+}
+
+type ListKey struct {
+	ListID     uint      `db:"list_id"`
+	UserID     uint      `db:"user_id"`
+	UserUUID   string    `db:"user_uuid"`
+	KeyVersion int       `db:"key_version"`
+	WrappedKey string    `db:"wrapped_key"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+func (k *ListKey) ToDomain() *domain.ListKey {
+	key := new(domain.ListKey)
+	key.ListID = k.ListID
+	key.UserID = k.UserID
+	key.UserUUID = k.UserUUID
+	key.KeyVersion = k.KeyVersion
+	key.WrappedKey = k.WrappedKey
+	key.CreatedAt = k.CreatedAt
+
+	return key
+}
@@ -0,0 +1,77 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type EscalationRule struct {
+	ID          uint          `db:"id"`
+	UUID        string        `db:"uuid"`
+	ListID      uint          `db:"list_id"`
+	UserID      uint          `db:"user_id"`
+	OverdueDays int           `db:"overdue_days"`
+	Priority    sql.NullInt64 `db:"priority"`
+	Notify      bool          `db:"notify"`
+	CreatedAt   time.Time     `db:"created_at"`
+	UpdatedAt   time.Time     `db:"updated_at"`
+	DeletedAt   sql.NullTime  `db:"deleted_at"`
+}
+
+func (r *EscalationRule) ToDomain() *domain.EscalationRule {
+	rule := new(domain.EscalationRule)
+	rule.ID = r.ID
+	rule.UUID = r.UUID
+	rule.ListID = r.ListID
+	rule.UserID = r.UserID
+	rule.OverdueBy = time.Duration(r.OverdueDays) * 24 * time.Hour
+	if r.Priority.Valid {
+		priority := domain.Priority(r.Priority.Int64)
+		rule.Priority = &priority
+	}
+	rule.Notify = r.Notify
+	rule.CreatedAt = r.CreatedAt
+	rule.UpdatedAt = r.UpdatedAt
+
+	return rule
+}
+
+type Escalation struct {
+	ID           uint          `db:"id"`
+	RuleID       uint          `db:"rule_id"`
+	RuleUUID     string        `db:"rule_uuid"`
+	ListID       uint          `db:"list_id"`
+	TodoID       uint          `db:"todo_id"`
+	TodoUUID     string        `db:"todo_uuid"`
+	UserID       uint          `db:"user_id"`
+	Title        string        `db:"title"`
+	DueDate      time.Time     `db:"due_date"`
+	PriorityFrom int           `db:"priority_from"`
+	PriorityTo   sql.NullInt64 `db:"priority_to"`
+	Notified     bool          `db:"notified"`
+	CreatedAt    time.Time     `db:"created_at"`
+}
+
+func (e *Escalation) ToDomain() *domain.Escalation {
+	escalation := new(domain.Escalation)
+	escalation.ID = e.ID
+	escalation.RuleID = e.RuleID
+	escalation.RuleUUID = e.RuleUUID
+	escalation.ListID = e.ListID
+	escalation.TodoID = e.TodoID
+	escalation.TodoUUID = e.TodoUUID
+	escalation.UserID = e.UserID
+	escalation.Title = e.Title
+	escalation.DueDate = e.DueDate
+	escalation.PriorityFrom = domain.Priority(e.PriorityFrom)
+	if e.PriorityTo.Valid {
+		priority := domain.Priority(e.PriorityTo.Int64)
+		escalation.PriorityTo = &priority
+	}
+	escalation.Notified = e.Notified
+	escalation.CreatedAt = e.CreatedAt
+
+	return escalation
+}
@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type FeedToken struct {
+	ID         uint         `db:"id"`
+	UUID       string       `db:"uuid"`
+	UserID     uint         `db:"user_id"`
+	ListID     uint         `db:"list_id"`
+	ListUUID   string       `db:"list_uuid"`
+	Name       string       `db:"name"`
+	TokenHash  string       `db:"token_hash"`
+	LastUsedAt sql.NullTime `db:"last_used_at"`
+	CreatedAt  time.Time    `db:"created_at"`
+	UpdatedAt  time.Time    `db:"updated_at"`
+	DeletedAt  sql.NullTime `db:"deleted_at"`
+}
+
+func (t *FeedToken) ToDomain() *domain.FeedToken {
+	token := new(domain.FeedToken)
+	token.ID = t.ID
+	token.UUID = t.UUID
+	token.UserID = t.UserID
+	token.ListID = t.ListID
+	token.ListUUID = t.ListUUID
+	token.Name = t.Name
+	if t.LastUsedAt.Valid {
+		token.LastUsedAt = t.LastUsedAt.Time
+	}
+	token.CreatedAt = t.CreatedAt
+
+	return token
+}
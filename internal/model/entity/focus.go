@@ -0,0 +1,68 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type FocusSession struct {
+	ID             uint         `db:"id"`
+	UUID           string       `db:"uuid"`
+	UserID         uint         `db:"user_id"`
+	TodoID         uint         `db:"todo_id"`
+	TodoUUID       string       `db:"todo_uuid"`
+	TodoTitle      string       `db:"todo_title"`
+	Status         string       `db:"status"`
+	PlannedSeconds int          `db:"planned_seconds"`
+	FocusedSeconds int          `db:"focused_seconds"`
+	Interruptions  int          `db:"interruptions"`
+	StartedAt      time.Time    `db:"started_at"`
+	EndedAt        sql.NullTime `db:"ended_at"`
+	CreatedAt      time.Time    `db:"created_at"`
+	UpdatedAt      time.Time    `db:"updated_at"`
+}
+
+func (f *FocusSession) ToDomain() *domain.FocusSession {
+	session := new(domain.FocusSession)
+	session.ID = f.ID
+	session.UUID = f.UUID
+	session.UserID = f.UserID
+	session.TodoID = f.TodoID
+	session.TodoUUID = f.TodoUUID
+	session.TodoTitle = f.TodoTitle
+	session.Status = domain.FocusStatus(f.Status)
+	session.Planned = time.Duration(f.PlannedSeconds) * time.Second
+	session.Focused = time.Duration(f.FocusedSeconds) * time.Second
+	session.Interruptions = f.Interruptions
+	session.StartedAt = f.StartedAt
+	if f.EndedAt.Valid {
+		session.EndedAt = f.EndedAt.Time
+	}
+
+	return session
+}
+
+type FocusStats struct {
+	Sessions       int `db:"sessions"`
+	Completed      int `db:"completed"`
+	Interruptions  int `db:"interruptions"`
+	FocusedSeconds int `db:"focused_seconds"`
+}
+
+type FocusTodoStats struct {
+	TodoUUID       string `db:"todo_uuid"`
+	TodoTitle      string `db:"todo_title"`
+	Sessions       int    `db:"sessions"`
+	FocusedSeconds int    `db:"focused_seconds"`
+}
+
+func (f *FocusTodoStats) ToDomain() *domain.FocusTodoStats {
+	return &domain.FocusTodoStats{
+		TodoUUID:  f.TodoUUID,
+		TodoTitle: f.TodoTitle,
+		Sessions:  f.Sessions,
+		Focused:   time.Duration(f.FocusedSeconds) * time.Second,
+	}
+}
@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type Habit struct {
+	ID            uint         `db:"id"`
+	UUID          string       `db:"uuid"`
+	UserID        uint         `db:"user_id"`
+	Title         string       `db:"title"`
+	TargetPerWeek int          `db:"target_per_week"`
+	CreatedAt     time.Time    `db:"created_at"`
+	UpdatedAt     time.Time    `db:"updated_at"`
+	DeletedAt     sql.NullTime `db:"deleted_at"`
+}
+
+func (h *Habit) ToDomain() *domain.Habit {
+	return &domain.Habit{
+		ID:            h.ID,
+		UUID:          h.UUID,
+		UserID:        h.UserID,
+		Title:         h.Title,
+		TargetPerWeek: h.TargetPerWeek,
+		CreatedAt:     h.CreatedAt,
+		UpdatedAt:     h.UpdatedAt,
+	}
+}
+
+type HabitEntry struct {
+	HabitID   uint      `db:"habit_id"`
+	EntryDate time.Time `db:"entry_date"`
+	Status    string    `db:"status"`
+}
+
+func (h *HabitEntry) ToDomain() *domain.HabitEntry {
+	return &domain.HabitEntry{
+		HabitID: h.HabitID,
+		Date:    domain.Day(h.EntryDate),
+		Status:  domain.HabitEntryStatus(h.Status),
+	}
+}
@@ -0,0 +1,47 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type ImportJob struct {
+	ID            uint         `db:"id"`
+	UUID          string       `db:"uuid"`
+	UserID        uint         `db:"user_id"`
+	Provider      string       `db:"provider"`
+	Status        string       `db:"status"`
+	ListsCreated  int          `db:"lists_created"`
+	TodosCreated  int          `db:"todos_created"`
+	Error         string       `db:"error"`
+	SourceURL     string       `db:"source_url"`
+	BytesReceived int64        `db:"bytes_received"`
+	ArchiveSHA256 string       `db:"archive_sha256"`
+	StartedAt     sql.NullTime `db:"started_at"`
+	FinishedAt    sql.NullTime `db:"finished_at"`
+	CreatedAt     time.Time    `db:"created_at"`
+	UpdatedAt     time.Time    `db:"updated_at"`
+}
+
+func (j *ImportJob) ToDomain() *domain.ImportJob {
+	job := new(domain.ImportJob)
+	job.ID = j.ID
+	job.UUID = j.UUID
+	job.UserID = j.UserID
+	job.Provider = domain.ImportProvider(j.Provider)
+	job.Status = domain.ImportStatus(j.Status)
+	job.ListsCreated = j.ListsCreated
+	job.TodosCreated = j.TodosCreated
+	job.Error = j.Error
+	job.SourceURL = j.SourceURL
+	job.BytesReceived = j.BytesReceived
+	job.ArchiveSHA256 = j.ArchiveSHA256
+	job.StartedAt = j.StartedAt.Time
+	job.FinishedAt = j.FinishedAt.Time
+	job.CreatedAt = j.CreatedAt
+	job.UpdatedAt = j.UpdatedAt
+
+	return job
+}
@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type InsightSettings struct {
+	UserID          uint         `db:"user_id"`
+	Enabled         bool         `db:"enabled"`
+	OverdueSpike    int          `db:"overdue_spike"`
+	IdleDays        int          `db:"idle_days"`
+	NextCheckAt     time.Time    `db:"next_check_at"`
+	OverdueNudgedAt sql.NullTime `db:"overdue_nudged_at"`
+	IdleNudgedAt    sql.NullTime `db:"idle_nudged_at"`
+	CreatedAt       time.Time    `db:"created_at"`
+	UpdatedAt       time.Time    `db:"updated_at"`
+}
+
+func (s *InsightSettings) ToDomain() *domain.InsightSettings {
+	settings := new(domain.InsightSettings)
+	settings.UserID = s.UserID
+	settings.Enabled = s.Enabled
+	settings.OverdueSpike = s.OverdueSpike
+	settings.IdleDays = s.IdleDays
+	settings.NextCheckAt = s.NextCheckAt
+	if s.OverdueNudgedAt.Valid {
+		settings.OverdueNudgedAt = s.OverdueNudgedAt.Time
+	}
+	if s.IdleNudgedAt.Valid {
+		settings.IdleNudgedAt = s.IdleNudgedAt.Time
+	}
+
+	return settings
+}
+
+type ProductivityStats struct {
+	OverdueToday    int `db:"overdue_today"`
+	OverdueBaseline int `db:"overdue_baseline"`
+	Completed       int `db:"completed"`
+	Open            int `db:"open"`
+}
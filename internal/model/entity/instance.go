@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type Instance struct {
+	ID          string    `db:"id"`
+	Hostname    string    `db:"hostname"`
+	Version     string    `db:"version"`
+	Regions     string    `db:"regions"`
+	Shard       int       `db:"shard"`
+	Shards      int       `db:"shards"`
+	StartedAt   time.Time `db:"started_at"`
+	HeartbeatAt time.Time `db:"heartbeat_at"`
+}
+
+func (i *Instance) ToDomain() *domain.Instance {
+	instance := &domain.Instance{
+		ID:          i.ID,
+		Hostname:    i.Hostname,
+		Version:     i.Version,
+		Shard:       i.Shard,
+		Shards:      i.Shards,
+		StartedAt:   i.StartedAt,
+		HeartbeatAt: i.HeartbeatAt,
+	}
+	if i.Regions != "" {
+		instance.Regions = strings.Split(i.Regions, ",")
+	}
+
+	return instance
+}
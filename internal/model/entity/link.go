@@ -0,0 +1,60 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type TodoLink struct {
+	TodoID      uint          `db:"todo_id"`
+	UserID      uint          `db:"user_id"`
+	URL         string        `db:"url"`
+	StatusCode  sql.NullInt64 `db:"status_code"`
+	Failures    int           `db:"failures"`
+	CheckedAt   sql.NullTime  `db:"checked_at"`
+	NextCheckAt time.Time     `db:"next_check_at"`
+	DeadSince   sql.NullTime  `db:"dead_since"`
+	NotifiedAt  sql.NullTime  `db:"notified_at"`
+
+	TodoUUID  sql.NullString `db:"todo_uuid"`
+	TodoTitle sql.NullString `db:"todo_title"`
+}
+
+func (l *TodoLink) ToDomain() *domain.TodoLink {
+	link := new(domain.TodoLink)
+	link.TodoID = l.TodoID
+	link.UserID = l.UserID
+	link.URL = l.URL
+	if l.StatusCode.Valid {
+		link.StatusCode = int(l.StatusCode.Int64)
+	}
+	link.Failures = l.Failures
+	if l.CheckedAt.Valid {
+		link.CheckedAt = l.CheckedAt.Time
+	}
+	link.NextCheckAt = l.NextCheckAt
+	if l.DeadSince.Valid {
+		link.DeadSince = l.DeadSince.Time
+	}
+	if l.NotifiedAt.Valid {
+		link.NotifiedAt = l.NotifiedAt.Time
+	}
+	link.TodoUUID = l.TodoUUID.String
+	link.TodoTitle = l.TodoTitle.String
+
+	return link
+}
+
+type LinkSettings struct {
+	CheckLinks bool `db:"check_links"`
+	Notify     bool `db:"notify"`
+}
+
+func (s *LinkSettings) ToDomain() *domain.LinkSettings {
+	return &domain.LinkSettings{
+		Check:  s.CheckLinks,
+		Notify: s.Notify,
+	}
+}
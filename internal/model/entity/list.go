@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type List struct {
+	ID     uint   `db:"id"`
+	UUID   string `db:"uuid"`
+	UserID uint   `db:"user_id"`
+	Name   string `db:"name"`
+	// CompletedRetentionDays is NULL for lists that keep their completed todos visible.
+	CompletedRetentionDays sql.NullInt64 `db:"completed_retention_days"`
+	KeyVersion             int           `db:"key_version"`
+	CreatedAt              time.Time     `db:"created_at"`
+	UpdatedAt              time.Time     `db:"updated_at"`
+	DeletedAt              sql.NullTime  `db:"deleted_at"`
+}
+
+func (l *List) ToDomain() *domain.List {
+	list := new(domain.List)
+	list.ID = l.ID
+	list.UUID = l.UUID
+	list.UserID = l.UserID
+	list.Name = l.Name
+	if l.CompletedRetentionDays.Valid {
+		list.CompletedRetention = time.Duration(l.CompletedRetentionDays.Int64) * 24 * time.Hour
+	}
+	list.KeyVersion = l.KeyVersion
+	list.CreatedAt = l.CreatedAt
+	list.UpdatedAt = l.UpdatedAt
+	if l.DeletedAt.Valid {
+		list.DeletedAt = l.DeletedAt.Time
+	}
+
+	return list
+}
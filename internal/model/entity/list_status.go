@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type ListStatus struct {
+	ID        uint      `db:"id"`
+	ListID    uint      `db:"list_id"`
+	Key       string    `db:"key"`
+	Name      string    `db:"name"`
+	Position  int       `db:"position"`
+	Done      bool      `db:"done"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (s *ListStatus) ToDomain() *domain.ListStatus {
+	status := new(domain.ListStatus)
+	status.ID = s.ID
+	status.ListID = s.ListID
+	status.Key = s.Key
+	status.Name = s.Name
+	status.Position = s.Position
+	status.Done = s.Done
+	status.CreatedAt = s.CreatedAt
+	status.UpdatedAt = s.UpdatedAt
+
+	return status
+}
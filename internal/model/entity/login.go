@@ -0,0 +1,61 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type Login struct {
+	ID          uint      `db:"id"`
+	UUID        string    `db:"uuid"`
+	UserID      uint      `db:"user_id"`
+	IP          string    `db:"ip"`
+	UserAgent   string    `db:"user_agent"`
+	Country     string    `db:"country"`
+	Fingerprint string    `db:"fingerprint"`
+	NewDevice   bool      `db:"new_device"`
+	NewCountry  bool      `db:"new_country"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+func (l *Login) ToDomain() *domain.Login {
+	return &domain.Login{
+		ID:          l.ID,
+		UUID:        l.UUID,
+		UserID:      l.UserID,
+		IP:          l.IP,
+		UserAgent:   l.UserAgent,
+		Country:     l.Country,
+		Fingerprint: l.Fingerprint,
+		NewDevice:   l.NewDevice,
+		NewCountry:  l.NewCountry,
+		CreatedAt:   l.CreatedAt,
+	}
+}
+
+type KnownDevice struct {
+	ID          uint      `db:"id"`
+	UUID        string    `db:"uuid"`
+	UserID      uint      `db:"user_id"`
+	Fingerprint string    `db:"fingerprint"`
+	UserAgent   string    `db:"user_agent"`
+	LastIP      string    `db:"last_ip"`
+	LastCountry string    `db:"last_country"`
+	FirstSeenAt time.Time `db:"first_seen_at"`
+	LastSeenAt  time.Time `db:"last_seen_at"`
+}
+
+func (d *KnownDevice) ToDomain() *domain.KnownDevice {
+	return &domain.KnownDevice{
+		ID:          d.ID,
+		UUID:        d.UUID,
+		UserID:      d.UserID,
+		Fingerprint: d.Fingerprint,
+		UserAgent:   d.UserAgent,
+		LastIP:      d.LastIP,
+		LastCountry: d.LastCountry,
+		FirstSeenAt: d.FirstSeenAt,
+		LastSeenAt:  d.LastSeenAt,
+	}
+}
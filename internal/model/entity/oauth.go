@@ -0,0 +1,62 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type OAuthAuthorizationCode struct {
+	ID          uint         `db:"id"`
+	CodeHash    string       `db:"code_hash"`
+	UserID      uint         `db:"user_id"`
+	ClientID    string       `db:"client_id"`
+	RedirectURI string       `db:"redirect_uri"`
+	ExpiresAt   time.Time    `db:"expires_at"`
+	UsedAt      sql.NullTime `db:"used_at"`
+	CreatedAt   time.Time    `db:"created_at"`
+}
+
+func (o *OAuthAuthorizationCode) ToDomain() *domain.OAuthAuthorizationCode {
+	code := new(domain.OAuthAuthorizationCode)
+	code.ID = o.ID
+	code.UserID = o.UserID
+	code.ClientID = o.ClientID
+	code.RedirectURI = o.RedirectURI
+	code.ExpiresAt = o.ExpiresAt
+	if o.UsedAt.Valid {
+		code.UsedAt = o.UsedAt.Time
+	}
+
+	return code
+}
+
+type OAuthGrant struct {
+	ID               uint         `db:"id"`
+	UUID             string       `db:"uuid"`
+	UserID           uint         `db:"user_id"`
+	ClientID         string       `db:"client_id"`
+	AccessTokenHash  string       `db:"access_token_hash"`
+	RefreshTokenHash string       `db:"refresh_token_hash"`
+	AccessExpiresAt  time.Time    `db:"access_expires_at"`
+	LastUsedAt       sql.NullTime `db:"last_used_at"`
+	CreatedAt        time.Time    `db:"created_at"`
+	UpdatedAt        time.Time    `db:"updated_at"`
+	DeletedAt        sql.NullTime `db:"deleted_at"`
+}
+
+func (o *OAuthGrant) ToDomain() *domain.OAuthGrant {
+	grant := new(domain.OAuthGrant)
+	grant.ID = o.ID
+	grant.UUID = o.UUID
+	grant.UserID = o.UserID
+	grant.ClientID = o.ClientID
+	grant.AccessExpiresAt = o.AccessExpiresAt
+	if o.LastUsedAt.Valid {
+		grant.LastUsedAt = o.LastUsedAt.Time
+	}
+	grant.CreatedAt = o.CreatedAt
+
+	return grant
+}
@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+type OnboardingState struct {
+	UserID      uint         `db:"user_id"`
+	DismissedAt sql.NullTime `db:"dismissed_at"`
+	TriggeredAt sql.NullTime `db:"triggered_at"`
+	CreatedAt   time.Time    `db:"created_at"`
+	UpdatedAt   time.Time    `db:"updated_at"`
+}
+
+type OnboardingProgress struct {
+	Kind      string    `db:"kind"`
+	Key       string    `db:"key"`
+	CreatedAt time.Time `db:"created_at"`
+}
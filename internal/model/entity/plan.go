@@ -0,0 +1,53 @@
+package entity
+
+import (
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type Plan struct {
+	ID              uint      `db:"id"`
+	UserID          uint      `db:"user_id"`
+	PlanDate        time.Time `db:"plan_date"`
+	CapacityMinutes int       `db:"capacity_minutes"`
+	CreatedAt       time.Time `db:"created_at"`
+	UpdatedAt       time.Time `db:"updated_at"`
+}
+
+func (p *Plan) ToDomain() *domain.Plan {
+	return &domain.Plan{
+		ID:        p.ID,
+		UserID:    p.UserID,
+		Date:      domain.Day(p.PlanDate),
+		Capacity:  time.Duration(p.CapacityMinutes) * time.Minute,
+		Items:     []*domain.PlanItem{},
+		CreatedAt: p.CreatedAt,
+		UpdatedAt: p.UpdatedAt,
+	}
+}
+
+// PlanItem is a planned todo joined with the todo itself.
+type PlanItem struct {
+	Todo
+	Position       int     `db:"position"`
+	Score          float64 `db:"score"`
+	PlannedMinutes int     `db:"planned_minutes"`
+	Reasons        string  `db:"reasons"`
+}
+
+func (p *PlanItem) ToDomain() *domain.PlanItem {
+	item := &domain.PlanItem{
+		Todo:     p.Todo.ToDomain(),
+		Position: p.Position,
+		Score:    p.Score,
+		Estimate: time.Duration(p.PlannedMinutes) * time.Minute,
+		Reasons:  []string{},
+	}
+	if p.Reasons != "" {
+		item.Reasons = strings.Split(p.Reasons, "\n")
+	}
+
+	return item
+}
@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type PushSubscription struct {
+	ID        uint      `db:"id"`
+	UUID      string    `db:"uuid"`
+	UserID    uint      `db:"user_id"`
+	Endpoint  string    `db:"endpoint"`
+	P256dh    string    `db:"p256dh"`
+	Auth      string    `db:"auth"`
+	UserAgent string    `db:"user_agent"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (e *PushSubscription) ToDomain() *domain.PushSubscription {
+	return &domain.PushSubscription{
+		ID:        e.ID,
+		UUID:      e.UUID,
+		UserID:    e.UserID,
+		Endpoint:  e.Endpoint,
+		P256dh:    e.P256dh,
+		Auth:      e.Auth,
+		UserAgent: e.UserAgent,
+		CreatedAt: e.CreatedAt,
+	}
+}
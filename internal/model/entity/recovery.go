@@ -0,0 +1,64 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type TrustedContact struct {
+	UserID    uint           `db:"user_id"`
+	UUID      string         `db:"uuid"`
+	Email     sql.NullString `db:"email"`
+	FirstName sql.NullString `db:"first_name"`
+	LastName  sql.NullString `db:"last_name"`
+	CreatedAt time.Time      `db:"created_at"`
+}
+
+func (c *TrustedContact) ToDomain() *domain.TrustedContact {
+	contact := new(domain.TrustedContact)
+	contact.UserID = c.UserID
+	contact.UUID = c.UUID
+	contact.Email = c.Email.String
+	contact.FirstName = c.FirstName.String
+	contact.LastName = c.LastName.String
+	contact.CreatedAt = c.CreatedAt
+
+	return contact
+}
+
+type RecoveryRequest struct {
+	ID          uint         `db:"id"`
+	UUID        string       `db:"uuid"`
+	UserID      uint         `db:"user_id"`
+	UserUUID    string       `db:"user_uuid"`
+	UserName    string       `db:"user_name"`
+	Approvals   int          `db:"approvals"`
+	Threshold   int          `db:"threshold"`
+	Approved    bool         `db:"approved"`
+	ReadyAt     time.Time    `db:"ready_at"`
+	ExpiresAt   time.Time    `db:"expires_at"`
+	CompletedAt sql.NullTime `db:"completed_at"`
+	CancelledAt sql.NullTime `db:"cancelled_at"`
+	CreatedAt   time.Time    `db:"created_at"`
+}
+
+func (r *RecoveryRequest) ToDomain() *domain.RecoveryRequest {
+	request := new(domain.RecoveryRequest)
+	request.ID = r.ID
+	request.UUID = r.UUID
+	request.UserID = r.UserID
+	request.UserUUID = r.UserUUID
+	request.UserName = r.UserName
+	request.Approvals = r.Approvals
+	request.Threshold = r.Threshold
+	request.Approved = r.Approved
+	request.ReadyAt = r.ReadyAt
+	request.ExpiresAt = r.ExpiresAt
+	request.CompletedAt = r.CompletedAt.Time
+	request.CancelledAt = r.CancelledAt.Time
+	request.CreatedAt = r.CreatedAt
+
+	return request
+}
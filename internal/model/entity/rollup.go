@@ -0,0 +1,24 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+// TodoRollup is a day of rollups summed over a chart scope, Day is zero for totals.
+type TodoRollup struct {
+	Day       time.Time `db:"day"`
+	Created   int       `db:"created"`
+	Completed int       `db:"completed"`
+	Deleted   int       `db:"deleted"`
+}
+
+func (r *TodoRollup) ToDomain() *domain.TodoRollup {
+	return &domain.TodoRollup{
+		Day:       r.Day.UTC(),
+		Created:   r.Created,
+		Completed: r.Completed,
+		Deleted:   r.Deleted,
+	}
+}
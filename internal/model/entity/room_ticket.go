@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type RoomTicket struct {
+	UserID           uint      `db:"user_id"`
+	UserUUID         string    `db:"user_uuid"`
+	Email            string    `db:"email"`
+	ListUUID         string    `db:"list_uuid"`
+	SessionExpiresAt time.Time `db:"session_expires_at"`
+	ExpiresAt        time.Time `db:"expires_at"`
+	CreatedAt        time.Time `db:"created_at"`
+}
+
+func (t *RoomTicket) ToDomain() *domain.RoomTicket {
+	return &domain.RoomTicket{
+		UserID:           t.UserID,
+		UserUUID:         t.UserUUID,
+		Email:            t.Email,
+		ListUUID:         t.ListUUID,
+		SessionExpiresAt: t.SessionExpiresAt,
+		ExpiresAt:        t.ExpiresAt,
+		CreatedAt:        t.CreatedAt,
+	}
+}
@@ -0,0 +1,69 @@
+package entity
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type Rule struct {
+	ID                  uint           `db:"id"`
+	UUID                string         `db:"uuid"`
+	UserID              uint           `db:"user_id"`
+	Name                string         `db:"name"`
+	Position            int            `db:"position"`
+	Field               string         `db:"field"`
+	Operator            string         `db:"operator"`
+	Value               string         `db:"value"`
+	Source              string         `db:"source"`
+	Channel             string         `db:"channel"`
+	Condition           string         `db:"condition"`
+	Tags                string         `db:"tags"`
+	Priority            sql.NullInt64  `db:"priority"`
+	ListUUID            sql.NullString `db:"list_uuid"`
+	ComputedTitle       string         `db:"computed_title"`
+	ComputedDescription string         `db:"computed_description"`
+	ComputedPriority    string         `db:"computed_priority"`
+	ComputedTags        string         `db:"computed_tags"`
+	Stop                bool           `db:"stop"`
+	CreatedAt           time.Time      `db:"created_at"`
+	UpdatedAt           time.Time      `db:"updated_at"`
+}
+
+func (r *Rule) ToDomain() *domain.Rule {
+	rule := new(domain.Rule)
+	rule.ID = r.ID
+	rule.UUID = r.UUID
+	rule.UserID = r.UserID
+	rule.Name = r.Name
+	rule.Position = r.Position
+	rule.Field = domain.RuleField(r.Field)
+	rule.Operator = domain.RuleOperator(r.Operator)
+	rule.Value = r.Value
+	rule.Source = domain.CaptureSource(r.Source)
+	rule.Channel = r.Channel
+	rule.Condition = r.Condition
+	rule.Tags = []string{}
+	if r.Tags != "" {
+		rule.Tags = strings.Split(r.Tags, "\n")
+	}
+	if r.Priority.Valid {
+		priority := domain.Priority(r.Priority.Int64)
+		rule.Priority = &priority
+	}
+	if r.ListUUID.Valid {
+		rule.ListUUID = r.ListUUID.String
+	}
+	rule.Computed = domain.RuleComputed{
+		Title:       r.ComputedTitle,
+		Description: r.ComputedDescription,
+		Priority:    r.ComputedPriority,
+		Tags:        r.ComputedTags,
+	}
+	rule.Stop = r.Stop
+	rule.CreatedAt = r.CreatedAt
+
+	return rule
+}
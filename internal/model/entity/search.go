@@ -0,0 +1,25 @@
+package entity
+
+import (
+	"database/sql"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type TodoSearchResult struct {
+	Todo
+	Rank    float64 `db:"rank"`
+	Snippet string  `db:"snippet"`
+
+	AttachmentUUID sql.NullString `db:"attachment_uuid"`
+}
+
+func (t *TodoSearchResult) ToDomain() *domain.TodoSearchResult {
+	return &domain.TodoSearchResult{
+		Todo:    t.Todo.ToDomain(),
+		Rank:    t.Rank,
+		Snippet: t.Snippet,
+
+		AttachmentUUID: t.AttachmentUUID.String,
+	}
+}
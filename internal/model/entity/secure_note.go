@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type SecureNote struct {
+	TodoID    uint          `db:"todo_id"`
+	Sealed    []byte        `db:"sealed"`
+	UpdatedBy sql.NullInt64 `db:"updated_by"`
+	CreatedAt time.Time     `db:"created_at"`
+	UpdatedAt time.Time     `db:"updated_at"`
+}
+
+func (n *SecureNote) ToDomain() *domain.SecureNote {
+	note := new(domain.SecureNote)
+	note.TodoID = n.TodoID
+	note.Sealed = n.Sealed
+	note.UpdatedBy = uint(n.UpdatedBy.Int64) //nolint:gosec // ids are positive
+	note.CreatedAt = n.CreatedAt
+	note.UpdatedAt = n.UpdatedAt
+
+	return note
+}
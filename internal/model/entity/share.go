@@ -0,0 +1,76 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type ListMember struct {
+	ListID    uint           `db:"list_id"`
+	UserID    uint           `db:"user_id"`
+	UserUUID  string         `db:"user_uuid"`
+	Email     sql.NullString `db:"email"`
+	Role      string         `db:"role"`
+	CreatedAt time.Time      `db:"created_at"`
+}
+
+func (m *ListMember) ToDomain() *domain.ListMember {
+	member := new(domain.ListMember)
+	member.ListID = m.ListID
+	member.UserID = m.UserID
+	member.UserUUID = m.UserUUID
+	if m.Email.Valid {
+		member.Email = m.Email.String
+	}
+	member.Role = domain.ListRole(m.Role)
+	member.CreatedAt = m.CreatedAt
+
+	return member
+}
+
+// SharedList is a list joined with the role of a member.
+type SharedList struct {
+	Role string `db:"role"`
+	List
+}
+
+func (s *SharedList) ToDomain() *domain.SharedList {
+	return &domain.SharedList{
+		List: s.List.ToDomain(),
+		Role: domain.ListRole(s.Role),
+	}
+}
+
+type ListInvitation struct {
+	ID         uint         `db:"id"`
+	UUID       string       `db:"uuid"`
+	ListID     uint         `db:"list_id"`
+	InviterID  uint         `db:"inviter_id"`
+	Email      string       `db:"email"`
+	Role       string       `db:"role"`
+	TokenHash  string       `db:"token_hash"`
+	ExpiresAt  time.Time    `db:"expires_at"`
+	AcceptedAt sql.NullTime `db:"accepted_at"`
+	CreatedAt  time.Time    `db:"created_at"`
+	UpdatedAt  time.Time    `db:"updated_at"`
+	DeletedAt  sql.NullTime `db:"deleted_at"`
+}
+
+func (i *ListInvitation) ToDomain() *domain.ListInvitation {
+	invitation := new(domain.ListInvitation)
+	invitation.ID = i.ID
+	invitation.UUID = i.UUID
+	invitation.ListID = i.ListID
+	invitation.InviterID = i.InviterID
+	invitation.Email = i.Email
+	invitation.Role = domain.ListRole(i.Role)
+	invitation.ExpiresAt = i.ExpiresAt
+	if i.AcceptedAt.Valid {
+		invitation.AcceptedAt = i.AcceptedAt.Time
+	}
+	invitation.CreatedAt = i.CreatedAt
+
+	return invitation
+}
@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type SigningKey struct {
+	ID         uint         `db:"id"`
+	KID        string       `db:"kid"`
+	Algorithm  string       `db:"algorithm"`
+	PrivateKey []byte       `db:"private_key"`
+	PublicKey  []byte       `db:"public_key"`
+	CreatedAt  time.Time    `db:"created_at"`
+	RetiresAt  sql.NullTime `db:"retires_at"`
+}
+
+func (k *SigningKey) ToDomain() *domain.SigningKey {
+	key := new(domain.SigningKey)
+	key.ID = k.ID
+	key.KID = k.KID
+	key.Algorithm = k.Algorithm
+	key.PrivateKey = k.PrivateKey
+	key.PublicKey = k.PublicKey
+	key.CreatedAt = k.CreatedAt
+	if k.RetiresAt.Valid {
+		key.RetiresAt = k.RetiresAt.Time
+	}
+
+	return key
+}
@@ -0,0 +1,93 @@
+package entity
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type SlackConnection struct {
+	ID                uint           `db:"id"`
+	UserID            uint           `db:"user_id"`
+	WebhookURL        string         `db:"webhook_url"`
+	BotToken          string         `db:"bot_token"`
+	Channel           string         `db:"channel"`
+	Events            string         `db:"events"`
+	TeamID            sql.NullString `db:"slack_team_id"`
+	SlackUserID       sql.NullString `db:"slack_user_id"`
+	LinkCodeHash      sql.NullString `db:"link_code_hash"`
+	LinkCodeExpiresAt sql.NullTime   `db:"link_code_expires_at"`
+	CreatedAt         time.Time      `db:"created_at"`
+	UpdatedAt         time.Time      `db:"updated_at"`
+}
+
+func (s *SlackConnection) ToDomain() *domain.SlackConnection {
+	conn := new(domain.SlackConnection)
+	conn.ID = s.ID
+	conn.UserID = s.UserID
+	conn.WebhookURL = s.WebhookURL
+	conn.BotToken = s.BotToken
+	conn.Channel = s.Channel
+	for _, event := range strings.Split(s.Events, ",") {
+		conn.Events = append(conn.Events, domain.SlackEvent(event))
+	}
+	conn.TeamID = s.TeamID.String
+	conn.SlackUserID = s.SlackUserID.String
+	conn.LinkCodeHash = s.LinkCodeHash.String
+	if s.LinkCodeExpiresAt.Valid {
+		conn.LinkCodeExpiresAt = s.LinkCodeExpiresAt.Time
+	}
+	conn.CreatedAt = s.CreatedAt
+	conn.UpdatedAt = s.UpdatedAt
+
+	return conn
+}
+
+// SlackMessage is a message joined with the connection it is posted through.
+type SlackMessage struct {
+	ID                   uint           `db:"id"`
+	UUID                 string         `db:"uuid"`
+	ConnectionID         uint           `db:"connection_id"`
+	ConnectionUserID     uint           `db:"connection_user_id"`
+	ConnectionWebhookURL string         `db:"connection_webhook_url"`
+	ConnectionBotToken   string         `db:"connection_bot_token"`
+	ConnectionChannel    string         `db:"connection_channel"`
+	Event                string         `db:"event"`
+	Text                 string         `db:"text"`
+	Status               string         `db:"status"`
+	Attempts             int            `db:"attempts"`
+	NextAttemptAt        time.Time      `db:"next_attempt_at"`
+	LastError            sql.NullString `db:"last_error"`
+	SentAt               sql.NullTime   `db:"sent_at"`
+	CreatedAt            time.Time      `db:"created_at"`
+	UpdatedAt            time.Time      `db:"updated_at"`
+}
+
+func (s *SlackMessage) ToDomain() *domain.SlackMessage {
+	message := new(domain.SlackMessage)
+	message.ID = s.ID
+	message.UUID = s.UUID
+	message.Connection = &domain.SlackConnection{
+		ID:         s.ConnectionID,
+		UserID:     s.ConnectionUserID,
+		WebhookURL: s.ConnectionWebhookURL,
+		BotToken:   s.ConnectionBotToken,
+		Channel:    s.ConnectionChannel,
+	}
+	message.Event = domain.SlackEvent(s.Event)
+	message.Text = s.Text
+	message.Status = domain.SlackMessageStatus(s.Status)
+	message.Attempts = s.Attempts
+	message.NextAttemptAt = s.NextAttemptAt
+	if s.LastError.Valid {
+		message.LastError = s.LastError.String
+	}
+	if s.SentAt.Valid {
+		message.SentAt = s.SentAt.Time
+	}
+	message.CreatedAt = s.CreatedAt
+
+	return message
+}
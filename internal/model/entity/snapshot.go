@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type ListSnapshotSchedule struct {
+	ID         uint         `db:"id"`
+	UUID       string       `db:"uuid"`
+	ListID     uint         `db:"list_id"`
+	UserID     uint         `db:"user_id"`
+	Recipients string       `db:"recipients"`
+	Frequency  string       `db:"frequency"`
+	NextRunAt  time.Time    `db:"next_run_at"`
+	LastSentAt sql.NullTime `db:"last_sent_at"`
+	CreatedAt  time.Time    `db:"created_at"`
+	UpdatedAt  time.Time    `db:"updated_at"`
+	DeletedAt  sql.NullTime `db:"deleted_at"`
+}
+
+func (s *ListSnapshotSchedule) ToDomain() *domain.ListSnapshotSchedule {
+	schedule := new(domain.ListSnapshotSchedule)
+	schedule.ID = s.ID
+	schedule.UUID = s.UUID
+	schedule.ListID = s.ListID
+	schedule.UserID = s.UserID
+	schedule.Recipients = strings.Split(s.Recipients, ",")
+	schedule.Frequency = domain.SnapshotFrequency(s.Frequency)
+	schedule.NextRunAt = s.NextRunAt
+	if s.LastSentAt.Valid {
+		schedule.LastSentAt = s.LastSentAt.Time
+	}
+	schedule.CreatedAt = s.CreatedAt
+
+	return schedule
+}
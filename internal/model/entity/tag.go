@@ -0,0 +1,37 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type Tag struct {
+	ID        uint           `db:"id"`
+	UUID      string         `db:"uuid"`
+	UserID    uint           `db:"user_id"`
+	Name      string         `db:"name"`
+	Color     sql.NullString `db:"color"`
+	CreatedAt time.Time      `db:"created_at"`
+	UpdatedAt time.Time      `db:"updated_at"`
+}
+
+// TodoTag is a tag joined with the todo it is assigned to.
+type TodoTag struct {
+	TodoID uint `db:"todo_id"`
+	Tag
+}
+
+func (t *Tag) ToDomain() *domain.Tag {
+	tag := new(domain.Tag)
+	tag.ID = t.ID
+	tag.UUID = t.UUID
+	tag.UserID = t.UserID
+	tag.Name = t.Name
+	tag.Color = t.Color.String
+	tag.CreatedAt = t.CreatedAt
+	tag.UpdatedAt = t.UpdatedAt
+
+	return tag
+}
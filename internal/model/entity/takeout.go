@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type Takeout struct {
+	ID         uint         `db:"id"`
+	UUID       string       `db:"uuid"`
+	UserID     uint         `db:"user_id"`
+	Status     string       `db:"status"`
+	StorageKey string       `db:"storage_key"`
+	Size       int64        `db:"size"`
+	Error      string       `db:"error"`
+	StartedAt  sql.NullTime `db:"started_at"`
+	FinishedAt sql.NullTime `db:"finished_at"`
+	ExpiresAt  sql.NullTime `db:"expires_at"`
+	CreatedAt  time.Time    `db:"created_at"`
+	UpdatedAt  time.Time    `db:"updated_at"`
+}
+
+func (t *Takeout) ToDomain() *domain.Takeout {
+	takeout := new(domain.Takeout)
+	takeout.ID = t.ID
+	takeout.UUID = t.UUID
+	takeout.UserID = t.UserID
+	takeout.Status = domain.TakeoutStatus(t.Status)
+	takeout.StorageKey = t.StorageKey
+	takeout.Size = t.Size
+	takeout.Error = t.Error
+	takeout.StartedAt = t.StartedAt.Time
+	takeout.FinishedAt = t.FinishedAt.Time
+	takeout.ExpiresAt = t.ExpiresAt.Time
+	takeout.CreatedAt = t.CreatedAt
+	takeout.UpdatedAt = t.UpdatedAt
+
+	return takeout
+}
@@ -0,0 +1,53 @@
+package entity
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type TodoTemplate struct {
+	ID          uint          `db:"id"`
+	UUID        string        `db:"uuid"`
+	UserID      uint          `db:"user_id"`
+	Name        string        `db:"name"`
+	Title       string        `db:"title"`
+	Description string        `db:"description"`
+	Priority    int           `db:"priority"`
+	Estimate    int           `db:"estimate_minutes"`
+	Tags        string        `db:"tags"`
+	Subtasks    string        `db:"subtasks"`
+	DueInDays   sql.NullInt64 `db:"due_in_days"`
+	CreatedAt   time.Time     `db:"created_at"`
+	UpdatedAt   time.Time     `db:"updated_at"`
+}
+
+func (t *TodoTemplate) ToDomain() *domain.TodoTemplate {
+	template := new(domain.TodoTemplate)
+	template.ID = t.ID
+	template.UUID = t.UUID
+	template.UserID = t.UserID
+	template.Name = t.Name
+	template.Title = t.Title
+	template.Description = t.Description
+	template.Priority = domain.Priority(t.Priority)
+	template.Estimate = time.Duration(t.Estimate) * time.Minute
+	template.Tags = []string{}
+	if t.Tags != "" {
+		template.Tags = strings.Split(t.Tags, "\n")
+	}
+	template.Subtasks = []string{}
+	if t.Subtasks != "" {
+		template.Subtasks = strings.Split(t.Subtasks, "\n")
+	}
+	if t.DueInDays.Valid {
+		dueIn := time.Duration(t.DueInDays.Int64) * 24 * time.Hour
+		template.DueIn = &dueIn
+	}
+	template.CreatedAt = t.CreatedAt
+	template.UpdatedAt = t.UpdatedAt
+
+	return template
+}
@@ -0,0 +1,82 @@
+package entity
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type Todo struct {
+	ID          uint           `db:"id"`
+	UUID        string         `db:"uuid"`
+	UserID      uint           `db:"user_id"`
+	ListID      sql.NullInt64  `db:"list_id"`
+	ListUUID    sql.NullString `db:"list_uuid"`
+	Title       string         `db:"title"`
+	Description string         `db:"description"`
+	Priority    int            `db:"priority"`
+	DueDate     sql.NullTime   `db:"due_date"`
+	Estimate    sql.NullInt64  `db:"estimate_minutes"`
+	Postponed   int            `db:"postponed_count"`
+	Version     int            `db:"version"`
+	CompletedAt sql.NullTime   `db:"completed_at"`
+	ArchivedAt  sql.NullTime   `db:"archived_at"`
+	Ciphertext  sql.NullString `db:"ciphertext"`
+	KeyVersion  sql.NullInt64  `db:"key_version"`
+	Status      sql.NullString `db:"status"`
+	Position    sql.NullInt64  `db:"position"`
+	Rank        sql.NullString `db:"rank"`
+	CreatedAt   time.Time      `db:"created_at"`
+	UpdatedAt   time.Time      `db:"updated_at"`
+	DeletedAt   sql.NullTime   `db:"deleted_at"`
+	// DeadLinks holds the dead links separated by newlines, like HasSecureNote it is only
+	// selected by reads.
+	DeadLinks     sql.NullString `db:"dead_links"`
+	HasSecureNote sql.NullBool   `db:"has_secure_note"`
+}
+
+func (t *Todo) ToDomain() *domain.Todo {
+	todo := new(domain.Todo)
+	todo.ID = t.ID
+	todo.UUID = t.UUID
+	todo.UserID = t.UserID
+	if t.ListID.Valid {
+		todo.ListID = uint(t.ListID.Int64) //nolint:gosec // ids are positive
+	}
+	if t.ListUUID.Valid {
+		todo.ListUUID = t.ListUUID.String
+	}
+	todo.Title = t.Title
+	todo.Description = t.Description
+	todo.Priority = domain.Priority(t.Priority)
+	if t.DueDate.Valid {
+		todo.DueDate = t.DueDate.Time
+	}
+	if t.Estimate.Valid {
+		todo.Estimate = time.Duration(t.Estimate.Int64) * time.Minute
+	}
+	todo.Postponed = t.Postponed
+	todo.Version = t.Version
+	if t.CompletedAt.Valid {
+		todo.CompletedAt = t.CompletedAt.Time
+	}
+	todo.ArchivedAt = t.ArchivedAt.Time
+	todo.Ciphertext = t.Ciphertext.String
+	todo.KeyVersion = int(t.KeyVersion.Int64)
+	todo.Status = t.Status.String
+	todo.Position = int(t.Position.Int64)
+	todo.Rank = t.Rank.String
+	todo.CreatedAt = t.CreatedAt
+	todo.UpdatedAt = t.UpdatedAt
+	if t.DeletedAt.Valid {
+		todo.DeletedAt = t.DeletedAt.Time
+	}
+	if t.DeadLinks.Valid && t.DeadLinks.String != "" {
+		todo.DeadLinks = strings.Split(t.DeadLinks.String, "\n")
+	}
+	todo.HasSecureNote = t.HasSecureNote.Bool
+
+	return todo
+}
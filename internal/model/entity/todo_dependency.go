@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type TodoDependency struct {
+	TodoID      uint      `db:"todo_id"`
+	TodoUUID    string    `db:"todo_uuid"`
+	BlockerID   uint      `db:"blocker_id"`
+	BlockerUUID string    `db:"blocker_uuid"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+func (d *TodoDependency) ToDomain() *domain.TodoDependency {
+	dependency := new(domain.TodoDependency)
+	dependency.TodoID = d.TodoID
+	dependency.TodoUUID = d.TodoUUID
+	dependency.BlockerID = d.BlockerID
+	dependency.BlockerUUID = d.BlockerUUID
+	dependency.CreatedAt = d.CreatedAt
+
+	return dependency
+}
@@ -8,26 +8,36 @@ import (
 )
 
 type User struct {
-	ID        uint           `db:"id"`
-	UUID      string         `db:"uuid"`
-	Email     string         `db:"email"`
-	FirstName sql.NullString `db:"first_name"`
-	LastName  sql.NullString `db:"last_name"`
-	CreatedAt time.Time      `db:"created_at"`
-	UpdatedAt time.Time      `db:"updated_at"`
-	DeletedAt sql.NullTime   `db:"deleted_at"`
+	ID            uint           `db:"id"`
+	UUID          string         `db:"uuid"`
+	Email         sql.NullString `db:"email"`
+	FirstName     sql.NullString `db:"first_name"`
+	LastName      sql.NullString `db:"last_name"`
+	Timezone      string         `db:"timezone"`
+	Locale        sql.NullString `db:"locale"`
+	AvatarURL     sql.NullString `db:"avatar_url"`
+	CreatedAt     time.Time      `db:"created_at"`
+	UpdatedAt     time.Time      `db:"updated_at"`
+	DeletedAt     sql.NullTime   `db:"deleted_at"`
+	ParentID      sql.NullInt64  `db:"parent_id"`
+	Username      sql.NullString `db:"username"`
+	AllowSharing  bool           `db:"allow_sharing"`
+	AllowDeletion bool           `db:"allow_deletion"`
+	PurgeAt       sql.NullTime   `db:"purge_at"`
 }
 
 type UserWithPassword struct {
 	ID        uint           `db:"id"`
 	Password  string         `db:"password"`
 	UUID      string         `db:"uuid"`
-	Email     string         `db:"email"`
+	Email     sql.NullString `db:"email"`
 	FirstName sql.NullString `db:"first_name"`
 	LastName  sql.NullString `db:"last_name"`
 	CreatedAt time.Time      `db:"created_at"`
 	UpdatedAt time.Time      `db:"updated_at"`
 	DeletedAt sql.NullTime   `db:"deleted_at"`
+	ParentID  sql.NullInt64  `db:"parent_id"`
+	Username  sql.NullString `db:"username"`
 }
 
 func (u *UserWithPassword) ToDomain() *domain.User {
@@ -35,7 +45,9 @@ func (u *UserWithPassword) ToDomain() *domain.User {
 	user.ID = u.ID
 	user.Password = u.Password
 	user.UUID = u.UUID
-	user.Email = u.Email
+	if u.Email.Valid {
+		user.Email = u.Email.String
+	}
 	if u.FirstName.Valid {
 		user.FirstName = u.FirstName.String
 	}
@@ -46,6 +58,12 @@ func (u *UserWithPassword) ToDomain() *domain.User {
 	if u.DeletedAt.Valid {
 		user.DeletedAt = u.DeletedAt.Time
 	}
+	if u.ParentID.Valid {
+		user.ParentID = uint(u.ParentID.Int64)
+	}
+	if u.Username.Valid {
+		user.Username = u.Username.String
+	}
 
 	return user
 }
@@ -54,17 +72,39 @@ func (u *User) ToDomain() *domain.User {
 	user := new(domain.User)
 	user.ID = u.ID
 	user.UUID = u.UUID
-	user.Email = u.Email
+	if u.Email.Valid {
+		user.Email = u.Email.String
+	}
 	if u.FirstName.Valid {
 		user.FirstName = u.FirstName.String
 	}
 	if u.LastName.Valid {
 		user.LastName = u.LastName.String
 	}
+	user.Timezone = u.Timezone
+	if u.Locale.Valid {
+		user.Locale = u.Locale.String
+	}
+	if u.AvatarURL.Valid {
+		user.AvatarURL = u.AvatarURL.String
+	}
 	user.CreatedAt = u.CreatedAt
 	if u.DeletedAt.Valid {
 		user.DeletedAt = u.DeletedAt.Time
 	}
+	if u.ParentID.Valid {
+		user.ParentID = uint(u.ParentID.Int64)
+	}
+	if u.Username.Valid {
+		user.Username = u.Username.String
+	}
+	user.Controls = domain.ParentalControls{
+		AllowSharing:  u.AllowSharing,
+		AllowDeletion: u.AllowDeletion,
+	}
+	if u.PurgeAt.Valid {
+		user.PurgeAt = u.PurgeAt.Time
+	}
 
 	return user
 }
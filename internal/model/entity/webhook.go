@@ -0,0 +1,87 @@
+package entity
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type Webhook struct {
+	ID        uint         `db:"id"`
+	UUID      string       `db:"uuid"`
+	UserID    uint         `db:"user_id"`
+	URL       string       `db:"url"`
+	Secret    string       `db:"secret"`
+	Events    string       `db:"events"`
+	CreatedAt time.Time    `db:"created_at"`
+	UpdatedAt time.Time    `db:"updated_at"`
+	DeletedAt sql.NullTime `db:"deleted_at"`
+}
+
+func (w *Webhook) ToDomain() *domain.Webhook {
+	webhook := new(domain.Webhook)
+	webhook.ID = w.ID
+	webhook.UUID = w.UUID
+	webhook.UserID = w.UserID
+	webhook.URL = w.URL
+	webhook.Secret = w.Secret
+	for _, event := range strings.Split(w.Events, ",") {
+		webhook.Events = append(webhook.Events, domain.EventType(event))
+	}
+	webhook.CreatedAt = w.CreatedAt
+
+	return webhook
+}
+
+// WebhookDelivery is a delivery joined with the webhook it is sent to.
+type WebhookDelivery struct {
+	ID             uint           `db:"id"`
+	UUID           string         `db:"uuid"`
+	WebhookID      uint           `db:"webhook_id"`
+	WebhookUUID    string         `db:"webhook_uuid"`
+	WebhookUserID  uint           `db:"webhook_user_id"`
+	WebhookURL     string         `db:"webhook_url"`
+	WebhookSecret  string         `db:"webhook_secret"`
+	Event          string         `db:"event"`
+	Payload        string         `db:"payload"`
+	Status         string         `db:"status"`
+	Attempts       int            `db:"attempts"`
+	NextAttemptAt  time.Time      `db:"next_attempt_at"`
+	LastStatusCode sql.NullInt64  `db:"last_status_code"`
+	LastError      sql.NullString `db:"last_error"`
+	DeliveredAt    sql.NullTime   `db:"delivered_at"`
+	CreatedAt      time.Time      `db:"created_at"`
+	UpdatedAt      time.Time      `db:"updated_at"`
+}
+
+func (w *WebhookDelivery) ToDomain() *domain.WebhookDelivery {
+	delivery := new(domain.WebhookDelivery)
+	delivery.ID = w.ID
+	delivery.UUID = w.UUID
+	delivery.Webhook = &domain.Webhook{
+		ID:     w.WebhookID,
+		UUID:   w.WebhookUUID,
+		UserID: w.WebhookUserID,
+		URL:    w.WebhookURL,
+		Secret: w.WebhookSecret,
+	}
+	delivery.Event = domain.EventType(w.Event)
+	delivery.Payload = []byte(w.Payload)
+	delivery.Status = domain.WebhookDeliveryStatus(w.Status)
+	delivery.Attempts = w.Attempts
+	delivery.NextAttemptAt = w.NextAttemptAt
+	if w.LastStatusCode.Valid {
+		delivery.LastStatusCode = int(w.LastStatusCode.Int64)
+	}
+	if w.LastError.Valid {
+		delivery.LastError = w.LastError.String
+	}
+	if w.DeliveredAt.Valid {
+		delivery.DeliveredAt = w.DeliveredAt.Time
+	}
+	delivery.CreatedAt = w.CreatedAt
+
+	return delivery
+}
@@ -0,0 +1,130 @@
+package entity
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type Workspace struct {
+	ID         uint      `db:"id"`
+	Slug       string    `db:"slug"`
+	Name       string    `db:"name"`
+	OwnerUUID  string    `db:"owner_uuid"`
+	Region     string    `db:"region"`
+	Plan       string    `db:"plan"`
+	MaxMembers int       `db:"max_members"`
+	MaxLists   int       `db:"max_lists"`
+	CreatedAt  time.Time `db:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at"`
+
+	MaxWebhookDeliveries int `db:"max_webhook_deliveries"`
+	MaxAPICalls          int `db:"max_api_calls"`
+	MaxIntegrationSyncs  int `db:"max_integration_syncs"`
+
+	WorkStartMinute int    `db:"work_start_minute"`
+	WorkEndMinute   int    `db:"work_end_minute"`
+	WorkDays        string `db:"work_days"`
+	Timezone        string `db:"timezone"`
+
+	// the SSO config is left joined
+	SSOIssuerURL    sql.NullString `db:"sso_issuer_url"`
+	SSOClientID     sql.NullString `db:"sso_client_id"`
+	SSOClientSecret sql.NullString `db:"sso_client_secret"`
+	SSODomains      sql.NullString `db:"sso_domains"`
+	SSOUpdatedAt    sql.NullTime   `db:"sso_updated_at"`
+}
+
+func (w *Workspace) ToDomain() *domain.Workspace {
+	workspace := &domain.Workspace{
+		ID:        w.ID,
+		Slug:      w.Slug,
+		Name:      w.Name,
+		OwnerUUID: w.OwnerUUID,
+		Region:    w.Region,
+		Plan:      w.Plan,
+		Quotas: domain.Quotas{
+			MaxMembers: w.MaxMembers,
+			MaxLists:   w.MaxLists,
+
+			MaxWebhookDeliveries: w.MaxWebhookDeliveries,
+			MaxAPICalls:          w.MaxAPICalls,
+			MaxIntegrationSyncs:  w.MaxIntegrationSyncs,
+		},
+		Hours: &domain.WorkingHours{
+			Start:    time.Duration(w.WorkStartMinute) * time.Minute,
+			End:      time.Duration(w.WorkEndMinute) * time.Minute,
+			Days:     parseWeekdays(w.WorkDays),
+			Timezone: w.Timezone,
+		},
+		CreatedAt: w.CreatedAt,
+		UpdatedAt: w.UpdatedAt,
+	}
+
+	if w.SSOIssuerURL.Valid {
+		workspace.SSO = &domain.SSOConfig{
+			IssuerURL:    w.SSOIssuerURL.String,
+			ClientID:     w.SSOClientID.String,
+			ClientSecret: w.SSOClientSecret.String,
+			UpdatedAt:    w.SSOUpdatedAt.Time,
+		}
+		if w.SSODomains.String != "" {
+			workspace.SSO.Domains = strings.Split(w.SSODomains.String, ",")
+		}
+	}
+
+	return workspace
+}
+
+type WorkspaceUsage struct {
+	Resource string `db:"resource"`
+	Used     int    `db:"used"`
+}
+
+// FormatWeekdays joins the weekdays as stored in work_days.
+func FormatWeekdays(days []time.Weekday) string {
+	values := make([]string, 0, len(days))
+	for _, day := range days {
+		values = append(values, strconv.Itoa(int(day)))
+	}
+
+	return strings.Join(values, ",")
+}
+
+func parseWeekdays(value string) []time.Weekday {
+	days := make([]time.Weekday, 0, 7)
+	for _, item := range strings.Split(value, ",") {
+		if day, err := strconv.Atoi(strings.TrimSpace(item)); err == nil && day >= 0 && day < 7 {
+			days = append(days, time.Weekday(day))
+		}
+	}
+
+	return days
+}
+
+type Holiday struct {
+	ID          uint      `db:"id"`
+	UUID        string    `db:"uuid"`
+	WorkspaceID uint      `db:"workspace_id"`
+	Date        time.Time `db:"date"`
+	Name        string    `db:"name"`
+	Recurring   bool      `db:"recurring"`
+	CreatedAt   time.Time `db:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
+
+func (h *Holiday) ToDomain() *domain.Holiday {
+	return &domain.Holiday{
+		ID:          h.ID,
+		UUID:        h.UUID,
+		WorkspaceID: h.WorkspaceID,
+		Date:        domain.Day(h.Date),
+		Name:        h.Name,
+		Recurring:   h.Recurring,
+		CreatedAt:   h.CreatedAt,
+		UpdatedAt:   h.UpdatedAt,
+	}
+}
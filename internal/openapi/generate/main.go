@@ -0,0 +1,810 @@
+// Command generate writes the OpenAPI document of the REST API. The routes are read off the
+// Add*Routes methods of the controllers, the request and response schemas off the types of the
+// endpoint package. Run go generate ./internal/openapi after changing either.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log"
+	"net/http"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// apiPrefix is the prefix of the API group, the routes of controllers added to it need an
+// access token.
+const apiPrefix = "/api"
+
+//nolint:gochecknoglobals // lookup table
+var statusCodes = map[string]int{
+	"StatusOK":                    200,
+	"StatusCreated":               201,
+	"StatusAccepted":              202,
+	"StatusNoContent":             204,
+	"StatusMovedPermanently":      301,
+	"StatusFound":                 302,
+	"StatusSeeOther":              303,
+	"StatusNotModified":           304,
+	"StatusTemporaryRedirect":     307,
+	"StatusBadRequest":            400,
+	"StatusUnauthorized":          401,
+	"StatusForbidden":             403,
+	"StatusNotFound":              404,
+	"StatusConflict":              409,
+	"StatusGone":                  410,
+	"StatusPreconditionFailed":    412,
+	"StatusRequestEntityTooLarge": 413,
+	"StatusUnsupportedMediaType":  415,
+	"StatusMisdirectedRequest":    421,
+	"StatusUnprocessableEntity":   422,
+	"StatusLocked":                423,
+	"StatusPreconditionRequired":  428,
+	"StatusTooManyRequests":       429,
+	"StatusInternalServerError":   500,
+	"StatusNotImplemented":        501,
+	"StatusBadGateway":            502,
+	"StatusServiceUnavailable":    503,
+}
+
+//nolint:gochecknoglobals // read-only
+var pathParam = regexp.MustCompile(`:([A-Za-z_]+)`)
+
+type object = map[string]any
+
+func main() {
+	controllers := flag.String("controllers", "../controller", "directory of the controller package")
+	endpoints := flag.String("endpoints", "../model/endpoint", "directory of the endpoint package")
+	out := flag.String("o", "openapi.json", "file to write the document to")
+	flag.Parse()
+
+	g := &generator{
+		types:     map[string]ast.Expr{},
+		docs:      map[string]string{},
+		factories: map[string]ast.Expr{},
+		schemas:   object{},
+		responses: object{},
+		funcs:     map[string]*ast.FuncDecl{},
+		methods:   map[string]*ast.FuncDecl{},
+		constants: map[string]string{},
+	}
+	g.loadEndpoints(parseDir(*endpoints))
+	g.loadControllers(parseDir(*controllers))
+
+	doc := object{
+		"openapi": "3.0.3",
+		"info": object{
+			"title":       "Todo API",
+			"version":     "1.0.0",
+			"description": "Generated from the controllers and endpoint types, do not edit by hand.",
+		},
+		"paths": g.paths,
+		"components": object{
+			"schemas":   g.schemas,
+			"responses": g.responses,
+			"securitySchemes": object{
+				"bearerAuth": object{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			},
+		},
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = os.WriteFile(*out, append(data, '\n'), 0o600); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func parseDir(dir string) []*ast.File {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, dir+"/"+name, nil, parser.ParseComments)
+		if err != nil {
+			log.Fatal(err)
+		}
+		files = append(files, file)
+	}
+
+	return files
+}
+
+type generator struct {
+	// types, docs and factories are read from the endpoint package, factories holds the result
+	// type of the New* functions.
+	types     map[string]ast.Expr
+	docs      map[string]string
+	factories map[string]ast.Expr
+	schemas   object
+	responses object
+
+	// funcs, methods and constants are read from the controller package, methods are keyed by
+	// receiver type and name.
+	funcs     map[string]*ast.FuncDecl
+	methods   map[string]*ast.FuncDecl
+	constants map[string]string
+	paths     map[string]object
+}
+
+func (g *generator) loadEndpoints(files []*ast.File) {
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					if spec, ok := spec.(*ast.TypeSpec); ok {
+						g.types[spec.Name.Name] = spec.Type
+						g.docs[spec.Name.Name] = comment(decl.Doc, spec.Doc)
+					}
+				}
+			case *ast.FuncDecl:
+				if decl.Recv == nil && strings.HasPrefix(decl.Name.Name, "New") &&
+					decl.Type.Results != nil && len(decl.Type.Results.List) == 1 {
+					g.factories[decl.Name.Name] = decl.Type.Results.List[0].Type
+				}
+			}
+		}
+	}
+}
+
+func (g *generator) loadControllers(files []*ast.File) {
+	var routes []*ast.FuncDecl
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				g.loadConstants(decl)
+			case *ast.FuncDecl:
+				if decl.Recv == nil {
+					g.funcs[decl.Name.Name] = decl
+					continue
+				}
+				receiver := receiverType(decl)
+				g.methods[receiver+"."+decl.Name.Name] = decl
+				if strings.HasPrefix(decl.Name.Name, "Add") && strings.HasSuffix(decl.Name.Name, "Routes") {
+					routes = append(routes, decl)
+				}
+			}
+		}
+	}
+
+	g.paths = map[string]object{}
+	for _, decl := range routes {
+		g.addRoutes(decl)
+	}
+}
+
+func (g *generator) loadConstants(decl *ast.GenDecl) {
+	if decl.Tok != token.CONST {
+		return
+	}
+	for _, spec := range decl.Specs {
+		spec, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+		for i, name := range spec.Names {
+			if i < len(spec.Values) {
+				if value, ok := g.eval(spec.Values[i]); ok {
+					g.constants[name.Name] = value
+				}
+			}
+		}
+	}
+}
+
+// addRoutes adds the routes registered by an Add*Routes method, routes on the API group and
+// those passed the auth middleware need an access token.
+func (g *generator) addRoutes(decl *ast.FuncDecl) {
+	params := decl.Type.Params.List
+	if len(params) == 0 || len(params[0].Names) == 0 {
+		return
+	}
+	base := params[0].Names[0].Name
+	prefixes := map[string]string{base: ""}
+	secure := map[string]bool{base: false}
+	if star, ok := params[0].Type.(*ast.StarExpr); ok {
+		if sel, ok := star.X.(*ast.SelectorExpr); ok && sel.Sel.Name == "Group" {
+			prefixes[base] = apiPrefix
+			secure[base] = true
+		}
+	}
+
+	receiver := receiverType(decl)
+	tag := strings.TrimSuffix(receiver, "Controller")
+
+	ast.Inspect(decl.Body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.AssignStmt:
+			// e.g. calendar := e.Group("/calendar")
+			if len(node.Lhs) != 1 || len(node.Rhs) != 1 {
+				return true
+			}
+			call, ok := node.Rhs[0].(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			parent, method, ok := selectorCall(call)
+			if !ok || method != "Group" || len(call.Args) == 0 {
+				return true
+			}
+			prefix, ok := g.eval(call.Args[0])
+			if !ok {
+				return true
+			}
+			if ident, ok := node.Lhs[0].(*ast.Ident); ok {
+				prefixes[ident.Name] = prefixes[parent] + prefix
+				secure[ident.Name] = secure[parent]
+			}
+		case *ast.CallExpr:
+			parent, method, ok := selectorCall(node)
+			if !ok || len(node.Args) < 2 {
+				return true
+			}
+			if _, known := prefixes[parent]; !known {
+				return true
+			}
+			switch method {
+			case "GET", "POST", "PUT", "PATCH", "DELETE":
+			default:
+				return true
+			}
+			path, ok := g.eval(node.Args[0])
+			if !ok || strings.Contains(path, "*") {
+				return true
+			}
+			authed := secure[parent]
+			for _, arg := range node.Args[2:] {
+				if ident, ok := arg.(*ast.Ident); ok && ident.Name == "auth" && node.Ellipsis.IsValid() {
+					authed = true
+				}
+			}
+			g.addOperation(method, prefixes[parent]+path, tag, receiver, node.Args[1], authed)
+		}
+		return true
+	})
+}
+
+func (g *generator) addOperation(method string, path string, tag string, receiver string, handler ast.Expr, authed bool) {
+	operation := object{
+		"tags":        []string{tag},
+		"operationId": lowerFirst(tag) + upperFirst(handlerName(handler)),
+		"responses":   object{},
+	}
+
+	var parameters []object
+	for _, match := range pathParam.FindAllStringSubmatch(path, -1) {
+		parameters = append(parameters, object{
+			"name": match[1], "in": "path", "required": true, "schema": object{"type": "string"},
+		})
+	}
+	path = pathParam.ReplaceAllString(path, "{$1}")
+
+	if decl := g.methods[receiver+"."+handlerName(handler)]; decl != nil {
+		if summary := firstSentence(comment(decl.Doc)); summary != "" {
+			operation["summary"] = summary
+		}
+		h := &handlerInfo{queries: map[string]object{}, responses: map[int]object{}}
+		g.inspectHandler(decl.Body, h, 0)
+
+		names := make([]string, 0, len(h.queries))
+		for name := range h.queries {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			parameters = append(parameters, object{"name": name, "in": "query", "schema": h.queries[name]})
+		}
+
+		if h.request != "" && method != "GET" && method != "DELETE" {
+			operation["requestBody"] = object{
+				"required": true,
+				"content":  object{g.contentType(h.request): object{"schema": g.ref(h.request)}},
+			}
+		}
+		for code, response := range h.responses {
+			operation["responses"].(object)[strconv.Itoa(code)] = response
+		}
+	}
+	if authed {
+		operation["security"] = []object{{"bearerAuth": []string{}}}
+		operation["responses"].(object)["401"] = g.errorResponse(401)
+	}
+	// the errors of the service layer are answered by the error handler of the router
+	operation["responses"].(object)["default"] = g.errorResponse(0)
+	if len(parameters) > 0 {
+		operation["parameters"] = parameters
+	}
+
+	if g.paths[path] == nil {
+		g.paths[path] = object{}
+	}
+	g.paths[path][strings.ToLower(method)] = operation
+}
+
+type handlerInfo struct {
+	request   string
+	queries   map[string]object
+	responses map[int]object
+}
+
+// inspectHandler collects the request type, query parameters and responses of a handler, the
+// helpers of the controller package it passes the context to are followed.
+func (g *generator) inspectHandler(body *ast.BlockStmt, h *handlerInfo, depth int) {
+	if body == nil || depth > 3 {
+		return
+	}
+
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.ValueSpec:
+			// var req endpoint.TodoCreateRequest
+			if sel, ok := node.Type.(*ast.SelectorExpr); ok && isPackage(sel.X, "endpoint") && h.request == "" {
+				h.request = sel.Sel.Name
+			}
+		case *ast.CallExpr:
+			g.inspectCall(node, h, depth)
+		}
+		return true
+	})
+}
+
+func (g *generator) inspectCall(call *ast.CallExpr, h *handlerInfo, depth int) {
+	if ident, ok := call.Fun.(*ast.Ident); ok {
+		switch {
+		case ident.Name == "timeParam" && len(call.Args) == 2:
+			if name, ok := g.eval(call.Args[1]); ok {
+				h.queries[name] = object{"type": "string", "format": "date-time"}
+			}
+		case g.funcs[ident.Name] != nil && passesContext(call):
+			g.inspectHandler(g.funcs[ident.Name].Body, h, depth+1)
+		}
+		return
+	}
+
+	receiver, method, ok := selectorCall(call)
+	if ok && receiver == "echo" && method == "NewHTTPError" && len(call.Args) > 0 {
+		if code, ok := statusCode(call.Args[0]); ok {
+			h.responses[code] = g.errorResponse(code)
+		}
+		return
+	}
+	if !ok || receiver != "c" {
+		return
+	}
+	switch method {
+	case "Validate":
+		h.responses[http.StatusUnprocessableEntity] = g.errorResponse(http.StatusUnprocessableEntity)
+	case "QueryParam":
+		if len(call.Args) == 1 {
+			if name, ok := g.eval(call.Args[0]); ok {
+				if _, seen := h.queries[name]; !seen {
+					h.queries[name] = object{"type": "string"}
+				}
+			}
+		}
+	case "JSON":
+		if len(call.Args) == 2 {
+			if code, ok := statusCode(call.Args[0]); ok {
+				if code >= 400 {
+					h.responses[code] = g.errorResponse(code)
+				} else if _, seen := h.responses[code]; !seen {
+					h.responses[code] = object{
+						"description": statusText(code),
+						"content":     object{"application/json": object{"schema": g.valueSchema(call.Args[1])}},
+					}
+				}
+			}
+		}
+	case "NoContent", "Redirect":
+		if len(call.Args) > 0 {
+			if code, ok := statusCode(call.Args[0]); ok {
+				h.responses[code] = object{"description": statusText(code)}
+			}
+		}
+	case "Blob", "Stream":
+		if len(call.Args) > 1 {
+			if code, ok := statusCode(call.Args[0]); ok {
+				h.responses[code] = object{
+					"description": statusText(code),
+					"content": object{"application/octet-stream": object{
+						"schema": object{"type": "string", "format": "binary"},
+					}},
+				}
+			}
+		}
+	}
+}
+
+// valueSchema returns the schema of a response body, the fields of echo.Map literals and the
+// results of endpoint factories are known, anything else is left open.
+func (g *generator) valueSchema(expr ast.Expr) object {
+	switch expr := expr.(type) {
+	case *ast.CompositeLit:
+		properties := object{}
+		for _, elt := range expr.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+			if key, ok := g.eval(kv.Key); ok {
+				properties[key] = g.valueSchema(kv.Value)
+			}
+		}
+		return object{"type": "object", "properties": properties}
+	case *ast.BasicLit:
+		return object{"type": "string"}
+	case *ast.UnaryExpr:
+		return g.valueSchema(expr.X)
+	case *ast.CallExpr:
+		if sel, ok := expr.Fun.(*ast.SelectorExpr); ok {
+			if isPackage(sel.X, "endpoint") {
+				if result, ok := g.factories[sel.Sel.Name]; ok {
+					return g.schema(result)
+				}
+			}
+			switch sel.Sel.Name {
+			case "Encode", "Error", "String":
+				return object{"type": "string"}
+			}
+		}
+	}
+
+	return object{}
+}
+
+// schema returns the schema of a type of the endpoint package.
+func (g *generator) schema(expr ast.Expr) object {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		switch expr.Name {
+		case "string":
+			return object{"type": "string"}
+		case "bool":
+			return object{"type": "boolean"}
+		case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+			return object{"type": "integer"}
+		case "float32", "float64":
+			return object{"type": "number"}
+		case "any":
+			return object{}
+		}
+		if _, ok := g.types[expr.Name]; ok {
+			return g.ref(expr.Name)
+		}
+		return object{}
+	case *ast.StarExpr:
+		return g.schema(expr.X)
+	case *ast.ArrayType:
+		if ident, ok := expr.Elt.(*ast.Ident); ok && ident.Name == "byte" {
+			return object{"type": "string", "format": "byte"}
+		}
+		return object{"type": "array", "items": g.schema(expr.Elt)}
+	case *ast.MapType:
+		return object{"type": "object", "additionalProperties": g.schema(expr.Value)}
+	case *ast.SelectorExpr:
+		switch {
+		case isPackage(expr.X, "time") && expr.Sel.Name == "Time":
+			return object{"type": "string", "format": "date-time"}
+		case isPackage(expr.X, "time") && expr.Sel.Name == "Duration":
+			return object{"type": "integer"}
+		}
+		return object{}
+	case *ast.StructType:
+		return g.structSchema(expr)
+	}
+
+	return object{}
+}
+
+// ref adds the component of a named type of the endpoint package and refers to it.
+func (g *generator) ref(name string) object {
+	if _, ok := g.schemas[name]; !ok {
+		// a placeholder ends the recursion of self-referencing types
+		g.schemas[name] = object{}
+		schema := g.schema(g.types[name])
+		if doc := g.docs[name]; doc != "" {
+			schema["description"] = doc
+		}
+		g.schemas[name] = schema
+	}
+
+	return object{"$ref": "#/components/schemas/" + name}
+}
+
+func (g *generator) structSchema(st *ast.StructType) object {
+	properties := object{}
+	var required []string
+	g.addFields(st, properties, &required)
+
+	schema := object{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+
+	return schema
+}
+
+func (g *generator) addFields(st *ast.StructType, properties object, required *[]string) {
+	for _, field := range st.Fields.List {
+		tag := reflect.StructTag("")
+		if field.Tag != nil {
+			if unquoted, err := strconv.Unquote(field.Tag.Value); err == nil {
+				tag = reflect.StructTag(unquoted)
+			}
+		}
+
+		// embedded structs of the endpoint package are flattened like encoding/json does
+		if len(field.Names) == 0 {
+			if ident, ok := field.Type.(*ast.Ident); ok {
+				if embedded, ok := g.types[ident.Name].(*ast.StructType); ok && tag.Get("json") == "" {
+					g.addFields(embedded, properties, required)
+				}
+			}
+			continue
+		}
+		if !field.Names[0].IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag.Get("json"), ",")
+		if name == "" {
+			name, _, _ = strings.Cut(tag.Get("form"), ",")
+		}
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Names[0].Name
+		}
+
+		schema := g.schema(field.Type)
+		if _, isRef := schema["$ref"]; !isRef {
+			if doc := comment(field.Doc, field.Comment); doc != "" {
+				schema["description"] = doc
+			}
+			if validate(tag.Get("validate"), schema) {
+				*required = append(*required, name)
+			}
+		} else if validate(tag.Get("validate"), object{}) {
+			*required = append(*required, name)
+		}
+		properties[name] = schema
+	}
+}
+
+// contentType picks form encoding for requests bound from form fields, e.g. the OAuth token
+// request.
+func (g *generator) contentType(name string) string {
+	if st, ok := g.types[name].(*ast.StructType); ok {
+		for _, field := range st.Fields.List {
+			if field.Tag != nil && strings.Contains(field.Tag.Value, `form:"`) {
+				return "application/x-www-form-urlencoded"
+			}
+		}
+	}
+
+	return "application/json"
+}
+
+// eval evaluates a string expression of literals and constants, e.g. "/"+V1+"/todos".
+func (g *generator) eval(expr ast.Expr) (string, bool) {
+	switch expr := expr.(type) {
+	case *ast.BasicLit:
+		if expr.Kind != token.STRING {
+			return "", false
+		}
+		value, err := strconv.Unquote(expr.Value)
+		return value, err == nil
+	case *ast.Ident:
+		value, ok := g.constants[expr.Name]
+		return value, ok
+	case *ast.BinaryExpr:
+		if expr.Op != token.ADD {
+			return "", false
+		}
+		left, ok := g.eval(expr.X)
+		if !ok {
+			return "", false
+		}
+		right, ok := g.eval(expr.Y)
+		return left + right, ok
+	case *ast.ParenExpr:
+		return g.eval(expr.X)
+	}
+
+	return "", false
+}
+
+// validate applies the validator rules of a field to its schema and reports whether it is
+// required, rules after dive apply to the elements and are left out.
+func validate(rules string, schema object) bool {
+	if rules == "" {
+		return false
+	}
+
+	required := false
+	for _, rule := range strings.Split(rules, ",") {
+		name, value, _ := strings.Cut(rule, "=")
+		switch name {
+		case "dive":
+			return required
+		case "required":
+			required = true
+		case "email":
+			schema["format"] = "email"
+		case "url":
+			schema["format"] = "uri"
+		case "oneof":
+			schema["enum"] = strings.Fields(value)
+		case "min", "max", "len":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				continue
+			}
+			applyBound(schema, name, n)
+		}
+	}
+
+	return required
+}
+
+func applyBound(schema object, rule string, n int) {
+	keys := map[string][2]string{
+		"string":  {"minLength", "maxLength"},
+		"array":   {"minItems", "maxItems"},
+		"integer": {"minimum", "maximum"},
+		"number":  {"minimum", "maximum"},
+	}
+	kind, _ := schema["type"].(string)
+	bounds, ok := keys[kind]
+	if !ok {
+		return
+	}
+
+	switch rule {
+	case "min":
+		schema[bounds[0]] = n
+	case "max":
+		schema[bounds[1]] = n
+	case "len":
+		schema[bounds[0]] = n
+		schema[bounds[1]] = n
+	}
+}
+
+// errorResponse refers to the shared response of an error status, every error is answered
+// with problem details. Code 0 is the response of any other error.
+func (g *generator) errorResponse(code int) object {
+	name, description := "Error", "Error"
+	if code != 0 {
+		description = statusText(code)
+		name = strings.ReplaceAll(description, " ", "")
+	}
+	if _, ok := g.responses[name]; !ok {
+		g.responses[name] = object{
+			"description": description,
+			"content":     object{"application/problem+json": object{"schema": g.ref("Problem")}},
+		}
+	}
+
+	return object{"$ref": "#/components/responses/" + name}
+}
+
+func statusCode(expr ast.Expr) (int, bool) {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok || !isPackage(sel.X, "http") {
+		return 0, false
+	}
+	code, ok := statusCodes[sel.Sel.Name]
+	return code, ok
+}
+
+func statusText(code int) string {
+	if text := http.StatusText(code); text != "" {
+		return text
+	}
+	return fmt.Sprintf("Status %d", code)
+}
+
+func selectorCall(call *ast.CallExpr) (string, string, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return "", "", false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return "", "", false
+	}
+	return ident.Name, sel.Sel.Name, true
+}
+
+// passesContext reports whether the echo context c is an argument of the call.
+func passesContext(call *ast.CallExpr) bool {
+	for _, arg := range call.Args {
+		if ident, ok := arg.(*ast.Ident); ok && ident.Name == "c" {
+			return true
+		}
+	}
+	return false
+}
+
+func isPackage(expr ast.Expr, name string) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == name
+}
+
+func receiverType(decl *ast.FuncDecl) string {
+	expr := decl.Recv.List[0].Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+func handlerName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.SelectorExpr:
+		return expr.Sel.Name
+	case *ast.Ident:
+		return expr.Name
+	case *ast.CallExpr:
+		// canary routes are documented by their stable handler
+		if sel, ok := expr.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Canary" && len(expr.Args) == 3 {
+			return handlerName(expr.Args[1])
+		}
+	}
+	return "handler"
+}
+
+func comment(groups ...*ast.CommentGroup) string {
+	for _, group := range groups {
+		if text := strings.TrimSpace(group.Text()); text != "" {
+			return strings.Join(strings.Fields(text), " ")
+		}
+	}
+	return ""
+}
+
+func firstSentence(text string) string {
+	if i := strings.Index(text, ". "); i >= 0 {
+		return text[:i+1]
+	}
+	return text
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,21 @@
+// Package openapi holds the OpenAPI document of the REST API. It is generated from the routes of
+// the controllers and the endpoint types, clients can be generated from it.
+package openapi
+
+import (
+	_ "embed"
+)
+
+//go:generate go run ./generate -controllers ../controller -endpoints ../model/endpoint -o openapi.json
+
+var (
+	// Spec is the OpenAPI 3 document, served on /openapi.json.
+	//
+	//go:embed openapi.json
+	Spec []byte
+
+	// Docs is the Swagger UI page for Spec, served on /docs. The UI itself is loaded from a CDN.
+	//
+	//go:embed docs.html
+	Docs []byte
+)
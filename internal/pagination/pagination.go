@@ -0,0 +1,114 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+const (
+	DefaultLimit = 25
+	MaxLimit     = 100
+)
+
+var (
+	ErrInvalidCursor = domain.NewError(domain.KindValidation, "invalid cursor")
+	ErrInvalidLimit  = domain.NewError(domain.KindValidation, "invalid limit")
+)
+
+// Cursor points right after the last item of a page. Values holds the sort key of that item
+// and ID breaks ties, Sort identifies the ordering the cursor was created for.
+type Cursor struct {
+	Values []string `json:"v,omitempty"`
+	ID     uint     `json:"id"`
+	Sort   string   `json:"s,omitempty"`
+}
+
+// Encode returns the opaque string handed to clients, a nil cursor encodes to an empty string.
+func (c *Cursor) Encode() string {
+	if c == nil {
+		return ""
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return ""
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func Decode(encoded string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	var cursor Cursor
+	if err = json.Unmarshal(data, &cursor); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	return &cursor, nil
+}
+
+// Page requests at most Limit items after Cursor, a nil Cursor requests the first page.
+type Page struct {
+	Cursor *Cursor
+	Limit  int
+}
+
+// NewPage parses the cursor and limit request parameters, a zero limit uses the default.
+func NewPage(cursor string, limit int) (*Page, error) {
+	if limit < 0 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidLimit, limit)
+	}
+	if limit == 0 {
+		limit = DefaultLimit
+	}
+
+	page := &Page{
+		Limit: min(limit, MaxLimit),
+	}
+
+	if cursor != "" {
+		decoded, err := Decode(cursor)
+		if err != nil {
+			return nil, err
+		}
+		page.Cursor = decoded
+	}
+
+	return page, nil
+}
+
+// After returns the cursor when it was created for the given sort, otherwise ErrInvalidCursor.
+func (p *Page) After(sort string, values int) (*Cursor, error) {
+	if p == nil || p.Cursor == nil {
+		return nil, nil //nolint:nilnil // no cursor means the first page
+	}
+
+	if p.Cursor.Sort != sort || len(p.Cursor.Values) != values {
+		return nil, ErrInvalidCursor
+	}
+
+	return p.Cursor, nil
+}
+
+// FetchLimit is the number of rows to query, one more than the limit to detect a next page.
+func (p *Page) FetchLimit() int {
+	return p.Limit + 1
+}
+
+// Trim drops the extra item fetched to detect a next page and returns the cursor of the
+// next page, or nil when this is the last page.
+func Trim[T any](items []T, page *Page, cursor func(item T) *Cursor) ([]T, *Cursor) {
+	if page == nil || len(items) <= page.Limit {
+		return items, nil
+	}
+
+	items = items[:page.Limit]
+	return items, cursor(items[len(items)-1])
+}
@@ -0,0 +1,118 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+	argon2Prefix     = "$argon2id$"
+)
+
+// Argon2Params are the cost parameters of Argon2id, see RFC 9106 for choosing them.
+type Argon2Params struct {
+	// Memory is in KiB.
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+}
+
+// argon2Hasher hashes with Argon2id, hashes are encoded in the PHC string format used by the
+// reference implementation, e.g. $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>.
+type argon2Hasher struct {
+	params Argon2Params
+}
+
+func NewArgon2id(params Argon2Params) *argon2Hasher {
+	return &argon2Hasher{
+		params: params,
+	}
+}
+
+var _ PasswordHasher = (*argon2Hasher)(nil)
+
+func (h *argon2Hasher) Hash(password string) (string, error) {
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, argon2KeyLength)
+
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2Prefix, argon2.Version,
+		h.params.Memory, h.params.Iterations, h.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (h *argon2Hasher) Compare(hash string, password string) error {
+	decoded, err := decodeArgon2(hash)
+	if err != nil {
+		return err
+	}
+	key := argon2.IDKey([]byte(password), decoded.salt,
+		decoded.params.Iterations, decoded.params.Memory, decoded.params.Parallelism, uint32(len(decoded.key)))
+	if subtle.ConstantTimeCompare(key, decoded.key) != 1 {
+		return ErrMismatch
+	}
+
+	return nil
+}
+
+func (h *argon2Hasher) Recognizes(hash string) bool {
+	return strings.HasPrefix(hash, argon2Prefix)
+}
+
+func (h *argon2Hasher) Outdated(hash string) bool {
+	decoded, err := decodeArgon2(hash)
+	return err != nil || decoded.params != h.params || len(decoded.key) != argon2KeyLength
+}
+
+type argon2Hash struct {
+	params Argon2Params
+	salt   []byte
+	key    []byte
+}
+
+func decodeArgon2(hash string) (*argon2Hash, error) {
+	// "", "argon2id", version, parameters, salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, ErrUnknownHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return nil, fmt.Errorf("invalid argon2id version: %w", err)
+	}
+	if version != argon2.Version {
+		return nil, fmt.Errorf("unsupported argon2id version %d", version)
+	}
+
+	var decoded argon2Hash
+	_, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d",
+		&decoded.params.Memory, &decoded.params.Iterations, &decoded.params.Parallelism)
+	if err != nil {
+		return nil, fmt.Errorf("invalid argon2id parameters: %w", err)
+	}
+	if decoded.params.Iterations == 0 || decoded.params.Parallelism == 0 {
+		return nil, fmt.Errorf("invalid argon2id parameters %q", parts[3])
+	}
+	if decoded.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, fmt.Errorf("invalid argon2id salt: %w", err)
+	}
+	if decoded.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return nil, fmt.Errorf("invalid argon2id key: %w", err)
+	}
+	if len(decoded.key) == 0 {
+		return nil, errors.New("empty argon2id key")
+	}
+
+	return &decoded, nil
+}
@@ -0,0 +1,65 @@
+// Package password hashes passwords for storage. New passwords are hashed with one algorithm
+// while the hashes of every known algorithm keep verifying, so the algorithm or its parameters
+// can change without locking anyone out. Outdated hashes are replaced when the password is next
+// known, i.e. on login.
+package password
+
+import (
+	"errors"
+)
+
+var (
+	// ErrMismatch is returned when a password doesn't match its hash.
+	ErrMismatch = errors.New("password doesn't match hash")
+	// ErrUnknownHash is returned for hashes none of the algorithms made.
+	ErrUnknownHash = errors.New("unknown password hash")
+)
+
+// PasswordHasher is a password hashing algorithm, implementations must be safe for concurrent
+// use.
+type PasswordHasher interface {
+	// Hash returns the hash of password encoded with its parameters.
+	Hash(password string) (string, error)
+	// Compare returns ErrMismatch when password doesn't match hash.
+	Compare(hash string, password string) error
+	// Recognizes reports whether hash was made by the algorithm, with any parameters.
+	Recognizes(hash string) bool
+	// Outdated reports whether hash was made with other parameters than new hashes get.
+	Outdated(hash string) bool
+}
+
+// Passwords hashes new passwords with its current algorithm and verifies hashes of all of its
+// algorithms.
+type Passwords struct {
+	current PasswordHasher
+	hashers []PasswordHasher
+}
+
+// New returns Passwords hashing with current, the hashes of others keep verifying.
+func New(current PasswordHasher, others ...PasswordHasher) *Passwords {
+	return &Passwords{
+		current: current,
+		hashers: append([]PasswordHasher{current}, others...),
+	}
+}
+
+func (p *Passwords) Hash(password string) (string, error) {
+	return p.current.Hash(password)
+}
+
+// Verify returns ErrMismatch when password doesn't match hash. rehash reports whether a matching
+// hash is outdated and should be replaced by a new hash of password.
+func (p *Passwords) Verify(hash string, password string) (rehash bool, err error) {
+	for _, hasher := range p.hashers {
+		if !hasher.Recognizes(hash) {
+			continue
+		}
+		if err = hasher.Compare(hash, password); err != nil {
+			return false, err
+		}
+
+		return hasher != p.current || hasher.Outdated(hash), nil
+	}
+
+	return false, ErrUnknownHash
+}
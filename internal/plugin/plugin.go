@@ -0,0 +1,183 @@
+// Package plugin lets compiled-in plugins hook into the app without patching its services.
+// A plugin registers itself from the init func of its package, the binary includes it with a
+// blank import:
+//
+//	import _ "example.com/fork/plugins/audit"
+//
+// Plugins implement the hooks they need besides Plugin: TodoHook, SignupHook and RouteHook.
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/meowmix1337/the_recipe_book/internal/metrics"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// Plugin is an extension of the app. Init is called once on startup with the settings of the
+// plugin from PLUGIN_SETTINGS, a plugin whose Init fails isn't loaded.
+type Plugin interface {
+	Init(settings map[string]string) error
+}
+
+// TodoHook is notified after a todo changed, e.g. it was created or completed.
+type TodoHook interface {
+	HandleTodoEvent(ctx context.Context, event *domain.TodoEvent) error
+}
+
+// SignupHook is notified after a user signed up.
+type SignupHook interface {
+	HandleSignup(ctx context.Context, user *domain.User) error
+}
+
+// RouteHook adds routes to the API. The group is mounted at /api/v1/plugins/<name> behind the
+// authentication of the API, so plugins can't shadow the routes of the app.
+type RouteHook interface {
+	AddRoutes(e *echo.Group)
+}
+
+type registration struct {
+	name   string
+	order  int
+	plugin Plugin
+}
+
+//nolint:gochecknoglobals // plugins register themselves by name, like database drivers
+var registry = struct {
+	mu      sync.RWMutex
+	plugins map[string]*registration
+}{plugins: map[string]*registration{}}
+
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Register makes a plugin available by name. The hooks of plugins run in ascending order, plugins
+// of the same order by name. Registering a name twice or a name that isn't lowercase letters,
+// digits, dashes and underscores panics.
+func Register(name string, order int, plugin Plugin) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if !validName.MatchString(name) {
+		panic(fmt.Sprintf("plugin name %q is invalid", name))
+	}
+	if _, ok := registry.plugins[name]; ok {
+		panic(fmt.Sprintf("plugin %q registered twice", name))
+	}
+	registry.plugins[name] = &registration{name: name, order: order, plugin: plugin}
+}
+
+// Registered returns the names of the registered plugins in the order their hooks run.
+func Registered() []string {
+	registrations := registered()
+	names := make([]string, 0, len(registrations))
+	for _, registration := range registrations {
+		names = append(names, registration.name)
+	}
+
+	return names
+}
+
+func registered() []*registration {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	registrations := make([]*registration, 0, len(registry.plugins))
+	for _, registration := range registry.plugins {
+		registrations = append(registrations, registration)
+	}
+	sort.Slice(registrations, func(i, j int) bool {
+		if registrations[i].order != registrations[j].order {
+			return registrations[i].order < registrations[j].order
+		}
+		return registrations[i].name < registrations[j].name
+	})
+
+	return registrations
+}
+
+// Host runs the hooks of the loaded plugins. A hook that fails or panics is logged and counted,
+// it never fails the request that ran it or keeps the other plugins from running.
+type Host struct {
+	plugins []*registration
+}
+
+// Load initializes the registered plugins except the disabled ones, settings returns the
+// settings of a plugin by its name.
+func Load(settings func(name string) map[string]string, disabled []string) *Host {
+	host := &Host{}
+	for _, registration := range registered() {
+		if slices.Contains(disabled, registration.name) {
+			log.Info().Str("plugin", registration.name).Msg("plugin disabled")
+			continue
+		}
+
+		err := host.run(registration.name, "init", func() error {
+			return registration.plugin.Init(settings(registration.name))
+		})
+		if err != nil {
+			continue
+		}
+		host.plugins = append(host.plugins, registration)
+		log.Info().Str("plugin", registration.name).Msg("plugin loaded")
+	}
+
+	return host
+}
+
+// HandleTodoEvent runs the todo hooks, it subscribes the plugins to the todo service.
+func (h *Host) HandleTodoEvent(ctx context.Context, event *domain.TodoEvent) {
+	for _, registration := range h.plugins {
+		if hook, ok := registration.plugin.(TodoHook); ok {
+			_ = h.run(registration.name, string(event.Type), func() error {
+				return hook.HandleTodoEvent(ctx, event)
+			})
+		}
+	}
+}
+
+// HandleSignup runs the signup hooks, it subscribes the plugins to the user service.
+func (h *Host) HandleSignup(ctx context.Context, user *domain.User) {
+	for _, registration := range h.plugins {
+		if hook, ok := registration.plugin.(SignupHook); ok {
+			_ = h.run(registration.name, "user.signup", func() error {
+				return hook.HandleSignup(ctx, user)
+			})
+		}
+	}
+}
+
+// AddRoutes mounts the routes of the plugins, each below a group of its own.
+func (h *Host) AddRoutes(e *echo.Group) {
+	for _, registration := range h.plugins {
+		if hook, ok := registration.plugin.(RouteHook); ok {
+			name := registration.name
+			_ = h.run(name, "routes", func() error {
+				hook.AddRoutes(e.Group("/v1/plugins/" + name))
+				return nil
+			})
+		}
+	}
+}
+
+// run runs a hook of a plugin, a panic is turned into an error.
+func (h *Host) run(name string, hook string, fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+		if err != nil {
+			log.Err(err).Str("plugin", name).Str("hook", hook).Msg("plugin hook failed")
+			metrics.PluginHookFailures.WithLabelValues(name, hook).Inc()
+		}
+	}()
+
+	return fn()
+}
@@ -0,0 +1,77 @@
+package pubsub
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// subscriberBuffer is how many messages a subscriber can fall behind before messages to it
+// are dropped.
+const subscriberBuffer = 64
+
+// PubSub fans messages out to the subscribers of a topic, implementations must be safe for
+// concurrent use. Delivery is best effort, subscribers that fall behind may miss messages.
+type PubSub interface {
+	Publish(ctx context.Context, topic string, payload []byte) error
+	// Subscribe returns the messages published to the topic until ctx is done, the channel is
+	// closed afterwards.
+	Subscribe(ctx context.Context, topic string) (<-chan []byte, error)
+}
+
+// memoryPubSub only reaches subscribers of the same instance, running several instances
+// requires a shared broker like Redis pub/sub.
+type memoryPubSub struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[chan []byte]struct{}
+}
+
+func NewMemoryPubSub() *memoryPubSub {
+	return &memoryPubSub{
+		subscribers: make(map[string]map[chan []byte]struct{}),
+	}
+}
+
+var _ PubSub = (*memoryPubSub)(nil)
+
+func (p *memoryPubSub) Publish(_ context.Context, topic string, payload []byte) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for subscriber := range p.subscribers[topic] {
+		select {
+		case subscriber <- payload:
+		default:
+			log.Warn().Str("topic", topic).Msg("pubsub subscriber is falling behind, message dropped")
+		}
+	}
+
+	return nil
+}
+
+func (p *memoryPubSub) Subscribe(ctx context.Context, topic string) (<-chan []byte, error) {
+	subscriber := make(chan []byte, subscriberBuffer)
+
+	p.mu.Lock()
+	if p.subscribers[topic] == nil {
+		p.subscribers[topic] = make(map[chan []byte]struct{})
+	}
+	p.subscribers[topic][subscriber] = struct{}{}
+	p.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		// closing under the write lock guarantees no publish is sending to the channel
+		p.mu.Lock()
+		delete(p.subscribers[topic], subscriber)
+		if len(p.subscribers[topic]) == 0 {
+			delete(p.subscribers, topic)
+		}
+		close(subscriber)
+		p.mu.Unlock()
+	}()
+
+	return subscriber, nil
+}
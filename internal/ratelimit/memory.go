@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryStore keeps the buckets in memory, limits are per instance. Full buckets are removed
+// by Prune.
+type memoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+}
+
+type memoryBucket struct {
+	bucket
+	// fullAt is when the bucket is refilled completely and can be forgotten.
+	fullAt time.Time
+}
+
+func NewMemoryStore() *memoryStore {
+	return &memoryStore{
+		buckets: map[string]*memoryBucket{},
+	}
+}
+
+var (
+	_ Store  = (*memoryStore)(nil)
+	_ Pruner = (*memoryStore)(nil)
+)
+
+func (s *memoryStore) Take(_ context.Context, key string, limit Limit) (*Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &memoryBucket{}
+		s.buckets[key] = b
+	}
+
+	now := time.Now()
+	result := b.take(now, limit)
+	b.fullAt = now.Add(result.Reset)
+
+	return result, nil
+}
+
+func (s *memoryStore) Prune(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for key, b := range s.buckets {
+		if !b.fullAt.After(now) {
+			delete(s.buckets, key)
+		}
+	}
+
+	return nil
+}
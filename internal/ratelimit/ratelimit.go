@@ -0,0 +1,87 @@
+// Package ratelimit limits requests with token buckets. A bucket holds up to Burst tokens and
+// refills at Burst tokens per Period, every request takes one token.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit is the size and refill period of a bucket, a zero Burst disables the limit.
+type Limit struct {
+	Burst  int
+	Period time.Duration
+}
+
+// Enabled reports whether requests are limited at all.
+func (l Limit) Enabled() bool {
+	return l.Burst > 0 && l.Period > 0
+}
+
+// Result is the state of a bucket after taking a token.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next token, only set when the request is not allowed.
+	RetryAfter time.Duration
+}
+
+// Store keeps the buckets, implementations must be safe for concurrent use.
+type Store interface {
+	// Take takes a token from the bucket of key.
+	Take(ctx context.Context, key string, limit Limit) (*Result, error)
+}
+
+// Pruner is implemented by stores that don't expire buckets on their own, Prune removes full
+// buckets.
+type Pruner interface {
+	Prune(ctx context.Context) error
+}
+
+// bucket is a token bucket, tokens are refilled lazily when a token is taken.
+type bucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+// take refills the bucket up to now and takes a token when there is one.
+func (b *bucket) take(now time.Time, limit Limit) *Result {
+	rate := float64(limit.Burst) / limit.Period.Seconds()
+	if b.UpdatedAt.IsZero() {
+		b.Tokens = float64(limit.Burst)
+	} else if elapsed := now.Sub(b.UpdatedAt).Seconds(); elapsed > 0 {
+		b.Tokens = math.Min(float64(limit.Burst), b.Tokens+elapsed*rate)
+	}
+	b.UpdatedAt = now
+
+	allowed := b.Tokens >= 1
+	if allowed {
+		b.Tokens--
+	}
+
+	return newResult(b.Tokens, allowed, limit)
+}
+
+// newResult describes a bucket left with tokens after a request took a token, or was refused one.
+func newResult(tokens float64, allowed bool, limit Limit) *Result {
+	rate := float64(limit.Burst) / limit.Period.Seconds()
+	result := &Result{
+		Allowed:   allowed,
+		Limit:     limit.Burst,
+		Remaining: int(tokens),
+		Reset:     seconds((float64(limit.Burst) - tokens) / rate),
+	}
+	if !allowed {
+		result.RetryAfter = seconds((1 - tokens) / rate)
+	}
+
+	return result
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// takeScript refills and takes from the bucket in one step, Redis runs scripts atomically so
+// concurrent requests of the same key never overshoot the limit. The time of the Redis server is
+// used, instances with skewed clocks would refill the bucket unevenly. Buckets expire once they
+// are full, at least a second later since they may be a fraction of a token short of full.
+var takeScript = redis.NewScript(`
+local burst = tonumber(ARGV[1])
+local rate = burst / tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) + tonumber(time[2]) / 1000000
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'updated_at')
+local tokens = tonumber(state[1])
+local updated = tonumber(state[2])
+if tokens == nil or updated == nil then
+	tokens = burst
+elseif now > updated then
+	tokens = math.min(burst, tokens + (now - updated) * rate)
+end
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated_at', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil((burst - tokens) / rate * 1000) + 1000)
+
+return {allowed, tostring(tokens)}
+`)
+
+// redisStore shares the buckets between instances.
+type redisStore struct {
+	client redis.Scripter
+}
+
+func NewRedisStore(client redis.Scripter) *redisStore {
+	return &redisStore{
+		client: client,
+	}
+}
+
+var _ Store = (*redisStore)(nil)
+
+func (s *redisStore) Take(ctx context.Context, key string, limit Limit) (*Result, error) {
+	reply, err := takeScript.Run(ctx, s.client, []string{redisKey(key)}, limit.Burst, limit.Period.Seconds()).Slice()
+	if err != nil {
+		return nil, err
+	}
+	if len(reply) != 2 {
+		return nil, fmt.Errorf("unexpected rate limit reply %v", reply)
+	}
+
+	allowed, _ := reply[0].(int64)
+	value, _ := reply[1].(string)
+	tokens, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected rate limit tokens %q: %w", value, err)
+	}
+
+	return newResult(tokens, allowed == 1, limit), nil
+}
+
+func redisKey(key string) string {
+	return fmt.Sprintf("ratelimit_%v", key)
+}
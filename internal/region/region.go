@@ -0,0 +1,198 @@
+// Package region pins the data of users to the database of their data region, e.g. to keep the
+// data of EU users in the EU. The region is carried by the access token and the context of a
+// request, the Router sends the queries of the request to the database of that region.
+//
+// Bearer tokens of integrations, like agent and calendar tokens, are tagged with the region they
+// were issued in, see TagToken, so their lookup can be routed too. Display boards only reach the
+// home region.
+package region
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+// Home is the region of the default database, users that didn't pick a region live there.
+const Home = ""
+
+type contextKey struct{}
+
+// WithRegion returns a context whose queries go to the database of region.
+func WithRegion(ctx context.Context, region string) context.Context {
+	return context.WithValue(ctx, contextKey{}, region)
+}
+
+// FromContext returns the region of the context, Home when none is set.
+func FromContext(ctx context.Context) string {
+	region, _ := ctx.Value(contextKey{}).(string)
+	return region
+}
+
+// Scoped prefixes key with the region of the context. IDs are only unique within a region, keys
+// shared by all regions of an instance, like pub/sub topics, must be scoped.
+func Scoped(ctx context.Context, key string) string {
+	if region := FromContext(ctx); region != Home {
+		return region + ":" + key
+	}
+
+	return key
+}
+
+// tokenSeparator ends the region of tagged tokens, it is not part of the base64url alphabet of
+// the generated part.
+const tokenSeparator = "."
+
+// TagToken inserts the region of ctx after the prefix of token. Tokens of the home region are
+// not tagged, which keeps the tokens issued before regions were tagged valid.
+func TagToken(ctx context.Context, prefix string, token string) string {
+	region := FromContext(ctx)
+	if region == Home {
+		return token
+	}
+
+	return prefix + region + tokenSeparator + strings.TrimPrefix(token, prefix)
+}
+
+// OfToken returns the region token was tagged with by TagToken, Home for untagged tokens.
+func OfToken(prefix string, token string) string {
+	tagged, ok := strings.CutPrefix(token, prefix)
+	if !ok {
+		return Home
+	}
+	region, _, ok := strings.Cut(tagged, tokenSeparator)
+	if !ok {
+		return Home
+	}
+
+	return region
+}
+
+// Router is a db.DB routing queries to the database of the region of their context. Contexts
+// of an unknown region fail rather than falling back to the home database, which could store
+// data outside of its region.
+type Router struct {
+	// DB is the home database, it also serves the methods that are not routed.
+	db.DB
+
+	regions map[string]db.DB
+}
+
+func NewRouter(home db.DB, regions map[string]db.DB) *Router {
+	return &Router{
+		DB:      home,
+		regions: regions,
+	}
+}
+
+var _ db.DB = (*Router)(nil)
+
+// Serves reports whether the router has a database for region.
+func (r *Router) Serves(region string) bool {
+	if region == Home {
+		return true
+	}
+	_, ok := r.regions[region]
+
+	return ok
+}
+
+// Regions returns the regions served by the router, the home region first.
+func (r *Router) Regions() []string {
+	regions := make([]string, 0, len(r.regions)+1)
+	for region := range r.regions {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+
+	return append([]string{Home}, regions...)
+}
+
+// Each returns fn running once per region served by the router, for background workers that
+// have no request to take the region from.
+func (r *Router) Each(fn func(ctx context.Context) error) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		var errs []error
+		for _, region := range r.Regions() {
+			if err := fn(WithRegion(ctx, region)); err != nil {
+				errs = append(errs, fmt.Errorf("region %q: %w", region, err))
+			}
+		}
+
+		return errors.Join(errs...)
+	}
+}
+
+func (r *Router) resolve(ctx context.Context) (db.DB, error) {
+	region := FromContext(ctx)
+	if region == Home {
+		return r.DB, nil
+	}
+
+	regionDB, ok := r.regions[region]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", domain.ErrUnknownRegion, region)
+	}
+
+	return regionDB, nil
+}
+
+func (r *Router) Get(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	regionDB, err := r.resolve(ctx)
+	if err != nil {
+		return err
+	}
+
+	return regionDB.Get(ctx, dest, query, args...)
+}
+
+func (r *Router) Get_RO(ctx context.Context, dest interface{}, query string, args ...interface{}) error { //nolint:revive,stylecheck // named after db.DB
+	regionDB, err := r.resolve(ctx)
+	if err != nil {
+		return err
+	}
+
+	return regionDB.Get_RO(ctx, dest, query, args...)
+}
+
+func (r *Router) Select(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	regionDB, err := r.resolve(ctx)
+	if err != nil {
+		return err
+	}
+
+	return regionDB.Select(ctx, dest, query, args...)
+}
+
+func (r *Router) Select_RO(ctx context.Context, dest interface{}, query string, args ...interface{}) error { //nolint:revive,stylecheck // named after db.DB
+	regionDB, err := r.resolve(ctx)
+	if err != nil {
+		return err
+	}
+
+	return regionDB.Select_RO(ctx, dest, query, args...)
+}
+
+func (r *Router) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	regionDB, err := r.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return regionDB.Exec(ctx, query, args...)
+}
+
+func (r *Router) Transaction(ctx context.Context, fn func(ctx context.Context, tx db.Tx) error) error {
+	regionDB, err := r.resolve(ctx)
+	if err != nil {
+		return err
+	}
+
+	return regionDB.Transaction(ctx, fn)
+}
@@ -0,0 +1,54 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+type AdminRepo interface {
+	Scopes(ctx context.Context, userID uint) ([]domain.AdminScope, error)
+	// SetScopes replaces the scopes of the user, no scopes revoke the admin access.
+	SetScopes(ctx context.Context, userID uint, scopes []domain.AdminScope) error
+}
+
+type adminRepo struct {
+	DB db.DB
+}
+
+func NewAdminRepo(db db.DB) *adminRepo {
+	return &adminRepo{
+		DB: db,
+	}
+}
+
+var _ AdminRepo = (*adminRepo)(nil)
+
+func (r *adminRepo) Scopes(ctx context.Context, userID uint) ([]domain.AdminScope, error) {
+	query := `SELECT scope FROM admin_scopes WHERE user_id = $1 ORDER BY scope`
+
+	var scopes []domain.AdminScope
+	if err := r.DB.Select(ctx, &scopes, query, userID); err != nil {
+		return nil, err
+	}
+
+	return scopes, nil
+}
+
+func (r *adminRepo) SetScopes(ctx context.Context, userID uint, scopes []domain.AdminScope) error {
+	return r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM admin_scopes WHERE user_id = $1`, userID); err != nil {
+			return err
+		}
+
+		for _, scope := range scopes {
+			query := `INSERT INTO admin_scopes (user_id, scope) VALUES ($1, $2)`
+			if _, err := tx.Exec(ctx, query, userID, string(scope)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
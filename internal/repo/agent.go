@@ -0,0 +1,109 @@
+package repo
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+)
+
+type AgentTokenRepo interface {
+	Create(ctx context.Context, token *domain.AgentToken, tokenHash string) (*domain.AgentToken, error)
+	Delete(ctx context.Context, userID uint, uuid string) error
+	// Touch records when the token was last used by an assistant.
+	Touch(ctx context.Context, id uint, usedAt time.Time) error
+
+	ByHash(ctx context.Context, tokenHash string) (*domain.AgentToken, error)
+	All(ctx context.Context, userID uint) ([]*domain.AgentToken, error)
+}
+
+type agentTokenRepo struct {
+	DB db.DB
+}
+
+func NewAgentTokenRepo(db db.DB) *agentTokenRepo {
+	return &agentTokenRepo{
+		DB: db,
+	}
+}
+
+var _ AgentTokenRepo = (*agentTokenRepo)(nil)
+
+const (
+	agentTokenColumns = `id, uuid, user_id, name, scopes, token_hash, expires_at, last_used_at, created_at, updated_at,
+		deleted_at`
+)
+
+func (r *agentTokenRepo) Create(ctx context.Context, token *domain.AgentToken, tokenHash string) (*domain.AgentToken, error) {
+	query := `
+		INSERT INTO agent_tokens (uuid, user_id, name, scopes, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + agentTokenColumns
+
+	scopes := make([]string, 0, len(token.Scopes))
+	for _, scope := range token.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+
+	var tokenEntity entity.AgentToken
+	err := r.DB.Get(ctx, &tokenEntity, query,
+		token.UUID,
+		token.UserID,
+		token.Name,
+		strings.Join(scopes, ","),
+		tokenHash,
+		nullTime(token.ExpiresAt),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return tokenEntity.ToDomain(), nil
+}
+
+func (r *agentTokenRepo) Delete(ctx context.Context, userID uint, uuid string) error {
+	query := `UPDATE agent_tokens SET deleted_at = $1 WHERE uuid = $2 AND user_id = $3 AND deleted_at IS NULL`
+	_, err := r.DB.Exec(ctx, query, time.Now().UTC(), uuid, userID)
+
+	return err
+}
+
+func (r *agentTokenRepo) Touch(ctx context.Context, id uint, usedAt time.Time) error {
+	_, err := r.DB.Exec(ctx, `UPDATE agent_tokens SET last_used_at = $1 WHERE id = $2`, usedAt.UTC(), id)
+
+	return err
+}
+
+func (r *agentTokenRepo) ByHash(ctx context.Context, tokenHash string) (*domain.AgentToken, error) {
+	query := `SELECT ` + agentTokenColumns + ` FROM agent_tokens WHERE token_hash = $1 AND deleted_at IS NULL`
+
+	var tokenEntity entity.AgentToken
+	err := r.DB.Get_RO(ctx, &tokenEntity, query, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return tokenEntity.ToDomain(), nil
+}
+
+func (r *agentTokenRepo) All(ctx context.Context, userID uint) ([]*domain.AgentToken, error) {
+	query := `SELECT ` + agentTokenColumns + ` FROM agent_tokens
+		WHERE user_id = $1 AND deleted_at IS NULL
+		ORDER BY created_at DESC, id DESC`
+
+	var tokenEntities []*entity.AgentToken
+	err := r.DB.Select_RO(ctx, &tokenEntities, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]*domain.AgentToken, 0, len(tokenEntities))
+	for _, tokenEntity := range tokenEntities {
+		tokens = append(tokens, tokenEntity.ToDomain())
+	}
+
+	return tokens, nil
+}
@@ -0,0 +1,110 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+)
+
+// IPAllowlistRepo stores the IP allowlists of the workspaces, like the workspace directory it
+// always uses the home database.
+type IPAllowlistRepo interface {
+	CreateRange(ctx context.Context, uuid string, workspaceID uint, ipRange *domain.IPRangeCreate) (*domain.IPRange, error)
+	// DeleteRange returns sql.ErrNoRows when the workspace has no such range.
+	DeleteRange(ctx context.Context, workspaceID uint, uuid string) error
+	Ranges(ctx context.Context, workspaceID uint) ([]*domain.IPRange, error)
+
+	CreateBypass(ctx context.Context, workspaceID uint, tokenHash string, expiresAt time.Time) error
+	// UseBypass marks an unused, unexpired bypass token of the workspace as used and suspends the
+	// allowlist until suspendedUntil, it returns sql.ErrNoRows for any other token.
+	UseBypass(ctx context.Context, workspaceID uint, tokenHash string, suspendedUntil time.Time) error
+	// Suspended reports whether a bypass currently suspends the allowlist of the workspace.
+	Suspended(ctx context.Context, workspaceID uint) (bool, error)
+}
+
+type ipAllowlistRepo struct {
+	DB db.DB
+}
+
+func NewIPAllowlistRepo(db db.DB) *ipAllowlistRepo {
+	return &ipAllowlistRepo{
+		DB: db,
+	}
+}
+
+var _ IPAllowlistRepo = (*ipAllowlistRepo)(nil)
+
+func (r *ipAllowlistRepo) CreateRange(ctx context.Context, uuid string, workspaceID uint, ipRange *domain.IPRangeCreate) (*domain.IPRange, error) {
+	query := `
+		INSERT INTO ip_ranges (uuid, workspace_id, cidr, label)
+		VALUES ($1, $2, $3, $4)
+		RETURNING *`
+
+	var rangeEntity entity.IPRange
+	if err := r.DB.Get(ctx, &rangeEntity, query, uuid, workspaceID, ipRange.CIDR, ipRange.Label); err != nil {
+		return nil, err
+	}
+
+	return rangeEntity.ToDomain(), nil
+}
+
+func (r *ipAllowlistRepo) DeleteRange(ctx context.Context, workspaceID uint, uuid string) error {
+	query := `
+		UPDATE ip_ranges SET deleted_at = $1
+		WHERE workspace_id = $2 AND uuid = $3 AND deleted_at IS NULL
+		RETURNING id`
+
+	var id uint
+	return r.DB.Get(ctx, &id, query, time.Now().UTC(), workspaceID, uuid)
+}
+
+func (r *ipAllowlistRepo) Ranges(ctx context.Context, workspaceID uint) ([]*domain.IPRange, error) {
+	query := `SELECT * FROM ip_ranges WHERE workspace_id = $1 AND deleted_at IS NULL ORDER BY id`
+
+	var rangeEntities []*entity.IPRange
+	if err := r.DB.Select_RO(ctx, &rangeEntities, query, workspaceID); err != nil {
+		return nil, err
+	}
+
+	ranges := make([]*domain.IPRange, 0, len(rangeEntities))
+	for _, rangeEntity := range rangeEntities {
+		ranges = append(ranges, rangeEntity.ToDomain())
+	}
+
+	return ranges, nil
+}
+
+func (r *ipAllowlistRepo) CreateBypass(ctx context.Context, workspaceID uint, tokenHash string, expiresAt time.Time) error {
+	query := `INSERT INTO ip_allowlist_bypasses (workspace_id, token_hash, expires_at) VALUES ($1, $2, $3)`
+	_, err := r.DB.Exec(ctx, query, workspaceID, tokenHash, expiresAt.UTC())
+
+	return err
+}
+
+func (r *ipAllowlistRepo) UseBypass(ctx context.Context, workspaceID uint, tokenHash string, suspendedUntil time.Time) error {
+	now := time.Now().UTC()
+	query := `
+		UPDATE ip_allowlist_bypasses SET used_at = $1, suspended_until = $2
+		WHERE workspace_id = $3 AND token_hash = $4 AND used_at IS NULL AND expires_at > $1
+		RETURNING id`
+
+	var id uint
+	return r.DB.Get(ctx, &id, query, now, suspendedUntil.UTC(), workspaceID, tokenHash)
+}
+
+func (r *ipAllowlistRepo) Suspended(ctx context.Context, workspaceID uint) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM ip_allowlist_bypasses WHERE workspace_id = $1 AND suspended_until > $2
+		)`
+
+	var suspended bool
+	if err := r.DB.Get_RO(ctx, &suspended, query, workspaceID, time.Now().UTC()); err != nil {
+		return false, err
+	}
+
+	return suspended, nil
+}
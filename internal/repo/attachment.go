@@ -0,0 +1,171 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+)
+
+type AttachmentRepo interface {
+	Create(ctx context.Context, attachment *domain.Attachment) (*domain.Attachment, error)
+	Delete(ctx context.Context, id uint) error
+
+	ByUUID(ctx context.Context, todoID uint, uuid string) (*domain.Attachment, error)
+	All(ctx context.Context, todoID uint) ([]*domain.Attachment, error)
+	// StorageKeys returns the files of the attachments a user uploaded or that belong to the
+	// todos of the user.
+	StorageKeys(ctx context.Context, userID uint) ([]string, error)
+
+	// PendingExtraction returns the attachments whose text is still to be extracted, including
+	// those whose worker claimed them before staleBefore and never finished, oldest first.
+	PendingExtraction(ctx context.Context, staleBefore time.Time, limit int) ([]*domain.Attachment, error)
+	// ClaimExtraction counts an attempt and marks the attachment as extracting. It fails with
+	// sql.ErrNoRows when another worker claimed it since it was read.
+	ClaimExtraction(ctx context.Context, attachment *domain.Attachment, startedAt time.Time) error
+	// SetExtraction stores the outcome of an extraction, the text is only stored when it is done.
+	SetExtraction(ctx context.Context, id uint, status domain.ExtractionStatus, text string) error
+}
+
+type attachmentRepo struct {
+	DB db.DB
+}
+
+func NewAttachmentRepo(db db.DB) *attachmentRepo {
+	return &attachmentRepo{
+		DB: db,
+	}
+}
+
+var _ AttachmentRepo = (*attachmentRepo)(nil)
+
+// attachmentColumns leaves out the extracted text and the search vector, they are only used
+// by the search.
+const attachmentColumns = `
+	id, uuid, todo_id, user_id, filename, content_type, size, storage_key, created_at, updated_at, deleted_at,
+	extraction_status, extraction_attempts, extraction_started_at`
+
+func (r *attachmentRepo) Create(ctx context.Context, attachment *domain.Attachment) (*domain.Attachment, error) {
+	query := `
+		INSERT INTO attachments (uuid, todo_id, user_id, filename, content_type, size, storage_key)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + attachmentColumns
+
+	var attachmentEntity entity.Attachment
+	err := r.DB.Get(ctx, &attachmentEntity, query,
+		attachment.UUID,
+		attachment.TodoID,
+		attachment.UserID,
+		attachment.Filename,
+		attachment.ContentType,
+		attachment.Size,
+		attachment.StorageKey,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return attachmentEntity.ToDomain(), nil
+}
+
+func (r *attachmentRepo) Delete(ctx context.Context, id uint) error {
+	query := `UPDATE attachments SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL`
+	_, err := r.DB.Exec(ctx, query, time.Now().UTC(), id)
+
+	return err
+}
+
+func (r *attachmentRepo) ByUUID(ctx context.Context, todoID uint, uuid string) (*domain.Attachment, error) {
+	query := `SELECT ` + attachmentColumns + ` FROM attachments WHERE todo_id = $1 AND uuid = $2 AND deleted_at IS NULL`
+
+	var attachmentEntity entity.Attachment
+	err := r.DB.Get_RO(ctx, &attachmentEntity, query, todoID, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	return attachmentEntity.ToDomain(), nil
+}
+
+func (r *attachmentRepo) All(ctx context.Context, todoID uint) ([]*domain.Attachment, error) {
+	query := `SELECT ` + attachmentColumns + ` FROM attachments WHERE todo_id = $1 AND deleted_at IS NULL ORDER BY created_at, id`
+
+	var attachmentEntities []*entity.Attachment
+	err := r.DB.Select_RO(ctx, &attachmentEntities, query, todoID)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments := make([]*domain.Attachment, 0, len(attachmentEntities))
+	for _, attachmentEntity := range attachmentEntities {
+		attachments = append(attachments, attachmentEntity.ToDomain())
+	}
+
+	return attachments, nil
+}
+
+func (r *attachmentRepo) PendingExtraction(ctx context.Context, staleBefore time.Time, limit int) ([]*domain.Attachment, error) {
+	query := `
+		SELECT ` + attachmentColumns + `
+			FROM attachments
+		WHERE deleted_at IS NULL
+			AND (extraction_status = 'pending'
+				OR (extraction_status = 'extracting' AND extraction_started_at < $1))
+		ORDER BY id
+		LIMIT $2`
+
+	var attachmentEntities []*entity.Attachment
+	if err := r.DB.Select(ctx, &attachmentEntities, query, staleBefore.UTC(), limit); err != nil {
+		return nil, err
+	}
+
+	attachments := make([]*domain.Attachment, 0, len(attachmentEntities))
+	for _, attachmentEntity := range attachmentEntities {
+		attachments = append(attachments, attachmentEntity.ToDomain())
+	}
+
+	return attachments, nil
+}
+
+func (r *attachmentRepo) ClaimExtraction(ctx context.Context, attachment *domain.Attachment, startedAt time.Time) error {
+	query := `
+		UPDATE attachments
+			SET extraction_status = 'extracting', extraction_started_at = $1, extraction_attempts = extraction_attempts + 1
+		WHERE id = $2
+			AND extraction_status = $3
+			AND extraction_started_at IS NOT DISTINCT FROM $4
+		RETURNING id`
+
+	var id uint
+	return r.DB.Get(ctx, &id, query,
+		startedAt.UTC(), attachment.ID, string(attachment.ExtractionStatus), nullTime(attachment.ExtractionStartedAt),
+	)
+}
+
+func (r *attachmentRepo) SetExtraction(ctx context.Context, id uint, status domain.ExtractionStatus, text string) error {
+	query := `UPDATE attachments SET extraction_status = $1, content_text = $2, extraction_started_at = NULL WHERE id = $3`
+
+	var content interface{}
+	if status == domain.ExtractionDone {
+		content = text
+	}
+	_, err := r.DB.Exec(ctx, query, string(status), content, id)
+
+	return err
+}
+
+func (r *attachmentRepo) StorageKeys(ctx context.Context, userID uint) ([]string, error) {
+	query := `
+		SELECT attachments.storage_key FROM attachments
+		JOIN todos ON todos.id = attachments.todo_id
+		WHERE attachments.user_id = $1 OR todos.user_id = $1`
+
+	var keys []string
+	if err := r.DB.Select(ctx, &keys, query, userID); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
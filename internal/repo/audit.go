@@ -0,0 +1,147 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/audit"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+	"github.com/meowmix1337/the_recipe_book/internal/pagination"
+)
+
+// AuditRepo is append-only, entries can not be changed or deleted once written. The only
+// exception is clearing the personal fields of the entries of purged accounts.
+type AuditRepo interface {
+	// Create chains the entry onto the last entry of the log and sets its hashes.
+	Create(ctx context.Context, entry *domain.AuditEntry) error
+
+	// Chain returns the entries of all users after afterID, oldest first.
+	Chain(ctx context.Context, afterID uint, limit int) ([]*domain.AuditEntry, error)
+
+	// All returns the entries of a user, newest first.
+	All(ctx context.Context, userID uint, page *pagination.Page) ([]*domain.AuditEntry, *pagination.Cursor, error)
+
+	// Anonymize clears the user, IP and user agent of the entries of a user, the entries and
+	// their hashes stay so the chain still links.
+	Anonymize(ctx context.Context, userID uint, at time.Time) error
+}
+
+type auditRepo struct {
+	DB db.DB
+}
+
+func NewAuditRepo(db db.DB) *auditRepo {
+	return &auditRepo{
+		DB: db,
+	}
+}
+
+var _ AuditRepo = (*auditRepo)(nil)
+
+// auditChainLock is the advisory lock serializing appends to the audit log, concurrent appends
+// would chain onto the same entry.
+const auditChainLock = 0x61756469
+
+func (r *auditRepo) Create(ctx context.Context, entry *domain.AuditEntry) error {
+	return r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, auditChainLock); err != nil {
+			return err
+		}
+
+		var prevHash string
+		err := tx.Get(ctx, &prevHash, `SELECT hash FROM audit_logs ORDER BY id DESC LIMIT 1`)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		// postgres stores microseconds, the hash must match what is read back
+		entry.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+		entry.PrevHash = prevHash
+		entry.Hash = audit.Hash(entry)
+
+		query := `
+			INSERT INTO audit_logs (user_id, action, path, status, ip, user_agent, request_id, created_at, prev_hash, hash)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+		_, err = tx.Exec(ctx, query,
+			nullID(entry.UserID),
+			entry.Action,
+			entry.Path,
+			entry.Status,
+			entry.IP,
+			entry.UserAgent,
+			entry.RequestID,
+			entry.CreatedAt,
+			entry.PrevHash,
+			entry.Hash,
+		)
+
+		return err
+	})
+}
+
+func (r *auditRepo) Chain(ctx context.Context, afterID uint, limit int) ([]*domain.AuditEntry, error) {
+	query := `SELECT * FROM audit_logs WHERE id > $1 ORDER BY id LIMIT $2`
+
+	var entryEntities []*entity.AuditEntry
+	if err := r.DB.Select_RO(ctx, &entryEntities, query, afterID, limit); err != nil {
+		return nil, err
+	}
+
+	entries := make([]*domain.AuditEntry, 0, len(entryEntities))
+	for _, entryEntity := range entryEntities {
+		entries = append(entries, entryEntity.ToDomain())
+	}
+
+	return entries, nil
+}
+
+func (r *auditRepo) All(ctx context.Context, userID uint, page *pagination.Page) ([]*domain.AuditEntry, *pagination.Cursor, error) {
+	query := `SELECT * FROM audit_logs WHERE user_id = $1`
+	args := []interface{}{userID}
+
+	cursor, err := page.After("", 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cursor != nil {
+		args = append(args, cursor.ID)
+		query += fmt.Sprintf(` AND id < $%d`, len(args))
+	}
+
+	query += ` ORDER BY id DESC`
+	if page != nil {
+		args = append(args, page.FetchLimit())
+		query += fmt.Sprintf(` LIMIT $%d`, len(args))
+	}
+
+	var entryEntities []*entity.AuditEntry
+	err = r.DB.Select_RO(ctx, &entryEntities, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entries := make([]*domain.AuditEntry, 0, len(entryEntities))
+	for _, entryEntity := range entryEntities {
+		entries = append(entries, entryEntity.ToDomain())
+	}
+
+	entries, next := pagination.Trim(entries, page, func(entry *domain.AuditEntry) *pagination.Cursor {
+		return &pagination.Cursor{ID: entry.ID}
+	})
+
+	return entries, next, nil
+}
+
+func (r *auditRepo) Anonymize(ctx context.Context, userID uint, at time.Time) error {
+	query := `
+		UPDATE audit_logs SET user_id = NULL, ip = '', user_agent = '', anonymized_at = $1
+		WHERE user_id = $2 AND anonymized_at IS NULL`
+	_, err := r.DB.Exec(ctx, query, at.UTC(), userID)
+
+	return err
+}
@@ -0,0 +1,254 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+	"github.com/meowmix1337/the_recipe_book/internal/pagination"
+)
+
+type AutomationRepo interface {
+	Create(ctx context.Context, automation *domain.Automation) (*domain.Automation, error)
+	Delete(ctx context.Context, userID uint, uuid string) error
+	ByUUID(ctx context.Context, userID uint, uuid string) (*domain.Automation, error)
+	// All returns the automations of the user without their modules.
+	All(ctx context.Context, userID uint) ([]*domain.Automation, error)
+
+	CreateRuns(ctx context.Context, runs []*domain.AutomationRun) error
+	// Due returns the pending runs of automations that still exist with their modules, oldest first.
+	Due(ctx context.Context, limit int) ([]*domain.AutomationRun, error)
+	// Claim marks a pending run as failed with reason until UpdateRun stores its outcome, so a
+	// run is never started twice. It fails with sql.ErrNoRows when another instance already
+	// claimed the run.
+	Claim(ctx context.Context, run *domain.AutomationRun, reason string) error
+	// UpdateRun stores the outcome of a run.
+	UpdateRun(ctx context.Context, run *domain.AutomationRun) error
+	// Runs returns the runs of an automation, newest first.
+	Runs(ctx context.Context, automationID uint, page *pagination.Page) ([]*domain.AutomationRun, *pagination.Cursor, error)
+}
+
+type automationRepo struct {
+	DB db.DB
+}
+
+func NewAutomationRepo(db db.DB) *automationRepo {
+	return &automationRepo{
+		DB: db,
+	}
+}
+
+var _ AutomationRepo = (*automationRepo)(nil)
+
+const (
+	// automationColumns leaves out the module, it is only selected to run it.
+	automationColumns = `id, uuid, user_id, name, events, created_at, updated_at, deleted_at`
+	// automationRunColumns selects a run joined with its automation.
+	automationRunColumns = `automation_runs.id, automation_runs.uuid, automation_runs.automation_id,
+		automations.uuid AS automation_uuid, automations.user_id AS automation_user_id,
+		automations.name AS automation_name, automation_runs.event, automation_runs.payload,
+		automation_runs.status, automation_runs.fuel_used, automation_runs.log, automation_runs.error,
+		automation_runs.finished_at, automation_runs.created_at, automation_runs.updated_at`
+)
+
+func (r *automationRepo) Create(ctx context.Context, automation *domain.Automation) (*domain.Automation, error) {
+	query := `
+		INSERT INTO automations (uuid, user_id, name, events, module)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + automationColumns
+
+	events := make([]string, 0, len(automation.Events))
+	for _, event := range automation.Events {
+		events = append(events, string(event))
+	}
+
+	var automationEntity entity.Automation
+	err := r.DB.Get(ctx, &automationEntity, query,
+		automation.UUID,
+		automation.UserID,
+		automation.Name,
+		strings.Join(events, ","),
+		automation.Module,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return automationEntity.ToDomain(), nil
+}
+
+func (r *automationRepo) Delete(ctx context.Context, userID uint, uuid string) error {
+	query := `UPDATE automations SET deleted_at = $1 WHERE uuid = $2 AND user_id = $3 AND deleted_at IS NULL`
+	_, err := r.DB.Exec(ctx, query, time.Now().UTC(), uuid, userID)
+
+	return err
+}
+
+func (r *automationRepo) ByUUID(ctx context.Context, userID uint, uuid string) (*domain.Automation, error) {
+	query := `SELECT ` + automationColumns + ` FROM automations WHERE uuid = $1 AND user_id = $2 AND deleted_at IS NULL`
+
+	var automationEntity entity.Automation
+	err := r.DB.Get_RO(ctx, &automationEntity, query, uuid, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return automationEntity.ToDomain(), nil
+}
+
+func (r *automationRepo) All(ctx context.Context, userID uint) ([]*domain.Automation, error) {
+	query := `
+		SELECT ` + automationColumns + `
+			FROM automations
+		WHERE user_id = $1
+			AND deleted_at IS NULL
+		ORDER BY created_at`
+
+	var automationEntities []*entity.Automation
+	err := r.DB.Select_RO(ctx, &automationEntities, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	automations := make([]*domain.Automation, 0, len(automationEntities))
+	for _, automationEntity := range automationEntities {
+		automations = append(automations, automationEntity.ToDomain())
+	}
+
+	return automations, nil
+}
+
+func (r *automationRepo) CreateRuns(ctx context.Context, runs []*domain.AutomationRun) error {
+	if len(runs) == 0 {
+		return nil
+	}
+
+	return r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		query := `
+			INSERT INTO automation_runs (uuid, automation_id, event, payload)
+			VALUES ($1, $2, $3, $4)`
+		for _, run := range runs {
+			_, err := tx.Exec(ctx, query,
+				run.UUID,
+				run.Automation.ID,
+				string(run.Event),
+				string(run.Payload),
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *automationRepo) Due(ctx context.Context, limit int) ([]*domain.AutomationRun, error) {
+	query := `
+		SELECT ` + automationRunColumns + `, automations.module AS automation_module
+			FROM automation_runs
+		JOIN automations
+			ON automations.id = automation_runs.automation_id
+		WHERE automation_runs.status = 'pending'
+			AND automations.deleted_at IS NULL
+		ORDER BY automation_runs.created_at
+		LIMIT $1`
+
+	return r.selectRuns(ctx, query, limit)
+}
+
+func (r *automationRepo) Claim(ctx context.Context, run *domain.AutomationRun, reason string) error {
+	query := `
+		UPDATE automation_runs SET status = 'failed', error = $1
+		WHERE id = $2
+			AND status = 'pending'
+		RETURNING id`
+
+	var id uint
+	return r.DB.Get(ctx, &id, query, reason, run.ID)
+}
+
+func (r *automationRepo) UpdateRun(ctx context.Context, run *domain.AutomationRun) error {
+	query := `
+		UPDATE automation_runs
+			SET status = $1, fuel_used = $2, log = $3, error = $4, finished_at = $5
+		WHERE id = $6`
+
+	var runLog, runError interface{}
+	if run.Log != "" {
+		runLog = run.Log
+	}
+	if run.Error != "" {
+		runError = run.Error
+	}
+
+	_, err := r.DB.Exec(ctx, query,
+		string(run.Status),
+		int64(run.FuelUsed),
+		runLog,
+		runError,
+		nullTime(run.FinishedAt),
+		run.ID,
+	)
+
+	return err
+}
+
+func (r *automationRepo) Runs(
+	ctx context.Context,
+	automationID uint,
+	page *pagination.Page,
+) ([]*domain.AutomationRun, *pagination.Cursor, error) {
+	query := `
+		SELECT ` + automationRunColumns + `
+			FROM automation_runs
+		JOIN automations
+			ON automations.id = automation_runs.automation_id
+		WHERE automation_runs.automation_id = $1`
+	args := []interface{}{automationID}
+
+	cursor, err := page.After("", 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cursor != nil {
+		args = append(args, cursor.ID)
+		query += fmt.Sprintf(` AND automation_runs.id < $%d`, len(args))
+	}
+
+	query += ` ORDER BY automation_runs.id DESC`
+	if page != nil {
+		args = append(args, page.FetchLimit())
+		query += fmt.Sprintf(` LIMIT $%d`, len(args))
+	}
+
+	runs, err := r.selectRuns(ctx, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	runs, next := pagination.Trim(runs, page, func(run *domain.AutomationRun) *pagination.Cursor {
+		return &pagination.Cursor{ID: run.ID}
+	})
+
+	return runs, next, nil
+}
+
+func (r *automationRepo) selectRuns(ctx context.Context, query string, args ...interface{}) ([]*domain.AutomationRun, error) {
+	var runEntities []*entity.AutomationRun
+	err := r.DB.Select(ctx, &runEntities, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := make([]*domain.AutomationRun, 0, len(runEntities))
+	for _, runEntity := range runEntities {
+		runs = append(runs, runEntity.ToDomain())
+	}
+
+	return runs, nil
+}
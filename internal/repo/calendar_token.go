@@ -0,0 +1,94 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+)
+
+type CalendarTokenRepo interface {
+	Create(ctx context.Context, token *domain.CalendarToken, tokenHash string) (*domain.CalendarToken, error)
+	// Delete returns sql.ErrNoRows when the user has no such token.
+	Delete(ctx context.Context, userID uint, uuid string) error
+	// Touch records when the token was last used by a calendar app.
+	Touch(ctx context.Context, id uint, usedAt time.Time) error
+
+	ByHash(ctx context.Context, tokenHash string) (*domain.CalendarToken, error)
+	All(ctx context.Context, userID uint) ([]*domain.CalendarToken, error)
+}
+
+type calendarTokenRepo struct {
+	DB db.DB
+}
+
+func NewCalendarTokenRepo(db db.DB) *calendarTokenRepo {
+	return &calendarTokenRepo{
+		DB: db,
+	}
+}
+
+var _ CalendarTokenRepo = (*calendarTokenRepo)(nil)
+
+const calendarTokenColumns = `id, uuid, user_id, name, token_hash, last_used_at, created_at, updated_at, deleted_at`
+
+func (r *calendarTokenRepo) Create(ctx context.Context, token *domain.CalendarToken, tokenHash string) (*domain.CalendarToken, error) {
+	query := `
+		INSERT INTO calendar_tokens (uuid, user_id, name, token_hash)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + calendarTokenColumns
+
+	var tokenEntity entity.CalendarToken
+	if err := r.DB.Get(ctx, &tokenEntity, query, token.UUID, token.UserID, token.Name, tokenHash); err != nil {
+		return nil, err
+	}
+
+	return tokenEntity.ToDomain(), nil
+}
+
+func (r *calendarTokenRepo) Delete(ctx context.Context, userID uint, uuid string) error {
+	query := `
+		UPDATE calendar_tokens SET deleted_at = $1
+		WHERE uuid = $2 AND user_id = $3 AND deleted_at IS NULL
+		RETURNING id`
+
+	var id uint
+	return r.DB.Get(ctx, &id, query, time.Now().UTC(), uuid, userID)
+}
+
+func (r *calendarTokenRepo) Touch(ctx context.Context, id uint, usedAt time.Time) error {
+	_, err := r.DB.Exec(ctx, `UPDATE calendar_tokens SET last_used_at = $1 WHERE id = $2`, usedAt.UTC(), id)
+
+	return err
+}
+
+func (r *calendarTokenRepo) ByHash(ctx context.Context, tokenHash string) (*domain.CalendarToken, error) {
+	query := `SELECT ` + calendarTokenColumns + ` FROM calendar_tokens WHERE token_hash = $1 AND deleted_at IS NULL`
+
+	var tokenEntity entity.CalendarToken
+	if err := r.DB.Get_RO(ctx, &tokenEntity, query, tokenHash); err != nil {
+		return nil, err
+	}
+
+	return tokenEntity.ToDomain(), nil
+}
+
+func (r *calendarTokenRepo) All(ctx context.Context, userID uint) ([]*domain.CalendarToken, error) {
+	query := `SELECT ` + calendarTokenColumns + ` FROM calendar_tokens
+		WHERE user_id = $1 AND deleted_at IS NULL
+		ORDER BY created_at DESC, id DESC`
+
+	var tokenEntities []*entity.CalendarToken
+	if err := r.DB.Select_RO(ctx, &tokenEntities, query, userID); err != nil {
+		return nil, err
+	}
+
+	tokens := make([]*domain.CalendarToken, 0, len(tokenEntities))
+	for _, tokenEntity := range tokenEntities {
+		tokens = append(tokens, tokenEntity.ToDomain())
+	}
+
+	return tokens, nil
+}
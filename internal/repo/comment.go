@@ -0,0 +1,187 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+	"github.com/meowmix1337/the_recipe_book/internal/pagination"
+)
+
+type CommentRepo interface {
+	Create(ctx context.Context, uuid string, todoID uint, authorID uint, body string) (*domain.Comment, error)
+	Update(ctx context.Context, comment *domain.Comment) (*domain.Comment, error)
+	Delete(ctx context.Context, id uint) error
+
+	ByUUID(ctx context.Context, todoID uint, uuid string) (*domain.Comment, error)
+	// All returns a page of the comments of a todo, oldest first.
+	All(ctx context.Context, todoID uint, page *pagination.Page) ([]*domain.Comment, *pagination.Cursor, error)
+	// ByAuthor returns the comments the user posted on any todo with the UUIDs of their todos,
+	// oldest first.
+	ByAuthor(ctx context.Context, authorID uint) ([]*domain.Comment, error)
+}
+
+type commentRepo struct {
+	DB db.DB
+}
+
+func NewCommentRepo(db db.DB) *commentRepo {
+	return &commentRepo{
+		DB: db,
+	}
+}
+
+var _ CommentRepo = (*commentRepo)(nil)
+
+const (
+	// commentColumns selects a comment joined with its author.
+	commentColumns = `comments.id, comments.uuid, comments.todo_id, comments.user_id, comments.body,
+		comments.created_at, comments.updated_at, comments.deleted_at, users.uuid AS author_uuid,
+		COALESCE(NULLIF(TRIM(CONCAT_WS(' ', users.first_name, users.last_name)), ''), users.username, users.email, '') AS author_name`
+
+	commentSort = "created_at"
+)
+
+func (r *commentRepo) Create(ctx context.Context, uuid string, todoID uint, authorID uint, body string) (*domain.Comment, error) {
+	query := `
+		WITH inserted AS (
+			INSERT INTO comments (uuid, todo_id, user_id, body)
+			VALUES ($1, $2, $3, $4)
+			RETURNING *
+		)
+		SELECT ` + commentColumns + `
+			FROM inserted AS comments
+		JOIN users
+			ON users.id = comments.user_id`
+
+	var commentEntity entity.Comment
+	err := r.DB.Get(ctx, &commentEntity, query, uuid, todoID, authorID, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return commentEntity.ToDomain(), nil
+}
+
+func (r *commentRepo) Update(ctx context.Context, comment *domain.Comment) (*domain.Comment, error) {
+	query := `
+		WITH updated AS (
+			UPDATE comments SET body = $1
+			WHERE id = $2
+				AND deleted_at IS NULL
+			RETURNING *
+		)
+		SELECT ` + commentColumns + `
+			FROM updated AS comments
+		JOIN users
+			ON users.id = comments.user_id`
+
+	var commentEntity entity.Comment
+	err := r.DB.Get(ctx, &commentEntity, query, comment.Body, comment.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return commentEntity.ToDomain(), nil
+}
+
+func (r *commentRepo) Delete(ctx context.Context, id uint) error {
+	query := `UPDATE comments SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL`
+	_, err := r.DB.Exec(ctx, query, time.Now().UTC(), id)
+
+	return err
+}
+
+func (r *commentRepo) ByUUID(ctx context.Context, todoID uint, uuid string) (*domain.Comment, error) {
+	query := `
+		SELECT ` + commentColumns + `
+			FROM comments
+		JOIN users
+			ON users.id = comments.user_id
+		WHERE comments.todo_id = $1
+			AND comments.uuid = $2
+			AND comments.deleted_at IS NULL`
+
+	var commentEntity entity.Comment
+	err := r.DB.Get_RO(ctx, &commentEntity, query, todoID, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	return commentEntity.ToDomain(), nil
+}
+
+func (r *commentRepo) All(ctx context.Context, todoID uint, page *pagination.Page) ([]*domain.Comment, *pagination.Cursor, error) {
+	query := `
+		SELECT ` + commentColumns + `
+			FROM comments
+		JOIN users
+			ON users.id = comments.user_id
+		WHERE comments.todo_id = $1
+			AND comments.deleted_at IS NULL`
+	args := []interface{}{todoID}
+
+	cursor, err := page.After(commentSort, 1)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cursor != nil {
+		args = append(args, cursor.Values[0], cursor.ID)
+		query += ` AND (comments.created_at, comments.id) > ($2, $3)`
+	}
+
+	query += ` ORDER BY comments.created_at, comments.id`
+	if page != nil {
+		args = append(args, page.FetchLimit())
+		query += fmt.Sprintf(` LIMIT $%d`, len(args))
+	}
+
+	var commentEntities []*entity.Comment
+	err = r.DB.Select_RO(ctx, &commentEntities, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	comments := make([]*domain.Comment, 0, len(commentEntities))
+	for _, commentEntity := range commentEntities {
+		comments = append(comments, commentEntity.ToDomain())
+	}
+
+	comments, next := pagination.Trim(comments, page, func(comment *domain.Comment) *pagination.Cursor {
+		return &pagination.Cursor{
+			Values: []string{comment.CreatedAt.UTC().Format(time.RFC3339Nano)},
+			ID:     comment.ID,
+			Sort:   commentSort,
+		}
+	})
+
+	return comments, next, nil
+}
+
+func (r *commentRepo) ByAuthor(ctx context.Context, authorID uint) ([]*domain.Comment, error) {
+	query := `
+		SELECT ` + commentColumns + `, todos.uuid AS todo_uuid
+			FROM comments
+		JOIN users
+			ON users.id = comments.user_id
+		JOIN todos
+			ON todos.id = comments.todo_id
+		WHERE comments.user_id = $1
+			AND comments.deleted_at IS NULL
+		ORDER BY comments.created_at, comments.id`
+
+	var commentEntities []*entity.Comment
+	if err := r.DB.Select_RO(ctx, &commentEntities, query, authorID); err != nil {
+		return nil, err
+	}
+
+	comments := make([]*domain.Comment, 0, len(commentEntities))
+	for _, commentEntity := range commentEntities {
+		comments = append(comments, commentEntity.ToDomain())
+	}
+
+	return comments, nil
+}
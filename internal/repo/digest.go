@@ -0,0 +1,148 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+)
+
+type DigestRepo interface {
+	// Settings fails with sql.ErrNoRows when the user never saved settings.
+	Settings(ctx context.Context, userID uint) (*domain.DigestSettings, error)
+	Upsert(ctx context.Context, settings *domain.DigestSettings) (*domain.DigestSettings, error)
+	// Due claims up to limit enabled settings whose digest is due by moving their next send to
+	// retryAt, and returns them.
+	Due(ctx context.Context, now time.Time, retryAt time.Time, limit int) ([]*domain.DigestSettings, error)
+	// Sent records that the digest of the user was sent at sentAt and schedules the next one.
+	Sent(ctx context.Context, userID uint, sentAt time.Time, next time.Time) error
+
+	// Digest collects the week of the user until to, each section has at most limit todos.
+	Digest(ctx context.Context, userID uint, from time.Time, to time.Time, limit int) (*domain.WeeklyDigest, error)
+}
+
+type digestRepo struct {
+	DB db.DB
+}
+
+func NewDigestRepo(db db.DB) *digestRepo {
+	return &digestRepo{
+		DB: db,
+	}
+}
+
+var _ DigestRepo = (*digestRepo)(nil)
+
+const digestSettingsColumns = `user_id, enabled, weekday, hour, next_send_at, last_sent_at, created_at, updated_at`
+
+func (r *digestRepo) Settings(ctx context.Context, userID uint) (*domain.DigestSettings, error) {
+	query := `SELECT ` + digestSettingsColumns + ` FROM digest_settings WHERE user_id = $1`
+
+	var settingsEntity entity.DigestSettings
+	err := r.DB.Get_RO(ctx, &settingsEntity, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return settingsEntity.ToDomain(), nil
+}
+
+func (r *digestRepo) Upsert(ctx context.Context, settings *domain.DigestSettings) (*domain.DigestSettings, error) {
+	query := `
+		INSERT INTO digest_settings (user_id, enabled, weekday, hour, next_send_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id) DO UPDATE
+			SET enabled = EXCLUDED.enabled, weekday = EXCLUDED.weekday, hour = EXCLUDED.hour,
+				next_send_at = EXCLUDED.next_send_at
+		RETURNING ` + digestSettingsColumns
+
+	var settingsEntity entity.DigestSettings
+	err := r.DB.Get(ctx, &settingsEntity, query,
+		settings.UserID,
+		settings.Enabled,
+		int(settings.Weekday),
+		settings.Hour,
+		settings.NextSendAt.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return settingsEntity.ToDomain(), nil
+}
+
+func (r *digestRepo) Due(ctx context.Context, now time.Time, retryAt time.Time, limit int) ([]*domain.DigestSettings, error) {
+	query := `
+		UPDATE digest_settings SET next_send_at = $1
+		WHERE user_id IN (
+			SELECT user_id
+				FROM digest_settings
+			WHERE enabled
+				AND next_send_at <= $2
+			ORDER BY next_send_at
+			LIMIT $3
+		)
+			AND next_send_at <= $2
+		RETURNING ` + digestSettingsColumns
+
+	var settingsEntities []*entity.DigestSettings
+	if err := r.DB.Select(ctx, &settingsEntities, query, retryAt.UTC(), now.UTC(), limit); err != nil {
+		return nil, err
+	}
+
+	settings := make([]*domain.DigestSettings, 0, len(settingsEntities))
+	for _, settingsEntity := range settingsEntities {
+		settings = append(settings, settingsEntity.ToDomain())
+	}
+
+	return settings, nil
+}
+
+func (r *digestRepo) Sent(ctx context.Context, userID uint, sentAt time.Time, next time.Time) error {
+	query := `UPDATE digest_settings SET last_sent_at = $1, next_send_at = $2 WHERE user_id = $3`
+
+	_, err := r.DB.Exec(ctx, query, sentAt.UTC(), next.UTC(), userID)
+	return err
+}
+
+func (r *digestRepo) Digest(ctx context.Context, userID uint, from time.Time, to time.Time, limit int) (*domain.WeeklyDigest, error) {
+	from, to = from.UTC(), to.UTC()
+	digest := &domain.WeeklyDigest{From: from, To: to}
+
+	// completed todos are listed newest first, overdue and upcoming todos by their due date
+	sections := []struct {
+		todos *[]*domain.Todo
+		where string
+		order string
+		args  []interface{}
+	}{
+		{&digest.Completed, `todos.completed_at > $2 AND todos.completed_at <= $3`, `todos.completed_at DESC`, []interface{}{from, to}},
+		{&digest.Overdue, `todos.completed_at IS NULL AND todos.due_date <= $2`, `todos.due_date`, []interface{}{to}},
+		{&digest.Upcoming, `todos.completed_at IS NULL AND todos.due_date > $2 AND todos.due_date <= $3`, `todos.due_date`, []interface{}{to, to.Add(domain.DigestPeriod)}},
+	}
+	for _, section := range sections {
+		args := append([]interface{}{userID}, section.args...)
+		args = append(args, limit)
+		query := `SELECT ` + todoSelectColumns + ` FROM todos
+			WHERE todos.user_id = $1
+				AND todos.deleted_at IS NULL
+				AND ` + section.where + `
+			ORDER BY ` + section.order + `, todos.id` + fmt.Sprintf(` LIMIT $%d`, len(args))
+
+		var todoEntities []*entity.Todo
+		if err := r.DB.Select_RO(ctx, &todoEntities, query, args...); err != nil {
+			return nil, err
+		}
+
+		todos := make([]*domain.Todo, 0, len(todoEntities))
+		for _, todoEntity := range todoEntities {
+			todos = append(todos, todoEntity.ToDomain())
+		}
+		*section.todos = todos
+	}
+
+	return digest, nil
+}
@@ -0,0 +1,165 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+)
+
+type DisplayTokenRepo interface {
+	Create(ctx context.Context, token *domain.DisplayToken, tokenHash string) (*domain.DisplayToken, error)
+	Delete(ctx context.Context, userID uint, uuid string) error
+	// Touch records when the token was last used by a display.
+	Touch(ctx context.Context, id uint, usedAt time.Time) error
+
+	ByHash(ctx context.Context, tokenHash string) (*domain.DisplayToken, error)
+	All(ctx context.Context, userID uint) ([]*domain.DisplayToken, error)
+}
+
+type displayTokenRepo struct {
+	DB db.DB
+}
+
+func NewDisplayTokenRepo(db db.DB) *displayTokenRepo {
+	return &displayTokenRepo{
+		DB: db,
+	}
+}
+
+var _ DisplayTokenRepo = (*displayTokenRepo)(nil)
+
+const (
+	displayTokenColumns = `display_tokens.id, display_tokens.uuid, display_tokens.user_id, display_tokens.name,
+		display_tokens.token_hash, display_tokens.expires_at, display_tokens.last_used_at, display_tokens.created_at,
+		display_tokens.updated_at, display_tokens.deleted_at`
+)
+
+func (r *displayTokenRepo) Create(ctx context.Context, token *domain.DisplayToken, tokenHash string) (*domain.DisplayToken, error) {
+	var tokenEntity entity.DisplayToken
+	err := r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		query := `
+			INSERT INTO display_tokens (uuid, user_id, name, token_hash, expires_at)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING ` + displayTokenColumns
+
+		err := tx.Get(ctx, &tokenEntity, query, token.UUID, token.UserID, token.Name, tokenHash, nullTime(token.ExpiresAt))
+		if err != nil {
+			return err
+		}
+
+		query = `INSERT INTO display_token_lists (display_token_id, list_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+		for _, listID := range token.ListIDs {
+			if _, err = tx.Exec(ctx, query, tokenEntity.ID, listID); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	created := tokenEntity.ToDomain()
+	if err = r.attachLists(ctx, []*domain.DisplayToken{created}); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (r *displayTokenRepo) Delete(ctx context.Context, userID uint, uuid string) error {
+	query := `UPDATE display_tokens SET deleted_at = $1 WHERE uuid = $2 AND user_id = $3 AND deleted_at IS NULL`
+	_, err := r.DB.Exec(ctx, query, time.Now().UTC(), uuid, userID)
+
+	return err
+}
+
+func (r *displayTokenRepo) Touch(ctx context.Context, id uint, usedAt time.Time) error {
+	_, err := r.DB.Exec(ctx, `UPDATE display_tokens SET last_used_at = $1 WHERE id = $2`, usedAt.UTC(), id)
+
+	return err
+}
+
+func (r *displayTokenRepo) ByHash(ctx context.Context, tokenHash string) (*domain.DisplayToken, error) {
+	query := `SELECT ` + displayTokenColumns + ` FROM display_tokens WHERE token_hash = $1 AND deleted_at IS NULL`
+
+	var tokenEntity entity.DisplayToken
+	err := r.DB.Get_RO(ctx, &tokenEntity, query, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+
+	token := tokenEntity.ToDomain()
+	if err = r.attachLists(ctx, []*domain.DisplayToken{token}); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+func (r *displayTokenRepo) All(ctx context.Context, userID uint) ([]*domain.DisplayToken, error) {
+	query := `SELECT ` + displayTokenColumns + ` FROM display_tokens
+		WHERE user_id = $1 AND deleted_at IS NULL
+		ORDER BY display_tokens.created_at DESC, display_tokens.id DESC`
+
+	var tokenEntities []*entity.DisplayToken
+	err := r.DB.Select_RO(ctx, &tokenEntities, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]*domain.DisplayToken, 0, len(tokenEntities))
+	for _, tokenEntity := range tokenEntities {
+		tokens = append(tokens, tokenEntity.ToDomain())
+	}
+
+	if err = r.attachLists(ctx, tokens); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// attachLists loads the lists of all given tokens in a single query, deleted lists are skipped.
+func (r *displayTokenRepo) attachLists(ctx context.Context, tokens []*domain.DisplayToken) error {
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	byID := make(map[uint]*domain.DisplayToken, len(tokens))
+	args := make([]interface{}, 0, len(tokens))
+	for _, token := range tokens {
+		token.ListIDs = []uint{}
+		token.ListUUIDs = []string{}
+		byID[token.ID] = token
+		args = append(args, token.ID)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT display_token_lists.display_token_id, lists.id AS list_id, lists.uuid AS list_uuid
+			FROM display_token_lists
+		JOIN lists
+			ON lists.id = display_token_lists.list_id
+		WHERE display_token_lists.display_token_id IN (%s)
+			AND lists.deleted_at IS NULL
+		ORDER BY lists.name, lists.id`, placeholders(1, len(args)))
+
+	var tokenLists []*entity.DisplayTokenList
+	if err := r.DB.Select_RO(ctx, &tokenLists, query, args...); err != nil {
+		return err
+	}
+
+	for _, tokenList := range tokenLists {
+		if token, ok := byID[tokenList.DisplayTokenID]; ok {
+			token.ListIDs = append(token.ListIDs, tokenList.ListID)
+			token.ListUUIDs = append(token.ListUUIDs, tokenList.ListUUID)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package repo
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+)
+
+type EmailRepo interface {
+	Create(ctx context.Context, email *domain.Email) error
+	// Due returns the pending emails whose next attempt is due, oldest first.
+	Due(ctx context.Context, now time.Time, limit int) ([]*domain.Email, error)
+	// Claim counts an attempt and moves the next attempt of a due email forward. It fails with
+	// sql.ErrNoRows when another instance already claimed the attempt.
+	Claim(ctx context.Context, email *domain.Email, nextAttemptAt time.Time) error
+	// Update stores the outcome of an attempt.
+	Update(ctx context.Context, email *domain.Email) error
+}
+
+type emailRepo struct {
+	DB db.DB
+}
+
+func NewEmailRepo(db db.DB) *emailRepo {
+	return &emailRepo{
+		DB: db,
+	}
+}
+
+var _ EmailRepo = (*emailRepo)(nil)
+
+const emailColumns = `id, uuid, user_id, kind, recipients, subject, text_body, html_body, status, attempts,
+	next_attempt_at, last_error, sent_at, created_at, updated_at`
+
+func (r *emailRepo) Create(ctx context.Context, email *domain.Email) error {
+	query := `
+		INSERT INTO emails (uuid, user_id, kind, recipients, subject, text_body, html_body, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	_, err := r.DB.Exec(ctx, query,
+		email.UUID,
+		nullID(email.UserID),
+		string(email.Kind),
+		strings.Join(email.To, ","),
+		email.Subject,
+		email.Text,
+		email.HTML,
+		email.NextAttemptAt.UTC(),
+	)
+
+	return err
+}
+
+func (r *emailRepo) Due(ctx context.Context, now time.Time, limit int) ([]*domain.Email, error) {
+	query := `
+		SELECT ` + emailColumns + `
+			FROM emails
+		WHERE status = 'pending'
+			AND next_attempt_at <= $1
+		ORDER BY next_attempt_at
+		LIMIT $2`
+
+	var emailEntities []*entity.Email
+	err := r.DB.Select(ctx, &emailEntities, query, now.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	emails := make([]*domain.Email, 0, len(emailEntities))
+	for _, emailEntity := range emailEntities {
+		emails = append(emails, emailEntity.ToDomain())
+	}
+
+	return emails, nil
+}
+
+func (r *emailRepo) Claim(ctx context.Context, email *domain.Email, nextAttemptAt time.Time) error {
+	query := `
+		UPDATE emails SET next_attempt_at = $1, attempts = attempts + 1
+		WHERE id = $2
+			AND next_attempt_at = $3
+			AND status = 'pending'
+		RETURNING id`
+
+	var id uint
+	return r.DB.Get(ctx, &id, query, nextAttemptAt.UTC(), email.ID, email.NextAttemptAt.UTC())
+}
+
+func (r *emailRepo) Update(ctx context.Context, email *domain.Email) error {
+	query := `
+		UPDATE emails
+			SET status = $1, next_attempt_at = $2, last_error = $3, sent_at = $4
+		WHERE id = $5`
+
+	var lastError interface{}
+	if email.LastError != "" {
+		lastError = email.LastError
+	}
+
+	_, err := r.DB.Exec(ctx, query,
+		string(email.Status),
+		email.NextAttemptAt.UTC(),
+		lastError,
+		nullTime(email.SentAt),
+		email.ID,
+	)
+
+	return err
+}
@@ -0,0 +1,100 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+)
+
+type EmailChangeRepo interface {
+	// Create replaces the pending email change of the user.
+	Create(ctx context.Context, change *domain.EmailChange, oldTokenHash string, newTokenHash string) (*domain.EmailChange, error)
+	// Confirm confirms the address of the token of a pending change that is unexpired at now, it
+	// returns sql.ErrNoRows when there is none.
+	Confirm(ctx context.Context, tokenHash string, now time.Time) (*domain.EmailChange, error)
+	// Complete marks the change as done, it returns sql.ErrNoRows when it was completed already,
+	// so the email is swapped once.
+	Complete(ctx context.Context, id uint, now time.Time) error
+	// Cancel deletes the pending change of the user, it returns sql.ErrNoRows without one.
+	Cancel(ctx context.Context, userID uint) error
+}
+
+type emailChangeRepo struct {
+	DB db.DB
+}
+
+func NewEmailChangeRepo(db db.DB) *emailChangeRepo {
+	return &emailChangeRepo{
+		DB: db,
+	}
+}
+
+var _ EmailChangeRepo = (*emailChangeRepo)(nil)
+
+const emailChangeColumns = `id, uuid, user_id, new_email, old_confirmed_at, new_confirmed_at, expires_at,
+	completed_at, created_at`
+
+func (r *emailChangeRepo) Create(
+	ctx context.Context, change *domain.EmailChange, oldTokenHash string, newTokenHash string,
+) (*domain.EmailChange, error) {
+	var changeEntity entity.EmailChange
+	err := r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		query := `DELETE FROM email_changes WHERE user_id = $1 AND completed_at IS NULL`
+		if _, err := tx.Exec(ctx, query, change.UserID); err != nil {
+			return err
+		}
+
+		query = `
+			INSERT INTO email_changes (uuid, user_id, new_email, old_token_hash, new_token_hash, expires_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING ` + emailChangeColumns
+
+		return tx.Get(ctx, &changeEntity, query,
+			change.UUID,
+			change.UserID,
+			change.NewEmail,
+			oldTokenHash,
+			newTokenHash,
+			change.ExpiresAt.UTC(),
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return changeEntity.ToDomain(), nil
+}
+
+func (r *emailChangeRepo) Confirm(ctx context.Context, tokenHash string, now time.Time) (*domain.EmailChange, error) {
+	query := `
+		UPDATE email_changes SET
+			old_confirmed_at = CASE WHEN old_token_hash = $1 THEN COALESCE(old_confirmed_at, $2) ELSE old_confirmed_at END,
+			new_confirmed_at = CASE WHEN new_token_hash = $1 THEN COALESCE(new_confirmed_at, $2) ELSE new_confirmed_at END
+		WHERE (old_token_hash = $1 OR new_token_hash = $1)
+			AND completed_at IS NULL
+			AND expires_at > $2
+		RETURNING ` + emailChangeColumns
+
+	var changeEntity entity.EmailChange
+	if err := r.DB.Get(ctx, &changeEntity, query, tokenHash, now.UTC()); err != nil {
+		return nil, err
+	}
+
+	return changeEntity.ToDomain(), nil
+}
+
+func (r *emailChangeRepo) Complete(ctx context.Context, id uint, now time.Time) error {
+	query := `UPDATE email_changes SET completed_at = $1 WHERE id = $2 AND completed_at IS NULL RETURNING id`
+
+	return r.DB.Get(ctx, &id, query, now.UTC(), id)
+}
+
+func (r *emailChangeRepo) Cancel(ctx context.Context, userID uint) error {
+	var id uint
+	query := `DELETE FROM email_changes WHERE user_id = $1 AND completed_at IS NULL RETURNING id`
+
+	return r.DB.Get(ctx, &id, query, userID)
+}
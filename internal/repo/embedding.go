@@ -0,0 +1,146 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+)
+
+type EmbeddingRepo interface {
+	// MarkDue has the todo embedded again, e.g. after it was edited or moved to another user.
+	MarkDue(ctx context.Context, todoID uint, userID uint, dueAt time.Time) error
+	Delete(ctx context.Context, todoID uint) error
+	// Backfill marks the todos due that were never embedded or were embedded by another model,
+	// at most limit of them. It returns how many were marked.
+	Backfill(ctx context.Context, model string, dueAt time.Time, limit int) (int64, error)
+
+	// Claim returns the due embeddings that failed less than maxAttempts times and postpones
+	// them to retryAt, so a failed run is retried and instances never embed the same todo at
+	// once.
+	Claim(ctx context.Context, now time.Time, retryAt time.Time, maxAttempts int, limit int) ([]*domain.TodoEmbedding, error)
+	// Save stores the vector of a claimed embedding, a nil vector keeps the stored one. A todo
+	// that changed since it was claimed stays due.
+	Save(ctx context.Context, embedding *domain.TodoEmbedding, vector []float32) error
+}
+
+type embeddingRepo struct {
+	DB db.DB
+}
+
+func NewEmbeddingRepo(db db.DB) *embeddingRepo {
+	return &embeddingRepo{
+		DB: db,
+	}
+}
+
+var _ EmbeddingRepo = (*embeddingRepo)(nil)
+
+func (r *embeddingRepo) MarkDue(ctx context.Context, todoID uint, userID uint, dueAt time.Time) error {
+	query := `
+		INSERT INTO todo_embeddings (todo_id, user_id, due_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (todo_id) DO UPDATE SET user_id = EXCLUDED.user_id, due_at = EXCLUDED.due_at, attempts = 0`
+
+	_, err := r.DB.Exec(ctx, query, todoID, userID, dueAt.UTC())
+	return err
+}
+
+func (r *embeddingRepo) Delete(ctx context.Context, todoID uint) error {
+	_, err := r.DB.Exec(ctx, `DELETE FROM todo_embeddings WHERE todo_id = $1`, todoID)
+	return err
+}
+
+func (r *embeddingRepo) Backfill(ctx context.Context, model string, dueAt time.Time, limit int) (int64, error) {
+	// embeddings that are still due or gave up are left alone, so a todo the model keeps
+	// failing on isn't marked again and again
+	query := `
+		INSERT INTO todo_embeddings (todo_id, user_id, due_at)
+		SELECT todos.id, todos.user_id, $2
+			FROM todos
+			LEFT JOIN todo_embeddings ON todo_embeddings.todo_id = todos.id
+		WHERE todos.deleted_at IS NULL
+			AND todos.ciphertext IS NULL
+			AND (todo_embeddings.todo_id IS NULL
+				OR (todo_embeddings.model <> $1 AND todo_embeddings.due_at IS NULL))
+		LIMIT $3
+		ON CONFLICT (todo_id) DO UPDATE SET due_at = EXCLUDED.due_at, attempts = 0`
+
+	result, err := r.DB.Exec(ctx, query, model, dueAt.UTC(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (r *embeddingRepo) Claim(
+	ctx context.Context, now time.Time, retryAt time.Time, maxAttempts int, limit int,
+) ([]*domain.TodoEmbedding, error) {
+	query := `
+		WITH claimed AS (
+			UPDATE todo_embeddings SET due_at = $2, attempts = attempts + 1
+			WHERE todo_id IN (
+				SELECT todo_id FROM todo_embeddings
+				WHERE due_at <= $1
+					AND attempts < $3
+				ORDER BY due_at
+				LIMIT $4
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING todo_id, user_id, model, content_hash, attempts, due_at
+		)
+		SELECT claimed.*, todos.title, todos.description
+			FROM claimed
+			JOIN todos ON todos.id = claimed.todo_id
+		WHERE todos.deleted_at IS NULL`
+
+	// the claim is compared when saving, timestamps are stored with microseconds
+	var embeddingEntities []*entity.TodoEmbedding
+	err := r.DB.Select(ctx, &embeddingEntities, query, now.UTC(), retryAt.UTC().Truncate(time.Microsecond), maxAttempts, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	embeddings := make([]*domain.TodoEmbedding, 0, len(embeddingEntities))
+	for _, embeddingEntity := range embeddingEntities {
+		embeddings = append(embeddings, embeddingEntity.ToDomain())
+	}
+
+	return embeddings, nil
+}
+
+func (r *embeddingRepo) Save(ctx context.Context, embedding *domain.TodoEmbedding, vector []float32) error {
+	query := `
+		UPDATE todo_embeddings
+			SET model = $1, content_hash = $2, embedding = COALESCE($3::vector, embedding), attempts = 0,
+				due_at = CASE WHEN due_at = $4 THEN NULL ELSE due_at END
+		WHERE todo_id = $5`
+
+	var value sql.NullString
+	if vector != nil {
+		value = sql.NullString{String: vectorLiteral(vector), Valid: true}
+	}
+	_, err := r.DB.Exec(ctx, query, embedding.Model, embedding.ContentHash, value, embedding.DueAt.UTC(), embedding.TodoID)
+	return err
+}
+
+// vectorLiteral formats a vector the way pgvector parses it, e.g. "[0.1,-0.2]".
+func vectorLiteral(vector []float32) string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, value := range vector {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(value), 'g', -1, 32))
+	}
+	b.WriteByte(']')
+
+	return b.String()
+}
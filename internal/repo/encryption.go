@@ -0,0 +1,210 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+)
+
+type EncryptionRepo interface {
+	// SavePublicKey sets or replaces the public key of the user.
+	SavePublicKey(ctx context.Context, userID uint, key string) (*domain.PublicKey, error)
+	// PublicKeys returns the public keys of the owner and the members of a list, users that
+	// didn't set one up yet have an empty key.
+	PublicKeys(ctx context.Context, listID uint) ([]*domain.PublicKey, error)
+
+	// Encrypt switches an empty list to key version 1 with the key wrapped for its owner. It fails
+	// with sql.ErrNoRows when the list is already encrypted or has todos.
+	Encrypt(ctx context.Context, listID uint, key *domain.ListKey) error
+	// Rotate moves the list from fromVersion to the next key version wrapped with keys and returns
+	// it. It fails with sql.ErrNoRows when the list isn't at fromVersion anymore.
+	Rotate(ctx context.Context, listID uint, fromVersion int, keys []*domain.ListKey) (int, error)
+	// SaveKeys adds keys to a list or replaces them.
+	SaveKeys(ctx context.Context, keys []*domain.ListKey) error
+	// Keys returns every version of the key of a list the user holds, the oldest first.
+	Keys(ctx context.Context, listID uint, userID uint) ([]*domain.ListKey, error)
+	// ByVersion returns a version of the key of a list for everyone that holds it.
+	ByVersion(ctx context.Context, listID uint, version int) ([]*domain.ListKey, error)
+
+	// SetBlindIndexes replaces the blind indexes of an encrypted todo.
+	SetBlindIndexes(ctx context.Context, todoID uint, tokens []string) error
+}
+
+type encryptionRepo struct {
+	DB db.DB
+}
+
+func NewEncryptionRepo(db db.DB) *encryptionRepo {
+	return &encryptionRepo{
+		DB: db,
+	}
+}
+
+var _ EncryptionRepo = (*encryptionRepo)(nil)
+
+const listKeyColumns = `list_keys.list_id, list_keys.user_id, users.uuid AS user_uuid, list_keys.key_version,
+	list_keys.wrapped_key, list_keys.created_at`
+
+func (r *encryptionRepo) SavePublicKey(ctx context.Context, userID uint, key string) (*domain.PublicKey, error) {
+	query := `
+		INSERT INTO user_public_keys (user_id, public_key)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET public_key = EXCLUDED.public_key
+		RETURNING user_id, (SELECT uuid FROM users WHERE id = $1) AS user_uuid, public_key, created_at, updated_at`
+
+	var keyEntity entity.PublicKey
+	if err := r.DB.Get(ctx, &keyEntity, query, userID, key); err != nil {
+		return nil, err
+	}
+
+	return keyEntity.ToDomain(), nil
+}
+
+func (r *encryptionRepo) PublicKeys(ctx context.Context, listID uint) ([]*domain.PublicKey, error) {
+	query := `
+		SELECT users.id AS user_id, users.uuid AS user_uuid, user_public_keys.public_key,
+			user_public_keys.created_at, user_public_keys.updated_at
+			FROM users
+		LEFT JOIN user_public_keys
+			ON user_public_keys.user_id = users.id
+		WHERE users.deleted_at IS NULL
+			AND (
+				users.id = (SELECT lists.user_id FROM lists WHERE lists.id = $1)
+				OR users.id IN (SELECT list_members.user_id FROM list_members WHERE list_members.list_id = $1)
+			)
+		ORDER BY users.id`
+
+	var keyEntities []*entity.PublicKey
+	if err := r.DB.Select_RO(ctx, &keyEntities, query, listID); err != nil {
+		return nil, err
+	}
+
+	keys := make([]*domain.PublicKey, 0, len(keyEntities))
+	for _, keyEntity := range keyEntities {
+		keys = append(keys, keyEntity.ToDomain())
+	}
+
+	return keys, nil
+}
+
+func (r *encryptionRepo) Encrypt(ctx context.Context, listID uint, key *domain.ListKey) error {
+	return r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		query := `
+			UPDATE lists SET key_version = 1
+			WHERE id = $1
+				AND key_version = 0
+				AND deleted_at IS NULL
+				AND NOT EXISTS (SELECT 1 FROM todos WHERE todos.list_id = lists.id AND todos.deleted_at IS NULL)
+			RETURNING key_version`
+
+		var version int
+		if err := tx.Get(ctx, &version, query, listID); err != nil {
+			return err
+		}
+
+		return insertListKeys(ctx, tx, version, []*domain.ListKey{key})
+	})
+}
+
+func (r *encryptionRepo) Rotate(ctx context.Context, listID uint, fromVersion int, keys []*domain.ListKey) (int, error) {
+	var version int
+	err := r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		query := `
+			UPDATE lists SET key_version = key_version + 1
+			WHERE id = $1
+				AND key_version = $2
+				AND deleted_at IS NULL
+			RETURNING key_version`
+
+		if err := tx.Get(ctx, &version, query, listID, fromVersion); err != nil {
+			return err
+		}
+
+		return insertListKeys(ctx, tx, version, keys)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
+
+func (r *encryptionRepo) SaveKeys(ctx context.Context, keys []*domain.ListKey) error {
+	return r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		query := `
+			INSERT INTO list_keys (list_id, user_id, key_version, wrapped_key)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (list_id, user_id, key_version) DO UPDATE SET wrapped_key = EXCLUDED.wrapped_key`
+		for _, key := range keys {
+			if _, err := tx.Exec(ctx, query, key.ListID, key.UserID, key.KeyVersion, key.WrappedKey); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *encryptionRepo) Keys(ctx context.Context, listID uint, userID uint) ([]*domain.ListKey, error) {
+	query := `
+		SELECT ` + listKeyColumns + `
+			FROM list_keys
+		JOIN users
+			ON users.id = list_keys.user_id
+		WHERE list_keys.list_id = $1
+			AND list_keys.user_id = $2
+		ORDER BY list_keys.key_version`
+
+	return r.selectKeys(ctx, query, listID, userID)
+}
+
+func (r *encryptionRepo) ByVersion(ctx context.Context, listID uint, version int) ([]*domain.ListKey, error) {
+	query := `
+		SELECT ` + listKeyColumns + `
+			FROM list_keys
+		JOIN users
+			ON users.id = list_keys.user_id
+		WHERE list_keys.list_id = $1
+			AND list_keys.key_version = $2
+		ORDER BY list_keys.user_id`
+
+	return r.selectKeys(ctx, query, listID, version)
+}
+
+func (r *encryptionRepo) SetBlindIndexes(ctx context.Context, todoID uint, tokens []string) error {
+	return r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM todo_blind_indexes WHERE todo_id = $1`, todoID); err != nil {
+			return err
+		}
+
+		return assignBlindIndexes(ctx, tx, todoID, tokens)
+	})
+}
+
+func (r *encryptionRepo) selectKeys(ctx context.Context, query string, args ...interface{}) ([]*domain.ListKey, error) {
+	var keyEntities []*entity.ListKey
+	if err := r.DB.Select_RO(ctx, &keyEntities, query, args...); err != nil {
+		return nil, err
+	}
+
+	keys := make([]*domain.ListKey, 0, len(keyEntities))
+	for _, keyEntity := range keyEntities {
+		keys = append(keys, keyEntity.ToDomain())
+	}
+
+	return keys, nil
+}
+
+// insertListKeys stores keys as version of the key of their list.
+func insertListKeys(ctx context.Context, tx db.Tx, version int, keys []*domain.ListKey) error {
+	query := `INSERT INTO list_keys (list_id, user_id, key_version, wrapped_key) VALUES ($1, $2, $3, $4)`
+	for _, key := range keys {
+		if _, err := tx.Exec(ctx, query, key.ListID, key.UserID, version, key.WrappedKey); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
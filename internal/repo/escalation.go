@@ -0,0 +1,206 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+	"github.com/meowmix1337/the_recipe_book/internal/pagination"
+)
+
+type EscalationRepo interface {
+	Create(ctx context.Context, rule *domain.EscalationRule) (*domain.EscalationRule, error)
+	// Update replaces the definition of a rule. It fails with sql.ErrNoRows when the list has
+	// no such rule.
+	Update(ctx context.Context, rule *domain.EscalationRule) (*domain.EscalationRule, error)
+	Delete(ctx context.Context, listID uint, uuid string) error
+	// ByList returns the rules of a list, the oldest first.
+	ByList(ctx context.Context, listID uint) ([]*domain.EscalationRule, error)
+
+	// Claim records up to limit escalations of open todos that became due for a rule by now and
+	// returns them. A rule is only applied when it raises the priority of the todo or notifies.
+	Claim(ctx context.Context, now time.Time, limit int) ([]*domain.Escalation, error)
+	// Log returns the escalations of a list, newest first.
+	Log(ctx context.Context, listID uint, page *pagination.Page) ([]*domain.Escalation, *pagination.Cursor, error)
+}
+
+type escalationRepo struct {
+	DB db.DB
+}
+
+func NewEscalationRepo(db db.DB) *escalationRepo {
+	return &escalationRepo{
+		DB: db,
+	}
+}
+
+var _ EscalationRepo = (*escalationRepo)(nil)
+
+const escalationRuleColumns = `id, uuid, list_id, user_id, overdue_days, priority, notify, created_at, updated_at, deleted_at`
+
+// selectEscalations selects the escalations of from, a table or CTE with the columns of
+// todo_escalations, with the UUID of their rule and the todo they escalated.
+func selectEscalations(from string) string {
+	return `
+		SELECT todo_escalations.id, todo_escalations.rule_id, escalation_rules.uuid AS rule_uuid, todo_escalations.list_id,
+			todo_escalations.todo_id, todos.uuid AS todo_uuid, todos.user_id, todos.title, todo_escalations.due_date,
+			todo_escalations.priority_from, todo_escalations.priority_to, todo_escalations.notified, todo_escalations.created_at
+		FROM ` + from + ` AS todo_escalations
+			JOIN escalation_rules ON escalation_rules.id = todo_escalations.rule_id
+			JOIN todos ON todos.id = todo_escalations.todo_id`
+}
+
+func (r *escalationRepo) Create(ctx context.Context, rule *domain.EscalationRule) (*domain.EscalationRule, error) {
+	query := `
+		INSERT INTO escalation_rules (uuid, list_id, user_id, overdue_days, priority, notify)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + escalationRuleColumns
+
+	var ruleEntity entity.EscalationRule
+	err := r.DB.Get(ctx, &ruleEntity, query,
+		rule.UUID,
+		rule.ListID,
+		rule.UserID,
+		int(rule.OverdueBy/(24*time.Hour)),
+		nullPriority(rule.Priority),
+		rule.Notify,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return ruleEntity.ToDomain(), nil
+}
+
+func (r *escalationRepo) Update(ctx context.Context, rule *domain.EscalationRule) (*domain.EscalationRule, error) {
+	query := `
+		UPDATE escalation_rules SET overdue_days = $1, priority = $2, notify = $3
+		WHERE uuid = $4
+			AND list_id = $5
+			AND deleted_at IS NULL
+		RETURNING ` + escalationRuleColumns
+
+	var ruleEntity entity.EscalationRule
+	err := r.DB.Get(ctx, &ruleEntity, query,
+		int(rule.OverdueBy/(24*time.Hour)),
+		nullPriority(rule.Priority),
+		rule.Notify,
+		rule.UUID,
+		rule.ListID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return ruleEntity.ToDomain(), nil
+}
+
+// Delete keeps the rule for the escalations it logged.
+func (r *escalationRepo) Delete(ctx context.Context, listID uint, uuid string) error {
+	query := `UPDATE escalation_rules SET deleted_at = $1 WHERE uuid = $2 AND list_id = $3 AND deleted_at IS NULL`
+	_, err := r.DB.Exec(ctx, query, time.Now().UTC(), uuid, listID)
+
+	return err
+}
+
+func (r *escalationRepo) ByList(ctx context.Context, listID uint) ([]*domain.EscalationRule, error) {
+	query := `
+		SELECT ` + escalationRuleColumns + `
+			FROM escalation_rules
+		WHERE list_id = $1
+			AND deleted_at IS NULL
+		ORDER BY id`
+
+	var ruleEntities []*entity.EscalationRule
+	if err := r.DB.Select_RO(ctx, &ruleEntities, query, listID); err != nil {
+		return nil, err
+	}
+
+	rules := make([]*domain.EscalationRule, 0, len(ruleEntities))
+	for _, ruleEntity := range ruleEntities {
+		rules = append(rules, ruleEntity.ToDomain())
+	}
+
+	return rules, nil
+}
+
+func (r *escalationRepo) Claim(ctx context.Context, now time.Time, limit int) ([]*domain.Escalation, error) {
+	query := `
+		WITH claimed AS (
+			INSERT INTO todo_escalations (rule_id, list_id, todo_id, due_date, priority_from, priority_to, notified)
+			SELECT escalation_rules.id, escalation_rules.list_id, todos.id, todos.due_date, todos.priority,
+					CASE WHEN escalation_rules.priority > todos.priority THEN escalation_rules.priority END,
+					escalation_rules.notify
+				FROM escalation_rules
+					JOIN todos ON todos.list_id = escalation_rules.list_id
+			WHERE escalation_rules.deleted_at IS NULL
+				AND todos.completed_at IS NULL
+				AND todos.deleted_at IS NULL
+				AND todos.archived_at IS NULL
+				AND todos.due_date <= $1::timestamptz - make_interval(days => escalation_rules.overdue_days)
+				AND (escalation_rules.notify OR escalation_rules.priority > todos.priority)
+				AND NOT EXISTS (
+					SELECT 1
+						FROM todo_escalations
+					WHERE todo_escalations.rule_id = escalation_rules.id
+						AND todo_escalations.todo_id = todos.id
+						AND todo_escalations.due_date = todos.due_date
+				)
+			ORDER BY todos.due_date, escalation_rules.id
+			LIMIT $2
+			ON CONFLICT (rule_id, todo_id, due_date) DO NOTHING
+			RETURNING *
+		)` + selectEscalations("claimed") + `
+		ORDER BY todo_escalations.id`
+
+	var escalationEntities []*entity.Escalation
+	if err := r.DB.Select(ctx, &escalationEntities, query, now.UTC(), limit); err != nil {
+		return nil, err
+	}
+
+	escalations := make([]*domain.Escalation, 0, len(escalationEntities))
+	for _, escalationEntity := range escalationEntities {
+		escalations = append(escalations, escalationEntity.ToDomain())
+	}
+
+	return escalations, nil
+}
+
+func (r *escalationRepo) Log(ctx context.Context, listID uint, page *pagination.Page) ([]*domain.Escalation, *pagination.Cursor, error) {
+	query := selectEscalations("todo_escalations") + ` WHERE todo_escalations.list_id = $1`
+	args := []interface{}{listID}
+
+	cursor, err := page.After("", 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cursor != nil {
+		args = append(args, cursor.ID)
+		query += fmt.Sprintf(` AND todo_escalations.id < $%d`, len(args))
+	}
+
+	query += ` ORDER BY todo_escalations.id DESC`
+	if page != nil {
+		args = append(args, page.FetchLimit())
+		query += fmt.Sprintf(` LIMIT $%d`, len(args))
+	}
+
+	var escalationEntities []*entity.Escalation
+	if err = r.DB.Select_RO(ctx, &escalationEntities, query, args...); err != nil {
+		return nil, nil, err
+	}
+
+	escalations := make([]*domain.Escalation, 0, len(escalationEntities))
+	for _, escalationEntity := range escalationEntities {
+		escalations = append(escalations, escalationEntity.ToDomain())
+	}
+
+	escalations, next := pagination.Trim(escalations, page, func(escalation *domain.Escalation) *pagination.Cursor {
+		return &pagination.Cursor{ID: escalation.ID}
+	})
+
+	return escalations, next, nil
+}
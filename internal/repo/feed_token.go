@@ -0,0 +1,115 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+)
+
+type FeedTokenRepo interface {
+	Create(ctx context.Context, token *domain.FeedToken, tokenHash string) (*domain.FeedToken, error)
+	// Delete returns sql.ErrNoRows when the user has no such token.
+	Delete(ctx context.Context, userID uint, uuid string) error
+	// Touch records when the token was last used by a feed reader.
+	Touch(ctx context.Context, id uint, usedAt time.Time) error
+
+	ByHash(ctx context.Context, tokenHash string) (*domain.FeedToken, error)
+	// All returns the tokens the user created for the list.
+	All(ctx context.Context, userID uint, listID uint) ([]*domain.FeedToken, error)
+}
+
+type feedTokenRepo struct {
+	DB db.DB
+}
+
+func NewFeedTokenRepo(db db.DB) *feedTokenRepo {
+	return &feedTokenRepo{
+		DB: db,
+	}
+}
+
+var _ FeedTokenRepo = (*feedTokenRepo)(nil)
+
+const feedTokenColumns = `feed_tokens.id, feed_tokens.uuid, feed_tokens.user_id, feed_tokens.list_id,
+	lists.uuid AS list_uuid, feed_tokens.name, feed_tokens.token_hash, feed_tokens.last_used_at,
+	feed_tokens.created_at, feed_tokens.updated_at, feed_tokens.deleted_at`
+
+func (r *feedTokenRepo) Create(ctx context.Context, token *domain.FeedToken, tokenHash string) (*domain.FeedToken, error) {
+	query := `
+		WITH inserted AS (
+			INSERT INTO feed_tokens (uuid, user_id, list_id, name, token_hash)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING *
+		)
+		SELECT ` + feedTokenColumns + `
+			FROM inserted AS feed_tokens
+		JOIN lists
+			ON lists.id = feed_tokens.list_id`
+
+	var tokenEntity entity.FeedToken
+	err := r.DB.Get(ctx, &tokenEntity, query, token.UUID, token.UserID, token.ListID, token.Name, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return tokenEntity.ToDomain(), nil
+}
+
+func (r *feedTokenRepo) Delete(ctx context.Context, userID uint, uuid string) error {
+	query := `
+		UPDATE feed_tokens SET deleted_at = $1
+		WHERE uuid = $2 AND user_id = $3 AND deleted_at IS NULL
+		RETURNING id`
+
+	var id uint
+	return r.DB.Get(ctx, &id, query, time.Now().UTC(), uuid, userID)
+}
+
+func (r *feedTokenRepo) Touch(ctx context.Context, id uint, usedAt time.Time) error {
+	_, err := r.DB.Exec(ctx, `UPDATE feed_tokens SET last_used_at = $1 WHERE id = $2`, usedAt.UTC(), id)
+
+	return err
+}
+
+func (r *feedTokenRepo) ByHash(ctx context.Context, tokenHash string) (*domain.FeedToken, error) {
+	query := `SELECT ` + feedTokenColumns + `
+			FROM feed_tokens
+		JOIN lists
+			ON lists.id = feed_tokens.list_id
+		WHERE feed_tokens.token_hash = $1
+			AND feed_tokens.deleted_at IS NULL
+			AND lists.deleted_at IS NULL`
+
+	var tokenEntity entity.FeedToken
+	if err := r.DB.Get_RO(ctx, &tokenEntity, query, tokenHash); err != nil {
+		return nil, err
+	}
+
+	return tokenEntity.ToDomain(), nil
+}
+
+func (r *feedTokenRepo) All(ctx context.Context, userID uint, listID uint) ([]*domain.FeedToken, error) {
+	query := `SELECT ` + feedTokenColumns + `
+			FROM feed_tokens
+		JOIN lists
+			ON lists.id = feed_tokens.list_id
+		WHERE feed_tokens.user_id = $1
+			AND feed_tokens.list_id = $2
+			AND feed_tokens.deleted_at IS NULL
+		ORDER BY feed_tokens.created_at DESC, feed_tokens.id DESC`
+
+	var tokenEntities []*entity.FeedToken
+	if err := r.DB.Select_RO(ctx, &tokenEntities, query, userID, listID); err != nil {
+		return nil, err
+	}
+
+	tokens := make([]*domain.FeedToken, 0, len(tokenEntities))
+	for _, tokenEntity := range tokenEntities {
+		tokens = append(tokens, tokenEntity.ToDomain())
+	}
+
+	return tokens, nil
+}
@@ -0,0 +1,245 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+	"github.com/meowmix1337/the_recipe_book/internal/pagination"
+)
+
+type FocusSessionRepo interface {
+	// Create starts a session, it returns sql.ErrNoRows when the user already has an active session.
+	Create(ctx context.Context, session *domain.FocusSession) (*domain.FocusSession, error)
+	// Interrupt counts an interruption of an active session.
+	Interrupt(ctx context.Context, id uint) (*domain.FocusSession, error)
+	// End ends an active session, the focused time is capped at the planned duration. A session
+	// that already ended returns sql.ErrNoRows.
+	End(ctx context.Context, id uint, status domain.FocusStatus, endedAt time.Time) (*domain.FocusSession, error)
+
+	ByUUID(ctx context.Context, userID uint, uuid string) (*domain.FocusSession, error)
+	// All returns the session history of a user, newest first.
+	All(ctx context.Context, userID uint, filter *domain.FocusSessionFilter, page *pagination.Page) ([]*domain.FocusSession, *pagination.Cursor, error)
+	// Stats aggregates the ended sessions started within [from, to).
+	Stats(ctx context.Context, userID uint, from time.Time, to time.Time) (*domain.FocusStats, error)
+}
+
+type focusSessionRepo struct {
+	DB db.DB
+}
+
+func NewFocusSessionRepo(db db.DB) *focusSessionRepo {
+	return &focusSessionRepo{
+		DB: db,
+	}
+}
+
+var _ FocusSessionRepo = (*focusSessionRepo)(nil)
+
+const (
+	// focusSessionColumns selects a focus session joined with its todo.
+	focusSessionColumns = `focus_sessions.id, focus_sessions.uuid, focus_sessions.user_id, focus_sessions.todo_id,
+		todos.uuid AS todo_uuid, todos.title AS todo_title, focus_sessions.status, focus_sessions.planned_seconds,
+		focus_sessions.focused_seconds, focus_sessions.interruptions, focus_sessions.started_at,
+		focus_sessions.ended_at, focus_sessions.created_at, focus_sessions.updated_at`
+)
+
+func (r *focusSessionRepo) Create(ctx context.Context, session *domain.FocusSession) (*domain.FocusSession, error) {
+	query := `
+		WITH inserted AS (
+			INSERT INTO focus_sessions (uuid, user_id, todo_id, planned_seconds)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (user_id) WHERE status = 'active' DO NOTHING
+			RETURNING *
+		)
+		SELECT ` + focusSessionColumns + `
+			FROM inserted AS focus_sessions
+		JOIN todos
+			ON todos.id = focus_sessions.todo_id`
+
+	var sessionEntity entity.FocusSession
+	err := r.DB.Get(ctx, &sessionEntity, query,
+		session.UUID,
+		session.UserID,
+		session.TodoID,
+		int(session.Planned.Seconds()),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return sessionEntity.ToDomain(), nil
+}
+
+func (r *focusSessionRepo) Interrupt(ctx context.Context, id uint) (*domain.FocusSession, error) {
+	query := `
+		WITH updated AS (
+			UPDATE focus_sessions SET interruptions = interruptions + 1
+			WHERE id = $1
+				AND status = 'active'
+			RETURNING *
+		)
+		SELECT ` + focusSessionColumns + `
+			FROM updated AS focus_sessions
+		JOIN todos
+			ON todos.id = focus_sessions.todo_id`
+
+	var sessionEntity entity.FocusSession
+	err := r.DB.Get(ctx, &sessionEntity, query, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return sessionEntity.ToDomain(), nil
+}
+
+func (r *focusSessionRepo) End(ctx context.Context, id uint, status domain.FocusStatus, endedAt time.Time) (*domain.FocusSession, error) {
+	query := `
+		WITH updated AS (
+			UPDATE focus_sessions SET
+				status = $1,
+				ended_at = $2,
+				focused_seconds = LEAST(GREATEST(EXTRACT(EPOCH FROM ($2 - started_at)), 0), planned_seconds)::INTEGER
+			WHERE id = $3
+				AND status = 'active'
+			RETURNING *
+		)
+		SELECT ` + focusSessionColumns + `
+			FROM updated AS focus_sessions
+		JOIN todos
+			ON todos.id = focus_sessions.todo_id`
+
+	var sessionEntity entity.FocusSession
+	err := r.DB.Get(ctx, &sessionEntity, query, string(status), endedAt.UTC(), id)
+	if err != nil {
+		return nil, err
+	}
+
+	return sessionEntity.ToDomain(), nil
+}
+
+func (r *focusSessionRepo) ByUUID(ctx context.Context, userID uint, uuid string) (*domain.FocusSession, error) {
+	query := `
+		SELECT ` + focusSessionColumns + `
+			FROM focus_sessions
+		JOIN todos
+			ON todos.id = focus_sessions.todo_id
+		WHERE focus_sessions.user_id = $1
+			AND focus_sessions.uuid = $2`
+
+	var sessionEntity entity.FocusSession
+	err := r.DB.Get_RO(ctx, &sessionEntity, query, userID, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	return sessionEntity.ToDomain(), nil
+}
+
+func (r *focusSessionRepo) All(
+	ctx context.Context,
+	userID uint,
+	filter *domain.FocusSessionFilter,
+	page *pagination.Page,
+) ([]*domain.FocusSession, *pagination.Cursor, error) {
+	query := `
+		SELECT ` + focusSessionColumns + `
+			FROM focus_sessions
+		JOIN todos
+			ON todos.id = focus_sessions.todo_id
+		WHERE focus_sessions.user_id = $1`
+	args := []interface{}{userID}
+
+	if filter != nil && filter.TodoID != 0 {
+		args = append(args, filter.TodoID)
+		query += fmt.Sprintf(` AND focus_sessions.todo_id = $%d`, len(args))
+	}
+
+	cursor, err := page.After("", 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cursor != nil {
+		args = append(args, cursor.ID)
+		query += fmt.Sprintf(` AND focus_sessions.id < $%d`, len(args))
+	}
+
+	query += ` ORDER BY focus_sessions.id DESC`
+	if page != nil {
+		args = append(args, page.FetchLimit())
+		query += fmt.Sprintf(` LIMIT $%d`, len(args))
+	}
+
+	var sessionEntities []*entity.FocusSession
+	err = r.DB.Select_RO(ctx, &sessionEntities, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sessions := make([]*domain.FocusSession, 0, len(sessionEntities))
+	for _, sessionEntity := range sessionEntities {
+		sessions = append(sessions, sessionEntity.ToDomain())
+	}
+
+	sessions, next := pagination.Trim(sessions, page, func(session *domain.FocusSession) *pagination.Cursor {
+		return &pagination.Cursor{ID: session.ID}
+	})
+
+	return sessions, next, nil
+}
+
+func (r *focusSessionRepo) Stats(ctx context.Context, userID uint, from time.Time, to time.Time) (*domain.FocusStats, error) {
+	query := `
+		SELECT COUNT(*) AS sessions,
+			COUNT(*) FILTER (WHERE status = 'completed') AS completed,
+			COALESCE(SUM(interruptions), 0) AS interruptions,
+			COALESCE(SUM(focused_seconds), 0) AS focused_seconds
+			FROM focus_sessions
+		WHERE user_id = $1
+			AND status <> 'active'
+			AND started_at >= $2
+			AND started_at < $3`
+
+	var statsEntity entity.FocusStats
+	err := r.DB.Get_RO(ctx, &statsEntity, query, userID, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	query = `
+		SELECT todos.uuid AS todo_uuid, todos.title AS todo_title,
+			COUNT(*) AS sessions, SUM(focus_sessions.focused_seconds) AS focused_seconds
+			FROM focus_sessions
+		JOIN todos
+			ON todos.id = focus_sessions.todo_id
+		WHERE focus_sessions.user_id = $1
+			AND focus_sessions.status <> 'active'
+			AND focus_sessions.started_at >= $2
+			AND focus_sessions.started_at < $3
+		GROUP BY todos.id
+		ORDER BY focused_seconds DESC, todos.id`
+
+	var todoEntities []*entity.FocusTodoStats
+	err = r.DB.Select_RO(ctx, &todoEntities, query, userID, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &domain.FocusStats{
+		From:          from,
+		To:            to,
+		Sessions:      statsEntity.Sessions,
+		Completed:     statsEntity.Completed,
+		Interruptions: statsEntity.Interruptions,
+		Focused:       time.Duration(statsEntity.FocusedSeconds) * time.Second,
+		Todos:         make([]*domain.FocusTodoStats, 0, len(todoEntities)),
+	}
+	for _, todoEntity := range todoEntities {
+		stats.Todos = append(stats.Todos, todoEntity.ToDomain())
+	}
+
+	return stats, nil
+}
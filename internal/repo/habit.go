@@ -0,0 +1,174 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+	"github.com/meowmix1337/the_recipe_book/internal/pagination"
+)
+
+type HabitRepo interface {
+	Create(ctx context.Context, habit *domain.Habit) (*domain.Habit, error)
+	Update(ctx context.Context, habit *domain.Habit) error
+	Delete(ctx context.Context, userID uint, uuid string) error
+	ByUUID(ctx context.Context, userID uint, uuid string) (*domain.Habit, error)
+	// All returns the habits of a user, oldest first.
+	All(ctx context.Context, userID uint, page *pagination.Page) ([]*domain.Habit, *pagination.Cursor, error)
+
+	// SetEntry creates or replaces the entry of a day.
+	SetEntry(ctx context.Context, entry *domain.HabitEntry) error
+	// DeleteEntry returns sql.ErrNoRows when the day has no entry.
+	DeleteEntry(ctx context.Context, habitID uint, date time.Time) error
+	// Entries returns the entries within [from, to] ordered by date, a zero from starts at the
+	// first entry.
+	Entries(ctx context.Context, habitID uint, from time.Time, to time.Time) ([]*domain.HabitEntry, error)
+}
+
+type habitRepo struct {
+	DB db.DB
+}
+
+func NewHabitRepo(db db.DB) *habitRepo {
+	return &habitRepo{
+		DB: db,
+	}
+}
+
+var _ HabitRepo = (*habitRepo)(nil)
+
+const (
+	habitColumns = `id, uuid, user_id, title, target_per_week, created_at, updated_at, deleted_at`
+)
+
+func (r *habitRepo) Create(ctx context.Context, habit *domain.Habit) (*domain.Habit, error) {
+	query := `
+		INSERT INTO habits (uuid, user_id, title, target_per_week)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + habitColumns
+
+	var habitEntity entity.Habit
+	err := r.DB.Get(ctx, &habitEntity, query, habit.UUID, habit.UserID, habit.Title, habit.TargetPerWeek)
+	if err != nil {
+		return nil, err
+	}
+
+	return habitEntity.ToDomain(), nil
+}
+
+func (r *habitRepo) Update(ctx context.Context, habit *domain.Habit) error {
+	query := `
+		UPDATE habits SET title = $1, target_per_week = $2
+		WHERE id = $3
+			AND user_id = $4
+			AND deleted_at IS NULL`
+
+	_, err := r.DB.Exec(ctx, query, habit.Title, habit.TargetPerWeek, habit.ID, habit.UserID)
+
+	return err
+}
+
+func (r *habitRepo) Delete(ctx context.Context, userID uint, uuid string) error {
+	query := `UPDATE habits SET deleted_at = $1 WHERE uuid = $2 AND user_id = $3 AND deleted_at IS NULL`
+	_, err := r.DB.Exec(ctx, query, time.Now().UTC(), uuid, userID)
+
+	return err
+}
+
+func (r *habitRepo) ByUUID(ctx context.Context, userID uint, uuid string) (*domain.Habit, error) {
+	query := `SELECT ` + habitColumns + ` FROM habits WHERE uuid = $1 AND user_id = $2 AND deleted_at IS NULL`
+
+	var habitEntity entity.Habit
+	err := r.DB.Get_RO(ctx, &habitEntity, query, uuid, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return habitEntity.ToDomain(), nil
+}
+
+func (r *habitRepo) All(ctx context.Context, userID uint, page *pagination.Page) ([]*domain.Habit, *pagination.Cursor, error) {
+	query := `SELECT ` + habitColumns + ` FROM habits WHERE user_id = $1 AND deleted_at IS NULL`
+	args := []interface{}{userID}
+
+	cursor, err := page.After("", 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cursor != nil {
+		args = append(args, cursor.ID)
+		query += fmt.Sprintf(` AND id > $%d`, len(args))
+	}
+
+	query += ` ORDER BY id`
+	if page != nil {
+		args = append(args, page.FetchLimit())
+		query += fmt.Sprintf(` LIMIT $%d`, len(args))
+	}
+
+	var habitEntities []*entity.Habit
+	err = r.DB.Select_RO(ctx, &habitEntities, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	habits := make([]*domain.Habit, 0, len(habitEntities))
+	for _, habitEntity := range habitEntities {
+		habits = append(habits, habitEntity.ToDomain())
+	}
+
+	habits, next := pagination.Trim(habits, page, func(habit *domain.Habit) *pagination.Cursor {
+		return &pagination.Cursor{ID: habit.ID}
+	})
+
+	return habits, next, nil
+}
+
+func (r *habitRepo) SetEntry(ctx context.Context, entry *domain.HabitEntry) error {
+	query := `
+		INSERT INTO habit_entries (habit_id, entry_date, status)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (habit_id, entry_date) DO UPDATE SET status = EXCLUDED.status`
+
+	_, err := r.DB.Exec(ctx, query, entry.HabitID, entry.Date.Format(domain.DateLayout), string(entry.Status))
+
+	return err
+}
+
+func (r *habitRepo) DeleteEntry(ctx context.Context, habitID uint, date time.Time) error {
+	query := `DELETE FROM habit_entries WHERE habit_id = $1 AND entry_date = $2 RETURNING habit_id`
+
+	var id uint
+	return r.DB.Get(ctx, &id, query, habitID, date.Format(domain.DateLayout))
+}
+
+func (r *habitRepo) Entries(ctx context.Context, habitID uint, from time.Time, to time.Time) ([]*domain.HabitEntry, error) {
+	query := `
+		SELECT habit_id, entry_date, status
+			FROM habit_entries
+		WHERE habit_id = $1
+			AND entry_date <= $2`
+	args := []interface{}{habitID, to.Format(domain.DateLayout)}
+
+	if !from.IsZero() {
+		args = append(args, from.Format(domain.DateLayout))
+		query += fmt.Sprintf(` AND entry_date >= $%d`, len(args))
+	}
+	query += ` ORDER BY entry_date`
+
+	var entryEntities []*entity.HabitEntry
+	err := r.DB.Select_RO(ctx, &entryEntities, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*domain.HabitEntry, 0, len(entryEntities))
+	for _, entryEntity := range entryEntities {
+		entries = append(entries, entryEntity.ToDomain())
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,184 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+)
+
+type ImportJobRepo interface {
+	Create(ctx context.Context, job *domain.ImportJob, payload []byte) (*domain.ImportJob, error)
+	// CreateTransfer creates a job importing the archive downloaded from job.SourceURL with token.
+	CreateTransfer(ctx context.Context, job *domain.ImportJob, token string) (*domain.ImportJob, error)
+	ByUUID(ctx context.Context, userID uint, uuid string) (*domain.ImportJob, error)
+	// Recent returns the latest jobs of the user, newest first.
+	Recent(ctx context.Context, userID uint, limit int) ([]*domain.ImportJob, error)
+
+	// Unfinished returns the pending jobs and the running jobs claimed before staleBefore.
+	Unfinished(ctx context.Context, staleBefore time.Time, limit int) ([]*domain.ImportJob, error)
+	// Claim marks the job as running. It fails with sql.ErrNoRows when another worker claimed
+	// the job since it was read.
+	Claim(ctx context.Context, job *domain.ImportJob, startedAt time.Time) error
+	// Payload returns the uploaded backup of an unfinished job.
+	Payload(ctx context.Context, id uint) ([]byte, error)
+	// SourceToken returns the token an unfinished transfer downloads the archive with.
+	SourceToken(ctx context.Context, id uint) (string, error)
+	// Progress stores the counts of a running job.
+	Progress(ctx context.Context, job *domain.ImportJob) error
+	// Received stores the download progress of a running transfer.
+	Received(ctx context.Context, job *domain.ImportJob) error
+	// Finish stores the outcome of a job and drops its backup and source token.
+	Finish(ctx context.Context, job *domain.ImportJob) error
+}
+
+type importJobRepo struct {
+	DB db.DB
+}
+
+func NewImportJobRepo(db db.DB) *importJobRepo {
+	return &importJobRepo{
+		DB: db,
+	}
+}
+
+var _ ImportJobRepo = (*importJobRepo)(nil)
+
+const importJobColumns = `id, uuid, user_id, provider, status, lists_created, todos_created, error,
+	source_url, bytes_received, archive_sha256, started_at, finished_at, created_at, updated_at`
+
+func (r *importJobRepo) Create(ctx context.Context, job *domain.ImportJob, payload []byte) (*domain.ImportJob, error) {
+	query := `
+		INSERT INTO import_jobs (uuid, user_id, provider, payload)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + importJobColumns
+
+	var jobEntity entity.ImportJob
+	if err := r.DB.Get(ctx, &jobEntity, query, job.UUID, job.UserID, string(job.Provider), payload); err != nil {
+		return nil, err
+	}
+
+	return jobEntity.ToDomain(), nil
+}
+
+func (r *importJobRepo) CreateTransfer(ctx context.Context, job *domain.ImportJob, token string) (*domain.ImportJob, error) {
+	query := `
+		INSERT INTO import_jobs (uuid, user_id, provider, source_url, source_token)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + importJobColumns
+
+	var jobEntity entity.ImportJob
+	if err := r.DB.Get(ctx, &jobEntity, query, job.UUID, job.UserID, string(job.Provider), job.SourceURL, token); err != nil {
+		return nil, err
+	}
+
+	return jobEntity.ToDomain(), nil
+}
+
+func (r *importJobRepo) ByUUID(ctx context.Context, userID uint, uuid string) (*domain.ImportJob, error) {
+	query := `SELECT ` + importJobColumns + ` FROM import_jobs WHERE uuid = $1 AND user_id = $2`
+
+	var jobEntity entity.ImportJob
+	if err := r.DB.Get_RO(ctx, &jobEntity, query, uuid, userID); err != nil {
+		return nil, err
+	}
+
+	return jobEntity.ToDomain(), nil
+}
+
+func (r *importJobRepo) Recent(ctx context.Context, userID uint, limit int) ([]*domain.ImportJob, error) {
+	query := `SELECT ` + importJobColumns + ` FROM import_jobs WHERE user_id = $1 ORDER BY created_at DESC, id DESC LIMIT $2`
+
+	var jobEntities []*entity.ImportJob
+	if err := r.DB.Select_RO(ctx, &jobEntities, query, userID, limit); err != nil {
+		return nil, err
+	}
+
+	return importJobs(jobEntities), nil
+}
+
+func (r *importJobRepo) Unfinished(ctx context.Context, staleBefore time.Time, limit int) ([]*domain.ImportJob, error) {
+	query := `
+		SELECT ` + importJobColumns + `
+			FROM import_jobs
+		WHERE status = 'pending'
+			OR (status = 'running' AND started_at < $1)
+		ORDER BY id
+		LIMIT $2`
+
+	var jobEntities []*entity.ImportJob
+	if err := r.DB.Select(ctx, &jobEntities, query, staleBefore.UTC(), limit); err != nil {
+		return nil, err
+	}
+
+	return importJobs(jobEntities), nil
+}
+
+func (r *importJobRepo) Claim(ctx context.Context, job *domain.ImportJob, startedAt time.Time) error {
+	query := `
+		UPDATE import_jobs SET status = 'running', started_at = $1
+		WHERE id = $2
+			AND status = $3
+			AND started_at IS NOT DISTINCT FROM $4
+		RETURNING id`
+
+	var id uint
+	return r.DB.Get(ctx, &id, query, startedAt.UTC(), job.ID, string(job.Status), nullTime(job.StartedAt))
+}
+
+func (r *importJobRepo) Payload(ctx context.Context, id uint) ([]byte, error) {
+	var payload []byte
+	err := r.DB.Get(ctx, &payload, `SELECT payload FROM import_jobs WHERE id = $1 AND payload IS NOT NULL`, id)
+
+	return payload, err
+}
+
+func (r *importJobRepo) SourceToken(ctx context.Context, id uint) (string, error) {
+	var token string
+	err := r.DB.Get(ctx, &token, `SELECT source_token FROM import_jobs WHERE id = $1 AND source_token IS NOT NULL`, id)
+
+	return token, err
+}
+
+func (r *importJobRepo) Progress(ctx context.Context, job *domain.ImportJob) error {
+	query := `UPDATE import_jobs SET lists_created = $1, todos_created = $2 WHERE id = $3`
+	_, err := r.DB.Exec(ctx, query, job.ListsCreated, job.TodosCreated, job.ID)
+
+	return err
+}
+
+func (r *importJobRepo) Received(ctx context.Context, job *domain.ImportJob) error {
+	query := `UPDATE import_jobs SET bytes_received = $1, archive_sha256 = $2 WHERE id = $3`
+	_, err := r.DB.Exec(ctx, query, job.BytesReceived, job.ArchiveSHA256, job.ID)
+
+	return err
+}
+
+func (r *importJobRepo) Finish(ctx context.Context, job *domain.ImportJob) error {
+	query := `
+		UPDATE import_jobs
+			SET status = $1, lists_created = $2, todos_created = $3, error = $4, finished_at = $5,
+				payload = NULL, source_token = NULL
+		WHERE id = $6`
+	_, err := r.DB.Exec(ctx, query,
+		string(job.Status),
+		job.ListsCreated,
+		job.TodosCreated,
+		job.Error,
+		nullTime(job.FinishedAt),
+		job.ID,
+	)
+
+	return err
+}
+
+func importJobs(jobEntities []*entity.ImportJob) []*domain.ImportJob {
+	jobs := make([]*domain.ImportJob, 0, len(jobEntities))
+	for _, jobEntity := range jobEntities {
+		jobs = append(jobs, jobEntity.ToDomain())
+	}
+
+	return jobs
+}
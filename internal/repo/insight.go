@@ -0,0 +1,148 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+)
+
+type InsightRepo interface {
+	// Settings fails with sql.ErrNoRows when the user never saved settings.
+	Settings(ctx context.Context, userID uint) (*domain.InsightSettings, error)
+	Upsert(ctx context.Context, settings *domain.InsightSettings) (*domain.InsightSettings, error)
+	// Due claims up to limit enabled settings whose check is due by moving their next check to
+	// next, and returns them.
+	Due(ctx context.Context, now time.Time, next time.Time, limit int) ([]*domain.InsightSettings, error)
+	// Stats counts the todos of the user the nudges are detected from, idle is the idle period
+	// of the user.
+	Stats(ctx context.Context, userID uint, now time.Time, idle time.Duration) (*domain.ProductivityStats, error)
+	// Nudged records when a nudge of the kind was sent to the user.
+	Nudged(ctx context.Context, userID uint, kind domain.NudgeKind, at time.Time) error
+}
+
+type insightRepo struct {
+	DB db.DB
+}
+
+func NewInsightRepo(db db.DB) *insightRepo {
+	return &insightRepo{
+		DB: db,
+	}
+}
+
+var _ InsightRepo = (*insightRepo)(nil)
+
+const insightSettingsColumns = `user_id, enabled, overdue_spike, idle_days, next_check_at, overdue_nudged_at,
+	idle_nudged_at, created_at, updated_at`
+
+func (r *insightRepo) Settings(ctx context.Context, userID uint) (*domain.InsightSettings, error) {
+	query := `SELECT ` + insightSettingsColumns + ` FROM insight_settings WHERE user_id = $1`
+
+	var settingsEntity entity.InsightSettings
+	err := r.DB.Get_RO(ctx, &settingsEntity, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return settingsEntity.ToDomain(), nil
+}
+
+func (r *insightRepo) Upsert(ctx context.Context, settings *domain.InsightSettings) (*domain.InsightSettings, error) {
+	query := `
+		INSERT INTO insight_settings (user_id, enabled, overdue_spike, idle_days)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE
+			SET enabled = EXCLUDED.enabled, overdue_spike = EXCLUDED.overdue_spike, idle_days = EXCLUDED.idle_days
+		RETURNING ` + insightSettingsColumns
+
+	var settingsEntity entity.InsightSettings
+	err := r.DB.Get(ctx, &settingsEntity, query,
+		settings.UserID,
+		settings.Enabled,
+		settings.OverdueSpike,
+		settings.IdleDays,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return settingsEntity.ToDomain(), nil
+}
+
+func (r *insightRepo) Due(ctx context.Context, now time.Time, next time.Time, limit int) ([]*domain.InsightSettings, error) {
+	query := `
+		UPDATE insight_settings SET next_check_at = $1
+		WHERE user_id IN (
+			SELECT user_id
+				FROM insight_settings
+			WHERE enabled
+				AND next_check_at <= $2
+			ORDER BY next_check_at
+			LIMIT $3
+		)
+			AND next_check_at <= $2
+		RETURNING ` + insightSettingsColumns
+
+	var settingsEntities []*entity.InsightSettings
+	if err := r.DB.Select(ctx, &settingsEntities, query, next.UTC(), now.UTC(), limit); err != nil {
+		return nil, err
+	}
+
+	settings := make([]*domain.InsightSettings, 0, len(settingsEntities))
+	for _, settingsEntity := range settingsEntities {
+		settings = append(settings, settingsEntity.ToDomain())
+	}
+
+	return settings, nil
+}
+
+func (r *insightRepo) Stats(ctx context.Context, userID uint, now time.Time, idle time.Duration) (*domain.ProductivityStats, error) {
+	query := `
+		SELECT COUNT(*) FILTER (WHERE due_date > $2 AND due_date <= $3 AND completed_at IS NULL) AS overdue_today,
+			COUNT(*) FILTER (
+				WHERE due_date > $4 AND due_date <= $2 AND (completed_at IS NULL OR completed_at > due_date)
+			) AS overdue_baseline,
+			COUNT(*) FILTER (WHERE completed_at > $5) AS completed,
+			COUNT(*) FILTER (WHERE completed_at IS NULL AND created_at <= $5) AS open
+			FROM todos
+		WHERE user_id = $1
+			AND deleted_at IS NULL`
+
+	now = now.UTC()
+	dayAgo := now.Add(-24 * time.Hour)
+
+	var statsEntity entity.ProductivityStats
+	err := r.DB.Get_RO(ctx, &statsEntity, query,
+		userID,
+		dayAgo,
+		now,
+		dayAgo.AddDate(0, 0, -domain.OverdueBaselineDays),
+		now.Add(-idle),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.ProductivityStats{
+		OverdueToday:    statsEntity.OverdueToday,
+		OverdueBaseline: statsEntity.OverdueBaseline,
+		Completed:       statsEntity.Completed,
+		Open:            statsEntity.Open,
+	}, nil
+}
+
+func (r *insightRepo) Nudged(ctx context.Context, userID uint, kind domain.NudgeKind, at time.Time) error {
+	column := "idle_nudged_at"
+	if kind == domain.NudgeOverdueSpike {
+		column = "overdue_nudged_at"
+	}
+
+	query := fmt.Sprintf(`UPDATE insight_settings SET %s = $1 WHERE user_id = $2`, column)
+	_, err := r.DB.Exec(ctx, query, at.UTC(), userID)
+
+	return err
+}
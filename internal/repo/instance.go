@@ -0,0 +1,78 @@
+package repo
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+)
+
+// InstanceRepo is the registry of the running instances, it always uses the home database so
+// every instance sees the same registry whatever regions it serves.
+type InstanceRepo interface {
+	// Heartbeat registers the instance or refreshes its row, the start time is kept.
+	Heartbeat(ctx context.Context, instance *domain.Instance) error
+	// Live returns the instances with a heartbeat after since, ordered by ID.
+	Live(ctx context.Context, since time.Time) ([]*domain.Instance, error)
+	Deregister(ctx context.Context, id string) error
+	// Prune removes the instances whose last heartbeat is before before.
+	Prune(ctx context.Context, before time.Time) error
+}
+
+type instanceRepo struct {
+	DB db.DB
+}
+
+func NewInstanceRepo(db db.DB) *instanceRepo {
+	return &instanceRepo{
+		DB: db,
+	}
+}
+
+var _ InstanceRepo = (*instanceRepo)(nil)
+
+func (r *instanceRepo) Heartbeat(ctx context.Context, instance *domain.Instance) error {
+	query := `
+		INSERT INTO instances (id, hostname, version, regions, shard, shards, started_at, heartbeat_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (id) DO UPDATE SET
+			hostname = EXCLUDED.hostname,
+			version = EXCLUDED.version,
+			regions = EXCLUDED.regions,
+			shard = EXCLUDED.shard,
+			shards = EXCLUDED.shards,
+			heartbeat_at = EXCLUDED.heartbeat_at`
+
+	_, err := r.DB.Exec(ctx, query, instance.ID, instance.Hostname, instance.Version, strings.Join(instance.Regions, ","),
+		instance.Shard, instance.Shards, instance.StartedAt, instance.HeartbeatAt)
+	return err
+}
+
+func (r *instanceRepo) Live(ctx context.Context, since time.Time) ([]*domain.Instance, error) {
+	query := `SELECT * FROM instances WHERE heartbeat_at > $1 ORDER BY id`
+
+	var rows []*entity.Instance
+	if err := r.DB.Select(ctx, &rows, query, since); err != nil {
+		return nil, err
+	}
+
+	instances := make([]*domain.Instance, 0, len(rows))
+	for _, row := range rows {
+		instances = append(instances, row.ToDomain())
+	}
+
+	return instances, nil
+}
+
+func (r *instanceRepo) Deregister(ctx context.Context, id string) error {
+	_, err := r.DB.Exec(ctx, `DELETE FROM instances WHERE id = $1`, id)
+	return err
+}
+
+func (r *instanceRepo) Prune(ctx context.Context, before time.Time) error {
+	_, err := r.DB.Exec(ctx, `DELETE FROM instances WHERE heartbeat_at < $1`, before)
+	return err
+}
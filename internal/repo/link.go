@@ -0,0 +1,173 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+)
+
+type LinkRepo interface {
+	// Sync replaces the links of a todo, links that are kept keep the outcome of their checks.
+	Sync(ctx context.Context, todoID uint, userID uint, links []string) error
+	// Due returns the links due for a check of open todos whose owner didn't disable the
+	// checker, with the UUID and title of their todo.
+	Due(ctx context.Context, now time.Time, limit int) ([]*domain.TodoLink, error)
+	// Claim moves the next check of the link to nextCheckAt. It fails with sql.ErrNoRows when
+	// another worker claimed it since it was read.
+	Claim(ctx context.Context, link *domain.TodoLink, nextCheckAt time.Time) error
+	// SetResult stores the outcome of a check.
+	SetResult(ctx context.Context, link *domain.TodoLink) error
+	// MarkNotified fails with sql.ErrNoRows when the owner was already notified about the link.
+	MarkNotified(ctx context.Context, link *domain.TodoLink, notifiedAt time.Time) error
+
+	// Settings returns the defaults for users who never changed them.
+	Settings(ctx context.Context, userID uint) (*domain.LinkSettings, error)
+	UpdateSettings(ctx context.Context, userID uint, settings *domain.LinkSettings) error
+}
+
+type linkRepo struct {
+	DB db.DB
+}
+
+func NewLinkRepo(db db.DB) *linkRepo {
+	return &linkRepo{
+		DB: db,
+	}
+}
+
+var _ LinkRepo = (*linkRepo)(nil)
+
+const todoLinkColumns = `todo_links.todo_id, todo_links.user_id, todo_links.url, todo_links.status_code,
+	todo_links.failures, todo_links.checked_at, todo_links.next_check_at, todo_links.dead_since, todo_links.notified_at`
+
+func (r *linkRepo) Sync(ctx context.Context, todoID uint, userID uint, links []string) error {
+	return r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		query := `DELETE FROM todo_links WHERE todo_id = $1`
+		args := []interface{}{todoID}
+		if len(links) > 0 {
+			query += fmt.Sprintf(` AND url NOT IN (%s)`, placeholders(2, len(links)))
+			for _, link := range links {
+				args = append(args, link)
+			}
+		}
+		if _, err := tx.Exec(ctx, query, args...); err != nil {
+			return err
+		}
+
+		insert := `INSERT INTO todo_links (todo_id, user_id, url) VALUES ($1, $2, $3) ON CONFLICT (todo_id, url) DO NOTHING`
+		for _, link := range links {
+			if _, err := tx.Exec(ctx, insert, todoID, userID, link); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *linkRepo) Due(ctx context.Context, now time.Time, limit int) ([]*domain.TodoLink, error) {
+	query := `
+		SELECT ` + todoLinkColumns + `, todos.uuid AS todo_uuid, todos.title AS todo_title
+			FROM todo_links
+			JOIN todos ON todos.id = todo_links.todo_id
+			LEFT JOIN link_check_settings ON link_check_settings.user_id = todo_links.user_id
+		WHERE todo_links.next_check_at <= $1
+			AND todos.deleted_at IS NULL
+			AND todos.completed_at IS NULL
+			AND COALESCE(link_check_settings.check_links, TRUE)
+		ORDER BY todo_links.next_check_at
+		LIMIT $2`
+
+	var linkEntities []*entity.TodoLink
+	if err := r.DB.Select(ctx, &linkEntities, query, now.UTC(), limit); err != nil {
+		return nil, err
+	}
+
+	links := make([]*domain.TodoLink, 0, len(linkEntities))
+	for _, linkEntity := range linkEntities {
+		links = append(links, linkEntity.ToDomain())
+	}
+
+	return links, nil
+}
+
+func (r *linkRepo) Claim(ctx context.Context, link *domain.TodoLink, nextCheckAt time.Time) error {
+	query := `
+		UPDATE todo_links SET next_check_at = $1
+		WHERE todo_id = $2
+			AND url = $3
+			AND next_check_at = $4
+		RETURNING todo_id`
+
+	var todoID uint
+	return r.DB.Get(ctx, &todoID, query, nextCheckAt.UTC(), link.TodoID, link.URL, link.NextCheckAt.UTC())
+}
+
+func (r *linkRepo) SetResult(ctx context.Context, link *domain.TodoLink) error {
+	query := `
+		UPDATE todo_links
+			SET status_code = $1, failures = $2, checked_at = $3, next_check_at = $4, dead_since = $5, notified_at = $6
+		WHERE todo_id = $7
+			AND url = $8`
+
+	var statusCode interface{}
+	if link.StatusCode != 0 {
+		statusCode = link.StatusCode
+	}
+	_, err := r.DB.Exec(ctx, query,
+		statusCode,
+		link.Failures,
+		nullTime(link.CheckedAt),
+		link.NextCheckAt.UTC(),
+		nullTime(link.DeadSince),
+		nullTime(link.NotifiedAt),
+		link.TodoID,
+		link.URL,
+	)
+
+	return err
+}
+
+func (r *linkRepo) MarkNotified(ctx context.Context, link *domain.TodoLink, notifiedAt time.Time) error {
+	query := `
+		UPDATE todo_links SET notified_at = $1
+		WHERE todo_id = $2
+			AND url = $3
+			AND notified_at IS NULL
+		RETURNING todo_id`
+
+	var todoID uint
+	return r.DB.Get(ctx, &todoID, query, notifiedAt.UTC(), link.TodoID, link.URL)
+}
+
+func (r *linkRepo) Settings(ctx context.Context, userID uint) (*domain.LinkSettings, error) {
+	query := `SELECT check_links, notify FROM link_check_settings WHERE user_id = $1`
+
+	var settingsEntity entity.LinkSettings
+	err := r.DB.Get_RO(ctx, &settingsEntity, query, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.DefaultLinkSettings(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return settingsEntity.ToDomain(), nil
+}
+
+func (r *linkRepo) UpdateSettings(ctx context.Context, userID uint, settings *domain.LinkSettings) error {
+	query := `
+		INSERT INTO link_check_settings (user_id, check_links, notify)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE
+			SET check_links = EXCLUDED.check_links, notify = EXCLUDED.notify, updated_at = CURRENT_TIMESTAMP`
+	_, err := r.DB.Exec(ctx, query, userID, settings.Check, settings.Notify)
+
+	return err
+}
@@ -0,0 +1,209 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+	"github.com/meowmix1337/the_recipe_book/internal/pagination"
+)
+
+type ListRepo interface {
+	Create(ctx context.Context, uuid string, userID uint, name string) (*domain.List, error)
+	Update(ctx context.Context, list *domain.List) error
+	// Delete soft deletes the list together with its todos.
+	Delete(ctx context.Context, userID uint, uuid string) error
+	// Merge moves the todos of the source list to the target and soft deletes the source, the
+	// todos of skipTodoIDs are deleted with it. Displays of the source show the target instead.
+	Merge(ctx context.Context, sourceID uint, targetID uint, skipTodoIDs []uint) error
+
+	ByID(ctx context.Context, id uint) (*domain.List, error)
+	ByUUID(ctx context.Context, userID uint, uuid string) (*domain.List, error)
+	All(ctx context.Context, userID uint, page *pagination.Page) ([]*domain.List, *pagination.Cursor, error)
+	// CountHousehold counts the lists of the parent account and its child accounts.
+	CountHousehold(ctx context.Context, parentID uint) (int, error)
+}
+
+type listRepo struct {
+	DB db.DB
+}
+
+func NewListRepo(db db.DB) *listRepo {
+	return &listRepo{
+		DB: db,
+	}
+}
+
+var _ ListRepo = (*listRepo)(nil)
+
+const (
+	listColumns = `lists.id, lists.uuid, lists.user_id, lists.name, lists.completed_retention_days, lists.key_version,
+		lists.created_at, lists.updated_at, lists.deleted_at`
+
+	listSort = "name"
+)
+
+func (r *listRepo) Create(ctx context.Context, uuid string, userID uint, name string) (*domain.List, error) {
+	query := `INSERT INTO lists (uuid, user_id, name) VALUES ($1, $2, $3) RETURNING ` + listColumns
+
+	var listEntity entity.List
+	err := r.DB.Get(ctx, &listEntity, query, uuid, userID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return listEntity.ToDomain(), nil
+}
+
+func (r *listRepo) Update(ctx context.Context, list *domain.List) error {
+	query := `UPDATE lists SET name = $1, completed_retention_days = $2 WHERE id = $3 AND deleted_at IS NULL`
+
+	var retentionDays interface{}
+	if list.CompletedRetention > 0 {
+		retentionDays = int(list.CompletedRetention / (24 * time.Hour))
+	}
+
+	_, err := r.DB.Exec(ctx, query, list.Name, retentionDays, list.ID)
+
+	return err
+}
+
+func (r *listRepo) Delete(ctx context.Context, userID uint, uuid string) error {
+	return r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		now := time.Now().UTC()
+
+		query := `
+			UPDATE todos SET deleted_at = $1
+			WHERE deleted_at IS NULL
+				AND list_id = (SELECT id FROM lists WHERE uuid = $2 AND user_id = $3 AND deleted_at IS NULL)`
+		if _, err := tx.Exec(ctx, query, now, uuid, userID); err != nil {
+			return err
+		}
+
+		query = `UPDATE lists SET deleted_at = $1 WHERE uuid = $2 AND user_id = $3 AND deleted_at IS NULL`
+		_, err := tx.Exec(ctx, query, now, uuid, userID)
+
+		return err
+	})
+}
+
+func (r *listRepo) Merge(ctx context.Context, sourceID uint, targetID uint, skipTodoIDs []uint) error {
+	return r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		now := time.Now().UTC()
+
+		if len(skipTodoIDs) > 0 {
+			args := []interface{}{now, sourceID}
+			for _, id := range skipTodoIDs {
+				args = append(args, id)
+			}
+			query := fmt.Sprintf(`
+				UPDATE todos SET deleted_at = $1
+				WHERE list_id = $2 AND deleted_at IS NULL AND id IN (%s)`, placeholders(3, len(skipTodoIDs)))
+			if _, err := tx.Exec(ctx, query, args...); err != nil {
+				return err
+			}
+		}
+
+		// statuses are per list, the todos start over on the board of the target
+		query := `
+			UPDATE todos SET list_id = $1, status = NULL, position = NULL, version = version + 1
+			WHERE list_id = $2 AND deleted_at IS NULL`
+		if _, err := tx.Exec(ctx, query, targetID, sourceID); err != nil {
+			return err
+		}
+
+		query = `
+			INSERT INTO display_token_lists (display_token_id, list_id)
+			SELECT display_token_id, $1 FROM display_token_lists WHERE list_id = $2
+			ON CONFLICT DO NOTHING`
+		if _, err := tx.Exec(ctx, query, targetID, sourceID); err != nil {
+			return err
+		}
+
+		query = `UPDATE list_snapshot_schedules SET deleted_at = $1 WHERE list_id = $2 AND deleted_at IS NULL`
+		if _, err := tx.Exec(ctx, query, now, sourceID); err != nil {
+			return err
+		}
+
+		query = `UPDATE lists SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL`
+		_, err := tx.Exec(ctx, query, now, sourceID)
+
+		return err
+	})
+}
+
+func (r *listRepo) CountHousehold(ctx context.Context, parentID uint) (int, error) {
+	query := `
+		SELECT COUNT(*) FROM lists
+		JOIN users ON users.id = lists.user_id
+		WHERE (users.id = $1 OR users.parent_id = $1) AND lists.deleted_at IS NULL`
+
+	var count int
+	err := r.DB.Get(ctx, &count, query, parentID)
+
+	return count, err
+}
+
+func (r *listRepo) ByID(ctx context.Context, id uint) (*domain.List, error) {
+	query := `SELECT ` + listColumns + ` FROM lists WHERE id = $1 AND deleted_at IS NULL`
+
+	var listEntity entity.List
+	err := r.DB.Get_RO(ctx, &listEntity, query, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return listEntity.ToDomain(), nil
+}
+
+func (r *listRepo) ByUUID(ctx context.Context, userID uint, uuid string) (*domain.List, error) {
+	query := `SELECT ` + listColumns + ` FROM lists WHERE uuid = $1 AND user_id = $2 AND deleted_at IS NULL`
+
+	var listEntity entity.List
+	err := r.DB.Get_RO(ctx, &listEntity, query, uuid, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return listEntity.ToDomain(), nil
+}
+
+func (r *listRepo) All(ctx context.Context, userID uint, page *pagination.Page) ([]*domain.List, *pagination.Cursor, error) {
+	query := `SELECT ` + listColumns + ` FROM lists WHERE user_id = $1 AND deleted_at IS NULL`
+	args := []interface{}{userID}
+
+	cursor, err := page.After(listSort, 1)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cursor != nil {
+		args = append(args, cursor.Values[0], cursor.ID)
+		query += ` AND (lists.name, lists.id) > ($2, $3)`
+	}
+
+	query += ` ORDER BY lists.name, lists.id`
+	if page != nil {
+		args = append(args, page.FetchLimit())
+		query += fmt.Sprintf(` LIMIT $%d`, len(args))
+	}
+
+	var listEntities []*entity.List
+	err = r.DB.Select_RO(ctx, &listEntities, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lists := make([]*domain.List, 0, len(listEntities))
+	for _, listEntity := range listEntities {
+		lists = append(lists, listEntity.ToDomain())
+	}
+
+	lists, next := pagination.Trim(lists, page, func(list *domain.List) *pagination.Cursor {
+		return &pagination.Cursor{Values: []string{list.Name}, ID: list.ID, Sort: listSort}
+	})
+
+	return lists, next, nil
+}
@@ -0,0 +1,127 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+)
+
+type ListInvitationRepo interface {
+	Create(ctx context.Context, invitation *domain.ListInvitation, tokenHash string) (*domain.ListInvitation, error)
+	Delete(ctx context.Context, listID uint, uuid string) error
+	// Accept marks the invitation as accepted and adds the user as a member of the list.
+	Accept(ctx context.Context, invitation *domain.ListInvitation, userID uint) error
+
+	ByHash(ctx context.Context, tokenHash string) (*domain.ListInvitation, error)
+	Pending(ctx context.Context, listID uint) ([]*domain.ListInvitation, error)
+}
+
+type listInvitationRepo struct {
+	DB db.DB
+}
+
+func NewListInvitationRepo(db db.DB) *listInvitationRepo {
+	return &listInvitationRepo{
+		DB: db,
+	}
+}
+
+var _ ListInvitationRepo = (*listInvitationRepo)(nil)
+
+const (
+	listInvitationColumns = `list_invitations.id, list_invitations.uuid, list_invitations.list_id, list_invitations.inviter_id,
+		list_invitations.email, list_invitations.role, list_invitations.token_hash, list_invitations.expires_at,
+		list_invitations.accepted_at, list_invitations.created_at, list_invitations.updated_at, list_invitations.deleted_at`
+)
+
+func (r *listInvitationRepo) Create(ctx context.Context, invitation *domain.ListInvitation, tokenHash string) (*domain.ListInvitation, error) {
+	query := `
+		INSERT INTO list_invitations (uuid, list_id, inviter_id, email, role, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + listInvitationColumns
+
+	var invitationEntity entity.ListInvitation
+	err := r.DB.Get(ctx, &invitationEntity, query,
+		invitation.UUID,
+		invitation.ListID,
+		invitation.InviterID,
+		invitation.Email,
+		string(invitation.Role),
+		tokenHash,
+		invitation.ExpiresAt.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return invitationEntity.ToDomain(), nil
+}
+
+func (r *listInvitationRepo) Delete(ctx context.Context, listID uint, uuid string) error {
+	query := `UPDATE list_invitations SET deleted_at = $1 WHERE list_id = $2 AND uuid = $3 AND deleted_at IS NULL`
+	_, err := r.DB.Exec(ctx, query, time.Now().UTC(), listID, uuid)
+
+	return err
+}
+
+func (r *listInvitationRepo) Accept(ctx context.Context, invitation *domain.ListInvitation, userID uint) error {
+	return r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		// only one request can accept the invitation
+		query := `
+			UPDATE list_invitations SET accepted_at = $1
+			WHERE id = $2
+				AND accepted_at IS NULL
+				AND deleted_at IS NULL
+			RETURNING id`
+
+		var id uint
+		if err := tx.Get(ctx, &id, query, time.Now().UTC(), invitation.ID); err != nil {
+			return err
+		}
+
+		query = `
+			INSERT INTO list_members (list_id, user_id, role)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (list_id, user_id) DO UPDATE SET role = EXCLUDED.role`
+		_, err := tx.Exec(ctx, query, invitation.ListID, userID, string(invitation.Role))
+
+		return err
+	})
+}
+
+func (r *listInvitationRepo) ByHash(ctx context.Context, tokenHash string) (*domain.ListInvitation, error) {
+	query := `SELECT ` + listInvitationColumns + ` FROM list_invitations WHERE token_hash = $1 AND deleted_at IS NULL`
+
+	var invitationEntity entity.ListInvitation
+	err := r.DB.Get_RO(ctx, &invitationEntity, query, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return invitationEntity.ToDomain(), nil
+}
+
+func (r *listInvitationRepo) Pending(ctx context.Context, listID uint) ([]*domain.ListInvitation, error) {
+	query := `SELECT ` + listInvitationColumns + ` FROM list_invitations
+		WHERE list_id = $1
+			AND accepted_at IS NULL
+			AND deleted_at IS NULL
+			AND expires_at > $2
+		ORDER BY list_invitations.created_at DESC, list_invitations.id DESC`
+
+	var invitationEntities []*entity.ListInvitation
+	err := r.DB.Select_RO(ctx, &invitationEntities, query, listID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	invitations := make([]*domain.ListInvitation, 0, len(invitationEntities))
+	for _, invitationEntity := range invitationEntities {
+		invitations = append(invitations, invitationEntity.ToDomain())
+	}
+
+	return invitations, nil
+}
@@ -0,0 +1,116 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+)
+
+type ListMemberRepo interface {
+	// Add adds the user to the list or changes the role of an existing member.
+	Add(ctx context.Context, listID uint, userID uint, role domain.ListRole) error
+	Remove(ctx context.Context, listID uint, userID uint) error
+
+	Members(ctx context.Context, listID uint) ([]*domain.ListMember, error)
+	// Shared returns the list with the given UUID when it is shared with the user.
+	Shared(ctx context.Context, userID uint, listUUID string) (*domain.SharedList, error)
+	SharedLists(ctx context.Context, userID uint) ([]*domain.SharedList, error)
+}
+
+type listMemberRepo struct {
+	DB db.DB
+}
+
+func NewListMemberRepo(db db.DB) *listMemberRepo {
+	return &listMemberRepo{
+		DB: db,
+	}
+}
+
+var _ ListMemberRepo = (*listMemberRepo)(nil)
+
+func (r *listMemberRepo) Add(ctx context.Context, listID uint, userID uint, role domain.ListRole) error {
+	query := `
+		INSERT INTO list_members (list_id, user_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (list_id, user_id) DO UPDATE SET role = EXCLUDED.role`
+	_, err := r.DB.Exec(ctx, query, listID, userID, string(role))
+
+	return err
+}
+
+func (r *listMemberRepo) Remove(ctx context.Context, listID uint, userID uint) error {
+	_, err := r.DB.Exec(ctx, `DELETE FROM list_members WHERE list_id = $1 AND user_id = $2`, listID, userID)
+
+	return err
+}
+
+func (r *listMemberRepo) Members(ctx context.Context, listID uint) ([]*domain.ListMember, error) {
+	query := `
+		SELECT list_members.list_id, list_members.user_id, users.uuid AS user_uuid, users.email,
+			list_members.role, list_members.created_at
+			FROM list_members
+		JOIN users
+			ON users.id = list_members.user_id
+		WHERE list_members.list_id = $1
+			AND users.deleted_at IS NULL
+		ORDER BY list_members.created_at, list_members.id`
+
+	var memberEntities []*entity.ListMember
+	err := r.DB.Select_RO(ctx, &memberEntities, query, listID)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]*domain.ListMember, 0, len(memberEntities))
+	for _, memberEntity := range memberEntities {
+		members = append(members, memberEntity.ToDomain())
+	}
+
+	return members, nil
+}
+
+func (r *listMemberRepo) Shared(ctx context.Context, userID uint, listUUID string) (*domain.SharedList, error) {
+	query := `
+		SELECT list_members.role, ` + listColumns + `
+			FROM lists
+		JOIN list_members
+			ON list_members.list_id = lists.id
+		WHERE lists.uuid = $1
+			AND list_members.user_id = $2
+			AND lists.deleted_at IS NULL`
+
+	var sharedEntity entity.SharedList
+	err := r.DB.Get_RO(ctx, &sharedEntity, query, listUUID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return sharedEntity.ToDomain(), nil
+}
+
+func (r *listMemberRepo) SharedLists(ctx context.Context, userID uint) ([]*domain.SharedList, error) {
+	query := `
+		SELECT list_members.role, ` + listColumns + `
+			FROM lists
+		JOIN list_members
+			ON list_members.list_id = lists.id
+		WHERE list_members.user_id = $1
+			AND lists.deleted_at IS NULL
+		ORDER BY lists.name, lists.id`
+
+	var sharedEntities []*entity.SharedList
+	err := r.DB.Select_RO(ctx, &sharedEntities, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	shared := make([]*domain.SharedList, 0, len(sharedEntities))
+	for _, sharedEntity := range sharedEntities {
+		shared = append(shared, sharedEntity.ToDomain())
+	}
+
+	return shared, nil
+}
@@ -0,0 +1,128 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+)
+
+type ListStatusRepo interface {
+	// Save stores the statuses of a list in their order, replacing the names, positions and done
+	// flags of those it already has.
+	Save(ctx context.Context, listID uint, statuses []*domain.ListStatus) error
+	// Delete removes a status, its todos go back to not being placed.
+	Delete(ctx context.Context, listID uint, key string) error
+	// ByList returns the stored statuses of a list in position order, none for lists that use the
+	// default statuses.
+	ByList(ctx context.Context, listID uint) ([]*domain.ListStatus, error)
+
+	// Place puts the todo in status and, together with it, the todos of todoIDs at their index in
+	// that column. The todo is only changed at todo.Version like an update and fails with
+	// sql.ErrNoRows otherwise.
+	Place(ctx context.Context, todo *domain.Todo, status string, todoIDs []uint) error
+}
+
+type listStatusRepo struct {
+	DB db.DB
+}
+
+func NewListStatusRepo(db db.DB) *listStatusRepo {
+	return &listStatusRepo{
+		DB: db,
+	}
+}
+
+var _ ListStatusRepo = (*listStatusRepo)(nil)
+
+const listStatusColumns = `id, list_id, key, name, position, done, created_at, updated_at`
+
+func (r *listStatusRepo) Save(ctx context.Context, listID uint, statuses []*domain.ListStatus) error {
+	return r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		query := `
+			INSERT INTO list_statuses (list_id, key, name, position, done)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (list_id, key) DO UPDATE
+				SET name = EXCLUDED.name, position = EXCLUDED.position, done = EXCLUDED.done`
+		for _, status := range statuses {
+			if _, err := tx.Exec(ctx, query, listID, status.Key, status.Name, status.Position, status.Done); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *listStatusRepo) Delete(ctx context.Context, listID uint, key string) error {
+	return r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		query := `
+			UPDATE todos SET status = NULL, position = NULL
+			WHERE list_id = $1
+				AND status = $2`
+		if _, err := tx.Exec(ctx, query, listID, key); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(ctx, `DELETE FROM list_statuses WHERE list_id = $1 AND key = $2`, listID, key)
+
+		return err
+	})
+}
+
+func (r *listStatusRepo) ByList(ctx context.Context, listID uint) ([]*domain.ListStatus, error) {
+	query := `SELECT ` + listStatusColumns + ` FROM list_statuses WHERE list_id = $1 ORDER BY position, id`
+
+	var statusEntities []*entity.ListStatus
+	if err := r.DB.Select_RO(ctx, &statusEntities, query, listID); err != nil {
+		return nil, err
+	}
+
+	statuses := make([]*domain.ListStatus, 0, len(statusEntities))
+	for _, statusEntity := range statusEntities {
+		statuses = append(statuses, statusEntity.ToDomain())
+	}
+
+	return statuses, nil
+}
+
+func (r *listStatusRepo) Place(ctx context.Context, todo *domain.Todo, status string, todoIDs []uint) error {
+	return r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		query := `
+			UPDATE todos
+				SET status = $1, completed_at = $2, version = version + 1,
+					archived_at = CASE WHEN $2::timestamptz IS NULL THEN NULL ELSE archived_at END
+			WHERE id = $3
+				AND user_id = $4
+				AND version = $5
+				AND deleted_at IS NULL
+			RETURNING version`
+
+		var version int
+		err := tx.Get(ctx, &version, query, status, nullTime(todo.CompletedAt), todo.ID, todo.UserID, todo.Version)
+		if err != nil {
+			return err
+		}
+
+		// the other todos of the column only move up or down, that isn't a change of theirs
+		query = `
+			UPDATE todos SET status = $1, position = $2
+			WHERE id = $3
+				AND list_id = $4
+				AND deleted_at IS NULL`
+		for i, todoID := range todoIDs {
+			if _, err = tx.Exec(ctx, query, status, i+1, todoID, todo.ListID); err != nil {
+				return err
+			}
+			if todoID == todo.ID {
+				todo.Position = i + 1
+			}
+		}
+
+		todo.Status = status
+		todo.Version = version
+
+		return nil
+	})
+}
@@ -0,0 +1,93 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+)
+
+type LockoutRepo interface {
+	// RecordFailure counts a failed login and locks the account until lockedUntil once it
+	// reaches maxFailures, it reports whether this failure locked the account.
+	RecordFailure(ctx context.Context, userID uint, maxFailures int, lockedUntil time.Time) (bool, error)
+	// LockedUntil returns the end of the lockout of the account, the zero time when it isn't locked.
+	LockedUntil(ctx context.Context, userID uint) (time.Time, error)
+	// Reset forgets the failed logins and lifts the lockout.
+	Reset(ctx context.Context, userID uint) error
+
+	CreateUnlock(ctx context.Context, userID uint, tokenHash string, expiresAt time.Time) error
+	// UseUnlock marks an unused and unexpired token as used and returns its user, sql.ErrNoRows
+	// when there is none.
+	UseUnlock(ctx context.Context, tokenHash string) (uint, error)
+}
+
+type lockoutRepo struct {
+	DB db.DB
+}
+
+func NewLockoutRepo(db db.DB) *lockoutRepo {
+	return &lockoutRepo{
+		DB: db,
+	}
+}
+
+var _ LockoutRepo = (*lockoutRepo)(nil)
+
+func (r *lockoutRepo) RecordFailure(ctx context.Context, userID uint, maxFailures int, lockedUntil time.Time) (bool, error) {
+	// the counter starts over with the lockout, so only the failure that locked it returns 0
+	query := `
+		INSERT INTO login_lockouts (user_id, failed_logins, updated_at) VALUES ($1, 1, $4)
+		ON CONFLICT (user_id) DO UPDATE SET
+			failed_logins = CASE WHEN login_lockouts.failed_logins + 1 >= $2 THEN 0 ELSE login_lockouts.failed_logins + 1 END,
+			locked_until = CASE WHEN login_lockouts.failed_logins + 1 >= $2 THEN $3 ELSE login_lockouts.locked_until END,
+			updated_at = $4
+		RETURNING failed_logins = 0`
+
+	var locked bool
+	err := r.DB.Get(ctx, &locked, query, userID, maxFailures, lockedUntil.UTC(), time.Now().UTC())
+
+	return locked, err
+}
+
+func (r *lockoutRepo) LockedUntil(ctx context.Context, userID uint) (time.Time, error) {
+	query := `SELECT locked_until FROM login_lockouts WHERE user_id = $1 AND locked_until > $2`
+
+	var lockedUntil time.Time
+	if err := r.DB.Get(ctx, &lockedUntil, query, userID, time.Now().UTC()); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+
+	return lockedUntil, nil
+}
+
+func (r *lockoutRepo) Reset(ctx context.Context, userID uint) error {
+	_, err := r.DB.Exec(ctx, `DELETE FROM login_lockouts WHERE user_id = $1`, userID)
+
+	return err
+}
+
+func (r *lockoutRepo) CreateUnlock(ctx context.Context, userID uint, tokenHash string, expiresAt time.Time) error {
+	query := `INSERT INTO account_unlocks (user_id, token_hash, expires_at) VALUES ($1, $2, $3)`
+	_, err := r.DB.Exec(ctx, query, userID, tokenHash, expiresAt.UTC())
+
+	return err
+}
+
+func (r *lockoutRepo) UseUnlock(ctx context.Context, tokenHash string) (uint, error) {
+	now := time.Now().UTC()
+	query := `
+		UPDATE account_unlocks SET used_at = $1
+		WHERE token_hash = $2 AND used_at IS NULL AND expires_at > $1
+		RETURNING user_id`
+
+	var userID uint
+	err := r.DB.Get(ctx, &userID, query, now, tokenHash)
+
+	return userID, err
+}
@@ -0,0 +1,194 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+	"github.com/meowmix1337/the_recipe_book/internal/pagination"
+)
+
+type LoginRepo interface {
+	Create(ctx context.Context, login *domain.Login) (*domain.Login, error)
+	// History sums up the logins of a user, FromCountry counts those from country.
+	History(ctx context.Context, userID uint, country string) (*domain.LoginHistory, error)
+	// All returns the logins of a user, newest first.
+	All(ctx context.Context, userID uint, page *pagination.Page) ([]*domain.Login, *pagination.Cursor, error)
+	// DeleteBefore deletes the logins older than before, of every user.
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+
+	// SaveDevice records a login from the device, a device seen before only gets its last
+	// login updated.
+	SaveDevice(ctx context.Context, device *domain.KnownDevice) (*domain.KnownDevice, error)
+	Device(ctx context.Context, userID uint, fingerprint string) (*domain.KnownDevice, error)
+	// Devices returns the known devices of a user, the last used first.
+	Devices(ctx context.Context, userID uint) ([]*domain.KnownDevice, error)
+	DeleteDevice(ctx context.Context, userID uint, uuid string) error
+}
+
+type loginRepo struct {
+	DB db.DB
+}
+
+func NewLoginRepo(db db.DB) *loginRepo {
+	return &loginRepo{
+		DB: db,
+	}
+}
+
+var _ LoginRepo = (*loginRepo)(nil)
+
+const (
+	loginColumns       = `id, uuid, user_id, ip, user_agent, country, fingerprint, new_device, new_country, created_at`
+	knownDeviceColumns = `id, uuid, user_id, fingerprint, user_agent, last_ip, last_country, first_seen_at, last_seen_at`
+)
+
+func (r *loginRepo) Create(ctx context.Context, login *domain.Login) (*domain.Login, error) {
+	query := `
+		INSERT INTO logins (uuid, user_id, ip, user_agent, country, fingerprint, new_device, new_country, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING ` + loginColumns
+
+	var loginEntity entity.Login
+	err := r.DB.Get(ctx, &loginEntity, query,
+		login.UUID,
+		login.UserID,
+		login.IP,
+		login.UserAgent,
+		login.Country,
+		login.Fingerprint,
+		login.NewDevice,
+		login.NewCountry,
+		login.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return loginEntity.ToDomain(), nil
+}
+
+func (r *loginRepo) History(ctx context.Context, userID uint, country string) (*domain.LoginHistory, error) {
+	query := `
+		SELECT COUNT(*) AS logins, COUNT(*) FILTER (WHERE country = $2 AND country <> '') AS from_country
+		FROM logins WHERE user_id = $1`
+
+	var history struct {
+		Logins      int `db:"logins"`
+		FromCountry int `db:"from_country"`
+	}
+	if err := r.DB.Get(ctx, &history, query, userID, country); err != nil {
+		return nil, err
+	}
+
+	return &domain.LoginHistory{Logins: history.Logins, FromCountry: history.FromCountry}, nil
+}
+
+func (r *loginRepo) All(ctx context.Context, userID uint, page *pagination.Page) ([]*domain.Login, *pagination.Cursor, error) {
+	query := `SELECT ` + loginColumns + ` FROM logins WHERE user_id = $1`
+	args := []interface{}{userID}
+
+	cursor, err := page.After("", 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cursor != nil {
+		args = append(args, cursor.ID)
+		query += fmt.Sprintf(` AND id < $%d`, len(args))
+	}
+
+	query += ` ORDER BY id DESC`
+	if page != nil {
+		args = append(args, page.FetchLimit())
+		query += fmt.Sprintf(` LIMIT $%d`, len(args))
+	}
+
+	var loginEntities []*entity.Login
+	err = r.DB.Select_RO(ctx, &loginEntities, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	logins := make([]*domain.Login, 0, len(loginEntities))
+	for _, loginEntity := range loginEntities {
+		logins = append(logins, loginEntity.ToDomain())
+	}
+
+	logins, next := pagination.Trim(logins, page, func(login *domain.Login) *pagination.Cursor {
+		return &pagination.Cursor{ID: login.ID}
+	})
+
+	return logins, next, nil
+}
+
+func (r *loginRepo) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.DB.Exec(ctx, `DELETE FROM logins WHERE created_at < $1`, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (r *loginRepo) SaveDevice(ctx context.Context, device *domain.KnownDevice) (*domain.KnownDevice, error) {
+	query := `
+		INSERT INTO known_devices (uuid, user_id, fingerprint, user_agent, last_ip, last_country, first_seen_at, last_seen_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+		ON CONFLICT (user_id, fingerprint) DO UPDATE
+			SET user_agent = EXCLUDED.user_agent, last_ip = EXCLUDED.last_ip,
+				last_country = EXCLUDED.last_country, last_seen_at = EXCLUDED.last_seen_at
+		RETURNING ` + knownDeviceColumns
+
+	var deviceEntity entity.KnownDevice
+	err := r.DB.Get(ctx, &deviceEntity, query,
+		device.UUID,
+		device.UserID,
+		device.Fingerprint,
+		device.UserAgent,
+		device.LastIP,
+		device.LastCountry,
+		device.LastSeenAt.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return deviceEntity.ToDomain(), nil
+}
+
+func (r *loginRepo) Device(ctx context.Context, userID uint, fingerprint string) (*domain.KnownDevice, error) {
+	query := `SELECT ` + knownDeviceColumns + ` FROM known_devices WHERE user_id = $1 AND fingerprint = $2`
+
+	var deviceEntity entity.KnownDevice
+	if err := r.DB.Get(ctx, &deviceEntity, query, userID, fingerprint); err != nil {
+		return nil, err
+	}
+
+	return deviceEntity.ToDomain(), nil
+}
+
+func (r *loginRepo) Devices(ctx context.Context, userID uint) ([]*domain.KnownDevice, error) {
+	query := `SELECT ` + knownDeviceColumns + ` FROM known_devices WHERE user_id = $1 ORDER BY last_seen_at DESC, id DESC`
+
+	var deviceEntities []*entity.KnownDevice
+	if err := r.DB.Select_RO(ctx, &deviceEntities, query, userID); err != nil {
+		return nil, err
+	}
+
+	devices := make([]*domain.KnownDevice, 0, len(deviceEntities))
+	for _, deviceEntity := range deviceEntities {
+		devices = append(devices, deviceEntity.ToDomain())
+	}
+
+	return devices, nil
+}
+
+func (r *loginRepo) DeleteDevice(ctx context.Context, userID uint, uuid string) error {
+	query := `DELETE FROM known_devices WHERE uuid = $1 AND user_id = $2 RETURNING id`
+
+	var id uint
+	return r.DB.Get(ctx, &id, query, uuid, userID)
+}
@@ -0,0 +1,166 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+)
+
+type OAuthRepo interface {
+	CreateCode(ctx context.Context, code *domain.OAuthAuthorizationCode, codeHash string) error
+	// RedeemCode marks an unused code as used and returns it, a code that was already redeemed
+	// returns sql.ErrNoRows.
+	RedeemCode(ctx context.Context, codeHash string, usedAt time.Time) (*domain.OAuthAuthorizationCode, error)
+
+	CreateGrant(ctx context.Context, grant *domain.OAuthGrant, accessHash string, refreshHash string) (*domain.OAuthGrant, error)
+	// RotateGrant replaces the tokens of the client's grant the refresh token belongs to, a refresh
+	// token can only be used once.
+	RotateGrant(
+		ctx context.Context,
+		clientID string,
+		refreshHash string,
+		accessHash string,
+		newRefreshHash string,
+		accessExpiresAt time.Time,
+	) (*domain.OAuthGrant, error)
+	DeleteGrant(ctx context.Context, userID uint, uuid string) error
+	// DeleteGrants revokes every grant of the user, their refresh tokens can't be used any longer.
+	DeleteGrants(ctx context.Context, userID uint) error
+	// TouchGrant records when the grant was last used by its client.
+	TouchGrant(ctx context.Context, id uint, usedAt time.Time) error
+
+	GrantByAccessHash(ctx context.Context, accessHash string) (*domain.OAuthGrant, error)
+	Grants(ctx context.Context, userID uint) ([]*domain.OAuthGrant, error)
+}
+
+type oauthRepo struct {
+	DB db.DB
+}
+
+func NewOAuthRepo(db db.DB) *oauthRepo {
+	return &oauthRepo{
+		DB: db,
+	}
+}
+
+var _ OAuthRepo = (*oauthRepo)(nil)
+
+func (r *oauthRepo) CreateCode(ctx context.Context, code *domain.OAuthAuthorizationCode, codeHash string) error {
+	query := `
+		INSERT INTO oauth_authorization_codes (code_hash, user_id, client_id, redirect_uri, expires_at)
+		VALUES ($1, $2, $3, $4, $5)`
+	_, err := r.DB.Exec(ctx, query, codeHash, code.UserID, code.ClientID, code.RedirectURI, code.ExpiresAt.UTC())
+
+	return err
+}
+
+func (r *oauthRepo) RedeemCode(ctx context.Context, codeHash string, usedAt time.Time) (*domain.OAuthAuthorizationCode, error) {
+	query := `
+		UPDATE oauth_authorization_codes SET used_at = $1
+		WHERE code_hash = $2 AND used_at IS NULL
+		RETURNING *`
+
+	var codeEntity entity.OAuthAuthorizationCode
+	err := r.DB.Get(ctx, &codeEntity, query, usedAt.UTC(), codeHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return codeEntity.ToDomain(), nil
+}
+
+func (r *oauthRepo) CreateGrant(ctx context.Context, grant *domain.OAuthGrant, accessHash string, refreshHash string) (*domain.OAuthGrant, error) {
+	query := `
+		INSERT INTO oauth_grants (uuid, user_id, client_id, access_token_hash, refresh_token_hash, access_expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING *`
+
+	var grantEntity entity.OAuthGrant
+	err := r.DB.Get(ctx, &grantEntity, query,
+		grant.UUID,
+		grant.UserID,
+		grant.ClientID,
+		accessHash,
+		refreshHash,
+		grant.AccessExpiresAt.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return grantEntity.ToDomain(), nil
+}
+
+func (r *oauthRepo) RotateGrant(
+	ctx context.Context,
+	clientID string,
+	refreshHash string,
+	accessHash string,
+	newRefreshHash string,
+	accessExpiresAt time.Time,
+) (*domain.OAuthGrant, error) {
+	query := `
+		UPDATE oauth_grants SET access_token_hash = $1, refresh_token_hash = $2, access_expires_at = $3
+		WHERE refresh_token_hash = $4 AND client_id = $5 AND deleted_at IS NULL
+		RETURNING *`
+
+	var grantEntity entity.OAuthGrant
+	err := r.DB.Get(ctx, &grantEntity, query, accessHash, newRefreshHash, accessExpiresAt.UTC(), refreshHash, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	return grantEntity.ToDomain(), nil
+}
+
+func (r *oauthRepo) DeleteGrant(ctx context.Context, userID uint, uuid string) error {
+	query := `UPDATE oauth_grants SET deleted_at = $1 WHERE uuid = $2 AND user_id = $3 AND deleted_at IS NULL`
+	_, err := r.DB.Exec(ctx, query, time.Now().UTC(), uuid, userID)
+
+	return err
+}
+
+func (r *oauthRepo) DeleteGrants(ctx context.Context, userID uint) error {
+	query := `UPDATE oauth_grants SET deleted_at = $1 WHERE user_id = $2 AND deleted_at IS NULL`
+	_, err := r.DB.Exec(ctx, query, time.Now().UTC(), userID)
+
+	return err
+}
+
+func (r *oauthRepo) TouchGrant(ctx context.Context, id uint, usedAt time.Time) error {
+	_, err := r.DB.Exec(ctx, `UPDATE oauth_grants SET last_used_at = $1 WHERE id = $2`, usedAt.UTC(), id)
+
+	return err
+}
+
+func (r *oauthRepo) GrantByAccessHash(ctx context.Context, accessHash string) (*domain.OAuthGrant, error) {
+	query := `SELECT * FROM oauth_grants WHERE access_token_hash = $1 AND deleted_at IS NULL`
+
+	var grantEntity entity.OAuthGrant
+	err := r.DB.Get_RO(ctx, &grantEntity, query, accessHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return grantEntity.ToDomain(), nil
+}
+
+func (r *oauthRepo) Grants(ctx context.Context, userID uint) ([]*domain.OAuthGrant, error) {
+	query := `SELECT * FROM oauth_grants WHERE user_id = $1 AND deleted_at IS NULL ORDER BY created_at DESC, id DESC`
+
+	var grantEntities []*entity.OAuthGrant
+	err := r.DB.Select_RO(ctx, &grantEntities, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	grants := make([]*domain.OAuthGrant, 0, len(grantEntities))
+	for _, grantEntity := range grantEntities {
+		grants = append(grants, grantEntity.ToDomain())
+	}
+
+	return grants, nil
+}
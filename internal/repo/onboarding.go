@@ -0,0 +1,144 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+)
+
+const (
+	onboardingKindStep = "step"
+	onboardingKindTip  = "tip"
+)
+
+type OnboardingRepo interface {
+	// State returns the onboarding state of the user, nothing completed for users that never
+	// changed it.
+	State(ctx context.Context, userID uint) (*domain.OnboardingState, error)
+	// Save completes the steps and dismisses the tips of update, which were validated already.
+	Save(ctx context.Context, userID uint, update *domain.OnboardingUpdate, now time.Time) error
+	// Reset forgets the completed steps and dismissed tips of the user, the first login triggers
+	// don't run again.
+	Reset(ctx context.Context, userID uint) error
+	// Trigger claims the first login triggers of the user, it reports false when they ran before.
+	Trigger(ctx context.Context, userID uint, now time.Time) (bool, error)
+}
+
+type onboardingRepo struct {
+	DB db.DB
+}
+
+func NewOnboardingRepo(db db.DB) *onboardingRepo {
+	return &onboardingRepo{
+		DB: db,
+	}
+}
+
+var _ OnboardingRepo = (*onboardingRepo)(nil)
+
+func (r *onboardingRepo) State(ctx context.Context, userID uint) (*domain.OnboardingState, error) {
+	var stateEntity entity.OnboardingState
+	query := `SELECT user_id, dismissed_at, triggered_at, created_at, updated_at FROM onboarding_states WHERE user_id = $1`
+	err := r.DB.Get_RO(ctx, &stateEntity, query, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	var progress []*entity.OnboardingProgress
+	query = `SELECT kind, key, created_at FROM onboarding_progress WHERE user_id = $1 ORDER BY created_at, key`
+	if err = r.DB.Select_RO(ctx, &progress, query, userID); err != nil {
+		return nil, err
+	}
+
+	completed := make(map[string]time.Time, len(progress))
+	tips := []string{}
+	for _, item := range progress {
+		switch item.Kind {
+		case onboardingKindStep:
+			completed[item.Key] = item.CreatedAt
+		case onboardingKindTip:
+			tips = append(tips, item.Key)
+		}
+	}
+
+	state := domain.NewOnboardingState(userID, completed, tips)
+	state.DismissedAt = stateEntity.DismissedAt.Time
+	state.UpdatedAt = stateEntity.UpdatedAt
+
+	return state, nil
+}
+
+func (r *onboardingRepo) Save(ctx context.Context, userID uint, update *domain.OnboardingUpdate, now time.Time) error {
+	return r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		// the state row is touched on every change, so updated_at tells clients when to sync
+		query := `
+			INSERT INTO onboarding_states (user_id, dismissed_at)
+			VALUES ($1, CASE WHEN $2 THEN $3::TIMESTAMPTZ END)
+			ON CONFLICT (user_id) DO UPDATE
+				SET dismissed_at = CASE
+					WHEN $4 AND $2 THEN COALESCE(onboarding_states.dismissed_at, EXCLUDED.dismissed_at)
+					WHEN $4 THEN NULL
+					ELSE onboarding_states.dismissed_at
+				END`
+		dismissed := update.Dismissed != nil && *update.Dismissed
+		if _, err := tx.Exec(ctx, query, userID, dismissed, now.UTC(), update.Dismissed != nil); err != nil {
+			return err
+		}
+
+		query = `
+			INSERT INTO onboarding_progress (user_id, kind, key, created_at)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (user_id, kind, key) DO NOTHING`
+		for _, key := range update.CompleteSteps {
+			if _, err := tx.Exec(ctx, query, userID, onboardingKindStep, key, now.UTC()); err != nil {
+				return err
+			}
+		}
+		for _, key := range update.DismissTips {
+			if _, err := tx.Exec(ctx, query, userID, onboardingKindTip, key, now.UTC()); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *onboardingRepo) Reset(ctx context.Context, userID uint) error {
+	return r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM onboarding_progress WHERE user_id = $1`, userID); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(ctx, `UPDATE onboarding_states SET dismissed_at = NULL WHERE user_id = $1`, userID)
+
+		return err
+	})
+}
+
+func (r *onboardingRepo) Trigger(ctx context.Context, userID uint, now time.Time) (bool, error) {
+	// the row is only returned when triggered_at was set by this statement
+	query := `
+		INSERT INTO onboarding_states (user_id, triggered_at)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+			SET triggered_at = EXCLUDED.triggered_at
+			WHERE onboarding_states.triggered_at IS NULL
+		RETURNING user_id`
+
+	var claimed uint
+	err := r.DB.Get(ctx, &claimed, query, userID, now.UTC())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
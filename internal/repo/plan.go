@@ -0,0 +1,142 @@
+package repo
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+)
+
+type PlanRepo interface {
+	// Save stores the plan of its day, replacing the items of an earlier plan of the same day.
+	Save(ctx context.Context, plan *domain.Plan) (*domain.Plan, error)
+	// ByDate returns the plan of a day, deleted todos are left out.
+	ByDate(ctx context.Context, userID uint, date time.Time) (*domain.Plan, error)
+	// LastCapacity returns the capacity of the latest plan of a working day, it returns
+	// sql.ErrNoRows when the user never planned one.
+	LastCapacity(ctx context.Context, userID uint) (time.Duration, error)
+}
+
+type planRepo struct {
+	DB db.DB
+}
+
+func NewPlanRepo(db db.DB) *planRepo {
+	return &planRepo{
+		DB: db,
+	}
+}
+
+var _ PlanRepo = (*planRepo)(nil)
+
+const (
+	planColumns = `daily_plans.id, daily_plans.user_id, daily_plans.plan_date, daily_plans.capacity_minutes,
+		daily_plans.created_at, daily_plans.updated_at`
+)
+
+func (r *planRepo) Save(ctx context.Context, plan *domain.Plan) (*domain.Plan, error) {
+	var planEntity entity.Plan
+	err := r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		query := `
+			INSERT INTO daily_plans (user_id, plan_date, capacity_minutes)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (user_id, plan_date) DO UPDATE SET capacity_minutes = EXCLUDED.capacity_minutes
+			RETURNING ` + planColumns
+
+		err := tx.Get(ctx, &planEntity, query,
+			plan.UserID,
+			plan.Date.Format(domain.DateLayout),
+			int(plan.Capacity.Minutes()),
+		)
+		if err != nil {
+			return err
+		}
+
+		if _, err = tx.Exec(ctx, `DELETE FROM daily_plan_items WHERE plan_id = $1`, planEntity.ID); err != nil {
+			return err
+		}
+
+		query = `
+			INSERT INTO daily_plan_items (plan_id, todo_id, position, score, estimate_minutes, reasons)
+			VALUES ($1, $2, $3, $4, $5, $6)`
+		for _, item := range plan.Items {
+			_, err = tx.Exec(ctx, query,
+				planEntity.ID,
+				item.Todo.ID,
+				item.Position,
+				item.Score,
+				int(item.Estimate.Minutes()),
+				strings.Join(item.Reasons, "\n"),
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	saved := planEntity.ToDomain()
+	saved.Items = plan.Items
+
+	return saved, nil
+}
+
+func (r *planRepo) ByDate(ctx context.Context, userID uint, date time.Time) (*domain.Plan, error) {
+	query := `SELECT ` + planColumns + ` FROM daily_plans WHERE user_id = $1 AND plan_date = $2`
+
+	var planEntity entity.Plan
+	err := r.DB.Get_RO(ctx, &planEntity, query, userID, date.Format(domain.DateLayout))
+	if err != nil {
+		return nil, err
+	}
+
+	query = `
+		SELECT ` + todoSelectColumns + `, daily_plan_items.position, daily_plan_items.score,
+			daily_plan_items.estimate_minutes AS planned_minutes, daily_plan_items.reasons
+			FROM daily_plan_items
+		JOIN todos
+			ON todos.id = daily_plan_items.todo_id
+		WHERE daily_plan_items.plan_id = $1
+			AND todos.deleted_at IS NULL
+		ORDER BY daily_plan_items.position`
+
+	var itemEntities []*entity.PlanItem
+	err = r.DB.Select_RO(ctx, &itemEntities, query, planEntity.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := planEntity.ToDomain()
+	todos := make([]*domain.Todo, 0, len(itemEntities))
+	for _, itemEntity := range itemEntities {
+		item := itemEntity.ToDomain()
+		plan.Items = append(plan.Items, item)
+		todos = append(todos, item.Todo)
+	}
+
+	if err = attachTodoTags(ctx, r.DB, todos); err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+func (r *planRepo) LastCapacity(ctx context.Context, userID uint) (time.Duration, error) {
+	// plans of days off have no capacity, they don't carry over to working days
+	query := `SELECT capacity_minutes FROM daily_plans WHERE user_id = $1 AND capacity_minutes > 0 ORDER BY updated_at DESC LIMIT 1`
+
+	var minutes int
+	err := r.DB.Get_RO(ctx, &minutes, query, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(minutes) * time.Minute, nil
+}
@@ -0,0 +1,94 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+)
+
+type PushSubscriptionRepo interface {
+	// Upsert stores the subscription, a known endpoint is handed to the user with the new keys
+	// since browsers keep the endpoint when they renew a subscription.
+	Upsert(ctx context.Context, subscription *domain.PushSubscription) (*domain.PushSubscription, error)
+	// Delete fails with sql.ErrNoRows when the user has no such subscription.
+	Delete(ctx context.Context, userID uint, uuid string) error
+	// DeleteEndpoint removes a subscription the push service dropped.
+	DeleteEndpoint(ctx context.Context, endpoint string) error
+	All(ctx context.Context, userID uint) ([]*domain.PushSubscription, error)
+}
+
+type pushSubscriptionRepo struct {
+	DB db.DB
+}
+
+func NewPushSubscriptionRepo(db db.DB) *pushSubscriptionRepo {
+	return &pushSubscriptionRepo{
+		DB: db,
+	}
+}
+
+var _ PushSubscriptionRepo = (*pushSubscriptionRepo)(nil)
+
+const pushSubscriptionColumns = `id, uuid, user_id, endpoint, p256dh, auth, user_agent, created_at, updated_at`
+
+func (r *pushSubscriptionRepo) Upsert(ctx context.Context, subscription *domain.PushSubscription) (*domain.PushSubscription, error) {
+	query := `
+		INSERT INTO push_subscriptions (uuid, user_id, endpoint, p256dh, auth, user_agent)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (endpoint) DO UPDATE
+			SET user_id = EXCLUDED.user_id, p256dh = EXCLUDED.p256dh, auth = EXCLUDED.auth,
+				user_agent = EXCLUDED.user_agent
+		RETURNING ` + pushSubscriptionColumns
+
+	var subscriptionEntity entity.PushSubscription
+	err := r.DB.Get(ctx, &subscriptionEntity, query,
+		subscription.UUID,
+		subscription.UserID,
+		subscription.Endpoint,
+		subscription.P256dh,
+		subscription.Auth,
+		subscription.UserAgent,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return subscriptionEntity.ToDomain(), nil
+}
+
+func (r *pushSubscriptionRepo) Delete(ctx context.Context, userID uint, uuid string) error {
+	query := `DELETE FROM push_subscriptions WHERE uuid = $1 AND user_id = $2 RETURNING id`
+
+	var id uint
+	return r.DB.Get(ctx, &id, query, uuid, userID)
+}
+
+func (r *pushSubscriptionRepo) DeleteEndpoint(ctx context.Context, endpoint string) error {
+	query := `DELETE FROM push_subscriptions WHERE endpoint = $1`
+	_, err := r.DB.Exec(ctx, query, endpoint)
+
+	return err
+}
+
+func (r *pushSubscriptionRepo) All(ctx context.Context, userID uint) ([]*domain.PushSubscription, error) {
+	query := `
+		SELECT ` + pushSubscriptionColumns + `
+			FROM push_subscriptions
+		WHERE user_id = $1
+		ORDER BY created_at`
+
+	var subscriptionEntities []*entity.PushSubscription
+	err := r.DB.Select_RO(ctx, &subscriptionEntities, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	subscriptions := make([]*domain.PushSubscription, 0, len(subscriptionEntities))
+	for _, subscriptionEntity := range subscriptionEntities {
+		subscriptions = append(subscriptions, subscriptionEntity.ToDomain())
+	}
+
+	return subscriptions, nil
+}
@@ -0,0 +1,16 @@
+package repo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// placeholders returns a comma separated list of count postgres placeholders starting at $start.
+func placeholders(start int, count int) string {
+	params := make([]string, count)
+	for i := range count {
+		params[i] = fmt.Sprintf("$%d", start+i)
+	}
+
+	return strings.Join(params, ", ")
+}
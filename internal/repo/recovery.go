@@ -0,0 +1,304 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+)
+
+type RecoveryRepo interface {
+	// SaveSettings replaces the trusted contacts and the threshold of the user. Approvals of
+	// removed contacts no longer count.
+	SaveSettings(ctx context.Context, userID uint, threshold int, contactIDs []uint) error
+	// Settings returns the threshold and the trusted contacts of the user, a zero threshold when
+	// recovery isn't set up.
+	Settings(ctx context.Context, userID uint) (*domain.RecoverySettings, error)
+
+	CreateRequest(ctx context.Context, request *domain.RecoveryRequest, tokenHash string) (*domain.RecoveryRequest, error)
+	// ByToken returns the request of a token, it returns sql.ErrNoRows when there is none.
+	ByToken(ctx context.Context, tokenHash string) (*domain.RecoveryRequest, error)
+	// Pending returns the requests of the user that weren't completed or cancelled and are
+	// unexpired at now.
+	Pending(ctx context.Context, userID uint, now time.Time) ([]*domain.RecoveryRequest, error)
+	// ForContact returns the pending requests of the accounts the contact is trusted by.
+	ForContact(ctx context.Context, contactID uint, now time.Time) ([]*domain.RecoveryRequest, error)
+	// Approve records the approval of a pending request by a current trusted contact of its
+	// account, it returns sql.ErrNoRows for any other request.
+	Approve(ctx context.Context, uuid string, contactID uint, now time.Time) (*domain.RecoveryRequest, error)
+	// Complete marks the request as done and cancels the other pending requests of the user, it
+	// returns sql.ErrNoRows when it was completed or cancelled already.
+	Complete(ctx context.Context, request *domain.RecoveryRequest, now time.Time) error
+	// Cancel cancels the pending requests of the user, it returns sql.ErrNoRows without one.
+	Cancel(ctx context.Context, userID uint, now time.Time) error
+}
+
+type recoveryRepo struct {
+	DB db.DB
+}
+
+func NewRecoveryRepo(db db.DB) *recoveryRepo {
+	return &recoveryRepo{
+		DB: db,
+	}
+}
+
+var _ RecoveryRepo = (*recoveryRepo)(nil)
+
+// recoveryRequestColumns counts the approvals of current contacts only, $1 is the user the
+// requests are read for, so contacts see whether they approved already.
+const recoveryRequestColumns = `recovery_requests.id, recovery_requests.uuid, recovery_requests.user_id,
+	users.uuid AS user_uuid,
+	COALESCE(NULLIF(TRIM(CONCAT_WS(' ', users.first_name, users.last_name)), ''), users.username, users.email, '') AS user_name,
+	(SELECT COUNT(*) FROM recovery_approvals
+		JOIN trusted_contacts
+			ON trusted_contacts.user_id = recovery_requests.user_id
+			AND trusted_contacts.contact_id = recovery_approvals.contact_id
+		WHERE recovery_approvals.request_id = recovery_requests.id) AS approvals,
+	COALESCE((SELECT threshold FROM recovery_settings WHERE recovery_settings.user_id = recovery_requests.user_id), 0) AS threshold,
+	EXISTS (SELECT 1 FROM recovery_approvals
+		WHERE recovery_approvals.request_id = recovery_requests.id
+			AND recovery_approvals.contact_id = $1) AS approved,
+	recovery_requests.ready_at, recovery_requests.expires_at, recovery_requests.completed_at,
+	recovery_requests.cancelled_at, recovery_requests.created_at`
+
+func (r *recoveryRepo) SaveSettings(ctx context.Context, userID uint, threshold int, contactIDs []uint) error {
+	return r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM trusted_contacts WHERE user_id = $1`, userID); err != nil {
+			return err
+		}
+		if len(contactIDs) == 0 {
+			_, err := tx.Exec(ctx, `DELETE FROM recovery_settings WHERE user_id = $1`, userID)
+			return err
+		}
+
+		query := `
+			INSERT INTO recovery_settings (user_id, threshold)
+			VALUES ($1, $2)
+			ON CONFLICT (user_id) DO UPDATE SET threshold = EXCLUDED.threshold`
+		if _, err := tx.Exec(ctx, query, userID, threshold); err != nil {
+			return err
+		}
+
+		for _, contactID := range contactIDs {
+			query = `INSERT INTO trusted_contacts (user_id, contact_id) VALUES ($1, $2)`
+			if _, err := tx.Exec(ctx, query, userID, contactID); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *recoveryRepo) Settings(ctx context.Context, userID uint) (*domain.RecoverySettings, error) {
+	settings := &domain.RecoverySettings{Contacts: []*domain.TrustedContact{}}
+	err := r.DB.Get(ctx, &settings.Threshold, `SELECT threshold FROM recovery_settings WHERE user_id = $1`, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	query := `
+		SELECT users.id AS user_id, users.uuid, users.email, users.first_name, users.last_name, trusted_contacts.created_at
+			FROM trusted_contacts
+		JOIN users
+			ON users.id = trusted_contacts.contact_id
+		WHERE trusted_contacts.user_id = $1
+			AND users.deleted_at IS NULL
+		ORDER BY trusted_contacts.created_at, users.id`
+
+	var contactEntities []*entity.TrustedContact
+	if err = r.DB.Select(ctx, &contactEntities, query, userID); err != nil {
+		return nil, err
+	}
+	for _, contactEntity := range contactEntities {
+		settings.Contacts = append(settings.Contacts, contactEntity.ToDomain())
+	}
+
+	return settings, nil
+}
+
+func (r *recoveryRepo) CreateRequest(
+	ctx context.Context, request *domain.RecoveryRequest, tokenHash string,
+) (*domain.RecoveryRequest, error) {
+	query := `
+		WITH created AS (
+			INSERT INTO recovery_requests (uuid, user_id, token_hash, ready_at, expires_at)
+			VALUES ($2, $3, $4, $5, $6)
+			RETURNING *
+		)
+		SELECT ` + recoveryRequestColumns + `
+			FROM created AS recovery_requests
+		JOIN users
+			ON users.id = recovery_requests.user_id`
+
+	var requestEntity entity.RecoveryRequest
+	err := r.DB.Get(ctx, &requestEntity, query,
+		request.UserID,
+		request.UUID,
+		request.UserID,
+		tokenHash,
+		request.ReadyAt.UTC(),
+		request.ExpiresAt.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return requestEntity.ToDomain(), nil
+}
+
+func (r *recoveryRepo) ByToken(ctx context.Context, tokenHash string) (*domain.RecoveryRequest, error) {
+	query := `
+		SELECT ` + recoveryRequestColumns + `
+			FROM recovery_requests
+		JOIN users
+			ON users.id = recovery_requests.user_id
+		WHERE recovery_requests.token_hash = $2`
+
+	var requestEntity entity.RecoveryRequest
+	if err := r.DB.Get(ctx, &requestEntity, query, 0, tokenHash); err != nil {
+		return nil, err
+	}
+
+	return requestEntity.ToDomain(), nil
+}
+
+func (r *recoveryRepo) Pending(ctx context.Context, userID uint, now time.Time) ([]*domain.RecoveryRequest, error) {
+	query := `
+		SELECT ` + recoveryRequestColumns + `
+			FROM recovery_requests
+		JOIN users
+			ON users.id = recovery_requests.user_id
+		WHERE recovery_requests.user_id = $1
+			AND recovery_requests.completed_at IS NULL
+			AND recovery_requests.cancelled_at IS NULL
+			AND recovery_requests.expires_at > $2
+		ORDER BY recovery_requests.created_at DESC, recovery_requests.id DESC`
+
+	return r.selectRequests(ctx, query, userID, now.UTC())
+}
+
+func (r *recoveryRepo) ForContact(ctx context.Context, contactID uint, now time.Time) ([]*domain.RecoveryRequest, error) {
+	query := `
+		SELECT ` + recoveryRequestColumns + `
+			FROM recovery_requests
+		JOIN users
+			ON users.id = recovery_requests.user_id
+		JOIN trusted_contacts
+			ON trusted_contacts.user_id = recovery_requests.user_id
+			AND trusted_contacts.contact_id = $1
+		WHERE recovery_requests.completed_at IS NULL
+			AND recovery_requests.cancelled_at IS NULL
+			AND recovery_requests.expires_at > $2
+		ORDER BY recovery_requests.created_at DESC, recovery_requests.id DESC`
+
+	return r.selectRequests(ctx, query, contactID, now.UTC())
+}
+
+func (r *recoveryRepo) selectRequests(ctx context.Context, query string, args ...interface{}) ([]*domain.RecoveryRequest, error) {
+	var requestEntities []*entity.RecoveryRequest
+	if err := r.DB.Select(ctx, &requestEntities, query, args...); err != nil {
+		return nil, err
+	}
+
+	requests := make([]*domain.RecoveryRequest, 0, len(requestEntities))
+	for _, requestEntity := range requestEntities {
+		requests = append(requests, requestEntity.ToDomain())
+	}
+
+	return requests, nil
+}
+
+func (r *recoveryRepo) Approve(ctx context.Context, uuid string, contactID uint, now time.Time) (*domain.RecoveryRequest, error) {
+	var requestEntity entity.RecoveryRequest
+	err := r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		query := `
+			INSERT INTO recovery_approvals (request_id, contact_id)
+			SELECT recovery_requests.id, trusted_contacts.contact_id
+				FROM recovery_requests
+			JOIN trusted_contacts
+				ON trusted_contacts.user_id = recovery_requests.user_id
+				AND trusted_contacts.contact_id = $1
+			WHERE recovery_requests.uuid = $2
+				AND recovery_requests.completed_at IS NULL
+				AND recovery_requests.cancelled_at IS NULL
+				AND recovery_requests.expires_at > $3
+			ON CONFLICT (request_id, contact_id) DO NOTHING`
+		if _, err := tx.Exec(ctx, query, contactID, uuid, now.UTC()); err != nil {
+			return err
+		}
+
+		query = `
+			SELECT ` + recoveryRequestColumns + `
+				FROM recovery_requests
+			JOIN users
+				ON users.id = recovery_requests.user_id
+			WHERE recovery_requests.uuid = $2
+				AND EXISTS (
+					SELECT 1
+						FROM recovery_approvals
+					WHERE recovery_approvals.request_id = recovery_requests.id
+						AND recovery_approvals.contact_id = $1
+				)`
+
+		return tx.Get(ctx, &requestEntity, query, contactID, uuid)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return requestEntity.ToDomain(), nil
+}
+
+func (r *recoveryRepo) Complete(ctx context.Context, request *domain.RecoveryRequest, now time.Time) error {
+	return r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		query := `
+			UPDATE recovery_requests SET completed_at = $1
+			WHERE id = $2
+				AND completed_at IS NULL
+				AND cancelled_at IS NULL
+			RETURNING id`
+
+		var id uint
+		if err := tx.Get(ctx, &id, query, now.UTC(), request.ID); err != nil {
+			return err
+		}
+
+		query = `
+			UPDATE recovery_requests SET cancelled_at = $1
+			WHERE user_id = $2
+				AND completed_at IS NULL
+				AND cancelled_at IS NULL`
+		_, err := tx.Exec(ctx, query, now.UTC(), request.UserID)
+
+		return err
+	})
+}
+
+func (r *recoveryRepo) Cancel(ctx context.Context, userID uint, now time.Time) error {
+	query := `
+		WITH cancelled AS (
+			UPDATE recovery_requests SET cancelled_at = $1
+			WHERE user_id = $2
+				AND completed_at IS NULL
+				AND cancelled_at IS NULL
+				AND expires_at > $1
+			RETURNING id
+		)
+		SELECT COUNT(*) FROM cancelled`
+
+	var cancelled int
+	if err := r.DB.Get(ctx, &cancelled, query, now.UTC(), userID); err != nil {
+		return err
+	}
+	if cancelled == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -15,6 +15,8 @@ type RefreshTokenRepo interface {
 	CreateRefreshToken(ctx context.Context, refreshToken string, userID uint) error
 	DeleteRefreshToken(ctx context.Context, userID uint) error
 
+	// ByRefreshToken locks the token until the transaction of ctx ends, concurrent refreshes
+	// with the same token wait and then find it deleted.
 	ByRefreshToken(ctx context.Context, userID uint, refreshToken string) (*domain.RefreshToken, error)
 }
 
@@ -70,7 +72,8 @@ func (r *refreshTokenRepo) ByRefreshToken(ctx context.Context, userID uint, refr
 		FROM refresh_tokens 
 	WHERE refresh_tokens.token = $1 
 		AND refresh_tokens.user_id = $2 
-		AND refresh_tokens.deleted_at IS NULL`
+		AND refresh_tokens.deleted_at IS NULL
+	FOR UPDATE`
 
 	var refreshTokenEntity entity.RefreshToken
 	err := r.DB.Get(ctx, &refreshTokenEntity, query, refreshToken, userID)
@@ -0,0 +1,134 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+)
+
+type RollupRepo interface {
+	// Refresh recomputes the rollups of the days from the day of since on from the todos.
+	Refresh(ctx context.Context, since time.Time) error
+	// Totals sums the rollups of the scope before the day of before.
+	Totals(ctx context.Context, scope *domain.RollupScope, before time.Time) (*domain.TodoRollup, error)
+	// Daily sums the rollups of the scope per day, for the days from the day of from up to the day
+	// of to, oldest first. Days without todo changes are left out.
+	Daily(ctx context.Context, scope *domain.RollupScope, from time.Time, to time.Time) ([]*domain.TodoRollup, error)
+}
+
+type rollupRepo struct {
+	DB db.DB
+}
+
+func NewRollupRepo(db db.DB) *rollupRepo {
+	return &rollupRepo{
+		DB: db,
+	}
+}
+
+var _ RollupRepo = (*rollupRepo)(nil)
+
+func (r *rollupRepo) Refresh(ctx context.Context, since time.Time) error {
+	since = since.UTC()
+
+	return r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		query := `DELETE FROM todo_rollups WHERE day >= $1::date`
+		if _, err := tx.Exec(ctx, query, since); err != nil {
+			return err
+		}
+
+		// a todo created before since can still have been completed or deleted after it
+		query = `
+			INSERT INTO todo_rollups (user_id, list_id, day, created, completed, deleted)
+			SELECT user_id, list_id, day, SUM(created), SUM(completed), SUM(deleted)
+				FROM (
+					SELECT user_id, COALESCE(list_id, 0) AS list_id, (created_at AT TIME ZONE 'UTC')::date AS day,
+						1 AS created, 0 AS completed, 0 AS deleted
+						FROM todos
+					WHERE created_at >= $1::date
+					UNION ALL
+					SELECT user_id, COALESCE(list_id, 0), (completed_at AT TIME ZONE 'UTC')::date, 0, 1, 0
+						FROM todos
+					WHERE completed_at >= $1::date
+					UNION ALL
+					SELECT user_id, COALESCE(list_id, 0), (deleted_at AT TIME ZONE 'UTC')::date, 0, 0, 1
+						FROM todos
+					WHERE deleted_at >= $1::date AND completed_at IS NULL
+				) AS events
+			GROUP BY user_id, list_id, day`
+		_, err := tx.Exec(ctx, query, since)
+
+		return err
+	})
+}
+
+func (r *rollupRepo) Totals(ctx context.Context, scope *domain.RollupScope, before time.Time) (*domain.TodoRollup, error) {
+	where, args := rollupScope(scope)
+	args = append(args, before.UTC())
+
+	query := fmt.Sprintf(`
+		SELECT COALESCE(SUM(created), 0) AS created,
+			COALESCE(SUM(completed), 0) AS completed,
+			COALESCE(SUM(deleted), 0) AS deleted
+			FROM todo_rollups
+		WHERE %s
+			AND day < $%d::date`, where, len(args))
+
+	var rollupEntity entity.TodoRollup
+	err := r.DB.Get_RO(ctx, &rollupEntity, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return rollupEntity.ToDomain(), nil
+}
+
+func (r *rollupRepo) Daily(
+	ctx context.Context,
+	scope *domain.RollupScope,
+	from time.Time,
+	to time.Time,
+) ([]*domain.TodoRollup, error) {
+	where, args := rollupScope(scope)
+	args = append(args, from.UTC(), to.UTC())
+
+	query := fmt.Sprintf(`
+		SELECT day, SUM(created) AS created, SUM(completed) AS completed, SUM(deleted) AS deleted
+			FROM todo_rollups
+		WHERE %s
+			AND day >= $%d::date
+			AND day <= $%d::date
+		GROUP BY day
+		ORDER BY day`, where, len(args)-1, len(args))
+
+	var rollupEntities []*entity.TodoRollup
+	err := r.DB.Select_RO(ctx, &rollupEntities, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	rollups := make([]*domain.TodoRollup, 0, len(rollupEntities))
+	for _, rollupEntity := range rollupEntities {
+		rollups = append(rollups, rollupEntity.ToDomain())
+	}
+
+	return rollups, nil
+}
+
+// rollupScope returns the condition selecting the rollups of the scope with its arguments.
+func rollupScope(scope *domain.RollupScope) (string, []interface{}) {
+	if scope.ListID != 0 {
+		return `list_id = $1`, []interface{}{scope.ListID}
+	}
+
+	args := make([]interface{}, 0, len(scope.UserIDs))
+	for _, userID := range scope.UserIDs {
+		args = append(args, userID)
+	}
+
+	return fmt.Sprintf(`user_id IN (%s)`, placeholders(1, len(args))), args
+}
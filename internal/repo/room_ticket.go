@@ -0,0 +1,57 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+)
+
+type RoomTicketRepo interface {
+	Create(ctx context.Context, ticket *domain.RoomTicket, tokenHash string) error
+	// Use marks an unused and unexpired ticket of the list as used and returns it, sql.ErrNoRows
+	// when there is none.
+	Use(ctx context.Context, tokenHash string, listUUID string) (*domain.RoomTicket, error)
+}
+
+type roomTicketRepo struct {
+	DB db.DB
+}
+
+func NewRoomTicketRepo(db db.DB) *roomTicketRepo {
+	return &roomTicketRepo{
+		DB: db,
+	}
+}
+
+var _ RoomTicketRepo = (*roomTicketRepo)(nil)
+
+func (r *roomTicketRepo) Create(ctx context.Context, ticket *domain.RoomTicket, tokenHash string) error {
+	query := `
+		INSERT INTO room_tickets (user_id, list_uuid, token_hash, session_expires_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)`
+	_, err := r.DB.Exec(ctx, query, ticket.UserID, ticket.ListUUID, tokenHash, ticket.SessionExpiresAt.UTC(), ticket.ExpiresAt.UTC())
+
+	return err
+}
+
+func (r *roomTicketRepo) Use(ctx context.Context, tokenHash string, listUUID string) (*domain.RoomTicket, error) {
+	now := time.Now().UTC()
+	query := `
+		UPDATE room_tickets SET used_at = $1
+		FROM users
+		WHERE users.id = room_tickets.user_id
+			AND room_tickets.token_hash = $2 AND room_tickets.list_uuid = $3
+			AND room_tickets.used_at IS NULL AND room_tickets.expires_at > $1
+		RETURNING room_tickets.user_id, users.uuid AS user_uuid, COALESCE(users.email, '') AS email, room_tickets.list_uuid,
+			room_tickets.session_expires_at, room_tickets.expires_at, room_tickets.created_at`
+
+	var ticketEntity entity.RoomTicket
+	if err := r.DB.Get(ctx, &ticketEntity, query, now, tokenHash, listUUID); err != nil {
+		return nil, err
+	}
+
+	return ticketEntity.ToDomain(), nil
+}
@@ -0,0 +1,174 @@
+package repo
+
+import (
+	"context"
+	"strings"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+)
+
+type RuleRepo interface {
+	// Create adds the rule after the other rules of the user.
+	Create(ctx context.Context, rule *domain.Rule) (*domain.Rule, error)
+	// Update replaces the definition of a rule, keeping its position. It fails with
+	// sql.ErrNoRows when the user has no such rule.
+	Update(ctx context.Context, rule *domain.Rule) (*domain.Rule, error)
+	Delete(ctx context.Context, userID uint, uuid string) error
+	// Reorder sets the positions of the rules of the user to the order of ids.
+	Reorder(ctx context.Context, userID uint, ids []uint) error
+
+	ByUUID(ctx context.Context, userID uint, uuid string) (*domain.Rule, error)
+	// All returns the rules of the user in evaluation order.
+	All(ctx context.Context, userID uint) ([]*domain.Rule, error)
+}
+
+type ruleRepo struct {
+	DB db.DB
+}
+
+func NewRuleRepo(db db.DB) *ruleRepo {
+	return &ruleRepo{
+		DB: db,
+	}
+}
+
+var _ RuleRepo = (*ruleRepo)(nil)
+
+const ruleColumns = `id, uuid, user_id, name, position, field, operator, value, source, channel, condition, tags, priority, list_uuid,
+	computed_title, computed_description, computed_priority, computed_tags, stop, created_at, updated_at`
+
+func (r *ruleRepo) Create(ctx context.Context, rule *domain.Rule) (*domain.Rule, error) {
+	query := `
+		INSERT INTO todo_rules (uuid, user_id, name, position, field, operator, value, source, channel, condition, tags, priority,
+			list_uuid, computed_title, computed_description, computed_priority, computed_tags, stop)
+		VALUES ($1, $2, $3, (SELECT COALESCE(MAX(position), 0) + 1 FROM todo_rules WHERE user_id = $2),
+			$4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		RETURNING ` + ruleColumns
+
+	var ruleEntity entity.Rule
+	err := r.DB.Get(ctx, &ruleEntity, query,
+		rule.UUID,
+		rule.UserID,
+		rule.Name,
+		string(rule.Field),
+		string(rule.Operator),
+		rule.Value,
+		string(rule.Source),
+		rule.Channel,
+		rule.Condition,
+		strings.Join(rule.Tags, "\n"),
+		nullPriority(rule.Priority),
+		nullString(rule.ListUUID),
+		rule.Computed.Title,
+		rule.Computed.Description,
+		rule.Computed.Priority,
+		rule.Computed.Tags,
+		rule.Stop,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return ruleEntity.ToDomain(), nil
+}
+
+func (r *ruleRepo) Update(ctx context.Context, rule *domain.Rule) (*domain.Rule, error) {
+	query := `
+		UPDATE todo_rules
+			SET name = $1, field = $2, operator = $3, value = $4, source = $5, channel = $6, condition = $7, tags = $8,
+				priority = $9, list_uuid = $10, computed_title = $11, computed_description = $12, computed_priority = $13,
+				computed_tags = $14, stop = $15
+		WHERE uuid = $16
+			AND user_id = $17
+		RETURNING ` + ruleColumns
+
+	var ruleEntity entity.Rule
+	err := r.DB.Get(ctx, &ruleEntity, query,
+		rule.Name,
+		string(rule.Field),
+		string(rule.Operator),
+		rule.Value,
+		string(rule.Source),
+		rule.Channel,
+		rule.Condition,
+		strings.Join(rule.Tags, "\n"),
+		nullPriority(rule.Priority),
+		nullString(rule.ListUUID),
+		rule.Computed.Title,
+		rule.Computed.Description,
+		rule.Computed.Priority,
+		rule.Computed.Tags,
+		rule.Stop,
+		rule.UUID,
+		rule.UserID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return ruleEntity.ToDomain(), nil
+}
+
+func (r *ruleRepo) Delete(ctx context.Context, userID uint, uuid string) error {
+	query := `DELETE FROM todo_rules WHERE uuid = $1 AND user_id = $2`
+	_, err := r.DB.Exec(ctx, query, uuid, userID)
+
+	return err
+}
+
+func (r *ruleRepo) Reorder(ctx context.Context, userID uint, ids []uint) error {
+	return r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		query := `UPDATE todo_rules SET position = $1 WHERE id = $2 AND user_id = $3`
+		for i, id := range ids {
+			if _, err := tx.Exec(ctx, query, i+1, id, userID); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *ruleRepo) ByUUID(ctx context.Context, userID uint, uuid string) (*domain.Rule, error) {
+	query := `SELECT ` + ruleColumns + ` FROM todo_rules WHERE uuid = $1 AND user_id = $2`
+
+	var ruleEntity entity.Rule
+	err := r.DB.Get_RO(ctx, &ruleEntity, query, uuid, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return ruleEntity.ToDomain(), nil
+}
+
+func (r *ruleRepo) All(ctx context.Context, userID uint) ([]*domain.Rule, error) {
+	query := `
+		SELECT ` + ruleColumns + `
+			FROM todo_rules
+		WHERE user_id = $1
+		ORDER BY position, id`
+
+	var ruleEntities []*entity.Rule
+	err := r.DB.Select_RO(ctx, &ruleEntities, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]*domain.Rule, 0, len(ruleEntities))
+	for _, ruleEntity := range ruleEntities {
+		rules = append(rules, ruleEntity.ToDomain())
+	}
+
+	return rules, nil
+}
+
+// nullPriority stores a missing priority as NULL.
+func nullPriority(priority *domain.Priority) interface{} {
+	if priority == nil {
+		return nil
+	}
+
+	return int(*priority)
+}
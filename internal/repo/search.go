@@ -0,0 +1,113 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+)
+
+type SearchRepo interface {
+	SearchTodos(ctx context.Context, userID uint, query string, limit int) ([]*domain.TodoSearchResult, error)
+	// SemanticSearchTodos returns the todos whose embeddings by model are closest to vector.
+	SemanticSearchTodos(ctx context.Context, userID uint, model string, vector []float32, limit int) ([]*domain.TodoSearchResult, error)
+}
+
+type searchRepo struct {
+	DB db.DB
+}
+
+func NewSearchRepo(db db.DB) *searchRepo {
+	return &searchRepo{
+		DB: db,
+	}
+}
+
+var _ SearchRepo = (*searchRepo)(nil)
+
+const searchHeadlineOptions = `'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5'`
+
+func (r *searchRepo) SearchTodos(ctx context.Context, userID uint, query string, limit int) ([]*domain.TodoSearchResult, error) {
+	// a todo also matches by the filename and the extracted text of its best matching
+	// attachment, the snippet is from the attachment when the todo itself doesn't match
+	searchQuery := `
+		SELECT ` + todoSelectColumns + `,
+			ts_rank(todos.search_vector, search_query) + coalesce(attachment.rank, 0) AS rank,
+			CASE WHEN todos.search_vector @@ search_query THEN
+				ts_headline('english', todos.title || ' ' || todos.description, search_query, ` + searchHeadlineOptions + `)
+			ELSE
+				ts_headline('english', attachment.filename || ' ' || coalesce(attachment.content_text, ''), search_query, ` + searchHeadlineOptions + `)
+			END AS snippet,
+			CASE WHEN todos.search_vector @@ search_query THEN NULL ELSE attachment.uuid END AS attachment_uuid
+			FROM todos
+		CROSS JOIN websearch_to_tsquery('english', $2) search_query
+		LEFT JOIN LATERAL (
+			SELECT attachments.uuid, attachments.filename, attachments.content_text,
+				ts_rank(attachments.search_vector, search_query) AS rank
+				FROM attachments
+			WHERE attachments.todo_id = todos.id
+				AND attachments.deleted_at IS NULL
+				AND attachments.search_vector @@ search_query
+			ORDER BY rank DESC
+			LIMIT 1
+		) attachment ON true
+		WHERE todos.user_id = $1
+			AND todos.deleted_at IS NULL
+			AND (todos.search_vector @@ search_query OR attachment.uuid IS NOT NULL)
+		ORDER BY rank DESC, todos.id DESC
+		LIMIT $3`
+
+	var resultEntities []*entity.TodoSearchResult
+	err := r.DB.Select_RO(ctx, &resultEntities, searchQuery, userID, query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.searchResults(ctx, resultEntities)
+}
+
+func (r *searchRepo) SemanticSearchTodos(
+	ctx context.Context, userID uint, model string, vector []float32, limit int,
+) ([]*domain.TodoSearchResult, error) {
+	// the rank is the cosine similarity, the vectors of a user are few enough to compare them
+	// all without an index
+	searchQuery := `
+		SELECT ` + todoSelectColumns + `,
+			1 - (todo_embeddings.embedding <=> $3::vector) AS rank,
+			'' AS snippet,
+			NULL AS attachment_uuid
+			FROM todos
+			JOIN todo_embeddings ON todo_embeddings.todo_id = todos.id
+		WHERE todos.user_id = $1
+			AND todos.deleted_at IS NULL
+			AND todo_embeddings.user_id = $1
+			AND todo_embeddings.model = $2
+			AND todo_embeddings.embedding IS NOT NULL
+		ORDER BY todo_embeddings.embedding <=> $3::vector, todos.id DESC
+		LIMIT $4`
+
+	var resultEntities []*entity.TodoSearchResult
+	err := r.DB.Select_RO(ctx, &resultEntities, searchQuery, userID, model, vectorLiteral(vector), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.searchResults(ctx, resultEntities)
+}
+
+func (r *searchRepo) searchResults(ctx context.Context, resultEntities []*entity.TodoSearchResult) ([]*domain.TodoSearchResult, error) {
+	results := make([]*domain.TodoSearchResult, 0, len(resultEntities))
+	todos := make([]*domain.Todo, 0, len(resultEntities))
+	for _, resultEntity := range resultEntities {
+		result := resultEntity.ToDomain()
+		results = append(results, result)
+		todos = append(todos, result.Todo)
+	}
+
+	if err := attachTodoTags(ctx, r.DB, todos); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -0,0 +1,63 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+)
+
+type SecureNoteRepo interface {
+	// Save sets or replaces the sealed note of a todo.
+	Save(ctx context.Context, note *domain.SecureNote) (*domain.SecureNote, error)
+	// Delete removes the note of a todo, it returns sql.ErrNoRows when the todo has none.
+	Delete(ctx context.Context, todoID uint) error
+	ByTodo(ctx context.Context, todoID uint) (*domain.SecureNote, error)
+}
+
+type secureNoteRepo struct {
+	DB db.DB
+}
+
+func NewSecureNoteRepo(db db.DB) *secureNoteRepo {
+	return &secureNoteRepo{
+		DB: db,
+	}
+}
+
+var _ SecureNoteRepo = (*secureNoteRepo)(nil)
+
+const secureNoteColumns = `todo_id, sealed, updated_by, created_at, updated_at`
+
+func (r *secureNoteRepo) Save(ctx context.Context, note *domain.SecureNote) (*domain.SecureNote, error) {
+	query := `
+		INSERT INTO todo_secure_notes (todo_id, sealed, updated_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (todo_id) DO UPDATE
+			SET sealed = EXCLUDED.sealed, updated_by = EXCLUDED.updated_by
+		RETURNING ` + secureNoteColumns
+
+	var noteEntity entity.SecureNote
+	if err := r.DB.Get(ctx, &noteEntity, query, note.TodoID, note.Sealed, note.UpdatedBy); err != nil {
+		return nil, err
+	}
+
+	return noteEntity.ToDomain(), nil
+}
+
+func (r *secureNoteRepo) Delete(ctx context.Context, todoID uint) error {
+	var deleted uint
+	return r.DB.Get(ctx, &deleted, `DELETE FROM todo_secure_notes WHERE todo_id = $1 RETURNING todo_id`, todoID)
+}
+
+func (r *secureNoteRepo) ByTodo(ctx context.Context, todoID uint) (*domain.SecureNote, error) {
+	query := `SELECT ` + secureNoteColumns + ` FROM todo_secure_notes WHERE todo_id = $1`
+
+	var noteEntity entity.SecureNote
+	if err := r.DB.Get(ctx, &noteEntity, query, todoID); err != nil {
+		return nil, err
+	}
+
+	return noteEntity.ToDomain(), nil
+}
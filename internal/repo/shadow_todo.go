@@ -0,0 +1,299 @@
+package repo
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/pagination"
+	"github.com/rs/zerolog/log"
+)
+
+// shadowTodoRepo dual-writes to a candidate TodoRepo while a storage implementation is being
+// migrated. The primary repo stays the source of truth, shadow failures and read mismatches
+// are only logged so the candidate can be validated against production traffic.
+type shadowTodoRepo struct {
+	primary      TodoRepo
+	shadow       TodoRepo
+	compareReads bool
+}
+
+func NewShadowTodoRepo(primary TodoRepo, shadow TodoRepo, compareReads bool) *shadowTodoRepo {
+	return &shadowTodoRepo{
+		primary:      primary,
+		shadow:       shadow,
+		compareReads: compareReads,
+	}
+}
+
+var _ TodoRepo = (*shadowTodoRepo)(nil)
+
+func (r *shadowTodoRepo) Create(ctx context.Context, uuid string, userID uint, todo *domain.TodoCreate, tagIDs []uint) (*domain.Todo, error) {
+	created, err := r.primary.Create(ctx, uuid, userID, todo, tagIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = r.shadow.Create(ctx, uuid, userID, todo, tagIDs); err != nil {
+		log.Err(err).Str("method", "Create").Str("uuid", uuid).Msg("shadow todo repo write failed")
+	}
+
+	return created, nil
+}
+
+func (r *shadowTodoRepo) Update(ctx context.Context, todo *domain.Todo) error {
+	if err := r.primary.Update(ctx, todo); err != nil {
+		return err
+	}
+
+	// the shadow assigns its own IDs, so resolve the shadow copy by UUID first
+	shadowTodo, err := r.shadow.ByUUID(ctx, todo.UserID, todo.UUID)
+	if err != nil {
+		log.Err(err).Str("method", "Update").Str("uuid", todo.UUID).Msg("shadow todo repo write failed")
+		return nil
+	}
+
+	updated := *todo
+	updated.ID = shadowTodo.ID
+	// the shadow keeps its own versions, the primary already settled conflicts
+	updated.Version = shadowTodo.Version
+	if err = r.shadow.Update(ctx, &updated); err != nil {
+		log.Err(err).Str("method", "Update").Str("uuid", todo.UUID).Msg("shadow todo repo write failed")
+	}
+
+	return nil
+}
+
+func (r *shadowTodoRepo) Move(ctx context.Context, todo *domain.Todo, move *domain.TodoMove, tagIDs []uint) error {
+	previousOwnerID := todo.UserID
+	if err := r.primary.Move(ctx, todo, move, tagIDs); err != nil {
+		return err
+	}
+
+	shadowTodo, err := r.shadow.ByUUID(ctx, previousOwnerID, todo.UUID)
+	if err != nil {
+		log.Err(err).Str("method", "Move").Str("uuid", todo.UUID).Msg("shadow todo repo write failed")
+		return nil
+	}
+
+	// the shadow keeps its own IDs and versions, so the copy is moved as found
+	if err = r.shadow.Move(ctx, shadowTodo, move, tagIDs); err != nil {
+		log.Err(err).Str("method", "Move").Str("uuid", todo.UUID).Msg("shadow todo repo write failed")
+	}
+
+	return nil
+}
+
+func (r *shadowTodoRepo) Rank(ctx context.Context, todo *domain.Todo, rank string) error {
+	if err := r.primary.Rank(ctx, todo, rank); err != nil {
+		return err
+	}
+
+	shadowTodo, err := r.shadow.ByUUID(ctx, todo.UserID, todo.UUID)
+	if err != nil {
+		log.Err(err).Str("method", "Rank").Str("uuid", todo.UUID).Msg("shadow todo repo write failed")
+		return nil
+	}
+
+	if err = r.shadow.Rank(ctx, shadowTodo, rank); err != nil {
+		log.Err(err).Str("method", "Rank").Str("uuid", todo.UUID).Msg("shadow todo repo write failed")
+	}
+
+	return nil
+}
+
+func (r *shadowTodoRepo) Rerank(ctx context.Context, userID uint) error {
+	if err := r.primary.Rerank(ctx, userID); err != nil {
+		return err
+	}
+
+	// the shadow holds the same ranks, so spreading them out keeps them in step
+	if err := r.shadow.Rerank(ctx, userID); err != nil {
+		log.Err(err).Str("method", "Rerank").Uint("user_id", userID).Msg("shadow todo repo write failed")
+	}
+
+	return nil
+}
+
+// NextRank only reads the primary, the ranks it hands out are written to both.
+func (r *shadowTodoRepo) NextRank(ctx context.Context, userID uint, rank string, excludeID uint) (string, error) {
+	return r.primary.NextRank(ctx, userID, rank, excludeID)
+}
+
+func (r *shadowTodoRepo) LastRank(ctx context.Context, userID uint) (string, error) {
+	return r.primary.LastRank(ctx, userID)
+}
+
+func (r *shadowTodoRepo) Delete(ctx context.Context, userID uint, uuid string) error {
+	if err := r.primary.Delete(ctx, userID, uuid); err != nil {
+		return err
+	}
+
+	if err := r.shadow.Delete(ctx, userID, uuid); err != nil {
+		log.Err(err).Str("method", "Delete").Str("uuid", uuid).Msg("shadow todo repo write failed")
+	}
+
+	return nil
+}
+
+func (r *shadowTodoRepo) ByUUID(ctx context.Context, userID uint, uuid string) (*domain.Todo, error) {
+	todo, err := r.primary.ByUUID(ctx, userID, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.compareReads {
+		shadowTodo, shadowErr := r.shadow.ByUUID(ctx, userID, uuid)
+		if shadowErr != nil {
+			log.Err(shadowErr).Str("method", "ByUUID").Str("uuid", uuid).Msg("shadow todo repo read failed")
+		} else {
+			logTodoMismatch("ByUUID", todo, shadowTodo)
+		}
+	}
+
+	return todo, nil
+}
+
+func (r *shadowTodoRepo) ByUUIDShared(ctx context.Context, memberID uint, uuid string) (*domain.Todo, error) {
+	todo, err := r.primary.ByUUIDShared(ctx, memberID, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.compareReads {
+		shadowTodo, shadowErr := r.shadow.ByUUIDShared(ctx, memberID, uuid)
+		if shadowErr != nil {
+			log.Err(shadowErr).Str("method", "ByUUIDShared").Str("uuid", uuid).Msg("shadow todo repo read failed")
+		} else {
+			logTodoMismatch("ByUUIDShared", todo, shadowTodo)
+		}
+	}
+
+	return todo, nil
+}
+
+func (r *shadowTodoRepo) All(ctx context.Context, userID uint, filter *domain.TodoFilter, page *pagination.Page) ([]*domain.Todo, *pagination.Cursor, error) {
+	todos, next, err := r.primary.All(ctx, userID, filter, page)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// cursors hold primary IDs, so only the first page can be compared against the shadow
+	if r.compareReads && (page == nil || page.Cursor == nil) {
+		shadowTodos, _, shadowErr := r.shadow.All(ctx, userID, filter, page)
+		if shadowErr != nil {
+			log.Err(shadowErr).Str("method", "All").Msg("shadow todo repo read failed")
+			return todos, next, nil
+		}
+
+		if len(todos) != len(shadowTodos) {
+			log.Warn().
+				Str("method", "All").
+				Uint("user_id", userID).
+				Int("primary_count", len(todos)).
+				Int("shadow_count", len(shadowTodos)).
+				Msg("shadow todo repo mismatch")
+		}
+
+		for i := range min(len(todos), len(shadowTodos)) {
+			logTodoMismatch("All", todos[i], shadowTodos[i])
+		}
+	}
+
+	return todos, next, nil
+}
+
+func (r *shadowTodoRepo) Archive(ctx context.Context, now time.Time, limit int) (int, error) {
+	archived, err := r.primary.Archive(ctx, now, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	// the shadow applies the same retention to its own copies
+	if _, err = r.shadow.Archive(ctx, now, limit); err != nil {
+		log.Err(err).Str("method", "Archive").Msg("shadow todo repo write failed")
+	}
+
+	return archived, nil
+}
+
+func (r *shadowTodoRepo) Remind(ctx context.Context, now time.Time, until time.Time, limit int) ([]*domain.Todo, error) {
+	todos, err := r.primary.Remind(ctx, now, until, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// only the primary claims reminders, the shadow just keeps reminded_at in step
+	if _, err = r.shadow.Remind(ctx, now, until, limit); err != nil {
+		log.Err(err).Str("method", "Remind").Msg("shadow todo repo write failed")
+	}
+
+	return todos, nil
+}
+
+func (r *shadowTodoRepo) Overdue(ctx context.Context, since time.Time, now time.Time, limit int) ([]*domain.Todo, error) {
+	todos, err := r.primary.Overdue(ctx, since, now, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = r.shadow.Overdue(ctx, since, now, limit); err != nil {
+		log.Err(err).Str("method", "Overdue").Msg("shadow todo repo write failed")
+	}
+
+	return todos, nil
+}
+
+// logTodoMismatch logs the fields that differ between the primary and shadow todo. IDs and
+// timestamps managed by the database are expected to differ and are not compared.
+func logTodoMismatch(method string, primary *domain.Todo, shadow *domain.Todo) {
+	var fields []string
+	if primary.UUID != shadow.UUID {
+		fields = append(fields, "uuid")
+	}
+	if primary.Title != shadow.Title {
+		fields = append(fields, "title")
+	}
+	if primary.Description != shadow.Description {
+		fields = append(fields, "description")
+	}
+	if primary.Priority != shadow.Priority {
+		fields = append(fields, "priority")
+	}
+	if !primary.DueDate.Equal(shadow.DueDate) {
+		fields = append(fields, "due_date")
+	}
+	if primary.Estimate != shadow.Estimate {
+		fields = append(fields, "estimate")
+	}
+	if primary.Postponed != shadow.Postponed {
+		fields = append(fields, "postponed")
+	}
+	if primary.Completed() != shadow.Completed() {
+		fields = append(fields, "completed")
+	}
+	if primary.Rank != shadow.Rank {
+		fields = append(fields, "rank")
+	}
+	if !slices.Equal(tagNames(primary.Tags), tagNames(shadow.Tags)) {
+		fields = append(fields, "tags")
+	}
+
+	if len(fields) > 0 {
+		log.Warn().
+			Str("method", method).
+			Str("uuid", primary.UUID).
+			Strs("fields", fields).
+			Msg("shadow todo repo mismatch")
+	}
+}
+
+func tagNames(tags []*domain.Tag) []string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+
+	return names
+}
@@ -0,0 +1,90 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+)
+
+type SigningKeyRepo interface {
+	// Rotate adds key as the active key and retires the previous active key at retiresAt, unless
+	// the active key was created after rotateBefore. It reports whether the key was added, so
+	// concurrent instances rotate once.
+	Rotate(ctx context.Context, key *domain.SigningKey, rotateBefore time.Time, retiresAt time.Time) (bool, error)
+
+	// Verifiable returns the keys that aren't retired at now, newest first. It reads the primary,
+	// a rotation must be visible right away.
+	Verifiable(ctx context.Context, now time.Time) ([]*domain.SigningKey, error)
+}
+
+type signingKeyRepo struct {
+	DB db.DB
+}
+
+func NewSigningKeyRepo(db db.DB) *signingKeyRepo {
+	return &signingKeyRepo{
+		DB: db,
+	}
+}
+
+var _ SigningKeyRepo = (*signingKeyRepo)(nil)
+
+// signingKeyLock is the advisory lock serializing rotations.
+const signingKeyLock = 0x6a776b73
+
+const signingKeyColumns = `id, kid, algorithm, private_key, public_key, created_at, retires_at`
+
+func (r *signingKeyRepo) Rotate(ctx context.Context, key *domain.SigningKey, rotateBefore time.Time, retiresAt time.Time) (bool, error) {
+	rotated := false
+	err := r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, signingKeyLock); err != nil {
+			return err
+		}
+
+		var activeCreatedAt time.Time
+		err := tx.Get(ctx, &activeCreatedAt, `SELECT created_at FROM signing_keys WHERE retires_at IS NULL ORDER BY created_at DESC LIMIT 1`)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		if err == nil && activeCreatedAt.After(rotateBefore) {
+			return nil
+		}
+
+		if _, err = tx.Exec(ctx, `UPDATE signing_keys SET retires_at = $1 WHERE retires_at IS NULL`, retiresAt.UTC()); err != nil {
+			return err
+		}
+
+		query := `INSERT INTO signing_keys (kid, algorithm, private_key, public_key) VALUES ($1, $2, $3, $4)`
+		if _, err = tx.Exec(ctx, query, key.KID, key.Algorithm, key.PrivateKey, key.PublicKey); err != nil {
+			return err
+		}
+
+		rotated = true
+		return nil
+	})
+
+	return rotated, err
+}
+
+func (r *signingKeyRepo) Verifiable(ctx context.Context, now time.Time) ([]*domain.SigningKey, error) {
+	query := `SELECT ` + signingKeyColumns + ` FROM signing_keys
+		WHERE retires_at IS NULL OR retires_at > $1
+		ORDER BY created_at DESC, id DESC`
+
+	var keyEntities []*entity.SigningKey
+	if err := r.DB.Select(ctx, &keyEntities, query, now.UTC()); err != nil {
+		return nil, err
+	}
+
+	keys := make([]*domain.SigningKey, 0, len(keyEntities))
+	for _, keyEntity := range keyEntities {
+		keys = append(keys, keyEntity.ToDomain())
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,272 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+)
+
+type SlackRepo interface {
+	// Upsert stores the connection of the user, the linked Slack user is kept when it already exists.
+	Upsert(ctx context.Context, conn *domain.SlackConnection) (*domain.SlackConnection, error)
+	ByUserID(ctx context.Context, userID uint) (*domain.SlackConnection, error)
+	// ByUserIDs returns the connections of whichever of the users have one.
+	ByUserIDs(ctx context.Context, userIDs []uint) ([]*domain.SlackConnection, error)
+	BySlackUser(ctx context.Context, teamID, slackUserID string) (*domain.SlackConnection, error)
+	// Link binds the Slack user to the connection waiting with the link code and uses the code up.
+	// It fails with sql.ErrNoRows when no connection has the code or it expired.
+	Link(ctx context.Context, codeHash string, now time.Time, teamID, slackUserID string) (*domain.SlackConnection, error)
+	// Delete fails with sql.ErrNoRows when the user has no connection.
+	Delete(ctx context.Context, userID uint) error
+
+	CreateMessages(ctx context.Context, messages []*domain.SlackMessage) error
+	// DueMessages returns the pending messages, oldest first.
+	DueMessages(ctx context.Context, now time.Time, limit int) ([]*domain.SlackMessage, error)
+	// ClaimMessage counts an attempt and moves the next attempt of a due message forward. It fails
+	// with sql.ErrNoRows when another instance already claimed the attempt.
+	ClaimMessage(ctx context.Context, message *domain.SlackMessage, nextAttemptAt time.Time) error
+	// UpdateMessage stores the outcome of an attempt.
+	UpdateMessage(ctx context.Context, message *domain.SlackMessage) error
+}
+
+type slackRepo struct {
+	DB db.DB
+}
+
+func NewSlackRepo(db db.DB) *slackRepo {
+	return &slackRepo{
+		DB: db,
+	}
+}
+
+var _ SlackRepo = (*slackRepo)(nil)
+
+const (
+	slackConnectionColumns = `id, user_id, webhook_url, bot_token, channel, events, slack_team_id, slack_user_id,
+		link_code_hash, link_code_expires_at, created_at, updated_at`
+	// slackMessageColumns selects a message joined with its connection.
+	slackMessageColumns = `slack_messages.id, slack_messages.uuid, slack_messages.connection_id,
+		slack_connections.user_id AS connection_user_id, slack_connections.webhook_url AS connection_webhook_url,
+		slack_connections.bot_token AS connection_bot_token, slack_connections.channel AS connection_channel,
+		slack_messages.event, slack_messages.text, slack_messages.status, slack_messages.attempts,
+		slack_messages.next_attempt_at, slack_messages.last_error, slack_messages.sent_at,
+		slack_messages.created_at, slack_messages.updated_at`
+)
+
+func (r *slackRepo) Upsert(ctx context.Context, conn *domain.SlackConnection) (*domain.SlackConnection, error) {
+	query := `
+		INSERT INTO slack_connections (user_id, webhook_url, bot_token, channel, events, link_code_hash, link_code_expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (user_id) DO UPDATE
+			SET webhook_url = EXCLUDED.webhook_url, bot_token = EXCLUDED.bot_token, channel = EXCLUDED.channel,
+				events = EXCLUDED.events, link_code_hash = EXCLUDED.link_code_hash,
+				link_code_expires_at = EXCLUDED.link_code_expires_at
+		RETURNING ` + slackConnectionColumns
+
+	events := make([]string, 0, len(conn.Events))
+	for _, event := range conn.Events {
+		events = append(events, string(event))
+	}
+
+	var linkCodeHash interface{}
+	if conn.LinkCodeHash != "" {
+		linkCodeHash = conn.LinkCodeHash
+	}
+
+	var connEntity entity.SlackConnection
+	err := r.DB.Get(ctx, &connEntity, query,
+		conn.UserID,
+		conn.WebhookURL,
+		conn.BotToken,
+		conn.Channel,
+		strings.Join(events, ","),
+		linkCodeHash,
+		nullTime(conn.LinkCodeExpiresAt),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return connEntity.ToDomain(), nil
+}
+
+func (r *slackRepo) ByUserID(ctx context.Context, userID uint) (*domain.SlackConnection, error) {
+	query := `SELECT ` + slackConnectionColumns + ` FROM slack_connections WHERE user_id = $1`
+
+	var connEntity entity.SlackConnection
+	err := r.DB.Get_RO(ctx, &connEntity, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return connEntity.ToDomain(), nil
+}
+
+func (r *slackRepo) ByUserIDs(ctx context.Context, userIDs []uint) ([]*domain.SlackConnection, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM slack_connections WHERE user_id IN (%s)`,
+		slackConnectionColumns, placeholders(1, len(userIDs)))
+
+	args := make([]interface{}, 0, len(userIDs))
+	for _, userID := range userIDs {
+		args = append(args, userID)
+	}
+
+	var connEntities []*entity.SlackConnection
+	err := r.DB.Select_RO(ctx, &connEntities, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	conns := make([]*domain.SlackConnection, 0, len(connEntities))
+	for _, connEntity := range connEntities {
+		conns = append(conns, connEntity.ToDomain())
+	}
+
+	return conns, nil
+}
+
+func (r *slackRepo) BySlackUser(ctx context.Context, teamID, slackUserID string) (*domain.SlackConnection, error) {
+	query := `SELECT ` + slackConnectionColumns + ` FROM slack_connections WHERE slack_team_id = $1 AND slack_user_id = $2`
+
+	var connEntity entity.SlackConnection
+	err := r.DB.Get_RO(ctx, &connEntity, query, teamID, slackUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return connEntity.ToDomain(), nil
+}
+
+func (r *slackRepo) Link(
+	ctx context.Context,
+	codeHash string,
+	now time.Time,
+	teamID, slackUserID string,
+) (*domain.SlackConnection, error) {
+	var connEntity entity.SlackConnection
+	err := r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		// a Slack user drives a single account, linking again moves it over
+		query := `
+			UPDATE slack_connections SET slack_team_id = NULL, slack_user_id = NULL
+			WHERE slack_team_id = $1 AND slack_user_id = $2`
+		_, err := tx.Exec(ctx, query, teamID, slackUserID)
+		if err != nil {
+			return err
+		}
+
+		query = `
+			UPDATE slack_connections
+				SET slack_team_id = $1, slack_user_id = $2, link_code_hash = NULL, link_code_expires_at = NULL
+			WHERE link_code_hash = $3
+				AND link_code_expires_at > $4
+			RETURNING ` + slackConnectionColumns
+
+		return tx.Get(ctx, &connEntity, query, teamID, slackUserID, codeHash, now.UTC())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return connEntity.ToDomain(), nil
+}
+
+func (r *slackRepo) Delete(ctx context.Context, userID uint) error {
+	query := `DELETE FROM slack_connections WHERE user_id = $1 RETURNING id`
+
+	var id uint
+	return r.DB.Get(ctx, &id, query, userID)
+}
+
+func (r *slackRepo) CreateMessages(ctx context.Context, messages []*domain.SlackMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	return r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		query := `
+			INSERT INTO slack_messages (uuid, connection_id, event, text, next_attempt_at)
+			VALUES ($1, $2, $3, $4, $5)`
+		for _, message := range messages {
+			_, err := tx.Exec(ctx, query,
+				message.UUID,
+				message.Connection.ID,
+				string(message.Event),
+				message.Text,
+				message.NextAttemptAt.UTC(),
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *slackRepo) DueMessages(ctx context.Context, now time.Time, limit int) ([]*domain.SlackMessage, error) {
+	query := `
+		SELECT ` + slackMessageColumns + `
+			FROM slack_messages
+		JOIN slack_connections
+			ON slack_connections.id = slack_messages.connection_id
+		WHERE slack_messages.status = 'pending'
+			AND slack_messages.next_attempt_at <= $1
+		ORDER BY slack_messages.next_attempt_at
+		LIMIT $2`
+
+	var messageEntities []*entity.SlackMessage
+	err := r.DB.Select(ctx, &messageEntities, query, now.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]*domain.SlackMessage, 0, len(messageEntities))
+	for _, messageEntity := range messageEntities {
+		messages = append(messages, messageEntity.ToDomain())
+	}
+
+	return messages, nil
+}
+
+func (r *slackRepo) ClaimMessage(ctx context.Context, message *domain.SlackMessage, nextAttemptAt time.Time) error {
+	query := `
+		UPDATE slack_messages SET next_attempt_at = $1, attempts = attempts + 1
+		WHERE id = $2
+			AND next_attempt_at = $3
+			AND status = 'pending'
+		RETURNING id`
+
+	var id uint
+	return r.DB.Get(ctx, &id, query, nextAttemptAt.UTC(), message.ID, message.NextAttemptAt.UTC())
+}
+
+func (r *slackRepo) UpdateMessage(ctx context.Context, message *domain.SlackMessage) error {
+	query := `
+		UPDATE slack_messages
+			SET status = $1, next_attempt_at = $2, last_error = $3, sent_at = $4
+		WHERE id = $5`
+
+	var lastError interface{}
+	if message.LastError != "" {
+		lastError = message.LastError
+	}
+
+	_, err := r.DB.Exec(ctx, query,
+		string(message.Status),
+		message.NextAttemptAt.UTC(),
+		lastError,
+		nullTime(message.SentAt),
+		message.ID,
+	)
+
+	return err
+}
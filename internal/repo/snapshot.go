@@ -0,0 +1,125 @@
+package repo
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+)
+
+type SnapshotScheduleRepo interface {
+	Create(ctx context.Context, schedule *domain.ListSnapshotSchedule) (*domain.ListSnapshotSchedule, error)
+	Delete(ctx context.Context, listID uint, uuid string) error
+	// Claim moves the next run of a due schedule forward. It fails with sql.ErrNoRows when
+	// another instance already claimed the run.
+	Claim(ctx context.Context, schedule *domain.ListSnapshotSchedule, nextRunAt time.Time) error
+	MarkSent(ctx context.Context, id uint, sentAt time.Time) error
+
+	ByList(ctx context.Context, listID uint) ([]*domain.ListSnapshotSchedule, error)
+	Due(ctx context.Context, now time.Time, limit int) ([]*domain.ListSnapshotSchedule, error)
+}
+
+type snapshotScheduleRepo struct {
+	DB db.DB
+}
+
+func NewSnapshotScheduleRepo(db db.DB) *snapshotScheduleRepo {
+	return &snapshotScheduleRepo{
+		DB: db,
+	}
+}
+
+var _ SnapshotScheduleRepo = (*snapshotScheduleRepo)(nil)
+
+const (
+	snapshotScheduleColumns = `id, uuid, list_id, user_id, recipients, frequency, next_run_at, last_sent_at,
+		created_at, updated_at, deleted_at`
+)
+
+func (r *snapshotScheduleRepo) Create(ctx context.Context, schedule *domain.ListSnapshotSchedule) (*domain.ListSnapshotSchedule, error) {
+	query := `
+		INSERT INTO list_snapshot_schedules (uuid, list_id, user_id, recipients, frequency, next_run_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + snapshotScheduleColumns
+
+	var scheduleEntity entity.ListSnapshotSchedule
+	err := r.DB.Get(ctx, &scheduleEntity, query,
+		schedule.UUID,
+		schedule.ListID,
+		schedule.UserID,
+		strings.Join(schedule.Recipients, ","),
+		string(schedule.Frequency),
+		schedule.NextRunAt.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return scheduleEntity.ToDomain(), nil
+}
+
+func (r *snapshotScheduleRepo) Delete(ctx context.Context, listID uint, uuid string) error {
+	query := `UPDATE list_snapshot_schedules SET deleted_at = $1 WHERE uuid = $2 AND list_id = $3 AND deleted_at IS NULL`
+	_, err := r.DB.Exec(ctx, query, time.Now().UTC(), uuid, listID)
+
+	return err
+}
+
+func (r *snapshotScheduleRepo) Claim(ctx context.Context, schedule *domain.ListSnapshotSchedule, nextRunAt time.Time) error {
+	query := `
+		UPDATE list_snapshot_schedules SET next_run_at = $1
+		WHERE id = $2
+			AND next_run_at = $3
+			AND deleted_at IS NULL
+		RETURNING id`
+
+	var id uint
+	return r.DB.Get(ctx, &id, query, nextRunAt.UTC(), schedule.ID, schedule.NextRunAt.UTC())
+}
+
+func (r *snapshotScheduleRepo) MarkSent(ctx context.Context, id uint, sentAt time.Time) error {
+	_, err := r.DB.Exec(ctx, `UPDATE list_snapshot_schedules SET last_sent_at = $1 WHERE id = $2`, sentAt.UTC(), id)
+
+	return err
+}
+
+func (r *snapshotScheduleRepo) ByList(ctx context.Context, listID uint) ([]*domain.ListSnapshotSchedule, error) {
+	query := `
+		SELECT ` + snapshotScheduleColumns + `
+			FROM list_snapshot_schedules
+		WHERE list_id = $1
+			AND deleted_at IS NULL
+		ORDER BY created_at`
+
+	return r.selectSchedules(ctx, query, listID)
+}
+
+func (r *snapshotScheduleRepo) Due(ctx context.Context, now time.Time, limit int) ([]*domain.ListSnapshotSchedule, error) {
+	query := `
+		SELECT ` + snapshotScheduleColumns + `
+			FROM list_snapshot_schedules
+		WHERE next_run_at <= $1
+			AND deleted_at IS NULL
+		ORDER BY next_run_at
+		LIMIT $2`
+
+	return r.selectSchedules(ctx, query, now.UTC(), limit)
+}
+
+func (r *snapshotScheduleRepo) selectSchedules(ctx context.Context, query string, args ...interface{}) ([]*domain.ListSnapshotSchedule, error) {
+	var scheduleEntities []*entity.ListSnapshotSchedule
+	err := r.DB.Select(ctx, &scheduleEntities, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules := make([]*domain.ListSnapshotSchedule, 0, len(scheduleEntities))
+	for _, scheduleEntity := range scheduleEntities {
+		schedules = append(schedules, scheduleEntity.ToDomain())
+	}
+
+	return schedules, nil
+}
@@ -0,0 +1,247 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+	"github.com/meowmix1337/the_recipe_book/internal/pagination"
+)
+
+type TagRepo interface {
+	Create(ctx context.Context, uuid string, userID uint, name string, color string) (*domain.Tag, error)
+	// Ensure creates the tags that do not exist yet and returns all of them.
+	Ensure(ctx context.Context, userID uint, tags []*domain.Tag) ([]*domain.Tag, error)
+	AssignToTodo(ctx context.Context, todoID uint, tagIDs []uint) error
+	RemoveFromTodo(ctx context.Context, todoID uint, tagID uint) error
+
+	// SetColor sets the color of a tag of the user, an empty color removes it. It fails with
+	// sql.ErrNoRows when the user has no such tag.
+	SetColor(ctx context.Context, userID uint, uuid string, color string) (*domain.Tag, error)
+	// Rename renames a tag of the user, the todos it is assigned to get a new version. It fails
+	// with sql.ErrNoRows when the user has no such tag.
+	Rename(ctx context.Context, userID uint, uuid string, name string) (*domain.Tag, error)
+	// Merge moves the todos of the source tag to the target and deletes the source in a single
+	// transaction, todos that had both keep one assignment. It returns how many todos were moved
+	// and how many already had the target.
+	Merge(ctx context.Context, sourceID uint, targetID uint) (int, int, error)
+
+	ByUUID(ctx context.Context, userID uint, uuid string) (*domain.Tag, error)
+	ByName(ctx context.Context, userID uint, name string) (*domain.Tag, error)
+	// All returns the tags of the user, only those in group unless it is empty.
+	All(ctx context.Context, userID uint, group string, page *pagination.Page) ([]*domain.Tag, *pagination.Cursor, error)
+}
+
+type tagRepo struct {
+	DB db.DB
+}
+
+func NewTagRepo(db db.DB) *tagRepo {
+	return &tagRepo{
+		DB: db,
+	}
+}
+
+var _ TagRepo = (*tagRepo)(nil)
+
+const (
+	tagColumns = `tags.id, tags.uuid, tags.user_id, tags.name, tags.color, tags.created_at, tags.updated_at`
+
+	tagSort = "name"
+)
+
+func (r *tagRepo) Create(ctx context.Context, uuid string, userID uint, name string, color string) (*domain.Tag, error) {
+	query := `INSERT INTO tags (uuid, user_id, name, color) VALUES ($1, $2, $3, $4) RETURNING ` + tagColumns
+
+	var tagEntity entity.Tag
+	err := r.DB.Get(ctx, &tagEntity, query, uuid, userID, name, nullString(color))
+	if err != nil {
+		return nil, err
+	}
+
+	return tagEntity.ToDomain(), nil
+}
+
+func (r *tagRepo) Ensure(ctx context.Context, userID uint, tags []*domain.Tag) ([]*domain.Tag, error) {
+	if len(tags) == 0 {
+		return []*domain.Tag{}, nil
+	}
+
+	var tagEntities []*entity.Tag
+	err := r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		insert := `INSERT INTO tags (uuid, user_id, name) VALUES ($1, $2, $3) ON CONFLICT (user_id, name) DO NOTHING`
+
+		args := []interface{}{userID}
+		for _, tag := range tags {
+			if _, err := tx.Exec(ctx, insert, tag.UUID, userID, tag.Name); err != nil {
+				return err
+			}
+			args = append(args, tag.Name)
+		}
+
+		query := fmt.Sprintf(`SELECT %s FROM tags WHERE tags.user_id = $1 AND tags.name IN (%s) ORDER BY tags.name`,
+			tagColumns, placeholders(2, len(tags)))
+
+		return tx.Select(ctx, &tagEntities, query, args...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ensured := make([]*domain.Tag, 0, len(tagEntities))
+	for _, tagEntity := range tagEntities {
+		ensured = append(ensured, tagEntity.ToDomain())
+	}
+
+	return ensured, nil
+}
+
+func (r *tagRepo) AssignToTodo(ctx context.Context, todoID uint, tagIDs []uint) error {
+	return r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		return assignTags(ctx, tx, todoID, tagIDs)
+	})
+}
+
+func (r *tagRepo) RemoveFromTodo(ctx context.Context, todoID uint, tagID uint) error {
+	_, err := r.DB.Exec(ctx, `DELETE FROM todo_tags WHERE todo_id = $1 AND tag_id = $2`, todoID, tagID)
+
+	return err
+}
+
+func (r *tagRepo) SetColor(ctx context.Context, userID uint, uuid string, color string) (*domain.Tag, error) {
+	query := `UPDATE tags SET color = $1 WHERE tags.uuid = $2 AND tags.user_id = $3 RETURNING ` + tagColumns
+
+	var tagEntity entity.Tag
+	err := r.DB.Get(ctx, &tagEntity, query, nullString(color), uuid, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return tagEntity.ToDomain(), nil
+}
+
+func (r *tagRepo) Rename(ctx context.Context, userID uint, uuid string, name string) (*domain.Tag, error) {
+	var tagEntity entity.Tag
+	err := r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		query := `UPDATE tags SET name = $1 WHERE tags.uuid = $2 AND tags.user_id = $3 RETURNING ` + tagColumns
+		if err := tx.Get(ctx, &tagEntity, query, name, uuid, userID); err != nil {
+			return err
+		}
+
+		// the todos read differently now, so clients holding them must refetch
+		query = `UPDATE todos SET version = version + 1 WHERE id IN (SELECT todo_id FROM todo_tags WHERE tag_id = $1)`
+		_, err := tx.Exec(ctx, query, tagEntity.ID)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tagEntity.ToDomain(), nil
+}
+
+func (r *tagRepo) Merge(ctx context.Context, sourceID uint, targetID uint) (int, int, error) {
+	var retagged, total int64
+	err := r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		query := `UPDATE todos SET version = version + 1 WHERE id IN (SELECT todo_id FROM todo_tags WHERE tag_id = $1)`
+		result, err := tx.Exec(ctx, query, sourceID)
+		if err != nil {
+			return err
+		}
+		if total, err = result.RowsAffected(); err != nil {
+			return err
+		}
+
+		// todos tagged with both already have the target, the conflict skips them
+		query = `
+			INSERT INTO todo_tags (todo_id, tag_id, created_at)
+			SELECT todo_id, $1, created_at FROM todo_tags WHERE tag_id = $2
+			ON CONFLICT DO NOTHING`
+		result, err = tx.Exec(ctx, query, targetID, sourceID)
+		if err != nil {
+			return err
+		}
+		if retagged, err = result.RowsAffected(); err != nil {
+			return err
+		}
+
+		// deleting the tag cascades to its assignments
+		_, err = tx.Exec(ctx, `DELETE FROM tags WHERE id = $1`, sourceID)
+
+		return err
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return int(retagged), int(total - retagged), nil
+}
+
+func (r *tagRepo) ByUUID(ctx context.Context, userID uint, uuid string) (*domain.Tag, error) {
+	query := `SELECT ` + tagColumns + ` FROM tags WHERE tags.user_id = $1 AND tags.uuid = $2`
+
+	var tagEntity entity.Tag
+	err := r.DB.Get_RO(ctx, &tagEntity, query, userID, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	return tagEntity.ToDomain(), nil
+}
+
+func (r *tagRepo) ByName(ctx context.Context, userID uint, name string) (*domain.Tag, error) {
+	query := `SELECT ` + tagColumns + ` FROM tags WHERE tags.user_id = $1 AND tags.name = $2`
+
+	var tagEntity entity.Tag
+	err := r.DB.Get_RO(ctx, &tagEntity, query, userID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return tagEntity.ToDomain(), nil
+}
+
+func (r *tagRepo) All(ctx context.Context, userID uint, group string, page *pagination.Page) ([]*domain.Tag, *pagination.Cursor, error) {
+	query := `SELECT ` + tagColumns + ` FROM tags WHERE tags.user_id = $1`
+	args := []interface{}{userID}
+
+	if group != "" {
+		args = append(args, group+domain.TagGroupSeparator)
+		query += fmt.Sprintf(` AND left(tags.name, length($%d)) = $%d`, len(args), len(args))
+	}
+
+	cursor, err := page.After(tagSort, 1)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cursor != nil {
+		args = append(args, cursor.Values[0], cursor.ID)
+		query += fmt.Sprintf(` AND (tags.name, tags.id) > ($%d, $%d)`, len(args)-1, len(args))
+	}
+
+	query += ` ORDER BY tags.name, tags.id`
+	if page != nil {
+		args = append(args, page.FetchLimit())
+		query += fmt.Sprintf(` LIMIT $%d`, len(args))
+	}
+
+	var tagEntities []*entity.Tag
+	err = r.DB.Select_RO(ctx, &tagEntities, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tags := make([]*domain.Tag, 0, len(tagEntities))
+	for _, tagEntity := range tagEntities {
+		tags = append(tags, tagEntity.ToDomain())
+	}
+
+	tags, next := pagination.Trim(tags, page, func(tag *domain.Tag) *pagination.Cursor {
+		return &pagination.Cursor{Values: []string{tag.Name}, ID: tag.ID, Sort: tagSort}
+	})
+
+	return tags, next, nil
+}
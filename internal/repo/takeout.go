@@ -0,0 +1,163 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+)
+
+type TakeoutRepo interface {
+	// Create queues a takeout. It fails with sql.ErrNoRows while another takeout of the user is
+	// unfinished.
+	Create(ctx context.Context, takeout *domain.Takeout) (*domain.Takeout, error)
+	ByUUID(ctx context.Context, userID uint, uuid string) (*domain.Takeout, error)
+
+	// Unfinished returns the pending takeouts and the running takeouts claimed before staleBefore.
+	Unfinished(ctx context.Context, staleBefore time.Time, limit int) ([]*domain.Takeout, error)
+	// Claim marks the takeout as running. It fails with sql.ErrNoRows when another worker claimed
+	// it since it was read.
+	Claim(ctx context.Context, takeout *domain.Takeout, startedAt time.Time) error
+	// Finish stores the outcome of a takeout.
+	Finish(ctx context.Context, takeout *domain.Takeout) error
+
+	// Expired returns the takeouts whose archive expired at now and is still stored.
+	Expired(ctx context.Context, now time.Time, limit int) ([]*domain.Takeout, error)
+	// Forget clears the archive of a takeout once it was deleted.
+	Forget(ctx context.Context, id uint) error
+	// StorageKeys returns the archives of the takeouts of a user that are still stored.
+	StorageKeys(ctx context.Context, userID uint) ([]string, error)
+}
+
+type takeoutRepo struct {
+	DB db.DB
+}
+
+func NewTakeoutRepo(db db.DB) *takeoutRepo {
+	return &takeoutRepo{
+		DB: db,
+	}
+}
+
+var _ TakeoutRepo = (*takeoutRepo)(nil)
+
+const takeoutColumns = `id, uuid, user_id, status, storage_key, size, error, started_at, finished_at,
+	expires_at, created_at, updated_at`
+
+func (r *takeoutRepo) Create(ctx context.Context, takeout *domain.Takeout) (*domain.Takeout, error) {
+	query := `
+		INSERT INTO takeouts (uuid, user_id)
+		SELECT $1, $2
+		WHERE NOT EXISTS (
+			SELECT 1 FROM takeouts WHERE user_id = $2 AND status IN ('pending', 'running')
+		)
+		RETURNING ` + takeoutColumns
+
+	var takeoutEntity entity.Takeout
+	if err := r.DB.Get(ctx, &takeoutEntity, query, takeout.UUID, takeout.UserID); err != nil {
+		return nil, err
+	}
+
+	return takeoutEntity.ToDomain(), nil
+}
+
+func (r *takeoutRepo) ByUUID(ctx context.Context, userID uint, uuid string) (*domain.Takeout, error) {
+	query := `SELECT ` + takeoutColumns + ` FROM takeouts WHERE uuid = $1 AND user_id = $2`
+
+	var takeoutEntity entity.Takeout
+	if err := r.DB.Get(ctx, &takeoutEntity, query, uuid, userID); err != nil {
+		return nil, err
+	}
+
+	return takeoutEntity.ToDomain(), nil
+}
+
+func (r *takeoutRepo) Unfinished(ctx context.Context, staleBefore time.Time, limit int) ([]*domain.Takeout, error) {
+	query := `
+		SELECT ` + takeoutColumns + `
+			FROM takeouts
+		WHERE status = 'pending'
+			OR (status = 'running' AND started_at < $1)
+		ORDER BY id
+		LIMIT $2`
+
+	var takeoutEntities []*entity.Takeout
+	if err := r.DB.Select(ctx, &takeoutEntities, query, staleBefore.UTC(), limit); err != nil {
+		return nil, err
+	}
+
+	return takeouts(takeoutEntities), nil
+}
+
+func (r *takeoutRepo) Claim(ctx context.Context, takeout *domain.Takeout, startedAt time.Time) error {
+	query := `
+		UPDATE takeouts SET status = 'running', started_at = $1
+		WHERE id = $2
+			AND status = $3
+			AND started_at IS NOT DISTINCT FROM $4
+		RETURNING id`
+
+	var id uint
+	return r.DB.Get(ctx, &id, query, startedAt.UTC(), takeout.ID, string(takeout.Status), nullTime(takeout.StartedAt))
+}
+
+func (r *takeoutRepo) Finish(ctx context.Context, takeout *domain.Takeout) error {
+	query := `
+		UPDATE takeouts
+			SET status = $1, storage_key = $2, size = $3, error = $4, finished_at = $5, expires_at = $6
+		WHERE id = $7`
+	_, err := r.DB.Exec(ctx, query,
+		string(takeout.Status),
+		takeout.StorageKey,
+		takeout.Size,
+		takeout.Error,
+		nullTime(takeout.FinishedAt),
+		nullTime(takeout.ExpiresAt),
+		takeout.ID,
+	)
+
+	return err
+}
+
+func (r *takeoutRepo) Expired(ctx context.Context, now time.Time, limit int) ([]*domain.Takeout, error) {
+	query := `
+		SELECT ` + takeoutColumns + `
+			FROM takeouts
+		WHERE storage_key <> ''
+			AND expires_at <= $1
+		ORDER BY expires_at
+		LIMIT $2`
+
+	var takeoutEntities []*entity.Takeout
+	if err := r.DB.Select(ctx, &takeoutEntities, query, now.UTC(), limit); err != nil {
+		return nil, err
+	}
+
+	return takeouts(takeoutEntities), nil
+}
+
+func (r *takeoutRepo) Forget(ctx context.Context, id uint) error {
+	_, err := r.DB.Exec(ctx, `UPDATE takeouts SET storage_key = '' WHERE id = $1`, id)
+
+	return err
+}
+
+func (r *takeoutRepo) StorageKeys(ctx context.Context, userID uint) ([]string, error) {
+	var keys []string
+	if err := r.DB.Select(ctx, &keys, `SELECT storage_key FROM takeouts WHERE user_id = $1 AND storage_key <> ''`, userID); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func takeouts(takeoutEntities []*entity.Takeout) []*domain.Takeout {
+	takeouts := make([]*domain.Takeout, 0, len(takeoutEntities))
+	for _, takeoutEntity := range takeoutEntities {
+		takeouts = append(takeouts, takeoutEntity.ToDomain())
+	}
+
+	return takeouts
+}
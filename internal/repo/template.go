@@ -0,0 +1,135 @@
+package repo
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+)
+
+type TemplateRepo interface {
+	Create(ctx context.Context, template *domain.TodoTemplate) (*domain.TodoTemplate, error)
+	// Update replaces a template. It fails with sql.ErrNoRows when the user has no such template.
+	Update(ctx context.Context, template *domain.TodoTemplate) (*domain.TodoTemplate, error)
+	Delete(ctx context.Context, userID uint, uuid string) error
+
+	ByUUID(ctx context.Context, userID uint, uuid string) (*domain.TodoTemplate, error)
+	// All returns the templates of the user by name.
+	All(ctx context.Context, userID uint) ([]*domain.TodoTemplate, error)
+}
+
+type templateRepo struct {
+	DB db.DB
+}
+
+func NewTemplateRepo(db db.DB) *templateRepo {
+	return &templateRepo{
+		DB: db,
+	}
+}
+
+var _ TemplateRepo = (*templateRepo)(nil)
+
+const templateColumns = `id, uuid, user_id, name, title, description, priority, estimate_minutes, tags, subtasks, due_in_days,
+	created_at, updated_at`
+
+func (r *templateRepo) Create(ctx context.Context, template *domain.TodoTemplate) (*domain.TodoTemplate, error) {
+	query := `
+		INSERT INTO todo_templates (uuid, user_id, name, title, description, priority, estimate_minutes, tags, subtasks, due_in_days)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING ` + templateColumns
+
+	var templateEntity entity.TodoTemplate
+	err := r.DB.Get(ctx, &templateEntity, query,
+		template.UUID,
+		template.UserID,
+		template.Name,
+		template.Title,
+		template.Description,
+		int(template.Priority),
+		int(template.Estimate/time.Minute),
+		strings.Join(template.Tags, "\n"),
+		strings.Join(template.Subtasks, "\n"),
+		nullDays(template.DueIn),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return templateEntity.ToDomain(), nil
+}
+
+func (r *templateRepo) Update(ctx context.Context, template *domain.TodoTemplate) (*domain.TodoTemplate, error) {
+	query := `
+		UPDATE todo_templates
+			SET name = $1, title = $2, description = $3, priority = $4, estimate_minutes = $5, tags = $6, subtasks = $7,
+				due_in_days = $8
+		WHERE uuid = $9
+			AND user_id = $10
+		RETURNING ` + templateColumns
+
+	var templateEntity entity.TodoTemplate
+	err := r.DB.Get(ctx, &templateEntity, query,
+		template.Name,
+		template.Title,
+		template.Description,
+		int(template.Priority),
+		int(template.Estimate/time.Minute),
+		strings.Join(template.Tags, "\n"),
+		strings.Join(template.Subtasks, "\n"),
+		nullDays(template.DueIn),
+		template.UUID,
+		template.UserID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return templateEntity.ToDomain(), nil
+}
+
+func (r *templateRepo) Delete(ctx context.Context, userID uint, uuid string) error {
+	query := `DELETE FROM todo_templates WHERE uuid = $1 AND user_id = $2`
+	_, err := r.DB.Exec(ctx, query, uuid, userID)
+
+	return err
+}
+
+func (r *templateRepo) ByUUID(ctx context.Context, userID uint, uuid string) (*domain.TodoTemplate, error) {
+	query := `SELECT ` + templateColumns + ` FROM todo_templates WHERE uuid = $1 AND user_id = $2`
+
+	var templateEntity entity.TodoTemplate
+	if err := r.DB.Get_RO(ctx, &templateEntity, query, uuid, userID); err != nil {
+		return nil, err
+	}
+
+	return templateEntity.ToDomain(), nil
+}
+
+func (r *templateRepo) All(ctx context.Context, userID uint) ([]*domain.TodoTemplate, error) {
+	query := `SELECT ` + templateColumns + ` FROM todo_templates WHERE user_id = $1 ORDER BY name, id`
+
+	var templateEntities []*entity.TodoTemplate
+	if err := r.DB.Select_RO(ctx, &templateEntities, query, userID); err != nil {
+		return nil, err
+	}
+
+	templates := make([]*domain.TodoTemplate, 0, len(templateEntities))
+	for _, templateEntity := range templateEntities {
+		templates = append(templates, templateEntity.ToDomain())
+	}
+
+	return templates, nil
+}
+
+// nullDays stores a missing duration as NULL and others in whole days.
+func nullDays(duration *time.Duration) interface{} {
+	if duration == nil {
+		return nil
+	}
+
+	return int(*duration / (24 * time.Hour))
+}
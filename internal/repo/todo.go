@@ -0,0 +1,778 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+	"github.com/meowmix1337/the_recipe_book/internal/pagination"
+)
+
+type TodoRepo interface {
+	Create(ctx context.Context, uuid string, userID uint, todo *domain.TodoCreate, tagIDs []uint) (*domain.Todo, error)
+	// Update only applies when the todo is still at todo.Version and increases the version, it
+	// fails with sql.ErrNoRows when the todo was changed or deleted since.
+	Update(ctx context.Context, todo *domain.Todo) error
+	Delete(ctx context.Context, userID uint, uuid string) error
+	// Move hands the todo over to move.OwnerID and move.ListID and replaces its tags with tagIDs,
+	// the tags of the previous owner don't apply anymore. Like Update it only applies at
+	// todo.Version. Comments, attachments and focus sessions stay with the todo.
+	Move(ctx context.Context, todo *domain.Todo, move *domain.TodoMove, tagIDs []uint) error
+	// Rank sets the rank of the todo without touching its version, ordering isn't an edit.
+	Rank(ctx context.Context, todo *domain.Todo, rank string) error
+	// Rerank spreads the ranks of the todos of the user out again in their current order.
+	Rerank(ctx context.Context, userID uint) error
+	// NextRank returns the lowest rank of the todos of the user above rank, leaving out the todo
+	// of excludeID, or an empty string when there is none.
+	NextRank(ctx context.Context, userID uint, rank string, excludeID uint) (string, error)
+	// LastRank returns the highest rank of the todos of the user, empty when none is ranked.
+	LastRank(ctx context.Context, userID uint) (string, error)
+
+	ByUUID(ctx context.Context, userID uint, uuid string) (*domain.Todo, error)
+	// ByUUIDShared returns a todo of a list that is shared with the member.
+	ByUUIDShared(ctx context.Context, memberID uint, uuid string) (*domain.Todo, error)
+	// All returns a page of todos and the cursor of the next page, a nil page returns every todo.
+	All(ctx context.Context, userID uint, filter *domain.TodoFilter, page *pagination.Page) ([]*domain.Todo, *pagination.Cursor, error)
+
+	// Archive stamps up to limit completed todos past the retention of their list as archived
+	// and returns how many it archived.
+	Archive(ctx context.Context, now time.Time, limit int) (int, error)
+	// Remind claims up to limit open todos due after now and until until that weren't reminded
+	// of yet and returns them, a todo is only returned again once its due date changes.
+	Remind(ctx context.Context, now time.Time, until time.Time, limit int) ([]*domain.Todo, error)
+	// Overdue claims up to limit open todos that became overdue after since and until now like
+	// Remind, every todo is returned once per due date.
+	Overdue(ctx context.Context, since time.Time, now time.Time, limit int) ([]*domain.Todo, error)
+}
+
+type todoRepo struct {
+	DB db.DB
+}
+
+func NewTodoRepo(db db.DB) *todoRepo {
+	return &todoRepo{
+		DB: db,
+	}
+}
+
+var _ TodoRepo = (*todoRepo)(nil)
+
+const (
+	todoColumns = `todos.id, todos.uuid, todos.user_id, todos.list_id, todos.title, todos.description, todos.priority,
+		todos.due_date, todos.estimate_minutes, todos.postponed_count, todos.version, todos.completed_at, todos.archived_at,
+		todos.ciphertext, todos.key_version, todos.status, todos.position, todos.rank, todos.created_at, todos.updated_at,
+		todos.deleted_at`
+	// todoSelectColumns also resolves the list UUID, the dead links and whether the todo has a
+	// secure note, RETURNING clauses use todoColumns.
+	todoSelectColumns = todoColumns + `, (SELECT lists.uuid FROM lists WHERE lists.id = todos.list_id) AS list_uuid,
+		(SELECT string_agg(todo_links.url, E'\n' ORDER BY todo_links.url) FROM todo_links
+			WHERE todo_links.todo_id = todos.id AND todo_links.dead_since IS NOT NULL) AS dead_links,
+		EXISTS (SELECT 1 FROM todo_secure_notes WHERE todo_secure_notes.todo_id = todos.id) AS has_secure_note`
+)
+
+//nolint:gochecknoglobals // lookup table
+var todoSortColumns = map[domain.TodoSortField]string{
+	domain.TodoSortPriority:  "todos.priority",
+	domain.TodoSortDueDate:   "todos.due_date",
+	domain.TodoSortTitle:     "todos.title",
+	domain.TodoSortCreatedAt: "todos.created_at",
+	domain.TodoSortUpdatedAt: "todos.updated_at",
+	domain.TodoSortRank:      "todos.rank",
+}
+
+// todoSort is the resolved ordering of a todo query, shared by the ORDER BY clause and the
+// keyset condition so both always agree.
+type todoSort struct {
+	fields     []domain.TodoSortField
+	descending bool
+}
+
+func newTodoSort(filter *domain.TodoFilter) *todoSort {
+	if filter == nil || len(filter.Sort) == 0 {
+		return &todoSort{
+			fields:     []domain.TodoSortField{domain.TodoSortCreatedAt},
+			descending: true,
+		}
+	}
+
+	sort := &todoSort{
+		descending: filter.Order == domain.SortOrderDesc,
+	}
+	for _, field := range filter.Sort {
+		if _, ok := todoSortColumns[field]; ok {
+			sort.fields = append(sort.fields, field)
+		}
+	}
+
+	return sort
+}
+
+// key identifies the ordering inside a cursor, e.g. "priority,due_date:desc".
+func (s *todoSort) key() string {
+	fields := make([]string, 0, len(s.fields))
+	for _, field := range s.fields {
+		fields = append(fields, string(field))
+	}
+
+	direction := string(domain.SortOrderAsc)
+	if s.descending {
+		direction = string(domain.SortOrderDesc)
+	}
+
+	return strings.Join(fields, ",") + ":" + direction
+}
+
+// expression returns the sort expression of a field. Todos without a due date or a rank always
+// go last, which is expressed with sentinels so the keyset comparison can treat it as a value.
+func (s *todoSort) expression(field domain.TodoSortField) string {
+	switch field {
+	case domain.TodoSortDueDate:
+		if s.descending {
+			return `COALESCE(todos.due_date, '-infinity'::timestamptz)`
+		}
+		return `COALESCE(todos.due_date, 'infinity'::timestamptz)`
+	case domain.TodoSortRank:
+		// ranks only use digits and lowercase letters, which all sort before "~"
+		if s.descending {
+			return `COALESCE(todos.rank, '')`
+		}
+		return `COALESCE(todos.rank, '~')`
+	default:
+		return todoSortColumns[field]
+	}
+}
+
+func (s *todoSort) orderBy() string {
+	direction := "ASC"
+	if s.descending {
+		direction = "DESC"
+	}
+
+	clauses := make([]string, 0, len(s.fields)+1)
+	for _, field := range s.fields {
+		clauses = append(clauses, s.expression(field)+" "+direction)
+	}
+	clauses = append(clauses, "todos.id "+direction)
+
+	return ` ORDER BY ` + strings.Join(clauses, ", ")
+}
+
+// after builds the keyset condition that continues right after the cursor.
+func (s *todoSort) after(cursor *pagination.Cursor, args []interface{}) (string, []interface{}) {
+	expressions := make([]string, 0, len(s.fields)+1)
+	for _, field := range s.fields {
+		expressions = append(expressions, s.expression(field))
+	}
+	expressions = append(expressions, "todos.id")
+
+	for _, value := range cursor.Values {
+		args = append(args, value)
+	}
+	args = append(args, cursor.ID)
+
+	operator := ">"
+	if s.descending {
+		operator = "<"
+	}
+
+	return fmt.Sprintf(` AND (%s) %s (%s)`,
+		strings.Join(expressions, ", "),
+		operator,
+		placeholders(len(args)-len(expressions)+1, len(expressions)),
+	), args
+}
+
+// cursor builds the cursor pointing right after the given todo.
+func (s *todoSort) cursor(todo *domain.Todo) *pagination.Cursor {
+	values := make([]string, 0, len(s.fields))
+	for _, field := range s.fields {
+		switch field {
+		case domain.TodoSortPriority:
+			values = append(values, strconv.Itoa(int(todo.Priority)))
+		case domain.TodoSortDueDate:
+			switch {
+			case !todo.DueDate.IsZero():
+				values = append(values, todo.DueDate.UTC().Format(time.RFC3339Nano))
+			case s.descending:
+				values = append(values, "-infinity")
+			default:
+				values = append(values, "infinity")
+			}
+		case domain.TodoSortTitle:
+			values = append(values, todo.Title)
+		case domain.TodoSortCreatedAt:
+			values = append(values, todo.CreatedAt.UTC().Format(time.RFC3339Nano))
+		case domain.TodoSortUpdatedAt:
+			values = append(values, todo.UpdatedAt.UTC().Format(time.RFC3339Nano))
+		case domain.TodoSortRank:
+			switch {
+			case todo.Rank != "":
+				values = append(values, todo.Rank)
+			case s.descending:
+				values = append(values, "")
+			default:
+				values = append(values, "~")
+			}
+		}
+	}
+
+	return &pagination.Cursor{
+		Values: values,
+		ID:     todo.ID,
+		Sort:   s.key(),
+	}
+}
+
+func (r *todoRepo) Create(ctx context.Context, uuid string, userID uint, todo *domain.TodoCreate, tagIDs []uint) (*domain.Todo, error) {
+	var todoEntity entity.Todo
+	err := r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		query := `
+			INSERT INTO todos (uuid, user_id, list_id, title, description, priority, due_date, estimate_minutes, created_at, completed_at,
+				ciphertext, key_version)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, COALESCE($9, NOW()), $10, $11, $12)
+			RETURNING ` + todoColumns
+
+		err := tx.Get(ctx, &todoEntity, query,
+			uuid,
+			userID,
+			nullID(todo.ListID),
+			todo.Title,
+			todo.Description,
+			int(todo.Priority),
+			nullTime(todo.DueDate),
+			nullMinutes(todo.Estimate),
+			nullTime(todo.CreatedAt),
+			nullTime(todo.CompletedAt),
+			nullString(todo.Ciphertext),
+			nullVersion(todo.KeyVersion),
+		)
+		if err != nil {
+			return err
+		}
+		if err = assignBlindIndexes(ctx, tx, todoEntity.ID, todo.BlindIndexes); err != nil {
+			return err
+		}
+
+		return assignTags(ctx, tx, todoEntity.ID, tagIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	created := todoEntity.ToDomain()
+	created.ListUUID = todo.ListUUID
+	if err = attachTodoTags(ctx, r.DB, []*domain.Todo{created}); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (r *todoRepo) Update(ctx context.Context, todo *domain.Todo) error {
+	query := `
+		UPDATE todos
+			SET title = $1, description = $2, priority = $3, due_date = $4, estimate_minutes = $5,
+				postponed_count = $6, completed_at = $7, ciphertext = $8, key_version = $9, version = version + 1,
+				archived_at = CASE WHEN $7::timestamptz IS NULL THEN NULL ELSE archived_at END,
+				reminded_at = CASE WHEN due_date IS DISTINCT FROM $4 THEN NULL ELSE reminded_at END,
+				overdue_reminded_at = CASE WHEN due_date IS DISTINCT FROM $4 THEN NULL ELSE overdue_reminded_at END
+		WHERE id = $10
+			AND user_id = $11
+			AND version = $12
+			AND deleted_at IS NULL
+		RETURNING version`
+
+	var version int
+	err := r.DB.Get(ctx, &version, query,
+		todo.Title,
+		todo.Description,
+		int(todo.Priority),
+		nullTime(todo.DueDate),
+		nullMinutes(todo.Estimate),
+		todo.Postponed,
+		nullTime(todo.CompletedAt),
+		nullString(todo.Ciphertext),
+		nullVersion(todo.KeyVersion),
+		todo.ID,
+		todo.UserID,
+		todo.Version,
+	)
+	if err != nil {
+		return err
+	}
+	todo.Version = version
+
+	return nil
+}
+
+func (r *todoRepo) Move(ctx context.Context, todo *domain.Todo, move *domain.TodoMove, tagIDs []uint) error {
+	return r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		query := `
+			UPDATE todos
+				SET user_id = $1, list_id = $2, status = NULL, position = NULL, version = version + 1,
+					rank = CASE WHEN user_id = $1 THEN rank ELSE NULL END
+			WHERE id = $3
+				AND user_id = $4
+				AND version = $5
+				AND deleted_at IS NULL
+			RETURNING version`
+
+		var version int
+		err := tx.Get(ctx, &version, query, move.OwnerID, nullID(move.ListID), todo.ID, todo.UserID, todo.Version)
+		if err != nil {
+			return err
+		}
+
+		if _, err = tx.Exec(ctx, `DELETE FROM todo_tags WHERE todo_id = $1`, todo.ID); err != nil {
+			return err
+		}
+		if err = assignTags(ctx, tx, todo.ID, tagIDs); err != nil {
+			return err
+		}
+
+		todo.ListID = move.ListID
+		todo.ListUUID = move.ListUUID
+		todo.Status, todo.Position = "", 0
+		// ranks order the todos of an owner, the new owner places it themselves
+		if todo.UserID != move.OwnerID {
+			todo.Rank = ""
+		}
+		todo.UserID = move.OwnerID
+		todo.Version = version
+
+		return nil
+	})
+}
+
+func (r *todoRepo) Rank(ctx context.Context, todo *domain.Todo, rank string) error {
+	query := `
+		UPDATE todos SET rank = $1
+		WHERE id = $2
+			AND user_id = $3
+			AND deleted_at IS NULL
+		RETURNING id`
+
+	var id uint
+	if err := r.DB.Get(ctx, &id, query, rank, todo.ID, todo.UserID); err != nil {
+		return err
+	}
+	todo.Rank = rank
+
+	return nil
+}
+
+func (r *todoRepo) Rerank(ctx context.Context, userID uint) error {
+	return r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		// FOR UPDATE keeps concurrent moves from slipping in between the old ranks
+		query := `
+			SELECT id
+				FROM todos
+			WHERE user_id = $1
+				AND rank IS NOT NULL
+				AND deleted_at IS NULL
+			ORDER BY rank, id
+			FOR UPDATE`
+
+		var ids []uint
+		if err := tx.Select(ctx, &ids, query, userID); err != nil {
+			return err
+		}
+
+		for i, rank := range domain.SpreadRanks(len(ids)) {
+			if _, err := tx.Exec(ctx, `UPDATE todos SET rank = $1 WHERE id = $2`, rank, ids[i]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *todoRepo) NextRank(ctx context.Context, userID uint, rank string, excludeID uint) (string, error) {
+	query := `
+		SELECT MIN(rank)
+			FROM todos
+		WHERE user_id = $1
+			AND rank > $2
+			AND id <> $3
+			AND deleted_at IS NULL`
+
+	var next sql.NullString
+	if err := r.DB.Get(ctx, &next, query, userID, rank, excludeID); err != nil {
+		return "", err
+	}
+
+	return next.String, nil
+}
+
+func (r *todoRepo) LastRank(ctx context.Context, userID uint) (string, error) {
+	query := `SELECT MAX(rank) FROM todos WHERE user_id = $1 AND deleted_at IS NULL`
+
+	var last sql.NullString
+	if err := r.DB.Get(ctx, &last, query, userID); err != nil {
+		return "", err
+	}
+
+	return last.String, nil
+}
+
+func (r *todoRepo) Delete(ctx context.Context, userID uint, uuid string) error {
+	query := `UPDATE todos SET deleted_at = $1 WHERE uuid = $2 AND user_id = $3 AND deleted_at IS NULL`
+	_, err := r.DB.Exec(ctx, query, time.Now().UTC(), uuid, userID)
+
+	return err
+}
+
+func (r *todoRepo) ByUUID(ctx context.Context, userID uint, uuid string) (*domain.Todo, error) {
+	query := `SELECT ` + todoSelectColumns + ` FROM todos WHERE uuid = $1 AND user_id = $2 AND deleted_at IS NULL`
+
+	var todoEntity entity.Todo
+	err := r.DB.Get_RO(ctx, &todoEntity, query, uuid, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	todo := todoEntity.ToDomain()
+	if err = attachTodoTags(ctx, r.DB, []*domain.Todo{todo}); err != nil {
+		return nil, err
+	}
+
+	return todo, nil
+}
+
+func (r *todoRepo) ByUUIDShared(ctx context.Context, memberID uint, uuid string) (*domain.Todo, error) {
+	query := `SELECT ` + todoSelectColumns + ` FROM todos
+		WHERE todos.uuid = $1
+			AND todos.deleted_at IS NULL
+			AND todos.list_id IN (SELECT list_members.list_id FROM list_members WHERE list_members.user_id = $2)`
+
+	var todoEntity entity.Todo
+	err := r.DB.Get_RO(ctx, &todoEntity, query, uuid, memberID)
+	if err != nil {
+		return nil, err
+	}
+
+	todo := todoEntity.ToDomain()
+	if err = attachTodoTags(ctx, r.DB, []*domain.Todo{todo}); err != nil {
+		return nil, err
+	}
+
+	return todo, nil
+}
+
+func (r *todoRepo) All(ctx context.Context, userID uint, filter *domain.TodoFilter, page *pagination.Page) ([]*domain.Todo, *pagination.Cursor, error) {
+	var query strings.Builder
+	args := []interface{}{userID}
+
+	query.WriteString(`SELECT ` + todoSelectColumns + ` FROM todos WHERE todos.user_id = $1 AND todos.deleted_at IS NULL`)
+
+	if filter != nil && filter.ListID != 0 {
+		args = append(args, filter.ListID)
+		query.WriteString(fmt.Sprintf(` AND todos.list_id = $%d`, len(args)))
+	}
+
+	if filter != nil && !filter.DueFrom.IsZero() {
+		args = append(args, filter.DueFrom.UTC())
+		query.WriteString(fmt.Sprintf(` AND todos.due_date >= $%d`, len(args)))
+	}
+	if filter != nil && !filter.DueBefore.IsZero() {
+		args = append(args, filter.DueBefore.UTC())
+		query.WriteString(fmt.Sprintf(` AND todos.due_date < $%d`, len(args)))
+	}
+
+	if filter != nil && !filter.CreatedFrom.IsZero() {
+		args = append(args, filter.CreatedFrom.UTC())
+		query.WriteString(fmt.Sprintf(` AND todos.created_at >= $%d`, len(args)))
+	}
+	if filter != nil && !filter.CreatedBefore.IsZero() {
+		args = append(args, filter.CreatedBefore.UTC())
+		query.WriteString(fmt.Sprintf(` AND todos.created_at < $%d`, len(args)))
+	}
+
+	if filter != nil {
+		switch filter.Completed {
+		case domain.CompletedExclude:
+			query.WriteString(` AND todos.completed_at IS NULL`)
+		case domain.CompletedOnly:
+			query.WriteString(` AND todos.completed_at IS NOT NULL`)
+		case domain.CompletedInclude:
+		}
+	}
+
+	archived := domain.ArchiveExclude
+	if filter != nil {
+		archived = filter.Archived
+	}
+	switch archived {
+	case domain.ArchiveExclude:
+		args = append(args, time.Now().UTC())
+		query.WriteString(` AND NOT ` + todoArchived(len(args)))
+	case domain.ArchiveOnly:
+		args = append(args, time.Now().UTC())
+		query.WriteString(` AND ` + todoArchived(len(args)))
+	case domain.ArchiveInclude:
+	}
+
+	if filter != nil && len(filter.Tags) > 0 {
+		// only keep todos that have every requested tag
+		query.WriteString(fmt.Sprintf(`
+			AND todos.id IN (
+				SELECT todo_tags.todo_id
+					FROM todo_tags
+				JOIN tags
+					ON tags.id = todo_tags.tag_id
+				WHERE tags.user_id = $1
+					AND tags.name IN (%s)
+				GROUP BY todo_tags.todo_id
+				HAVING COUNT(DISTINCT tags.id) = %d
+			)`, placeholders(len(args)+1, len(filter.Tags)), len(filter.Tags)))
+		for _, tag := range filter.Tags {
+			args = append(args, tag)
+		}
+	}
+
+	if filter != nil && filter.TagGroup != "" {
+		args = append(args, filter.TagGroup+domain.TagGroupSeparator)
+		query.WriteString(fmt.Sprintf(`
+			AND EXISTS (
+				SELECT 1
+					FROM todo_tags
+				JOIN tags
+					ON tags.id = todo_tags.tag_id
+				WHERE todo_tags.todo_id = todos.id
+					AND left(tags.name, length($%d)) = $%d
+			)`, len(args), len(args)))
+	}
+
+	if filter != nil && filter.BlindIndex != "" {
+		args = append(args, filter.BlindIndex)
+		query.WriteString(fmt.Sprintf(`
+			AND EXISTS (
+				SELECT 1
+					FROM todo_blind_indexes
+				WHERE todo_blind_indexes.todo_id = todos.id
+					AND todo_blind_indexes.token = $%d
+			)`, len(args)))
+	}
+
+	sort := newTodoSort(filter)
+	cursor, err := page.After(sort.key(), len(sort.fields))
+	if err != nil {
+		return nil, nil, err
+	}
+	if cursor != nil {
+		var condition string
+		condition, args = sort.after(cursor, args)
+		query.WriteString(condition)
+	}
+
+	query.WriteString(sort.orderBy())
+
+	if page != nil {
+		args = append(args, page.FetchLimit())
+		query.WriteString(fmt.Sprintf(` LIMIT $%d`, len(args)))
+	}
+
+	var todoEntities []*entity.Todo
+	err = r.DB.Select_RO(ctx, &todoEntities, query.String(), args...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	todos := make([]*domain.Todo, 0, len(todoEntities))
+	for _, todoEntity := range todoEntities {
+		todos = append(todos, todoEntity.ToDomain())
+	}
+
+	todos, next := pagination.Trim(todos, page, sort.cursor)
+	if err = attachTodoTags(ctx, r.DB, todos); err != nil {
+		return nil, nil, err
+	}
+
+	return todos, next, nil
+}
+
+func (r *todoRepo) Archive(ctx context.Context, now time.Time, limit int) (int, error) {
+	query := `
+		UPDATE todos SET archived_at = $1
+		WHERE id IN (
+			SELECT todos.id
+				FROM todos
+			JOIN lists
+				ON lists.id = todos.list_id
+			WHERE todos.archived_at IS NULL
+				AND todos.deleted_at IS NULL
+				AND lists.completed_retention_days IS NOT NULL
+				AND todos.completed_at < $1::timestamptz - make_interval(days => lists.completed_retention_days)
+			LIMIT $2
+		)
+		RETURNING id`
+
+	var ids []uint
+	if err := r.DB.Select(ctx, &ids, query, now.UTC(), limit); err != nil {
+		return 0, err
+	}
+
+	return len(ids), nil
+}
+
+func (r *todoRepo) Remind(ctx context.Context, now time.Time, until time.Time, limit int) ([]*domain.Todo, error) {
+	return r.claimDue(ctx, "reminded_at", now, now, until, limit)
+}
+
+func (r *todoRepo) Overdue(ctx context.Context, since time.Time, now time.Time, limit int) ([]*domain.Todo, error) {
+	return r.claimDue(ctx, "overdue_reminded_at", now, since, now, limit)
+}
+
+// claimDue stamps column with now on up to limit open todos due after from and until until
+// whose column is still empty, and returns them.
+func (r *todoRepo) claimDue(ctx context.Context, column string, now time.Time, from time.Time, until time.Time, limit int) ([]*domain.Todo, error) {
+	query := `
+		UPDATE todos SET ` + column + ` = $1
+		WHERE id IN (
+			SELECT id
+				FROM todos
+			WHERE ` + column + ` IS NULL
+				AND completed_at IS NULL
+				AND deleted_at IS NULL
+				AND due_date > $2
+				AND due_date <= $3
+			ORDER BY due_date
+			LIMIT $4
+		)
+			AND ` + column + ` IS NULL
+		RETURNING ` + todoColumns
+
+	var todoEntities []*entity.Todo
+	if err := r.DB.Select(ctx, &todoEntities, query, now.UTC(), from.UTC(), until.UTC(), limit); err != nil {
+		return nil, err
+	}
+
+	todos := make([]*domain.Todo, 0, len(todoEntities))
+	for _, todoEntity := range todoEntities {
+		todos = append(todos, todoEntity.ToDomain())
+	}
+
+	return todos, nil
+}
+
+// todoArchived matches archived todos, including completed todos past the retention of their
+// list the archival didn't get to yet. The placeholder at arg is the current time.
+func todoArchived(arg int) string {
+	return fmt.Sprintf(`(todos.archived_at IS NOT NULL OR EXISTS (
+			SELECT 1
+				FROM lists
+			WHERE lists.id = todos.list_id
+				AND lists.completed_retention_days IS NOT NULL
+				AND todos.completed_at < $%d::timestamptz - make_interval(days => lists.completed_retention_days)
+		))`, arg)
+}
+
+// attachTodoTags loads the tags of all given todos in a single query.
+func attachTodoTags(ctx context.Context, db db.DB, todos []*domain.Todo) error {
+	if len(todos) == 0 {
+		return nil
+	}
+
+	byID := make(map[uint]*domain.Todo, len(todos))
+	args := make([]interface{}, 0, len(todos))
+	for _, todo := range todos {
+		todo.Tags = []*domain.Tag{}
+		byID[todo.ID] = todo
+		args = append(args, todo.ID)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT todo_tags.todo_id, tags.id, tags.uuid, tags.user_id, tags.name, tags.color, tags.created_at, tags.updated_at
+			FROM todo_tags
+		JOIN tags
+			ON tags.id = todo_tags.tag_id
+		WHERE todo_tags.todo_id IN (%s)
+		ORDER BY tags.name`, placeholders(1, len(args)))
+
+	var todoTags []*entity.TodoTag
+	if err := db.Select_RO(ctx, &todoTags, query, args...); err != nil {
+		return err
+	}
+
+	for _, todoTag := range todoTags {
+		if todo, ok := byID[todoTag.TodoID]; ok {
+			todo.Tags = append(todo.Tags, todoTag.ToDomain())
+		}
+	}
+
+	return nil
+}
+
+func assignTags(ctx context.Context, tx db.Tx, todoID uint, tagIDs []uint) error {
+	query := `INSERT INTO todo_tags (todo_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	for _, tagID := range tagIDs {
+		if _, err := tx.Exec(ctx, query, todoID, tagID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func assignBlindIndexes(ctx context.Context, tx db.Tx, todoID uint, tokens []string) error {
+	query := `INSERT INTO todo_blind_indexes (todo_id, token) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	for _, token := range tokens {
+		if _, err := tx.Exec(ctx, query, todoID, token); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// nullID stores zero ids as NULL.
+func nullID(id uint) interface{} {
+	if id == 0 {
+		return nil
+	}
+
+	return id
+}
+
+// nullTime stores zero times as NULL.
+func nullTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+
+	return t.UTC()
+}
+
+// nullString stores empty strings as NULL.
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+
+	return s
+}
+
+// nullVersion stores the key version of todos that aren't encrypted as NULL.
+func nullVersion(version int) interface{} {
+	if version == 0 {
+		return nil
+	}
+
+	return version
+}
+
+// nullMinutes stores zero durations as NULL and others as whole minutes.
+func nullMinutes(d time.Duration) interface{} {
+	if d <= 0 {
+		return nil
+	}
+
+	return int(d.Minutes())
+}
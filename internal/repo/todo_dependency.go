@@ -0,0 +1,191 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+)
+
+type TodoDependencyRepo interface {
+	// Add marks todo as blocked by blocker, which were checked to be of the same list. It fails
+	// with domain.ErrDependencyCycle when blocker waits for todo already and with
+	// domain.ErrTooManyBlockers past domain.MaxTodoBlockers. Adding it again changes nothing.
+	Add(ctx context.Context, todo *domain.Todo, blocker *domain.Todo, createdBy uint) error
+	// Remove fails with sql.ErrNoRows when todo isn't blocked by blocker.
+	Remove(ctx context.Context, todoID uint, blockerID uint) error
+	// OpenBlockers counts the open todos that block the todo.
+	OpenBlockers(ctx context.Context, todo *domain.Todo) (int, error)
+	// Graph returns the todos the todo waits for and those waiting for it, directly or through
+	// others, up to domain.MaxDependencyGraph todos.
+	Graph(ctx context.Context, todo *domain.Todo) (*domain.DependencyGraph, error)
+}
+
+type todoDependencyRepo struct {
+	DB db.DB
+}
+
+func NewTodoDependencyRepo(db db.DB) *todoDependencyRepo {
+	return &todoDependencyRepo{
+		DB: db,
+	}
+}
+
+var _ TodoDependencyRepo = (*todoDependencyRepo)(nil)
+
+// todoDependencyLock is the advisory lock serializing new dependencies of an owner, two
+// concurrent dependencies in opposite directions would make a cycle.
+const todoDependencyLock = 0x74646570
+
+// todoDependencyEdges are the dependencies that hold between todos of the owner $1 and list $2,
+// those of deleted todos and todos that were moved apart are left out.
+const todoDependencyEdges = `
+	edges AS (
+		SELECT todo_dependencies.todo_id, blocked.uuid AS todo_uuid, todo_dependencies.blocker_id,
+			blocker.uuid AS blocker_uuid, todo_dependencies.created_at
+			FROM todo_dependencies
+			JOIN todos blocked ON blocked.id = todo_dependencies.todo_id
+			JOIN todos blocker ON blocker.id = todo_dependencies.blocker_id
+		WHERE blocked.user_id = $1
+			AND blocker.user_id = $1
+			AND blocked.list_id IS NOT DISTINCT FROM $2::INTEGER
+			AND blocker.list_id IS NOT DISTINCT FROM $2::INTEGER
+			AND blocked.deleted_at IS NULL
+			AND blocker.deleted_at IS NULL
+	)`
+
+// todoDependencyGraph collects the ids of the graph of the todo $3 as graph(id).
+const todoDependencyGraph = `
+	WITH RECURSIVE ` + todoDependencyEdges + `,
+	upstream (id) AS (
+		SELECT $3::INTEGER
+		UNION
+		SELECT edges.blocker_id FROM edges JOIN upstream ON edges.todo_id = upstream.id
+	),
+	downstream (id) AS (
+		SELECT $3::INTEGER
+		UNION
+		SELECT edges.todo_id FROM edges JOIN downstream ON edges.blocker_id = downstream.id
+	),
+	graph (id) AS (
+		SELECT id FROM upstream
+		UNION
+		SELECT id FROM downstream
+	)`
+
+func (r *todoDependencyRepo) Add(ctx context.Context, todo *domain.Todo, blocker *domain.Todo, createdBy uint) error {
+	return r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1, $2)`, todoDependencyLock, todo.UserID); err != nil {
+			return err
+		}
+
+		// the new dependency makes a cycle when the blocker waits for the todo already
+		query := `
+			WITH RECURSIVE ` + todoDependencyEdges + `,
+			upstream (id) AS (
+				SELECT $3::INTEGER
+				UNION
+				SELECT edges.blocker_id FROM edges JOIN upstream ON edges.todo_id = upstream.id
+			)
+			SELECT EXISTS (SELECT 1 FROM upstream WHERE id = $4)`
+
+		var cycle bool
+		if err := tx.Get(ctx, &cycle, query, todo.UserID, nullID(todo.ListID), blocker.ID, todo.ID); err != nil {
+			return err
+		}
+		if cycle {
+			return domain.ErrDependencyCycle
+		}
+
+		var blockers int
+		query = `SELECT COUNT(*) FROM todo_dependencies WHERE todo_id = $1 AND blocker_id <> $2`
+		if err := tx.Get(ctx, &blockers, query, todo.ID, blocker.ID); err != nil {
+			return err
+		}
+		if blockers >= domain.MaxTodoBlockers {
+			return domain.ErrTooManyBlockers
+		}
+
+		query = `
+			INSERT INTO todo_dependencies (todo_id, blocker_id, created_by)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (todo_id, blocker_id) DO NOTHING`
+		_, err := tx.Exec(ctx, query, todo.ID, blocker.ID, createdBy)
+
+		return err
+	})
+}
+
+func (r *todoDependencyRepo) Remove(ctx context.Context, todoID uint, blockerID uint) error {
+	query := `DELETE FROM todo_dependencies WHERE todo_id = $1 AND blocker_id = $2 RETURNING todo_id`
+
+	var id uint
+	return r.DB.Get(ctx, &id, query, todoID, blockerID)
+}
+
+func (r *todoDependencyRepo) OpenBlockers(ctx context.Context, todo *domain.Todo) (int, error) {
+	query := `
+		SELECT COUNT(*)
+			FROM todo_dependencies
+			JOIN todos blocker ON blocker.id = todo_dependencies.blocker_id
+		WHERE todo_dependencies.todo_id = $1
+			AND blocker.user_id = $2
+			AND blocker.list_id IS NOT DISTINCT FROM $3::INTEGER
+			AND blocker.completed_at IS NULL
+			AND blocker.deleted_at IS NULL`
+
+	var blockers int
+	if err := r.DB.Get(ctx, &blockers, query, todo.ID, todo.UserID, nullID(todo.ListID)); err != nil {
+		return 0, err
+	}
+
+	return blockers, nil
+}
+
+func (r *todoDependencyRepo) Graph(ctx context.Context, todo *domain.Todo) (*domain.DependencyGraph, error) {
+	args := []interface{}{todo.UserID, nullID(todo.ListID), todo.ID, domain.MaxDependencyGraph}
+
+	query := todoDependencyGraph + `
+		SELECT ` + todoSelectColumns + `
+			FROM todos
+		WHERE todos.id IN (SELECT id FROM graph)
+		ORDER BY todos.id
+		LIMIT $4`
+
+	var todoEntities []*entity.Todo
+	if err := r.DB.Select_RO(ctx, &todoEntities, query, args...); err != nil {
+		return nil, err
+	}
+
+	graph := &domain.DependencyGraph{
+		Todo:  todo,
+		Todos: make([]*domain.Todo, 0, len(todoEntities)),
+		Edges: []*domain.TodoDependency{},
+	}
+	ids := make(map[uint]bool, len(todoEntities))
+	for _, todoEntity := range todoEntities {
+		graph.Todos = append(graph.Todos, todoEntity.ToDomain())
+		ids[todoEntity.ID] = true
+	}
+
+	query = todoDependencyGraph + `
+		SELECT todo_id, todo_uuid, blocker_id, blocker_uuid, created_at
+			FROM edges
+		WHERE todo_id IN (SELECT id FROM graph)
+			AND blocker_id IN (SELECT id FROM graph)
+		ORDER BY created_at, todo_id, blocker_id`
+
+	var edgeEntities []*entity.TodoDependency
+	if err := r.DB.Select_RO(ctx, &edgeEntities, query, args[:3]...); err != nil {
+		return nil, err
+	}
+	// edges to todos cut off by the limit are left out too
+	for _, edgeEntity := range edgeEntities {
+		if ids[edgeEntity.TodoID] && ids[edgeEntity.BlockerID] {
+			graph.Edges = append(graph.Edges, edgeEntity.ToDomain())
+		}
+	}
+
+	return graph, nil
+}
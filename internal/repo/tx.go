@@ -0,0 +1,132 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"io"
+
+	"github.com/meowmix1337/go-core/db"
+)
+
+// TxManager runs multi-step service operations atomically. The repos join the transaction
+// through the context, so services pass the context of fn on and the repos need no changes.
+type TxManager interface {
+	// WithTx runs fn in a transaction that is committed when fn returns nil and rolled back
+	// otherwise. Nested calls join the outer transaction.
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type txManager struct {
+	DB db.DB
+}
+
+// NewTxManager starts the transactions on db. Only databases wrapped with NewTxDB hand the
+// transaction on to the repos, the region router starts it on the database of the region of
+// the context.
+func NewTxManager(db db.DB) *txManager {
+	return &txManager{
+		DB: db,
+	}
+}
+
+var _ TxManager = (*txManager)(nil)
+
+func (m *txManager) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return m.DB.Transaction(ctx, func(ctx context.Context, _ db.Tx) error {
+		return fn(ctx)
+	})
+}
+
+type txKey struct{}
+
+// ctxTx is the transaction of a context and the database it was started on, databases only
+// join their own transactions.
+type ctxTx struct {
+	db *txDB
+	tx db.Tx
+}
+
+// txDB runs the queries of a context with a transaction in that transaction, including reads
+// of the reader, so a transaction reads its own writes.
+type txDB struct {
+	db db.DB
+}
+
+// NewTxDB makes the queries of db join the transaction of their context.
+func NewTxDB(db db.DB) *txDB {
+	return &txDB{
+		db: db,
+	}
+}
+
+var _ db.DB = (*txDB)(nil)
+
+func (d *txDB) tx(ctx context.Context) (db.Tx, bool) {
+	current, ok := ctx.Value(txKey{}).(*ctxTx)
+	if !ok || current.db != d {
+		return nil, false
+	}
+
+	return current.tx, true
+}
+
+func (d *txDB) Get(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	if tx, ok := d.tx(ctx); ok {
+		return tx.Get(ctx, dest, query, args...)
+	}
+
+	return d.db.Get(ctx, dest, query, args...)
+}
+
+func (d *txDB) Get_RO(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	if tx, ok := d.tx(ctx); ok {
+		return tx.Get(ctx, dest, query, args...)
+	}
+
+	return d.db.Get_RO(ctx, dest, query, args...)
+}
+
+func (d *txDB) Select(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	if tx, ok := d.tx(ctx); ok {
+		return tx.Select(ctx, dest, query, args...)
+	}
+
+	return d.db.Select(ctx, dest, query, args...)
+}
+
+func (d *txDB) Select_RO(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	if tx, ok := d.tx(ctx); ok {
+		return tx.Select(ctx, dest, query, args...)
+	}
+
+	return d.db.Select_RO(ctx, dest, query, args...)
+}
+
+func (d *txDB) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if tx, ok := d.tx(ctx); ok {
+		return tx.Exec(ctx, query, args...)
+	}
+
+	return d.db.Exec(ctx, query, args...)
+}
+
+// Transaction joins the transaction of the context, the outer transaction commits or rolls
+// back the work of fn.
+func (d *txDB) Transaction(ctx context.Context, fn func(ctx context.Context, tx db.Tx) error) error {
+	if tx, ok := d.tx(ctx); ok {
+		return fn(ctx, tx)
+	}
+
+	return d.db.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		return fn(context.WithValue(ctx, txKey{}, &ctxTx{db: d, tx: tx}), tx)
+	})
+}
+
+// Close passes the close on to the wrapped database.
+func (d *txDB) Close() error {
+	if closer, ok := d.db.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
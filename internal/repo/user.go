@@ -2,16 +2,43 @@ package repo
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/meowmix1337/go-core/db"
 	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
 	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+	"github.com/meowmix1337/the_recipe_book/internal/pagination"
 )
 
 type UserRepo interface {
-	Create(ctx context.Context, uuid string, email string, password string) error
+	Create(ctx context.Context, uuid string, email string, password string) (uint, error)
+	CreateChild(ctx context.Context, uuid string, parentID uint, child *domain.ChildCreate, password string) (*domain.User, error)
+	UpdateControls(ctx context.Context, userID uint, controls domain.ParentalControls) error
+	UpdatePassword(ctx context.Context, userID uint, password string) error
+	// UpdateEmail changes the email of the user, it returns sql.ErrNoRows when the user is gone.
+	UpdateEmail(ctx context.Context, userID uint, email string) error
+	// UpdateProfile saves the name, timezone, locale and avatar of the user.
+	UpdateProfile(ctx context.Context, user *domain.User) (*domain.User, error)
+	Delete(ctx context.Context, userID uint) error
+	// ScheduleDeletion sets when the account is purged, a scheduled deletion keeps its date.
+	ScheduleDeletion(ctx context.Context, userID uint, purgeAt time.Time) (time.Time, error)
+	// CancelDeletion returns sql.ErrNoRows when the account isn't scheduled for deletion.
+	CancelDeletion(ctx context.Context, userID uint) error
+	// PurgeDue returns the accounts whose purge is due at now, oldest first.
+	PurgeDue(ctx context.Context, now time.Time, limit int) ([]*domain.User, error)
+	// Purge removes the account and, by their foreign keys, its child accounts and their data.
+	// It returns sql.ErrNoRows when the deletion was cancelled in the meantime.
+	Purge(ctx context.Context, userID uint, now time.Time) error
+
+	ByID(ctx context.Context, id uint) (*domain.User, error)
+	ByUUID(ctx context.Context, uuid string) (*domain.User, error)
 	ByEmail(ctx context.Context, email string) (*domain.User, error)
 	ByEmailWithPassword(ctx context.Context, email string) (*domain.User, error)
+	ByUsername(ctx context.Context, username string) (*domain.User, error)
+	ByUsernameWithPassword(ctx context.Context, username string) (*domain.User, error)
+	Children(ctx context.Context, parentID uint) ([]*domain.User, error)
+	All(ctx context.Context, page *pagination.Page) ([]*domain.User, *pagination.Cursor, error)
 }
 
 type userRepo struct {
@@ -28,11 +55,11 @@ func NewUserRepository(db db.DB) *userRepo {
 
 var _ UserRepo = (*userRepo)(nil)
 
-func (u *userRepo) Create(ctx context.Context, uuid string, email string, password string) error {
+func (u *userRepo) Create(ctx context.Context, uuid string, email string, password string) (uint, error) {
+	var userID uint
 	err := u.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
 		query := `INSERT INTO users (uuid, email) VALUES ($1, $2) RETURNING id`
 
-		var userID int
 		err := tx.Get(ctx, &userID, query, uuid, email)
 		if err != nil {
 			return err
@@ -45,10 +72,164 @@ func (u *userRepo) Create(ctx context.Context, uuid string, email string, passwo
 		}
 		return nil
 	})
+	if err != nil {
+		return 0, err
+	}
+
+	return userID, nil
+}
+
+func (u *userRepo) CreateChild(ctx context.Context, uuid string, parentID uint, child *domain.ChildCreate, password string) (*domain.User, error) {
+	var userEntity entity.User
+	err := u.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		query := `
+			INSERT INTO users (uuid, parent_id, username, first_name, last_name, allow_sharing, allow_deletion)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING *`
+
+		err := tx.Get(ctx, &userEntity, query,
+			uuid,
+			parentID,
+			child.Username,
+			child.FirstName,
+			child.LastName,
+			child.Controls.AllowSharing,
+			child.Controls.AllowDeletion,
+		)
+		if err != nil {
+			return err
+		}
+
+		query = `INSERT INTO user_passwords (user_id, password) VALUES ($1, $2)`
+		_, err = tx.Exec(ctx, query, userEntity.ID, password)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return userEntity.ToDomain(), nil
+}
+
+func (u *userRepo) UpdateControls(ctx context.Context, userID uint, controls domain.ParentalControls) error {
+	query := `UPDATE users SET allow_sharing = $1, allow_deletion = $2 WHERE id = $3 AND deleted_at IS NULL`
+	_, err := u.DB.Exec(ctx, query, controls.AllowSharing, controls.AllowDeletion, userID)
+
+	return err
+}
+
+func (u *userRepo) UpdatePassword(ctx context.Context, userID uint, password string) error {
+	query := `UPDATE user_passwords SET password = $1 WHERE user_id = $2`
+	_, err := u.DB.Exec(ctx, query, password, userID)
 
 	return err
 }
 
+func (u *userRepo) UpdateEmail(ctx context.Context, userID uint, email string) error {
+	query := `UPDATE users SET email = $1 WHERE id = $2 AND deleted_at IS NULL RETURNING id`
+
+	return u.DB.Get(ctx, &userID, query, email, userID)
+}
+
+func (u *userRepo) UpdateProfile(ctx context.Context, user *domain.User) (*domain.User, error) {
+	query := `
+		UPDATE users SET
+			first_name = NULLIF($1, ''),
+			last_name = NULLIF($2, ''),
+			timezone = $3,
+			locale = NULLIF($4, ''),
+			avatar_url = NULLIF($5, '')
+		WHERE id = $6 AND deleted_at IS NULL
+		RETURNING *`
+
+	var userEntity entity.User
+	err := u.DB.Get(ctx, &userEntity, query,
+		user.FirstName,
+		user.LastName,
+		user.Timezone,
+		user.Locale,
+		user.AvatarURL,
+		user.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return userEntity.ToDomain(), nil
+}
+
+func (u *userRepo) Delete(ctx context.Context, userID uint) error {
+	query := `UPDATE users SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL`
+	_, err := u.DB.Exec(ctx, query, time.Now().UTC(), userID)
+
+	return err
+}
+
+func (u *userRepo) ScheduleDeletion(ctx context.Context, userID uint, purgeAt time.Time) (time.Time, error) {
+	query := `
+		UPDATE users SET purge_at = COALESCE(purge_at, $1)
+		WHERE id = $2 AND deleted_at IS NULL
+		RETURNING purge_at`
+
+	var scheduled time.Time
+	err := u.DB.Get(ctx, &scheduled, query, purgeAt.UTC(), userID)
+
+	return scheduled, err
+}
+
+func (u *userRepo) CancelDeletion(ctx context.Context, userID uint) error {
+	query := `UPDATE users SET purge_at = NULL WHERE id = $1 AND purge_at IS NOT NULL RETURNING id`
+
+	var id uint
+	return u.DB.Get(ctx, &id, query, userID)
+}
+
+func (u *userRepo) PurgeDue(ctx context.Context, now time.Time, limit int) ([]*domain.User, error) {
+	query := `SELECT * FROM users WHERE purge_at <= $1 ORDER BY purge_at LIMIT $2`
+
+	var userEntities []*entity.User
+	if err := u.DB.Select(ctx, &userEntities, query, now.UTC(), limit); err != nil {
+		return nil, err
+	}
+
+	users := make([]*domain.User, 0, len(userEntities))
+	for _, userEntity := range userEntities {
+		users = append(users, userEntity.ToDomain())
+	}
+
+	return users, nil
+}
+
+func (u *userRepo) Purge(ctx context.Context, userID uint, now time.Time) error {
+	var id uint
+	return u.DB.Get(ctx, &id, `DELETE FROM users WHERE id = $1 AND purge_at <= $2 RETURNING id`, userID, now.UTC())
+}
+
+func (u *userRepo) ByID(ctx context.Context, id uint) (*domain.User, error) {
+	query := `SELECT * FROM users WHERE id = $1 AND deleted_at IS NULL`
+
+	var userEntity entity.User
+	err := u.DB.Get_RO(ctx, &userEntity, query, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return userEntity.ToDomain(), nil
+}
+
+func (u *userRepo) ByUUID(ctx context.Context, uuid string) (*domain.User, error) {
+	query := `SELECT * FROM users WHERE uuid = $1 AND deleted_at IS NULL`
+
+	var userEntity entity.User
+	err := u.DB.Get_RO(ctx, &userEntity, query, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	return userEntity.ToDomain(), nil
+}
+
 func (u *userRepo) ByEmail(ctx context.Context, email string) (*domain.User, error) {
 	query := `SELECT * FROM users WHERE email = $1 AND deleted_at IS NULL`
 
@@ -63,7 +244,8 @@ func (u *userRepo) ByEmail(ctx context.Context, email string) (*domain.User, err
 
 func (u *userRepo) ByEmailWithPassword(ctx context.Context, email string) (*domain.User, error) {
 	query := `
-		SELECT users.id, users.uuid, users.email, users.first_name, users.last_name, users.created_at, users.deleted_at, user_passwords.password
+		SELECT users.id, users.uuid, users.email, users.first_name, users.last_name, users.created_at, users.deleted_at,
+			users.parent_id, users.username, user_passwords.password
 			FROM users
 		JOIN user_passwords
 			ON user_passwords.user_id = users.id
@@ -79,3 +261,89 @@ func (u *userRepo) ByEmailWithPassword(ctx context.Context, email string) (*doma
 
 	return userEntity.ToDomain(), nil
 }
+
+func (u *userRepo) ByUsername(ctx context.Context, username string) (*domain.User, error) {
+	query := `SELECT * FROM users WHERE username = $1 AND deleted_at IS NULL`
+
+	var userEntity entity.User
+	err := u.DB.Get_RO(ctx, &userEntity, query, username)
+	if err != nil {
+		return nil, err
+	}
+
+	return userEntity.ToDomain(), nil
+}
+
+func (u *userRepo) ByUsernameWithPassword(ctx context.Context, username string) (*domain.User, error) {
+	query := `
+		SELECT users.id, users.uuid, users.email, users.first_name, users.last_name, users.created_at, users.deleted_at,
+			users.parent_id, users.username, user_passwords.password
+			FROM users
+		JOIN user_passwords
+			ON user_passwords.user_id = users.id
+		WHERE users.username = $1
+			AND users.deleted_at IS NULL
+	`
+
+	var userEntity entity.UserWithPassword
+	err := u.DB.Get_RO(ctx, &userEntity, query, username)
+	if err != nil {
+		return nil, err
+	}
+
+	return userEntity.ToDomain(), nil
+}
+
+func (u *userRepo) Children(ctx context.Context, parentID uint) ([]*domain.User, error) {
+	query := `SELECT * FROM users WHERE parent_id = $1 AND deleted_at IS NULL ORDER BY username`
+
+	var userEntities []*entity.User
+	err := u.DB.Select_RO(ctx, &userEntities, query, parentID)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]*domain.User, 0, len(userEntities))
+	for _, userEntity := range userEntities {
+		users = append(users, userEntity.ToDomain())
+	}
+
+	return users, nil
+}
+
+func (u *userRepo) All(ctx context.Context, page *pagination.Page) ([]*domain.User, *pagination.Cursor, error) {
+	query := `SELECT * FROM users WHERE deleted_at IS NULL`
+	args := []interface{}{}
+
+	cursor, err := page.After("", 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cursor != nil {
+		args = append(args, cursor.ID)
+		query += ` AND id > $1`
+	}
+
+	query += ` ORDER BY id`
+	if page != nil {
+		args = append(args, page.FetchLimit())
+		query += fmt.Sprintf(` LIMIT $%d`, len(args))
+	}
+
+	var userEntities []*entity.User
+	err = u.DB.Select_RO(ctx, &userEntities, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	users := make([]*domain.User, 0, len(userEntities))
+	for _, userEntity := range userEntities {
+		users = append(users, userEntity.ToDomain())
+	}
+
+	users, next := pagination.Trim(users, page, func(user *domain.User) *pagination.Cursor {
+		return &pagination.Cursor{ID: user.ID}
+	})
+
+	return users, next, nil
+}
@@ -0,0 +1,56 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/meowmix1337/go-core/db"
+)
+
+// UserRegionRepo is the directory of the data region of every login, it always uses the home
+// database since logins have to be routed before their region is known.
+type UserRegionRepo interface {
+	// Create returns sql.ErrNoRows when the login is taken in any region.
+	Create(ctx context.Context, loginHash string, region string) error
+	Delete(ctx context.Context, loginHash string) error
+
+	// Region returns sql.ErrNoRows for logins created before regions existed, they live in the
+	// home region.
+	Region(ctx context.Context, loginHash string) (string, error)
+}
+
+type userRegionRepo struct {
+	DB db.DB
+}
+
+func NewUserRegionRepo(db db.DB) *userRegionRepo {
+	return &userRegionRepo{
+		DB: db,
+	}
+}
+
+var _ UserRegionRepo = (*userRegionRepo)(nil)
+
+func (r *userRegionRepo) Create(ctx context.Context, loginHash string, region string) error {
+	query := `
+		INSERT INTO user_regions (login_hash, region) VALUES ($1, $2)
+		ON CONFLICT (login_hash) DO NOTHING
+		RETURNING login_hash`
+
+	return r.DB.Get(ctx, &loginHash, query, loginHash, region)
+}
+
+func (r *userRegionRepo) Delete(ctx context.Context, loginHash string) error {
+	_, err := r.DB.Exec(ctx, `DELETE FROM user_regions WHERE login_hash = $1`, loginHash)
+
+	return err
+}
+
+func (r *userRegionRepo) Region(ctx context.Context, loginHash string) (string, error) {
+	var region string
+	query := `SELECT region FROM user_regions WHERE login_hash = $1`
+	if err := r.DB.Get_RO(ctx, &region, query, loginHash); err != nil {
+		return "", err
+	}
+
+	return region, nil
+}
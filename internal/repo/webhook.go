@@ -0,0 +1,266 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+	"github.com/meowmix1337/the_recipe_book/internal/pagination"
+)
+
+type WebhookRepo interface {
+	Create(ctx context.Context, webhook *domain.Webhook) (*domain.Webhook, error)
+	Delete(ctx context.Context, userID uint, uuid string) error
+	ByUUID(ctx context.Context, userID uint, uuid string) (*domain.Webhook, error)
+	All(ctx context.Context, userID uint) ([]*domain.Webhook, error)
+
+	CreateDeliveries(ctx context.Context, deliveries []*domain.WebhookDelivery) error
+	// Due returns the pending deliveries of webhooks that still exist, oldest first.
+	Due(ctx context.Context, now time.Time, limit int) ([]*domain.WebhookDelivery, error)
+	// Claim counts an attempt and moves the next attempt of a due delivery forward. It fails
+	// with sql.ErrNoRows when another instance already claimed the attempt.
+	Claim(ctx context.Context, delivery *domain.WebhookDelivery, nextAttemptAt time.Time) error
+	// UpdateDelivery stores the outcome of an attempt.
+	UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error
+	// Hold gives back a claimed attempt that wasn't sent and postpones the delivery to until.
+	Hold(ctx context.Context, delivery *domain.WebhookDelivery, until time.Time, reason string) error
+	// Deliveries returns the deliveries of a webhook, newest first.
+	Deliveries(ctx context.Context, webhookID uint, page *pagination.Page) ([]*domain.WebhookDelivery, *pagination.Cursor, error)
+}
+
+type webhookRepo struct {
+	DB db.DB
+}
+
+func NewWebhookRepo(db db.DB) *webhookRepo {
+	return &webhookRepo{
+		DB: db,
+	}
+}
+
+var _ WebhookRepo = (*webhookRepo)(nil)
+
+const (
+	webhookColumns = `id, uuid, user_id, url, secret, events, created_at, updated_at, deleted_at`
+	// webhookDeliveryColumns selects a delivery joined with its webhook.
+	webhookDeliveryColumns = `webhook_deliveries.id, webhook_deliveries.uuid, webhook_deliveries.webhook_id,
+		webhooks.uuid AS webhook_uuid, webhooks.user_id AS webhook_user_id, webhooks.url AS webhook_url,
+		webhooks.secret AS webhook_secret, webhook_deliveries.event, webhook_deliveries.payload,
+		webhook_deliveries.status, webhook_deliveries.attempts, webhook_deliveries.next_attempt_at,
+		webhook_deliveries.last_status_code, webhook_deliveries.last_error, webhook_deliveries.delivered_at,
+		webhook_deliveries.created_at, webhook_deliveries.updated_at`
+)
+
+func (r *webhookRepo) Create(ctx context.Context, webhook *domain.Webhook) (*domain.Webhook, error) {
+	query := `
+		INSERT INTO webhooks (uuid, user_id, url, secret, events)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + webhookColumns
+
+	events := make([]string, 0, len(webhook.Events))
+	for _, event := range webhook.Events {
+		events = append(events, string(event))
+	}
+
+	var webhookEntity entity.Webhook
+	err := r.DB.Get(ctx, &webhookEntity, query,
+		webhook.UUID,
+		webhook.UserID,
+		webhook.URL,
+		webhook.Secret,
+		strings.Join(events, ","),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return webhookEntity.ToDomain(), nil
+}
+
+func (r *webhookRepo) Delete(ctx context.Context, userID uint, uuid string) error {
+	query := `UPDATE webhooks SET deleted_at = $1 WHERE uuid = $2 AND user_id = $3 AND deleted_at IS NULL`
+	_, err := r.DB.Exec(ctx, query, time.Now().UTC(), uuid, userID)
+
+	return err
+}
+
+func (r *webhookRepo) ByUUID(ctx context.Context, userID uint, uuid string) (*domain.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE uuid = $1 AND user_id = $2 AND deleted_at IS NULL`
+
+	var webhookEntity entity.Webhook
+	err := r.DB.Get_RO(ctx, &webhookEntity, query, uuid, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return webhookEntity.ToDomain(), nil
+}
+
+func (r *webhookRepo) All(ctx context.Context, userID uint) ([]*domain.Webhook, error) {
+	query := `
+		SELECT ` + webhookColumns + `
+			FROM webhooks
+		WHERE user_id = $1
+			AND deleted_at IS NULL
+		ORDER BY created_at`
+
+	var webhookEntities []*entity.Webhook
+	err := r.DB.Select_RO(ctx, &webhookEntities, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks := make([]*domain.Webhook, 0, len(webhookEntities))
+	for _, webhookEntity := range webhookEntities {
+		webhooks = append(webhooks, webhookEntity.ToDomain())
+	}
+
+	return webhooks, nil
+}
+
+func (r *webhookRepo) CreateDeliveries(ctx context.Context, deliveries []*domain.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	return r.DB.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		query := `
+			INSERT INTO webhook_deliveries (uuid, webhook_id, event, payload, next_attempt_at)
+			VALUES ($1, $2, $3, $4, $5)`
+		for _, delivery := range deliveries {
+			_, err := tx.Exec(ctx, query,
+				delivery.UUID,
+				delivery.Webhook.ID,
+				string(delivery.Event),
+				string(delivery.Payload),
+				delivery.NextAttemptAt.UTC(),
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *webhookRepo) Due(ctx context.Context, now time.Time, limit int) ([]*domain.WebhookDelivery, error) {
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+			FROM webhook_deliveries
+		JOIN webhooks
+			ON webhooks.id = webhook_deliveries.webhook_id
+		WHERE webhook_deliveries.status = 'pending'
+			AND webhook_deliveries.next_attempt_at <= $1
+			AND webhooks.deleted_at IS NULL
+		ORDER BY webhook_deliveries.next_attempt_at
+		LIMIT $2`
+
+	return r.selectDeliveries(ctx, query, now.UTC(), limit)
+}
+
+func (r *webhookRepo) Claim(ctx context.Context, delivery *domain.WebhookDelivery, nextAttemptAt time.Time) error {
+	query := `
+		UPDATE webhook_deliveries SET next_attempt_at = $1, attempts = attempts + 1
+		WHERE id = $2
+			AND next_attempt_at = $3
+			AND status = 'pending'
+		RETURNING id`
+
+	var id uint
+	return r.DB.Get(ctx, &id, query, nextAttemptAt.UTC(), delivery.ID, delivery.NextAttemptAt.UTC())
+}
+
+func (r *webhookRepo) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+			SET status = $1, next_attempt_at = $2, last_status_code = $3, last_error = $4, delivered_at = $5
+		WHERE id = $6`
+
+	var statusCode, lastError interface{}
+	if delivery.LastStatusCode != 0 {
+		statusCode = delivery.LastStatusCode
+	}
+	if delivery.LastError != "" {
+		lastError = delivery.LastError
+	}
+
+	_, err := r.DB.Exec(ctx, query,
+		string(delivery.Status),
+		delivery.NextAttemptAt.UTC(),
+		statusCode,
+		lastError,
+		nullTime(delivery.DeliveredAt),
+		delivery.ID,
+	)
+
+	return err
+}
+
+func (r *webhookRepo) Hold(ctx context.Context, delivery *domain.WebhookDelivery, until time.Time, reason string) error {
+	query := `
+		UPDATE webhook_deliveries SET next_attempt_at = $1, attempts = attempts - 1, last_error = $2
+		WHERE id = $3`
+
+	_, err := r.DB.Exec(ctx, query, until.UTC(), reason, delivery.ID)
+	return err
+}
+
+func (r *webhookRepo) Deliveries(
+	ctx context.Context,
+	webhookID uint,
+	page *pagination.Page,
+) ([]*domain.WebhookDelivery, *pagination.Cursor, error) {
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+			FROM webhook_deliveries
+		JOIN webhooks
+			ON webhooks.id = webhook_deliveries.webhook_id
+		WHERE webhook_deliveries.webhook_id = $1`
+	args := []interface{}{webhookID}
+
+	cursor, err := page.After("", 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cursor != nil {
+		args = append(args, cursor.ID)
+		query += fmt.Sprintf(` AND webhook_deliveries.id < $%d`, len(args))
+	}
+
+	query += ` ORDER BY webhook_deliveries.id DESC`
+	if page != nil {
+		args = append(args, page.FetchLimit())
+		query += fmt.Sprintf(` LIMIT $%d`, len(args))
+	}
+
+	deliveries, err := r.selectDeliveries(ctx, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	deliveries, next := pagination.Trim(deliveries, page, func(delivery *domain.WebhookDelivery) *pagination.Cursor {
+		return &pagination.Cursor{ID: delivery.ID}
+	})
+
+	return deliveries, next, nil
+}
+
+func (r *webhookRepo) selectDeliveries(ctx context.Context, query string, args ...interface{}) ([]*domain.WebhookDelivery, error) {
+	var deliveryEntities []*entity.WebhookDelivery
+	err := r.DB.Select(ctx, &deliveryEntities, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]*domain.WebhookDelivery, 0, len(deliveryEntities))
+	for _, deliveryEntity := range deliveryEntities {
+		deliveries = append(deliveries, deliveryEntity.ToDomain())
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,249 @@
+package repo
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/entity"
+)
+
+// WorkspaceRepo is the directory of the provisioned workspaces, like the region directory it
+// always uses the home database.
+type WorkspaceRepo interface {
+	// Upsert creates the workspace of slug or updates its name and plan and reports whether it
+	// was created, it returns sql.ErrNoRows when the slug belongs to another owner.
+	Upsert(ctx context.Context, slug string, provision *domain.WorkspaceProvision, ownerUUID string) (bool, error)
+	// SetQuotas returns sql.ErrNoRows when there is no such workspace.
+	SetQuotas(ctx context.Context, slug string, quotas domain.Quotas) error
+	// SetSSO returns sql.ErrNoRows when there is no such workspace.
+	SetSSO(ctx context.Context, slug string, sso *domain.SSOConfig) error
+
+	BySlug(ctx context.Context, slug string) (*domain.Workspace, error)
+	ByOwner(ctx context.Context, ownerUUID string) (*domain.Workspace, error)
+
+	// AddUsage counts one more use of resource in the month of period and returns the usage,
+	// it returns sql.ErrNoRows without counting when the usage reached limit. A limit of 0 is
+	// unlimited.
+	AddUsage(ctx context.Context, workspaceID uint, resource domain.QuotaResource, period time.Time, limit int) (int, error)
+	// Usage returns the usage of the metered resources in the month of period.
+	Usage(ctx context.Context, workspaceID uint, period time.Time) (map[domain.QuotaResource]int, error)
+	// MarkWarned records that the owner was warned about resource in the month of period, it
+	// returns sql.ErrNoRows when the warning was already sent.
+	MarkWarned(ctx context.Context, workspaceID uint, resource domain.QuotaResource, period time.Time) error
+
+	SetWorkingHours(ctx context.Context, workspaceID uint, hours *domain.WorkingHours) error
+	// Holidays returns the holidays of the workspace ordered by date.
+	Holidays(ctx context.Context, workspaceID uint) ([]*domain.Holiday, error)
+	// CreateHoliday returns sql.ErrNoRows when the workspace already has a holiday on the date.
+	CreateHoliday(ctx context.Context, holiday *domain.Holiday) (*domain.Holiday, error)
+	UpdateHoliday(ctx context.Context, holiday *domain.Holiday) error
+	// DeleteHoliday returns sql.ErrNoRows when there is no such holiday.
+	DeleteHoliday(ctx context.Context, workspaceID uint, uuid string) error
+}
+
+type workspaceRepo struct {
+	DB db.DB
+}
+
+func NewWorkspaceRepo(db db.DB) *workspaceRepo {
+	return &workspaceRepo{
+		DB: db,
+	}
+}
+
+var _ WorkspaceRepo = (*workspaceRepo)(nil)
+
+const workspaceSelect = `
+	SELECT workspaces.*,
+		workspace_sso.issuer_url AS sso_issuer_url,
+		workspace_sso.client_id AS sso_client_id,
+		workspace_sso.client_secret AS sso_client_secret,
+		workspace_sso.domains AS sso_domains,
+		workspace_sso.updated_at AS sso_updated_at
+	FROM workspaces
+	LEFT JOIN workspace_sso ON workspace_sso.workspace_id = workspaces.id`
+
+func (r *workspaceRepo) Upsert(ctx context.Context, slug string, provision *domain.WorkspaceProvision, ownerUUID string) (bool, error) {
+	// xmax is only 0 for inserted rows
+	query := `
+		INSERT INTO workspaces (slug, name, plan, owner_uuid, region)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (slug) DO UPDATE SET name = EXCLUDED.name, plan = EXCLUDED.plan, updated_at = $6
+		WHERE workspaces.owner_uuid = EXCLUDED.owner_uuid
+		RETURNING xmax = 0`
+
+	var created bool
+	err := r.DB.Get(ctx, &created, query, slug, provision.Name, provision.Plan, ownerUUID, provision.Region, time.Now().UTC())
+
+	return created, err
+}
+
+func (r *workspaceRepo) SetQuotas(ctx context.Context, slug string, quotas domain.Quotas) error {
+	query := `
+		UPDATE workspaces SET
+			max_members = $1,
+			max_lists = $2,
+			max_webhook_deliveries = $3,
+			max_api_calls = $4,
+			max_integration_syncs = $5,
+			updated_at = $6
+		WHERE slug = $7
+		RETURNING id`
+
+	var id uint
+	return r.DB.Get(ctx, &id, query, quotas.MaxMembers, quotas.MaxLists,
+		quotas.MaxWebhookDeliveries, quotas.MaxAPICalls, quotas.MaxIntegrationSyncs, time.Now().UTC(), slug)
+}
+
+func (r *workspaceRepo) SetSSO(ctx context.Context, slug string, sso *domain.SSOConfig) error {
+	query := `
+		INSERT INTO workspace_sso (workspace_id, issuer_url, client_id, client_secret, domains, updated_at)
+		SELECT id, $2, $3, $4, $5, $6 FROM workspaces WHERE slug = $1
+		ON CONFLICT (workspace_id) DO UPDATE SET
+			issuer_url = EXCLUDED.issuer_url,
+			client_id = EXCLUDED.client_id,
+			client_secret = EXCLUDED.client_secret,
+			domains = EXCLUDED.domains,
+			updated_at = EXCLUDED.updated_at
+		RETURNING workspace_id`
+
+	var id uint
+	return r.DB.Get(ctx, &id, query,
+		slug, sso.IssuerURL, sso.ClientID, sso.ClientSecret, strings.Join(sso.Domains, ","), time.Now().UTC(),
+	)
+}
+
+func (r *workspaceRepo) BySlug(ctx context.Context, slug string) (*domain.Workspace, error) {
+	var workspaceEntity entity.Workspace
+	if err := r.DB.Get(ctx, &workspaceEntity, workspaceSelect+` WHERE workspaces.slug = $1`, slug); err != nil {
+		return nil, err
+	}
+
+	return workspaceEntity.ToDomain(), nil
+}
+
+func (r *workspaceRepo) ByOwner(ctx context.Context, ownerUUID string) (*domain.Workspace, error) {
+	var workspaceEntity entity.Workspace
+	if err := r.DB.Get_RO(ctx, &workspaceEntity, workspaceSelect+` WHERE workspaces.owner_uuid = $1`, ownerUUID); err != nil {
+		return nil, err
+	}
+
+	return workspaceEntity.ToDomain(), nil
+}
+
+func (r *workspaceRepo) AddUsage(ctx context.Context, workspaceID uint, resource domain.QuotaResource, period time.Time, limit int) (int, error) {
+	// the limit is checked in the upsert, concurrent requests of several instances can't
+	// overshoot it
+	query := `
+		INSERT INTO workspace_usage (workspace_id, resource, period, used)
+		VALUES ($1, $2, $3, 1)
+		ON CONFLICT (workspace_id, resource, period) DO UPDATE SET used = workspace_usage.used + 1
+		WHERE $4 = 0 OR workspace_usage.used < $4
+		RETURNING used`
+
+	var used int
+	err := r.DB.Get(ctx, &used, query, workspaceID, string(resource), period.UTC(), limit)
+
+	return used, err
+}
+
+func (r *workspaceRepo) Usage(ctx context.Context, workspaceID uint, period time.Time) (map[domain.QuotaResource]int, error) {
+	query := `SELECT resource, used FROM workspace_usage WHERE workspace_id = $1 AND period = $2`
+
+	var rows []*entity.WorkspaceUsage
+	if err := r.DB.Select(ctx, &rows, query, workspaceID, period.UTC()); err != nil {
+		return nil, err
+	}
+
+	usage := make(map[domain.QuotaResource]int, len(rows))
+	for _, row := range rows {
+		usage[domain.QuotaResource(row.Resource)] = row.Used
+	}
+
+	return usage, nil
+}
+
+func (r *workspaceRepo) MarkWarned(ctx context.Context, workspaceID uint, resource domain.QuotaResource, period time.Time) error {
+	query := `
+		UPDATE workspace_usage SET warned_at = $1
+		WHERE workspace_id = $2 AND resource = $3 AND period = $4 AND warned_at IS NULL
+		RETURNING workspace_id`
+
+	var id uint
+	return r.DB.Get(ctx, &id, query, time.Now().UTC(), workspaceID, string(resource), period.UTC())
+}
+
+func (r *workspaceRepo) SetWorkingHours(ctx context.Context, workspaceID uint, hours *domain.WorkingHours) error {
+	query := `
+		UPDATE workspaces SET
+			work_start_minute = $1,
+			work_end_minute = $2,
+			work_days = $3,
+			timezone = $4,
+			updated_at = $5
+		WHERE id = $6`
+
+	_, err := r.DB.Exec(ctx, query,
+		int(hours.Start/time.Minute),
+		int(hours.End/time.Minute),
+		entity.FormatWeekdays(hours.Days),
+		hours.Timezone,
+		time.Now().UTC(),
+		workspaceID,
+	)
+
+	return err
+}
+
+func (r *workspaceRepo) Holidays(ctx context.Context, workspaceID uint) ([]*domain.Holiday, error) {
+	query := `SELECT * FROM workspace_holidays WHERE workspace_id = $1 ORDER BY date, id`
+
+	var holidayEntities []*entity.Holiday
+	if err := r.DB.Select_RO(ctx, &holidayEntities, query, workspaceID); err != nil {
+		return nil, err
+	}
+
+	holidays := make([]*domain.Holiday, 0, len(holidayEntities))
+	for _, holidayEntity := range holidayEntities {
+		holidays = append(holidays, holidayEntity.ToDomain())
+	}
+
+	return holidays, nil
+}
+
+func (r *workspaceRepo) CreateHoliday(ctx context.Context, holiday *domain.Holiday) (*domain.Holiday, error) {
+	query := `
+		INSERT INTO workspace_holidays (uuid, workspace_id, date, name, recurring)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (workspace_id, date) DO NOTHING
+		RETURNING *`
+
+	var holidayEntity entity.Holiday
+	err := r.DB.Get(ctx, &holidayEntity, query,
+		holiday.UUID, holiday.WorkspaceID, holiday.Date.Format(domain.DateLayout), holiday.Name, holiday.Recurring,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return holidayEntity.ToDomain(), nil
+}
+
+func (r *workspaceRepo) UpdateHoliday(ctx context.Context, holiday *domain.Holiday) error {
+	query := `UPDATE workspace_holidays SET date = $1, name = $2, recurring = $3 WHERE id = $4 AND workspace_id = $5`
+	_, err := r.DB.Exec(ctx, query,
+		holiday.Date.Format(domain.DateLayout), holiday.Name, holiday.Recurring, holiday.ID, holiday.WorkspaceID,
+	)
+
+	return err
+}
+
+func (r *workspaceRepo) DeleteHoliday(ctx context.Context, workspaceID uint, uuid string) error {
+	query := `DELETE FROM workspace_holidays WHERE workspace_id = $1 AND uuid = $2 RETURNING id`
+
+	var id uint
+	return r.DB.Get(ctx, &id, query, workspaceID, uuid)
+}
@@ -0,0 +1,74 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+
+	todov1 "github.com/meowmix1337/the_recipe_book/api/proto/todo/v1"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type authServer struct {
+	todov1.UnimplementedAuthServiceServer
+
+	userService service.UserService
+}
+
+func (s *authServer) Login(ctx context.Context, req *todov1.LoginRequest) (*todov1.LoginResponse, error) {
+	if (req.GetEmail() == "" && req.GetUsername() == "") || req.GetPassword() == "" {
+		return nil, status.Error(codes.InvalidArgument, "email or username and password are required")
+	}
+
+	token, err := s.userService.Login(ctx, &domain.UserCredentials{
+		Email:    req.GetEmail(),
+		Username: req.GetUsername(),
+		Password: req.GetPassword(),
+	})
+	if err != nil {
+		// we want to mask the actual error to the caller
+		if errors.Is(err, domain.ErrInvalidCredentials) ||
+			errors.Is(err, domain.ErrNoCredentialsProvided) ||
+			errors.Is(err, domain.ErrUserNotFound) {
+			return nil, status.Error(codes.Unauthenticated, "Unauthorized")
+		}
+		return nil, statusError(err)
+	}
+
+	return &todov1.LoginResponse{Token: token.Token, RefreshToken: token.RefreshToken}, nil
+}
+
+func (s *authServer) RefreshToken(ctx context.Context, req *todov1.RefreshTokenRequest) (*todov1.RefreshTokenResponse, error) {
+	session, err := callSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &domain.User{
+		ID:    session.claims.UserID,
+		Email: session.claims.Email,
+		UUID:  session.claims.UUID,
+	}
+	token, err := s.userService.RefreshToken(ctx, session.token, user, req.GetRefreshToken(), session.claims.ExpiresAt.Time)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &todov1.RefreshTokenResponse{Token: token.Token, RefreshToken: token.RefreshToken}, nil
+}
+
+func (s *authServer) Logout(ctx context.Context, _ *todov1.LogoutRequest) (*todov1.LogoutResponse, error) {
+	session, err := callSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.userService.Logout(ctx, session.token, session.claims); err != nil {
+		return nil, statusError(err)
+	}
+
+	return &todov1.LogoutResponse{}, nil
+}
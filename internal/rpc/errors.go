@@ -0,0 +1,44 @@
+package rpc
+
+import (
+	"errors"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+//nolint:gochecknoglobals // lookup table
+var kindCodes = map[domain.ErrorKind]codes.Code{
+	domain.KindValidation:   codes.InvalidArgument,
+	domain.KindUnauthorized: codes.Unauthenticated,
+	domain.KindForbidden:    codes.PermissionDenied,
+	domain.KindNotFound:     codes.NotFound,
+	domain.KindConflict:     codes.AlreadyExists,
+	domain.KindTooLarge:     codes.ResourceExhausted,
+	domain.KindRateLimited:  codes.ResourceExhausted,
+}
+
+// statusError maps the errors of the service layer to status codes by their kind like the error
+// handler of the HTTP API, unexpected errors are masked as internal.
+func statusError(err error) error {
+	switch {
+	// the reason a login failed is masked
+	case errors.Is(err, domain.ErrUnauthorized):
+		return status.Error(codes.Unauthenticated, "Unauthorized")
+	case errors.Is(err, domain.ErrUnknownRegion):
+		return status.Error(codes.FailedPrecondition, domain.ErrUnknownRegion.Error())
+	// a stale version is retried after reading the todo again
+	case errors.Is(err, domain.ErrConflict):
+		return status.Error(codes.Aborted, err.Error())
+	}
+
+	if code, ok := kindCodes[domain.KindOf(err)]; ok {
+		return status.Error(code, err.Error())
+	}
+
+	log.Err(err).Msg("error handling grpc call")
+	return status.Error(codes.Internal, "Internal Server Error")
+}
@@ -0,0 +1,146 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	todov1 "github.com/meowmix1337/the_recipe_book/api/proto/todo/v1"
+	"github.com/meowmix1337/the_recipe_book/internal/api/middleware"
+	"github.com/meowmix1337/the_recipe_book/internal/metrics"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// publicMethods can be called without an access token.
+//
+//nolint:gochecknoglobals // read-only
+var publicMethods = map[string]bool{
+	todov1.AuthService_Login_FullMethodName: true,
+}
+
+// sessionKey is the context key of the session of an authenticated call.
+type sessionKey struct{}
+
+// session holds the access token of a call and its claims.
+type session struct {
+	claims *domain.JWTCustomClaims
+	token  string
+}
+
+// callSession returns the session authInterceptor attached to the context of the call.
+func callSession(ctx context.Context) (*session, error) {
+	s, ok := ctx.Value(sessionKey{}).(*session)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, domain.ErrUnableToVerifyClaim.Error())
+	}
+
+	return s, nil
+}
+
+// authInterceptor verifies the bearer token in the authorization metadata like JWTMiddleware
+// and routes the queries of the call to the data region of the token like RegionMiddleware.
+func authInterceptor(auth *Auth) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if publicMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		token := bearerToken(ctx)
+		if token == "" {
+			return nil, status.Error(codes.Unauthenticated, "Unauthorized")
+		}
+
+		claims, err := middleware.VerifyJWT(ctx, auth.Blacklist, token, auth.Keys)
+		if err != nil {
+			var validationErr *jwt.ValidationError
+			if errors.Is(err, echo.ErrUnauthorized) || errors.As(err, &validationErr) {
+				return nil, status.Error(codes.Unauthenticated, "Unauthorized")
+			}
+			log.Err(err).Msg("error verifying access token")
+			return nil, status.Error(codes.Internal, "Internal Server Error")
+		}
+
+		if claims.Region != region.Home {
+			if !auth.Serves(claims.Region) {
+				return nil, status.Error(codes.FailedPrecondition, domain.ErrUnknownRegion.Error())
+			}
+			ctx = region.WithRegion(ctx, claims.Region)
+		}
+
+		ctx = context.WithValue(ctx, middleware.UserIDContextKey, claims.UserID)
+		ctx = context.WithValue(ctx, sessionKey{}, &session{claims: claims, token: token})
+
+		return handler(ctx, req)
+	}
+}
+
+// bearerToken returns the token of the authorization metadata, empty when there is none.
+func bearerToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return ""
+	}
+
+	return strings.TrimPrefix(values[0], "Bearer ")
+}
+
+func loggingInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+
+	event := log.Info()
+	if status.Code(err) == codes.Internal || status.Code(err) == codes.Unknown {
+		event = log.Error()
+	}
+	event.
+		Str("method", info.FullMethod).
+		Str("code", status.Code(err).String()).
+		Dur("duration", time.Since(start)).
+		Msg("grpc call")
+
+	return resp, err
+}
+
+// metricsInterceptor records the duration and code of every call in GRPCRequestDuration.
+func metricsInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+
+	metrics.GRPCRequestDuration.WithLabelValues(info.FullMethod, status.Code(err).String()).
+		Observe(time.Since(start).Seconds())
+
+	return resp, err
+}
+
+// recoverInterceptor turns a panic into an internal error, a panicking call would take the whole
+// server down otherwise.
+func recoverInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (resp any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error().Interface("panic", r).Str("method", info.FullMethod).Msg("recovered grpc call")
+			err = status.Error(codes.Internal, "Internal Server Error")
+		}
+	}()
+
+	return handler(ctx, req)
+}
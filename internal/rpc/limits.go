@@ -0,0 +1,115 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/meowmix1337/the_recipe_book/internal/api/middleware"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/ratelimit"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Limits are the controls the REST API applies on top of auth, calls go through the same
+// services so neither API is a way around the other.
+type Limits struct {
+	Store ratelimit.Store
+	// Anonymous limits the public methods like Login per client IP, User the other calls per
+	// user.
+	Anonymous ratelimit.Limit
+	User      ratelimit.Limit
+	// IPAllowlist and Consume are the checks of IPAllowlistMiddleware and UsageMiddleware.
+	IPAllowlist middleware.IPAllowlistChecker
+	Consume     middleware.UsageConsumer
+}
+
+// anonymousRateLimitInterceptor limits the public methods per client IP like the login route.
+func anonymousRateLimitInterceptor(limits *Limits) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !publicMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+		if err := take(ctx, limits.Store, limits.Anonymous, "ip_"+peerIP(ctx)); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// limitsInterceptor applies the user rate limit, the IP allowlist and the usage quota to the
+// authenticated calls in the order of the REST middleware. It must come after authInterceptor.
+func limitsInterceptor(limits *Limits) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		s, err := callSession(ctx)
+		if err != nil {
+			return handler(ctx, req)
+		}
+		claims := s.claims
+
+		bucket := fmt.Sprintf("user_%v_%v", claims.Region, claims.UserID)
+		if err = take(ctx, limits.Store, limits.User, bucket); err != nil {
+			return nil, err
+		}
+
+		ip := peerIP(ctx)
+		if err = limits.IPAllowlist(ctx, claims.UserID, ip); err != nil {
+			if errors.Is(err, domain.ErrIPNotAllowed) {
+				return nil, status.Error(codes.PermissionDenied, domain.ErrIPNotAllowed.Error())
+			}
+			log.Err(err).Msg("error checking IP allowlist")
+			return nil, status.Error(codes.Internal, "Internal Server Error")
+		}
+
+		if err = limits.Consume(ctx, claims.UserID, domain.QuotaAPICalls); err != nil {
+			if errors.Is(err, domain.ErrUsageLimitExceeded) {
+				return nil, status.Error(codes.ResourceExhausted, err.Error())
+			}
+			log.Err(err).Msg("error counting API call")
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// take takes a token from bucket like RateLimitMiddleware, calls are let through when the store
+// fails.
+func take(ctx context.Context, store ratelimit.Store, limit ratelimit.Limit, bucket string) error {
+	if !limit.Enabled() {
+		return nil
+	}
+
+	result, err := store.Take(ctx, bucket, limit)
+	if err != nil {
+		log.Err(err).Str("bucket", bucket).Msg("error taking rate limit token")
+		return nil
+	}
+	if !result.Allowed {
+		return status.Errorf(codes.ResourceExhausted, "Too Many Requests, retry after %s", result.RetryAfter)
+	}
+
+	return nil
+}
+
+// peerIP is the IP of the client of the call. Unlike the REST API no forwarded header is
+// trusted, the gRPC port is meant to be reached directly by internal services.
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+
+	return host
+}
@@ -0,0 +1,69 @@
+// Package rpc serves the gRPC API for internal services, see api/proto/todo/v1. It shares the
+// service layer with the REST API, so both apply the same rules.
+package rpc
+
+import (
+	"context"
+
+	todov1 "github.com/meowmix1337/the_recipe_book/api/proto/todo/v1"
+	"github.com/meowmix1337/the_recipe_book/internal/api/middleware"
+	"github.com/meowmix1337/the_recipe_book/internal/blacklist"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"google.golang.org/grpc"
+)
+
+// Auth verifies the access tokens of the calls.
+type Auth struct {
+	Keys      middleware.JWTKeyResolver
+	Blacklist blacklist.Blacklist
+	// Serves reports whether this instance serves a data region, calls with tokens of other
+	// regions are rejected rather than falling back to the home database.
+	Serves func(region string) bool
+}
+
+// Services are the services the gRPC services are implemented with.
+type Services struct {
+	UserService  service.UserService
+	AdminService service.AdminService
+	TodoService  service.TodoService
+}
+
+// NewServer returns a server with the auth, user and todo services registered. Every call is
+// recovered, logged, measured, rate limited, authenticated and checked against the limits of the
+// user in that order.
+func NewServer(auth *Auth, limits *Limits, services *Services) *grpc.Server {
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		recoverInterceptor,
+		loggingInterceptor,
+		metricsInterceptor,
+		anonymousRateLimitInterceptor(limits),
+		authInterceptor(auth),
+		limitsInterceptor(limits),
+	))
+
+	todov1.RegisterAuthServiceServer(server, &authServer{userService: services.UserService})
+	todov1.RegisterUserServiceServer(server, &userServer{
+		userService:  services.UserService,
+		adminService: services.AdminService,
+	})
+	todov1.RegisterTodoServiceServer(server, &todoServer{todoService: services.TodoService})
+
+	return server
+}
+
+// Shutdown stops accepting calls and waits for those in progress, calls still running when ctx
+// is done are cut off.
+func Shutdown(ctx context.Context, server *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		server.Stop()
+	}
+}
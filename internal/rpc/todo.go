@@ -0,0 +1,231 @@
+package rpc
+
+import (
+	"context"
+	"time"
+
+	todov1 "github.com/meowmix1337/the_recipe_book/api/proto/todo/v1"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/pagination"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// maxTitleLength and maxEstimate match the validation of the REST requests.
+	maxTitleLength = 255
+	maxEstimate    = 24 * time.Hour
+)
+
+type todoServer struct {
+	todov1.UnimplementedTodoServiceServer
+
+	todoService service.TodoService
+}
+
+func (s *todoServer) CreateTodo(ctx context.Context, req *todov1.CreateTodoRequest) (*todov1.CreateTodoResponse, error) {
+	session, err := callSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.GetTitle() == "" || len(req.GetTitle()) > maxTitleLength {
+		return nil, status.Errorf(codes.InvalidArgument, "title is required and at most %d characters", maxTitleLength)
+	}
+
+	todoCreate := &domain.TodoCreate{
+		ListUUID:    req.GetListUuid(),
+		Title:       req.GetTitle(),
+		Description: req.GetDescription(),
+		Priority:    domain.PriorityMedium,
+		Tags:        req.GetTags(),
+	}
+	if req.GetPriority() != "" {
+		if todoCreate.Priority, err = parsePriority(req.GetPriority()); err != nil {
+			return nil, err
+		}
+	}
+	if req.GetDueDate() != nil {
+		todoCreate.DueDate = req.GetDueDate().AsTime()
+	}
+	if req.GetEstimate() != nil {
+		if todoCreate.Estimate, err = parseEstimate(req.GetEstimate()); err != nil {
+			return nil, err
+		}
+	}
+
+	todo, err := s.todoService.Create(ctx, session.claims.UserID, todoCreate)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &todov1.CreateTodoResponse{Todo: newTodo(todo)}, nil
+}
+
+func (s *todoServer) GetTodo(ctx context.Context, req *todov1.GetTodoRequest) (*todov1.GetTodoResponse, error) {
+	session, err := callSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	todo, err := s.todoService.ByUUID(ctx, session.claims.UserID, req.GetUuid())
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &todov1.GetTodoResponse{Todo: newTodo(todo)}, nil
+}
+
+func (s *todoServer) UpdateTodo(ctx context.Context, req *todov1.UpdateTodoRequest) (*todov1.UpdateTodoResponse, error) {
+	session, err := callSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	todoUpdate := &domain.TodoUpdate{
+		Description: req.Description,
+		Completed:   req.Completed,
+	}
+	if req.Title != nil {
+		if *req.Title == "" || len(*req.Title) > maxTitleLength {
+			return nil, status.Errorf(codes.InvalidArgument, "title must be 1 to %d characters", maxTitleLength)
+		}
+		todoUpdate.Title = req.Title
+	}
+	if req.Priority != nil {
+		priority, err := parsePriority(*req.Priority)
+		if err != nil {
+			return nil, err
+		}
+		todoUpdate.Priority = &priority
+	}
+	if req.GetDueDate() != nil {
+		dueDate := req.GetDueDate().AsTime()
+		todoUpdate.DueDate = &dueDate
+	}
+	if req.GetEstimate() != nil {
+		estimate, err := parseEstimate(req.GetEstimate())
+		if err != nil {
+			return nil, err
+		}
+		todoUpdate.Estimate = &estimate
+	}
+	if req.Version != nil {
+		version := int(*req.Version)
+		todoUpdate.Version = &version
+	}
+
+	todo, err := s.todoService.Update(ctx, session.claims.UserID, req.GetUuid(), todoUpdate)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &todov1.UpdateTodoResponse{Todo: newTodo(todo)}, nil
+}
+
+func (s *todoServer) DeleteTodo(ctx context.Context, req *todov1.DeleteTodoRequest) (*todov1.DeleteTodoResponse, error) {
+	session, err := callSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.todoService.Delete(ctx, session.claims.UserID, req.GetUuid()); err != nil {
+		return nil, statusError(err)
+	}
+
+	return &todov1.DeleteTodoResponse{}, nil
+}
+
+func (s *todoServer) ListTodos(ctx context.Context, req *todov1.ListTodosRequest) (*todov1.ListTodosResponse, error) {
+	session, err := callSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := pagination.NewPage(req.GetCursor(), int(req.GetLimit()))
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	filter := &domain.TodoFilter{
+		ListUUID: req.GetListUuid(),
+		Tags:     req.GetTags(),
+		TagGroup: req.GetTagGroup(),
+	}
+	if req.GetDueFrom() != nil {
+		filter.DueFrom = req.GetDueFrom().AsTime()
+	}
+	if req.GetDueBefore() != nil {
+		filter.DueBefore = req.GetDueBefore().AsTime()
+	}
+
+	todos, next, err := s.todoService.All(ctx, session.claims.UserID, filter, page)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	resp := &todov1.ListTodosResponse{
+		Todos:      make([]*todov1.Todo, 0, len(todos)),
+		NextCursor: next.Encode(),
+	}
+	for _, todo := range todos {
+		resp.Todos = append(resp.Todos, newTodo(todo))
+	}
+
+	return resp, nil
+}
+
+func parsePriority(name string) (domain.Priority, error) {
+	priority, err := domain.ParsePriority(name)
+	if err != nil {
+		return 0, status.Error(codes.InvalidArgument, "priority must be one of low, medium, high or urgent")
+	}
+
+	return priority, nil
+}
+
+func parseEstimate(estimate *durationpb.Duration) (time.Duration, error) {
+	if err := estimate.CheckValid(); err != nil || estimate.AsDuration() < 0 || estimate.AsDuration() > maxEstimate {
+		return 0, status.Errorf(codes.InvalidArgument, "estimate must be between 0 and %s", maxEstimate)
+	}
+
+	// estimates are stored in minutes
+	return estimate.AsDuration().Truncate(time.Minute), nil
+}
+
+func newTodo(todo *domain.Todo) *todov1.Todo {
+	resp := &todov1.Todo{
+		Uuid:        todo.UUID,
+		ListUuid:    todo.ListUUID,
+		Title:       todo.Title,
+		Description: todo.Description,
+		Priority:    todo.Priority.String(),
+		DueDate:     timestamp(todo.DueDate),
+		CompletedAt: timestamp(todo.CompletedAt),
+		Tags:        make([]string, 0, len(todo.Tags)),
+		Version:     int32(todo.Version),
+		CreatedAt:   timestamp(todo.CreatedAt),
+		UpdatedAt:   timestamp(todo.UpdatedAt),
+	}
+	if todo.Estimate > 0 {
+		resp.Estimate = durationpb.New(todo.Estimate)
+	}
+	for _, tag := range todo.Tags {
+		resp.Tags = append(resp.Tags, tag.Name)
+	}
+
+	return resp
+}
+
+// timestamp leaves unset times out of the message rather than sending the zero time.
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+
+	return timestamppb.New(t)
+}
@@ -0,0 +1,72 @@
+package rpc
+
+import (
+	"context"
+
+	todov1 "github.com/meowmix1337/the_recipe_book/api/proto/todo/v1"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/pagination"
+	"github.com/meowmix1337/the_recipe_book/internal/service"
+)
+
+type userServer struct {
+	todov1.UnimplementedUserServiceServer
+
+	userService  service.UserService
+	adminService service.AdminService
+}
+
+func (s *userServer) GetCurrentUser(ctx context.Context, _ *todov1.GetCurrentUserRequest) (*todov1.GetCurrentUserResponse, error) {
+	session, err := callSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userService.ByID(ctx, session.claims.UserID)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &todov1.GetCurrentUserResponse{User: newUser(user)}, nil
+}
+
+func (s *userServer) ListUsers(ctx context.Context, req *todov1.ListUsersRequest) (*todov1.ListUsersResponse, error) {
+	session, err := callSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.adminService.Authorize(ctx, session.claims.UserID, domain.AdminActionListUsers); err != nil {
+		return nil, statusError(err)
+	}
+
+	page, err := pagination.NewPage(req.GetCursor(), int(req.GetLimit()))
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	users, next, err := s.userService.All(ctx, page)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	resp := &todov1.ListUsersResponse{
+		Users:      make([]*todov1.User, 0, len(users)),
+		NextCursor: next.Encode(),
+	}
+	for _, user := range users {
+		resp.Users = append(resp.Users, newUser(user))
+	}
+
+	return resp, nil
+}
+
+func newUser(user *domain.User) *todov1.User {
+	return &todov1.User{
+		Uuid:      user.UUID,
+		Email:     user.Email,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		CreatedAt: timestamp(user.CreatedAt),
+	}
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/metrics"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+	"github.com/meowmix1337/the_recipe_book/internal/storage"
+
+	"github.com/rs/zerolog/log"
+)
+
+// AccountDeletionService erases accounts on request of their users. A deleted account is
+// purged after a grace period during which the deletion can be cancelled.
+type AccountDeletionService interface {
+	// Schedule schedules the deletion of the account and returns when it is purged, deleting
+	// an account that is already scheduled keeps its date.
+	Schedule(ctx context.Context, userID uint) (time.Time, error)
+	Cancel(ctx context.Context, userID uint) error
+	// PurgeDue purges the accounts whose grace period is over, it is run by a background worker.
+	PurgeDue(ctx context.Context) error
+}
+
+// purgeBatchSize is how many accounts a run of the purge worker purges.
+const purgeBatchSize = 20
+
+type accountDeletionService struct {
+	*BaseService
+
+	userRepo       repo.UserRepo
+	userRegionRepo repo.UserRegionRepo
+	attachmentRepo repo.AttachmentRepo
+	auditRepo      repo.AuditRepo
+	takeoutRepo    repo.TakeoutRepo
+	storage        storage.Storage
+}
+
+func NewAccountDeletionService(
+	base *BaseService,
+	userRepo repo.UserRepo,
+	userRegionRepo repo.UserRegionRepo,
+	attachmentRepo repo.AttachmentRepo,
+	auditRepo repo.AuditRepo,
+	takeoutRepo repo.TakeoutRepo,
+	storage storage.Storage,
+) *accountDeletionService {
+	return &accountDeletionService{
+		BaseService:    base,
+		userRepo:       userRepo,
+		userRegionRepo: userRegionRepo,
+		attachmentRepo: attachmentRepo,
+		auditRepo:      auditRepo,
+		takeoutRepo:    takeoutRepo,
+		storage:        storage,
+	}
+}
+
+// check AccountDeletionService interface implementation on compile time.
+var _ AccountDeletionService = (*accountDeletionService)(nil)
+
+func (s *accountDeletionService) Schedule(ctx context.Context, userID uint) (time.Time, error) {
+	user, err := s.userRepo.ByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return time.Time{}, domain.ErrUserNotFound
+		}
+		log.Err(err).Msg("error retrieving user")
+		return time.Time{}, err
+	}
+	if user.IsChild() {
+		return time.Time{}, domain.ErrChildAccountDeletion
+	}
+
+	grace := time.Duration(s.Config.GetAccountDeletionGraceDays()) * 24 * time.Hour
+	purgeAt, err := s.userRepo.ScheduleDeletion(ctx, userID, time.Now().Add(grace))
+	if err != nil {
+		log.Err(err).Msg("error scheduling account deletion")
+		return time.Time{}, fmt.Errorf("error scheduling account deletion: %w", err)
+	}
+
+	return purgeAt, nil
+}
+
+func (s *accountDeletionService) Cancel(ctx context.Context, userID uint) error {
+	if err := s.userRepo.CancelDeletion(ctx, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrDeletionNotScheduled
+		}
+		log.Err(err).Msg("error cancelling account deletion")
+		return fmt.Errorf("error cancelling account deletion: %w", err)
+	}
+
+	return nil
+}
+
+func (s *accountDeletionService) PurgeDue(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	users, err := s.userRepo.PurgeDue(ctx, now, purgeBatchSize)
+	if err != nil {
+		return fmt.Errorf("error retrieving accounts to purge: %w", err)
+	}
+	metrics.WorkerQueueDepth.WithLabelValues("account_purge", metrics.RegionLabel(region.FromContext(ctx))).Set(float64(len(users)))
+
+	for _, user := range users {
+		if err = s.purge(ctx, user, now); err != nil {
+			log.Err(err).Str("user", user.UUID).Msg("error purging account")
+		}
+	}
+
+	return nil
+}
+
+// purge removes the account with its child accounts. Their rows go first, once they are gone the
+// purge can't be cancelled anymore and what is left is cleaned up best effort.
+func (s *accountDeletionService) purge(ctx context.Context, user *domain.User, now time.Time) error {
+	children, err := s.userRepo.Children(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	accounts := append([]*domain.User{user}, children...)
+
+	var keys []string
+	for _, account := range accounts {
+		accountKeys, err := s.attachmentRepo.StorageKeys(ctx, account.ID)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, accountKeys...)
+
+		if accountKeys, err = s.takeoutRepo.StorageKeys(ctx, account.ID); err != nil {
+			return err
+		}
+		keys = append(keys, accountKeys...)
+	}
+
+	if err = s.userRepo.Purge(ctx, user.ID, now); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// the deletion was cancelled since the account was read
+			return nil
+		}
+		return err
+	}
+
+	for _, key := range keys {
+		if err = s.storage.Delete(ctx, key); err != nil {
+			log.Err(err).Str("key", key).Msg("error deleting file of purged account")
+		}
+	}
+
+	// the entries of a user are logged in the database of their region, like their other data
+	for _, account := range accounts {
+		if err = s.auditRepo.Anonymize(ctx, account.ID, now); err != nil {
+			log.Err(err).Str("user", account.UUID).Msg("error anonymizing audit log of purged account")
+		}
+
+		key := loginKey("email", account.Email)
+		if account.IsChild() {
+			key = loginKey("username", account.Username)
+		}
+		if err = s.userRegionRepo.Delete(ctx, key); err != nil {
+			log.Err(err).Str("user", account.UUID).Msg("error releasing login of purged account")
+		}
+	}
+
+	log.Info().Str("user", user.UUID).Int("children", len(children)).Int("files", len(keys)).Msg("purged account")
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+// AdminService checks the admin scopes of staff accounts against the policy of each admin
+// action. The scopes are looked up on every check, so a revoked scope applies right away
+// instead of when the token expires.
+type AdminService interface {
+	// Authorize returns ErrAdminForbidden unless the user has a scope allowed to perform action.
+	Authorize(ctx context.Context, userID uint, action domain.AdminAction) error
+
+	// Scopes returns the scopes of the user with the given UUID, only superadmins may manage them.
+	Scopes(ctx context.Context, adminID uint, userUUID string) ([]domain.AdminScope, error)
+	// SetScopes replaces the scopes of the user with the given UUID.
+	SetScopes(ctx context.Context, adminID uint, userUUID string, scopes []domain.AdminScope) ([]domain.AdminScope, error)
+
+	// Grant replaces the scopes of a user without a policy check, it bootstraps the first
+	// superadmin from the command line.
+	Grant(ctx context.Context, userUUID string, scopes []domain.AdminScope) error
+}
+
+type adminService struct {
+	*BaseService
+
+	userRepo  repo.UserRepo
+	adminRepo repo.AdminRepo
+
+	securityEvents *SecurityEvents
+}
+
+func NewAdminService(base *BaseService, userRepo repo.UserRepo, adminRepo repo.AdminRepo, securityEvents *SecurityEvents) *adminService {
+	return &adminService{
+		BaseService:    base,
+		userRepo:       userRepo,
+		adminRepo:      adminRepo,
+		securityEvents: securityEvents,
+	}
+}
+
+// check AdminService interface implementation on compile time.
+var _ AdminService = (*adminService)(nil)
+
+func (s *adminService) Authorize(ctx context.Context, userID uint, action domain.AdminAction) error {
+	scopes, err := s.adminRepo.Scopes(ctx, userID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving admin scopes")
+		return err
+	}
+
+	if !domain.AdminAllowed(scopes, action) {
+		return fmt.Errorf("%s: %w", action, domain.ErrAdminForbidden)
+	}
+
+	return nil
+}
+
+func (s *adminService) Scopes(ctx context.Context, adminID uint, userUUID string) ([]domain.AdminScope, error) {
+	if err := s.Authorize(ctx, adminID, domain.AdminActionManageScopes); err != nil {
+		return nil, err
+	}
+
+	user, err := s.user(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes, err := s.adminRepo.Scopes(ctx, user.ID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving admin scopes")
+		return nil, err
+	}
+
+	return scopes, nil
+}
+
+func (s *adminService) SetScopes(ctx context.Context, adminID uint, userUUID string, scopes []domain.AdminScope) ([]domain.AdminScope, error) {
+	if err := s.Authorize(ctx, adminID, domain.AdminActionManageScopes); err != nil {
+		return nil, err
+	}
+
+	user, err := s.user(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the last superadmin could otherwise lock everyone out of the admin endpoints
+	if user.ID == adminID && !slices.Contains(scopes, domain.AdminScopeSuperadmin) {
+		return nil, domain.ErrRevokeOwnSuperadmin
+	}
+
+	if err = s.setScopes(ctx, user, scopes); err != nil {
+		return nil, err
+	}
+
+	return s.adminRepo.Scopes(ctx, user.ID)
+}
+
+func (s *adminService) Grant(ctx context.Context, userUUID string, scopes []domain.AdminScope) error {
+	user, err := s.user(ctx, userUUID)
+	if err != nil {
+		return err
+	}
+
+	return s.setScopes(ctx, user, scopes)
+}
+
+func (s *adminService) setScopes(ctx context.Context, user *domain.User, scopes []domain.AdminScope) error {
+	scopes = slices.Compact(slices.Sorted(slices.Values(scopes)))
+	if err := s.adminRepo.SetScopes(ctx, user.ID, scopes); err != nil {
+		log.Err(err).Msg("error updating admin scopes")
+		return err
+	}
+
+	names := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		names = append(names, string(scope))
+	}
+	s.securityEvents.Publish(ctx, domain.EventSecurityPermissionChanged, user, map[string]string{
+		"admin_scopes": strings.Join(names, ","),
+	})
+
+	return nil
+}
+
+func (s *adminService) user(ctx context.Context, userUUID string) (*domain.User, error) {
+	user, err := s.userRepo.ByUUID(ctx, userUUID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("user not found: %w", domain.ErrUserNotFound)
+		}
+		log.Err(err).Msg("error retrieving user")
+		return nil, err
+	}
+
+	return user, nil
+}
@@ -0,0 +1,407 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/idempotency"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/pagination"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+// AgentService lets AI assistants manage the todos of a user with scoped agent tokens. The
+// actions are constrained for callers that act on their own: queries return few todos, creating
+// can be retried safely with an idempotency key, and completing takes a confirmation of the user.
+type AgentService interface {
+	CreateToken(ctx context.Context, userID uint, tokenCreate *domain.AgentTokenCreate) (*domain.AgentToken, error)
+	RevokeToken(ctx context.Context, userID uint, uuid string) error
+	Tokens(ctx context.Context, userID uint) ([]*domain.AgentToken, error)
+	// Authenticate resolves a raw agent token, expired and revoked tokens are rejected.
+	Authenticate(ctx context.Context, token string) (*domain.AgentToken, error)
+
+	QueryTodos(ctx context.Context, token *domain.AgentToken, query *domain.AgentTodoQuery) ([]*domain.Todo, error)
+	// CreateTodo creates a todo once per idempotency key, retries get the todo created first.
+	// An empty key creates a todo every time.
+	CreateTodo(ctx context.Context, token *domain.AgentToken, todoCreate *domain.TodoCreate, idempotencyKey string) (*domain.AgentTodoCreated, error)
+	// CompleteTodo returns a confirmation to ask the user with when confirmation is empty, and
+	// completes the todo with a valid one.
+	CompleteTodo(ctx context.Context, token *domain.AgentToken, uuid string, confirmation string) (*domain.AgentCompletion, error)
+}
+
+type agentService struct {
+	*BaseService
+
+	householdService HouseholdService
+	workspaceService WorkspaceService
+	todoService      TodoService
+	searchService    SearchService
+
+	agentTokenRepo   repo.AgentTokenRepo
+	idempotencyStore idempotency.Store
+}
+
+func NewAgentService(
+	base *BaseService,
+	householdService HouseholdService,
+	workspaceService WorkspaceService,
+	todoService TodoService,
+	searchService SearchService,
+	agentTokenRepo repo.AgentTokenRepo,
+	idempotencyStore idempotency.Store,
+) *agentService {
+	return &agentService{
+		BaseService:      base,
+		householdService: householdService,
+		workspaceService: workspaceService,
+		todoService:      todoService,
+		searchService:    searchService,
+		agentTokenRepo:   agentTokenRepo,
+		idempotencyStore: idempotencyStore,
+	}
+}
+
+// check AgentService interface implementation on compile time.
+var _ AgentService = (*agentService)(nil)
+
+func (s *agentService) CreateToken(ctx context.Context, userID uint, tokenCreate *domain.AgentTokenCreate) (*domain.AgentToken, error) {
+	if tokenCreate == nil {
+		return nil, fmt.Errorf("no agent token details provided")
+	}
+
+	// an assistant acts on its own, child accounts can't hand their todos to one
+	if err := s.householdService.RequireParent(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	raw, err := generateRegionalToken(ctx, domain.AgentTokenPrefix)
+	if err != nil {
+		log.Err(err).Msg("error generating agent token")
+		return nil, err
+	}
+
+	token, err := s.agentTokenRepo.Create(ctx, &domain.AgentToken{
+		UUID:      s.GenerateUUIDHash("agent"),
+		UserID:    userID,
+		Name:      tokenCreate.Name,
+		Scopes:    tokenCreate.Scopes,
+		ExpiresAt: tokenCreate.ExpiresAt,
+	}, hashToken(raw))
+	if err != nil {
+		log.Err(err).Msg("error creating agent token")
+		return nil, fmt.Errorf("error creating agent token: %w", err)
+	}
+
+	token.Token = raw
+	return token, nil
+}
+
+func (s *agentService) RevokeToken(ctx context.Context, userID uint, uuid string) error {
+	tokens, err := s.Tokens(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, token := range tokens {
+		if token.UUID != uuid {
+			continue
+		}
+
+		if err = s.agentTokenRepo.Delete(ctx, userID, uuid); err != nil {
+			log.Err(err).Msg("error revoking agent token")
+			return fmt.Errorf("error revoking agent token: %w", err)
+		}
+		return nil
+	}
+
+	return domain.ErrAgentTokenNotFound
+}
+
+func (s *agentService) Tokens(ctx context.Context, userID uint) ([]*domain.AgentToken, error) {
+	tokens, err := s.agentTokenRepo.All(ctx, userID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving agent tokens")
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+func (s *agentService) Authenticate(ctx context.Context, raw string) (*domain.AgentToken, error) {
+	if !strings.HasPrefix(raw, domain.AgentTokenPrefix) {
+		return nil, domain.ErrInvalidAgentToken
+	}
+
+	ctx, err := s.tokenContext(ctx, domain.AgentTokenPrefix, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := s.agentTokenRepo.ByHash(ctx, hashToken(raw))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrInvalidAgentToken
+		}
+		log.Err(err).Msg("error retrieving agent token")
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if token.Expired(now) {
+		return nil, domain.ErrInvalidAgentToken
+	}
+
+	if err = s.agentTokenRepo.Touch(ctx, token.ID, now); err != nil {
+		log.Err(err).Str("agent_token", token.UUID).Msg("error updating agent token usage")
+	}
+
+	token.Region = region.FromContext(ctx)
+	return token, nil
+}
+
+// authorize checks the token has scope and counts the call against the API cap of the workspace,
+// like the calls of the apps.
+func (s *agentService) authorize(ctx context.Context, token *domain.AgentToken, scope domain.AgentScope) error {
+	if !token.Allows(scope) {
+		return fmt.Errorf("%w: %s", domain.ErrAgentScopeMissing, scope)
+	}
+
+	if err := s.workspaceService.Consume(ctx, token.UserID, domain.QuotaAPICalls); err != nil {
+		if errors.Is(err, domain.ErrUsageLimitExceeded) {
+			return err
+		}
+		log.Err(err).Msg("error counting agent call")
+	}
+
+	return nil
+}
+
+func (s *agentService) QueryTodos(ctx context.Context, token *domain.AgentToken, query *domain.AgentTodoQuery) ([]*domain.Todo, error) {
+	if err := s.authorize(ctx, token, domain.AgentScopeRead); err != nil {
+		return nil, err
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = domain.DefaultAgentQueryLimit
+	}
+	limit = min(limit, domain.MaxAgentQueryLimit)
+
+	if strings.TrimSpace(query.Text) == "" {
+		todos, _, err := s.todoService.All(ctx, token.UserID, &domain.TodoFilter{
+			ListUUID:  query.ListUUID,
+			Completed: query.Completed,
+			DueBefore: query.DueBefore,
+			Sort:      []domain.TodoSortField{domain.TodoSortDueDate, domain.TodoSortPriority},
+		}, &pagination.Page{Limit: limit})
+
+		return todos, err
+	}
+
+	// search ranks by relevance, the filters are applied to the best matches
+	results, err := s.searchService.SearchTodos(ctx, token.UserID, query.Text, domain.MaxSearchLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	todos := make([]*domain.Todo, 0, limit)
+	for _, result := range results {
+		if len(todos) == limit {
+			break
+		}
+		if matchesAgentQuery(result.Todo, query) {
+			todos = append(todos, result.Todo)
+		}
+	}
+
+	return todos, nil
+}
+
+func matchesAgentQuery(todo *domain.Todo, query *domain.AgentTodoQuery) bool {
+	switch {
+	case query.ListUUID != "" && todo.ListUUID != query.ListUUID:
+		return false
+	case query.Completed == domain.CompletedExclude && todo.Completed():
+		return false
+	case query.Completed == domain.CompletedOnly && !todo.Completed():
+		return false
+	case !query.DueBefore.IsZero() && (todo.DueDate.IsZero() || !todo.DueDate.Before(query.DueBefore)):
+		return false
+	}
+
+	return true
+}
+
+func (s *agentService) CreateTodo(
+	ctx context.Context,
+	token *domain.AgentToken,
+	todoCreate *domain.TodoCreate,
+	idempotencyKey string,
+) (*domain.AgentTodoCreated, error) {
+	if err := s.authorize(ctx, token, domain.AgentScopeCreate); err != nil {
+		return nil, err
+	}
+
+	// rules can route the todos of an assistant by the name of its token
+	todoCreate.Source = domain.CaptureSourceAgent
+	todoCreate.Channel = token.Name
+
+	if idempotencyKey == "" {
+		return s.createTodo(ctx, token, todoCreate)
+	}
+
+	// keys are scoped by token, so one assistant can't replay the todos of another
+	key := "agent_" + token.UUID + ":" + idempotencyKey
+	fingerprint, err := agentTodoFingerprint(todoCreate)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := s.idempotencyStore.Begin(ctx, key, fingerprint, time.Now().Add(idempotency.TTL))
+	if err != nil {
+		log.Err(err).Msg("error claiming idempotency key")
+		return s.createTodo(ctx, token, todoCreate)
+	}
+
+	switch {
+	case stored == nil:
+		created, err := s.createTodo(ctx, token, todoCreate)
+		if err != nil {
+			if releaseErr := s.idempotencyStore.Release(ctx, key); releaseErr != nil {
+				log.Err(releaseErr).Msg("error releasing idempotency key")
+			}
+			return nil, err
+		}
+
+		// only the UUID is stored, a replay returns the todo as it is now
+		err = s.idempotencyStore.Complete(ctx, key, &idempotency.Response{
+			Fingerprint: fingerprint,
+			StatusCode:  http.StatusCreated,
+			Body:        []byte(created.Todo.UUID),
+		})
+		if err != nil {
+			log.Err(err).Msg("error storing idempotent todo")
+		}
+		return created, nil
+	case stored.Fingerprint != fingerprint:
+		return nil, domain.ErrIdempotencyKeyReused
+	case !stored.Completed():
+		return nil, domain.ErrIdempotencyKeyInUse
+	}
+
+	todo, err := s.todoService.ByUUID(ctx, token.UserID, string(stored.Body))
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.AgentTodoCreated{Todo: todo, Replayed: true}, nil
+}
+
+func (s *agentService) createTodo(ctx context.Context, token *domain.AgentToken, todoCreate *domain.TodoCreate) (*domain.AgentTodoCreated, error) {
+	todo, err := s.todoService.Create(ctx, token.UserID, todoCreate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.AgentTodoCreated{Todo: todo}, nil
+}
+
+func agentTodoFingerprint(todoCreate *domain.TodoCreate) (string, error) {
+	data, err := json.Marshal(todoCreate)
+	if err != nil {
+		return "", fmt.Errorf("error fingerprinting todo: %w", err)
+	}
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (s *agentService) CompleteTodo(ctx context.Context, token *domain.AgentToken, uuid string, confirmation string) (*domain.AgentCompletion, error) {
+	if err := s.authorize(ctx, token, domain.AgentScopeComplete); err != nil {
+		return nil, err
+	}
+
+	todo, err := s.todoService.Writable(ctx, token.UserID, uuid)
+	if err != nil {
+		return nil, err
+	}
+	if todo.Completed() {
+		return &domain.AgentCompletion{
+			Todo:      todo,
+			Completed: true,
+			Summary:   fmt.Sprintf("%q is already done.", todo.Title),
+		}, nil
+	}
+
+	now := time.Now()
+	if confirmation == "" {
+		expiresAt := now.Add(domain.AgentConfirmationExpiration).Truncate(time.Second)
+		return &domain.AgentCompletion{
+			Todo:                  todo,
+			Summary:               fmt.Sprintf("Mark %q as done.", todo.Title),
+			Confirmation:          s.confirmation(token, todo, expiresAt),
+			ConfirmationExpiresAt: expiresAt,
+		}, nil
+	}
+
+	if !s.validConfirmation(token, todo, confirmation, now) {
+		return nil, domain.ErrInvalidConfirmation
+	}
+
+	completed := true
+	todo, err = s.todoService.Update(ctx, token.UserID, uuid, &domain.TodoUpdate{Completed: &completed})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.AgentCompletion{
+		Todo:      todo,
+		Completed: true,
+		Summary:   fmt.Sprintf("Marked %q as done.", todo.Title),
+	}, nil
+}
+
+// confirmation signs the completion of a version of todo by token until expiresAt. It isn't
+// stored: any change of the todo or a revoked token invalidates it.
+func (s *agentService) confirmation(token *domain.AgentToken, todo *domain.Todo, expiresAt time.Time) string {
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+
+	return expires + "." + base64.RawURLEncoding.EncodeToString(s.confirmationMAC(token, todo, expires))
+}
+
+func (s *agentService) validConfirmation(token *domain.AgentToken, todo *domain.Todo, confirmation string, now time.Time) bool {
+	expires, signature, ok := strings.Cut(confirmation, ".")
+	if !ok {
+		return false
+	}
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || !now.Before(time.Unix(expiresAt, 0)) {
+		return false
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	return hmac.Equal(mac, s.confirmationMAC(token, todo, expires))
+}
+
+func (s *agentService) confirmationMAC(token *domain.AgentToken, todo *domain.Todo, expires string) []byte {
+	mac := hmac.New(sha256.New, []byte("agent-confirmation:"+s.Config.GetJWTSecret()))
+	fmt.Fprintf(mac, "complete\n%s\n%s\n%d\n%s", token.UUID, todo.UUID, todo.Version, expires)
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/mail"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+// IPAllowlistService restricts the access to a workspace to CIDR ranges. The owner of the
+// workspace manages the ranges, they apply to the owner and all of its child accounts. An owner
+// who locked themselves out can suspend the allowlist with a token sent to their email.
+type IPAllowlistService interface {
+	Ranges(ctx context.Context, userID uint) ([]*domain.IPRange, error)
+	AddRange(ctx context.Context, userID uint, ipRange *domain.IPRangeCreate) (*domain.IPRange, error)
+	RemoveRange(ctx context.Context, userID uint, uuid string) error
+
+	// Check returns ErrIPNotAllowed when the workspace of the user can't be accessed from ip,
+	// households that aren't part of a workspace can be accessed from anywhere.
+	Check(ctx context.Context, userID uint, ip string) error
+
+	// RequestBypass emails the owner a token to suspend the allowlist.
+	RequestBypass(ctx context.Context, userID uint) error
+	// ConfirmBypass suspends the allowlist for IPAllowlistSuspension and returns when it
+	// applies again.
+	ConfirmBypass(ctx context.Context, userID uint, token string) (time.Time, error)
+}
+
+type ipAllowlistService struct {
+	*BaseService
+
+	userRepo        repo.UserRepo
+	ipAllowlistRepo repo.IPAllowlistRepo
+	workspaceRepo   repo.WorkspaceRepo
+
+	sender         mail.Sender
+	securityEvents *SecurityEvents
+}
+
+func NewIPAllowlistService(
+	base *BaseService,
+	userRepo repo.UserRepo,
+	ipAllowlistRepo repo.IPAllowlistRepo,
+	workspaceRepo repo.WorkspaceRepo,
+	sender mail.Sender,
+	securityEvents *SecurityEvents,
+) *ipAllowlistService {
+	return &ipAllowlistService{
+		BaseService:     base,
+		userRepo:        userRepo,
+		ipAllowlistRepo: ipAllowlistRepo,
+		workspaceRepo:   workspaceRepo,
+		sender:          sender,
+		securityEvents:  securityEvents,
+	}
+}
+
+// check IPAllowlistService interface implementation on compile time.
+var _ IPAllowlistService = (*ipAllowlistService)(nil)
+
+func (s *ipAllowlistService) Ranges(ctx context.Context, userID uint) ([]*domain.IPRange, error) {
+	_, workspace, err := s.ownedWorkspace(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ranges, err := s.ipAllowlistRepo.Ranges(ctx, workspace.ID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving IP ranges")
+		return nil, err
+	}
+
+	return ranges, nil
+}
+
+func (s *ipAllowlistService) AddRange(ctx context.Context, userID uint, ipRange *domain.IPRangeCreate) (*domain.IPRange, error) {
+	owner, workspace, err := s.ownedWorkspace(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, err := domain.ParseCIDR(ipRange.CIDR)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ipRange.CIDR, err)
+	}
+
+	created, err := s.ipAllowlistRepo.CreateRange(ctx, s.GenerateUUIDHash("ip_range"), workspace.ID, &domain.IPRangeCreate{
+		CIDR:  prefix.String(),
+		Label: ipRange.Label,
+	})
+	if err != nil {
+		log.Err(err).Msg("error creating IP range")
+		return nil, fmt.Errorf("error creating IP range: %w", err)
+	}
+	s.securityEvents.Publish(ctx, domain.EventSecurityPermissionChanged, owner, map[string]string{
+		"change": "ip_range_added",
+		"cidr":   created.CIDR,
+	})
+
+	return created, nil
+}
+
+func (s *ipAllowlistService) RemoveRange(ctx context.Context, userID uint, uuid string) error {
+	owner, workspace, err := s.ownedWorkspace(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err = s.ipAllowlistRepo.DeleteRange(ctx, workspace.ID, uuid); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("IP range not found: %w", domain.ErrIPRangeNotFound)
+		}
+		log.Err(err).Msg("error deleting IP range")
+		return fmt.Errorf("error deleting IP range: %w", err)
+	}
+	s.securityEvents.Publish(ctx, domain.EventSecurityPermissionChanged, owner, map[string]string{
+		"change": "ip_range_removed",
+		"uuid":   uuid,
+	})
+
+	return nil
+}
+
+func (s *ipAllowlistService) Check(ctx context.Context, userID uint, ip string) error {
+	workspace, err := s.workspace(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrWorkspaceNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	ranges, err := s.ipAllowlistRepo.Ranges(ctx, workspace.ID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving IP ranges")
+		return err
+	}
+	if len(ranges) == 0 || domain.IPAllowed(ranges, ip) {
+		return nil
+	}
+
+	suspended, err := s.ipAllowlistRepo.Suspended(ctx, workspace.ID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving IP allowlist bypasses")
+		return err
+	}
+	if suspended {
+		return nil
+	}
+
+	log.Warn().Uint("user_id", userID).Str("ip", ip).Msg("request blocked by IP allowlist")
+	return fmt.Errorf("%s: %w", ip, domain.ErrIPNotAllowed)
+}
+
+func (s *ipAllowlistService) RequestBypass(ctx context.Context, userID uint) error {
+	owner, workspace, err := s.ownedWorkspace(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	token, err := generateToken(domain.IPAllowlistBypassPrefix)
+	if err != nil {
+		log.Err(err).Msg("error generating bypass token")
+		return err
+	}
+
+	expiresAt := time.Now().Add(domain.IPAllowlistBypassExpiration)
+	if err = s.ipAllowlistRepo.CreateBypass(ctx, workspace.ID, hashToken(token), expiresAt); err != nil {
+		log.Err(err).Msg("error creating IP allowlist bypass")
+		return fmt.Errorf("error creating IP allowlist bypass: %w", err)
+	}
+
+	// the token is only emailed, a stolen session alone can't suspend the allowlist
+	confirmURL := fmt.Sprintf("%s/ip-allowlist/bypass?token=%s", strings.TrimRight(s.Config.GetAppURL(), "/"), url.QueryEscape(token))
+	err = s.sender.Send(ctx, &mail.Message{
+		To:      []string{owner.Email},
+		Subject: fmt.Sprintf("Suspend the IP allowlist of %s", workspace.Name),
+		Text: fmt.Sprintf("A request was made to suspend the IP allowlist of the %s workspace for %s.\n\nSuspend it: %s\n\nThe link expires in %s. If you didn't ask for this, change your password.\n",
+			workspace.Name, domain.IPAllowlistSuspension, confirmURL, domain.IPAllowlistBypassExpiration),
+	})
+	if err != nil {
+		log.Err(err).Msg("error sending IP allowlist bypass")
+		return fmt.Errorf("error sending IP allowlist bypass: %w", err)
+	}
+
+	return nil
+}
+
+func (s *ipAllowlistService) ConfirmBypass(ctx context.Context, userID uint, token string) (time.Time, error) {
+	owner, workspace, err := s.ownedWorkspace(ctx, userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	suspendedUntil := time.Now().Add(domain.IPAllowlistSuspension).UTC()
+	if err = s.ipAllowlistRepo.UseBypass(ctx, workspace.ID, hashToken(token), suspendedUntil); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return time.Time{}, domain.ErrInvalidBypassToken
+		}
+		log.Err(err).Msg("error using IP allowlist bypass")
+		return time.Time{}, err
+	}
+	log.Warn().Uint("user_id", owner.ID).Uint("workspace_id", workspace.ID).Time("until", suspendedUntil).Msg("IP allowlist suspended")
+	s.securityEvents.Publish(ctx, domain.EventSecurityPermissionChanged, owner, map[string]string{
+		"change":          "ip_allowlist_suspended",
+		"suspended_until": suspendedUntil.Format(time.RFC3339),
+	})
+
+	return suspendedUntil, nil
+}
+
+// ownedWorkspace returns the user and their workspace when they own it, child accounts can't
+// manage the allowlist.
+func (s *ipAllowlistService) ownedWorkspace(ctx context.Context, userID uint) (*domain.User, *domain.Workspace, error) {
+	owner, err := s.user(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if owner.IsChild() {
+		return nil, nil, domain.ErrIPAllowlistOwnerOnly
+	}
+
+	workspace, err := s.ownerWorkspace(ctx, owner)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return owner, workspace, nil
+}
+
+// workspace returns the workspace of the household of the user, it returns ErrWorkspaceNotFound
+// when the household isn't part of a workspace.
+func (s *ipAllowlistService) workspace(ctx context.Context, userID uint) (*domain.Workspace, error) {
+	owner, err := s.user(ctx, userID)
+	if err == nil && owner.IsChild() {
+		owner, err = s.user(ctx, owner.ParentID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.ownerWorkspace(ctx, owner)
+}
+
+func (s *ipAllowlistService) ownerWorkspace(ctx context.Context, owner *domain.User) (*domain.Workspace, error) {
+	workspace, err := s.workspaceRepo.ByOwner(ctx, owner.UUID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("household is not part of a workspace: %w", domain.ErrWorkspaceNotFound)
+		}
+		log.Err(err).Msg("error retrieving workspace")
+		return nil, err
+	}
+
+	return workspace, nil
+}
+
+func (s *ipAllowlistService) user(ctx context.Context, userID uint) (*domain.User, error) {
+	user, err := s.userRepo.ByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("user not found: %w", domain.ErrUserNotFound)
+		}
+		log.Err(err).Msg("error retrieving user")
+		return nil, err
+	}
+
+	return user, nil
+}
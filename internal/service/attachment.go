@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/extract"
+	"github.com/meowmix1337/the_recipe_book/internal/metrics"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+	"github.com/meowmix1337/the_recipe_book/internal/storage"
+
+	"github.com/rs/zerolog/log"
+)
+
+// AttachmentService manages the files attached to todos. Everyone who can read a todo can
+// download its attachments, uploading and deleting requires write access.
+type AttachmentService interface {
+	Upload(ctx context.Context, userID uint, todoUUID string, upload *domain.AttachmentUpload) (*domain.Attachment, error)
+	Delete(ctx context.Context, userID uint, todoUUID string, uuid string) error
+
+	All(ctx context.Context, userID uint, todoUUID string) ([]*domain.Attachment, error)
+	// Download returns a signed URL for the attachment.
+	Download(ctx context.Context, userID uint, todoUUID string, uuid string) (*domain.AttachmentDownload, error)
+
+	// ExtractDue extracts the text of the attachments uploaded since the last run, so they can
+	// be found by their content. It is run by a background worker.
+	ExtractDue(ctx context.Context) error
+}
+
+const (
+	// extractionBatchSize is how many attachments a run of the extraction worker handles, they
+	// are extracted one at a time since OCR is heavy.
+	extractionBatchSize = 10
+	// extractionBudget is how long a run keeps starting extractions, the rest of the batch is
+	// left to the next run so the worker doesn't look stuck.
+	extractionBudget = time.Minute
+)
+
+type attachmentService struct {
+	*BaseService
+
+	todoService      TodoService
+	householdService HouseholdService
+
+	attachmentRepo repo.AttachmentRepo
+	storage        storage.Storage
+	extractor      extract.Extractor
+}
+
+func NewAttachmentService(
+	base *BaseService,
+	todoService TodoService,
+	householdService HouseholdService,
+	attachmentRepo repo.AttachmentRepo,
+	storage storage.Storage,
+	extractor extract.Extractor,
+) *attachmentService {
+	return &attachmentService{
+		BaseService:      base,
+		todoService:      todoService,
+		householdService: householdService,
+		attachmentRepo:   attachmentRepo,
+		storage:          storage,
+		extractor:        extractor,
+	}
+}
+
+// check AttachmentService interface implementation on compile time.
+var _ AttachmentService = (*attachmentService)(nil)
+
+func (s *attachmentService) Upload(
+	ctx context.Context,
+	userID uint,
+	todoUUID string,
+	upload *domain.AttachmentUpload,
+) (*domain.Attachment, error) {
+	if upload == nil {
+		return nil, fmt.Errorf("no attachment provided")
+	}
+
+	if upload.Size > s.Config.GetAttachmentMaxSize() {
+		return nil, domain.ErrAttachmentTooLarge
+	}
+
+	todo, err := s.todoService.Writable(ctx, userID, todoUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	uuid := s.GenerateUUIDHash("attachment")
+	key := fmt.Sprintf("todos/%s/%s", todo.UUID, uuid)
+	if err = s.storage.Put(ctx, key, upload.Body, upload.Size, upload.ContentType); err != nil {
+		log.Err(err).Msg("error storing attachment")
+		return nil, fmt.Errorf("error storing attachment: %w", err)
+	}
+
+	attachment, err := s.attachmentRepo.Create(ctx, &domain.Attachment{
+		UUID:        uuid,
+		TodoID:      todo.ID,
+		UserID:      userID,
+		Filename:    upload.Filename,
+		ContentType: upload.ContentType,
+		Size:        upload.Size,
+		StorageKey:  key,
+	})
+	if err != nil {
+		log.Err(err).Msg("error creating attachment")
+		s.deleteFile(ctx, key)
+		return nil, fmt.Errorf("error creating attachment: %w", err)
+	}
+
+	return attachment, nil
+}
+
+func (s *attachmentService) Delete(ctx context.Context, userID uint, todoUUID string, uuid string) error {
+	if err := s.householdService.Permit(ctx, userID, domain.ParentalActionDelete); err != nil {
+		return err
+	}
+
+	todo, err := s.todoService.Writable(ctx, userID, todoUUID)
+	if err != nil {
+		return err
+	}
+
+	attachment, err := s.attachment(ctx, todo.ID, uuid)
+	if err != nil {
+		return err
+	}
+
+	if err = s.attachmentRepo.Delete(ctx, attachment.ID); err != nil {
+		log.Err(err).Msg("error deleting attachment")
+		return fmt.Errorf("error deleting attachment: %w", err)
+	}
+
+	s.deleteFile(ctx, attachment.StorageKey)
+	return nil
+}
+
+func (s *attachmentService) All(ctx context.Context, userID uint, todoUUID string) ([]*domain.Attachment, error) {
+	todo, err := s.todoService.ByUUID(ctx, userID, todoUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments, err := s.attachmentRepo.All(ctx, todo.ID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving attachments")
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+func (s *attachmentService) Download(ctx context.Context, userID uint, todoUUID string, uuid string) (*domain.AttachmentDownload, error) {
+	todo, err := s.todoService.ByUUID(ctx, userID, todoUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment, err := s.attachment(ctx, todo.ID, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().UTC().Add(domain.AttachmentURLExpiration)
+	url, err := s.storage.SignedURL(ctx, attachment.StorageKey, attachment.Filename, expiresAt)
+	if err != nil {
+		log.Err(err).Msg("error signing attachment url")
+		return nil, err
+	}
+
+	return &domain.AttachmentDownload{
+		URL:       url,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (s *attachmentService) ExtractDue(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	attachments, err := s.attachmentRepo.PendingExtraction(ctx, now.Add(-domain.AttachmentExtractionTimeout), extractionBatchSize)
+	if err != nil {
+		return fmt.Errorf("error retrieving pending attachments: %w", err)
+	}
+	metrics.WorkerQueueDepth.WithLabelValues("attachment_extraction", metrics.RegionLabel(region.FromContext(ctx))).Set(float64(len(attachments)))
+
+	for _, attachment := range attachments {
+		if time.Since(now) > extractionBudget {
+			break
+		}
+		s.extract(ctx, attachment, now)
+	}
+
+	return nil
+}
+
+func (s *attachmentService) extract(ctx context.Context, attachment *domain.Attachment, now time.Time) {
+	// claim the attachment first so multiple instances never extract the same file at once
+	if err := s.attachmentRepo.ClaimExtraction(ctx, attachment, now); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Err(err).Str("attachment", attachment.UUID).Msg("error claiming attachment extraction")
+		}
+		return
+	}
+	attachment.ExtractionAttempts++
+
+	text, err := s.extractText(ctx, attachment)
+	status := domain.ExtractionDone
+	switch {
+	case errors.Is(err, extract.ErrUnsupported):
+		status = domain.ExtractionUnsupported
+	case err != nil && attachment.ExtractionAttempts >= domain.AttachmentExtractionAttempts:
+		status = domain.ExtractionFailed
+		log.Warn().Err(err).Str("attachment", attachment.UUID).Msg("attachment extraction failed, giving up")
+	case err != nil:
+		status = domain.ExtractionPending
+		log.Debug().Err(err).Str("attachment", attachment.UUID).Msg("attachment extraction failed")
+	}
+
+	if err = s.attachmentRepo.SetExtraction(ctx, attachment.ID, status, text); err != nil {
+		log.Err(err).Str("attachment", attachment.UUID).Msg("error storing attachment extraction")
+	}
+}
+
+func (s *attachmentService) extractText(ctx context.Context, attachment *domain.Attachment) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, domain.AttachmentExtractionTimeout)
+	defer cancel()
+
+	file, err := s.storage.Open(ctx, attachment.StorageKey)
+	if err != nil {
+		return "", fmt.Errorf("error opening attachment: %w", err)
+	}
+	defer file.Close()
+
+	return s.extractor.Extract(ctx, attachment.ContentType, file)
+}
+
+func (s *attachmentService) attachment(ctx context.Context, todoID uint, uuid string) (*domain.Attachment, error) {
+	attachment, err := s.attachmentRepo.ByUUID(ctx, todoID, uuid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("attachment not found: %w", domain.ErrAttachmentNotFound)
+		}
+		log.Err(err).Msg("error retrieving attachment")
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// deleteFile removes a stored file, a leftover file is only logged since the attachment is already gone.
+func (s *attachmentService) deleteFile(ctx context.Context, key string) {
+	if err := s.storage.Delete(ctx, key); err != nil {
+		log.Err(err).Str("storage_key", key).Msg("error deleting attachment file")
+	}
+}
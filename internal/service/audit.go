@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/meowmix1337/the_recipe_book/internal/audit"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/pagination"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+type AuditService interface {
+	// Record appends an entry to the audit log, failures are logged so they never fail the request.
+	Record(ctx context.Context, entry *domain.AuditEntry)
+
+	// All returns the audit log of a user, newest first.
+	All(ctx context.Context, userID uint, page *pagination.Page) ([]*domain.AuditEntry, *pagination.Cursor, error)
+	// ByUser returns the audit log of the user with the given UUID, it is only meant for admins.
+	ByUser(ctx context.Context, userUUID string, page *pagination.Page) ([]*domain.AuditEntry, *pagination.Cursor, error)
+
+	// Verify walks the hash chain of the whole audit log of the region of ctx, it stops at the
+	// first entry breaking the chain.
+	Verify(ctx context.Context) (*domain.AuditVerification, error)
+}
+
+// auditVerifyBatch is how many entries are read at once while verifying the chain.
+const auditVerifyBatch = 1000
+
+type auditService struct {
+	*BaseService
+
+	auditRepo repo.AuditRepo
+	userRepo  repo.UserRepo
+}
+
+func NewAuditService(base *BaseService, auditRepo repo.AuditRepo, userRepo repo.UserRepo) *auditService {
+	return &auditService{
+		BaseService: base,
+		auditRepo:   auditRepo,
+		userRepo:    userRepo,
+	}
+}
+
+// check AuditService interface implementation on compile time.
+var _ AuditService = (*auditService)(nil)
+
+func (s *auditService) Record(ctx context.Context, entry *domain.AuditEntry) {
+	if entry.Region != region.Home {
+		ctx = region.WithRegion(ctx, entry.Region)
+	}
+	if err := s.auditRepo.Create(ctx, entry); err != nil {
+		log.Err(err).Str("action", entry.Action).Uint("user_id", entry.UserID).Msg("error recording audit entry")
+	}
+}
+
+func (s *auditService) All(ctx context.Context, userID uint, page *pagination.Page) ([]*domain.AuditEntry, *pagination.Cursor, error) {
+	entries, next, err := s.auditRepo.All(ctx, userID, page)
+	if err != nil {
+		log.Err(err).Msg("error retrieving audit log")
+		return nil, nil, err
+	}
+
+	return entries, next, nil
+}
+
+func (s *auditService) ByUser(ctx context.Context, userUUID string, page *pagination.Page) ([]*domain.AuditEntry, *pagination.Cursor, error) {
+	user, err := s.userRepo.ByUUID(ctx, userUUID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, fmt.Errorf("user not found: %w", domain.ErrUserNotFound)
+		}
+		log.Err(err).Msg("error retrieving user")
+		return nil, nil, err
+	}
+
+	return s.All(ctx, user.ID, page)
+}
+
+func (s *auditService) Verify(ctx context.Context) (*domain.AuditVerification, error) {
+	verification := &domain.AuditVerification{Valid: true}
+
+	var afterID uint
+	for {
+		entries, err := s.auditRepo.Chain(ctx, afterID, auditVerifyBatch)
+		if err != nil {
+			log.Err(err).Msg("error retrieving audit log")
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			// entries written before the chain existed come first
+			if verification.Head == "" && entry.Hash == "" {
+				verification.Unchained++
+				continue
+			}
+
+			if reason := audit.Verify(verification.Head, entry); reason != "" {
+				verification.Valid = false
+				verification.BrokenID = entry.ID
+				verification.Reason = reason
+				log.Error().Uint("entry_id", entry.ID).Str("reason", reason).Msg("audit log chain is broken")
+
+				return verification, nil
+			}
+			verification.Checked++
+			if !entry.AnonymizedAt.IsZero() {
+				verification.Anonymized++
+			}
+			verification.Head = entry.Hash
+		}
+
+		if len(entries) < auditVerifyBatch {
+			return verification, nil
+		}
+		afterID = entries[len(entries)-1].ID
+	}
+}
@@ -2,11 +2,13 @@ package service
 
 import (
 	"context"
-	"fmt"
 	"time"
 
+	"github.com/meowmix1337/the_recipe_book/internal/blacklist"
 	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
 	"github.com/meowmix1337/the_recipe_book/internal/repo"
+	"github.com/meowmix1337/the_recipe_book/internal/tracing"
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
@@ -14,10 +16,14 @@ import (
 )
 
 type AuthService interface {
+	// GenerateToken signs an access token for the user, pinned to the data region of ctx.
 	GenerateToken(ctx context.Context, user *domain.User) (string, error)
 	GenerateRefreshToken(ctx context.Context, userID uint) (string, error)
 	DeleteRefreshToken(ctx context.Context, userID uint) error
 	BlacklistToken(ctx context.Context, token string, userID uint, expiresAt time.Time) error
+	// RevokeTokens signs the user out everywhere, the access tokens issued before issuedBefore
+	// are revoked and the refresh token is deleted.
+	RevokeTokens(ctx context.Context, user *domain.User, issuedBefore time.Time) error
 
 	ByRefreshToken(ctx context.Context, userID uint, refreshToken string) (*domain.RefreshToken, error)
 }
@@ -25,13 +31,23 @@ type AuthService interface {
 type authService struct {
 	*BaseService
 
+	signingKeyService SigningKeyService
+
 	refreshTokenRepo repo.RefreshTokenRepo
+	blacklist        blacklist.Blacklist
 }
 
-func NewAuthService(base *BaseService, refreshTokenRepo repo.RefreshTokenRepo) *authService {
+func NewAuthService(
+	base *BaseService,
+	signingKeyService SigningKeyService,
+	refreshTokenRepo repo.RefreshTokenRepo,
+	blacklist blacklist.Blacklist,
+) *authService {
 	return &authService{
-		BaseService:      base,
-		refreshTokenRepo: refreshTokenRepo,
+		BaseService:       base,
+		signingKeyService: signingKeyService,
+		refreshTokenRepo:  refreshTokenRepo,
+		blacklist:         blacklist,
 	}
 }
 
@@ -39,11 +55,14 @@ func NewAuthService(base *BaseService, refreshTokenRepo repo.RefreshTokenRepo) *
 var _ AuthService = (*authService)(nil)
 
 func (s *authService) GenerateToken(ctx context.Context, user *domain.User) (string, error) {
+	ctx, span := tracing.Start(ctx, "authService.GenerateToken")
+	defer span.End()
+
 	claims := &domain.JWTCustomClaims{
 		UserID: user.ID,
 		Email:  user.Email,
 		UUID:   user.UUID,
-		Admin:  false,
+		Region: region.FromContext(ctx),
 	}
 
 	claims.RegisteredClaims = jwt.RegisteredClaims{
@@ -53,9 +72,7 @@ func (s *authService) GenerateToken(ctx context.Context, user *domain.User) (str
 	}
 
 	// Generate JWT token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	tokenString, err := token.SignedString([]byte(s.Config.GetJWTSecret()))
+	tokenString, err := s.signingKeyService.Sign(ctx, claims)
 	if err != nil {
 		log.Err(err).Msg("error generating JWT token")
 		return "", domain.ErrJWTGeneration
@@ -65,6 +82,9 @@ func (s *authService) GenerateToken(ctx context.Context, user *domain.User) (str
 }
 
 func (s *authService) GenerateRefreshToken(ctx context.Context, userID uint) (string, error) {
+	ctx, span := tracing.Start(ctx, "authService.GenerateRefreshToken")
+	defer span.End()
+
 	uuid := uuid.NewString()
 
 	err := s.refreshTokenRepo.CreateRefreshToken(ctx, uuid, userID)
@@ -84,11 +104,7 @@ func (s *authService) ByRefreshToken(ctx context.Context, userID uint, refreshTo
 }
 
 func (s *authService) BlacklistToken(ctx context.Context, token string, userID uint, expiresAt time.Time) error {
-	key := fmt.Sprintf("%v_%v", userID, token)
-	expirationTime := time.Unix(expiresAt.Unix(), 0)
-	ttl := time.Until(expirationTime)
-
-	err := s.Cache.Set(ctx, key, "", int(ttl))
+	err := s.blacklist.Add(ctx, userID, token, expiresAt)
 	if err != nil {
 		log.Err(err).Msg("error blacklisting token")
 		return err
@@ -96,3 +112,14 @@ func (s *authService) BlacklistToken(ctx context.Context, token string, userID u
 
 	return nil
 }
+
+func (s *authService) RevokeTokens(ctx context.Context, user *domain.User, issuedBefore time.Time) error {
+	// the tokens are valid for JWTExpiration at most, the revocation isn't needed any longer
+	err := s.blacklist.RevokeIssuedBefore(ctx, user.UUID, issuedBefore, issuedBefore.Add(domain.JWTExpiration))
+	if err != nil {
+		log.Err(err).Msg("error revoking tokens")
+		return err
+	}
+
+	return s.DeleteRefreshToken(ctx, user.ID)
+}
@@ -0,0 +1,536 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/meowmix1337/the_recipe_book/internal/metrics"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/pagination"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+	"github.com/meowmix1337/the_recipe_book/internal/wasm"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	dueAutomationBatchSize = 20
+	// automationConcurrency is how many runs execute at once, runs are bound by CPU so it is kept
+	// low.
+	automationConcurrency = 4
+	// automationErrorLength caps the error stored with a failed run.
+	automationErrorLength = 500
+	// automationInputSize caps the JSON a module passes to a host function.
+	automationInputSize = 16 << 10
+	// automationNoticeTitleLength caps the title of a notification, it is the subject of the email.
+	automationNoticeTitleLength = 100
+	// automationHostModule is the module the host functions are imported from.
+	automationHostModule = "todo"
+)
+
+// errAutomationInterrupted is stored with a run while it executes, it stays when the instance
+// dies before the outcome is stored.
+var errAutomationInterrupted = errors.New("run was interrupted")
+
+// automationRunKey marks the context of a run, todos an automation creates don't run
+// automations so automations can't trigger each other in a loop.
+type automationRunKey struct{}
+
+// AutomationService lets power users upload WebAssembly modules run on the events of their
+// todos, see domain.Automation for the host functions modules can call. Events are queued as
+// runs when they happen and executed by a background worker, every run is limited in the
+// instructions it executes, the memory it uses and how long it takes.
+type AutomationService interface {
+	Create(ctx context.Context, userID uint, automationCreate *domain.AutomationCreate) (*domain.Automation, error)
+	Delete(ctx context.Context, userID uint, uuid string) error
+	ByUUID(ctx context.Context, userID uint, uuid string) (*domain.Automation, error)
+	All(ctx context.Context, userID uint) ([]*domain.Automation, error)
+	Runs(ctx context.Context, userID uint, uuid string, page *pagination.Page) ([]*domain.AutomationRun, *pagination.Cursor, error)
+
+	// HandleTodoEvent queues a run for every automation of the todo owner subscribed to the event.
+	HandleTodoEvent(ctx context.Context, event *domain.TodoEvent)
+	// RunDue executes the queued runs, it is run by a background worker.
+	RunDue(ctx context.Context) error
+}
+
+type automationService struct {
+	*BaseService
+
+	householdService    HouseholdService
+	todoService         TodoService
+	notificationService NotificationService
+
+	automationRepo repo.AutomationRepo
+	userRepo       repo.UserRepo
+}
+
+func NewAutomationService(
+	base *BaseService,
+	householdService HouseholdService,
+	todoService TodoService,
+	notificationService NotificationService,
+	automationRepo repo.AutomationRepo,
+	userRepo repo.UserRepo,
+) *automationService {
+	return &automationService{
+		BaseService:         base,
+		householdService:    householdService,
+		todoService:         todoService,
+		notificationService: notificationService,
+		automationRepo:      automationRepo,
+		userRepo:            userRepo,
+	}
+}
+
+// check AutomationService interface implementation on compile time.
+var _ AutomationService = (*automationService)(nil)
+
+// check TodoEventHandler interface implementation on compile time.
+var _ TodoEventHandler = (*automationService)(nil)
+
+func (s *automationService) Create(ctx context.Context, userID uint, automationCreate *domain.AutomationCreate) (*domain.Automation, error) {
+	if automationCreate == nil {
+		return nil, fmt.Errorf("no automation details provided")
+	}
+
+	// automations run code on behalf of the account, child accounts can't upload them
+	if err := s.householdService.RequireParent(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	if slices.ContainsFunc(automationCreate.Events, domain.EventType.Security) {
+		return nil, domain.ErrAutomationEventsTodoOnly
+	}
+	if len(automationCreate.Module) > domain.MaxAutomationModuleSize {
+		return nil, domain.ErrAutomationModuleTooLarge
+	}
+	if err := checkAutomationModule(automationCreate.Module); err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrInvalidAutomationModule, err)
+	}
+
+	automations, err := s.All(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(automations) >= domain.MaxAutomations {
+		return nil, domain.ErrAutomationLimitReached
+	}
+
+	created, err := s.automationRepo.Create(ctx, &domain.Automation{
+		UUID:   s.GenerateUUIDHash("automation"),
+		UserID: userID,
+		Name:   automationCreate.Name,
+		Events: automationCreate.Events,
+		Module: automationCreate.Module,
+	})
+	if err != nil {
+		log.Err(err).Msg("error creating automation")
+		return nil, fmt.Errorf("error creating automation: %w", err)
+	}
+
+	return created, nil
+}
+
+// checkAutomationModule compiles a module and checks it only imports the host functions and
+// exports the entry point, so modules fail when they are uploaded rather than on every run.
+func checkAutomationModule(module []byte) error {
+	compiled, err := wasm.Compile(module)
+	if err != nil {
+		return err
+	}
+
+	host := automationImports(nil)[automationHostModule]
+	for _, imp := range compiled.Imports() {
+		fn, ok := host[imp.Name]
+		if imp.Module != automationHostModule || !ok {
+			return fmt.Errorf("unknown import %s.%s", imp.Module, imp.Name)
+		}
+		if !slices.Equal(fn.Type.Params, imp.Type.Params) || !slices.Equal(fn.Type.Results, imp.Type.Results) {
+			return fmt.Errorf("import %s.%s has the wrong signature", imp.Module, imp.Name)
+		}
+	}
+
+	entryPoint, ok := compiled.ExportedFunction(domain.AutomationEntryPoint)
+	if !ok {
+		return fmt.Errorf("no exported function %s", domain.AutomationEntryPoint)
+	}
+	if len(entryPoint.Params) != 0 || len(entryPoint.Results) != 0 {
+		return fmt.Errorf("function %s has parameters or results", domain.AutomationEntryPoint)
+	}
+
+	return nil
+}
+
+func (s *automationService) Delete(ctx context.Context, userID uint, uuid string) error {
+	if _, err := s.ByUUID(ctx, userID, uuid); err != nil {
+		return err
+	}
+
+	if err := s.automationRepo.Delete(ctx, userID, uuid); err != nil {
+		log.Err(err).Msg("error deleting automation")
+		return fmt.Errorf("error deleting automation: %w", err)
+	}
+
+	return nil
+}
+
+func (s *automationService) ByUUID(ctx context.Context, userID uint, uuid string) (*domain.Automation, error) {
+	found, err := s.automationRepo.ByUUID(ctx, userID, uuid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("automation not found: %w", domain.ErrAutomationNotFound)
+		}
+		log.Err(err).Msg("error retrieving automation")
+		return nil, err
+	}
+
+	return found, nil
+}
+
+func (s *automationService) All(ctx context.Context, userID uint) ([]*domain.Automation, error) {
+	automations, err := s.automationRepo.All(ctx, userID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving automations")
+		return nil, err
+	}
+
+	return automations, nil
+}
+
+func (s *automationService) Runs(
+	ctx context.Context,
+	userID uint,
+	uuid string,
+	page *pagination.Page,
+) ([]*domain.AutomationRun, *pagination.Cursor, error) {
+	found, err := s.ByUUID(ctx, userID, uuid)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	runs, next, err := s.automationRepo.Runs(ctx, found.ID, page)
+	if err != nil {
+		log.Err(err).Msg("error retrieving automation runs")
+		return nil, nil, err
+	}
+
+	return runs, next, nil
+}
+
+func (s *automationService) HandleTodoEvent(ctx context.Context, event *domain.TodoEvent) {
+	if ctx.Value(automationRunKey{}) != nil {
+		return
+	}
+
+	automations, err := s.automationRepo.All(ctx, event.Todo.UserID)
+	if err != nil {
+		log.Err(err).Str("event", string(event.Type)).Msg("error retrieving automations for event")
+		return
+	}
+
+	runs := make([]*domain.AutomationRun, 0, len(automations))
+	for _, automation := range automations {
+		if !automation.Subscribed(event.Type) {
+			continue
+		}
+
+		run := &domain.AutomationRun{
+			UUID:       s.GenerateUUIDHash("automation_run"),
+			Automation: automation,
+			Event:      event.Type,
+		}
+		run.Payload, err = json.Marshal(endpoint.NewTodoEventPayload(run.UUID, event))
+		if err != nil {
+			log.Err(err).Str("event", string(event.Type)).Msg("error rendering automation payload")
+			return
+		}
+		runs = append(runs, run)
+	}
+
+	if err = s.automationRepo.CreateRuns(ctx, runs); err != nil {
+		log.Err(err).Str("event", string(event.Type)).Msg("error queueing automation runs")
+	}
+}
+
+func (s *automationService) RunDue(ctx context.Context) error {
+	runs, err := s.automationRepo.Due(ctx, dueAutomationBatchSize)
+	if err != nil {
+		return fmt.Errorf("error retrieving due automation runs: %w", err)
+	}
+	metrics.WorkerQueueDepth.WithLabelValues("automation_runs", metrics.RegionLabel(region.FromContext(ctx))).Set(float64(len(runs)))
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, automationConcurrency)
+	for _, run := range runs {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			s.execute(ctx, run)
+		}()
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (s *automationService) execute(ctx context.Context, run *domain.AutomationRun) {
+	// runs aren't retried, claiming one marks it failed until the outcome is stored so a run is
+	// never executed twice
+	if err := s.automationRepo.Claim(ctx, run, errAutomationInterrupted.Error()); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Err(err).Str("run", run.UUID).Msg("error claiming automation run")
+		}
+		return
+	}
+
+	runner := &automationRunner{service: s, run: run}
+	err := runner.execute(ctx)
+	run.FuelUsed = runner.fuelUsed
+	run.Log = runner.log.String()
+	run.FinishedAt = time.Now().UTC()
+	if err != nil {
+		run.Status = domain.AutomationRunFailed
+		run.Error = truncate(err.Error(), automationErrorLength)
+		log.Debug().Err(err).Str("run", run.UUID).Msg("automation run failed")
+	} else {
+		run.Status = domain.AutomationRunSucceeded
+		run.Error = ""
+	}
+
+	if err = s.automationRepo.UpdateRun(ctx, run); err != nil {
+		log.Err(err).Str("run", run.UUID).Msg("error updating automation run")
+	}
+}
+
+// automationRunner is a run in progress, it carries the state the host functions share.
+type automationRunner struct {
+	service  *automationService
+	run      *domain.AutomationRun
+	user     *domain.User
+	log      strings.Builder
+	todos    int
+	notices  int
+	fuelUsed uint64
+}
+
+func (r *automationRunner) execute(ctx context.Context) error {
+	module, err := wasm.Compile(r.run.Automation.Module)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithValue(ctx, automationRunKey{}, r.run.UUID), domain.AutomationTimeout)
+	defer cancel()
+
+	limits := wasm.Limits{Fuel: domain.AutomationFuel, MemoryPages: domain.AutomationMemoryPages}
+	instance, err := wasm.Instantiate(ctx, module, automationImports(r), limits)
+	if err != nil {
+		return err
+	}
+	_, err = instance.Call(ctx, domain.AutomationEntryPoint)
+	r.fuelUsed = limits.Fuel - instance.Fuel()
+
+	return err
+}
+
+// logf appends a line to the log of the run, lines past domain.AutomationLogSize are dropped.
+func (r *automationRunner) logf(format string, args ...interface{}) {
+	line := fmt.Sprintf(format, args...) + "\n"
+	if r.log.Len()+len(line) > domain.AutomationLogSize {
+		return
+	}
+	r.log.WriteString(line)
+}
+
+// input reads the JSON a module passes to a host function, the bytes are charged as fuel.
+func (r *automationRunner) input(call *wasm.Call, ptr, length uint64, v interface{}) error {
+	if length > automationInputSize {
+		return fmt.Errorf("input of %d bytes is larger than %d", length, automationInputSize)
+	}
+	if err := call.Charge(length); err != nil {
+		return err
+	}
+	data, err := call.Read(uint32(ptr), uint32(length))
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// automationTodo is the JSON create_todo takes, named like the fields of the API.
+type automationTodo struct {
+	ListUUID    string     `json:"list_uuid"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Priority    string     `json:"priority"`
+	DueDate     *time.Time `json:"due_date"`
+}
+
+func (r *automationRunner) createTodo(call *wasm.Call, ptr, length uint64) (bool, error) {
+	var todo automationTodo
+	if err := r.input(call, ptr, length, &todo); err != nil {
+		// a memory access out of bounds is a bug of the module, it fails the run
+		if errors.Is(err, wasm.ErrTrap) {
+			return false, err
+		}
+		r.logf("create_todo: %v", err)
+		return false, nil
+	}
+	if r.todos >= domain.AutomationMaxTodos {
+		r.logf("create_todo: a run can create at most %d todos", domain.AutomationMaxTodos)
+		return false, nil
+	}
+
+	title := strings.TrimSpace(todo.Title)
+	if title == "" || utf8.RuneCountInString(title) > domain.MaxComputedTitleLength {
+		r.logf("create_todo: title must have 1 to %d characters", domain.MaxComputedTitleLength)
+		return false, nil
+	}
+	todoCreate := &domain.TodoCreate{
+		ListUUID:    todo.ListUUID,
+		Title:       title,
+		Description: todo.Description,
+		Priority:    domain.PriorityMedium,
+		Channel:     r.run.Automation.Name,
+	}
+	if todo.Priority != "" {
+		priority, err := domain.ParsePriority(todo.Priority)
+		if err != nil {
+			r.logf("create_todo: %v", err)
+			return false, nil
+		}
+		todoCreate.Priority = priority
+	}
+	if todo.DueDate != nil {
+		todoCreate.DueDate = *todo.DueDate
+	}
+
+	r.todos++
+	created, err := r.service.todoService.Create(call.Context(), r.run.Automation.UserID, todoCreate)
+	if err != nil {
+		r.logf("create_todo: %v", err)
+		return false, nil
+	}
+	r.logf("created todo %s", created.UUID)
+
+	return true, nil
+}
+
+// automationNotice is the JSON notify takes.
+type automationNotice struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+func (r *automationRunner) notify(call *wasm.Call, ptr, length uint64) (bool, error) {
+	var notice automationNotice
+	if err := r.input(call, ptr, length, &notice); err != nil {
+		if errors.Is(err, wasm.ErrTrap) {
+			return false, err
+		}
+		r.logf("notify: %v", err)
+		return false, nil
+	}
+	if r.notices >= domain.AutomationMaxNotifications {
+		r.logf("notify: a run can send at most %d notifications", domain.AutomationMaxNotifications)
+		return false, nil
+	}
+
+	// the title is the subject of the email, so it has to fit on one line
+	title := strings.TrimSpace(notice.Title)
+	if title == "" || utf8.RuneCountInString(title) > automationNoticeTitleLength || strings.ContainsFunc(title, unicode.IsControl) {
+		r.logf("notify: title must be a line of 1 to %d characters", automationNoticeTitleLength)
+		return false, nil
+	}
+
+	if r.user == nil {
+		user, err := r.service.userRepo.ByID(call.Context(), r.run.Automation.UserID)
+		if err != nil {
+			return false, fmt.Errorf("error retrieving user: %w", err)
+		}
+		r.user = user
+	}
+
+	r.notices++
+	err := r.service.notificationService.SendAutomationNotice(call.Context(), r.user, &domain.AutomationNotice{
+		Automation: r.run.Automation.Name,
+		Title:      title,
+		Body:       notice.Body,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// automationImports returns the host functions of a run, the signatures are also checked
+// against the imports of uploaded modules with a nil runner.
+func automationImports(r *automationRunner) wasm.Imports {
+	i32 := wasm.I32
+	result := func(ok bool) []uint64 {
+		if ok {
+			return []uint64{1}
+		}
+		return []uint64{0}
+	}
+
+	return wasm.Imports{automationHostModule: {
+		"event_size": {
+			Type: wasm.FuncType{Results: []wasm.ValueType{i32}},
+			Fn: func(_ *wasm.Call, _ []uint64) ([]uint64, error) {
+				return []uint64{uint64(len(r.run.Payload))}, nil
+			},
+		},
+		"event_read": {
+			Type: wasm.FuncType{Params: []wasm.ValueType{i32, i32}, Results: []wasm.ValueType{i32}},
+			Fn: func(call *wasm.Call, args []uint64) ([]uint64, error) {
+				n := min(int(uint32(args[1])), len(r.run.Payload))
+				if err := call.Write(uint32(args[0]), r.run.Payload[:n]); err != nil {
+					return nil, err
+				}
+				return []uint64{uint64(n)}, nil
+			},
+		},
+		"create_todo": {
+			Type: wasm.FuncType{Params: []wasm.ValueType{i32, i32}, Results: []wasm.ValueType{i32}},
+			Fn: func(call *wasm.Call, args []uint64) ([]uint64, error) {
+				ok, err := r.createTodo(call, uint64(uint32(args[0])), uint64(uint32(args[1])))
+				return result(ok), err
+			},
+		},
+		"notify": {
+			Type: wasm.FuncType{Params: []wasm.ValueType{i32, i32}, Results: []wasm.ValueType{i32}},
+			Fn: func(call *wasm.Call, args []uint64) ([]uint64, error) {
+				ok, err := r.notify(call, uint64(uint32(args[0])), uint64(uint32(args[1])))
+				return result(ok), err
+			},
+		},
+		"log": {
+			Type: wasm.FuncType{Params: []wasm.ValueType{i32, i32}},
+			Fn: func(call *wasm.Call, args []uint64) ([]uint64, error) {
+				length := min(uint32(args[1]), domain.AutomationLogSize)
+				line, err := call.Read(uint32(args[0]), length)
+				if err != nil {
+					return nil, err
+				}
+				r.logf("%s", strings.ToValidUTF8(string(line), "�"))
+				return nil, nil
+			},
+		},
+	}}
+}
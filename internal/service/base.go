@@ -5,18 +5,22 @@ import (
 
 	"github.com/meowmix1337/go-core/cache"
 	"github.com/meowmix1337/the_recipe_book/internal/config"
+	"github.com/meowmix1337/the_recipe_book/internal/password"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
 	"github.com/segmentio/ksuid"
 )
 
 type BaseService struct {
-	Config config.Config
-	Cache  cache.Cache
+	Config    config.Config
+	Cache     cache.Cache
+	Passwords *password.Passwords
 }
 
 func NewBaseService(cfg config.Config, cache cache.Cache) *BaseService {
 	return &BaseService{
-		Config: cfg,
-		Cache:  cache,
+		Config:    cfg,
+		Cache:     cache,
+		Passwords: newPasswords(cfg),
 	}
 }
 
@@ -28,3 +32,13 @@ func (s *BaseService) GenerateUUIDHash(prefix string) string {
 
 	return prefix + "_" + id.String()
 }
+
+// servesRegion reports whether a database is configured for the data region.
+func (s *BaseService) servesRegion(userRegion string) bool {
+	if userRegion == region.Home {
+		return true
+	}
+	_, ok := s.Config.GetDBRegions()[userRegion]
+
+	return ok
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+// BoardService shows lists as boards with a column per status and manages the statuses. Lists
+// start with the default statuses, only the owner of a list changes them. Todos are moved on the
+// board with TodoService.Place.
+type BoardService interface {
+	Statuses(ctx context.Context, userID uint, listUUID string) ([]*domain.ListStatus, error)
+	CreateStatus(ctx context.Context, userID uint, listUUID string, statusCreate *domain.ListStatusCreate) (*domain.ListStatus, error)
+	UpdateStatus(ctx context.Context, userID uint, listUUID string, key string, statusUpdate *domain.ListStatusUpdate) (*domain.ListStatus, error)
+	// DeleteStatus removes a status, its todos go back to the first column.
+	DeleteStatus(ctx context.Context, userID uint, listUUID string, key string) error
+
+	// Board returns the open and recently completed todos of a list the user can access in the
+	// columns of its statuses.
+	Board(ctx context.Context, userID uint, listUUID string) (*domain.Board, error)
+}
+
+type boardService struct {
+	*BaseService
+
+	listService ListService
+
+	listStatusRepo repo.ListStatusRepo
+	todoRepo       repo.TodoRepo
+	txManager      repo.TxManager
+}
+
+func NewBoardService(
+	base *BaseService,
+	listService ListService,
+	listStatusRepo repo.ListStatusRepo,
+	todoRepo repo.TodoRepo,
+	txManager repo.TxManager,
+) *boardService {
+	return &boardService{
+		BaseService:    base,
+		listService:    listService,
+		listStatusRepo: listStatusRepo,
+		todoRepo:       todoRepo,
+		txManager:      txManager,
+	}
+}
+
+// check BoardService interface implementation on compile time.
+var _ BoardService = (*boardService)(nil)
+
+func (s *boardService) Statuses(ctx context.Context, userID uint, listUUID string) ([]*domain.ListStatus, error) {
+	access, err := s.listService.Access(ctx, userID, listUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return listStatuses(ctx, s.listStatusRepo, access.List)
+}
+
+func (s *boardService) CreateStatus(
+	ctx context.Context,
+	userID uint,
+	listUUID string,
+	statusCreate *domain.ListStatusCreate,
+) (*domain.ListStatus, error) {
+	if !domain.ValidListStatusKey(statusCreate.Key) {
+		return nil, domain.ErrInvalidListStatus
+	}
+
+	list, err := s.listService.ByUUID(ctx, userID, listUUID)
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := listStatuses(ctx, s.listStatusRepo, list)
+	if err != nil {
+		return nil, err
+	}
+	if findListStatus(statuses, statusCreate.Key) != nil {
+		return nil, domain.ErrListStatusExists
+	}
+
+	status := &domain.ListStatus{ListID: list.ID, Key: statusCreate.Key, Name: statusCreate.Name, Done: statusCreate.Done}
+	statuses = moveListStatus(statuses, status, statusCreate.Position)
+	if err = s.save(ctx, list, statuses); err != nil {
+		return nil, err
+	}
+
+	return s.status(ctx, list, status.Key)
+}
+
+func (s *boardService) UpdateStatus(
+	ctx context.Context,
+	userID uint,
+	listUUID string,
+	key string,
+	statusUpdate *domain.ListStatusUpdate,
+) (*domain.ListStatus, error) {
+	list, err := s.listService.ByUUID(ctx, userID, listUUID)
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := listStatuses(ctx, s.listStatusRepo, list)
+	if err != nil {
+		return nil, err
+	}
+	status := findListStatus(statuses, key)
+	if status == nil {
+		return nil, domain.ErrListStatusNotFound
+	}
+
+	if statusUpdate.Name != nil {
+		status.Name = *statusUpdate.Name
+	}
+	if statusUpdate.Done != nil {
+		status.Done = *statusUpdate.Done
+	}
+	if statusUpdate.Position != nil {
+		statuses = moveListStatus(statuses, status, *statusUpdate.Position)
+	}
+	if err = s.save(ctx, list, statuses); err != nil {
+		return nil, err
+	}
+
+	return s.status(ctx, list, key)
+}
+
+func (s *boardService) DeleteStatus(ctx context.Context, userID uint, listUUID string, key string) error {
+	list, err := s.listService.ByUUID(ctx, userID, listUUID)
+	if err != nil {
+		return err
+	}
+	statuses, err := listStatuses(ctx, s.listStatusRepo, list)
+	if err != nil {
+		return err
+	}
+	status := findListStatus(statuses, key)
+	if status == nil {
+		return domain.ErrListStatusNotFound
+	}
+	if len(statuses) == 1 {
+		return domain.ErrLastListStatus
+	}
+
+	// the default statuses are stored first, so the list doesn't fall back to all of them
+	statuses = moveListStatus(statuses, status, 0)
+	err = s.txManager.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.listStatusRepo.Save(ctx, list.ID, statuses[:len(statuses)-1]); err != nil {
+			return err
+		}
+
+		return s.listStatusRepo.Delete(ctx, list.ID, key)
+	})
+	if err != nil {
+		log.Err(err).Msg("error deleting list status")
+		return fmt.Errorf("error deleting list status: %w", err)
+	}
+
+	return nil
+}
+
+func (s *boardService) Board(ctx context.Context, userID uint, listUUID string) (*domain.Board, error) {
+	access, err := s.listService.Access(ctx, userID, listUUID)
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := listStatuses(ctx, s.listStatusRepo, access.List)
+	if err != nil {
+		return nil, err
+	}
+
+	todos, _, err := s.todoRepo.All(ctx, access.List.UserID, &domain.TodoFilter{ListID: access.List.ID}, nil)
+	if err != nil {
+		log.Err(err).Msg("error retrieving todos")
+		return nil, err
+	}
+
+	return domain.NewBoard(access.List, statuses, todos), nil
+}
+
+func (s *boardService) save(ctx context.Context, list *domain.List, statuses []*domain.ListStatus) error {
+	if err := s.listStatusRepo.Save(ctx, list.ID, statuses); err != nil {
+		log.Err(err).Msg("error saving list statuses")
+		return fmt.Errorf("error saving list statuses: %w", err)
+	}
+
+	return nil
+}
+
+// status returns a stored status of the list.
+func (s *boardService) status(ctx context.Context, list *domain.List, key string) (*domain.ListStatus, error) {
+	statuses, err := listStatuses(ctx, s.listStatusRepo, list)
+	if err != nil {
+		return nil, err
+	}
+	status := findListStatus(statuses, key)
+	if status == nil {
+		return nil, domain.ErrListStatusNotFound
+	}
+
+	return status, nil
+}
+
+// listStatuses returns the statuses of a list, the default statuses unless it has its own.
+func listStatuses(ctx context.Context, listStatusRepo repo.ListStatusRepo, list *domain.List) ([]*domain.ListStatus, error) {
+	statuses, err := listStatusRepo.ByList(ctx, list.ID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving list statuses")
+		return nil, err
+	}
+	if len(statuses) == 0 {
+		return domain.DefaultListStatuses(list.ID), nil
+	}
+
+	return statuses, nil
+}
+
+func findListStatus(statuses []*domain.ListStatus, key string) *domain.ListStatus {
+	for _, status := range statuses {
+		if status.Key == key {
+			return status
+		}
+	}
+
+	return nil
+}
+
+// moveListStatus puts status at position from 1 on and renumbers the statuses, zero or positions
+// past the end put it last. The status is added when it isn't one of statuses yet.
+func moveListStatus(statuses []*domain.ListStatus, status *domain.ListStatus, position int) []*domain.ListStatus {
+	moved := make([]*domain.ListStatus, 0, len(statuses)+1)
+	for _, other := range statuses {
+		if other.Key != status.Key {
+			moved = append(moved, other)
+		}
+	}
+
+	index := len(moved)
+	if position > 0 && position <= len(moved) {
+		index = position - 1
+	}
+	moved = slices.Insert(moved, index, status)
+
+	for i, other := range moved {
+		other.Position = i + 1
+	}
+
+	return moved
+}
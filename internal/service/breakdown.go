@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/meowmix1337/the_recipe_book/internal/llm"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+
+	"github.com/rs/zerolog/log"
+)
+
+// maxStepTitleLength is the length of todo titles, longer steps are cut.
+const maxStepTitleLength = 255
+
+// breakdownSystemPrompt sets the task of the model, the todo follows as JSON in the prompt.
+const breakdownSystemPrompt = `You help people get started on large todos by breaking them down into
+smaller, concrete steps. You get a todo as JSON with its title, description, tags, due date and
+estimate in minutes. Reply with a single JSON object and nothing else:
+
+{"steps": [{"title": "...", "estimate_minutes": 30}]}
+
+List between 2 and 10 steps in the order they should be done. Titles are short imperative
+sentences in the language of the todo, without numbering. estimate_minutes is a realistic
+estimate of each step, the steps should add up to roughly the estimate of the todo when it has
+one. Don't invent details the todo doesn't imply.`
+
+// BreakdownService proposes how to split a large todo into smaller ones with a language model.
+// Proposals create nothing, the user confirms the steps they want.
+type BreakdownService interface {
+	// Propose sends the todo to the language model, it fails with ErrBreakdownDisabled when no
+	// model is configured.
+	Propose(ctx context.Context, userID uint, uuid string) (*domain.TodoBreakdown, error)
+	// Confirm creates the confirmed steps as todos next to the todo like a split.
+	Confirm(ctx context.Context, userID uint, uuid string, todoSplit *domain.TodoSplit) (*domain.TodoSplitResult, error)
+}
+
+type breakdownService struct {
+	*BaseService
+
+	todoService TodoService
+
+	model llm.Model
+}
+
+func NewBreakdownService(base *BaseService, todoService TodoService, model llm.Model) *breakdownService {
+	return &breakdownService{
+		BaseService: base,
+		todoService: todoService,
+		model:       model,
+	}
+}
+
+// check BreakdownService interface implementation on compile time.
+var _ BreakdownService = (*breakdownService)(nil)
+
+type breakdownTodo struct {
+	Title       string     `json:"title"`
+	Description string     `json:"description,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	Estimate    int        `json:"estimate_minutes,omitempty"`
+}
+
+type breakdownReply struct {
+	Steps []struct {
+		Title    string  `json:"title"`
+		Estimate float64 `json:"estimate_minutes"`
+	} `json:"steps"`
+}
+
+func (s *breakdownService) Propose(ctx context.Context, userID uint, uuid string) (*domain.TodoBreakdown, error) {
+	if !llm.Enabled(s.model) {
+		return nil, domain.ErrBreakdownDisabled
+	}
+
+	todo, err := s.todoService.Writable(ctx, userID, uuid)
+	if err != nil {
+		return nil, err
+	}
+	if todo.Encrypted() {
+		return nil, domain.ErrTodoEncrypted
+	}
+
+	input := &breakdownTodo{
+		Title:       todo.Title,
+		Description: todo.Description,
+		Estimate:    int(todo.Estimate.Minutes()),
+	}
+	for _, tag := range todo.Tags {
+		input.Tags = append(input.Tags, tag.Name)
+	}
+	if !todo.DueDate.IsZero() {
+		input.DueDate = &todo.DueDate
+	}
+	prompt, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := s.model.Complete(ctx, &llm.Request{
+		System: breakdownSystemPrompt,
+		Prompt: string(prompt),
+		JSON:   true,
+	})
+	if err != nil {
+		if errors.Is(err, llm.ErrDisabled) {
+			return nil, domain.ErrBreakdownDisabled
+		}
+		log.Err(err).Str("todo_uuid", uuid).Msg("error asking language model for breakdown")
+		return nil, fmt.Errorf("error asking language model: %w", err)
+	}
+
+	steps, err := parseBreakdown(reply)
+	if err != nil {
+		log.Err(err).Str("todo_uuid", uuid).Msg("language model replied with an unusable breakdown")
+		return nil, err
+	}
+
+	return &domain.TodoBreakdown{Todo: todo, Steps: steps}, nil
+}
+
+// parseBreakdown reads the steps of a reply. Models tend to wrap JSON in prose or code fences
+// despite the instructions, so the outermost object is used. Steps are cleaned up rather than
+// rejected, only a reply without any step is an error.
+func parseBreakdown(reply string) ([]*domain.BreakdownStep, error) {
+	start, end := strings.Index(reply, "{"), strings.LastIndex(reply, "}")
+	if start < 0 || end < start {
+		return nil, errors.New("breakdown reply holds no JSON object")
+	}
+
+	var parsed breakdownReply
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &parsed); err != nil {
+		return nil, fmt.Errorf("error decoding breakdown reply: %w", err)
+	}
+
+	steps := make([]*domain.BreakdownStep, 0, len(parsed.Steps))
+	for _, step := range parsed.Steps {
+		title := strings.Join(strings.Fields(step.Title), " ")
+		if title == "" {
+			continue
+		}
+		if utf8.RuneCountInString(title) > maxStepTitleLength {
+			title = string([]rune(title)[:maxStepTitleLength])
+		}
+		minutes := min(max(step.Estimate, 0), domain.MaxBreakdownStepEstimate.Minutes())
+		steps = append(steps, &domain.BreakdownStep{
+			Title:    title,
+			Estimate: time.Duration(minutes) * time.Minute,
+		})
+		if len(steps) == domain.MaxBreakdownSteps {
+			break
+		}
+	}
+	if len(steps) == 0 {
+		return nil, errors.New("breakdown reply has no steps")
+	}
+
+	return steps, nil
+}
+
+func (s *breakdownService) Confirm(ctx context.Context, userID uint, uuid string, todoSplit *domain.TodoSplit) (*domain.TodoSplitResult, error) {
+	if len(todoSplit.Items) == 0 {
+		return nil, domain.ErrNoChecklist
+	}
+
+	return s.todoService.Split(ctx, userID, uuid, todoSplit)
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/export"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+// calendarName is the name calendar apps show for the feed until the user renames it.
+const calendarName = "Todos"
+
+// CalendarService renders the due todos of a user as an iCalendar feed and manages the
+// read-only tokens of the subscription URLs of the feed.
+type CalendarService interface {
+	// Feed renders the todos of the user that have a due date.
+	Feed(ctx context.Context, userID uint, component domain.CalendarComponent) ([]byte, error)
+
+	CreateToken(ctx context.Context, userID uint, name string) (*domain.CalendarToken, error)
+	RevokeToken(ctx context.Context, userID uint, uuid string) error
+	Tokens(ctx context.Context, userID uint) ([]*domain.CalendarToken, error)
+	// Authenticate resolves a raw calendar token, revoked tokens are rejected.
+	Authenticate(ctx context.Context, token string) (*domain.CalendarToken, error)
+}
+
+type calendarService struct {
+	*BaseService
+
+	householdService HouseholdService
+
+	calendarTokenRepo repo.CalendarTokenRepo
+	todoRepo          repo.TodoRepo
+}
+
+func NewCalendarService(
+	base *BaseService,
+	householdService HouseholdService,
+	calendarTokenRepo repo.CalendarTokenRepo,
+	todoRepo repo.TodoRepo,
+) *calendarService {
+	return &calendarService{
+		BaseService:       base,
+		householdService:  householdService,
+		calendarTokenRepo: calendarTokenRepo,
+		todoRepo:          todoRepo,
+	}
+}
+
+// check CalendarService interface implementation on compile time.
+var _ CalendarService = (*calendarService)(nil)
+
+func (s *calendarService) Feed(ctx context.Context, userID uint, component domain.CalendarComponent) ([]byte, error) {
+	todos, _, err := s.todoRepo.All(ctx, userID, &domain.TodoFilter{
+		Sort:  []domain.TodoSortField{domain.TodoSortDueDate},
+		Order: domain.SortOrderAsc,
+	}, nil)
+	if err != nil {
+		log.Err(err).Msg("error retrieving todos for calendar")
+		return nil, err
+	}
+
+	return export.RenderCalendar(calendarName, todos, component, time.Now()), nil
+}
+
+func (s *calendarService) CreateToken(ctx context.Context, userID uint, name string) (*domain.CalendarToken, error) {
+	// anyone with the subscription URL can read the todos
+	if err := s.householdService.Permit(ctx, userID, domain.ParentalActionShare); err != nil {
+		return nil, err
+	}
+
+	raw, err := generateRegionalToken(ctx, domain.CalendarTokenPrefix)
+	if err != nil {
+		log.Err(err).Msg("error generating calendar token")
+		return nil, err
+	}
+
+	token, err := s.calendarTokenRepo.Create(ctx, &domain.CalendarToken{
+		UUID:   s.GenerateUUIDHash("calendar"),
+		UserID: userID,
+		Name:   name,
+	}, hashToken(raw))
+	if err != nil {
+		log.Err(err).Msg("error creating calendar token")
+		return nil, fmt.Errorf("error creating calendar token: %w", err)
+	}
+
+	token.Token = raw
+	return token, nil
+}
+
+func (s *calendarService) RevokeToken(ctx context.Context, userID uint, uuid string) error {
+	if err := s.calendarTokenRepo.Delete(ctx, userID, uuid); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrCalendarTokenNotFound
+		}
+		log.Err(err).Msg("error revoking calendar token")
+		return fmt.Errorf("error revoking calendar token: %w", err)
+	}
+
+	return nil
+}
+
+func (s *calendarService) Tokens(ctx context.Context, userID uint) ([]*domain.CalendarToken, error) {
+	tokens, err := s.calendarTokenRepo.All(ctx, userID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving calendar tokens")
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+func (s *calendarService) Authenticate(ctx context.Context, raw string) (*domain.CalendarToken, error) {
+	if !strings.HasPrefix(raw, domain.CalendarTokenPrefix) {
+		return nil, domain.ErrInvalidCalendarToken
+	}
+
+	ctx, err := s.tokenContext(ctx, domain.CalendarTokenPrefix, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := s.calendarTokenRepo.ByHash(ctx, hashToken(raw))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrInvalidCalendarToken
+		}
+		log.Err(err).Msg("error retrieving calendar token")
+		return nil, err
+	}
+
+	if err = s.calendarTokenRepo.Touch(ctx, token.ID, time.Now().UTC()); err != nil {
+		// usage tracking must not break the subscription
+		log.Err(err).Str("calendar_token", token.UUID).Msg("error updating calendar token usage")
+	}
+
+	token.Region = region.FromContext(ctx)
+	return token, nil
+}
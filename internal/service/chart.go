@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ChartService returns the history of todos as time series for charts, read from the daily
+// rollups so a chart over a year doesn't scan every todo.
+type ChartService interface {
+	// Burndown returns the open todos and the todos completed since the start of the chart, at
+	// the end of every bucket of the query.
+	Burndown(ctx context.Context, userID uint, query *domain.ChartQuery) ([]*domain.BurndownPoint, error)
+	// CompletionTrend returns the todos created and completed during every bucket of the query.
+	CompletionTrend(ctx context.Context, userID uint, query *domain.ChartQuery) ([]*domain.TrendPoint, error)
+
+	// RollupDue recomputes the rollups of the last RollupWindow, it is run by a background worker.
+	RollupDue(ctx context.Context) error
+}
+
+type chartService struct {
+	*BaseService
+
+	listService      ListService
+	householdService HouseholdService
+
+	rollupRepo repo.RollupRepo
+}
+
+func NewChartService(
+	base *BaseService,
+	listService ListService,
+	householdService HouseholdService,
+	rollupRepo repo.RollupRepo,
+) *chartService {
+	return &chartService{
+		BaseService:      base,
+		listService:      listService,
+		householdService: householdService,
+		rollupRepo:       rollupRepo,
+	}
+}
+
+// check ChartService interface implementation on compile time.
+var _ ChartService = (*chartService)(nil)
+
+func (s *chartService) Burndown(ctx context.Context, userID uint, query *domain.ChartQuery) ([]*domain.BurndownPoint, error) {
+	totals, buckets, err := s.rollups(ctx, userID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	open := totals.Opened()
+	completed := 0
+	points := make([]*domain.BurndownPoint, 0, len(buckets))
+	for _, bucket := range buckets {
+		open += bucket.Opened()
+		completed += bucket.Completed
+		points = append(points, &domain.BurndownPoint{
+			Start:     bucket.Day,
+			Open:      open,
+			Completed: completed,
+		})
+	}
+
+	return points, nil
+}
+
+func (s *chartService) CompletionTrend(ctx context.Context, userID uint, query *domain.ChartQuery) ([]*domain.TrendPoint, error) {
+	_, buckets, err := s.rollups(ctx, userID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]*domain.TrendPoint, 0, len(buckets))
+	for _, bucket := range buckets {
+		points = append(points, &domain.TrendPoint{
+			Start:     bucket.Day,
+			Created:   bucket.Created,
+			Completed: bucket.Completed,
+		})
+	}
+
+	return points, nil
+}
+
+func (s *chartService) RollupDue(ctx context.Context) error {
+	since := time.Now().UTC().Add(-domain.RollupWindow)
+	if err := s.rollupRepo.Refresh(ctx, since); err != nil {
+		return fmt.Errorf("error refreshing todo rollups: %w", err)
+	}
+
+	return nil
+}
+
+// rollups returns the totals before the chart and the rollups summed per bucket, every bucket
+// of the chart is present and Day holds its start.
+func (s *chartService) rollups(
+	ctx context.Context,
+	userID uint,
+	query *domain.ChartQuery,
+) (*domain.TodoRollup, []*domain.TodoRollup, error) {
+	granularity := query.Granularity
+	if granularity == "" {
+		granularity = domain.ChartDay
+	}
+
+	to := query.To
+	if to.IsZero() {
+		to = time.Now().UTC()
+	}
+	from := query.From
+	if from.IsZero() {
+		from = to.Add(-domain.DefaultChartPeriod)
+	}
+	if !to.After(from) {
+		return nil, nil, domain.ErrInvalidChartRange
+	}
+
+	buckets := make([]*domain.TodoRollup, 0)
+	for start := granularity.Truncate(from); !start.After(to); start = granularity.Next(start) {
+		if len(buckets) == domain.MaxChartPoints {
+			return nil, nil, domain.ErrChartRangeTooLarge
+		}
+		buckets = append(buckets, &domain.TodoRollup{Day: start})
+	}
+	from = buckets[0].Day
+
+	scope, err := s.scope(ctx, userID, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	totals, err := s.rollupRepo.Totals(ctx, scope, from)
+	if err != nil {
+		log.Err(err).Msg("error retrieving todo rollup totals")
+		return nil, nil, err
+	}
+
+	days, err := s.rollupRepo.Daily(ctx, scope, from, to)
+	if err != nil {
+		log.Err(err).Msg("error retrieving todo rollups")
+		return nil, nil, err
+	}
+
+	// both are ordered by time, so every day falls into the current bucket or a later one
+	i := 0
+	for _, day := range days {
+		for i+1 < len(buckets) && !day.Day.Before(buckets[i+1].Day) {
+			i++
+		}
+		buckets[i].Created += day.Created
+		buckets[i].Completed += day.Completed
+		buckets[i].Deleted += day.Deleted
+	}
+
+	return totals, buckets, nil
+}
+
+// scope resolves the todos the query covers, only the owner of a household can chart the todos
+// of its child accounts.
+func (s *chartService) scope(ctx context.Context, userID uint, query *domain.ChartQuery) (*domain.RollupScope, error) {
+	if query.ListUUID != "" {
+		shared, err := s.listService.Access(ctx, userID, query.ListUUID)
+		if err != nil {
+			return nil, err
+		}
+		return &domain.RollupScope{ListID: shared.List.ID}, nil
+	}
+
+	scope := &domain.RollupScope{UserIDs: []uint{userID}}
+	if !query.Workspace {
+		return scope, nil
+	}
+
+	if err := s.householdService.RequireParent(ctx, userID); err != nil {
+		return nil, err
+	}
+	children, err := s.householdService.Children(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range children {
+		scope.UserIDs = append(scope.UserIDs, child.ID)
+	}
+
+	return scope, nil
+}
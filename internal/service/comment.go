@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/pagination"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+// CommentService manages the comments on todos. Everyone who can read a todo, its owner and the
+// collaborators of its list, can read and post comments.
+type CommentService interface {
+	Create(ctx context.Context, userID uint, todoUUID string, body string) (*domain.Comment, error)
+	// Update changes the body of a comment, only its author can edit it.
+	Update(ctx context.Context, userID uint, todoUUID string, uuid string, body string) (*domain.Comment, error)
+	// Delete removes a comment, the author and the todo owner can delete it.
+	Delete(ctx context.Context, userID uint, todoUUID string, uuid string) error
+
+	All(ctx context.Context, userID uint, todoUUID string, page *pagination.Page) ([]*domain.Comment, *pagination.Cursor, error)
+}
+
+type commentService struct {
+	*BaseService
+
+	todoService TodoService
+
+	commentRepo repo.CommentRepo
+}
+
+func NewCommentService(base *BaseService, todoService TodoService, commentRepo repo.CommentRepo) *commentService {
+	return &commentService{
+		BaseService: base,
+		todoService: todoService,
+		commentRepo: commentRepo,
+	}
+}
+
+// check CommentService interface implementation on compile time.
+var _ CommentService = (*commentService)(nil)
+
+func (s *commentService) Create(ctx context.Context, userID uint, todoUUID string, body string) (*domain.Comment, error) {
+	todo, err := s.todoService.ByUUID(ctx, userID, todoUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := s.commentRepo.Create(ctx, s.GenerateUUIDHash("comment"), todo.ID, userID, body)
+	if err != nil {
+		log.Err(err).Msg("error creating comment")
+		return nil, fmt.Errorf("error creating comment: %w", err)
+	}
+
+	return comment, nil
+}
+
+func (s *commentService) Update(ctx context.Context, userID uint, todoUUID string, uuid string, body string) (*domain.Comment, error) {
+	_, comment, err := s.comment(ctx, userID, todoUUID, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if comment.AuthorID != userID {
+		return nil, domain.ErrCommentForbidden
+	}
+
+	comment.Body = body
+	updated, err := s.commentRepo.Update(ctx, comment)
+	if err != nil {
+		log.Err(err).Msg("error updating comment")
+		return nil, fmt.Errorf("error updating comment: %w", err)
+	}
+
+	return updated, nil
+}
+
+func (s *commentService) Delete(ctx context.Context, userID uint, todoUUID string, uuid string) error {
+	todo, comment, err := s.comment(ctx, userID, todoUUID, uuid)
+	if err != nil {
+		return err
+	}
+
+	// the todo owner moderates the comments on their todos
+	if comment.AuthorID != userID && todo.UserID != userID {
+		return domain.ErrCommentForbidden
+	}
+
+	if err = s.commentRepo.Delete(ctx, comment.ID); err != nil {
+		log.Err(err).Msg("error deleting comment")
+		return fmt.Errorf("error deleting comment: %w", err)
+	}
+
+	return nil
+}
+
+func (s *commentService) All(ctx context.Context, userID uint, todoUUID string, page *pagination.Page) ([]*domain.Comment, *pagination.Cursor, error) {
+	todo, err := s.todoService.ByUUID(ctx, userID, todoUUID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	comments, next, err := s.commentRepo.All(ctx, todo.ID, page)
+	if err != nil {
+		log.Err(err).Msg("error retrieving comments")
+		return nil, nil, err
+	}
+
+	return comments, next, nil
+}
+
+func (s *commentService) comment(ctx context.Context, userID uint, todoUUID string, uuid string) (*domain.Todo, *domain.Comment, error) {
+	todo, err := s.todoService.ByUUID(ctx, userID, todoUUID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	comment, err := s.commentRepo.ByUUID(ctx, todo.ID, uuid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, fmt.Errorf("comment not found: %w", domain.ErrCommentNotFound)
+		}
+		log.Err(err).Msg("error retrieving comment")
+		return nil, nil, err
+	}
+
+	return todo, comment, nil
+}
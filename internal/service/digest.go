@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/metrics"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+// dueDigestBatchSize is how many digests a single digest run sends.
+const dueDigestBatchSize = 100
+
+// DigestService emails users who opted in a weekly summary of their todos: what they completed,
+// what is overdue and what is due in the coming week. Digests are sent at the weekday and hour
+// the user picked in their timezone.
+type DigestService interface {
+	// Settings returns the digest settings of the user, the defaults when they never saved any.
+	Settings(ctx context.Context, userID uint) (*domain.DigestSettings, error)
+	UpdateSettings(ctx context.Context, userID uint, update *domain.DigestSettingsUpdate) (*domain.DigestSettings, error)
+
+	// SendDue queues the digests that are due, it is run by a background worker.
+	SendDue(ctx context.Context) error
+}
+
+type digestService struct {
+	*BaseService
+
+	notificationService NotificationService
+
+	digestRepo repo.DigestRepo
+	userRepo   repo.UserRepo
+}
+
+func NewDigestService(
+	base *BaseService,
+	notificationService NotificationService,
+	digestRepo repo.DigestRepo,
+	userRepo repo.UserRepo,
+) *digestService {
+	return &digestService{
+		BaseService:         base,
+		notificationService: notificationService,
+		digestRepo:          digestRepo,
+		userRepo:            userRepo,
+	}
+}
+
+// check DigestService interface implementation on compile time.
+var _ DigestService = (*digestService)(nil)
+
+func (s *digestService) Settings(ctx context.Context, userID uint) (*domain.DigestSettings, error) {
+	settings, err := s.digestRepo.Settings(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.DefaultDigestSettings(userID), nil
+		}
+		log.Err(err).Msg("error retrieving digest settings")
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+func (s *digestService) UpdateSettings(
+	ctx context.Context,
+	userID uint,
+	update *domain.DigestSettingsUpdate,
+) (*domain.DigestSettings, error) {
+	if update == nil {
+		return nil, fmt.Errorf("no digest settings provided")
+	}
+
+	settings := &domain.DigestSettings{
+		UserID:  userID,
+		Enabled: update.Enabled,
+		Weekday: update.Weekday,
+		Hour:    update.Hour,
+	}
+	settings.NextSendAt = settings.NextSend(time.Now(), userLocation(ctx, s.userRepo, userID))
+
+	settings, err := s.digestRepo.Upsert(ctx, settings)
+	if err != nil {
+		log.Err(err).Msg("error saving digest settings")
+		return nil, fmt.Errorf("error saving digest settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+func (s *digestService) SendDue(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	// claiming postpones the digest, so a digest that failed to be queued is retried later
+	// rather than by every run
+	due, err := s.digestRepo.Due(ctx, now, now.Add(domain.DigestRetryInterval), dueDigestBatchSize)
+	if err != nil {
+		return fmt.Errorf("error retrieving due digests: %w", err)
+	}
+	metrics.WorkerQueueDepth.WithLabelValues("weekly_digests", metrics.RegionLabel(region.FromContext(ctx))).Set(float64(len(due)))
+
+	for _, settings := range due {
+		if err = s.send(ctx, settings, now); err != nil {
+			log.Err(err).Uint("user_id", settings.UserID).Msg("error sending weekly digest")
+		}
+	}
+
+	return nil
+}
+
+func (s *digestService) send(ctx context.Context, settings *domain.DigestSettings, now time.Time) error {
+	user, err := s.userRepo.ByID(ctx, settings.UserID)
+	if err != nil {
+		return fmt.Errorf("error retrieving user: %w", err)
+	}
+
+	// the week starts with the last digest, a missed week isn't summed up twice
+	from := now.Add(-domain.DigestPeriod)
+	if settings.LastSentAt.After(from) {
+		from = settings.LastSentAt
+	}
+	digest, err := s.digestRepo.Digest(ctx, user.ID, from, now, domain.MaxDigestTodos)
+	if err != nil {
+		return fmt.Errorf("error collecting weekly digest: %w", err)
+	}
+
+	location := user.Location()
+	if !digest.Empty() {
+		digest.In(location)
+		if err = s.notificationService.SendWeeklyDigest(ctx, user, digest); err != nil {
+			return err
+		}
+	}
+
+	// the next digest follows the timezone the user has now
+	if err = s.digestRepo.Sent(ctx, user.ID, now, settings.NextSend(now, location)); err != nil {
+		return fmt.Errorf("error recording weekly digest: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DisplayService manages read-only display tokens, so a wall mounted tablet can show a board of
+// lists without a full user session.
+type DisplayService interface {
+	Create(ctx context.Context, userID uint, tokenCreate *domain.DisplayTokenCreate) (*domain.DisplayToken, error)
+	Revoke(ctx context.Context, userID uint, uuid string) error
+	All(ctx context.Context, userID uint) ([]*domain.DisplayToken, error)
+
+	// Authenticate resolves a raw display token, expired and revoked tokens are rejected.
+	Authenticate(ctx context.Context, token string) (*domain.DisplayToken, error)
+	Board(ctx context.Context, token *domain.DisplayToken) (*domain.DisplayBoard, error)
+}
+
+type displayService struct {
+	*BaseService
+
+	listService      ListService
+	householdService HouseholdService
+
+	displayTokenRepo repo.DisplayTokenRepo
+	listRepo         repo.ListRepo
+	todoRepo         repo.TodoRepo
+}
+
+func NewDisplayService(
+	base *BaseService,
+	listService ListService,
+	householdService HouseholdService,
+	displayTokenRepo repo.DisplayTokenRepo,
+	listRepo repo.ListRepo,
+	todoRepo repo.TodoRepo,
+) *displayService {
+	return &displayService{
+		BaseService:      base,
+		listService:      listService,
+		householdService: householdService,
+		displayTokenRepo: displayTokenRepo,
+		listRepo:         listRepo,
+		todoRepo:         todoRepo,
+	}
+}
+
+// check DisplayService interface implementation on compile time.
+var _ DisplayService = (*displayService)(nil)
+
+func (s *displayService) Create(ctx context.Context, userID uint, tokenCreate *domain.DisplayTokenCreate) (*domain.DisplayToken, error) {
+	if tokenCreate == nil {
+		return nil, fmt.Errorf("no display token details provided")
+	}
+
+	// a display shows the lists to anyone in the room
+	if err := s.householdService.Permit(ctx, userID, domain.ParentalActionShare); err != nil {
+		return nil, err
+	}
+
+	listIDs := make([]uint, 0, len(tokenCreate.ListUUIDs))
+	for _, listUUID := range tokenCreate.ListUUIDs {
+		list, err := s.listService.ByUUID(ctx, userID, listUUID)
+		if err != nil {
+			return nil, err
+		}
+		listIDs = append(listIDs, list.ID)
+	}
+
+	raw, err := generateToken(domain.DisplayTokenPrefix)
+	if err != nil {
+		log.Err(err).Msg("error generating display token")
+		return nil, err
+	}
+
+	token, err := s.displayTokenRepo.Create(ctx, &domain.DisplayToken{
+		UUID:      s.GenerateUUIDHash("display"),
+		UserID:    userID,
+		Name:      tokenCreate.Name,
+		ListIDs:   listIDs,
+		ExpiresAt: tokenCreate.ExpiresAt,
+	}, hashToken(raw))
+	if err != nil {
+		log.Err(err).Msg("error creating display token")
+		return nil, fmt.Errorf("error creating display token: %w", err)
+	}
+
+	token.Token = raw
+	return token, nil
+}
+
+func (s *displayService) Revoke(ctx context.Context, userID uint, uuid string) error {
+	tokens, err := s.All(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, token := range tokens {
+		if token.UUID != uuid {
+			continue
+		}
+
+		if err = s.displayTokenRepo.Delete(ctx, userID, uuid); err != nil {
+			log.Err(err).Msg("error revoking display token")
+			return fmt.Errorf("error revoking display token: %w", err)
+		}
+		return nil
+	}
+
+	return domain.ErrDisplayTokenNotFound
+}
+
+func (s *displayService) All(ctx context.Context, userID uint) ([]*domain.DisplayToken, error) {
+	tokens, err := s.displayTokenRepo.All(ctx, userID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving display tokens")
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+func (s *displayService) Authenticate(ctx context.Context, raw string) (*domain.DisplayToken, error) {
+	if !strings.HasPrefix(raw, domain.DisplayTokenPrefix) {
+		return nil, domain.ErrInvalidDisplayToken
+	}
+
+	token, err := s.displayTokenRepo.ByHash(ctx, hashToken(raw))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrInvalidDisplayToken
+		}
+		log.Err(err).Msg("error retrieving display token")
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if token.Expired(now) {
+		return nil, domain.ErrInvalidDisplayToken
+	}
+
+	if err = s.displayTokenRepo.Touch(ctx, token.ID, now); err != nil {
+		// usage tracking must not take the display down
+		log.Err(err).Str("display_token", token.UUID).Msg("error updating display token usage")
+	}
+
+	return token, nil
+}
+
+func (s *displayService) Board(ctx context.Context, token *domain.DisplayToken) (*domain.DisplayBoard, error) {
+	board := &domain.DisplayBoard{
+		Lists: make([]*domain.DisplayBoardList, 0, len(token.ListIDs)),
+	}
+
+	version := sha256.New()
+	for _, listID := range token.ListIDs {
+		list, err := s.listRepo.ByID(ctx, listID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			log.Err(err).Msg("error retrieving list for display")
+			return nil, err
+		}
+
+		// lists can only be shown while they still belong to the token owner
+		if list.UserID != token.UserID {
+			continue
+		}
+
+		todos, _, err := s.todoRepo.All(ctx, token.UserID, &domain.TodoFilter{
+			ListID: list.ID,
+			Sort:   []domain.TodoSortField{domain.TodoSortPriority, domain.TodoSortDueDate},
+			Order:  domain.SortOrderDesc,
+		}, nil)
+		if err != nil {
+			log.Err(err).Msg("error retrieving todos for display")
+			return nil, err
+		}
+
+		writeBoardVersion(version, list.UUID, list.UpdatedAt)
+		for _, todo := range todos {
+			writeBoardVersion(version, todo.UUID, todo.UpdatedAt)
+		}
+
+		board.Lists = append(board.Lists, &domain.DisplayBoardList{
+			List:  list,
+			Todos: todos,
+		})
+	}
+
+	board.Version = hex.EncodeToString(version.Sum(nil))[:16]
+	return board, nil
+}
+
+// writeBoardVersion adds an item to the board version, a changed or removed item changes the version.
+func writeBoardVersion(version hash.Hash, uuid string, updatedAt time.Time) {
+	version.Write([]byte(uuid + ":" + strconv.FormatInt(updatedAt.UnixNano(), 10) + ";")) //nolint:errcheck // hash writes never fail
+}
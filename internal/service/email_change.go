@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/audit"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/password"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+// EmailChangeService changes the email of accounts. The password is entered again and both the
+// current and the new address get a link to confirm the change, the email is only swapped once
+// both confirmed, so neither a stolen session nor a mistyped address takes over the account.
+type EmailChangeService interface {
+	// Request replaces the pending change of the user and emails the confirmation links.
+	Request(ctx context.Context, userID uint, request *domain.EmailChangeRequest) (*domain.EmailChange, error)
+	// Confirm confirms the address of an emailed token, userRegion is the data region from the
+	// link. The email is swapped by the second confirmation.
+	Confirm(ctx context.Context, token string, userRegion string) (*domain.EmailChange, error)
+	Cancel(ctx context.Context, userID uint) error
+}
+
+type emailChangeService struct {
+	*BaseService
+
+	notificationService NotificationService
+
+	txManager       repo.TxManager
+	userRepo        repo.UserRepo
+	userRegionRepo  repo.UserRegionRepo
+	emailChangeRepo repo.EmailChangeRepo
+}
+
+func NewEmailChangeService(
+	base *BaseService,
+	notificationService NotificationService,
+	txManager repo.TxManager,
+	userRepo repo.UserRepo,
+	userRegionRepo repo.UserRegionRepo,
+	emailChangeRepo repo.EmailChangeRepo,
+) *emailChangeService {
+	return &emailChangeService{
+		BaseService:         base,
+		notificationService: notificationService,
+		txManager:           txManager,
+		userRepo:            userRepo,
+		userRegionRepo:      userRegionRepo,
+		emailChangeRepo:     emailChangeRepo,
+	}
+}
+
+// check EmailChangeService interface implementation on compile time.
+var _ EmailChangeService = (*emailChangeService)(nil)
+
+func (s *emailChangeService) Request(ctx context.Context, userID uint, request *domain.EmailChangeRequest) (*domain.EmailChange, error) {
+	user, err := s.userRepo.ByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrUserNotFound
+		}
+		log.Err(err).Msg("error retrieving user")
+		return nil, err
+	}
+	if user.IsChild() {
+		return nil, domain.ErrChildAccountEmail
+	}
+	if strings.EqualFold(request.Email, user.Email) {
+		return nil, domain.ErrEmailUnchanged
+	}
+
+	withPassword, err := s.userRepo.ByEmailWithPassword(ctx, user.Email)
+	if err != nil {
+		log.Err(err).Msg("error retrieving user password")
+		return nil, err
+	}
+	if _, err = s.comparePassword(ctx, withPassword.Password, request.Password); err != nil {
+		if errors.Is(err, password.ErrMismatch) {
+			return nil, domain.ErrIncorrectPassword
+		}
+		log.Err(err).Msg("error comparing password")
+		return nil, err
+	}
+
+	if err = s.available(ctx, request.Email); err != nil {
+		return nil, err
+	}
+
+	oldToken, err := generateToken(domain.EmailChangeTokenPrefix)
+	if err != nil {
+		return nil, err
+	}
+	newToken, err := generateToken(domain.EmailChangeTokenPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	change, err := s.emailChangeRepo.Create(ctx, &domain.EmailChange{
+		UUID:      s.GenerateUUIDHash("emailchange"),
+		UserID:    user.ID,
+		NewEmail:  request.Email,
+		ExpiresAt: time.Now().Add(domain.EmailChangeExpiration),
+	}, hashToken(oldToken), hashToken(newToken))
+	if err != nil {
+		log.Err(err).Msg("error creating email change")
+		return nil, fmt.Errorf("error creating email change: %w", err)
+	}
+
+	confirmations := []struct{ to, token string }{{user.Email, oldToken}, {change.NewEmail, newToken}}
+	for _, confirmation := range confirmations {
+		err = s.notificationService.SendEmailChange(
+			ctx, user, confirmation.to, change.NewEmail, s.confirmURL(ctx, confirmation.token), change.ExpiresAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return change, nil
+}
+
+// available checks that no account uses the email, in any region.
+func (s *emailChangeService) available(ctx context.Context, email string) error {
+	_, err := s.userRegionRepo.Region(ctx, loginKey("email", email))
+	if err == nil {
+		return domain.ErrEmailTaken
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		log.Err(err).Msg("error retrieving user region")
+		return err
+	}
+
+	// logins created before regions existed are only in the home region
+	if _, err = s.userRepo.ByEmail(region.WithRegion(ctx, region.Home), email); err == nil {
+		return domain.ErrEmailTaken
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		log.Err(err).Msg("error retrieving user by email")
+		return err
+	}
+
+	return nil
+}
+
+func (s *emailChangeService) confirmURL(ctx context.Context, token string) string {
+	query := url.Values{"token": {token}}
+	if userRegion := region.FromContext(ctx); userRegion != region.Home {
+		query.Set("region", userRegion)
+	}
+
+	return fmt.Sprintf("%s/email-change?%s", strings.TrimRight(s.Config.GetAppURL(), "/"), query.Encode())
+}
+
+func (s *emailChangeService) Confirm(ctx context.Context, token string, userRegion string) (*domain.EmailChange, error) {
+	if !s.servesRegion(userRegion) {
+		return nil, fmt.Errorf("region %q: %w", userRegion, domain.ErrUnknownRegion)
+	}
+	ctx = region.WithRegion(ctx, userRegion)
+	audit.SetRegion(ctx, userRegion)
+
+	now := time.Now().UTC()
+	change, err := s.emailChangeRepo.Confirm(ctx, hashToken(token), now)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrInvalidEmailChangeToken
+		}
+		log.Err(err).Msg("error confirming email change")
+		return nil, err
+	}
+	audit.SetUser(ctx, change.UserID)
+
+	if !change.Confirmed() {
+		return change, nil
+	}
+	if err = s.swap(ctx, change, userRegion, now); err != nil {
+		return nil, err
+	}
+	change.CompletedAt = now
+
+	return change, nil
+}
+
+// swap changes the email once both addresses confirmed. The new login is claimed in the region
+// directory first, the directory lives in the home database and can't join the transaction.
+func (s *emailChangeService) swap(ctx context.Context, change *domain.EmailChange, userRegion string, now time.Time) error {
+	user, err := s.userRepo.ByID(ctx, change.UserID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving user")
+		return err
+	}
+
+	newKey := loginKey("email", change.NewEmail)
+	if err = s.userRegionRepo.Create(ctx, newKey, userRegion); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrEmailTaken
+		}
+		log.Err(err).Msg("error claiming user region")
+		return err
+	}
+
+	err = s.txManager.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.emailChangeRepo.Complete(ctx, change.ID, now); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrInvalidEmailChangeToken
+			}
+			return err
+		}
+
+		return s.userRepo.UpdateEmail(ctx, change.UserID, change.NewEmail)
+	})
+	if err != nil {
+		if deleteErr := s.userRegionRepo.Delete(ctx, newKey); deleteErr != nil {
+			log.Err(deleteErr).Msg("error releasing user region")
+		}
+		if !errors.Is(err, domain.ErrInvalidEmailChangeToken) {
+			log.Err(err).Msg("error changing email")
+		}
+		return err
+	}
+
+	if err = s.userRegionRepo.Delete(ctx, loginKey("email", user.Email)); err != nil {
+		log.Err(err).Str("user", user.UUID).Msg("error releasing previous email")
+	}
+	log.Info().Str("user", user.UUID).Msg("changed email")
+
+	return nil
+}
+
+func (s *emailChangeService) Cancel(ctx context.Context, userID uint) error {
+	if err := s.emailChangeRepo.Cancel(ctx, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrEmailChangeNotFound
+		}
+		log.Err(err).Msg("error cancelling email change")
+		return fmt.Errorf("error cancelling email change: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+// EncryptionService manages end-to-end encrypted lists. Clients encrypt the todos of such a list
+// with a list key they wrap with the public key of every user that may read it, the server only
+// stores the wrapped keys and ciphertext. Only the owner of a list encrypts it and hands out its
+// keys.
+type EncryptionService interface {
+	// SetPublicKey sets or replaces the public key list keys are wrapped with for the user.
+	SetPublicKey(ctx context.Context, userID uint, key string) (*domain.PublicKey, error)
+	// PublicKeys returns the public keys of the owner and the members of a list the user can
+	// access, an empty key for users that didn't set one up yet.
+	PublicKeys(ctx context.Context, userID uint, listUUID string) ([]*domain.PublicKey, error)
+
+	// Encrypt turns an empty list of the user into an encrypted list with key version 1,
+	// wrappedKey is that key wrapped for the owner.
+	Encrypt(ctx context.Context, userID uint, listUUID string, wrappedKey string) (*domain.List, error)
+	// Keyring returns the versions of the key of a list wrapped for the user, for the owner
+	// together with the members still missing the current version.
+	Keyring(ctx context.Context, userID uint, listUUID string) (*domain.ListKeyring, error)
+	// AddKeys hands the key of keyVersion, which must be the current one, to members of the list.
+	AddKeys(ctx context.Context, userID uint, listUUID string, keyVersion int, keys []*domain.ListKey) (*domain.ListKeyring, error)
+	// RotateKey replaces the key of fromVersion with a new version wrapped for the owner and every
+	// member with a public key, e.g. after a member was removed. Todos keep their key version
+	// until a client encrypts them again.
+	RotateKey(ctx context.Context, userID uint, listUUID string, fromVersion int, keys []*domain.ListKey) (*domain.ListKeyring, error)
+}
+
+type encryptionService struct {
+	*BaseService
+
+	listService ListService
+
+	encryptionRepo repo.EncryptionRepo
+}
+
+func NewEncryptionService(base *BaseService, listService ListService, encryptionRepo repo.EncryptionRepo) *encryptionService {
+	return &encryptionService{
+		BaseService:    base,
+		listService:    listService,
+		encryptionRepo: encryptionRepo,
+	}
+}
+
+// check EncryptionService interface implementation on compile time.
+var _ EncryptionService = (*encryptionService)(nil)
+
+func (s *encryptionService) SetPublicKey(ctx context.Context, userID uint, key string) (*domain.PublicKey, error) {
+	publicKey, err := s.encryptionRepo.SavePublicKey(ctx, userID, key)
+	if err != nil {
+		log.Err(err).Msg("error saving public key")
+		return nil, fmt.Errorf("error saving public key: %w", err)
+	}
+
+	return publicKey, nil
+}
+
+func (s *encryptionService) PublicKeys(ctx context.Context, userID uint, listUUID string) ([]*domain.PublicKey, error) {
+	access, err := s.listService.Access(ctx, userID, listUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := s.encryptionRepo.PublicKeys(ctx, access.List.ID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving public keys")
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (s *encryptionService) Encrypt(ctx context.Context, userID uint, listUUID string, wrappedKey string) (*domain.List, error) {
+	list, err := s.listService.ByUUID(ctx, userID, listUUID)
+	if err != nil {
+		return nil, err
+	}
+	if list.Encrypted() {
+		return nil, domain.ErrListAlreadyEncrypted
+	}
+
+	recipients, err := s.recipients(ctx, list)
+	if err != nil {
+		return nil, err
+	}
+	owner, ok := recipients[list.UserID]
+	if !ok || owner.Key == "" {
+		return nil, domain.ErrPublicKeyNotFound
+	}
+
+	err = s.encryptionRepo.Encrypt(ctx, list.ID, &domain.ListKey{ListID: list.ID, UserID: list.UserID, WrappedKey: wrappedKey})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrListNotEmpty
+		}
+		log.Err(err).Msg("error encrypting list")
+		return nil, fmt.Errorf("error encrypting list: %w", err)
+	}
+
+	return s.listService.ByUUID(ctx, userID, listUUID)
+}
+
+func (s *encryptionService) Keyring(ctx context.Context, userID uint, listUUID string) (*domain.ListKeyring, error) {
+	access, err := s.listService.Access(ctx, userID, listUUID)
+	if err != nil {
+		return nil, err
+	}
+	if !access.List.Encrypted() {
+		return nil, domain.ErrListNotEncrypted
+	}
+
+	return s.keyring(ctx, userID, access.List)
+}
+
+func (s *encryptionService) AddKeys(
+	ctx context.Context,
+	userID uint,
+	listUUID string,
+	keyVersion int,
+	keys []*domain.ListKey,
+) (*domain.ListKeyring, error) {
+	list, err := s.encryptedList(ctx, userID, listUUID, keyVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	recipients, err := s.recipients(ctx, list)
+	if err != nil {
+		return nil, err
+	}
+	if err = resolveKeys(list, recipients, keyVersion, keys); err != nil {
+		return nil, err
+	}
+
+	if err = s.encryptionRepo.SaveKeys(ctx, keys); err != nil {
+		log.Err(err).Msg("error saving list keys")
+		return nil, fmt.Errorf("error saving list keys: %w", err)
+	}
+
+	return s.keyring(ctx, userID, list)
+}
+
+func (s *encryptionService) RotateKey(
+	ctx context.Context,
+	userID uint,
+	listUUID string,
+	fromVersion int,
+	keys []*domain.ListKey,
+) (*domain.ListKeyring, error) {
+	list, err := s.encryptedList(ctx, userID, listUUID, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	recipients, err := s.recipients(ctx, list)
+	if err != nil {
+		return nil, err
+	}
+	if err = resolveKeys(list, recipients, fromVersion+1, keys); err != nil {
+		return nil, err
+	}
+
+	// a user left out of the new key couldn't read the todos encrypted with it
+	wrapped := make(map[uint]bool, len(keys))
+	for _, key := range keys {
+		wrapped[key.UserID] = true
+	}
+	for _, recipient := range recipients {
+		if recipient.Key != "" && !wrapped[recipient.UserID] {
+			return nil, fmt.Errorf("no key for user %s: %w", recipient.UserUUID, domain.ErrIncompleteListKeys)
+		}
+	}
+
+	if _, err = s.encryptionRepo.Rotate(ctx, list.ID, fromVersion, keys); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("list %s was rotated since: %w", listUUID, domain.ErrStaleListKey)
+		}
+		log.Err(err).Msg("error rotating list key")
+		return nil, fmt.Errorf("error rotating list key: %w", err)
+	}
+
+	list.KeyVersion = fromVersion + 1
+
+	return s.keyring(ctx, userID, list)
+}
+
+// encryptedList returns an encrypted list of the user that is at keyVersion.
+func (s *encryptionService) encryptedList(ctx context.Context, userID uint, listUUID string, keyVersion int) (*domain.List, error) {
+	list, err := s.listService.ByUUID(ctx, userID, listUUID)
+	if err != nil {
+		return nil, err
+	}
+	if !list.Encrypted() {
+		return nil, domain.ErrListNotEncrypted
+	}
+	if keyVersion != list.KeyVersion {
+		return nil, fmt.Errorf("list %s is at key version %d: %w", listUUID, list.KeyVersion, domain.ErrStaleListKey)
+	}
+
+	return list, nil
+}
+
+// recipients returns the public keys of the owner and the members of a list by user ID.
+func (s *encryptionService) recipients(ctx context.Context, list *domain.List) (map[uint]*domain.PublicKey, error) {
+	keys, err := s.encryptionRepo.PublicKeys(ctx, list.ID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving public keys")
+		return nil, err
+	}
+
+	recipients := make(map[uint]*domain.PublicKey, len(keys))
+	for _, key := range keys {
+		recipients[key.UserID] = key
+	}
+
+	return recipients, nil
+}
+
+// resolveKeys fills in the list, user and version of keys, which name their user by UUID. Keys
+// are only wrapped once for recipients that have a public key.
+func resolveKeys(list *domain.List, recipients map[uint]*domain.PublicKey, keyVersion int, keys []*domain.ListKey) error {
+	byUUID := make(map[string]*domain.PublicKey, len(recipients))
+	for _, recipient := range recipients {
+		byUUID[recipient.UserUUID] = recipient
+	}
+
+	seen := make(map[uint]bool, len(keys))
+	for _, key := range keys {
+		recipient, ok := byUUID[key.UserUUID]
+		if !ok || seen[recipient.UserID] {
+			return fmt.Errorf("user %s: %w", key.UserUUID, domain.ErrListKeyNotMember)
+		}
+		if recipient.Key == "" {
+			return fmt.Errorf("user %s: %w", key.UserUUID, domain.ErrPublicKeyNotFound)
+		}
+		seen[recipient.UserID] = true
+
+		key.ListID = list.ID
+		key.UserID = recipient.UserID
+		key.KeyVersion = keyVersion
+	}
+
+	return nil
+}
+
+// keyring returns the keys of an encrypted list wrapped for the user, the owner also learns who
+// is still missing the current version.
+func (s *encryptionService) keyring(ctx context.Context, userID uint, list *domain.List) (*domain.ListKeyring, error) {
+	keys, err := s.encryptionRepo.Keys(ctx, list.ID, userID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving list keys")
+		return nil, err
+	}
+
+	keyring := &domain.ListKeyring{KeyVersion: list.KeyVersion, Keys: keys, Pending: []string{}}
+	if list.UserID != userID {
+		return keyring, nil
+	}
+
+	recipients, err := s.recipients(ctx, list)
+	if err != nil {
+		return nil, err
+	}
+	holders, err := s.encryptionRepo.ByVersion(ctx, list.ID, list.KeyVersion)
+	if err != nil {
+		log.Err(err).Msg("error retrieving list keys")
+		return nil, err
+	}
+	for _, holder := range holders {
+		delete(recipients, holder.UserID)
+	}
+	for _, recipient := range recipients {
+		keyring.Pending = append(keyring.Pending, recipient.UserUUID)
+	}
+	sort.Strings(keyring.Pending)
+
+	return keyring, nil
+}
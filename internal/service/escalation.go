@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/metrics"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/pagination"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+// escalationBatchSize is how many escalations a single escalation run applies.
+const escalationBatchSize = 200
+
+// EscalationService manages the escalation rules of lists, e.g. raise todos overdue by two days
+// to urgent and email the owner. Only the owner of a list manages its rules, the escalations
+// applied are kept in the activity log of the list.
+type EscalationService interface {
+	Rules(ctx context.Context, userID uint, listUUID string) ([]*domain.EscalationRule, error)
+	CreateRule(ctx context.Context, userID uint, listUUID string, ruleCreate *domain.EscalationRuleCreate) (*domain.EscalationRule, error)
+	UpdateRule(ctx context.Context, userID uint, listUUID string, uuid string, ruleCreate *domain.EscalationRuleCreate) (*domain.EscalationRule, error)
+	DeleteRule(ctx context.Context, userID uint, listUUID string, uuid string) error
+	// Log returns the escalations applied to the todos of a list, newest first.
+	Log(ctx context.Context, userID uint, listUUID string, page *pagination.Page) ([]*domain.Escalation, *pagination.Cursor, error)
+
+	// EscalateDue applies the rules to the todos that became overdue enough, it is run by a
+	// background worker.
+	EscalateDue(ctx context.Context) error
+}
+
+type escalationService struct {
+	*BaseService
+
+	listService         ListService
+	todoService         TodoService
+	notificationService NotificationService
+
+	escalationRepo repo.EscalationRepo
+	userRepo       repo.UserRepo
+}
+
+func NewEscalationService(
+	base *BaseService,
+	listService ListService,
+	todoService TodoService,
+	notificationService NotificationService,
+	escalationRepo repo.EscalationRepo,
+	userRepo repo.UserRepo,
+) *escalationService {
+	return &escalationService{
+		BaseService:         base,
+		listService:         listService,
+		todoService:         todoService,
+		notificationService: notificationService,
+		escalationRepo:      escalationRepo,
+		userRepo:            userRepo,
+	}
+}
+
+// check EscalationService interface implementation on compile time.
+var _ EscalationService = (*escalationService)(nil)
+
+func (s *escalationService) Rules(ctx context.Context, userID uint, listUUID string) ([]*domain.EscalationRule, error) {
+	list, err := s.listService.ByUUID(ctx, userID, listUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := s.escalationRepo.ByList(ctx, list.ID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving escalation rules")
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func (s *escalationService) CreateRule(
+	ctx context.Context,
+	userID uint,
+	listUUID string,
+	ruleCreate *domain.EscalationRuleCreate,
+) (*domain.EscalationRule, error) {
+	rule, err := s.rule(ctx, userID, listUUID, ruleCreate)
+	if err != nil {
+		return nil, err
+	}
+	rule.UUID = s.GenerateUUIDHash("escalation")
+
+	created, err := s.escalationRepo.Create(ctx, rule)
+	if err != nil {
+		log.Err(err).Msg("error creating escalation rule")
+		return nil, fmt.Errorf("error creating escalation rule: %w", err)
+	}
+
+	return created, nil
+}
+
+func (s *escalationService) UpdateRule(
+	ctx context.Context,
+	userID uint,
+	listUUID string,
+	uuid string,
+	ruleCreate *domain.EscalationRuleCreate,
+) (*domain.EscalationRule, error) {
+	rule, err := s.rule(ctx, userID, listUUID, ruleCreate)
+	if err != nil {
+		return nil, err
+	}
+	rule.UUID = uuid
+
+	updated, err := s.escalationRepo.Update(ctx, rule)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("escalation rule not found: %w", domain.ErrEscalationRuleNotFound)
+		}
+		log.Err(err).Msg("error updating escalation rule")
+		return nil, fmt.Errorf("error updating escalation rule: %w", err)
+	}
+
+	return updated, nil
+}
+
+func (s *escalationService) DeleteRule(ctx context.Context, userID uint, listUUID string, uuid string) error {
+	rules, err := s.Rules(ctx, userID, listUUID)
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		if rule.UUID != uuid {
+			continue
+		}
+		if err = s.escalationRepo.Delete(ctx, rule.ListID, uuid); err != nil {
+			log.Err(err).Msg("error deleting escalation rule")
+			return fmt.Errorf("error deleting escalation rule: %w", err)
+		}
+		return nil
+	}
+
+	return domain.ErrEscalationRuleNotFound
+}
+
+func (s *escalationService) Log(
+	ctx context.Context,
+	userID uint,
+	listUUID string,
+	page *pagination.Page,
+) ([]*domain.Escalation, *pagination.Cursor, error) {
+	list, err := s.listService.ByUUID(ctx, userID, listUUID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	escalations, next, err := s.escalationRepo.Log(ctx, list.ID, page)
+	if err != nil {
+		log.Err(err).Msg("error retrieving escalations")
+		return nil, nil, err
+	}
+
+	return escalations, next, nil
+}
+
+// rule checks the definition of a rule for the list of the user.
+func (s *escalationService) rule(
+	ctx context.Context,
+	userID uint,
+	listUUID string,
+	ruleCreate *domain.EscalationRuleCreate,
+) (*domain.EscalationRule, error) {
+	if ruleCreate == nil {
+		return nil, fmt.Errorf("no escalation rule provided")
+	}
+	if ruleCreate.Priority == nil && !ruleCreate.Notify {
+		return nil, domain.ErrEscalationNoActions
+	}
+
+	list, err := s.listService.ByUUID(ctx, userID, listUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.EscalationRule{
+		ListID:    list.ID,
+		UserID:    userID,
+		OverdueBy: ruleCreate.OverdueBy.Truncate(24 * time.Hour),
+		Priority:  ruleCreate.Priority,
+		Notify:    ruleCreate.Notify,
+	}, nil
+}
+
+func (s *escalationService) EscalateDue(ctx context.Context) error {
+	// claiming records the escalations, a todo whose update or email fails isn't escalated
+	// by the rule again
+	escalations, err := s.escalationRepo.Claim(ctx, time.Now(), escalationBatchSize)
+	if err != nil {
+		return fmt.Errorf("error claiming due escalations: %w", err)
+	}
+	metrics.WorkerQueueDepth.WithLabelValues("todo_escalations", metrics.RegionLabel(region.FromContext(ctx))).Set(float64(len(escalations)))
+
+	// a todo escalated by several rules at once is raised to the highest of their priorities and
+	// listed once in the email to its owner
+	byTodo := make(map[uint]*domain.Escalation, len(escalations))
+	notify := make(map[uint][]*domain.Escalation)
+	for _, escalation := range escalations {
+		raised, ok := byTodo[escalation.TodoID]
+		if !ok {
+			byTodo[escalation.TodoID] = escalation
+			if escalation.Notified {
+				notify[escalation.UserID] = append(notify[escalation.UserID], escalation)
+			}
+			continue
+		}
+		if escalation.PriorityTo != nil && (raised.PriorityTo == nil || *escalation.PriorityTo > *raised.PriorityTo) {
+			raised.PriorityTo = escalation.PriorityTo
+		}
+		if escalation.Notified && !raised.Notified {
+			raised.Notified = true
+			notify[raised.UserID] = append(notify[raised.UserID], raised)
+		}
+	}
+
+	for _, escalation := range byTodo {
+		if escalation.PriorityTo == nil {
+			continue
+		}
+		todoUpdate := &domain.TodoUpdate{Priority: escalation.PriorityTo}
+		if _, err = s.todoService.Update(ctx, escalation.UserID, escalation.TodoUUID, todoUpdate); err != nil {
+			log.Err(err).Str("todo", escalation.TodoUUID).Msg("error escalating todo")
+		}
+	}
+
+	for userID, escalations := range notify {
+		if err = s.notify(ctx, userID, escalations); err != nil {
+			log.Err(err).Uint("user_id", userID).Msg("error notifying of escalated todos")
+		}
+	}
+
+	return nil
+}
+
+func (s *escalationService) notify(ctx context.Context, userID uint, escalations []*domain.Escalation) error {
+	user, err := s.userRepo.ByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("error retrieving user: %w", err)
+	}
+
+	return s.notificationService.SendEscalation(ctx, user, escalations)
+}
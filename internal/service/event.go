@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/pubsub"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+// EventService publishes todo events to the owner of the todo and, for todos on a list, to the
+// room of the list, clients subscribe to update without polling.
+type EventService interface {
+	// Subscribe returns the JSON encoded events of a user until ctx is done.
+	Subscribe(ctx context.Context, userID uint) (<-chan []byte, error)
+	// SubscribeList returns the JSON encoded events of the todos on a list the user owns or
+	// collaborates on until ctx is done.
+	SubscribeList(ctx context.Context, userID uint, listUUID string) (<-chan []byte, error)
+	// RoomTicket returns a ticket that opens the room of a list the user of claims owns or
+	// collaborates on once, see domain.RoomTicket.
+	RoomTicket(ctx context.Context, claims *domain.JWTCustomClaims, listUUID string) (*domain.RoomTicket, error)
+	// UseRoomTicket redeems a ticket for the room of the list and returns the claims of the
+	// session it was issued for.
+	UseRoomTicket(ctx context.Context, ticket string, listUUID string) (*domain.JWTCustomClaims, error)
+
+	HandleTodoEvent(ctx context.Context, event *domain.TodoEvent)
+}
+
+type eventService struct {
+	*BaseService
+
+	listService ListService
+
+	roomTicketRepo repo.RoomTicketRepo
+	pubSub         pubsub.PubSub
+}
+
+func NewEventService(base *BaseService, listService ListService, roomTicketRepo repo.RoomTicketRepo, pubSub pubsub.PubSub) *eventService {
+	return &eventService{
+		BaseService:    base,
+		listService:    listService,
+		roomTicketRepo: roomTicketRepo,
+		pubSub:         pubSub,
+	}
+}
+
+// check EventService interface implementation on compile time.
+var _ EventService = (*eventService)(nil)
+
+// check TodoEventHandler interface implementation on compile time.
+var _ TodoEventHandler = (*eventService)(nil)
+
+func (s *eventService) Subscribe(ctx context.Context, userID uint) (<-chan []byte, error) {
+	events, err := s.pubSub.Subscribe(ctx, userTopic(ctx, userID))
+	if err != nil {
+		log.Err(err).Msg("error subscribing to events")
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (s *eventService) SubscribeList(ctx context.Context, userID uint, listUUID string) (<-chan []byte, error) {
+	shared, err := s.listService.Access(ctx, userID, listUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := s.pubSub.Subscribe(ctx, listTopic(ctx, shared.List.ID))
+	if err != nil {
+		log.Err(err).Msg("error subscribing to list events")
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (s *eventService) RoomTicket(ctx context.Context, claims *domain.JWTCustomClaims, listUUID string) (*domain.RoomTicket, error) {
+	if _, err := s.listService.Access(ctx, claims.UserID, listUUID); err != nil {
+		return nil, err
+	}
+
+	raw, err := generateRegionalToken(ctx, domain.RoomTicketPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket := &domain.RoomTicket{
+		Ticket:    raw,
+		UserID:    claims.UserID,
+		ListUUID:  listUUID,
+		ExpiresAt: time.Now().UTC().Add(domain.RoomTicketExpiration),
+	}
+	ticket.SessionExpiresAt = ticket.ExpiresAt
+	if claims.ExpiresAt != nil {
+		ticket.SessionExpiresAt = claims.ExpiresAt.Time.UTC()
+	}
+	// the ticket can't outlive the session it was issued with
+	if ticket.SessionExpiresAt.Before(ticket.ExpiresAt) {
+		ticket.ExpiresAt = ticket.SessionExpiresAt
+	}
+
+	if err = s.roomTicketRepo.Create(ctx, ticket, hashToken(raw)); err != nil {
+		log.Err(err).Msg("error creating room ticket")
+		return nil, fmt.Errorf("error creating room ticket: %w", err)
+	}
+
+	return ticket, nil
+}
+
+func (s *eventService) UseRoomTicket(ctx context.Context, raw string, listUUID string) (*domain.JWTCustomClaims, error) {
+	if !strings.HasPrefix(raw, domain.RoomTicketPrefix) {
+		return nil, domain.ErrInvalidRoomTicket
+	}
+
+	ctx, err := s.tokenContext(ctx, domain.RoomTicketPrefix, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket, err := s.roomTicketRepo.Use(ctx, hashToken(raw), listUUID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrInvalidRoomTicket
+		}
+		log.Err(err).Msg("error using room ticket")
+		return nil, err
+	}
+
+	return &domain.JWTCustomClaims{
+		UserID: ticket.UserID,
+		Email:  ticket.Email,
+		UUID:   ticket.UserUUID,
+		Region: region.FromContext(ctx),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(ticket.SessionExpiresAt),
+			IssuedAt:  jwt.NewNumericDate(ticket.CreatedAt),
+		},
+	}, nil
+}
+
+func (s *eventService) HandleTodoEvent(ctx context.Context, event *domain.TodoEvent) {
+	payload, err := json.Marshal(endpoint.NewTodoEventPayload(s.GenerateUUIDHash("event"), event))
+	if err != nil {
+		log.Err(err).Str("event", string(event.Type)).Msg("error rendering event payload")
+		return
+	}
+
+	if err = s.pubSub.Publish(ctx, userTopic(ctx, event.Todo.UserID), payload); err != nil {
+		log.Err(err).Str("event", string(event.Type)).Msg("error publishing event")
+	}
+
+	if event.Todo.ListID != 0 {
+		if err = s.pubSub.Publish(ctx, listTopic(ctx, event.Todo.ListID), payload); err != nil {
+			log.Err(err).Str("event", string(event.Type)).Msg("error publishing list event")
+		}
+	}
+
+	// a moved todo also leaves the streams it was part of
+	if previous := event.Previous; previous != nil {
+		if previous.UserID != event.Todo.UserID {
+			if err = s.pubSub.Publish(ctx, userTopic(ctx, previous.UserID), payload); err != nil {
+				log.Err(err).Str("event", string(event.Type)).Msg("error publishing event")
+			}
+		}
+		if previous.ListID != 0 && previous.ListID != event.Todo.ListID {
+			if err = s.pubSub.Publish(ctx, listTopic(ctx, previous.ListID), payload); err != nil {
+				log.Err(err).Str("event", string(event.Type)).Msg("error publishing list event")
+			}
+		}
+	}
+}
+
+func userTopic(ctx context.Context, userID uint) string {
+	return region.Scoped(ctx, fmt.Sprintf("users:%d:events", userID))
+}
+
+func listTopic(ctx context.Context, listID uint) string {
+	return region.Scoped(ctx, fmt.Sprintf("lists:%d:events", listID))
+}
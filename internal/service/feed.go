@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/export"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+// FeedService renders the recent additions and completions of a list as an Atom feed and
+// manages the read-only tokens of the feed URLs, so a shared list can be followed from a
+// feed reader.
+type FeedService interface {
+	// Feed renders the list of the token, selfURL is the URL of the feed without the token.
+	Feed(ctx context.Context, token *domain.FeedToken, selfURL string) ([]byte, error)
+
+	// CreateToken creates a token for a list the user owns or that is shared with them.
+	CreateToken(ctx context.Context, userID uint, listUUID string, name string) (*domain.FeedToken, error)
+	RevokeToken(ctx context.Context, userID uint, uuid string) error
+	Tokens(ctx context.Context, userID uint, listUUID string) ([]*domain.FeedToken, error)
+	// Authenticate resolves a raw feed token, revoked tokens and tokens of deleted lists are
+	// rejected.
+	Authenticate(ctx context.Context, token string) (*domain.FeedToken, error)
+}
+
+type feedService struct {
+	*BaseService
+
+	listService      ListService
+	householdService HouseholdService
+
+	feedTokenRepo repo.FeedTokenRepo
+	todoRepo      repo.TodoRepo
+}
+
+func NewFeedService(
+	base *BaseService,
+	listService ListService,
+	householdService HouseholdService,
+	feedTokenRepo repo.FeedTokenRepo,
+	todoRepo repo.TodoRepo,
+) *feedService {
+	return &feedService{
+		BaseService:      base,
+		listService:      listService,
+		householdService: householdService,
+		feedTokenRepo:    feedTokenRepo,
+		todoRepo:         todoRepo,
+	}
+}
+
+// check FeedService interface implementation on compile time.
+var _ FeedService = (*feedService)(nil)
+
+func (s *feedService) Feed(ctx context.Context, token *domain.FeedToken, selfURL string) ([]byte, error) {
+	// the token only reads the list while its creator still has access to it
+	shared, err := s.listService.Access(ctx, token.UserID, token.ListUUID)
+	if err != nil {
+		if errors.Is(err, domain.ErrListNotFound) {
+			return nil, domain.ErrInvalidFeedToken
+		}
+		return nil, err
+	}
+
+	// todos belong to the owner of their list, archived ones were still completed recently
+	todos, _, err := s.todoRepo.All(ctx, shared.List.UserID, &domain.TodoFilter{
+		ListID:   shared.List.ID,
+		Archived: domain.ArchiveInclude,
+	}, nil)
+	if err != nil {
+		log.Err(err).Msg("error retrieving todos for feed")
+		return nil, err
+	}
+
+	now := time.Now()
+	feed, err := export.RenderAtom(&domain.ListFeed{
+		List:    shared.List,
+		Entries: feedEntries(todos, now.Add(-domain.FeedWindow)),
+	}, selfURL, now)
+	if err != nil {
+		log.Err(err).Msg("error rendering feed")
+		return nil, err
+	}
+
+	return feed, nil
+}
+
+// feedEntries returns the additions and completions since since, newest first.
+func feedEntries(todos []*domain.Todo, since time.Time) []*domain.FeedEntry {
+	entries := make([]*domain.FeedEntry, 0, len(todos))
+	for _, todo := range todos {
+		if todo.CreatedAt.After(since) {
+			entries = append(entries, &domain.FeedEntry{Kind: domain.FeedEntryAdded, Todo: todo, At: todo.CreatedAt})
+		}
+		if todo.Completed() && todo.CompletedAt.After(since) {
+			entries = append(entries, &domain.FeedEntry{Kind: domain.FeedEntryCompleted, Todo: todo, At: todo.CompletedAt})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].At.After(entries[j].At)
+	})
+	if len(entries) > domain.FeedMaxEntries {
+		entries = entries[:domain.FeedMaxEntries]
+	}
+
+	return entries
+}
+
+func (s *feedService) CreateToken(ctx context.Context, userID uint, listUUID string, name string) (*domain.FeedToken, error) {
+	// anyone with the feed URL can read the list
+	if err := s.householdService.Permit(ctx, userID, domain.ParentalActionShare); err != nil {
+		return nil, err
+	}
+
+	shared, err := s.listService.Access(ctx, userID, listUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := generateRegionalToken(ctx, domain.FeedTokenPrefix)
+	if err != nil {
+		log.Err(err).Msg("error generating feed token")
+		return nil, err
+	}
+
+	token, err := s.feedTokenRepo.Create(ctx, &domain.FeedToken{
+		UUID:   s.GenerateUUIDHash("feed"),
+		UserID: userID,
+		ListID: shared.List.ID,
+		Name:   name,
+	}, hashToken(raw))
+	if err != nil {
+		log.Err(err).Msg("error creating feed token")
+		return nil, fmt.Errorf("error creating feed token: %w", err)
+	}
+
+	token.Token = raw
+	return token, nil
+}
+
+func (s *feedService) RevokeToken(ctx context.Context, userID uint, uuid string) error {
+	if err := s.feedTokenRepo.Delete(ctx, userID, uuid); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrFeedTokenNotFound
+		}
+		log.Err(err).Msg("error revoking feed token")
+		return fmt.Errorf("error revoking feed token: %w", err)
+	}
+
+	return nil
+}
+
+func (s *feedService) Tokens(ctx context.Context, userID uint, listUUID string) ([]*domain.FeedToken, error) {
+	shared, err := s.listService.Access(ctx, userID, listUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.feedTokenRepo.All(ctx, userID, shared.List.ID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving feed tokens")
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+func (s *feedService) Authenticate(ctx context.Context, raw string) (*domain.FeedToken, error) {
+	if !strings.HasPrefix(raw, domain.FeedTokenPrefix) {
+		return nil, domain.ErrInvalidFeedToken
+	}
+
+	ctx, err := s.tokenContext(ctx, domain.FeedTokenPrefix, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := s.feedTokenRepo.ByHash(ctx, hashToken(raw))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrInvalidFeedToken
+		}
+		log.Err(err).Msg("error retrieving feed token")
+		return nil, err
+	}
+
+	if err = s.feedTokenRepo.Touch(ctx, token.ID, time.Now().UTC()); err != nil {
+		// usage tracking must not break the feed
+		log.Err(err).Str("feed_token", token.UUID).Msg("error updating feed token usage")
+	}
+
+	token.Region = region.FromContext(ctx)
+	return token, nil
+}
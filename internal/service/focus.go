@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/pagination"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+// FocusService tracks pomodoro style focus sessions on todos, a user can only focus on one
+// todo at a time.
+type FocusService interface {
+	Start(ctx context.Context, userID uint, start *domain.FocusSessionStart) (*domain.FocusSession, error)
+	Interrupt(ctx context.Context, userID uint, uuid string) (*domain.FocusSession, error)
+	Complete(ctx context.Context, userID uint, uuid string) (*domain.FocusSession, error)
+	Abandon(ctx context.Context, userID uint, uuid string) (*domain.FocusSession, error)
+
+	ByUUID(ctx context.Context, userID uint, uuid string) (*domain.FocusSession, error)
+	All(ctx context.Context, userID uint, filter *domain.FocusSessionFilter, page *pagination.Page) ([]*domain.FocusSession, *pagination.Cursor, error)
+	// Stats aggregates the time tracked in ended sessions, the period defaults to the last week.
+	Stats(ctx context.Context, userID uint, from time.Time, to time.Time) (*domain.FocusStats, error)
+}
+
+type focusService struct {
+	*BaseService
+
+	todoService TodoService
+
+	focusSessionRepo repo.FocusSessionRepo
+}
+
+func NewFocusService(base *BaseService, todoService TodoService, focusSessionRepo repo.FocusSessionRepo) *focusService {
+	return &focusService{
+		BaseService:      base,
+		todoService:      todoService,
+		focusSessionRepo: focusSessionRepo,
+	}
+}
+
+// check FocusService interface implementation on compile time.
+var _ FocusService = (*focusService)(nil)
+
+func (s *focusService) Start(ctx context.Context, userID uint, start *domain.FocusSessionStart) (*domain.FocusSession, error) {
+	if start == nil {
+		return nil, fmt.Errorf("no focus session details provided")
+	}
+
+	todo, err := s.todoService.ByUUID(ctx, userID, start.TodoUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	duration := start.Duration
+	if duration <= 0 {
+		duration = domain.DefaultFocusDuration
+	}
+
+	session, err := s.focusSessionRepo.Create(ctx, &domain.FocusSession{
+		UUID:    s.GenerateUUIDHash("focus"),
+		UserID:  userID,
+		TodoID:  todo.ID,
+		Planned: duration,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrFocusSessionActive
+		}
+		log.Err(err).Msg("error starting focus session")
+		return nil, fmt.Errorf("error starting focus session: %w", err)
+	}
+
+	return session, nil
+}
+
+func (s *focusService) Interrupt(ctx context.Context, userID uint, uuid string) (*domain.FocusSession, error) {
+	session, err := s.ByUUID(ctx, userID, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	interrupted, err := s.focusSessionRepo.Interrupt(ctx, session.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrFocusSessionEnded
+		}
+		log.Err(err).Msg("error interrupting focus session")
+		return nil, err
+	}
+
+	return interrupted, nil
+}
+
+func (s *focusService) Complete(ctx context.Context, userID uint, uuid string) (*domain.FocusSession, error) {
+	return s.end(ctx, userID, uuid, domain.FocusStatusCompleted)
+}
+
+func (s *focusService) Abandon(ctx context.Context, userID uint, uuid string) (*domain.FocusSession, error) {
+	return s.end(ctx, userID, uuid, domain.FocusStatusAbandoned)
+}
+
+func (s *focusService) ByUUID(ctx context.Context, userID uint, uuid string) (*domain.FocusSession, error) {
+	session, err := s.focusSessionRepo.ByUUID(ctx, userID, uuid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("focus session not found: %w", domain.ErrFocusSessionNotFound)
+		}
+		log.Err(err).Msg("error retrieving focus session")
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (s *focusService) All(
+	ctx context.Context,
+	userID uint,
+	filter *domain.FocusSessionFilter,
+	page *pagination.Page,
+) ([]*domain.FocusSession, *pagination.Cursor, error) {
+	if filter != nil && filter.TodoUUID != "" {
+		todo, err := s.todoService.ByUUID(ctx, userID, filter.TodoUUID)
+		if err != nil {
+			return nil, nil, err
+		}
+		filter.TodoID = todo.ID
+	}
+
+	sessions, next, err := s.focusSessionRepo.All(ctx, userID, filter, page)
+	if err != nil {
+		log.Err(err).Msg("error retrieving focus sessions")
+		return nil, nil, err
+	}
+
+	return sessions, next, nil
+}
+
+func (s *focusService) Stats(ctx context.Context, userID uint, from time.Time, to time.Time) (*domain.FocusStats, error) {
+	if to.IsZero() {
+		to = time.Now().UTC()
+	}
+	if from.IsZero() {
+		from = to.Add(-domain.DefaultFocusStatsPeriod)
+	}
+
+	stats, err := s.focusSessionRepo.Stats(ctx, userID, from, to)
+	if err != nil {
+		log.Err(err).Msg("error retrieving focus stats")
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+func (s *focusService) end(ctx context.Context, userID uint, uuid string, status domain.FocusStatus) (*domain.FocusSession, error) {
+	session, err := s.ByUUID(ctx, userID, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	ended, err := s.focusSessionRepo.End(ctx, session.ID, status, time.Now().UTC())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrFocusSessionEnded
+		}
+		log.Err(err).Msg("error ending focus session")
+		return nil, fmt.Errorf("error ending focus session: %w", err)
+	}
+
+	return ended, nil
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/pagination"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+// HabitService tracks habits, recurring goals like "exercise 3x a week" that are checked off
+// on any days of a week and counted in streaks.
+type HabitService interface {
+	Create(ctx context.Context, userID uint, habitCreate *domain.HabitCreate) (*domain.Habit, error)
+	Update(ctx context.Context, userID uint, uuid string, habitUpdate *domain.HabitUpdate) (*domain.Habit, error)
+	Delete(ctx context.Context, userID uint, uuid string) error
+	ByUUID(ctx context.Context, userID uint, uuid string) (*domain.Habit, error)
+	All(ctx context.Context, userID uint, page *pagination.Page) ([]*domain.Habit, *pagination.Cursor, error)
+
+	// Check records a day as done or skipped, checking a day again replaces its status.
+	Check(ctx context.Context, userID uint, uuid string, date time.Time, status domain.HabitEntryStatus) (*domain.HabitEntry, error)
+	Uncheck(ctx context.Context, userID uint, uuid string, date time.Time) error
+	// Stats covers the weeks from the week of from up to today, today is the current day of the
+	// user and defaults to today in UTC. Streaks always count the whole history.
+	Stats(ctx context.Context, userID uint, uuid string, from time.Time, today time.Time) (*domain.HabitStats, error)
+}
+
+type habitService struct {
+	*BaseService
+
+	habitRepo repo.HabitRepo
+}
+
+func NewHabitService(base *BaseService, habitRepo repo.HabitRepo) *habitService {
+	return &habitService{
+		BaseService: base,
+		habitRepo:   habitRepo,
+	}
+}
+
+// check HabitService interface implementation on compile time.
+var _ HabitService = (*habitService)(nil)
+
+func (s *habitService) Create(ctx context.Context, userID uint, habitCreate *domain.HabitCreate) (*domain.Habit, error) {
+	if habitCreate == nil {
+		return nil, fmt.Errorf("no habit details provided")
+	}
+
+	habit, err := s.habitRepo.Create(ctx, &domain.Habit{
+		UUID:          s.GenerateUUIDHash("habit"),
+		UserID:        userID,
+		Title:         habitCreate.Title,
+		TargetPerWeek: habitCreate.TargetPerWeek,
+	})
+	if err != nil {
+		log.Err(err).Msg("error creating habit")
+		return nil, fmt.Errorf("error creating habit: %w", err)
+	}
+
+	return habit, nil
+}
+
+func (s *habitService) Update(ctx context.Context, userID uint, uuid string, habitUpdate *domain.HabitUpdate) (*domain.Habit, error) {
+	habit, err := s.ByUUID(ctx, userID, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if habitUpdate.Title != nil {
+		habit.Title = *habitUpdate.Title
+	}
+	if habitUpdate.TargetPerWeek != nil {
+		habit.TargetPerWeek = *habitUpdate.TargetPerWeek
+	}
+
+	if err = s.habitRepo.Update(ctx, habit); err != nil {
+		log.Err(err).Msg("error updating habit")
+		return nil, fmt.Errorf("error updating habit: %w", err)
+	}
+
+	return habit, nil
+}
+
+func (s *habitService) Delete(ctx context.Context, userID uint, uuid string) error {
+	if _, err := s.ByUUID(ctx, userID, uuid); err != nil {
+		return err
+	}
+
+	if err := s.habitRepo.Delete(ctx, userID, uuid); err != nil {
+		log.Err(err).Msg("error deleting habit")
+		return fmt.Errorf("error deleting habit: %w", err)
+	}
+
+	return nil
+}
+
+func (s *habitService) ByUUID(ctx context.Context, userID uint, uuid string) (*domain.Habit, error) {
+	habit, err := s.habitRepo.ByUUID(ctx, userID, uuid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("habit not found: %w", domain.ErrHabitNotFound)
+		}
+		log.Err(err).Msg("error retrieving habit")
+		return nil, err
+	}
+
+	return habit, nil
+}
+
+func (s *habitService) All(ctx context.Context, userID uint, page *pagination.Page) ([]*domain.Habit, *pagination.Cursor, error) {
+	habits, next, err := s.habitRepo.All(ctx, userID, page)
+	if err != nil {
+		log.Err(err).Msg("error retrieving habits")
+		return nil, nil, err
+	}
+
+	return habits, next, nil
+}
+
+func (s *habitService) Check(
+	ctx context.Context,
+	userID uint,
+	uuid string,
+	date time.Time,
+	status domain.HabitEntryStatus,
+) (*domain.HabitEntry, error) {
+	// the day of the user may already be tomorrow in UTC
+	if date.After(domain.Day(time.Now()).AddDate(0, 0, 1)) {
+		return nil, domain.ErrFutureHabitEntry
+	}
+
+	habit, err := s.ByUUID(ctx, userID, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &domain.HabitEntry{
+		HabitID: habit.ID,
+		Date:    domain.Day(date),
+		Status:  status,
+	}
+	if err = s.habitRepo.SetEntry(ctx, entry); err != nil {
+		log.Err(err).Msg("error checking habit")
+		return nil, fmt.Errorf("error checking habit: %w", err)
+	}
+
+	return entry, nil
+}
+
+func (s *habitService) Uncheck(ctx context.Context, userID uint, uuid string, date time.Time) error {
+	habit, err := s.ByUUID(ctx, userID, uuid)
+	if err != nil {
+		return err
+	}
+
+	if err = s.habitRepo.DeleteEntry(ctx, habit.ID, domain.Day(date)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("habit entry not found: %w", domain.ErrHabitEntryNotFound)
+		}
+		log.Err(err).Msg("error unchecking habit")
+		return fmt.Errorf("error unchecking habit: %w", err)
+	}
+
+	return nil
+}
+
+func (s *habitService) Stats(ctx context.Context, userID uint, uuid string, from time.Time, today time.Time) (*domain.HabitStats, error) {
+	habit, err := s.ByUUID(ctx, userID, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if today.IsZero() {
+		today = time.Now()
+	}
+	today = domain.Day(today)
+	if from.IsZero() || from.After(today) {
+		from = today.AddDate(0, 0, -7*(domain.DefaultHabitStatsWeeks-1))
+	}
+
+	// streaks need the whole history
+	entries, err := s.habitRepo.Entries(ctx, habit.ID, time.Time{}, today)
+	if err != nil {
+		log.Err(err).Msg("error retrieving habit entries")
+		return nil, err
+	}
+
+	return habitStats(habit, entries, domain.WeekStart(from), today), nil
+}
+
+// habitOutcome is the outcome of a day of a daily habit or a week of any other habit.
+type habitOutcome int
+
+const (
+	habitMissed habitOutcome = iota
+	habitMet
+	// habitExcused periods neither count nor break a streak.
+	habitExcused
+)
+
+func habitStats(habit *domain.Habit, entries []*domain.HabitEntry, from time.Time, today time.Time) *domain.HabitStats {
+	statuses := make(map[time.Time]domain.HabitEntryStatus, len(entries))
+	first := domain.Day(habit.CreatedAt)
+	for _, entry := range entries {
+		statuses[entry.Date] = entry.Status
+		if entry.Date.Before(first) {
+			first = entry.Date
+		}
+	}
+
+	var outcomes []habitOutcome
+	stats := &domain.HabitStats{
+		From:  from,
+		To:    today,
+		Weeks: []*domain.HabitWeek{},
+	}
+
+	if habit.Daily() {
+		stats.StreakUnit = "day"
+		for day := first; !day.After(today); day = day.AddDate(0, 0, 1) {
+			switch statuses[day] {
+			case domain.HabitEntryDone:
+				outcomes = append(outcomes, habitMet)
+			case domain.HabitEntrySkipped:
+				outcomes = append(outcomes, habitExcused)
+			default:
+				outcomes = append(outcomes, habitMissed)
+			}
+		}
+	} else {
+		stats.StreakUnit = "week"
+	}
+
+	var met, target int
+	for start := domain.WeekStart(first); !start.After(today); start = start.AddDate(0, 0, 7) {
+		week := &domain.HabitWeek{Start: start}
+		for day := start; day.Before(start.AddDate(0, 0, 7)) && !day.After(today); day = day.AddDate(0, 0, 1) {
+			switch statuses[day] {
+			case domain.HabitEntryDone:
+				week.Done++
+			case domain.HabitEntrySkipped:
+				week.Skipped++
+			}
+		}
+		week.Target = min(habit.TargetPerWeek, 7-week.Skipped)
+		week.Met = week.Target > 0 && week.Done >= week.Target
+
+		if !habit.Daily() {
+			switch {
+			case week.Met:
+				outcomes = append(outcomes, habitMet)
+			case week.Target == 0:
+				outcomes = append(outcomes, habitExcused)
+			default:
+				outcomes = append(outcomes, habitMissed)
+			}
+		}
+
+		if start.Before(from) {
+			continue
+		}
+		stats.Weeks = append(stats.Weeks, week)
+		stats.Done += week.Done
+		stats.Skipped += week.Skipped
+		met += min(week.Done, week.Target)
+		target += week.Target
+	}
+
+	if target > 0 {
+		stats.CompletionRate = float64(met) / float64(target)
+	}
+
+	// the current day or week is still in progress, it can't break a streak yet
+	if last := len(outcomes) - 1; last >= 0 && outcomes[last] == habitMissed {
+		outcomes[last] = habitExcused
+	}
+
+	run := 0
+	for _, outcome := range outcomes {
+		switch outcome {
+		case habitMet:
+			run++
+			stats.LongestStreak = max(stats.LongestStreak, run)
+		case habitMissed:
+			run = 0
+		}
+	}
+	stats.CurrentStreak = run
+
+	return stats
+}
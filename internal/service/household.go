@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+// HouseholdService manages child accounts. Child accounts have no email, are created and
+// managed by a parent account and can only share or delete what their parental controls allow.
+type HouseholdService interface {
+	CreateChild(ctx context.Context, parentID uint, child *domain.ChildCreate) (*domain.User, error)
+	UpdateChild(ctx context.Context, parentID uint, uuid string, childUpdate *domain.ChildUpdate) (*domain.User, error)
+	DeleteChild(ctx context.Context, parentID uint, uuid string) error
+	Children(ctx context.Context, parentID uint) ([]*domain.User, error)
+
+	// RequireParent returns ErrChildAccount for child accounts, they can't manage a household.
+	RequireParent(ctx context.Context, userID uint) error
+	// Permit returns ErrParentalControl when the user is a child account that is not allowed the action.
+	Permit(ctx context.Context, userID uint, action domain.ParentalAction) error
+}
+
+type householdService struct {
+	*BaseService
+
+	userRepo       repo.UserRepo
+	userRegionRepo repo.UserRegionRepo
+
+	workspaceService WorkspaceService
+	securityEvents   *SecurityEvents
+}
+
+func NewHouseholdService(
+	base *BaseService,
+	userRepo repo.UserRepo,
+	userRegionRepo repo.UserRegionRepo,
+	workspaceService WorkspaceService,
+	securityEvents *SecurityEvents,
+) *householdService {
+	return &householdService{
+		BaseService:      base,
+		userRepo:         userRepo,
+		userRegionRepo:   userRegionRepo,
+		workspaceService: workspaceService,
+		securityEvents:   securityEvents,
+	}
+}
+
+// check HouseholdService interface implementation on compile time.
+var _ HouseholdService = (*householdService)(nil)
+
+func (s *householdService) CreateChild(ctx context.Context, parentID uint, child *domain.ChildCreate) (*domain.User, error) {
+	if child == nil {
+		return nil, fmt.Errorf("no child account details provided")
+	}
+
+	if err := s.RequireParent(ctx, parentID); err != nil {
+		return nil, err
+	}
+	if err := s.workspaceService.Allow(ctx, parentID, domain.QuotaMembers); err != nil {
+		return nil, err
+	}
+
+	_, err := s.userRepo.ByUsername(ctx, child.Username)
+	if err == nil {
+		return nil, domain.ErrUsernameTaken
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		log.Err(err).Msg("error retrieving user by username")
+		return nil, err
+	}
+
+	hashedPassword, err := s.hashPassword(ctx, child.Password)
+	if err != nil {
+		log.Err(err).Msg("error generating hash password")
+		return nil, err
+	}
+
+	// children live in the region of their parent, usernames are unique across regions
+	key := loginKey("username", child.Username)
+	if err = s.userRegionRepo.Create(ctx, key, region.FromContext(ctx)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrUsernameTaken
+		}
+		log.Err(err).Msg("error reserving user region")
+		return nil, err
+	}
+
+	user, err := s.userRepo.CreateChild(ctx, s.GenerateUUIDHash("user"), parentID, child, hashedPassword)
+	if err != nil {
+		if deleteErr := s.userRegionRepo.Delete(ctx, key); deleteErr != nil {
+			log.Err(deleteErr).Msg("error releasing user region")
+		}
+		log.Err(err).Msg("error creating child account")
+		return nil, fmt.Errorf("error creating child account: %w", err)
+	}
+
+	return user, nil
+}
+
+func (s *householdService) UpdateChild(ctx context.Context, parentID uint, uuid string, childUpdate *domain.ChildUpdate) (*domain.User, error) {
+	if childUpdate == nil || (childUpdate.Password == nil && childUpdate.Controls == nil) {
+		return nil, domain.ErrInvalidChildUpdate
+	}
+
+	child, err := s.child(ctx, parentID, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if childUpdate.Controls != nil {
+		if err = s.userRepo.UpdateControls(ctx, child.ID, *childUpdate.Controls); err != nil {
+			log.Err(err).Msg("error updating parental controls")
+			return nil, fmt.Errorf("error updating parental controls: %w", err)
+		}
+		child.Controls = *childUpdate.Controls
+		s.securityEvents.Publish(ctx, domain.EventSecurityPermissionChanged, child, map[string]string{
+			"change":         "parental_controls",
+			"allow_sharing":  strconv.FormatBool(child.Controls.AllowSharing),
+			"allow_deletion": strconv.FormatBool(child.Controls.AllowDeletion),
+		})
+	}
+
+	if childUpdate.Password != nil {
+		hashedPassword, err := s.hashPassword(ctx, *childUpdate.Password)
+		if err != nil {
+			log.Err(err).Msg("error generating hash password")
+			return nil, err
+		}
+
+		if err = s.userRepo.UpdatePassword(ctx, child.ID, hashedPassword); err != nil {
+			log.Err(err).Msg("error updating child account password")
+			return nil, fmt.Errorf("error updating child account password: %w", err)
+		}
+		s.securityEvents.Publish(ctx, domain.EventSecurityPermissionChanged, child, map[string]string{"change": "password_reset"})
+	}
+
+	return child, nil
+}
+
+func (s *householdService) DeleteChild(ctx context.Context, parentID uint, uuid string) error {
+	child, err := s.child(ctx, parentID, uuid)
+	if err != nil {
+		return err
+	}
+
+	if err = s.userRepo.Delete(ctx, child.ID); err != nil {
+		log.Err(err).Msg("error deleting child account")
+		return fmt.Errorf("error deleting child account: %w", err)
+	}
+
+	return nil
+}
+
+func (s *householdService) Children(ctx context.Context, parentID uint) ([]*domain.User, error) {
+	if err := s.RequireParent(ctx, parentID); err != nil {
+		return nil, err
+	}
+
+	children, err := s.userRepo.Children(ctx, parentID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving child accounts")
+		return nil, err
+	}
+
+	return children, nil
+}
+
+func (s *householdService) Permit(ctx context.Context, userID uint, action domain.ParentalAction) error {
+	user, err := s.user(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.IsChild() && !user.Controls.Allows(action) {
+		return fmt.Errorf("%s: %w", action, domain.ErrParentalControl)
+	}
+
+	return nil
+}
+
+func (s *householdService) RequireParent(ctx context.Context, userID uint) error {
+	user, err := s.user(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.IsChild() {
+		return domain.ErrChildAccount
+	}
+
+	return nil
+}
+
+func (s *householdService) child(ctx context.Context, parentID uint, uuid string) (*domain.User, error) {
+	child, err := s.userRepo.ByUUID(ctx, uuid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("child account not found: %w", domain.ErrChildNotFound)
+		}
+		log.Err(err).Msg("error retrieving child account")
+		return nil, err
+	}
+
+	// only the parent can see its children
+	if child.ParentID != parentID {
+		return nil, fmt.Errorf("child account not found: %w", domain.ErrChildNotFound)
+	}
+
+	return child, nil
+}
+
+func (s *householdService) user(ctx context.Context, userID uint) (*domain.User, error) {
+	user, err := s.userRepo.ByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("user not found: %w", domain.ErrUserNotFound)
+		}
+		log.Err(err).Msg("error retrieving user")
+		return nil, err
+	}
+
+	return user, nil
+}
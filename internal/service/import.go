@@ -0,0 +1,355 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/importer"
+	"github.com/meowmix1337/the_recipe_book/internal/metrics"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+	"github.com/meowmix1337/the_recipe_book/internal/transfer"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ImportService imports the backups of other todo apps and the export archives of this one in
+// the background, the archives are uploaded or downloaded from another instance.
+type ImportService interface {
+	// Start checks the backup and queues a job importing it.
+	Start(ctx context.Context, userID uint, provider domain.ImportProvider, payload []byte) (*domain.ImportJob, error)
+	// Transfer queues a job downloading the export archive of the account from another instance
+	// and importing it, the archive is verified against the digest the source sent with it.
+	Transfer(ctx context.Context, userID uint, transfer *domain.AccountTransfer) (*domain.ImportJob, error)
+	Job(ctx context.Context, userID uint, uuid string) (*domain.ImportJob, error)
+	Jobs(ctx context.Context, userID uint) ([]*domain.ImportJob, error)
+	// RunDue runs the queued jobs, it is run by a background worker.
+	RunDue(ctx context.Context) error
+}
+
+const (
+	// importBatchSize is how many jobs a run of the import worker picks up, they run one after
+	// the other.
+	importBatchSize = 5
+	// importBudget is how long a run keeps claiming jobs, whatever is left waits for the next
+	// run instead of holding up the worker's heartbeat.
+	importBudget = 30 * time.Second
+	// importHistory is how many of their past jobs users see.
+	importHistory = 20
+)
+
+type importService struct {
+	*BaseService
+
+	listService       ListService
+	todoService       TodoService
+	attachmentService AttachmentService
+
+	importJobRepo  repo.ImportJobRepo
+	transferClient transfer.Client
+}
+
+func NewImportService(
+	base *BaseService,
+	listService ListService,
+	todoService TodoService,
+	attachmentService AttachmentService,
+	importJobRepo repo.ImportJobRepo,
+	transferClient transfer.Client,
+) *importService {
+	return &importService{
+		BaseService:       base,
+		listService:       listService,
+		todoService:       todoService,
+		attachmentService: attachmentService,
+		importJobRepo:     importJobRepo,
+		transferClient:    transferClient,
+	}
+}
+
+// check ImportService interface implementation on compile time.
+var _ ImportService = (*importService)(nil)
+
+func (s *importService) Start(
+	ctx context.Context, userID uint, provider domain.ImportProvider, payload []byte,
+) (*domain.ImportJob, error) {
+	adapter, err := importer.New(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	// a backup that can't be read is refused right away instead of failing in the background
+	if _, err = adapter.Parse(bytes.NewReader(payload)); err != nil {
+		return nil, err
+	}
+
+	job, err := s.importJobRepo.Create(ctx, &domain.ImportJob{
+		UUID:     s.GenerateUUIDHash("import"),
+		UserID:   userID,
+		Provider: provider,
+	}, payload)
+	if err != nil {
+		log.Err(err).Msg("error creating import job")
+		return nil, fmt.Errorf("error creating import job: %w", err)
+	}
+
+	return job, nil
+}
+
+func (s *importService) Transfer(ctx context.Context, userID uint, transfer *domain.AccountTransfer) (*domain.ImportJob, error) {
+	sourceURL, err := parseTransferSource(transfer.SourceURL)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := s.importJobRepo.CreateTransfer(ctx, &domain.ImportJob{
+		UUID:      s.GenerateUUIDHash("import"),
+		UserID:    userID,
+		Provider:  domain.ImportArchive,
+		SourceURL: sourceURL,
+	}, transfer.Token)
+	if err != nil {
+		log.Err(err).Msg("error creating transfer job")
+		return nil, fmt.Errorf("error creating transfer job: %w", err)
+	}
+
+	return job, nil
+}
+
+// parseTransferSource returns the base URL of the source instance. It must be https, the access
+// token is sent to it.
+func parseTransferSource(source string) (string, error) {
+	parsed, err := url.Parse(source)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" || parsed.User != nil ||
+		parsed.RawQuery != "" || parsed.Fragment != "" {
+		return "", fmt.Errorf("%q: %w", source, domain.ErrInvalidTransferSource)
+	}
+
+	return strings.TrimSuffix(parsed.String(), "/"), nil
+}
+
+func (s *importService) Job(ctx context.Context, userID uint, uuid string) (*domain.ImportJob, error) {
+	job, err := s.importJobRepo.ByUUID(ctx, userID, uuid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("import job not found: %w", domain.ErrImportJobNotFound)
+		}
+		log.Err(err).Msg("error retrieving import job")
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func (s *importService) Jobs(ctx context.Context, userID uint) ([]*domain.ImportJob, error) {
+	jobs, err := s.importJobRepo.Recent(ctx, userID, importHistory)
+	if err != nil {
+		log.Err(err).Msg("error retrieving import jobs")
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+func (s *importService) RunDue(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	jobs, err := s.importJobRepo.Unfinished(ctx, now.Add(-domain.ImportJobTimeout), importBatchSize)
+	if err != nil {
+		return fmt.Errorf("error retrieving unfinished import jobs: %w", err)
+	}
+	metrics.WorkerQueueDepth.WithLabelValues("imports", metrics.RegionLabel(region.FromContext(ctx))).Set(float64(len(jobs)))
+
+	for _, job := range jobs {
+		if time.Since(now) > importBudget {
+			break
+		}
+		s.run(ctx, job, now)
+	}
+
+	return nil
+}
+
+func (s *importService) run(ctx context.Context, job *domain.ImportJob, now time.Time) {
+	// claim the job first so multiple instances never import the same backup
+	interrupted := job.Status == domain.ImportRunning
+	if err := s.importJobRepo.Claim(ctx, job, now); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Err(err).Str("import", job.UUID).Msg("error claiming import job")
+		}
+		return
+	}
+
+	// running an interrupted job again would create its todos twice
+	err := errors.New("import was interrupted, the lists and todos it created so far were kept")
+	if !interrupted {
+		err = s.importBackup(ctx, job)
+	}
+
+	job.Status = domain.ImportCompleted
+	job.FinishedAt = time.Now().UTC()
+	if err != nil {
+		job.Status = domain.ImportFailed
+		job.Error = err.Error()
+		log.Warn().Err(err).Str("import", job.UUID).Msg("import failed")
+	}
+	if err = s.importJobRepo.Finish(ctx, job); err != nil {
+		log.Err(err).Str("import", job.UUID).Msg("error storing import outcome")
+	}
+}
+
+// importBackup creates the lists and todos of the backup, the returned error is shown to the user.
+func (s *importService) importBackup(ctx context.Context, job *domain.ImportJob) error {
+	ctx, cancel := context.WithTimeout(ctx, domain.ImportJobTimeout)
+	defer cancel()
+
+	payload, err := s.payload(ctx, job)
+	if err != nil {
+		return err
+	}
+
+	adapter, err := importer.New(job.Provider)
+	if err != nil {
+		return err
+	}
+	backup, err := adapter.Parse(bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	for _, backupList := range backup.Lists {
+		list, err := s.listService.Create(ctx, job.UserID, backupList.Name)
+		if err != nil {
+			if errors.Is(err, domain.ErrQuotaExceeded) {
+				return fmt.Errorf("list %q: %w", backupList.Name, err)
+			}
+			return fmt.Errorf("list %q could not be created", backupList.Name)
+		}
+		job.ListsCreated++
+
+		if backupList.CompletedRetention > 0 {
+			if _, err = s.listService.SetRetention(ctx, job.UserID, list.UUID, backupList.CompletedRetention); err != nil {
+				log.Err(err).Str("import", job.UUID).Msg("error restoring list retention")
+				return fmt.Errorf("list %q could not be created", backupList.Name)
+			}
+		}
+
+		if err = s.importTodos(ctx, job, list.UUID, backupList.Todos); err != nil {
+			return err
+		}
+	}
+
+	return s.importTodos(ctx, job, "", backup.Unlisted)
+}
+
+// payload returns the backup of the job, transfers download it from their source first.
+func (s *importService) payload(ctx context.Context, job *domain.ImportJob) ([]byte, error) {
+	if job.SourceURL == "" {
+		payload, err := s.importJobRepo.Payload(ctx, job.ID)
+		if err != nil {
+			log.Err(err).Str("import", job.UUID).Msg("error retrieving import backup")
+			return nil, errors.New("backup could not be read")
+		}
+		return payload, nil
+	}
+
+	token, err := s.importJobRepo.SourceToken(ctx, job.ID)
+	if err != nil {
+		log.Err(err).Str("import", job.UUID).Msg("error retrieving transfer token")
+		return nil, errors.New("transfer token could not be read")
+	}
+
+	archive, err := s.transferClient.Download(ctx, job.SourceURL, token, domain.MaxArchiveImportSize, func(received int64) {
+		job.BytesReceived = received
+		if err := s.importJobRepo.Received(ctx, job); err != nil {
+			log.Err(err).Str("import", job.UUID).Msg("error storing transfer progress")
+		}
+	})
+	if err != nil {
+		if errors.Is(err, transfer.ErrTooLarge) {
+			return nil, domain.ErrImportTooLarge
+		}
+		return nil, fmt.Errorf("archive could not be downloaded from %s: %w", job.SourceURL, err)
+	}
+
+	job.ArchiveSHA256 = archive.SHA256
+	if err = s.importJobRepo.Received(ctx, job); err != nil {
+		log.Err(err).Str("import", job.UUID).Msg("error storing transfer progress")
+	}
+
+	return archive.Payload, nil
+}
+
+// importTodos creates the todos in the list, todos without a list when listUUID is empty.
+func (s *importService) importTodos(ctx context.Context, job *domain.ImportJob, listUUID string, backupTodos []*domain.BackupTodo) error {
+	for _, backupTodo := range backupTodos {
+		if err := s.importTodo(ctx, job.UserID, listUUID, backupTodo); err != nil {
+			log.Err(err).Str("import", job.UUID).Msg("error importing todo")
+			return fmt.Errorf("todo %q could not be created", backupTodo.Title)
+		}
+		job.TodosCreated++
+	}
+
+	if err := s.importJobRepo.Progress(ctx, job); err != nil {
+		log.Err(err).Str("import", job.UUID).Msg("error storing import progress")
+	}
+
+	return nil
+}
+
+func (s *importService) importTodo(ctx context.Context, userID uint, listUUID string, backupTodo *domain.BackupTodo) error {
+	todo, err := s.todoService.Create(ctx, userID, &domain.TodoCreate{
+		ListUUID:    listUUID,
+		Title:       backupTodo.Title,
+		Description: backupTodo.Description,
+		Priority:    backupTodo.Priority,
+		DueDate:     backupTodo.DueDate,
+		Estimate:    backupTodo.Estimate,
+		Tags:        backupTodo.Tags,
+		CreatedAt:   backupTodo.CreatedAt,
+		CompletedAt: backupTodo.CompletedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	// backups that don't tell when a todo was completed complete it now
+	if backupTodo.Completed && backupTodo.CompletedAt.IsZero() {
+		completed := true
+		if _, err = s.todoService.Update(ctx, userID, todo.UUID, &domain.TodoUpdate{Completed: &completed}); err != nil {
+			return err
+		}
+	}
+
+	for _, backupAttachment := range backupTodo.Attachments {
+		if err = s.importAttachment(ctx, userID, todo.UUID, backupAttachment); err != nil {
+			return fmt.Errorf("attachment %q: %w", backupAttachment.Filename, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *importService) importAttachment(ctx context.Context, userID uint, todoUUID string, backupAttachment *domain.BackupAttachment) error {
+	body, err := backupAttachment.Open()
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	_, err = s.attachmentService.Upload(ctx, userID, todoUUID, &domain.AttachmentUpload{
+		Filename:    backupAttachment.Filename,
+		ContentType: backupAttachment.ContentType,
+		Size:        backupAttachment.Size,
+		Body:        body,
+	})
+
+	return err
+}
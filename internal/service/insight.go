@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/metrics"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+// dueInsightBatchSize is how many users a single insight run checks.
+const dueInsightBatchSize = 100
+
+// InsightService watches the todos of users who opted in for unusual drops in productivity,
+// like a spike of overdue todos or a week without completing anything, and emails them a
+// gentle nudge.
+type InsightService interface {
+	// Settings returns the insight settings of the user, the defaults when they never saved any.
+	Settings(ctx context.Context, userID uint) (*domain.InsightSettings, error)
+	UpdateSettings(ctx context.Context, userID uint, update *domain.InsightSettingsUpdate) (*domain.InsightSettings, error)
+
+	// CheckDue checks the users whose check is due and queues their nudges, it is run by a
+	// background worker.
+	CheckDue(ctx context.Context) error
+}
+
+type insightService struct {
+	*BaseService
+
+	notificationService NotificationService
+
+	insightRepo repo.InsightRepo
+	userRepo    repo.UserRepo
+}
+
+func NewInsightService(
+	base *BaseService,
+	notificationService NotificationService,
+	insightRepo repo.InsightRepo,
+	userRepo repo.UserRepo,
+) *insightService {
+	return &insightService{
+		BaseService:         base,
+		notificationService: notificationService,
+		insightRepo:         insightRepo,
+		userRepo:            userRepo,
+	}
+}
+
+// check InsightService interface implementation on compile time.
+var _ InsightService = (*insightService)(nil)
+
+func (s *insightService) Settings(ctx context.Context, userID uint) (*domain.InsightSettings, error) {
+	settings, err := s.insightRepo.Settings(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.DefaultInsightSettings(userID), nil
+		}
+		log.Err(err).Msg("error retrieving insight settings")
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+func (s *insightService) UpdateSettings(
+	ctx context.Context,
+	userID uint,
+	update *domain.InsightSettingsUpdate,
+) (*domain.InsightSettings, error) {
+	if update == nil {
+		return nil, fmt.Errorf("no insight settings provided")
+	}
+
+	settings, err := s.insightRepo.Upsert(ctx, &domain.InsightSettings{
+		UserID:       userID,
+		Enabled:      update.Enabled,
+		OverdueSpike: update.OverdueSpike,
+		IdleDays:     update.IdleDays,
+	})
+	if err != nil {
+		log.Err(err).Msg("error saving insight settings")
+		return nil, fmt.Errorf("error saving insight settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+func (s *insightService) CheckDue(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	// claiming moves the next check a day ahead, so a failed check waits for the next day rather
+	// than nudging twice
+	due, err := s.insightRepo.Due(ctx, now, now.Add(domain.InsightCheckInterval), dueInsightBatchSize)
+	if err != nil {
+		return fmt.Errorf("error retrieving due insight checks: %w", err)
+	}
+	metrics.WorkerQueueDepth.WithLabelValues("insights", metrics.RegionLabel(region.FromContext(ctx))).Set(float64(len(due)))
+
+	for _, settings := range due {
+		if err = s.check(ctx, settings, now); err != nil {
+			log.Err(err).Uint("user_id", settings.UserID).Msg("error checking insights")
+		}
+	}
+
+	return nil
+}
+
+func (s *insightService) check(ctx context.Context, settings *domain.InsightSettings, now time.Time) error {
+	stats, err := s.insightRepo.Stats(ctx, settings.UserID, now, settings.IdlePeriod())
+	if err != nil {
+		return fmt.Errorf("error retrieving productivity stats: %w", err)
+	}
+
+	nudges := settings.Detect(stats, now)
+	if len(nudges) == 0 {
+		return nil
+	}
+
+	user, err := s.userRepo.ByID(ctx, settings.UserID)
+	if err != nil {
+		return fmt.Errorf("error retrieving user: %w", err)
+	}
+
+	for _, nudge := range nudges {
+		if err = s.notificationService.SendNudge(ctx, user, nudge); err != nil {
+			return err
+		}
+		if err = s.insightRepo.Nudged(ctx, settings.UserID, nudge.Kind, now); err != nil {
+			return fmt.Errorf("error recording nudge: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"maps"
+	"os"
+	"runtime/debug"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// instanceTimeout is how long an instance counts as live after its last heartbeat, a few
+	// heartbeats so a slow database doesn't reshuffle the shards.
+	instanceTimeout = time.Minute
+	// instanceRetention is how long the rows of stopped instances are kept for debugging.
+	instanceRetention = 24 * time.Hour
+)
+
+// InstanceService registers the instance in the instance registry and splits the background
+// work between the live instances. Each live instance owns one shard, the shards follow the
+// order of the instance IDs so every instance computes the same assignment.
+type InstanceService interface {
+	// Heartbeat registers the instance or refreshes its registration and recomputes its shard.
+	Heartbeat(ctx context.Context) error
+	// Deregister removes the instance so the others take over its shard right away.
+	Deregister(ctx context.Context) error
+	// Live returns the instances that sent a heartbeat recently.
+	Live(ctx context.Context) ([]*domain.Instance, error)
+	// ID returns the ID of this instance.
+	ID() string
+	// Owns reports whether key belongs to the shard of this instance, an instance that doesn't
+	// know the others yet owns every key.
+	Owns(key string) bool
+	// Sharded wraps a worker run per region so it only runs for the regions this instance owns.
+	Sharded(fn func(ctx context.Context) error) func(ctx context.Context) error
+}
+
+type instanceService struct {
+	*BaseService
+
+	instanceRepo repo.InstanceRepo
+
+	mu       sync.Mutex
+	instance domain.Instance
+}
+
+func NewInstanceService(base *BaseService, instanceRepo repo.InstanceRepo) *instanceService {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	return &instanceService{
+		BaseService:  base,
+		instanceRepo: instanceRepo,
+		instance: domain.Instance{
+			ID:        base.GenerateUUIDHash("instance"),
+			Hostname:  hostname,
+			Version:   buildVersion(),
+			Regions:   slices.Sorted(maps.Keys(base.Config.GetDBRegions())),
+			StartedAt: time.Now().UTC(),
+		},
+	}
+}
+
+// check InstanceService interface implementation on compile time.
+var _ InstanceService = (*instanceService)(nil)
+
+func (s *instanceService) Heartbeat(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	s.mu.Lock()
+	s.instance.HeartbeatAt = now
+	instance := s.instance
+	s.mu.Unlock()
+
+	if err := s.instanceRepo.Heartbeat(ctx, &instance); err != nil {
+		log.Err(err).Msg("error sending instance heartbeat")
+		return err
+	}
+
+	live, err := s.instanceRepo.Live(ctx, now.Add(-instanceTimeout))
+	if err != nil {
+		log.Err(err).Msg("error retrieving live instances")
+		return err
+	}
+
+	shard := slices.IndexFunc(live, func(other *domain.Instance) bool { return other.ID == instance.ID })
+	if shard < 0 {
+		// the heartbeat was written, a clock skewed database is the only way to miss it
+		shard, live = 0, nil
+	}
+	if shard != instance.Shard || len(live) != instance.Shards {
+		log.Info().Str("instance", instance.ID).Int("shard", shard).Int("shards", len(live)).Msg("instance shard changed")
+
+		instance.Shard, instance.Shards = shard, len(live)
+		if err = s.instanceRepo.Heartbeat(ctx, &instance); err != nil {
+			log.Err(err).Msg("error updating instance shard")
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	s.instance.Shard, s.instance.Shards = instance.Shard, instance.Shards
+	s.mu.Unlock()
+
+	if err = s.instanceRepo.Prune(ctx, now.Add(-instanceRetention)); err != nil {
+		log.Err(err).Msg("error pruning stopped instances")
+		return err
+	}
+
+	return nil
+}
+
+func (s *instanceService) Deregister(ctx context.Context) error {
+	if err := s.instanceRepo.Deregister(ctx, s.ID()); err != nil {
+		log.Err(err).Msg("error deregistering instance")
+		return err
+	}
+
+	return nil
+}
+
+func (s *instanceService) Live(ctx context.Context) ([]*domain.Instance, error) {
+	live, err := s.instanceRepo.Live(ctx, time.Now().UTC().Add(-instanceTimeout))
+	if err != nil {
+		log.Err(err).Msg("error retrieving live instances")
+		return nil, err
+	}
+
+	return live, nil
+}
+
+func (s *instanceService) ID() string {
+	return s.instance.ID
+}
+
+func (s *instanceService) Owns(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.instance.Shards == 0 {
+		return true
+	}
+
+	return domain.ShardOf(key, s.instance.Shards) == s.instance.Shard
+}
+
+func (s *instanceService) Sharded(fn func(ctx context.Context) error) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		if !s.Owns(region.FromContext(ctx)) {
+			return nil
+		}
+
+		return fn(ctx)
+	}
+}
+
+// buildVersion returns the VCS revision the binary was built from, or the module version when
+// it was built without VCS information.
+func buildVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+
+	version := info.Main.Version
+	var dirty bool
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			version = setting.Value
+		case "vcs.modified":
+			dirty = setting.Value == "true"
+		}
+	}
+	if dirty {
+		version += "-dirty"
+	}
+
+	return version
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/linkcheck"
+	"github.com/meowmix1337/the_recipe_book/internal/mail"
+	"github.com/meowmix1337/the_recipe_book/internal/metrics"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+// LinkService keeps track of the links in the descriptions of todos and periodically checks
+// whether they still work. Dead links are flagged on their todo and emailed to its owner,
+// users can turn off the checks or the emails.
+type LinkService interface {
+	TodoEventHandler
+
+	Settings(ctx context.Context, userID uint) (*domain.LinkSettings, error)
+	UpdateSettings(ctx context.Context, userID uint, update *domain.LinkSettingsUpdate) (*domain.LinkSettings, error)
+
+	// CheckDue checks the links that are due, it is run by a background worker.
+	CheckDue(ctx context.Context) error
+}
+
+const (
+	dueLinkBatchSize = 50
+	// linkCheckBudget is how long a run keeps starting checks, the rest of the batch is left
+	// to the next run.
+	linkCheckBudget = 30 * time.Second
+)
+
+type linkService struct {
+	*BaseService
+
+	linkRepo repo.LinkRepo
+	userRepo repo.UserRepo
+
+	checker linkcheck.Checker
+	sender  mail.Sender
+}
+
+func NewLinkService(
+	base *BaseService,
+	linkRepo repo.LinkRepo,
+	userRepo repo.UserRepo,
+	checker linkcheck.Checker,
+	sender mail.Sender,
+) *linkService {
+	return &linkService{
+		BaseService: base,
+		linkRepo:    linkRepo,
+		userRepo:    userRepo,
+		checker:     checker,
+		sender:      sender,
+	}
+}
+
+// check LinkService interface implementation on compile time.
+var _ LinkService = (*linkService)(nil)
+
+// HandleTodoEvent picks up the links of created and edited todos, deleted and completed todos
+// keep their links but aren't checked anymore.
+func (s *linkService) HandleTodoEvent(ctx context.Context, event *domain.TodoEvent) {
+	if event.Type != domain.EventTodoCreated && event.Type != domain.EventTodoUpdated {
+		return
+	}
+
+	todo := event.Todo
+	if err := s.linkRepo.Sync(ctx, todo.ID, todo.UserID, domain.ExtractLinks(todo.Description)); err != nil {
+		log.Err(err).Str("todo", todo.UUID).Msg("error storing todo links")
+	}
+}
+
+func (s *linkService) Settings(ctx context.Context, userID uint) (*domain.LinkSettings, error) {
+	settings, err := s.linkRepo.Settings(ctx, userID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving link settings")
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+func (s *linkService) UpdateSettings(ctx context.Context, userID uint, update *domain.LinkSettingsUpdate) (*domain.LinkSettings, error) {
+	settings, err := s.Settings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Check != nil {
+		settings.Check = *update.Check
+	}
+	if update.Notify != nil {
+		settings.Notify = *update.Notify
+	}
+
+	if err = s.linkRepo.UpdateSettings(ctx, userID, settings); err != nil {
+		log.Err(err).Msg("error updating link settings")
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+func (s *linkService) CheckDue(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	links, err := s.linkRepo.Due(ctx, now, dueLinkBatchSize)
+	if err != nil {
+		return fmt.Errorf("error retrieving due links: %w", err)
+	}
+	metrics.WorkerQueueDepth.WithLabelValues("link_checks", metrics.RegionLabel(region.FromContext(ctx))).Set(float64(len(links)))
+
+	for _, link := range links {
+		if time.Since(now) > linkCheckBudget || ctx.Err() != nil {
+			break
+		}
+		s.check(ctx, link, now)
+	}
+
+	return nil
+}
+
+func (s *linkService) check(ctx context.Context, link *domain.TodoLink, now time.Time) {
+	// claim the check first so multiple instances never check the same link at once, a check
+	// that never finishes is retried like a failed one
+	if err := s.linkRepo.Claim(ctx, link, now.Add(domain.LinkRetryInterval)); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Err(err).Str("todo", link.TodoUUID).Msg("error claiming link check")
+		}
+		return
+	}
+
+	statusCode, err := s.checker.Check(ctx, link.URL)
+	if ctx.Err() != nil {
+		// shutting down, the outcome says nothing about the link
+		return
+	}
+	if err != nil {
+		log.Debug().Err(err).Str("todo", link.TodoUUID).Msg("link check failed")
+	}
+
+	link.Record(time.Now().UTC(), statusCode, linkcheck.Broken(statusCode, err))
+	if err = s.linkRepo.SetResult(ctx, link); err != nil {
+		log.Err(err).Str("todo", link.TodoUUID).Msg("error storing link check")
+		return
+	}
+
+	if link.Dead() && link.NotifiedAt.IsZero() {
+		s.notify(ctx, link)
+	}
+}
+
+// notify emails the owner of the todo about the dead link once, users who turned off the
+// emails are notified if they turn them on while the link is still dead.
+func (s *linkService) notify(ctx context.Context, link *domain.TodoLink) {
+	settings, err := s.linkRepo.Settings(ctx, link.UserID)
+	if err != nil {
+		log.Err(err).Str("todo", link.TodoUUID).Msg("error retrieving link settings")
+		return
+	}
+	if !settings.Notify {
+		return
+	}
+
+	user, err := s.userRepo.ByID(ctx, link.UserID)
+	if err == nil && user.IsChild() {
+		// child accounts don't have an email address, their parent is notified
+		user, err = s.userRepo.ByID(ctx, user.ParentID)
+	}
+	if err != nil {
+		log.Err(err).Str("todo", link.TodoUUID).Msg("error retrieving owner of dead link")
+		return
+	}
+
+	if err = s.linkRepo.MarkNotified(ctx, link, time.Now().UTC()); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Err(err).Str("todo", link.TodoUUID).Msg("error marking dead link as notified")
+		}
+		return
+	}
+
+	err = s.sender.Send(ctx, &mail.Message{
+		To:      []string{user.Email},
+		Subject: fmt.Sprintf("A link in your todo %q is broken", link.TodoTitle),
+		Text: fmt.Sprintf("The link %s in your todo %q didn't work the last %d times we checked it.\n\n"+
+			"You can turn off these emails or the link checks in your settings.\n",
+			link.URL, link.TodoTitle, link.Failures),
+	})
+	if err != nil {
+		log.Err(err).Str("todo", link.TodoUUID).Msg("error sending dead link notification")
+	}
+}
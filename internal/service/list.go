@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/metrics"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/pagination"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// archiveBatchSize is how many todos a single archival update stamps.
+	archiveBatchSize = 500
+	// archiveBudget is how long a run keeps archiving batches, a backlog is worked off over
+	// several runs.
+	archiveBudget = time.Minute
+)
+
+type ListService interface {
+	Create(ctx context.Context, userID uint, name string) (*domain.List, error)
+	Rename(ctx context.Context, userID uint, uuid string, name string) (*domain.List, error)
+	// SetRetention sets how long completed todos of the list stay visible, zero keeps them.
+	SetRetention(ctx context.Context, userID uint, uuid string, retention time.Duration) (*domain.List, error)
+	Delete(ctx context.Context, userID uint, uuid string) error
+	// Merge folds a list of the user into another one of their lists in a single transaction,
+	// the source list is deleted afterwards. Members of the source lose access to its todos.
+	Merge(ctx context.Context, userID uint, uuid string, listMerge *domain.ListMerge) (*domain.ListMergeResult, error)
+
+	ByUUID(ctx context.Context, userID uint, uuid string) (*domain.List, error)
+	// Access returns a list the user owns or that is shared with them, together with their role.
+	Access(ctx context.Context, userID uint, uuid string) (*domain.SharedList, error)
+	All(ctx context.Context, userID uint, page *pagination.Page) ([]*domain.List, *pagination.Cursor, error)
+
+	// ArchiveDue archives the completed todos past the retention of their list, it is run by a
+	// background worker. Queries hide these todos already, the archival makes it permanent.
+	ArchiveDue(ctx context.Context) error
+}
+
+type listService struct {
+	*BaseService
+
+	householdService HouseholdService
+	workspaceService WorkspaceService
+
+	listRepo       repo.ListRepo
+	listMemberRepo repo.ListMemberRepo
+	todoRepo       repo.TodoRepo
+}
+
+func NewListService(
+	base *BaseService,
+	householdService HouseholdService,
+	workspaceService WorkspaceService,
+	listRepo repo.ListRepo,
+	listMemberRepo repo.ListMemberRepo,
+	todoRepo repo.TodoRepo,
+) *listService {
+	return &listService{
+		BaseService:      base,
+		householdService: householdService,
+		workspaceService: workspaceService,
+		listRepo:         listRepo,
+		listMemberRepo:   listMemberRepo,
+		todoRepo:         todoRepo,
+	}
+}
+
+// check ListService interface implementation on compile time.
+var _ ListService = (*listService)(nil)
+
+func (s *listService) Create(ctx context.Context, userID uint, name string) (*domain.List, error) {
+	if err := s.workspaceService.Allow(ctx, userID, domain.QuotaLists); err != nil {
+		return nil, err
+	}
+
+	list, err := s.listRepo.Create(ctx, s.GenerateUUIDHash("list"), userID, name)
+	if err != nil {
+		log.Err(err).Msg("error creating list")
+		return nil, fmt.Errorf("error creating list: %w", err)
+	}
+
+	return list, nil
+}
+
+func (s *listService) Rename(ctx context.Context, userID uint, uuid string, name string) (*domain.List, error) {
+	list, err := s.ByUUID(ctx, userID, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	list.Name = name
+	if err = s.listRepo.Update(ctx, list); err != nil {
+		log.Err(err).Msg("error updating list")
+		return nil, fmt.Errorf("error updating list: %w", err)
+	}
+
+	return list, nil
+}
+
+func (s *listService) SetRetention(ctx context.Context, userID uint, uuid string, retention time.Duration) (*domain.List, error) {
+	list, err := s.ByUUID(ctx, userID, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	list.CompletedRetention = retention
+	if err = s.listRepo.Update(ctx, list); err != nil {
+		log.Err(err).Msg("error updating list retention")
+		return nil, fmt.Errorf("error updating list: %w", err)
+	}
+
+	return list, nil
+}
+
+func (s *listService) Delete(ctx context.Context, userID uint, uuid string) error {
+	if err := s.householdService.Permit(ctx, userID, domain.ParentalActionDelete); err != nil {
+		return err
+	}
+
+	if _, err := s.ByUUID(ctx, userID, uuid); err != nil {
+		return err
+	}
+
+	if err := s.listRepo.Delete(ctx, userID, uuid); err != nil {
+		log.Err(err).Msg("error deleting list")
+		return fmt.Errorf("error deleting list: %w", err)
+	}
+
+	return nil
+}
+
+func (s *listService) Merge(
+	ctx context.Context, userID uint, uuid string, listMerge *domain.ListMerge,
+) (*domain.ListMergeResult, error) {
+	// the source list is gone afterwards, so merging is deleting as far as parental controls go
+	if err := s.householdService.Permit(ctx, userID, domain.ParentalActionDelete); err != nil {
+		return nil, err
+	}
+
+	target, err := s.ByUUID(ctx, userID, uuid)
+	if err != nil {
+		return nil, err
+	}
+	source, err := s.ByUUID(ctx, userID, listMerge.SourceUUID)
+	if err != nil {
+		return nil, err
+	}
+	if source.ID == target.ID {
+		return nil, domain.ErrListMergeSelf
+	}
+	// duplicates can't be told apart and the todos would need the key of the target
+	if source.Encrypted() || target.Encrypted() {
+		return nil, domain.ErrListEncrypted
+	}
+
+	sourceTodos, err := s.listTodos(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.ListMergeResult{Target: target, DryRun: listMerge.DryRun}
+	seen := make(map[string]bool)
+	if listMerge.SkipDuplicates {
+		targetTodos, err := s.listTodos(ctx, target)
+		if err != nil {
+			return nil, err
+		}
+		for _, todo := range targetTodos {
+			seen[domain.TodoDuplicateKey("", todo.Title, todo.DueDate)] = true
+		}
+	}
+
+	skipIDs := make([]uint, 0)
+	for _, todo := range sourceTodos {
+		key := domain.TodoDuplicateKey("", todo.Title, todo.DueDate)
+		if listMerge.SkipDuplicates && seen[key] {
+			result.Duplicates = append(result.Duplicates, todo)
+			skipIDs = append(skipIDs, todo.ID)
+			continue
+		}
+		seen[key] = true
+
+		todo.ListID = target.ID
+		todo.ListUUID = target.UUID
+		result.Moved = append(result.Moved, todo)
+	}
+
+	if listMerge.DryRun {
+		return result, nil
+	}
+
+	if err = s.listRepo.Merge(ctx, source.ID, target.ID, skipIDs); err != nil {
+		log.Err(err).Msg("error merging lists")
+		return nil, fmt.Errorf("error merging lists: %w", err)
+	}
+	for _, todo := range result.Moved {
+		todo.Version++
+	}
+
+	return result, nil
+}
+
+func (s *listService) listTodos(ctx context.Context, list *domain.List) ([]*domain.Todo, error) {
+	todos, _, err := s.todoRepo.All(ctx, list.UserID, &domain.TodoFilter{
+		ListID:   list.ID,
+		Sort:     []domain.TodoSortField{domain.TodoSortCreatedAt},
+		Order:    domain.SortOrderAsc,
+		Archived: domain.ArchiveInclude,
+	}, nil)
+	if err != nil {
+		log.Err(err).Msg("error retrieving list todos")
+		return nil, err
+	}
+
+	return todos, nil
+}
+
+func (s *listService) ByUUID(ctx context.Context, userID uint, uuid string) (*domain.List, error) {
+	list, err := s.listRepo.ByUUID(ctx, userID, uuid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Err(domain.ErrListNotFound).Msg("list not found")
+			return nil, fmt.Errorf("list not found: %w", domain.ErrListNotFound)
+		}
+		log.Err(err).Msg("error retrieving list")
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (s *listService) Access(ctx context.Context, userID uint, uuid string) (*domain.SharedList, error) {
+	list, err := s.listRepo.ByUUID(ctx, userID, uuid)
+	if err == nil {
+		return &domain.SharedList{List: list, Role: domain.ListRoleOwner}, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		log.Err(err).Msg("error retrieving list")
+		return nil, err
+	}
+
+	shared, err := s.listMemberRepo.Shared(ctx, userID, uuid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Err(domain.ErrListNotFound).Msg("list not found")
+			return nil, fmt.Errorf("list not found: %w", domain.ErrListNotFound)
+		}
+		log.Err(err).Msg("error retrieving shared list")
+		return nil, err
+	}
+
+	return shared, nil
+}
+
+func (s *listService) All(ctx context.Context, userID uint, page *pagination.Page) ([]*domain.List, *pagination.Cursor, error) {
+	lists, next, err := s.listRepo.All(ctx, userID, page)
+	if err != nil {
+		log.Err(err).Msg("error retrieving lists")
+		return nil, nil, err
+	}
+
+	return lists, next, nil
+}
+
+func (s *listService) ArchiveDue(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	var archived int
+	for time.Since(now) < archiveBudget {
+		count, err := s.todoRepo.Archive(ctx, now, archiveBatchSize)
+		if err != nil {
+			return fmt.Errorf("error archiving completed todos: %w", err)
+		}
+		archived += count
+		if count < archiveBatchSize {
+			break
+		}
+	}
+	metrics.WorkerQueueDepth.WithLabelValues("todo_archival", metrics.RegionLabel(region.FromContext(ctx))).Set(float64(archived))
+
+	if archived > 0 {
+		log.Info().Int("todos", archived).Msg("archived completed todos")
+	}
+
+	return nil
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/meowmix1337/the_recipe_book/internal/audit"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/pagination"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+// maxLoginUserAgentLength caps the user agent stored with a login.
+const maxLoginUserAgentLength = 255
+
+// LoginService keeps the recent logins and the known devices of users. A login from a device or
+// country the user didn't log in from before is alerted by email, in the audit log and as a
+// security event. The first login of a user has nothing to compare with and is never alerted.
+type LoginService interface {
+	// HandleSecurityEvent records logins, logins without a device fingerprint are skipped.
+	HandleSecurityEvent(ctx context.Context, event *domain.SecurityEvent)
+
+	// Logins returns the recent logins of a user, newest first.
+	Logins(ctx context.Context, userID uint, page *pagination.Page) ([]*domain.Login, *pagination.Cursor, error)
+	Devices(ctx context.Context, userID uint) ([]*domain.KnownDevice, error)
+	// ForgetDevice removes a known device, the next login from it is alerted again.
+	ForgetDevice(ctx context.Context, userID uint, uuid string) error
+
+	// PruneDue deletes the logins older than LoginRetention, it is run by a background worker.
+	PruneDue(ctx context.Context) error
+}
+
+type loginService struct {
+	*BaseService
+
+	notificationService NotificationService
+	auditService        AuditService
+	securityEvents      *SecurityEvents
+
+	loginRepo repo.LoginRepo
+}
+
+func NewLoginService(
+	base *BaseService,
+	notificationService NotificationService,
+	auditService AuditService,
+	securityEvents *SecurityEvents,
+	loginRepo repo.LoginRepo,
+) *loginService {
+	return &loginService{
+		BaseService:         base,
+		notificationService: notificationService,
+		auditService:        auditService,
+		securityEvents:      securityEvents,
+		loginRepo:           loginRepo,
+	}
+}
+
+// check LoginService interface implementation on compile time.
+var _ LoginService = (*loginService)(nil)
+
+// check SecurityEventHandler interface implementation on compile time.
+var _ SecurityEventHandler = (*loginService)(nil)
+
+func (s *loginService) HandleSecurityEvent(ctx context.Context, event *domain.SecurityEvent) {
+	if event.Type != domain.EventSecurityLogin || event.Details["device"] == "" {
+		return
+	}
+
+	login, err := s.record(ctx, event)
+	if err != nil {
+		log.Err(err).Uint("user_id", event.User.ID).Msg("error recording login")
+		return
+	}
+	if login.Suspicious() {
+		s.alert(ctx, event.User, login)
+	}
+}
+
+// record stores the login and its device, the login is new when the user logged in before but
+// never from its device or country.
+func (s *loginService) record(ctx context.Context, event *domain.SecurityEvent) (*domain.Login, error) {
+	login := &domain.Login{
+		UUID:        s.GenerateUUIDHash("login"),
+		UserID:      event.User.ID,
+		IP:          event.IP,
+		UserAgent:   event.UserAgent,
+		Country:     event.Details["country"],
+		Fingerprint: event.Details["device"],
+		CreatedAt:   event.OccurredAt,
+	}
+	if utf8.RuneCountInString(login.UserAgent) > maxLoginUserAgentLength {
+		login.UserAgent = string([]rune(login.UserAgent)[:maxLoginUserAgentLength])
+	}
+
+	history, err := s.loginRepo.History(ctx, login.UserID, login.Country)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving login history: %w", err)
+	}
+	_, err = s.loginRepo.Device(ctx, login.UserID, login.Fingerprint)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("error retrieving known device: %w", err)
+	}
+	if history.Logins > 0 {
+		login.NewDevice = err != nil
+		login.NewCountry = login.Country != "" && history.FromCountry == 0
+	}
+
+	_, err = s.loginRepo.SaveDevice(ctx, &domain.KnownDevice{
+		UUID:        s.GenerateUUIDHash("device"),
+		UserID:      login.UserID,
+		Fingerprint: login.Fingerprint,
+		UserAgent:   login.UserAgent,
+		LastIP:      login.IP,
+		LastCountry: login.Country,
+		LastSeenAt:  login.CreatedAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error saving known device: %w", err)
+	}
+
+	return s.loginRepo.Create(ctx, login)
+}
+
+// alert tells the user about the login, each channel is best effort so a failing one doesn't
+// keep the others from alerting.
+func (s *loginService) alert(ctx context.Context, user *domain.User, login *domain.Login) {
+	if err := s.notificationService.SendLoginAlert(ctx, user, login); err != nil {
+		log.Err(err).Uint("user_id", user.ID).Msg("error queueing login alert")
+	}
+
+	entry := &domain.AuditEntry{
+		UserID:    user.ID,
+		Action:    string(domain.EventSecurityLoginAlert),
+		Status:    http.StatusOK,
+		IP:        login.IP,
+		UserAgent: login.UserAgent,
+		Region:    region.FromContext(ctx),
+	}
+	if request := audit.FromContext(ctx); request != nil {
+		entry.Path = request.Path
+	}
+	s.auditService.Record(ctx, entry)
+
+	details := map[string]string{
+		"device":      login.Fingerprint,
+		"new_device":  strconv.FormatBool(login.NewDevice),
+		"new_country": strconv.FormatBool(login.NewCountry),
+	}
+	if login.Country != "" {
+		details["country"] = login.Country
+	}
+	s.securityEvents.Publish(ctx, domain.EventSecurityLoginAlert, user, details)
+}
+
+func (s *loginService) Logins(ctx context.Context, userID uint, page *pagination.Page) ([]*domain.Login, *pagination.Cursor, error) {
+	logins, next, err := s.loginRepo.All(ctx, userID, page)
+	if err != nil {
+		log.Err(err).Msg("error retrieving logins")
+		return nil, nil, err
+	}
+
+	return logins, next, nil
+}
+
+func (s *loginService) Devices(ctx context.Context, userID uint) ([]*domain.KnownDevice, error) {
+	devices, err := s.loginRepo.Devices(ctx, userID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving known devices")
+		return nil, err
+	}
+
+	return devices, nil
+}
+
+func (s *loginService) ForgetDevice(ctx context.Context, userID uint, uuid string) error {
+	if err := s.loginRepo.DeleteDevice(ctx, userID, uuid); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("device %s: %w", uuid, domain.ErrKnownDeviceNotFound)
+		}
+		log.Err(err).Msg("error deleting known device")
+		return err
+	}
+
+	return nil
+}
+
+func (s *loginService) PruneDue(ctx context.Context) error {
+	pruned, err := s.loginRepo.DeleteBefore(ctx, time.Now().Add(-domain.LoginRetention))
+	if err != nil {
+		return fmt.Errorf("error pruning logins: %w", err)
+	}
+	if pruned > 0 {
+		log.Info().Int64("logins", pruned).Msg("pruned old logins")
+	}
+
+	return nil
+}
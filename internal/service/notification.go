@@ -0,0 +1,360 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/mail"
+	"github.com/meowmix1337/the_recipe_book/internal/metrics"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	dueEmailBatchSize = 50
+	// emailConcurrency is how many emails are sent at once, mail providers throttle
+	// connections so it is kept low.
+	emailConcurrency = 4
+	// emailErrorLength caps the error stored with a failed attempt.
+	emailErrorLength = 500
+)
+
+// NotificationService emails users. Emails are rendered from the mail templates and queued
+// in an outbox, a background worker sends them through the configured mail provider and retries
+// failures with exponential backoff, so a provider outage doesn't fail the request that sent
+// the email.
+type NotificationService interface {
+	// SendVerification queues the email confirming the address of the user with verifyURL.
+	SendVerification(ctx context.Context, user *domain.User, verifyURL string, expiresAt time.Time) error
+	// SendPasswordReset queues the email with the link to choose a new password.
+	SendPasswordReset(ctx context.Context, user *domain.User, resetURL string, expiresAt time.Time) error
+	// SendDueReminder queues a reminder of todos due soon, nothing is sent without todos.
+	SendDueReminder(ctx context.Context, user *domain.User, todos []*domain.Todo) error
+	SendWeeklyDigest(ctx context.Context, user *domain.User, digest *domain.WeeklyDigest) error
+	// SendTakeout queues the email with the link to download a data export of the user.
+	SendTakeout(ctx context.Context, user *domain.User, downloadURL string, expiresAt time.Time) error
+	// SendEmailChange queues the email with the link to confirm changing the email of the user
+	// to newEmail, to is either the current or the new address.
+	SendEmailChange(ctx context.Context, user *domain.User, to string, newEmail string, confirmURL string, expiresAt time.Time) error
+	// SendNudge queues a productivity nudge, users only get them after opting in to insights.
+	SendNudge(ctx context.Context, user *domain.User, nudge *domain.ProductivityNudge) error
+	// SendAutomationNotice queues a notification an automation of the user sent.
+	SendAutomationNotice(ctx context.Context, user *domain.User, notice *domain.AutomationNotice) error
+	// SendLoginAlert queues the alert of a login from a new device or country.
+	SendLoginAlert(ctx context.Context, user *domain.User, login *domain.Login) error
+	// SendEscalation queues the notice of overdue todos escalation rules escalated, nothing is
+	// sent without escalations.
+	SendEscalation(ctx context.Context, user *domain.User, escalations []*domain.Escalation) error
+	// SendRecoveryStarted queues the notice that a recovery of the account of the user was
+	// started, threshold is how many trusted contacts have to approve it.
+	SendRecoveryStarted(ctx context.Context, user *domain.User, request *domain.RecoveryRequest, threshold int) error
+	// SendRecoveryApproval queues the request to approve the recovery to a trusted contact.
+	SendRecoveryApproval(ctx context.Context, contact *domain.TrustedContact, request *domain.RecoveryRequest) error
+
+	// SendDue sends the queued emails whose next attempt is due, it is run by a background worker.
+	SendDue(ctx context.Context) error
+}
+
+type notificationService struct {
+	*BaseService
+
+	userRepo  repo.UserRepo
+	emailRepo repo.EmailRepo
+
+	sender mail.Sender
+}
+
+func NewNotificationService(
+	base *BaseService,
+	userRepo repo.UserRepo,
+	emailRepo repo.EmailRepo,
+	sender mail.Sender,
+) *notificationService {
+	return &notificationService{
+		BaseService: base,
+		userRepo:    userRepo,
+		emailRepo:   emailRepo,
+		sender:      sender,
+	}
+}
+
+// check NotificationService interface implementation on compile time.
+var _ NotificationService = (*notificationService)(nil)
+
+// check ReminderHandler interface implementation on compile time.
+var _ ReminderHandler = (*notificationService)(nil)
+
+func (s *notificationService) SendVerification(ctx context.Context, user *domain.User, verifyURL string, expiresAt time.Time) error {
+	return s.queue(ctx, user, domain.NotificationVerification, mail.Verification{
+		Name:      user.FirstName,
+		URL:       verifyURL,
+		ExpiresAt: expiresAt,
+	})
+}
+
+func (s *notificationService) SendPasswordReset(ctx context.Context, user *domain.User, resetURL string, expiresAt time.Time) error {
+	return s.queue(ctx, user, domain.NotificationPasswordReset, mail.PasswordReset{
+		Name:      user.FirstName,
+		URL:       resetURL,
+		ExpiresAt: expiresAt,
+	})
+}
+
+func (s *notificationService) SendTakeout(ctx context.Context, user *domain.User, downloadURL string, expiresAt time.Time) error {
+	return s.queue(ctx, user, domain.NotificationTakeout, mail.Takeout{
+		Name:      user.FirstName,
+		URL:       downloadURL,
+		ExpiresAt: expiresAt,
+	})
+}
+
+func (s *notificationService) SendEmailChange(
+	ctx context.Context, user *domain.User, to string, newEmail string, confirmURL string, expiresAt time.Time,
+) error {
+	return s.queueTo(ctx, user.ID, to, domain.NotificationEmailChange, mail.EmailChange{
+		Name:      user.FirstName,
+		Email:     newEmail,
+		URL:       confirmURL,
+		ExpiresAt: expiresAt,
+		Current:   to == user.Email,
+	})
+}
+
+func (s *notificationService) SendDueReminder(ctx context.Context, user *domain.User, todos []*domain.Todo) error {
+	if len(todos) == 0 {
+		return nil
+	}
+
+	return s.queue(ctx, user, domain.NotificationDueReminder, mail.DueReminder{
+		Name:  user.FirstName,
+		Todos: todos,
+		URL:   s.appURL(),
+	})
+}
+
+// HandleReminder emails the todos that are due soon, overdue todos are left to escalations.
+func (s *notificationService) HandleReminder(ctx context.Context, reminder *domain.Reminder) {
+	if reminder.Kind != domain.ReminderDueSoon || len(reminder.Todos) == 0 {
+		return
+	}
+
+	user, err := s.userRepo.ByID(ctx, reminder.UserID)
+	if err != nil {
+		log.Err(err).Uint("user_id", reminder.UserID).Msg("error retrieving user for due reminder")
+		return
+	}
+	if err = s.SendDueReminder(ctx, user, reminder.Todos); err != nil {
+		log.Err(err).Uint("user_id", reminder.UserID).Msg("error sending due reminder")
+	}
+}
+
+func (s *notificationService) SendWeeklyDigest(ctx context.Context, user *domain.User, digest *domain.WeeklyDigest) error {
+	if digest == nil {
+		return fmt.Errorf("no weekly digest provided")
+	}
+
+	return s.queue(ctx, user, domain.NotificationWeeklyDigest, mail.WeeklyDigest{
+		Name:      user.FirstName,
+		From:      digest.From,
+		To:        digest.To,
+		Completed: digest.Completed,
+		Overdue:   digest.Overdue,
+		Upcoming:  digest.Upcoming,
+		URL:       s.appURL(),
+	})
+}
+
+func (s *notificationService) SendNudge(ctx context.Context, user *domain.User, nudge *domain.ProductivityNudge) error {
+	if nudge == nil {
+		return fmt.Errorf("no productivity nudge provided")
+	}
+
+	return s.queue(ctx, user, domain.NotificationNudge, mail.ProductivityNudge{
+		Name:     user.FirstName,
+		Kind:     nudge.Kind,
+		Overdue:  nudge.Overdue,
+		IdleDays: nudge.IdleDays,
+		URL:      s.appURL(),
+	})
+}
+
+func (s *notificationService) SendAutomationNotice(ctx context.Context, user *domain.User, notice *domain.AutomationNotice) error {
+	if notice == nil {
+		return fmt.Errorf("no automation notice provided")
+	}
+
+	return s.queue(ctx, user, domain.NotificationAutomation, mail.AutomationNotice{
+		Name:       user.FirstName,
+		Automation: notice.Automation,
+		Title:      notice.Title,
+		Body:       notice.Body,
+		URL:        s.appURL(),
+	})
+}
+
+// queue renders the template for the user and stores it in the outbox, the emails of child
+// accounts go to their parent since children log in without an email address.
+func (s *notificationService) queue(ctx context.Context, user *domain.User, kind domain.NotificationKind, data mail.Template) error {
+	recipient := user.Email
+	if user.IsChild() {
+		parent, err := s.userRepo.ByID(ctx, user.ParentID)
+		if err != nil {
+			return fmt.Errorf("error retrieving parent account: %w", err)
+		}
+		recipient = parent.Email
+	}
+
+	return s.queueTo(ctx, user.ID, recipient, kind, data)
+}
+
+// queueTo renders the template for the user and stores it in the outbox for the recipient.
+func (s *notificationService) queueTo(ctx context.Context, userID uint, recipient string, kind domain.NotificationKind, data mail.Template) error {
+	msg, err := mail.Render([]string{recipient}, data)
+	if err != nil {
+		log.Err(err).Str("kind", string(kind)).Msg("error rendering email")
+		return err
+	}
+
+	err = s.emailRepo.Create(ctx, &domain.Email{
+		UUID:          s.GenerateUUIDHash("email"),
+		UserID:        userID,
+		Kind:          kind,
+		To:            msg.To,
+		Subject:       msg.Subject,
+		Text:          msg.Text,
+		HTML:          msg.HTML,
+		NextAttemptAt: time.Now().UTC(),
+	})
+	if err != nil {
+		log.Err(err).Str("kind", string(kind)).Msg("error queueing email")
+		return fmt.Errorf("error queueing email: %w", err)
+	}
+
+	return nil
+}
+
+func (s *notificationService) SendLoginAlert(ctx context.Context, user *domain.User, login *domain.Login) error {
+	return s.queue(ctx, user, domain.NotificationLoginAlert, mail.LoginAlert{
+		Name:       user.FirstName,
+		Device:     domain.DeviceName(login.UserAgent),
+		IP:         login.IP,
+		Country:    login.Country,
+		At:         login.CreatedAt,
+		NewDevice:  login.NewDevice,
+		NewCountry: login.NewCountry,
+		URL:        s.appURL() + "/settings/security",
+	})
+}
+
+func (s *notificationService) SendEscalation(ctx context.Context, user *domain.User, escalations []*domain.Escalation) error {
+	if len(escalations) == 0 {
+		return nil
+	}
+
+	return s.queue(ctx, user, domain.NotificationEscalation, mail.Escalation{
+		Name:        user.FirstName,
+		Escalations: escalations,
+		URL:         s.appURL(),
+	})
+}
+
+func (s *notificationService) SendRecoveryStarted(
+	ctx context.Context, user *domain.User, request *domain.RecoveryRequest, threshold int,
+) error {
+	return s.queue(ctx, user, domain.NotificationRecoveryStarted, mail.RecoveryStarted{
+		Name:      user.FirstName,
+		Threshold: threshold,
+		ReadyAt:   request.ReadyAt,
+		URL:       s.appURL() + "/settings/security",
+	})
+}
+
+func (s *notificationService) SendRecoveryApproval(
+	ctx context.Context, contact *domain.TrustedContact, request *domain.RecoveryRequest,
+) error {
+	return s.queueTo(ctx, contact.UserID, contact.Email, domain.NotificationRecoveryApproval, mail.RecoveryApproval{
+		Name:     contact.FirstName,
+		UserName: request.UserName,
+		URL:      s.appURL(),
+	})
+}
+
+func (s *notificationService) appURL() string {
+	return strings.TrimRight(s.Config.GetAppURL(), "/")
+}
+
+func (s *notificationService) SendDue(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	emails, err := s.emailRepo.Due(ctx, now, dueEmailBatchSize)
+	if err != nil {
+		return fmt.Errorf("error retrieving due emails: %w", err)
+	}
+	metrics.WorkerQueueDepth.WithLabelValues("emails", metrics.RegionLabel(region.FromContext(ctx))).Set(float64(len(emails)))
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, emailConcurrency)
+	for _, email := range emails {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			s.send(ctx, email, now)
+		}()
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (s *notificationService) send(ctx context.Context, email *domain.Email, now time.Time) {
+	// claim the attempt first so multiple instances never send the same email twice, the next
+	// attempt already points at the retry in case this instance dies while sending
+	retryAt := now.Add(domain.EmailBackoff(email.Attempts + 1))
+	if err := s.emailRepo.Claim(ctx, email, retryAt); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Err(err).Str("email", email.UUID).Msg("error claiming email")
+		}
+		return
+	}
+	email.Attempts++
+	email.NextAttemptAt = retryAt
+
+	err := s.sender.Send(ctx, &mail.Message{
+		To:      email.To,
+		Subject: email.Subject,
+		Text:    email.Text,
+		HTML:    email.HTML,
+	})
+
+	result := metrics.EmailSent
+	switch {
+	case err == nil:
+		email.Status = domain.EmailSent
+		email.LastError = ""
+		email.SentAt = time.Now().UTC()
+	case email.Attempts >= domain.EmailMaxAttempts:
+		result = metrics.EmailFailed
+		email.Status = domain.EmailFailed
+		email.LastError = truncate(err.Error(), emailErrorLength)
+		log.Warn().Err(err).Str("email", email.UUID).Str("kind", string(email.Kind)).Msg("sending email failed, giving up")
+	default:
+		result = metrics.EmailRetry
+		email.LastError = truncate(err.Error(), emailErrorLength)
+		log.Debug().Err(err).Str("email", email.UUID).Time("retry_at", retryAt).Msg("sending email failed")
+	}
+	metrics.EmailsSent.WithLabelValues(string(email.Kind), result).Inc()
+
+	if err = s.emailRepo.Update(ctx, email); err != nil {
+		log.Err(err).Str("email", email.UUID).Msg("error updating email")
+	}
+}
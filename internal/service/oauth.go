@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+// OAuthService is the authorization server used for account linking, e.g. by smart speaker
+// skills. It implements the authorization code grant with rotating refresh tokens.
+type OAuthService interface {
+	// Authorize issues an authorization code for the signed in user and returns the URI the
+	// user is redirected back to the client with.
+	Authorize(ctx context.Context, userID uint, clientID string, redirectURI string, state string) (string, error)
+	// Token exchanges an authorization code or a refresh token for new tokens.
+	Token(ctx context.Context, tokenRequest *domain.OAuthTokenRequest) (*domain.OAuthGrant, error)
+	// Authenticate resolves a raw access token, expired and revoked tokens are rejected.
+	Authenticate(ctx context.Context, accessToken string) (*domain.OAuthGrant, error)
+
+	Grants(ctx context.Context, userID uint) ([]*domain.OAuthGrant, error)
+	Revoke(ctx context.Context, userID uint, uuid string) error
+}
+
+type oauthService struct {
+	*BaseService
+
+	oauthRepo repo.OAuthRepo
+}
+
+func NewOAuthService(base *BaseService, oauthRepo repo.OAuthRepo) *oauthService {
+	return &oauthService{
+		BaseService: base,
+		oauthRepo:   oauthRepo,
+	}
+}
+
+// check OAuthService interface implementation on compile time.
+var _ OAuthService = (*oauthService)(nil)
+
+func (s *oauthService) Authorize(ctx context.Context, userID uint, clientID string, redirectURI string, state string) (string, error) {
+	client, err := s.client(clientID)
+	if err != nil {
+		return "", err
+	}
+
+	if !client.AllowsRedirect(redirectURI) {
+		return "", domain.ErrInvalidRedirectURI
+	}
+
+	redirect, err := url.Parse(redirectURI)
+	if err != nil {
+		return "", domain.ErrInvalidRedirectURI
+	}
+
+	code, err := generateRegionalToken(ctx, domain.OAuthCodePrefix)
+	if err != nil {
+		log.Err(err).Msg("error generating authorization code")
+		return "", err
+	}
+
+	err = s.oauthRepo.CreateCode(ctx, &domain.OAuthAuthorizationCode{
+		UserID:      userID,
+		ClientID:    client.ID,
+		RedirectURI: redirectURI,
+		ExpiresAt:   time.Now().UTC().Add(domain.OAuthCodeExpiration),
+	}, hashToken(code))
+	if err != nil {
+		log.Err(err).Msg("error creating authorization code")
+		return "", fmt.Errorf("error creating authorization code: %w", err)
+	}
+
+	query := redirect.Query()
+	query.Set("code", code)
+	if state != "" {
+		query.Set("state", state)
+	}
+	redirect.RawQuery = query.Encode()
+
+	return redirect.String(), nil
+}
+
+func (s *oauthService) Token(ctx context.Context, tokenRequest *domain.OAuthTokenRequest) (*domain.OAuthGrant, error) {
+	client, err := s.client(tokenRequest.ClientID)
+	if err != nil {
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(client.Secret), []byte(tokenRequest.ClientSecret)) != 1 {
+		return nil, domain.ErrInvalidClient
+	}
+
+	switch tokenRequest.GrantType {
+	case domain.OAuthGrantTypeAuthorizationCode:
+		return s.exchangeCode(ctx, client, tokenRequest)
+	case domain.OAuthGrantTypeRefreshToken:
+		return s.refresh(ctx, client, tokenRequest)
+	}
+
+	return nil, domain.ErrUnsupportedGrantType
+}
+
+func (s *oauthService) Authenticate(ctx context.Context, accessToken string) (*domain.OAuthGrant, error) {
+	if !strings.HasPrefix(accessToken, domain.OAuthAccessTokenPrefix) {
+		return nil, domain.ErrInvalidOAuthToken
+	}
+
+	ctx, err := s.tokenContext(ctx, domain.OAuthAccessTokenPrefix, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	grant, err := s.oauthRepo.GrantByAccessHash(ctx, hashToken(accessToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrInvalidOAuthToken
+		}
+		log.Err(err).Msg("error retrieving oauth grant")
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if !now.Before(grant.AccessExpiresAt) {
+		return nil, domain.ErrInvalidOAuthToken
+	}
+
+	if err = s.oauthRepo.TouchGrant(ctx, grant.ID, now); err != nil {
+		// usage tracking must not fail the request
+		log.Err(err).Str("oauth_grant", grant.UUID).Msg("error updating oauth grant usage")
+	}
+
+	grant.Region = region.FromContext(ctx)
+	return grant, nil
+}
+
+func (s *oauthService) Grants(ctx context.Context, userID uint) ([]*domain.OAuthGrant, error) {
+	grants, err := s.oauthRepo.Grants(ctx, userID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving oauth grants")
+		return nil, err
+	}
+
+	return grants, nil
+}
+
+func (s *oauthService) Revoke(ctx context.Context, userID uint, uuid string) error {
+	grants, err := s.Grants(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, grant := range grants {
+		if grant.UUID != uuid {
+			continue
+		}
+
+		if err = s.oauthRepo.DeleteGrant(ctx, userID, uuid); err != nil {
+			log.Err(err).Msg("error revoking oauth grant")
+			return fmt.Errorf("error revoking oauth grant: %w", err)
+		}
+		return nil
+	}
+
+	return domain.ErrOAuthGrantNotFound
+}
+
+func (s *oauthService) exchangeCode(
+	ctx context.Context,
+	client *domain.OAuthClient,
+	tokenRequest *domain.OAuthTokenRequest,
+) (*domain.OAuthGrant, error) {
+	// the code and the grant live in the region of the user who authorized the client
+	ctx, err := s.tokenContext(ctx, domain.OAuthCodePrefix, tokenRequest.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	code, err := s.oauthRepo.RedeemCode(ctx, hashToken(tokenRequest.Code), now)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrInvalidGrant
+		}
+		log.Err(err).Msg("error redeeming authorization code")
+		return nil, err
+	}
+
+	// the redirect URI must match the one the code was issued for, RFC 6749 section 4.1.3
+	if code.ClientID != client.ID || code.RedirectURI != tokenRequest.RedirectURI || !now.Before(code.ExpiresAt) {
+		return nil, domain.ErrInvalidGrant
+	}
+
+	accessToken, refreshToken, err := generateTokenPair(ctx)
+	if err != nil {
+		log.Err(err).Msg("error generating oauth tokens")
+		return nil, err
+	}
+
+	grant, err := s.oauthRepo.CreateGrant(ctx, &domain.OAuthGrant{
+		UUID:            s.GenerateUUIDHash("grant"),
+		UserID:          code.UserID,
+		ClientID:        client.ID,
+		AccessExpiresAt: now.Add(domain.OAuthAccessTokenExpiration),
+	}, hashToken(accessToken), hashToken(refreshToken))
+	if err != nil {
+		log.Err(err).Msg("error creating oauth grant")
+		return nil, fmt.Errorf("error creating oauth grant: %w", err)
+	}
+
+	grant.AccessToken = accessToken
+	grant.RefreshToken = refreshToken
+	return grant, nil
+}
+
+func (s *oauthService) refresh(
+	ctx context.Context,
+	client *domain.OAuthClient,
+	tokenRequest *domain.OAuthTokenRequest,
+) (*domain.OAuthGrant, error) {
+	ctx, err := s.tokenContext(ctx, domain.OAuthRefreshTokenPrefix, tokenRequest.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, refreshToken, err := generateTokenPair(ctx)
+	if err != nil {
+		log.Err(err).Msg("error generating oauth tokens")
+		return nil, err
+	}
+
+	grant, err := s.oauthRepo.RotateGrant(
+		ctx,
+		client.ID,
+		hashToken(tokenRequest.RefreshToken),
+		hashToken(accessToken),
+		hashToken(refreshToken),
+		time.Now().UTC().Add(domain.OAuthAccessTokenExpiration),
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrInvalidGrant
+		}
+		log.Err(err).Msg("error rotating oauth grant")
+		return nil, err
+	}
+
+	grant.AccessToken = accessToken
+	grant.RefreshToken = refreshToken
+	return grant, nil
+}
+
+// client returns the configured client with the given ID, only the voice assistant client exists for now.
+func (s *oauthService) client(clientID string) (*domain.OAuthClient, error) {
+	configured := s.Config.GetVoiceClientID()
+	if configured == "" || clientID != configured {
+		return nil, domain.ErrInvalidClient
+	}
+
+	return &domain.OAuthClient{
+		ID:           configured,
+		Secret:       s.Config.GetVoiceClientSecret(),
+		RedirectURIs: s.Config.GetVoiceRedirectURIs(),
+	}, nil
+}
+
+// generateTokenPair returns access and refresh tokens tagged with the region of ctx.
+func generateTokenPair(ctx context.Context) (string, string, error) {
+	accessToken, err := generateRegionalToken(ctx, domain.OAuthAccessTokenPrefix)
+	if err != nil {
+		return "", "", err
+	}
+
+	refreshToken, err := generateRegionalToken(ctx, domain.OAuthRefreshTokenPrefix)
+	if err != nil {
+		return "", "", err
+	}
+
+	return accessToken, refreshToken, nil
+}
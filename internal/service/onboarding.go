@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/config"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+// sampleListName and sampleTodos make the list new users find on their first login when the
+// onboarding_sample_list feature is on, the todos walk them through the onboarding steps.
+const sampleListName = "Getting started"
+
+//nolint:gochecknoglobals // constant list
+var sampleTodos = []string{
+	"Set your name and timezone in your profile",
+	"Add a todo of your own, try \"Pay rent tomorrow 5pm #finance\"",
+	"Drag a todo to reorder it",
+	"Complete this todo",
+	"Invite someone to share a list",
+}
+
+// OnboardingService keeps the onboarding of a user in sync across their devices: the steps they
+// completed and the tips they dismissed. On the first login it runs the triggers turned on with
+// FEATURE_FLAGS, e.g. creating a sample list.
+type OnboardingService interface {
+	State(ctx context.Context, userID uint) (*domain.OnboardingState, error)
+	Update(ctx context.Context, userID uint, update *domain.OnboardingUpdate) (*domain.OnboardingState, error)
+	// Reset restarts the onboarding, the first login triggers don't run again.
+	Reset(ctx context.Context, userID uint) (*domain.OnboardingState, error)
+}
+
+type onboardingService struct {
+	*BaseService
+
+	listService ListService
+	todoService TodoService
+
+	onboardingRepo repo.OnboardingRepo
+}
+
+func NewOnboardingService(
+	base *BaseService,
+	listService ListService,
+	todoService TodoService,
+	onboardingRepo repo.OnboardingRepo,
+) *onboardingService {
+	return &onboardingService{
+		BaseService:    base,
+		listService:    listService,
+		todoService:    todoService,
+		onboardingRepo: onboardingRepo,
+	}
+}
+
+// check OnboardingService interface implementation on compile time.
+var _ OnboardingService = (*onboardingService)(nil)
+
+func (s *onboardingService) State(ctx context.Context, userID uint) (*domain.OnboardingState, error) {
+	state, err := s.onboardingRepo.State(ctx, userID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving onboarding state")
+		return nil, err
+	}
+
+	return state, nil
+}
+
+func (s *onboardingService) Update(ctx context.Context, userID uint, update *domain.OnboardingUpdate) (*domain.OnboardingState, error) {
+	for _, key := range update.CompleteSteps {
+		if !domain.ValidOnboardingStep(key) {
+			return nil, fmt.Errorf("step %q: %w", key, domain.ErrUnknownOnboardingStep)
+		}
+	}
+	for _, key := range update.DismissTips {
+		if !domain.ValidOnboardingTip(key) {
+			return nil, fmt.Errorf("tip %q: %w", key, domain.ErrInvalidOnboardingTip)
+		}
+	}
+
+	state, err := s.State(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	tips := len(state.DismissedTips)
+	for _, key := range update.DismissTips {
+		if !slices.Contains(state.DismissedTips, key) {
+			tips++
+		}
+	}
+	if tips > domain.MaxDismissedTips {
+		return nil, domain.ErrTooManyDismissedTips
+	}
+
+	if err = s.onboardingRepo.Save(ctx, userID, update, time.Now()); err != nil {
+		log.Err(err).Msg("error saving onboarding state")
+		return nil, fmt.Errorf("error saving onboarding state: %w", err)
+	}
+
+	return s.State(ctx, userID)
+}
+
+func (s *onboardingService) Reset(ctx context.Context, userID uint) (*domain.OnboardingState, error) {
+	if err := s.onboardingRepo.Reset(ctx, userID); err != nil {
+		log.Err(err).Msg("error resetting onboarding state")
+		return nil, fmt.Errorf("error resetting onboarding state: %w", err)
+	}
+
+	return s.State(ctx, userID)
+}
+
+// HandleSecurityEvent runs the first login triggers on the first login of a user. They only
+// run once, even when they fail, so a user never ends up with two sample lists.
+func (s *onboardingService) HandleSecurityEvent(ctx context.Context, event *domain.SecurityEvent) {
+	if event.Type != domain.EventSecurityLogin {
+		return
+	}
+
+	first, err := s.onboardingRepo.Trigger(ctx, event.User.ID, event.OccurredAt)
+	if err != nil {
+		log.Err(err).Uint("user_id", event.User.ID).Msg("error claiming onboarding triggers")
+		return
+	}
+	if !first {
+		return
+	}
+
+	if s.Config.GetFeatureFlag(config.FeatureOnboardingSampleList) {
+		if err = s.createSampleList(ctx, event.User.ID); err != nil {
+			log.Err(err).Uint("user_id", event.User.ID).Msg("error creating sample list")
+		}
+	}
+}
+
+func (s *onboardingService) createSampleList(ctx context.Context, userID uint) error {
+	list, err := s.listService.Create(ctx, userID, sampleListName)
+	if err != nil {
+		return err
+	}
+
+	for _, title := range sampleTodos {
+		_, err = s.todoService.Create(ctx, userID, &domain.TodoCreate{
+			ListUUID: list.UUID,
+			Title:    title,
+			Priority: domain.PriorityMedium,
+			Source:   domain.CaptureSourceApp,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	log.Info().Uint("user_id", userID).Str("list", list.UUID).Msg("created sample list")
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/config"
+	"github.com/meowmix1337/the_recipe_book/internal/metrics"
+	"github.com/meowmix1337/the_recipe_book/internal/password"
+	"github.com/meowmix1337/the_recipe_book/internal/tracing"
+)
+
+// newPasswords hashes new passwords with the configured PASSWORD_HASHER, hashes of the other
+// algorithm keep verifying so switching doesn't lock anyone out.
+func newPasswords(cfg config.Config) *password.Passwords {
+	bcryptHasher := password.NewBcrypt(cfg.GetBcryptCost())
+	argon2Hasher := password.NewArgon2id(password.Argon2Params{
+		Memory:      uint32(cfg.GetArgon2Memory()),     //nolint:gosec // validated on startup
+		Iterations:  uint32(cfg.GetArgon2Iterations()), //nolint:gosec // validated on startup
+		Parallelism: uint8(cfg.GetArgon2Parallelism()), //nolint:gosec // validated on startup
+	})
+
+	if cfg.GetPasswordHasher() == "argon2id" {
+		return password.New(argon2Hasher, bcryptHasher)
+	}
+	return password.New(bcryptHasher, argon2Hasher)
+}
+
+// hashPassword hashes a password for storage with the configured PASSWORD_HASHER.
+func (s *BaseService) hashPassword(ctx context.Context, plain string) (string, error) {
+	_, span := tracing.Start(ctx, "password.hash")
+	defer span.End()
+	defer observePasswordHash("hash", time.Now())
+	return s.Passwords.Hash(plain)
+}
+
+// comparePassword returns password.ErrMismatch when plain doesn't match hash. rehash reports
+// whether the hash is outdated and should be replaced, see rehashPassword.
+func (s *BaseService) comparePassword(ctx context.Context, hash string, plain string) (rehash bool, err error) {
+	_, span := tracing.Start(ctx, "password.compare")
+	defer span.End()
+	defer observePasswordHash("compare", time.Now())
+	return s.Passwords.Verify(hash, plain)
+}
+
+func observePasswordHash(operation string, start time.Time) {
+	metrics.PasswordHashDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// planQuickWin is the estimate up to which a todo counts as a quick win.
+	planQuickWin = 15 * time.Minute
+	// planStaleAfter is how long a todo stays open before it gets a bump for waiting.
+	planStaleAfter = 14 * 24 * time.Hour
+)
+
+//nolint:gochecknoglobals // lookup table
+var planPriorityScores = map[domain.Priority]float64{
+	domain.PriorityLow:    0,
+	domain.PriorityMedium: 10,
+	domain.PriorityHigh:   20,
+	domain.PriorityUrgent: 35,
+}
+
+// PlanService plans the day of a user, it ranks the open todos by priority, due date and age
+// and picks the best ones that fit the daily capacity. Members of a workspace get no capacity
+// on its days off and a working day of its working hours until they pick their own.
+type PlanService interface {
+	// Plan replaces the plan of the requested day.
+	Plan(ctx context.Context, userID uint, request *domain.PlanRequest) (*domain.Plan, error)
+	ByDate(ctx context.Context, userID uint, date time.Time) (*domain.Plan, error)
+}
+
+type planService struct {
+	*BaseService
+
+	todoService      TodoService
+	workspaceService WorkspaceService
+
+	planRepo repo.PlanRepo
+	userRepo repo.UserRepo
+}
+
+func NewPlanService(
+	base *BaseService,
+	todoService TodoService,
+	workspaceService WorkspaceService,
+	planRepo repo.PlanRepo,
+	userRepo repo.UserRepo,
+) *planService {
+	return &planService{
+		BaseService:      base,
+		todoService:      todoService,
+		workspaceService: workspaceService,
+		planRepo:         planRepo,
+		userRepo:         userRepo,
+	}
+}
+
+// check PlanService interface implementation on compile time.
+var _ PlanService = (*planService)(nil)
+
+func (s *planService) Plan(ctx context.Context, userID uint, request *domain.PlanRequest) (*domain.Plan, error) {
+	if request == nil {
+		request = &domain.PlanRequest{}
+	}
+
+	// today and the days until todos are due are those of the timezone of the user
+	location := userLocation(ctx, s.userRepo, userID)
+	day := domain.Day(request.Date)
+	if request.Date.IsZero() {
+		day = domain.DayIn(time.Now(), location)
+	}
+
+	capacity := request.Capacity
+	if capacity <= 0 {
+		var err error
+		if capacity, err = s.capacity(ctx, userID, day); err != nil {
+			return nil, err
+		}
+	}
+
+	todos, _, err := s.todoService.All(ctx, userID, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]*domain.PlanItem, 0, len(todos))
+	for _, todo := range todos {
+		if todo.Completed() {
+			continue
+		}
+		candidates = append(candidates, rankTodo(todo, day, location))
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if !a.Todo.DueDate.Equal(b.Todo.DueDate) {
+			// todos with a due date go before todos without one
+			return !a.Todo.DueDate.IsZero() && (b.Todo.DueDate.IsZero() || a.Todo.DueDate.Before(b.Todo.DueDate))
+		}
+
+		return a.Todo.CreatedAt.Before(b.Todo.CreatedAt)
+	})
+
+	plan := &domain.Plan{
+		UserID:   userID,
+		Date:     day,
+		Capacity: capacity,
+		Items:    []*domain.PlanItem{},
+	}
+
+	// fill the day greedily, a todo that doesn't fit is skipped so smaller ones can still fit
+	remaining := capacity
+	for _, item := range candidates {
+		if item.Estimate > remaining {
+			continue
+		}
+		remaining -= item.Estimate
+		item.Position = len(plan.Items) + 1
+		plan.Items = append(plan.Items, item)
+	}
+
+	saved, err := s.planRepo.Save(ctx, plan)
+	if err != nil {
+		log.Err(err).Msg("error saving plan")
+		return nil, fmt.Errorf("error saving plan: %w", err)
+	}
+
+	return saved, nil
+}
+
+// capacity returns the capacity of a day the user didn't pick one for.
+func (s *planService) capacity(ctx context.Context, userID uint, day time.Time) (time.Duration, error) {
+	calendar, err := s.workspaceService.Calendar(ctx, userID)
+	if err != nil && !errors.Is(err, domain.ErrWorkspaceNotFound) {
+		return 0, err
+	}
+	if calendar != nil && !calendar.WorkingDay(day) {
+		return 0, nil
+	}
+
+	capacity, err := s.planRepo.LastCapacity(ctx, userID)
+	if err == nil {
+		return capacity, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		log.Err(err).Msg("error retrieving plan capacity")
+		return 0, err
+	}
+
+	if calendar != nil {
+		return calendar.Hours.Length(), nil
+	}
+	return domain.DefaultDailyCapacity, nil
+}
+
+func (s *planService) ByDate(ctx context.Context, userID uint, date time.Time) (*domain.Plan, error) {
+	plan, err := s.planRepo.ByDate(ctx, userID, domain.Day(date))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("plan not found: %w", domain.ErrPlanNotFound)
+		}
+		log.Err(err).Msg("error retrieving plan")
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// rankTodo scores a todo for the given day and explains every part of the score, due dates are
+// read in location.
+func rankTodo(todo *domain.Todo, day time.Time, location *time.Location) *domain.PlanItem {
+	item := &domain.PlanItem{
+		Todo:     todo,
+		Estimate: todo.Estimate,
+		Reasons:  []string{},
+	}
+
+	if score := planPriorityScores[todo.Priority]; score > 0 {
+		item.Score += score
+		item.Reasons = append(item.Reasons, fmt.Sprintf("%s priority", todo.Priority))
+	}
+
+	if !todo.DueDate.IsZero() {
+		days := int(domain.DayIn(todo.DueDate, location).Sub(day).Hours() / 24)
+		switch {
+		case days < 0:
+			item.Score += 50 + 5*float64(min(-days, 6))
+			item.Reasons = append(item.Reasons, fmt.Sprintf("overdue by %s", plural(-days, "day")))
+		case days == 0:
+			item.Score += 45
+			item.Reasons = append(item.Reasons, "due today")
+		case days == 1:
+			item.Score += 30
+			item.Reasons = append(item.Reasons, "due tomorrow")
+		case days <= 7:
+			item.Score += 20 - 2*float64(days-2)
+			item.Reasons = append(item.Reasons, fmt.Sprintf("due in %d days", days))
+		}
+	}
+
+	if age := day.Sub(todo.CreatedAt); age >= planStaleAfter {
+		weeks := int(age.Hours() / 24 / 7)
+		item.Score += 2 * float64(min(weeks, 4))
+		item.Reasons = append(item.Reasons, fmt.Sprintf("open for %s", plural(weeks, "week")))
+	}
+
+	switch {
+	case item.Estimate <= 0:
+		item.Estimate = domain.DefaultTodoEstimate
+		item.Reasons = append(item.Reasons,
+			fmt.Sprintf("no estimate, assumed %s", plural(int(item.Estimate.Minutes()), "minute")))
+	case item.Estimate <= planQuickWin:
+		item.Score += 5
+		item.Reasons = append(item.Reasons,
+			fmt.Sprintf("quick win, about %s", plural(int(item.Estimate.Minutes()), "minute")))
+	}
+
+	return item
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, unit)
+	}
+
+	return fmt.Sprintf("%d %ss", n, unit)
+}
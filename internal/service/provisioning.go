@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ProvisioningService sets up enterprise deployments from infrastructure tools. Every operation
+// is idempotent, it describes the desired state and repeating it changes nothing, so the
+// tools can apply their configuration over and over.
+type ProvisioningService interface {
+	// PutWorkspace creates or updates the workspace of slug together with its owner account, it
+	// reports whether the workspace was created.
+	PutWorkspace(ctx context.Context, slug string, provision *domain.WorkspaceProvision) (*domain.Workspace, bool, error)
+	Workspace(ctx context.Context, slug string) (*domain.Workspace, error)
+	SetQuotas(ctx context.Context, slug string, quotas domain.Quotas) (*domain.Workspace, error)
+	SetSSO(ctx context.Context, slug string, sso *domain.SSOConfig) (*domain.Workspace, error)
+
+	// PutAdmin creates the staff account unless it exists and replaces its admin scopes, it
+	// reports whether the account was created.
+	PutAdmin(ctx context.Context, provision *domain.AdminProvision) (*domain.User, bool, error)
+}
+
+type provisioningService struct {
+	*BaseService
+
+	userService  UserService
+	adminService AdminService
+
+	userRegionRepo repo.UserRegionRepo
+	workspaceRepo  repo.WorkspaceRepo
+}
+
+func NewProvisioningService(
+	base *BaseService,
+	userService UserService,
+	adminService AdminService,
+	userRegionRepo repo.UserRegionRepo,
+	workspaceRepo repo.WorkspaceRepo,
+) *provisioningService {
+	return &provisioningService{
+		BaseService:    base,
+		userService:    userService,
+		adminService:   adminService,
+		userRegionRepo: userRegionRepo,
+		workspaceRepo:  workspaceRepo,
+	}
+}
+
+// check ProvisioningService interface implementation on compile time.
+var _ ProvisioningService = (*provisioningService)(nil)
+
+func (s *provisioningService) PutWorkspace(ctx context.Context, slug string, provision *domain.WorkspaceProvision) (*domain.Workspace, bool, error) {
+	if provision == nil {
+		return nil, false, fmt.Errorf("no workspace details provided")
+	}
+
+	ctx, owner, _, err := s.account(ctx, provision.OwnerEmail, provision.OwnerPassword, provision.Region)
+	if err != nil {
+		return nil, false, err
+	}
+	if owner.IsChild() {
+		return nil, false, domain.ErrChildAccount
+	}
+
+	existing, err := s.workspaceRepo.ByOwner(ctx, owner.UUID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Err(err).Msg("error retrieving workspace of owner")
+		return nil, false, err
+	}
+	if existing != nil && existing.Slug != slug {
+		return nil, false, fmt.Errorf("workspace %q: %w", existing.Slug, domain.ErrWorkspaceOwnerTaken)
+	}
+
+	// the directory records where the owner actually lives, which is not the requested region
+	// when the account existed already
+	upsert := *provision
+	upsert.Region = region.FromContext(ctx)
+	created, err := s.workspaceRepo.Upsert(ctx, slug, &upsert, owner.UUID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, false, domain.ErrWorkspaceOwnerMismatch
+		}
+		log.Err(err).Msg("error provisioning workspace")
+		return nil, false, err
+	}
+
+	workspace, err := s.Workspace(ctx, slug)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return workspace, created, nil
+}
+
+func (s *provisioningService) Workspace(ctx context.Context, slug string) (*domain.Workspace, error) {
+	workspace, err := s.workspaceRepo.BySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("workspace %q: %w", slug, domain.ErrWorkspaceNotFound)
+		}
+		log.Err(err).Msg("error retrieving workspace")
+		return nil, err
+	}
+
+	return workspace, nil
+}
+
+func (s *provisioningService) SetQuotas(ctx context.Context, slug string, quotas domain.Quotas) (*domain.Workspace, error) {
+	if err := s.workspaceRepo.SetQuotas(ctx, slug, quotas); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("workspace %q: %w", slug, domain.ErrWorkspaceNotFound)
+		}
+		log.Err(err).Msg("error updating workspace quotas")
+		return nil, err
+	}
+
+	return s.Workspace(ctx, slug)
+}
+
+func (s *provisioningService) SetSSO(ctx context.Context, slug string, sso *domain.SSOConfig) (*domain.Workspace, error) {
+	if sso == nil {
+		return nil, fmt.Errorf("no SSO config provided")
+	}
+
+	if err := s.workspaceRepo.SetSSO(ctx, slug, sso); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("workspace %q: %w", slug, domain.ErrWorkspaceNotFound)
+		}
+		log.Err(err).Msg("error updating workspace SSO config")
+		return nil, err
+	}
+
+	return s.Workspace(ctx, slug)
+}
+
+func (s *provisioningService) PutAdmin(ctx context.Context, provision *domain.AdminProvision) (*domain.User, bool, error) {
+	if provision == nil {
+		return nil, false, fmt.Errorf("no admin details provided")
+	}
+
+	ctx, user, created, err := s.account(ctx, provision.Email, provision.Password, provision.Region)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err = s.adminService.Grant(ctx, user.UUID, provision.Scopes); err != nil {
+		return nil, false, err
+	}
+
+	return user, created, nil
+}
+
+// account returns the account of email and ctx routed to its region, the account is signed up
+// in userRegion with password when it doesn't exist yet.
+func (s *provisioningService) account(
+	ctx context.Context,
+	email string,
+	password string,
+	userRegion string,
+) (context.Context, *domain.User, bool, error) {
+	existingRegion, err := s.userRegionRepo.Region(ctx, loginKey("email", email))
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Err(err).Msg("error retrieving user region")
+		return nil, nil, false, err
+	}
+	// logins from before regions have no directory entry and live in the home region
+	user, err := s.userService.ByEmail(region.WithRegion(ctx, existingRegion), email)
+	if err == nil {
+		return region.WithRegion(ctx, existingRegion), user, false, nil
+	}
+	if !errors.Is(err, domain.ErrUserNotFound) {
+		return nil, nil, false, err
+	}
+
+	if password == "" {
+		return nil, nil, false, domain.ErrPasswordRequired
+	}
+	err = s.userService.SignUp(ctx, &domain.UserSignup{
+		Email:    email,
+		Password: password,
+		Region:   userRegion,
+	})
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	ctx = region.WithRegion(ctx, userRegion)
+	user, err = s.userService.ByEmail(ctx, email)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	return ctx, user, true, nil
+}
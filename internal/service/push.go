@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/metrics"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+	"github.com/meowmix1337/the_recipe_book/internal/webpush"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// pushBodyLength caps the todo titles listed in a reminder, the encrypted payload has to fit
+	// in a single push message.
+	pushBodyLength = 1000
+)
+
+// pushTopics let the push service replace an undelivered reminder with a newer one of its kind.
+//
+//nolint:gochecknoglobals // read-only
+var pushTopics = map[domain.ReminderKind]string{
+	domain.ReminderDueSoon: "due-reminder",
+	domain.ReminderOverdue: "overdue-reminder",
+}
+
+// PushService delivers reminders as Web Push notifications to the browsers users registered.
+// Push is disabled when the server has no VAPID keys, subscribing then fails with
+// domain.ErrPushDisabled.
+type PushService interface {
+	// PublicKey returns the VAPID public key browsers need to subscribe.
+	PublicKey() (string, error)
+	// Subscribe registers the push subscription of a browser for the user.
+	Subscribe(ctx context.Context, userID uint, subscription *domain.PushSubscription) (*domain.PushSubscription, error)
+	Unsubscribe(ctx context.Context, userID uint, uuid string) error
+	Subscriptions(ctx context.Context, userID uint) ([]*domain.PushSubscription, error)
+
+	// HandleReminder pushes the reminder to every browser of the user, subscriptions the push
+	// service dropped are deleted.
+	HandleReminder(ctx context.Context, reminder *domain.Reminder)
+}
+
+type pushService struct {
+	*BaseService
+
+	pushSubscriptionRepo repo.PushSubscriptionRepo
+
+	// keys and sender are nil when push is disabled
+	keys   *webpush.Keys
+	sender webpush.Sender
+}
+
+func NewPushService(
+	base *BaseService,
+	pushSubscriptionRepo repo.PushSubscriptionRepo,
+	keys *webpush.Keys,
+	sender webpush.Sender,
+) *pushService {
+	return &pushService{
+		BaseService:          base,
+		pushSubscriptionRepo: pushSubscriptionRepo,
+		keys:                 keys,
+		sender:               sender,
+	}
+}
+
+// check PushService interface implementation on compile time.
+var _ PushService = (*pushService)(nil)
+
+// check ReminderHandler interface implementation on compile time.
+var _ ReminderHandler = (*pushService)(nil)
+
+func (s *pushService) PublicKey() (string, error) {
+	if s.keys == nil {
+		return "", domain.ErrPushDisabled
+	}
+
+	return s.keys.Public, nil
+}
+
+func (s *pushService) Subscribe(ctx context.Context, userID uint, subscription *domain.PushSubscription) (*domain.PushSubscription, error) {
+	if subscription == nil {
+		return nil, fmt.Errorf("no push subscription provided")
+	}
+	if s.keys == nil {
+		return nil, domain.ErrPushDisabled
+	}
+
+	err := (&webpush.Subscription{
+		Endpoint: subscription.Endpoint,
+		P256dh:   subscription.P256dh,
+		Auth:     subscription.Auth,
+	}).Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", domain.ErrInvalidPushSubscription, err)
+	}
+
+	subscription.UUID = s.GenerateUUIDHash("push")
+	subscription.UserID = userID
+	created, err := s.pushSubscriptionRepo.Upsert(ctx, subscription)
+	if err != nil {
+		log.Err(err).Msg("error saving push subscription")
+		return nil, fmt.Errorf("error saving push subscription: %w", err)
+	}
+
+	return created, nil
+}
+
+func (s *pushService) Unsubscribe(ctx context.Context, userID uint, uuid string) error {
+	err := s.pushSubscriptionRepo.Delete(ctx, userID, uuid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("push subscription not found: %w", domain.ErrPushSubscriptionNotFound)
+		}
+		log.Err(err).Msg("error deleting push subscription")
+		return fmt.Errorf("error deleting push subscription: %w", err)
+	}
+
+	return nil
+}
+
+func (s *pushService) Subscriptions(ctx context.Context, userID uint) ([]*domain.PushSubscription, error) {
+	subscriptions, err := s.pushSubscriptionRepo.All(ctx, userID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving push subscriptions")
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+// pushNotification is the payload of a push message, the service worker of the web app shows
+// it with showNotification.
+type pushNotification struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	URL   string `json:"url"`
+	Tag   string `json:"tag"`
+}
+
+func (s *pushService) HandleReminder(ctx context.Context, reminder *domain.Reminder) {
+	if s.sender == nil || len(reminder.Todos) == 0 {
+		return
+	}
+
+	subscriptions, err := s.pushSubscriptionRepo.All(ctx, reminder.UserID)
+	if err != nil {
+		log.Err(err).Uint("user_id", reminder.UserID).Msg("error retrieving push subscriptions")
+		return
+	}
+	if len(subscriptions) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(s.reminderNotification(reminder, time.Now()))
+	if err != nil {
+		log.Err(err).Msg("error encoding push notification")
+		return
+	}
+
+	// a reminder is pointless once the todos are due, overdue todos stay news for a while
+	ttl := domain.ReminderLead
+	if reminder.Kind == domain.ReminderOverdue {
+		ttl = domain.OverdueWindow
+	}
+
+	for _, subscription := range subscriptions {
+		err = s.sender.Send(ctx, &webpush.Message{
+			Subscription: &webpush.Subscription{
+				Endpoint: subscription.Endpoint,
+				P256dh:   subscription.P256dh,
+				Auth:     subscription.Auth,
+			},
+			Payload: payload,
+			TTL:     ttl,
+			Urgency: webpush.UrgencyHigh,
+			Topic:   pushTopics[reminder.Kind],
+		})
+
+		result := metrics.PushSent
+		switch {
+		case errors.Is(err, webpush.ErrGone), errors.Is(err, webpush.ErrInvalidSubscription):
+			result = metrics.PushGone
+			if err = s.pushSubscriptionRepo.DeleteEndpoint(ctx, subscription.Endpoint); err != nil {
+				log.Err(err).Str("subscription", subscription.UUID).Msg("error deleting expired push subscription")
+			}
+		case err != nil:
+			result = metrics.PushFailed
+			log.Warn().Err(err).Str("subscription", subscription.UUID).Msg("error sending push notification")
+		}
+		metrics.PushMessages.WithLabelValues(result).Inc()
+	}
+}
+
+// reminderNotification names the todo of a single reminder, larger reminders list as many
+// titles as fit.
+func (s *pushService) reminderNotification(reminder *domain.Reminder, now time.Time) *pushNotification {
+	notification := &pushNotification{
+		URL: strings.TrimRight(s.Config.GetAppURL(), "/"),
+		Tag: pushTopics[reminder.Kind],
+	}
+
+	if len(reminder.Todos) == 1 {
+		todo := reminder.Todos[0]
+		notification.Title = todo.Title
+		if reminder.Kind == domain.ReminderOverdue {
+			notification.Body = "Overdue"
+		} else {
+			location := reminder.Location
+			if location == nil {
+				location = time.UTC
+			}
+			notification.Body = fmt.Sprintf("Due at %s, in %d minutes",
+				todo.DueDate.In(location).Format("15:04"), int(math.Ceil(todo.DueDate.Sub(now).Minutes())))
+		}
+		return notification
+	}
+
+	notification.Title = fmt.Sprintf("%d todos are due soon", len(reminder.Todos))
+	if reminder.Kind == domain.ReminderOverdue {
+		notification.Title = fmt.Sprintf("%d todos are overdue", len(reminder.Todos))
+	}
+	var body strings.Builder
+	for _, todo := range reminder.Todos {
+		if body.Len()+len(todo.Title) > pushBodyLength {
+			body.WriteString("\n…")
+			break
+		}
+		if body.Len() > 0 {
+			body.WriteString("\n")
+		}
+		body.WriteString(todo.Title)
+	}
+	notification.Body = body.String()
+
+	return notification
+}
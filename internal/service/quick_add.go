@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+)
+
+// QuickAddService creates todos from a single line of text like "Pay rent tomorrow 5pm #finance
+// !high", dates and times are read in the timezone of the user.
+type QuickAddService interface {
+	// QuickAdd parses the text and creates the todo like any other, the rules of the user apply
+	// to it. A dry run only returns how the text was read.
+	QuickAdd(ctx context.Context, userID uint, quickAddCreate *domain.QuickAddCreate) (*domain.QuickAddResult, error)
+}
+
+type quickAddService struct {
+	*BaseService
+
+	todoService TodoService
+
+	userRepo repo.UserRepo
+}
+
+func NewQuickAddService(base *BaseService, todoService TodoService, userRepo repo.UserRepo) *quickAddService {
+	return &quickAddService{
+		BaseService: base,
+		todoService: todoService,
+		userRepo:    userRepo,
+	}
+}
+
+// check QuickAddService interface implementation on compile time.
+var _ QuickAddService = (*quickAddService)(nil)
+
+func (s *quickAddService) QuickAdd(ctx context.Context, userID uint, quickAddCreate *domain.QuickAddCreate) (*domain.QuickAddResult, error) {
+	if quickAddCreate == nil {
+		return nil, fmt.Errorf("no quick add provided")
+	}
+
+	quickAdd, err := domain.ParseQuickAdd(quickAddCreate.Text, time.Now(), userLocation(ctx, s.userRepo, userID))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.QuickAddResult{QuickAdd: quickAdd, DryRun: quickAddCreate.DryRun}
+	if quickAddCreate.DryRun {
+		return result, nil
+	}
+
+	todoCreate := quickAdd.TodoCreate()
+	todoCreate.ListUUID = quickAddCreate.ListUUID
+	todoCreate.Source = domain.CaptureSourceApp
+	if result.Todo, err = s.todoService.Create(ctx, userID, todoCreate); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,352 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/audit"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/password"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+// RecoveryService recovers accounts through trusted contacts, for users that lost their password
+// and can't rely on their email. The user names a few other users and how many of them have to
+// approve. Anyone can start a recovery and keeps its token, the contacts check with the user
+// before they approve it. Even once approved it can only be completed after RecoveryDelay, the
+// user is emailed and can cancel it in the meantime.
+type RecoveryService interface {
+	// Settings returns the trusted contacts of the user and the pending recovery requests.
+	Settings(ctx context.Context, userID uint) (*domain.RecoverySettings, error)
+	// Configure replaces the trusted contacts of the user, no contacts turn recovery off.
+	Configure(ctx context.Context, userID uint, setup *domain.RecoverySetup) (*domain.RecoverySettings, error)
+	// Cancel cancels the pending recovery requests of the user.
+	Cancel(ctx context.Context, userID uint) error
+
+	// Requests returns the pending requests of the accounts the user is a trusted contact of.
+	Requests(ctx context.Context, contactID uint) ([]*domain.RecoveryRequest, error)
+	Approve(ctx context.Context, contactID uint, uuid string) (*domain.RecoveryRequest, error)
+
+	// Start starts the recovery of an account. The ticket looks the same for accounts that don't
+	// exist or have no trusted contacts, so it doesn't tell which accounts do.
+	Start(ctx context.Context, start *domain.RecoveryStart) (*domain.RecoveryTicket, error)
+	// Complete sets a new password with the token of an approved request and signs the account
+	// out everywhere.
+	Complete(ctx context.Context, completion *domain.RecoveryCompletion) error
+}
+
+type recoveryService struct {
+	*BaseService
+
+	authService         AuthService
+	notificationService NotificationService
+	securityEvents      *SecurityEvents
+
+	txManager      repo.TxManager
+	userRepo       repo.UserRepo
+	userRegionRepo repo.UserRegionRepo
+	recoveryRepo   repo.RecoveryRepo
+	oauthRepo      repo.OAuthRepo
+}
+
+func NewRecoveryService(
+	base *BaseService,
+	authService AuthService,
+	notificationService NotificationService,
+	securityEvents *SecurityEvents,
+	txManager repo.TxManager,
+	userRepo repo.UserRepo,
+	userRegionRepo repo.UserRegionRepo,
+	recoveryRepo repo.RecoveryRepo,
+	oauthRepo repo.OAuthRepo,
+) *recoveryService {
+	return &recoveryService{
+		BaseService:         base,
+		authService:         authService,
+		notificationService: notificationService,
+		securityEvents:      securityEvents,
+		txManager:           txManager,
+		userRepo:            userRepo,
+		userRegionRepo:      userRegionRepo,
+		recoveryRepo:        recoveryRepo,
+		oauthRepo:           oauthRepo,
+	}
+}
+
+// check RecoveryService interface implementation on compile time.
+var _ RecoveryService = (*recoveryService)(nil)
+
+func (s *recoveryService) Settings(ctx context.Context, userID uint) (*domain.RecoverySettings, error) {
+	settings, err := s.recoveryRepo.Settings(ctx, userID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving recovery settings")
+		return nil, err
+	}
+
+	if settings.Requests, err = s.recoveryRepo.Pending(ctx, userID, time.Now()); err != nil {
+		log.Err(err).Msg("error retrieving recovery requests")
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+func (s *recoveryService) Configure(ctx context.Context, userID uint, setup *domain.RecoverySetup) (*domain.RecoverySettings, error) {
+	user, err := s.userRepo.ByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrUserNotFound
+		}
+		log.Err(err).Msg("error retrieving user")
+		return nil, err
+	}
+	if user.IsChild() {
+		return nil, domain.ErrChildAccountRecovery
+	}
+
+	withPassword, err := s.userRepo.ByEmailWithPassword(ctx, user.Email)
+	if err != nil {
+		log.Err(err).Msg("error retrieving user password")
+		return nil, err
+	}
+	if _, err = s.comparePassword(ctx, withPassword.Password, setup.Password); err != nil {
+		if errors.Is(err, password.ErrMismatch) {
+			return nil, domain.ErrIncorrectPassword
+		}
+		log.Err(err).Msg("error comparing password")
+		return nil, err
+	}
+
+	contactIDs, err := s.contactIDs(ctx, user, setup.ContactEmails)
+	if err != nil {
+		return nil, err
+	}
+	if len(contactIDs) > 0 && (setup.Threshold < 1 || setup.Threshold > len(contactIDs)) {
+		return nil, domain.ErrInvalidRecoveryThreshold
+	}
+
+	if err = s.recoveryRepo.SaveSettings(ctx, user.ID, setup.Threshold, contactIDs); err != nil {
+		log.Err(err).Msg("error saving recovery settings")
+		return nil, fmt.Errorf("error saving recovery settings: %w", err)
+	}
+
+	return s.Settings(ctx, user.ID)
+}
+
+// contactIDs resolves the emails of trusted contacts to adult users of the same region, the
+// emails are looked up in the region of the user.
+func (s *recoveryService) contactIDs(ctx context.Context, user *domain.User, emails []string) ([]uint, error) {
+	ids := make([]uint, 0, len(emails))
+	seen := make(map[string]bool, len(emails))
+	for _, email := range emails {
+		email = strings.ToLower(strings.TrimSpace(email))
+		if seen[email] {
+			continue
+		}
+		seen[email] = true
+
+		contact, err := s.userRepo.ByEmail(ctx, email)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, fmt.Errorf("%s: %w", email, domain.ErrTrustedContactNotFound)
+			}
+			log.Err(err).Msg("error retrieving user by email")
+			return nil, err
+		}
+		if contact.ID == user.ID {
+			return nil, domain.ErrTrustedContactSelf
+		}
+		if contact.IsChild() {
+			return nil, fmt.Errorf("%s: %w", email, domain.ErrTrustedContactChild)
+		}
+		ids = append(ids, contact.ID)
+	}
+	if len(ids) > domain.MaxTrustedContacts {
+		return nil, domain.ErrTooManyTrustedContacts
+	}
+
+	return ids, nil
+}
+
+func (s *recoveryService) Cancel(ctx context.Context, userID uint) error {
+	if err := s.recoveryRepo.Cancel(ctx, userID, time.Now()); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrRecoveryNotFound
+		}
+		log.Err(err).Msg("error cancelling recovery requests")
+		return fmt.Errorf("error cancelling recovery requests: %w", err)
+	}
+
+	return nil
+}
+
+func (s *recoveryService) Requests(ctx context.Context, contactID uint) ([]*domain.RecoveryRequest, error) {
+	requests, err := s.recoveryRepo.ForContact(ctx, contactID, time.Now())
+	if err != nil {
+		log.Err(err).Msg("error retrieving recovery requests")
+		return nil, err
+	}
+
+	return requests, nil
+}
+
+func (s *recoveryService) Approve(ctx context.Context, contactID uint, uuid string) (*domain.RecoveryRequest, error) {
+	request, err := s.recoveryRepo.Approve(ctx, uuid, contactID, time.Now())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrRecoveryNotFound
+		}
+		log.Err(err).Msg("error approving recovery request")
+		return nil, fmt.Errorf("error approving recovery request: %w", err)
+	}
+	log.Info().Str("request", request.UUID).Uint("contact_id", contactID).Msg("approved recovery request")
+
+	return request, nil
+}
+
+func (s *recoveryService) Start(ctx context.Context, start *domain.RecoveryStart) (*domain.RecoveryTicket, error) {
+	userRegion, err := s.userRegionRepo.Region(ctx, loginKey("email", start.Email))
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Err(err).Msg("error retrieving user region")
+		return nil, err
+	}
+	if !s.servesRegion(userRegion) {
+		log.Error().Str("region", userRegion).Msg("user region is not served by this instance")
+		return nil, fmt.Errorf("region %q: %w", userRegion, domain.ErrUnknownRegion)
+	}
+	ctx = region.WithRegion(ctx, userRegion)
+	audit.SetRegion(ctx, userRegion)
+
+	token, err := generateToken(domain.RecoveryTokenPrefix)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	ticket := &domain.RecoveryTicket{
+		Token:     token,
+		Region:    userRegion,
+		ReadyAt:   now.Add(domain.RecoveryDelay),
+		ExpiresAt: now.Add(domain.RecoveryExpiration),
+	}
+
+	user, err := s.userRepo.ByEmail(ctx, start.Email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ticket, nil
+		}
+		log.Err(err).Msg("error retrieving user by email")
+		return nil, err
+	}
+	settings, err := s.recoveryRepo.Settings(ctx, user.ID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving recovery settings")
+		return nil, err
+	}
+	if settings.Threshold == 0 {
+		return ticket, nil
+	}
+	audit.SetUser(ctx, user.ID)
+
+	request, err := s.recoveryRepo.CreateRequest(ctx, &domain.RecoveryRequest{
+		UUID:      s.GenerateUUIDHash("recovery"),
+		UserID:    user.ID,
+		ReadyAt:   ticket.ReadyAt,
+		ExpiresAt: ticket.ExpiresAt,
+	}, hashToken(token))
+	if err != nil {
+		log.Err(err).Msg("error creating recovery request")
+		return nil, fmt.Errorf("error creating recovery request: %w", err)
+	}
+	log.Info().Str("user", user.UUID).Str("request", request.UUID).Msg("started account recovery")
+
+	s.notifyStart(ctx, user, settings, request)
+
+	return ticket, nil
+}
+
+// notifyStart emails the user and the trusted contacts about a new request. Failures are only
+// logged, the request can't be completed before the delay anyway.
+func (s *recoveryService) notifyStart(
+	ctx context.Context, user *domain.User, settings *domain.RecoverySettings, request *domain.RecoveryRequest,
+) {
+	if err := s.notificationService.SendRecoveryStarted(ctx, user, request, settings.Threshold); err != nil {
+		log.Err(err).Str("user", user.UUID).Msg("error sending recovery notice")
+	}
+
+	for _, contact := range settings.Contacts {
+		if contact.Email == "" {
+			continue
+		}
+		if err := s.notificationService.SendRecoveryApproval(ctx, contact, request); err != nil {
+			log.Err(err).Str("contact", contact.UUID).Msg("error sending recovery approval request")
+		}
+	}
+}
+
+func (s *recoveryService) Complete(ctx context.Context, completion *domain.RecoveryCompletion) error {
+	if !s.servesRegion(completion.Region) {
+		return fmt.Errorf("region %q: %w", completion.Region, domain.ErrUnknownRegion)
+	}
+	ctx = region.WithRegion(ctx, completion.Region)
+	audit.SetRegion(ctx, completion.Region)
+
+	request, err := s.recoveryRepo.ByToken(ctx, hashToken(completion.Token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrInvalidRecoveryToken
+		}
+		log.Err(err).Msg("error retrieving recovery request")
+		return err
+	}
+	audit.SetUser(ctx, request.UserID)
+
+	if err = request.Completable(time.Now()); err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.ByID(ctx, request.UserID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving user")
+		return err
+	}
+	hashedPassword, err := s.hashPassword(ctx, completion.NewPassword)
+	if err != nil {
+		log.Err(err).Msg("error generating hash password")
+		return err
+	}
+
+	// like a password change the sessions are revoked first, whoever lost the account may still
+	// be signed in somewhere
+	now := time.Now().Truncate(time.Microsecond)
+	if err = s.authService.RevokeTokens(ctx, user, now); err != nil {
+		return err
+	}
+	err = s.txManager.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.recoveryRepo.Complete(ctx, request, now); err != nil {
+			return err
+		}
+		if err := s.userRepo.UpdatePassword(ctx, user.ID, hashedPassword); err != nil {
+			return err
+		}
+
+		return s.oauthRepo.DeleteGrants(ctx, user.ID)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrInvalidRecoveryToken
+		}
+		log.Err(err).Msg("error completing account recovery")
+		return fmt.Errorf("error completing account recovery: %w", err)
+	}
+	s.securityEvents.Publish(ctx, domain.EventSecurityTokenRevoked, user, map[string]string{"reason": "account_recovered"})
+	log.Info().Str("user", user.UUID).Str("request", request.UUID).Msg("recovered account")
+
+	return nil
+}
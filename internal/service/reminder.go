@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/metrics"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+)
+
+const (
+	dueReminderBatchSize = 200
+	// reminderConcurrency is how many users are reminded at once, handlers call out to push
+	// services so one slow user shouldn't hold up the others.
+	reminderConcurrency = 8
+)
+
+// ReminderHandler is notified of the todos of a user that are due soon or overdue. Reminders of different
+// users are handled concurrently.
+type ReminderHandler interface {
+	HandleReminder(ctx context.Context, reminder *domain.Reminder)
+}
+
+// ReminderService reminds users of their open todos shortly before they are due and once they
+// are overdue. A background worker claims the todos due within domain.ReminderLead and those that
+// became overdue and hands them to the subscribed handlers grouped by user, every todo is
+// reminded of once per due date.
+type ReminderService interface {
+	Subscribe(handler ReminderHandler)
+
+	// RemindDue reminds of the todos that are due soon or overdue, it is run by a background worker.
+	RemindDue(ctx context.Context) error
+}
+
+type reminderService struct {
+	*BaseService
+
+	todoRepo repo.TodoRepo
+	userRepo repo.UserRepo
+
+	handlers []ReminderHandler
+}
+
+func NewReminderService(base *BaseService, todoRepo repo.TodoRepo, userRepo repo.UserRepo) *reminderService {
+	return &reminderService{
+		BaseService: base,
+		todoRepo:    todoRepo,
+		userRepo:    userRepo,
+	}
+}
+
+// check ReminderService interface implementation on compile time.
+var _ ReminderService = (*reminderService)(nil)
+
+// Subscribe registers a handler, it is not safe to call once the worker is running.
+func (s *reminderService) Subscribe(handler ReminderHandler) {
+	s.handlers = append(s.handlers, handler)
+}
+
+func (s *reminderService) RemindDue(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	dueSoon, err := s.todoRepo.Remind(ctx, now, now.Add(domain.ReminderLead), dueReminderBatchSize)
+	if err != nil {
+		return fmt.Errorf("error claiming due todos: %w", err)
+	}
+	overdue, err := s.todoRepo.Overdue(ctx, now.Add(-domain.OverdueWindow), now, dueReminderBatchSize)
+	if err != nil {
+		return fmt.Errorf("error claiming overdue todos: %w", err)
+	}
+	metrics.WorkerQueueDepth.WithLabelValues("reminders", metrics.RegionLabel(region.FromContext(ctx))).Set(float64(len(dueSoon) + len(overdue)))
+
+	// the todos are claimed already, without handlers there is nobody to remind
+	if len(s.handlers) == 0 {
+		return nil
+	}
+
+	reminders := append(groupReminders(domain.ReminderDueSoon, dueSoon), groupReminders(domain.ReminderOverdue, overdue)...)
+	locations := make(map[uint]*time.Location)
+	for _, reminder := range reminders {
+		if _, ok := locations[reminder.UserID]; !ok {
+			locations[reminder.UserID] = userLocation(ctx, s.userRepo, reminder.UserID)
+		}
+		reminder.Location = locations[reminder.UserID]
+	}
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, reminderConcurrency)
+	for _, reminder := range reminders {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			for _, handler := range s.handlers {
+				handler.HandleReminder(ctx, reminder)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return nil
+}
+
+// groupReminders returns a reminder for every user with todos, in the order of their first todo.
+func groupReminders(kind domain.ReminderKind, todos []*domain.Todo) []*domain.Reminder {
+	var reminders []*domain.Reminder
+	byUser := make(map[uint]*domain.Reminder)
+	for _, todo := range todos {
+		reminder, ok := byUser[todo.UserID]
+		if !ok {
+			reminder = &domain.Reminder{Kind: kind, UserID: todo.UserID}
+			byUser[todo.UserID] = reminder
+			reminders = append(reminders, reminder)
+		}
+		reminder.Todos = append(reminder.Todos, todo)
+	}
+
+	return reminders
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+// RuleService manages the rules that tag and sort todos as they are created and changed. The
+// rules of a user are evaluated in their order, see domain.EvaluateRules.
+type RuleService interface {
+	Create(ctx context.Context, userID uint, ruleCreate *domain.RuleCreate) (*domain.Rule, error)
+	Update(ctx context.Context, userID uint, uuid string, ruleCreate *domain.RuleCreate) (*domain.Rule, error)
+	Delete(ctx context.Context, userID uint, uuid string) error
+	// Reorder sets the evaluation order, uuids has to list every rule of the user once.
+	Reorder(ctx context.Context, userID uint, uuids []string) ([]*domain.Rule, error)
+	ByUUID(ctx context.Context, userID uint, uuid string) (*domain.Rule, error)
+	All(ctx context.Context, userID uint) ([]*domain.Rule, error)
+
+	// Evaluate runs the rules of the user against a todo without changing anything. A list the
+	// user can no longer add todos to is left out of the outcome.
+	Evaluate(ctx context.Context, userID uint, subject *domain.RuleSubject) (*domain.RuleOutcome, error)
+	// CheckExpression checks an expression for a rule before it is saved and evaluates it against
+	// the sample todo when there is one, see domain.CheckRuleExpression.
+	CheckExpression(ctx context.Context, target domain.RuleExpressionTarget, source string, sample *domain.RuleSubject) *domain.RuleExpressionCheck
+}
+
+type ruleService struct {
+	*BaseService
+
+	listService ListService
+
+	ruleRepo repo.RuleRepo
+}
+
+func NewRuleService(base *BaseService, listService ListService, ruleRepo repo.RuleRepo) *ruleService {
+	return &ruleService{
+		BaseService: base,
+		listService: listService,
+		ruleRepo:    ruleRepo,
+	}
+}
+
+// check RuleService interface implementation on compile time.
+var _ RuleService = (*ruleService)(nil)
+
+func (s *ruleService) Create(ctx context.Context, userID uint, ruleCreate *domain.RuleCreate) (*domain.Rule, error) {
+	rule, err := s.rule(ctx, userID, ruleCreate)
+	if err != nil {
+		return nil, err
+	}
+	rule.UUID = s.GenerateUUIDHash("rule")
+
+	created, err := s.ruleRepo.Create(ctx, rule)
+	if err != nil {
+		log.Err(err).Msg("error creating rule")
+		return nil, fmt.Errorf("error creating rule: %w", err)
+	}
+
+	return created, nil
+}
+
+func (s *ruleService) Update(ctx context.Context, userID uint, uuid string, ruleCreate *domain.RuleCreate) (*domain.Rule, error) {
+	rule, err := s.rule(ctx, userID, ruleCreate)
+	if err != nil {
+		return nil, err
+	}
+	rule.UUID = uuid
+
+	updated, err := s.ruleRepo.Update(ctx, rule)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("rule not found: %w", domain.ErrRuleNotFound)
+		}
+		log.Err(err).Msg("error updating rule")
+		return nil, fmt.Errorf("error updating rule: %w", err)
+	}
+
+	return updated, nil
+}
+
+func (s *ruleService) Delete(ctx context.Context, userID uint, uuid string) error {
+	if _, err := s.ByUUID(ctx, userID, uuid); err != nil {
+		return err
+	}
+
+	if err := s.ruleRepo.Delete(ctx, userID, uuid); err != nil {
+		log.Err(err).Msg("error deleting rule")
+		return fmt.Errorf("error deleting rule: %w", err)
+	}
+
+	return nil
+}
+
+func (s *ruleService) Reorder(ctx context.Context, userID uint, uuids []string) ([]*domain.Rule, error) {
+	rules, err := s.All(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(uuids) != len(rules) {
+		return nil, domain.ErrInvalidRuleSet
+	}
+
+	byUUID := make(map[string]*domain.Rule, len(rules))
+	for _, rule := range rules {
+		byUUID[rule.UUID] = rule
+	}
+
+	ids := make([]uint, 0, len(uuids))
+	for _, uuid := range uuids {
+		rule, ok := byUUID[uuid]
+		if !ok {
+			return nil, domain.ErrInvalidRuleSet
+		}
+		// listing a rule twice leaves another one out
+		delete(byUUID, uuid)
+		ids = append(ids, rule.ID)
+	}
+
+	if err = s.ruleRepo.Reorder(ctx, userID, ids); err != nil {
+		log.Err(err).Msg("error reordering rules")
+		return nil, fmt.Errorf("error reordering rules: %w", err)
+	}
+
+	return s.All(ctx, userID)
+}
+
+func (s *ruleService) ByUUID(ctx context.Context, userID uint, uuid string) (*domain.Rule, error) {
+	rule, err := s.ruleRepo.ByUUID(ctx, userID, uuid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("rule not found: %w", domain.ErrRuleNotFound)
+		}
+		log.Err(err).Msg("error retrieving rule")
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (s *ruleService) All(ctx context.Context, userID uint) ([]*domain.Rule, error) {
+	rules, err := s.ruleRepo.All(ctx, userID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving rules")
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func (s *ruleService) Evaluate(ctx context.Context, userID uint, subject *domain.RuleSubject) (*domain.RuleOutcome, error) {
+	rules, err := s.All(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	outcome := domain.EvaluateRules(rules, subject)
+	for _, ruleErr := range outcome.Errors {
+		// the expressions compiled when the rule was saved, these are runtime errors like an
+		// exceeded limit, which the todo shouldn't fail on
+		log.Warn().Err(ruleErr.Err).Str("rule", ruleErr.Rule.UUID).Str("target", string(ruleErr.Target)).
+			Msg("error evaluating rule expression")
+	}
+	if outcome.ListUUID == "" {
+		return outcome, nil
+	}
+
+	// the list may have been deleted or unshared since the rule was saved, which shouldn't keep
+	// todos from being created
+	access, err := s.listService.Access(ctx, userID, outcome.ListUUID)
+	switch {
+	case errors.Is(err, domain.ErrListNotFound):
+		log.Warn().Str("list_uuid", outcome.ListUUID).Msg("rule list not found, ignoring it")
+		outcome.ListUUID = ""
+	case err != nil:
+		return nil, err
+	case !access.Role.CanWrite():
+		log.Warn().Str("list_uuid", outcome.ListUUID).Msg("rule list is read only, ignoring it")
+		outcome.ListUUID = ""
+	}
+
+	return outcome, nil
+}
+
+func (s *ruleService) CheckExpression(
+	_ context.Context, target domain.RuleExpressionTarget, source string, sample *domain.RuleSubject,
+) *domain.RuleExpressionCheck {
+	return domain.CheckRuleExpression(target, source, sample)
+}
+
+// rule checks a rule definition and turns it into a rule of the user.
+func (s *ruleService) rule(ctx context.Context, userID uint, ruleCreate *domain.RuleCreate) (*domain.Rule, error) {
+	if ruleCreate == nil {
+		return nil, fmt.Errorf("no rule details provided")
+	}
+
+	condition := strings.TrimSpace(ruleCreate.Condition)
+	if condition == "" && (ruleCreate.Field == "" || ruleCreate.Operator == "") {
+		return nil, domain.ErrRuleNoMatch
+	}
+	field, operator := ruleCreate.Field, ruleCreate.Operator
+	if condition != "" {
+		// the condition replaces the field and operator, keeping them would suggest otherwise
+		field, operator = "", ""
+		if _, err := domain.CompileRuleExpression(domain.RuleTargetCondition, condition); err != nil {
+			return nil, err
+		}
+	}
+
+	computed := domain.RuleComputed{
+		Title:       strings.TrimSpace(ruleCreate.Computed.Title),
+		Description: strings.TrimSpace(ruleCreate.Computed.Description),
+		Priority:    strings.TrimSpace(ruleCreate.Computed.Priority),
+		Tags:        strings.TrimSpace(ruleCreate.Computed.Tags),
+	}
+	expressions := []struct {
+		target domain.RuleExpressionTarget
+		source string
+	}{
+		{domain.RuleTargetTitle, computed.Title},
+		{domain.RuleTargetDescription, computed.Description},
+		{domain.RuleTargetPriority, computed.Priority},
+		{domain.RuleTargetTags, computed.Tags},
+	}
+	for _, expression := range expressions {
+		if expression.source == "" {
+			continue
+		}
+		if _, err := domain.CompileRuleExpression(expression.target, expression.source); err != nil {
+			return nil, err
+		}
+	}
+
+	tags := domain.NormalizeTagNames(ruleCreate.Tags)
+	if len(tags) == 0 && ruleCreate.Priority == nil && ruleCreate.ListUUID == "" && computed.Empty() {
+		return nil, domain.ErrRuleNoActions
+	}
+
+	if ruleCreate.ListUUID != "" {
+		access, err := s.listService.Access(ctx, userID, ruleCreate.ListUUID)
+		if err != nil {
+			return nil, err
+		}
+		if !access.Role.CanWrite() {
+			return nil, domain.ErrListReadOnly
+		}
+	}
+
+	return &domain.Rule{
+		UserID:    userID,
+		Name:      strings.TrimSpace(ruleCreate.Name),
+		Field:     field,
+		Operator:  operator,
+		Value:     ruleCreate.Value,
+		Source:    ruleCreate.Source,
+		Channel:   strings.TrimSpace(ruleCreate.Channel),
+		Condition: condition,
+		Tags:      tags,
+		Priority:  ruleCreate.Priority,
+		ListUUID:  ruleCreate.ListUUID,
+		Computed:  computed,
+		Stop:      ruleCreate.Stop,
+	}, nil
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/llm"
+	"github.com/meowmix1337/the_recipe_book/internal/metrics"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+// SearchService searches the todos of a user by keywords, or by meaning when an embedding model
+// is configured. Todos are embedded in the background after they were created or edited.
+type SearchService interface {
+	TodoEventHandler
+
+	SearchTodos(ctx context.Context, userID uint, query string, limit int) ([]*domain.TodoSearchResult, error)
+	// SemanticSearchTodos returns the todos closest in meaning to the query, it fails with
+	// ErrSemanticSearchDisabled when no embedding model is configured.
+	SemanticSearchTodos(ctx context.Context, userID uint, query string, limit int) ([]*domain.TodoSearchResult, error)
+
+	// EmbedDue embeds the todos that changed since they were last embedded, it is run by a
+	// background worker.
+	EmbedDue(ctx context.Context) error
+}
+
+const (
+	// embeddingBatchSize is how many todos are embedded with a single request to the model.
+	embeddingBatchSize = 16
+	// embeddingBackfillSize is how many todos without a current embedding are marked due at once,
+	// e.g. after semantic search was enabled or the model was changed.
+	embeddingBackfillSize = 500
+	// embeddingRetryInterval is when a failed embedding is retried, a todo is given up on after
+	// maxEmbeddingAttempts.
+	embeddingRetryInterval = 10 * time.Minute
+	maxEmbeddingAttempts   = 5
+)
+
+type searchService struct {
+	*BaseService
+
+	searchRepo    repo.SearchRepo
+	embeddingRepo repo.EmbeddingRepo
+
+	embedder llm.Embedder
+}
+
+func NewSearchService(base *BaseService, searchRepo repo.SearchRepo, embeddingRepo repo.EmbeddingRepo, embedder llm.Embedder) *searchService {
+	return &searchService{
+		BaseService:   base,
+		searchRepo:    searchRepo,
+		embeddingRepo: embeddingRepo,
+		embedder:      embedder,
+	}
+}
+
+// check SearchService interface implementation on compile time.
+var _ SearchService = (*searchService)(nil)
+
+func (s *searchService) SearchTodos(ctx context.Context, userID uint, query string, limit int) ([]*domain.TodoSearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, domain.ErrEmptySearchQuery
+	}
+
+	results, err := s.searchRepo.SearchTodos(ctx, userID, query, searchLimit(limit))
+	if err != nil {
+		log.Err(err).Msg("error searching todos")
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (s *searchService) SemanticSearchTodos(ctx context.Context, userID uint, query string, limit int) ([]*domain.TodoSearchResult, error) {
+	if !llm.EmbedderEnabled(s.embedder) {
+		return nil, domain.ErrSemanticSearchDisabled
+	}
+
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, domain.ErrEmptySearchQuery
+	}
+
+	vectors, err := s.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		if errors.Is(err, llm.ErrDisabled) {
+			return nil, domain.ErrSemanticSearchDisabled
+		}
+		log.Err(err).Msg("error embedding search query")
+		return nil, fmt.Errorf("error embedding search query: %w", err)
+	}
+
+	results, err := s.searchRepo.SemanticSearchTodos(ctx, userID, s.embedder.Name(), vectors[0], searchLimit(limit))
+	if err != nil {
+		log.Err(err).Msg("error searching todos by meaning")
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func searchLimit(limit int) int {
+	if limit <= 0 {
+		limit = domain.DefaultSearchLimit
+	}
+
+	return min(limit, domain.MaxSearchLimit)
+}
+
+// HandleTodoEvent has created and edited todos embedded, deleted todos drop their embedding.
+// Nothing is recorded while semantic search is disabled, the todos are backfilled once it is
+// enabled.
+func (s *searchService) HandleTodoEvent(ctx context.Context, event *domain.TodoEvent) {
+	if !llm.EmbedderEnabled(s.embedder) {
+		return
+	}
+
+	todo := event.Todo
+	var err error
+	switch event.Type {
+	case domain.EventTodoCreated, domain.EventTodoUpdated, domain.EventTodoMoved:
+		// there is nothing to embed in the ciphertext of an encrypted todo
+		if todo.Encrypted() {
+			return
+		}
+		err = s.embeddingRepo.MarkDue(ctx, todo.ID, todo.UserID, time.Now())
+	case domain.EventTodoDeleted:
+		err = s.embeddingRepo.Delete(ctx, todo.ID)
+	default:
+		return
+	}
+	if err != nil {
+		log.Err(err).Str("todo", todo.UUID).Msg("error updating todo embedding")
+	}
+}
+
+func (s *searchService) EmbedDue(ctx context.Context) error {
+	if !llm.EmbedderEnabled(s.embedder) {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	embeddings, err := s.embeddingRepo.Claim(ctx, now, now.Add(embeddingRetryInterval), maxEmbeddingAttempts, embeddingBatchSize)
+	if err != nil {
+		return fmt.Errorf("error claiming due embeddings: %w", err)
+	}
+	metrics.WorkerQueueDepth.WithLabelValues("todo_embeddings", metrics.RegionLabel(region.FromContext(ctx))).Set(float64(len(embeddings)))
+
+	// todos that were never embedded are picked up once the todos that changed are done
+	if len(embeddings) == 0 {
+		if _, err = s.embeddingRepo.Backfill(ctx, s.embedder.Name(), now, embeddingBackfillSize); err != nil {
+			return fmt.Errorf("error backfilling embeddings: %w", err)
+		}
+		return nil
+	}
+
+	// todos whose text didn't change, e.g. after their priority was changed, aren't embedded again
+	name := s.embedder.Name()
+	changed := make([]*domain.TodoEmbedding, 0, len(embeddings))
+	texts := make([]string, 0, len(embeddings))
+	for _, embedding := range embeddings {
+		contentHash := domain.EmbeddingContentHash(embedding.Text)
+		if embedding.Model == name && embedding.ContentHash == contentHash {
+			if err = s.embeddingRepo.Save(ctx, embedding, nil); err != nil {
+				log.Err(err).Uint("todo_id", embedding.TodoID).Msg("error saving todo embedding")
+			}
+			continue
+		}
+
+		embedding.Model = name
+		embedding.ContentHash = contentHash
+		changed = append(changed, embedding)
+		texts = append(texts, embedding.Text)
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	// a failed batch is retried with the claims
+	vectors, err := s.embedder.Embed(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("error embedding todos: %w", err)
+	}
+
+	for i, embedding := range changed {
+		if err = s.embeddingRepo.Save(ctx, embedding, vectors[i]); err != nil {
+			log.Err(err).Uint("todo_id", embedding.TodoID).Msg("error saving todo embedding")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+// SecureNoteService manages the secure notes of todos, secrets like a locker code that are
+// sealed at rest and left out of exports, search and notifications. Everyone who can read a
+// todo can reveal its note, setting and deleting it requires write access.
+type SecureNoteService interface {
+	Set(ctx context.Context, userID uint, todoUUID string, note string) (*domain.SecureNote, error)
+	Delete(ctx context.Context, userID uint, todoUUID string) error
+	// Reveal opens the note of a todo. It is only served by a POST, so every reveal is recorded
+	// in the audit log.
+	Reveal(ctx context.Context, userID uint, todoUUID string) (*domain.SecureNote, error)
+}
+
+type secureNoteService struct {
+	*BaseService
+
+	todoService TodoService
+
+	secureNoteRepo repo.SecureNoteRepo
+}
+
+func NewSecureNoteService(base *BaseService, todoService TodoService, secureNoteRepo repo.SecureNoteRepo) *secureNoteService {
+	return &secureNoteService{
+		BaseService:    base,
+		todoService:    todoService,
+		secureNoteRepo: secureNoteRepo,
+	}
+}
+
+// check SecureNoteService interface implementation on compile time.
+var _ SecureNoteService = (*secureNoteService)(nil)
+
+func (s *secureNoteService) Set(ctx context.Context, userID uint, todoUUID string, note string) (*domain.SecureNote, error) {
+	todo, err := s.todoService.Writable(ctx, userID, todoUUID)
+	if err != nil {
+		return nil, err
+	}
+	// the server would hold the secret of a todo that is meant to be unreadable to it
+	if todo.Encrypted() {
+		return nil, domain.ErrTodoEncrypted
+	}
+
+	sealed, err := s.seal(todo, note)
+	if err != nil {
+		log.Err(err).Msg("error sealing secure note")
+		return nil, fmt.Errorf("error sealing secure note: %w", err)
+	}
+
+	saved, err := s.secureNoteRepo.Save(ctx, &domain.SecureNote{TodoID: todo.ID, Sealed: sealed, UpdatedBy: userID})
+	if err != nil {
+		log.Err(err).Msg("error saving secure note")
+		return nil, fmt.Errorf("error saving secure note: %w", err)
+	}
+	saved.Note = note
+
+	return saved, nil
+}
+
+func (s *secureNoteService) Delete(ctx context.Context, userID uint, todoUUID string) error {
+	todo, err := s.todoService.Writable(ctx, userID, todoUUID)
+	if err != nil {
+		return err
+	}
+
+	if err = s.secureNoteRepo.Delete(ctx, todo.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrSecureNoteNotFound
+		}
+		log.Err(err).Msg("error deleting secure note")
+		return fmt.Errorf("error deleting secure note: %w", err)
+	}
+
+	return nil
+}
+
+func (s *secureNoteService) Reveal(ctx context.Context, userID uint, todoUUID string) (*domain.SecureNote, error) {
+	todo, err := s.todoService.ByUUID(ctx, userID, todoUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	note, err := s.secureNoteRepo.ByTodo(ctx, todo.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrSecureNoteNotFound
+		}
+		log.Err(err).Msg("error retrieving secure note")
+		return nil, err
+	}
+
+	if note.Note, err = s.open(todo, note.Sealed); err != nil {
+		log.Err(err).Str("todo_uuid", todo.UUID).Msg("error opening secure note")
+		return nil, domain.ErrSecureNoteUnsealed
+	}
+
+	return note, nil
+}
+
+// noteKey is the key the secure notes are sealed with, derived from JWT_SECRET.
+func (s *secureNoteService) noteKey() []byte {
+	sum := sha256.Sum256([]byte("secure-note:" + s.Config.GetJWTSecret()))
+	return sum[:]
+}
+
+// seal encrypts a note with AES-GCM, the nonce is prepended. The UUID of the todo is
+// authenticated with it, so a sealed note can't be moved to another todo.
+func (s *secureNoteService) seal(todo *domain.Todo, note string) ([]byte, error) {
+	gcm, err := newGCM(s.noteKey())
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, []byte(note), []byte(todo.UUID)), nil
+}
+
+func (s *secureNoteService) open(todo *domain.Todo, sealed []byte) (string, error) {
+	gcm, err := newGCM(s.noteKey())
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("sealed secure note is too short")
+	}
+
+	note, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(todo.UUID))
+	if err != nil {
+		return "", err
+	}
+
+	return string(note), nil
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/audit"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+// SecurityEventHandler is notified of security events. Handlers run synchronously within the
+// request, so they should only queue work and never fail it.
+type SecurityEventHandler interface {
+	HandleSecurityEvent(ctx context.Context, event *domain.SecurityEvent)
+}
+
+// SecurityEvents publishes the security events of the services to the subscribed handlers.
+type SecurityEvents struct {
+	handlers []SecurityEventHandler
+}
+
+func NewSecurityEvents() *SecurityEvents {
+	return &SecurityEvents{}
+}
+
+// Subscribe registers a handler, it must be called before the events are published.
+func (e *SecurityEvents) Subscribe(handler SecurityEventHandler) {
+	e.handlers = append(e.handlers, handler)
+}
+
+// Publish reports an event of user, the IP and user agent are taken from the audit entry of the
+// request.
+func (e *SecurityEvents) Publish(ctx context.Context, eventType domain.EventType, user *domain.User, details map[string]string) {
+	event := &domain.SecurityEvent{
+		Type:       eventType,
+		User:       user,
+		Details:    details,
+		OccurredAt: time.Now().UTC(),
+	}
+	if entry := audit.FromContext(ctx); entry != nil {
+		event.IP = entry.IP
+		event.UserAgent = entry.UserAgent
+		event.RequestID = entry.RequestID
+	}
+
+	for _, handler := range e.handlers {
+		handler.HandleSecurityEvent(ctx, event)
+	}
+}
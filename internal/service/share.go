@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/mail"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+const invitationTokenPrefix = "inv_"
+
+// ShareService shares lists with collaborators. The list owner invites users by email with a
+// viewer or editor role, the invitee accepts the emailed token to become a member.
+type ShareService interface {
+	Invite(ctx context.Context, userID uint, listUUID string, email string, role domain.ListRole) (*domain.ListInvitation, error)
+	Invitations(ctx context.Context, userID uint, listUUID string) ([]*domain.ListInvitation, error)
+	RevokeInvitation(ctx context.Context, userID uint, listUUID string, invitationUUID string) error
+	Accept(ctx context.Context, userID uint, token string) (*domain.SharedList, error)
+
+	Members(ctx context.Context, userID uint, listUUID string) ([]*domain.ListMember, error)
+	UpdateMember(ctx context.Context, userID uint, listUUID string, memberUUID string, role domain.ListRole) error
+	// RemoveMember removes a member from the list, members can also remove themselves to leave the list.
+	RemoveMember(ctx context.Context, userID uint, listUUID string, memberUUID string) error
+	SharedLists(ctx context.Context, userID uint) ([]*domain.SharedList, error)
+}
+
+type shareService struct {
+	*BaseService
+
+	listService      ListService
+	householdService HouseholdService
+
+	userRepo           repo.UserRepo
+	listRepo           repo.ListRepo
+	listMemberRepo     repo.ListMemberRepo
+	listInvitationRepo repo.ListInvitationRepo
+
+	sender mail.Sender
+}
+
+func NewShareService(
+	base *BaseService,
+	listService ListService,
+	householdService HouseholdService,
+	userRepo repo.UserRepo,
+	listRepo repo.ListRepo,
+	listMemberRepo repo.ListMemberRepo,
+	listInvitationRepo repo.ListInvitationRepo,
+	sender mail.Sender,
+) *shareService {
+	return &shareService{
+		BaseService:        base,
+		listService:        listService,
+		householdService:   householdService,
+		userRepo:           userRepo,
+		listRepo:           listRepo,
+		listMemberRepo:     listMemberRepo,
+		listInvitationRepo: listInvitationRepo,
+		sender:             sender,
+	}
+}
+
+// check ShareService interface implementation on compile time.
+var _ ShareService = (*shareService)(nil)
+
+func (s *shareService) Invite(ctx context.Context, userID uint, listUUID string, email string, role domain.ListRole) (*domain.ListInvitation, error) {
+	if err := s.householdService.Permit(ctx, userID, domain.ParentalActionShare); err != nil {
+		return nil, err
+	}
+
+	list, err := s.ownedList(ctx, userID, listUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, err := s.userRepo.ByID(ctx, userID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving list owner")
+		return nil, err
+	}
+
+	email = strings.ToLower(strings.TrimSpace(email))
+	if strings.EqualFold(owner.Email, email) {
+		return nil, domain.ErrAlreadyListMember
+	}
+
+	token, err := generateToken(invitationTokenPrefix)
+	if err != nil {
+		log.Err(err).Msg("error generating invitation token")
+		return nil, err
+	}
+
+	invitation, err := s.listInvitationRepo.Create(ctx, &domain.ListInvitation{
+		UUID:      s.GenerateUUIDHash("invitation"),
+		ListID:    list.ID,
+		InviterID: userID,
+		Email:     email,
+		Role:      role,
+		ExpiresAt: time.Now().UTC().Add(domain.ListInvitationExpiration),
+	}, hashToken(token))
+	if err != nil {
+		log.Err(err).Msg("error creating list invitation")
+		return nil, fmt.Errorf("error creating list invitation: %w", err)
+	}
+
+	acceptURL := fmt.Sprintf("%s/invitations/accept?token=%s", strings.TrimRight(s.Config.GetAppURL(), "/"), url.QueryEscape(token))
+	err = s.sender.Send(ctx, &mail.Message{
+		To:      []string{email},
+		Subject: fmt.Sprintf("You have been invited to %s", list.Name),
+		Text: fmt.Sprintf("%s invited you to the list %q as %s.\n\nAccept the invitation: %s\n\nThe invitation expires on %s.\n",
+			owner.Email, list.Name, role, acceptURL, invitation.ExpiresAt.Format("Jan 2, 2006")),
+	})
+	if err != nil {
+		log.Err(err).Msg("error sending list invitation")
+		return nil, fmt.Errorf("error sending list invitation: %w", err)
+	}
+
+	return invitation, nil
+}
+
+func (s *shareService) Invitations(ctx context.Context, userID uint, listUUID string) ([]*domain.ListInvitation, error) {
+	list, err := s.ownedList(ctx, userID, listUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	invitations, err := s.listInvitationRepo.Pending(ctx, list.ID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving list invitations")
+		return nil, err
+	}
+
+	return invitations, nil
+}
+
+func (s *shareService) RevokeInvitation(ctx context.Context, userID uint, listUUID string, invitationUUID string) error {
+	invitations, err := s.Invitations(ctx, userID, listUUID)
+	if err != nil {
+		return err
+	}
+
+	for _, invitation := range invitations {
+		if invitation.UUID != invitationUUID {
+			continue
+		}
+
+		if err = s.listInvitationRepo.Delete(ctx, invitation.ListID, invitationUUID); err != nil {
+			log.Err(err).Msg("error revoking list invitation")
+			return err
+		}
+		return nil
+	}
+
+	return domain.ErrInvitationNotFound
+}
+
+func (s *shareService) Accept(ctx context.Context, userID uint, token string) (*domain.SharedList, error) {
+	invitation, err := s.listInvitationRepo.ByHash(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrInvitationNotFound
+		}
+		log.Err(err).Msg("error retrieving list invitation")
+		return nil, err
+	}
+
+	if invitation.Accepted() {
+		return nil, domain.ErrInvitationNotFound
+	}
+	if !time.Now().Before(invitation.ExpiresAt) {
+		return nil, domain.ErrInvitationExpired
+	}
+
+	user, err := s.userRepo.ByID(ctx, userID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving user")
+		return nil, err
+	}
+
+	// the token alone is not enough, it must be accepted by the invited account
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		return nil, domain.ErrInvitationEmailMismatch
+	}
+
+	list, err := s.listRepo.ByID(ctx, invitation.ListID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("list not found: %w", domain.ErrListNotFound)
+		}
+		log.Err(err).Msg("error retrieving list")
+		return nil, err
+	}
+
+	if list.UserID == userID {
+		return nil, domain.ErrAlreadyListMember
+	}
+
+	if err = s.listInvitationRepo.Accept(ctx, invitation, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// accepted by a concurrent request
+			return nil, domain.ErrInvitationNotFound
+		}
+		log.Err(err).Msg("error accepting list invitation")
+		return nil, err
+	}
+
+	return &domain.SharedList{List: list, Role: invitation.Role}, nil
+}
+
+func (s *shareService) Members(ctx context.Context, userID uint, listUUID string) ([]*domain.ListMember, error) {
+	access, err := s.listService.Access(ctx, userID, listUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.listMemberRepo.Members(ctx, access.List.ID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving list members")
+		return nil, err
+	}
+
+	return members, nil
+}
+
+func (s *shareService) UpdateMember(ctx context.Context, userID uint, listUUID string, memberUUID string, role domain.ListRole) error {
+	list, err := s.ownedList(ctx, userID, listUUID)
+	if err != nil {
+		return err
+	}
+
+	member, err := s.member(ctx, list.ID, memberUUID)
+	if err != nil {
+		return err
+	}
+
+	if err = s.listMemberRepo.Add(ctx, list.ID, member.UserID, role); err != nil {
+		log.Err(err).Msg("error updating list member")
+		return err
+	}
+
+	return nil
+}
+
+func (s *shareService) RemoveMember(ctx context.Context, userID uint, listUUID string, memberUUID string) error {
+	access, err := s.listService.Access(ctx, userID, listUUID)
+	if err != nil {
+		return err
+	}
+
+	member, err := s.member(ctx, access.List.ID, memberUUID)
+	if err != nil {
+		return err
+	}
+
+	if access.Role != domain.ListRoleOwner && member.UserID != userID {
+		return domain.ErrListOwnerOnly
+	}
+
+	if err = s.listMemberRepo.Remove(ctx, access.List.ID, member.UserID); err != nil {
+		log.Err(err).Msg("error removing list member")
+		return err
+	}
+
+	return nil
+}
+
+func (s *shareService) SharedLists(ctx context.Context, userID uint) ([]*domain.SharedList, error) {
+	shared, err := s.listMemberRepo.SharedLists(ctx, userID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving shared lists")
+		return nil, err
+	}
+
+	return shared, nil
+}
+
+// ownedList returns the list when the user owns it, collaborators get ErrListOwnerOnly.
+func (s *shareService) ownedList(ctx context.Context, userID uint, listUUID string) (*domain.List, error) {
+	access, err := s.listService.Access(ctx, userID, listUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if access.Role != domain.ListRoleOwner {
+		return nil, domain.ErrListOwnerOnly
+	}
+
+	return access.List, nil
+}
+
+func (s *shareService) member(ctx context.Context, listID uint, memberUUID string) (*domain.ListMember, error) {
+	members, err := s.listMemberRepo.Members(ctx, listID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving list members")
+		return nil, err
+	}
+
+	for _, member := range members {
+		if member.UserUUID == memberUUID {
+			return member, nil
+		}
+	}
+
+	return nil, domain.ErrListMemberNotFound
+}
@@ -0,0 +1,367 @@
+package service
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// signingKeyCacheTTL is how long the keys are kept in memory, a key created by another
+	// instance signs tokens here at most this much later.
+	signingKeyCacheTTL = time.Minute
+	// signingKeyRefreshInterval throttles reloading the keys for tokens with an unknown kid.
+	signingKeyRefreshInterval = 10 * time.Second
+)
+
+// SigningKeyService signs access tokens with rotating keys and resolves the key a token is
+// verified with. The active key signs new tokens, the keys it replaced keep verifying until
+// the tokens they signed have expired.
+type SigningKeyService interface {
+	// Sign signs the claims with the active key, the kid header names the key.
+	Sign(ctx context.Context, claims jwt.Claims) (string, error)
+	// Resolve returns the key the token is verified with, it is the key function of
+	// jwt.ParseWithClaims. Tokens of unknown or retired keys are rejected.
+	Resolve(ctx context.Context, token *jwt.Token) (interface{}, error)
+	// Verifiable returns the keys tokens are currently verified with, newest first.
+	Verifiable(ctx context.Context) ([]*domain.SigningKey, error)
+	// RotateDue creates a new active key once the active key is older than the rotation
+	// interval. Instances rotate once between them.
+	RotateDue(ctx context.Context) error
+}
+
+// signingKey is a key with its parsed keys, private is nil when the key can't be opened.
+type signingKey struct {
+	key     *domain.SigningKey
+	public  *ecdsa.PublicKey
+	private *ecdsa.PrivateKey
+}
+
+type signingKeyService struct {
+	*BaseService
+
+	signingKeyRepo repo.SigningKeyRepo
+
+	mu       sync.RWMutex
+	keys     []*signingKey
+	loadedAt time.Time
+}
+
+func NewSigningKeyService(base *BaseService, signingKeyRepo repo.SigningKeyRepo) *signingKeyService {
+	return &signingKeyService{
+		BaseService:    base,
+		signingKeyRepo: signingKeyRepo,
+	}
+}
+
+// check SigningKeyService interface implementation on compile time.
+var _ SigningKeyService = (*signingKeyService)(nil)
+
+func (s *signingKeyService) Sign(ctx context.Context, claims jwt.Claims) (string, error) {
+	active, err := s.active(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["kid"] = active.key.KID
+
+	return token.SignedString(active.private)
+}
+
+// active returns the newest key that can sign. Without one, e.g. on the first start or after
+// JWT_SECRET changed, a new key is created right away.
+func (s *signingKeyService) active(ctx context.Context) (*signingKey, error) {
+	keys, err := s.load(ctx, signingKeyCacheTTL)
+	if err != nil {
+		return nil, err
+	}
+	if key := firstSigning(keys); key != nil {
+		return key, nil
+	}
+
+	if _, err = s.rotate(ctx, time.Now()); err != nil {
+		return nil, err
+	}
+	if keys, err = s.load(ctx, 0); err != nil {
+		return nil, err
+	}
+	if key := firstSigning(keys); key != nil {
+		return key, nil
+	}
+
+	return nil, domain.ErrNoSigningKey
+}
+
+func firstSigning(keys []*signingKey) *signingKey {
+	for _, key := range keys {
+		if key.private != nil {
+			return key
+		}
+	}
+
+	return nil
+}
+
+func (s *signingKeyService) Resolve(ctx context.Context, token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return s.resolveLegacy(ctx, token)
+	}
+	if token.Method != jwt.SigningMethodES256 {
+		return nil, domain.ErrUnknownSigningKey
+	}
+
+	keys, err := s.load(ctx, signingKeyCacheTTL)
+	if err != nil {
+		return nil, err
+	}
+	key := findSigningKey(keys, kid)
+	if key == nil {
+		// the key may have been created by another instance since the keys were loaded
+		if keys, err = s.load(ctx, signingKeyRefreshInterval); err != nil {
+			return nil, err
+		}
+		key = findSigningKey(keys, kid)
+	}
+	if key == nil || key.key.Retired(time.Now()) {
+		return nil, domain.ErrUnknownSigningKey
+	}
+
+	return key.public, nil
+}
+
+// resolveLegacy verifies the HS256 tokens signed with JWT_SECRET before the first signing key
+// was created. They were issued with the usual lifetime before the oldest key, so they are all
+// expired a token lifetime after it.
+func (s *signingKeyService) resolveLegacy(ctx context.Context, token *jwt.Token) (interface{}, error) {
+	if token.Method != jwt.SigningMethodHS256 {
+		return nil, domain.ErrUnknownSigningKey
+	}
+
+	claims, ok := token.Claims.(*domain.JWTCustomClaims)
+	if !ok || claims.IssuedAt == nil || claims.ExpiresAt == nil ||
+		claims.ExpiresAt.Sub(claims.IssuedAt.Time) > domain.JWTExpiration {
+		return nil, domain.ErrUnknownSigningKey
+	}
+
+	keys, err := s.load(ctx, signingKeyCacheTTL)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) > 0 && !claims.IssuedAt.Before(keys[len(keys)-1].key.CreatedAt) {
+		return nil, domain.ErrUnknownSigningKey
+	}
+
+	return []byte(s.Config.GetJWTSecret()), nil
+}
+
+func findSigningKey(keys []*signingKey, kid string) *signingKey {
+	for _, key := range keys {
+		if key.key.KID == kid {
+			return key
+		}
+	}
+
+	return nil
+}
+
+func (s *signingKeyService) Verifiable(ctx context.Context) ([]*domain.SigningKey, error) {
+	keys, err := s.load(ctx, signingKeyCacheTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	verifiable := make([]*domain.SigningKey, 0, len(keys))
+	for _, key := range keys {
+		if !key.key.Retired(now) {
+			verifiable = append(verifiable, key.key)
+		}
+	}
+
+	return verifiable, nil
+}
+
+func (s *signingKeyService) RotateDue(ctx context.Context) error {
+	interval := time.Duration(s.Config.GetJWTKeyRotationDays()) * 24 * time.Hour
+
+	rotated, err := s.rotate(ctx, time.Now().Add(-interval))
+	if err != nil {
+		return err
+	}
+	if rotated {
+		_, err = s.load(ctx, 0)
+	}
+
+	return err
+}
+
+// rotate creates a new active key unless the active key was created after rotateBefore. The
+// replaced key verifies until every token it signed has expired, including those signed by
+// instances that haven't reloaded the keys yet.
+func (s *signingKeyService) rotate(ctx context.Context, rotateBefore time.Time) (bool, error) {
+	key, err := s.generate()
+	if err != nil {
+		log.Err(err).Msg("error generating signing key")
+		return false, err
+	}
+
+	retiresAt := time.Now().Add(domain.JWTExpiration + signingKeyCacheTTL)
+	rotated, err := s.signingKeyRepo.Rotate(ctx, key, rotateBefore, retiresAt)
+	if err != nil {
+		log.Err(err).Msg("error rotating signing key")
+		return false, fmt.Errorf("error rotating signing key: %w", err)
+	}
+	if rotated {
+		log.Info().Str("kid", key.KID).Msg("rotated signing key")
+	}
+
+	return rotated, nil
+}
+
+func (s *signingKeyService) generate() (*domain.SigningKey, error) {
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := s.seal(privateDER)
+	if err != nil {
+		return nil, err
+	}
+
+	publicDER, err := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// the kid is derived from the public key, so it never names two keys
+	sum := sha256.Sum256(publicDER)
+
+	return &domain.SigningKey{
+		KID:        base64.RawURLEncoding.EncodeToString(sum[:16]),
+		Algorithm:  domain.SigningKeyAlgorithm,
+		PrivateKey: sealed,
+		PublicKey:  publicDER,
+	}, nil
+}
+
+// load returns the keys, they are read again when they were loaded longer than maxAge ago.
+func (s *signingKeyService) load(ctx context.Context, maxAge time.Duration) ([]*signingKey, error) {
+	s.mu.RLock()
+	keys, loadedAt := s.keys, s.loadedAt
+	s.mu.RUnlock()
+	if keys != nil && time.Since(loadedAt) < maxAge {
+		return keys, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// another caller may have loaded them while this one waited
+	if s.keys != nil && time.Since(s.loadedAt) < maxAge {
+		return s.keys, nil
+	}
+
+	stored, err := s.signingKeyRepo.Verifiable(ctx, time.Now())
+	if err != nil {
+		log.Err(err).Msg("error retrieving signing keys")
+		return nil, err
+	}
+
+	keys = make([]*signingKey, 0, len(stored))
+	for _, key := range stored {
+		public, err := key.ECDSAPublicKey()
+		if err != nil {
+			log.Err(err).Str("kid", key.KID).Msg("error parsing signing key")
+			continue
+		}
+
+		parsed := &signingKey{key: key, public: public}
+		if parsed.private, err = s.open(key.PrivateKey); err != nil {
+			// the key still verifies, it just can't sign here
+			log.Warn().Err(err).Str("kid", key.KID).Msg("error opening signing key")
+		}
+		keys = append(keys, parsed)
+	}
+
+	s.keys, s.loadedAt = keys, time.Now()
+	return keys, nil
+}
+
+// sealKey is the key the private keys are stored with, derived from JWT_SECRET.
+func (s *signingKeyService) sealKey() []byte {
+	sum := sha256.Sum256([]byte("signing-key:" + s.Config.GetJWTSecret()))
+	return sum[:]
+}
+
+// seal encrypts a private key with AES-GCM, the nonce is prepended.
+func (s *signingKeyService) seal(plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(s.sealKey())
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (s *signingKeyService) open(sealed []byte) (*ecdsa.PrivateKey, error) {
+	gcm, err := newGCM(s.sealKey())
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("sealed signing key is too short")
+	}
+
+	der, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	private, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("signing key is not an ECDSA key")
+	}
+
+	return private, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
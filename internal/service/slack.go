@@ -0,0 +1,389 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/metrics"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+	"github.com/meowmix1337/the_recipe_book/internal/slack"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	dueSlackBatchSize = 50
+	// slackConcurrency is how many messages are posted at once.
+	slackConcurrency = 4
+	// slackErrorLength caps the error stored with a failed attempt.
+	slackErrorLength = 500
+
+	slackCommandUsage = "Send `/todo <title>` to add a todo, or `/todo link <code>` to link your account " +
+		"with the code shown when you connected Slack."
+)
+
+// SlackService posts todo notifications of a user to Slack and lets linked Slack users add todos
+// with a slash command. Messages are queued when the event happens and posted by a background
+// worker, failed messages are retried with exponential backoff.
+type SlackService interface {
+	// Connect stores the Slack connection of the user and returns it with a new link code, the
+	// code binds the Slack user that sends it with the link command.
+	Connect(ctx context.Context, userID uint, update *domain.SlackConnectionUpdate) (*domain.SlackConnection, string, error)
+	Connection(ctx context.Context, userID uint) (*domain.SlackConnection, error)
+	Disconnect(ctx context.Context, userID uint) error
+
+	// VerifyCommand checks the signature of a slash command request.
+	VerifyCommand(timestamp string, signature string, body []byte) error
+	// Command runs a slash command, the response is shown to the Slack user only.
+	Command(ctx context.Context, command *domain.SlackCommand) (*domain.SlackCommandResponse, error)
+
+	// HandleTodoEvent queues a message for todos moved to another user and for todos completed in
+	// shared lists, to everyone on the list.
+	HandleTodoEvent(ctx context.Context, event *domain.TodoEvent)
+	// HandleReminder queues a message for todos that became overdue.
+	HandleReminder(ctx context.Context, reminder *domain.Reminder)
+	// SendDue posts the due messages, it is run by a background worker.
+	SendDue(ctx context.Context) error
+}
+
+type slackService struct {
+	*BaseService
+
+	householdService HouseholdService
+	workspaceService WorkspaceService
+	todoService      TodoService
+
+	slackRepo      repo.SlackRepo
+	listMemberRepo repo.ListMemberRepo
+
+	sender slack.Sender
+}
+
+func NewSlackService(
+	base *BaseService,
+	householdService HouseholdService,
+	workspaceService WorkspaceService,
+	todoService TodoService,
+	slackRepo repo.SlackRepo,
+	listMemberRepo repo.ListMemberRepo,
+	sender slack.Sender,
+) *slackService {
+	return &slackService{
+		BaseService:      base,
+		householdService: householdService,
+		workspaceService: workspaceService,
+		todoService:      todoService,
+		slackRepo:        slackRepo,
+		listMemberRepo:   listMemberRepo,
+		sender:           sender,
+	}
+}
+
+// check SlackService interface implementation on compile time.
+var _ SlackService = (*slackService)(nil)
+
+// check TodoEventHandler interface implementation on compile time.
+var _ TodoEventHandler = (*slackService)(nil)
+
+// check ReminderHandler interface implementation on compile time.
+var _ ReminderHandler = (*slackService)(nil)
+
+func (s *slackService) Connect(
+	ctx context.Context,
+	userID uint,
+	update *domain.SlackConnectionUpdate,
+) (*domain.SlackConnection, string, error) {
+	if update == nil {
+		return nil, "", fmt.Errorf("no slack connection details provided")
+	}
+
+	// notifications send todos to a third party
+	if err := s.householdService.Permit(ctx, userID, domain.ParentalActionShare); err != nil {
+		return nil, "", err
+	}
+
+	conn := &domain.SlackConnection{
+		UserID:     userID,
+		WebhookURL: update.WebhookURL,
+		BotToken:   update.BotToken,
+		Channel:    update.Channel,
+		Events:     update.Events,
+	}
+
+	// the bot token is never shown again, saving the connection without one keeps the stored token
+	if conn.WebhookURL == "" && conn.BotToken == "" {
+		existing, err := s.slackRepo.ByUserID(ctx, userID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			log.Err(err).Msg("error retrieving slack connection")
+			return nil, "", err
+		}
+		if existing != nil {
+			conn.BotToken = existing.BotToken
+		}
+	}
+
+	switch {
+	case conn.WebhookURL != "":
+		if err := slack.ValidateWebhookURL(conn.WebhookURL); err != nil {
+			return nil, "", err
+		}
+		conn.BotToken, conn.Channel = "", ""
+	case conn.BotToken == "" || conn.Channel == "":
+		return nil, "", domain.ErrInvalidSlackConnection
+	}
+
+	linkCode, err := generateToken(domain.SlackLinkCodePrefix)
+	if err != nil {
+		log.Err(err).Msg("error generating slack link code")
+		return nil, "", err
+	}
+	conn.LinkCodeHash = hashToken(linkCode)
+	conn.LinkCodeExpiresAt = time.Now().UTC().Add(domain.SlackLinkCodeTTL)
+
+	saved, err := s.slackRepo.Upsert(ctx, conn)
+	if err != nil {
+		log.Err(err).Msg("error saving slack connection")
+		return nil, "", fmt.Errorf("error saving slack connection: %w", err)
+	}
+
+	return saved, linkCode, nil
+}
+
+func (s *slackService) Connection(ctx context.Context, userID uint) (*domain.SlackConnection, error) {
+	conn, err := s.slackRepo.ByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrSlackNotConnected
+		}
+		log.Err(err).Msg("error retrieving slack connection")
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+func (s *slackService) Disconnect(ctx context.Context, userID uint) error {
+	if err := s.slackRepo.Delete(ctx, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrSlackNotConnected
+		}
+		log.Err(err).Msg("error deleting slack connection")
+		return fmt.Errorf("error deleting slack connection: %w", err)
+	}
+
+	return nil
+}
+
+func (s *slackService) VerifyCommand(timestamp string, signature string, body []byte) error {
+	secret := s.Config.GetSlackSigningSecret()
+	if secret == "" {
+		return domain.ErrSlackCommandsDisabled
+	}
+
+	return slack.Verify(secret, timestamp, signature, body, time.Now())
+}
+
+func (s *slackService) Command(ctx context.Context, command *domain.SlackCommand) (*domain.SlackCommandResponse, error) {
+	text := strings.TrimSpace(command.Text)
+	verb, arg, _ := strings.Cut(text, " ")
+
+	switch strings.ToLower(verb) {
+	case "", "help":
+		return &domain.SlackCommandResponse{Text: slackCommandUsage}, nil
+	case "link":
+		return s.link(ctx, command, strings.TrimSpace(arg))
+	}
+
+	conn, err := s.slackRepo.BySlackUser(ctx, command.TeamID, command.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &domain.SlackCommandResponse{
+				Text: "Your Slack account isn't linked yet. " + slackCommandUsage,
+			}, nil
+		}
+		log.Err(err).Msg("error retrieving slack connection")
+		return nil, err
+	}
+
+	// every command of a linked Slack user is an integration sync of the workspace
+	if err = s.workspaceService.Consume(ctx, conn.UserID, domain.QuotaIntegrationSyncs); err != nil {
+		return nil, err
+	}
+
+	todo, err := s.todoService.Create(ctx, conn.UserID, &domain.TodoCreate{
+		Title:   text,
+		Source:  domain.CaptureSourceSlack,
+		Channel: command.ChannelName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.SlackCommandResponse{Text: fmt.Sprintf("Added *%s* to your todos.", slack.Escape(todo.Title))}, nil
+}
+
+func (s *slackService) link(ctx context.Context, command *domain.SlackCommand, code string) (*domain.SlackCommandResponse, error) {
+	if code == "" {
+		return &domain.SlackCommandResponse{Text: slackCommandUsage}, nil
+	}
+
+	_, err := s.slackRepo.Link(ctx, hashToken(code), time.Now(), command.TeamID, command.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrInvalidSlackLinkCode
+		}
+		log.Err(err).Msg("error linking slack user")
+		return nil, fmt.Errorf("error linking slack user: %w", err)
+	}
+
+	return &domain.SlackCommandResponse{Text: "Your Slack account is linked, send `/todo <title>` to add a todo."}, nil
+}
+
+func (s *slackService) HandleTodoEvent(ctx context.Context, event *domain.TodoEvent) {
+	title := slack.Escape(event.Todo.Title)
+
+	switch event.Type {
+	case domain.EventTodoMoved:
+		if event.Previous == nil || event.Previous.UserID == event.Todo.UserID {
+			return
+		}
+		s.queue(ctx, []uint{event.Todo.UserID}, domain.SlackEventAssigned, event.OccurredAt,
+			fmt.Sprintf(":inbox_tray: *%s* was assigned to you", title))
+	case domain.EventTodoCompleted:
+		if event.Todo.ListID == 0 {
+			return
+		}
+		members, err := s.listMemberRepo.Members(ctx, event.Todo.ListID)
+		if err != nil {
+			log.Err(err).Str("event", string(event.Type)).Msg("error retrieving list members for slack")
+			return
+		}
+		// lists nobody was invited to aren't shared
+		if len(members) == 0 {
+			return
+		}
+
+		userIDs := []uint{event.Todo.UserID}
+		for _, member := range members {
+			userIDs = append(userIDs, member.UserID)
+		}
+		s.queue(ctx, userIDs, domain.SlackEventCompleted, event.OccurredAt,
+			fmt.Sprintf(":white_check_mark: *%s* was completed", title))
+	}
+}
+
+func (s *slackService) HandleReminder(ctx context.Context, reminder *domain.Reminder) {
+	if reminder.Kind != domain.ReminderOverdue || len(reminder.Todos) == 0 {
+		return
+	}
+
+	lines := make([]string, 0, len(reminder.Todos)+1)
+	if len(reminder.Todos) == 1 {
+		lines = append(lines, fmt.Sprintf(":alarm_clock: *%s* is overdue", slack.Escape(reminder.Todos[0].Title)))
+	} else {
+		lines = append(lines, fmt.Sprintf(":alarm_clock: %d todos are overdue", len(reminder.Todos)))
+		for _, todo := range reminder.Todos {
+			lines = append(lines, "• "+slack.Escape(todo.Title))
+		}
+	}
+
+	s.queue(ctx, []uint{reminder.UserID}, domain.SlackEventOverdue, time.Now().UTC(), strings.Join(lines, "\n"))
+}
+
+// queue queues the text for every connection of the users subscribed to the event.
+func (s *slackService) queue(ctx context.Context, userIDs []uint, event domain.SlackEvent, occurredAt time.Time, text string) {
+	conns, err := s.slackRepo.ByUserIDs(ctx, userIDs)
+	if err != nil {
+		log.Err(err).Str("event", string(event)).Msg("error retrieving slack connections for event")
+		return
+	}
+
+	messages := make([]*domain.SlackMessage, 0, len(conns))
+	for _, conn := range conns {
+		if !conn.Subscribed(event) {
+			continue
+		}
+		messages = append(messages, &domain.SlackMessage{
+			UUID:          s.GenerateUUIDHash("slack_message"),
+			Connection:    conn,
+			Event:         event,
+			Text:          text,
+			NextAttemptAt: occurredAt,
+		})
+	}
+
+	if err = s.slackRepo.CreateMessages(ctx, messages); err != nil {
+		log.Err(err).Str("event", string(event)).Msg("error queueing slack messages")
+	}
+}
+
+func (s *slackService) SendDue(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	messages, err := s.slackRepo.DueMessages(ctx, now, dueSlackBatchSize)
+	if err != nil {
+		return fmt.Errorf("error retrieving due slack messages: %w", err)
+	}
+	metrics.WorkerQueueDepth.WithLabelValues("slack_messages", metrics.RegionLabel(region.FromContext(ctx))).Set(float64(len(messages)))
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, slackConcurrency)
+	for _, message := range messages {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			s.send(ctx, message, now)
+		}()
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (s *slackService) send(ctx context.Context, message *domain.SlackMessage, now time.Time) {
+	// claim the attempt first so multiple instances never post the same message twice
+	retryAt := now.Add(domain.SlackBackoff(message.Attempts + 1))
+	if err := s.slackRepo.ClaimMessage(ctx, message, retryAt); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Err(err).Str("message", message.UUID).Msg("error claiming slack message")
+		}
+		return
+	}
+	message.Attempts++
+	message.NextAttemptAt = retryAt
+
+	err := s.sender.Send(ctx, &slack.Destination{
+		WebhookURL: message.Connection.WebhookURL,
+		BotToken:   message.Connection.BotToken,
+		Channel:    message.Connection.Channel,
+	}, message.Text)
+
+	// a revoked webhook or a channel the bot left won't heal by retrying
+	var apiErr *slack.APIError
+	switch {
+	case err == nil:
+		message.Status = domain.SlackMessageSent
+		message.LastError = ""
+		message.SentAt = time.Now().UTC()
+	case message.Attempts >= domain.SlackMaxAttempts || (errors.As(err, &apiErr) && apiErr.Permanent()):
+		message.Status = domain.SlackMessageFailed
+		message.LastError = truncate(err.Error(), slackErrorLength)
+		log.Warn().Err(err).Str("message", message.UUID).Msg("slack message failed, giving up")
+	default:
+		message.LastError = truncate(err.Error(), slackErrorLength)
+		log.Debug().Err(err).Str("message", message.UUID).Time("retry_at", retryAt).Msg("slack message failed")
+	}
+
+	if err = s.slackRepo.UpdateMessage(ctx, message); err != nil {
+		log.Err(err).Str("message", message.UUID).Msg("error updating slack message")
+	}
+}
@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/export"
+	"github.com/meowmix1337/the_recipe_book/internal/mail"
+	"github.com/meowmix1337/the_recipe_book/internal/metrics"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+const dueSnapshotBatchSize = 50
+
+// SnapshotService emails rendered snapshots of a list, on demand or on a schedule, so
+// people without an account can follow it.
+type SnapshotService interface {
+	Send(ctx context.Context, userID uint, listUUID string, recipients []string) error
+	CreateSchedule(ctx context.Context, userID uint, listUUID string, recipients []string, frequency domain.SnapshotFrequency) (*domain.ListSnapshotSchedule, error)
+	DeleteSchedule(ctx context.Context, userID uint, listUUID string, scheduleUUID string) error
+	Schedules(ctx context.Context, userID uint, listUUID string) ([]*domain.ListSnapshotSchedule, error)
+
+	// SendDue sends the snapshots of all due schedules, it is run by a background worker.
+	SendDue(ctx context.Context) error
+}
+
+type snapshotService struct {
+	*BaseService
+
+	listService      ListService
+	householdService HouseholdService
+	workspaceService WorkspaceService
+
+	listRepo     repo.ListRepo
+	todoRepo     repo.TodoRepo
+	scheduleRepo repo.SnapshotScheduleRepo
+
+	sender mail.Sender
+}
+
+func NewSnapshotService(
+	base *BaseService,
+	listService ListService,
+	householdService HouseholdService,
+	workspaceService WorkspaceService,
+	listRepo repo.ListRepo,
+	todoRepo repo.TodoRepo,
+	scheduleRepo repo.SnapshotScheduleRepo,
+	sender mail.Sender,
+) *snapshotService {
+	return &snapshotService{
+		BaseService:      base,
+		listService:      listService,
+		householdService: householdService,
+		workspaceService: workspaceService,
+		listRepo:         listRepo,
+		todoRepo:         todoRepo,
+		scheduleRepo:     scheduleRepo,
+		sender:           sender,
+	}
+}
+
+// check SnapshotService interface implementation on compile time.
+var _ SnapshotService = (*snapshotService)(nil)
+
+func (s *snapshotService) Send(ctx context.Context, userID uint, listUUID string, recipients []string) error {
+	if err := s.householdService.Permit(ctx, userID, domain.ParentalActionShare); err != nil {
+		return err
+	}
+
+	list, err := s.listService.ByUUID(ctx, userID, listUUID)
+	if err != nil {
+		return err
+	}
+
+	return s.send(ctx, list, recipients)
+}
+
+func (s *snapshotService) CreateSchedule(
+	ctx context.Context,
+	userID uint,
+	listUUID string,
+	recipients []string,
+	frequency domain.SnapshotFrequency,
+) (*domain.ListSnapshotSchedule, error) {
+	if err := s.householdService.Permit(ctx, userID, domain.ParentalActionShare); err != nil {
+		return nil, err
+	}
+
+	list, err := s.listService.ByUUID(ctx, userID, listUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule, err := s.scheduleRepo.Create(ctx, &domain.ListSnapshotSchedule{
+		UUID:       s.GenerateUUIDHash("snapshot"),
+		ListID:     list.ID,
+		UserID:     userID,
+		Recipients: recipients,
+		Frequency:  frequency,
+		NextRunAt:  frequency.Next(time.Now().UTC()),
+	})
+	if err != nil {
+		log.Err(err).Msg("error creating snapshot schedule")
+		return nil, fmt.Errorf("error creating snapshot schedule: %w", err)
+	}
+
+	return schedule, nil
+}
+
+func (s *snapshotService) DeleteSchedule(ctx context.Context, userID uint, listUUID string, scheduleUUID string) error {
+	schedules, err := s.Schedules(ctx, userID, listUUID)
+	if err != nil {
+		return err
+	}
+
+	for _, schedule := range schedules {
+		if schedule.UUID != scheduleUUID {
+			continue
+		}
+
+		if err = s.scheduleRepo.Delete(ctx, schedule.ListID, scheduleUUID); err != nil {
+			log.Err(err).Msg("error deleting snapshot schedule")
+			return err
+		}
+		return nil
+	}
+
+	return domain.ErrSnapshotScheduleNotFound
+}
+
+func (s *snapshotService) Schedules(ctx context.Context, userID uint, listUUID string) ([]*domain.ListSnapshotSchedule, error) {
+	list, err := s.listService.ByUUID(ctx, userID, listUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules, err := s.scheduleRepo.ByList(ctx, list.ID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving snapshot schedules")
+		return nil, err
+	}
+
+	return schedules, nil
+}
+
+func (s *snapshotService) SendDue(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	schedules, err := s.scheduleRepo.Due(ctx, now, dueSnapshotBatchSize)
+	if err != nil {
+		return fmt.Errorf("error retrieving due snapshot schedules: %w", err)
+	}
+	metrics.WorkerQueueDepth.WithLabelValues("list_snapshots", metrics.RegionLabel(region.FromContext(ctx))).Set(float64(len(schedules)))
+
+	for _, schedule := range schedules {
+		s.sendScheduled(ctx, schedule, now)
+	}
+
+	return nil
+}
+
+func (s *snapshotService) sendScheduled(ctx context.Context, schedule *domain.ListSnapshotSchedule, now time.Time) {
+	// claim the run first so multiple instances never send the same snapshot twice
+	err := s.scheduleRepo.Claim(ctx, schedule, schedule.Frequency.Next(now))
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Err(err).Str("schedule", schedule.UUID).Msg("error claiming snapshot schedule")
+		}
+		return
+	}
+
+	list, err := s.listRepo.ByID(ctx, schedule.ListID)
+	if err != nil {
+		log.Err(err).Str("schedule", schedule.UUID).Msg("error retrieving list for snapshot schedule")
+		return
+	}
+
+	// the holidays of the workspace of the list owner are skipped, the claim already moved the
+	// schedule to its next run
+	if s.holiday(ctx, list.UserID, now) {
+		return
+	}
+
+	if err = s.send(ctx, list, schedule.Recipients); err != nil {
+		return
+	}
+
+	if err = s.scheduleRepo.MarkSent(ctx, schedule.ID, now); err != nil {
+		log.Err(err).Str("schedule", schedule.UUID).Msg("error marking snapshot schedule as sent")
+	}
+}
+
+// holiday reports whether it is a holiday of the workspace of the user, users outside of a
+// workspace have no holidays.
+func (s *snapshotService) holiday(ctx context.Context, userID uint, now time.Time) bool {
+	calendar, err := s.workspaceService.Calendar(ctx, userID)
+	if err != nil {
+		if !errors.Is(err, domain.ErrWorkspaceNotFound) {
+			log.Err(err).Msg("error retrieving workspace calendar for snapshot schedule")
+		}
+		return false
+	}
+
+	return calendar.Holiday(calendar.Date(now)) != nil
+}
+
+func (s *snapshotService) send(ctx context.Context, list *domain.List, recipients []string) error {
+	// snapshots always contain the whole list
+	todos, _, err := s.todoRepo.All(ctx, list.UserID, &domain.TodoFilter{
+		ListID: list.ID,
+		Sort:   []domain.TodoSortField{domain.TodoSortPriority, domain.TodoSortDueDate},
+		Order:  domain.SortOrderDesc,
+	}, nil)
+	if err != nil {
+		log.Err(err).Msg("error retrieving todos for snapshot")
+		return err
+	}
+
+	rendered, err := export.RenderListSnapshot(export.NewListSnapshot(list, todos))
+	if err != nil {
+		log.Err(err).Msg("error rendering list snapshot")
+		return err
+	}
+
+	err = s.sender.Send(ctx, &mail.Message{
+		To:      recipients,
+		Subject: list.Name,
+		Text:    rendered.Text,
+		HTML:    rendered.HTML,
+	})
+	if err != nil {
+		log.Err(err).Str("list", list.UUID).Msg("error sending list snapshot")
+		return err
+	}
+
+	return nil
+}
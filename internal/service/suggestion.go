@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+	"github.com/meowmix1337/the_recipe_book/internal/suggestion"
+
+	"github.com/rs/zerolog/log"
+)
+
+// SuggestionService looks for todos that keep getting postponed or stay overdue and suggests
+// what to do about them, the heuristics live in the suggestion package.
+type SuggestionService interface {
+	Postponed(ctx context.Context, userID uint) ([]*domain.TodoSuggestion, error)
+}
+
+type suggestionService struct {
+	*BaseService
+
+	todoService TodoService
+
+	listMemberRepo repo.ListMemberRepo
+
+	analyzer *suggestion.Analyzer
+}
+
+func NewSuggestionService(
+	base *BaseService,
+	todoService TodoService,
+	listMemberRepo repo.ListMemberRepo,
+	analyzer *suggestion.Analyzer,
+) *suggestionService {
+	return &suggestionService{
+		BaseService:    base,
+		todoService:    todoService,
+		listMemberRepo: listMemberRepo,
+		analyzer:       analyzer,
+	}
+}
+
+// check SuggestionService interface implementation on compile time.
+var _ SuggestionService = (*suggestionService)(nil)
+
+func (s *suggestionService) Postponed(ctx context.Context, userID uint) ([]*domain.TodoSuggestion, error) {
+	todos, _, err := s.todoService.All(ctx, userID, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	collaborators := make(map[uint][]string)
+	candidates := make([]*suggestion.Candidate, 0)
+	for _, todo := range todos {
+		// only look up the collaborators of lists with stalled todos
+		if len(s.analyzer.Stalled(todo, now)) == 0 {
+			continue
+		}
+
+		candidate := &suggestion.Candidate{Todo: todo}
+		if todo.ListID != 0 {
+			emails, ok := collaborators[todo.ListID]
+			if !ok {
+				emails, err = s.collaborators(ctx, userID, todo.ListID)
+				if err != nil {
+					return nil, err
+				}
+				collaborators[todo.ListID] = emails
+			}
+			candidate.Collaborators = emails
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	return s.analyzer.Analyze(candidates, now), nil
+}
+
+// collaborators returns the emails of the members of a list that can take over its todos.
+func (s *suggestionService) collaborators(ctx context.Context, userID uint, listID uint) ([]string, error) {
+	members, err := s.listMemberRepo.Members(ctx, listID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving list members")
+		return nil, err
+	}
+
+	emails := make([]string, 0, len(members))
+	for _, member := range members {
+		if member.UserID != userID && member.Role.CanWrite() {
+			emails = append(emails, member.Email)
+		}
+	}
+
+	return emails, nil
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/pagination"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+type TagService interface {
+	// Create creates a tag, color is a #rrggbb hex color or empty.
+	Create(ctx context.Context, userID uint, name string, color string) (*domain.Tag, error)
+	// SetColor sets the color of a tag, an empty color removes it.
+	SetColor(ctx context.Context, userID uint, uuid string, color string) (*domain.Tag, error)
+	ByUUID(ctx context.Context, userID uint, uuid string) (*domain.Tag, error)
+	// Rename renames a tag, the name must not be taken by another tag of the user, those are
+	// merged instead.
+	Rename(ctx context.Context, userID uint, uuid string, name string) (*domain.Tag, error)
+	// Merge folds the source tag into the tag of uuid, its todos are tagged with the target
+	// instead and the source is deleted.
+	Merge(ctx context.Context, userID uint, uuid string, sourceUUID string) (*domain.TagMergeResult, error)
+	Ensure(ctx context.Context, userID uint, names []string) ([]*domain.Tag, error)
+	// Assign tags a todo with the named tags of its owner, creating those that are missing.
+	Assign(ctx context.Context, todo *domain.Todo, names []string) ([]*domain.Tag, error)
+	AddToTodo(ctx context.Context, userID uint, todoUUID string, names []string) (*domain.Todo, error)
+	RemoveFromTodo(ctx context.Context, userID uint, todoUUID string, name string) (*domain.Todo, error)
+
+	// All returns the tags of the user, only those in group unless it is empty.
+	All(ctx context.Context, userID uint, group string, page *pagination.Page) ([]*domain.Tag, *pagination.Cursor, error)
+}
+
+type tagService struct {
+	*BaseService
+
+	tagRepo  repo.TagRepo
+	todoRepo repo.TodoRepo
+}
+
+func NewTagService(base *BaseService, tagRepo repo.TagRepo, todoRepo repo.TodoRepo) *tagService {
+	return &tagService{
+		BaseService: base,
+		tagRepo:     tagRepo,
+		todoRepo:    todoRepo,
+	}
+}
+
+// check TagService interface implementation on compile time.
+var _ TagService = (*tagService)(nil)
+
+func (s *tagService) Create(ctx context.Context, userID uint, name string, color string) (*domain.Tag, error) {
+	name = domain.NormalizeTagName(name)
+
+	_, err := s.tagRepo.ByName(ctx, userID, name)
+	if err == nil {
+		return nil, domain.ErrTagAlreadyExists
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		log.Err(err).Msg("error retrieving tag by name")
+		return nil, err
+	}
+
+	tag, err := s.tagRepo.Create(ctx, s.GenerateUUIDHash("tag"), userID, name, domain.NormalizeTagColor(color))
+	if err != nil {
+		log.Err(err).Msg("error creating tag")
+		return nil, fmt.Errorf("error creating tag: %w", err)
+	}
+
+	return tag, nil
+}
+
+func (s *tagService) SetColor(ctx context.Context, userID uint, uuid string, color string) (*domain.Tag, error) {
+	tag, err := s.tagRepo.SetColor(ctx, userID, uuid, domain.NormalizeTagColor(color))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("tag not found: %w", domain.ErrTagNotFound)
+		}
+		log.Err(err).Msg("error setting tag color")
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+func (s *tagService) Rename(ctx context.Context, userID uint, uuid string, name string) (*domain.Tag, error) {
+	name = domain.NormalizeTagName(name)
+
+	existing, err := s.tagRepo.ByName(ctx, userID, name)
+	if err == nil {
+		// renaming to the current name, e.g. with different case, changes nothing
+		if existing.UUID == uuid {
+			return existing, nil
+		}
+		return nil, domain.ErrTagAlreadyExists
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		log.Err(err).Msg("error retrieving tag by name")
+		return nil, err
+	}
+
+	tag, err := s.tagRepo.Rename(ctx, userID, uuid, name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("tag not found: %w", domain.ErrTagNotFound)
+		}
+		log.Err(err).Msg("error renaming tag")
+		return nil, fmt.Errorf("error renaming tag: %w", err)
+	}
+
+	return tag, nil
+}
+
+func (s *tagService) Merge(ctx context.Context, userID uint, uuid string, sourceUUID string) (*domain.TagMergeResult, error) {
+	target, err := s.ByUUID(ctx, userID, uuid)
+	if err != nil {
+		return nil, err
+	}
+	source, err := s.ByUUID(ctx, userID, sourceUUID)
+	if err != nil {
+		return nil, err
+	}
+	if source.ID == target.ID {
+		return nil, domain.ErrTagMergeSelf
+	}
+
+	retagged, duplicates, err := s.tagRepo.Merge(ctx, source.ID, target.ID)
+	if err != nil {
+		log.Err(err).Msg("error merging tags")
+		return nil, fmt.Errorf("error merging tags: %w", err)
+	}
+
+	return &domain.TagMergeResult{Target: target, Retagged: retagged, Duplicates: duplicates}, nil
+}
+
+func (s *tagService) Ensure(ctx context.Context, userID uint, names []string) ([]*domain.Tag, error) {
+	names = domain.NormalizeTagNames(names)
+
+	tags := make([]*domain.Tag, 0, len(names))
+	for _, name := range names {
+		tags = append(tags, &domain.Tag{
+			UUID: s.GenerateUUIDHash("tag"),
+			Name: name,
+		})
+	}
+
+	ensured, err := s.tagRepo.Ensure(ctx, userID, tags)
+	if err != nil {
+		log.Err(err).Msg("error ensuring tags")
+		return nil, err
+	}
+
+	return ensured, nil
+}
+
+func (s *tagService) AddToTodo(ctx context.Context, userID uint, todoUUID string, names []string) (*domain.Todo, error) {
+	todo, err := s.todo(ctx, userID, todoUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = s.Assign(ctx, todo, names); err != nil {
+		return nil, err
+	}
+
+	return s.todo(ctx, userID, todoUUID)
+}
+
+func (s *tagService) Assign(ctx context.Context, todo *domain.Todo, names []string) ([]*domain.Tag, error) {
+	tags, err := s.Ensure(ctx, todo.UserID, names)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.tagRepo.AssignToTodo(ctx, todo.ID, tagIDs(tags))
+	if err != nil {
+		log.Err(err).Msg("error assigning tags to todo")
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+func (s *tagService) RemoveFromTodo(ctx context.Context, userID uint, todoUUID string, name string) (*domain.Todo, error) {
+	todo, err := s.todo(ctx, userID, todoUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := s.tagRepo.ByName(ctx, userID, domain.NormalizeTagName(name))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("tag not found: %w", domain.ErrTagNotFound)
+		}
+		log.Err(err).Msg("error retrieving tag by name")
+		return nil, err
+	}
+
+	err = s.tagRepo.RemoveFromTodo(ctx, todo.ID, tag.ID)
+	if err != nil {
+		log.Err(err).Msg("error removing tag from todo")
+		return nil, err
+	}
+
+	return s.todo(ctx, userID, todoUUID)
+}
+
+func (s *tagService) All(ctx context.Context, userID uint, group string, page *pagination.Page) ([]*domain.Tag, *pagination.Cursor, error) {
+	tags, next, err := s.tagRepo.All(ctx, userID, domain.NormalizeTagGroup(group), page)
+	if err != nil {
+		log.Err(err).Msg("error retrieving tags")
+		return nil, nil, err
+	}
+
+	return tags, next, nil
+}
+
+func (s *tagService) todo(ctx context.Context, userID uint, todoUUID string) (*domain.Todo, error) {
+	todo, err := s.todoRepo.ByUUID(ctx, userID, todoUUID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("todo not found: %w", domain.ErrTodoNotFound)
+		}
+		log.Err(err).Msg("error retrieving todo")
+		return nil, err
+	}
+
+	return todo, nil
+}
+
+func (s *tagService) ByUUID(ctx context.Context, userID uint, uuid string) (*domain.Tag, error) {
+	tag, err := s.tagRepo.ByUUID(ctx, userID, uuid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("tag not found: %w", domain.ErrTagNotFound)
+		}
+		log.Err(err).Msg("error retrieving tag")
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+func tagIDs(tags []*domain.Tag) []uint {
+	ids := make([]uint, 0, len(tags))
+	for _, tag := range tags {
+		ids = append(ids, tag.ID)
+	}
+
+	return ids
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/export"
+	"github.com/meowmix1337/the_recipe_book/internal/metrics"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+	"github.com/meowmix1337/the_recipe_book/internal/storage"
+
+	"github.com/rs/zerolog/log"
+)
+
+// TakeoutService exports all the personal data of users. The archive is assembled in the
+// background, the user is emailed a link to download it until it expires.
+type TakeoutService interface {
+	// Request queues a takeout, a user has one takeout in the making at a time.
+	Request(ctx context.Context, userID uint) (*domain.Takeout, error)
+	// Takeout returns the takeout with a link to download its archive while it can be downloaded.
+	Takeout(ctx context.Context, userID uint, uuid string) (*domain.Takeout, string, error)
+	// RunDue assembles the queued takeouts and deletes the expired archives, it is run by a
+	// background worker.
+	RunDue(ctx context.Context) error
+}
+
+const (
+	// takeoutBatchSize is how many takeouts a run of the takeout worker assembles.
+	takeoutBatchSize = 5
+	// takeoutBudget is how long a run keeps claiming takeouts.
+	takeoutBudget = 30 * time.Second
+	// takeoutPruneBatchSize is how many expired archives a run deletes.
+	takeoutPruneBatchSize = 50
+)
+
+type takeoutService struct {
+	*BaseService
+
+	listService         ListService
+	todoService         TodoService
+	notificationService NotificationService
+
+	userRepo       repo.UserRepo
+	commentRepo    repo.CommentRepo
+	attachmentRepo repo.AttachmentRepo
+	takeoutRepo    repo.TakeoutRepo
+
+	storage storage.Storage
+}
+
+func NewTakeoutService(
+	base *BaseService,
+	listService ListService,
+	todoService TodoService,
+	notificationService NotificationService,
+	userRepo repo.UserRepo,
+	commentRepo repo.CommentRepo,
+	attachmentRepo repo.AttachmentRepo,
+	takeoutRepo repo.TakeoutRepo,
+	storage storage.Storage,
+) *takeoutService {
+	return &takeoutService{
+		BaseService:         base,
+		listService:         listService,
+		todoService:         todoService,
+		notificationService: notificationService,
+		userRepo:            userRepo,
+		commentRepo:         commentRepo,
+		attachmentRepo:      attachmentRepo,
+		takeoutRepo:         takeoutRepo,
+		storage:             storage,
+	}
+}
+
+// check TakeoutService interface implementation on compile time.
+var _ TakeoutService = (*takeoutService)(nil)
+
+func (s *takeoutService) Request(ctx context.Context, userID uint) (*domain.Takeout, error) {
+	takeout, err := s.takeoutRepo.Create(ctx, &domain.Takeout{
+		UUID:   s.GenerateUUIDHash("takeout"),
+		UserID: userID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrTakeoutInProgress
+		}
+		log.Err(err).Msg("error creating takeout")
+		return nil, fmt.Errorf("error creating takeout: %w", err)
+	}
+
+	return takeout, nil
+}
+
+func (s *takeoutService) Takeout(ctx context.Context, userID uint, uuid string) (*domain.Takeout, string, error) {
+	takeout, err := s.takeoutRepo.ByUUID(ctx, userID, uuid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, "", domain.ErrTakeoutNotFound
+		}
+		log.Err(err).Msg("error retrieving takeout")
+		return nil, "", err
+	}
+
+	if takeout.Status != domain.TakeoutCompleted || takeout.Expired(time.Now()) {
+		return takeout, "", nil
+	}
+
+	downloadURL, err := s.downloadURL(ctx, takeout)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return takeout, downloadURL, nil
+}
+
+// downloadURL signs a link to the archive that expires with it.
+func (s *takeoutService) downloadURL(ctx context.Context, takeout *domain.Takeout) (string, error) {
+	filename := fmt.Sprintf("takeout-%s.zip", takeout.FinishedAt.Format(time.DateOnly))
+	downloadURL, err := s.storage.SignedURL(ctx, takeout.StorageKey, filename, takeout.ExpiresAt)
+	if err != nil {
+		log.Err(err).Msg("error signing takeout url")
+		return "", err
+	}
+
+	return downloadURL, nil
+}
+
+func (s *takeoutService) RunDue(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	s.pruneExpired(ctx, now)
+
+	takeouts, err := s.takeoutRepo.Unfinished(ctx, now.Add(-domain.TakeoutJobTimeout), takeoutBatchSize)
+	if err != nil {
+		return fmt.Errorf("error retrieving unfinished takeouts: %w", err)
+	}
+	metrics.WorkerQueueDepth.WithLabelValues("takeouts", metrics.RegionLabel(region.FromContext(ctx))).Set(float64(len(takeouts)))
+
+	for _, takeout := range takeouts {
+		if time.Since(now) > takeoutBudget {
+			break
+		}
+		s.run(ctx, takeout, now)
+	}
+
+	return nil
+}
+
+func (s *takeoutService) pruneExpired(ctx context.Context, now time.Time) {
+	takeouts, err := s.takeoutRepo.Expired(ctx, now, takeoutPruneBatchSize)
+	if err != nil {
+		log.Err(err).Msg("error retrieving expired takeouts")
+		return
+	}
+
+	for _, takeout := range takeouts {
+		if err = s.storage.Delete(ctx, takeout.StorageKey); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			log.Err(err).Str("takeout", takeout.UUID).Msg("error deleting expired takeout")
+			continue
+		}
+		if err = s.takeoutRepo.Forget(ctx, takeout.ID); err != nil {
+			log.Err(err).Str("takeout", takeout.UUID).Msg("error forgetting expired takeout")
+		}
+	}
+}
+
+func (s *takeoutService) run(ctx context.Context, takeout *domain.Takeout, now time.Time) {
+	// claim the takeout first so multiple instances never assemble it twice, an interrupted
+	// takeout is simply assembled again
+	if err := s.takeoutRepo.Claim(ctx, takeout, now); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Err(err).Str("takeout", takeout.UUID).Msg("error claiming takeout")
+		}
+		return
+	}
+
+	user, err := s.userRepo.ByID(ctx, takeout.UserID)
+	if err == nil {
+		err = s.assemble(ctx, user, takeout)
+	}
+
+	takeout.Status = domain.TakeoutCompleted
+	takeout.FinishedAt = time.Now().UTC()
+	if err != nil {
+		log.Err(err).Str("takeout", takeout.UUID).Msg("error assembling takeout")
+		takeout.Status = domain.TakeoutFailed
+		takeout.Error = "data export could not be assembled, request a new one"
+	} else {
+		takeout.ExpiresAt = takeout.FinishedAt.Add(domain.TakeoutExpiration)
+	}
+	if err = s.takeoutRepo.Finish(ctx, takeout); err != nil {
+		log.Err(err).Str("takeout", takeout.UUID).Msg("error storing takeout outcome")
+		return
+	}
+
+	if takeout.Status == domain.TakeoutCompleted {
+		s.notify(ctx, user, takeout)
+	}
+}
+
+// assemble writes the archive to a temporary file first, storages need its size.
+func (s *takeoutService) assemble(ctx context.Context, user *domain.User, takeout *domain.Takeout) error {
+	ctx, cancel := context.WithTimeout(ctx, domain.TakeoutJobTimeout)
+	defer cancel()
+
+	data, err := s.collect(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp("", "takeout-*.zip")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	if err = export.WriteTakeout(file, data); err != nil {
+		return err
+	}
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf("takeouts/%s/%s.zip", user.UUID, takeout.UUID)
+	if err = s.storage.Put(ctx, key, file, size, "application/zip"); err != nil {
+		return fmt.Errorf("error storing takeout: %w", err)
+	}
+	takeout.StorageKey = key
+	takeout.Size = size
+
+	return nil
+}
+
+// collect reads the data of the user, the lists and todos the user owns and the comments the
+// user posted anywhere.
+func (s *takeoutService) collect(ctx context.Context, user *domain.User) (*export.Takeout, error) {
+	lists, _, err := s.listService.All(ctx, user.ID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	todos, _, err := s.todoService.All(ctx, user.ID, &domain.TodoFilter{
+		Sort:     []domain.TodoSortField{domain.TodoSortCreatedAt},
+		Order:    domain.SortOrderAsc,
+		Archived: domain.ArchiveInclude,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := s.commentRepo.ByAuthor(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving comments: %w", err)
+	}
+
+	var attachments []*domain.Attachment
+	for _, todo := range todos {
+		todoAttachments, err := s.attachmentRepo.All(ctx, todo.ID)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving attachments: %w", err)
+		}
+		attachments = append(attachments, todoAttachments...)
+	}
+
+	return &export.Takeout{
+		User:        user,
+		Lists:       lists,
+		Todos:       todos,
+		Comments:    comments,
+		Attachments: attachments,
+		ExportedAt:  time.Now(),
+	}, nil
+}
+
+// notify emails the download link, the takeout stays available from the API when it fails.
+func (s *takeoutService) notify(ctx context.Context, user *domain.User, takeout *domain.Takeout) {
+	downloadURL, err := s.downloadURL(ctx, takeout)
+	if err != nil {
+		return
+	}
+
+	if err = s.notificationService.SendTakeout(ctx, user, downloadURL, takeout.ExpiresAt); err != nil {
+		log.Err(err).Str("takeout", takeout.UUID).Msg("error sending takeout email")
+	}
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+// TemplateService manages the todo templates of a user, reusable todos like a release checklist
+// that new todos are created from.
+type TemplateService interface {
+	Create(ctx context.Context, userID uint, templateCreate *domain.TodoTemplateCreate) (*domain.TodoTemplate, error)
+	Update(ctx context.Context, userID uint, uuid string, templateCreate *domain.TodoTemplateCreate) (*domain.TodoTemplate, error)
+	Delete(ctx context.Context, userID uint, uuid string) error
+	ByUUID(ctx context.Context, userID uint, uuid string) (*domain.TodoTemplate, error)
+	All(ctx context.Context, userID uint) ([]*domain.TodoTemplate, error)
+
+	// CreateTodo creates a todo from a template like any other, the rules of the user apply to it.
+	// A relative due date is counted from now in the timezone of the user.
+	CreateTodo(ctx context.Context, userID uint, uuid string, fromTemplate *domain.TodoFromTemplate) (*domain.Todo, error)
+}
+
+type templateService struct {
+	*BaseService
+
+	todoService TodoService
+
+	templateRepo repo.TemplateRepo
+	userRepo     repo.UserRepo
+}
+
+func NewTemplateService(base *BaseService, todoService TodoService, templateRepo repo.TemplateRepo, userRepo repo.UserRepo) *templateService {
+	return &templateService{
+		BaseService:  base,
+		todoService:  todoService,
+		templateRepo: templateRepo,
+		userRepo:     userRepo,
+	}
+}
+
+// check TemplateService interface implementation on compile time.
+var _ TemplateService = (*templateService)(nil)
+
+func (s *templateService) Create(ctx context.Context, userID uint, templateCreate *domain.TodoTemplateCreate) (*domain.TodoTemplate, error) {
+	template, err := s.template(userID, templateCreate)
+	if err != nil {
+		return nil, err
+	}
+	template.UUID = s.GenerateUUIDHash("template")
+
+	created, err := s.templateRepo.Create(ctx, template)
+	if err != nil {
+		log.Err(err).Msg("error creating todo template")
+		return nil, fmt.Errorf("error creating todo template: %w", err)
+	}
+
+	return created, nil
+}
+
+func (s *templateService) Update(
+	ctx context.Context,
+	userID uint,
+	uuid string,
+	templateCreate *domain.TodoTemplateCreate,
+) (*domain.TodoTemplate, error) {
+	template, err := s.template(userID, templateCreate)
+	if err != nil {
+		return nil, err
+	}
+	template.UUID = uuid
+
+	updated, err := s.templateRepo.Update(ctx, template)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("todo template not found: %w", domain.ErrTodoTemplateNotFound)
+		}
+		log.Err(err).Msg("error updating todo template")
+		return nil, fmt.Errorf("error updating todo template: %w", err)
+	}
+
+	return updated, nil
+}
+
+func (s *templateService) Delete(ctx context.Context, userID uint, uuid string) error {
+	if _, err := s.ByUUID(ctx, userID, uuid); err != nil {
+		return err
+	}
+
+	if err := s.templateRepo.Delete(ctx, userID, uuid); err != nil {
+		log.Err(err).Msg("error deleting todo template")
+		return fmt.Errorf("error deleting todo template: %w", err)
+	}
+
+	return nil
+}
+
+func (s *templateService) ByUUID(ctx context.Context, userID uint, uuid string) (*domain.TodoTemplate, error) {
+	template, err := s.templateRepo.ByUUID(ctx, userID, uuid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("todo template not found: %w", domain.ErrTodoTemplateNotFound)
+		}
+		log.Err(err).Msg("error retrieving todo template")
+		return nil, err
+	}
+
+	return template, nil
+}
+
+func (s *templateService) All(ctx context.Context, userID uint) ([]*domain.TodoTemplate, error) {
+	templates, err := s.templateRepo.All(ctx, userID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving todo templates")
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+func (s *templateService) CreateTodo(
+	ctx context.Context,
+	userID uint,
+	uuid string,
+	fromTemplate *domain.TodoFromTemplate,
+) (*domain.Todo, error) {
+	template, err := s.ByUUID(ctx, userID, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	todoCreate := template.TodoCreate(time.Now(), userLocation(ctx, s.userRepo, userID))
+	todoCreate.Source = domain.CaptureSourceApp
+	if fromTemplate != nil {
+		todoCreate.ListUUID = fromTemplate.ListUUID
+		if fromTemplate.Title != "" {
+			todoCreate.Title = fromTemplate.Title
+		}
+	}
+
+	return s.todoService.Create(ctx, userID, todoCreate)
+}
+
+// template checks the definition of a template of the user.
+func (s *templateService) template(userID uint, templateCreate *domain.TodoTemplateCreate) (*domain.TodoTemplate, error) {
+	if templateCreate == nil {
+		return nil, fmt.Errorf("no todo template provided")
+	}
+	if err := templateCreate.Validate(); err != nil {
+		return nil, err
+	}
+
+	template := &domain.TodoTemplate{
+		UserID:      userID,
+		Name:        templateCreate.Name,
+		Title:       templateCreate.Title,
+		Description: templateCreate.Description,
+		Priority:    templateCreate.Priority,
+		Estimate:    templateCreate.Estimate,
+		Tags:        templateCreate.Tags,
+		Subtasks:    templateCreate.Subtasks,
+		DueIn:       templateCreate.DueIn,
+	}
+	if template.Name == "" {
+		template.Name = template.Title
+	}
+
+	return template, nil
+}
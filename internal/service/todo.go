@@ -0,0 +1,849 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/fuzzy"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/pagination"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+type TodoService interface {
+	Create(ctx context.Context, userID uint, todoCreate *domain.TodoCreate) (*domain.Todo, error)
+	Update(ctx context.Context, userID uint, uuid string, todoUpdate *domain.TodoUpdate) (*domain.Todo, error)
+	Delete(ctx context.Context, userID uint, uuid string) error
+	// Move relocates a todo to another list, possibly of another household, together with its
+	// comments, attachments and history. Tags are recreated for the new owner by name.
+	Move(ctx context.Context, userID uint, uuid string, todoMove *domain.TodoMove) (*domain.Todo, error)
+	// Split turns the checklist items of a todo into todos of their own, in order and with their
+	// completion state, and removes them from its description. Items of todoSplit, e.g. of a
+	// breakdown, are created instead when given.
+	Split(ctx context.Context, userID uint, uuid string, todoSplit *domain.TodoSplit) (*domain.TodoSplitResult, error)
+	// Place moves a todo on the board of its list to another status or position, the other todos
+	// of the column move up or down. Moving it into or out of a done status completes or reopens it.
+	Place(ctx context.Context, userID uint, uuid string, placement *domain.TodoPlacement) (*domain.Todo, error)
+	// Reorder moves a todo in the order of its owner by giving it a rank between its new
+	// neighbours, the other todos keep theirs.
+	Reorder(ctx context.Context, userID uint, uuid string, reorder *domain.TodoReorder) (*domain.Todo, error)
+
+	ByUUID(ctx context.Context, userID uint, uuid string) (*domain.Todo, error)
+	// Writable returns the todo if the user is allowed to change it.
+	Writable(ctx context.Context, userID uint, uuid string) (*domain.Todo, error)
+	// Match resolves an open todo by a fuzzy or partial title, optionally within a list. Several
+	// similar matches return an *domain.AmbiguousTodoMatchError with the candidates.
+	Match(ctx context.Context, userID uint, listUUID string, title string) (*domain.TodoMatch, error)
+	All(ctx context.Context, userID uint, filter *domain.TodoFilter, page *pagination.Page) ([]*domain.Todo, *pagination.Cursor, error)
+}
+
+// TodoEventHandler is notified after a todo changed. Handlers run synchronously within the
+// request, so they should only queue work and never fail it.
+type TodoEventHandler interface {
+	HandleTodoEvent(ctx context.Context, event *domain.TodoEvent)
+}
+
+type todoService struct {
+	*BaseService
+
+	tagService       TagService
+	listService      ListService
+	householdService HouseholdService
+	ruleService      RuleService
+
+	todoRepo       repo.TodoRepo
+	encryptionRepo repo.EncryptionRepo
+	listStatusRepo repo.ListStatusRepo
+	dependencyRepo repo.TodoDependencyRepo
+	txManager      repo.TxManager
+
+	handlers []TodoEventHandler
+}
+
+func NewTodoService(
+	base *BaseService,
+	tagService TagService,
+	listService ListService,
+	householdService HouseholdService,
+	ruleService RuleService,
+	todoRepo repo.TodoRepo,
+	encryptionRepo repo.EncryptionRepo,
+	listStatusRepo repo.ListStatusRepo,
+	dependencyRepo repo.TodoDependencyRepo,
+	txManager repo.TxManager,
+) *todoService {
+	return &todoService{
+		BaseService:      base,
+		tagService:       tagService,
+		listService:      listService,
+		householdService: householdService,
+		ruleService:      ruleService,
+		todoRepo:         todoRepo,
+		encryptionRepo:   encryptionRepo,
+		listStatusRepo:   listStatusRepo,
+		dependencyRepo:   dependencyRepo,
+		txManager:        txManager,
+	}
+}
+
+// check TodoService interface implementation on compile time.
+var _ TodoService = (*todoService)(nil)
+
+// Subscribe registers a handler for todo events, it must be called before serving requests.
+func (s *todoService) Subscribe(handler TodoEventHandler) {
+	s.handlers = append(s.handlers, handler)
+}
+
+func (s *todoService) Create(ctx context.Context, userID uint, todoCreate *domain.TodoCreate) (*domain.Todo, error) {
+	if todoCreate == nil {
+		return nil, fmt.Errorf("no todo details provided")
+	}
+
+	// rules can't read encrypted todos, so they are left to the client
+	if todoCreate.CreatedAt.IsZero() && todoCreate.Ciphertext == "" {
+		outcome, err := s.ruleService.Evaluate(ctx, userID, &domain.RuleSubject{
+			Title:       todoCreate.Title,
+			Description: todoCreate.Description,
+			Source:      todoCreate.Source,
+			Channel:     todoCreate.Channel,
+		})
+		if err != nil {
+			return nil, err
+		}
+		todoCreate.Tags = append(todoCreate.Tags, outcome.Tags...)
+		// rules only compute the text of new todos, an edit is never rewritten behind the user's back
+		if outcome.Title != "" {
+			todoCreate.Title = outcome.Title
+		}
+		if outcome.Description != "" {
+			todoCreate.Description = outcome.Description
+		}
+		// new todos can't tell a picked priority from the default one, so the rules win
+		if outcome.Priority != nil {
+			todoCreate.Priority = *outcome.Priority
+		}
+		// a list picked by the user wins over the rules
+		if todoCreate.ListUUID == "" {
+			todoCreate.ListUUID = outcome.ListUUID
+		}
+	}
+
+	// todos of a shared list belong to the list owner, no matter which collaborator created them
+	ownerID := userID
+	var list *domain.List
+	if todoCreate.ListUUID != "" {
+		access, err := s.listService.Access(ctx, userID, todoCreate.ListUUID)
+		if err != nil {
+			return nil, err
+		}
+		if !access.Role.CanWrite() {
+			return nil, domain.ErrListReadOnly
+		}
+		list = access.List
+		todoCreate.ListID = access.List.ID
+		ownerID = access.List.UserID
+	}
+	plaintext := todoCreate.Title != "" || todoCreate.Description != ""
+	err := checkCiphertext(list, plaintext, todoCreate.Ciphertext, todoCreate.KeyVersion, len(todoCreate.BlindIndexes))
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := s.tagService.Ensure(ctx, ownerID, todoCreate.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	todo, err := s.todoRepo.Create(ctx, s.GenerateUUIDHash("todo"), ownerID, todoCreate, tagIDs(tags))
+	if err != nil {
+		log.Err(err).Msg("error creating todo")
+		return nil, fmt.Errorf("error creating todo: %w", err)
+	}
+
+	s.publish(ctx, domain.EventTodoCreated, todo)
+
+	return todo, nil
+}
+
+func (s *todoService) Update(ctx context.Context, userID uint, uuid string, todoUpdate *domain.TodoUpdate) (*domain.Todo, error) {
+	todo, err := s.todo(ctx, userID, uuid, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// a stale version is refused right away, the update below catches changes made since
+	if todoUpdate.Version != nil && *todoUpdate.Version != todo.Version {
+		return nil, fmt.Errorf("todo %s is at version %d: %w", uuid, todo.Version, domain.ErrConflict)
+	}
+
+	if err = s.checkEncryptedUpdate(ctx, userID, todo, todoUpdate); err != nil {
+		return nil, err
+	}
+
+	// rules only see changes to the text, so tags removed by hand stay removed on other updates
+	var outcome *domain.RuleOutcome
+	if todoUpdate.Title != nil || todoUpdate.Description != nil {
+		subject := &domain.RuleSubject{Title: todo.Title, Description: todo.Description}
+		if todoUpdate.Title != nil {
+			subject.Title = *todoUpdate.Title
+		}
+		if todoUpdate.Description != nil {
+			subject.Description = *todoUpdate.Description
+		}
+		if outcome, err = s.ruleService.Evaluate(ctx, userID, subject); err != nil {
+			return nil, err
+		}
+		// a priority set along with the change wins over the rules
+		if todoUpdate.Priority == nil {
+			todoUpdate.Priority = outcome.Priority
+		}
+	}
+
+	wasCompleted := todo.Completed()
+	if todoUpdate.Title != nil {
+		todo.Title = *todoUpdate.Title
+	}
+	if todoUpdate.Description != nil {
+		todo.Description = *todoUpdate.Description
+	}
+	if todoUpdate.Priority != nil {
+		todo.Priority = *todoUpdate.Priority
+	}
+	if todoUpdate.DueDate != nil {
+		// moving a due date later counts as postponing the todo
+		if !todo.DueDate.IsZero() && todoUpdate.DueDate.After(todo.DueDate) {
+			todo.Postponed++
+		}
+		todo.DueDate = *todoUpdate.DueDate
+	}
+	if todoUpdate.Estimate != nil {
+		todo.Estimate = *todoUpdate.Estimate
+	}
+	if todoUpdate.Completed != nil {
+		switch {
+		case *todoUpdate.Completed && !todo.Completed():
+			if !todoUpdate.Force {
+				if err = s.checkBlockers(ctx, todo); err != nil {
+					return nil, err
+				}
+			}
+			todo.CompletedAt = time.Now().UTC()
+		case !*todoUpdate.Completed:
+			todo.CompletedAt = time.Time{}
+		}
+	}
+	if todoUpdate.Ciphertext != nil {
+		todo.Ciphertext = *todoUpdate.Ciphertext
+		todo.KeyVersion = *todoUpdate.KeyVersion
+	}
+
+	err = s.txManager.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.todoRepo.Update(ctx, todo); err != nil {
+			return err
+		}
+		if todoUpdate.BlindIndexes == nil {
+			return nil
+		}
+
+		return s.encryptionRepo.SetBlindIndexes(ctx, todo.ID, todoUpdate.BlindIndexes)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("todo %s was changed while updating it: %w", uuid, domain.ErrConflict)
+	}
+	if err != nil {
+		log.Err(err).Msg("error updating todo")
+		return nil, fmt.Errorf("error updating todo: %w", err)
+	}
+
+	// lists are only picked by rules for new todos, moving a todo is left to the user
+	if outcome != nil && len(outcome.Tags) > 0 {
+		tags, err := s.tagService.Assign(ctx, todo, outcome.Tags)
+		if err != nil {
+			return nil, err
+		}
+		for _, tag := range tags {
+			if !slices.ContainsFunc(todo.Tags, func(t *domain.Tag) bool { return t.ID == tag.ID }) {
+				todo.Tags = append(todo.Tags, tag)
+			}
+		}
+	}
+
+	s.publish(ctx, domain.EventTodoUpdated, todo)
+	if !wasCompleted && todo.Completed() {
+		s.publish(ctx, domain.EventTodoCompleted, todo)
+	}
+
+	return todo, nil
+}
+
+func (s *todoService) Delete(ctx context.Context, userID uint, uuid string) error {
+	if err := s.householdService.Permit(ctx, userID, domain.ParentalActionDelete); err != nil {
+		return err
+	}
+
+	todo, err := s.todo(ctx, userID, uuid, true)
+	if err != nil {
+		return err
+	}
+
+	err = s.todoRepo.Delete(ctx, todo.UserID, uuid)
+	if err != nil {
+		log.Err(err).Msg("error deleting todo")
+		return fmt.Errorf("error deleting todo: %w", err)
+	}
+
+	s.publish(ctx, domain.EventTodoDeleted, todo)
+
+	return nil
+}
+
+func (s *todoService) Move(ctx context.Context, userID uint, uuid string, todoMove *domain.TodoMove) (*domain.Todo, error) {
+	todo, err := s.todo(ctx, userID, uuid, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if todoMove.Version != nil && *todoMove.Version != todo.Version {
+		return nil, fmt.Errorf("todo %s is at version %d: %w", uuid, todo.Version, domain.ErrConflict)
+	}
+
+	// the todo would have to be encrypted with the key of the other list, which only clients hold
+	if todo.Encrypted() {
+		return nil, domain.ErrTodoEncrypted
+	}
+
+	// like new todos, todos moved out of a list belong to whoever moved them
+	todoMove.ListID = 0
+	todoMove.OwnerID = userID
+	if todoMove.ListUUID != "" {
+		access, err := s.listService.Access(ctx, userID, todoMove.ListUUID)
+		if err != nil {
+			return nil, err
+		}
+		if !access.Role.CanWrite() {
+			return nil, domain.ErrListReadOnly
+		}
+		if access.List.Encrypted() {
+			return nil, domain.ErrListEncrypted
+		}
+		todoMove.ListID = access.List.ID
+		todoMove.OwnerID = access.List.UserID
+	}
+
+	if todoMove.ListID == todo.ListID && todoMove.OwnerID == todo.UserID {
+		return todo, nil
+	}
+
+	ids := tagIDs(todo.Tags)
+	if todoMove.OwnerID != todo.UserID {
+		// the comments and attachments go along, so handing a todo to another account is sharing
+		if err = s.householdService.Permit(ctx, userID, domain.ParentalActionShare); err != nil {
+			return nil, err
+		}
+
+		names := make([]string, 0, len(todo.Tags))
+		for _, tag := range todo.Tags {
+			names = append(names, tag.Name)
+		}
+		tags, err := s.tagService.Ensure(ctx, todoMove.OwnerID, names)
+		if err != nil {
+			return nil, err
+		}
+		ids = tagIDs(tags)
+	}
+
+	previous := *todo
+	if err = s.todoRepo.Move(ctx, todo, todoMove, ids); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("todo %s changed while moving it: %w", uuid, domain.ErrConflict)
+		}
+		log.Err(err).Msg("error moving todo")
+		return nil, fmt.Errorf("error moving todo: %w", err)
+	}
+
+	moved, err := s.todoRepo.ByUUID(ctx, todo.UserID, uuid)
+	if err != nil {
+		log.Err(err).Msg("error retrieving moved todo")
+		return nil, err
+	}
+
+	s.dispatch(ctx, &domain.TodoEvent{
+		Type:       domain.EventTodoMoved,
+		Todo:       moved,
+		Previous:   &previous,
+		OccurredAt: time.Now().UTC(),
+	})
+
+	return moved, nil
+}
+
+func (s *todoService) Split(ctx context.Context, userID uint, uuid string, todoSplit *domain.TodoSplit) (*domain.TodoSplitResult, error) {
+	if todoSplit.NewList && todoSplit.ListUUID != "" {
+		return nil, domain.ErrSplitListAmbiguous
+	}
+
+	todo, err := s.todo(ctx, userID, uuid, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if todoSplit.Version != nil && *todoSplit.Version != todo.Version {
+		return nil, fmt.Errorf("todo %s is at version %d: %w", uuid, todo.Version, domain.ErrConflict)
+	}
+	if todo.Encrypted() {
+		return nil, domain.ErrTodoEncrypted
+	}
+
+	// explicit items leave the description alone, only a checklist is moved out of it
+	items, description := todoSplit.Items, todo.Description
+	if items == nil {
+		items, description = domain.ParseChecklist(todo.Description)
+	}
+	changed := description != todo.Description
+	if len(items) == 0 {
+		return nil, domain.ErrNoChecklist
+	}
+	if len(items) > domain.MaxChecklistItems {
+		return nil, fmt.Errorf("%d items, at most %d: %w", len(items), domain.MaxChecklistItems, domain.ErrChecklistTooLong)
+	}
+
+	// the new todos stay next to the todo unless they go to another list, like new todos they
+	// belong to the owner of that list
+	target := &domain.TodoCreate{ListUUID: todo.ListUUID, ListID: todo.ListID}
+	ownerID := todo.UserID
+	switch {
+	case todoSplit.NewList:
+		target.ListUUID, target.ListID = "", 0
+		ownerID = userID
+	case todoSplit.ListUUID != "":
+		access, err := s.listService.Access(ctx, userID, todoSplit.ListUUID)
+		if err != nil {
+			return nil, err
+		}
+		if !access.Role.CanWrite() {
+			return nil, domain.ErrListReadOnly
+		}
+		if access.List.Encrypted() {
+			return nil, domain.ErrListEncrypted
+		}
+		target.ListUUID, target.ListID = access.List.UUID, access.List.ID
+		ownerID = access.List.UserID
+	}
+	if ownerID != todo.UserID {
+		// the todos carry parts of the description, so creating them for another account is sharing
+		if err = s.householdService.Permit(ctx, userID, domain.ParentalActionShare); err != nil {
+			return nil, err
+		}
+	}
+
+	names := make([]string, 0, len(todo.Tags))
+	for _, tag := range todo.Tags {
+		names = append(names, tag.Name)
+	}
+
+	result := &domain.TodoSplitResult{Todo: todo}
+	err = s.txManager.WithTx(ctx, func(ctx context.Context) error {
+		if todoSplit.NewList {
+			list, err := s.listService.Create(ctx, userID, todo.Title)
+			if err != nil {
+				return err
+			}
+			result.List = list
+			target.ListUUID, target.ListID = list.UUID, list.ID
+		}
+
+		tags, err := s.tagService.Ensure(ctx, ownerID, names)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		for _, item := range items {
+			created, err := s.todoRepo.Create(ctx, s.GenerateUUIDHash("todo"), ownerID, &domain.TodoCreate{
+				ListUUID: target.ListUUID,
+				ListID:   target.ListID,
+				Title:    item.Title,
+				Priority: todo.Priority,
+				DueDate:  todo.DueDate,
+				Estimate: item.Estimate,
+			}, tagIDs(tags))
+			if err != nil {
+				return fmt.Errorf("error creating todo: %w", err)
+			}
+			if item.Completed {
+				created.CompletedAt = now
+				if err = s.todoRepo.Update(ctx, created); err != nil {
+					return fmt.Errorf("error completing todo: %w", err)
+				}
+			}
+			result.Todos = append(result.Todos, created)
+		}
+
+		if !changed {
+			return nil
+		}
+		todo.Description = description
+		if err = s.todoRepo.Update(ctx, todo); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("todo %s was changed while splitting it: %w", uuid, domain.ErrConflict)
+			}
+			return fmt.Errorf("error updating todo: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, domain.ErrConflict) && !errors.Is(err, domain.ErrQuotaExceeded) {
+			log.Err(err).Msg("error splitting todo")
+		}
+		return nil, err
+	}
+
+	// events go out once the split is committed, a rolled back split never happened
+	for _, created := range result.Todos {
+		s.publish(ctx, domain.EventTodoCreated, created)
+		if created.Completed() {
+			s.publish(ctx, domain.EventTodoCompleted, created)
+		}
+	}
+	if changed {
+		s.publish(ctx, domain.EventTodoUpdated, todo)
+	}
+
+	return result, nil
+}
+
+func (s *todoService) Place(ctx context.Context, userID uint, uuid string, placement *domain.TodoPlacement) (*domain.Todo, error) {
+	todo, err := s.todo(ctx, userID, uuid, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if placement.Version != nil && *placement.Version != todo.Version {
+		return nil, fmt.Errorf("todo %s is at version %d: %w", uuid, todo.Version, domain.ErrConflict)
+	}
+	if todo.ListUUID == "" {
+		return nil, domain.ErrTodoNotOnBoard
+	}
+
+	access, err := s.listService.Access(ctx, userID, todo.ListUUID)
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := listStatuses(ctx, s.listStatusRepo, access.List)
+	if err != nil {
+		return nil, err
+	}
+	todos, _, err := s.todoRepo.All(ctx, todo.UserID, &domain.TodoFilter{ListID: todo.ListID}, nil)
+	if err != nil {
+		log.Err(err).Msg("error retrieving todos")
+		return nil, err
+	}
+	column := domain.NewBoard(access.List, statuses, todos).Column(placement.Status)
+	if column == nil {
+		return nil, fmt.Errorf("%q: %w", placement.Status, domain.ErrListStatusNotFound)
+	}
+
+	// the whole column is numbered again, so todos that weren't placed yet keep their place
+	ids := make([]uint, 0, len(column.Todos)+1)
+	for _, other := range column.Todos {
+		if other.ID != todo.ID {
+			ids = append(ids, other.ID)
+		}
+	}
+	index := len(ids)
+	if placement.Position > 0 && placement.Position <= len(ids) {
+		index = placement.Position - 1
+	}
+	ids = slices.Insert(ids, index, todo.ID)
+
+	wasCompleted := todo.Completed()
+	switch {
+	case column.Status.Done && !wasCompleted:
+		if !placement.Force {
+			if err = s.checkBlockers(ctx, todo); err != nil {
+				return nil, err
+			}
+		}
+		todo.CompletedAt = time.Now().UTC()
+	case !column.Status.Done && wasCompleted:
+		todo.CompletedAt = time.Time{}
+	}
+
+	if err = s.listStatusRepo.Place(ctx, todo, column.Status.Key, ids); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("todo %s was changed while moving it: %w", uuid, domain.ErrConflict)
+		}
+		log.Err(err).Msg("error placing todo")
+		return nil, fmt.Errorf("error placing todo: %w", err)
+	}
+
+	s.publish(ctx, domain.EventTodoUpdated, todo)
+	if !wasCompleted && todo.Completed() {
+		s.publish(ctx, domain.EventTodoCompleted, todo)
+	}
+
+	return todo, nil
+}
+
+func (s *todoService) Reorder(ctx context.Context, userID uint, uuid string, reorder *domain.TodoReorder) (*domain.Todo, error) {
+	todo, err := s.todo(ctx, userID, uuid, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var after *domain.Todo
+	if reorder.AfterUUID != "" {
+		if after, err = s.todo(ctx, userID, reorder.AfterUUID, false); err != nil {
+			return nil, err
+		}
+		if after.UserID != todo.UserID {
+			return nil, domain.ErrReorderOtherOwner
+		}
+		if after.ID == todo.ID {
+			return todo, nil
+		}
+	}
+
+	err = s.txManager.WithTx(ctx, func(ctx context.Context) error {
+		var lower string
+		if after != nil {
+			// todos without a rank follow the ranked ones, so the anchor is ranked last first
+			if after.Rank == "" {
+				last, err := s.todoRepo.LastRank(ctx, todo.UserID)
+				if err != nil {
+					return err
+				}
+				if err = s.todoRepo.Rank(ctx, after, domain.RankBetween(last, "")); err != nil {
+					return err
+				}
+			}
+			lower = after.Rank
+		}
+
+		upper, err := s.todoRepo.NextRank(ctx, todo.UserID, lower, todo.ID)
+		if err != nil {
+			return err
+		}
+		rank := domain.RankBetween(lower, upper)
+		if err = s.todoRepo.Rank(ctx, todo, rank); err != nil {
+			return err
+		}
+		if len(rank) > domain.MaxRankLength {
+			return s.todoRepo.Rerank(ctx, todo.UserID)
+		}
+
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("todo %s was deleted while reordering it: %w", uuid, domain.ErrConflict)
+		}
+		log.Err(err).Msg("error reordering todo")
+		return nil, fmt.Errorf("error reordering todo: %w", err)
+	}
+
+	if len(todo.Rank) > domain.MaxRankLength {
+		return s.todo(ctx, userID, uuid, false)
+	}
+
+	return todo, nil
+}
+
+func (s *todoService) ByUUID(ctx context.Context, userID uint, uuid string) (*domain.Todo, error) {
+	return s.todo(ctx, userID, uuid, false)
+}
+
+func (s *todoService) Writable(ctx context.Context, userID uint, uuid string) (*domain.Todo, error) {
+	return s.todo(ctx, userID, uuid, true)
+}
+
+func (s *todoService) Match(ctx context.Context, userID uint, listUUID string, title string) (*domain.TodoMatch, error) {
+	todos, _, err := s.All(ctx, userID, &domain.TodoFilter{ListUUID: listUUID}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]*domain.TodoMatch, 0)
+	for _, todo := range todos {
+		if todo.Completed() {
+			continue
+		}
+		if confidence := fuzzy.Score(title, todo.Title); confidence >= domain.TodoMatchThreshold {
+			matches = append(matches, &domain.TodoMatch{Todo: todo, Confidence: confidence})
+		}
+	}
+
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%q: %w", title, domain.ErrNoTodoMatch)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Confidence > matches[j].Confidence
+	})
+
+	best := matches[0]
+	candidates := []*domain.TodoMatch{best}
+	for _, match := range matches[1:] {
+		if best.Confidence-match.Confidence < domain.TodoMatchAmbiguity {
+			candidates = append(candidates, match)
+		}
+	}
+	if len(candidates) > 1 {
+		return nil, &domain.AmbiguousTodoMatchError{Candidates: candidates}
+	}
+
+	return best, nil
+}
+
+func (s *todoService) All(ctx context.Context, userID uint, filter *domain.TodoFilter, page *pagination.Page) ([]*domain.Todo, *pagination.Cursor, error) {
+	ownerID := userID
+	if filter != nil {
+		filter.Tags = domain.NormalizeTagNames(filter.Tags)
+		filter.TagGroup = domain.NormalizeTagGroup(filter.TagGroup)
+
+		if filter.ListUUID != "" {
+			access, err := s.listService.Access(ctx, userID, filter.ListUUID)
+			if err != nil {
+				return nil, nil, err
+			}
+			filter.ListID = access.List.ID
+			ownerID = access.List.UserID
+		}
+	}
+
+	todos, next, err := s.todoRepo.All(ctx, ownerID, filter, page)
+	if err != nil {
+		log.Err(err).Msg("error retrieving todos")
+		return nil, nil, err
+	}
+
+	return todos, next, nil
+}
+
+func (s *todoService) publish(ctx context.Context, eventType domain.EventType, todo *domain.Todo) {
+	s.dispatch(ctx, &domain.TodoEvent{
+		Type:       eventType,
+		Todo:       todo,
+		OccurredAt: time.Now().UTC(),
+	})
+}
+
+func (s *todoService) dispatch(ctx context.Context, event *domain.TodoEvent) {
+	for _, handler := range s.handlers {
+		handler.HandleTodoEvent(ctx, event)
+	}
+}
+
+// todo returns a todo the user owns or can access through a shared list, write access
+// requires the editor role on the shared list.
+func (s *todoService) todo(ctx context.Context, userID uint, uuid string, write bool) (*domain.Todo, error) {
+	todo, err := s.todoRepo.ByUUID(ctx, userID, uuid)
+	if err == nil {
+		return todo, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		log.Err(err).Msg("error retrieving todo")
+		return nil, err
+	}
+
+	todo, err = s.todoRepo.ByUUIDShared(ctx, userID, uuid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Err(domain.ErrTodoNotFound).Msg("todo not found")
+			return nil, fmt.Errorf("todo not found: %w", domain.ErrTodoNotFound)
+		}
+		log.Err(err).Msg("error retrieving shared todo")
+		return nil, err
+	}
+
+	if write {
+		access, err := s.listService.Access(ctx, userID, todo.ListUUID)
+		if err != nil {
+			return nil, err
+		}
+		if !access.Role.CanWrite() {
+			return nil, domain.ErrListReadOnly
+		}
+	}
+
+	return todo, nil
+}
+
+// checkBlockers refuses to complete a todo that open todos still block.
+func (s *todoService) checkBlockers(ctx context.Context, todo *domain.Todo) error {
+	blockers, err := s.dependencyRepo.OpenBlockers(ctx, todo)
+	if err != nil {
+		log.Err(err).Msg("error counting blocking todos")
+		return err
+	}
+	if blockers > 0 {
+		return fmt.Errorf("todo %s is blocked by %d open todos: %w", todo.UUID, blockers, domain.ErrTodoBlocked)
+	}
+
+	return nil
+}
+
+// checkEncryptedUpdate refuses plaintext for encrypted todos and ciphertext for the others. New
+// ciphertext must be encrypted with the current key of the list.
+func (s *todoService) checkEncryptedUpdate(ctx context.Context, userID uint, todo *domain.Todo, todoUpdate *domain.TodoUpdate) error {
+	if !todo.Encrypted() {
+		if todoUpdate.Ciphertext != nil || todoUpdate.BlindIndexes != nil {
+			return domain.ErrListNotEncrypted
+		}
+		return nil
+	}
+
+	if todoUpdate.Title != nil || todoUpdate.Description != nil {
+		return domain.ErrListEncrypted
+	}
+	if len(todoUpdate.BlindIndexes) > domain.MaxBlindIndexes {
+		return domain.ErrTooManyBlindIndexes
+	}
+	if todoUpdate.Ciphertext == nil {
+		return nil
+	}
+
+	access, err := s.listService.Access(ctx, userID, todo.ListUUID)
+	if err != nil {
+		return err
+	}
+	// without a key version the ciphertext is stale, which also keeps Update from applying it
+	keyVersion := 0
+	if todoUpdate.KeyVersion != nil {
+		keyVersion = *todoUpdate.KeyVersion
+	}
+
+	return checkCiphertext(access.List, false, *todoUpdate.Ciphertext, keyVersion, len(todoUpdate.BlindIndexes))
+}
+
+// checkCiphertext checks a todo of list, nil for todos without a list. Todos of encrypted lists
+// carry ciphertext instead of plaintext, encrypted with the current key of the list, and only
+// they may carry ciphertext and blind indexes.
+func checkCiphertext(list *domain.List, plaintext bool, ciphertext string, keyVersion int, blindIndexes int) error {
+	if list == nil || !list.Encrypted() {
+		if ciphertext != "" || blindIndexes > 0 {
+			return domain.ErrListNotEncrypted
+		}
+		return nil
+	}
+
+	if plaintext || ciphertext == "" {
+		return domain.ErrListEncrypted
+	}
+	if keyVersion != list.KeyVersion {
+		return fmt.Errorf("list %s is at key version %d: %w", list.UUID, list.KeyVersion, domain.ErrStaleListKey)
+	}
+	if blindIndexes > domain.MaxBlindIndexes {
+		return domain.ErrTooManyBlindIndexes
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+// TodoDependencyService marks todos as blocked by others. TodoService refuses to complete a todo
+// while the todos blocking it are open, unless it is forced.
+type TodoDependencyService interface {
+	// Graph returns the todos the todo waits for and those waiting for it, directly or through
+	// others.
+	Graph(ctx context.Context, userID uint, uuid string) (*domain.DependencyGraph, error)
+	// Block marks a todo the user can change as blocked by another todo of the same list, a
+	// dependency that would make a cycle is refused.
+	Block(ctx context.Context, userID uint, uuid string, block *domain.TodoBlock) (*domain.DependencyGraph, error)
+	Unblock(ctx context.Context, userID uint, uuid string, blockerUUID string) error
+}
+
+type todoDependencyService struct {
+	*BaseService
+
+	todoService TodoService
+
+	dependencyRepo repo.TodoDependencyRepo
+}
+
+func NewTodoDependencyService(base *BaseService, todoService TodoService, dependencyRepo repo.TodoDependencyRepo) *todoDependencyService {
+	return &todoDependencyService{
+		BaseService:    base,
+		todoService:    todoService,
+		dependencyRepo: dependencyRepo,
+	}
+}
+
+// check TodoDependencyService interface implementation on compile time.
+var _ TodoDependencyService = (*todoDependencyService)(nil)
+
+func (s *todoDependencyService) Graph(ctx context.Context, userID uint, uuid string) (*domain.DependencyGraph, error) {
+	todo, err := s.todoService.ByUUID(ctx, userID, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.graph(ctx, todo)
+}
+
+func (s *todoDependencyService) Block(
+	ctx context.Context,
+	userID uint,
+	uuid string,
+	block *domain.TodoBlock,
+) (*domain.DependencyGraph, error) {
+	todo, err := s.todoService.Writable(ctx, userID, uuid)
+	if err != nil {
+		return nil, err
+	}
+	blocker, err := s.todoService.ByUUID(ctx, userID, block.BlockerUUID)
+	if err != nil {
+		return nil, err
+	}
+	if blocker.ID == todo.ID {
+		return nil, domain.ErrDependencySelf
+	}
+	// the graph of a todo is shown to everyone who can read it, so it stays within its list
+	if blocker.UserID != todo.UserID || blocker.ListID != todo.ListID {
+		return nil, domain.ErrDependencyOtherList
+	}
+
+	if err = s.dependencyRepo.Add(ctx, todo, blocker, userID); err != nil {
+		if errors.Is(err, domain.ErrDependencyCycle) || errors.Is(err, domain.ErrTooManyBlockers) {
+			return nil, err
+		}
+		log.Err(err).Msg("error adding todo dependency")
+		return nil, fmt.Errorf("error adding todo dependency: %w", err)
+	}
+
+	return s.graph(ctx, todo)
+}
+
+func (s *todoDependencyService) Unblock(ctx context.Context, userID uint, uuid string, blockerUUID string) error {
+	todo, err := s.todoService.Writable(ctx, userID, uuid)
+	if err != nil {
+		return err
+	}
+	blocker, err := s.todoService.ByUUID(ctx, userID, blockerUUID)
+	if err != nil {
+		return err
+	}
+
+	if err = s.dependencyRepo.Remove(ctx, todo.ID, blocker.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrDependencyNotFound
+		}
+		log.Err(err).Msg("error removing todo dependency")
+		return fmt.Errorf("error removing todo dependency: %w", err)
+	}
+
+	return nil
+}
+
+func (s *todoDependencyService) graph(ctx context.Context, todo *domain.Todo) (*domain.DependencyGraph, error) {
+	graph, err := s.dependencyRepo.Graph(ctx, todo)
+	if err != nil {
+		log.Err(err).Msg("error retrieving dependency graph")
+		return nil, err
+	}
+
+	return graph, nil
+}
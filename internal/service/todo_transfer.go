@@ -0,0 +1,354 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/export"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+	"github.com/meowmix1337/the_recipe_book/internal/storage"
+
+	"github.com/rs/zerolog/log"
+)
+
+// TodoTransferService exports todos to and imports them from CSV and JSON files.
+type TodoTransferService interface {
+	// Export writes the selected todos to w, archives also hold a manifest of the export and
+	// optionally the attachments of the todos.
+	Export(ctx context.Context, userID uint, selection *domain.TodoExport, w io.Writer) error
+	// Planner writes the todos matching the filter that are due in the week as a printable
+	// week at a glance.
+	Planner(ctx context.Context, userID uint, filter *domain.TodoFilter, week *domain.PlannerWeek, format domain.PlannerFormat, w io.Writer) error
+	// Import creates the todos read from r. Rows that fail validation and todos that already
+	// exist are skipped and reported, a dry run only reports what would be created.
+	Import(ctx context.Context, userID uint, r io.Reader, todoImport *domain.TodoImport) (*domain.TodoImportResult, error)
+}
+
+type todoTransferService struct {
+	*BaseService
+
+	todoService TodoService
+	listService ListService
+
+	attachmentRepo repo.AttachmentRepo
+	storage        storage.Storage
+}
+
+func NewTodoTransferService(
+	base *BaseService,
+	todoService TodoService,
+	listService ListService,
+	attachmentRepo repo.AttachmentRepo,
+	storage storage.Storage,
+) *todoTransferService {
+	return &todoTransferService{
+		BaseService:    base,
+		todoService:    todoService,
+		listService:    listService,
+		attachmentRepo: attachmentRepo,
+		storage:        storage,
+	}
+}
+
+// check TodoTransferService interface implementation on compile time.
+var _ TodoTransferService = (*todoTransferService)(nil)
+
+func (s *todoTransferService) Export(ctx context.Context, userID uint, selection *domain.TodoExport, w io.Writer) error {
+	if selection.Attachments && selection.Format != domain.TodoFormatZIP {
+		return domain.ErrAttachmentsNeedArchive
+	}
+
+	todos, err := s.selected(ctx, userID, selection)
+	if err != nil {
+		return err
+	}
+
+	switch selection.Format {
+	case domain.TodoFormatCSV:
+		err = export.WriteTodosCSV(w, todos)
+	case domain.TodoFormatJSON:
+		err = export.WriteTodosJSON(w, todos)
+	case domain.TodoFormatZIP:
+		err = s.writeArchive(ctx, userID, selection, todos, w)
+	default:
+		return fmt.Errorf("%q: %w", selection.Format, domain.ErrInvalidTodoFormat)
+	}
+	if err != nil {
+		log.Err(err).Msg("error writing todo export")
+		return fmt.Errorf("error writing todo export: %w", err)
+	}
+
+	return nil
+}
+
+// selected returns the todos of the selection. Lists are read one at a time since lists shared
+// with the user belong to someone else, a todo is exported once even when lists repeat.
+func (s *todoTransferService) selected(ctx context.Context, userID uint, selection *domain.TodoExport) ([]*domain.Todo, error) {
+	filter := domain.TodoFilter{}
+	if selection.Filter != nil {
+		filter = *selection.Filter
+	}
+	filter.ListUUID = ""
+	filter.ListID = 0
+
+	if len(selection.ListUUIDs) == 0 {
+		todos, _, err := s.todoService.All(ctx, userID, &filter, nil)
+		return todos, err
+	}
+
+	todos := make([]*domain.Todo, 0)
+	seen := make(map[string]bool, len(selection.ListUUIDs))
+	for _, listUUID := range selection.ListUUIDs {
+		if seen[listUUID] {
+			continue
+		}
+		seen[listUUID] = true
+
+		listFilter := filter
+		listFilter.ListUUID = listUUID
+
+		listTodos, _, err := s.todoService.All(ctx, userID, &listFilter, nil)
+		if err != nil {
+			return nil, err
+		}
+		todos = append(todos, listTodos...)
+	}
+
+	return todos, nil
+}
+
+func (s *todoTransferService) writeArchive(
+	ctx context.Context, userID uint, selection *domain.TodoExport, todos []*domain.Todo, w io.Writer,
+) error {
+	archive := &export.TodoArchive{
+		Selection:  selection,
+		Todos:      todos,
+		Open:       s.openAttachment,
+		ExportedAt: time.Now(),
+	}
+
+	// the lists are restored with the todos, including selected lists without any
+	seen := make(map[string]bool)
+	for _, listUUID := range append(slices.Clone(selection.ListUUIDs), todoListUUIDs(todos)...) {
+		if listUUID == "" || seen[listUUID] {
+			continue
+		}
+		seen[listUUID] = true
+
+		shared, err := s.listService.Access(ctx, userID, listUUID)
+		if err != nil {
+			return err
+		}
+		archive.Lists = append(archive.Lists, shared.List)
+	}
+
+	if selection.Attachments {
+		for _, todo := range todos {
+			attachments, err := s.attachmentRepo.All(ctx, todo.ID)
+			if err != nil {
+				log.Err(err).Msg("error retrieving attachments for export")
+				return err
+			}
+			archive.Attachments = append(archive.Attachments, attachments...)
+		}
+	}
+
+	return export.WriteTodoArchive(ctx, w, archive)
+}
+
+func todoListUUIDs(todos []*domain.Todo) []string {
+	listUUIDs := make([]string, 0, len(todos))
+	for _, todo := range todos {
+		listUUIDs = append(listUUIDs, todo.ListUUID)
+	}
+
+	return listUUIDs
+}
+
+func (s *todoTransferService) openAttachment(ctx context.Context, attachment *domain.Attachment) (io.ReadCloser, error) {
+	return s.storage.Open(ctx, attachment.StorageKey)
+}
+
+func (s *todoTransferService) Planner(
+	ctx context.Context, userID uint, filter *domain.TodoFilter, week *domain.PlannerWeek, format domain.PlannerFormat, w io.Writer,
+) error {
+	if filter == nil {
+		filter = &domain.TodoFilter{}
+	}
+	filter.DueFrom = week.Start
+	filter.DueBefore = week.End()
+	filter.Sort = []domain.TodoSortField{domain.TodoSortDueDate, domain.TodoSortTitle}
+	filter.Order = domain.SortOrderAsc
+
+	todos, _, err := s.todoService.All(ctx, userID, filter, nil)
+	if err != nil {
+		return err
+	}
+
+	planner := export.NewWeeklyPlanner(week, todos)
+	switch format {
+	case domain.PlannerFormatHTML:
+		err = export.WriteWeeklyPlannerHTML(w, planner)
+	case domain.PlannerFormatPDF:
+		err = export.WriteWeeklyPlannerPDF(w, planner)
+	default:
+		return fmt.Errorf("%q: %w", format, domain.ErrInvalidPlannerFormat)
+	}
+	if err != nil {
+		log.Err(err).Msg("error writing weekly planner")
+		return fmt.Errorf("error writing weekly planner: %w", err)
+	}
+
+	return nil
+}
+
+func (s *todoTransferService) Import(
+	ctx context.Context, userID uint, r io.Reader, todoImport *domain.TodoImport,
+) (*domain.TodoImportResult, error) {
+	reader, err := export.NewTodoReader(r, todoImport.Format, todoImport.Mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.TodoImportResult{DryRun: todoImport.DryRun}
+
+	// the whole file is read before anything is created, so an import that is too large or
+	// malformed further down doesn't leave half of its todos behind
+	rows := make([]*domain.TodoImportRow, 0)
+	for {
+		row, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var rowErr *domain.TodoImportError
+		if errors.As(err, &rowErr) {
+			result.Errors = append(result.Errors, rowErr)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) == domain.MaxTodoImportRows {
+			return nil, fmt.Errorf("more than %d todos: %w", domain.MaxTodoImportRows, domain.ErrTodoImportTooLarge)
+		}
+		rows = append(rows, row)
+	}
+
+	existing, err := s.existing(ctx, userID, "")
+	if err != nil {
+		return nil, err
+	}
+	lists := make(map[string]error)
+
+	for _, row := range rows {
+		listUUID := row.Todo.ListUUID
+		if listUUID != "" {
+			listErr, ok := lists[listUUID]
+			if !ok {
+				listErr = s.writableList(ctx, userID, listUUID, existing)
+				lists[listUUID] = listErr
+			}
+			if listErr != nil && !errors.Is(listErr, domain.ErrListNotFound) && !errors.Is(listErr, domain.ErrListReadOnly) {
+				return nil, listErr
+			}
+			if listErr != nil {
+				result.Errors = append(result.Errors, &domain.TodoImportError{Line: row.Line, Message: listErr.Error()})
+				continue
+			}
+		}
+
+		record := &domain.TodoImportRecord{
+			Line:     row.Line,
+			ListUUID: listUUID,
+			Title:    row.Todo.Title,
+			DueDate:  row.Todo.DueDate,
+		}
+		if existing[row.DuplicateKey()] {
+			result.Duplicates = append(result.Duplicates, record)
+			continue
+		}
+		existing[row.DuplicateKey()] = true
+
+		if !todoImport.DryRun {
+			todo, err := s.create(ctx, userID, row)
+			if err != nil {
+				// the todos created so far stay, the result tells which rows are missing
+				log.Err(err).Int("line", row.Line).Msg("error importing todo")
+				result.Errors = append(result.Errors, &domain.TodoImportError{Line: row.Line, Message: "todo could not be created"})
+				continue
+			}
+			record.UUID = todo.UUID
+		}
+		result.Created = append(result.Created, record)
+	}
+
+	slices.SortFunc(result.Errors, func(a, b *domain.TodoImportError) int {
+		return a.Line - b.Line
+	})
+
+	return result, nil
+}
+
+// writableList checks the user can add todos to the list and adds its todos to existing.
+func (s *todoTransferService) writableList(ctx context.Context, userID uint, listUUID string, existing map[string]bool) error {
+	access, err := s.listService.Access(ctx, userID, listUUID)
+	if err != nil {
+		return err
+	}
+	if !access.Role.CanWrite() {
+		return domain.ErrListReadOnly
+	}
+
+	// todos of lists shared with the user belong to the owner, they aren't among the user's own
+	if access.List.UserID != userID {
+		listTodos, err := s.existing(ctx, userID, listUUID)
+		if err != nil {
+			return err
+		}
+		for key := range listTodos {
+			existing[key] = true
+		}
+	}
+
+	return nil
+}
+
+// existing returns the duplicate keys of the todos of the user, or of a list when listUUID is set.
+func (s *todoTransferService) existing(ctx context.Context, userID uint, listUUID string) (map[string]bool, error) {
+	// archived todos are still there, importing them again would duplicate them
+	filter := &domain.TodoFilter{ListUUID: listUUID, Archived: domain.ArchiveInclude}
+
+	todos, _, err := s.todoService.All(ctx, userID, filter, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]bool, len(todos))
+	for _, todo := range todos {
+		keys[domain.TodoDuplicateKey(todo.ListUUID, todo.Title, todo.DueDate)] = true
+	}
+
+	return keys, nil
+}
+
+func (s *todoTransferService) create(ctx context.Context, userID uint, row *domain.TodoImportRow) (*domain.Todo, error) {
+	todo, err := s.todoService.Create(ctx, userID, row.Todo)
+	if err != nil {
+		return nil, err
+	}
+
+	if !row.CompletedAt.IsZero() {
+		completed := true
+		todo, err = s.todoService.Update(ctx, userID, todo.UUID, &domain.TodoUpdate{Completed: &completed})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return todo, nil
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
+)
+
+const tokenBytes = 32
+
+// generateToken returns a random opaque token, the prefix makes the kind of token recognizable.
+func generateToken(prefix string) (string, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return prefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// generateRegionalToken returns a token tagged with the region of ctx, for tokens that are looked
+// up before the region of their user is known, like the bearer tokens of integrations.
+func generateRegionalToken(ctx context.Context, prefix string) (string, error) {
+	token, err := generateToken(prefix)
+	if err != nil {
+		return "", err
+	}
+
+	return region.TagToken(ctx, prefix, token), nil
+}
+
+// tokenContext returns ctx routed to the region token was tagged with by generateRegionalToken.
+func (s *BaseService) tokenContext(ctx context.Context, prefix string, token string) (context.Context, error) {
+	tokenRegion := region.OfToken(prefix, token)
+	if !s.servesRegion(tokenRegion) {
+		return nil, fmt.Errorf("region %q: %w", tokenRegion, domain.ErrUnknownRegion)
+	}
+
+	return region.WithRegion(ctx, tokenRegion), nil
+}
+
+// hashToken is stored instead of the token itself, so a database leak does not leak credentials.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -2,17 +2,27 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
+	"github.com/meowmix1337/the_recipe_book/internal/audit"
+	"github.com/meowmix1337/the_recipe_book/internal/mail"
+	"github.com/meowmix1337/the_recipe_book/internal/metrics"
 	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
 	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/pagination"
+	"github.com/meowmix1337/the_recipe_book/internal/password"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
 	"github.com/meowmix1337/the_recipe_book/internal/repo"
+	"github.com/meowmix1337/the_recipe_book/internal/tracing"
 
 	"github.com/rs/zerolog/log"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type UserService interface {
@@ -20,9 +30,26 @@ type UserService interface {
 	Login(ctx context.Context, userCredentials *domain.UserCredentials) (*endpoint.JWTResponse, error)
 	Logout(ctx context.Context, token string, claims *domain.JWTCustomClaims) error
 	RefreshToken(ctx context.Context, jwtToken string, user *domain.User, refreshToken string, expiresAt time.Time) (*endpoint.JWTResponse, error)
-
+	// Unlock lifts the lockout of the account of an emailed unlock token, userRegion is the
+	// data region from the unlock link. It only proves control of the email address, accounts
+	// have no second factor yet to step up to, the unlock has to require it once they do.
+	Unlock(ctx context.Context, token string, userRegion string) error
+
+	ByID(ctx context.Context, userID uint) (*domain.User, error)
+	// ChangePassword changes the password of the user and signs out every session, the returned
+	// tokens replace those of the session that changed it.
+	ChangePassword(ctx context.Context, userID uint, change *domain.PasswordChange) (*endpoint.JWTResponse, error)
+	// UpdateProfile changes the name, timezone, locale and avatar of the user.
+	UpdateProfile(ctx context.Context, userID uint, update *domain.UserProfileUpdate) (*domain.User, error)
 	ByEmail(ctx context.Context, email string) (*domain.User, error)
 	ByEmailWithPassword(ctx context.Context, email string) (*domain.User, error)
+	All(ctx context.Context, page *pagination.Page) ([]*domain.User, *pagination.Cursor, error)
+}
+
+// SignupHandler is notified after a user signed up. Handlers run synchronously within the
+// request, so they should only queue work and never fail it.
+type SignupHandler interface {
+	HandleSignup(ctx context.Context, user *domain.User)
 }
 
 type userService struct {
@@ -30,73 +57,211 @@ type userService struct {
 
 	authService AuthService
 
-	userRepo repo.UserRepo
+	txManager      repo.TxManager
+	userRepo       repo.UserRepo
+	userRegionRepo repo.UserRegionRepo
+	lockoutRepo    repo.LockoutRepo
+	oauthRepo      repo.OAuthRepo
+
+	sender         mail.Sender
+	securityEvents *SecurityEvents
+
+	signupHandlers []SignupHandler
 }
 
-func NewUserService(base *BaseService, authService AuthService, userRepo repo.UserRepo) *userService {
+func NewUserService(
+	base *BaseService,
+	authService AuthService,
+	txManager repo.TxManager,
+	userRepo repo.UserRepo,
+	userRegionRepo repo.UserRegionRepo,
+	lockoutRepo repo.LockoutRepo,
+	oauthRepo repo.OAuthRepo,
+	sender mail.Sender,
+	securityEvents *SecurityEvents,
+) *userService {
 	return &userService{
-		BaseService: base,
-		authService: authService,
-		userRepo:    userRepo,
+		BaseService:    base,
+		authService:    authService,
+		txManager:      txManager,
+		userRepo:       userRepo,
+		userRegionRepo: userRegionRepo,
+		lockoutRepo:    lockoutRepo,
+		oauthRepo:      oauthRepo,
+		sender:         sender,
+		securityEvents: securityEvents,
 	}
 }
 
 // check UserService interface implementation on compile time.
 var _ UserService = (*userService)(nil)
 
+// Subscribe registers a handler for signups, it must be called before serving requests.
+func (u *userService) Subscribe(handler SignupHandler) {
+	u.signupHandlers = append(u.signupHandlers, handler)
+}
+
 func (u *userService) SignUp(ctx context.Context, userSignup *domain.UserSignup) error {
+	ctx, span := tracing.Start(ctx, "userService.SignUp")
+	defer span.End()
+
 	if userSignup == nil {
 		return fmt.Errorf("no user sign up details provided")
 	}
 
+	if !u.servesRegion(userSignup.Region) {
+		return fmt.Errorf("region %q: %w", userSignup.Region, domain.ErrUnknownRegion)
+	}
+
+	// emails are unique across regions, the directory entry is removed again if the signup fails
+	key := loginKey("email", userSignup.Email)
+	if err := u.userRegionRepo.Create(ctx, key, userSignup.Region); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrUserAlreadyExists
+		}
+		log.Err(err).Msg("error reserving user region")
+		return err
+	}
+	// the directory lives in the home database and can't join the transaction of the region
+	regionCtx := region.WithRegion(ctx, userSignup.Region)
+	var user *domain.User
+	err := u.txManager.WithTx(regionCtx, func(ctx context.Context) error {
+		var err error
+		user, err = u.signUp(ctx, userSignup)
+		return err
+	})
+	if err != nil {
+		if deleteErr := u.userRegionRepo.Delete(ctx, key); deleteErr != nil {
+			log.Err(deleteErr).Msg("error releasing user region")
+		}
+		return err
+	}
+	audit.SetRegion(ctx, userSignup.Region)
+
+	for _, handler := range u.signupHandlers {
+		handler.HandleSignup(regionCtx, user)
+	}
+
+	return nil
+}
+
+func (u *userService) signUp(ctx context.Context, userSignup *domain.UserSignup) (*domain.User, error) {
 	// check if email exists already
 	user, err := u.ByEmail(ctx, userSignup.Email)
 	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
-		return err
+		return nil, err
 	}
 
 	if user != nil {
-		return domain.ErrUserAlreadyExists
+		return nil, domain.ErrUserAlreadyExists
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(userSignup.Password), bcrypt.DefaultCost)
+	hashedPassword, err := u.hashPassword(ctx, userSignup.Password)
 	if err != nil {
 		log.Err(err).Msg("error generating hash password")
-		return err
+		return nil, err
 	}
 
 	// generate uuid
 	uuid := u.GenerateUUIDHash("user")
 
-	err = u.userRepo.Create(ctx, uuid, userSignup.Email, string(hashedPassword))
+	userID, err := u.userRepo.Create(ctx, uuid, userSignup.Email, hashedPassword)
 	if err != nil {
 		log.Err(err).Msg("error creating user")
-		return fmt.Errorf("error creating user: %w", err)
+		return nil, fmt.Errorf("error creating user: %w", err)
 	}
-
-	return nil
+	audit.SetUser(ctx, userID)
+
+	return &domain.User{
+		ID:        userID,
+		UUID:      uuid,
+		Email:     userSignup.Email,
+		Timezone:  "UTC",
+		CreatedAt: time.Now().UTC(),
+	}, nil
 }
 
 func (u *userService) Login(ctx context.Context, userCredentials *domain.UserCredentials) (*endpoint.JWTResponse, error) {
+	ctx, span := tracing.Start(ctx, "userService.Login")
+	defer span.End()
+
+	resp, err := u.login(ctx, userCredentials)
+	switch {
+	case err == nil:
+		metrics.Logins.WithLabelValues(metrics.LoginSuccess).Inc()
+	case errors.Is(err, domain.ErrInvalidCredentials), errors.Is(err, domain.ErrUserNotFound),
+		errors.Is(err, domain.ErrNoCredentialsProvided):
+		metrics.Logins.WithLabelValues(metrics.LoginInvalidCredentials).Inc()
+	case errors.Is(err, domain.ErrAccountLocked):
+		metrics.Logins.WithLabelValues(metrics.LoginLocked).Inc()
+	default:
+		metrics.Logins.WithLabelValues(metrics.LoginError).Inc()
+	}
+
+	return resp, err
+}
+
+func (u *userService) login(ctx context.Context, userCredentials *domain.UserCredentials) (*endpoint.JWTResponse, error) {
 	if userCredentials == nil {
 		log.Err(domain.ErrNoCredentialsProvided).Msg("no credentials were provided")
 		return nil, fmt.Errorf("no user login credentials provided: %w", domain.ErrNoCredentialsProvided)
 	}
 
-	user, err := u.ByEmailWithPassword(ctx, userCredentials.Email)
+	key := loginKey("username", userCredentials.Username)
+	if userCredentials.Email != "" {
+		key = loginKey("email", userCredentials.Email)
+	}
+	userRegion, err := u.userRegionRepo.Region(ctx, key)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Err(err).Msg("error retrieving user region")
+		return nil, err
+	}
+	if !u.servesRegion(userRegion) {
+		log.Error().Str("region", userRegion).Msg("user region is not served by this instance")
+		return nil, fmt.Errorf("region %q: %w", userRegion, domain.ErrUnknownRegion)
+	}
+	ctx = region.WithRegion(ctx, userRegion)
+
+	var user *domain.User
+	if userCredentials.Email != "" {
+		user, err = u.ByEmailWithPassword(ctx, userCredentials.Email)
+	} else {
+		user, err = u.byUsernameWithPassword(ctx, userCredentials.Username)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// locked accounts don't even get their password checked, so guessing is pointless
+	lockedUntil, err := u.lockoutRepo.LockedUntil(ctx, user.ID)
 	if err != nil {
+		log.Err(err).Msg("error retrieving login lockout")
 		return nil, err
 	}
+	if !lockedUntil.IsZero() {
+		u.securityEvents.Publish(ctx, domain.EventSecurityLoginFailed, user, map[string]string{"reason": "locked"})
+		return nil, fmt.Errorf("locked until %s: %w", lockedUntil.Format(time.RFC3339), domain.ErrAccountLocked)
+	}
 
 	// Compare the stored hash with the provided password
-	if err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(userCredentials.Password)); err != nil {
-		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+	rehash, err := u.comparePassword(ctx, user.Password, userCredentials.Password)
+	if err != nil {
+		if errors.Is(err, password.ErrMismatch) {
+			// unknown logins have no household to report to
+			u.securityEvents.Publish(ctx, domain.EventSecurityLoginFailed, user, map[string]string{"reason": "invalid_password"})
 			log.Err(domain.ErrInvalidCredentials).Msg("invalid credentials")
-			return nil, fmt.Errorf("invalid credentials: %w", domain.ErrInvalidCredentials)
+			return nil, u.recordFailure(ctx, user)
 		}
 		log.Err(err).Msg("error comparing password")
 		return nil, err
 	}
+	if err = u.lockoutRepo.Reset(ctx, user.ID); err != nil {
+		log.Err(err).Msg("error resetting failed logins")
+		return nil, err
+	}
+	if rehash {
+		u.rehashPassword(ctx, user.ID, userCredentials.Password)
+	}
 
 	token, err := u.authService.GenerateToken(ctx, user)
 	if err != nil {
@@ -107,6 +272,9 @@ func (u *userService) Login(ctx context.Context, userCredentials *domain.UserCre
 	if err != nil {
 		return nil, err
 	}
+	audit.SetUser(ctx, user.ID)
+	audit.SetRegion(ctx, userRegion)
+	u.securityEvents.Publish(ctx, domain.EventSecurityLogin, user, loginDetails(userCredentials.Client))
 
 	return &endpoint.JWTResponse{
 		Token:        token,
@@ -114,43 +282,181 @@ func (u *userService) Login(ctx context.Context, userCredentials *domain.UserCre
 	}, nil
 }
 
+// loginDetails are the details of a login event, the fingerprint of the device and the country.
+func loginDetails(client *domain.LoginClient) map[string]string {
+	if client == nil {
+		return nil
+	}
+
+	details := map[string]string{"device": client.Fingerprint()}
+	if client.Country != "" {
+		details["country"] = client.Country
+	}
+
+	return details
+}
+
 func (u *userService) Logout(ctx context.Context, token string, claims *domain.JWTCustomClaims) error {
+	ctx, span := tracing.Start(ctx, "userService.Logout")
+	defer span.End()
+
 	err := u.authService.BlacklistToken(ctx, token, claims.UserID, claims.ExpiresAt.Time)
 	if err != nil {
 		return err
 	}
 
-	return u.authService.DeleteRefreshToken(ctx, claims.UserID)
+	if err = u.authService.DeleteRefreshToken(ctx, claims.UserID); err != nil {
+		return err
+	}
+
+	user, err := u.userRepo.ByID(ctx, claims.UserID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving user for security event")
+		return nil
+	}
+	u.securityEvents.Publish(ctx, domain.EventSecurityTokenRevoked, user, map[string]string{"reason": "logout"})
+
+	return nil
 }
 
-func (u *userService) RefreshToken(ctx context.Context, jwtToken string, user *domain.User, refreshToken string, expiresAt time.Time) (*endpoint.JWTResponse, error) {
-	// make sure refresh token exists
-	rt, err := u.authService.ByRefreshToken(ctx, user.ID, refreshToken)
+// rehashPassword replaces an outdated hash while the password is known, e.g. after BCRYPT_COST
+// was raised. Failures are only logged, the old hash keeps working.
+func (u *userService) rehashPassword(ctx context.Context, userID uint, plain string) {
+	hashedPassword, err := u.hashPassword(ctx, plain)
 	if err != nil {
-		log.Err(err).Msg("could not find refresh token")
-		return nil, err
+		log.Err(err).Msg("error rehashing password")
+		return
+	}
+	if err = u.userRepo.UpdatePassword(ctx, userID, hashedPassword); err != nil {
+		log.Err(err).Uint("user_id", userID).Msg("error updating rehashed password")
+	}
+}
+
+// recordFailure counts a failed login and emails an unlock link when it locked the account, it
+// returns the error for the login.
+func (u *userService) recordFailure(ctx context.Context, user *domain.User) error {
+	lockedUntil := time.Now().Add(domain.LoginLockout)
+	locked, err := u.lockoutRepo.RecordFailure(ctx, user.ID, domain.LoginMaxFailures, lockedUntil)
+	if err != nil {
+		log.Err(err).Msg("error recording failed login")
+		return err
+	}
+	if !locked {
+		return fmt.Errorf("invalid credentials: %w", domain.ErrInvalidCredentials)
+	}
+
+	log.Warn().Uint("user_id", user.ID).Msg("account locked after too many failed logins")
+	if err = u.sendUnlock(ctx, user, lockedUntil); err != nil {
+		// the lockout ends on its own, the login still fails with the lockout
+		log.Err(err).Msg("error sending unlock link")
 	}
 
-	// if the token has expired, delete it and return unauthorized
-	if rt.ExpiresAt.Before(time.Now()) {
-		err = u.authService.DeleteRefreshToken(ctx, user.ID)
+	return fmt.Errorf("locked until %s: %w", lockedUntil.Format(time.RFC3339), domain.ErrAccountLocked)
+}
+
+// sendUnlock emails an unlock link to the user, child accounts have no email so their parent
+// gets it.
+func (u *userService) sendUnlock(ctx context.Context, user *domain.User, lockedUntil time.Time) error {
+	recipient := user.Email
+	if user.IsChild() {
+		parent, err := u.userRepo.ByID(ctx, user.ParentID)
 		if err != nil {
-			return nil, err
+			return fmt.Errorf("error retrieving parent account: %w", err)
 		}
+		recipient = parent.Email
+	}
 
-		return nil, domain.ErrUnauthorized
+	token, err := generateToken(domain.UnlockTokenPrefix)
+	if err != nil {
+		return err
+	}
+	if err = u.lockoutRepo.CreateUnlock(ctx, user.ID, hashToken(token), time.Now().Add(domain.UnlockTokenExpiration)); err != nil {
+		return fmt.Errorf("error creating unlock token: %w", err)
 	}
 
-	// refresh the token and generate a JWT token
-	newJwtToken, err := u.authService.GenerateToken(ctx, user)
+	query := url.Values{"token": {token}}
+	if userRegion := region.FromContext(ctx); userRegion != region.Home {
+		query.Set("region", userRegion)
+	}
+	unlockURL := fmt.Sprintf("%s/unlock?%s", strings.TrimRight(u.Config.GetAppURL(), "/"), query.Encode())
+
+	account := user.Email
+	if user.IsChild() {
+		account = user.Username
+	}
+
+	return u.sender.Send(ctx, &mail.Message{
+		To:      []string{recipient},
+		Subject: "Your account was locked",
+		Text: fmt.Sprintf("The account %s was locked after %d failed logins, it unlocks on its own at %s.\n\n"+
+			"If it was you, unlock it now: %s\n\nIf it wasn't you, someone is guessing the password, consider changing it.\n",
+			account, domain.LoginMaxFailures, lockedUntil.UTC().Format(time.RFC1123), unlockURL),
+	})
+}
+
+func (u *userService) Unlock(ctx context.Context, token string, userRegion string) error {
+	ctx, span := tracing.Start(ctx, "userService.Unlock")
+	defer span.End()
+
+	if !u.servesRegion(userRegion) {
+		return fmt.Errorf("region %q: %w", userRegion, domain.ErrUnknownRegion)
+	}
+	ctx = region.WithRegion(ctx, userRegion)
+	audit.SetRegion(ctx, userRegion)
+
+	userID, err := u.lockoutRepo.UseUnlock(ctx, hashToken(token))
 	if err != nil {
-		return nil, err
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrInvalidUnlockToken
+		}
+		log.Err(err).Msg("error using unlock token")
+		return err
+	}
+	audit.SetUser(ctx, userID)
+
+	if err = u.lockoutRepo.Reset(ctx, userID); err != nil {
+		log.Err(err).Msg("error lifting login lockout")
+		return err
 	}
 
-	newRefreshToken, err := u.authService.GenerateRefreshToken(ctx, user.ID)
+	return nil
+}
+
+func (u *userService) RefreshToken(ctx context.Context, jwtToken string, user *domain.User, refreshToken string, expiresAt time.Time) (*endpoint.JWTResponse, error) {
+	ctx, span := tracing.Start(ctx, "userService.RefreshToken")
+	defer span.End()
+
+	// the refresh token is locked until the new one replaced it, so it can only be used once
+	var newJwtToken, newRefreshToken string
+	var expired bool
+	err := u.txManager.WithTx(ctx, func(ctx context.Context) error {
+		// make sure refresh token exists
+		rt, err := u.authService.ByRefreshToken(ctx, user.ID, refreshToken)
+		if err != nil {
+			log.Err(err).Msg("could not find refresh token")
+			return err
+		}
+
+		// if the token has expired, delete it and return unauthorized once that is committed
+		if rt.ExpiresAt.Before(time.Now()) {
+			expired = true
+			return u.authService.DeleteRefreshToken(ctx, user.ID)
+		}
+
+		// refresh the token and generate a JWT token
+		if newJwtToken, err = u.authService.GenerateToken(ctx, user); err != nil {
+			return err
+		}
+
+		newRefreshToken, err = u.authService.GenerateRefreshToken(ctx, user.ID)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
+	if expired {
+		return nil, domain.ErrUnauthorized
+	}
 
 	// blacklist jwtToken
 	err = u.authService.BlacklistToken(ctx, jwtToken, user.ID, expiresAt)
@@ -164,6 +470,126 @@ func (u *userService) RefreshToken(ctx context.Context, jwtToken string, user *d
 	}, nil
 }
 
+func (u *userService) ByID(ctx context.Context, userID uint) (*domain.User, error) {
+	user, err := u.userRepo.ByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("user not found: %w", domain.ErrUserNotFound)
+		}
+		log.Err(err).Msg("error retrieving user by id")
+		return nil, err
+	}
+	return user, nil
+}
+
+func (u *userService) ChangePassword(ctx context.Context, userID uint, change *domain.PasswordChange) (*endpoint.JWTResponse, error) {
+	ctx, span := tracing.Start(ctx, "userService.ChangePassword")
+	defer span.End()
+
+	user, err := u.ByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// child accounts log in with their username and have no email
+	var withPassword *domain.User
+	if user.IsChild() {
+		withPassword, err = u.userRepo.ByUsernameWithPassword(ctx, user.Username)
+	} else {
+		withPassword, err = u.userRepo.ByEmailWithPassword(ctx, user.Email)
+	}
+	if err != nil {
+		log.Err(err).Msg("error retrieving user password")
+		return nil, err
+	}
+	if _, err = u.comparePassword(ctx, withPassword.Password, change.CurrentPassword); err != nil {
+		if errors.Is(err, password.ErrMismatch) {
+			return nil, domain.ErrIncorrectPassword
+		}
+		log.Err(err).Msg("error comparing password")
+		return nil, err
+	}
+	if change.NewPassword == change.CurrentPassword {
+		return nil, domain.ErrPasswordUnchanged
+	}
+
+	hashedPassword, err := u.hashPassword(ctx, change.NewPassword)
+	if err != nil {
+		log.Err(err).Msg("error generating hash password")
+		return nil, err
+	}
+
+	// the sessions are revoked first, a stolen session must not outlive a failed change. Tokens
+	// carry their issue time in microseconds like the database, the tokens issued below are not
+	// revoked.
+	now := time.Now().Truncate(time.Microsecond)
+	if err = u.authService.RevokeTokens(ctx, user, now); err != nil {
+		return nil, err
+	}
+	// linked clients lose their grants with the password, they are linked again with the new one
+	err = u.txManager.WithTx(ctx, func(ctx context.Context) error {
+		if err := u.userRepo.UpdatePassword(ctx, user.ID, hashedPassword); err != nil {
+			return err
+		}
+
+		return u.oauthRepo.DeleteGrants(ctx, user.ID)
+	})
+	if err != nil {
+		log.Err(err).Msg("error updating password")
+		return nil, fmt.Errorf("error updating password: %w", err)
+	}
+	u.securityEvents.Publish(ctx, domain.EventSecurityTokenRevoked, user, map[string]string{"reason": "password_changed"})
+
+	token, err := u.authService.GenerateToken(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := u.authService.GenerateRefreshToken(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &endpoint.JWTResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+func (u *userService) UpdateProfile(ctx context.Context, userID uint, update *domain.UserProfileUpdate) (*domain.User, error) {
+	if err := update.Validate(); err != nil {
+		return nil, err
+	}
+
+	user, err := u.ByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	update.Apply(user)
+
+	updated, err := u.userRepo.UpdateProfile(ctx, user)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("user not found: %w", domain.ErrUserNotFound)
+		}
+		log.Err(err).Msg("error updating user profile")
+		return nil, fmt.Errorf("error updating user profile: %w", err)
+	}
+
+	return updated, nil
+}
+
+// userLocation returns the timezone of the user, UTC when the user can't be read. Dates are
+// only off by the offset then, so it doesn't fail the caller.
+func userLocation(ctx context.Context, userRepo repo.UserRepo, userID uint) *time.Location {
+	user, err := userRepo.ByID(ctx, userID)
+	if err != nil {
+		log.Warn().Err(err).Uint("user_id", userID).Msg("error retrieving timezone of user")
+		return time.UTC
+	}
+
+	return user.Location()
+}
+
 func (u *userService) ByEmail(ctx context.Context, email string) (*domain.User, error) {
 	user, err := u.userRepo.ByEmail(ctx, email)
 	if err != nil {
@@ -189,3 +615,33 @@ func (u *userService) ByEmailWithPassword(ctx context.Context, email string) (*d
 	}
 	return user, nil
 }
+
+func (u *userService) byUsernameWithPassword(ctx context.Context, username string) (*domain.User, error) {
+	user, err := u.userRepo.ByUsernameWithPassword(ctx, username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Err(domain.ErrUserNotFound).Msg("user not found")
+			return nil, fmt.Errorf("user not found: %w", domain.ErrUserNotFound)
+		}
+		log.Err(err).Msg("error retreiving user by username")
+		return nil, err
+	}
+	return user, nil
+}
+
+func (u *userService) All(ctx context.Context, page *pagination.Page) ([]*domain.User, *pagination.Cursor, error) {
+	users, next, err := u.userRepo.All(ctx, page)
+	if err != nil {
+		log.Err(err).Msg("error retrieving users")
+		return nil, nil, err
+	}
+
+	return users, next, nil
+}
+
+// loginKey identifies an email or username in the region directory, it is hashed so logins are
+// not stored outside of their region.
+func loginKey(kind string, login string) string {
+	sum := sha256.Sum256([]byte(kind + ":" + strings.ToLower(login)))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+)
+
+// VoiceService handles the intents of smart speaker skills, responses are short sentences
+// the speaker can read out.
+type VoiceService interface {
+	Handle(ctx context.Context, userID uint, voiceRequest *domain.VoiceRequest) (*domain.VoiceResponse, error)
+}
+
+type voiceService struct {
+	*BaseService
+
+	todoService      TodoService
+	workspaceService WorkspaceService
+
+	userRepo repo.UserRepo
+}
+
+func NewVoiceService(
+	base *BaseService,
+	todoService TodoService,
+	workspaceService WorkspaceService,
+	userRepo repo.UserRepo,
+) *voiceService {
+	return &voiceService{
+		BaseService:      base,
+		todoService:      todoService,
+		workspaceService: workspaceService,
+		userRepo:         userRepo,
+	}
+}
+
+// check VoiceService interface implementation on compile time.
+var _ VoiceService = (*voiceService)(nil)
+
+func (s *voiceService) Handle(ctx context.Context, userID uint, voiceRequest *domain.VoiceRequest) (*domain.VoiceResponse, error) {
+	// every intent of a linked speaker is an integration sync of the workspace
+	if err := s.workspaceService.Consume(ctx, userID, domain.QuotaIntegrationSyncs); err != nil {
+		return nil, err
+	}
+
+	switch voiceRequest.Intent {
+	case domain.VoiceIntentAddItem:
+		return s.addItem(ctx, userID, voiceRequest.ClientID, voiceRequest.Item)
+	case domain.VoiceIntentListToday:
+		return s.listToday(ctx, userID)
+	case domain.VoiceIntentCompleteItem:
+		return s.completeItem(ctx, userID, voiceRequest.Item)
+	}
+
+	return nil, fmt.Errorf("%q: %w", voiceRequest.Intent, domain.ErrUnknownVoiceIntent)
+}
+
+func (s *voiceService) addItem(ctx context.Context, userID uint, clientID string, item string) (*domain.VoiceResponse, error) {
+	item = strings.TrimSpace(item)
+	if item == "" {
+		return &domain.VoiceResponse{Speech: "What would you like to add?"}, nil
+	}
+
+	todo, err := s.todoService.Create(ctx, userID, &domain.TodoCreate{
+		Title:   item,
+		Source:  domain.CaptureSourceVoice,
+		Channel: clientID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.VoiceResponse{
+		Speech: fmt.Sprintf("Added %s to your todos.", todo.Title),
+		Todos:  []*domain.Todo{todo},
+	}, nil
+}
+
+// listToday reads out the open todos due today, overdue todos are included so they are not forgotten.
+func (s *voiceService) listToday(ctx context.Context, userID uint) (*domain.VoiceResponse, error) {
+	todos, err := s.openTodos(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// today ends at midnight in the timezone of the user
+	now := time.Now().In(userLocation(ctx, s.userRepo, userID))
+	endOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1)
+
+	today := make([]*domain.Todo, 0, len(todos))
+	titles := make([]string, 0, len(todos))
+	for _, todo := range todos {
+		if todo.DueDate.IsZero() || !todo.DueDate.Before(endOfDay) {
+			continue
+		}
+		today = append(today, todo)
+		titles = append(titles, todo.Title)
+	}
+
+	switch len(today) {
+	case 0:
+		return &domain.VoiceResponse{Speech: "You have nothing due today.", Todos: today}, nil
+	case 1:
+		return &domain.VoiceResponse{Speech: fmt.Sprintf("You have one item today: %s.", titles[0]), Todos: today}, nil
+	}
+
+	return &domain.VoiceResponse{
+		Speech: fmt.Sprintf("You have %d items today: %s.", len(today), joinSpoken(titles, "and")),
+		Todos:  today,
+	}, nil
+}
+
+func (s *voiceService) completeItem(ctx context.Context, userID uint, item string) (*domain.VoiceResponse, error) {
+	if strings.TrimSpace(item) == "" {
+		return &domain.VoiceResponse{Speech: "Which item would you like to complete?"}, nil
+	}
+
+	// speech recognition rarely gets the title exactly right
+	match, err := s.todoService.Match(ctx, userID, "", item)
+	if err != nil {
+		var ambiguous *domain.AmbiguousTodoMatchError
+		switch {
+		case errors.As(err, &ambiguous):
+			return &domain.VoiceResponse{Speech: fmt.Sprintf("Did you mean %s?", joinSpoken(matchTitles(ambiguous.Candidates), "or"))}, nil
+		case errors.Is(err, domain.ErrNoTodoMatch):
+			return &domain.VoiceResponse{Speech: fmt.Sprintf("I couldn't find %s on your todos.", item)}, nil
+		}
+		return nil, err
+	}
+
+	completed := true
+	todo, err := s.todoService.Update(ctx, userID, match.Todo.UUID, &domain.TodoUpdate{Completed: &completed})
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.VoiceResponse{
+		Speech: fmt.Sprintf("Marked %s as done.", todo.Title),
+		Todos:  []*domain.Todo{todo},
+	}, nil
+}
+
+func (s *voiceService) openTodos(ctx context.Context, userID uint) ([]*domain.Todo, error) {
+	todos, _, err := s.todoService.All(ctx, userID, &domain.TodoFilter{
+		Sort: []domain.TodoSortField{domain.TodoSortDueDate, domain.TodoSortPriority},
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	open := make([]*domain.Todo, 0, len(todos))
+	for _, todo := range todos {
+		if !todo.Completed() {
+			open = append(open, todo)
+		}
+	}
+
+	return open, nil
+}
+
+func matchTitles(matches []*domain.TodoMatch) []string {
+	titles := make([]string, 0, len(matches))
+	for _, match := range matches {
+		titles = append(titles, match.Todo.Title)
+	}
+
+	return titles
+}
+
+// joinSpoken joins items the way they are read out, e.g. "a, b and c".
+func joinSpoken(items []string, conjunction string) string {
+	if len(items) < 2 {
+		return strings.Join(items, "")
+	}
+
+	return strings.Join(items[:len(items)-1], ", ") + " " + conjunction + " " + items[len(items)-1]
+}
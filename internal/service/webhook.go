@@ -0,0 +1,337 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/metrics"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/model/endpoint"
+	"github.com/meowmix1337/the_recipe_book/internal/pagination"
+	"github.com/meowmix1337/the_recipe_book/internal/region"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+	"github.com/meowmix1337/the_recipe_book/internal/webhook"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	dueWebhookBatchSize = 50
+	// webhookConcurrency is how many deliveries are sent at once, so one slow receiver
+	// doesn't hold up the others.
+	webhookConcurrency = 8
+	// webhookErrorLength caps the error stored with a failed attempt.
+	webhookErrorLength = 500
+)
+
+// WebhookService lets users subscribe URLs to todo events, household owners can also subscribe
+// to the security events of their household, e.g. to feed a SIEM. Events are queued as deliveries
+// when they happen and sent by a background worker, failed deliveries are retried with
+// exponential backoff.
+type WebhookService interface {
+	Create(ctx context.Context, userID uint, webhookCreate *domain.WebhookCreate) (*domain.Webhook, error)
+	Delete(ctx context.Context, userID uint, uuid string) error
+	ByUUID(ctx context.Context, userID uint, uuid string) (*domain.Webhook, error)
+	All(ctx context.Context, userID uint) ([]*domain.Webhook, error)
+	Deliveries(ctx context.Context, userID uint, uuid string, page *pagination.Page) ([]*domain.WebhookDelivery, *pagination.Cursor, error)
+
+	// HandleTodoEvent queues a delivery for every webhook of the todo owner subscribed to the event,
+	// moved todos also notify the webhooks of their previous owner.
+	HandleTodoEvent(ctx context.Context, event *domain.TodoEvent)
+	// HandleSecurityEvent queues a delivery for every webhook of the household owner subscribed
+	// to the event.
+	HandleSecurityEvent(ctx context.Context, event *domain.SecurityEvent)
+	// DeliverDue sends the due deliveries, it is run by a background worker.
+	DeliverDue(ctx context.Context) error
+}
+
+type webhookService struct {
+	*BaseService
+
+	householdService HouseholdService
+	workspaceService WorkspaceService
+
+	webhookRepo repo.WebhookRepo
+
+	sender webhook.Sender
+}
+
+func NewWebhookService(
+	base *BaseService,
+	householdService HouseholdService,
+	workspaceService WorkspaceService,
+	webhookRepo repo.WebhookRepo,
+	sender webhook.Sender,
+) *webhookService {
+	return &webhookService{
+		BaseService:      base,
+		householdService: householdService,
+		workspaceService: workspaceService,
+		webhookRepo:      webhookRepo,
+		sender:           sender,
+	}
+}
+
+// check WebhookService interface implementation on compile time.
+var _ WebhookService = (*webhookService)(nil)
+
+// check TodoEventHandler interface implementation on compile time.
+var _ TodoEventHandler = (*webhookService)(nil)
+
+// check SecurityEventHandler interface implementation on compile time.
+var _ SecurityEventHandler = (*webhookService)(nil)
+
+func (s *webhookService) Create(ctx context.Context, userID uint, webhookCreate *domain.WebhookCreate) (*domain.Webhook, error) {
+	if webhookCreate == nil {
+		return nil, fmt.Errorf("no webhook details provided")
+	}
+
+	// webhooks send todos to a third party
+	if err := s.householdService.Permit(ctx, userID, domain.ParentalActionShare); err != nil {
+		return nil, err
+	}
+
+	// security events of the household members are only reported to the owner
+	if slices.ContainsFunc(webhookCreate.Events, domain.EventType.Security) {
+		if err := s.householdService.RequireParent(ctx, userID); err != nil {
+			if errors.Is(err, domain.ErrChildAccount) {
+				return nil, domain.ErrSecurityEventsOwnerOnly
+			}
+			return nil, err
+		}
+	}
+
+	target, err := url.Parse(webhookCreate.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, domain.ErrInvalidWebhookURL
+	}
+
+	secret, err := generateToken(domain.WebhookSecretPrefix)
+	if err != nil {
+		log.Err(err).Msg("error generating webhook secret")
+		return nil, err
+	}
+
+	created, err := s.webhookRepo.Create(ctx, &domain.Webhook{
+		UUID:   s.GenerateUUIDHash("webhook"),
+		UserID: userID,
+		URL:    target.String(),
+		Secret: secret,
+		Events: webhookCreate.Events,
+	})
+	if err != nil {
+		log.Err(err).Msg("error creating webhook")
+		return nil, fmt.Errorf("error creating webhook: %w", err)
+	}
+
+	return created, nil
+}
+
+func (s *webhookService) Delete(ctx context.Context, userID uint, uuid string) error {
+	if _, err := s.ByUUID(ctx, userID, uuid); err != nil {
+		return err
+	}
+
+	if err := s.webhookRepo.Delete(ctx, userID, uuid); err != nil {
+		log.Err(err).Msg("error deleting webhook")
+		return fmt.Errorf("error deleting webhook: %w", err)
+	}
+
+	return nil
+}
+
+func (s *webhookService) ByUUID(ctx context.Context, userID uint, uuid string) (*domain.Webhook, error) {
+	found, err := s.webhookRepo.ByUUID(ctx, userID, uuid)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("webhook not found: %w", domain.ErrWebhookNotFound)
+		}
+		log.Err(err).Msg("error retrieving webhook")
+		return nil, err
+	}
+
+	return found, nil
+}
+
+func (s *webhookService) All(ctx context.Context, userID uint) ([]*domain.Webhook, error) {
+	webhooks, err := s.webhookRepo.All(ctx, userID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving webhooks")
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+func (s *webhookService) Deliveries(
+	ctx context.Context,
+	userID uint,
+	uuid string,
+	page *pagination.Page,
+) ([]*domain.WebhookDelivery, *pagination.Cursor, error) {
+	found, err := s.ByUUID(ctx, userID, uuid)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	deliveries, next, err := s.webhookRepo.Deliveries(ctx, found.ID, page)
+	if err != nil {
+		log.Err(err).Msg("error retrieving webhook deliveries")
+		return nil, nil, err
+	}
+
+	return deliveries, next, nil
+}
+
+func (s *webhookService) HandleTodoEvent(ctx context.Context, event *domain.TodoEvent) {
+	payload := func(deliveryID string) any {
+		return endpoint.NewTodoEventPayload(deliveryID, event)
+	}
+	s.queue(ctx, event.Todo.UserID, event.Type, event.OccurredAt, payload)
+
+	// the previous owner of a moved todo learns where it went
+	if event.Previous != nil && event.Previous.UserID != event.Todo.UserID {
+		s.queue(ctx, event.Previous.UserID, event.Type, event.OccurredAt, payload)
+	}
+}
+
+func (s *webhookService) HandleSecurityEvent(ctx context.Context, event *domain.SecurityEvent) {
+	s.queue(ctx, event.OwnerID(), event.Type, event.OccurredAt, func(deliveryID string) any {
+		return endpoint.NewSecurityEventPayload(deliveryID, event)
+	})
+}
+
+// queue queues a delivery of the event for every webhook of the user subscribed to it, payload
+// renders the body of a delivery.
+func (s *webhookService) queue(
+	ctx context.Context,
+	userID uint,
+	eventType domain.EventType,
+	occurredAt time.Time,
+	payload func(deliveryID string) any,
+) {
+	webhooks, err := s.webhookRepo.All(ctx, userID)
+	if err != nil {
+		log.Err(err).Str("event", string(eventType)).Msg("error retrieving webhooks for event")
+		return
+	}
+
+	deliveries := make([]*domain.WebhookDelivery, 0, len(webhooks))
+	for _, subscriber := range webhooks {
+		if !subscriber.Subscribed(eventType) {
+			continue
+		}
+
+		delivery := &domain.WebhookDelivery{
+			UUID:          s.GenerateUUIDHash("delivery"),
+			Webhook:       subscriber,
+			Event:         eventType,
+			NextAttemptAt: occurredAt,
+		}
+		delivery.Payload, err = json.Marshal(payload(delivery.UUID))
+		if err != nil {
+			log.Err(err).Str("event", string(eventType)).Msg("error rendering webhook payload")
+			return
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err = s.webhookRepo.CreateDeliveries(ctx, deliveries); err != nil {
+		log.Err(err).Str("event", string(eventType)).Msg("error queueing webhook deliveries")
+	}
+}
+
+func (s *webhookService) DeliverDue(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	deliveries, err := s.webhookRepo.Due(ctx, now, dueWebhookBatchSize)
+	if err != nil {
+		return fmt.Errorf("error retrieving due webhook deliveries: %w", err)
+	}
+	metrics.WorkerQueueDepth.WithLabelValues("webhook_deliveries", metrics.RegionLabel(region.FromContext(ctx))).Set(float64(len(deliveries)))
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, webhookConcurrency)
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			s.deliver(ctx, delivery, now)
+		}()
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (s *webhookService) deliver(ctx context.Context, delivery *domain.WebhookDelivery, now time.Time) {
+	// claim the attempt first so multiple instances never send the same delivery at once, the
+	// next attempt already points at the retry in case this instance dies while sending
+	retryAt := now.Add(domain.WebhookBackoff(delivery.Attempts + 1))
+	if err := s.webhookRepo.Claim(ctx, delivery, retryAt); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Err(err).Str("delivery", delivery.UUID).Msg("error claiming webhook delivery")
+		}
+		return
+	}
+	delivery.Attempts++
+	delivery.NextAttemptAt = retryAt
+
+	// deliveries over the monthly limit of the workspace wait for the next month rather than
+	// failing, an error counting the usage doesn't hold the delivery up
+	if err := s.workspaceService.Consume(ctx, delivery.Webhook.UserID, domain.QuotaWebhookDeliveries); err != nil {
+		if errors.Is(err, domain.ErrUsageLimitExceeded) {
+			_, until := domain.UsagePeriod(now)
+			if err = s.webhookRepo.Hold(ctx, delivery, until, domain.ErrUsageLimitExceeded.Error()); err != nil {
+				log.Err(err).Str("delivery", delivery.UUID).Msg("error holding webhook delivery")
+			}
+			return
+		}
+		log.Err(err).Str("delivery", delivery.UUID).Msg("error counting webhook delivery")
+	}
+
+	statusCode, err := s.sender.Send(ctx, &webhook.Message{
+		URL:        delivery.Webhook.URL,
+		Secret:     delivery.Webhook.Secret,
+		Event:      string(delivery.Event),
+		DeliveryID: delivery.UUID,
+		Payload:    delivery.Payload,
+		Timestamp:  time.Now().UTC(),
+	})
+	delivery.LastStatusCode = statusCode
+
+	switch {
+	case err == nil:
+		delivery.Status = domain.WebhookDeliveryDelivered
+		delivery.LastError = ""
+		delivery.DeliveredAt = time.Now().UTC()
+	case delivery.Attempts >= domain.WebhookMaxAttempts:
+		delivery.Status = domain.WebhookDeliveryFailed
+		delivery.LastError = truncate(err.Error(), webhookErrorLength)
+		log.Warn().Err(err).Str("delivery", delivery.UUID).Msg("webhook delivery failed, giving up")
+	default:
+		delivery.LastError = truncate(err.Error(), webhookErrorLength)
+		log.Debug().Err(err).Str("delivery", delivery.UUID).Time("retry_at", retryAt).Msg("webhook delivery failed")
+	}
+
+	if err = s.webhookRepo.UpdateDelivery(ctx, delivery); err != nil {
+		log.Err(err).Str("delivery", delivery.UUID).Msg("error updating webhook delivery")
+	}
+}
+
+func truncate(s string, length int) string {
+	if len(s) <= length {
+		return s
+	}
+
+	return s[:length]
+}
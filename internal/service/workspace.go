@@ -0,0 +1,380 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/mail"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+
+	"github.com/rs/zerolog/log"
+)
+
+// WorkspaceService applies the quotas of provisioned workspaces to their households, households
+// outside of a workspace are not limited.
+type WorkspaceService interface {
+	// Allow returns ErrQuotaExceeded when the household of the user used up its quota of resource.
+	Allow(ctx context.Context, userID uint, resource domain.QuotaResource) error
+	// Consume counts one use of the metered resource by the household of the user, it returns
+	// ErrUsageLimitExceeded once the cap of the month is used up. The owner is warned by email
+	// the first time the usage of a month reaches the warning share of the cap.
+	Consume(ctx context.Context, userID uint, resource domain.QuotaResource) error
+	// Limits returns the caps and the usage of the workspace of the household of the user, it
+	// returns ErrWorkspaceNotFound when the household isn't part of a workspace.
+	Limits(ctx context.Context, userID uint) (*domain.WorkspaceLimits, error)
+
+	// Calendar returns the working hours and holidays of the workspace of the household of the
+	// user, it returns ErrWorkspaceNotFound when the household isn't part of a workspace.
+	Calendar(ctx context.Context, userID uint) (*domain.WorkCalendar, error)
+	// SetWorkingHours and the holiday changes are only allowed for the owner of the workspace,
+	// child accounts get ErrWorkspaceOwnerOnly.
+	SetWorkingHours(ctx context.Context, userID uint, hours *domain.WorkingHours) (*domain.WorkingHours, error)
+	CreateHoliday(ctx context.Context, userID uint, holiday *domain.Holiday) (*domain.Holiday, error)
+	UpdateHoliday(ctx context.Context, userID uint, uuid string, update *domain.HolidayUpdate) (*domain.Holiday, error)
+	DeleteHoliday(ctx context.Context, userID uint, uuid string) error
+}
+
+type workspaceService struct {
+	*BaseService
+
+	userRepo      repo.UserRepo
+	listRepo      repo.ListRepo
+	workspaceRepo repo.WorkspaceRepo
+	sender        mail.Sender
+}
+
+func NewWorkspaceService(
+	base *BaseService,
+	userRepo repo.UserRepo,
+	listRepo repo.ListRepo,
+	workspaceRepo repo.WorkspaceRepo,
+	sender mail.Sender,
+) *workspaceService {
+	return &workspaceService{
+		BaseService:   base,
+		userRepo:      userRepo,
+		listRepo:      listRepo,
+		workspaceRepo: workspaceRepo,
+		sender:        sender,
+	}
+}
+
+// check WorkspaceService interface implementation on compile time.
+var _ WorkspaceService = (*workspaceService)(nil)
+
+func (s *workspaceService) Allow(ctx context.Context, userID uint, resource domain.QuotaResource) error {
+	owner, workspace, err := s.workspace(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrWorkspaceNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	limit := workspace.Limit(resource)
+	if limit == 0 {
+		return nil
+	}
+
+	used, err := s.used(ctx, owner.ID, resource)
+	if err != nil {
+		log.Err(err).Str("resource", string(resource)).Msg("error counting quota usage")
+		return err
+	}
+	if used >= limit {
+		return fmt.Errorf("%d %s: %w", limit, resource, domain.ErrQuotaExceeded)
+	}
+
+	return nil
+}
+
+func (s *workspaceService) Consume(ctx context.Context, userID uint, resource domain.QuotaResource) error {
+	owner, workspace, err := s.workspace(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrWorkspaceNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	limit := workspace.Limit(resource)
+	period, _ := domain.UsagePeriod(time.Now())
+	used, err := s.workspaceRepo.AddUsage(ctx, workspace.ID, resource, period, limit)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%d %s: %w", limit, resource, domain.ErrUsageLimitExceeded)
+		}
+		log.Err(err).Str("resource", string(resource)).Msg("error counting workspace usage")
+		return err
+	}
+
+	if domain.UsageWarning(used, limit) {
+		s.warn(ctx, owner, workspace, resource, period, used, limit)
+	}
+
+	return nil
+}
+
+// warn sends the soft warning of resource to the owner once per month, a failed warning
+// doesn't fail the use that triggered it.
+func (s *workspaceService) warn(
+	ctx context.Context,
+	owner *domain.User,
+	workspace *domain.Workspace,
+	resource domain.QuotaResource,
+	period time.Time,
+	used int,
+	limit int,
+) {
+	// only the use that marks the month as warned sends the email
+	if err := s.workspaceRepo.MarkWarned(ctx, workspace.ID, resource, period); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Err(err).Str("workspace", workspace.Slug).Msg("error marking usage warning")
+		}
+		return
+	}
+
+	_, end := domain.UsagePeriod(period)
+	err := s.sender.Send(ctx, &mail.Message{
+		To:      []string{owner.Email},
+		Subject: fmt.Sprintf("Your workspace %s used %d%% of its %s", workspace.Name, used*100/limit, resource),
+		Text: fmt.Sprintf("The workspace %s used %d of its %d %s this month.\n\n"+
+			"Once the limit is reached they are refused until the limit resets on %s. Upgrade the plan to raise it.\n",
+			workspace.Name, used, limit, resource, end.Format("Jan 2, 2006")),
+	})
+	if err != nil {
+		log.Err(err).Str("workspace", workspace.Slug).Msg("error sending usage warning")
+	}
+}
+
+func (s *workspaceService) Limits(ctx context.Context, userID uint) (*domain.WorkspaceLimits, error) {
+	owner, workspace, err := s.workspace(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	start, end := domain.UsagePeriod(time.Now())
+	metered, err := s.workspaceRepo.Usage(ctx, workspace.ID, start)
+	if err != nil {
+		log.Err(err).Msg("error retrieving workspace usage")
+		return nil, err
+	}
+
+	limits := &domain.WorkspaceLimits{
+		Slug:        workspace.Slug,
+		Plan:        workspace.Plan,
+		PeriodStart: start,
+		PeriodEnd:   end,
+	}
+	for _, resource := range domain.QuotaResources {
+		used := metered[resource]
+		if !resource.Metered() {
+			if used, err = s.used(ctx, owner.ID, resource); err != nil {
+				log.Err(err).Str("resource", string(resource)).Msg("error counting quota usage")
+				return nil, err
+			}
+		}
+
+		limit := workspace.Limit(resource)
+		limits.Resources = append(limits.Resources, &domain.ResourceUsage{
+			Resource: resource,
+			Limit:    limit,
+			Used:     used,
+			Warning:  resource.Metered() && domain.UsageWarning(used, limit),
+		})
+	}
+
+	return limits, nil
+}
+
+func (s *workspaceService) Calendar(ctx context.Context, userID uint) (*domain.WorkCalendar, error) {
+	_, workspace, err := s.workspace(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	holidays, err := s.workspaceRepo.Holidays(ctx, workspace.ID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving workspace holidays")
+		return nil, err
+	}
+
+	return &domain.WorkCalendar{
+		Hours:    workspace.Hours,
+		Holidays: holidays,
+	}, nil
+}
+
+func (s *workspaceService) SetWorkingHours(ctx context.Context, userID uint, hours *domain.WorkingHours) (*domain.WorkingHours, error) {
+	if err := hours.Validate(); err != nil {
+		return nil, err
+	}
+
+	workspace, err := s.ownedWorkspace(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.workspaceRepo.SetWorkingHours(ctx, workspace.ID, hours); err != nil {
+		log.Err(err).Str("workspace", workspace.Slug).Msg("error updating working hours")
+		return nil, err
+	}
+
+	return hours, nil
+}
+
+func (s *workspaceService) CreateHoliday(ctx context.Context, userID uint, holiday *domain.Holiday) (*domain.Holiday, error) {
+	workspace, err := s.ownedWorkspace(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	holiday.UUID = s.GenerateUUIDHash("holiday")
+	holiday.WorkspaceID = workspace.ID
+	holiday.Date = domain.Day(holiday.Date)
+
+	created, err := s.workspaceRepo.CreateHoliday(ctx, holiday)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", holiday.Date.Format(domain.DateLayout), domain.ErrHolidayExists)
+		}
+		log.Err(err).Str("workspace", workspace.Slug).Msg("error creating holiday")
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (s *workspaceService) UpdateHoliday(
+	ctx context.Context,
+	userID uint,
+	uuid string,
+	update *domain.HolidayUpdate,
+) (*domain.Holiday, error) {
+	workspace, err := s.ownedWorkspace(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	holidays, err := s.workspaceRepo.Holidays(ctx, workspace.ID)
+	if err != nil {
+		log.Err(err).Msg("error retrieving workspace holidays")
+		return nil, err
+	}
+
+	var holiday *domain.Holiday
+	for _, candidate := range holidays {
+		if candidate.UUID == uuid {
+			holiday = candidate
+		}
+	}
+	if holiday == nil {
+		return nil, domain.ErrHolidayNotFound
+	}
+
+	if update.Date != nil {
+		date := domain.Day(*update.Date)
+		for _, other := range holidays {
+			if other.UUID != uuid && other.Date.Equal(date) {
+				return nil, fmt.Errorf("%s: %w", date.Format(domain.DateLayout), domain.ErrHolidayExists)
+			}
+		}
+		holiday.Date = date
+	}
+	if update.Name != nil {
+		holiday.Name = *update.Name
+	}
+	if update.Recurring != nil {
+		holiday.Recurring = *update.Recurring
+	}
+
+	if err = s.workspaceRepo.UpdateHoliday(ctx, holiday); err != nil {
+		log.Err(err).Str("workspace", workspace.Slug).Msg("error updating holiday")
+		return nil, err
+	}
+
+	return holiday, nil
+}
+
+func (s *workspaceService) DeleteHoliday(ctx context.Context, userID uint, uuid string) error {
+	workspace, err := s.ownedWorkspace(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err = s.workspaceRepo.DeleteHoliday(ctx, workspace.ID, uuid); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrHolidayNotFound
+		}
+		log.Err(err).Str("workspace", workspace.Slug).Msg("error deleting holiday")
+		return err
+	}
+
+	return nil
+}
+
+func (s *workspaceService) used(ctx context.Context, ownerID uint, resource domain.QuotaResource) (int, error) {
+	switch resource {
+	case domain.QuotaMembers:
+		children, err := s.userRepo.Children(ctx, ownerID)
+		return len(children), err
+	case domain.QuotaLists:
+		return s.listRepo.CountHousehold(ctx, ownerID)
+	}
+
+	return 0, nil
+}
+
+// workspace returns the owner of the household of the user and its workspace, it returns
+// ErrWorkspaceNotFound when the household isn't part of a workspace.
+func (s *workspaceService) workspace(ctx context.Context, userID uint) (*domain.User, *domain.Workspace, error) {
+	owner, err := s.owner(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	workspace, err := s.workspaceRepo.ByOwner(ctx, owner.UUID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, fmt.Errorf("household is not part of a workspace: %w", domain.ErrWorkspaceNotFound)
+		}
+		log.Err(err).Msg("error retrieving workspace")
+		return nil, nil, err
+	}
+
+	return owner, workspace, nil
+}
+
+// ownedWorkspace returns the workspace of the household when the user is its owner.
+func (s *workspaceService) ownedWorkspace(ctx context.Context, userID uint) (*domain.Workspace, error) {
+	owner, workspace, err := s.workspace(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if owner.ID != userID {
+		return nil, domain.ErrWorkspaceOwnerOnly
+	}
+
+	return workspace, nil
+}
+
+// owner returns the parent account of the household of the user.
+func (s *workspaceService) owner(ctx context.Context, userID uint) (*domain.User, error) {
+	user, err := s.userRepo.ByID(ctx, userID)
+	if err == nil && user.IsChild() {
+		user, err = s.userRepo.ByID(ctx, user.ParentID)
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("user not found: %w", domain.ErrUserNotFound)
+		}
+		log.Err(err).Msg("error retrieving user")
+		return nil, err
+	}
+
+	return user, nil
+}
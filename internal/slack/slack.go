@@ -0,0 +1,216 @@
+// Package slack posts messages to Slack, through an incoming webhook or the chat.postMessage API
+// of a bot, and verifies the signed requests Slack sends for slash commands.
+package slack
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+const (
+	// SignatureHeader and TimestampHeader carry the signature of a slash command request, the
+	// HMAC-SHA256 of "v0:<timestamp>:<body>" keyed with the signing secret of the app.
+	SignatureHeader = "X-Slack-Signature"
+	TimestampHeader = "X-Slack-Request-Timestamp"
+
+	// maxClockSkew is how old a signed request may be, older ones are rejected as replays.
+	maxClockSkew = 5 * time.Minute
+	// webhookHost is the only host incoming webhooks are posted to.
+	webhookHost = "hooks.slack.com"
+	// postMessageURL is the API method bots post messages with.
+	postMessageURL = "https://slack.com/api/chat.postMessage"
+	// maxResponseBody is how much of a response is read, answers are small JSON objects or "ok".
+	maxResponseBody = 16 << 10
+)
+
+var (
+	ErrInvalidSignature  = domain.NewError(domain.KindUnauthorized, "invalid slack request signature")
+	ErrInvalidWebhookURL = domain.NewError(domain.KindValidation, "slack webhook url must start with https://hooks.slack.com/")
+)
+
+// Destination is where a message is posted, either an incoming webhook or a channel the bot of
+// BotToken is a member of.
+type Destination struct {
+	WebhookURL string
+	BotToken   string
+	Channel    string
+}
+
+// APIError is returned when Slack answers, but rejects the message.
+type APIError struct {
+	StatusCode int
+	// Code is the error code of the Web API, e.g. "channel_not_found", or the body of a webhook
+	// response.
+	Code string
+}
+
+func (e *APIError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("slack answered with status %d", e.StatusCode)
+	}
+
+	return fmt.Sprintf("slack answered with status %d: %s", e.StatusCode, e.Code)
+}
+
+// Permanent reports whether retrying can't help, e.g. because the webhook was removed or the
+// token revoked.
+func (e *APIError) Permanent() bool {
+	switch e.Code {
+	case "invalid_token", "account_inactive", "token_revoked", "channel_not_found", "not_in_channel",
+		"is_archived", "no_service", "no_team", "channel_is_archived", "invalid_auth":
+		return true
+	}
+
+	return e.StatusCode == http.StatusNotFound || e.StatusCode == http.StatusGone
+}
+
+// Sender posts messages, implementations must be safe for concurrent use.
+type Sender interface {
+	Send(ctx context.Context, destination *Destination, text string) error
+}
+
+type httpSender struct {
+	client *http.Client
+}
+
+// NewHTTPSender posts messages with the given timeout. Messages only go to Slack hosts, so
+// unlike webhooks no address checks are needed.
+func NewHTTPSender(timeout time.Duration) *httpSender {
+	return &httpSender{
+		client: &http.Client{
+			Timeout: timeout,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+var _ Sender = (*httpSender)(nil)
+
+func (s *httpSender) Send(ctx context.Context, destination *Destination, text string) error {
+	if destination.WebhookURL != "" {
+		return s.postWebhook(ctx, destination.WebhookURL, text)
+	}
+
+	return s.postMessage(ctx, destination.BotToken, destination.Channel, text)
+}
+
+func (s *httpSender) postWebhook(ctx context.Context, webhookURL string, text string) error {
+	if err := ValidateWebhookURL(webhookURL); err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.post(ctx, webhookURL, "", body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// webhooks answer "ok" or a plain text error code like "no_service"
+	answer, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &APIError{StatusCode: resp.StatusCode, Code: strings.TrimSpace(string(answer))}
+	}
+
+	return nil
+}
+
+func (s *httpSender) postMessage(ctx context.Context, token string, channel string, text string) error {
+	body, err := json.Marshal(map[string]string{"channel": channel, "text": text})
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.post(ctx, postMessageURL, token, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// the Web API answers 200 with "ok": false on errors
+	var answer struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	err = json.NewDecoder(io.LimitReader(resp.Body, maxResponseBody)).Decode(&answer)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 || err != nil || !answer.OK {
+		return &APIError{StatusCode: resp.StatusCode, Code: answer.Error}
+	}
+
+	return nil
+}
+
+func (s *httpSender) post(ctx context.Context, target string, token string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("error building slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error posting to slack: %w", err)
+	}
+
+	return resp, nil
+}
+
+// ValidateWebhookURL checks that the URL is an incoming webhook of Slack, so a connection can't
+// be used to make requests to other hosts.
+func ValidateWebhookURL(webhookURL string) error {
+	target, err := url.Parse(webhookURL)
+	if err != nil || target.Scheme != "https" || target.Host != webhookHost || !strings.HasPrefix(target.Path, "/services/") {
+		return ErrInvalidWebhookURL
+	}
+
+	return nil
+}
+
+// Verify checks the signature of a request Slack sent with the signing secret of the app, the
+// timestamp has to be within a few minutes of now.
+func Verify(secret string, timestamp string, signature string, body []byte, now time.Time) error {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if skew := now.Sub(time.Unix(seconds, 0)); skew > maxClockSkew || skew < -maxClockSkew {
+		return fmt.Errorf("%w: timestamp too old", ErrInvalidSignature)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// Escape escapes the characters Slack treats as markup in message text.
+func Escape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
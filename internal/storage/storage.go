@@ -0,0 +1,31 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var (
+	ErrNotFound         = errors.New("file not found")
+	ErrInvalidKey       = errors.New("invalid file key")
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// Storage stores uploaded files by key, implementations must be safe for concurrent use.
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a URL the file can be downloaded from without a session until it expires,
+	// the file is downloaded with the given filename.
+	SignedURL(ctx context.Context, key string, filename string, expiresAt time.Time) (string, error)
+}
+
+// FileServer is implemented by storages whose signed URLs point back at this API instead of
+// the storage itself, the API serves the files after verifying the signature.
+type FileServer interface {
+	Storage
+	Verify(key string, filename string, expires int64, signature string) error
+}
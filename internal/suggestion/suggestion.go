@@ -0,0 +1,196 @@
+package suggestion
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+)
+
+const day = 24 * time.Hour
+
+// Config holds the thresholds of the heuristics, zero values fall back to DefaultConfig.
+type Config struct {
+	// MinPostponed is how often a todo has to be postponed to count as stalled.
+	MinPostponed int
+	// MinOverdue is how long a todo has to be overdue to count as stalled.
+	MinOverdue time.Duration
+	// LargeEstimate is the estimate from which a stalled todo should be broken down.
+	LargeEstimate time.Duration
+	// DropAfter is the age from which a stalled todo of at most DropMaxPriority may be dropped.
+	DropAfter       time.Duration
+	DropMaxPriority domain.Priority
+}
+
+func DefaultConfig() Config {
+	return Config{
+		MinPostponed:    2,
+		MinOverdue:      3 * day,
+		LargeEstimate:   2 * time.Hour,
+		DropAfter:       30 * day,
+		DropMaxPriority: domain.PriorityLow,
+	}
+}
+
+// Candidate is an open todo together with what the heuristics need to know about it.
+type Candidate struct {
+	Todo *domain.Todo
+	// Collaborators are the emails of the other editors of the todo's list.
+	Collaborators []string
+}
+
+// Heuristic suggests an action for a stalled todo, ok is false when the action doesn't fit.
+type Heuristic func(cfg Config, candidate *Candidate, now time.Time) (action *domain.SuggestedAction, ok bool)
+
+// Analyzer finds stalled todos and runs the heuristics on them.
+type Analyzer struct {
+	cfg        Config
+	heuristics []Heuristic
+}
+
+// NewAnalyzer runs the given heuristics in order, without any it runs BreakDown, Drop and Delegate.
+func NewAnalyzer(cfg Config, heuristics ...Heuristic) *Analyzer {
+	defaults := DefaultConfig()
+	if cfg.MinPostponed <= 0 {
+		cfg.MinPostponed = defaults.MinPostponed
+	}
+	if cfg.MinOverdue <= 0 {
+		cfg.MinOverdue = defaults.MinOverdue
+	}
+	if cfg.LargeEstimate <= 0 {
+		cfg.LargeEstimate = defaults.LargeEstimate
+	}
+	if cfg.DropAfter <= 0 {
+		cfg.DropAfter = defaults.DropAfter
+	}
+	if len(heuristics) == 0 {
+		heuristics = []Heuristic{BreakDown, Drop, Delegate}
+	}
+
+	return &Analyzer{
+		cfg:        cfg,
+		heuristics: heuristics,
+	}
+}
+
+// Analyze returns a suggestion for every stalled candidate at least one heuristic has an
+// action for, the most postponed and most overdue todos come first.
+func (a *Analyzer) Analyze(candidates []*Candidate, now time.Time) []*domain.TodoSuggestion {
+	suggestions := make([]*domain.TodoSuggestion, 0)
+	for _, candidate := range candidates {
+		stalled := a.Stalled(candidate.Todo, now)
+		if len(stalled) == 0 {
+			continue
+		}
+
+		suggestion := &domain.TodoSuggestion{
+			Todo:    candidate.Todo,
+			Stalled: stalled,
+		}
+		for _, heuristic := range a.heuristics {
+			if action, ok := heuristic(a.cfg, candidate, now); ok {
+				suggestion.Actions = append(suggestion.Actions, action)
+			}
+		}
+		if len(suggestion.Actions) > 0 {
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		a, b := suggestions[i].Todo, suggestions[j].Todo
+		if a.Postponed != b.Postponed {
+			return a.Postponed > b.Postponed
+		}
+
+		return a.Overdue(now) > b.Overdue(now)
+	})
+
+	return suggestions
+}
+
+// Stalled explains why a todo counts as stalled, it is empty for todos that are on track.
+func (a *Analyzer) Stalled(todo *domain.Todo, now time.Time) []string {
+	if todo.Completed() {
+		return nil
+	}
+
+	var reasons []string
+	if todo.Postponed >= a.cfg.MinPostponed {
+		reasons = append(reasons, fmt.Sprintf("postponed %s", times(todo.Postponed)))
+	}
+	if overdue := todo.Overdue(now); overdue >= a.cfg.MinOverdue {
+		reasons = append(reasons, fmt.Sprintf("overdue by %s", days(overdue)))
+	}
+
+	return reasons
+}
+
+// BreakDown suggests splitting todos that are estimated to take long.
+func BreakDown(cfg Config, candidate *Candidate, _ time.Time) (*domain.SuggestedAction, bool) {
+	if candidate.Todo.Estimate < cfg.LargeEstimate {
+		return nil, false
+	}
+
+	return &domain.SuggestedAction{
+		Action: domain.SuggestionBreakDown,
+		Reason: fmt.Sprintf("estimated at %s, smaller steps are easier to start", estimate(candidate.Todo.Estimate)),
+	}, true
+}
+
+// Drop suggests deleting old todos of low priority.
+func Drop(cfg Config, candidate *Candidate, now time.Time) (*domain.SuggestedAction, bool) {
+	todo := candidate.Todo
+	age := now.Sub(todo.CreatedAt)
+	if todo.Priority > cfg.DropMaxPriority || age < cfg.DropAfter {
+		return nil, false
+	}
+
+	return &domain.SuggestedAction{
+		Action: domain.SuggestionDrop,
+		Reason: fmt.Sprintf("%s priority and open for %s, it might not be needed anymore", todo.Priority, days(age)),
+	}, true
+}
+
+// Delegate suggests handing todos of shared lists to a collaborator.
+func Delegate(_ Config, candidate *Candidate, _ time.Time) (*domain.SuggestedAction, bool) {
+	if len(candidate.Collaborators) == 0 {
+		return nil, false
+	}
+
+	return &domain.SuggestedAction{
+		Action:    domain.SuggestionDelegate,
+		Reason:    "the list is shared with " + strings.Join(candidate.Collaborators, ", "),
+		Delegates: candidate.Collaborators,
+	}, true
+}
+
+func times(n int) string {
+	if n == 1 {
+		return "once"
+	}
+
+	return fmt.Sprintf("%d times", n)
+}
+
+func days(d time.Duration) string {
+	n := int(d / day)
+	if n == 1 {
+		return "1 day"
+	}
+
+	return fmt.Sprintf("%d days", n)
+}
+
+func estimate(d time.Duration) string {
+	switch {
+	case d == time.Hour:
+		return "1 hour"
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%d hours", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%d minutes", int(d.Minutes()))
+	}
+}
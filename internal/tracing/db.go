@@ -0,0 +1,134 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+
+	"github.com/meowmix1337/go-core/db"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedDB starts a client span for every query of a database. The statement is recorded
+// without its arguments, so no user data ends up in the traces.
+type tracedDB struct {
+	db     db.DB
+	region string
+}
+
+// NewDB traces the queries of the database of region, region is the label of the spans.
+func NewDB(db db.DB, region string) *tracedDB {
+	return &tracedDB{
+		db:     db,
+		region: region,
+	}
+}
+
+var _ db.DB = (*tracedDB)(nil)
+
+func (d *tracedDB) Get(ctx context.Context, dest interface{}, query string, args ...interface{}) (err error) {
+	ctx, span := d.start(ctx, "db.get", query)
+	defer endQuery(span, &err)
+
+	return d.db.Get(ctx, dest, query, args...)
+}
+
+func (d *tracedDB) Get_RO(ctx context.Context, dest interface{}, query string, args ...interface{}) (err error) {
+	ctx, span := d.start(ctx, "db.get_ro", query)
+	defer endQuery(span, &err)
+
+	return d.db.Get_RO(ctx, dest, query, args...)
+}
+
+func (d *tracedDB) Select(ctx context.Context, dest interface{}, query string, args ...interface{}) (err error) {
+	ctx, span := d.start(ctx, "db.select", query)
+	defer endQuery(span, &err)
+
+	return d.db.Select(ctx, dest, query, args...)
+}
+
+func (d *tracedDB) Select_RO(ctx context.Context, dest interface{}, query string, args ...interface{}) (err error) {
+	ctx, span := d.start(ctx, "db.select_ro", query)
+	defer endQuery(span, &err)
+
+	return d.db.Select_RO(ctx, dest, query, args...)
+}
+
+func (d *tracedDB) Exec(ctx context.Context, query string, args ...interface{}) (result sql.Result, err error) {
+	ctx, span := d.start(ctx, "db.exec", query)
+	defer endQuery(span, &err)
+
+	return d.db.Exec(ctx, query, args...)
+}
+
+// Transaction spans the whole transaction, the queries of tx are its children.
+func (d *tracedDB) Transaction(ctx context.Context, fn func(ctx context.Context, tx db.Tx) error) (err error) {
+	ctx, span := d.start(ctx, "db.transaction", "")
+	defer endQuery(span, &err)
+
+	return d.db.Transaction(ctx, func(ctx context.Context, tx db.Tx) error {
+		return fn(ctx, &tracedTx{tx: tx, db: d})
+	})
+}
+
+// Close passes the close on to the wrapped database, so the pool can be closed through the
+// wrappers.
+func (d *tracedDB) Close() error {
+	if closer, ok := d.db.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+func (d *tracedDB) start(ctx context.Context, name string, query string) (context.Context, trace.Span) {
+	attributes := []attribute.KeyValue{
+		semconv.DBSystemPostgreSQL,
+		attribute.String("db.region", d.region),
+	}
+	if query != "" {
+		attributes = append(attributes, semconv.DBQueryText(query))
+	}
+
+	return Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attributes...))
+}
+
+// endQuery ends the span of a query, sql.ErrNoRows is a result and not recorded as an error.
+func endQuery(span trace.Span, err *error) {
+	if errors.Is(*err, sql.ErrNoRows) {
+		span.End()
+		return
+	}
+	End(span, err)
+}
+
+type tracedTx struct {
+	tx db.Tx
+	db *tracedDB
+}
+
+var _ db.Tx = (*tracedTx)(nil)
+
+func (t *tracedTx) Get(ctx context.Context, dest interface{}, query string, args ...interface{}) (err error) {
+	ctx, span := t.db.start(ctx, "db.get", query)
+	defer endQuery(span, &err)
+
+	return t.tx.Get(ctx, dest, query, args...)
+}
+
+func (t *tracedTx) Select(ctx context.Context, dest interface{}, query string, args ...interface{}) (err error) {
+	ctx, span := t.db.start(ctx, "db.select", query)
+	defer endQuery(span, &err)
+
+	return t.tx.Select(ctx, dest, query, args...)
+}
+
+func (t *tracedTx) Exec(ctx context.Context, query string, args ...interface{}) (result sql.Result, err error) {
+	ctx, span := t.db.start(ctx, "db.exec", query)
+	defer endQuery(span, &err)
+
+	return t.tx.Exec(ctx, query, args...)
+}
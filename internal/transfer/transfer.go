@@ -0,0 +1,149 @@
+// Package transfer downloads the export archive of an account from another todo instance, so
+// the account moves over without the user downloading and uploading the archive.
+package transfer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/webhook"
+)
+
+// DigestTrailer is the HTTP trailer ZIP exports end with, the hex encoded SHA-256 of the
+// archive. It is only known once the archive was streamed, so it can't be a header.
+const DigestTrailer = "X-Archive-Sha256"
+
+// ExportPath is the export of the source instance that is downloaded, the whole account with
+// its archived todos and attachments.
+const ExportPath = "/api/v1/todos/export?format=zip&attachments=true&archived=include"
+
+// progressInterval is how many bytes are received between progress reports.
+const progressInterval = 1 << 20
+
+var (
+	// ErrIntegrity is returned when the received archive doesn't match the digest of the source.
+	ErrIntegrity = errors.New("archive doesn't match the digest sent by the source, it was changed or cut off on the way")
+	// ErrNoDigest is returned when the source didn't send a digest, e.g. an older instance or a
+	// proxy dropping trailers.
+	ErrNoDigest = errors.New("source didn't send a digest of the archive")
+	// ErrTooLarge is returned when the archive is larger than the limit of the download.
+	ErrTooLarge = errors.New("archive is too large")
+)
+
+// Archive is a downloaded and verified export archive.
+type Archive struct {
+	Payload []byte
+	// SHA256 is the hex encoded digest the archive was verified with.
+	SHA256 string
+}
+
+// Client downloads export archives, implementations must be safe for concurrent use.
+type Client interface {
+	// Download downloads the export archive of the account token belongs to from the instance
+	// at sourceURL and verifies it. progress is called with the bytes received so far.
+	Download(ctx context.Context, sourceURL string, token string, limit int64, progress func(received int64)) (*Archive, error)
+}
+
+type httpClient struct {
+	client *http.Client
+}
+
+// NewHTTPClient downloads archives, timeout bounds connecting and the response headers, the
+// download itself is bounded by the context. Like webhooks, connections to loopback, private
+// and link-local addresses are refused.
+func NewHTTPClient(timeout time.Duration) *httpClient {
+	dialer := &net.Dialer{Timeout: timeout}
+	webhook.RefusePrivate(dialer)
+
+	return &httpClient{
+		client: &http.Client{
+			Transport: &http.Transport{
+				DialContext:           dialer.DialContext,
+				TLSHandshakeTimeout:   timeout,
+				ResponseHeaderTimeout: timeout,
+			},
+			// the token must only ever be sent to the instance the user named
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+var _ Client = (*httpClient)(nil)
+
+func (c *httpClient) Download(
+	ctx context.Context, sourceURL string, token string, limit int64, progress func(received int64),
+) (*Archive, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL+ExportPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error building transfer request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("User-Agent", "todo-transfer/1")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return nil, errors.New("source rejected the token, it may have expired")
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("source answered with status %d", resp.StatusCode)
+	}
+
+	digest := sha256.New()
+	body := &progressReader{
+		r:        io.TeeReader(io.LimitReader(resp.Body, limit+1), digest),
+		progress: progress,
+	}
+	payload, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(payload)) > limit {
+		return nil, ErrTooLarge
+	}
+	progress(int64(len(payload)))
+
+	// the trailer is only filled in once the body was read to the end
+	expected := resp.Trailer.Get(DigestTrailer)
+	if expected == "" {
+		return nil, ErrNoDigest
+	}
+	sum := hex.EncodeToString(digest.Sum(nil))
+	if sum != expected {
+		return nil, ErrIntegrity
+	}
+
+	return &Archive{Payload: payload, SHA256: sum}, nil
+}
+
+// progressReader reports the bytes read every progressInterval.
+type progressReader struct {
+	r        io.Reader
+	progress func(received int64)
+	read     int64
+	reported int64
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.read += int64(n)
+	if r.read-r.reported >= progressInterval {
+		r.reported = r.read
+		r.progress(r.read)
+	}
+
+	return n, err
+}
@@ -0,0 +1,769 @@
+package wasm
+
+import "encoding/binary"
+
+const (
+	opUnreachable  = 0x00
+	opNop          = 0x01
+	opBlock        = 0x02
+	opLoop         = 0x03
+	opIf           = 0x04
+	opElse         = 0x05
+	opEnd          = 0x0b
+	opBr           = 0x0c
+	opBrIf         = 0x0d
+	opBrTable      = 0x0e
+	opReturn       = 0x0f
+	opCall         = 0x10
+	opCallIndirect = 0x11
+	opDrop         = 0x1a
+	opSelect       = 0x1b
+	opSelectTyped  = 0x1c
+	opLocalGet     = 0x20
+	opLocalSet     = 0x21
+	opLocalTee     = 0x22
+	opGlobalGet    = 0x23
+	opGlobalSet    = 0x24
+
+	opI32Load    = 0x28
+	opI64Load    = 0x29
+	opF32Load    = 0x2a
+	opF64Load    = 0x2b
+	opI32Load8S  = 0x2c
+	opI32Load8U  = 0x2d
+	opI32Load16S = 0x2e
+	opI32Load16U = 0x2f
+	opI64Load8S  = 0x30
+	opI64Load8U  = 0x31
+	opI64Load16S = 0x32
+	opI64Load16U = 0x33
+	opI64Load32S = 0x34
+	opI64Load32U = 0x35
+	opI32Store   = 0x36
+	opI64Store   = 0x37
+	opF32Store   = 0x38
+	opF64Store   = 0x39
+	opI32Store8  = 0x3a
+	opI32Store16 = 0x3b
+	opI64Store8  = 0x3c
+	opI64Store16 = 0x3d
+	opI64Store32 = 0x3e
+	opMemorySize = 0x3f
+	opMemoryGrow = 0x40
+
+	opI32Const = 0x41
+	opI64Const = 0x42
+	opF32Const = 0x43
+	opF64Const = 0x44
+
+	opI32Eqz  = 0x45
+	opI32Eq   = 0x46
+	opI32Ne   = 0x47
+	opI32LtS  = 0x48
+	opI32LtU  = 0x49
+	opI32GtS  = 0x4a
+	opI32GtU  = 0x4b
+	opI32LeS  = 0x4c
+	opI32LeU  = 0x4d
+	opI32GeS  = 0x4e
+	opI32GeU  = 0x4f
+	opI64Eqz  = 0x50
+	opI64Eq   = 0x51
+	opI64Ne   = 0x52
+	opI64LtS  = 0x53
+	opI64LtU  = 0x54
+	opI64GtS  = 0x55
+	opI64GtU  = 0x56
+	opI64LeS  = 0x57
+	opI64LeU  = 0x58
+	opI64GeS  = 0x59
+	opI64GeU  = 0x5a
+	opF32Eq   = 0x5b
+	opF32Ne   = 0x5c
+	opF32Lt   = 0x5d
+	opF32Gt   = 0x5e
+	opF32Le   = 0x5f
+	opF32Ge   = 0x60
+	opF64Eq   = 0x61
+	opF64Ne   = 0x62
+	opF64Lt   = 0x63
+	opF64Gt   = 0x64
+	opF64Le   = 0x65
+	opF64Ge   = 0x66
+	opI32Clz  = 0x67
+	opI32Ctz  = 0x68
+	opI32Pop  = 0x69
+	opI32Add  = 0x6a
+	opI32Sub  = 0x6b
+	opI32Mul  = 0x6c
+	opI32DivS = 0x6d
+	opI32DivU = 0x6e
+	opI32RemS = 0x6f
+	opI32RemU = 0x70
+	opI32And  = 0x71
+	opI32Or   = 0x72
+	opI32Xor  = 0x73
+	opI32Shl  = 0x74
+	opI32ShrS = 0x75
+	opI32ShrU = 0x76
+	opI32Rotl = 0x77
+	opI32Rotr = 0x78
+	opI64Clz  = 0x79
+	opI64Ctz  = 0x7a
+	opI64Pop  = 0x7b
+	opI64Add  = 0x7c
+	opI64Sub  = 0x7d
+	opI64Mul  = 0x7e
+	opI64DivS = 0x7f
+	opI64DivU = 0x80
+	opI64RemS = 0x81
+	opI64RemU = 0x82
+	opI64And  = 0x83
+	opI64Or   = 0x84
+	opI64Xor  = 0x85
+	opI64Shl  = 0x86
+	opI64ShrS = 0x87
+	opI64ShrU = 0x88
+	opI64Rotl = 0x89
+	opI64Rotr = 0x8a
+
+	opF32Abs      = 0x8b
+	opF32Neg      = 0x8c
+	opF32Ceil     = 0x8d
+	opF32Floor    = 0x8e
+	opF32Trunc    = 0x8f
+	opF32Nearest  = 0x90
+	opF32Sqrt     = 0x91
+	opF32Add      = 0x92
+	opF32Sub      = 0x93
+	opF32Mul      = 0x94
+	opF32Div      = 0x95
+	opF32Min      = 0x96
+	opF32Max      = 0x97
+	opF32Copysign = 0x98
+	opF64Abs      = 0x99
+	opF64Neg      = 0x9a
+	opF64Ceil     = 0x9b
+	opF64Floor    = 0x9c
+	opF64Trunc    = 0x9d
+	opF64Nearest  = 0x9e
+	opF64Sqrt     = 0x9f
+	opF64Add      = 0xa0
+	opF64Sub      = 0xa1
+	opF64Mul      = 0xa2
+	opF64Div      = 0xa3
+	opF64Min      = 0xa4
+	opF64Max      = 0xa5
+	opF64Copysign = 0xa6
+
+	opI32WrapI64        = 0xa7
+	opI32TruncF32S      = 0xa8
+	opI32TruncF32U      = 0xa9
+	opI32TruncF64S      = 0xaa
+	opI32TruncF64U      = 0xab
+	opI64ExtendI32S     = 0xac
+	opI64ExtendI32U     = 0xad
+	opI64TruncF32S      = 0xae
+	opI64TruncF32U      = 0xaf
+	opI64TruncF64S      = 0xb0
+	opI64TruncF64U      = 0xb1
+	opF32ConvertI32S    = 0xb2
+	opF32ConvertI32U    = 0xb3
+	opF32ConvertI64S    = 0xb4
+	opF32ConvertI64U    = 0xb5
+	opF32DemoteF64      = 0xb6
+	opF64ConvertI32S    = 0xb7
+	opF64ConvertI32U    = 0xb8
+	opF64ConvertI64S    = 0xb9
+	opF64ConvertI64U    = 0xba
+	opF64PromoteF32     = 0xbb
+	opI32ReinterpretF32 = 0xbc
+	opI64ReinterpretF64 = 0xbd
+	opF32ReinterpretI32 = 0xbe
+	opF64ReinterpretI64 = 0xbf
+	opI32Extend8S       = 0xc0
+	opI32Extend16S      = 0xc1
+	opI64Extend8S       = 0xc2
+	opI64Extend16S      = 0xc3
+	opI64Extend32S      = 0xc4
+
+	// opMisc prefixes the saturating conversions and the bulk memory instructions.
+	opMisc = 0xfc
+	// opJump is not an instruction of the binary format, the compiler replaces else with it to
+	// skip from the end of the then branch to the end of the if.
+	opJump = 0xff
+)
+
+const (
+	miscI32TruncSatF32S = 0
+	miscI32TruncSatF32U = 1
+	miscI32TruncSatF64S = 2
+	miscI32TruncSatF64U = 3
+	miscI64TruncSatF32S = 4
+	miscI64TruncSatF32U = 5
+	miscI64TruncSatF64S = 6
+	miscI64TruncSatF64U = 7
+	miscMemoryCopy      = 10
+	miscMemoryFill      = 11
+)
+
+// instr is a compiled instruction. Branches are resolved to the index of the instruction they
+// continue at in a, the operand stack height to keep in b and the number of values they carry
+// in arity. Other instructions keep their immediate in a: the index of a local, global, function
+// or type, the offset of a memory access or the bits of a constant.
+type instr struct {
+	op    byte
+	misc  byte
+	arity uint32
+	a, b  uint64
+}
+
+// branch is a target of br_table.
+type branch struct {
+	pc     uint64
+	height uint64
+	arity  uint32
+}
+
+// function is a function defined by a module.
+type function struct {
+	typ *FuncType
+	// locals are the parameters followed by the declared locals.
+	locals []ValueType
+	code   []instr
+	tables [][]branch
+	// maxHeight is the most operands the function holds at once.
+	maxHeight int
+}
+
+// signature is the operand and result types of a numeric or memory instruction, result is
+// unknown for stores.
+type signature struct {
+	params []ValueType
+	result ValueType
+	// memory is set for loads and stores, align is their natural alignment as a power of 2.
+	memory bool
+	align  uint32
+}
+
+// signatures are the signatures of the numeric and memory instructions by opcode.
+var signatures = func() (s [256]*signature) {
+	set := func(from, to byte, result ValueType, params ...ValueType) {
+		for op := int(from); op <= int(to); op++ {
+			s[op] = &signature{params: params, result: result}
+		}
+	}
+	load := func(op byte, typ ValueType, align uint32) {
+		s[op] = &signature{params: []ValueType{I32}, result: typ, memory: true, align: align}
+	}
+	store := func(op byte, typ ValueType, align uint32) {
+		s[op] = &signature{params: []ValueType{I32, typ}, memory: true, align: align}
+	}
+
+	load(opI32Load, I32, 2)
+	load(opI64Load, I64, 3)
+	load(opF32Load, F32, 2)
+	load(opF64Load, F64, 3)
+	load(opI32Load8S, I32, 0)
+	load(opI32Load8U, I32, 0)
+	load(opI32Load16S, I32, 1)
+	load(opI32Load16U, I32, 1)
+	load(opI64Load8S, I64, 0)
+	load(opI64Load8U, I64, 0)
+	load(opI64Load16S, I64, 1)
+	load(opI64Load16U, I64, 1)
+	load(opI64Load32S, I64, 2)
+	load(opI64Load32U, I64, 2)
+	store(opI32Store, I32, 2)
+	store(opI64Store, I64, 3)
+	store(opF32Store, F32, 2)
+	store(opF64Store, F64, 3)
+	store(opI32Store8, I32, 0)
+	store(opI32Store16, I32, 1)
+	store(opI64Store8, I64, 0)
+	store(opI64Store16, I64, 1)
+	store(opI64Store32, I64, 2)
+
+	set(opI32Eqz, opI32Eqz, I32, I32)
+	set(opI32Eq, opI32GeU, I32, I32, I32)
+	set(opI64Eqz, opI64Eqz, I32, I64)
+	set(opI64Eq, opI64GeU, I32, I64, I64)
+	set(opF32Eq, opF32Ge, I32, F32, F32)
+	set(opF64Eq, opF64Ge, I32, F64, F64)
+	set(opI32Clz, opI32Pop, I32, I32)
+	set(opI32Add, opI32Rotr, I32, I32, I32)
+	set(opI64Clz, opI64Pop, I64, I64)
+	set(opI64Add, opI64Rotr, I64, I64, I64)
+	set(opF32Abs, opF32Sqrt, F32, F32)
+	set(opF32Add, opF32Copysign, F32, F32, F32)
+	set(opF64Abs, opF64Sqrt, F64, F64)
+	set(opF64Add, opF64Copysign, F64, F64, F64)
+
+	set(opI32WrapI64, opI32WrapI64, I32, I64)
+	set(opI32TruncF32S, opI32TruncF32U, I32, F32)
+	set(opI32TruncF64S, opI32TruncF64U, I32, F64)
+	set(opI64ExtendI32S, opI64ExtendI32U, I64, I32)
+	set(opI64TruncF32S, opI64TruncF32U, I64, F32)
+	set(opI64TruncF64S, opI64TruncF64U, I64, F64)
+	set(opF32ConvertI32S, opF32ConvertI32U, F32, I32)
+	set(opF32ConvertI64S, opF32ConvertI64U, F32, I64)
+	set(opF32DemoteF64, opF32DemoteF64, F32, F64)
+	set(opF64ConvertI32S, opF64ConvertI32U, F64, I32)
+	set(opF64ConvertI64S, opF64ConvertI64U, F64, I64)
+	set(opF64PromoteF32, opF64PromoteF32, F64, F32)
+	set(opI32ReinterpretF32, opI32ReinterpretF32, I32, F32)
+	set(opI64ReinterpretF64, opI64ReinterpretF64, I64, F64)
+	set(opF32ReinterpretI32, opF32ReinterpretI32, F32, I32)
+	set(opF64ReinterpretI64, opF64ReinterpretI64, F64, I64)
+	set(opI32Extend8S, opI32Extend16S, I32, I32)
+	set(opI64Extend8S, opI64Extend32S, I64, I64)
+
+	return s
+}()
+
+// unknown is the type of an operand of the polymorphic stack of unreachable code, it matches
+// every type.
+const unknown ValueType = 0
+
+// block is a block, loop or if being compiled, the function body is the outermost block.
+type block struct {
+	loop bool
+	// height is the operand stack height below the parameters of the block.
+	height          int
+	params, results []ValueType
+	// label is the target of branches to the block, elseLabel the target of the if instruction.
+	label, elseLabel int
+	isIf, hasElse    bool
+	unreachable      bool
+}
+
+// compiler turns a function body into instructions, checking the types of the operands and the
+// operand stack heights so branches know how many values to keep.
+type compiler struct {
+	m  *Module
+	fn *function
+	r  *reader
+	// stack holds the types of the operands.
+	stack  []ValueType
+	blocks []*block
+	// labels are the instruction indexes branches resolve to, -1 until known.
+	labels []int
+}
+
+func (m *Module) compileBody(r *reader, fn *function) {
+	c := &compiler{m: m, fn: fn, r: r}
+	c.blocks = []*block{{results: fn.typ.Results, label: c.newLabel(), elseLabel: -1}}
+
+	for len(c.blocks) > 0 && r.err == nil {
+		c.instruction(r.byte())
+	}
+	if r.err != nil {
+		return
+	}
+	if r.pos != len(r.b) {
+		r.fail("function has instructions after its end")
+		return
+	}
+
+	// resolve the labels now that every block ended
+	for i := range fn.code {
+		switch in := &fn.code[i]; in.op {
+		case opBr, opBrIf, opIf, opJump:
+			in.a = uint64(c.labels[in.a])
+		}
+	}
+	for _, table := range fn.tables {
+		for i := range table {
+			table[i].pc = uint64(c.labels[table[i].pc])
+		}
+	}
+}
+
+func (c *compiler) newLabel() int {
+	c.labels = append(c.labels, -1)
+
+	return len(c.labels) - 1
+}
+
+func (c *compiler) emit(in instr) {
+	c.fn.code = append(c.fn.code, in)
+}
+
+func (c *compiler) top() *block {
+	return c.blocks[len(c.blocks)-1]
+}
+
+// pop pops an operand of type want, or of any type for unknown, and returns its type.
+func (c *compiler) pop(want ValueType) ValueType {
+	b := c.top()
+	if len(c.stack) == b.height {
+		if !b.unreachable {
+			c.r.fail("operand stack underflow")
+		}
+		return want
+	}
+
+	got := c.stack[len(c.stack)-1]
+	c.stack = c.stack[:len(c.stack)-1]
+	if got == unknown {
+		return want
+	}
+	if want != unknown && got != want {
+		c.r.fail("type mismatch: expected %s, got %s", want, got)
+	}
+
+	return got
+}
+
+// popTypes pops operands of the types, the last one first.
+func (c *compiler) popTypes(types []ValueType) {
+	for i := len(types) - 1; i >= 0; i-- {
+		c.pop(types[i])
+	}
+}
+
+func (c *compiler) push(types ...ValueType) {
+	c.stack = append(c.stack, types...)
+	if len(c.stack) > c.fn.maxHeight {
+		c.fn.maxHeight = len(c.stack)
+	}
+}
+
+// unreachable marks the rest of the block as dead code, after it the stack is polymorphic.
+func (c *compiler) unreachable() {
+	b := c.top()
+	b.unreachable = true
+	c.stack = c.stack[:b.height]
+}
+
+func (c *compiler) blockType() ([]ValueType, []ValueType) {
+	switch t := ValueType(c.r.peek()); t {
+	case 0x40:
+		c.r.byte()
+		return nil, nil
+	case I32, I64, F32, F64:
+		c.r.byte()
+		return nil, []ValueType{t}
+	}
+
+	index := c.r.sleb(33)
+	if c.r.err == nil && (index < 0 || index >= int64(len(c.m.types))) {
+		c.r.fail("unknown block type %d", index)
+		return nil, nil
+	}
+	if c.r.err != nil {
+		return nil, nil
+	}
+	t := c.m.types[index]
+
+	return t.Params, t.Results
+}
+
+func (c *compiler) openBlock(loop, isIf bool) {
+	params, results := c.blockType()
+	if isIf {
+		c.pop(I32)
+	}
+	c.popTypes(params)
+	b := &block{loop: loop, isIf: isIf, height: len(c.stack), params: params, results: results, elseLabel: -1}
+	c.push(params...)
+
+	b.label = c.newLabel()
+	if loop {
+		c.labels[b.label] = len(c.fn.code)
+	}
+	if isIf {
+		b.elseLabel = c.newLabel()
+		c.emit(instr{op: opIf, a: uint64(b.elseLabel)})
+	}
+	c.blocks = append(c.blocks, b)
+}
+
+// target returns the branch to the block depth levels out and the types of the values it
+// carries.
+func (c *compiler) target(depth uint32) (branch, []ValueType, bool) {
+	if uint64(depth) >= uint64(len(c.blocks)) {
+		c.r.fail("unknown label %d", depth)
+		return branch{}, nil, false
+	}
+	b := c.blocks[len(c.blocks)-1-int(depth)]
+	types := b.results
+	if b.loop {
+		types = b.params
+	}
+
+	return branch{pc: uint64(b.label), height: uint64(b.height), arity: uint32(len(types))}, types, true
+}
+
+func (c *compiler) memarg(align uint32) uint64 {
+	if a := c.r.u32(); c.r.err == nil && a > align {
+		c.r.fail("alignment 2^%d is larger than the natural alignment 2^%d", a, align)
+	}
+
+	return uint64(c.r.u32())
+}
+
+func (c *compiler) instruction(op byte) {
+	r := c.r
+	if s := signatures[op]; s != nil {
+		if s.memory {
+			c.needMemory()
+			c.emit(instr{op: op, a: c.memarg(s.align)})
+		} else {
+			c.emit(instr{op: op})
+		}
+		c.popTypes(s.params)
+		if s.result != unknown {
+			c.push(s.result)
+		}
+		return
+	}
+
+	switch op {
+	case opUnreachable:
+		c.emit(instr{op: op})
+		c.unreachable()
+	case opNop:
+	case opBlock:
+		c.openBlock(false, false)
+	case opLoop:
+		c.openBlock(true, false)
+	case opIf:
+		c.openBlock(false, true)
+	case opElse:
+		b := c.top()
+		if !b.isIf || b.hasElse {
+			r.fail("else outside of an if")
+			return
+		}
+		c.popTypes(b.results)
+		if !b.unreachable && len(c.stack) != b.height {
+			r.fail("if branch leaves %d extra values", len(c.stack)-b.height)
+			return
+		}
+		c.emit(instr{op: opJump, a: uint64(b.label)})
+		c.labels[b.elseLabel] = len(c.fn.code)
+		b.hasElse, b.unreachable = true, false
+		c.stack = c.stack[:b.height]
+		c.push(b.params...)
+	case opEnd:
+		c.endBlock()
+	case opBr:
+		t, types, ok := c.target(r.u32())
+		if !ok {
+			return
+		}
+		c.popTypes(types)
+		c.emit(instr{op: op, a: t.pc, b: t.height, arity: t.arity})
+		c.unreachable()
+	case opBrIf:
+		t, types, ok := c.target(r.u32())
+		if !ok {
+			return
+		}
+		c.pop(I32)
+		c.popTypes(types)
+		c.emit(instr{op: op, a: t.pc, b: t.height, arity: t.arity})
+		c.push(types...)
+	case opBrTable:
+		n := r.count()
+		table := make([]branch, 0, n+1)
+		var types []ValueType
+		for i := uint32(0); i <= n && r.err == nil; i++ {
+			t, labelTypes, ok := c.target(r.u32())
+			if !ok {
+				return
+			}
+			if len(table) > 0 && string(valueTypeBytes(labelTypes)) != string(valueTypeBytes(types)) {
+				r.fail("br_table targets have different types")
+				return
+			}
+			table, types = append(table, t), labelTypes
+		}
+		if r.err != nil {
+			return
+		}
+		c.pop(I32)
+		c.popTypes(types)
+		c.emit(instr{op: op, a: uint64(len(c.fn.tables))})
+		c.fn.tables = append(c.fn.tables, table)
+		c.unreachable()
+	case opReturn:
+		t, types, _ := c.target(uint32(len(c.blocks) - 1))
+		c.popTypes(types)
+		c.emit(instr{op: opBr, a: t.pc, b: t.height, arity: t.arity})
+		c.unreachable()
+	case opCall:
+		index := r.u32()
+		if r.err == nil && index >= uint32(len(c.m.funcTypes)) {
+			r.fail("call of unknown function %d", index)
+		}
+		if r.err != nil {
+			return
+		}
+		t := c.m.types[c.m.funcTypes[index]]
+		c.emit(instr{op: op, a: uint64(index)})
+		c.popTypes(t.Params)
+		c.push(t.Results...)
+	case opCallIndirect:
+		index := c.m.typeIndex(r)
+		if table := r.byte(); r.err == nil && (table != 0 || c.m.table == nil) {
+			r.fail("call_indirect without a table")
+		}
+		if r.err != nil {
+			return
+		}
+		t := c.m.types[index]
+		c.emit(instr{op: op, a: uint64(index)})
+		c.pop(I32)
+		c.popTypes(t.Params)
+		c.push(t.Results...)
+	case opDrop:
+		c.emit(instr{op: op})
+		c.pop(unknown)
+	case opSelect, opSelectTyped:
+		typ := unknown
+		if op == opSelectTyped {
+			if n := r.count(); n != 1 {
+				r.fail("select with %d types", n)
+				return
+			}
+			typ = r.valueType()
+		}
+		c.emit(instr{op: opSelect})
+		c.pop(I32)
+		typ = c.pop(typ)
+		c.push(c.pop(typ))
+	case opLocalGet, opLocalSet, opLocalTee:
+		index := r.u32()
+		if r.err == nil && index >= uint32(len(c.fn.locals)) {
+			r.fail("unknown local %d", index)
+		}
+		if r.err != nil {
+			return
+		}
+		c.emit(instr{op: op, a: uint64(index)})
+		typ := c.fn.locals[index]
+		switch op {
+		case opLocalGet:
+			c.push(typ)
+		case opLocalSet:
+			c.pop(typ)
+		case opLocalTee:
+			c.push(c.pop(typ))
+		}
+	case opGlobalGet, opGlobalSet:
+		index := r.u32()
+		if r.err == nil && index >= uint32(len(c.m.globals)) {
+			r.fail("unknown global %d", index)
+		}
+		if r.err != nil {
+			return
+		}
+		c.emit(instr{op: op, a: uint64(index)})
+		g := c.m.globals[index]
+		if op == opGlobalGet {
+			c.push(g.typ)
+			return
+		}
+		if !g.mutable {
+			r.fail("global %d is immutable", index)
+		}
+		c.pop(g.typ)
+	case opMemorySize, opMemoryGrow:
+		c.needMemory()
+		if memory := r.byte(); r.err == nil && memory != 0 {
+			r.fail("unknown memory %d", memory)
+		}
+		c.emit(instr{op: op})
+		if op == opMemoryGrow {
+			c.pop(I32)
+		}
+		c.push(I32)
+	case opI32Const:
+		c.emit(instr{op: op, a: uint64(uint32(int32(r.sleb(32))))})
+		c.push(I32)
+	case opI64Const:
+		c.emit(instr{op: op, a: uint64(r.sleb(64))})
+		c.push(I64)
+	case opF32Const:
+		if b := r.bytes(4); r.err == nil {
+			c.emit(instr{op: op, a: uint64(binary.LittleEndian.Uint32(b))})
+		}
+		c.push(F32)
+	case opF64Const:
+		if b := r.bytes(8); r.err == nil {
+			c.emit(instr{op: op, a: binary.LittleEndian.Uint64(b)})
+		}
+		c.push(F64)
+	case opMisc:
+		c.misc(r.u32())
+	default:
+		r.fail("unsupported instruction 0x%x", op)
+	}
+}
+
+func (c *compiler) misc(sub uint32) {
+	r := c.r
+	switch {
+	case sub <= miscI64TruncSatF64U:
+		// the conversions go from f32, f32, f64 and f64 to i32 and then to i64, signed first
+		from, to := F32, I32
+		if sub%4 >= 2 {
+			from = F64
+		}
+		if sub >= miscI64TruncSatF32S {
+			to = I64
+		}
+		c.emit(instr{op: opMisc, misc: byte(sub)})
+		c.pop(from)
+		c.push(to)
+	case sub == miscMemoryCopy || sub == miscMemoryFill:
+		c.needMemory()
+		memories := 2
+		if sub == miscMemoryFill {
+			memories = 1
+		}
+		for i := 0; i < memories; i++ {
+			if memory := r.byte(); r.err == nil && memory != 0 {
+				r.fail("unknown memory %d", memory)
+			}
+		}
+		c.emit(instr{op: opMisc, misc: byte(sub)})
+		c.popTypes([]ValueType{I32, I32, I32})
+	default:
+		r.fail("unsupported instruction 0xfc %d", sub)
+	}
+}
+
+func (c *compiler) needMemory() {
+	if c.m.memory == nil {
+		c.r.fail("memory instruction without a memory")
+	}
+}
+
+func (c *compiler) endBlock() {
+	b := c.top()
+	c.popTypes(b.results)
+	if !b.unreachable && len(c.stack) != b.height {
+		c.r.fail("block leaves %d extra values", len(c.stack)-b.height)
+		return
+	}
+	if b.isIf && !b.hasElse {
+		if string(valueTypeBytes(b.params)) != string(valueTypeBytes(b.results)) {
+			c.r.fail("if without else changes the stack")
+			return
+		}
+		c.labels[b.elseLabel] = len(c.fn.code)
+	}
+	if !b.loop {
+		c.labels[b.label] = len(c.fn.code)
+	}
+	c.blocks = c.blocks[:len(c.blocks)-1]
+	c.stack = c.stack[:b.height]
+	if len(c.blocks) > 0 {
+		c.push(b.results...)
+	} else {
+		c.stack = append(c.stack, b.results...)
+	}
+}
@@ -0,0 +1,598 @@
+package wasm
+
+import (
+	"encoding/binary"
+	"unicode/utf8"
+)
+
+const (
+	// maxLocals bounds the locals of a function, they are allocated on every call.
+	maxLocals = 50000
+	// maxFunctions bounds the functions, types, globals and other index spaces of a module.
+	maxFunctions = 100000
+	// maxTableSize bounds the table of a module.
+	maxTableSize = 100000
+)
+
+// reader reads the binary format. The first error sticks, reads after it return zero values, so
+// decoders check err once per item.
+type reader struct {
+	b   []byte
+	pos int
+	err error
+}
+
+func (r *reader) fail(format string, args ...interface{}) {
+	if r.err == nil {
+		r.err = invalid(format, args...)
+	}
+}
+
+func (r *reader) done() bool {
+	return r.err != nil || r.pos >= len(r.b)
+}
+
+func (r *reader) byte() byte {
+	if r.err != nil {
+		return 0
+	}
+	if r.pos >= len(r.b) {
+		r.fail("unexpected end at offset %d", r.pos)
+		return 0
+	}
+	b := r.b[r.pos]
+	r.pos++
+
+	return b
+}
+
+// peek returns the next byte without reading it.
+func (r *reader) peek() byte {
+	if r.err != nil || r.pos >= len(r.b) {
+		return 0
+	}
+
+	return r.b[r.pos]
+}
+
+func (r *reader) bytes(n uint32) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if uint64(n) > uint64(len(r.b)-r.pos) {
+		r.fail("unexpected end at offset %d", r.pos)
+		return nil
+	}
+	b := r.b[r.pos : r.pos+int(n)]
+	r.pos += int(n)
+
+	return b
+}
+
+// uleb reads an unsigned LEB128 number of at most bits bits.
+func (r *reader) uleb(bits uint) uint64 {
+	var result uint64
+	for shift := uint(0); ; shift += 7 {
+		b := r.byte()
+		if r.err != nil {
+			return 0
+		}
+		if shift >= bits {
+			r.fail("integer too long at offset %d", r.pos)
+			return 0
+		}
+		result |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			if bits < 64 && result>>bits != 0 {
+				r.fail("integer too large at offset %d", r.pos)
+				return 0
+			}
+			return result
+		}
+	}
+}
+
+// sleb reads a signed LEB128 number of at most bits bits.
+func (r *reader) sleb(bits uint) int64 {
+	var result int64
+	var shift uint
+	for {
+		b := r.byte()
+		if r.err != nil {
+			return 0
+		}
+		if shift >= bits {
+			r.fail("integer too long at offset %d", r.pos)
+			return 0
+		}
+		result |= int64(b&0x7f) << shift
+		shift += 7
+		if b&0x80 == 0 {
+			if shift < 64 && b&0x40 != 0 {
+				result |= -1 << shift
+			}
+			return result
+		}
+	}
+}
+
+func (r *reader) u32() uint32 {
+	return uint32(r.uleb(32))
+}
+
+// count reads the length of a vector, it can't be longer than the bytes left.
+func (r *reader) count() uint32 {
+	n := r.u32()
+	if r.err == nil && uint64(n) > uint64(len(r.b)-r.pos) {
+		r.fail("vector of %d items at offset %d is longer than the module", n, r.pos)
+		return 0
+	}
+
+	return n
+}
+
+func (r *reader) name() string {
+	b := r.bytes(r.count())
+	if r.err == nil && !utf8.Valid(b) {
+		r.fail("name is not utf-8")
+	}
+
+	return string(b)
+}
+
+func (r *reader) valueType() ValueType {
+	switch t := ValueType(r.byte()); t {
+	case I32, I64, F32, F64:
+		return t
+	default:
+		r.fail("unsupported value type 0x%x", byte(t))
+		return 0
+	}
+}
+
+func (r *reader) limits(max uint32) (uint32, uint32, bool) {
+	flags := r.byte()
+	min := r.u32()
+	upper, bounded := max, false
+	switch flags {
+	case 0:
+	case 1:
+		upper, bounded = r.u32(), true
+		if r.err == nil && upper < min {
+			r.fail("limits maximum %d below minimum %d", upper, min)
+		}
+	default:
+		r.fail("unsupported limits flags 0x%x", flags)
+	}
+	if r.err == nil && (min > max || upper > max) {
+		r.fail("limits exceed %d", max)
+	}
+
+	return min, upper, bounded
+}
+
+const (
+	kindFunc   = 0x00
+	kindTable  = 0x01
+	kindMemory = 0x02
+	kindGlobal = 0x03
+)
+
+type export struct {
+	kind  byte
+	index uint32
+}
+
+type global struct {
+	typ     ValueType
+	mutable bool
+	init    uint64
+}
+
+type element struct {
+	offset uint32
+	funcs  []uint32
+}
+
+type segment struct {
+	offset uint32
+	data   []byte
+}
+
+// Module is a decoded and validated module, it can be instantiated any number of times.
+type Module struct {
+	types   []*FuncType
+	imports []Import
+	// funcTypes are the type indexes of the imported and then the defined functions.
+	funcTypes []uint32
+	funcs     []*function
+	table     *struct{ min, max uint32 }
+	memory    *struct {
+		min, max uint32
+		bounded  bool
+	}
+	globals  []global
+	exports  map[string]export
+	start    int64
+	elements []element
+	data     []segment
+}
+
+// Compile decodes and validates a module in the binary format.
+func Compile(binaryModule []byte) (*Module, error) {
+	r := &reader{b: binaryModule}
+	if magic := r.bytes(4); r.err != nil || string(magic) != "\x00asm" {
+		return nil, invalid("not a wasm module")
+	}
+	if version := r.bytes(4); r.err != nil || binary.LittleEndian.Uint32(version) != 1 {
+		return nil, invalid("unsupported version")
+	}
+
+	m := &Module{exports: map[string]export{}, start: -1}
+	var funcTypeIndexes []uint32
+	lastID := byte(0)
+	for !r.done() {
+		id := r.byte()
+		size := r.u32()
+		content := r.bytes(size)
+		if r.err != nil {
+			break
+		}
+		// sections other than custom ones come in order, each at most once
+		if id != 0 {
+			if id <= lastID && id != 12 {
+				return nil, invalid("section %d out of order", id)
+			}
+			lastID = id
+		}
+
+		section := &reader{b: content}
+		switch id {
+		case 0:
+			// custom sections carry names and debug info, which don't matter here
+		case 1:
+			m.decodeTypes(section)
+		case 2:
+			m.decodeImports(section)
+		case 3:
+			n := section.count()
+			for i := uint32(0); i < n && section.err == nil; i++ {
+				funcTypeIndexes = append(funcTypeIndexes, m.typeIndex(section))
+			}
+		case 4:
+			m.decodeTable(section)
+		case 5:
+			m.decodeMemory(section)
+		case 6:
+			m.decodeGlobals(section)
+		case 7:
+			m.decodeExports(section)
+		case 8:
+			m.start = int64(section.u32())
+		case 9:
+			m.decodeElements(section)
+		case 10:
+			m.decodeCode(section, funcTypeIndexes)
+		case 11:
+			m.decodeData(section)
+		case 12:
+			// the data count only helps single pass validators
+			section.u32()
+		default:
+			return nil, invalid("unknown section %d", id)
+		}
+		if section.err == nil && id != 0 && section.pos != len(section.b) {
+			section.fail("section %d has %d trailing bytes", id, len(section.b)-section.pos)
+		}
+		if section.err != nil {
+			return nil, section.err
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	if len(funcTypeIndexes) != len(m.funcs) {
+		return nil, invalid("%d functions declared, %d defined", len(funcTypeIndexes), len(m.funcs))
+	}
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (m *Module) decodeTypes(r *reader) {
+	n := r.count()
+	if n > maxFunctions {
+		r.fail("too many types")
+	}
+	for i := uint32(0); i < n && r.err == nil; i++ {
+		if form := r.byte(); form != 0x60 {
+			r.fail("unsupported type form 0x%x", form)
+			return
+		}
+		t := &FuncType{}
+		params := r.count()
+		for j := uint32(0); j < params && r.err == nil; j++ {
+			t.Params = append(t.Params, r.valueType())
+		}
+		results := r.count()
+		for j := uint32(0); j < results && r.err == nil; j++ {
+			t.Results = append(t.Results, r.valueType())
+		}
+		m.types = append(m.types, t)
+	}
+}
+
+func (m *Module) typeIndex(r *reader) uint32 {
+	index := r.u32()
+	if r.err == nil && index >= uint32(len(m.types)) {
+		r.fail("unknown type %d", index)
+	}
+
+	return index
+}
+
+func (m *Module) decodeImports(r *reader) {
+	n := r.count()
+	for i := uint32(0); i < n && r.err == nil; i++ {
+		module, name := r.name(), r.name()
+		if kind := r.byte(); kind != kindFunc {
+			// the host only provides functions, memory and globals stay inside the module
+			r.fail("import %s.%s is not a function", module, name)
+			return
+		}
+		index := m.typeIndex(r)
+		if r.err != nil {
+			return
+		}
+		m.imports = append(m.imports, Import{Module: module, Name: name, Type: *m.types[index]})
+		m.funcTypes = append(m.funcTypes, index)
+	}
+}
+
+func (m *Module) decodeTable(r *reader) {
+	n := r.count()
+	if n > 1 {
+		r.fail("more than one table")
+		return
+	}
+	if n == 1 {
+		if elemType := r.byte(); elemType != 0x70 {
+			r.fail("unsupported table element type 0x%x", elemType)
+			return
+		}
+		min, max, _ := r.limits(maxTableSize)
+		m.table = &struct{ min, max uint32 }{min, max}
+	}
+}
+
+func (m *Module) decodeMemory(r *reader) {
+	n := r.count()
+	if n > 1 {
+		r.fail("more than one memory")
+		return
+	}
+	if n == 1 {
+		min, max, bounded := r.limits(65536)
+		m.memory = &struct {
+			min, max uint32
+			bounded  bool
+		}{min, max, bounded}
+	}
+}
+
+func (m *Module) decodeGlobals(r *reader) {
+	n := r.count()
+	if n > maxFunctions {
+		r.fail("too many globals")
+	}
+	for i := uint32(0); i < n && r.err == nil; i++ {
+		g := global{typ: r.valueType()}
+		switch mutable := r.byte(); mutable {
+		case 0:
+		case 1:
+			g.mutable = true
+		default:
+			r.fail("invalid global mutability 0x%x", mutable)
+		}
+		g.init = m.constExpr(r, g.typ)
+		m.globals = append(m.globals, g)
+	}
+}
+
+// constExpr reads the initializer of a global or the offset of a segment.
+func (m *Module) constExpr(r *reader, typ ValueType) uint64 {
+	var value uint64
+	var got ValueType
+	switch op := r.byte(); op {
+	case opI32Const:
+		value, got = uint64(uint32(int32(r.sleb(32)))), I32
+	case opI64Const:
+		value, got = uint64(r.sleb(64)), I64
+	case opF32Const:
+		if b := r.bytes(4); r.err == nil {
+			value, got = uint64(binary.LittleEndian.Uint32(b)), F32
+		}
+	case opF64Const:
+		if b := r.bytes(8); r.err == nil {
+			value, got = binary.LittleEndian.Uint64(b), F64
+		}
+	case opGlobalGet:
+		index := r.u32()
+		if r.err == nil && index >= uint32(len(m.globals)) {
+			r.fail("constant expression reads unknown global %d", index)
+			return 0
+		}
+		if r.err == nil {
+			value, got = m.globals[index].init, m.globals[index].typ
+		}
+	default:
+		r.fail("unsupported constant expression 0x%x", op)
+		return 0
+	}
+	if end := r.byte(); r.err == nil && end != opEnd {
+		r.fail("constant expression is not terminated")
+	}
+	if r.err == nil && got != typ {
+		r.fail("constant expression is %s, not %s", got, typ)
+	}
+
+	return value
+}
+
+func (m *Module) decodeExports(r *reader) {
+	n := r.count()
+	for i := uint32(0); i < n && r.err == nil; i++ {
+		name := r.name()
+		e := export{kind: r.byte(), index: r.u32()}
+		if _, ok := m.exports[name]; ok && r.err == nil {
+			r.fail("export %q is duplicated", name)
+		}
+		m.exports[name] = e
+	}
+}
+
+func (m *Module) decodeElements(r *reader) {
+	n := r.count()
+	for i := uint32(0); i < n && r.err == nil; i++ {
+		if flags := r.u32(); flags != 0 {
+			r.fail("unsupported element segment flags %d", flags)
+			return
+		}
+		e := element{offset: uint32(m.constExpr(r, I32))}
+		funcs := r.count()
+		for j := uint32(0); j < funcs && r.err == nil; j++ {
+			e.funcs = append(e.funcs, r.u32())
+		}
+		m.elements = append(m.elements, e)
+	}
+}
+
+func (m *Module) decodeData(r *reader) {
+	n := r.count()
+	for i := uint32(0); i < n && r.err == nil; i++ {
+		if flags := r.u32(); flags != 0 {
+			r.fail("unsupported data segment flags %d", flags)
+			return
+		}
+		s := segment{offset: uint32(m.constExpr(r, I32))}
+		s.data = r.bytes(r.count())
+		m.data = append(m.data, s)
+	}
+}
+
+func (m *Module) decodeCode(r *reader, typeIndexes []uint32) {
+	n := r.count()
+	if n != uint32(len(typeIndexes)) {
+		r.fail("%d functions declared, %d defined", len(typeIndexes), n)
+		return
+	}
+	m.funcTypes = append(m.funcTypes, typeIndexes...)
+	if len(m.funcTypes) > maxFunctions {
+		r.fail("too many functions")
+		return
+	}
+
+	for i := uint32(0); i < n && r.err == nil; i++ {
+		body := &reader{b: r.bytes(r.u32())}
+		if r.err != nil {
+			return
+		}
+
+		fn := &function{typ: m.types[typeIndexes[i]]}
+		fn.locals = append(fn.locals, fn.typ.Params...)
+		groups := body.count()
+		for j := uint32(0); j < groups && body.err == nil; j++ {
+			count := body.u32()
+			typ := body.valueType()
+			if uint64(len(fn.locals))+uint64(count) > maxLocals {
+				body.fail("function %d has too many locals", i)
+				break
+			}
+			for k := uint32(0); k < count; k++ {
+				fn.locals = append(fn.locals, typ)
+			}
+		}
+		if body.err == nil {
+			m.compileBody(body, fn)
+		}
+		if body.err != nil {
+			r.err = body.err
+			return
+		}
+		m.funcs = append(m.funcs, fn)
+	}
+}
+
+// validate checks the indexes between the sections.
+func (m *Module) validate() error {
+	funcCount := uint32(len(m.funcTypes))
+	for name, e := range m.exports {
+		var count uint32
+		switch e.kind {
+		case kindFunc:
+			count = funcCount
+		case kindTable:
+			count = boolCount(m.table != nil)
+		case kindMemory:
+			count = boolCount(m.memory != nil)
+		case kindGlobal:
+			count = uint32(len(m.globals))
+		default:
+			return invalid("export %q has unknown kind %d", name, e.kind)
+		}
+		if e.index >= count {
+			return invalid("export %q refers to an unknown index %d", name, e.index)
+		}
+	}
+
+	if m.start >= 0 {
+		if m.start >= int64(funcCount) {
+			return invalid("unknown start function %d", m.start)
+		}
+		if t := m.types[m.funcTypes[m.start]]; len(t.Params) != 0 || len(t.Results) != 0 {
+			return invalid("start function has parameters or results")
+		}
+	}
+	if len(m.elements) > 0 && m.table == nil {
+		return invalid("element segments without a table")
+	}
+	for _, e := range m.elements {
+		for _, index := range e.funcs {
+			if index >= funcCount {
+				return invalid("element segment refers to unknown function %d", index)
+			}
+		}
+	}
+	if len(m.data) > 0 && m.memory == nil {
+		return invalid("data segments without a memory")
+	}
+
+	return nil
+}
+
+func boolCount(b bool) uint32 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+// Imports returns the functions the module imports.
+func (m *Module) Imports() []Import {
+	return append([]Import(nil), m.imports...)
+}
+
+// ExportedFunction returns the signature of an exported function, ok is false when the module
+// doesn't export a function by the name.
+func (m *Module) ExportedFunction(name string) (FuncType, bool) {
+	e, ok := m.exports[name]
+	if !ok || e.kind != kindFunc {
+		return FuncType{}, false
+	}
+
+	return *m.types[m.funcTypes[e.index]], true
+}
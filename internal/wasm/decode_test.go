@@ -0,0 +1,170 @@
+package wasm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const header = "\x00asm\x01\x00\x00\x00"
+
+func TestCompileInvalid(t *testing.T) {
+	valid := (&testModule{funcs: []testFunc{{name: "f", results: i32, body: i32Const(1)}}}).bytes()
+	start := uint32(0)
+
+	tests := []struct {
+		name   string
+		module []byte
+	}{
+		{name: "empty", module: nil},
+		{name: "bad magic", module: []byte("\x00wat\x01\x00\x00\x00")},
+		{name: "unsupported version", module: []byte("\x00asm\x02\x00\x00\x00")},
+		{name: "truncated", module: valid[:len(valid)-1]},
+		{name: "trailing bytes", module: append(append([]byte(nil), valid...), 0x00)},
+		{name: "section out of order", module: cat([]byte(header), section(3, vec()), section(1, vec()))},
+		{name: "duplicate section", module: cat([]byte(header), section(1, vec()), section(1, vec()))},
+		{name: "section longer than the module", module: cat([]byte(header), []byte{1, 10, 0})},
+		{name: "overlong leb", module: cat([]byte(header), []byte{1, 0x80, 0x80, 0x80, 0x80, 0x80, 0x00})},
+		{name: "code without functions", module: cat([]byte(header), section(1, vec(funcType(nil, nil))), section(10, vec(cat(uleb(2), vec(), []byte{opEnd}))))},
+		{name: "unknown type", module: cat([]byte(header), section(1, vec()), section(3, vec(uleb(0))), section(10, vec(cat(uleb(2), vec(), []byte{opEnd}))))},
+		{name: "unknown export", module: cat([]byte(header), section(7, vec(cat(name("f"), []byte{0x00}, uleb(0)))))},
+		{name: "duplicate export", module: cat([]byte(header), section(1, vec(funcType(nil, nil))), section(3, vec(uleb(0))), section(7, vec(cat(name("f"), []byte{0x00}, uleb(0)), cat(name("f"), []byte{0x00}, uleb(0)))), section(10, vec(cat(uleb(2), vec(), []byte{opEnd}))))},
+		{name: "start function with params", module: (&testModule{funcs: []testFunc{{params: i32}}, start: &start}).bytes()},
+		{name: "data without memory", module: (&testModule{funcs: []testFunc{{}}, data: []byte{1}}).bytes()},
+		{name: "element of an unknown function", module: (&testModule{funcs: []testFunc{{}}, table: []uint32{3}}).bytes()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Compile(tt.module); !errors.Is(err, ErrInvalidModule) {
+				t.Fatalf("Compile() error = %v, want ErrInvalidModule", err)
+			}
+		})
+	}
+}
+
+func TestValidateInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   testFunc
+	}{
+		{name: "type mismatch", fn: testFunc{results: i32, body: i64Const(1)}},
+		{name: "operand type mismatch", fn: testFunc{results: i32, body: cat(i32Const(1), f32Const(1), []byte{opI32Add})}},
+		{name: "stack underflow", fn: testFunc{results: i32, body: cat(i32Const(1), []byte{opI32Add})}},
+		{name: "missing result", fn: testFunc{results: i32}},
+		{name: "extra result", fn: testFunc{body: i32Const(1)}},
+		{name: "unknown local", fn: testFunc{params: i32, results: i32, body: []byte{opLocalGet, 5}}},
+		{name: "unknown global", fn: testFunc{results: i32, body: []byte{opGlobalGet, 0}}},
+		{name: "branch too deep", fn: testFunc{body: []byte{opBlock, 0x40, opBr, 2, opEnd}}},
+		{name: "unknown function", fn: testFunc{body: []byte{opCall, 9}}},
+		{name: "call_indirect without a table", fn: testFunc{body: cat(i32Const(0), []byte{opCallIndirect, 0, 0x00})}},
+		{name: "if without else leaves a value", fn: testFunc{params: i32, results: i32, body: cat([]byte{opLocalGet, 0, opIf, byte(I32)}, i32Const(1), []byte{opEnd})}},
+		{name: "else without if", fn: testFunc{body: []byte{opElse}}},
+		{name: "unclosed block", fn: testFunc{body: []byte{opBlock, 0x40}}},
+		{name: "select of different types", fn: testFunc{results: i32, body: cat(i32Const(1), i64Const(2), i32Const(0), []byte{opSelect})}},
+		{name: "unknown opcode", fn: testFunc{body: []byte{0xff}}},
+		{name: "unknown misc opcode", fn: testFunc{body: []byte{opMisc, 0x7f}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Compile((&testModule{funcs: []testFunc{tt.fn}}).bytes()); !errors.Is(err, ErrInvalidModule) {
+				t.Fatalf("Compile() error = %v, want ErrInvalidModule", err)
+			}
+		})
+	}
+
+	loadWithoutMemory := testFunc{results: i32, body: cat(i32Const(0), []byte{opI32Load, 2, 0})}
+	if _, err := Compile((&testModule{funcs: []testFunc{loadWithoutMemory}}).bytes()); !errors.Is(err, ErrInvalidModule) {
+		t.Fatalf("Compile() of a load without a memory error = %v, want ErrInvalidModule", err)
+	}
+	overAligned := testFunc{results: i32, body: cat(i32Const(0), []byte{opI32Load, 3, 0})}
+	if _, err := Compile((&testModule{funcs: []testFunc{overAligned}, memory: []uint32{1}}).bytes()); !errors.Is(err, ErrInvalidModule) {
+		t.Fatalf("Compile() of a load aligned past its size error = %v, want ErrInvalidModule", err)
+	}
+}
+
+// FuzzCompile checks that any input either fails to compile with ErrInvalidModule or compiles to
+// a module whose exports run to a result or a trap within the limits. A Go panic inside the
+// interpreter is recovered as a trap that mentions the runtime error, so it fails the fuzz too.
+func FuzzCompile(f *testing.F) {
+	start := uint32(0)
+	seeds := []*testModule{
+		{funcs: []testFunc{{name: "f", params: i32i32, results: i32, body: []byte{opLocalGet, 0, opLocalGet, 1, opI32DivS}}}},
+		{funcs: []testFunc{{name: "f", results: i32, locals: i32, body: cat(
+			[]byte{opBlock, 0x40, opLoop, 0x40, opLocalGet, 0}, i32Const(1), []byte{opI32Add, opLocalTee, 0},
+			i32Const(100), []byte{opI32LtS, opBrIf, 0, opEnd, opEnd, opLocalGet, 0},
+		)}}},
+		{
+			funcs:  []testFunc{{name: "f", results: i32, body: cat(i32Const(0), []byte{opI32Load, 2, 0, opMemorySize, 0, opMemoryGrow, 0, opI32Add})}},
+			memory: []uint32{1, 2},
+			data:   []byte{1, 2, 3, 4},
+		},
+		{
+			funcs: []testFunc{
+				{results: i32, body: i32Const(1)},
+				{name: "f", params: i32, results: i32, body: []byte{opLocalGet, 0, opCallIndirect, 0, 0x00}},
+			},
+			table: []uint32{0, 1},
+		},
+		{
+			imports: []testImport{{name: "h", params: i32, results: i32}},
+			funcs:   []testFunc{{name: "f", results: i32, body: cat(i32Const(1), []byte{opCall, 0})}},
+		},
+		{
+			funcs:  []testFunc{{body: cat(i32Const(0), i32Const(1), i32Const(8), []byte{opMisc, miscMemoryFill, 0x00})}},
+			memory: []uint32{1},
+			start:  &start,
+		},
+		{funcs: []testFunc{{name: "f", params: f64, results: i32, body: []byte{opLocalGet, 0, opF64Nearest, opI32TruncF64S}}}},
+	}
+	for _, seed := range seeds {
+		f.Add(seed.bytes())
+	}
+
+	f.Fuzz(func(t *testing.T, binaryModule []byte) {
+		module, err := Compile(binaryModule)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidModule) {
+				t.Fatalf("Compile() error = %v, want ErrInvalidModule", err)
+			}
+			return
+		}
+
+		imports := Imports{}
+		for _, imp := range module.Imports() {
+			if imports[imp.Module] == nil {
+				imports[imp.Module] = map[string]*HostFunction{}
+			}
+			results := len(imp.Type.Results)
+			imports[imp.Module][imp.Name] = &HostFunction{
+				Type: imp.Type,
+				Fn: func(*Call, []uint64) ([]uint64, error) {
+					return make([]uint64, results), nil
+				},
+			}
+		}
+
+		limits := Limits{Fuel: 10000, MemoryPages: 4, CallDepth: 64, StackSize: 1024}
+		instance, err := Instantiate(context.Background(), module, imports, limits)
+		if err != nil {
+			checkRuntimeError(t, err)
+			return
+		}
+		for name := range module.exports {
+			typ, ok := module.ExportedFunction(name)
+			if !ok {
+				continue
+			}
+			_, err := instance.Call(context.Background(), name, make([]uint64, len(typ.Params))...)
+			checkRuntimeError(t, err)
+		}
+	})
+}
+
+func checkRuntimeError(t *testing.T, err error) {
+	t.Helper()
+
+	if err != nil && strings.Contains(err.Error(), "runtime error") {
+		t.Fatalf("interpreter panicked: %v", err)
+	}
+}
@@ -0,0 +1,872 @@
+package wasm
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+// DefaultLimits fill in the fields of Limits left zero.
+//
+//nolint:gochecknoglobals // defaults
+var DefaultLimits = Limits{
+	Fuel:        10_000_000,
+	MemoryPages: 16,
+	CallDepth:   500,
+	StackSize:   16384,
+}
+
+// ctxCheckInterval is how many instructions run between checks of the context.
+const ctxCheckInterval = 4096
+
+// nullFunc marks an empty element of the table.
+const nullFunc = math.MaxUint32
+
+// trapped carries a trap up the interpreter, it is recovered at the boundary to the host.
+type trapped struct {
+	err error
+}
+
+func fail(err error) {
+	panic(trapped{err: err})
+}
+
+// Instance is an instantiated module with its own memory, globals and fuel. It runs one call at a
+// time.
+type Instance struct {
+	module   *Module
+	imports  []*HostFunction
+	table    []uint32
+	memory   []byte
+	maxPages uint32
+	globals  []uint64
+	limits   Limits
+	fuel     uint64
+	stack    []uint64
+	depth    int
+	steps    int
+	ctx      context.Context
+}
+
+// Call is a call of a host function, it gives the host access to the memory of the instance.
+type Call struct {
+	instance *Instance
+}
+
+// Context returns the context of the call into the instance.
+func (c *Call) Context() context.Context {
+	return c.instance.ctx
+}
+
+// Read copies length bytes of memory at ptr.
+func (c *Call) Read(ptr, length uint32) ([]byte, error) {
+	end := uint64(ptr) + uint64(length)
+	if end > uint64(len(c.instance.memory)) {
+		return nil, trap("read of %d bytes at %d out of bounds", length, ptr)
+	}
+
+	return append([]byte(nil), c.instance.memory[ptr:end]...), nil
+}
+
+// Write copies data to memory at ptr.
+func (c *Call) Write(ptr uint32, data []byte) error {
+	end := uint64(ptr) + uint64(len(data))
+	if end > uint64(len(c.instance.memory)) {
+		return trap("write of %d bytes at %d out of bounds", len(data), ptr)
+	}
+	copy(c.instance.memory[ptr:end], data)
+
+	return nil
+}
+
+// Charge takes fuel for work the host does on behalf of the module.
+func (c *Call) Charge(fuel uint64) error {
+	if fuel > c.instance.fuel {
+		c.instance.fuel = 0
+		return ErrFuelExhausted
+	}
+	c.instance.fuel -= fuel
+
+	return nil
+}
+
+// Instantiate links a module with host functions, sets up its memory, table and globals and runs
+// its start function.
+func Instantiate(ctx context.Context, m *Module, imports Imports, limits Limits) (*Instance, error) {
+	limits = withDefaults(limits)
+	in := &Instance{module: m, limits: limits, fuel: limits.Fuel, ctx: ctx}
+
+	for _, imp := range m.imports {
+		host := imports[imp.Module][imp.Name]
+		if host == nil {
+			return nil, fmt.Errorf("%w: %s.%s", ErrMissingImport, imp.Module, imp.Name)
+		}
+		if !host.Type.equal(&imp.Type) {
+			return nil, fmt.Errorf("%w: %s.%s has a different signature", ErrMissingImport, imp.Module, imp.Name)
+		}
+		in.imports = append(in.imports, host)
+	}
+
+	if m.memory != nil {
+		in.maxPages = limits.MemoryPages
+		if m.memory.bounded {
+			in.maxPages = min(in.maxPages, m.memory.max)
+		}
+		if m.memory.min > in.maxPages {
+			return nil, trap("memory of %d pages exceeds the limit of %d", m.memory.min, in.maxPages)
+		}
+		in.memory = make([]byte, int(m.memory.min)*PageSize)
+	}
+
+	in.globals = make([]uint64, len(m.globals))
+	for i, g := range m.globals {
+		in.globals[i] = g.init
+	}
+
+	if m.table != nil {
+		in.table = make([]uint32, m.table.min)
+		for i := range in.table {
+			in.table[i] = nullFunc
+		}
+	}
+	for _, e := range m.elements {
+		if uint64(e.offset)+uint64(len(e.funcs)) > uint64(len(in.table)) {
+			return nil, trap("element segment out of bounds of the table")
+		}
+		copy(in.table[e.offset:], e.funcs)
+	}
+	for _, s := range m.data {
+		if uint64(s.offset)+uint64(len(s.data)) > uint64(len(in.memory)) {
+			return nil, trap("data segment out of bounds of the memory")
+		}
+		copy(in.memory[s.offset:], s.data)
+	}
+
+	if m.start >= 0 {
+		if _, err := in.call(ctx, uint32(m.start), nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return in, nil
+}
+
+func withDefaults(limits Limits) Limits {
+	if limits.Fuel == 0 {
+		limits.Fuel = DefaultLimits.Fuel
+	}
+	if limits.MemoryPages == 0 {
+		limits.MemoryPages = DefaultLimits.MemoryPages
+	}
+	if limits.CallDepth == 0 {
+		limits.CallDepth = DefaultLimits.CallDepth
+	}
+	if limits.StackSize == 0 {
+		limits.StackSize = DefaultLimits.StackSize
+	}
+
+	return limits
+}
+
+// Call calls an exported function. Arguments and results are passed as described by ValueType.
+func (in *Instance) Call(ctx context.Context, name string, args ...uint64) ([]uint64, error) {
+	e, ok := in.module.exports[name]
+	if !ok || e.kind != kindFunc {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownExport, name)
+	}
+
+	return in.call(ctx, e.index, args)
+}
+
+// Fuel returns the fuel left.
+func (in *Instance) Fuel() uint64 {
+	return in.fuel
+}
+
+// Memory returns the linear memory, it is only valid until the next call into the instance.
+func (in *Instance) Memory() []byte {
+	return in.memory
+}
+
+func (in *Instance) call(ctx context.Context, index uint32, args []uint64) (results []uint64, err error) {
+	t := in.module.types[in.module.funcTypes[index]]
+	if len(args) != len(t.Params) {
+		return nil, fmt.Errorf("%d arguments for a function of %d parameters", len(args), len(t.Params))
+	}
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if in.stack == nil {
+		in.stack = make([]uint64, in.limits.StackSize)
+	}
+	if len(args) > len(in.stack) {
+		return nil, ErrStackExhausted
+	}
+	in.ctx, in.depth = ctx, 0
+
+	defer func() {
+		if r := recover(); r != nil {
+			results = nil
+			if t, ok := r.(trapped); ok {
+				err = t.err
+				return
+			}
+			// the interpreter checks everything a module can get wrong, a panic left is a bug
+			err = trap("%v", r)
+		}
+	}()
+
+	copy(in.stack, args)
+	sp := in.invoke(index, len(args))
+
+	return append([]uint64(nil), in.stack[:sp]...), nil
+}
+
+// invoke calls a function whose arguments are at the top of the stack, sp is the height of the
+// stack. It returns the height after replacing the arguments with the results.
+func (in *Instance) invoke(index uint32, sp int) int {
+	t := in.module.types[in.module.funcTypes[index]]
+	base := sp - len(t.Params)
+
+	if int(index) < len(in.imports) {
+		host := in.imports[index]
+		args := append([]uint64(nil), in.stack[base:sp]...)
+		results, err := host.Fn(&Call{instance: in}, args)
+		if err != nil {
+			if !errors.Is(err, ErrTrap) {
+				err = fmt.Errorf("%w: %w", ErrTrap, err)
+			}
+			fail(err)
+		}
+		if len(results) != len(t.Results) {
+			fail(trap("host function returned %d results, not %d", len(results), len(t.Results)))
+		}
+		if base+len(results) > len(in.stack) {
+			fail(ErrStackExhausted)
+		}
+		return base + copy(in.stack[base:], results)
+	}
+
+	fn := in.module.funcs[int(index)-len(in.imports)]
+	in.depth++
+	if in.depth > in.limits.CallDepth || base+len(fn.locals)+fn.maxHeight > len(in.stack) {
+		fail(ErrStackExhausted)
+	}
+	clear(in.stack[sp : base+len(fn.locals)])
+	sp = in.run(fn, base)
+	in.depth--
+
+	return sp
+}
+
+// charge takes fuel, trapping once it is used up.
+func (in *Instance) charge(fuel uint64) {
+	if fuel > in.fuel {
+		in.fuel = 0
+		fail(ErrFuelExhausted)
+	}
+	in.fuel -= fuel
+}
+
+// access returns the bytes of memory at the address and offset of a memory instruction.
+func (in *Instance) access(addr, offset uint64, size uint64) []byte {
+	at := uint64(uint32(addr)) + offset
+	if at+size > uint64(len(in.memory)) {
+		fail(trap("memory access of %d bytes at %d out of bounds", size, at))
+	}
+
+	return in.memory[at : at+size]
+}
+
+// span returns the region of memory of a bulk memory instruction.
+func (in *Instance) span(addr, length uint64) []byte {
+	at, n := uint64(uint32(addr)), uint64(uint32(length))
+	if at+n > uint64(len(in.memory)) {
+		fail(trap("memory access of %d bytes at %d out of bounds", n, at))
+	}
+
+	return in.memory[at : at+n]
+}
+
+func (in *Instance) grow(delta uint32) uint64 {
+	pages := uint32(len(in.memory) / PageSize)
+	if uint64(pages)+uint64(delta) > uint64(in.maxPages) {
+		return math.MaxUint32
+	}
+	// new pages are charged like the instructions that would zero them
+	in.charge(uint64(delta) * PageSize / 64)
+	in.memory = append(in.memory, make([]byte, int(delta)*PageSize)...)
+
+	return uint64(pages)
+}
+
+func (in *Instance) callIndirect(typeIndex uint64, element uint32, sp int) int {
+	if element >= uint32(len(in.table)) {
+		fail(trap("undefined table element %d", element))
+	}
+	index := in.table[element]
+	if index == nullFunc {
+		fail(trap("uninitialized table element %d", element))
+	}
+	if !in.module.types[typeIndex].equal(in.module.types[in.module.funcTypes[index]]) {
+		fail(trap("indirect call type mismatch"))
+	}
+
+	return in.invoke(index, sp)
+}
+
+// run executes a function whose locals start at base and returns the stack height after its
+// results.
+//
+//nolint:gocyclo,cyclop,funlen,maintidx // the interpreter loop is one switch over the instructions
+func (in *Instance) run(fn *function, base int) int {
+	stack := in.stack
+	bottom := base + len(fn.locals)
+	sp := bottom
+	code := fn.code
+
+	for pc := 0; pc < len(code); pc++ {
+		if in.fuel == 0 {
+			fail(ErrFuelExhausted)
+		}
+		in.fuel--
+		in.steps++
+		if in.steps%ctxCheckInterval == 0 {
+			if err := in.ctx.Err(); err != nil {
+				fail(err)
+			}
+		}
+
+		ins := &code[pc]
+		switch ins.op {
+		case opUnreachable:
+			fail(trap("unreachable"))
+		case opBr:
+			sp = jump(stack, bottom, sp, ins.b, ins.arity)
+			pc = int(ins.a) - 1
+		case opBrIf:
+			sp--
+			if uint32(stack[sp]) != 0 {
+				sp = jump(stack, bottom, sp, ins.b, ins.arity)
+				pc = int(ins.a) - 1
+			}
+		case opBrTable:
+			sp--
+			table := fn.tables[ins.a]
+			i := min(int(uint32(stack[sp])), len(table)-1)
+			sp = jump(stack, bottom, sp, table[i].height, table[i].arity)
+			pc = int(table[i].pc) - 1
+		case opIf:
+			sp--
+			if uint32(stack[sp]) == 0 {
+				pc = int(ins.a) - 1
+			}
+		case opJump:
+			pc = int(ins.a) - 1
+		case opCall:
+			sp = in.invoke(uint32(ins.a), sp)
+		case opCallIndirect:
+			sp--
+			sp = in.callIndirect(ins.a, uint32(stack[sp]), sp)
+		case opDrop:
+			sp--
+		case opSelect:
+			sp -= 2
+			if uint32(stack[sp+1]) == 0 {
+				stack[sp-1] = stack[sp]
+			}
+		case opLocalGet:
+			stack[sp] = stack[base+int(ins.a)]
+			sp++
+		case opLocalSet:
+			sp--
+			stack[base+int(ins.a)] = stack[sp]
+		case opLocalTee:
+			stack[base+int(ins.a)] = stack[sp-1]
+		case opGlobalGet:
+			stack[sp] = in.globals[ins.a]
+			sp++
+		case opGlobalSet:
+			sp--
+			in.globals[ins.a] = stack[sp]
+
+		case opI32Load, opF32Load:
+			stack[sp-1] = uint64(binary.LittleEndian.Uint32(in.access(stack[sp-1], ins.a, 4)))
+		case opI64Load, opF64Load:
+			stack[sp-1] = binary.LittleEndian.Uint64(in.access(stack[sp-1], ins.a, 8))
+		case opI32Load8S:
+			stack[sp-1] = uint64(uint32(int32(int8(in.access(stack[sp-1], ins.a, 1)[0]))))
+		case opI32Load8U, opI64Load8U:
+			stack[sp-1] = uint64(in.access(stack[sp-1], ins.a, 1)[0])
+		case opI32Load16S:
+			stack[sp-1] = uint64(uint32(int32(int16(binary.LittleEndian.Uint16(in.access(stack[sp-1], ins.a, 2))))))
+		case opI32Load16U, opI64Load16U:
+			stack[sp-1] = uint64(binary.LittleEndian.Uint16(in.access(stack[sp-1], ins.a, 2)))
+		case opI64Load8S:
+			stack[sp-1] = uint64(int64(int8(in.access(stack[sp-1], ins.a, 1)[0])))
+		case opI64Load16S:
+			stack[sp-1] = uint64(int64(int16(binary.LittleEndian.Uint16(in.access(stack[sp-1], ins.a, 2)))))
+		case opI64Load32S:
+			stack[sp-1] = uint64(int64(int32(binary.LittleEndian.Uint32(in.access(stack[sp-1], ins.a, 4)))))
+		case opI64Load32U:
+			stack[sp-1] = uint64(binary.LittleEndian.Uint32(in.access(stack[sp-1], ins.a, 4)))
+		case opI32Store, opF32Store, opI64Store32:
+			sp -= 2
+			binary.LittleEndian.PutUint32(in.access(stack[sp], ins.a, 4), uint32(stack[sp+1]))
+		case opI64Store, opF64Store:
+			sp -= 2
+			binary.LittleEndian.PutUint64(in.access(stack[sp], ins.a, 8), stack[sp+1])
+		case opI32Store8, opI64Store8:
+			sp -= 2
+			in.access(stack[sp], ins.a, 1)[0] = byte(stack[sp+1])
+		case opI32Store16, opI64Store16:
+			sp -= 2
+			binary.LittleEndian.PutUint16(in.access(stack[sp], ins.a, 2), uint16(stack[sp+1]))
+		case opMemorySize:
+			stack[sp] = uint64(len(in.memory) / PageSize)
+			sp++
+		case opMemoryGrow:
+			stack[sp-1] = in.grow(uint32(stack[sp-1]))
+
+		case opI32Const, opI64Const, opF32Const, opF64Const:
+			stack[sp] = ins.a
+			sp++
+
+		case opI32Eqz:
+			stack[sp-1] = boolValue(uint32(stack[sp-1]) == 0)
+		case opI64Eqz:
+			stack[sp-1] = boolValue(stack[sp-1] == 0)
+		case opI32Clz:
+			stack[sp-1] = uint64(bits.LeadingZeros32(uint32(stack[sp-1])))
+		case opI32Ctz:
+			stack[sp-1] = uint64(bits.TrailingZeros32(uint32(stack[sp-1])))
+		case opI32Pop:
+			stack[sp-1] = uint64(bits.OnesCount32(uint32(stack[sp-1])))
+		case opI64Clz:
+			stack[sp-1] = uint64(bits.LeadingZeros64(stack[sp-1]))
+		case opI64Ctz:
+			stack[sp-1] = uint64(bits.TrailingZeros64(stack[sp-1]))
+		case opI64Pop:
+			stack[sp-1] = uint64(bits.OnesCount64(stack[sp-1]))
+
+		case opF32Abs, opF32Neg, opF32Ceil, opF32Floor, opF32Trunc, opF32Nearest, opF32Sqrt:
+			stack[sp-1] = f32Unary(ins.op, uint32(stack[sp-1]))
+		case opF64Abs, opF64Neg, opF64Ceil, opF64Floor, opF64Trunc, opF64Nearest, opF64Sqrt:
+			stack[sp-1] = f64Unary(ins.op, stack[sp-1])
+
+		case opI32WrapI64, opI64ExtendI32U, opI32ReinterpretF32, opF32ReinterpretI32:
+			stack[sp-1] = uint64(uint32(stack[sp-1]))
+		case opI64ReinterpretF64, opF64ReinterpretI64:
+		case opI64ExtendI32S, opI64Extend32S:
+			stack[sp-1] = uint64(int64(int32(stack[sp-1])))
+		case opI32Extend8S:
+			stack[sp-1] = uint64(uint32(int32(int8(stack[sp-1]))))
+		case opI32Extend16S:
+			stack[sp-1] = uint64(uint32(int32(int16(stack[sp-1]))))
+		case opI64Extend8S:
+			stack[sp-1] = uint64(int64(int8(stack[sp-1])))
+		case opI64Extend16S:
+			stack[sp-1] = uint64(int64(int16(stack[sp-1])))
+		case opI32TruncF32S, opI32TruncF32U, opI32TruncF64S, opI32TruncF64U,
+			opI64TruncF32S, opI64TruncF32U, opI64TruncF64S, opI64TruncF64U:
+			stack[sp-1] = truncate(ins.op, stack[sp-1])
+		case opF32ConvertI32S:
+			stack[sp-1] = f32Value(float32(int32(stack[sp-1])))
+		case opF32ConvertI32U:
+			stack[sp-1] = f32Value(float32(uint32(stack[sp-1])))
+		case opF32ConvertI64S:
+			stack[sp-1] = f32Value(float32(int64(stack[sp-1])))
+		case opF32ConvertI64U:
+			stack[sp-1] = f32Value(float32(stack[sp-1]))
+		case opF32DemoteF64:
+			stack[sp-1] = f32Value(float32(math.Float64frombits(stack[sp-1])))
+		case opF64ConvertI32S:
+			stack[sp-1] = math.Float64bits(float64(int32(stack[sp-1])))
+		case opF64ConvertI32U:
+			stack[sp-1] = math.Float64bits(float64(uint32(stack[sp-1])))
+		case opF64ConvertI64S:
+			stack[sp-1] = math.Float64bits(float64(int64(stack[sp-1])))
+		case opF64ConvertI64U:
+			stack[sp-1] = math.Float64bits(float64(stack[sp-1]))
+		case opF64PromoteF32:
+			stack[sp-1] = math.Float64bits(float64(math.Float32frombits(uint32(stack[sp-1]))))
+
+		case opMisc:
+			switch ins.misc {
+			case miscMemoryCopy:
+				sp -= 3
+				n := uint64(uint32(stack[sp+2]))
+				dst, src := in.span(stack[sp], n), in.span(stack[sp+1], n)
+				in.charge(n / 64)
+				copy(dst, src)
+			case miscMemoryFill:
+				sp -= 3
+				dst := in.span(stack[sp], stack[sp+2])
+				in.charge(uint64(len(dst)) / 64)
+				value := byte(stack[sp+1])
+				for i := range dst {
+					dst[i] = value
+				}
+			default:
+				stack[sp-1] = truncateSaturated(ins.misc, stack[sp-1])
+			}
+
+		default:
+			// the rest are the binary operators
+			sp--
+			stack[sp-1] = binaryOp(ins.op, stack[sp-1], stack[sp])
+		}
+	}
+
+	results := len(fn.typ.Results)
+	copy(stack[base:], stack[sp-results:sp])
+
+	return base + results
+}
+
+// jump keeps the values a branch carries on top of the stack at the height of its target.
+func jump(stack []uint64, bottom, sp int, height uint64, arity uint32) int {
+	dst := bottom + int(height)
+	copy(stack[dst:], stack[sp-int(arity):sp])
+
+	return dst + int(arity)
+}
+
+func boolValue(b bool) uint64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+func f32Value(f float32) uint64 {
+	return uint64(math.Float32bits(f))
+}
+
+func f32Unary(op byte, v uint32) uint64 {
+	switch op {
+	case opF32Abs:
+		return uint64(v &^ (1 << 31))
+	case opF32Neg:
+		return uint64(v ^ (1 << 31))
+	}
+
+	// the float64 result of these is exact, so rounding it to float32 is too
+	return f32Value(float32(f64Op(op-opF32Abs+opF64Abs, float64(math.Float32frombits(v)))))
+}
+
+func f64Unary(op byte, v uint64) uint64 {
+	switch op {
+	case opF64Abs:
+		return v &^ (1 << 63)
+	case opF64Neg:
+		return v ^ (1 << 63)
+	}
+
+	return math.Float64bits(f64Op(op, math.Float64frombits(v)))
+}
+
+func f64Op(op byte, f float64) float64 {
+	switch op {
+	case opF64Ceil:
+		return math.Ceil(f)
+	case opF64Floor:
+		return math.Floor(f)
+	case opF64Trunc:
+		return math.Trunc(f)
+	case opF64Nearest:
+		return math.RoundToEven(f)
+	}
+
+	return math.Sqrt(f)
+}
+
+// Bounds of the conversions from floats to integers, exclusive.
+const (
+	minI32 = -2147483649.0
+	maxI32 = 2147483648.0
+	maxU32 = 4294967296.0
+	// minI64 is the float64 below -2^63.
+	minI64 = -9223372036854777856.0
+	maxI64 = 9223372036854775808.0
+	maxU64 = 18446744073709551616.0
+)
+
+func truncate(op byte, v uint64) uint64 {
+	var f float64
+	switch op {
+	case opI32TruncF32S, opI32TruncF32U, opI64TruncF32S, opI64TruncF32U:
+		f = float64(math.Float32frombits(uint32(v)))
+	default:
+		f = math.Float64frombits(v)
+	}
+	if math.IsNaN(f) {
+		fail(trap("invalid conversion to integer"))
+	}
+
+	var lo, hi float64
+	switch op {
+	case opI32TruncF32S, opI32TruncF64S:
+		lo, hi = minI32, maxI32
+	case opI32TruncF32U, opI32TruncF64U:
+		lo, hi = -1, maxU32
+	case opI64TruncF32S, opI64TruncF64S:
+		lo, hi = minI64, maxI64
+	default:
+		lo, hi = -1, maxU64
+	}
+	if f <= lo || f >= hi {
+		fail(trap("integer overflow"))
+	}
+
+	switch op {
+	case opI32TruncF32S, opI32TruncF64S:
+		return uint64(uint32(int32(f)))
+	case opI32TruncF32U, opI32TruncF64U:
+		return uint64(uint32(f))
+	case opI64TruncF32S, opI64TruncF64S:
+		return uint64(int64(f))
+	}
+
+	return uint64(f)
+}
+
+func truncateSaturated(misc byte, v uint64) uint64 {
+	var f float64
+	switch misc {
+	case miscI32TruncSatF32S, miscI32TruncSatF32U, miscI64TruncSatF32S, miscI64TruncSatF32U:
+		f = float64(math.Float32frombits(uint32(v)))
+	default:
+		f = math.Float64frombits(v)
+	}
+	if math.IsNaN(f) {
+		return 0
+	}
+
+	switch misc {
+	case miscI32TruncSatF32S, miscI32TruncSatF64S:
+		return uint64(uint32(int32(math.Max(math.Min(f, math.MaxInt32), math.MinInt32))))
+	case miscI32TruncSatF32U, miscI32TruncSatF64U:
+		return uint64(uint32(math.Max(math.Min(f, math.MaxUint32), 0)))
+	case miscI64TruncSatF32S, miscI64TruncSatF64S:
+		switch {
+		case f <= math.MinInt64:
+			return 1 << 63
+		case f >= maxI64:
+			return math.MaxInt64
+		}
+		return uint64(int64(f))
+	}
+
+	switch {
+	case f <= 0:
+		return 0
+	case f >= maxU64:
+		return math.MaxUint64
+	}
+
+	return uint64(f)
+}
+
+//nolint:gocyclo,cyclop,funlen // one case per operator
+func binaryOp(op byte, a, b uint64) uint64 {
+	x, y := uint32(a), uint32(b)
+	fx, fy := math.Float32frombits(x), math.Float32frombits(y)
+	dx, dy := math.Float64frombits(a), math.Float64frombits(b)
+
+	switch op {
+	case opI32Eq:
+		return boolValue(x == y)
+	case opI32Ne:
+		return boolValue(x != y)
+	case opI32LtS:
+		return boolValue(int32(x) < int32(y))
+	case opI32LtU:
+		return boolValue(x < y)
+	case opI32GtS:
+		return boolValue(int32(x) > int32(y))
+	case opI32GtU:
+		return boolValue(x > y)
+	case opI32LeS:
+		return boolValue(int32(x) <= int32(y))
+	case opI32LeU:
+		return boolValue(x <= y)
+	case opI32GeS:
+		return boolValue(int32(x) >= int32(y))
+	case opI32GeU:
+		return boolValue(x >= y)
+	case opI64Eq:
+		return boolValue(a == b)
+	case opI64Ne:
+		return boolValue(a != b)
+	case opI64LtS:
+		return boolValue(int64(a) < int64(b))
+	case opI64LtU:
+		return boolValue(a < b)
+	case opI64GtS:
+		return boolValue(int64(a) > int64(b))
+	case opI64GtU:
+		return boolValue(a > b)
+	case opI64LeS:
+		return boolValue(int64(a) <= int64(b))
+	case opI64LeU:
+		return boolValue(a <= b)
+	case opI64GeS:
+		return boolValue(int64(a) >= int64(b))
+	case opI64GeU:
+		return boolValue(a >= b)
+	case opF32Eq:
+		return boolValue(fx == fy)
+	case opF32Ne:
+		return boolValue(fx != fy)
+	case opF32Lt:
+		return boolValue(fx < fy)
+	case opF32Gt:
+		return boolValue(fx > fy)
+	case opF32Le:
+		return boolValue(fx <= fy)
+	case opF32Ge:
+		return boolValue(fx >= fy)
+	case opF64Eq:
+		return boolValue(dx == dy)
+	case opF64Ne:
+		return boolValue(dx != dy)
+	case opF64Lt:
+		return boolValue(dx < dy)
+	case opF64Gt:
+		return boolValue(dx > dy)
+	case opF64Le:
+		return boolValue(dx <= dy)
+	case opF64Ge:
+		return boolValue(dx >= dy)
+
+	case opI32Add:
+		return uint64(x + y)
+	case opI32Sub:
+		return uint64(x - y)
+	case opI32Mul:
+		return uint64(x * y)
+	case opI32DivS:
+		if y == 0 {
+			fail(trap("integer divide by zero"))
+		}
+		if int32(x) == math.MinInt32 && int32(y) == -1 {
+			fail(trap("integer overflow"))
+		}
+		return uint64(uint32(int32(x) / int32(y)))
+	case opI32DivU:
+		if y == 0 {
+			fail(trap("integer divide by zero"))
+		}
+		return uint64(x / y)
+	case opI32RemS:
+		if y == 0 {
+			fail(trap("integer divide by zero"))
+		}
+		return uint64(uint32(int32(x) % int32(y)))
+	case opI32RemU:
+		if y == 0 {
+			fail(trap("integer divide by zero"))
+		}
+		return uint64(x % y)
+	case opI32And:
+		return uint64(x & y)
+	case opI32Or:
+		return uint64(x | y)
+	case opI32Xor:
+		return uint64(x ^ y)
+	case opI32Shl:
+		return uint64(x << (y & 31))
+	case opI32ShrS:
+		return uint64(uint32(int32(x) >> (y & 31)))
+	case opI32ShrU:
+		return uint64(x >> (y & 31))
+	case opI32Rotl:
+		return uint64(bits.RotateLeft32(x, int(y&31)))
+	case opI32Rotr:
+		return uint64(bits.RotateLeft32(x, -int(y&31)))
+
+	case opI64Add:
+		return a + b
+	case opI64Sub:
+		return a - b
+	case opI64Mul:
+		return a * b
+	case opI64DivS:
+		if b == 0 {
+			fail(trap("integer divide by zero"))
+		}
+		if int64(a) == math.MinInt64 && int64(b) == -1 {
+			fail(trap("integer overflow"))
+		}
+		return uint64(int64(a) / int64(b))
+	case opI64DivU:
+		if b == 0 {
+			fail(trap("integer divide by zero"))
+		}
+		return a / b
+	case opI64RemS:
+		if b == 0 {
+			fail(trap("integer divide by zero"))
+		}
+		return uint64(int64(a) % int64(b))
+	case opI64RemU:
+		if b == 0 {
+			fail(trap("integer divide by zero"))
+		}
+		return a % b
+	case opI64And:
+		return a & b
+	case opI64Or:
+		return a | b
+	case opI64Xor:
+		return a ^ b
+	case opI64Shl:
+		return a << (b & 63)
+	case opI64ShrS:
+		return uint64(int64(a) >> (b & 63))
+	case opI64ShrU:
+		return a >> (b & 63)
+	case opI64Rotl:
+		return bits.RotateLeft64(a, int(b&63))
+	case opI64Rotr:
+		return bits.RotateLeft64(a, -int(b&63))
+
+	case opF32Add:
+		return f32Value(fx + fy)
+	case opF32Sub:
+		return f32Value(fx - fy)
+	case opF32Mul:
+		return f32Value(fx * fy)
+	case opF32Div:
+		return f32Value(fx / fy)
+	case opF32Min:
+		return f32Value(float32(math.Min(float64(fx), float64(fy))))
+	case opF32Max:
+		return f32Value(float32(math.Max(float64(fx), float64(fy))))
+	case opF32Copysign:
+		return uint64(x&^(1<<31) | y&(1<<31))
+	case opF64Add:
+		return math.Float64bits(dx + dy)
+	case opF64Sub:
+		return math.Float64bits(dx - dy)
+	case opF64Mul:
+		return math.Float64bits(dx * dy)
+	case opF64Div:
+		return math.Float64bits(dx / dy)
+	case opF64Min:
+		return math.Float64bits(math.Min(dx, dy))
+	case opF64Max:
+		return math.Float64bits(math.Max(dx, dy))
+	case opF64Copysign:
+		return a&^(1<<63) | b&(1<<63)
+	}
+
+	fail(trap("unsupported instruction 0x%x", op))
+	return 0
+}
@@ -0,0 +1,458 @@
+package wasm
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+// The expected values follow the WebAssembly 1.0 spec tests, e.g. i32.wast and conversions.wast.
+
+var (
+	i32i32 = []ValueType{I32, I32}
+	i64i64 = []ValueType{I64, I64}
+	i32    = []ValueType{I32}
+	i64    = []ValueType{I64}
+	f32    = []ValueType{F32}
+	f64    = []ValueType{F64}
+)
+
+// call compiles and instantiates m and calls its export name.
+func call(t *testing.T, m *testModule, imports Imports, limits Limits, name string, args ...uint64) ([]uint64, error) {
+	t.Helper()
+
+	module, err := Compile(m.bytes())
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	instance, err := Instantiate(context.Background(), module, imports, limits)
+	if err != nil {
+		t.Fatalf("Instantiate() error = %v", err)
+	}
+
+	return instance.Call(context.Background(), name, args...)
+}
+
+// callFunc calls a module made of fn alone.
+func callFunc(t *testing.T, fn testFunc, args ...uint64) ([]uint64, error) {
+	t.Helper()
+
+	fn.name = "f"
+	return call(t, &testModule{funcs: []testFunc{fn}, memory: []uint32{1}}, nil, Limits{}, "f", args...)
+}
+
+type conformanceCase struct {
+	name string
+	fn   testFunc
+	args []uint64
+	want uint64
+	// trap is set for calls that trap instead of returning want.
+	trap bool
+}
+
+func runConformance(t *testing.T, cases []conformanceCase) {
+	t.Helper()
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := callFunc(t, tc.fn, tc.args...)
+			if tc.trap {
+				if !errors.Is(err, ErrTrap) {
+					t.Fatalf("Call() = %v, %v, want a trap", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Call() error = %v", err)
+			}
+			if len(got) != 1 || got[0] != tc.want {
+				t.Fatalf("Call() = %#x, want %#x", got, tc.want)
+			}
+		})
+	}
+}
+
+func binary32(op byte) testFunc {
+	return testFunc{params: i32i32, results: i32, body: []byte{opLocalGet, 0, opLocalGet, 1, op}}
+}
+
+func binary64(op byte) testFunc {
+	return testFunc{params: i64i64, results: i64, body: []byte{opLocalGet, 0, opLocalGet, 1, op}}
+}
+
+func unary(params, results []ValueType, op ...byte) testFunc {
+	return testFunc{params: params, results: results, body: append([]byte{opLocalGet, 0}, op...)}
+}
+
+func u32(v int32) uint64 {
+	return uint64(uint32(v))
+}
+
+func TestIntegerArithmetic(t *testing.T) {
+	runConformance(t, []conformanceCase{
+		{name: "i32.add wraps", fn: binary32(opI32Add), args: []uint64{0x7fffffff, 1}, want: 0x80000000},
+		{name: "i32.sub wraps", fn: binary32(opI32Sub), args: []uint64{0, 1}, want: 0xffffffff},
+		{name: "i32.mul", fn: binary32(opI32Mul), args: []uint64{0x01234567, 0x76543210}, want: 0x358e7470},
+		{name: "i32.div_s truncates", fn: binary32(opI32DivS), args: []uint64{u32(-7), 2}, want: u32(-3)},
+		{name: "i32.div_s overflow", fn: binary32(opI32DivS), args: []uint64{0x80000000, u32(-1)}, trap: true},
+		{name: "i32.div_s by zero", fn: binary32(opI32DivS), args: []uint64{1, 0}, trap: true},
+		{name: "i32.div_u", fn: binary32(opI32DivU), args: []uint64{0xffffffff, 2}, want: 0x7fffffff},
+		{name: "i32.div_u by zero", fn: binary32(opI32DivU), args: []uint64{1, 0}, trap: true},
+		{name: "i32.rem_s sign of dividend", fn: binary32(opI32RemS), args: []uint64{u32(-7), 2}, want: u32(-1)},
+		{name: "i32.rem_s of overflow", fn: binary32(opI32RemS), args: []uint64{0x80000000, u32(-1)}, want: 0},
+		{name: "i32.rem_u by zero", fn: binary32(opI32RemU), args: []uint64{1, 0}, trap: true},
+		{name: "i32.shl masks the count", fn: binary32(opI32Shl), args: []uint64{1, 33}, want: 2},
+		{name: "i32.shr_s", fn: binary32(opI32ShrS), args: []uint64{0x80000000, 1}, want: 0xc0000000},
+		{name: "i32.shr_u", fn: binary32(opI32ShrU), args: []uint64{0x80000000, 1}, want: 0x40000000},
+		{name: "i32.rotl", fn: binary32(opI32Rotl), args: []uint64{0xabcd9876, 1}, want: 0x579b30ed},
+		{name: "i32.rotr", fn: binary32(opI32Rotr), args: []uint64{0xb0c1d2e3, 5}, want: 0x1d860e97},
+		{name: "i32.clz of zero", fn: unary(i32, i32, opI32Clz), args: []uint64{0}, want: 32},
+		{name: "i32.clz", fn: unary(i32, i32, opI32Clz), args: []uint64{0x00008000}, want: 16},
+		{name: "i32.ctz of zero", fn: unary(i32, i32, opI32Ctz), args: []uint64{0}, want: 32},
+		{name: "i32.popcnt", fn: unary(i32, i32, opI32Pop), args: []uint64{0xffffffff}, want: 32},
+		{name: "i32.eqz", fn: unary(i32, i32, opI32Eqz), args: []uint64{0}, want: 1},
+		{name: "i32.lt_s", fn: binary32(opI32LtS), args: []uint64{u32(-1), 1}, want: 1},
+		{name: "i32.lt_u", fn: binary32(opI32LtU), args: []uint64{u32(-1), 1}, want: 0},
+		{name: "i32.extend8_s", fn: unary(i32, i32, opI32Extend8S), args: []uint64{0x80}, want: 0xffffff80},
+		{name: "i32.extend16_s", fn: unary(i32, i32, opI32Extend16S), args: []uint64{0x8000}, want: 0xffff8000},
+		{name: "i64.add wraps", fn: binary64(opI64Add), args: []uint64{math.MaxInt64, 1}, want: 1 << 63},
+		{name: "i64.mul", fn: binary64(opI64Mul), args: []uint64{0x0123456789abcdef, 0xfedcba9876543210}, want: 0x2236d88fe5618cf0},
+		{name: "i64.div_s overflow", fn: binary64(opI64DivS), args: []uint64{1 << 63, math.MaxUint64}, trap: true},
+		{name: "i64.div_u by zero", fn: binary64(opI64DivU), args: []uint64{1, 0}, trap: true},
+		{name: "i64.rem_s of overflow", fn: binary64(opI64RemS), args: []uint64{1 << 63, math.MaxUint64}, want: 0},
+		{name: "i64.shl masks the count", fn: binary64(opI64Shl), args: []uint64{1, 65}, want: 2},
+		{name: "i64.extend_i32_s", fn: unary(i32, i64, opI64ExtendI32S), args: []uint64{0xffffffff}, want: math.MaxUint64},
+		{name: "i64.extend_i32_u", fn: unary(i32, i64, opI64ExtendI32U), args: []uint64{0xffffffff}, want: 0xffffffff},
+		{name: "i32.wrap_i64", fn: unary(i64, i32, opI32WrapI64), args: []uint64{0x100000002}, want: 2},
+	})
+}
+
+func f32Bits(v float32) uint64 {
+	return uint64(math.Float32bits(v))
+}
+
+func f64Bits(v float64) uint64 {
+	return math.Float64bits(v)
+}
+
+func TestFloatArithmetic(t *testing.T) {
+	negZero32 := uint64(0x80000000)
+	negZero64 := uint64(1 << 63)
+
+	runConformance(t, []conformanceCase{
+		{name: "f32.add", fn: testFunc{params: []ValueType{F32, F32}, results: f32, body: []byte{opLocalGet, 0, opLocalGet, 1, opF32Add}}, args: []uint64{f32Bits(1.5), f32Bits(2.25)}, want: f32Bits(3.75)},
+		{name: "f32.min of zeros", fn: testFunc{params: []ValueType{F32, F32}, results: f32, body: []byte{opLocalGet, 0, opLocalGet, 1, opF32Min}}, args: []uint64{negZero32, 0}, want: negZero32},
+		{name: "f64.max of zeros", fn: testFunc{params: []ValueType{F64, F64}, results: f64, body: []byte{opLocalGet, 0, opLocalGet, 1, opF64Max}}, args: []uint64{negZero64, 0}, want: 0},
+		{name: "f32.sqrt", fn: unary(f32, f32, opF32Sqrt), args: []uint64{f32Bits(4)}, want: f32Bits(2)},
+		{name: "f64.nearest rounds to even", fn: unary(f64, f64, opF64Nearest), args: []uint64{f64Bits(2.5)}, want: f64Bits(2)},
+		{name: "f64.nearest keeps the sign", fn: unary(f64, f64, opF64Nearest), args: []uint64{f64Bits(-0.5)}, want: negZero64},
+		{name: "f64.copysign", fn: testFunc{params: []ValueType{F64, F64}, results: f64, body: []byte{opLocalGet, 0, opLocalGet, 1, opF64Copysign}}, args: []uint64{f64Bits(1), negZero64}, want: f64Bits(-1)},
+		{name: "f32.neg of zero", fn: unary(f32, f32, opF32Neg), args: []uint64{0}, want: negZero32},
+		{name: "f64.div by zero", fn: testFunc{params: []ValueType{F64, F64}, results: f64, body: []byte{opLocalGet, 0, opLocalGet, 1, opF64Div}}, args: []uint64{f64Bits(1), 0}, want: f64Bits(math.Inf(1))},
+		{name: "f64.promote_f32", fn: unary(f32, f64, opF64PromoteF32), args: []uint64{f32Bits(0.5)}, want: f64Bits(0.5)},
+		{name: "f64.convert_i64_u", fn: unary(i64, f64, opF64ConvertI64U), args: []uint64{math.MaxUint64}, want: f64Bits(18446744073709551616)},
+		{name: "i32.reinterpret_f32", fn: unary(f32, i32, opI32ReinterpretF32), args: []uint64{f32Bits(-1)}, want: 0xbf800000},
+	})
+}
+
+func TestTruncation(t *testing.T) {
+	nan32 := f32Bits(float32(math.NaN()))
+
+	runConformance(t, []conformanceCase{
+		{name: "i32.trunc_f32_s", fn: unary(f32, i32, opI32TruncF32S), args: []uint64{f32Bits(-1.9)}, want: u32(-1)},
+		{name: "i32.trunc_f32_s of NaN", fn: unary(f32, i32, opI32TruncF32S), args: []uint64{nan32}, trap: true},
+		{name: "i32.trunc_f64_s lowest", fn: unary(f64, i32, opI32TruncF64S), args: []uint64{f64Bits(-2147483648.9)}, want: 0x80000000},
+		{name: "i32.trunc_f64_s overflow", fn: unary(f64, i32, opI32TruncF64S), args: []uint64{f64Bits(2147483648)}, trap: true},
+		{name: "i32.trunc_f64_u of a negative fraction", fn: unary(f64, i32, opI32TruncF64U), args: []uint64{f64Bits(-0.9)}, want: 0},
+		{name: "i32.trunc_f64_u overflow", fn: unary(f64, i32, opI32TruncF64U), args: []uint64{f64Bits(4294967296)}, trap: true},
+		{name: "i64.trunc_f64_s overflow", fn: unary(f64, i64, opI64TruncF64S), args: []uint64{f64Bits(9223372036854775808)}, trap: true},
+		{name: "i32.trunc_sat_f32_s of NaN", fn: unary(f32, i32, opMisc, miscI32TruncSatF32S), args: []uint64{nan32}, want: 0},
+		{name: "i32.trunc_sat_f64_s saturates", fn: unary(f64, i32, opMisc, miscI32TruncSatF64S), args: []uint64{f64Bits(1e10)}, want: 0x7fffffff},
+		{name: "i32.trunc_sat_f64_u of a negative", fn: unary(f64, i32, opMisc, miscI32TruncSatF64U), args: []uint64{f64Bits(-1)}, want: 0},
+		{name: "i64.trunc_sat_f64_u saturates", fn: unary(f64, i64, opMisc, miscI64TruncSatF64U), args: []uint64{f64Bits(math.Inf(1))}, want: math.MaxUint64},
+	})
+}
+
+func TestControlFlow(t *testing.T) {
+	factorial := testFunc{params: i32, results: i32, locals: i32, body: cat(
+		i32Const(1), []byte{opLocalSet, 1},
+		[]byte{opBlock, 0x40, opLoop, 0x40},
+		[]byte{opLocalGet, 0, opI32Eqz, opBrIf, 1},
+		[]byte{opLocalGet, 1, opLocalGet, 0, opI32Mul, opLocalSet, 1},
+		[]byte{opLocalGet, 0}, i32Const(1), []byte{opI32Sub, opLocalSet, 0},
+		[]byte{opBr, 0, opEnd, opEnd, opLocalGet, 1},
+	)}
+	// br_table jumps out of the innermost block for 0, the middle one for 1 and the outer one
+	// otherwise
+	brTable := testFunc{params: i32, results: i32, body: cat(
+		[]byte{opBlock, 0x40, opBlock, 0x40, opBlock, 0x40},
+		[]byte{opLocalGet, 0, opBrTable, 2, 0, 1, 2, opEnd},
+		i32Const(10), []byte{opReturn, opEnd},
+		i32Const(11), []byte{opReturn, opEnd},
+		i32Const(12),
+	)}
+	ifElse := testFunc{params: i32, results: i32, body: cat(
+		[]byte{opLocalGet, 0, opIf, byte(I32)}, i32Const(1), []byte{opElse}, i32Const(2), []byte{opEnd},
+	)}
+	// the branch carries the value of the block, the rest of the block is skipped
+	brValue := testFunc{params: i32, results: i32, body: cat(
+		[]byte{opBlock, byte(I32)}, i32Const(7), []byte{opLocalGet, 0, opBrIf, 0, opDrop}, i32Const(8), []byte{opEnd},
+	)}
+	selectFn := testFunc{params: i32, results: i32, body: cat(i32Const(3), i32Const(4), []byte{opLocalGet, 0, opSelect})}
+
+	runConformance(t, []conformanceCase{
+		{name: "loop", fn: factorial, args: []uint64{10}, want: 3628800},
+		{name: "br_table first", fn: brTable, args: []uint64{0}, want: 10},
+		{name: "br_table second", fn: brTable, args: []uint64{1}, want: 11},
+		{name: "br_table default", fn: brTable, args: []uint64{5}, want: 12},
+		{name: "if", fn: ifElse, args: []uint64{1}, want: 1},
+		{name: "else", fn: ifElse, args: []uint64{0}, want: 2},
+		{name: "br_if taken", fn: brValue, args: []uint64{1}, want: 7},
+		{name: "br_if not taken", fn: brValue, args: []uint64{0}, want: 8},
+		{name: "select first", fn: selectFn, args: []uint64{1}, want: 3},
+		{name: "select second", fn: selectFn, args: []uint64{0}, want: 4},
+		{name: "unreachable", fn: testFunc{results: i32, body: []byte{opUnreachable}}, trap: true},
+	})
+}
+
+func TestCalls(t *testing.T) {
+	// fib calls itself, it is the only function so its index is 0
+	fib := testFunc{name: "fib", params: i32, results: i32, body: cat(
+		[]byte{opLocalGet, 0}, i32Const(2), []byte{opI32LtU, opIf, byte(I32), opLocalGet, 0, opElse},
+		[]byte{opLocalGet, 0}, i32Const(1), []byte{opI32Sub, opCall, 0},
+		[]byte{opLocalGet, 0}, i32Const(2), []byte{opI32Sub, opCall, 0},
+		[]byte{opI32Add, opEnd},
+	)}
+	got, err := call(t, &testModule{funcs: []testFunc{fib}}, nil, Limits{}, "fib", 20)
+	if err != nil || len(got) != 1 || got[0] != 6765 {
+		t.Fatalf("fib(20) = %v, %v, want 6765", got, err)
+	}
+
+	_, err = call(t, &testModule{funcs: []testFunc{fib}}, nil, Limits{}, "missing")
+	if !errors.Is(err, ErrUnknownExport) {
+		t.Fatalf("Call() of a missing export error = %v, want ErrUnknownExport", err)
+	}
+}
+
+func TestCallIndirect(t *testing.T) {
+	m := &testModule{
+		funcs: []testFunc{
+			{results: i32, body: i32Const(1)},
+			{results: i64, body: i64Const(2)},
+			// the type of the first function is type 0
+			{name: "dispatch", params: i32, results: i32, body: []byte{opLocalGet, 0, opCallIndirect, 0, 0x00}},
+		},
+		table: []uint32{0, 1},
+	}
+
+	got, err := call(t, m, nil, Limits{}, "dispatch", 0)
+	if err != nil || len(got) != 1 || got[0] != 1 {
+		t.Fatalf("dispatch(0) = %v, %v, want 1", got, err)
+	}
+	if _, err = call(t, m, nil, Limits{}, "dispatch", 1); !errors.Is(err, ErrTrap) {
+		t.Fatalf("dispatch(1) error = %v, want a trap for the type mismatch", err)
+	}
+	if _, err = call(t, m, nil, Limits{}, "dispatch", 5); !errors.Is(err, ErrTrap) {
+		t.Fatalf("dispatch(5) error = %v, want a trap for the undefined element", err)
+	}
+}
+
+func TestMemory(t *testing.T) {
+	loadAt := testFunc{name: "load", params: i32, results: i32, body: []byte{opLocalGet, 0, opI32Load, 2, 0}}
+	m := &testModule{
+		funcs: []testFunc{
+			loadAt,
+			{name: "store", params: i32i32, body: []byte{opLocalGet, 0, opLocalGet, 1, opI32Store, 2, 0}},
+			{name: "load8", params: i32, results: i32, body: []byte{opLocalGet, 0, opI32Load8U, 0, 0}},
+		},
+		memory: []uint32{1},
+		data:   []byte{0x01, 0x02, 0x03, 0x84},
+	}
+
+	module, err := Compile(m.bytes())
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	instance, err := Instantiate(context.Background(), module, nil, Limits{})
+	if err != nil {
+		t.Fatalf("Instantiate() error = %v", err)
+	}
+	ctx := context.Background()
+
+	if got, err := instance.Call(ctx, "load", 0); err != nil || got[0] != 0x84030201 {
+		t.Fatalf("load(0) = %v, %v, want the little endian data segment", got, err)
+	}
+	if got, err := instance.Call(ctx, "load8", 3); err != nil || got[0] != 0x84 {
+		t.Fatalf("load8(3) = %v, %v, want 0x84 zero extended", got, err)
+	}
+	if _, err := instance.Call(ctx, "store", PageSize-4, 42); err != nil {
+		t.Fatalf("store() at the end of the memory error = %v", err)
+	}
+	if got, err := instance.Call(ctx, "load", PageSize-4); err != nil || got[0] != 42 {
+		t.Fatalf("load() at the end of the memory = %v, %v, want 42", got, err)
+	}
+	if _, err := instance.Call(ctx, "load", PageSize-3); !errors.Is(err, ErrTrap) {
+		t.Fatalf("load() across the end of the memory error = %v, want a trap", err)
+	}
+	// the address is unsigned, negative addresses are far out of bounds
+	if _, err := instance.Call(ctx, "load", u32(-4)); !errors.Is(err, ErrTrap) {
+		t.Fatalf("load(-4) error = %v, want a trap", err)
+	}
+}
+
+func TestMemoryGrow(t *testing.T) {
+	grow := testFunc{name: "grow", params: i32, results: i32, body: []byte{opLocalGet, 0, opMemoryGrow, 0}}
+	size := testFunc{name: "size", results: i32, body: []byte{opMemorySize, 0}}
+
+	tests := []struct {
+		name   string
+		memory []uint32
+		limits Limits
+		grow   []uint64
+		want   []uint64
+		size   uint64
+	}{
+		{name: "within the maximum", memory: []uint32{1, 2}, grow: []uint64{1}, want: []uint64{1}, size: 2},
+		{name: "past the maximum", memory: []uint32{1, 2}, grow: []uint64{1, 1}, want: []uint64{1, 0xffffffff}, size: 2},
+		{name: "past the limit", memory: []uint32{1}, limits: Limits{MemoryPages: 2}, grow: []uint64{5, 1}, want: []uint64{0xffffffff, 1}, size: 2},
+		{name: "by zero", memory: []uint32{1}, grow: []uint64{0}, want: []uint64{1}, size: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			module, err := Compile((&testModule{funcs: []testFunc{grow, size}, memory: tt.memory}).bytes())
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			instance, err := Instantiate(context.Background(), module, nil, tt.limits)
+			if err != nil {
+				t.Fatalf("Instantiate() error = %v", err)
+			}
+
+			for i, delta := range tt.grow {
+				got, err := instance.Call(context.Background(), "grow", delta)
+				if err != nil || got[0] != tt.want[i] {
+					t.Fatalf("grow(%d) = %v, %v, want %#x", delta, got, err, tt.want[i])
+				}
+			}
+			if got, err := instance.Call(context.Background(), "size"); err != nil || got[0] != tt.size {
+				t.Fatalf("size() = %v, %v, want %d", got, err, tt.size)
+			}
+		})
+	}
+
+	module, err := Compile((&testModule{funcs: []testFunc{size}, memory: []uint32{3}}).bytes())
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if _, err = Instantiate(context.Background(), module, nil, Limits{MemoryPages: 2}); !errors.Is(err, ErrTrap) {
+		t.Fatalf("Instantiate() of a memory over the limit error = %v, want a trap", err)
+	}
+}
+
+func TestBulkMemory(t *testing.T) {
+	fillCopy := testFunc{name: "f", results: i32, body: cat(
+		i32Const(0), i32Const(0xaa), i32Const(4), []byte{opMisc, miscMemoryFill, 0x00},
+		i32Const(8), i32Const(0), i32Const(4), []byte{opMisc, miscMemoryCopy, 0x00, 0x00},
+		i32Const(8), []byte{opI32Load, 2, 0},
+	)}
+	got, err := callFunc(t, fillCopy)
+	if err != nil || got[0] != 0xaaaaaaaa {
+		t.Fatalf("fill and copy = %v, %v, want 0xaaaaaaaa", got, err)
+	}
+
+	outOfBounds := testFunc{body: cat(i32Const(PageSize-2), i32Const(0), i32Const(4), []byte{opMisc, miscMemoryFill, 0x00})}
+	if _, err = callFunc(t, outOfBounds); !errors.Is(err, ErrTrap) {
+		t.Fatalf("memory.fill out of bounds error = %v, want a trap", err)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	loop := testFunc{name: "f", body: []byte{opLoop, 0x40, opBr, 0, opEnd}}
+	_, err := call(t, &testModule{funcs: []testFunc{loop}}, nil, Limits{Fuel: 1000}, "f")
+	if !errors.Is(err, ErrFuelExhausted) {
+		t.Fatalf("endless loop error = %v, want ErrFuelExhausted", err)
+	}
+
+	recursion := testFunc{name: "f", body: []byte{opCall, 0}}
+	_, err = call(t, &testModule{funcs: []testFunc{recursion}}, nil, Limits{CallDepth: 100}, "f")
+	if !errors.Is(err, ErrStackExhausted) {
+		t.Fatalf("endless recursion error = %v, want ErrStackExhausted", err)
+	}
+
+	module, err := Compile((&testModule{funcs: []testFunc{loop}}).bytes())
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	instance, err := Instantiate(context.Background(), module, nil, Limits{})
+	if err != nil {
+		t.Fatalf("Instantiate() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err = instance.Call(ctx, "f"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Call() with a canceled context error = %v, want context.Canceled", err)
+	}
+}
+
+func TestHostFunctions(t *testing.T) {
+	m := &testModule{
+		imports: []testImport{{name: "add", params: i32i32, results: i32}},
+		funcs: []testFunc{
+			{name: "f", params: i32, results: i32, body: cat([]byte{opLocalGet, 0}, i32Const(2), []byte{opCall, 0})},
+		},
+	}
+	add := &HostFunction{
+		Type: FuncType{Params: i32i32, Results: i32},
+		Fn: func(call *Call, args []uint64) ([]uint64, error) {
+			if err := call.Charge(10); err != nil {
+				return nil, err
+			}
+			return []uint64{u32(int32(args[0]) + int32(args[1]))}, nil
+		},
+	}
+
+	got, err := call(t, m, Imports{"env": {"add": add}}, Limits{}, "f", 40)
+	if err != nil || got[0] != 42 {
+		t.Fatalf("f(40) = %v, %v, want 42", got, err)
+	}
+
+	failing := &HostFunction{
+		Type: add.Type,
+		Fn: func(*Call, []uint64) ([]uint64, error) {
+			return nil, errors.New("host failure")
+		},
+	}
+	if _, err = call(t, m, Imports{"env": {"add": failing}}, Limits{}, "f", 40); !errors.Is(err, ErrTrap) {
+		t.Fatalf("f() with a failing host function error = %v, want a trap", err)
+	}
+
+	module, err := Compile(m.bytes())
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if _, err = Instantiate(context.Background(), module, nil, Limits{}); !errors.Is(err, ErrMissingImport) {
+		t.Fatalf("Instantiate() without the import error = %v, want ErrMissingImport", err)
+	}
+	mismatched := &HostFunction{Type: FuncType{Params: i64i64, Results: i32}, Fn: add.Fn}
+	if _, err = Instantiate(context.Background(), module, Imports{"env": {"add": mismatched}}, Limits{}); !errors.Is(err, ErrMissingImport) {
+		t.Fatalf("Instantiate() with a mismatched import error = %v, want ErrMissingImport", err)
+	}
+}
+
+func TestStart(t *testing.T) {
+	start := uint32(0)
+	m := &testModule{
+		funcs: []testFunc{
+			{body: cat(i32Const(0), i32Const(42), []byte{opI32Store, 2, 0})},
+			{name: "get", results: i32, body: cat(i32Const(0), []byte{opI32Load, 2, 0})},
+		},
+		memory: []uint32{1},
+		start:  &start,
+	}
+
+	got, err := call(t, m, nil, Limits{}, "get")
+	if err != nil || got[0] != 42 {
+		t.Fatalf("get() = %v, %v, want the 42 stored by the start function", got, err)
+	}
+}
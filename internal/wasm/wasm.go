@@ -0,0 +1,113 @@
+// Package wasm runs untrusted WebAssembly modules. It is an interpreter of the WebAssembly 1.0
+// instruction set with the sign extension, saturating conversion and bulk memory copy and fill
+// instructions that compilers emit by default. Every instruction is metered, so a module stops
+// when it used up its fuel, and memory can't grow past a limit. Modules only reach the outside
+// through the host functions they are instantiated with.
+package wasm
+
+import (
+	"errors"
+	"fmt"
+)
+
+// PageSize is the size of a page of linear memory.
+const PageSize = 65536
+
+var (
+	// ErrInvalidModule is wrapped by the errors of Compile.
+	ErrInvalidModule = errors.New("invalid wasm module")
+	// ErrTrap is wrapped by the errors of an execution that failed, e.g. on a division by zero
+	// or a memory access out of bounds.
+	ErrTrap = errors.New("wasm trap")
+	// ErrFuelExhausted is a trap of an execution that ran more instructions than its fuel.
+	ErrFuelExhausted = fmt.Errorf("%w: fuel exhausted", ErrTrap)
+	// ErrStackExhausted is a trap of an execution that nested calls deeper than its limit or
+	// held too many values.
+	ErrStackExhausted = fmt.Errorf("%w: stack exhausted", ErrTrap)
+	// ErrMissingImport is returned by Instantiate for an import without a host function.
+	ErrMissingImport = errors.New("missing import")
+	// ErrUnknownExport is returned by Instance.Call for a name the module doesn't export as a
+	// function.
+	ErrUnknownExport = errors.New("unknown export")
+)
+
+// ValueType is the type of a value, values are passed as uint64: i32 zero extended and floats
+// as their bits.
+type ValueType byte
+
+const (
+	I32 ValueType = 0x7f
+	I64 ValueType = 0x7e
+	F32 ValueType = 0x7d
+	F64 ValueType = 0x7c
+)
+
+func (t ValueType) String() string {
+	switch t {
+	case I32:
+		return "i32"
+	case I64:
+		return "i64"
+	case F32:
+		return "f32"
+	case F64:
+		return "f64"
+	}
+
+	return fmt.Sprintf("type 0x%x", byte(t))
+}
+
+// FuncType is the signature of a function.
+type FuncType struct {
+	Params  []ValueType
+	Results []ValueType
+}
+
+func (t *FuncType) equal(other *FuncType) bool {
+	return string(valueTypeBytes(t.Params)) == string(valueTypeBytes(other.Params)) &&
+		string(valueTypeBytes(t.Results)) == string(valueTypeBytes(other.Results))
+}
+
+func valueTypeBytes(types []ValueType) []byte {
+	b := make([]byte, len(types))
+	for i, t := range types {
+		b[i] = byte(t)
+	}
+
+	return b
+}
+
+// Limits sandbox an instance. Fuel is the number of instructions all calls into the instance may
+// run together, MemoryPages caps the linear memory, also when the module declares more. CallDepth
+// and StackSize bound the nesting of calls and the values held at once.
+type Limits struct {
+	Fuel        uint64
+	MemoryPages uint32
+	CallDepth   int
+	StackSize   int
+}
+
+// HostFunction is a function of the host a module imports. Fn gets the arguments as declared by
+// Type and returns the results, an error traps the execution.
+type HostFunction struct {
+	Type FuncType
+	Fn   func(call *Call, args []uint64) ([]uint64, error)
+}
+
+// Imports are the host functions modules can import, by module and name.
+type Imports map[string]map[string]*HostFunction
+
+// Import is a function a module imports.
+type Import struct {
+	Module string
+	Name   string
+	Type   FuncType
+}
+
+func invalid(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidModule, fmt.Sprintf(format, args...))
+}
+
+func trap(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrTrap, fmt.Sprintf(format, args...))
+}
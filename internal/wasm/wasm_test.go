@@ -0,0 +1,176 @@
+package wasm
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// testFunc is a function of a test module. body is its code without the final end, name exports
+// it when set.
+type testFunc struct {
+	name    string
+	params  []ValueType
+	results []ValueType
+	locals  []ValueType
+	body    []byte
+}
+
+// testImport is a function a test module imports from the env module.
+type testImport struct {
+	name    string
+	params  []ValueType
+	results []ValueType
+}
+
+// testModule assembles small modules in the binary format, so the tests need no toolchain.
+type testModule struct {
+	imports []testImport
+	funcs   []testFunc
+	// memory is the minimum and optionally the maximum pages of the memory.
+	memory []uint32
+	// table holds the function indexes of a table of that size.
+	table []uint32
+	// data is copied to the start of the memory.
+	data  []byte
+	start *uint32
+}
+
+func (m *testModule) bytes() []byte {
+	out := []byte("\x00asm\x01\x00\x00\x00")
+
+	var types [][]byte
+	for _, imp := range m.imports {
+		types = append(types, funcType(imp.params, imp.results))
+	}
+	for _, fn := range m.funcs {
+		types = append(types, funcType(fn.params, fn.results))
+	}
+	out = append(out, section(1, vec(types...))...)
+
+	if len(m.imports) > 0 {
+		var imports [][]byte
+		for i, imp := range m.imports {
+			entry := cat(name("env"), name(imp.name), []byte{0x00}, uleb(uint64(i)))
+			imports = append(imports, entry)
+		}
+		out = append(out, section(2, vec(imports...))...)
+	}
+
+	var funcs [][]byte
+	for i := range m.funcs {
+		funcs = append(funcs, uleb(uint64(len(m.imports)+i)))
+	}
+	out = append(out, section(3, vec(funcs...))...)
+
+	if m.table != nil {
+		out = append(out, section(4, vec(cat([]byte{0x70, 0x00}, uleb(uint64(len(m.table))))))...)
+	}
+	if m.memory != nil {
+		limits := cat([]byte{0x00}, uleb(uint64(m.memory[0])))
+		if len(m.memory) > 1 {
+			limits = cat([]byte{0x01}, uleb(uint64(m.memory[0])), uleb(uint64(m.memory[1])))
+		}
+		out = append(out, section(5, vec(limits))...)
+	}
+
+	var exports [][]byte
+	for i, fn := range m.funcs {
+		if fn.name != "" {
+			exports = append(exports, cat(name(fn.name), []byte{0x00}, uleb(uint64(len(m.imports)+i))))
+		}
+	}
+	out = append(out, section(7, vec(exports...))...)
+
+	if m.start != nil {
+		out = append(out, section(8, uleb(uint64(*m.start)))...)
+	}
+	if m.table != nil {
+		var indexes [][]byte
+		for _, index := range m.table {
+			indexes = append(indexes, uleb(uint64(index)))
+		}
+		out = append(out, section(9, vec(cat([]byte{0x00, opI32Const, 0x00, opEnd}, vec(indexes...))))...)
+	}
+
+	var bodies [][]byte
+	for _, fn := range m.funcs {
+		var locals [][]byte
+		for _, typ := range fn.locals {
+			locals = append(locals, []byte{0x01, byte(typ)})
+		}
+		body := cat(vec(locals...), fn.body, []byte{opEnd})
+		bodies = append(bodies, cat(uleb(uint64(len(body))), body))
+	}
+	out = append(out, section(10, vec(bodies...))...)
+
+	if m.data != nil {
+		out = append(out, section(11, vec(cat([]byte{0x00, opI32Const, 0x00, opEnd}, uleb(uint64(len(m.data))), m.data)))...)
+	}
+
+	return out
+}
+
+func funcType(params, results []ValueType) []byte {
+	return cat([]byte{0x60}, uleb(uint64(len(params))), valueTypeBytes(params), uleb(uint64(len(results))), valueTypeBytes(results))
+}
+
+func section(id byte, content []byte) []byte {
+	return cat([]byte{id}, uleb(uint64(len(content))), content)
+}
+
+func vec(items ...[]byte) []byte {
+	return cat(append([][]byte{uleb(uint64(len(items)))}, items...)...)
+}
+
+func name(s string) []byte {
+	return cat(uleb(uint64(len(s))), []byte(s))
+}
+
+func cat(parts ...[]byte) []byte {
+	var out []byte
+	for _, part := range parts {
+		out = append(out, part...)
+	}
+
+	return out
+}
+
+func uleb(v uint64) []byte {
+	var out []byte
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if v == 0 {
+			return append(out, b)
+		}
+		out = append(out, b|0x80)
+	}
+}
+
+func sleb(v int64) []byte {
+	var out []byte
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if (v == 0 && b&0x40 == 0) || (v == -1 && b&0x40 != 0) {
+			return append(out, b)
+		}
+		out = append(out, b|0x80)
+	}
+}
+
+func i32Const(v int32) []byte {
+	return cat([]byte{opI32Const}, sleb(int64(v)))
+}
+
+func i64Const(v int64) []byte {
+	return cat([]byte{opI64Const}, sleb(v))
+}
+
+func f32Const(v float32) []byte {
+	return binary.LittleEndian.AppendUint32([]byte{opF32Const}, math.Float32bits(v))
+}
+
+func f64Const(v float64) []byte {
+	return binary.LittleEndian.AppendUint64([]byte{opF64Const}, math.Float64bits(v))
+}
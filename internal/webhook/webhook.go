@@ -0,0 +1,149 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	// SignatureHeader carries the timestamp and the HMAC-SHA256 of "<timestamp>.<body>" as
+	// "t=<unix timestamp>,v1=<hex signature>". Receivers should recompute the signature and
+	// reject old timestamps to prevent replays.
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+
+	// maxResponseBody is how much of a response is read, receivers only need to answer 2xx.
+	maxResponseBody = 4 << 10
+)
+
+var ErrPrivateAddress = errors.New("webhook address is not public")
+
+type Message struct {
+	URL        string
+	Secret     string
+	Event      string
+	DeliveryID string
+	Payload    []byte
+	Timestamp  time.Time
+}
+
+// StatusError is returned when the receiver answers with a non 2xx status.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("webhook receiver answered with status %d", e.StatusCode)
+}
+
+// Sender posts webhook messages, implementations must be safe for concurrent use.
+type Sender interface {
+	// Send returns the status code of the response, or zero when no response was received.
+	Send(ctx context.Context, msg *Message) (int, error)
+}
+
+type httpSender struct {
+	client *http.Client
+}
+
+// NewHTTPSender posts messages with the given timeout. Unless allowPrivate is set, connections
+// to loopback, private and link-local addresses are refused so webhooks can't reach internal
+// services, the check runs on the resolved address so DNS tricks don't get around it.
+func NewHTTPSender(timeout time.Duration, allowPrivate bool) *httpSender {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		RefusePrivate(dialer)
+	}
+
+	return &httpSender{
+		client: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: timeout,
+				MaxIdleConnsPerHost: 2,
+			},
+			// redirects are not followed, a webhook URL has to point at the receiver itself
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+var _ Sender = (*httpSender)(nil)
+
+func (s *httpSender) Send(ctx context.Context, msg *Message) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, msg.URL, bytes.NewReader(msg.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("error building webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "todo-webhooks/1")
+	req.Header.Set(EventHeader, msg.Event)
+	req.Header.Set(DeliveryHeader, msg.DeliveryID)
+	req.Header.Set(SignatureHeader, Sign(msg.Secret, msg.Timestamp, msg.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("error sending webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// drain a bit of the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBody))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	return resp.StatusCode, nil
+}
+
+// Sign returns the signature header value of a payload sent at timestamp.
+func Sign(secret string, timestamp time.Time, payload []byte) string {
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unix + "."))
+	mac.Write(payload)
+
+	return "t=" + unix + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// RefusePrivate makes the dialer refuse connections to loopback, private and link-local
+// addresses with ErrPrivateAddress, other clients fetching user provided URLs use it too.
+func RefusePrivate(dialer *net.Dialer) {
+	dialer.Control = func(_ string, address string, _ syscall.RawConn) error {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return err
+		}
+		if ip := net.ParseIP(host); ip == nil || !isPublic(ip) {
+			return fmt.Errorf("%s: %w", host, ErrPrivateAddress)
+		}
+		return nil
+	}
+}
+
+func isPublic(ip net.IP) bool {
+	return !ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsUnspecified() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast()
+}
@@ -0,0 +1,349 @@
+// Package webpush sends Web Push messages (RFC 8030) to browser push services. Payloads are
+// encrypted for the subscription with aes128gcm (RFC 8291) and the server identifies itself
+// with VAPID (RFC 8292), so no push service account is needed.
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/meowmix1337/the_recipe_book/internal/webhook"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	// recordSize is the aes128gcm record size, payloads are sent as a single record.
+	recordSize = 4096
+	// MaxPayload is the largest payload that fits in a single record with its padding delimiter,
+	// the tag and the header, push services accept 4096 bytes.
+	MaxPayload = recordSize - 16 - 1 - 86
+	// vapidExpiry is how long the VAPID token of a request is valid, at most 24 hours.
+	vapidExpiry = 12 * time.Hour
+	// maxResponseBody is how much of a response is read, push services answer 201 without a body.
+	maxResponseBody = 4 << 10
+)
+
+var (
+	// ErrGone means the push service dropped the subscription, it should be deleted.
+	ErrGone = errors.New("push subscription expired or was unsubscribed")
+	// ErrInvalidSubscription means the keys of a subscription can't be used for encryption.
+	ErrInvalidSubscription = errors.New("invalid push subscription")
+	ErrPayloadTooLarge     = errors.New("push payload too large")
+	ErrInvalidVAPIDKey     = errors.New("invalid VAPID private key")
+)
+
+// Urgency tells the push service how soon a message has to reach a device on battery.
+type Urgency string
+
+const (
+	UrgencyLow    Urgency = "low"
+	UrgencyNormal Urgency = "normal"
+	UrgencyHigh   Urgency = "high"
+)
+
+// Subscription is the PushSubscription of a browser, the keys are base64url encoded as the
+// browser returns them.
+type Subscription struct {
+	Endpoint string
+	P256dh   string
+	Auth     string
+}
+
+// Validate checks that the endpoint is an https URL and that the keys can be used for encryption.
+func (s *Subscription) Validate() error {
+	if _, err := s.endpoint(); err != nil {
+		return err
+	}
+	_, _, err := s.keys()
+
+	return err
+}
+
+func (s *Subscription) endpoint() (*url.URL, error) {
+	endpoint, err := url.Parse(s.Endpoint)
+	if err != nil || endpoint.Scheme != "https" || endpoint.Host == "" {
+		return nil, fmt.Errorf("%w: endpoint must be an https url", ErrInvalidSubscription)
+	}
+
+	return endpoint, nil
+}
+
+func (s *Subscription) keys() (*ecdh.PublicKey, []byte, error) {
+	uaPublicBytes, err := decodeBase64(s.P256dh)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: p256dh: %w", ErrInvalidSubscription, err)
+	}
+	uaPublic, err := ecdh.P256().NewPublicKey(uaPublicBytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: p256dh: %w", ErrInvalidSubscription, err)
+	}
+	authSecret, err := decodeBase64(s.Auth)
+	if err != nil || len(authSecret) != 16 {
+		return nil, nil, fmt.Errorf("%w: auth secret must be 16 bytes", ErrInvalidSubscription)
+	}
+
+	return uaPublic, authSecret, nil
+}
+
+type Message struct {
+	Subscription *Subscription
+	Payload      []byte
+	// TTL is how long the push service keeps the message for an offline device.
+	TTL     time.Duration
+	Urgency Urgency
+	// Topic replaces an undelivered message with the same topic.
+	Topic string
+}
+
+// StatusError is returned when the push service answers with a non 2xx status.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("push service answered with status %d", e.StatusCode)
+}
+
+// Sender delivers push messages, implementations must be safe for concurrent use.
+type Sender interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Keys are the VAPID keys of the server, browsers need the public key to subscribe.
+type Keys struct {
+	private *ecdsa.PrivateKey
+	// Public is the uncompressed public key, base64url encoded without padding.
+	Public string
+}
+
+// ParseKeys reads the base64url encoded private key of a VAPID key pair, the format of
+// GenerateKeys and of the common web-push tools.
+func ParseKeys(privateKey string) (*Keys, error) {
+	d, err := decodeBase64(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidVAPIDKey, err)
+	}
+
+	key, err := ecdh.P256().NewPrivateKey(d)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidVAPIDKey, err)
+	}
+	public := key.PublicKey().Bytes()
+
+	return &Keys{
+		private: &ecdsa.PrivateKey{
+			PublicKey: ecdsa.PublicKey{
+				Curve: elliptic.P256(),
+				X:     new(big.Int).SetBytes(public[1:33]),
+				Y:     new(big.Int).SetBytes(public[33:]),
+			},
+			D: new(big.Int).SetBytes(d),
+		},
+		Public: base64.RawURLEncoding.EncodeToString(public),
+	}, nil
+}
+
+// GenerateKeys returns a new VAPID key pair, base64url encoded.
+func GenerateKeys() (privateKey string, publicKey string, err error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(key.Bytes()), base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()), nil
+}
+
+type httpSender struct {
+	client  *http.Client
+	keys    *Keys
+	subject string
+}
+
+// NewHTTPSender sends messages signed with the VAPID keys, subject is a mailto: or https: URL
+// push services can use to contact the operator. Push endpoints come from browsers, so like
+// webhooks connections to private addresses are refused.
+func NewHTTPSender(timeout time.Duration, keys *Keys, subject string) *httpSender {
+	dialer := &net.Dialer{Timeout: timeout}
+	webhook.RefusePrivate(dialer)
+
+	return &httpSender{
+		client: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: timeout,
+				MaxIdleConnsPerHost: 4,
+			},
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		keys:    keys,
+		subject: subject,
+	}
+}
+
+var _ Sender = (*httpSender)(nil)
+
+func (s *httpSender) Send(ctx context.Context, msg *Message) error {
+	endpoint, err := msg.Subscription.endpoint()
+	if err != nil {
+		return err
+	}
+
+	body, err := Encrypt(msg.Subscription, msg.Payload)
+	if err != nil {
+		return err
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": endpoint.Scheme + "://" + endpoint.Host,
+		"exp": time.Now().Add(vapidExpiry).Unix(),
+		"sub": s.subject,
+	}).SignedString(s.keys.private)
+	if err != nil {
+		return fmt.Errorf("error signing VAPID token: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error building push request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", strconv.Itoa(int(msg.TTL.Seconds())))
+	req.Header.Set("Authorization", "vapid t="+token+", k="+s.keys.Public)
+	if msg.Urgency != "" {
+		req.Header.Set("Urgency", string(msg.Urgency))
+	}
+	if msg.Topic != "" {
+		req.Header.Set("Topic", msg.Topic)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending push message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBody))
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrGone
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	return nil
+}
+
+// Encrypt encrypts the payload for the subscription as a single aes128gcm record, RFC 8291.
+func Encrypt(subscription *Subscription, payload []byte) ([]byte, error) {
+	if len(payload) > MaxPayload {
+		return nil, fmt.Errorf("%d bytes: %w", len(payload), ErrPayloadTooLarge)
+	}
+
+	uaPublic, authSecret, err := subscription.keys()
+	if err != nil {
+		return nil, err
+	}
+
+	// every message uses a new key pair and salt
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err = rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	return encrypt(uaPublic, authSecret, asPrivate, salt, payload)
+}
+
+func encrypt(uaPublic *ecdh.PublicKey, authSecret []byte, asPrivate *ecdh.PrivateKey, salt []byte, payload []byte) ([]byte, error) {
+	asPublic := asPrivate.PublicKey().Bytes()
+	ecdhSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSubscription, err)
+	}
+
+	keyInfo := append([]byte("WebPush: info\x00"), uaPublic.Bytes()...)
+	keyInfo = append(keyInfo, asPublic...)
+	ikm, err := expand(hkdf.Extract(sha256.New, ecdhSecret, authSecret), keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	prk := hkdf.Extract(sha256.New, ikm, salt)
+	cek, err := expand(prk, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := expand(prk, []byte("Content-Encoding: nonce\x00"), 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// the header carries the salt, the record size and the public key of the server
+	header := make([]byte, 0, 16+4+1+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+
+	// 0x02 marks the last and only record
+	plaintext := append(append([]byte{}, payload...), 0x02)
+
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+func expand(prk []byte, info []byte, length int) ([]byte, error) {
+	out := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.Expand(sha256.New, prk, info), out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// decodeBase64 accepts the base64url keys of browsers with or without padding, and standard
+// base64 as some clients send it.
+func decodeBase64(value string) ([]byte, error) {
+	value = strings.TrimRight(strings.TrimSpace(value), "=")
+	if strings.ContainsAny(value, "+/") {
+		return base64.RawStdEncoding.DecodeString(value)
+	}
+
+	return base64.RawURLEncoding.DecodeString(value)
+}
@@ -0,0 +1,46 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Group starts periodic workers and waits for them on shutdown.
+type Group struct {
+	// Interval overrides the interval of a worker by its name, it returns fallback for the
+	// workers it doesn't change.
+	Interval func(name string, fallback time.Duration) time.Duration
+
+	wg sync.WaitGroup
+}
+
+// Periodic starts a worker that runs until ctx is cancelled, see Periodic.
+func (g *Group) Periodic(ctx context.Context, name string, interval time.Duration, fn func(ctx context.Context) error) {
+	if g.Interval != nil {
+		interval = g.Interval(name, interval)
+	}
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		Periodic(ctx, name, interval, fn)
+	}()
+}
+
+// Wait waits until the runs in progress finished after the workers were cancelled, it returns
+// the error of ctx when they don't finish in time.
+func (g *Group) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// livenessRuns is how many runs a worker may miss before it counts as stuck.
+const livenessRuns = 2
+
+// ErrWorkerStuck means a worker has not finished a run for livenessRuns intervals.
+var ErrWorkerStuck = errors.New("workers are stuck")
+
+type heartbeat struct {
+	interval time.Duration
+	beat     time.Time
+}
+
+// heartbeats records the last run of every running worker.
+//
+//nolint:gochecknoglobals // workers are started by name, like their metrics
+var heartbeats = struct {
+	mu      sync.Mutex
+	workers map[string]*heartbeat
+}{workers: map[string]*heartbeat{}}
+
+func beat(name string, interval time.Duration) {
+	heartbeats.mu.Lock()
+	defer heartbeats.mu.Unlock()
+
+	heartbeats.workers[name] = &heartbeat{interval: interval, beat: time.Now()}
+}
+
+func stopped(name string) {
+	heartbeats.mu.Lock()
+	defer heartbeats.mu.Unlock()
+
+	delete(heartbeats.workers, name)
+}
+
+// Alive returns ErrWorkerStuck naming the workers that have not finished a run in time, a
+// worker blocked in a run is as dead as a crashed one.
+func Alive(_ context.Context) error {
+	heartbeats.mu.Lock()
+	defer heartbeats.mu.Unlock()
+
+	var stuck []string
+	for name, heartbeat := range heartbeats.workers {
+		if time.Since(heartbeat.beat) > livenessRuns*heartbeat.interval {
+			stuck = append(stuck, name)
+		}
+	}
+	if len(stuck) == 0 {
+		return nil
+	}
+	slices.Sort(stuck)
+
+	return fmt.Errorf("%w: %s", ErrWorkerStuck, strings.Join(stuck, ", "))
+}
@@ -0,0 +1,46 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/meowmix1337/the_recipe_book/internal/metrics"
+	"github.com/meowmix1337/the_recipe_book/internal/tracing"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Periodic runs fn every interval until ctx is cancelled. Errors are logged and do not stop the worker.
+// A run in progress is not cancelled with ctx, so a shutdown doesn't cut a delivery in half.
+func Periodic(ctx context.Context, name string, interval time.Duration, fn func(ctx context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Info().Str("worker", name).Dur("interval", interval).Msg("worker started")
+	beat(name, interval)
+	defer stopped(name)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info().Str("worker", name).Msg("worker stopped")
+			return
+		case <-ticker.C:
+			run(context.WithoutCancel(ctx), name, fn)
+			beat(name, interval)
+		}
+	}
+}
+
+// run runs fn once as the root span of its own trace.
+func run(ctx context.Context, name string, fn func(ctx context.Context) error) {
+	ctx, span := tracing.Start(ctx, "worker."+name, trace.WithNewRoot())
+	defer span.End()
+
+	start := time.Now()
+	if err := fn(ctx); err != nil {
+		span.RecordError(err)
+		log.Err(err).Str("worker", name).Msg("worker run failed")
+	}
+	metrics.WorkerRunDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+}
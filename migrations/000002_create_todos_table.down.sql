@@ -0,0 +1,13 @@
+-- Drop triggers
+DROP TRIGGER update_updated_at_trigger_tags ON tags;
+DROP TRIGGER update_updated_at_trigger_todos ON todos;
+
+-- Drop indexes
+DROP INDEX idx_todo_tags_tag_id;
+DROP INDEX idx_tags_user_id;
+DROP INDEX idx_todos_user_id;
+
+-- Drop tables
+DROP TABLE todo_tags;
+DROP TABLE tags;
+DROP TABLE todos;
@@ -0,0 +1,49 @@
+-- Create the todos table
+CREATE TABLE todos (
+  id SERIAL PRIMARY KEY,
+  uuid VARCHAR(255) NOT NULL UNIQUE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  title VARCHAR(255) NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
+  due_date TIMESTAMP WITH TIME ZONE,
+  completed_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create the tags table, tag names are unique per user
+CREATE TABLE tags (
+  id SERIAL PRIMARY KEY,
+  uuid VARCHAR(255) NOT NULL UNIQUE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name VARCHAR(64) NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT unique_tag_name_per_user UNIQUE (user_id, name)
+);
+
+-- Create the todo_tags join table
+CREATE TABLE todo_tags (
+  todo_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+  tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (todo_id, tag_id)
+);
+
+-- Create indexes
+CREATE INDEX idx_todos_user_id ON todos (user_id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_tags_user_id ON tags (user_id);
+CREATE INDEX idx_todo_tags_tag_id ON todo_tags (tag_id);
+
+-- Create a trigger to update the updated_at column on update for todos
+CREATE TRIGGER update_updated_at_trigger_todos
+BEFORE UPDATE ON todos
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
+
+-- Create a trigger to update the updated_at column on update for tags
+CREATE TRIGGER update_updated_at_trigger_tags
+BEFORE UPDATE ON tags
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
@@ -0,0 +1,5 @@
+-- Drop indexes
+DROP INDEX idx_todos_user_id_due_date;
+DROP INDEX idx_todos_user_id_priority;
+
+ALTER TABLE todos DROP COLUMN priority;
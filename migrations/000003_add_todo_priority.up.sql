@@ -0,0 +1,5 @@
+-- Priority is stored as an ordinal so it can be sorted on: 0 low, 1 medium, 2 high, 3 urgent
+ALTER TABLE todos ADD COLUMN priority SMALLINT NOT NULL DEFAULT 1 CHECK (priority BETWEEN 0 AND 3);
+
+CREATE INDEX idx_todos_user_id_priority ON todos (user_id, priority) WHERE deleted_at IS NULL;
+CREATE INDEX idx_todos_user_id_due_date ON todos (user_id, due_date) WHERE deleted_at IS NULL;
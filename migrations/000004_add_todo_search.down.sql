@@ -0,0 +1,4 @@
+-- Drop indexes
+DROP INDEX idx_todos_search_vector;
+
+ALTER TABLE todos DROP COLUMN search_vector;
@@ -0,0 +1,13 @@
+-- Drop triggers
+DROP TRIGGER update_updated_at_trigger_list_snapshot_schedules ON list_snapshot_schedules;
+DROP TRIGGER update_updated_at_trigger_lists ON lists;
+
+-- Drop indexes
+DROP INDEX idx_list_snapshot_schedules_next_run_at;
+DROP INDEX idx_todos_list_id;
+DROP INDEX idx_lists_user_id;
+
+-- Drop tables
+DROP TABLE list_snapshot_schedules;
+ALTER TABLE todos DROP COLUMN list_id;
+DROP TABLE lists;
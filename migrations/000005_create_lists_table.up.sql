@@ -0,0 +1,44 @@
+-- Create the lists table
+CREATE TABLE lists (
+  id SERIAL PRIMARY KEY,
+  uuid VARCHAR(255) NOT NULL UNIQUE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name VARCHAR(255) NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+ALTER TABLE todos ADD COLUMN list_id INTEGER REFERENCES lists(id) ON DELETE SET NULL;
+
+-- Create the list_snapshot_schedules table, recipients are stored comma separated
+CREATE TABLE list_snapshot_schedules (
+  id SERIAL PRIMARY KEY,
+  uuid VARCHAR(255) NOT NULL UNIQUE,
+  list_id INTEGER NOT NULL REFERENCES lists(id) ON DELETE CASCADE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  recipients TEXT NOT NULL,
+  frequency VARCHAR(16) NOT NULL,
+  next_run_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  last_sent_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create indexes
+CREATE INDEX idx_lists_user_id ON lists (user_id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_todos_list_id ON todos (list_id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_list_snapshot_schedules_next_run_at ON list_snapshot_schedules (next_run_at) WHERE deleted_at IS NULL;
+
+-- Create a trigger to update the updated_at column on update for lists
+CREATE TRIGGER update_updated_at_trigger_lists
+BEFORE UPDATE ON lists
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
+
+-- Create a trigger to update the updated_at column on update for list_snapshot_schedules
+CREATE TRIGGER update_updated_at_trigger_list_snapshot_schedules
+BEFORE UPDATE ON list_snapshot_schedules
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
@@ -0,0 +1,13 @@
+-- Drop indexes
+DROP INDEX idx_users_parent_id;
+
+-- Drop child accounts, they can not log in without an email
+DELETE FROM users WHERE email IS NULL;
+
+-- Drop columns
+ALTER TABLE users DROP CONSTRAINT users_login_check;
+ALTER TABLE users DROP COLUMN allow_deletion;
+ALTER TABLE users DROP COLUMN allow_sharing;
+ALTER TABLE users DROP COLUMN username;
+ALTER TABLE users DROP COLUMN parent_id;
+ALTER TABLE users ALTER COLUMN email SET NOT NULL;
@@ -0,0 +1,14 @@
+-- Child accounts are created and managed by a parent account and log in with a username
+ALTER TABLE users ALTER COLUMN email DROP NOT NULL;
+ALTER TABLE users ADD COLUMN parent_id INTEGER REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE users ADD COLUMN username VARCHAR(255) UNIQUE;
+
+-- Parental controls, only enforced for child accounts
+ALTER TABLE users ADD COLUMN allow_sharing BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN allow_deletion BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Every account needs a way to log in
+ALTER TABLE users ADD CONSTRAINT users_login_check CHECK (email IS NOT NULL OR (parent_id IS NOT NULL AND username IS NOT NULL));
+
+-- Create indexes
+CREATE INDEX idx_users_parent_id ON users (parent_id) WHERE deleted_at IS NULL;
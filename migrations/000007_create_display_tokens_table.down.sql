@@ -0,0 +1,9 @@
+-- Drop triggers
+DROP TRIGGER update_updated_at_trigger_display_tokens ON display_tokens;
+
+-- Drop indexes
+DROP INDEX idx_display_tokens_user_id;
+
+-- Drop tables
+DROP TABLE display_token_lists;
+DROP TABLE display_tokens;
@@ -0,0 +1,30 @@
+-- Create the display_tokens table, only a hash of the token is stored
+CREATE TABLE display_tokens (
+  id SERIAL PRIMARY KEY,
+  uuid VARCHAR(255) NOT NULL UNIQUE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name VARCHAR(255) NOT NULL,
+  token_hash VARCHAR(64) NOT NULL UNIQUE,
+  expires_at TIMESTAMP WITH TIME ZONE,
+  last_used_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create the display_token_lists table, the lists a display token can read
+CREATE TABLE display_token_lists (
+  display_token_id INTEGER NOT NULL REFERENCES display_tokens(id) ON DELETE CASCADE,
+  list_id INTEGER NOT NULL REFERENCES lists(id) ON DELETE CASCADE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (display_token_id, list_id)
+);
+
+-- Create indexes
+CREATE INDEX idx_display_tokens_user_id ON display_tokens (user_id) WHERE deleted_at IS NULL;
+
+-- Create a trigger to update the updated_at column on update for display_tokens
+CREATE TRIGGER update_updated_at_trigger_display_tokens
+BEFORE UPDATE ON display_tokens
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
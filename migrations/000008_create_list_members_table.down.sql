@@ -0,0 +1,11 @@
+-- Drop triggers
+DROP TRIGGER update_updated_at_trigger_list_invitations ON list_invitations;
+DROP TRIGGER update_updated_at_trigger_list_members ON list_members;
+
+-- Drop indexes
+DROP INDEX idx_list_invitations_list_id;
+DROP INDEX idx_list_members_user_id;
+
+-- Drop tables
+DROP TABLE list_invitations;
+DROP TABLE list_members;
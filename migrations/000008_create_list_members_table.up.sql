@@ -0,0 +1,42 @@
+-- Create the list_members table, the collaborators of a list besides its owner
+CREATE TABLE list_members (
+  id SERIAL PRIMARY KEY,
+  list_id INTEGER NOT NULL REFERENCES lists(id) ON DELETE CASCADE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  role VARCHAR(16) NOT NULL CHECK (role IN ('viewer', 'editor')),
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (list_id, user_id)
+);
+
+-- Create the list_invitations table, only a hash of the invitation token is stored
+CREATE TABLE list_invitations (
+  id SERIAL PRIMARY KEY,
+  uuid VARCHAR(255) NOT NULL UNIQUE,
+  list_id INTEGER NOT NULL REFERENCES lists(id) ON DELETE CASCADE,
+  inviter_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  email VARCHAR(255) NOT NULL,
+  role VARCHAR(16) NOT NULL CHECK (role IN ('viewer', 'editor')),
+  token_hash VARCHAR(64) NOT NULL UNIQUE,
+  expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  accepted_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create indexes
+CREATE INDEX idx_list_members_user_id ON list_members (user_id);
+CREATE INDEX idx_list_invitations_list_id ON list_invitations (list_id) WHERE deleted_at IS NULL;
+
+-- Create a trigger to update the updated_at column on update for list_members
+CREATE TRIGGER update_updated_at_trigger_list_members
+BEFORE UPDATE ON list_members
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
+
+-- Create a trigger to update the updated_at column on update for list_invitations
+CREATE TRIGGER update_updated_at_trigger_list_invitations
+BEFORE UPDATE ON list_invitations
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
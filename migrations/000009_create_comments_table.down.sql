@@ -0,0 +1,8 @@
+-- Drop triggers
+DROP TRIGGER update_updated_at_trigger_comments ON comments;
+
+-- Drop indexes
+DROP INDEX idx_comments_todo_id_created_at;
+
+-- Drop tables
+DROP TABLE comments;
@@ -0,0 +1,9 @@
+-- Drop triggers
+DROP TRIGGER update_updated_at_trigger_oauth_grants ON oauth_grants;
+
+-- Drop indexes
+DROP INDEX idx_oauth_grants_user_id;
+
+-- Drop tables
+DROP TABLE oauth_grants;
+DROP TABLE oauth_authorization_codes;
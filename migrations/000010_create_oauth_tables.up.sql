@@ -0,0 +1,36 @@
+-- Create the oauth_authorization_codes table, codes are short-lived and can only be redeemed once
+CREATE TABLE oauth_authorization_codes (
+  id SERIAL PRIMARY KEY,
+  code_hash VARCHAR(64) NOT NULL UNIQUE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  client_id VARCHAR(255) NOT NULL,
+  redirect_uri TEXT NOT NULL,
+  expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  used_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create the oauth_grants table, a linked account of a third party client. Only hashes of the
+-- tokens are stored, refreshing rotates both tokens.
+CREATE TABLE oauth_grants (
+  id SERIAL PRIMARY KEY,
+  uuid VARCHAR(255) NOT NULL UNIQUE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  client_id VARCHAR(255) NOT NULL,
+  access_token_hash VARCHAR(64) NOT NULL UNIQUE,
+  refresh_token_hash VARCHAR(64) NOT NULL UNIQUE,
+  access_expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  last_used_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create indexes
+CREATE INDEX idx_oauth_grants_user_id ON oauth_grants (user_id) WHERE deleted_at IS NULL;
+
+-- Create a trigger to update the updated_at column on update for oauth_grants
+CREATE TRIGGER update_updated_at_trigger_oauth_grants
+BEFORE UPDATE ON oauth_grants
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
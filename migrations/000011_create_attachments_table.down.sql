@@ -0,0 +1,8 @@
+-- Drop triggers
+DROP TRIGGER update_updated_at_trigger_attachments ON attachments;
+
+-- Drop indexes
+DROP INDEX idx_attachments_todo_id;
+
+-- Drop tables
+DROP TABLE attachments;
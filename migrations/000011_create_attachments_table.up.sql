@@ -0,0 +1,23 @@
+-- Create the attachments table, the files themselves live in the configured storage
+CREATE TABLE attachments (
+  id SERIAL PRIMARY KEY,
+  uuid VARCHAR(255) NOT NULL UNIQUE,
+  todo_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  filename VARCHAR(255) NOT NULL,
+  content_type VARCHAR(255) NOT NULL,
+  size BIGINT NOT NULL,
+  storage_key TEXT NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create indexes
+CREATE INDEX idx_attachments_todo_id ON attachments (todo_id) WHERE deleted_at IS NULL;
+
+-- Create a trigger to update the updated_at column on update for attachments
+CREATE TRIGGER update_updated_at_trigger_attachments
+BEFORE UPDATE ON attachments
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
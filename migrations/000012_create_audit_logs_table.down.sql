@@ -0,0 +1,11 @@
+-- Drop triggers
+DROP TRIGGER prevent_changes_trigger_audit_logs ON audit_logs;
+
+-- Drop functions
+DROP FUNCTION prevent_changes();
+
+-- Drop indexes
+DROP INDEX idx_audit_logs_user_id_id;
+
+-- Drop tables
+DROP TABLE audit_logs;
@@ -0,0 +1,29 @@
+-- Create the audit_logs table, entries outlive their user so user_id has no foreign key
+CREATE TABLE audit_logs (
+  id BIGSERIAL PRIMARY KEY,
+  user_id INTEGER,
+  action VARCHAR(255) NOT NULL,
+  path TEXT NOT NULL,
+  status INTEGER NOT NULL,
+  ip VARCHAR(255) NOT NULL DEFAULT '',
+  user_agent TEXT NOT NULL DEFAULT '',
+  request_id VARCHAR(255) NOT NULL DEFAULT '',
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX idx_audit_logs_user_id_id ON audit_logs (user_id, id);
+
+-- Create a function rejecting changes to append-only tables
+CREATE OR REPLACE FUNCTION prevent_changes()
+RETURNS TRIGGER AS $$
+BEGIN
+  RAISE EXCEPTION '% is append-only', TG_TABLE_NAME;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Create a trigger to keep audit_logs append-only
+CREATE TRIGGER prevent_changes_trigger_audit_logs
+BEFORE UPDATE OR DELETE ON audit_logs
+FOR EACH ROW
+EXECUTE PROCEDURE prevent_changes();
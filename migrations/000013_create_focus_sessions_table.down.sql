@@ -0,0 +1,9 @@
+-- Drop triggers
+DROP TRIGGER update_updated_at_trigger_focus_sessions ON focus_sessions;
+
+-- Drop indexes
+DROP INDEX idx_focus_sessions_user_id_started_at;
+DROP INDEX idx_focus_sessions_active_user_id;
+
+-- Drop tables
+DROP TABLE focus_sessions;
@@ -0,0 +1,25 @@
+-- Create the focus_sessions table, pomodoro style focus sessions on a todo
+CREATE TABLE focus_sessions (
+  id SERIAL PRIMARY KEY,
+  uuid VARCHAR(255) NOT NULL UNIQUE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  todo_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+  status VARCHAR(16) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'completed', 'abandoned')),
+  planned_seconds INTEGER NOT NULL CHECK (planned_seconds > 0),
+  focused_seconds INTEGER NOT NULL DEFAULT 0,
+  interruptions INTEGER NOT NULL DEFAULT 0,
+  started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  ended_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes, a user can only focus on one todo at a time
+CREATE UNIQUE INDEX idx_focus_sessions_active_user_id ON focus_sessions (user_id) WHERE status = 'active';
+CREATE INDEX idx_focus_sessions_user_id_started_at ON focus_sessions (user_id, started_at);
+
+-- Create a trigger to update the updated_at column on update for focus_sessions
+CREATE TRIGGER update_updated_at_trigger_focus_sessions
+BEFORE UPDATE ON focus_sessions
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
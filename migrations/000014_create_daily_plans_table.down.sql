@@ -0,0 +1,8 @@
+-- Drop triggers
+DROP TRIGGER update_updated_at_trigger_daily_plans ON daily_plans;
+
+-- Drop tables
+DROP TABLE daily_plan_items;
+DROP TABLE daily_plans;
+
+ALTER TABLE todos DROP COLUMN estimate_minutes;
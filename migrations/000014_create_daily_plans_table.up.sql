@@ -0,0 +1,30 @@
+-- Estimates are optional, the planner assumes a default for todos without one
+ALTER TABLE todos ADD COLUMN estimate_minutes INTEGER CHECK (estimate_minutes > 0);
+
+-- Create the daily_plans table, the todos a user planned to do on a day
+CREATE TABLE daily_plans (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  plan_date DATE NOT NULL,
+  capacity_minutes INTEGER NOT NULL CHECK (capacity_minutes > 0),
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (user_id, plan_date)
+);
+
+-- Create the daily_plan_items table, reasons are stored one per line
+CREATE TABLE daily_plan_items (
+  plan_id INTEGER NOT NULL REFERENCES daily_plans(id) ON DELETE CASCADE,
+  todo_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+  position INTEGER NOT NULL,
+  score DOUBLE PRECISION NOT NULL,
+  estimate_minutes INTEGER NOT NULL,
+  reasons TEXT NOT NULL DEFAULT '',
+  PRIMARY KEY (plan_id, todo_id)
+);
+
+-- Create a trigger to update the updated_at column on update for daily_plans
+CREATE TRIGGER update_updated_at_trigger_daily_plans
+BEFORE UPDATE ON daily_plans
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
@@ -0,0 +1,12 @@
+-- Drop triggers
+DROP TRIGGER update_updated_at_trigger_webhook_deliveries ON webhook_deliveries;
+DROP TRIGGER update_updated_at_trigger_webhooks ON webhooks;
+
+-- Drop indexes
+DROP INDEX idx_webhook_deliveries_next_attempt_at;
+DROP INDEX idx_webhook_deliveries_webhook_id;
+DROP INDEX idx_webhooks_user_id;
+
+-- Drop tables
+DROP TABLE webhook_deliveries;
+DROP TABLE webhooks;
@@ -0,0 +1,2 @@
+-- Count how often the due date of a todo was moved later, used to spot stalled todos
+ALTER TABLE todos ADD COLUMN postponed_count INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,10 @@
+-- Drop triggers
+DROP TRIGGER update_updated_at_trigger_habit_entries ON habit_entries;
+DROP TRIGGER update_updated_at_trigger_habits ON habits;
+
+-- Drop indexes
+DROP INDEX idx_habits_user_id;
+
+-- Drop tables
+DROP TABLE habit_entries;
+DROP TABLE habits;
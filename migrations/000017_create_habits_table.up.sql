@@ -0,0 +1,35 @@
+-- Create the habits table, a habit is met by checking it off target_per_week times a week
+CREATE TABLE habits (
+  id SERIAL PRIMARY KEY,
+  uuid VARCHAR(255) NOT NULL UNIQUE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  title VARCHAR(255) NOT NULL,
+  target_per_week SMALLINT NOT NULL CHECK (target_per_week BETWEEN 1 AND 7),
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create the habit_entries table, one entry per habit and day, either done or skipped
+CREATE TABLE habit_entries (
+  habit_id INTEGER NOT NULL REFERENCES habits(id) ON DELETE CASCADE,
+  entry_date DATE NOT NULL,
+  status VARCHAR(16) NOT NULL CHECK (status IN ('done', 'skipped')),
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (habit_id, entry_date)
+);
+
+-- Create indexes
+CREATE INDEX idx_habits_user_id ON habits (user_id) WHERE deleted_at IS NULL;
+
+-- Create triggers to update the updated_at column on update
+CREATE TRIGGER update_updated_at_trigger_habits
+BEFORE UPDATE ON habits
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
+
+CREATE TRIGGER update_updated_at_trigger_habit_entries
+BEFORE UPDATE ON habit_entries
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
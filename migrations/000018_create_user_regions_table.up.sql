@@ -0,0 +1,20 @@
+-- Create the user_regions table, the directory of the data region of every login. It lives in
+-- the home region and only holds hashes, the accounts themselves live in their region.
+CREATE TABLE user_regions (
+  login_hash VARCHAR(64) PRIMARY KEY,
+  region VARCHAR(64) NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Existing users live in the home region, in regional databases there are none yet
+INSERT INTO user_regions (login_hash, region)
+SELECT encode(sha256(convert_to('email:' || lower(email), 'UTF8')), 'hex'), ''
+FROM users
+WHERE email IS NOT NULL
+ON CONFLICT (login_hash) DO NOTHING;
+
+INSERT INTO user_regions (login_hash, region)
+SELECT encode(sha256(convert_to('username:' || lower(username), 'UTF8')), 'hex'), ''
+FROM users
+WHERE username IS NOT NULL
+ON CONFLICT (login_hash) DO NOTHING;
@@ -0,0 +1,4 @@
+-- Chain the audit log, every entry stores the hash of the previous one. Entries written before
+-- the chain existed keep empty hashes, the table is append-only so they can't be backfilled.
+ALTER TABLE audit_logs ADD COLUMN prev_hash VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE audit_logs ADD COLUMN hash VARCHAR(64) NOT NULL DEFAULT '';
@@ -0,0 +1,11 @@
+-- Create the token_blacklist table used by the database token blacklist, rows are pruned once
+-- the token expired
+CREATE TABLE token_blacklist (
+  token_hash VARCHAR(64) PRIMARY KEY,
+  user_id INTEGER NOT NULL,
+  expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX idx_token_blacklist_expires_at ON token_blacklist (expires_at);
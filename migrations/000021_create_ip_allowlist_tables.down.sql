@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS ip_allowlist_bypasses;
+DROP TABLE IF EXISTS ip_ranges;
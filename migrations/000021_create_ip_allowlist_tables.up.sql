@@ -0,0 +1,27 @@
+-- Create the ip_ranges table, the CIDR ranges a household can be accessed from. The owner is
+-- the parent account, the ranges apply to the parent and all of its child accounts.
+CREATE TABLE ip_ranges (
+  id SERIAL PRIMARY KEY,
+  uuid VARCHAR(255) NOT NULL UNIQUE,
+  owner_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  cidr VARCHAR(64) NOT NULL,
+  label VARCHAR(255) NOT NULL DEFAULT '',
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create the ip_allowlist_bypasses table, emailed tokens that let an owner who locked themselves
+-- out suspend the allowlist
+CREATE TABLE ip_allowlist_bypasses (
+  id SERIAL PRIMARY KEY,
+  owner_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  token_hash VARCHAR(64) NOT NULL UNIQUE,
+  expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  used_at TIMESTAMP WITH TIME ZONE,
+  suspended_until TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX idx_ip_ranges_owner_id ON ip_ranges (owner_id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_ip_allowlist_bypasses_owner_id ON ip_allowlist_bypasses (owner_id, suspended_until);
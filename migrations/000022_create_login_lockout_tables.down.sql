@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS account_unlocks;
+DROP TABLE IF EXISTS login_lockouts;
@@ -0,0 +1,7 @@
+-- Create the admin_scopes table, the admin scopes granted to staff accounts
+CREATE TABLE admin_scopes (
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  scope VARCHAR(32) NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (user_id, scope)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS workspace_sso;
+DROP TABLE IF EXISTS workspaces;
@@ -0,0 +1,26 @@
+-- Create the workspaces table, the households provisioned by enterprise deployments with their
+-- plan and quotas. Like user_regions it is a directory that lives in the home region, the owner
+-- account lives in its own region. A quota of 0 is unlimited.
+CREATE TABLE workspaces (
+  id SERIAL PRIMARY KEY,
+  slug VARCHAR(64) NOT NULL UNIQUE,
+  name VARCHAR(255) NOT NULL,
+  owner_uuid VARCHAR(255) NOT NULL UNIQUE,
+  region VARCHAR(64) NOT NULL DEFAULT '',
+  plan VARCHAR(64) NOT NULL DEFAULT '',
+  max_members INTEGER NOT NULL DEFAULT 0,
+  max_lists INTEGER NOT NULL DEFAULT 0,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create the workspace_sso table, the OpenID Connect provider members of a workspace sign in
+-- with, domains is a comma separated list of the email domains of the provider
+CREATE TABLE workspace_sso (
+  workspace_id INTEGER PRIMARY KEY REFERENCES workspaces(id) ON DELETE CASCADE,
+  issuer_url VARCHAR(2048) NOT NULL,
+  client_id VARCHAR(255) NOT NULL,
+  client_secret TEXT NOT NULL,
+  domains TEXT NOT NULL DEFAULT '',
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,16 @@
+-- Create the instances table, the API servers that are running. Every instance upserts its row
+-- on a heartbeat, rows with an old heartbeat belong to instances that stopped or crashed. Like
+-- user_regions it lives in the home region, regions is a comma separated list of the data
+-- regions the instance serves.
+CREATE TABLE instances (
+  id VARCHAR(64) PRIMARY KEY,
+  hostname VARCHAR(255) NOT NULL,
+  version VARCHAR(255) NOT NULL DEFAULT '',
+  regions TEXT NOT NULL DEFAULT '',
+  shard INTEGER NOT NULL DEFAULT 0,
+  shards INTEGER NOT NULL DEFAULT 0,
+  started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  heartbeat_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_instances_heartbeat_at ON instances (heartbeat_at);
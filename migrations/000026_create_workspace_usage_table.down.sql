@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS workspace_usage;
+
+ALTER TABLE workspaces
+  DROP COLUMN IF EXISTS max_webhook_deliveries,
+  DROP COLUMN IF EXISTS max_api_calls,
+  DROP COLUMN IF EXISTS max_integration_syncs;
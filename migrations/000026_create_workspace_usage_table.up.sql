@@ -0,0 +1,16 @@
+-- Monthly caps of the metered resources of a workspace, 0 falls back to the cap of the plan.
+ALTER TABLE workspaces
+  ADD COLUMN max_webhook_deliveries INTEGER NOT NULL DEFAULT 0,
+  ADD COLUMN max_api_calls INTEGER NOT NULL DEFAULT 0,
+  ADD COLUMN max_integration_syncs INTEGER NOT NULL DEFAULT 0;
+
+-- Create the workspace_usage table, the usage of the metered resources per calendar month in
+-- UTC. warned_at is set once the soft warning of the month was sent to the owner.
+CREATE TABLE workspace_usage (
+  workspace_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+  resource VARCHAR(64) NOT NULL,
+  period DATE NOT NULL,
+  used INTEGER NOT NULL DEFAULT 0,
+  warned_at TIMESTAMP WITH TIME ZONE,
+  PRIMARY KEY (workspace_id, resource, period)
+);
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_attachments_extraction;
+DROP INDEX IF EXISTS idx_attachments_search_vector;
+
+ALTER TABLE attachments
+  DROP COLUMN IF EXISTS search_vector,
+  DROP COLUMN IF EXISTS extraction_started_at,
+  DROP COLUMN IF EXISTS extraction_attempts,
+  DROP COLUMN IF EXISTS extraction_status,
+  DROP COLUMN IF EXISTS content_text;
@@ -0,0 +1,16 @@
+-- The text extracted from attachments by the extraction worker. New attachments start out
+-- pending, extraction_started_at is set while a worker extracts one so a crashed worker's claim
+-- expires. Filenames rank higher than the content.
+ALTER TABLE attachments
+  ADD COLUMN content_text TEXT,
+  ADD COLUMN extraction_status VARCHAR(16) NOT NULL DEFAULT 'pending',
+  ADD COLUMN extraction_attempts INTEGER NOT NULL DEFAULT 0,
+  ADD COLUMN extraction_started_at TIMESTAMP WITH TIME ZONE,
+  ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(filename, '')), 'C') ||
+    setweight(to_tsvector('english', coalesce(content_text, '')), 'D')
+  ) STORED;
+
+CREATE INDEX idx_attachments_search_vector ON attachments USING GIN (search_vector);
+CREATE INDEX idx_attachments_extraction ON attachments (id)
+  WHERE extraction_status IN ('pending', 'extracting') AND deleted_at IS NULL;
@@ -0,0 +1,15 @@
+-- Create the idempotency_keys table used by the database idempotency store, it lives in the
+-- home region. A row without a status code belongs to a request still in progress, rows are
+-- pruned once they expire.
+CREATE TABLE idempotency_keys (
+  key_hash VARCHAR(64) PRIMARY KEY,
+  fingerprint VARCHAR(64) NOT NULL,
+  status_code INTEGER,
+  content_type VARCHAR(255),
+  body BYTEA,
+  expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS link_check_settings;
+DROP TABLE IF EXISTS todo_links;
@@ -0,0 +1,32 @@
+-- Create the todo_links table, the links found in the descriptions of todos. A link is dead
+-- once dead_since is set, notified_at is set once its owner was emailed about it.
+CREATE TABLE todo_links (
+  todo_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  url TEXT NOT NULL,
+  status_code INTEGER,
+  failures INTEGER NOT NULL DEFAULT 0,
+  checked_at TIMESTAMP WITH TIME ZONE,
+  next_check_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  dead_since TIMESTAMP WITH TIME ZONE,
+  notified_at TIMESTAMP WITH TIME ZONE,
+  PRIMARY KEY (todo_id, url)
+);
+
+CREATE INDEX idx_todo_links_next_check_at ON todo_links (next_check_at);
+
+-- Create the link_check_settings table, users without a row have their links checked and
+-- are notified about dead links.
+CREATE TABLE link_check_settings (
+  user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  check_links BOOLEAN NOT NULL DEFAULT TRUE,
+  notify BOOLEAN NOT NULL DEFAULT TRUE,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Pick up the links of the existing todos, new links are added when a todo is saved
+INSERT INTO todo_links (todo_id, user_id, url)
+SELECT DISTINCT todos.id, todos.user_id, rtrim(link[1], '.,;:!?)]')
+  FROM todos, regexp_matches(todos.description, '(https?://[^\s<>"'']+)', 'g') AS link
+WHERE todos.deleted_at IS NULL
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,3 @@
+-- Count the updates of a todo, updates only apply to the version they were based on so
+-- concurrent edits don't silently overwrite each other
+ALTER TABLE todos ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS workspace_holidays;
+
+ALTER TABLE workspaces
+  DROP COLUMN IF EXISTS work_start_minute,
+  DROP COLUMN IF EXISTS work_end_minute,
+  DROP COLUMN IF EXISTS work_days,
+  DROP COLUMN IF EXISTS timezone;
@@ -0,0 +1,25 @@
+-- Working hours of a workspace, in minutes since midnight in its timezone. work_days are the
+-- comma separated weekdays, 0 is Sunday.
+ALTER TABLE workspaces
+  ADD COLUMN work_start_minute INTEGER NOT NULL DEFAULT 540,
+  ADD COLUMN work_end_minute INTEGER NOT NULL DEFAULT 1020,
+  ADD COLUMN work_days VARCHAR(32) NOT NULL DEFAULT '1,2,3,4,5',
+  ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+-- Create the workspace_holidays table, recurring holidays fall on the same day every year.
+CREATE TABLE workspace_holidays (
+  id SERIAL PRIMARY KEY,
+  uuid VARCHAR(255) NOT NULL UNIQUE,
+  workspace_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+  date DATE NOT NULL,
+  name VARCHAR(255) NOT NULL,
+  recurring BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT unique_holiday_date_per_workspace UNIQUE (workspace_id, date)
+);
+
+CREATE TRIGGER update_updated_at_trigger_workspace_holidays
+BEFORE UPDATE ON workspace_holidays
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
@@ -0,0 +1,20 @@
+-- Create the calendar_tokens table, the read-only tokens of calendar subscription URLs. Only
+-- a hash of the token is stored.
+CREATE TABLE calendar_tokens (
+  id SERIAL PRIMARY KEY,
+  uuid VARCHAR(255) NOT NULL UNIQUE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name VARCHAR(255) NOT NULL,
+  token_hash VARCHAR(64) NOT NULL UNIQUE,
+  last_used_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_calendar_tokens_user_id ON calendar_tokens (user_id) WHERE deleted_at IS NULL;
+
+CREATE TRIGGER update_updated_at_trigger_calendar_tokens
+BEFORE UPDATE ON calendar_tokens
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
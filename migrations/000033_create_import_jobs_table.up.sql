@@ -0,0 +1,26 @@
+-- Create the import_jobs table, backups of other todo apps imported by the import worker. The
+-- uploaded backup is kept until the job finished. started_at is the claim of the worker running
+-- the job, a job whose claim expired was interrupted and is failed instead of run twice.
+CREATE TABLE import_jobs (
+  id SERIAL PRIMARY KEY,
+  uuid VARCHAR(255) NOT NULL UNIQUE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  provider VARCHAR(32) NOT NULL,
+  status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'completed', 'failed')),
+  payload BYTEA,
+  lists_created INTEGER NOT NULL DEFAULT 0,
+  todos_created INTEGER NOT NULL DEFAULT 0,
+  error TEXT NOT NULL DEFAULT '',
+  started_at TIMESTAMP WITH TIME ZONE,
+  finished_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_import_jobs_user_id_created_at ON import_jobs (user_id, created_at);
+CREATE INDEX idx_import_jobs_unfinished ON import_jobs (id) WHERE status IN ('pending', 'running');
+
+CREATE TRIGGER update_updated_at_trigger_import_jobs
+BEFORE UPDATE ON import_jobs
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
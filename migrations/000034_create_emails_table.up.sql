@@ -0,0 +1,28 @@
+-- Create the emails table, the outbox of the notification worker. Emails are rendered when they
+-- are queued so a retry sends the same message, user_id is empty for emails to addresses that
+-- don't belong to an account yet.
+CREATE TABLE emails (
+  id SERIAL PRIMARY KEY,
+  uuid VARCHAR(255) NOT NULL UNIQUE,
+  user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+  kind VARCHAR(32) NOT NULL,
+  recipients TEXT NOT NULL,
+  subject TEXT NOT NULL,
+  text_body TEXT NOT NULL,
+  html_body TEXT NOT NULL DEFAULT '',
+  status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'failed')),
+  attempts INTEGER NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  last_error TEXT,
+  sent_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_emails_user_id ON emails (user_id);
+CREATE INDEX idx_emails_next_attempt_at ON emails (next_attempt_at) WHERE status = 'pending';
+
+CREATE TRIGGER update_updated_at_trigger_emails
+BEFORE UPDATE ON emails
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
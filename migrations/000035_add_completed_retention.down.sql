@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_todos_archivable;
+ALTER TABLE todos DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE lists DROP COLUMN IF EXISTS completed_retention_days;
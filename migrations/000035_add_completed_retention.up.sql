@@ -0,0 +1,8 @@
+-- Lists can hide their completed todos after a number of days, NULL keeps them visible. The
+-- archival worker stamps archived_at on completed todos past the retention of their list,
+-- queries hide them right away so the worker may run behind.
+ALTER TABLE lists ADD COLUMN completed_retention_days INTEGER CHECK (completed_retention_days BETWEEN 1 AND 3650);
+ALTER TABLE todos ADD COLUMN archived_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_todos_archivable ON todos (list_id, completed_at)
+  WHERE completed_at IS NOT NULL AND archived_at IS NULL AND deleted_at IS NULL;
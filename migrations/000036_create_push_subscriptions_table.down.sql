@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_todos_remindable;
+ALTER TABLE todos DROP COLUMN IF EXISTS reminded_at;
+DROP TABLE IF EXISTS push_subscriptions;
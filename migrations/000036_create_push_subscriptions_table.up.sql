@@ -0,0 +1,26 @@
+-- Create the push_subscriptions table, the Web Push subscriptions browsers registered for
+-- reminders. A browser has one endpoint per subscription, registering it again replaces its keys.
+CREATE TABLE push_subscriptions (
+  id SERIAL PRIMARY KEY,
+  uuid VARCHAR(255) NOT NULL UNIQUE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  endpoint TEXT NOT NULL UNIQUE,
+  p256dh TEXT NOT NULL,
+  auth TEXT NOT NULL,
+  user_agent TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_push_subscriptions_user_id ON push_subscriptions (user_id);
+
+CREATE TRIGGER update_updated_at_trigger_push_subscriptions
+BEFORE UPDATE ON push_subscriptions
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
+
+-- reminded_at marks todos the reminder worker already reminded of, moving the due date clears it
+ALTER TABLE todos ADD COLUMN reminded_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_todos_remindable ON todos (due_date)
+  WHERE reminded_at IS NULL AND completed_at IS NULL AND deleted_at IS NULL;
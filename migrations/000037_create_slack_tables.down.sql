@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_todos_overdue;
+ALTER TABLE todos DROP COLUMN IF EXISTS overdue_reminded_at;
+DROP TABLE IF EXISTS slack_messages;
+DROP TABLE IF EXISTS slack_connections;
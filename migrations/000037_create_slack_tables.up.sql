@@ -0,0 +1,54 @@
+-- Create the slack_connections table, a user posts notifications to Slack through an incoming
+-- webhook or the bot token of their Slack app. Slash commands find the user by the linked Slack
+-- user, which is bound once with a short-lived link code.
+CREATE TABLE slack_connections (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+  webhook_url TEXT NOT NULL DEFAULT '',
+  bot_token TEXT NOT NULL DEFAULT '',
+  channel VARCHAR(255) NOT NULL DEFAULT '',
+  events VARCHAR(255) NOT NULL,
+  slack_team_id VARCHAR(32),
+  slack_user_id VARCHAR(32),
+  link_code_hash VARCHAR(64) UNIQUE,
+  link_code_expires_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (slack_team_id, slack_user_id),
+  CHECK (webhook_url <> '' OR (bot_token <> '' AND channel <> ''))
+);
+
+CREATE TRIGGER update_updated_at_trigger_slack_connections
+BEFORE UPDATE ON slack_connections
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
+
+-- Create the slack_messages table, the outbox of the Slack worker like emails
+CREATE TABLE slack_messages (
+  id SERIAL PRIMARY KEY,
+  uuid VARCHAR(255) NOT NULL UNIQUE,
+  connection_id INTEGER NOT NULL REFERENCES slack_connections(id) ON DELETE CASCADE,
+  event VARCHAR(32) NOT NULL,
+  text TEXT NOT NULL,
+  status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'failed')),
+  attempts INTEGER NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  last_error TEXT,
+  sent_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_slack_messages_connection_id ON slack_messages (connection_id);
+CREATE INDEX idx_slack_messages_next_attempt_at ON slack_messages (next_attempt_at) WHERE status = 'pending';
+
+CREATE TRIGGER update_updated_at_trigger_slack_messages
+BEFORE UPDATE ON slack_messages
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
+
+-- overdue_reminded_at marks todos the reminder worker reported overdue, like reminded_at
+ALTER TABLE todos ADD COLUMN overdue_reminded_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_todos_overdue ON todos (due_date)
+  WHERE overdue_reminded_at IS NULL AND completed_at IS NULL AND deleted_at IS NULL;
@@ -0,0 +1,33 @@
+-- Create the todo_rollups table, the todos created, completed and deleted per day, user and list
+-- that chart queries read instead of scanning todos. list_id is 0 for todos without a list, so
+-- it can be part of the key. Days are UTC, the rollup worker recomputes the most recent ones.
+CREATE TABLE todo_rollups (
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  list_id INTEGER NOT NULL DEFAULT 0,
+  day DATE NOT NULL,
+  created INTEGER NOT NULL DEFAULT 0,
+  completed INTEGER NOT NULL DEFAULT 0,
+  -- deleted only counts todos deleted while open, completed ones already left the open count
+  deleted INTEGER NOT NULL DEFAULT 0,
+  PRIMARY KEY (user_id, list_id, day)
+);
+
+CREATE INDEX idx_todo_rollups_list_id ON todo_rollups (list_id, day) WHERE list_id <> 0;
+
+-- Backfill the history of existing todos, later days are kept up to date by the worker
+INSERT INTO todo_rollups (user_id, list_id, day, created, completed, deleted)
+SELECT user_id, list_id, day, SUM(created), SUM(completed), SUM(deleted)
+  FROM (
+    SELECT user_id, COALESCE(list_id, 0) AS list_id, (created_at AT TIME ZONE 'UTC')::date AS day,
+      1 AS created, 0 AS completed, 0 AS deleted
+      FROM todos
+    UNION ALL
+    SELECT user_id, COALESCE(list_id, 0), (completed_at AT TIME ZONE 'UTC')::date, 0, 1, 0
+      FROM todos
+    WHERE completed_at IS NOT NULL
+    UNION ALL
+    SELECT user_id, COALESCE(list_id, 0), (deleted_at AT TIME ZONE 'UTC')::date, 0, 0, 1
+      FROM todos
+    WHERE deleted_at IS NOT NULL AND completed_at IS NULL
+  ) AS events
+GROUP BY user_id, list_id, day;
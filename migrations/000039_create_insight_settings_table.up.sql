@@ -0,0 +1,21 @@
+-- Create the insight_settings table, users opt in to nudges about drops in their productivity
+-- and tune when they are sent. The insight worker checks a user once per interval, next_check_at
+-- spreads the checks and the *_nudged_at columns keep a nudge from repeating every day.
+CREATE TABLE insight_settings (
+  user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  enabled BOOLEAN NOT NULL DEFAULT FALSE,
+  overdue_spike INTEGER NOT NULL CHECK (overdue_spike BETWEEN 1 AND 1000),
+  idle_days INTEGER NOT NULL CHECK (idle_days BETWEEN 1 AND 90),
+  next_check_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  overdue_nudged_at TIMESTAMP WITH TIME ZONE,
+  idle_nudged_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_insight_settings_next_check_at ON insight_settings (next_check_at) WHERE enabled;
+
+CREATE TRIGGER update_updated_at_trigger_insight_settings
+BEFORE UPDATE ON insight_settings
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
@@ -0,0 +1,3 @@
+-- Tags can have a color for clients to render them with, as a lowercase #rrggbb hex color. Tag
+-- groups need no column, they are the part of the name before the first slash, e.g. "project/".
+ALTER TABLE tags ADD COLUMN color VARCHAR(7) CHECK (color ~ '^#[0-9a-f]{6}$');
@@ -0,0 +1,8 @@
+-- Drop triggers
+DROP TRIGGER update_updated_at_trigger_todo_rules ON todo_rules;
+
+-- Drop indexes
+DROP INDEX idx_todo_rules_user_id_position;
+
+-- Drop tables
+DROP TABLE todo_rules;
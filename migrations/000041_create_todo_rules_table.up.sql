@@ -0,0 +1,27 @@
+-- Create the todo_rules table, the rules a user has todos tagged and sorted with. Rules are
+-- evaluated by position, tags are stored one per line and a null priority or list leaves it as is.
+CREATE TABLE todo_rules (
+  id SERIAL PRIMARY KEY,
+  uuid VARCHAR(255) NOT NULL UNIQUE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name VARCHAR(255) NOT NULL,
+  position INTEGER NOT NULL,
+  field VARCHAR(16) NOT NULL CHECK (field IN ('title', 'description')),
+  operator VARCHAR(16) NOT NULL CHECK (operator IN ('contains', 'equals', 'starts_with', 'ends_with')),
+  value TEXT NOT NULL,
+  tags TEXT NOT NULL DEFAULT '',
+  priority INTEGER,
+  list_uuid VARCHAR(255),
+  stop BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX idx_todo_rules_user_id_position ON todo_rules (user_id, position);
+
+-- Create a trigger to update the updated_at column on update for todo_rules
+CREATE TRIGGER update_updated_at_trigger_todo_rules
+BEFORE UPDATE ON todo_rules
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
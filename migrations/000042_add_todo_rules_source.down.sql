@@ -0,0 +1,2 @@
+ALTER TABLE todo_rules DROP COLUMN IF EXISTS channel;
+ALTER TABLE todo_rules DROP COLUMN IF EXISTS source;
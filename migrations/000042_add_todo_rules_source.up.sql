@@ -0,0 +1,5 @@
+-- Rules can be limited to the todos captured by one source, and to one channel of it, e.g. a
+-- Slack channel. Empty columns match every source and channel.
+ALTER TABLE todo_rules ADD COLUMN source VARCHAR(16) NOT NULL DEFAULT ''
+  CHECK (source IN ('', 'app', 'slack', 'voice', 'email', 'share'));
+ALTER TABLE todo_rules ADD COLUMN channel VARCHAR(255) NOT NULL DEFAULT '';
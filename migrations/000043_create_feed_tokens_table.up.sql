@@ -0,0 +1,21 @@
+-- Create the feed_tokens table, the read-only tokens of list feed URLs. A token reads a single
+-- list and only a hash of it is stored.
+CREATE TABLE feed_tokens (
+  id SERIAL PRIMARY KEY,
+  uuid VARCHAR(255) NOT NULL UNIQUE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  list_id INTEGER NOT NULL REFERENCES lists(id) ON DELETE CASCADE,
+  name VARCHAR(255) NOT NULL,
+  token_hash VARCHAR(64) NOT NULL UNIQUE,
+  last_used_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_feed_tokens_user_id_list_id ON feed_tokens (user_id, list_id) WHERE deleted_at IS NULL;
+
+CREATE TRIGGER update_updated_at_trigger_feed_tokens
+BEFORE UPDATE ON feed_tokens
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
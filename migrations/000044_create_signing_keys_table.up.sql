@@ -0,0 +1,14 @@
+-- Create the signing_keys table, the keys access tokens are signed with. The private keys are
+-- sealed with a key derived from JWT_SECRET. Keys are only kept in the home database since every
+-- instance verifies the tokens of every region.
+CREATE TABLE signing_keys (
+  id SERIAL PRIMARY KEY,
+  kid VARCHAR(64) NOT NULL UNIQUE,
+  algorithm VARCHAR(16) NOT NULL,
+  private_key BYTEA NOT NULL,
+  public_key BYTEA NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  retires_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_signing_keys_retires_at ON signing_keys (retires_at);
@@ -0,0 +1,13 @@
+DROP TRIGGER prevent_changes_trigger_audit_logs ON audit_logs;
+
+CREATE TRIGGER prevent_changes_trigger_audit_logs
+BEFORE UPDATE OR DELETE ON audit_logs
+FOR EACH ROW
+EXECUTE PROCEDURE prevent_changes();
+
+DROP FUNCTION IF EXISTS prevent_audit_changes;
+
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS anonymized_at;
+
+DROP INDEX IF EXISTS idx_users_purge_at;
+ALTER TABLE users DROP COLUMN IF EXISTS purge_at;
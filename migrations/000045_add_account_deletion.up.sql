@@ -0,0 +1,30 @@
+-- Accounts scheduled for deletion are purged by the purge worker once purge_at passed, until then
+-- the deletion can be cancelled.
+ALTER TABLE users ADD COLUMN purge_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_users_purge_at ON users (purge_at) WHERE purge_at IS NOT NULL;
+
+-- The audit entries of purged accounts are anonymized. audit_logs stays append-only otherwise,
+-- only the personal fields of an entry may be cleared, and only once.
+ALTER TABLE audit_logs ADD COLUMN anonymized_at TIMESTAMP WITH TIME ZONE;
+
+CREATE OR REPLACE FUNCTION prevent_audit_changes()
+RETURNS TRIGGER AS $$
+BEGIN
+  IF TG_OP = 'UPDATE' AND OLD.anonymized_at IS NULL AND NEW.anonymized_at IS NOT NULL
+    AND NEW.user_id IS NULL AND NEW.ip = '' AND NEW.user_agent = ''
+    AND NEW.id = OLD.id AND NEW.action = OLD.action AND NEW.path = OLD.path
+    AND NEW.status = OLD.status AND NEW.request_id = OLD.request_id
+    AND NEW.created_at = OLD.created_at AND NEW.prev_hash = OLD.prev_hash AND NEW.hash = OLD.hash THEN
+    RETURN NEW;
+  END IF;
+  RAISE EXCEPTION '% is append-only', TG_TABLE_NAME;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER prevent_changes_trigger_audit_logs ON audit_logs;
+
+CREATE TRIGGER prevent_changes_trigger_audit_logs
+BEFORE UPDATE OR DELETE ON audit_logs
+FOR EACH ROW
+EXECUTE PROCEDURE prevent_audit_changes();
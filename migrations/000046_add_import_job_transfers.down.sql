@@ -0,0 +1,5 @@
+ALTER TABLE import_jobs
+  DROP COLUMN archive_sha256,
+  DROP COLUMN bytes_received,
+  DROP COLUMN source_token,
+  DROP COLUMN source_url;
@@ -0,0 +1,8 @@
+-- Transfers import the export archive of an account downloaded from another instance instead of
+-- an upload. The access token of the source is kept until the job finished, bytes_received is the
+-- progress of the download and archive_sha256 the digest the archive was verified with.
+ALTER TABLE import_jobs
+  ADD COLUMN source_url TEXT NOT NULL DEFAULT '',
+  ADD COLUMN source_token TEXT,
+  ADD COLUMN bytes_received BIGINT NOT NULL DEFAULT 0,
+  ADD COLUMN archive_sha256 VARCHAR(64) NOT NULL DEFAULT '';
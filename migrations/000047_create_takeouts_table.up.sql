@@ -0,0 +1,26 @@
+-- Create the takeouts table, exports of all the personal data of a user assembled by the takeout
+-- worker. The archive is stored under storage_key until expires_at, then it is deleted and the
+-- row kept as a record of the export.
+CREATE TABLE takeouts (
+  id SERIAL PRIMARY KEY,
+  uuid VARCHAR(255) NOT NULL UNIQUE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'completed', 'failed')),
+  storage_key VARCHAR(512) NOT NULL DEFAULT '',
+  size BIGINT NOT NULL DEFAULT 0,
+  error TEXT NOT NULL DEFAULT '',
+  started_at TIMESTAMP WITH TIME ZONE,
+  finished_at TIMESTAMP WITH TIME ZONE,
+  expires_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_takeouts_user_id ON takeouts (user_id);
+CREATE INDEX idx_takeouts_unfinished ON takeouts (id) WHERE status IN ('pending', 'running');
+CREATE INDEX idx_takeouts_expires_at ON takeouts (expires_at) WHERE storage_key <> '';
+
+CREATE TRIGGER update_updated_at_trigger_takeouts
+BEFORE UPDATE ON takeouts
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
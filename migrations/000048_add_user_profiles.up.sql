@@ -0,0 +1,6 @@
+-- The profile of a user. Reminders and due dates are read in the timezone, locale is a language
+-- tag like en-US and avatar_url links to a picture of the user.
+ALTER TABLE users
+  ADD COLUMN timezone TEXT NOT NULL DEFAULT 'UTC',
+  ADD COLUMN locale TEXT,
+  ADD COLUMN avatar_url TEXT;
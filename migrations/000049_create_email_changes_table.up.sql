@@ -0,0 +1,18 @@
+-- Create the email_changes table, pending changes of the email of an account. The current and the
+-- new address each get a token, only their hashes are stored. The email is swapped once both
+-- confirmed, a user has at most one pending change.
+CREATE TABLE email_changes (
+  id SERIAL PRIMARY KEY,
+  uuid VARCHAR(255) NOT NULL UNIQUE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  new_email VARCHAR(255) NOT NULL,
+  old_token_hash VARCHAR(64) NOT NULL UNIQUE,
+  new_token_hash VARCHAR(64) NOT NULL UNIQUE,
+  old_confirmed_at TIMESTAMP WITH TIME ZONE,
+  new_confirmed_at TIMESTAMP WITH TIME ZONE,
+  expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  completed_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_email_changes_pending ON email_changes (user_id) WHERE completed_at IS NULL;
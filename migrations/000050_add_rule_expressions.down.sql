@@ -0,0 +1,13 @@
+-- Rules with a condition can't be kept without their expressions.
+DELETE FROM todo_rules WHERE condition <> '';
+ALTER TABLE todo_rules
+  DROP COLUMN computed_tags,
+  DROP COLUMN computed_priority,
+  DROP COLUMN computed_description,
+  DROP COLUMN computed_title,
+  DROP COLUMN condition;
+ALTER TABLE todo_rules DROP CONSTRAINT todo_rules_operator_check;
+ALTER TABLE todo_rules ADD CONSTRAINT todo_rules_operator_check
+  CHECK (operator IN ('contains', 'equals', 'starts_with', 'ends_with'));
+ALTER TABLE todo_rules DROP CONSTRAINT todo_rules_field_check;
+ALTER TABLE todo_rules ADD CONSTRAINT todo_rules_field_check CHECK (field IN ('title', 'description'));
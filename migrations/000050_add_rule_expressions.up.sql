@@ -0,0 +1,14 @@
+-- Rules can match on a condition expression instead of a field and operator, whose columns are
+-- empty then, and compute the title, description, priority and tags of todos with expressions.
+-- Empty expressions are unset.
+ALTER TABLE todo_rules DROP CONSTRAINT todo_rules_field_check;
+ALTER TABLE todo_rules ADD CONSTRAINT todo_rules_field_check CHECK (field IN ('', 'title', 'description'));
+ALTER TABLE todo_rules DROP CONSTRAINT todo_rules_operator_check;
+ALTER TABLE todo_rules ADD CONSTRAINT todo_rules_operator_check
+  CHECK (operator IN ('', 'contains', 'equals', 'starts_with', 'ends_with'));
+ALTER TABLE todo_rules
+  ADD COLUMN condition TEXT NOT NULL DEFAULT '',
+  ADD COLUMN computed_title TEXT NOT NULL DEFAULT '',
+  ADD COLUMN computed_description TEXT NOT NULL DEFAULT '',
+  ADD COLUMN computed_priority TEXT NOT NULL DEFAULT '',
+  ADD COLUMN computed_tags TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,12 @@
+-- Create the token_revocations table used by the database token blacklist, it revokes every
+-- access token of a user issued before issued_before, e.g. after the password changed. Rows are
+-- pruned once the last token they revoke expired.
+CREATE TABLE token_revocations (
+  user_uuid VARCHAR(255) PRIMARY KEY,
+  issued_before TIMESTAMP WITH TIME ZONE NOT NULL,
+  expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX idx_token_revocations_expires_at ON token_revocations (expires_at);
@@ -0,0 +1,12 @@
+-- Drop triggers
+DROP TRIGGER update_updated_at_trigger_automation_runs ON automation_runs;
+DROP TRIGGER update_updated_at_trigger_automations ON automations;
+
+-- Drop indexes
+DROP INDEX idx_automation_runs_created_at;
+DROP INDEX idx_automation_runs_automation_id;
+DROP INDEX idx_automations_user_id;
+
+-- Drop tables
+DROP TABLE automation_runs;
+DROP TABLE automations;
@@ -0,0 +1,45 @@
+-- Create the automations table, events are stored comma separated like those of webhooks. The
+-- module is the WebAssembly binary the user uploaded.
+CREATE TABLE automations (
+  id SERIAL PRIMARY KEY,
+  uuid VARCHAR(255) NOT NULL UNIQUE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name VARCHAR(100) NOT NULL,
+  events TEXT NOT NULL,
+  module BYTEA NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create the automation_runs table, one row per event and automation run once by the worker
+CREATE TABLE automation_runs (
+  id SERIAL PRIMARY KEY,
+  uuid VARCHAR(255) NOT NULL UNIQUE,
+  automation_id INTEGER NOT NULL REFERENCES automations(id) ON DELETE CASCADE,
+  event VARCHAR(64) NOT NULL,
+  payload TEXT NOT NULL,
+  status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+  fuel_used BIGINT NOT NULL DEFAULT 0,
+  log TEXT,
+  error TEXT,
+  finished_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX idx_automations_user_id ON automations (user_id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_automation_runs_automation_id ON automation_runs (automation_id);
+CREATE INDEX idx_automation_runs_created_at ON automation_runs (created_at) WHERE status = 'pending';
+
+-- Create triggers to update the updated_at column on update
+CREATE TRIGGER update_updated_at_trigger_automations
+BEFORE UPDATE ON automations
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
+
+CREATE TRIGGER update_updated_at_trigger_automation_runs
+BEFORE UPDATE ON automation_runs
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
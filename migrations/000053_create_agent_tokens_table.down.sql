@@ -0,0 +1,14 @@
+-- Rules for assistant todos can't be kept without the source.
+DELETE FROM todo_rules WHERE source = 'agent';
+ALTER TABLE todo_rules DROP CONSTRAINT todo_rules_source_check;
+ALTER TABLE todo_rules ADD CONSTRAINT todo_rules_source_check
+  CHECK (source IN ('', 'app', 'slack', 'voice', 'email', 'share'));
+
+-- Drop triggers
+DROP TRIGGER update_updated_at_trigger_agent_tokens ON agent_tokens;
+
+-- Drop indexes
+DROP INDEX idx_agent_tokens_user_id;
+
+-- Drop tables
+DROP TABLE agent_tokens;
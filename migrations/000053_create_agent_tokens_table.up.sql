@@ -0,0 +1,29 @@
+-- Create the agent_tokens table, scoped credentials of AI assistants. Scopes are stored comma
+-- separated and only a hash of the token is stored.
+CREATE TABLE agent_tokens (
+  id SERIAL PRIMARY KEY,
+  uuid VARCHAR(255) NOT NULL UNIQUE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name VARCHAR(100) NOT NULL,
+  scopes TEXT NOT NULL,
+  token_hash VARCHAR(64) NOT NULL UNIQUE,
+  expires_at TIMESTAMP WITH TIME ZONE,
+  last_used_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create indexes
+CREATE INDEX idx_agent_tokens_user_id ON agent_tokens (user_id) WHERE deleted_at IS NULL;
+
+-- Create a trigger to update the updated_at column on update for agent_tokens
+CREATE TRIGGER update_updated_at_trigger_agent_tokens
+BEFORE UPDATE ON agent_tokens
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
+
+-- Rules can route the todos created by assistants
+ALTER TABLE todo_rules DROP CONSTRAINT todo_rules_source_check;
+ALTER TABLE todo_rules ADD CONSTRAINT todo_rules_source_check
+  CHECK (source IN ('', 'app', 'slack', 'voice', 'email', 'share', 'agent'));
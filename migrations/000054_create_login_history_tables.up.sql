@@ -0,0 +1,32 @@
+-- Create the known_devices table, the devices a user logged in from. The fingerprint is a hash
+-- of the device ID the app sends or of the user agent without version numbers.
+CREATE TABLE known_devices (
+  id SERIAL PRIMARY KEY,
+  uuid VARCHAR(255) NOT NULL UNIQUE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  fingerprint VARCHAR(64) NOT NULL,
+  user_agent VARCHAR(255) NOT NULL DEFAULT '',
+  last_ip VARCHAR(45) NOT NULL DEFAULT '',
+  last_country VARCHAR(2) NOT NULL DEFAULT '',
+  first_seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  last_seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (user_id, fingerprint)
+);
+
+-- Create the logins table, the successful logins of the last days with what was new about them
+CREATE TABLE logins (
+  id SERIAL PRIMARY KEY,
+  uuid VARCHAR(255) NOT NULL UNIQUE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  ip VARCHAR(45) NOT NULL DEFAULT '',
+  user_agent VARCHAR(255) NOT NULL DEFAULT '',
+  country VARCHAR(2) NOT NULL DEFAULT '',
+  fingerprint VARCHAR(64) NOT NULL,
+  new_device BOOLEAN NOT NULL DEFAULT FALSE,
+  new_country BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX idx_logins_user_id ON logins (user_id, id DESC);
+CREATE INDEX idx_logins_created_at ON logins (created_at);
@@ -0,0 +1,11 @@
+-- Drop triggers
+DROP TRIGGER update_updated_at_trigger_todo_embeddings ON todo_embeddings;
+
+-- Drop indexes
+DROP INDEX idx_todo_embeddings_due_at;
+DROP INDEX idx_todo_embeddings_user_id;
+
+-- Drop tables
+DROP TABLE todo_embeddings;
+
+-- The vector extension is kept, it may be used outside of the app.
@@ -0,0 +1,27 @@
+-- Semantic search stores the embeddings of todos with pgvector. The column has no fixed
+-- dimensions so the embedding model can be changed, searches only compare the vectors of the
+-- model that embedded the query and scan the todos of a single user.
+CREATE EXTENSION IF NOT EXISTS vector;
+
+-- Create the todo_embeddings table. A row is due while the todo changed since it was embedded,
+-- content_hash skips embedding again when the embedded text didn't change.
+CREATE TABLE todo_embeddings (
+  todo_id INTEGER PRIMARY KEY REFERENCES todos(id) ON DELETE CASCADE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  model VARCHAR(255) NOT NULL DEFAULT '',
+  content_hash VARCHAR(64) NOT NULL DEFAULT '',
+  embedding vector,
+  due_at TIMESTAMP WITH TIME ZONE,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX idx_todo_embeddings_user_id ON todo_embeddings (user_id, model);
+CREATE INDEX idx_todo_embeddings_due_at ON todo_embeddings (due_at) WHERE due_at IS NOT NULL;
+
+-- Create a trigger to update the updated_at column on update for todo_embeddings
+CREATE TRIGGER update_updated_at_trigger_todo_embeddings
+BEFORE UPDATE ON todo_embeddings
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
@@ -0,0 +1,19 @@
+-- Create the digest_settings table, users opt in to a weekly digest email and pick the weekday and
+-- hour it is sent at in their timezone. next_send_at is computed from them by the digest worker.
+CREATE TABLE digest_settings (
+  user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  enabled BOOLEAN NOT NULL DEFAULT FALSE,
+  weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+  hour SMALLINT NOT NULL CHECK (hour BETWEEN 0 AND 23),
+  next_send_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  last_sent_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_digest_settings_next_send_at ON digest_settings (next_send_at) WHERE enabled;
+
+CREATE TRIGGER update_updated_at_trigger_digest_settings
+BEFORE UPDATE ON digest_settings
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
@@ -0,0 +1,38 @@
+-- Create the escalation_rules table, the rules that escalate the open todos of a list once they are
+-- overdue by overdue_days. A null priority leaves the priority as is.
+CREATE TABLE escalation_rules (
+  id SERIAL PRIMARY KEY,
+  uuid VARCHAR(255) NOT NULL UNIQUE,
+  list_id INTEGER NOT NULL REFERENCES lists(id) ON DELETE CASCADE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  overdue_days INTEGER NOT NULL CHECK (overdue_days >= 0),
+  priority INTEGER,
+  notify BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_escalation_rules_list_id ON escalation_rules (list_id) WHERE deleted_at IS NULL;
+
+CREATE TRIGGER update_updated_at_trigger_escalation_rules
+BEFORE UPDATE ON escalation_rules
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
+
+-- Create the todo_escalations table, the activity log of the escalations of a list. A rule applies
+-- to a todo once per due date, priority_to is null when the priority was left as is.
+CREATE TABLE todo_escalations (
+  id SERIAL PRIMARY KEY,
+  rule_id INTEGER NOT NULL REFERENCES escalation_rules(id) ON DELETE CASCADE,
+  list_id INTEGER NOT NULL REFERENCES lists(id) ON DELETE CASCADE,
+  todo_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+  due_date TIMESTAMP WITH TIME ZONE NOT NULL,
+  priority_from INTEGER NOT NULL,
+  priority_to INTEGER,
+  notified BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (rule_id, todo_id, due_date)
+);
+
+CREATE INDEX idx_todo_escalations_list_id ON todo_escalations (list_id, id);
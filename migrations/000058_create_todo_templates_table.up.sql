@@ -0,0 +1,24 @@
+-- Create the todo_templates table, reusable todos like a release checklist. Tags and subtasks are
+-- stored one per line, a null due_in_days creates todos without a due date.
+CREATE TABLE todo_templates (
+  id SERIAL PRIMARY KEY,
+  uuid VARCHAR(255) NOT NULL UNIQUE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name VARCHAR(255) NOT NULL,
+  title VARCHAR(255) NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
+  priority INTEGER NOT NULL,
+  estimate_minutes INTEGER NOT NULL DEFAULT 0,
+  tags TEXT NOT NULL DEFAULT '',
+  subtasks TEXT NOT NULL DEFAULT '',
+  due_in_days INTEGER CHECK (due_in_days > 0),
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_todo_templates_user_id ON todo_templates (user_id);
+
+CREATE TRIGGER update_updated_at_trigger_todo_templates
+BEFORE UPDATE ON todo_templates
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS todo_blind_indexes;
+DROP TABLE IF EXISTS list_keys;
+DROP TABLE IF EXISTS user_public_keys;
+ALTER TABLE todos DROP COLUMN IF EXISTS key_version;
+ALTER TABLE todos DROP COLUMN IF EXISTS ciphertext;
+ALTER TABLE lists DROP COLUMN IF EXISTS key_version;
@@ -0,0 +1,40 @@
+-- Lists are end-to-end encrypted from key_version 1 on, the todos of such lists hold ciphertext
+-- instead of a title and description. key_version of a todo is the list key it was encrypted with.
+ALTER TABLE lists ADD COLUMN key_version INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE todos ADD COLUMN ciphertext TEXT;
+ALTER TABLE todos ADD COLUMN key_version INTEGER;
+
+-- Create the user_public_keys table, the keys list keys are wrapped with for a user. The server
+-- never sees the private keys.
+CREATE TABLE user_public_keys (
+  user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  public_key TEXT NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_updated_at_trigger_user_public_keys
+BEFORE UPDATE ON user_public_keys
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
+
+-- Create the list_keys table, every version of the key of an encrypted list wrapped for each
+-- member that holds it.
+CREATE TABLE list_keys (
+  list_id INTEGER NOT NULL REFERENCES lists(id) ON DELETE CASCADE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  key_version INTEGER NOT NULL CHECK (key_version > 0),
+  wrapped_key TEXT NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (list_id, user_id, key_version)
+);
+
+-- Create the todo_blind_indexes table, keyed hashes clients compute from the fields of encrypted
+-- todos they want to filter by.
+CREATE TABLE todo_blind_indexes (
+  todo_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+  token VARCHAR(128) NOT NULL,
+  PRIMARY KEY (todo_id, token)
+);
+
+CREATE INDEX idx_todo_blind_indexes_token ON todo_blind_indexes (token);
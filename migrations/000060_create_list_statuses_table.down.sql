@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_todos_list_id_status;
+ALTER TABLE todos DROP COLUMN IF EXISTS position;
+ALTER TABLE todos DROP COLUMN IF EXISTS status;
+DROP TABLE IF EXISTS list_statuses;
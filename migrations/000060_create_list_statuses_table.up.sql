@@ -0,0 +1,25 @@
+-- Create the list_statuses table, the columns of the board of a list in position order. Lists
+-- without any use the default statuses, done statuses complete the todos moved into them.
+CREATE TABLE list_statuses (
+  id SERIAL PRIMARY KEY,
+  list_id INTEGER NOT NULL REFERENCES lists(id) ON DELETE CASCADE,
+  key VARCHAR(32) NOT NULL,
+  name VARCHAR(64) NOT NULL,
+  position INTEGER NOT NULL,
+  done BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE (list_id, key)
+);
+
+CREATE TRIGGER update_updated_at_trigger_list_statuses
+BEFORE UPDATE ON list_statuses
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
+
+-- status is the key of the status of a todo on the board of its list, position its place in that
+-- column from 1 on. Todos that weren't placed yet have neither.
+ALTER TABLE todos ADD COLUMN status VARCHAR(32);
+ALTER TABLE todos ADD COLUMN position INTEGER;
+
+CREATE INDEX idx_todos_list_id_status ON todos (list_id, status, position) WHERE deleted_at IS NULL;
@@ -0,0 +1,14 @@
+-- Create the todo_secure_notes table, a secret of a todo like a locker code sealed with a key of
+-- the server. It is kept apart from the todo so reads, exports and search never see it.
+CREATE TABLE todo_secure_notes (
+  todo_id INTEGER PRIMARY KEY REFERENCES todos(id) ON DELETE CASCADE,
+  sealed BYTEA NOT NULL,
+  updated_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_updated_at_trigger_todo_secure_notes
+BEFORE UPDATE ON todo_secure_notes
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
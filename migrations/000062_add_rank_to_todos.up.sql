@@ -0,0 +1,6 @@
+-- rank orders the todos of a user by hand. Ranks are compared byte by byte, so a todo moved
+-- between two others gets a rank in between and no other todo is rewritten. Todos without a
+-- rank follow the ranked ones.
+ALTER TABLE todos ADD COLUMN rank TEXT COLLATE "C";
+
+CREATE INDEX idx_todos_user_id_rank ON todos (user_id, rank) WHERE deleted_at IS NULL;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS recovery_approvals;
+DROP TABLE IF EXISTS recovery_requests;
+DROP TABLE IF EXISTS trusted_contacts;
+DROP TABLE IF EXISTS recovery_settings;
@@ -0,0 +1,49 @@
+-- Create the recovery_settings table, how many trusted contacts have to approve the recovery of
+-- an account.
+CREATE TABLE recovery_settings (
+  user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  threshold INTEGER NOT NULL CHECK (threshold > 0),
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_updated_at_trigger_recovery_settings
+BEFORE UPDATE ON recovery_settings
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
+
+-- Create the trusted_contacts table, the users that can approve the recovery of an account.
+CREATE TABLE trusted_contacts (
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  contact_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (user_id, contact_id),
+  CHECK (user_id <> contact_id)
+);
+
+CREATE INDEX idx_trusted_contacts_contact_id ON trusted_contacts (contact_id);
+
+-- Create the recovery_requests table, a request to recover an account by whoever holds the
+-- token. It can be completed once enough contacts approved it and the delay passed.
+CREATE TABLE recovery_requests (
+  id SERIAL PRIMARY KEY,
+  uuid VARCHAR(255) NOT NULL UNIQUE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  token_hash VARCHAR(64) NOT NULL UNIQUE,
+  ready_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  completed_at TIMESTAMP WITH TIME ZONE,
+  cancelled_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_recovery_requests_user_id ON recovery_requests (user_id)
+  WHERE completed_at IS NULL AND cancelled_at IS NULL;
+
+-- Create the recovery_approvals table, the contacts that approved a recovery request.
+CREATE TABLE recovery_approvals (
+  request_id INTEGER NOT NULL REFERENCES recovery_requests(id) ON DELETE CASCADE,
+  contact_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (request_id, contact_id)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS onboarding_progress;
+DROP TABLE IF EXISTS onboarding_states;
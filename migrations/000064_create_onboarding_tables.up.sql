@@ -0,0 +1,27 @@
+-- Create the onboarding_states table, how far a user is through onboarding across their devices.
+-- triggered_at is when the first login triggers ran, so they run once per user.
+CREATE TABLE onboarding_states (
+  user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  dismissed_at TIMESTAMP WITH TIME ZONE,
+  triggered_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_updated_at_trigger_onboarding_states
+BEFORE UPDATE ON onboarding_states
+FOR EACH ROW
+EXECUTE PROCEDURE update_updated_at();
+
+-- existing users logged in before, they don't get the first login triggers
+INSERT INTO onboarding_states (user_id, triggered_at)
+SELECT id, CURRENT_TIMESTAMP FROM users;
+
+-- Create the onboarding_progress table, the steps a user completed and the tips they dismissed.
+CREATE TABLE onboarding_progress (
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  kind VARCHAR(16) NOT NULL CHECK (kind IN ('step', 'tip')),
+  key VARCHAR(64) NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (user_id, kind, key)
+);
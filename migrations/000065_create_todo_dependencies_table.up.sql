@@ -0,0 +1,12 @@
+-- Create the todo_dependencies table, todo_id is blocked by blocker_id until the blocker is
+-- completed. Both are todos of the same list, or of no list of the same owner.
+CREATE TABLE todo_dependencies (
+  todo_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+  blocker_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+  created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (todo_id, blocker_id),
+  CHECK (todo_id <> blocker_id)
+);
+
+CREATE INDEX idx_todo_dependencies_blocker_id ON todo_dependencies (blocker_id);
@@ -0,0 +1,9 @@
+-- Create the shadow schema the candidate todo repo writes to while SHADOW_WRITES is on. Its
+-- connections put the schema first on the search path, so it writes these copies of the todo
+-- tables and reads every other table as usual. Migrations changing the todo tables change the
+-- copies too while a candidate is validated.
+CREATE SCHEMA IF NOT EXISTS shadow;
+
+CREATE TABLE shadow.todos (LIKE public.todos INCLUDING ALL);
+CREATE TABLE shadow.todo_tags (LIKE public.todo_tags INCLUDING ALL);
+CREATE TABLE shadow.todo_blind_indexes (LIKE public.todo_blind_indexes INCLUDING ALL);
@@ -0,0 +1,25 @@
+ALTER TABLE ip_ranges ADD COLUMN owner_id INTEGER REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE ip_allowlist_bypasses ADD COLUMN owner_id INTEGER REFERENCES users(id) ON DELETE CASCADE;
+
+UPDATE ip_ranges SET owner_id = users.id
+FROM workspaces, users
+WHERE workspaces.id = ip_ranges.workspace_id AND users.uuid = workspaces.owner_uuid;
+
+UPDATE ip_allowlist_bypasses SET owner_id = users.id
+FROM workspaces, users
+WHERE workspaces.id = ip_allowlist_bypasses.workspace_id AND users.uuid = workspaces.owner_uuid;
+
+DELETE FROM ip_ranges WHERE owner_id IS NULL;
+DELETE FROM ip_allowlist_bypasses WHERE owner_id IS NULL;
+
+ALTER TABLE ip_ranges ALTER COLUMN owner_id SET NOT NULL;
+ALTER TABLE ip_allowlist_bypasses ALTER COLUMN owner_id SET NOT NULL;
+
+DROP INDEX IF EXISTS idx_ip_ranges_workspace_id;
+DROP INDEX IF EXISTS idx_ip_allowlist_bypasses_workspace_id;
+
+ALTER TABLE ip_ranges DROP COLUMN workspace_id;
+ALTER TABLE ip_allowlist_bypasses DROP COLUMN workspace_id;
+
+CREATE INDEX idx_ip_ranges_owner_id ON ip_ranges (owner_id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_ip_allowlist_bypasses_owner_id ON ip_allowlist_bypasses (owner_id, suspended_until);
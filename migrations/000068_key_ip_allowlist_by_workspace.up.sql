@@ -0,0 +1,28 @@
+-- Key the IP allowlist by workspace instead of by the owner of a household. Like the other
+-- workspace tables the allowlist lives in the home database with the workspace directory, ranges
+-- and bypasses of households that aren't part of a workspace are dropped.
+ALTER TABLE ip_ranges ADD COLUMN workspace_id INTEGER REFERENCES workspaces(id) ON DELETE CASCADE;
+ALTER TABLE ip_allowlist_bypasses ADD COLUMN workspace_id INTEGER REFERENCES workspaces(id) ON DELETE CASCADE;
+
+UPDATE ip_ranges SET workspace_id = workspaces.id
+FROM users, workspaces
+WHERE users.id = ip_ranges.owner_id AND workspaces.owner_uuid = users.uuid;
+
+UPDATE ip_allowlist_bypasses SET workspace_id = workspaces.id
+FROM users, workspaces
+WHERE users.id = ip_allowlist_bypasses.owner_id AND workspaces.owner_uuid = users.uuid;
+
+DELETE FROM ip_ranges WHERE workspace_id IS NULL;
+DELETE FROM ip_allowlist_bypasses WHERE workspace_id IS NULL;
+
+ALTER TABLE ip_ranges ALTER COLUMN workspace_id SET NOT NULL;
+ALTER TABLE ip_allowlist_bypasses ALTER COLUMN workspace_id SET NOT NULL;
+
+DROP INDEX IF EXISTS idx_ip_ranges_owner_id;
+DROP INDEX IF EXISTS idx_ip_allowlist_bypasses_owner_id;
+
+ALTER TABLE ip_ranges DROP COLUMN owner_id;
+ALTER TABLE ip_allowlist_bypasses DROP COLUMN owner_id;
+
+CREATE INDEX idx_ip_ranges_workspace_id ON ip_ranges (workspace_id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_ip_allowlist_bypasses_workspace_id ON ip_allowlist_bypasses (workspace_id, suspended_until);
@@ -0,0 +1,117 @@
+// Package migrations versions the database schema. The SQL files are embedded into the binary,
+// so a deployment migrates its databases without a copy of this directory.
+package migrations
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// Status is the schema version of a database compared to the embedded migrations.
+type Status struct {
+	// Version is 0 before the first migration.
+	Version uint
+	// Dirty means a migration failed halfway and the schema needs a manual fix.
+	Dirty bool
+	// Latest is the version of the newest embedded migration.
+	Latest uint
+	// Pending counts the migrations newer than Version.
+	Pending int
+}
+
+// Runner migrates a single database.
+type Runner struct {
+	migrate *migrate.Migrate
+}
+
+// New returns the runner of the database of the DSN, it must be closed.
+func New(dsn string) (*Runner, error) {
+	source, err := iofs.New(files, ".")
+	if err != nil {
+		return nil, fmt.Errorf("error reading embedded migrations: %w", err)
+	}
+
+	m, err := migrate.NewWithSourceInstance("iofs", source, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("error creating migrate instance: %w", err)
+	}
+
+	return &Runner{migrate: m}, nil
+}
+
+// Up applies every pending migration.
+func (r *Runner) Up() error {
+	if err := r.migrate.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+
+	return nil
+}
+
+// Down reverts the last steps migrations.
+func (r *Runner) Down(steps int) error {
+	if err := r.migrate.Steps(-steps); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+
+	return nil
+}
+
+func (r *Runner) Status() (*Status, error) {
+	status := &Status{}
+
+	var err error
+	status.Version, status.Dirty, err = r.migrate.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return nil, err
+	}
+
+	versions, err := versions()
+	if err != nil {
+		return nil, err
+	}
+	for _, version := range versions {
+		if version > status.Version {
+			status.Pending++
+		}
+		status.Latest = version
+	}
+
+	return status, nil
+}
+
+func (r *Runner) Close() error {
+	sourceErr, dbErr := r.migrate.Close()
+
+	return errors.Join(sourceErr, dbErr)
+}
+
+// versions lists the versions of the embedded migrations in ascending order.
+func versions() ([]uint, error) {
+	source, err := iofs.New(files, ".")
+	if err != nil {
+		return nil, fmt.Errorf("error reading embedded migrations: %w", err)
+	}
+	defer source.Close()
+
+	var versions []uint
+	version, err := source.First()
+	for err == nil {
+		versions = append(versions, version)
+		version, err = source.Next(version)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	return versions, nil
+}
@@ -0,0 +1,159 @@
+// Package todo embeds the todo app in another binary. The app serves its REST and gRPC APIs and
+// runs its background workers like the recipe command does, on the context of the host:
+//
+//	cfg, err := todo.LoadConfig()
+//	if err != nil {
+//		return err
+//	}
+//	return todo.New(cfg, todo.WithMailer(mailer)).Start(ctx)
+//
+// The options replace the parts the config would pick otherwise, e.g. to deliver emails through
+// the host application.
+package todo
+
+import (
+	"context"
+
+	"github.com/meowmix1337/go-core/db"
+	"github.com/meowmix1337/the_recipe_book/internal/api"
+	"github.com/meowmix1337/the_recipe_book/internal/config"
+	"github.com/meowmix1337/the_recipe_book/internal/llm"
+	"github.com/meowmix1337/the_recipe_book/internal/mail"
+	"github.com/meowmix1337/the_recipe_book/internal/model/domain"
+	"github.com/meowmix1337/the_recipe_book/internal/plugin"
+	"github.com/meowmix1337/the_recipe_book/internal/repo"
+	"github.com/meowmix1337/the_recipe_book/internal/storage"
+	"github.com/meowmix1337/the_recipe_book/internal/webhook"
+)
+
+// The types the options take, they are aliases so the app uses them as they are.
+type (
+	// Config is the configuration of the app, see LoadConfig.
+	Config = config.Config
+
+	MailSender  = mail.Sender
+	MailMessage = mail.Message
+
+	WebhookSender  = webhook.Sender
+	WebhookMessage = webhook.Message
+
+	// Storage stores the attachments and exports, its signed URLs must be reachable by users.
+	Storage = storage.Storage
+
+	LanguageModel   = llm.Model
+	LanguageRequest = llm.Request
+	Embedder        = llm.Embedder
+
+	UserRepo = repo.UserRepo
+	ListRepo = repo.ListRepo
+	TodoRepo = repo.TodoRepo
+
+	// Plugin hooks into the app, see RegisterPlugin.
+	Plugin     = plugin.Plugin
+	TodoHook   = plugin.TodoHook
+	SignupHook = plugin.SignupHook
+	RouteHook  = plugin.RouteHook
+	TodoEvent  = domain.TodoEvent
+	User       = domain.User
+)
+
+// RegisterPlugin makes a plugin available to every app, it is usually called from the init func
+// of the package of the plugin. The hooks of plugins run in ascending order.
+func RegisterPlugin(name string, order int, p Plugin) {
+	plugin.Register(name, order, p)
+}
+
+// LoadConfig reads the config like the recipe command, from the environment, the .env file and
+// the CONFIG_FILE, and validates it.
+func LoadConfig() (Config, error) {
+	cfg, err := config.NewConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// App is an embedded instance of the todo app.
+type App struct {
+	server *api.Server
+}
+
+// Option changes how the app is assembled.
+type Option func(server *api.Server)
+
+// New assembles the app, nothing connects before Start.
+func New(cfg Config, opts ...Option) *App {
+	server := api.NewServer(cfg)
+	for _, opt := range opts {
+		opt(server)
+	}
+
+	return &App{server: server}
+}
+
+// Start runs the app until ctx is done, then it drains the requests in progress and returns.
+// It returns early with an error when the app can't be set up or a listener fails.
+func (a *App) Start(ctx context.Context) error {
+	return a.server.Start(ctx)
+}
+
+// WithMailer sends the emails of the app through sender instead of the configured provider.
+func WithMailer(sender MailSender) Option {
+	return func(server *api.Server) {
+		server.Mailer = sender
+	}
+}
+
+// WithWebhookSender delivers webhooks through sender instead of plain HTTP requests.
+func WithWebhookSender(sender WebhookSender) Option {
+	return func(server *api.Server) {
+		server.WebhookSender = sender
+	}
+}
+
+// WithStorage stores files in store instead of the configured storage driver.
+func WithStorage(store Storage) Option {
+	return func(server *api.Server) {
+		server.Storage = store
+	}
+}
+
+// WithLanguageModel proposes todo breakdowns with model instead of the configured provider.
+func WithLanguageModel(model LanguageModel) Option {
+	return func(server *api.Server) {
+		server.LanguageModel = model
+	}
+}
+
+// WithEmbedder embeds todos and queries for semantic search with embedder instead of the
+// configured provider.
+func WithEmbedder(embedder Embedder) Option {
+	return func(server *api.Server) {
+		server.Embedder = embedder
+	}
+}
+
+// WithUserRepo replaces the user repo of each database, wrap gets the built-in repo to delegate
+// to.
+func WithUserRepo(wrap func(database db.DB, builtin UserRepo) UserRepo) Option {
+	return func(server *api.Server) {
+		server.UserRepo = wrap
+	}
+}
+
+// WithListRepo replaces the list repo of each database, wrap gets the built-in repo to delegate
+// to.
+func WithListRepo(wrap func(database db.DB, builtin ListRepo) ListRepo) Option {
+	return func(server *api.Server) {
+		server.ListRepo = wrap
+	}
+}
+
+// WithTodoRepo replaces the todo repo of each database, wrap gets the built-in repo to delegate
+// to. Shadow writes still wrap the replacement.
+func WithTodoRepo(wrap func(database db.DB, builtin TodoRepo) TodoRepo) Option {
+	return func(server *api.Server) {
+		server.TodoRepo = wrap
+	}
+}